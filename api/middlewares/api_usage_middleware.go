@@ -0,0 +1,229 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+const (
+	apiUsageDefaultFlushInterval = 30
+	apiUsageDefaultMaxPending    = 5000
+)
+
+// apiUsageKey 聚合键：小时、用户、路由
+type apiUsageKey struct {
+	bucket   time.Time
+	userID   uint64
+	username string
+	method   string
+	route    string
+}
+
+// apiUsageEvent 单次请求的统计数据
+type apiUsageEvent struct {
+	key      apiUsageKey
+	status   int
+	duration time.Duration
+}
+
+// ApiUsageMiddleware 接口调用统计中间件
+// 请求结束后将统计事件写入 channel，由后台 worker 在内存中聚合并定期批量写库，服务停止时写入剩余的统计
+type ApiUsageMiddleware struct {
+	handler         lib.HttpHandler
+	logger          lib.Logger
+	config          lib.Config
+	apiUsageService service.ApiUsageService
+	usageCh         chan apiUsageEvent
+}
+
+// NewApiUsageMiddleware creates new api usage middleware
+func NewApiUsageMiddleware(
+	lc fx.Lifecycle,
+	handler lib.HttpHandler,
+	logger lib.Logger,
+	config lib.Config,
+	apiUsageService service.ApiUsageService,
+) ApiUsageMiddleware {
+	m := ApiUsageMiddleware{
+		handler:         handler,
+		logger:          logger,
+		config:          config,
+		apiUsageService: apiUsageService,
+	}
+
+	if cfg := config.Analytics; cfg != nil && cfg.Enable {
+		m.usageCh = make(chan apiUsageEvent, 1024)
+
+		interval := cfg.FlushInterval
+		if interval <= 0 {
+			interval = apiUsageDefaultFlushInterval
+		}
+		maxPending := cfg.MaxPending
+		if maxPending <= 0 {
+			maxPending = apiUsageDefaultMaxPending
+		}
+
+		done, stopped := make(chan struct{}), make(chan struct{})
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				go m.usageWorker(done, stopped, time.Duration(interval)*time.Second, maxPending)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				close(done)
+				select {
+				case <-stopped:
+				case <-ctx.Done():
+					m.logger.Zap.Warn("Timed out flushing api usage on shutdown")
+				}
+				return nil
+			},
+		})
+	}
+
+	return m
+}
+
+// usageWorker 聚合统计事件并定期写库，done 关闭后取完 channel 中的事件、写库并关闭 stopped
+func (m ApiUsageMiddleware) usageWorker(done, stopped chan struct{}, interval time.Duration, maxPending int) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pending := make(map[apiUsageKey]*system.ApiUsage)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+
+		list := make(system.ApiUsages, 0, len(pending))
+		for _, item := range pending {
+			list = append(list, item)
+		}
+		pending = make(map[apiUsageKey]*system.ApiUsage)
+
+		if err := m.apiUsageService.Accumulate(list); err != nil {
+			m.logger.Zap.Errorf("Failed to save api usage: %v", err)
+		}
+	}
+
+	add := func(event apiUsageEvent) {
+		item, ok := pending[event.key]
+		if !ok {
+			item = &system.ApiUsage{
+				BucketTime: event.key.bucket,
+				UserID:     event.key.userID,
+				Username:   event.key.username,
+				Method:     event.key.method,
+				Route:      event.key.route,
+			}
+			pending[event.key] = item
+		}
+
+		item.RequestCount++
+		item.DurationMs += event.duration.Milliseconds()
+		if event.status >= http.StatusBadRequest {
+			item.ErrorCount++
+		}
+		if event.status >= http.StatusInternalServerError {
+			item.ServerErrorCount++
+		}
+
+		if len(pending) >= maxPending {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case event := <-m.usageCh:
+			add(event)
+		case <-ticker.C:
+			flush()
+		case <-done:
+			for {
+				select {
+				case event := <-m.usageCh:
+					add(event)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Setup sets up api usage middleware
+func (m ApiUsageMiddleware) Setup() {
+	if m.usageCh == nil {
+		return
+	}
+
+	m.logger.Zap.Info("Setting up api usage middleware")
+	m.handler.Engine.Use(m.Handle())
+}
+
+// Handle 记录接口调用
+func (m ApiUsageMiddleware) Handle() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !strings.HasPrefix(ctx.Request().URL.Path, "/api/") {
+				return next(ctx)
+			}
+
+			start := time.Now()
+			err := next(ctx)
+
+			// 未匹配到路由的请求不统计，避免路径扫描产生大量无意义的行
+			route := ctx.Path()
+			if route == "" || strings.HasSuffix(route, "/*") {
+				return err
+			}
+
+			status := ctx.Response().Status
+			if err != nil && !ctx.Response().Committed {
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+
+			event := apiUsageEvent{
+				key: apiUsageKey{
+					bucket: start.Truncate(time.Hour),
+					method: ctx.Request().Method,
+					route:  route,
+				},
+				status:   status,
+				duration: time.Since(start),
+			}
+			if claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims != nil {
+				event.key.userID = claims.ID
+				event.key.username = claims.Username
+			}
+
+			select {
+			case m.usageCh <- event:
+			default:
+				m.logger.Zap.Warn("Api usage channel full, dropping usage event")
+			}
+
+			return err
+		}
+	}
+}
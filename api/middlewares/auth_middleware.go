@@ -4,19 +4,34 @@ import (
 	"net/http"
 	"strings"
 
+	platformservice "github.com/top-system/light-admin/api/platform/service"
 	"github.com/top-system/light-admin/api/system/service"
 	"github.com/top-system/light-admin/constants"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/pkg/echox"
+	ws "github.com/top-system/light-admin/pkg/websocket"
 	"github.com/labstack/echo/v4"
 )
 
+// 签名下载地址，由接口自身校验签名和有效期，跳过登录和权限校验
+var signedPathPrefixes = []string{
+	service.NoticeAttachmentDownloadPath,
+	platformservice.FileSignedDownloadPath,
+	// 刷新令牌时访问令牌可能已过期，不依赖配置中的 IgnorePathPrefixes
+	service.RefreshTokenPath,
+	// EventSource 无法设置请求头，由接口自身从 token 参数或 Authorization 头校验令牌
+	ws.EventStreamPath,
+}
+
 // AuthMiddleware middleware for cors
 type AuthMiddleware struct {
-	config      lib.Config
-	handler     lib.HttpHandler
-	logger      lib.Logger
-	authService service.AuthService
+	config        lib.Config
+	handler       lib.HttpHandler
+	logger        lib.Logger
+	registry      lib.PermRegistry
+	authService   service.AuthService
+	apiKeyService service.ApiKeyService
 }
 
 // NewCorsMiddleware creates new cors middleware
@@ -24,13 +39,17 @@ func NewAuthMiddleware(
 	config lib.Config,
 	handler lib.HttpHandler,
 	logger lib.Logger,
+	registry lib.PermRegistry,
 	authService service.AuthService,
+	apiKeyService service.ApiKeyService,
 ) AuthMiddleware {
 	return AuthMiddleware{
-		config:      config,
-		handler:     handler,
-		logger:      logger,
-		authService: authService,
+		config:        config,
+		handler:       handler,
+		logger:        logger,
+		registry:      registry,
+		authService:   authService,
+		apiKeyService: apiKeyService,
 	}
 }
 
@@ -40,7 +59,33 @@ func (a AuthMiddleware) core() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
 			request := ctx.Request()
-			if isIgnorePath(request.URL.Path, prefixes...) {
+			if isIgnorePath(request.URL.Path, prefixes...) || isIgnorePath(request.URL.Path, signedPathPrefixes...) {
+				return next(ctx)
+			}
+			// 声明为免登录访问的路由（见 PermGroup.Public）
+			if rp, ok := a.registry.Get(request.Method, ctx.Path()); ok && rp.Public {
+				return next(ctx)
+			}
+
+			// mTLS 监听上已校验的客户端证书，按服务账号认证
+			if request.TLS != nil && len(request.TLS.VerifiedChains) > 0 {
+				claims, err := a.authService.ParseClientCert(request.TLS.VerifiedChains[0][0])
+				if err != nil {
+					return echox.Response{Code: http.StatusUnauthorized, Message: err}.JSON(ctx)
+				}
+
+				ctx.Set(constants.CurrentUser, claims)
+				return next(ctx)
+			}
+
+			// 机器客户端使用 API Key 认证
+			if key := request.Header.Get(system.ApiKeyHeader); key != "" {
+				claims, err := a.apiKeyService.Authenticate(key, ctx.RealIP())
+				if err != nil {
+					return echox.Response{Code: http.StatusUnauthorized, Message: err}.JSON(ctx)
+				}
+
+				ctx.Set(constants.CurrentUser, claims)
 				return next(ctx)
 			}
 
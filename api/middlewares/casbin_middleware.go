@@ -16,8 +16,10 @@ type PermissionMiddleware struct {
 	handler           lib.HttpHandler
 	logger            lib.Logger
 	config            lib.Config
+	registry          lib.PermRegistry
 	permissionService service.PermissionService
 	userService       service.UserService
+	policyService     service.PolicyService
 }
 
 // NewPermissionMiddleware creates new permission middleware
@@ -25,15 +27,19 @@ func NewPermissionMiddleware(
 	handler lib.HttpHandler,
 	logger lib.Logger,
 	config lib.Config,
+	registry lib.PermRegistry,
 	permissionService service.PermissionService,
 	userService service.UserService,
+	policyService service.PolicyService,
 ) PermissionMiddleware {
 	return PermissionMiddleware{
 		handler:           handler,
 		logger:            logger,
 		config:            config,
+		registry:          registry,
 		permissionService: permissionService,
 		userService:       userService,
+		policyService:     policyService,
 	}
 }
 
@@ -43,7 +49,11 @@ func (a PermissionMiddleware) core() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
 			request := ctx.Request()
-			if isIgnorePath(request.URL.Path, prefixes...) {
+			if isIgnorePath(request.URL.Path, prefixes...) || isIgnorePath(request.URL.Path, signedPathPrefixes...) {
+				return next(ctx)
+			}
+			// 声明为免登录访问的路由（见 PermGroup.Public）
+			if rp, ok := a.registry.Get(request.Method, ctx.Path()); ok && rp.Public {
 				return next(ctx)
 			}
 
@@ -52,10 +62,18 @@ func (a PermissionMiddleware) core() echo.MiddlewareFunc {
 				return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
 			}
 
-			// For now, just pass through if user is authenticated
-			// The permission check can be done at the controller level
-			// by checking the perm identifier
-			_ = claims
+			// perm 标识在路由级别检查（见 RequirePerm），
+			// 开启 Enforce 时另外按访问策略检查，超级管理员（API Key 除外）不受限制
+			if !a.config.Casbin.Enforce || a.userService.IsSuperAdminClaims(claims) {
+				return next(ctx)
+			}
+
+			allowed, err := a.policyService.Enforce(claims, request)
+			if err != nil {
+				return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+			} else if !allowed {
+				return echox.Response{Code: http.StatusForbidden, Message: "没有访问权限"}.JSON(ctx)
+			}
 
 			return next(ctx)
 		}
@@ -71,25 +89,33 @@ func (a PermissionMiddleware) RequirePerm(perm string) echo.MiddlewareFunc {
 				return echox.Response{Code: http.StatusUnauthorized, Message: "未授权"}.JSON(ctx)
 			}
 
-			// 超级管理员跳过权限检查
-			if a.userService.IsSuperAdmin(claims.Username) {
+			// 超级管理员跳过权限检查，其 API Key 仍受授权范围限制
+			if a.userService.IsSuperAdminClaims(claims) {
 				return next(ctx)
 			}
 
-			// 获取用户角色
-			roleIDs, err := a.permissionService.GetUserRoleIDs(claims.ID)
+			// 从缓存读取用户权限标识
+			perms, err := a.permissionService.GetClaimsPerms(claims)
 			if err != nil {
 				return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
 			}
 
-			// 检查权限
-			hasPerm, err := a.permissionService.HasPerm(roleIDs, perm)
-			if err != nil {
-				return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+			if !service.MatchPerm(perms, perm) {
+				return echox.Response{Code: http.StatusForbidden, Message: "没有操作权限"}.JSON(ctx)
 			}
 
-			if !hasPerm {
-				return echox.Response{Code: http.StatusForbidden, Message: "没有操作权限"}.JSON(ctx)
+			return next(ctx)
+		}
+	}
+}
+
+// DenyApiKey 拒绝 API Key 访问，用于未声明 perm 的路由：
+// 授权范围只能约束声明了 perm 的接口，未声明的接口默认不向 API Key 开放
+func (a PermissionMiddleware) DenyApiKey() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims.ApiKeyID != 0 {
+				return echox.Response{Code: http.StatusForbidden, Message: "API Key 无权访问该接口"}.JSON(ctx)
 			}
 
 			return next(ctx)
@@ -97,6 +123,100 @@ func (a PermissionMiddleware) RequirePerm(perm string) echo.MiddlewareFunc {
 	}
 }
 
+// Group 包装路由组，组内每个路由都需要声明所需的 perm 标识
+func (a PermissionMiddleware) Group(group *echo.Group) PermGroup {
+	return PermGroup{group: group, middleware: a}
+}
+
+// PermGroup 带权限声明的路由组
+// 注册路由时同时写入 PermRegistry，perm 为空表示登录即可访问（API Key 需经 AllowApiKey 显式开放），
+// 免登录访问的路由经 Public 声明
+type PermGroup struct {
+	group      *echo.Group
+	middleware PermissionMiddleware
+	title      string
+	params     interface{}
+	apiKey     bool
+	public     bool
+}
+
+// Describe 为下一个注册的路由补充操作标题及参数结构（查询参数或请求体），
+// 用于 /meta/actions 生成操作描述，如：
+//
+//	api.Describe("新增用户", system.UserForm{}).POST("", a.userController.Create, "sys:user:add")
+func (g PermGroup) Describe(title string, params interface{}) PermGroup {
+	g.title = title
+	g.params = params
+	return g
+}
+
+// AllowApiKey 允许 API Key 访问下一个注册的无 perm 路由，仅用于只读且不涉及账号安全的接口
+func (g PermGroup) AllowApiKey() PermGroup {
+	g.apiKey = true
+	return g
+}
+
+// Public 声明下一个注册的路由免登录访问，perm 须为空；
+// 仅用于登录、刷新令牌、验证码以及由接口自身校验签名或令牌的路由，登录及权限中间件均按该声明放行
+func (g PermGroup) Public() PermGroup {
+	g.public = true
+	return g
+}
+
+// Add 注册路由并声明所需权限
+func (g PermGroup) Add(method, path string, h echo.HandlerFunc, perm string, m ...echo.MiddlewareFunc) *echo.Route {
+	if g.public {
+		if perm != "" {
+			panic("public route " + method + " " + path + " must not declare a perm")
+		}
+
+		route := g.group.Add(method, path, h, m...)
+		g.middleware.registry.RegisterPublic(route.Method, route.Path)
+		if g.title != "" || g.params != nil {
+			g.middleware.registry.Describe(route.Method, route.Path, g.title, g.params)
+		}
+		return route
+	}
+
+	if perm != "" {
+		m = append([]echo.MiddlewareFunc{g.middleware.RequirePerm(perm)}, m...)
+	} else if !g.apiKey {
+		m = append([]echo.MiddlewareFunc{g.middleware.DenyApiKey()}, m...)
+	}
+
+	route := g.group.Add(method, path, h, m...)
+	g.middleware.registry.Register(route.Method, route.Path, perm)
+	if g.title != "" || g.params != nil {
+		g.middleware.registry.Describe(route.Method, route.Path, g.title, g.params)
+	}
+	return route
+}
+
+// GET registers a GET route with its required perm
+func (g PermGroup) GET(path string, h echo.HandlerFunc, perm string, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.Add(http.MethodGet, path, h, perm, m...)
+}
+
+// POST registers a POST route with its required perm
+func (g PermGroup) POST(path string, h echo.HandlerFunc, perm string, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.Add(http.MethodPost, path, h, perm, m...)
+}
+
+// PUT registers a PUT route with its required perm
+func (g PermGroup) PUT(path string, h echo.HandlerFunc, perm string, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.Add(http.MethodPut, path, h, perm, m...)
+}
+
+// PATCH registers a PATCH route with its required perm
+func (g PermGroup) PATCH(path string, h echo.HandlerFunc, perm string, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.Add(http.MethodPatch, path, h, perm, m...)
+}
+
+// DELETE registers a DELETE route with its required perm
+func (g PermGroup) DELETE(path string, h echo.HandlerFunc, perm string, m ...echo.MiddlewareFunc) *echo.Route {
+	return g.Add(http.MethodDelete, path, h, perm, m...)
+}
+
 func (a PermissionMiddleware) Setup() {
 	if !a.config.Casbin.Enable {
 		return
@@ -113,8 +233,10 @@ func NewCasbinMiddleware(
 	handler lib.HttpHandler,
 	logger lib.Logger,
 	config lib.Config,
+	registry lib.PermRegistry,
 	permissionService service.PermissionService,
 	userService service.UserService,
+	policyService service.PolicyService,
 ) CasbinMiddleware {
-	return NewPermissionMiddleware(handler, logger, config, permissionService, userService)
+	return NewPermissionMiddleware(handler, logger, config, registry, permissionService, userService, policyService)
 }
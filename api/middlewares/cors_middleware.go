@@ -43,7 +43,7 @@ func (a CorsMiddleware) Setup() {
 		AllowCredentials: true,
 		AllowHeaders: []string{
 			"Authorization", "Content-Type", "Accept", "Origin",
-			"X-Requested-With", "X-Request-ID",
+			"X-Requested-With", "X-Request-ID", "X-API-Version",
 		},
 		AllowMethods: []string{
 			http.MethodGet, http.MethodPost, http.MethodPut,
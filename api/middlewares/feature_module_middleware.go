@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// FeatureModuleMiddleware 功能模块中间件，已禁用模块的接口按未注册处理，返回 404
+type FeatureModuleMiddleware struct {
+	handler        lib.HttpHandler
+	logger         lib.Logger
+	featureModules lib.FeatureModules
+}
+
+// NewFeatureModuleMiddleware creates new feature module middleware
+func NewFeatureModuleMiddleware(handler lib.HttpHandler, logger lib.Logger, featureModules lib.FeatureModules) FeatureModuleMiddleware {
+	return FeatureModuleMiddleware{
+		handler:        handler,
+		logger:         logger,
+		featureModules: featureModules,
+	}
+}
+
+func (a FeatureModuleMiddleware) Setup() {
+	a.handler.Engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if name, disabled := a.featureModules.DisabledByPath(ctx.Request().URL.Path); disabled {
+				return echox.Response{Code: http.StatusNotFound, Message: errors.WithMessage(errors.FeatureModuleDisabled, name)}.JSON(ctx)
+			}
+
+			return next(ctx)
+		}
+	})
+}
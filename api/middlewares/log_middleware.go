@@ -2,7 +2,9 @@ package middlewares
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -11,29 +13,53 @@ import (
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/queue"
 	"github.com/labstack/echo/v4"
 	"github.com/mssola/useragent"
 )
 
+// LogSaveTaskType 写入操作日志与操作审计日志的队列任务类型
+const LogSaveTaskType = "log_save"
+
+// logBodyLimit 操作日志记录的请求参数与响应内容的最大字节数
+const logBodyLimit = 4096
+
+// logEntry 一次请求产生的操作日志（logModules 中的接口）与操作审计日志（开启 OperationLog 时的全部修改类请求），
+// 请求体只读取一次，两者在同一个任务中写入
+type logEntry struct {
+	log   *system.Log
+	audit *system.OperationLog
+}
+
 // LogMiddleware 日志中间件
+// 启用任务队列时日志通过队列任务写入，否则由 worker 写入
 type LogMiddleware struct {
-	handler    lib.HttpHandler
-	logger     lib.Logger
-	logService service.LogService
-	logCh      chan *system.Log // 日志写入 channel，替代无限制 goroutine
+	handler             lib.HttpHandler
+	logger              lib.Logger
+	config              lib.Config
+	taskQueue           lib.TaskQueue
+	logService          service.LogService
+	operationLogService service.OperationLogService
+	logCh               chan logEntry // 日志写入 channel，替代无限制 goroutine
 }
 
 // NewLogMiddleware creates new log middleware
 func NewLogMiddleware(
 	handler lib.HttpHandler,
 	logger lib.Logger,
+	config lib.Config,
+	taskQueue lib.TaskQueue,
 	logService service.LogService,
+	operationLogService service.OperationLogService,
 ) LogMiddleware {
 	m := LogMiddleware{
-		handler:    handler,
-		logger:     logger,
-		logService: logService,
-		logCh:      make(chan *system.Log, 256), // 缓冲 256 条日志
+		handler:             handler,
+		logger:              logger,
+		config:              config,
+		taskQueue:           taskQueue,
+		logService:          logService,
+		operationLogService: operationLogService,
+		logCh:               make(chan logEntry, 256), // 缓冲 256 条日志
 	}
 
 	// 启动日志写入 worker（2个 worker 处理异步日志）
@@ -46,11 +72,43 @@ func NewLogMiddleware(
 
 // logWorker 日志写入协程（worker pool 模式，替代每次请求 spawn goroutine）
 func (m LogMiddleware) logWorker() {
-	for log := range m.logCh {
-		if err := m.logService.Create(log); err != nil {
+	for entry := range m.logCh {
+		m.save(entry)
+	}
+}
+
+// save 写入一次请求的日志，失败只记录错误，不重试，避免已写入的日志重复写入
+func (m LogMiddleware) save(entry logEntry) {
+	if entry.log != nil {
+		if err := m.logService.Create(entry.log); err != nil {
 			m.logger.Zap.Errorf("Failed to save log: %v", err)
 		}
 	}
+	if entry.audit != nil {
+		if err := m.operationLogService.Save(system.OperationLogs{entry.audit}); err != nil {
+			m.logger.Zap.Errorf("Failed to save operation log: %v", err)
+		}
+	}
+}
+
+// enqueue 启用任务队列时提交写入任务，否则交给 worker，channel 已满时丢弃
+func (m LogMiddleware) enqueue(entry logEntry) {
+	if m.taskQueue.IsEnabled() {
+		task := queue.NewFuncTask(LogSaveTaskType, nil, func(ctx context.Context) error {
+			m.save(entry)
+			return nil
+		})
+		if err := m.taskQueue.QueueTask(context.Background(), task); err != nil {
+			m.logger.Zap.Errorf("Failed to queue log task: %v", err)
+		}
+		return
+	}
+
+	select {
+	case m.logCh <- entry:
+	default:
+		m.logger.Zap.Warn("Log channel full, dropping log entry")
+	}
 }
 
 // Setup sets up the log middleware
@@ -82,6 +140,11 @@ var logModules = map[string]string{
 	"PUT:/api/v1/depts":    "部门管理",
 	"DELETE:/api/v1/depts": "部门管理",
 
+	// 部门默认角色规则（预览、同步不记录，同步时按用户写入变更日志）
+	"POST:/api/v1/dept-role-rules":   "部门默认角色规则",
+	"PUT:/api/v1/dept-role-rules":    "部门默认角色规则",
+	"DELETE:/api/v1/dept-role-rules": "部门默认角色规则",
+
 	// 字典管理
 	"POST:/api/v1/dicts":         "字典管理",
 	"PUT:/api/v1/dicts":          "字典管理",
@@ -109,8 +172,10 @@ var logModules = map[string]string{
 	"DELETE:/api/v1/files": "文件删除",
 
 	// 登录登出
-	"POST:/api/v1/auth/login":    "登录",
-	"DELETE:/api/v1/auth/logout": "登出",
+	"POST:/api/v1/auth/login":     "登录",
+	"POST:/api/v1/auth/refresh":   "刷新令牌",
+	"DELETE:/api/v1/auth/logout":  "登出",
+	"DELETE:/api/v1/auth/devices": "移除登录设备",
 }
 
 // 定义操作内容
@@ -132,6 +197,8 @@ var selfDescribedModules = map[string]bool{
 	"文件删除":    true,
 	"登录":      true,
 	"登出":      true,
+	"刷新令牌":    true,
+	"移除登录设备":  true,
 }
 
 // responseWriter 包装响应写入器以捕获响应内容
@@ -146,11 +213,19 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 
 // Handle 处理日志记录
 func (m LogMiddleware) Handle() echo.MiddlewareFunc {
+	cfg := m.config.OperationLog
+	maxBodySize := operationLogDefaultMaxBodySize
+	if cfg != nil && cfg.MaxBodySize > 0 {
+		maxBodySize = cfg.MaxBodySize
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// 只记录 POST, PUT, DELETE 请求
+			// 只记录修改类请求
 			method := c.Request().Method
-			if method != "POST" && method != "PUT" && method != "DELETE" {
+			switch method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
 				return next(c)
 			}
 
@@ -159,91 +234,103 @@ func (m LogMiddleware) Handle() echo.MiddlewareFunc {
 			// 移除路径中的数字ID，用于匹配
 			basePath := removePathIDs(path)
 			key := method + ":" + basePath
-			module, ok := logModules[key]
-			if !ok {
+			module, logged := logModules[key]
+			audited := cfg != nil && cfg.Enable && strings.HasPrefix(path, "/api/") && !isIgnorePath(path, cfg.IgnorePathPrefixes...)
+			if !logged && !audited {
 				return next(c)
 			}
 
 			startTime := time.Now()
 
-			// 读取请求体（跳过文件上传，避免将整个文件读入内存）
-			var requestBody []byte
-			contentType := c.Request().Header.Get("Content-Type")
-			isMultipart := strings.HasPrefix(contentType, "multipart/form-data")
-			if c.Request().Body != nil && !isMultipart {
-				requestBody, _ = io.ReadAll(io.LimitReader(c.Request().Body, 4096))
-				c.Request().Body = io.NopCloser(bytes.NewBuffer(requestBody))
+			// 读取请求体（跳过文件上传，避免将整个文件读入内存）；UTF-8 下 maxBodySize 个字符最多 4*maxBodySize 字节
+			limit := logBodyLimit
+			if audited && maxBodySize*4+1 > limit {
+				limit = maxBodySize*4 + 1
 			}
+			requestBody := readBodyHead(c.Request(), limit)
 
 			// 捕获响应
-			resBody := new(bytes.Buffer)
-			mw := io.MultiWriter(c.Response().Writer, resBody)
-			writer := &responseWriter{Writer: mw, Response: *c.Response()}
-			c.Response().Writer = writer
+			var resBody *bytes.Buffer
+			if logged {
+				resBody = new(bytes.Buffer)
+				mw := io.MultiWriter(c.Response().Writer, resBody)
+				writer := &responseWriter{Writer: mw, Response: *c.Response()}
+				c.Response().Writer = writer
+			}
 
 			// 执行下一个处理器
 			err := next(c)
 
-			// 计算执行时间
-			executionTime := time.Since(startTime).Milliseconds()
-
-			// 获取用户信息
-			var createBy uint64
-			if claims, ok := c.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims != nil {
-				createBy = claims.ID
+			var entry logEntry
+			if audited {
+				entry.audit = newOperationLog(c, bodySummary(c.Request(), requestBody, maxBodySize), responseStatus(c, err), startTime)
 			}
-
-			// 解析 User-Agent
-			ua := useragent.New(c.Request().UserAgent())
-			browserName, browserVersion := ua.Browser()
-			osInfo := ua.OS()
-
-			// 获取操作内容
-			var content string
-			if selfDescribedModules[module] {
-				// 模块名已包含完整操作描述，直接使用
-				content = module
-			} else {
-				// 普通模块，添加操作前缀
-				content = logContents[method]
-				if content == "" {
-					content = method
-				}
-				content = content + module
+			if logged {
+				entry.log = newLog(c, module, requestBody, resBody.String(), startTime)
 			}
 
-			// 截断过长的响应内容
-			responseContent := resBody.String()
-			if len(responseContent) > 4096 {
-				responseContent = responseContent[:4096]
-			}
+			// 异步保存日志
+			m.enqueue(entry)
 
-			// 创建日志记录
-			log := &system.Log{
-				Module:          module,
-				RequestMethod:   method,
-				RequestParams:   string(requestBody),
-				ResponseContent: responseContent,
-				Content:         content,
-				RequestURI:      path,
-				Method:          c.Path(),
-				IP:              c.RealIP(),
-				ExecutionTime:   executionTime,
-				Browser:         browserName,
-				BrowserVersion:  browserVersion,
-				OS:              osInfo,
-				CreateBy:        createBy,
-			}
+			return err
+		}
+	}
+}
 
-			// 异步保存日志（通过 worker pool channel）
-			select {
-			case m.logCh <- log:
-			default:
-				m.logger.Zap.Warn("Log channel full, dropping log entry")
-			}
+// newLog logModules 中接口的操作日志
+func newLog(c echo.Context, module string, requestBody []byte, responseContent string, startTime time.Time) *system.Log {
+	method := c.Request().Method
 
-			return err
+	// 计算执行时间
+	executionTime := time.Since(startTime).Milliseconds()
+
+	// 获取用户信息
+	var createBy uint64
+	if claims, ok := c.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims != nil {
+		createBy = claims.ID
+	}
+
+	// 解析 User-Agent
+	ua := useragent.New(c.Request().UserAgent())
+	browserName, browserVersion := ua.Browser()
+	osInfo := ua.OS()
+
+	// 获取操作内容
+	var content string
+	if selfDescribedModules[module] {
+		// 模块名已包含完整操作描述，直接使用
+		content = module
+	} else {
+		// 普通模块，添加操作前缀
+		content = logContents[method]
+		if content == "" {
+			content = method
 		}
+		content = content + module
+	}
+
+	// 截断过长的请求参数与响应内容
+	if len(requestBody) > logBodyLimit {
+		requestBody = requestBody[:logBodyLimit]
+	}
+	if len(responseContent) > logBodyLimit {
+		responseContent = responseContent[:logBodyLimit]
+	}
+
+	return &system.Log{
+		Module:          module,
+		RequestMethod:   method,
+		RequestParams:   string(requestBody),
+		ResponseContent: responseContent,
+		Content:         content,
+		RequestURI:      c.Request().URL.Path,
+		Method:          c.Path(),
+		IP:              c.RealIP(),
+		ExecutionTime:   executionTime,
+		Browser:         browserName,
+		BrowserVersion:  browserVersion,
+		OS:              osInfo,
+		CreateBy:        createBy,
 	}
 }
 
@@ -252,12 +339,12 @@ var knownPathSegments = map[string]bool{
 	"api": true, "v1": true,
 	"users": true, "password": true, "reset": true,
 	"roles": true, "menus": true,
-	"depts": true,
+	"depts": true, "dept-role-rules": true, "preview": true, "apply": true,
 	"dicts": true, "items": true,
 	"notices": true, "publish": true, "revoke": true, "read-all": true,
 	"configs": true, "refresh": true,
 	"files": true,
-	"auth": true, "login": true, "logout": true,
+	"auth": true, "login": true, "logout": true, "devices": true,
 	"ws": true, "sendToAll": true, "sendToUser": true, "dict-change": true,
 	"logs": true,
 }
@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/lib"
+)
+
+// metricsUnmatchedRoute 未匹配到路由的请求统一使用的标签，避免路径扫描产生大量时间序列
+const metricsUnmatchedRoute = "unmatched"
+
+// MetricsMiddleware Prometheus HTTP 指标中间件
+// 按路由模板、方法、状态码统计请求数及耗时，未启用指标时不注册
+type MetricsMiddleware struct {
+	handler lib.HttpHandler
+	logger  lib.Logger
+	metrics lib.Metrics
+}
+
+// NewMetricsMiddleware creates new metrics middleware
+func NewMetricsMiddleware(
+	handler lib.HttpHandler,
+	logger lib.Logger,
+	metrics lib.Metrics,
+) MetricsMiddleware {
+	return MetricsMiddleware{
+		handler: handler,
+		logger:  logger,
+		metrics: metrics,
+	}
+}
+
+// Setup sets up metrics middleware
+func (m MetricsMiddleware) Setup() {
+	if !m.metrics.IsEnabled() {
+		return
+	}
+
+	m.logger.Zap.Info("Setting up metrics middleware")
+	m.handler.Engine.Use(m.Handle())
+}
+
+// Handle 记录请求指标
+func (m MetricsMiddleware) Handle() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			route := ctx.Path()
+			if route == "" || strings.HasSuffix(route, "/*") {
+				route = metricsUnmatchedRoute
+			}
+
+			status := ctx.Response().Status
+			if err != nil && !ctx.Response().Committed {
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+
+			m.metrics.ObserveHTTP(route, ctx.Request().Method, status, time.Since(start))
+			return err
+		}
+	}
+}
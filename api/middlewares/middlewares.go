@@ -11,6 +11,12 @@ var Module = fx.Options(
 	fx.Provide(NewCasbinMiddleware),
 	fx.Provide(NewLogMiddleware),
 	fx.Provide(NewRateLimitMiddleware),
+	fx.Provide(NewReadOnlyMiddleware),
+	fx.Provide(NewFeatureModuleMiddleware),
+	fx.Provide(NewResponseCacheMiddleware),
+	fx.Provide(NewApiUsageMiddleware),
+	fx.Provide(NewMetricsMiddleware),
+	fx.Provide(NewTracingMiddleware),
 	fx.Provide(NewMiddlewares),
 )
 
@@ -32,15 +38,26 @@ func NewMiddlewares(
 	casbinMiddleware CasbinMiddleware,
 	logMiddleware LogMiddleware,
 	rateLimitMiddleware RateLimitMiddleware,
+	readOnlyMiddleware ReadOnlyMiddleware,
+	featureModuleMiddleware FeatureModuleMiddleware,
+	apiUsageMiddleware ApiUsageMiddleware,
+	metricsMiddleware MetricsMiddleware,
+	tracingMiddleware TracingMiddleware,
 ) Middlewares {
 	return Middlewares{
+		metricsMiddleware,
+		tracingMiddleware,
 		coreMiddleware,
 		rateLimitMiddleware,
 		zapMiddleware,
 		corsMiddleware,
+		featureModuleMiddleware,
 		authMiddleware,
-		casbinMiddleware,
+		apiUsageMiddleware,
+		// 在只读模式与权限检查之前记录，被拒绝的修改类请求同样写入操作审计日志
 		logMiddleware,
+		readOnlyMiddleware,
+		casbinMiddleware,
 	}
 }
 
@@ -0,0 +1,139 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+const (
+	operationLogDefaultMaxBodySize = 1000
+	operationLogMaskedValue        = "***"
+)
+
+// sensitiveWords 字段名包含这些词时脱敏；sensitiveNames 仅匹配完整的字段名（验证码、API Key 等），dictCode、configKey 等字段保留
+const (
+	sensitiveWords = `password|secret|accesskey|token|authorization|apikey|privatekey|signkey|encryptkey|captchacode|twofactorcode|verifycode`
+	sensitiveNames = `code|key`
+)
+
+// sensitiveBodyKey 请求体中需要脱敏的字段名
+var sensitiveBodyKey = regexp.MustCompile(`(?i)^(?:.*(?:` + sensitiveWords + `).*|` + sensitiveNames + `)$`)
+
+// sensitiveJSONField JSON 请求体中敏感字段的字符串值
+var sensitiveJSONField = regexp.MustCompile(`(?i)("(?:[^"]*(?:` + sensitiveWords + `)[^"]*|` + sensitiveNames + `)"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+// newOperationLog 修改类请求的操作审计日志，body 为脱敏后的请求体摘要
+func newOperationLog(ctx echo.Context, body string, status int, start time.Time) *system.OperationLog {
+	request := ctx.Request()
+	log := &system.OperationLog{
+		Module:      operationModule(request.URL.Path),
+		Method:      request.Method,
+		Path:        ctx.Path(),
+		URI:         str.S(request.URL.RequestURI()).Truncate(500),
+		RequestBody: body,
+		StatusCode:  status,
+		Latency:     time.Since(start).Milliseconds(),
+		IP:          ctx.RealIP(),
+		CreateTime:  dto.DateTime(start),
+	}
+	if claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims != nil {
+		log.UserID = claims.ID
+		log.Username = claims.Username
+	}
+
+	return log
+}
+
+// responseStatus 请求的响应状态码，处理器返回错误且尚未写入响应时按错误推断
+func responseStatus(ctx echo.Context, err error) int {
+	status := ctx.Response().Status
+	if err != nil && !ctx.Response().Committed {
+		status = http.StatusInternalServerError
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			status = he.Code
+		}
+	}
+	return status
+}
+
+// readBodyHead 读取请求体的前 limit 个字节，读取后恢复请求体供后续处理器使用
+// 文件上传不读取请求体，避免将整个文件读入内存
+func readBodyHead(request *http.Request, limit int) []byte {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil
+	}
+	if strings.HasPrefix(request.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return nil
+	}
+
+	head, _ := io.ReadAll(io.LimitReader(request.Body, int64(limit)))
+	request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), request.Body), request.Body}
+
+	return head
+}
+
+// bodySummary 请求体的前 maxSize 个字符并脱敏，文件上传只记录内容类型
+func bodySummary(request *http.Request, head []byte, maxSize int) string {
+	contentType := request.Header.Get(echo.HeaderContentType)
+	if strings.HasPrefix(contentType, echo.MIMEMultipartForm) {
+		return "[" + echo.MIMEMultipartForm + "]"
+	}
+	if len(head) == 0 {
+		return ""
+	}
+
+	var summary string
+	if strings.HasPrefix(contentType, echo.MIMEApplicationForm) {
+		summary = maskFormBody(string(head))
+	} else {
+		summary = sensitiveJSONField.ReplaceAllString(string(head), `${1}"`+operationLogMaskedValue+`"`)
+	}
+
+	return str.S(summary).Truncate(maxSize)
+}
+
+// maskFormBody 隐藏表单请求体中的敏感字段
+func maskFormBody(body string) string {
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return body
+	}
+
+	for key := range values {
+		if sensitiveBodyKey.MatchString(key) {
+			values.Set(key, operationLogMaskedValue)
+		}
+	}
+	return values.Encode()
+}
+
+// operationModule 路径 /api/v1 之后的第一段，作为操作所属的模块
+func operationModule(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if i > 0 && parts[i-1] == "api" && strings.HasPrefix(part, "v") {
+			if i+1 < len(parts) {
+				return str.S(parts[i+1]).Truncate(50)
+			}
+			return ""
+		}
+	}
+	return ""
+}
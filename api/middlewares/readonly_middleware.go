@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// 只读模式下仍允许的写接口前缀（登录、登出、刷新令牌）
+var readOnlyAllowPathPrefixes = []string{
+	"/api/v1/auth/",
+}
+
+// 只读模式下仍允许的写接口，按方法与路径精确匹配（只读模式切换接口本身以及 pprof 符号查询）
+var readOnlyAllowRoutes = map[string]string{
+	"/api/v1/maintenance/read-only": http.MethodPut,
+	"/api/v1/debug/pprof/symbol":    http.MethodPost,
+}
+
+// ReadOnlyMiddleware 只读模式中间件，开启时拒绝所有写请求
+type ReadOnlyMiddleware struct {
+	handler  lib.HttpHandler
+	logger   lib.Logger
+	readOnly lib.ReadOnly
+}
+
+// NewReadOnlyMiddleware creates new read-only middleware
+func NewReadOnlyMiddleware(handler lib.HttpHandler, logger lib.Logger, readOnly lib.ReadOnly) ReadOnlyMiddleware {
+	return ReadOnlyMiddleware{
+		handler:  handler,
+		logger:   logger,
+		readOnly: readOnly,
+	}
+}
+
+func (a ReadOnlyMiddleware) Setup() {
+	a.handler.Engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !a.readOnly.IsEnabled() {
+				return next(ctx)
+			}
+
+			request := ctx.Request()
+			switch request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(ctx)
+			}
+
+			if isIgnorePath(request.URL.Path, readOnlyAllowPathPrefixes...) ||
+				readOnlyAllowRoutes[request.URL.Path] == request.Method {
+				return next(ctx)
+			}
+
+			err := errors.SystemReadOnly
+			if reason := a.readOnly.Status().Reason; reason != "" {
+				err = errors.WithMessage(err, reason)
+			}
+
+			ctx.Response().Header().Set("Retry-After", "60")
+			return echox.Response{Code: http.StatusServiceUnavailable, Message: err}.JSON(ctx)
+		}
+	})
+}
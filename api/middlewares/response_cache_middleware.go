@@ -0,0 +1,167 @@
+package middlewares
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// CacheScope 响应缓存键的区分范围
+type CacheScope int
+
+const (
+	CacheScopeGlobal CacheScope = iota // 所有用户共享
+	CacheScopeRole                     // 相同角色组合的用户共享
+	CacheScopeUser                     // 按用户区分
+)
+
+// cachedResponse 缓存的响应内容
+type cachedResponse struct {
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// cacheBodyWriter 在写出响应的同时记录响应内容
+type cacheBodyWriter struct {
+	http.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w cacheBodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// ResponseCacheMiddleware 路由级响应缓存中间件
+// 用于字典、菜单、用户下拉选项等读多写少的接口，由对应服务在数据变更时按分组失效
+type ResponseCacheMiddleware struct {
+	logger            lib.Logger
+	responseCache     lib.ResponseCache
+	permissionService service.PermissionService
+	userService       service.UserService
+}
+
+// NewResponseCacheMiddleware creates new response cache middleware
+func NewResponseCacheMiddleware(
+	logger lib.Logger,
+	responseCache lib.ResponseCache,
+	permissionService service.PermissionService,
+	userService service.UserService,
+) ResponseCacheMiddleware {
+	return ResponseCacheMiddleware{
+		logger:            logger,
+		responseCache:     responseCache,
+		permissionService: permissionService,
+		userService:       userService,
+	}
+}
+
+// Cache 返回指定分组的响应缓存中间件
+func (a ResponseCacheMiddleware) Cache(group string, scope CacheScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if !a.responseCache.IsEnabled() || ctx.Request().Method != http.MethodGet {
+				return next(ctx)
+			}
+
+			scopeKey, err := a.scopeKey(ctx, scope)
+			if err != nil {
+				a.logger.Zap.Warnf("Failed to derive response cache key: %v", err)
+				return next(ctx)
+			}
+
+			ttl := a.responseCache.TTL(group)
+			cacheKey := a.responseCache.Key(group, scopeKey+":"+ctx.Request().URL.RequestURI())
+
+			header := ctx.Response().Header()
+			header.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
+
+			cached := new(cachedResponse)
+			if err := a.responseCache.Get(cacheKey, cached); err == nil {
+				header.Set("X-Cache", "HIT")
+				return ctx.Blob(http.StatusOK, cached.ContentType, cached.Body)
+			}
+
+			header.Set("X-Cache", "MISS")
+
+			body := new(bytes.Buffer)
+			writer := ctx.Response().Writer
+			ctx.Response().Writer = cacheBodyWriter{ResponseWriter: writer, body: body}
+			defer func() { ctx.Response().Writer = writer }()
+
+			if err := next(ctx); err != nil {
+				return err
+			}
+
+			if ctx.Response().Status != http.StatusOK {
+				return nil
+			}
+
+			if err := a.responseCache.Set(group, cacheKey, &cachedResponse{
+				ContentType: header.Get(echo.HeaderContentType),
+				Body:        body.Bytes(),
+			}); err != nil {
+				a.logger.Zap.Warnf("Failed to cache response %s: %v", cacheKey, err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// scopeKey 根据缓存范围生成键前缀
+func (a ResponseCacheMiddleware) scopeKey(ctx echo.Context, scope CacheScope) (string, error) {
+	if scope == CacheScopeGlobal {
+		return "all", nil
+	}
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || claims == nil {
+		return "anonymous", nil
+	}
+
+	// API Key 按授权范围裁剪结果，不能与所属用户或同角色用户共享缓存
+	if claims.ApiKeyID != 0 {
+		return "apikey:" + strconv.FormatUint(claims.ApiKeyID, 10), nil
+	}
+
+	if claims.ServiceAccount {
+		codes := append([]string(nil), claims.Roles...)
+		sort.Strings(codes)
+		return "sa:" + strings.Join(codes, ","), nil
+	}
+
+	if scope == CacheScopeUser {
+		return "user:" + strconv.FormatUint(claims.ID, 10), nil
+	}
+
+	if a.userService.IsSuperAdminClaims(claims) {
+		return "role:root", nil
+	}
+
+	roleIDs, err := a.permissionService.GetUserRoleIDs(claims.ID)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]uint64, len(roleIDs))
+	copy(ids, roleIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(id, 10)
+	}
+
+	return "role:" + strings.Join(parts, ","), nil
+}
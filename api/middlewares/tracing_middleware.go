@@ -0,0 +1,107 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// traceIDHeader 响应头中返回的 trace ID，便于按请求检索链路
+const traceIDHeader = "X-Trace-Id"
+
+// TracingMiddleware OpenTelemetry 请求链路追踪中间件
+// 从请求头（traceparent）继承上游的链路，每个请求创建一个 span 并放入请求的 context，
+// 服务中使用 ctx.Request().Context() 提交的队列任务、下载器调用会成为它的子 span
+type TracingMiddleware struct {
+	handler   lib.HttpHandler
+	logger    lib.Logger
+	telemetry lib.Telemetry
+}
+
+// NewTracingMiddleware creates new tracing middleware
+func NewTracingMiddleware(
+	handler lib.HttpHandler,
+	logger lib.Logger,
+	telemetry lib.Telemetry,
+) TracingMiddleware {
+	return TracingMiddleware{
+		handler:   handler,
+		logger:    logger,
+		telemetry: telemetry,
+	}
+}
+
+// Setup sets up tracing middleware
+func (m TracingMiddleware) Setup() {
+	if !m.telemetry.IsEnabled() {
+		return
+	}
+
+	m.logger.Zap.Info("Setting up tracing middleware")
+	m.handler.Engine.Use(m.Handle())
+}
+
+// Handle 为请求创建 span
+func (m TracingMiddleware) Handle() echo.MiddlewareFunc {
+	tracer := m.telemetry.Tracer("github.com/top-system/light-admin/api")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			route := ctx.Path()
+			if route == "" {
+				route = metricsUnmatchedRoute
+			}
+
+			parent := otel.GetTextMapPropagator().Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+			spanCtx, span := tracer.Start(parent, request.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", request.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", request.URL.Path),
+					attribute.String("client.address", ctx.RealIP()),
+				),
+			)
+			defer span.End()
+
+			ctx.SetRequest(request.WithContext(spanCtx))
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				ctx.Response().Header().Set(traceIDHeader, sc.TraceID().String())
+			}
+
+			err := next(ctx)
+
+			status := ctx.Response().Status
+			if err != nil && !ctx.Response().Committed {
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims != nil {
+				span.SetAttributes(attribute.Int64("enduser.id", int64(claims.ID)))
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			return err
+		}
+	}
+}
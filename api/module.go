@@ -19,20 +19,36 @@ var Module = fx.Options(
 	fx.Provide(NewRoutes),
 )
 
+// RouteGroup 自定义路由的 fx 分组名，嵌入使用时通过 server.WithRoutes 注册
+const RouteGroup = `group:"routes"`
+
+// Route 自定义路由
+type Route interface {
+	Setup()
+}
+
 // Routes 聚合所有模块的路由
 type Routes struct {
 	System   systemRoute.Routes
 	Platform platformRoute.Routes
+	Custom   []Route
+}
+
+// RoutesParams 路由依赖，Custom 为嵌入方注册的自定义路由，可以为空
+type RoutesParams struct {
+	fx.In
+
+	System   systemRoute.Routes
+	Platform platformRoute.Routes
+	Custom   []Route `group:"routes"`
 }
 
 // NewRoutes creates aggregated routes
-func NewRoutes(
-	system systemRoute.Routes,
-	platform platformRoute.Routes,
-) Routes {
+func NewRoutes(params RoutesParams) Routes {
 	return Routes{
-		System:   system,
-		Platform: platform,
+		System:   params.System,
+		Platform: params.Platform,
+		Custom:   params.Custom,
 	}
 }
 
@@ -40,4 +56,7 @@ func NewRoutes(
 func (r Routes) Setup() {
 	r.System.Setup()
 	r.Platform.Setup()
+	for _, route := range r.Custom {
+		route.Setup()
+	}
 }
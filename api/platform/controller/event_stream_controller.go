@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	apperrors "github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/pkg/echox"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+// eventStreamHeartbeat SSE 心跳间隔，防止代理因空闲断开连接
+const eventStreamHeartbeat = 25 * time.Second
+
+// EventStreamController Server-Sent Events 推送，作为 WebSocket 被代理拦截时的降级方案
+// 与 STOMP 会话接收相同的主题与用户队列消息，目标的权限校验与 SUBSCRIBE 一致
+type EventStreamController struct {
+	ws          *ws.WebSocket
+	logger      lib.Logger
+	authService service.AuthService
+}
+
+// NewEventStreamController 创建SSE推送控制器
+func NewEventStreamController(
+	websocket *ws.WebSocket,
+	logger lib.Logger,
+	authService service.AuthService,
+) EventStreamController {
+	return EventStreamController{
+		ws:          websocket,
+		logger:      logger,
+		authService: authService,
+	}
+}
+
+// Stream 建立SSE推送
+// 令牌从 token 参数或 Authorization 头获取；destinations 为逗号分隔的目标，可以包含通配符，
+// 用户队列使用 /user/queue/*，不传时接收通知、字典变更与下载进度。
+// 事件名为消息的目标，data 为消息体，没有权限的目标会被忽略
+// @tags WebSocket
+// @summary Server-Sent Events stream
+// @produce text/event-stream
+// @param token query string false "Access token"
+// @param destinations query string false "Destinations, comma separated"
+// @success 200 {string} string "event stream"
+// @failure 401 {object} echox.Response "unauthorized"
+// @failure 403 {object} echox.Response "forbidden"
+// @router /api/v1/events/stream [get]
+func (c EventStreamController) Stream(ctx echo.Context) error {
+	token := ctx.QueryParam("token")
+	if token == "" {
+		auth := ctx.Request().Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Bearer ") {
+			token = auth[len("Bearer "):]
+		}
+	}
+	claims, err := c.authService.ParseToken(token)
+	if err != nil {
+		return echox.Response{Code: http.StatusUnauthorized, Message: err}.JSON(ctx)
+	}
+
+	destinations := ws.DefaultStreamDestinations
+	if v := ctx.QueryParam("destinations"); v != "" {
+		destinations = nil
+		for _, dest := range strings.Split(v, ",") {
+			if dest = strings.TrimSpace(dest); dest != "" {
+				destinations = append(destinations, dest)
+			}
+		}
+	}
+
+	stream := stomp.NewStream(uuid.New().String(), claims.Username, append([]string{}, destinations...))
+	stream.RemoteIP = ctx.RealIP()
+	stream.ConnectTime = time.Now().UnixMilli()
+	c.ws.Broker.AuthorizeStream(stream)
+	if len(stream.Destinations) == 0 {
+		return echox.Response{Code: http.StatusForbidden, Message: apperrors.EventStreamForbidden}.JSON(ctx)
+	}
+
+	// 服务端的写超时会中断长连接，SSE 响应不设置写超时
+	res := ctx.Response()
+	if err := http.NewResponseController(res).SetWriteDeadline(time.Time{}); err != nil {
+		c.logger.Zap.Warnf("Failed to clear write deadline for event stream: %v", err)
+	}
+
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// 关闭 nginx 的响应缓冲
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprintf(res, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	res.Flush()
+
+	c.ws.Broker.AddStream(stream)
+	defer c.ws.Broker.RemoveStream(stream.ID)
+
+	c.logger.Zap.Infof("Event stream connected: user=%s, stream=%s", stream.Username, stream.ID)
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	var eventID uint64
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			c.logger.Zap.Infof("Event stream disconnected: user=%s, stream=%s", stream.Username, stream.ID)
+			return nil
+		case <-stream.Done():
+			// 服务端关闭（用户被禁用、模块停用），告知客户端原因后结束
+			if reason := stream.CloseReason(); reason != "" {
+				writeEvent(res, 0, "error", []byte(reason))
+			}
+			c.logger.Zap.Infof("Event stream closed by server: user=%s, stream=%s", stream.Username, stream.ID)
+			return nil
+		case msg := <-stream.Messages():
+			eventID++
+			if err := writeEvent(res, eventID, msg.Destination, msg.Body); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeEvent 写入一条 SSE 事件，多行消息体拆分为多个 data 字段
+func writeEvent(res *echo.Response, id uint64, event string, data []byte) error {
+	var buf strings.Builder
+	if id > 0 {
+		fmt.Fprintf(&buf, "id: %d\n", id)
+	}
+	fmt.Fprintf(&buf, "event: %s\n", event)
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+
+	if _, err := res.Write([]byte(buf.String())); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
@@ -1,31 +1,77 @@
 package controller
 
 import (
+	"mime"
 	"net/http"
+	"path"
 
 	"github.com/top-system/light-admin/api/platform/service"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
 	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/file"
+	"github.com/top-system/light-admin/pkg/str"
 	"github.com/labstack/echo/v4"
 )
 
 // FileController 文件控制器
 type FileController struct {
-	fileService service.FileService
-	logger      lib.Logger
+	fileService        service.FileService
+	fileCleanupService service.FileCleanupService
+	logger             lib.Logger
 }
 
 // NewFileController 创建文件控制器
 func NewFileController(
 	fileService service.FileService,
+	fileCleanupService service.FileCleanupService,
 	logger lib.Logger,
 ) FileController {
 	return FileController{
-		fileService: fileService,
-		logger:      logger,
+		fileService:        fileService,
+		fileCleanupService: fileCleanupService,
+		logger:             logger,
 	}
 }
 
+// Query 文件记录分页列表
+// @tags File
+// @summary File List
+// @produce application/json
+// @param keywords query string false "Keywords of file url"
+// @param contentType query string false "Content type prefix"
+// @param tagIds query string false "Tag IDs, comma separated"
+// @param pageNum query int false "Page number"
+// @param pageSize query int false "Page size"
+// @success 200 {object} echox.Response{data=[]platform.FileObject} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/files [get]
+func (c FileController) Query(ctx echo.Context) error {
+	param := new(platform.FileObjectQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	if v := ctx.QueryParam("tagIds"); v != "" {
+		param.TagIDs = str.S(v).Uint64s(",")
+	}
+
+	qr, err := c.fileService.QueryFiles(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
 // Upload 文件上传
 // @tags File
 // @summary Upload File
@@ -60,6 +106,87 @@ func (c FileController) Upload(ctx echo.Context) error {
 	return echox.Response{Code: http.StatusOK, Data: fileInfo}.JSON(ctx)
 }
 
+// Download 文件下载，需要 sys:file:query 权限，其他用户通过上传时返回的签名下载地址下载
+// 由服务端代理读取存储后端的文件，支持 Range 断点续传，前端无需直接访问存储桶
+// @tags File
+// @summary Download File
+// @produce application/octet-stream
+// @param filePath query string true "File path"
+// @param name query string false "Download file name"
+// @param inline query bool false "Display inline instead of attachment"
+// @success 200 {file} file "ok"
+// @success 206 {file} file "partial content"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/files/download [get]
+func (c FileController) Download(ctx echo.Context) error {
+	filePath := ctx.QueryParam("filePath")
+	if filePath == "" {
+		return echox.Response{Code: http.StatusBadRequest, Message: "filePath is required"}.JSON(ctx)
+	}
+
+	object, err := c.fileService.OpenFile(filePath)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	return c.serve(ctx, filePath, object)
+}
+
+// SignedDownload 通过签名下载地址下载文件，无需登录，签名过期后返回 403
+// @tags File
+// @summary Download File By Signed URL
+// @produce application/octet-stream
+// @param filePath query string true "File path"
+// @param expires query int true "Expire timestamp"
+// @param sign query string true "Signature"
+// @param name query string false "Download file name"
+// @param inline query bool false "Display inline instead of attachment"
+// @success 200 {file} file "ok"
+// @success 206 {file} file "partial content"
+// @failure 403 {object} echox.Response "invalid signature"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/files/signed [get]
+func (c FileController) SignedDownload(ctx echo.Context) error {
+	param := new(platform.FileSignedDownloadParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	object, err := c.fileService.OpenSigned(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	return c.serve(ctx, param.FilePath, object)
+}
+
+// serve 输出存储文件，处理 Range、If-Range、If-Modified-Since 等条件请求
+func (c FileController) serve(ctx echo.Context, filePath string, object *service.StoredObject) error {
+	defer object.Reader.Close()
+
+	name := ctx.QueryParam("name")
+	if name == "" {
+		name = path.Base(filePath)
+	}
+	name = file.NormalizeFilename(name)
+
+	disposition := "attachment"
+	if ctx.QueryParam("inline") == "true" {
+		disposition = "inline"
+	}
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	if object.ContentType != "" {
+		header.Set(echo.HeaderContentType, object.ContentType)
+	}
+
+	http.ServeContent(ctx.Response(), ctx.Request(), name, object.ModTime, object.Reader)
+	return nil
+}
+
 // Delete 文件删除
 // @tags File
 // @summary Delete File
@@ -82,3 +209,35 @@ func (c FileController) Delete(ctx echo.Context) error {
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
+
+// OrphanReport 孤立文件扫描报告（只读，不做删除）
+// @tags File
+// @summary Orphan File Report
+// @produce application/json
+// @success 200 {object} echox.Response{data=platform.OrphanReport} "ok"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/files/orphans [get]
+func (c FileController) OrphanReport(ctx echo.Context) error {
+	report, err := c.fileCleanupService.Run(true)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: report}.JSON(ctx)
+}
+
+// CleanupOrphans 清理孤立文件和悬空记录
+// @tags File
+// @summary Cleanup Orphan Files
+// @produce application/json
+// @success 200 {object} echox.Response{data=platform.OrphanReport} "ok"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/files/orphans/cleanup [post]
+func (c FileController) CleanupOrphans(ctx echo.Context) error {
+	report, err := c.fileCleanupService.Run(false)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: report}.JSON(ctx)
+}
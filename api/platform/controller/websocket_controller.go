@@ -2,16 +2,22 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 
+	platformservice "github.com/top-system/light-admin/api/platform/service"
 	"github.com/top-system/light-admin/api/system/service"
 	"github.com/top-system/light-admin/constants"
+	apperrors "github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/pkg/echox"
 	ws "github.com/top-system/light-admin/pkg/websocket"
 	"github.com/top-system/light-admin/pkg/websocket/stomp"
@@ -29,9 +35,16 @@ var upgrader = websocket.Upgrader{
 
 // WebSocketController WebSocket控制器
 type WebSocketController struct {
-	ws          *ws.WebSocket
-	logger      lib.Logger
-	authService service.AuthService
+	ws                *ws.WebSocket
+	logger            lib.Logger
+	authService       service.AuthService
+	userService       service.UserService
+	permissionService service.PermissionService
+	featureModules    lib.FeatureModules
+	// 任务进度推送随 WebSocket 控制器一起创建
+	taskProgressService service.TaskProgressService
+	// 离线消息存储在启用时设置到消息代理
+	offlineMessageService platformservice.OfflineMessageService
 }
 
 // NewWebSocketController 创建WebSocket控制器
@@ -39,11 +52,21 @@ func NewWebSocketController(
 	websocket *ws.WebSocket,
 	logger lib.Logger,
 	authService service.AuthService,
+	userService service.UserService,
+	permissionService service.PermissionService,
+	featureModules lib.FeatureModules,
+	taskProgressService service.TaskProgressService,
+	offlineMessageService platformservice.OfflineMessageService,
 ) WebSocketController {
 	ctrl := WebSocketController{
-		ws:          websocket,
-		logger:      logger,
-		authService: authService,
+		ws:                    websocket,
+		logger:                logger,
+		authService:           authService,
+		userService:           userService,
+		permissionService:     permissionService,
+		featureModules:        featureModules,
+		taskProgressService:   taskProgressService,
+		offlineMessageService: offlineMessageService,
 	}
 
 	// 设置 Token 验证器 (用于 STOMP CONNECT 认证)
@@ -55,6 +78,9 @@ func NewWebSocketController(
 		return claims.Username, nil
 	})
 
+	// 按目标注册表声明的权限校验 SUBSCRIBE / SEND
+	ctrl.ws.Broker.SetAuthorizer(ctrl.authorizeDestination)
+
 	// 注册消息处理器 (对应 Java @MessageMapping)
 	ctrl.registerHandlers()
 
@@ -80,10 +106,7 @@ func (c WebSocketController) registerHandlers() {
 
 	// @MessageMapping("/sendToUser/{username}")
 	c.ws.RegisterHandler(ws.AppSendToUser, func(session *stomp.Session, destination string, body []byte) {
-		var req struct {
-			Username string `json:"username"`
-			Message  string `json:"message"`
-		}
+		var req ws.SendToUserRequest
 		if err := json.Unmarshal(body, &req); err != nil {
 			c.logger.Zap.Errorf("Failed to unmarshal message: %v", err)
 			return
@@ -97,8 +120,46 @@ func (c WebSocketController) registerHandlers() {
 	})
 }
 
+// authorizeDestination 校验会话用户是否具备目标所需权限
+// 未注册或未声明权限的目标不做限制；通配符订阅需要具备全部匹配目标的权限
+func (c WebSocketController) authorizeDestination(session *stomp.Session, destination string) error {
+	var required []string
+	for _, topic := range c.ws.Catalog.Match(destination) {
+		if topic.Permission != "" {
+			required = append(required, topic.Permission)
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	if c.userService.IsSuperAdmin(session.Username) {
+		return nil
+	}
+
+	user, err := c.userService.GetByUsername(session.Username)
+	if err != nil {
+		return err
+	}
+	perms, err := c.permissionService.GetUserPerms(user.ID)
+	if err != nil {
+		return err
+	}
+	for _, perm := range required {
+		if !service.MatchPerm(perms, perm) {
+			return errors.New("missing permission " + perm)
+		}
+	}
+	return nil
+}
+
 // HandleWebSocket 处理原始 HTTP WebSocket 请求（绕过 Echo 中间件）
 func (c WebSocketController) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// 该请求不经过 Echo 中间件，在此检查模块是否已禁用
+	if !c.featureModules.IsEnabled(lib.FeatureModuleWebSocket) {
+		http.NotFound(w, r)
+		return
+	}
+
 	c.logger.Zap.Infof("WebSocket upgrade request from: %s", r.RemoteAddr)
 
 	// HTTP 层 JWT 校验：从 query 参数或 Authorization header 获取 token
@@ -146,6 +207,7 @@ func (c WebSocketController) HandleWebSocket(w http.ResponseWriter, r *http.Requ
 		Username:      preAuthUsername,
 		Conn:          conn,
 		Subscriptions: make(map[string]string),
+		RemoteIP:      remoteIP(r),
 		ConnectTime:   time.Now().UnixMilli(),
 		Authenticated: preAuthUsername != "",
 	}
@@ -188,6 +250,7 @@ func (c WebSocketController) Connect(ctx echo.Context) error {
 		Username:      "", // 认证成功后会设置
 		Conn:          conn,
 		Subscriptions: make(map[string]string),
+		RemoteIP:      ctx.RealIP(),
 		ConnectTime:   time.Now().UnixMilli(),
 		Authenticated: false,
 	}
@@ -204,6 +267,22 @@ func (c WebSocketController) Connect(ctx echo.Context) error {
 	return nil
 }
 
+// remoteIP 客户端地址，优先使用反向代理设置的 X-Forwarded-For / X-Real-IP，与 echo 的 RealIP 一致
+func remoteIP(r *http.Request) string {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		ip, _, _ := strings.Cut(xff, ",")
+		return strings.TrimSpace(ip)
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // handleMessages 处理WebSocket消息
 func (c WebSocketController) handleMessages(session *stomp.Session) {
 	defer func() {
@@ -349,3 +428,67 @@ func (c WebSocketController) BroadcastDictChange(ctx echo.Context) error {
 
 	return echox.Response{Code: http.StatusOK, Message: "Dict change notification sent"}.JSON(ctx)
 }
+
+// GetTopics 获取可订阅的目标列表 (HTTP API)
+// 只返回当前用户有权限访问的目标
+// @tags WebSocket
+// @summary List websocket topics
+// @produce json
+// @success 200 {object} echox.Response{data=[]ws.Topic} "ok"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/websocket/topics [get]
+func (c WebSocketController) GetTopics(ctx echo.Context) error {
+	topics := c.ws.Catalog.List()
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || c.userService.IsSuperAdminClaims(claims) {
+		return echox.Response{Code: http.StatusOK, Data: topics}.JSON(ctx)
+	}
+
+	perms, err := c.permissionService.GetClaimsPerms(claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	visible := make([]ws.Topic, 0, len(topics))
+	for _, topic := range topics {
+		if topic.Permission == "" || service.MatchPerm(perms, topic.Permission) {
+			visible = append(visible, topic)
+		}
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: visible}.JSON(ctx)
+}
+
+// GetSessions 获取本实例的全部会话 (HTTP API)
+// @tags WebSocket
+// @summary List websocket sessions
+// @produce json
+// @success 200 {object} echox.Response{data=[]stomp.SessionInfo} "ok"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/websocket/sessions [get]
+func (c WebSocketController) GetSessions(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: c.ws.Broker.GetSessions()}.JSON(ctx)
+}
+
+// CloseSession 强制断开会话 (HTTP API)
+// 断开前发送 ERROR 帧告知客户端原因，用户令牌仍有效时客户端可以重新连接
+// @tags WebSocket
+// @summary Force disconnect a websocket session
+// @produce json
+// @param id path string true "session id"
+// @success 200 {object} echox.Response "ok"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/websocket/sessions/{id} [delete]
+func (c WebSocketController) CloseSession(ctx echo.Context) error {
+	operator := "administrator"
+	if claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); ok {
+		operator = claims.Username
+	}
+
+	if !c.ws.Broker.CloseSession(ctx.Param("id"), "Session closed by "+operator) {
+		return echox.Response{Code: http.StatusNotFound, Message: apperrors.WebSocketSessionNotFound}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
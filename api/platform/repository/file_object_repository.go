@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	systemrepository "github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// FileObjectRepository 文件存储对象仓库
+type FileObjectRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewFileObjectRepository creates a new file object repository
+func NewFileObjectRepository(db lib.Database, logger lib.Logger) FileObjectRepository {
+	return FileObjectRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a FileObjectRepository) WithTrx(trxHandle *gorm.DB) FileObjectRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// first 查询单条记录，不存在时返回 nil
+func (a FileObjectRepository) first(db *gorm.DB) (*platform.FileObject, error) {
+	object := new(platform.FileObject)
+	if err := db.First(object).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return object, nil
+}
+
+// Get 根据 ID 查询
+func (a FileObjectRepository) Get(id uint64) (*platform.FileObject, error) {
+	return a.first(a.db.ORM.Model(&platform.FileObject{}).Where("id = ?", id))
+}
+
+// Query 分页查询文件记录
+func (a FileObjectRepository) Query(param *platform.FileObjectQueryParam) (*platform.FileObjectQueryResult, error) {
+	db := a.db.ORM.Model(&platform.FileObject{})
+
+	if v := param.Keywords; v != "" {
+		db = db.Where("url LIKE ?", "%"+v+"%")
+	}
+
+	if v := param.ContentType; v != "" {
+		db = db.Where("content_type LIKE ?", v+"%")
+	}
+
+	if v := param.TagIDs; len(v) > 0 {
+		db = db.Where("id IN (?)", systemrepository.TaggedResourceIDs(db, system.TagResourceFile, v))
+	}
+
+	db = db.Order("id DESC")
+
+	list := make(platform.FileObjects, 0)
+	pagination, err := systemrepository.QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &platform.FileObjectQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetByHash 根据内容哈希查询
+func (a FileObjectRepository) GetByHash(hash string) (*platform.FileObject, error) {
+	return a.first(a.db.ORM.Model(&platform.FileObject{}).Where("hash = ?", hash))
+}
+
+// GetByURL 根据访问地址查询
+func (a FileObjectRepository) GetByURL(url string) (*platform.FileObject, error) {
+	return a.first(a.db.ORM.Model(&platform.FileObject{}).Where("url = ?", url))
+}
+
+func (a FileObjectRepository) Create(object *platform.FileObject) error {
+	if err := a.db.ORM.Create(object).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// IncrRef 引用计数加一，记录已被删除（计数归零）时返回 false
+func (a FileObjectRepository) IncrRef(id uint64) (bool, error) {
+	result := a.db.ORM.Model(&platform.FileObject{}).Where("id = ? AND ref_count > 0", id).
+		Update("ref_count", gorm.Expr("ref_count + ?", 1))
+	if err := result.Error; err != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Release 引用计数减一，计数归零时调用 deleteObject 删除存储文件并删除记录
+// 在同一事务中锁定记录完成，期间并发的 IncrRef 等待事务结束，不会复用已删除的存储文件；
+// deleteObject 失败时回滚，引用计数不变
+func (a FileObjectRepository) Release(id uint64, deleteObject func(url string) error) (bool, error) {
+	deleted := false
+	err := a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		object := new(platform.FileObject)
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Limit(1).Find(object)
+		if err := result.Error; err != nil {
+			return errors.Wrap(errors.DatabaseInternalError, err.Error())
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if object.RefCount > 1 {
+			if err := tx.Model(object).Update("ref_count", object.RefCount-1).Error; err != nil {
+				return errors.Wrap(errors.DatabaseInternalError, err.Error())
+			}
+			return nil
+		}
+
+		if err := tx.Delete(object).Error; err != nil {
+			return errors.Wrap(errors.DatabaseInternalError, err.Error())
+		}
+		if err := deleteObject(object.URL); err != nil {
+			return err
+		}
+
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// List 获取全部文件记录
+func (a FileObjectRepository) List() (platform.FileObjects, error) {
+	list := make(platform.FileObjects, 0)
+	if err := a.db.ORM.Model(&platform.FileObject{}).Order("id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// DeleteByIDs 强制删除文件记录（不校验引用计数）
+func (a FileObjectRepository) DeleteByIDs(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Where("id IN ?", ids).Delete(&platform.FileObject{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
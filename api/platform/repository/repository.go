@@ -4,5 +4,6 @@ import "go.uber.org/fx"
 
 // Module exports dependency
 var Module = fx.Options(
-	// fx.Provide(NewXxxRepository),
+	fx.Provide(NewFileObjectRepository),
+	fx.Provide(NewStompMessageRepository),
 )
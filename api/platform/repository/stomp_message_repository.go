@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+)
+
+// StompMessageRepository 离线 STOMP 消息仓库
+type StompMessageRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewStompMessageRepository creates a new stomp message repository
+func NewStompMessageRepository(db lib.Database, logger lib.Logger) StompMessageRepository {
+	return StompMessageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a StompMessageRepository) WithTrx(trxHandle *gorm.DB) StompMessageRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Create 保存消息
+func (a StompMessageRepository) Create(msg *platform.StompMessage) error {
+	if err := a.db.ORM.Create(msg).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// ListByUsername 用户在 since 之后保存的消息，按保存顺序排列
+func (a StompMessageRepository) ListByUsername(username string, since time.Time) (platform.StompMessages, error) {
+	list := make(platform.StompMessages, 0)
+	db := a.db.ORM.Model(&platform.StompMessage{}).
+		Where("username = ? AND create_time >= ?", username, since).Order("id")
+	if err := db.Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// DeleteByIDs 删除消息，返回删除的行数
+func (a StompMessageRepository) DeleteByIDs(ids []uint64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := a.db.ORM.Where("id IN ?", ids).Delete(&platform.StompMessage{})
+	if err := result.Error; err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return result.RowsAffected, nil
+}
+
+// Trim 只保留用户最新的 keep 条消息
+func (a StompMessageRepository) Trim(username string, keep int) error {
+	var ids []uint64
+	err := a.db.ORM.Model(&platform.StompMessage{}).Where("username = ?", username).
+		Order("id DESC").Offset(keep-1).Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Where("username = ? AND id < ?", username, ids[0]).
+		Delete(&platform.StompMessage{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteBefore 删除 before 之前保存的消息
+func (a StompMessageRepository) DeleteBefore(before time.Time) error {
+	if err := a.db.ORM.Where("create_time < ?", before).Delete(&platform.StompMessage{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
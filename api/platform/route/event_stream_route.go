@@ -0,0 +1,36 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/platform/controller"
+	"github.com/top-system/light-admin/lib"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+// EventStreamRoute SSE推送路由
+type EventStreamRoute struct {
+	logger                lib.Logger
+	handler               lib.HttpHandler
+	eventStreamController controller.EventStreamController
+	permMiddleware        middlewares.PermissionMiddleware
+}
+
+// NewEventStreamRoute 创建SSE推送路由
+func NewEventStreamRoute(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	eventStreamController controller.EventStreamController,
+	permMiddleware middlewares.PermissionMiddleware,
+) EventStreamRoute {
+	return EventStreamRoute{
+		logger:                logger,
+		handler:               handler,
+		eventStreamController: eventStreamController,
+		permMiddleware:        permMiddleware,
+	}
+}
+
+// Setup 设置SSE推送路由，接口自身校验令牌，EventSource 可以通过 token 参数传递
+func (r EventStreamRoute) Setup() {
+	r.permMiddleware.Group(r.handler.Engine.Group("")).Public().GET(ws.EventStreamPath, r.eventStreamController.Stream, "")
+}
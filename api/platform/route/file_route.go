@@ -1,6 +1,7 @@
 package route
 
 import (
+	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/api/platform/controller"
 	"github.com/top-system/light-admin/lib"
 )
@@ -10,6 +11,7 @@ type FileRoute struct {
 	logger         lib.Logger
 	handler        lib.HttpHandler
 	fileController controller.FileController
+	permMiddleware middlewares.PermissionMiddleware
 }
 
 // NewFileRoute 创建文件路由
@@ -17,19 +19,28 @@ func NewFileRoute(
 	logger lib.Logger,
 	handler lib.HttpHandler,
 	fileController controller.FileController,
+	permMiddleware middlewares.PermissionMiddleware,
 ) FileRoute {
 	return FileRoute{
 		logger:         logger,
 		handler:        handler,
 		fileController: fileController,
+		permMiddleware: permMiddleware,
 	}
 }
 
 // Setup 设置文件路由
 func (r FileRoute) Setup() {
-	api := r.handler.RouterV1.Group("/files")
+	api := r.permMiddleware.Group(r.handler.RouterV1.Group("/files"))
 	{
-		api.POST("", r.fileController.Upload)
-		api.DELETE("", r.fileController.Delete)
+		api.GET("", r.fileController.Query, "sys:file:query")
+		api.POST("", r.fileController.Upload, "")
+		api.GET("/download", r.fileController.Download, "sys:file:query") // 支持 Range
+		api.Public().GET("/signed", r.fileController.SignedDownload, "")  // 签名下载地址，无需登录
+		api.DELETE("", r.fileController.Delete, "")
+
+		// 孤立文件扫描与清理
+		api.GET("/orphans", r.fileController.OrphanReport, "sys:file:cleanup")
+		api.POST("/orphans/cleanup", r.fileController.CleanupOrphans, "sys:file:cleanup")
 	}
 }
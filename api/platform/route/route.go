@@ -6,6 +6,7 @@ import "go.uber.org/fx"
 var Module = fx.Options(
 	fx.Provide(NewFileRoute),
 	fx.Provide(NewWebSocketRoute),
+	fx.Provide(NewEventStreamRoute),
 	fx.Provide(NewRoutes),
 )
 
@@ -21,10 +22,12 @@ type Route interface {
 func NewRoutes(
 	fileRoute FileRoute,
 	websocketRoute WebSocketRoute,
+	eventStreamRoute EventStreamRoute,
 ) Routes {
 	return Routes{
 		fileRoute,
 		websocketRoute,
+		eventStreamRoute,
 	}
 }
 
@@ -1,6 +1,7 @@
 package route
 
 import (
+	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/api/platform/controller"
 	"github.com/top-system/light-admin/lib"
 )
@@ -10,6 +11,7 @@ type WebSocketRoute struct {
 	logger              lib.Logger
 	handler             lib.HttpHandler
 	websocketController controller.WebSocketController
+	permMiddleware      middlewares.PermissionMiddleware
 }
 
 // NewWebSocketRoute 创建WebSocket路由
@@ -17,11 +19,13 @@ func NewWebSocketRoute(
 	logger lib.Logger,
 	handler lib.HttpHandler,
 	websocketController controller.WebSocketController,
+	permMiddleware middlewares.PermissionMiddleware,
 ) WebSocketRoute {
 	return WebSocketRoute{
 		logger:              logger,
 		handler:             handler,
 		websocketController: websocketController,
+		permMiddleware:      permMiddleware,
 	}
 }
 
@@ -34,22 +38,32 @@ func (r WebSocketRoute) SetupWebSocket() {
 
 // Setup 设置WebSocket HTTP API路由
 func (r WebSocketRoute) Setup() {
-	// HTTP API 接口 (用于管理和测试)
-	api := r.handler.RouterV1.Group("/websocket")
+	// HTTP API 接口 (用于管理和测试)，登录即可访问，不向 API Key 开放
+	api := r.permMiddleware.Group(r.handler.RouterV1.Group("/websocket"))
 	{
 		// 广播发送消息
-		api.POST("/sendToAll", r.websocketController.SendToAll)
+		api.POST("/sendToAll", r.websocketController.SendToAll, "")
 
 		// 点对点发送消息
-		api.POST("/sendToUser", r.websocketController.SendToUser)
+		api.POST("/sendToUser", r.websocketController.SendToUser, "")
 
 		// 获取在线用户列表
-		api.GET("/online-users", r.websocketController.GetOnlineUsers)
+		api.GET("/online-users", r.websocketController.GetOnlineUsers, "")
 
 		// 获取在线用户数量
-		api.GET("/online-count", r.websocketController.GetOnlineCount)
+		api.GET("/online-count", r.websocketController.GetOnlineCount, "")
 
 		// 广播字典变更
-		api.POST("/dict-change", r.websocketController.BroadcastDictChange)
+		api.POST("/dict-change", r.websocketController.BroadcastDictChange, "")
+
+		// 获取可订阅的目标列表
+		api.GET("/topics", r.websocketController.GetTopics, "")
+	}
+
+	// 会话管理，只包含本实例上的会话
+	sessions := r.permMiddleware.Group(r.handler.RouterV1.Group("/websocket/sessions"))
+	{
+		sessions.GET("", r.websocketController.GetSessions, "sys:websocket:session")
+		sessions.DELETE("/:id", r.websocketController.CloseSession, "sys:websocket:session")
 	}
 }
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/file"
+)
+
+const (
+	fileCleanupTaskName      = "file_orphan_cleanup"
+	fileCleanupDefaultSpec   = "0 0 3 * * *"
+	fileCleanupDefaultMinAge = 24
+)
+
+// FileCleanupService 孤立文件清理服务
+// 对比文件记录与存储后端中的对象，找出没有登记记录的孤立对象以及存储已丢失的悬空记录
+type FileCleanupService struct {
+	logger               lib.Logger
+	config               *lib.OSSCleanupConfig
+	storage              FileStorage
+	featureModules       lib.FeatureModules
+	fileObjectRepository repository.FileObjectRepository
+}
+
+// NewFileCleanupService 创建孤立文件清理服务，启用时注册定时扫描任务
+func NewFileCleanupService(
+	logger lib.Logger,
+	config lib.Config,
+	cron lib.Crontab,
+	storage FileStorage,
+	featureModules lib.FeatureModules,
+	fileObjectRepository repository.FileObjectRepository,
+) FileCleanupService {
+	cfg := &lib.OSSCleanupConfig{}
+	if config.OSS != nil && config.OSS.Cleanup != nil {
+		cfg = config.OSS.Cleanup
+	}
+
+	svc := FileCleanupService{
+		logger:               logger,
+		config:               cfg,
+		storage:              storage,
+		featureModules:       featureModules,
+		fileObjectRepository: fileObjectRepository,
+	}
+
+	if cfg.Enable && cron.IsEnabled() {
+		spec := cfg.Spec
+		if spec == "" {
+			spec = fileCleanupDefaultSpec
+		}
+
+		if err := cron.AddTask(fileCleanupTaskName, spec, svc.runScheduled, crontab.WithSkipIfRunning()); err != nil {
+			logger.Zap.Errorf("Failed to register file cleanup task: %v", err)
+		}
+	}
+
+	return svc
+}
+
+// runScheduled 定时任务入口，未开启自动删除时只输出报告；文件管理模块禁用时跳过
+func (a FileCleanupService) runScheduled(ctx context.Context) {
+	if !a.featureModules.IsEnabled(lib.FeatureModuleFile) {
+		return
+	}
+
+	report, err := a.Run(!a.config.Delete)
+	if err != nil {
+		a.logger.Zap.Errorf("File cleanup failed: %v", err)
+		crontab.SetError(ctx, err)
+		return
+	}
+
+	a.logger.Zap.Infof("File cleanup finished: dryRun=%v, objects=%d, records=%d, orphans=%d, dangling=%d",
+		report.DryRun, report.ScannedObjects, report.ScannedRecords,
+		len(report.OrphanObjects), len(report.DanglingRecords))
+}
+
+// minAge 存储对象最小存在时长
+func (a FileCleanupService) minAge() time.Duration {
+	if a.config.MinAge > 0 {
+		return time.Duration(a.config.MinAge) * time.Hour
+	}
+
+	return fileCleanupDefaultMinAge * time.Hour
+}
+
+// Scan 扫描孤立对象和悬空记录（不做任何修改）
+// 只有按内容哈希分片存储的对象才会登记记录，去重功能上线前上传的文件（头像、公告附件、导出文件等）
+// 没有记录也不视为孤立对象
+func (a FileCleanupService) Scan() (*platform.OrphanReport, error) {
+	objects, err := a.storage.ListObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := a.fileObjectRepository.List()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &platform.OrphanReport{
+		ScannedObjects:  len(objects),
+		ScannedRecords:  len(records),
+		OrphanObjects:   make([]*platform.StoredEntry, 0),
+		DanglingRecords: make(platform.FileObjects, 0),
+		DryRun:          true,
+		ScanTime:        dto.DateTime(time.Now()),
+	}
+
+	recordURLs := make(map[string]struct{}, len(records))
+	for _, record := range records {
+		recordURLs[record.URL] = struct{}{}
+	}
+
+	objectURLs := make(map[string]struct{}, len(objects))
+	deadline := time.Now().Add(-a.minAge())
+	for _, object := range objects {
+		objectURLs[object.URL] = struct{}{}
+
+		if _, ok := recordURLs[object.URL]; ok || !file.IsShardPath(object.URL) {
+			continue
+		}
+
+		// 新上传的文件可能还未写入记录，跳过
+		if object.ModTime.Time().After(deadline) {
+			continue
+		}
+
+		report.OrphanObjects = append(report.OrphanObjects, object)
+	}
+
+	for _, record := range records {
+		if _, ok := objectURLs[record.URL]; !ok {
+			report.DanglingRecords = append(report.DanglingRecords, record)
+		}
+	}
+
+	return report, nil
+}
+
+// Run 扫描并清理，dryRun 为 true 时只返回报告
+func (a FileCleanupService) Run(dryRun bool) (*platform.OrphanReport, error) {
+	report, err := a.Scan()
+	if err != nil || dryRun {
+		return report, err
+	}
+
+	report.DryRun = false
+
+	for _, object := range report.OrphanObjects {
+		if err := a.storage.DeleteFile(object.URL); err != nil {
+			a.logger.Zap.Warnf("Failed to delete orphan object %s: %v", object.URL, err)
+		}
+	}
+
+	ids := make([]uint64, 0, len(report.DanglingRecords))
+	for _, record := range report.DanglingRecords {
+		ids = append(ids, record.ID)
+	}
+
+	if err := a.fileObjectRepository.DeleteByIDs(ids); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
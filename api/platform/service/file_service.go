@@ -2,28 +2,73 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/platform"
-	"github.com/google/uuid"
+	"github.com/top-system/light-admin/pkg/file"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
+// FileSignedDownloadPath 文件签名下载地址，由签名校验访问权限，无需登录
+const FileSignedDownloadPath = "/api/v1/files/signed"
+
+// fileURLDefaultExpire 签名下载地址默认有效期
+const fileURLDefaultExpire = 60 * time.Minute
+
 // FileService 文件服务接口
 type FileService interface {
 	UploadFile(filename string, reader io.Reader, size int64, contentType string) (*platform.FileInfo, error)
+	OpenFile(filePath string) (*StoredObject, error)
+	OpenSigned(param *platform.FileSignedDownloadParam) (*StoredObject, error)
+	SignedURL(filePath string) string
+	DeleteFile(filePath string) error
+	QueryFiles(param *platform.FileObjectQueryParam) (*platform.FileObjectQueryResult, error)
+}
+
+// FileStorage 文件存储后端接口
+// objectKey 为存储路径（如 20240101/ab/<sha256>.png），返回文件访问地址
+type FileStorage interface {
+	PutObject(objectKey string, reader io.Reader, size int64, contentType string) (string, error)
+	GetObject(filePath string) (*StoredObject, error)
+	ListObjects() ([]*platform.StoredEntry, error)
 	DeleteFile(filePath string) error
 }
 
-// NewFileService 根据配置创建对应的文件服务
-func NewFileService(config lib.Config, logger lib.Logger) FileService {
+// StoredObject 存储对象读取句柄，Reader 支持 Seek 以便处理 Range 请求，使用后需关闭
+type StoredObject struct {
+	Reader      io.ReadSeekCloser
+	Size        int64
+	ModTime     time.Time
+	ContentType string
+}
+
+// NewFileService 创建文件服务，配置了 OSS.SignKey 时上传结果附带签名下载地址
+func NewFileService(config lib.Config, storage FileStorage, fileObjectRepository repository.FileObjectRepository, logger lib.Logger) FileService {
+	svc := NewObjectFileService(storage, fileObjectRepository, logger)
+	if oss := config.OSS; oss != nil && oss.SignKey != "" {
+		svc.SetSignKey(oss.SignKey, time.Duration(oss.URLExpire)*time.Minute)
+	}
+
+	return svc
+}
+
+// NewFileStorage 根据配置创建对应的存储后端
+func NewFileStorage(config lib.Config, logger lib.Logger) FileStorage {
 	ossConfig := config.OSS
 	if ossConfig == nil {
 		logger.Zap.Warn("OSS config not found, using local storage")
@@ -57,6 +102,206 @@ func NewFileService(config lib.Config, logger lib.Logger) FileService {
 	}
 }
 
+// ==================== Object File Service ====================
+
+// ObjectFileService 文件服务
+// 上传时规范化文件名，按日期和内容哈希分片存储；
+// 相同内容（SHA256）只存储一份，通过引用计数在最后一个引用删除时才删除存储文件
+type ObjectFileService struct {
+	storage              FileStorage
+	fileObjectRepository repository.FileObjectRepository
+	logger               lib.Logger
+	signKey              []byte
+	urlExpire            time.Duration
+}
+
+// NewObjectFileService 创建文件服务
+func NewObjectFileService(
+	storage FileStorage,
+	fileObjectRepository repository.FileObjectRepository,
+	logger lib.Logger,
+) *ObjectFileService {
+	return &ObjectFileService{
+		storage:              storage,
+		fileObjectRepository: fileObjectRepository,
+		logger:               logger,
+	}
+}
+
+// SetSignKey 设置签名下载地址的密钥与有效期，expire 不大于 0 时使用默认的 60 分钟
+func (s *ObjectFileService) SetSignKey(key string, expire time.Duration) {
+	if expire <= 0 {
+		expire = fileURLDefaultExpire
+	}
+
+	s.signKey = []byte(key)
+	s.urlExpire = expire
+}
+
+// QueryFiles 分页查询已登记的文件记录
+func (s *ObjectFileService) QueryFiles(param *platform.FileObjectQueryParam) (*platform.FileObjectQueryResult, error) {
+	return s.fileObjectRepository.Query(param)
+}
+
+// UploadFile 上传文件
+func (s *ObjectFileService) UploadFile(filename string, reader io.Reader, size int64, contentType string) (*platform.FileInfo, error) {
+	filename = file.NormalizeFilename(filename)
+
+	// 先写入临时文件并计算哈希，用于去重判断
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hasher), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	if object, err := s.reuse(hash); err != nil {
+		return nil, err
+	} else if object != nil {
+		return &platform.FileInfo{Name: filename, URL: object.URL, DownloadURL: s.SignedURL(object.URL)}, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read temp file: %w", err)
+	}
+
+	objectKey := file.ShardPath(time.Now().Format("20060102"), hash, file.SafeExt(filename))
+	fileURL, err := s.storage.PutObject(objectKey, tmp, written, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &platform.FileObject{
+		Hash:        hash,
+		URL:         fileURL,
+		Size:        written,
+		ContentType: contentType,
+		RefCount:    1,
+	}
+	if err := s.fileObjectRepository.Create(object); err != nil {
+		// 并发上传相同内容时唯一索引冲突，改为复用已有记录
+		existing, rerr := s.reuse(hash)
+		if rerr != nil || existing == nil {
+			// 没有记录引用刚上传的文件，删除以免成为孤立文件
+			if rerr == nil {
+				if derr := s.storage.DeleteFile(fileURL); derr != nil {
+					s.logger.Zap.Warnf("Failed to delete unregistered file %s: %v", fileURL, derr)
+				}
+			}
+			return nil, err
+		}
+		if existing.URL != fileURL {
+			if derr := s.storage.DeleteFile(fileURL); derr != nil {
+				s.logger.Zap.Warnf("Failed to delete duplicate file %s: %v", fileURL, derr)
+			}
+		}
+		fileURL = existing.URL
+	}
+
+	return &platform.FileInfo{
+		Name:        filename,
+		URL:         fileURL,
+		DownloadURL: s.SignedURL(fileURL),
+	}, nil
+}
+
+// reuse 查找相同内容的已存储文件并增加引用计数
+func (s *ObjectFileService) reuse(hash string) (*platform.FileObject, error) {
+	object, err := s.fileObjectRepository.GetByHash(hash)
+	if err != nil || object == nil {
+		return nil, err
+	}
+
+	// 记录在查询后被删除时按新文件上传
+	if ok, err := s.fileObjectRepository.IncrRef(object.ID); err != nil || !ok {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// OpenFile 打开存储文件用于下载
+func (s *ObjectFileService) OpenFile(filePath string) (*StoredObject, error) {
+	if filePath == "" {
+		return nil, errors.FileInvalidPath
+	}
+
+	object, err := s.storage.GetObject(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 存储后端未返回类型时使用上传时记录的类型
+	if object.ContentType == "" {
+		if record, err := s.fileObjectRepository.GetByURL(filePath); err == nil && record != nil {
+			object.ContentType = record.ContentType
+		}
+	}
+
+	return object, nil
+}
+
+// OpenSigned 校验签名下载地址并打开存储文件
+func (s *ObjectFileService) OpenSigned(param *platform.FileSignedDownloadParam) (*StoredObject, error) {
+	if len(s.signKey) == 0 || param.FilePath == "" || param.Expires < time.Now().Unix() {
+		return nil, errors.FileSignInvalid
+	}
+	if !hmac.Equal([]byte(s.sign(param.FilePath, param.Expires)), []byte(param.Sign)) {
+		return nil, errors.FileSignInvalid
+	}
+
+	return s.OpenFile(param.FilePath)
+}
+
+// SignedURL 生成文件的签名下载地址，未配置密钥时返回空字符串
+func (s *ObjectFileService) SignedURL(filePath string) string {
+	if len(s.signKey) == 0 {
+		return ""
+	}
+
+	expires := time.Now().Add(s.urlExpire).Unix()
+	query := url.Values{}
+	query.Set("filePath", filePath)
+	query.Set("expires", fmt.Sprint(expires))
+	query.Set("sign", s.sign(filePath, expires))
+	return FileSignedDownloadPath + "?" + query.Encode()
+}
+
+func (s *ObjectFileService) sign(filePath string, expires int64) string {
+	mac := hmac.New(sha256.New, s.signKey)
+	_, _ = fmt.Fprintf(mac, "file:%s:%d", filePath, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeleteFile 删除文件，引用计数归零时才删除存储文件
+func (s *ObjectFileService) DeleteFile(filePath string) error {
+	if filePath == "" {
+		return fmt.Errorf("file path is empty")
+	}
+
+	object, err := s.fileObjectRepository.GetByURL(filePath)
+	if err != nil {
+		return err
+	}
+
+	// 未登记的文件（如去重功能上线前上传的文件）直接删除
+	if object == nil {
+		return s.storage.DeleteFile(filePath)
+	}
+
+	_, err = s.fileObjectRepository.Release(object.ID, s.storage.DeleteFile)
+	return err
+}
+
 // ==================== Local File Service ====================
 
 // LocalFileService 本地文件存储服务
@@ -77,61 +322,121 @@ func NewLocalFileService(storagePath string, logger lib.Logger) *LocalFileServic
 	}
 }
 
-// UploadFile 上传文件到本地
-func (s *LocalFileService) UploadFile(filename string, reader io.Reader, size int64, contentType string) (*platform.FileInfo, error) {
-	// 获取文件后缀
-	ext := filepath.Ext(filename)
-	// 生成新文件名
-	newFilename := uuid.New().String() + ext
-	// 按日期分目录
-	dateFolder := time.Now().Format("20060102")
-	folderPath := filepath.Join(s.storagePath, dateFolder)
+// PutObject 保存文件到本地
+func (s *LocalFileService) PutObject(objectKey string, reader io.Reader, size int64, contentType string) (string, error) {
+	fullPath := filepath.Join(s.storagePath, filepath.FromSlash(objectKey))
 
-	// 创建日期目录
-	if err := os.MkdirAll(folderPath, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create directory: %w", err)
+	// 创建分片目录
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// 完整文件路径
-	fullPath := filepath.Join(folderPath, newFilename)
-
 	// 创建文件
 	file, err := os.Create(fullPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return "", fmt.Errorf("failed to create file: %w", err)
 	}
 	defer file.Close()
 
 	// 写入文件
 	if _, err := io.Copy(file, reader); err != nil {
-		return nil, fmt.Errorf("failed to write file: %w", err)
+		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// 返回相对路径，前端需要自行处理访问前缀
-	fileURL := "/" + dateFolder + "/" + newFilename
+	return "/" + objectKey, nil
+}
 
-	return &platform.FileInfo{
-		Name: filename,
-		URL:  fileURL,
+// resolve 解析文件的绝对路径
+// 安全检查：防止访问存储目录外的文件
+func (s *LocalFileService) resolve(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filepath.Join(s.storagePath, filepath.FromSlash(filePath)))
+	if err != nil {
+		return "", fmt.Errorf("invalid file path: %w", err)
+	}
+
+	absStoragePath, _ := filepath.Abs(s.storagePath)
+	if !strings.HasPrefix(absPath, absStoragePath+string(filepath.Separator)) {
+		return "", errors.FileInvalidPath
+	}
+
+	return absPath, nil
+}
+
+// GetObject 打开本地文件
+func (s *LocalFileService) GetObject(filePath string) (*StoredObject, error) {
+	absPath, err := s.resolve(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.FileNotFound
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if info.IsDir() {
+		f.Close()
+		return nil, errors.FileInvalidPath
+	}
+
+	return &StoredObject{
+		Reader:  f,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
 	}, nil
 }
 
+// ListObjects 列出本地存储目录下的所有文件
+func (s *LocalFileService) ListObjects() ([]*platform.StoredEntry, error) {
+	list := make([]*platform.StoredEntry, 0)
+	err := filepath.WalkDir(s.storagePath, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.storagePath, fullPath)
+		if err != nil {
+			return err
+		}
+
+		list = append(list, &platform.StoredEntry{
+			URL:     "/" + filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: dto.DateTime(info.ModTime()),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk storage directory: %w", err)
+	}
+
+	return list, nil
+}
+
 // DeleteFile 删除本地文件
 func (s *LocalFileService) DeleteFile(filePath string) error {
 	if filePath == "" {
 		return fmt.Errorf("file path is empty")
 	}
 
-	// 安全检查：防止删除存储目录外的文件
-	fullPath := filepath.Join(s.storagePath, filePath)
-	absPath, err := filepath.Abs(fullPath)
+	absPath, err := s.resolve(filePath)
 	if err != nil {
-		return fmt.Errorf("invalid file path: %w", err)
-	}
-
-	absStoragePath, _ := filepath.Abs(s.storagePath)
-	if !strings.HasPrefix(absPath, absStoragePath) {
-		return fmt.Errorf("invalid file path: access denied")
+		return err
 	}
 
 	// 检查是否为目录
@@ -224,37 +529,46 @@ func (s *MinioFileService) ensureBucket() error {
 	return nil
 }
 
-// UploadFile 上传文件到MinIO
-func (s *MinioFileService) UploadFile(filename string, reader io.Reader, size int64, contentType string) (*platform.FileInfo, error) {
-	// 获取文件后缀
-	ext := filepath.Ext(filename)
-	// 生成新文件名
-	newFilename := uuid.New().String() + ext
-	// 按日期分目录
-	dateFolder := time.Now().Format("20060102")
-	objectName := dateFolder + "/" + newFilename
-
+// PutObject 上传文件到MinIO
+func (s *MinioFileService) PutObject(objectKey string, reader io.Reader, size int64, contentType string) (string, error) {
 	ctx := context.Background()
 	// 上传文件
-	_, err := s.client.PutObject(ctx, s.bucketName, objectName, reader, size, minio.PutObjectOptions{
+	_, err := s.client.PutObject(ctx, s.bucketName, objectKey, reader, size, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload file: %w", err)
+		return "", fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// 构建文件URL
-	var fileURL string
+	return s.objectURL(objectKey), nil
+}
+
+// objectURL 构建文件URL
+func (s *MinioFileService) objectURL(objectKey string) string {
 	if s.customDomain != "" {
-		fileURL = s.customDomain + "/" + s.bucketName + "/" + objectName
-	} else {
-		fileURL = s.endpoint + "/" + s.bucketName + "/" + objectName
+		return s.customDomain + "/" + s.bucketName + "/" + objectKey
 	}
 
-	return &platform.FileInfo{
-		Name: filename,
-		URL:  fileURL,
-	}, nil
+	return s.endpoint + "/" + s.bucketName + "/" + objectKey
+}
+
+// ListObjects 列出存储桶中的所有对象
+func (s *MinioFileService) ListObjects() ([]*platform.StoredEntry, error) {
+	ctx := context.Background()
+	list := make([]*platform.StoredEntry, 0)
+	for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", object.Err)
+		}
+
+		list = append(list, &platform.StoredEntry{
+			URL:     s.objectURL(object.Key),
+			Size:    object.Size,
+			ModTime: dto.DateTime(object.LastModified),
+		})
+	}
+
+	return list, nil
 }
 
 // DeleteFile 删除MinIO文件
@@ -263,18 +577,42 @@ func (s *MinioFileService) DeleteFile(filePath string) error {
 		return fmt.Errorf("file path is empty")
 	}
 
-	// 从URL中提取对象名
-	var objectName string
+	ctx := context.Background()
+	return s.client.RemoveObject(ctx, s.bucketName, s.objectName(filePath), minio.RemoveObjectOptions{})
+}
+
+// objectName 从URL中提取对象名
+func (s *MinioFileService) objectName(filePath string) string {
 	if s.customDomain != "" {
-		prefix := s.customDomain + "/" + s.bucketName + "/"
-		objectName = strings.TrimPrefix(filePath, prefix)
-	} else {
-		prefix := s.endpoint + "/" + s.bucketName + "/"
-		objectName = strings.TrimPrefix(filePath, prefix)
+		return strings.TrimPrefix(filePath, s.customDomain+"/"+s.bucketName+"/")
 	}
 
+	return strings.TrimPrefix(filePath, s.endpoint+"/"+s.bucketName+"/")
+}
+
+// GetObject 打开MinIO文件
+func (s *MinioFileService) GetObject(filePath string) (*StoredObject, error) {
 	ctx := context.Background()
-	return s.client.RemoveObject(ctx, s.bucketName, objectName, minio.RemoveObjectOptions{})
+	object, err := s.client.GetObject(ctx, s.bucketName, s.objectName(filePath), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, errors.FileNotFound
+		}
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	return &StoredObject{
+		Reader:      object,
+		Size:        info.Size,
+		ModTime:     info.LastModified,
+		ContentType: info.ContentType,
+	}, nil
 }
 
 // ==================== Aliyun OSS File Service ====================
@@ -293,11 +631,21 @@ func NewAliyunFileService(config *lib.AliyunOSSConfig, logger lib.Logger) *Aliyu
 	}
 }
 
-// UploadFile 上传文件到阿里云OSS
-func (s *AliyunFileService) UploadFile(filename string, reader io.Reader, size int64, contentType string) (*platform.FileInfo, error) {
+// PutObject 上传文件到阿里云OSS
+func (s *AliyunFileService) PutObject(objectKey string, reader io.Reader, size int64, contentType string) (string, error) {
 	// 阿里云OSS需要引入阿里云SDK，这里提供一个简化实现
 	// 实际使用时需要: go get github.com/aliyun/aliyun-oss-go-sdk/oss
-	return nil, fmt.Errorf("aliyun OSS not implemented, please install aliyun-oss-go-sdk")
+	return "", fmt.Errorf("aliyun OSS not implemented, please install aliyun-oss-go-sdk")
+}
+
+// GetObject 打开阿里云OSS文件
+func (s *AliyunFileService) GetObject(filePath string) (*StoredObject, error) {
+	return nil, errors.FileNotSupported
+}
+
+// ListObjects 列出阿里云OSS对象
+func (s *AliyunFileService) ListObjects() ([]*platform.StoredEntry, error) {
+	return nil, errors.FileNotSupported
 }
 
 // DeleteFile 删除阿里云OSS文件
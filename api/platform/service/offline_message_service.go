@@ -0,0 +1,99 @@
+package service
+
+import (
+	"time"
+
+	"github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+const (
+	offlineMessageDefaultTTL          = 72
+	offlineMessageDefaultMaxQueueSize = 100
+)
+
+// OfflineMessageService 离线 STOMP 消息存储，启用时保存发送给离线用户的用户队列消息，
+// 用户下次订阅对应队列时由消息代理投递
+type OfflineMessageService struct {
+	logger                 lib.Logger
+	ttl                    time.Duration
+	maxQueueSize           int
+	stompMessageRepository repository.StompMessageRepository
+}
+
+// NewOfflineMessageService 创建离线消息存储，启用时设置为消息代理的离线消息存储
+func NewOfflineMessageService(
+	logger lib.Logger,
+	config lib.Config,
+	ws *websocket.WebSocket,
+	stompMessageRepository repository.StompMessageRepository,
+) OfflineMessageService {
+	cfg := &lib.OfflineMessageConfig{}
+	if config.WebSocket != nil && config.WebSocket.OfflineMessages != nil {
+		cfg = config.WebSocket.OfflineMessages
+	}
+
+	svc := OfflineMessageService{
+		logger:                 logger,
+		ttl:                    time.Duration(cfg.TTL) * time.Hour,
+		maxQueueSize:           cfg.MaxQueueSize,
+		stompMessageRepository: stompMessageRepository,
+	}
+	if svc.ttl <= 0 {
+		svc.ttl = offlineMessageDefaultTTL * time.Hour
+	}
+	if svc.maxQueueSize <= 0 {
+		svc.maxQueueSize = offlineMessageDefaultMaxQueueSize
+	}
+
+	if cfg.Enable {
+		ws.Broker.SetMessageStore(svc)
+	}
+
+	return svc
+}
+
+// Save 保存消息，同时清理过期消息并只保留该用户最新的 MaxQueueSize 条
+func (a OfflineMessageService) Save(username, destination string, body []byte) error {
+	if err := a.stompMessageRepository.DeleteBefore(time.Now().Add(-a.ttl)); err != nil {
+		a.logger.Zap.Warnf("Failed to purge expired offline messages: %v", err)
+	}
+
+	msg := &platform.StompMessage{
+		Username:    username,
+		Destination: destination,
+		Body:        body,
+	}
+	if err := a.stompMessageRepository.Create(msg); err != nil {
+		return err
+	}
+
+	return a.stompMessageRepository.Trim(username, a.maxQueueSize)
+}
+
+// Take 取出并删除用户未过期、目标满足 match 的消息
+// 只返回本次删除成功的消息，避免同一用户的多个会话同时订阅时重复投递
+func (a OfflineMessageService) Take(username string, match func(destination string) bool) ([]stomp.StoredMessage, error) {
+	list, err := a.stompMessageRepository.ListByUsername(username, time.Now().Add(-a.ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []stomp.StoredMessage
+	for _, item := range list {
+		if !match(item.Destination) {
+			continue
+		}
+		if n, err := a.stompMessageRepository.DeleteByIDs([]uint64{item.ID}); err != nil {
+			return msgs, err
+		} else if n == 0 {
+			continue
+		}
+		msgs = append(msgs, stomp.StoredMessage{Destination: item.Destination, Body: item.Body})
+	}
+
+	return msgs, nil
+}
@@ -4,5 +4,8 @@ import "go.uber.org/fx"
 
 // Module exports services present
 var Module = fx.Options(
+	fx.Provide(NewFileStorage),
 	fx.Provide(NewFileService),
+	fx.Provide(NewFileCleanupService),
+	fx.Provide(NewOfflineMessageService),
 )
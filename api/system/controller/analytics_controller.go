@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// AnalyticsController 统计分析控制器
+type AnalyticsController struct {
+	logger          lib.Logger
+	apiUsageService service.ApiUsageService
+}
+
+// NewAnalyticsController creates new analytics controller
+func NewAnalyticsController(
+	logger lib.Logger,
+	apiUsageService service.ApiUsageService,
+) AnalyticsController {
+	return AnalyticsController{
+		logger:          logger,
+		apiUsageService: apiUsageService,
+	}
+}
+
+// QueryApiUsage 分页查询接口调用统计明细
+// @tags Analytics
+// @summary Api Usage Query
+// @produce application/json
+// @param data query system.ApiUsageQueryParam true "ApiUsageQueryParam"
+// @success 200 {object} echox.Response{data=[]system.ApiUsage} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/analytics/api-usage [get]
+func (a AnalyticsController) QueryApiUsage(ctx echo.Context) error {
+	param := new(system.ApiUsageQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.apiUsageService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// TopUsers 调用量最高的用户
+// @tags Analytics
+// @summary Api Usage Top Users
+// @produce application/json
+// @param data query system.ApiUsageTopParam true "ApiUsageTopParam"
+// @success 200 {object} echox.Response{data=[]system.ApiUsageUserVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/analytics/api-usage/users [get]
+func (a AnalyticsController) TopUsers(ctx echo.Context) error {
+	param := new(system.ApiUsageTopParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	list, err := a.apiUsageService.TopUsers(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// TopRoutes 调用量或错误最多的接口
+// @tags Analytics
+// @summary Api Usage Top Routes
+// @produce application/json
+// @param data query system.ApiUsageTopParam true "ApiUsageTopParam"
+// @success 200 {object} echox.Response{data=[]system.ApiUsageRouteVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/analytics/api-usage/routes [get]
+func (a AnalyticsController) TopRoutes(ctx echo.Context) error {
+	param := new(system.ApiUsageTopParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	list, err := a.apiUsageService.TopRoutes(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// ApiKeyController 个人访问令牌（API Key）控制器
+type ApiKeyController struct {
+	logger        lib.Logger
+	apiKeyService service.ApiKeyService
+}
+
+// NewApiKeyController creates new api key controller
+func NewApiKeyController(
+	logger lib.Logger,
+	apiKeyService service.ApiKeyService,
+) ApiKeyController {
+	return ApiKeyController{
+		logger:        logger,
+		apiKeyService: apiKeyService,
+	}
+}
+
+// List 当前用户的 API Key
+// @tags ApiKey
+// @summary Api Key List
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.ApiKey} "ok"
+// @failure 403 {object} echox.Response "forbidden"
+// @router /api/v1/api-keys [get]
+func (a ApiKeyController) List(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	list, err := a.apiKeyService.List(claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Create 创建 API Key，明文密钥仅在本次响应中返回
+// @tags ApiKey
+// @summary Api Key Create
+// @produce application/json
+// @param data body system.ApiKeyForm true "ApiKeyForm"
+// @success 200 {object} echox.Response{data=system.ApiKey} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 403 {object} echox.Response "forbidden"
+// @router /api/v1/api-keys [post]
+func (a ApiKeyController) Create(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	form := new(system.ApiKeyForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	apiKey, err := a.apiKeyService.WithTrx(trxHandle).Create(claims, form)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: apiKey}.JSON(ctx)
+}
+
+// Revoke 吊销 API Key，立即失效
+// @tags ApiKey
+// @summary Api Key Revoke
+// @produce application/json
+// @param id path int true "API Key ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/api-keys/{id} [delete]
+func (a ApiKeyController) Revoke(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.apiKeyService.WithTrx(trxHandle).Revoke(claims, id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
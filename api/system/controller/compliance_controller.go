@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/report"
+)
+
+// ComplianceController 安全合规报表控制器
+type ComplianceController struct {
+	logger            lib.Logger
+	complianceService service.ComplianceService
+}
+
+// NewComplianceController creates new compliance controller
+func NewComplianceController(
+	logger lib.Logger,
+	complianceService service.ComplianceService,
+) ComplianceController {
+	return ComplianceController{
+		logger:            logger,
+		complianceService: complianceService,
+	}
+}
+
+// Generate 生成安全合规报表（异步）
+// @tags Compliance
+// @summary Generate Compliance Report
+// @accept application/json
+// @produce application/json
+// @param data body system.ComplianceReportForm true "ComplianceReportForm"
+// @success 200 {object} echox.Response{data=system.ComplianceReport} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/compliance/reports [post]
+func (a ComplianceController) Generate(ctx echo.Context) error {
+	form := new(system.ComplianceReportForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	record, err := a.complianceService.Generate(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: record}.JSON(ctx)
+}
+
+// Query 分页查询报表记录
+// @tags Compliance
+// @summary Compliance Report Query
+// @produce application/json
+// @param data query system.ComplianceReportQueryParam true "ComplianceReportQueryParam"
+// @success 200 {object} echox.Response{data=[]system.ComplianceReport} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/compliance/reports [get]
+func (a ComplianceController) Query(ctx echo.Context) error {
+	param := new(system.ComplianceReportQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.complianceService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Get 获取报表记录（生成状态）
+// @tags Compliance
+// @summary Compliance Report Get
+// @produce application/json
+// @param id path int true "报表ID"
+// @success 200 {object} echox.Response{data=system.ComplianceReport} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/compliance/reports/{id} [get]
+func (a ComplianceController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	record, err := a.complianceService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: record}.JSON(ctx)
+}
+
+// Download 下载已生成的报表
+// @tags Compliance
+// @summary Compliance Report Download
+// @produce application/octet-stream
+// @param id path int true "报表ID"
+// @success 200 {file} file "report"
+// @failure 409 {object} echox.Response "report is not ready"
+// @router /api/v1/compliance/reports/{id}/download [get]
+func (a ComplianceController) Download(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	record, object, err := a.complianceService.Open(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	defer object.Reader.Close()
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": record.FileName}))
+	header.Set(echo.HeaderContentType, report.ContentType(record.Format))
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(ctx.Response(), ctx.Request(), record.FileName, object.ModTime, object.Reader)
+	return nil
+}
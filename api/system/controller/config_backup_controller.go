@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// ConfigBackupController 配置备份控制器
+type ConfigBackupController struct {
+	logger              lib.Logger
+	configBackupService service.ConfigBackupService
+}
+
+// NewConfigBackupController creates new config backup controller
+func NewConfigBackupController(
+	logger lib.Logger,
+	configBackupService service.ConfigBackupService,
+) ConfigBackupController {
+	return ConfigBackupController{
+		logger:              logger,
+		configBackupService: configBackupService,
+	}
+}
+
+// Create 立即备份菜单、角色、字典和系统配置
+// @tags ConfigBackup
+// @summary Create Config Backup
+// @accept application/json
+// @produce application/json
+// @param data body system.ConfigBackupForm true "ConfigBackupForm"
+// @success 200 {object} echox.Response{data=system.ConfigBackup} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/config-backups [post]
+func (a ConfigBackupController) Create(ctx echo.Context) error {
+	form := new(system.ConfigBackupForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	record, err := a.configBackupService.Backup(system.ConfigBackupManual, form.Remark, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: record}.JSON(ctx)
+}
+
+// Query 分页查询备份记录
+// @tags ConfigBackup
+// @summary Config Backup Query
+// @produce application/json
+// @param data query system.ConfigBackupQueryParam true "ConfigBackupQueryParam"
+// @success 200 {object} echox.Response{data=[]system.ConfigBackup} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/config-backups [get]
+func (a ConfigBackupController) Query(ctx echo.Context) error {
+	param := new(system.ConfigBackupQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.configBackupService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Get 获取备份记录
+// @tags ConfigBackup
+// @summary Config Backup Get
+// @produce application/json
+// @param id path int true "备份ID"
+// @success 200 {object} echox.Response{data=system.ConfigBackup} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/config-backups/{id} [get]
+func (a ConfigBackupController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	record, err := a.configBackupService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: record}.JSON(ctx)
+}
+
+// Download 下载备份文件
+// @tags ConfigBackup
+// @summary Config Backup Download
+// @produce application/json
+// @param id path int true "备份ID"
+// @success 200 {file} file "backup"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/config-backups/{id}/download [get]
+func (a ConfigBackupController) Download(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	record, object, err := a.configBackupService.Open(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	defer object.Reader.Close()
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": record.FileName}))
+	header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(ctx.Response(), ctx.Request(), record.FileName, object.ModTime, object.Reader)
+	return nil
+}
+
+// Diff 预览恢复到指定备份时的变更
+// @tags ConfigBackup
+// @summary Config Backup Diff
+// @produce application/json
+// @param id path int true "备份ID"
+// @param data query system.ConfigRestoreParam true "ConfigRestoreParam"
+// @success 200 {object} echox.Response{data=system.ConfigDiff} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/config-backups/{id}/diff [get]
+func (a ConfigBackupController) Diff(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.ConfigRestoreParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	diff, err := a.configBackupService.Diff(id, param.Prune)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: diff}.JSON(ctx)
+}
+
+// Restore 恢复到指定备份，返回实际应用的变更
+// @tags ConfigBackup
+// @summary Config Backup Restore
+// @produce application/json
+// @param id path int true "备份ID"
+// @param data query system.ConfigRestoreParam true "ConfigRestoreParam"
+// @success 200 {object} echox.Response{data=system.ConfigDiff} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/config-backups/{id}/restore [post]
+func (a ConfigBackupController) Restore(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.ConfigRestoreParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var operator uint64
+	if claims != nil {
+		operator = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	diff, err := a.configBackupService.WithTrx(trxHandle).Restore(id, param.Prune, operator)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: diff}.JSON(ctx)
+}
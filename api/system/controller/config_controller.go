@@ -56,6 +56,7 @@ func (a ConfigController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
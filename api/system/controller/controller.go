@@ -16,4 +16,26 @@ var Module = fx.Options(
 	fx.Provide(NewLogController),
 	fx.Provide(NewTaskController),
 	fx.Provide(NewDownloadController),
+	fx.Provide(NewPermissionController),
+	fx.Provide(NewMaintenanceController),
+	fx.Provide(NewAnalyticsController),
+	fx.Provide(NewSecurityController),
+	fx.Provide(NewComplianceController),
+	fx.Provide(NewTagController),
+	fx.Provide(NewPositionController),
+	fx.Provide(NewApiKeyController),
+	fx.Provide(NewUserJobController),
+	fx.Provide(NewProvisionController),
+	fx.Provide(NewConfigBackupController),
+	fx.Provide(NewPolicyController),
+	fx.Provide(NewDeptRoleController),
+	fx.Provide(NewMetaController),
+	fx.Provide(NewSavedQueryController),
+	fx.Provide(NewWsEventController),
+	fx.Provide(NewDirectoryController),
+	fx.Provide(NewLockController),
+	fx.Provide(NewRetentionController),
+	fx.Provide(NewCrontabController),
+	fx.Provide(NewMailTemplateController),
+	fx.Provide(NewWebhookController),
 )
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// CrontabController 定时任务管理控制器
+type CrontabController struct {
+	logger         lib.Logger
+	crontabService service.CrontabService
+}
+
+// NewCrontabController creates new crontab controller
+func NewCrontabController(
+	logger lib.Logger,
+	crontabService service.CrontabService,
+) CrontabController {
+	return CrontabController{
+		logger:         logger,
+		crontabService: crontabService,
+	}
+}
+
+// List 全部定时任务，包括内置任务和运行时创建的任务
+// @tags Crontab
+// @summary Cron Task List
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.CronTaskVO} "ok"
+// @failure 503 {object} echox.Response "crontab not enabled"
+// @router /api/v1/crontab/tasks [get]
+func (a CrontabController) List(ctx echo.Context) error {
+	list, err := a.crontabService.List()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Handlers 可用于创建定时任务的处理器
+// @tags Crontab
+// @summary Cron Task Handlers
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.CronHandlerVO} "ok"
+// @router /api/v1/crontab/handlers [get]
+func (a CrontabController) Handlers(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: a.crontabService.Handlers()}.JSON(ctx)
+}
+
+// Create 创建定时任务，保存后立即注册，重启后自动恢复
+// @tags Crontab
+// @summary Cron Task Create
+// @accept application/json
+// @produce application/json
+// @param data body system.CronTaskForm true "CronTaskForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "already exists"
+// @router /api/v1/crontab/tasks [post]
+func (a CrontabController) Create(ctx echo.Context) error {
+	form := new(system.CronTaskForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if err := a.crontabService.Create(form, claims.ID); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Update 修改定时任务的执行时间，运行时创建的任务还可以修改参数和备注
+// @tags Crontab
+// @summary Cron Task Update
+// @accept application/json
+// @produce application/json
+// @param name path string true "任务名称"
+// @param data body system.CronTaskUpdateForm true "CronTaskUpdateForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/crontab/tasks/{name} [put]
+func (a CrontabController) Update(ctx echo.Context) error {
+	form := new(system.CronTaskUpdateForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if err := a.crontabService.Update(ctx.Param("name"), form, claims.ID); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// SetStatus 启用或停用定时任务
+// @tags Crontab
+// @summary Cron Task Enable/Disable
+// @accept application/json
+// @produce application/json
+// @param name path string true "任务名称"
+// @param data body system.CronTaskStatusForm true "CronTaskStatusForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/crontab/tasks/{name}/status [put]
+func (a CrontabController) SetStatus(ctx echo.Context) error {
+	form := new(system.CronTaskStatusForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if err := a.crontabService.SetStatus(ctx.Param("name"), form.Enable, claims.ID); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Run 立即执行一次定时任务，不影响定时调度
+// @tags Crontab
+// @summary Cron Task Run
+// @produce application/json
+// @param name path string true "任务名称"
+// @success 200 {object} echox.Response "ok"
+// @failure 404 {object} echox.Response "not found"
+// @failure 409 {object} echox.Response "still running"
+// @router /api/v1/crontab/tasks/{name}/run [post]
+func (a CrontabController) Run(ctx echo.Context) error {
+	if err := a.crontabService.Run(ctx.Param("name")); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// History 定时任务最近的执行记录，按开始时间倒序
+// @tags Crontab
+// @summary Cron Task History
+// @produce application/json
+// @param name path string true "任务名称"
+// @param limit query int false "返回条数，默认 20，最大 100"
+// @success 200 {object} echox.Response{data=[]system.CronTaskRecord} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/crontab/tasks/{name}/history [get]
+func (a CrontabController) History(ctx echo.Context) error {
+	param := new(system.CronTaskHistoryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	list, err := a.crontabService.History(ctx.Param("name"), param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Delete 删除运行时创建的定时任务，内置任务只能停用
+// @tags Crontab
+// @summary Cron Task Delete
+// @produce application/json
+// @param name path string true "任务名称"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "builtin task"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/crontab/tasks/{name} [delete]
+func (a CrontabController) Delete(ctx echo.Context) error {
+	if err := a.crontabService.Delete(ctx.Param("name")); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Reload 按持久化的配置重新加载定时任务，获取其他实例或直接修改数据库造成的变更
+// @tags Crontab
+// @summary Cron Task Reload
+// @produce application/json
+// @success 200 {object} echox.Response "ok"
+// @failure 503 {object} echox.Response "crontab not enabled"
+// @router /api/v1/crontab/reload [post]
+func (a CrontabController) Reload(ctx echo.Context) error {
+	if err := a.crontabService.Reload(); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
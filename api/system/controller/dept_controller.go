@@ -37,6 +37,7 @@ func NewDeptController(
 // @Produce application/json
 // @Param keywords query string false "关键字"
 // @Param status query int false "状态"
+// @Param tree query bool false "是否返回树形结构，默认 true"
 // @Success 200 {object} echox.Response{data=[]system.DeptVO} "ok"
 // @Router /api/v1/depts [get]
 func (a DeptController) Query(ctx echo.Context) error {
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// DeptRoleController 部门默认角色规则控制器
+type DeptRoleController struct {
+	logger          lib.Logger
+	deptRoleService service.DeptRoleService
+}
+
+// NewDeptRoleController creates new dept role controller
+func NewDeptRoleController(
+	logger lib.Logger,
+	deptRoleService service.DeptRoleService,
+) DeptRoleController {
+	return DeptRoleController{
+		logger:          logger,
+		deptRoleService: deptRoleService,
+	}
+}
+
+// Query 分页查询规则
+// @tags DeptRole
+// @summary Dept Role Rule Query
+// @produce application/json
+// @param data query system.DeptRoleRuleQueryParam true "DeptRoleRuleQueryParam"
+// @success 200 {object} echox.Response{data=[]system.DeptRoleRule} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/dept-role-rules [get]
+func (a DeptRoleController) Query(ctx echo.Context) error {
+	param := new(system.DeptRoleRuleQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.deptRoleService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Create 新增规则，已在部门中的用户需通过同步接口补齐角色
+// @tags DeptRole
+// @summary Dept Role Rule Create
+// @accept application/json
+// @produce application/json
+// @param data body system.DeptRoleRuleForm true "DeptRoleRuleForm"
+// @success 200 {object} echox.Response{data=uint64} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/dept-role-rules [post]
+func (a DeptRoleController) Create(ctx echo.Context) error {
+	form := new(system.DeptRoleRuleForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	id, err := a.deptRoleService.WithTrx(trxHandle).Create(form, claims.ID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: id}.JSON(ctx)
+}
+
+// Update 修改规则
+// @tags DeptRole
+// @summary Dept Role Rule Update By ID
+// @accept application/json
+// @produce application/json
+// @param id path int true "规则ID"
+// @param data body system.DeptRoleRuleForm true "DeptRoleRuleForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/dept-role-rules/{id} [put]
+func (a DeptRoleController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.DeptRoleRuleForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.deptRoleService.WithTrx(trxHandle).Update(id, form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除规则，已授予用户的角色保留
+// @tags DeptRole
+// @summary Dept Role Rule Delete
+// @produce application/json
+// @param ids path string true "规则ID，多个以英文逗号分割"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/dept-role-rules/{ids} [delete]
+func (a DeptRoleController) Delete(ctx echo.Context) error {
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.deptRoleService.WithTrx(trxHandle).Delete(ctx.Param("ids")); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Preview 预览将用户调入部门时的角色变更，不实际执行
+// @tags DeptRole
+// @summary Dept Role Preview
+// @accept application/json
+// @produce application/json
+// @param data body system.DeptRolePreviewForm true "DeptRolePreviewForm"
+// @success 200 {object} echox.Response{data=system.DeptRolePlan} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/dept-role-rules/preview [post]
+func (a DeptRoleController) Preview(ctx echo.Context) error {
+	form := new(system.DeptRolePreviewForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	plan, err := a.deptRoleService.Preview(form)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: plan}.JSON(ctx)
+}
+
+// Apply 按用户当前所在部门补齐默认角色，dryRun 为 true 时只返回变更计划
+// @tags DeptRole
+// @summary Dept Role Apply
+// @accept application/json
+// @produce application/json
+// @param data body system.DeptRoleApplyForm true "DeptRoleApplyForm"
+// @success 200 {object} echox.Response{data=system.DeptRolePlan} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/dept-role-rules/apply [post]
+func (a DeptRoleController) Apply(ctx echo.Context) error {
+	form := new(system.DeptRoleApplyForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	plan, err := a.deptRoleService.WithTrx(trxHandle).Apply(form, claims.ID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: plan}.JSON(ctx)
+}
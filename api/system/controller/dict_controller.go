@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
@@ -13,33 +14,72 @@ import (
 	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/pkg/echox"
-	ws "github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/eventbus"
 )
 
 type DictController struct {
 	dictService     service.DictService
 	dictItemService service.DictItemService
+	exportService   service.ExportService
 	logger          lib.Logger
-	websocket       *ws.WebSocket
+	bus             *eventbus.Bus
 }
 
 // NewDictController creates new dict controller
 func NewDictController(
 	dictService service.DictService,
 	dictItemService service.DictItemService,
+	exportService service.ExportService,
 	logger lib.Logger,
-	websocket *ws.WebSocket,
+	bus *eventbus.Bus,
 ) DictController {
 	return DictController{
 		dictService:     dictService,
 		dictItemService: dictItemService,
+		exportService:   exportService,
 		logger:          logger,
-		websocket:       websocket,
+		bus:             bus,
 	}
 }
 
 // ========== 字典相关接口 ==========
 
+// GetDelta 字典及字典项增量同步
+// version 为上次同步返回的版本号，为空时返回全量；也可通过 If-Modified-Since 指定起始时间，
+// 此时无变更返回 304。同时支持 If-None-Match（ETag 即版本号）
+// @Tags Dict
+// @Summary 字典增量同步
+// @Produce application/json
+// @Param version query string false "上次同步返回的版本号"
+// @Success 200 {object} echox.Response{data=system.DictDeltaVO} "ok"
+// @Success 304 "not modified"
+// @Router /api/v1/dicts/delta [get]
+func (a DictController) GetDelta(ctx echo.Context) error {
+	version := ctx.QueryParam("version")
+	since, conditional := echox.IfModifiedSince(ctx)
+	if version == "" && conditional {
+		version = strconv.FormatInt(since.Unix(), 10)
+	}
+
+	delta, err := a.dictService.GetDelta(version)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if echox.SetETag(ctx, delta.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	if latest, err := strconv.ParseInt(delta.Version, 10, 64); err == nil && latest > 0 {
+		ctx.Response().Header().Set("Last-Modified", time.Unix(latest, 0).UTC().Format(http.TimeFormat))
+	}
+	if conditional && !delta.Full && len(delta.Dicts) == 0 && len(delta.Items) == 0 &&
+		len(delta.RemovedDictIDs) == 0 && len(delta.RemovedItemIDs) == 0 {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: delta}.JSON(ctx)
+}
+
 // GetDictPage 字典分页列表
 // @Tags Dict
 // @Summary 字典分页列表
@@ -67,6 +107,7 @@ func (a DictController) GetDictPage(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
@@ -116,8 +157,8 @@ func (a DictController) SaveDict(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	// 发送字典更新通知
-	a.websocket.BroadcastDictChange(form.DictCode)
+	// 发布字典变更事件
+	a.publishDictChange(ctx, form.DictCode)
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
@@ -152,9 +193,9 @@ func (a DictController) UpdateDict(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	// 发送字典更新通知
+	// 发布字典变更事件
 	if form.DictCode != "" {
-		a.websocket.BroadcastDictChange(form.DictCode)
+		a.publishDictChange(ctx, form.DictCode)
 	}
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
@@ -187,9 +228,9 @@ func (a DictController) DeleteDict(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	// 发送字典删除通知
+	// 发布字典变更事件
 	for _, dictCode := range dictCodes {
-		a.websocket.BroadcastDictChange(dictCode)
+		a.publishDictChange(ctx, dictCode)
 	}
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
@@ -227,10 +268,30 @@ func (a DictController) GetDictItems(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
 
+// ExportDictItems 导出字典项，导出完成后推送下载地址
+// @Tags Dict
+// @Summary 导出字典项
+// @Produce application/json
+// @Param dictCode path string true "字典编码"
+// @Param keywords query string false "关键字"
+// @Param format query string false "导出格式：xlsx（默认）、csv"
+// @Success 200 {object} echox.Response "ok"
+// @Router /api/v1/dicts/{dictCode}/items/export [post]
+func (a DictController) ExportDictItems(ctx echo.Context) error {
+	param := new(system.DictItemQueryParam)
+	if err := bindExportQuery(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DictCode = ctx.Param("dictCode")
+
+	return submitExport(ctx, a.exportService, service.ExportSourceDictItem, param)
+}
+
 // GetDictItemOptions 字典项下拉选项（无分页）
 // @Tags Dict
 // @Summary 字典项下拉选项
@@ -299,8 +360,8 @@ func (a DictController) SaveDictItem(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	// 发送字典更新通知
-	a.websocket.BroadcastDictChange(dictCode)
+	// 发布字典变更事件
+	a.publishDictChange(ctx, dictCode)
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
@@ -338,8 +399,8 @@ func (a DictController) UpdateDictItem(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	// 发送字典更新通知
-	a.websocket.BroadcastDictChange(dictCode)
+	// 发布字典变更事件
+	a.publishDictChange(ctx, dictCode)
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
@@ -367,8 +428,16 @@ func (a DictController) DeleteDictItem(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	// 发送字典更新通知
-	a.websocket.BroadcastDictChange(dictCode)
+	// 发布字典变更事件
+	a.publishDictChange(ctx, dictCode)
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
+
+// publishDictChange 发布字典变更事件，由订阅者通知客户端与其他实例刷新字典缓存
+func (a DictController) publishDictChange(ctx echo.Context, dictCode string) {
+	if dictCode == "" {
+		return
+	}
+	system.EventDictChanged.Publish(ctx.Request().Context(), a.bus, system.DictChangedEvent{DictCode: dictCode})
+}
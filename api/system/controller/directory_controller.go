@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// DirectoryController 组织架构与通讯录控制器
+type DirectoryController struct {
+	logger           lib.Logger
+	directoryService service.DirectoryService
+	userService      service.UserService
+}
+
+// NewDirectoryController creates new directory controller
+func NewDirectoryController(
+	logger lib.Logger,
+	directoryService service.DirectoryService,
+	userService service.UserService,
+) DirectoryController {
+	return DirectoryController{
+		logger:           logger,
+		directoryService: directoryService,
+		userService:      userService,
+	}
+}
+
+// Depts 组织架构树
+// @tags Directory
+// @summary Directory Dept Tree
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.DirectoryDeptVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/directory/depts [get]
+func (a DirectoryController) Depts(ctx echo.Context) error {
+	tree, err := a.directoryService.GetDeptTree()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: tree}.JSON(ctx)
+}
+
+// Users 通讯录人员，手机号、邮箱需要对应的字段权限
+// @tags Directory
+// @summary Directory Users
+// @produce application/json
+// @param data query system.DirectoryQueryParam true "DirectoryQueryParam"
+// @success 200 {object} echox.Response{data=[]system.DirectoryUserVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 401 {object} echox.Response "unauthorized"
+// @router /api/v1/directory/users [get]
+func (a DirectoryController) Users(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	param := new(system.DirectoryQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	fields, err := a.directoryService.GetFields(claims, a.userService.IsSuperAdminClaims(claims))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.directoryService.QueryUsers(param, fields)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
@@ -1,31 +1,62 @@
 package controller
 
 import (
-	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/str"
 )
 
 type DownloadController struct {
-	downloadService service.DownloadService
-	logger          lib.Logger
+	downloadService   service.DownloadService
+	usageService      service.DownloadUsageService
+	mediaService      service.DownloadMediaService
+	healthService     service.DownloadHealthService
+	dlConfigService   service.DownloaderConfigService
+	savedQueryService service.SavedQueryService
+	userService       service.UserService
+	permissionService service.PermissionService
+	dataScopeService  service.DataScopeService
+	exportService     service.ExportService
+	logger            lib.Logger
 }
 
 // NewDownloadController creates new download controller
 func NewDownloadController(
 	logger lib.Logger,
 	downloadService service.DownloadService,
+	usageService service.DownloadUsageService,
+	mediaService service.DownloadMediaService,
+	healthService service.DownloadHealthService,
+	dlConfigService service.DownloaderConfigService,
+	savedQueryService service.SavedQueryService,
+	userService service.UserService,
+	permissionService service.PermissionService,
+	dataScopeService service.DataScopeService,
+	exportService service.ExportService,
 ) DownloadController {
 	return DownloadController{
-		logger:          logger,
-		downloadService: downloadService,
+		logger:            logger,
+		downloadService:   downloadService,
+		usageService:      usageService,
+		mediaService:      mediaService,
+		healthService:     healthService,
+		dlConfigService:   dlConfigService,
+		savedQueryService: savedQueryService,
+		userService:       userService,
+		permissionService: permissionService,
+		dataScopeService:  dataScopeService,
+		exportService:     exportService,
 	}
 }
 
@@ -34,15 +65,29 @@ func NewDownloadController(
 // @summary Download Task Query
 // @produce application/json
 // @param data query system.DownloadTaskQueryParam true "DownloadTaskQueryParam"
+// @param savedQueryId query int false "保存查询ID，合并保存的查询条件与排序"
 // @success 200 {object} echox.Response{data=[]system.DownloadTaskPageVO} "ok"
 // @failure 400 {object} echox.Response "bad request"
 // @failure 500 {object} echox.Response "internal error"
 // @router /api/v1/downloads [get]
 func (a DownloadController) Query(ctx echo.Context) error {
+	if err := applySavedQuery(ctx, a.savedQueryService, system.SavedQueryResourceDownload); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	param := new(system.DownloadTaskQueryParam)
 	if err := ctx.Bind(param); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
+	if v := ctx.QueryParam("tagIds"); v != "" {
+		param.TagIDs = str.S(v).Uint64s(",")
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
 
 	qr, err := a.downloadService.Query(param)
 	if err != nil {
@@ -56,10 +101,53 @@ func (a DownloadController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
 
+// Export 按查询条件导出下载任务，导出完成后推送下载地址
+// @tags Download
+// @summary Download Task Export
+// @produce application/json
+// @param data query system.DownloadTaskQueryParam true "DownloadTaskQueryParam"
+// @param savedQueryId query int false "保存查询ID，合并保存的查询条件与排序"
+// @param format query string false "导出格式：xlsx（默认）、csv"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/downloads/export [post]
+func (a DownloadController) Export(ctx echo.Context) error {
+	if err := applySavedQuery(ctx, a.savedQueryService, system.SavedQueryResourceDownload); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.DownloadTaskQueryParam)
+	if err := bindExportQuery(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	if v := ctx.QueryParam("tagIds"); v != "" {
+		param.TagIDs = str.S(v).Uint64s(",")
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
+
+	return submitExport(ctx, a.exportService, service.ExportSourceDownloadTask, param)
+}
+
+// checkDataScope 检查下载任务是否在当前身份的数据权限范围内，超出范围按不存在处理
+func (a DownloadController) checkDataScope(ctx echo.Context, ids ...uint64) error {
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return err
+	}
+
+	return a.downloadService.CheckDataScope(scope, ids...)
+}
+
 // Get 获取下载任务详情
 // @tags Download
 // @summary Get Download Task by ID
@@ -76,7 +164,11 @@ func (a DownloadController) Get(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
 	}
 
-	detail, err := a.downloadService.GetDetail(context.Background(), id)
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	detail, err := a.downloadService.GetDetail(ctx.Request().Context(), id)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
@@ -84,14 +176,19 @@ func (a DownloadController) Get(ctx echo.Context) error {
 	return echox.Response{Code: http.StatusOK, Data: detail}.JSON(ctx)
 }
 
-// Create 创建下载任务
+// Create 创建下载任务，dryRun=true 时只返回预检结果；指定 priority 需要 sys:download:priority 权限
+// 以 multipart/form-data 提交时可上传 .torrent / .metalink 文件代替下载地址
 // @tags Download
 // @summary Create Download Task
-// @accept application/json
+// @accept application/json,multipart/form-data
 // @produce application/json
 // @param data body system.DownloadTaskCreateForm true "DownloadTaskCreateForm"
+// @param file formData file false ".torrent / .metalink 文件，multipart 提交时与 url 二选一"
+// @param options formData string false "下载选项 JSON，multipart 提交时使用"
+// @param dryRun query bool false "仅预检，不创建任务，返回 system.DownloadDryRunVO"
 // @success 200 {object} echox.Response{data=system.DownloadTaskPageVO} "ok"
 // @failure 400 {object} echox.Response "bad request"
+// @failure 403 {object} echox.Response "priority not allowed"
 // @failure 500 {object} echox.Response "internal error"
 // @router /api/v1/downloads [post]
 func (a DownloadController) Create(ctx echo.Context) error {
@@ -99,6 +196,17 @@ func (a DownloadController) Create(ctx echo.Context) error {
 	if err := ctx.Bind(form); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
+	// 上传文件时下载选项以 JSON 字符串提交
+	if form.File != nil {
+		if raw := ctx.FormValue("options"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &form.Options); err != nil {
+				return echox.Response{Code: http.StatusBadRequest, Message: "options must be a JSON object"}.JSON(ctx)
+			}
+		}
+	}
+	if form.Priority > 0 && !a.canPrioritize(ctx) {
+		return echox.Response{Code: http.StatusForbidden, Message: errors.DownloadPriorityForbidden}.JSON(ctx)
+	}
 
 	// 获取当前用户ID
 	var ownerID uint64 = 0
@@ -106,7 +214,15 @@ func (a DownloadController) Create(ctx echo.Context) error {
 		ownerID = userID.(uint64)
 	}
 
-	task, err := a.downloadService.Create(context.Background(), form, ownerID)
+	if dryRun, _ := strconv.ParseBool(ctx.QueryParam("dryRun")); dryRun || form.DryRun {
+		result, err := a.downloadService.DryRun(ctx.Request().Context(), form, ownerID)
+		if err != nil {
+			return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+		}
+		return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+	}
+
+	task, err := a.downloadService.Create(ctx.Request().Context(), form, ownerID)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
@@ -155,7 +271,65 @@ func (a DownloadController) Cancel(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
 	}
 
-	if err := a.downloadService.Cancel(context.Background(), id); err != nil {
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	if err := a.downloadService.Cancel(ctx.Request().Context(), id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Pause 暂停下载任务
+// @tags Download
+// @summary Pause Download Task
+// @produce application/json
+// @param id path int true "Task ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/{id}/pause [post]
+func (a DownloadController) Pause(ctx echo.Context) error {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	if err := a.downloadService.Pause(ctx.Request().Context(), id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Resume 恢复已暂停的下载任务
+// @tags Download
+// @summary Resume Download Task
+// @produce application/json
+// @param id path int true "Task ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/{id}/resume [post]
+func (a DownloadController) Resume(ctx echo.Context) error {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	if err := a.downloadService.Resume(ctx.Request().Context(), id); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
@@ -180,18 +354,190 @@ func (a DownloadController) SetFiles(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
 	}
 
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
 	form := new(system.SetFileDownloadForm)
 	if err := ctx.Bind(form); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	if err := a.downloadService.SetFilesToDownload(context.Background(), id, form); err != nil {
+	if err := a.downloadService.SetFilesToDownload(ctx.Request().Context(), id, form); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
 
+// SetSpeedLimit 设置任务限速
+// @tags Download
+// @summary Set Download Task Speed Limit
+// @accept application/json
+// @produce application/json
+// @param id path int true "Task ID"
+// @param data body system.DownloadSpeedLimitForm true "DownloadSpeedLimitForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/{id}/limits [put]
+func (a DownloadController) SetSpeedLimit(ctx echo.Context) error {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.DownloadSpeedLimitForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.downloadService.SetSpeedLimit(ctx.Request().Context(), id, form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// ListArchive 浏览已完成任务中压缩包的内容
+// @tags Download
+// @summary List Archive Entries
+// @produce application/json
+// @param id path int true "Task ID"
+// @param data query system.DownloadArchiveQueryParam true "DownloadArchiveQueryParam"
+// @success 200 {object} echox.Response{data=system.DownloadArchiveVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/{id}/archive [get]
+func (a DownloadController) ListArchive(ctx echo.Context) error {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.DownloadArchiveQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	vo, err := a.downloadService.ListArchive(id, param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: vo}.JSON(ctx)
+}
+
+// ExtractArchive 解压压缩包中选中的条目到任务保存目录
+// @tags Download
+// @summary Extract Archive Entries
+// @accept application/json
+// @produce application/json
+// @param id path int true "Task ID"
+// @param data body system.DownloadArchiveExtractForm true "DownloadArchiveExtractForm"
+// @success 200 {object} echox.Response{data=system.DownloadArchiveExtractVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/{id}/archive/extract [post]
+func (a DownloadController) ExtractArchive(ctx echo.Context) error {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.DownloadArchiveExtractForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	var ownerID uint64
+	if userID := ctx.Get("userID"); userID != nil {
+		ownerID = userID.(uint64)
+	}
+
+	vo, err := a.downloadService.ExtractArchive(ctx.Request().Context(), id, form, ownerID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: vo}.JSON(ctx)
+}
+
+// ExtractMedia 重新提取已完成任务中音视频文件的元数据与缩略图
+// @tags Download
+// @summary Extract Download Media Metadata
+// @produce application/json
+// @param id path int true "Task ID"
+// @success 200 {object} echox.Response{data=system.DownloadMediaExtractVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/{id}/media [post]
+func (a DownloadController) ExtractMedia(ctx echo.Context) error {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	vo, err := a.downloadService.ExtractMedia(ctx.Request().Context(), id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: vo}.JSON(ctx)
+}
+
+// QueryMedia 媒体库：分页查询已完成任务中的音视频文件
+// @tags Download
+// @summary Download Media Library
+// @produce application/json
+// @param data query system.DownloadMediaQueryParam true "DownloadMediaQueryParam"
+// @success 200 {object} echox.Response{data=[]system.DownloadMediaVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/media [get]
+func (a DownloadController) QueryMedia(ctx echo.Context) error {
+	param := new(system.DownloadMediaQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.mediaService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
 // Sync 同步任务状态
 // @tags Download
 // @summary Sync Download Task Status
@@ -208,7 +554,11 @@ func (a DownloadController) Sync(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
 	}
 
-	if err := a.downloadService.SyncTaskStatus(context.Background(), id); err != nil {
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	if err := a.downloadService.SyncTaskStatus(ctx.Request().Context(), id); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
@@ -241,7 +591,11 @@ func (a DownloadController) Delete(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task IDs"}.JSON(ctx)
 	}
 
-	if err := a.downloadService.BatchDelete(context.Background(), ids); err != nil {
+	if err := a.checkDataScope(ctx, ids...); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	if err := a.downloadService.BatchDelete(ctx.Request().Context(), ids); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
@@ -278,6 +632,18 @@ func (a DownloadController) GetDownloaders(ctx echo.Context) error {
 	return echox.Response{Code: http.StatusOK, Data: downloaders}.JSON(ctx)
 }
 
+// GetDownloaderHealth 各下载器最近一次健康检查的结果
+// @tags Download
+// @summary Downloader Health
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.DownloaderHealthVO} "ok"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/downloaders/health [get]
+func (a DownloadController) GetDownloaderHealth(ctx echo.Context) error {
+	health := a.healthService.List(ctx.Request().Context())
+	return echox.Response{Code: http.StatusOK, Data: health}.JSON(ctx)
+}
+
 // TestDownloader 测试下载器连接
 // @tags Download
 // @summary Test Downloader Connection
@@ -289,10 +655,245 @@ func (a DownloadController) GetDownloaders(ctx echo.Context) error {
 // @router /api/v1/downloads/test/{name} [get]
 func (a DownloadController) TestDownloader(ctx echo.Context) error {
 	name := ctx.Param("name")
-	version, err := a.downloadService.TestDownloader(context.Background(), name)
+	version, err := a.downloadService.TestDownloader(ctx.Request().Context(), name)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
 	return echox.Response{Code: http.StatusOK, Data: map[string]string{"version": version}}.JSON(ctx)
 }
+
+// GetDownloaderConfigs 运行时管理的下载器配置列表，不包含密钥
+// @tags Download
+// @summary Downloader Config List
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.DownloaderConfig} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/downloads/downloaders/configs [get]
+func (a DownloadController) GetDownloaderConfigs(ctx echo.Context) error {
+	list, err := a.dlConfigService.List()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// CreateDownloader 新增下载器，保存后立即加载
+// 下载器从数据库重新加载配置，因此不使用请求的事务
+// @tags Download
+// @summary Downloader Config Create
+// @produce application/json
+// @param data body system.DownloaderConfigForm true "DownloaderConfigForm"
+// @success 200 {object} echox.Response{data=system.DownloaderConfig} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/downloads/downloaders [post]
+func (a DownloadController) CreateDownloader(ctx echo.Context) error {
+	form := new(system.DownloaderConfigForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	conf, err := a.dlConfigService.Create(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: conf}.JSON(ctx)
+}
+
+// UpdateDownloader 修改下载器，token、password 为空时保持不变，保存后立即重新加载
+// @tags Download
+// @summary Downloader Config Update By ID
+// @produce application/json
+// @param id path int true "下载器配置ID"
+// @param data body system.DownloaderConfigForm true "DownloaderConfigForm"
+// @success 200 {object} echox.Response{data=system.DownloaderConfig} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/downloads/downloaders/{id} [put]
+func (a DownloadController) UpdateDownloader(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.DownloaderConfigForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var updateBy uint64
+	if claims != nil {
+		updateBy = claims.ID
+	}
+
+	conf, err := a.dlConfigService.Update(id, form, updateBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: conf}.JSON(ctx)
+}
+
+// DeleteDownloader 删除下载器，有活跃任务时拒绝
+// @tags Download
+// @summary Downloader Config Delete By ID
+// @produce application/json
+// @param id path int true "下载器配置ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @failure 409 {object} echox.Response "downloader in use"
+// @router /api/v1/downloads/downloaders/{id} [delete]
+func (a DownloadController) DeleteDownloader(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.dlConfigService.Delete(id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// GetSession 查询下载器当前会话
+// @tags Download
+// @summary Downloader Session
+// @produce application/json
+// @param name path string true "Downloader Name"
+// @success 200 {object} echox.Response{data=system.DownloadSessionVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 504 {object} echox.Response "downloader timeout"
+// @router /api/v1/downloads/session/{name} [get]
+func (a DownloadController) GetSession(ctx echo.Context) error {
+	session, err := a.downloadService.GetSession(ctx.Request().Context(), ctx.Param("name"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: session}.JSON(ctx)
+}
+
+// SaveSession 立即保存下载器会话
+// @tags Download
+// @summary Save Downloader Session
+// @produce application/json
+// @param name path string true "Downloader Name"
+// @success 200 {object} echox.Response{data=system.DownloadSessionVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 504 {object} echox.Response "downloader timeout"
+// @router /api/v1/downloads/session/{name}/save [post]
+func (a DownloadController) SaveSession(ctx echo.Context) error {
+	session, err := a.downloadService.SaveSession(ctx.Request().Context(), ctx.Param("name"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: session}.JSON(ctx)
+}
+
+// ReconcileSession 对比下载器中的任务与下载任务表，导入缺失的任务并报告下载器中已不存在的活跃任务
+// @tags Download
+// @summary Reconcile Downloader Session
+// @accept application/json
+// @produce application/json
+// @param name path string true "Downloader Name"
+// @param data body system.DownloadSessionReconcileForm true "DownloadSessionReconcileForm"
+// @success 200 {object} echox.Response{data=system.DownloadSessionReconcileVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 504 {object} echox.Response "downloader timeout"
+// @router /api/v1/downloads/session/{name}/reconcile [post]
+func (a DownloadController) ReconcileSession(ctx echo.Context) error {
+	form := new(system.DownloadSessionReconcileForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	result, err := a.downloadService.ReconcileSession(ctx.Request().Context(), ctx.Param("name"), form, claims.ID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// UsageMe 当前用户的下载流量统计与配额
+// @tags Download
+// @summary My Download Usage
+// @produce application/json
+// @param data query system.DownloadUsageQueryParam true "DownloadUsageQueryParam"
+// @success 200 {object} echox.Response{data=system.DownloadUsageMeVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/usage/me [get]
+func (a DownloadController) UsageMe(ctx echo.Context) error {
+	param := new(system.DownloadUsageQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	var userID uint64
+	if v := ctx.Get("userID"); v != nil {
+		userID = v.(uint64)
+	}
+
+	usage, err := a.usageService.Me(userID, param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: usage}.JSON(ctx)
+}
+
+// Usage 按用户或部门汇总的下载流量
+// @tags Download
+// @summary Download Usage Breakdown
+// @produce application/json
+// @param data query system.DownloadUsageQueryParam true "DownloadUsageQueryParam"
+// @success 200 {object} echox.Response{data=[]system.DownloadUsageUserVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/downloads/usage [get]
+func (a DownloadController) Usage(ctx echo.Context) error {
+	param := new(system.DownloadUsageQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	list, err := a.usageService.Breakdown(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// canPrioritize 当前用户是否可以指定下载任务的队列优先级
+func (a DownloadController) canPrioritize(ctx echo.Context) bool {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return false
+	}
+	if a.userService.IsSuperAdminClaims(claims) {
+		return true
+	}
+
+	perms, err := a.permissionService.GetClaimsPerms(claims)
+	if err != nil {
+		return false
+	}
+	return service.MatchPerm(perms, "sys:download:priority")
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// LockController 资源锁控制器
+type LockController struct {
+	logger      lib.Logger
+	lockService service.LockService
+}
+
+// NewLockController creates new lock controller
+func NewLockController(
+	logger lib.Logger,
+	lockService service.LockService,
+) LockController {
+	return LockController{
+		logger:      logger,
+		lockService: lockService,
+	}
+}
+
+// List 当前持有的锁
+// @tags Lock
+// @summary Lock List
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.Lock} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/locks [get]
+func (a LockController) List(ctx echo.Context) error {
+	list, err := a.lockService.List()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Get 查询锁的持有者，未被持有时返回 404
+// @tags Lock
+// @summary Lock Get By Name
+// @produce application/json
+// @param name path string true "锁名称"
+// @success 200 {object} echox.Response{data=system.Lock} "ok"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/locks/{name} [get]
+func (a LockController) Get(ctx echo.Context) error {
+	lock, err := a.lockService.Get(ctx.Param("name"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: lock}.JSON(ctx)
+}
+
+// Acquire 获取锁，已被持有时返回 409 及持有者信息；返回的 token 用于释放
+// @tags Lock
+// @summary Lock Acquire
+// @accept application/json
+// @produce application/json
+// @param name path string true "锁名称"
+// @param data body system.LockAcquireForm true "LockAcquireForm"
+// @success 200 {object} echox.Response{data=system.LockAcquireVO} "ok"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/locks/{name} [post]
+func (a LockController) Acquire(ctx echo.Context) error {
+	form := new(system.LockAcquireForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	ttl := time.Duration(form.TTL) * time.Second
+	lock, err := a.lockService.Acquire(ctx.Param("name"), claims.ID, form.Reason, ttl)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: &system.LockAcquireVO{Lock: lock, Token: lock.Token}}.JSON(ctx)
+}
+
+// Release 使用获取时返回的 token 释放锁
+// @tags Lock
+// @summary Lock Release
+// @produce application/json
+// @param name path string true "锁名称"
+// @param token query string true "获取锁时返回的 token"
+// @success 200 {object} echox.Response "ok"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/locks/{name} [delete]
+func (a LockController) Release(ctx echo.Context) error {
+	param := new(system.LockReleaseParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.lockService.Release(ctx.Param("name"), param.Token); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// ForceRelease 强制释放锁，用于持有者异常退出后提前解除
+// @tags Lock
+// @summary Lock Force Release
+// @produce application/json
+// @param name path string true "锁名称"
+// @success 200 {object} echox.Response "ok"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/locks/{name}/force [delete]
+func (a LockController) ForceRelease(ctx echo.Context) error {
+	name := ctx.Param("name")
+	if err := a.lockService.ForceRelease(name); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	a.logger.Zap.Infof("Lock %s force released by %s", name, claims.Username)
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
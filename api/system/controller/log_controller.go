@@ -11,18 +11,33 @@ import (
 )
 
 type LogController struct {
-	logService service.LogService
-	logger     lib.Logger
+	logService          service.LogService
+	loginGuardService   service.LoginGuardService
+	operationLogService service.OperationLogService
+	savedQueryService   service.SavedQueryService
+	dataScopeService    service.DataScopeService
+	exportService       service.ExportService
+	logger              lib.Logger
 }
 
 // NewLogController creates new log controller
 func NewLogController(
 	logger lib.Logger,
 	logService service.LogService,
+	loginGuardService service.LoginGuardService,
+	operationLogService service.OperationLogService,
+	savedQueryService service.SavedQueryService,
+	dataScopeService service.DataScopeService,
+	exportService service.ExportService,
 ) LogController {
 	return LogController{
-		logger:     logger,
-		logService: logService,
+		logger:              logger,
+		logService:          logService,
+		loginGuardService:   loginGuardService,
+		operationLogService: operationLogService,
+		savedQueryService:   savedQueryService,
+		dataScopeService:    dataScopeService,
+		exportService:       exportService,
 	}
 }
 
@@ -30,16 +45,27 @@ func NewLogController(
 // @summary Log Query
 // @produce application/json
 // @param data query system.LogQueryParam true "LogQueryParam"
+// @param savedQueryId query int false "保存查询ID，合并保存的查询条件与排序"
 // @success 200 {object} echox.Response{data=[]system.LogPageVO} "ok"
 // @failure 400 {object} echox.Response "bad request"
 // @failure 500 {object} echox.Response "internal error"
 // @router /api/v1/logs [get]
 func (a LogController) Query(ctx echo.Context) error {
+	if err := applySavedQuery(ctx, a.savedQueryService, system.SavedQueryResourceLog); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	param := new(system.LogQueryParam)
 	if err := ctx.Bind(param); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
+
 	qr, err := a.logService.Query(param)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
@@ -52,6 +78,109 @@ func (a LogController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// @tags Log
+// @summary Log Export
+// @produce application/json
+// @param data query system.LogQueryParam true "LogQueryParam"
+// @param savedQueryId query int false "保存查询ID，合并保存的查询条件与排序"
+// @param format query string false "导出格式：xlsx（默认）、csv"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/logs/export [post]
+func (a LogController) Export(ctx echo.Context) error {
+	if err := applySavedQuery(ctx, a.savedQueryService, system.SavedQueryResourceLog); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.LogQueryParam)
+	if err := bindExportQuery(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
+
+	return submitExport(ctx, a.exportService, service.ExportSourceLog, param)
+}
+
+// @tags Log
+// @summary 登录日志查询，包含成功与失败的登录尝试
+// @produce application/json
+// @param data query system.LoginLogQueryParam true "LoginLogQueryParam"
+// @success 200 {object} echox.Response{data=system.LoginLogs} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/logs/login [get]
+func (a LogController) QueryLogin(ctx echo.Context) error {
+	param := new(system.LoginLogQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
+
+	qr, err := a.loginGuardService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// @tags Log
+// @summary 操作审计日志查询，包含所有修改类请求
+// @produce application/json
+// @param data query system.OperationLogQueryParam true "OperationLogQueryParam"
+// @success 200 {object} echox.Response{data=system.OperationLogs} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/logs/operations [get]
+func (a LogController) QueryOperations(ctx echo.Context) error {
+	param := new(system.OperationLogQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
+
+	qr, err := a.operationLogService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// MailTemplateController 邮件模板控制器
+type MailTemplateController struct {
+	logger      lib.Logger
+	mailService service.MailService
+}
+
+// NewMailTemplateController creates new mail template controller
+func NewMailTemplateController(
+	logger lib.Logger,
+	mailService service.MailService,
+) MailTemplateController {
+	return MailTemplateController{
+		logger:      logger,
+		mailService: mailService,
+	}
+}
+
+// Query 邮件模板分页列表
+// @tags MailTemplate
+// @summary MailTemplate Query
+// @produce application/json
+// @param data query system.MailTemplateQueryParam true "MailTemplateQueryParam"
+// @success 200 {object} echox.Response{data=[]system.MailTemplate} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/mail-templates [get]
+func (a MailTemplateController) Query(ctx echo.Context) error {
+	param := new(system.MailTemplateQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.mailService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Get 邮件模板详情
+// @tags MailTemplate
+// @summary MailTemplate Get By ID
+// @produce application/json
+// @param id path int true "模板ID"
+// @success 200 {object} echox.Response{data=system.MailTemplate} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/mail-templates/{id} [get]
+func (a MailTemplateController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	template, err := a.mailService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: template}.JSON(ctx)
+}
+
+// Create 新增邮件模板，主题与正文须为合法的 Go 模板
+// @tags MailTemplate
+// @summary MailTemplate Create
+// @produce application/json
+// @param data body system.MailTemplateForm true "MailTemplateForm"
+// @success 200 {object} echox.Response{data=uint64} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/mail-templates [post]
+func (a MailTemplateController) Create(ctx echo.Context) error {
+	form := new(system.MailTemplateForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	id, err := a.mailService.WithTrx(trxHandle).Create(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: id}.JSON(ctx)
+}
+
+// Update 修改邮件模板
+// @tags MailTemplate
+// @summary MailTemplate Update By ID
+// @produce application/json
+// @param id path int true "模板ID"
+// @param data body system.MailTemplateForm true "MailTemplateForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/mail-templates/{id} [put]
+func (a MailTemplateController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.MailTemplateForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var updateBy uint64
+	if claims != nil {
+		updateBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.mailService.WithTrx(trxHandle).Update(id, form, updateBy); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除邮件模板，内置编码删除后恢复使用内置模板
+// @tags MailTemplate
+// @summary MailTemplate Delete By ID
+// @produce application/json
+// @param id path int true "模板ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/mail-templates/{id} [delete]
+func (a MailTemplateController) Delete(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.mailService.WithTrx(trxHandle).Delete(id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Preview 使用给定数据渲染模板，不保存
+// @tags MailTemplate
+// @summary MailTemplate Preview
+// @produce application/json
+// @param data body system.MailTemplatePreviewForm true "MailTemplatePreviewForm"
+// @success 200 {object} echox.Response{data=system.MailPreview} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/mail-templates/preview [post]
+func (a MailTemplateController) Preview(ctx echo.Context) error {
+	form := new(system.MailTemplatePreviewForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	preview, err := a.mailService.Preview(form)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: preview}.JSON(ctx)
+}
+
+// Test 使用模板发送测试邮件
+// @tags MailTemplate
+// @summary MailTemplate Send Test Mail
+// @produce application/json
+// @param id path int true "模板ID"
+// @param data body system.MailTemplateTestForm true "MailTemplateTestForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 503 {object} echox.Response "mail disabled"
+// @router /api/v1/mail-templates/{id}/test [post]
+func (a MailTemplateController) Test(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.MailTemplateTestForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	var owner *queue.TaskOwner
+	if claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); claims != nil {
+		owner = &queue.TaskOwner{ID: claims.ID, Username: claims.Username}
+	}
+
+	if err := a.mailService.SendTest(id, form, owner); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// MaintenanceController 运维控制器
+type MaintenanceController struct {
+	logger         lib.Logger
+	config         lib.Config
+	readOnly       lib.ReadOnly
+	logShipper     lib.LogShipper
+	profileService service.ProfileService
+
+	featureModuleService service.FeatureModuleService
+}
+
+// NewMaintenanceController creates new maintenance controller
+func NewMaintenanceController(
+	logger lib.Logger,
+	config lib.Config,
+	readOnly lib.ReadOnly,
+	logShipper lib.LogShipper,
+	profileService service.ProfileService,
+	featureModuleService service.FeatureModuleService,
+) MaintenanceController {
+	return MaintenanceController{
+		logger:               logger,
+		config:               config,
+		readOnly:             readOnly,
+		logShipper:           logShipper,
+		profileService:       profileService,
+		featureModuleService: featureModuleService,
+	}
+}
+
+// GetConfig 获取生效配置（已脱敏）
+// @Tags Maintenance
+// @Summary 获取生效配置
+// @Produce application/json
+// @Success 200 {object} echox.Response{data=map[string]interface{}} "ok"
+// @Router /api/v1/maintenance/config [get]
+func (a MaintenanceController) GetConfig(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: lib.DumpConfig(a.config, true)}.JSON(ctx)
+}
+
+// GetReadOnly 获取只读模式状态
+// @Tags Maintenance
+// @Summary 获取只读模式状态
+// @Produce application/json
+// @Success 200 {object} echox.Response{data=lib.ReadOnlyStatus} "ok"
+// @Router /api/v1/maintenance/read-only [get]
+func (a MaintenanceController) GetReadOnly(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: a.readOnly.Status()}.JSON(ctx)
+}
+
+// SetReadOnly 切换只读模式
+// @Tags Maintenance
+// @Summary 切换只读模式
+// @Accept application/json
+// @Produce application/json
+// @Param data body dto.ReadOnlyForm true "只读模式"
+// @Success 200 {object} echox.Response{data=lib.ReadOnlyStatus} "ok"
+// @Router /api/v1/maintenance/read-only [put]
+func (a MaintenanceController) SetReadOnly(ctx echo.Context) error {
+	form := new(dto.ReadOnlyForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if form.Enable {
+		a.readOnly.Enable(form.Reason)
+	} else {
+		a.readOnly.Disable()
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: a.readOnly.Status()}.JSON(ctx)
+}
+
+// GetModules 获取功能模块及启用状态，前端据此隐藏已禁用模块的入口
+// @Tags Maintenance
+// @Summary 功能模块列表
+// @Produce application/json
+// @Success 200 {object} echox.Response{data=[]lib.FeatureModule} "ok"
+// @Router /api/v1/maintenance/modules [get]
+func (a MaintenanceController) GetModules(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: a.featureModuleService.List()}.JSON(ctx)
+}
+
+// SetModule 启用或禁用功能模块
+// @Tags Maintenance
+// @Summary 功能模块启停
+// @Accept application/json
+// @Produce application/json
+// @Param name path string true "模块名称：download、notice、websocket、file"
+// @Param data body dto.FeatureModuleForm true "启用状态"
+// @Success 200 {object} echox.Response{data=lib.FeatureModule} "ok"
+// @Failure 404 {object} echox.Response "feature module not found"
+// @Router /api/v1/maintenance/modules/{name} [put]
+func (a MaintenanceController) SetModule(ctx echo.Context) error {
+	form := new(dto.FeatureModuleForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var operator uint64
+	if claims != nil {
+		operator = claims.ID
+	}
+
+	module, err := a.featureModuleService.SetEnabled(ctx.Param("name"), form.Enabled, operator)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: module}.JSON(ctx)
+}
+
+// GetLogShipping 获取日志转发各接收端的统计
+// @Tags Maintenance
+// @Summary 日志转发统计
+// @Produce application/json
+// @Success 200 {object} echox.Response{data=[]logship.Stats} "ok"
+// @Router /api/v1/maintenance/log-shipping [get]
+func (a MaintenanceController) GetLogShipping(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: a.logShipper.Stats()}.JSON(ctx)
+}
+
+// CreateProfile 采集性能分析（cpu、trace 在后台采集）
+// @Tags Maintenance
+// @Summary 采集性能分析
+// @Accept application/json
+// @Produce application/json
+// @Param data body dto.ProfileForm true "采集参数"
+// @Success 200 {object} echox.Response{data=dto.ProfileCapture} "ok"
+// @Failure 409 {object} echox.Response "another cpu profile or trace is in progress"
+// @Router /api/v1/maintenance/profiles [post]
+func (a MaintenanceController) CreateProfile(ctx echo.Context) error {
+	form := new(dto.ProfileForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	record, err := a.profileService.Capture(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: record}.JSON(ctx)
+}
+
+// QueryProfiles 获取当前实例的性能分析采集记录
+// @Tags Maintenance
+// @Summary 性能分析采集记录
+// @Produce application/json
+// @Success 200 {object} echox.Response{data=[]dto.ProfileCapture} "ok"
+// @Router /api/v1/maintenance/profiles [get]
+func (a MaintenanceController) QueryProfiles(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: a.profileService.List()}.JSON(ctx)
+}
+
+// DownloadProfile 下载已采集的性能分析文件，可直接用 go tool pprof / go tool trace 打开
+// @Tags Maintenance
+// @Summary 下载性能分析文件
+// @Produce application/octet-stream
+// @Param id path int true "采集记录ID"
+// @Success 200 {file} file "profile"
+// @Failure 409 {object} echox.Response "profile is not ready"
+// @Router /api/v1/maintenance/profiles/{id}/download [get]
+func (a MaintenanceController) DownloadProfile(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	record, object, err := a.profileService.Open(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	defer object.Reader.Close()
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": record.FileName}))
+	header.Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	http.ServeContent(ctx.Response(), ctx.Request(), record.FileName, object.ModTime, object.Reader)
+	return nil
+}
@@ -1,11 +1,15 @@
 package controller
 
 import (
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/top-system/light-admin/api/system/service"
 	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/models/dto"
@@ -143,9 +147,12 @@ func (a MenuController) Update(ctx echo.Context) error {
 
 // @tags Menu
 // @summary Menu Delete By ID
+// @description cascade=true 删除整棵子树及其角色授权，reparent=true 将子节点挂到上级后删除，二者不能同时使用
 // @produce application/json
 // @param id path int true "menu id"
-// @success 200 {object} echox.Response "ok"
+// @param cascade query bool false "delete the whole subtree"
+// @param reparent query bool false "move children to the parent menu"
+// @success 200 {object} echox.Response{data=system.MenuDeletePreview} "ok"
 // @failure 400 {object} echox.Response "bad request"
 // @failure 500 {object} echox.Response "internal error"
 // @router /api/v1/menus/{id} [delete]
@@ -155,14 +162,102 @@ func (a MenuController) Delete(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	cascade := ctx.QueryParam("cascade") == "true"
+	reparent := ctx.QueryParam("reparent") == "true"
+	if cascade && reparent {
+		return echox.Response{Code: http.StatusBadRequest, Message: errors.MenuInvalidDeleteMode}.JSON(ctx)
+	}
+
+	mode := system.MenuDeleteModeDefault
+	if cascade {
+		mode = system.MenuDeleteModeCascade
+	} else if reparent {
+		mode = system.MenuDeleteModeReparent
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	result, err := a.menuService.WithTrx(trxHandle).DeleteWithMode(id, mode)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// Export 导出全部菜单（config/menu.yaml 格式），用于在环境之间迁移菜单结构
+// @tags Menu
+// @summary Menu Export
+// @produce application/x-yaml
+// @success 200 {file} file "menu.yaml"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/menus/export [get]
+func (a MenuController) Export(ctx echo.Context) error {
+	data, err := a.menuService.Export()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	filename := "menu-" + time.Now().Format("20060102150405") + ".yaml"
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return ctx.Blob(http.StatusOK, "application/x-yaml; charset=utf-8", data)
+}
+
+// Import 导入菜单文件（config/menu.yaml 格式）并合并到现有菜单，已存在的菜单跳过
+// @tags Menu
+// @summary Menu Import
+// @accept multipart/form-data
+// @produce application/json
+// @param file formData file true "菜单文件"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/menus/import [post]
+func (a MenuController) Import(ctx echo.Context) error {
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "file is required"}.JSON(ctx)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
-	if err := a.menuService.WithTrx(trxHandle).Delete(id); err != nil {
+	if err := a.menuService.WithTrx(trxHandle).Import(data, claims.ID); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
 
+// @tags Menu
+// @summary Menu Delete Preview
+// @produce application/json
+// @param id path int true "menu id"
+// @success 200 {object} echox.Response{data=system.MenuDeletePreview} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/menus/{id}/delete-preview [get]
+func (a MenuController) DeletePreview(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	preview, err := a.menuService.DeletePreview(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: preview}.JSON(ctx)
+}
+
 // @tags Menu
 // @summary Get Menu Options
 // @produce application/json
@@ -203,7 +298,7 @@ func (a MenuController) Routes(ctx echo.Context) error {
 	}
 
 	// 检查是否是超级管理员
-	isSuperAdmin := claims.Username == "root" || claims.Username == "admin"
+	isSuperAdmin := a.userService.IsSuperAdminClaims(claims)
 
 	// 使用 menuService.GetUserRoutes 获取正确格式的路由
 	routes, err := a.menuService.GetUserRoutes(roleIDs, isSuperAdmin)
@@ -213,3 +308,38 @@ func (a MenuController) Routes(ctx echo.Context) error {
 
 	return echox.Response{Code: http.StatusOK, Data: routes}.JSON(ctx)
 }
+
+// RoutesDelta 路由菜单增量同步
+// version 为上次同步返回的版本号，为空或可见菜单范围变化时返回全量；
+// 同时支持 If-None-Match（ETag 即版本号），未变化时返回 304
+// @Tags Menu
+// @Summary 路由菜单增量同步
+// @Produce application/json
+// @Param version query string false "上次同步返回的版本号"
+// @Success 200 {object} echox.Response{data=system.MenuDeltaVO} "ok"
+// @Success 304 "not modified"
+// @Router /api/v1/menus/routes/delta [get]
+func (a MenuController) RoutesDelta(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || claims == nil {
+		return echox.Response{Code: http.StatusUnauthorized, Message: "unauthorized"}.JSON(ctx)
+	}
+
+	roleIDs, err := a.userService.GetUserRoleIDs(claims.ID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	isSuperAdmin := a.userService.IsSuperAdminClaims(claims)
+
+	delta, err := a.menuService.GetUserRoutesDelta(roleIDs, isSuperAdmin, ctx.QueryParam("version"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if echox.SetETag(ctx, delta.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: delta}.JSON(ctx)
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// MetaController 接口元数据控制器
+type MetaController struct {
+	logger            lib.Logger
+	permissionService service.PermissionService
+	userService       service.UserService
+	apiClientService  service.ApiClientService
+}
+
+// NewMetaController creates new meta controller
+func NewMetaController(
+	logger lib.Logger,
+	permissionService service.PermissionService,
+	userService service.UserService,
+	apiClientService service.ApiClientService,
+) MetaController {
+	return MetaController{
+		logger:            logger,
+		permissionService: permissionService,
+		userService:       userService,
+		apiClientService:  apiClientService,
+	}
+}
+
+// Actions 当前用户可访问的操作描述，供前端命令面板及命令行工具生成使用
+// @tags Meta
+// @summary Available Actions
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]dto.ActionVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 401 {object} echox.Response "unauthorized"
+// @router /api/v1/meta/actions [get]
+func (a MetaController) Actions(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	actions, err := a.permissionService.GetActions(claims, a.userService.IsSuperAdminClaims(claims))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: actions}.JSON(ctx)
+}
+
+// Schema 全部接口的类型定义（JSON Schema）与接口描述，供代码生成工具使用
+// @tags Meta
+// @summary API Schema
+// @produce application/json
+// @success 200 {object} tsgen.Document "ok"
+// @failure 401 {object} echox.Response "unauthorized"
+// @router /api/v1/meta/schema [get]
+func (a MetaController) Schema(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, a.apiClientService.Document())
+}
+
+// TypeScriptClient 下载 TypeScript 类型定义与客户端
+// @tags Meta
+// @summary TypeScript Client
+// @produce text/plain
+// @success 200 {file} file "ok"
+// @failure 401 {object} echox.Response "unauthorized"
+// @router /api/v1/meta/client.ts [get]
+func (a MetaController) TypeScriptClient(ctx echo.Context) error {
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="api-client.ts"`)
+	return ctx.Blob(http.StatusOK, "application/typescript; charset=utf-8", a.apiClientService.TypeScript())
+}
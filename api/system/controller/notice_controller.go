@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"mime"
 	"net/http"
 	"strconv"
 
@@ -10,24 +11,35 @@ import (
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/file"
+	"github.com/top-system/light-admin/pkg/str"
 	"github.com/labstack/echo/v4"
 
 	"gorm.io/gorm"
 )
 
 type NoticeController struct {
-	noticeService service.NoticeService
-	logger        lib.Logger
+	noticeService      service.NoticeService
+	noticeDraftService service.NoticeDraftService
+	dataScopeService   service.DataScopeService
+	exportService      service.ExportService
+	logger             lib.Logger
 }
 
 // NewNoticeController creates new notice controller
 func NewNoticeController(
 	noticeService service.NoticeService,
+	noticeDraftService service.NoticeDraftService,
+	dataScopeService service.DataScopeService,
+	exportService service.ExportService,
 	logger lib.Logger,
 ) NoticeController {
 	return NoticeController{
-		noticeService: noticeService,
-		logger:        logger,
+		noticeService:      noticeService,
+		noticeDraftService: noticeDraftService,
+		dataScopeService:   dataScopeService,
+		exportService:      exportService,
+		logger:             logger,
 	}
 }
 
@@ -38,6 +50,7 @@ func NewNoticeController(
 // @Param title query string false "标题"
 // @Param type query int false "类型"
 // @Param publishStatus query int false "发布状态"
+// @Param tagIds query string false "标签ID，多个以英文逗号分割"
 // @Param current query int false "当前页"
 // @Param pageSize query int false "每页数量"
 // @Success 200 {object} echox.Response{data=[]system.Notice} "ok"
@@ -47,6 +60,15 @@ func (a NoticeController) Query(ctx echo.Context) error {
 	if err := ctx.Bind(param); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
+	if v := ctx.QueryParam("tagIds"); v != "" {
+		param.TagIDs = str.S(v).Uint64s(",")
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
 
 	qr, err := a.noticeService.Query(param)
 	if err != nil {
@@ -60,10 +82,50 @@ func (a NoticeController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
 
+// Export 按查询条件导出通知公告，导出完成后推送下载地址
+// @Tags Notice
+// @Summary 导出通知公告
+// @Produce application/json
+// @Param title query string false "标题"
+// @Param type query int false "类型"
+// @Param publishStatus query int false "发布状态"
+// @Param tagIds query string false "标签ID，多个以英文逗号分割"
+// @Param format query string false "导出格式：xlsx（默认）、csv"
+// @Success 200 {object} echox.Response "ok"
+// @Router /api/v1/notices/export [post]
+func (a NoticeController) Export(ctx echo.Context) error {
+	param := new(system.NoticeQueryParam)
+	if err := bindExportQuery(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	if v := ctx.QueryParam("tagIds"); v != "" {
+		param.TagIDs = str.S(v).Uint64s(",")
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.DataScope = scope
+
+	return submitExport(ctx, a.exportService, service.ExportSourceNotice, param)
+}
+
+// checkDataScope 检查通知公告是否在当前身份的数据权限范围内，超出范围按不存在处理
+func (a NoticeController) checkDataScope(ctx echo.Context, ids ...uint64) error {
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return err
+	}
+
+	return a.noticeService.CheckDataScope(scope, ids...)
+}
+
 // GetForm 获取通知公告表单数据
 // @Tags Notice
 // @Summary 获取通知公告表单数据
@@ -77,6 +139,10 @@ func (a NoticeController) GetForm(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
 	form, err := a.noticeService.GetForm(id)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
@@ -98,6 +164,10 @@ func (a NoticeController) GetDetail(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
 	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	var userID uint64
 	if claims != nil {
@@ -153,6 +223,10 @@ func (a NoticeController) Update(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
 	form := new(system.NoticeForm)
 	if err := ctx.Bind(form); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
@@ -181,6 +255,9 @@ func (a NoticeController) Update(ctx echo.Context) error {
 // @Router /api/v1/notices/{ids} [delete]
 func (a NoticeController) Delete(ctx echo.Context) error {
 	ids := ctx.Param("ids")
+	if err := a.checkDataScope(ctx, str.S(ids).Uint64s(",")...); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
 
 	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	var deletedBy uint64
@@ -209,6 +286,10 @@ func (a NoticeController) Publish(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
 	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	var publisherId uint64
 	if claims != nil {
@@ -236,6 +317,10 @@ func (a NoticeController) Revoke(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
 	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	var updatedBy uint64
 	if claims != nil {
@@ -250,6 +335,316 @@ func (a NoticeController) Revoke(ctx echo.Context) error {
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
 
+// UploadAttachment 上传通知公告附件
+// @Tags Notice
+// @Summary 上传通知公告附件
+// @Accept multipart/form-data
+// @Produce application/json
+// @Param file formData file true "附件"
+// @Success 200 {object} echox.Response{data=system.NoticeAttachmentVO} "ok"
+// @Router /api/v1/notices/attachments [post]
+func (a NoticeController) UploadAttachment(ctx echo.Context) error {
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "file is required"}.JSON(ctx)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+	defer src.Close()
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createdBy uint64
+	if claims != nil {
+		createdBy = claims.ID
+	}
+
+	vo, err := a.noticeService.UploadAttachment(fh.Filename, src, fh.Size, fh.Header.Get("Content-Type"), createdBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: vo}.JSON(ctx)
+}
+
+// DownloadAttachment 通过签名地址下载通知公告附件，无需登录，支持 Range
+// @Tags Notice
+// @Summary 下载通知公告附件
+// @Produce application/octet-stream
+// @Param id query int true "附件ID"
+// @Param expires query int true "过期时间（Unix 秒）"
+// @Param sign query string true "签名"
+// @Success 200 {file} file "ok"
+// @Router /api/v1/notices/attachments/download [get]
+func (a NoticeController) DownloadAttachment(ctx echo.Context) error {
+	param := new(system.NoticeAttachmentDownloadParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	attachment, object, err := a.noticeService.OpenAttachment(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	defer object.Reader.Close()
+
+	name := file.NormalizeFilename(attachment.FileName)
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	if object.ContentType != "" {
+		header.Set(echo.HeaderContentType, object.ContentType)
+	}
+
+	http.ServeContent(ctx.Response(), ctx.Request(), name, object.ModTime, object.Reader)
+	return nil
+}
+
+// SaveDraft 自动保存草稿
+// @Tags Notice
+// @Summary 自动保存通知公告草稿（只更新传入的字段）
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Param data body system.NoticeDraftForm true "草稿内容"
+// @Success 200 {object} echox.Response{data=system.NoticeDraftResult} "ok"
+// @Router /api/v1/notices/{id}/draft [patch]
+func (a NoticeController) SaveDraft(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.NoticeDraftForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	result, err := a.noticeDraftService.WithTrx(trxHandle).SaveDraft(id, form, claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// QueryRevisions 草稿修订记录分页列表
+// @Tags Notice
+// @Summary 草稿修订记录分页列表
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Param pageNum query int false "当前页"
+// @Param pageSize query int false "每页数量"
+// @Success 200 {object} echox.Response{data=[]system.NoticeRevision} "ok"
+// @Router /api/v1/notices/{id}/revisions [get]
+func (a NoticeController) QueryRevisions(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.NoticeRevisionQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	param.NoticeID = id
+
+	qr, err := a.noticeDraftService.QueryRevisions(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// GetRevision 获取指定版本的草稿
+// @Tags Notice
+// @Summary 获取指定版本的草稿
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} echox.Response{data=system.NoticeRevision} "ok"
+// @Router /api/v1/notices/{id}/revisions/{revision} [get]
+func (a NoticeController) GetRevision(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	revision, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	item, err := a.noticeDraftService.GetRevision(id, revision)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: item}.JSON(ctx)
+}
+
+// RestoreRevision 恢复到指定版本的草稿
+// @Tags Notice
+// @Summary 恢复到指定版本的草稿
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Param revision path int true "版本号"
+// @Success 200 {object} echox.Response{data=system.NoticeDraftResult} "ok"
+// @Router /api/v1/notices/{id}/revisions/{revision}/restore [put]
+func (a NoticeController) RestoreRevision(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	revision, err := strconv.Atoi(ctx.Param("revision"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	result, err := a.noticeDraftService.WithTrx(trxHandle).RestoreRevision(id, revision, claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// GetLock 查询编辑锁状态
+// @Tags Notice
+// @Summary 查询通知公告编辑锁状态
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Success 200 {object} echox.Response{data=system.NoticeEditLockStatus} "ok"
+// @Router /api/v1/notices/{id}/lock [get]
+func (a NoticeController) GetLock(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	status, err := a.noticeDraftService.LockStatus(id, claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: status}.JSON(ctx)
+}
+
+// Lock 获取或续期编辑锁
+// 锁由其他用户持有时返回 conflict=true 及对方信息，force=true 时强制接管
+// @Tags Notice
+// @Summary 获取或续期通知公告编辑锁
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Param force query bool false "强制接管"
+// @Success 200 {object} echox.Response{data=system.NoticeEditLockStatus} "ok"
+// @Router /api/v1/notices/{id}/lock [put]
+func (a NoticeController) Lock(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.NoticeLockParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	status, err := a.noticeDraftService.Lock(id, claims, param.Force)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: status}.JSON(ctx)
+}
+
+// Unlock 释放编辑锁
+// @Tags Notice
+// @Summary 释放通知公告编辑锁
+// @Produce application/json
+// @Param id path int true "通知公告ID"
+// @Success 200 {object} echox.Response "ok"
+// @Router /api/v1/notices/{id}/lock [delete]
+func (a NoticeController) Unlock(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusNotFound, Message: err}.JSON(ctx)
+	}
+
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	if err := a.noticeDraftService.Unlock(id, claims); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
 // ReadAll 全部已读
 // @Tags Notice
 // @Summary 全部已读
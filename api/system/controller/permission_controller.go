@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+type PermissionController struct {
+	permissionService service.PermissionService
+	logger            lib.Logger
+}
+
+// NewPermissionController creates new permission controller
+func NewPermissionController(
+	logger lib.Logger,
+	permissionService service.PermissionService,
+) PermissionController {
+	return PermissionController{
+		logger:            logger,
+		permissionService: permissionService,
+	}
+}
+
+// @tags Permission
+// @summary Route Permission Matrix
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]dto.RoutePermVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/permissions/routes [get]
+func (a PermissionController) RouteMatrix(ctx echo.Context) error {
+	matrix, err := a.permissionService.GetRouteMatrix()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: matrix}.JSON(ctx)
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// PolicyController 访问策略控制器
+type PolicyController struct {
+	logger        lib.Logger
+	policyService service.PolicyService
+}
+
+// NewPolicyController creates new policy controller
+func NewPolicyController(
+	logger lib.Logger,
+	policyService service.PolicyService,
+) PolicyController {
+	return PolicyController{
+		logger:        logger,
+		policyService: policyService,
+	}
+}
+
+// Query 分页查询策略
+// @tags Policy
+// @summary Policy Query
+// @produce application/json
+// @param data query system.PolicyQueryParam true "PolicyQueryParam"
+// @success 200 {object} echox.Response{data=[]system.CasbinRule} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies [get]
+func (a PolicyController) Query(ctx echo.Context) error {
+	param := new(system.PolicyQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.policyService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Get 获取策略
+// @tags Policy
+// @summary Policy Get By ID
+// @produce application/json
+// @param id path int true "策略ID"
+// @success 200 {object} echox.Response{data=system.CasbinRule} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/{id} [get]
+func (a PolicyController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	rule, err := a.policyService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: rule}.JSON(ctx)
+}
+
+// Create 新增策略，保存后立即生效
+// @tags Policy
+// @summary Policy Create
+// @accept application/json
+// @produce application/json
+// @param data body system.PolicyForm true "PolicyForm"
+// @success 200 {object} echox.Response{data=uint64} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies [post]
+func (a PolicyController) Create(ctx echo.Context) error {
+	form := new(system.PolicyForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	id, err := a.policyService.WithTrx(trxHandle).Create(form)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: id}.JSON(ctx)
+}
+
+// Update 修改策略，保存后立即生效
+// @tags Policy
+// @summary Policy Update By ID
+// @accept application/json
+// @produce application/json
+// @param id path int true "策略ID"
+// @param data body system.PolicyForm true "PolicyForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/{id} [put]
+func (a PolicyController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.PolicyForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.policyService.WithTrx(trxHandle).Update(id, form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除策略
+// @tags Policy
+// @summary Policy Delete
+// @produce application/json
+// @param ids path string true "策略ID，多个以英文逗号分割"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/{ids} [delete]
+func (a PolicyController) Delete(ctx echo.Context) error {
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.policyService.WithTrx(trxHandle).Delete(ctx.Param("ids")); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Test 测试用户能否访问指定接口
+// @tags Policy
+// @summary Policy Test
+// @accept application/json
+// @produce application/json
+// @param data body system.PolicyTestForm true "PolicyTestForm"
+// @success 200 {object} echox.Response{data=system.PolicyTestResult} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/test [post]
+func (a PolicyController) Test(ctx echo.Context) error {
+	form := new(system.PolicyTestForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	result, err := a.policyService.Test(form)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// Export 导出策略文件（casbin CSV 格式）
+// @tags Policy
+// @summary Policy Export
+// @produce text/csv
+// @success 200 {file} file "policy.csv"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/export [get]
+func (a PolicyController) Export(ctx echo.Context) error {
+	var buf bytes.Buffer
+	if err := a.policyService.Export(&buf); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	filename := "policy-" + time.Now().Format("20060102150405") + ".csv"
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	return ctx.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// Import 导入策略文件（casbin CSV 格式），导入后立即生效
+// @tags Policy
+// @summary Policy Import
+// @accept multipart/form-data
+// @produce application/json
+// @param file formData file true "策略文件"
+// @param replace query bool false "清空现有策略后导入，默认追加（已存在的策略跳过）"
+// @success 200 {object} echox.Response{data=system.PolicyImportResult} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/import [post]
+func (a PolicyController) Import(ctx echo.Context) error {
+	replace, _ := strconv.ParseBool(ctx.QueryParam("replace"))
+
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "file is required"}.JSON(ctx)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+	defer src.Close()
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	result, err := a.policyService.WithTrx(trxHandle).Import(src, replace, claims.ID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// Reload 从数据库重新加载策略（多实例部署时同步其他实例的修改）
+// @tags Policy
+// @summary Policy Reload
+// @produce application/json
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/policies/reload [post]
+func (a PolicyController) Reload(ctx echo.Context) error {
+	if err := a.policyService.Reload(); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// PositionController 岗位控制器
+type PositionController struct {
+	logger          lib.Logger
+	positionService service.PositionService
+}
+
+// NewPositionController creates new position controller
+func NewPositionController(
+	logger lib.Logger,
+	positionService service.PositionService,
+) PositionController {
+	return PositionController{
+		logger:          logger,
+		positionService: positionService,
+	}
+}
+
+// Query 岗位分页列表
+// @tags Position
+// @summary Position Query
+// @produce application/json
+// @param data query system.PositionQueryParam true "PositionQueryParam"
+// @success 200 {object} echox.Response{data=[]system.Position} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/positions [get]
+func (a PositionController) Query(ctx echo.Context) error {
+	param := new(system.PositionQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.positionService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// GetOptions 岗位下拉列表（用户表单）
+// @tags Position
+// @summary Position Options
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.PositionOption} "ok"
+// @router /api/v1/positions/options [get]
+func (a PositionController) GetOptions(ctx echo.Context) error {
+	list, err := a.positionService.ListOptions()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Get 岗位详情
+// @tags Position
+// @summary Position Get By ID
+// @produce application/json
+// @param id path int true "岗位ID"
+// @success 200 {object} echox.Response{data=system.Position} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/positions/{id} [get]
+func (a PositionController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	position, err := a.positionService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: position}.JSON(ctx)
+}
+
+// Create 新增岗位
+// @tags Position
+// @summary Position Create
+// @produce application/json
+// @param data body system.PositionForm true "PositionForm"
+// @success 200 {object} echox.Response{data=uint64} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/positions [post]
+func (a PositionController) Create(ctx echo.Context) error {
+	form := new(system.PositionForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	id, err := a.positionService.WithTrx(trxHandle).Create(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: id}.JSON(ctx)
+}
+
+// Update 修改岗位
+// @tags Position
+// @summary Position Update By ID
+// @produce application/json
+// @param id path int true "岗位ID"
+// @param data body system.PositionForm true "PositionForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/positions/{id} [put]
+func (a PositionController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.PositionForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var updateBy uint64
+	if claims != nil {
+		updateBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.positionService.WithTrx(trxHandle).Update(id, form, updateBy); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除岗位，仍有用户任职时不允许删除
+// @tags Position
+// @summary Position Delete By ID
+// @produce application/json
+// @param id path int true "岗位ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/positions/{id} [delete]
+func (a PositionController) Delete(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.positionService.WithTrx(trxHandle).Delete(id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// ProvisionController 部门与用户批量开通
+type ProvisionController struct {
+	logger           lib.Logger
+	provisionService service.ProvisionService
+}
+
+// NewProvisionController creates new provision controller
+func NewProvisionController(
+	logger lib.Logger,
+	provisionService service.ProvisionService,
+) ProvisionController {
+	return ProvisionController{
+		logger:           logger,
+		provisionService: provisionService,
+	}
+}
+
+// Provision 上传部门、用户 CSV 批量开通账号
+// 存在校验失败的行时不创建任何数据，返回逐行结果；初始密码只在本次结果中返回（或通过邮件发送）
+// @tags User
+// @summary Provision Depts And Users From CSV
+// @accept multipart/form-data
+// @produce application/json
+// @param depts formData file false "部门 CSV: code,name,parent_code,sort"
+// @param users formData file false "用户 CSV: username,nickname,email,mobile,gender,dept_code,role_codes"
+// @param dryRun query bool false "仅校验不创建"
+// @param notify query string false "初始密码下发方式: return、email"
+// @success 200 {object} echox.Response{data=system.ProvisionResult} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/users/provision [post]
+func (a ProvisionController) Provision(ctx echo.Context) error {
+	var (
+		depts []*system.ProvisionDeptRow
+		users []*system.ProvisionUserRow
+	)
+
+	if src, err := a.openFile(ctx, "depts"); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	} else if src != nil {
+		depts, err = a.provisionService.ParseDepts(src)
+		src.Close()
+		if err != nil {
+			return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+		}
+	}
+
+	if src, err := a.openFile(ctx, "users"); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	} else if src != nil {
+		users, err = a.provisionService.ParseUsers(src)
+		src.Close()
+		if err != nil {
+			return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+		}
+	}
+
+	opts := system.ProvisionOptions{
+		DryRun: ctx.QueryParam("dryRun") == "true",
+		Notify: ctx.QueryParam("notify"),
+	}
+	if claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); ok && claims != nil {
+		opts.Operator = claims.ID
+	}
+
+	result, err := a.provisionService.Provision(depts, users, opts)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// openFile 打开上传的 CSV 文件，未上传时返回 nil
+func (a ProvisionController) openFile(ctx echo.Context, name string) (io.ReadCloser, error) {
+	file, err := ctx.FormFile(name)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return file.Open()
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/top-system/light-admin/api/system/service"
 	"github.com/top-system/light-admin/constants"
@@ -13,27 +14,36 @@ import (
 )
 
 type PublicController struct {
-	userService service.UserService
-	authService service.AuthService
-	captcha     lib.Captcha
-	config      lib.Config
-	logger      lib.Logger
+	userService       service.UserService
+	authService       service.AuthService
+	securityService   service.SecurityService
+	loginGuardService service.LoginGuardService
+	twoFactorService  service.TwoFactorService
+	captcha           lib.Captcha
+	config            lib.Config
+	logger            lib.Logger
 }
 
 // NewPublicController creates new public controller
 func NewPublicController(
 	userService service.UserService,
 	authService service.AuthService,
+	securityService service.SecurityService,
+	loginGuardService service.LoginGuardService,
+	twoFactorService service.TwoFactorService,
 	captcha lib.Captcha,
 	config lib.Config,
 	logger lib.Logger,
 ) PublicController {
 	return PublicController{
-		userService: userService,
-		authService: authService,
-		captcha:     captcha,
-		config:      config,
-		logger:      logger,
+		userService:       userService,
+		authService:       authService,
+		securityService:   securityService,
+		loginGuardService: loginGuardService,
+		twoFactorService:  twoFactorService,
+		captcha:           captcha,
+		config:            config,
+		logger:            logger,
 	}
 }
 
@@ -43,6 +53,8 @@ func NewPublicController(
 // @param data body dto.Login true "Login"
 // @success 200 {object} echox.Response "ok"
 // @failure 400 {object} echox.Response "bad request"
+// @failure 401 {object} echox.Response "two-factor code required or invalid"
+// @failure 429 {object} echox.Response "account locked or too many failed logins"
 // @failure 500 {object} echox.Response "internal error"
 // @router /api/v1/auth/login [post]
 func (a PublicController) UserLogin(ctx echo.Context) error {
@@ -52,23 +64,39 @@ func (a PublicController) UserLogin(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	ip, userAgent := ctx.RealIP(), ctx.Request().UserAgent()
+	if err := a.loginGuardService.Check(login.Username, ip); err != nil {
+		a.loginGuardService.RecordFailure(login.Username, ip, userAgent, err)
+		return echox.Response{Code: http.StatusTooManyRequests, Message: err}.JSON(ctx)
+	}
+
 	// Only verify captcha if enabled in config
 	if a.config.Captcha != nil && a.config.Captcha.Enable {
 		if !a.captcha.Verify(login.CaptchaID, login.CaptchaCode, false) {
+			a.loginGuardService.RecordFailure(login.Username, ip, userAgent, errors.CaptchaAnswerCodeNoMatch)
 			return echox.Response{Code: http.StatusBadRequest, Message: errors.CaptchaAnswerCodeNoMatch}.JSON(ctx)
 		}
 	}
 
 	user, err := a.userService.Verify(login.Username, login.Password)
 	if err != nil {
+		a.loginGuardService.RecordFailure(login.Username, ip, userAgent, err)
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	loginResp, err := a.authService.GenerateToken(user)
+	if err := a.twoFactorService.VerifyLogin(user, login.TwoFactorCode); err != nil {
+		a.loginGuardService.RecordFailure(login.Username, ip, userAgent, err)
+		return echox.Response{Code: http.StatusUnauthorized, Message: err}.JSON(ctx)
+	}
+
+	loginResp, err := a.authService.GenerateToken(user, userAgent, ip)
 	if err != nil {
 		return echox.Response{Code: http.StatusInternalServerError, Message: errors.AuthTokenGenerateFail}.JSON(ctx)
 	}
 
+	a.loginGuardService.RecordSuccess(user, ip, userAgent)
+	a.securityService.RecordLogin(user, ip)
+
 	return echox.Response{Code: http.StatusOK, Data: loginResp}.JSON(ctx)
 }
 
@@ -80,8 +108,88 @@ func (a PublicController) UserLogin(ctx echo.Context) error {
 func (a PublicController) UserLogout(ctx echo.Context) error {
 	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	if ok {
-		a.authService.DestroyToken(claims.Username)
+		if err := a.authService.Logout(claims); err != nil {
+			a.logger.Zap.Warnf("Failed to logout %s: %v", claims.Username, err)
+		}
 	}
 
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
+
+// RefreshToken 使用刷新令牌换取新的访问令牌与刷新令牌，旧的刷新令牌随即失效
+// @tags Auth
+// @summary 刷新令牌
+// @produce application/json
+// @param data body dto.RefreshTokenForm true "RefreshTokenForm"
+// @success 200 {object} echox.Response{data=dto.LoginResponse} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 401 {object} echox.Response "refresh token is invalid or expired"
+// @router /api/v1/auth/refresh [post]
+func (a PublicController) RefreshToken(ctx echo.Context) error {
+	form := new(dto.RefreshTokenForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	loginResp, err := a.authService.RefreshToken(form.RefreshToken, ctx.RealIP())
+	if err != nil {
+		return echox.Response{Code: http.StatusUnauthorized, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: loginResp}.JSON(ctx)
+}
+
+// @tags Auth
+// @summary 当前用户的登录设备
+// @produce application/json
+// @success 200 {object} echox.Response{data=system.RefreshTokens} "ok"
+// @router /api/v1/auth/devices [get]
+func (a PublicController) GetDevices(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	devices, err := a.authService.ListDevices(claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusInternalServerError, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: devices}.JSON(ctx)
+}
+
+// @tags Auth
+// @summary 移除登录设备，该设备的令牌立即失效
+// @produce application/json
+// @param id path int true "device id"
+// @success 200 {object} echox.Response "ok"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/auth/devices/{id} [delete]
+func (a PublicController) RevokeDevice(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok {
+		return echox.Response{Code: http.StatusUnauthorized}.JSON(ctx)
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid device ID"}.JSON(ctx)
+	}
+
+	if err := a.authService.RevokeDevice(claims.ID, id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// JWKS 令牌验证公钥（JWKS），供其他内部服务验证本系统签发的令牌
+// @tags Auth
+// @summary JSON Web Key Set
+// @produce application/json
+// @success 200 {object} dto.JWKS "ok"
+// @router /api/v1/auth/jwks [get]
+func (a PublicController) JWKS(ctx echo.Context) error {
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, a.authService.JWKS())
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// RetentionController 数据保留策略控制器
+type RetentionController struct {
+	logger           lib.Logger
+	retentionService service.RetentionService
+}
+
+// NewRetentionController creates new retention controller
+func NewRetentionController(
+	logger lib.Logger,
+	retentionService service.RetentionService,
+) RetentionController {
+	return RetentionController{
+		logger:           logger,
+		retentionService: retentionService,
+	}
+}
+
+// List 全部保留策略
+// @tags Retention
+// @summary Retention Policy List
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.RetentionPolicy} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/retention-policies [get]
+func (a RetentionController) List(ctx echo.Context) error {
+	list, err := a.retentionService.List()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Update 修改数据类型的保留天数与启用状态
+// @tags Retention
+// @summary Retention Policy Update
+// @accept application/json
+// @produce application/json
+// @param dataType path string true "数据类型：operation_log、login_log、login_audit、operation_audit、download_task、queue_task、cron_record、download_temp"
+// @param data body system.RetentionPolicyForm true "RetentionPolicyForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/retention-policies/{dataType} [put]
+func (a RetentionController) Update(ctx echo.Context) error {
+	form := new(system.RetentionPolicyForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.retentionService.WithTrx(trxHandle).Update(ctx.Param("dataType"), form, claims.ID); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Preview 按当前策略估算各数据类型将被删除的行数
+// @tags Retention
+// @summary Retention Preview
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.RetentionPreviewVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/retention-policies/preview [get]
+func (a RetentionController) Preview(ctx echo.Context) error {
+	result, err := a.retentionService.Preview()
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// Run 立即执行保留策略，在后台分批删除
+// @tags Retention
+// @summary Retention Run
+// @accept application/json
+// @produce application/json
+// @param data body system.RetentionRunForm true "RetentionRunForm"
+// @success 200 {object} echox.Response{data=system.RetentionRunVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/retention-policies/run [post]
+func (a RetentionController) Run(ctx echo.Context) error {
+	form := new(system.RetentionRunForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	result, err := a.retentionService.Run(form, claims.ID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
@@ -31,6 +31,16 @@ func NewRoleController(
 	}
 }
 
+// applyDataScope 按当前身份角色的数据权限计算查询范围，未登录（内部调用）时不限制
+func applyDataScope(ctx echo.Context, dataScopeService service.DataScopeService) (*system.DataScope, error) {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || claims == nil {
+		return nil, nil
+	}
+
+	return dataScopeService.Resolve(claims)
+}
+
 // @tags Role
 // @summary Role Query
 // @produce application/json
@@ -57,6 +67,7 @@ func (a RoleController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// SavedQueryController 保存查询控制器，仅操作当前用户自己的保存查询
+type SavedQueryController struct {
+	logger            lib.Logger
+	savedQueryService service.SavedQueryService
+}
+
+// NewSavedQueryController creates new saved query controller
+func NewSavedQueryController(
+	logger lib.Logger,
+	savedQueryService service.SavedQueryService,
+) SavedQueryController {
+	return SavedQueryController{
+		logger:            logger,
+		savedQueryService: savedQueryService,
+	}
+}
+
+// currentUserID 当前登录用户ID
+func (a SavedQueryController) currentUserID(ctx echo.Context) uint64 {
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if claims == nil {
+		return 0
+	}
+	return claims.ID
+}
+
+// applySavedQuery 列表接口带 savedQueryId 时，将保存的查询条件与排序合并到请求参数中，
+// 请求中显式传入的参数优先，须在 Bind 之前调用
+func applySavedQuery(ctx echo.Context, savedQueryService service.SavedQueryService, resource string) error {
+	v := ctx.QueryParam("savedQueryId")
+	if v == "" {
+		return nil
+	}
+
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	var userID uint64
+	if claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); claims != nil {
+		userID = claims.ID
+	}
+
+	params, err := savedQueryService.Params(id, userID, resource)
+	if err != nil {
+		return err
+	}
+
+	// QueryParams 返回 echo 缓存的参数，Bind 及后续 QueryParam 读取的都是这份
+	query := ctx.QueryParams()
+	for k, vs := range params {
+		if _, ok := query[k]; !ok {
+			query[k] = vs
+		}
+	}
+
+	return nil
+}
+
+// List 我的保存查询
+// @tags SavedQuery
+// @summary My Saved Queries
+// @produce application/json
+// @param data query system.SavedQueryQueryParam true "SavedQueryQueryParam"
+// @success 200 {object} echox.Response{data=[]system.SavedQuery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/my/saved-queries [get]
+func (a SavedQueryController) List(ctx echo.Context) error {
+	param := new(system.SavedQueryQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	list, err := a.savedQueryService.List(a.currentUserID(ctx), param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: list}.JSON(ctx)
+}
+
+// Get 保存查询详情
+// @tags SavedQuery
+// @summary My Saved Query Get By ID
+// @produce application/json
+// @param id path int true "保存查询ID"
+// @success 200 {object} echox.Response{data=system.SavedQuery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/my/saved-queries/{id} [get]
+func (a SavedQueryController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	query, err := a.savedQueryService.Get(id, a.currentUserID(ctx))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: query}.JSON(ctx)
+}
+
+// Create 新增保存查询
+// @tags SavedQuery
+// @summary My Saved Query Create
+// @produce application/json
+// @param data body system.SavedQueryForm true "SavedQueryForm"
+// @success 200 {object} echox.Response{data=system.SavedQuery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/my/saved-queries [post]
+func (a SavedQueryController) Create(ctx echo.Context) error {
+	form := new(system.SavedQueryForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	query, err := a.savedQueryService.Create(form, a.currentUserID(ctx))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: query}.JSON(ctx)
+}
+
+// Update 修改保存查询
+// @tags SavedQuery
+// @summary My Saved Query Update By ID
+// @produce application/json
+// @param id path int true "保存查询ID"
+// @param data body system.SavedQueryForm true "SavedQueryForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/my/saved-queries/{id} [put]
+func (a SavedQueryController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.SavedQueryForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.savedQueryService.Update(id, form, a.currentUserID(ctx)); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除保存查询
+// @tags SavedQuery
+// @summary My Saved Query Delete By ID
+// @produce application/json
+// @param id path int true "保存查询ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/my/saved-queries/{id} [delete]
+func (a SavedQueryController) Delete(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.savedQueryService.Delete(id, a.currentUserID(ctx)); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
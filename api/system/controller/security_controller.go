@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// SecurityController 安全告警控制器
+type SecurityController struct {
+	logger          lib.Logger
+	securityService service.SecurityService
+}
+
+// NewSecurityController creates new security controller
+func NewSecurityController(
+	logger lib.Logger,
+	securityService service.SecurityService,
+) SecurityController {
+	return SecurityController{
+		logger:          logger,
+		securityService: securityService,
+	}
+}
+
+// Dashboard 安全概览
+// @tags Security
+// @summary Security Dashboard
+// @produce application/json
+// @param days query int false "统计天数，默认 7"
+// @success 200 {object} echox.Response{data=system.SecurityDashboardVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/dashboard [get]
+func (a SecurityController) Dashboard(ctx echo.Context) error {
+	days, _ := strconv.Atoi(ctx.QueryParam("days"))
+
+	dashboard, err := a.securityService.Dashboard(days)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: dashboard}.JSON(ctx)
+}
+
+// QueryAlerts 分页查询安全告警
+// @tags Security
+// @summary Security Alert Query
+// @produce application/json
+// @param data query system.SecurityAlertQueryParam true "SecurityAlertQueryParam"
+// @success 200 {object} echox.Response{data=[]system.SecurityAlert} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/alerts [get]
+func (a SecurityController) QueryAlerts(ctx echo.Context) error {
+	param := new(system.SecurityAlertQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.securityService.QueryAlerts(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// AckAlert 确认安全告警
+// @tags Security
+// @summary Security Alert Acknowledge
+// @produce application/json
+// @param id path int true "告警ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/security/alerts/{id}/ack [put]
+func (a SecurityController) AckAlert(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var ackBy uint64
+	if claims != nil {
+		ackBy = claims.ID
+	}
+
+	if err := a.securityService.AckAlert(id, ackBy); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// TagController 标签控制器
+type TagController struct {
+	logger     lib.Logger
+	tagService service.TagService
+}
+
+// NewTagController creates new tag controller
+func NewTagController(
+	logger lib.Logger,
+	tagService service.TagService,
+) TagController {
+	return TagController{
+		logger:     logger,
+		tagService: tagService,
+	}
+}
+
+// Query 标签分页列表
+// @tags Tag
+// @summary Tag Query
+// @produce application/json
+// @param data query system.TagQueryParam true "TagQueryParam"
+// @success 200 {object} echox.Response{data=[]system.Tag} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/tags [get]
+func (a TagController) Query(ctx echo.Context) error {
+	param := new(system.TagQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.tagService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Get 标签详情
+// @tags Tag
+// @summary Tag Get By ID
+// @produce application/json
+// @param id path int true "标签ID"
+// @success 200 {object} echox.Response{data=system.Tag} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/tags/{id} [get]
+func (a TagController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	tag, err := a.tagService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: tag}.JSON(ctx)
+}
+
+// Create 新增标签
+// @tags Tag
+// @summary Tag Create
+// @produce application/json
+// @param data body system.TagForm true "TagForm"
+// @success 200 {object} echox.Response{data=uint64} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/tags [post]
+func (a TagController) Create(ctx echo.Context) error {
+	form := new(system.TagForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	id, err := a.tagService.WithTrx(trxHandle).Create(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: id}.JSON(ctx)
+}
+
+// Update 修改标签
+// @tags Tag
+// @summary Tag Update By ID
+// @produce application/json
+// @param id path int true "标签ID"
+// @param data body system.TagForm true "TagForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "conflict"
+// @router /api/v1/tags/{id} [put]
+func (a TagController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.TagForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var updateBy uint64
+	if claims != nil {
+		updateBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.tagService.WithTrx(trxHandle).Update(id, form, updateBy); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除标签，同时移除其与资源的关联
+// @tags Tag
+// @summary Tag Delete By ID
+// @produce application/json
+// @param id path int true "标签ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/tags/{id} [delete]
+func (a TagController) Delete(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.tagService.WithTrx(trxHandle).Delete(id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// ResourceTags 获取资源的标签
+// @tags Tag
+// @summary Resource Tags
+// @produce application/json
+// @param type path string true "资源类型：user、file、notice、download"
+// @param id path int true "资源ID"
+// @success 200 {object} echox.Response{data=[]system.Tag} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/tags/resources/{type}/{id} [get]
+func (a TagController) ResourceTags(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	tags, err := a.tagService.ResourceTags(ctx.Param("type"), id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: tags}.JSON(ctx)
+}
+
+// SetResourceTags 设置资源的标签（整体替换，传空列表清除）
+// @tags Tag
+// @summary Set Resource Tags
+// @produce application/json
+// @param type path string true "资源类型：user、file、notice、download"
+// @param id path int true "资源ID"
+// @param data body system.ResourceTagsForm true "ResourceTagsForm"
+// @success 200 {object} echox.Response{data=[]system.Tag} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/tags/resources/{type}/{id} [put]
+func (a TagController) SetResourceTags(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.ResourceTagsForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	tags, err := a.tagService.WithTrx(trxHandle).SetResourceTags(ctx.Param("type"), id, form.TagIDs, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: tags}.JSON(ctx)
+}
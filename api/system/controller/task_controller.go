@@ -7,9 +7,12 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/queue"
 )
 
 type TaskController struct {
@@ -55,6 +58,7 @@ func (a TaskController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
@@ -81,18 +85,21 @@ func (a TaskController) Get(ctx echo.Context) error {
 	}
 
 	vo := &system.TaskPageVO{
-		ID:               task.ID,
-		Type:             task.Type,
-		Status:           string(task.Status),
-		CorrelationID:    task.CorrelationID,
-		OwnerID:          task.OwnerID,
-		RetryCount:       task.RetryCount,
-		ExecutedDuration: task.ExecutedDuration,
-		Error:            task.Error,
-		ErrorHistory:     task.ErrorHistory,
-		ResumeTime:       task.ResumeTime,
-		CreatedAt:        task.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:        task.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:                task.ID,
+		Type:              task.Type,
+		Status:            string(task.Status),
+		CorrelationID:     task.CorrelationID,
+		OwnerID:           task.OwnerID,
+		RetryCount:        task.RetryCount,
+		ExecutedDuration:  task.ExecutedDuration,
+		Error:             task.Error,
+		ErrorHistory:      task.ErrorHistory,
+		ResumeTime:        task.ResumeTime,
+		Priority:          task.Priority,
+		CreatedAt:         task.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:         task.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Progress:          task.ProgressSnapshot(),
+		ProgressUpdatedAt: task.ProgressAt,
 	}
 
 	return echox.Response{Code: http.StatusOK, Data: vo}.JSON(ctx)
@@ -164,3 +171,96 @@ func (a TaskController) GetTypes(ctx echo.Context) error {
 
 	return echox.Response{Code: http.StatusOK, Data: types}.JSON(ctx)
 }
+
+// Progress 获取任务摘要与进度
+// @tags Task
+// @summary Get Task Progress
+// @produce application/json
+// @param id path int true "Task ID"
+// @success 200 {object} echox.Response{data=system.TaskProgressVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/tasks/{id}/progress [get]
+func (a TaskController) Progress(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	vo, err := a.taskService.Progress(ctx.Request().Context(), id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: vo}.JSON(ctx)
+}
+
+// Cancel 取消排队中或挂起中的任务
+// @tags Task
+// @summary Cancel Task
+// @produce application/json
+// @param id path int true "Task ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @failure 409 {object} echox.Response "task not cancelable"
+// @failure 503 {object} echox.Response "task queue not enabled"
+// @router /api/v1/tasks/{id}/cancel [post]
+func (a TaskController) Cancel(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.taskService.Cancel(ctx.Request().Context(), id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Retry 重新执行失败的任务
+// @tags Task
+// @summary Retry Task
+// @produce application/json
+// @param id path int true "Task ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @failure 409 {object} echox.Response "task not retryable"
+// @failure 503 {object} echox.Response "task queue not enabled"
+// @router /api/v1/tasks/{id}/retry [post]
+func (a TaskController) Retry(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: "Invalid task ID"}.JSON(ctx)
+	}
+
+	if err := a.taskService.Retry(ctx.Request().Context(), id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// bindExportQuery 导出接口为 POST 请求，筛选条件与对应的列表查询相同，从查询参数绑定
+func bindExportQuery(ctx echo.Context, param interface{}) error {
+	return (&echo.DefaultBinder{}).BindQueryParams(ctx, param)
+}
+
+// submitExport 以当前用户身份提交导出任务，导出格式取查询参数 format（xlsx 或 csv，默认 xlsx）
+func submitExport(ctx echo.Context, exportService service.ExportService, source string, param interface{}) error {
+	owner := &queue.TaskOwner{}
+	if claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims); claims != nil {
+		owner.ID = claims.ID
+		owner.Username = claims.Username
+	}
+
+	taskID, err := exportService.Submit(owner, source, ctx.QueryParam("format"), param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: echo.Map{"taskId": taskID}}.JSON(ctx)
+}
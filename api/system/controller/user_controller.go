@@ -13,23 +13,32 @@ import (
 	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/pkg/echox"
+	"github.com/top-system/light-admin/pkg/str"
 	"github.com/labstack/echo/v4"
 
 	"gorm.io/gorm"
 )
 
 type UserController struct {
-	userService service.UserService
-	fileService platformService.FileService
-	logger      lib.Logger
+	userService       service.UserService
+	twoFactorService  service.TwoFactorService
+	fileService       platformService.FileService
+	savedQueryService service.SavedQueryService
+	dataScopeService  service.DataScopeService
+	exportService     service.ExportService
+	logger            lib.Logger
 }
 
 // NewUserController creates new user controller
-func NewUserController(userService service.UserService, fileService platformService.FileService, logger lib.Logger) UserController {
+func NewUserController(userService service.UserService, twoFactorService service.TwoFactorService, fileService platformService.FileService, savedQueryService service.SavedQueryService, dataScopeService service.DataScopeService, exportService service.ExportService, logger lib.Logger) UserController {
 	return UserController{
-		userService: userService,
-		fileService: fileService,
-		logger:      logger,
+		userService:       userService,
+		twoFactorService:  twoFactorService,
+		fileService:       fileService,
+		savedQueryService: savedQueryService,
+		dataScopeService:  dataScopeService,
+		exportService:     exportService,
+		logger:            logger,
 	}
 }
 
@@ -58,24 +67,22 @@ func (a UserController) Me(ctx echo.Context) error {
 // @summary User Query
 // @produce application/json
 // @param data query system.UserQueryParam true "UserQueryParam"
+// @param savedQueryId query int false "保存查询ID，合并保存的查询条件与排序"
 // @success 200 {object} echox.Response{data=system.Users} "ok"
 // @failure 400 {object} echox.Response "bad request"
 // @failure 500 {object} echox.Response "internal error"
 // @router /api/v1/users [get]
 func (a UserController) Query(ctx echo.Context) error {
+	if err := applySavedQuery(ctx, a.savedQueryService, system.SavedQueryResourceUser); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	param := new(system.UserQueryParam)
 	if err := ctx.Bind(param); err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
-	if v := ctx.QueryParam("role_ids"); v != "" {
-		strIDs := strings.Split(v, ",")
-		roleIDs := make([]uint64, 0, len(strIDs))
-		for _, s := range strIDs {
-			if id, err := strconv.ParseUint(s, 10, 64); err == nil {
-				roleIDs = append(roleIDs, id)
-			}
-		}
-		param.RoleIDs = roleIDs
+	if err := a.applyQueryParam(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
 	qr, err := a.userService.Query(param)
@@ -90,10 +97,71 @@ func (a UserController) Query(ctx echo.Context) error {
 			Total:    qr.Pagination.Total,
 			PageNum:  qr.Pagination.PageNum,
 			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
 		},
 	}.JSON(ctx)
 }
 
+// applyQueryParam 补充查询参数中 Bind 无法直接绑定的角色、标签过滤以及数据权限
+func (a UserController) applyQueryParam(ctx echo.Context, param *system.UserQueryParam) error {
+	if v := ctx.QueryParam("role_ids"); v != "" {
+		strIDs := strings.Split(v, ",")
+		roleIDs := make([]uint64, 0, len(strIDs))
+		for _, s := range strIDs {
+			if id, err := strconv.ParseUint(s, 10, 64); err == nil {
+				roleIDs = append(roleIDs, id)
+			}
+		}
+		param.RoleIDs = roleIDs
+	}
+	if v := ctx.QueryParam("tagIds"); v != "" {
+		param.TagIDs = str.S(v).Uint64s(",")
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return err
+	}
+	param.DataScope = scope
+	return nil
+}
+
+// checkDataScope 检查用户是否在当前身份的数据权限范围内
+func (a UserController) checkDataScope(ctx echo.Context, ids ...uint64) (*system.DataScope, error) {
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return nil, err
+	}
+
+	return scope, a.userService.CheckDataScope(scope, ids...)
+}
+
+// Export 按查询条件导出用户，导出完成后推送下载地址
+// @tags User
+// @summary User Export
+// @produce application/json
+// @param data query system.UserQueryParam true "UserQueryParam"
+// @param savedQueryId query int false "保存查询ID，合并保存的查询条件与排序"
+// @param format query string false "导出格式：xlsx（默认）、csv"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/users/export [post]
+func (a UserController) Export(ctx echo.Context) error {
+	if err := applySavedQuery(ctx, a.savedQueryService, system.SavedQueryResourceUser); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	param := new(system.UserQueryParam)
+	if err := bindExportQuery(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	if err := a.applyQueryParam(ctx, param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return submitExport(ctx, a.exportService, service.ExportSourceUser, param)
+}
+
 // @tags User
 // @summary User Options
 // @produce application/json
@@ -128,6 +196,14 @@ func (a UserController) Create(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: errors.UserPasswordRequired}.JSON(ctx)
 	}
 
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	if err := a.userService.CheckDeptScope(scope, 0, user.DeptID); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	user.CreateBy = claims.ID
 
@@ -153,6 +229,10 @@ func (a UserController) GetForm(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	if _, err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	form, err := a.userService.GetUserForm(id)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
@@ -183,6 +263,14 @@ func (a UserController) Update(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
+	scope, err := a.checkDataScope(ctx, id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	if err := a.userService.CheckDeptScope(scope, id, user.DeptID); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
 	user.UpdateBy = claims.ID
 
@@ -194,27 +282,120 @@ func (a UserController) Update(ctx echo.Context) error {
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
 
+// Delete 删除用户，不存在的用户和当前用户被跳过并在结果中说明原因
 // @tags User
-// @summary User Delete By ID
+// @summary User Delete By IDs
 // @produce application/json
-// @param id path int true "user id"
-// @success 200 {object} echox.Response "ok"
+// @param ids path string true "用户ID，多个以英文逗号分割"
+// @success 200 {object} echox.Response{data=system.UserBulkResult} "ok"
 // @failure 400 {object} echox.Response "bad request"
 // @failure 500 {object} echox.Response "internal error"
-// @router /api/v1/users/{id} [delete]
+// @router /api/v1/users/{ids} [delete]
 func (a UserController) Delete(ctx echo.Context) error {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var operatorID uint64
+	if claims != nil {
+		operatorID = claims.ID
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
 	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
-	err = a.userService.WithTrx(trxHandle).Delete(id)
+	result, err := a.userService.WithTrx(trxHandle).DeleteByIds(ctx.Param("ids"), operatorID, scope)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
 	}
 
-	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
+}
+
+// UpdateStatus 批量启用/禁用用户，等同于 POST /users/bulk 的 enable/disable 操作，
+// 与字典、通知的批量接口保持一致的资源风格
+// @tags User
+// @summary User Batch Status
+// @produce application/json
+// @param data body system.UserStatusForm true "UserStatusForm"
+// @success 200 {object} echox.Response{data=system.UserBulkResult} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/users/status [put]
+func (a UserController) UpdateStatus(ctx echo.Context) error {
+	form := new(system.UserStatusForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	operation := system.UserBulkDisable
+	if form.Status == constants.StatusEnable {
+		operation = system.UserBulkEnable
+	}
+
+	return a.bulk(ctx, &system.UserBulkForm{UserIDs: form.UserIDs, Operation: operation})
+}
+
+// UpdateRoles 批量设置用户角色，用户原有的角色被替换，等同于 POST /users/bulk 的 set_roles 操作
+// @tags User
+// @summary User Batch Roles
+// @produce application/json
+// @param data body system.UserRolesForm true "UserRolesForm"
+// @success 200 {object} echox.Response{data=system.UserBulkResult} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 403 {object} echox.Response "roles exceed the permissions of the current user"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/users/roles [put]
+func (a UserController) UpdateRoles(ctx echo.Context) error {
+	form := new(system.UserRolesForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return a.bulk(ctx, &system.UserBulkForm{UserIDs: form.UserIDs, Operation: system.UserBulkSetRoles, RoleIDs: form.RoleIDs})
+}
+
+// Bulk 批量用户操作
+// @tags User
+// @summary User Bulk Operation
+// @produce application/json
+// @param data body system.UserBulkForm true "UserBulkForm"
+// @success 200 {object} echox.Response{data=system.UserBulkResult} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 403 {object} echox.Response "roles exceed the permissions of the current user"
+// @failure 500 {object} echox.Response "internal error"
+// @router /api/v1/users/bulk [post]
+func (a UserController) Bulk(ctx echo.Context) error {
+	form := new(system.UserBulkForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return a.bulk(ctx, form)
+}
+
+// bulk 以当前身份及其数据权限在请求事务中执行批量用户操作
+func (a UserController) bulk(ctx echo.Context, form *system.UserBulkForm) error {
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var operatorID uint64
+	if claims != nil {
+		operatorID = claims.ID
+	}
+
+	scope, err := applyDataScope(ctx, a.dataScopeService)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+	form.DataScope = scope
+	form.Operator = claims
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	result, err := a.userService.WithTrx(trxHandle).Bulk(form, operatorID)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: result}.JSON(ctx)
 }
 
 // @tags User
@@ -237,6 +418,10 @@ func (a UserController) ResetPassword(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusBadRequest, Message: errors.UserPasswordRequired}.JSON(ctx)
 	}
 
+	if _, err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
 	err = a.userService.ResetPassword(id, password)
 	if err != nil {
 		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
@@ -245,6 +430,102 @@ func (a UserController) ResetPassword(ctx echo.Context) error {
 	return echox.Response{Code: http.StatusOK}.JSON(ctx)
 }
 
+// @tags User
+// @summary 绑定两步验证，生成新的 TOTP 密钥，使用验证码启用后生效
+// @produce application/json
+// @success 200 {object} echox.Response{data=dto.TwoFactorEnrollResponse} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 409 {object} echox.Response "already enabled"
+// @router /api/v1/users/me/two-factor [post]
+func (a UserController) EnrollTwoFactor(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || claims == nil {
+		return echox.Response{Code: http.StatusUnauthorized, Message: errors.AuthTokenInvalid}.JSON(ctx)
+	}
+
+	resp, err := a.twoFactorService.Enroll(claims)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: resp}.JSON(ctx)
+}
+
+// @tags User
+// @summary 启用两步验证，校验绑定后验证器生成的验证码
+// @produce application/json
+// @param data body dto.TwoFactorCodeForm true "TwoFactorCodeForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/users/me/two-factor [put]
+func (a UserController) ActivateTwoFactor(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || claims == nil {
+		return echox.Response{Code: http.StatusUnauthorized, Message: errors.AuthTokenInvalid}.JSON(ctx)
+	}
+
+	form := new(dto.TwoFactorCodeForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.twoFactorService.Activate(claims, form.Code); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// @tags User
+// @summary 关闭两步验证，需要提供当前验证码
+// @produce application/json
+// @param data body dto.TwoFactorCodeForm true "TwoFactorCodeForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/users/me/two-factor [delete]
+func (a UserController) DisableTwoFactor(ctx echo.Context) error {
+	claims, ok := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if !ok || claims == nil {
+		return echox.Response{Code: http.StatusUnauthorized, Message: errors.AuthTokenInvalid}.JSON(ctx)
+	}
+
+	form := new(dto.TwoFactorCodeForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.twoFactorService.Disable(claims, form.Code); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// @tags User
+// @summary 重置用户的两步验证，用于用户丢失验证器的情况
+// @produce application/json
+// @param id path int true "user id"
+// @success 200 {object} echox.Response "ok"
+// @failure 403 {object} echox.Response "forbidden"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/users/{id}/two-factor [delete]
+func (a UserController) ResetTwoFactor(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if _, err := a.checkDataScope(ctx, id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.twoFactorService.Reset(id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
 // @tags User
 // @summary Update User Profile
 // @accept multipart/form-data,application/json
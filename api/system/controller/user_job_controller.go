@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// UserJobController 用户自助定时任务控制器，仅操作当前用户自己的任务
+type UserJobController struct {
+	logger         lib.Logger
+	userJobService service.UserJobService
+}
+
+// NewUserJobController creates new user job controller
+func NewUserJobController(
+	logger lib.Logger,
+	userJobService service.UserJobService,
+) UserJobController {
+	return UserJobController{
+		logger:         logger,
+		userJobService: userJobService,
+	}
+}
+
+// currentUserID 当前登录用户ID
+func (a UserJobController) currentUserID(ctx echo.Context) uint64 {
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	if claims == nil {
+		return 0
+	}
+	return claims.ID
+}
+
+// List 我的定时任务
+// @tags UserJob
+// @summary My Jobs
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.UserJob} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/my/jobs [get]
+func (a UserJobController) List(ctx echo.Context) error {
+	jobs, err := a.userJobService.List(a.currentUserID(ctx))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: jobs}.JSON(ctx)
+}
+
+// Catalog 可用任务类型及配额
+// @tags UserJob
+// @summary My Job Types
+// @produce application/json
+// @success 200 {object} echox.Response{data=system.UserJobCatalogVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/my/jobs/types [get]
+func (a UserJobController) Catalog(ctx echo.Context) error {
+	catalog, err := a.userJobService.Catalog(a.currentUserID(ctx))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: catalog}.JSON(ctx)
+}
+
+// Create 新增定时任务
+// @tags UserJob
+// @summary My Job Create
+// @produce application/json
+// @param data body system.UserJobForm true "UserJobForm"
+// @success 200 {object} echox.Response{data=system.UserJob} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 403 {object} echox.Response "quota exceeded"
+// @router /api/v1/my/jobs [post]
+func (a UserJobController) Create(ctx echo.Context) error {
+	form := new(system.UserJobForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	job, err := a.userJobService.Create(form, a.currentUserID(ctx))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: job}.JSON(ctx)
+}
+
+// Update 修改定时任务
+// @tags UserJob
+// @summary My Job Update By ID
+// @produce application/json
+// @param id path int true "任务ID"
+// @param data body system.UserJobForm true "UserJobForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/my/jobs/{id} [put]
+func (a UserJobController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.UserJobForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.userJobService.Update(id, form, a.currentUserID(ctx)); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除定时任务
+// @tags UserJob
+// @summary My Job Delete By ID
+// @produce application/json
+// @param id path int true "任务ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/my/jobs/{id} [delete]
+func (a UserJobController) Delete(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.userJobService.Delete(id, a.currentUserID(ctx)); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Run 立即执行一次定时任务
+// @tags UserJob
+// @summary My Job Run Now
+// @produce application/json
+// @param id path int true "任务ID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/my/jobs/{id}/run [post]
+func (a UserJobController) Run(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	if err := a.userJobService.RunNow(id, a.currentUserID(ctx)); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
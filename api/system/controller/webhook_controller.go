@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// WebhookController Webhook 控制器
+type WebhookController struct {
+	logger         lib.Logger
+	webhookService service.WebhookService
+}
+
+// NewWebhookController creates new webhook controller
+func NewWebhookController(
+	logger lib.Logger,
+	webhookService service.WebhookService,
+) WebhookController {
+	return WebhookController{
+		logger:         logger,
+		webhookService: webhookService,
+	}
+}
+
+// Query Webhook 分页列表
+// @tags Webhook
+// @summary Webhook Query
+// @produce application/json
+// @param data query system.WebhookQueryParam true "WebhookQueryParam"
+// @success 200 {object} echox.Response{data=[]system.Webhook} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/webhooks [get]
+func (a WebhookController) Query(ctx echo.Context) error {
+	param := new(system.WebhookQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.webhookService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// GetEvents 可订阅的事件
+// @tags Webhook
+// @summary Webhook Events
+// @produce application/json
+// @success 200 {object} echox.Response{data=[]system.WebhookEventOption} "ok"
+// @router /api/v1/webhooks/events [get]
+func (a WebhookController) GetEvents(ctx echo.Context) error {
+	return echox.Response{Code: http.StatusOK, Data: a.webhookService.Events()}.JSON(ctx)
+}
+
+// Get Webhook 详情，不返回密钥
+// @tags Webhook
+// @summary Webhook Get By ID
+// @produce application/json
+// @param id path int true "WebhookID"
+// @success 200 {object} echox.Response{data=system.Webhook} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/webhooks/{id} [get]
+func (a WebhookController) Get(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	hook, err := a.webhookService.Get(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: hook}.JSON(ctx)
+}
+
+// Create 新增 Webhook，未填写密钥时自动生成，密钥只在新增时返回
+// @tags Webhook
+// @summary Webhook Create
+// @produce application/json
+// @param data body system.WebhookForm true "WebhookForm"
+// @success 200 {object} echox.Response{data=system.WebhookCreatedVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/webhooks [post]
+func (a WebhookController) Create(ctx echo.Context) error {
+	form := new(system.WebhookForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	created, err := a.webhookService.WithTrx(trxHandle).Create(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: created}.JSON(ctx)
+}
+
+// Update 修改 Webhook，密钥为空时保持不变
+// @tags Webhook
+// @summary Webhook Update By ID
+// @produce application/json
+// @param id path int true "WebhookID"
+// @param data body system.WebhookForm true "WebhookForm"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/webhooks/{id} [put]
+func (a WebhookController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	form := new(system.WebhookForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var updateBy uint64
+	if claims != nil {
+		updateBy = claims.ID
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.webhookService.WithTrx(trxHandle).Update(id, form, updateBy); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Delete 删除 Webhook，投递记录保留
+// @tags Webhook
+// @summary Webhook Delete By ID
+// @produce application/json
+// @param id path int true "WebhookID"
+// @success 200 {object} echox.Response "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/webhooks/{id} [delete]
+func (a WebhookController) Delete(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	trxHandle := ctx.Get(constants.DBTransaction).(*gorm.DB)
+	if err := a.webhookService.WithTrx(trxHandle).Delete(id); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK}.JSON(ctx)
+}
+
+// Ping 向 Webhook 投递测试事件，返回投递记录，投递结果通过投递记录查看
+// @tags Webhook
+// @summary Webhook Ping
+// @produce application/json
+// @param id path int true "WebhookID"
+// @success 200 {object} echox.Response{data=system.WebhookDelivery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/webhooks/{id}/ping [post]
+func (a WebhookController) Ping(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	delivery, err := a.webhookService.Ping(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: delivery}.JSON(ctx)
+}
+
+// QueryDeliveries 投递记录分页列表，可按 Webhook、事件、状态过滤
+// @tags Webhook
+// @summary Webhook Delivery Query
+// @produce application/json
+// @param data query system.WebhookDeliveryQueryParam true "WebhookDeliveryQueryParam"
+// @success 200 {object} echox.Response{data=[]system.WebhookDelivery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/webhooks/deliveries [get]
+func (a WebhookController) QueryDeliveries(ctx echo.Context) error {
+	param := new(system.WebhookDeliveryQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.webhookService.QueryDeliveries(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// GetDelivery 投递记录详情，包含请求体与最后一次响应
+// @tags Webhook
+// @summary Webhook Delivery Get By ID
+// @produce application/json
+// @param id path int true "投递记录ID"
+// @success 200 {object} echox.Response{data=system.WebhookDelivery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/webhooks/deliveries/{id} [get]
+func (a WebhookController) GetDelivery(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	delivery, err := a.webhookService.GetDelivery(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: delivery}.JSON(ctx)
+}
+
+// Redeliver 以原有的事件 ID 与内容重新投递，返回新的投递记录
+// @tags Webhook
+// @summary Webhook Redeliver
+// @produce application/json
+// @param id path int true "投递记录ID"
+// @success 200 {object} echox.Response{data=system.WebhookDelivery} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/webhooks/deliveries/{id}/redeliver [post]
+func (a WebhookController) Redeliver(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	delivery, err := a.webhookService.Redeliver(id)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: delivery}.JSON(ctx)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// WsEventController WebSocket 推送送达报告控制器
+type WsEventController struct {
+	logger         lib.Logger
+	wsEventService service.WsEventService
+}
+
+// NewWsEventController creates new websocket event controller
+func NewWsEventController(
+	logger lib.Logger,
+	wsEventService service.WsEventService,
+) WsEventController {
+	return WsEventController{
+		logger:         logger,
+		wsEventService: wsEventService,
+	}
+}
+
+// Query 推送事件送达报告
+// @tags WsEvent
+// @summary WebSocket Event Delivery Report
+// @produce application/json
+// @param data query system.WsEventQueryParam true "WsEventQueryParam"
+// @success 200 {object} echox.Response{data=[]system.WsEvent} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/ws-events [get]
+func (a WsEventController) Query(ctx echo.Context) error {
+	param := new(system.WsEventQueryParam)
+	if err := ctx.Bind(param); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	qr, err := a.wsEventService.Query(param)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{
+		Code: http.StatusOK,
+		Data: qr.List,
+		Page: &echox.PageInfo{
+			Total:    qr.Pagination.Total,
+			PageNum:  qr.Pagination.PageNum,
+			PageSize: qr.Pagination.PageSize,
+			HasNext:  qr.Pagination.HasNext,
+		},
+	}.JSON(ctx)
+}
+
+// Get 推送事件的逐用户送达情况
+// @tags WsEvent
+// @summary WebSocket Event Deliveries
+// @produce application/json
+// @param eventId path string true "事件ID"
+// @success 200 {object} echox.Response{data=system.WsEventDetailVO} "ok"
+// @failure 400 {object} echox.Response "bad request"
+// @failure 404 {object} echox.Response "not found"
+// @router /api/v1/ws-events/{eventId} [get]
+func (a WsEventController) Get(ctx echo.Context) error {
+	detail, err := a.wsEventService.Get(ctx.Param("eventId"))
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: detail}.JSON(ctx)
+}
+
+// Banner 向全部在线用户推送系统横幅
+// @tags WsEvent
+// @summary Push System Banner
+// @produce application/json
+// @param data body system.WsBannerForm true "WsBannerForm"
+// @success 200 {object} echox.Response{data=string} "eventId"
+// @failure 400 {object} echox.Response "bad request"
+// @router /api/v1/ws-events/banner [post]
+func (a WsEventController) Banner(ctx echo.Context) error {
+	form := new(system.WsBannerForm)
+	if err := ctx.Bind(form); err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	claims, _ := ctx.Get(constants.CurrentUser).(*dto.JwtClaims)
+	var createBy uint64
+	if claims != nil {
+		createBy = claims.ID
+	}
+
+	eventID, err := a.wsEventService.Banner(form, createBy)
+	if err != nil {
+		return echox.Response{Code: http.StatusBadRequest, Message: err}.JSON(ctx)
+	}
+
+	return echox.Response{Code: http.StatusOK, Data: eventID}.JSON(ctx)
+}
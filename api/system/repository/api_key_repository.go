@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ApiKeyRepository API Key 仓库
+type ApiKeyRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewApiKeyRepository creates a new api key repository
+func NewApiKeyRepository(db lib.Database, logger lib.Logger) ApiKeyRepository {
+	return ApiKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a ApiKeyRepository) WithTrx(trxHandle *gorm.DB) ApiKeyRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// ListByUser 获取用户的全部 API Key
+func (a ApiKeyRepository) ListByUser(userID uint64) (system.ApiKeys, error) {
+	list := make(system.ApiKeys, 0)
+	if err := a.db.ORM.Where("user_id = ?", userID).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// GetByHash 根据密钥摘要获取 API Key
+func (a ApiKeyRepository) GetByHash(keyHash string) (*system.ApiKey, error) {
+	apiKey := new(system.ApiKey)
+	if ok, err := QueryOne(a.db.ORM.Model(apiKey).Where("key_hash = ?", keyHash), apiKey); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.ApiKeyNotFound
+	}
+
+	return apiKey, nil
+}
+
+func (a ApiKeyRepository) Create(apiKey *system.ApiKey) error {
+	if err := a.db.ORM.Create(apiKey).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteByUser 删除用户的 API Key，不存在或不属于该用户时返回 ApiKeyNotFound
+func (a ApiKeyRepository) DeleteByUser(userID, id uint64) error {
+	result := a.db.ORM.Where("id = ? AND user_id = ?", id, userID).Delete(&system.ApiKey{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	} else if result.RowsAffected == 0 {
+		return errors.ApiKeyNotFound
+	}
+
+	return nil
+}
+
+// DeleteByUserIDs 删除多个用户的全部 API Key
+func (a ApiKeyRepository) DeleteByUserIDs(userIDs []uint64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Where("user_id IN (?)", userIDs).Delete(&system.ApiKey{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// UpdateLastUsed 记录最近一次使用的时间和客户端地址
+func (a ApiKeyRepository) UpdateLastUsed(id uint64, ip string, usedAt time.Time) error {
+	result := a.db.ORM.Model(&system.ApiKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_time": usedAt,
+		"last_used_ip":   ip,
+	})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
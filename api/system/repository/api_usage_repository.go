@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// apiUsageSorts 排行排序字段
+var apiUsageSorts = map[string]string{
+	"requests":  "request_count DESC",
+	"errors":    "error_count DESC",
+	"errorRate": "SUM(error_count) * 1.0 / SUM(request_count) DESC",
+}
+
+// ApiUsageRepository database structure
+type ApiUsageRepository struct {
+	db       lib.Database
+	logger   lib.Logger
+	dbCompat lib.DBCompat
+}
+
+// NewApiUsageRepository creates a new api usage repository
+func NewApiUsageRepository(db lib.Database, logger lib.Logger, dbCompat lib.DBCompat) ApiUsageRepository {
+	return ApiUsageRepository{
+		db:       db,
+		logger:   logger,
+		dbCompat: dbCompat,
+	}
+}
+
+// Accumulate 批量累加统计，同一小时、用户、路由的记录合并到一行
+func (a ApiUsageRepository) Accumulate(list system.ApiUsages) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "bucket_time"}, {Name: "user_id"}, {Name: "username"}, {Name: "method"}, {Name: "route"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"request_count":      a.dbCompat.Increment("request_count"),
+			"error_count":        a.dbCompat.Increment("error_count"),
+			"server_error_count": a.dbCompat.Increment("server_error_count"),
+			"duration_ms":        a.dbCompat.Increment("duration_ms"),
+		}),
+	}).CreateInBatches(list, 200)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Query 分页查询汇总明细
+func (a ApiUsageRepository) Query(param *system.ApiUsageQueryParam) (*system.ApiUsageQueryResult, error) {
+	db := a.filter(a.db.ORM.Model(&system.ApiUsage{}), param.From, param.To)
+
+	if v := param.UserID; v != 0 {
+		db = db.Where("user_id = ?", v)
+	}
+
+	if v := param.Route; v != "" {
+		db = db.Where("route LIKE ?", "%"+v+"%")
+	}
+
+	db = db.Order("bucket_time DESC").Order("request_count DESC")
+
+	list := make(system.ApiUsages, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.ApiUsageQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
+
+// TopUsers 按用户汇总排行
+func (a ApiUsageRepository) TopUsers(param *system.ApiUsageTopParam) ([]*system.ApiUsageUserVO, error) {
+	var list []*system.ApiUsageUserVO
+
+	db := a.filter(a.db.ORM.Model(&system.ApiUsage{}), param.From, param.To).
+		Select("user_id, username, " + apiUsageSums).
+		Group("user_id, username")
+
+	if err := a.top(db, param).Scan(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, item := range list {
+		item.ErrorRate, item.AvgDurationMs = apiUsageRates(item.RequestCount, item.ErrorCount, item.AvgDurationMs)
+	}
+
+	return list, nil
+}
+
+// TopRoutes 按路由汇总排行
+func (a ApiUsageRepository) TopRoutes(param *system.ApiUsageTopParam) ([]*system.ApiUsageRouteVO, error) {
+	var list []*system.ApiUsageRouteVO
+
+	db := a.filter(a.db.ORM.Model(&system.ApiUsage{}), param.From, param.To).
+		Select("method, route, " + apiUsageSums).
+		Group("method, route")
+
+	if err := a.top(db, param).Scan(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, item := range list {
+		item.ErrorRate, item.AvgDurationMs = apiUsageRates(item.RequestCount, item.ErrorCount, item.AvgDurationMs)
+	}
+
+	return list, nil
+}
+
+// apiUsageSums 汇总字段，累计耗时先扫描到 avg_duration_ms 再换算为平均值
+const apiUsageSums = "SUM(request_count) AS request_count, SUM(error_count) AS error_count, " +
+	"SUM(server_error_count) AS server_error_count, SUM(duration_ms) AS avg_duration_ms"
+
+func apiUsageRates(requests, errorCount int64, durationMs float64) (float64, float64) {
+	if requests == 0 {
+		return 0, 0
+	}
+	return float64(errorCount) / float64(requests), durationMs / float64(requests)
+}
+
+func (a ApiUsageRepository) filter(db *gorm.DB, from, to string) *gorm.DB {
+	if from != "" {
+		db = db.Where("bucket_time >= ?", from)
+	}
+
+	if to != "" {
+		if len(to) == len("2006-01-02") {
+			to += " 23:59:59"
+		}
+		db = db.Where("bucket_time <= ?", to)
+	}
+
+	return db
+}
+
+func (a ApiUsageRepository) top(db *gorm.DB, param *system.ApiUsageTopParam) *gorm.DB {
+	order, ok := apiUsageSorts[param.Sort]
+	if !ok {
+		order = apiUsageSorts["requests"]
+	}
+
+	limit := param.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	return db.Order(order).Limit(limit)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// CasbinRuleRepository database structure
+type CasbinRuleRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewCasbinRuleRepository creates a new casbin rule repository
+func NewCasbinRuleRepository(db lib.Database, logger lib.Logger) CasbinRuleRepository {
+	return CasbinRuleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a CasbinRuleRepository) WithTrx(trxHandle *gorm.DB) CasbinRuleRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 分页查询策略
+func (a CasbinRuleRepository) Query(param *system.PolicyQueryParam) (*system.PolicyQueryResult, error) {
+	db := a.db.ORM.Model(&system.CasbinRule{})
+
+	if v := param.PType; v != "" {
+		db = db.Where("ptype = ?", v)
+	}
+
+	if v := param.V0; v != "" {
+		db = db.Where("v0 LIKE ?", "%"+v+"%")
+	}
+
+	if v := param.V1; v != "" {
+		db = db.Where("v1 LIKE ?", "%"+v+"%")
+	}
+
+	db = db.Order("ptype DESC, v0, v1, id")
+
+	list := make(system.CasbinRules, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.PolicyQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
+
+// List 获取全部策略，按 ID 排序以保持策略的先后顺序
+func (a CasbinRuleRepository) List() (system.CasbinRules, error) {
+	list := make(system.CasbinRules, 0)
+
+	result := a.db.ORM.Order("id").Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// Get 获取策略
+func (a CasbinRuleRepository) Get(id uint64) (*system.CasbinRule, error) {
+	rule := new(system.CasbinRule)
+
+	if ok, err := QueryOne(a.db.ORM.Model(rule).Where("id=?", id), rule); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.PolicyRecordNotFound
+	}
+
+	return rule, nil
+}
+
+// Exists 是否存在相同的策略，excludeID 用于更新时排除自身
+func (a CasbinRuleRepository) Exists(rule *system.CasbinRule, excludeID uint64) (bool, error) {
+	var count int64
+
+	db := a.db.ORM.Model(&system.CasbinRule{}).
+		Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ?", rule.PType, rule.V0, rule.V1, rule.V2)
+	if excludeID != 0 {
+		db = db.Where("id <> ?", excludeID)
+	}
+
+	if result := db.Count(&count); result.Error != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return count > 0, nil
+}
+
+// Create 创建策略
+func (a CasbinRuleRepository) Create(rule *system.CasbinRule) error {
+	result := a.db.ORM.Create(rule)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Update 更新策略
+func (a CasbinRuleRepository) Update(id uint64, rule *system.CasbinRule) error {
+	result := a.db.ORM.Model(&system.CasbinRule{}).Where("id=?", id).
+		Select("ptype", "v0", "v1", "v2").Updates(rule)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// BatchDelete 批量删除策略
+func (a CasbinRuleRepository) BatchDelete(ids []uint64) error {
+	result := a.db.ORM.Where("id IN ?", ids).Delete(&system.CasbinRule{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Import 在一个事务中导入策略，replace 为 true 时先清空现有策略
+// 已存在的策略跳过，返回实际导入的条数
+func (a CasbinRuleRepository) Import(rules system.CasbinRules, replace bool) (int, error) {
+	imported := 0
+
+	err := a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("1 = 1").Delete(&system.CasbinRule{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, rule := range rules {
+			var count int64
+			if err := tx.Model(&system.CasbinRule{}).
+				Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ?", rule.PType, rule.V0, rule.V1, rule.V2).
+				Count(&count).Error; err != nil {
+				return err
+			} else if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(rule).Error; err != nil {
+				return err
+			}
+			imported++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return imported, nil
+}
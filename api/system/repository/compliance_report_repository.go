@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ComplianceReportRepository database structure
+type ComplianceReportRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewComplianceReportRepository creates a new compliance report repository
+func NewComplianceReportRepository(db lib.Database, logger lib.Logger) ComplianceReportRepository {
+	return ComplianceReportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create 创建报表记录
+func (a ComplianceReportRepository) Create(report *system.ComplianceReport) error {
+	result := a.db.ORM.Create(report)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Get 获取报表记录
+func (a ComplianceReportRepository) Get(id uint64) (*system.ComplianceReport, error) {
+	report := new(system.ComplianceReport)
+
+	if ok, err := QueryOne(a.db.ORM.Model(report).Where("id=?", id), report); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.DatabaseRecordNotFound
+	}
+
+	return report, nil
+}
+
+// Update 更新报表记录
+func (a ComplianceReportRepository) Update(id uint64, updates map[string]interface{}) error {
+	result := a.db.ORM.Model(&system.ComplianceReport{}).Where("id=?", id).Updates(updates)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Query 分页查询报表记录
+func (a ComplianceReportRepository) Query(param *system.ComplianceReportQueryParam) (*system.ComplianceReportQueryResult, error) {
+	db := a.db.ORM.Model(&system.ComplianceReport{})
+
+	if v := param.Status; v != "" {
+		db = db.Where("status = ?", v)
+	}
+
+	db = db.Order("id DESC")
+
+	list := make(system.ComplianceReports, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.ComplianceReportQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
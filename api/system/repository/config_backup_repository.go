@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ConfigBackupRepository database structure
+type ConfigBackupRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewConfigBackupRepository creates a new config backup repository
+func NewConfigBackupRepository(db lib.Database, logger lib.Logger) ConfigBackupRepository {
+	return ConfigBackupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a ConfigBackupRepository) WithTrx(trxHandle *gorm.DB) ConfigBackupRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Create 创建备份记录
+func (a ConfigBackupRepository) Create(backup *system.ConfigBackup) error {
+	result := a.db.ORM.Create(backup)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Get 获取备份记录
+func (a ConfigBackupRepository) Get(id uint64) (*system.ConfigBackup, error) {
+	backup := new(system.ConfigBackup)
+
+	if ok, err := QueryOne(a.db.ORM.Model(backup).Where("id=?", id), backup); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.DatabaseRecordNotFound
+	}
+
+	return backup, nil
+}
+
+// Latest 获取最近一次备份，没有备份时返回 nil
+func (a ConfigBackupRepository) Latest() (*system.ConfigBackup, error) {
+	backup := new(system.ConfigBackup)
+
+	if ok, err := QueryOne(a.db.ORM.Model(backup).Order("id DESC"), backup); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return backup, nil
+}
+
+// ListOutdated 获取保留最近 keep 份之外的旧备份
+func (a ConfigBackupRepository) ListOutdated(keep int) (system.ConfigBackups, error) {
+	list := make(system.ConfigBackups, 0)
+
+	err := a.db.ORM.Model(&system.ConfigBackup{}).Order("id DESC").Offset(keep).Limit(-1).Find(&list).Error
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Delete 删除备份记录
+func (a ConfigBackupRepository) Delete(id uint64) error {
+	result := a.db.ORM.Where("id=?", id).Delete(&system.ConfigBackup{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Query 分页查询备份记录
+func (a ConfigBackupRepository) Query(param *system.ConfigBackupQueryParam) (*system.ConfigBackupQueryResult, error) {
+	db := a.db.ORM.Model(&system.ConfigBackup{})
+
+	if v := param.Trigger; v != "" {
+		db = db.Where("trigger_type = ?", v)
+	}
+
+	db = db.Order("id DESC")
+
+	list := make(system.ConfigBackups, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.ConfigBackupQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
@@ -134,3 +134,22 @@ func (a ConfigRepository) Delete(id uint64, deletedBy uint64) error {
 
 	return nil
 }
+
+// Revive 恢复按键名软删除的配置，不存在已删除记录时返回 nil
+// 键名上有唯一索引，重新启用同一键名时需复用已删除的记录
+func (a ConfigRepository) Revive(key string) (*system.Config, error) {
+	config := new(system.Config)
+
+	if ok, err := QueryOne(a.db.ORM.Model(config).Where("config_key=? AND is_deleted=?", key, 1), config); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	if err := a.db.ORM.Model(&system.Config{}).Where("id=?", config.ID).Update("is_deleted", 0).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+	config.IsDeleted = 0
+
+	return config, nil
+}
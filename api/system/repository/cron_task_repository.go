@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// CronTaskRepository database structure
+type CronTaskRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewCronTaskRepository creates a new cron task repository
+func NewCronTaskRepository(db lib.Database, logger lib.Logger) CronTaskRepository {
+	return CronTaskRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a CronTaskRepository) WithTrx(trxHandle *gorm.DB) CronTaskRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// List 全部持久化的定时任务
+func (a CronTaskRepository) List() (system.CronTasks, error) {
+	list := make(system.CronTasks, 0)
+
+	if err := a.db.ORM.Order("id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// GetByName 按名称获取定时任务
+func (a CronTaskRepository) GetByName(name string) (*system.CronTask, error) {
+	task := new(system.CronTask)
+
+	if ok, err := QueryOne(a.db.ORM.Model(task).Where("name = ?", name), task); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.CronTaskNotFound
+	}
+
+	return task, nil
+}
+
+func (a CronTaskRepository) Create(task *system.CronTask) error {
+	if err := a.db.ORM.Create(task).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a CronTaskRepository) Update(name string, values map[string]interface{}) error {
+	if err := a.db.ORM.Model(&system.CronTask{}).Where("name = ?", name).Updates(values).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// RecordRun 记录最近一次执行结果，不更新修改时间
+func (a CronTaskRepository) RecordRun(name string, values map[string]interface{}) error {
+	if err := a.db.ORM.Model(&system.CronTask{}).Where("name = ?", name).UpdateColumns(values).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a CronTaskRepository) Delete(name string) error {
+	if err := a.db.ORM.Where("name = ?", name).Delete(&system.CronTask{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// CreateRecord 写入一条执行记录
+func (a CronTaskRepository) CreateRecord(record *system.CronTaskRecord) error {
+	if err := a.db.ORM.Create(record).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// ListRecords 任务最近 limit 次执行记录，按开始时间倒序
+func (a CronTaskRepository) ListRecords(name string, limit int) (system.CronTaskRecords, error) {
+	list := make(system.CronTaskRecords, 0)
+
+	if err := a.db.ORM.Where("task_name = ?", name).Order("start_time DESC, id DESC").Limit(limit).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// LatestRecords 每个任务最近一次执行记录，按任务名称索引
+func (a CronTaskRepository) LatestRecords() (map[string]*system.CronTaskRecord, error) {
+	list := make(system.CronTaskRecords, 0)
+
+	latest := a.db.ORM.Model(&system.CronTaskRecord{}).Select("MAX(id)").Group("task_name")
+	if err := a.db.ORM.Where("id IN (?)", latest).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	result := make(map[string]*system.CronTaskRecord, len(list))
+	for _, record := range list {
+		result[record.TaskName] = record
+	}
+	return result, nil
+}
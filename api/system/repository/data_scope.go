@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ScopeByDept 按数据权限过滤带部门字段的记录，可访问部门内的记录以及 userColumn 为本人的记录
+// scope 为 nil 或全部数据时不过滤；userColumn 为空时只按部门过滤
+func ScopeByDept(db *gorm.DB, scope *system.DataScope, deptColumn, userColumn string) *gorm.DB {
+	if scope == nil || scope.All {
+		return db
+	}
+
+	switch {
+	case len(scope.DeptIDs) > 0 && scope.UserID > 0 && userColumn != "":
+		return db.Where(deptColumn+" IN (?) OR "+userColumn+" = ?", scope.DeptIDs, scope.UserID)
+	case len(scope.DeptIDs) > 0:
+		return db.Where(deptColumn+" IN (?)", scope.DeptIDs)
+	case scope.UserID > 0 && userColumn != "":
+		return db.Where(userColumn+" = ?", scope.UserID)
+	default:
+		return db.Where("1 = 0")
+	}
+}
+
+// ScopeByUser 按数据权限过滤只记录了所属用户的记录（创建人、操作人等），按该用户当前所在的部门判断
+func ScopeByUser(db *gorm.DB, scope *system.DataScope, userColumn string) *gorm.DB {
+	if scope == nil || scope.All {
+		return db
+	}
+
+	if len(scope.DeptIDs) == 0 {
+		return ScopeByDept(db, scope, "", userColumn)
+	}
+
+	users := db.Session(&gorm.Session{NewDB: true}).Model(&system.User{}).
+		Select("id").
+		Where("dept_id IN (?)", scope.DeptIDs)
+	if scope.UserID > 0 {
+		return db.Where(userColumn+" IN (?) OR "+userColumn+" = ?", users, scope.UserID)
+	}
+	return db.Where(userColumn+" IN (?)", users)
+}
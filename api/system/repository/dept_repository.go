@@ -142,6 +142,30 @@ func (a DeptRepository) DeleteByTreePath(deptId uint64, deletedBy uint64) error
 	return nil
 }
 
+// ListSubtree 获取部门的所有子孙部门（不含自身）
+func (a DeptRepository) ListSubtree(deptId uint64) (system.Depts, error) {
+	treePathExpr := a.dbCompat.TreePathLike("tree_path")
+
+	var list system.Depts
+	if err := a.db.ORM.Model(&system.Dept{}).
+		Where("is_deleted = ? AND "+treePathExpr+" LIKE ?", 0, "%,"+strconv.FormatUint(deptId, 10)+",%").
+		Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// UpdateTreePath 修改部门的 tree_path
+func (a DeptRepository) UpdateTreePath(id uint64, treePath string) error {
+	result := a.db.ORM.Model(&system.Dept{}).Where("id=?", id).Update("tree_path", treePath)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 // GetAllEnabled 获取所有启用的部门
 func (a DeptRepository) GetAllEnabled() (system.Depts, error) {
 	var list system.Depts
@@ -175,3 +199,22 @@ func (a DeptRepository) GetByIDs(ids []uint64) (map[uint64]*system.Dept, error)
 
 	return result, nil
 }
+
+// GetByCodes 根据编号批量获取部门Map（包含已删除部门，编号唯一索引不区分删除状态）
+func (a DeptRepository) GetByCodes(codes []string) (map[string]*system.Dept, error) {
+	result := make(map[string]*system.Dept)
+	if len(codes) == 0 {
+		return result, nil
+	}
+
+	var list system.Depts
+	if err := a.db.ORM.Model(&system.Dept{}).Where("code IN (?)", codes).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, dept := range list {
+		result[dept.Code] = dept
+	}
+
+	return result, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DeptRoleRuleRepository database structure
+type DeptRoleRuleRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewDeptRoleRuleRepository creates a new dept role rule repository
+func NewDeptRoleRuleRepository(db lib.Database, logger lib.Logger) DeptRoleRuleRepository {
+	return DeptRoleRuleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a DeptRoleRuleRepository) WithTrx(trxHandle *gorm.DB) DeptRoleRuleRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 分页查询规则
+func (a DeptRoleRuleRepository) Query(param *system.DeptRoleRuleQueryParam) (*system.DeptRoleRuleQueryResult, error) {
+	db := a.db.ORM.Model(&system.DeptRoleRule{})
+
+	if v := param.DeptID; v != 0 {
+		db = db.Where("dept_id = ?", v)
+	}
+
+	if v := param.RoleID; v != 0 {
+		db = db.Where("role_id = ?", v)
+	}
+
+	db = db.Order("dept_id, role_id")
+
+	list := make(system.DeptRoleRules, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.DeptRoleRuleQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
+
+// List 获取全部规则
+func (a DeptRoleRuleRepository) List() (system.DeptRoleRules, error) {
+	list := make(system.DeptRoleRules, 0)
+
+	result := a.db.ORM.Order("id").Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// Get 获取规则
+func (a DeptRoleRuleRepository) Get(id uint64) (*system.DeptRoleRule, error) {
+	rule := new(system.DeptRoleRule)
+
+	if ok, err := QueryOne(a.db.ORM.Model(rule).Where("id=?", id), rule); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.DeptRoleRuleNotFound
+	}
+
+	return rule, nil
+}
+
+// Exists 部门是否已映射该角色，excludeID 用于更新时排除自身
+func (a DeptRoleRuleRepository) Exists(deptID, roleID, excludeID uint64) (bool, error) {
+	var count int64
+
+	db := a.db.ORM.Model(&system.DeptRoleRule{}).Where("dept_id = ? AND role_id = ?", deptID, roleID)
+	if excludeID != 0 {
+		db = db.Where("id <> ?", excludeID)
+	}
+
+	if result := db.Count(&count); result.Error != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return count > 0, nil
+}
+
+// Create 创建规则
+func (a DeptRoleRuleRepository) Create(rule *system.DeptRoleRule) error {
+	result := a.db.ORM.Create(rule)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Update 更新规则
+func (a DeptRoleRuleRepository) Update(id uint64, rule *system.DeptRoleRule) error {
+	result := a.db.ORM.Model(&system.DeptRoleRule{}).Where("id=?", id).
+		Select("dept_id", "role_id", "include_children", "remark").Updates(rule)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// BatchDelete 批量删除规则
+func (a DeptRoleRuleRepository) BatchDelete(ids []uint64) error {
+	result := a.db.ORM.Where("id IN ?", ids).Delete(&system.DeptRoleRule{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// DeleteByRoleID 删除角色的全部规则
+func (a DeptRoleRuleRepository) DeleteByRoleID(roleID uint64) error {
+	result := a.db.ORM.Where("role_id = ?", roleID).Delete(&system.DeptRoleRule{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/errors"
@@ -72,6 +74,37 @@ func (a DictItemRepository) GetByDictCode(dictCode string) (system.DictItems, er
 	return list, nil
 }
 
+// LatestUpdateTime 获取最后变更时间（包含已删除记录），无数据时返回零值
+func (a DictItemRepository) LatestUpdateTime() (time.Time, error) {
+	var list system.DictItems
+	if err := a.db.ORM.Model(&system.DictItem{}).Select("id", "update_time").
+		Order("update_time DESC").Limit(1).Find(&list).Error; err != nil {
+		return time.Time{}, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	if len(list) == 0 {
+		return time.Time{}, nil
+	}
+	return list[0].UpdateTime.Time(), nil
+}
+
+// ListChanged 增量同步：since 为空时返回全部未删除的字典项，否则返回 since 之后变更的字典项（包含已删除）
+func (a DictItemRepository) ListChanged(since *time.Time) (system.DictItems, error) {
+	db := a.db.ORM.Model(&system.DictItem{})
+	if since == nil {
+		db = db.Where("is_deleted = ?", 0)
+	} else {
+		db = db.Where("update_time >= ?", *since)
+	}
+
+	var list system.DictItems
+	if err := db.Order("dict_code").Order("sort").Order("id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
 // Get 获取字典项
 func (a DictItemRepository) Get(id uint64) (*system.DictItem, error) {
 	item := new(system.DictItem)
@@ -85,6 +118,19 @@ func (a DictItemRepository) Get(id uint64) (*system.DictItem, error) {
 	return item, nil
 }
 
+// GetDictCodesByIDs 获取字典项所属的字典编码（去重）
+func (a DictItemRepository) GetDictCodesByIDs(ids []uint64) ([]string, error) {
+	var codes []string
+	if err := a.db.ORM.Model(&system.DictItem{}).
+		Where("id IN ?", ids).
+		Distinct().
+		Pluck("dict_code", &codes).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return codes, nil
+}
+
 // Create 创建字典项
 func (a DictItemRepository) Create(item *system.DictItem) error {
 	result := a.db.ORM.Create(item)
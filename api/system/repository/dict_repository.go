@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/errors"
@@ -67,6 +69,37 @@ func (a DictRepository) GetAll() (system.Dicts, error) {
 	return list, nil
 }
 
+// LatestUpdateTime 获取最后变更时间（包含已删除记录），无数据时返回零值
+func (a DictRepository) LatestUpdateTime() (time.Time, error) {
+	var list system.Dicts
+	if err := a.db.ORM.Model(&system.Dict{}).Select("id", "update_time").
+		Order("update_time DESC").Limit(1).Find(&list).Error; err != nil {
+		return time.Time{}, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	if len(list) == 0 {
+		return time.Time{}, nil
+	}
+	return list[0].UpdateTime.Time(), nil
+}
+
+// ListChanged 增量同步：since 为空时返回全部未删除的字典，否则返回 since 之后变更的字典（包含已删除）
+func (a DictRepository) ListChanged(since *time.Time) (system.Dicts, error) {
+	db := a.db.ORM.Model(&system.Dict{})
+	if since == nil {
+		db = db.Where("is_deleted = ?", 0)
+	} else {
+		db = db.Where("update_time >= ?", *since)
+	}
+
+	var list system.Dicts
+	if err := db.Order("id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
 // Get 获取字典
 func (a DictRepository) Get(id uint64) (*system.Dict, error) {
 	dict := new(system.Dict)
@@ -174,3 +207,21 @@ func (a DictRepository) UpdateDictItemsCode(oldCode, newCode string) error {
 	return nil
 }
 
+// Revive 恢复按编码软删除的字典，不存在已删除记录时返回 nil
+// 编码上有唯一索引，重新启用同一编码时需复用已删除的记录
+func (a DictRepository) Revive(dictCode string) (*system.Dict, error) {
+	dict := new(system.Dict)
+
+	if ok, err := QueryOne(a.db.ORM.Model(dict).Where("dict_code=? AND is_deleted=?", dictCode, 1), dict); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	if err := a.db.ORM.Model(&system.Dict{}).Where("id=?", dict.ID).Update("is_deleted", 0).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+	dict.IsDeleted = 0
+
+	return dict, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DownloadMediaRepository database structure
+type DownloadMediaRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewDownloadMediaRepository creates a new download media repository
+func NewDownloadMediaRepository(db lib.Database, logger lib.Logger) DownloadMediaRepository {
+	return DownloadMediaRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Query 分页查询未删除任务中的音视频文件
+func (a DownloadMediaRepository) Query(param *system.DownloadMediaQueryParam) ([]*system.DownloadMedia, *dto.Pagination, error) {
+	db := a.db.ORM.Model(&system.DownloadMedia{}).
+		Where("download_task_id IN (?)", a.db.ORM.Model(&system.DownloadTask{}).Select("id"))
+
+	if v := param.Kind; v != "" {
+		db = db.Where("kind = ?", v)
+	}
+
+	if v := param.Keywords; v != "" {
+		db = db.Where("path LIKE ?", "%"+v+"%")
+	}
+
+	db = db.Order("id DESC")
+
+	list := make([]*system.DownloadMedia, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, pagination, nil
+}
+
+// ListByTask 任务的全部音视频文件，按路径排序
+func (a DownloadMediaRepository) ListByTask(taskID uint64) ([]*system.DownloadMedia, error) {
+	list := make([]*system.DownloadMedia, 0)
+	if err := a.db.ORM.Where("download_task_id = ?", taskID).Order("path").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// ListByTasks 多个任务的全部音视频文件
+func (a DownloadMediaRepository) ListByTasks(taskIDs []uint64) ([]*system.DownloadMedia, error) {
+	list := make([]*system.DownloadMedia, 0)
+	if len(taskIDs) == 0 {
+		return list, nil
+	}
+	if err := a.db.ORM.Where("download_task_id IN ?", taskIDs).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Replace 用新的提取结果替换任务原有的记录，返回被替换的记录
+func (a DownloadMediaRepository) Replace(taskID uint64, list []*system.DownloadMedia) ([]*system.DownloadMedia, error) {
+	old := make([]*system.DownloadMedia, 0)
+	err := a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("download_task_id = ?", taskID).Find(&old).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("download_task_id = ?", taskID).Delete(&system.DownloadMedia{}).Error; err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return nil
+		}
+		return tx.Create(&list).Error
+	})
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return old, nil
+}
+
+// DeleteByTasks 删除任务的全部记录
+func (a DownloadMediaRepository) DeleteByTasks(taskIDs []uint64) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	if err := a.db.ORM.Where("download_task_id IN ?", taskIDs).Delete(&system.DownloadMedia{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
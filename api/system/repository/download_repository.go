@@ -2,9 +2,11 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/system"
 )
 
@@ -58,7 +60,13 @@ func (a DownloadRepository) Query(param *system.DownloadTaskQueryParam) (*system
 		db = db.Where("created_at <= ?", v+" 23:59:59")
 	}
 
-	db = db.Order("created_at DESC")
+	if v := param.TagIDs; len(v) > 0 {
+		db = db.Where("id IN (?)", TaggedResourceIDs(db, system.TagResourceDownload, v))
+	}
+
+	db = ScopeByUser(db, param.DataScope, "owner_id")
+
+	db = QueryOrder(db, param.OrderParam, system.SavedQuerySortKeys[system.SavedQueryResourceDownload], "created_at DESC")
 
 	list := make(system.DownloadTasks, 0)
 	pagination, err := QueryPagination(db, param.PaginationParam, &list)
@@ -87,6 +95,35 @@ func (a DownloadRepository) Get(id uint64) (*system.DownloadTask, error) {
 	return task, nil
 }
 
+// ScopedIDs 返回 ids 中在数据权限范围内的下载任务ID
+func (a DownloadRepository) ScopedIDs(ids []uint64, scope *system.DataScope) ([]uint64, error) {
+	scoped := make([]uint64, 0, len(ids))
+	if len(ids) == 0 {
+		return scoped, nil
+	}
+
+	db := a.db.ORM.Model(&system.DownloadTask{}).Where("id IN (?)", ids)
+	db = ScopeByUser(db, scope, "owner_id")
+	if err := db.Pluck("id", &scoped).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return scoped, nil
+}
+
+// GetByIDs 批量获取下载任务
+func (a DownloadRepository) GetByIDs(ids []uint64) (system.DownloadTasks, error) {
+	list := make(system.DownloadTasks, 0)
+	if len(ids) == 0 {
+		return list, nil
+	}
+	if err := a.db.ORM.Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
 // GetByTaskID 根据任务ID获取下载任务
 func (a DownloadRepository) GetByTaskID(taskID string) (*system.DownloadTask, error) {
 	task := new(system.DownloadTask)
@@ -138,6 +175,51 @@ func (a DownloadRepository) UpdateStatus(id uint64, status string, downloaded, t
 	return nil
 }
 
+// UpdateSpeedLimit 更新下载任务的限速
+func (a DownloadRepository) UpdateSpeedLimit(id uint64, downloadLimit, uploadLimit int64) error {
+	result := a.db.ORM.Model(&system.DownloadTask{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"download_limit": downloadLimit,
+		"upload_limit":   uploadLimit,
+	})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// MarkTransferPending 将尚未转移的任务标记为等待转移，返回 false 表示任务已经提交过转移
+func (a DownloadRepository) MarkTransferPending(id uint64) (bool, error) {
+	result := a.db.ORM.Model(&system.DownloadTask{}).
+		Where("id = ? AND (transfer_status = '' OR transfer_status IS NULL)", id).
+		Update("transfer_status", system.DownloadTransferStatusPending)
+	if result.Error != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateTransfer 保存文件转移结果，转移到目录时同时将保存目录更新为目标目录
+func (a DownloadRepository) UpdateTransfer(id uint64, status, path string, files database.JSONB, errorMessage string) error {
+	updates := map[string]interface{}{
+		"transfer_status": status,
+		"transfer_path":   path,
+		"transfer_files":  files,
+		"transfer_error":  errorMessage,
+	}
+	if path != "" {
+		updates["save_path"] = path
+	}
+
+	result := a.db.ORM.Model(&system.DownloadTask{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 // Delete 删除下载任务
 func (a DownloadRepository) Delete(id uint64) error {
 	result := a.db.ORM.Where("id=?", id).Delete(&system.DownloadTask{})
@@ -184,6 +266,8 @@ func (a DownloadRepository) GetStatusCounts() (*system.DownloadTaskStatsVO, erro
 			stats.DownloadingCount = c.Count
 		case "seeding":
 			stats.SeedingCount = c.Count
+		case "paused":
+			stats.PausedCount = c.Count
 		case "completed":
 			stats.CompletedCount = c.Count
 		case "error":
@@ -194,11 +278,26 @@ func (a DownloadRepository) GetStatusCounts() (*system.DownloadTaskStatsVO, erro
 	return stats, nil
 }
 
+// CountByDownloaderStatus 按下载器和状态统计任务数量
+func (a DownloadRepository) CountByDownloaderStatus() ([]*system.DownloadStatusCount, error) {
+	var counts []*system.DownloadStatusCount
+	result := a.db.ORM.Model(&system.DownloadTask{}).
+		Select("downloader, status, count(*) as count").
+		Group("downloader, status").
+		Scan(&counts)
+
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return counts, nil
+}
+
 // GetActiveTaskIDs 获取活跃任务ID列表（用于状态同步）
 func (a DownloadRepository) GetActiveTaskIDs() ([]system.DownloadTask, error) {
 	var tasks []system.DownloadTask
 	result := a.db.ORM.Model(&system.DownloadTask{}).
-		Where("status IN ?", []string{"downloading", "seeding", "unknown", "queued"}).
+		Where("status IN ?", []string{"downloading", "seeding", "paused", "unknown", "queued"}).
 		Select("id, queue_task_id, task_id, hash, downloader").
 		Find(&tasks)
 
@@ -209,6 +308,65 @@ func (a DownloadRepository) GetActiveTaskIDs() ([]system.DownloadTask, error) {
 	return tasks, nil
 }
 
+// ListByDownloader 获取下载器的全部任务（用于与下载器会话对账）
+func (a DownloadRepository) ListByDownloader(name string) (system.DownloadTasks, error) {
+	var tasks system.DownloadTasks
+	result := a.db.ORM.Model(&system.DownloadTask{}).
+		Where("downloader = ?", name).
+		Select("id, task_id, hash, name, status").
+		Find(&tasks)
+
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return tasks, nil
+}
+
+// ListSavePaths 全部任务的保存目录（用于查找下载器临时目录中没有任务引用的目录）
+func (a DownloadRepository) ListSavePaths() ([]string, error) {
+	paths := make([]string, 0)
+	result := a.db.ORM.Model(&system.DownloadTask{}).
+		Where("save_path <> ''").
+		Distinct().
+		Pluck("save_path", &paths)
+
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return paths, nil
+}
+
+// CountActiveByDownloader 统计下载器的活跃任务数
+func (a DownloadRepository) CountActiveByDownloader(name string) (int64, error) {
+	var count int64
+	result := a.db.ORM.Model(&system.DownloadTask{}).
+		Where("downloader = ? AND status IN ?", name, []string{"downloading", "seeding", "paused", "unknown", "queued"}).
+		Count(&count)
+
+	if result.Error != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return count, nil
+}
+
+// GetActiveTasks 获取全部活跃任务
+func (a DownloadRepository) GetActiveTasks() (system.DownloadTasks, error) {
+	var tasks system.DownloadTasks
+	result := a.db.ORM.Model(&system.DownloadTask{}).
+		Where("status IN ?", []string{"downloading", "seeding", "paused", "unknown", "queued"}).
+		Order("id DESC").
+		Find(&tasks)
+
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return tasks, nil
+}
+
 // GetByQueueTaskID 根据队列任务ID获取下载任务
 func (a DownloadRepository) GetByQueueTaskID(queueTaskID uint64) (*system.DownloadTask, error) {
 	task := new(system.DownloadTask)
@@ -222,6 +380,21 @@ func (a DownloadRepository) GetByQueueTaskID(queueTaskID uint64) (*system.Downlo
 	return task, nil
 }
 
+// GetCountersForUpdate 在事务中锁定任务行并读取所有者与已同步的下载量、上传量
+func (a DownloadRepository) GetCountersForUpdate(id uint64) (*system.DownloadTask, error) {
+	task := new(system.DownloadTask)
+
+	db := a.db.ORM.Model(task).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "owner_id", "downloaded", "uploaded").Where("id = ?", id)
+	if ok, err := QueryOne(db, task); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.DatabaseRecordNotFound
+	}
+
+	return task, nil
+}
+
 // UpdateFromDownloader 从下载器同步更新任务完整信息
 func (a DownloadRepository) UpdateFromDownloader(id uint64, taskID, hash, name, savePath, status string, downloaded, total, downloadSpeed, uploaded, uploadSpeed int64, errorMessage string) error {
 	updates := map[string]interface{}{
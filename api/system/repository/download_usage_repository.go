@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DownloadUsageRepository database structure
+type DownloadUsageRepository struct {
+	db       lib.Database
+	logger   lib.Logger
+	dbCompat lib.DBCompat
+}
+
+// NewDownloadUsageRepository creates a new download usage repository
+func NewDownloadUsageRepository(db lib.Database, logger lib.Logger, dbCompat lib.DBCompat) DownloadUsageRepository {
+	return DownloadUsageRepository{
+		db:       db,
+		logger:   logger,
+		dbCompat: dbCompat,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a DownloadUsageRepository) WithTrx(trxHandle *gorm.DB) DownloadUsageRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Accumulate 累加用户当天的流量
+func (a DownloadUsageRepository) Accumulate(day string, userID uint64, downloaded, uploaded int64) error {
+	usage := &system.DownloadUsage{Day: day, UserID: userID, Downloaded: downloaded, Uploaded: uploaded}
+	result := a.db.ORM.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"downloaded": a.dbCompat.Increment("downloaded"),
+			"uploaded":   a.dbCompat.Increment("uploaded"),
+		}),
+	}).Create(usage)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Daily 用户在日期范围内的每日流量
+func (a DownloadUsageRepository) Daily(userID uint64, from, to string) ([]*system.DownloadUsageDayVO, error) {
+	list := make([]*system.DownloadUsageDayVO, 0)
+
+	err := a.db.ORM.Model(&system.DownloadUsage{}).
+		Select("day, downloaded, uploaded").
+		Where("user_id = ? AND day >= ? AND day <= ?", userID, from, to).
+		Order("day").
+		Scan(&list).Error
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Sum 用户在日期范围内的流量合计
+func (a DownloadUsageRepository) Sum(userID uint64, from, to string) (downloaded, uploaded int64, err error) {
+	var total struct {
+		Downloaded int64
+		Uploaded   int64
+	}
+
+	err = a.db.ORM.Model(&system.DownloadUsage{}).
+		Select("COALESCE(SUM(downloaded), 0) AS downloaded, COALESCE(SUM(uploaded), 0) AS uploaded").
+		Where("user_id = ? AND day >= ? AND day <= ?", userID, from, to).
+		Scan(&total).Error
+	if err != nil {
+		return 0, 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return total.Downloaded, total.Uploaded, nil
+}
+
+// ByUser 按用户汇总，按下载量降序
+func (a DownloadUsageRepository) ByUser(param *system.DownloadUsageQueryParam) ([]*system.DownloadUsageUserVO, error) {
+	list := make([]*system.DownloadUsageUserVO, 0)
+
+	db := a.joined(param).
+		Select("du.user_id, u.username, u.nickname, u.dept_id, d.name AS dept_name, " +
+			"SUM(du.downloaded) AS downloaded, SUM(du.uploaded) AS uploaded").
+		Group("du.user_id, u.username, u.nickname, u.dept_id, d.name").
+		Order("downloaded DESC").
+		Limit(param.Limit)
+
+	if err := db.Scan(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// ByDept 按用户当前所属部门汇总，按下载量降序
+func (a DownloadUsageRepository) ByDept(param *system.DownloadUsageQueryParam) ([]*system.DownloadUsageDeptVO, error) {
+	list := make([]*system.DownloadUsageDeptVO, 0)
+
+	db := a.joined(param).
+		Select("COALESCE(u.dept_id, 0) AS dept_id, d.name AS dept_name, COUNT(DISTINCT du.user_id) AS users, " +
+			"SUM(du.downloaded) AS downloaded, SUM(du.uploaded) AS uploaded").
+		Group("COALESCE(u.dept_id, 0), d.name").
+		Order("downloaded DESC").
+		Limit(param.Limit)
+
+	if err := db.Scan(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// joined 关联用户与部门并按日期、部门过滤
+func (a DownloadUsageRepository) joined(param *system.DownloadUsageQueryParam) *gorm.DB {
+	db := a.db.ORM.Table(a.db.TableName(&system.DownloadUsage{})+" du").
+		Joins("LEFT JOIN "+a.db.TableName(&system.User{})+" u ON u.id = du.user_id").
+		Joins("LEFT JOIN "+a.db.TableName(&system.Dept{})+" d ON d.id = u.dept_id").
+		Where("du.day >= ? AND du.day <= ?", param.From, param.To)
+
+	if v := param.DeptID; v != 0 {
+		db = db.Where("u.dept_id = ?", v)
+	}
+
+	return db
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DownloaderConfigRepository 运行时管理的下载器配置仓库
+type DownloaderConfigRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewDownloaderConfigRepository creates a new downloader config repository
+func NewDownloaderConfigRepository(db lib.Database, logger lib.Logger) DownloaderConfigRepository {
+	return DownloaderConfigRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a DownloaderConfigRepository) WithTrx(trxHandle *gorm.DB) DownloaderConfigRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// List 获取全部下载器配置
+func (a DownloaderConfigRepository) List() (system.DownloaderConfigs, error) {
+	list := make(system.DownloaderConfigs, 0)
+	if err := a.db.ORM.Model(&system.DownloaderConfig{}).Order("name").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Get 获取下载器配置
+func (a DownloaderConfigRepository) Get(id uint64) (*system.DownloaderConfig, error) {
+	conf := new(system.DownloaderConfig)
+
+	if ok, err := QueryOne(a.db.ORM.Model(conf).Where("id = ?", id), conf); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.DownloaderConfigNotFound
+	}
+
+	return conf, nil
+}
+
+// GetByName 根据名称获取下载器配置，不存在时返回 nil
+func (a DownloaderConfigRepository) GetByName(name string) (*system.DownloaderConfig, error) {
+	conf := new(system.DownloaderConfig)
+
+	if ok, err := QueryOne(a.db.ORM.Model(conf).Where("name = ?", name), conf); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return conf, nil
+}
+
+func (a DownloaderConfigRepository) Create(conf *system.DownloaderConfig) error {
+	if err := a.db.ORM.Create(conf).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a DownloaderConfigRepository) Update(id uint64, conf *system.DownloaderConfig) error {
+	result := a.db.ORM.Model(conf).Where("id = ?", id).
+		Select("name", "type", "server", "token", "user", "password", "temp_path", "category", "options", "remark", "update_by").
+		Updates(conf)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+func (a DownloaderConfigRepository) Delete(id uint64) error {
+	if err := a.db.ORM.Where("id = ?", id).Delete(&system.DownloaderConfig{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
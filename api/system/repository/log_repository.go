@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/errors"
@@ -54,7 +56,9 @@ func (a LogRepository) Query(param *system.LogQueryParam) (*system.LogQueryResul
 		db = db.Where("create_time <= ?", v+" 23:59:59")
 	}
 
-	db = db.Order("create_time DESC")
+	db = ScopeByUser(db, param.DataScope, "create_by")
+
+	db = QueryOrder(db, param.OrderParam, system.SavedQuerySortKeys[system.SavedQueryResourceLog], "create_time DESC")
 
 	list := make(system.Logs, 0)
 	pagination, err := QueryPagination(db, param.PaginationParam, &list)
@@ -70,6 +74,23 @@ func (a LogRepository) Query(param *system.LogQueryParam) (*system.LogQueryResul
 	return qr, nil
 }
 
+// ListHighlights 查询 since 之后的删除操作及指定模块的操作日志（按时间倒序，最多 limit 条）
+func (a LogRepository) ListHighlights(since time.Time, modules []string, limit int) (system.Logs, error) {
+	list := make(system.Logs, 0)
+
+	result := a.db.ORM.Omit("request_params", "response_content").
+		Where("create_time >= ?", since).
+		Where("request_method = ? OR module IN (?)", "DELETE", modules).
+		Order("create_time DESC").
+		Limit(limit).
+		Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
 // Get 获取日志详情
 func (a LogRepository) Get(id uint64) (*system.Log, error) {
 	log := new(system.Log)
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// LoginEventRepository database structure
+type LoginEventRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewLoginEventRepository creates a new login event repository
+func NewLoginEventRepository(db lib.Database, logger lib.Logger) LoginEventRepository {
+	return LoginEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create 记录登录事件
+func (a LoginEventRepository) Create(event *system.LoginEvent) error {
+	result := a.db.ORM.Create(event)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// ListLatest 每个用户最近一次登录记录
+func (a LoginEventRepository) ListLatest() (system.LoginEvents, error) {
+	list := make(system.LoginEvents, 0)
+
+	subQuery := a.db.ORM.Model(&system.LoginEvent{}).Select("MAX(id)").Group("user_id")
+	result := a.db.ORM.Where("id IN (?)", subQuery).Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// ListSince 查询用户 since 之后的登录记录（按时间倒序，最多 limit 条）
+func (a LoginEventRepository) ListSince(userID uint64, since time.Time, limit int) (system.LoginEvents, error) {
+	list := make(system.LoginEvents, 0)
+
+	result := a.db.ORM.Where("user_id = ? AND create_time >= ?", userID, since).
+		Order("create_time DESC").Order("id DESC").
+		Limit(limit).
+		Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// LoginLogRepository database structure
+type LoginLogRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewLoginLogRepository creates a new login log repository
+func NewLoginLogRepository(db lib.Database, logger lib.Logger) LoginLogRepository {
+	return LoginLogRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create 记录登录尝试
+func (a LoginLogRepository) Create(log *system.LoginLog) error {
+	result := a.db.ORM.Create(log)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Query 分页查询登录日志
+func (a LoginLogRepository) Query(param *system.LoginLogQueryParam) (*system.LoginLogQueryResult, error) {
+	db := a.db.ORM.Model(&system.LoginLog{})
+
+	if v := param.Username; v != "" {
+		db = db.Where("username LIKE ?", "%"+v+"%")
+	}
+
+	if v := param.IP; v != "" {
+		db = db.Where("ip = ?", v)
+	}
+
+	if v := param.Status; v != nil {
+		db = db.Where("status = ?", *v)
+	}
+
+	if v := param.CreateTimeFrom; v != "" {
+		db = db.Where("create_time >= ?", v)
+	}
+
+	if v := param.CreateTimeTo; v != "" {
+		db = db.Where("create_time <= ?", v+" 23:59:59")
+	}
+
+	db = ScopeByUser(db, param.DataScope, "user_id")
+
+	db = db.Order("create_time DESC").Order("id DESC")
+
+	list := make(system.LoginLogs, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	qr := &system.LoginLogQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}
+
+	return qr, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// MailTemplateRepository 邮件模板仓库
+type MailTemplateRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewMailTemplateRepository creates a new mail template repository
+func NewMailTemplateRepository(db lib.Database, logger lib.Logger) MailTemplateRepository {
+	return MailTemplateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a MailTemplateRepository) WithTrx(trxHandle *gorm.DB) MailTemplateRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 查询邮件模板分页列表
+func (a MailTemplateRepository) Query(param *system.MailTemplateQueryParam) (*system.MailTemplateQueryResult, error) {
+	db := a.db.ORM.Model(&system.MailTemplate{})
+
+	if v := param.Keywords; v != "" {
+		v = "%" + v + "%"
+		db = db.Where("name LIKE ? OR code LIKE ?", v, v)
+	}
+
+	if v := param.Status; v != nil {
+		db = db.Where("status = ?", *v)
+	}
+
+	db = db.Order("id ASC")
+
+	list := make(system.MailTemplates, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.MailTemplateQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// Get 获取邮件模板
+func (a MailTemplateRepository) Get(id uint64) (*system.MailTemplate, error) {
+	template := new(system.MailTemplate)
+
+	if ok, err := QueryOne(a.db.ORM.Model(template).Where("id = ?", id), template); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.MailTemplateRecordNotFound
+	}
+
+	return template, nil
+}
+
+// GetByCode 根据编码获取邮件模板，不存在时返回 nil
+func (a MailTemplateRepository) GetByCode(code string) (*system.MailTemplate, error) {
+	template := new(system.MailTemplate)
+
+	if ok, err := QueryOne(a.db.ORM.Model(template).Where("code = ?", code), template); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return template, nil
+}
+
+func (a MailTemplateRepository) Create(template *system.MailTemplate) error {
+	if err := a.db.ORM.Create(template).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a MailTemplateRepository) Update(id uint64, template *system.MailTemplate) error {
+	result := a.db.ORM.Model(template).Where("id = ?", id).
+		Select("code", "name", "subject", "content", "content_type", "status", "remark", "update_by").Updates(template)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+func (a MailTemplateRepository) Delete(id uint64) error {
+	if err := a.db.ORM.Where("id = ?", id).Delete(&system.MailTemplate{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -126,6 +126,58 @@ func (a MenuRepository) Delete(id uint64) error {
 	return nil
 }
 
+// DeleteByIDs 批量删除菜单
+func (a MenuRepository) DeleteByIDs(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Where("id IN (?)", ids).Delete(&system.Menu{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// List 获取全部菜单，不分页，按 sort、id 排序
+func (a MenuRepository) List() (system.Menus, error) {
+	list := make(system.Menus, 0)
+
+	if err := a.db.ORM.Order("sort, id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// ListSubtree 获取菜单的全部后代节点（不含自身）
+// 按 tree_path 精确匹配子树，避免 "1,2" 误匹配 "1,23"
+func (a MenuRepository) ListSubtree(path string) (system.Menus, error) {
+	list := make(system.Menus, 0)
+
+	err := a.db.ORM.Where("tree_path = ? OR tree_path LIKE ?", path, path+",%").
+		Order("id").Find(&list).Error
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// UpdateParent 修改菜单的上级及 tree_path
+func (a MenuRepository) UpdateParent(id, parentID uint64, treePath string) error {
+	result := a.db.ORM.Model(&system.Menu{}).Where("id=?", id).Updates(map[string]interface{}{
+		"parent_id": parentID,
+		"tree_path": treePath,
+	})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 func (a MenuRepository) UpdateVisible(id uint64, visible int) error {
 	menu := new(system.Menu)
 
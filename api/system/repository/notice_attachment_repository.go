@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// NoticeAttachmentRepository database structure
+type NoticeAttachmentRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewNoticeAttachmentRepository creates a new notice attachment repository
+func NewNoticeAttachmentRepository(db lib.Database, logger lib.Logger) NoticeAttachmentRepository {
+	return NoticeAttachmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a NoticeAttachmentRepository) WithTrx(trxHandle *gorm.DB) NoticeAttachmentRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Create 创建附件记录
+func (a NoticeAttachmentRepository) Create(attachment *system.NoticeAttachment) error {
+	result := a.db.ORM.Create(attachment)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Get 获取附件记录
+func (a NoticeAttachmentRepository) Get(id uint64) (*system.NoticeAttachment, error) {
+	attachment := new(system.NoticeAttachment)
+
+	if ok, err := QueryOne(a.db.ORM.Model(attachment).Where("id=?", id), attachment); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.DatabaseRecordNotFound
+	}
+
+	return attachment, nil
+}
+
+// ListByNoticeIDs 获取通知公告关联的附件
+func (a NoticeAttachmentRepository) ListByNoticeIDs(noticeIDs []uint64) (system.NoticeAttachments, error) {
+	list := make(system.NoticeAttachments, 0)
+	if len(noticeIDs) == 0 {
+		return list, nil
+	}
+
+	err := a.db.ORM.Model(&system.NoticeAttachment{}).
+		Where("notice_id IN (?) AND detach_time IS NULL", noticeIDs).
+		Order("id").Find(&list).Error
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// ListLinkable 获取可关联到指定通知公告的附件（未关联或已关联到该通知）
+func (a NoticeAttachmentRepository) ListLinkable(noticeID uint64, ids []uint64) (system.NoticeAttachments, error) {
+	list := make(system.NoticeAttachments, 0)
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	err := a.db.ORM.Model(&system.NoticeAttachment{}).
+		Where("id IN (?) AND notice_id IN (?) AND detach_time IS NULL", ids, []uint64{0, noticeID}).
+		Order("id").Find(&list).Error
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Link 将附件关联到通知公告
+func (a NoticeAttachmentRepository) Link(noticeID uint64, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Model(&system.NoticeAttachment{}).Where("id IN (?)", ids).Update("notice_id", noticeID)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Detach 将附件标记为已移除，记录与文件由定时清理任务删除
+func (a NoticeAttachmentRepository) Detach(ids []uint64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Model(&system.NoticeAttachment{}).Where("id IN (?)", ids).
+		Update("detach_time", dto.NullDateTime{Time: at, Valid: true})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// ListSweepable 获取待清理的附件：已移除的附件，以及 unlinkedBefore 之前上传但一直未关联的附件
+func (a NoticeAttachmentRepository) ListSweepable(unlinkedBefore time.Time, limit int) (system.NoticeAttachments, error) {
+	list := make(system.NoticeAttachments, 0)
+
+	err := a.db.ORM.Model(&system.NoticeAttachment{}).
+		Where("detach_time IS NOT NULL OR (notice_id = 0 AND create_time < ?)", unlinkedBefore).
+		Order("id").Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// BatchDelete 批量删除附件记录
+func (a NoticeAttachmentRepository) BatchDelete(ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Where("id IN (?)", ids).Delete(&system.NoticeAttachment{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
@@ -50,6 +50,12 @@ func (a NoticeRepository) Query(param *system.NoticeQueryParam) (*system.NoticeQ
 		db = db.Where("publish_status = ?", *v)
 	}
 
+	if v := param.TagIDs; len(v) > 0 {
+		db = db.Where("id IN (?)", TaggedResourceIDs(db, system.TagResourceNotice, v))
+	}
+
+	db = ScopeByUser(db, param.DataScope, "create_by")
+
 	db = db.Order("create_time DESC")
 
 	list := make(system.Notices, 0)
@@ -78,6 +84,22 @@ func (a NoticeRepository) Get(id uint64) (*system.Notice, error) {
 	return notice, nil
 }
 
+// ScopedIDs 返回 ids 中在数据权限范围内的通知公告ID
+func (a NoticeRepository) ScopedIDs(ids []uint64, scope *system.DataScope) ([]uint64, error) {
+	scoped := make([]uint64, 0, len(ids))
+	if len(ids) == 0 {
+		return scoped, nil
+	}
+
+	db := a.db.ORM.Model(&system.Notice{}).Where("id IN (?) AND is_deleted = ?", ids, 0)
+	db = ScopeByUser(db, scope, "create_by")
+	if err := db.Pluck("id", &scoped).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return scoped, nil
+}
+
 func (a NoticeRepository) Create(notice *system.Notice) error {
 	result := a.db.ORM.Model(notice).Create(notice)
 	if result.Error != nil {
@@ -90,7 +112,7 @@ func (a NoticeRepository) Create(notice *system.Notice) error {
 func (a NoticeRepository) Update(id uint64, notice *system.Notice) error {
 	result := a.db.ORM.Model(notice).Where("id=?", id).Select(
 		"title", "content", "type", "level", "target_type",
-		"target_user_ids", "update_by",
+		"target_user_ids", "channels", "update_by",
 	).Updates(notice)
 	if result.Error != nil {
 		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
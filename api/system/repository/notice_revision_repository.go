@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// NoticeRevisionRepository database structure
+type NoticeRevisionRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewNoticeRevisionRepository creates a new notice revision repository
+func NewNoticeRevisionRepository(db lib.Database, logger lib.Logger) NoticeRevisionRepository {
+	return NoticeRevisionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a NoticeRevisionRepository) WithTrx(trxHandle *gorm.DB) NoticeRevisionRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Create 创建修订记录
+func (a NoticeRevisionRepository) Create(revision *system.NoticeRevision) error {
+	result := a.db.ORM.Create(revision)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Get 获取通知公告的指定版本
+func (a NoticeRevisionRepository) Get(noticeID uint64, revision int) (*system.NoticeRevision, error) {
+	item := new(system.NoticeRevision)
+
+	db := a.db.ORM.Model(item).Where("notice_id=? AND revision=?", noticeID, revision)
+	if ok, err := QueryOne(db, item); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.DatabaseRecordNotFound
+	}
+
+	return item, nil
+}
+
+// Latest 获取通知公告的最新修订，没有修订时返回 nil
+func (a NoticeRevisionRepository) Latest(noticeID uint64) (*system.NoticeRevision, error) {
+	item := new(system.NoticeRevision)
+
+	db := a.db.ORM.Model(item).Where("notice_id=?", noticeID).Order("revision DESC")
+	if ok, err := QueryOne(db, item); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item, nil
+}
+
+// Query 分页查询修订记录，按版本号倒序
+func (a NoticeRevisionRepository) Query(param *system.NoticeRevisionQueryParam) (*system.NoticeRevisionQueryResult, error) {
+	db := a.db.ORM.Model(&system.NoticeRevision{}).Where("notice_id=?", param.NoticeID).Order("revision DESC")
+
+	list := make(system.NoticeRevisions, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.NoticeRevisionQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
+
+// DeleteBefore 删除通知公告中版本号小于 revision 的修订记录
+func (a NoticeRevisionRepository) DeleteBefore(noticeID uint64, revision int) error {
+	result := a.db.ORM.Where("notice_id=? AND revision<?", noticeID, revision).Delete(&system.NoticeRevision{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// DeleteByNoticeIDs 删除通知公告的全部修订记录
+func (a NoticeRevisionRepository) DeleteByNoticeIDs(noticeIDs []uint64) error {
+	if len(noticeIDs) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Where("notice_id IN (?)", noticeIDs).Delete(&system.NoticeRevision{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
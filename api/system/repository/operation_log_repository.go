@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// OperationLogRepository database structure
+type OperationLogRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewOperationLogRepository creates a new operation log repository
+func NewOperationLogRepository(db lib.Database, logger lib.Logger) OperationLogRepository {
+	return OperationLogRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateBatch 批量保存操作日志
+func (a OperationLogRepository) CreateBatch(list system.OperationLogs) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.CreateInBatches(list, 200).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// Query 分页查询操作日志
+func (a OperationLogRepository) Query(param *system.OperationLogQueryParam) (*system.OperationLogQueryResult, error) {
+	db := a.db.ORM.Model(&system.OperationLog{})
+
+	if v := param.UserID; v > 0 {
+		db = db.Where("user_id = ?", v)
+	}
+
+	if v := param.Username; v != "" {
+		db = db.Where("username LIKE ?", "%"+v+"%")
+	}
+
+	if v := param.Module; v != "" {
+		db = db.Where("module = ?", v)
+	}
+
+	if v := param.Method; v != "" {
+		db = db.Where("method = ?", strings.ToUpper(v))
+	}
+
+	if v := param.CreateTimeFrom; v != "" {
+		db = db.Where("create_time >= ?", v)
+	}
+
+	if v := param.CreateTimeTo; v != "" {
+		db = db.Where("create_time <= ?", v+" 23:59:59")
+	}
+
+	db = ScopeByUser(db, param.DataScope, "user_id")
+
+	db = db.Order("create_time DESC").Order("id DESC")
+
+	list := make(system.OperationLogs, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	qr := &system.OperationLogQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}
+
+	return qr, nil
+}
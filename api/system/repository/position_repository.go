@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// PositionRepository 岗位仓库
+type PositionRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewPositionRepository creates a new position repository
+func NewPositionRepository(db lib.Database, logger lib.Logger) PositionRepository {
+	return PositionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a PositionRepository) WithTrx(trxHandle *gorm.DB) PositionRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 查询岗位分页列表
+func (a PositionRepository) Query(param *system.PositionQueryParam) (*system.PositionQueryResult, error) {
+	db := a.db.ORM.Model(&system.Position{})
+
+	if v := param.Keywords; v != "" {
+		v = "%" + v + "%"
+		db = db.Where("name LIKE ? OR code LIKE ?", v, v)
+	}
+
+	if v := param.Status; v != nil {
+		db = db.Where("status = ?", *v)
+	}
+
+	db = db.Order("sort ASC, id ASC")
+
+	list := make(system.Positions, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.PositionQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// Get 获取岗位
+func (a PositionRepository) Get(id uint64) (*system.Position, error) {
+	position := new(system.Position)
+
+	if ok, err := QueryOne(a.db.ORM.Model(position).Where("id = ?", id), position); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.PositionRecordNotFound
+	}
+
+	return position, nil
+}
+
+// GetByCode 根据编号获取岗位，不存在时返回 nil
+func (a PositionRepository) GetByCode(code string) (*system.Position, error) {
+	position := new(system.Position)
+
+	if ok, err := QueryOne(a.db.ORM.Model(position).Where("code = ?", code), position); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return position, nil
+}
+
+// GetAllEnabled 获取所有启用的岗位
+func (a PositionRepository) GetAllEnabled() (system.Positions, error) {
+	list := make(system.Positions, 0)
+	if err := a.db.ORM.Model(&system.Position{}).
+		Where("status = ?", 1).
+		Order("sort ASC, id ASC").
+		Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+func (a PositionRepository) Create(position *system.Position) error {
+	if err := a.db.ORM.Create(position).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a PositionRepository) Update(id uint64, position *system.Position) error {
+	result := a.db.ORM.Model(position).Where("id = ?", id).
+		Select("name", "code", "sort", "status", "remark", "update_by").Updates(position)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+func (a PositionRepository) Delete(id uint64) error {
+	if err := a.db.ORM.Where("id = ?", id).Delete(&system.Position{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -1,25 +1,62 @@
 package repository
 
 import (
+	"reflect"
+
 	"github.com/top-system/light-admin/models/dto"
 	"gorm.io/gorm"
 )
 
 func QueryPagination(db *gorm.DB, pp dto.PaginationParam, out interface{}) (*dto.Pagination, error) {
 	pagination := new(dto.Pagination)
+	pagination.PageNum = pp.GetPageNum()
+	pagination.PageSize = pp.GetPageSize()
+
+	if !pp.CountTotal() {
+		hasNext, err := QueryPageWithoutCount(db, pp, out)
+		if err != nil {
+			return pagination, err
+		}
+
+		pagination.Total = -1
+		pagination.HasNext = hasNext
+		return pagination, nil
+	}
 
 	total, err := QeuryPage(db, pp, out)
 	if err != nil {
 		return pagination, err
 	}
 
-	pagination.PageNum = pp.GetPageNum()
-	pagination.PageSize = pp.GetPageSize()
 	pagination.Total = total
+	pagination.HasNext = pagination.PageSize > 0 && int64(max(pagination.PageNum, 1)*pagination.PageSize) < total
 
 	return pagination, nil
 }
 
+// QueryPageWithoutCount 不统计总数，多查询一行判断是否有下一页，out 须为切片指针
+func QueryPageWithoutCount(db *gorm.DB, pp dto.PaginationParam, out interface{}) (hasNext bool, err error) {
+	current, pageSize := pp.GetPageNum(), pp.GetPageSize()
+	if pageSize <= 0 {
+		err = db.Find(out).Error
+		return
+	}
+	if current > 0 {
+		db = db.Offset((current - 1) * pageSize)
+	}
+
+	if err = db.Limit(pageSize + 1).Find(out).Error; err != nil {
+		return
+	}
+
+	list := reflect.ValueOf(out).Elem()
+	if list.Len() > pageSize {
+		list.Set(list.Slice(0, pageSize))
+		hasNext = true
+	}
+	return
+}
+
 func QeuryPage(db *gorm.DB, pp dto.PaginationParam, out interface{}) (n int64, err error) {
 	n, err = QueryCount(db)
 	if err != nil {
@@ -60,3 +97,14 @@ func QueryCount(db *gorm.DB) (n int64, err error) {
 
 	return
 }
+
+// QueryOrder 按 OrderParam 排序，未指定排序字段或字段不在 allowed 中时按 fallback 排序
+func QueryOrder(db *gorm.DB, op dto.OrderParam, allowed []string, fallback string) *gorm.DB {
+	for _, key := range allowed {
+		if op.Key != "" && op.Key == key {
+			return db.Order(op.ParseOrder())
+		}
+	}
+
+	return db.Order(fallback)
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// RefreshTokenRepository 刷新令牌仓库
+type RefreshTokenRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewRefreshTokenRepository creates a new refresh token repository
+func NewRefreshTokenRepository(db lib.Database, logger lib.Logger) RefreshTokenRepository {
+	return RefreshTokenRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a RefreshTokenRepository) WithTrx(trxHandle *gorm.DB) RefreshTokenRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// GetByHash 根据令牌摘要查询，不存在时返回 nil
+func (a RefreshTokenRepository) GetByHash(hash string) (*system.RefreshToken, error) {
+	token := new(system.RefreshToken)
+
+	if ok, err := QueryOne(a.db.ORM.Model(token).Where("token_hash = ?", hash), token); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
+// ListByUserID 用户未过期的登录设备，最近刷新的在前
+func (a RefreshTokenRepository) ListByUserID(userID uint64) (system.RefreshTokens, error) {
+	list := make(system.RefreshTokens, 0)
+	db := a.db.ORM.Model(&system.RefreshToken{}).
+		Where("user_id = ? AND expire_time > ?", userID, time.Now()).Order("update_time DESC")
+	if err := db.Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Create 保存刷新令牌
+func (a RefreshTokenRepository) Create(token *system.RefreshToken) error {
+	if err := a.db.ORM.Create(token).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// Rotate 以旧摘要为条件替换令牌，旧令牌已被轮换（并发刷新或重放）时返回 false
+func (a RefreshTokenRepository) Rotate(id uint64, oldHash string, token *system.RefreshToken) (bool, error) {
+	result := a.db.ORM.Model(&system.RefreshToken{}).Where("id = ? AND token_hash = ?", id, oldHash).
+		Updates(map[string]interface{}{
+			"token_hash":  token.TokenHash,
+			"ip":          token.IP,
+			"expire_time": token.ExpireTime,
+			"update_time": time.Now(),
+		})
+	if err := result.Error; err != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Delete 删除用户的一个登录设备，返回是否存在
+func (a RefreshTokenRepository) Delete(userID, id uint64) (bool, error) {
+	result := a.db.ORM.Where("id = ? AND user_id = ?", id, userID).Delete(&system.RefreshToken{})
+	if err := result.Error; err != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByUsername 删除用户的全部登录设备
+func (a RefreshTokenRepository) DeleteByUsername(username string) error {
+	if err := a.db.ORM.Where("username = ?", username).Delete(&system.RefreshToken{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteExpired 删除已过期的刷新令牌
+func (a RefreshTokenRepository) DeleteExpired() error {
+	if err := a.db.ORM.Where("expire_time <= ?", time.Now()).Delete(&system.RefreshToken{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -8,14 +8,42 @@ var Module = fx.Options(
 	fx.Provide(NewUserRoleRepository),
 	fx.Provide(NewRoleRepository),
 	fx.Provide(NewRoleMenuRepository),
+	fx.Provide(NewRoleDeptRepository),
 	fx.Provide(NewMenuRepository),
 	fx.Provide(NewConfigRepository),
 	fx.Provide(NewNoticeRepository),
 	fx.Provide(NewUserNoticeRepository),
+	fx.Provide(NewNoticeAttachmentRepository),
+	fx.Provide(NewNoticeRevisionRepository),
 	fx.Provide(NewDeptRepository),
+	fx.Provide(NewPositionRepository),
+	fx.Provide(NewUserPositionRepository),
 	fx.Provide(NewDictRepository),
 	fx.Provide(NewDictItemRepository),
 	fx.Provide(NewLogRepository),
 	fx.Provide(NewTaskRepository),
 	fx.Provide(NewDownloadRepository),
+	fx.Provide(NewDownloadUsageRepository),
+	fx.Provide(NewDownloadMediaRepository),
+	fx.Provide(NewDownloaderConfigRepository),
+	fx.Provide(NewApiUsageRepository),
+	fx.Provide(NewLoginEventRepository),
+	fx.Provide(NewRefreshTokenRepository),
+	fx.Provide(NewApiKeyRepository),
+	fx.Provide(NewLoginLogRepository),
+	fx.Provide(NewOperationLogRepository),
+	fx.Provide(NewSecurityAlertRepository),
+	fx.Provide(NewComplianceReportRepository),
+	fx.Provide(NewTagRepository),
+	fx.Provide(NewUserJobRepository),
+	fx.Provide(NewConfigBackupRepository),
+	fx.Provide(NewCasbinRuleRepository),
+	fx.Provide(NewDeptRoleRuleRepository),
+	fx.Provide(NewSavedQueryRepository),
+	fx.Provide(NewWsEventRepository),
+	fx.Provide(NewRetentionRepository),
+	fx.Provide(NewCronTaskRepository),
+	fx.Provide(NewMailTemplateRepository),
+	fx.Provide(NewWebhookRepository),
+	fx.Provide(NewWebhookDeliveryRepository),
 )
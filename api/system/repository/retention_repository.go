@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// retentionFinishedDownloads 可按保留策略清理的下载任务状态
+var retentionFinishedDownloads = []string{"completed", "error", "canceled"}
+
+// RetentionRepository database structure
+type RetentionRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db lib.Database, logger lib.Logger) RetentionRepository {
+	return RetentionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a RetentionRepository) WithTrx(trxHandle *gorm.DB) RetentionRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// List 全部保留策略
+func (a RetentionRepository) List() (system.RetentionPolicies, error) {
+	list := make(system.RetentionPolicies, 0)
+	if err := a.db.ORM.Order("id ASC").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Create 创建保留策略
+func (a RetentionRepository) Create(policy *system.RetentionPolicy) error {
+	if err := a.db.ORM.Create(policy).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// Update 修改保留天数与启用状态
+func (a RetentionRepository) Update(dataType string, days int, enable bool, updateBy uint64) error {
+	result := a.db.ORM.Model(&system.RetentionPolicy{}).Where("data_type=?", dataType).Updates(map[string]interface{}{
+		"days":      days,
+		"enable":    enable,
+		"update_by": updateBy,
+	})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// RecordRun 记录最近一次执行结果
+func (a RetentionRepository) RecordRun(dataType string, runAt time.Time, purged int64, lastError string) error {
+	result := a.db.ORM.Model(&system.RetentionPolicy{}).Where("data_type=?", dataType).UpdateColumns(map[string]interface{}{
+		"last_run_at": runAt,
+		"last_purged": purged,
+		"last_error":  lastError,
+	})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// retentionModel 数据类型对应的表模型，未知类型及不在数据库表中的类型返回 nil
+func retentionModel(dataType string) interface{} {
+	switch dataType {
+	case system.RetentionOperationLog:
+		return &system.Log{}
+	case system.RetentionLoginLog:
+		return &system.LoginEvent{}
+	case system.RetentionLoginAudit:
+		return &system.LoginLog{}
+	case system.RetentionOperationAudit:
+		return &system.OperationLog{}
+	case system.RetentionDownloadTask:
+		return &system.DownloadTask{}
+	case system.RetentionCronRecord:
+		return &system.CronTaskRecord{}
+	}
+
+	return nil
+}
+
+// expired 数据类型中早于 cutoff 的数据，下载任务只包含已结束的；队列任务按任务存储清理，见 TaskRepository
+func (a RetentionRepository) expired(dataType string, cutoff time.Time) (*gorm.DB, error) {
+	model := retentionModel(dataType)
+	if model == nil {
+		return nil, errors.Wrapf(errors.RetentionUnknownDataType, "%s", dataType)
+	}
+
+	db := a.db.ORM.Model(model)
+	switch dataType {
+	case system.RetentionDownloadTask:
+		return db.Where("status IN ? AND updated_at < ?", retentionFinishedDownloads, cutoff), nil
+	case system.RetentionCronRecord:
+		return db.Where("start_time < ?", cutoff), nil
+	default:
+		return db.Where("create_time < ?", cutoff), nil
+	}
+}
+
+// CountExpired 早于 cutoff、将被清理的行数
+func (a RetentionRepository) CountExpired(dataType string, cutoff time.Time) (int64, error) {
+	db, err := a.expired(dataType, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := QueryCount(db)
+	if err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return n, nil
+}
+
+// ExpiredIDs 早于 cutoff 的一批数据ID，按ID升序
+func (a RetentionRepository) ExpiredIDs(dataType string, cutoff time.Time, limit int) ([]uint64, error) {
+	db, err := a.expired(dataType, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, limit)
+	if err := db.Order("id ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return ids, nil
+}
+
+// DeleteByIDs 删除一批数据，返回实际删除的行数
+func (a RetentionRepository) DeleteByIDs(dataType string, ids []uint64) (int64, error) {
+	model := retentionModel(dataType)
+	if model == nil {
+		return 0, errors.Wrapf(errors.RetentionUnknownDataType, "%s", dataType)
+	}
+
+	result := a.db.ORM.Where("id IN ?", ids).Delete(model)
+	if result.Error != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// RoleDeptRepository 角色自定义数据权限的部门
+type RoleDeptRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewRoleDeptRepository creates a new role dept repository
+func NewRoleDeptRepository(db lib.Database, logger lib.Logger) RoleDeptRepository {
+	return RoleDeptRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a RoleDeptRepository) WithTrx(trxHandle *gorm.DB) RoleDeptRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context. ")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// GetDeptIDsByRoleIDs 获取角色的自定义部门ID列表（去重）
+func (a RoleDeptRepository) GetDeptIDsByRoleIDs(roleIDs []uint64) ([]uint64, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var deptIDs []uint64
+	if err := a.db.ORM.Model(&system.RoleDept{}).
+		Where("role_id IN (?)", roleIDs).
+		Distinct().
+		Pluck("dept_id", &deptIDs).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return deptIDs, nil
+}
+
+// Replace 重新设置角色的自定义部门
+func (a RoleDeptRepository) Replace(roleID uint64, deptIDs []uint64) error {
+	if err := a.DeleteByRoleID(roleID); err != nil {
+		return err
+	}
+	if len(deptIDs) == 0 {
+		return nil
+	}
+
+	list := make(system.RoleDepts, 0, len(deptIDs))
+	seen := make(map[uint64]bool, len(deptIDs))
+	for _, deptID := range deptIDs {
+		if seen[deptID] {
+			continue
+		}
+		seen[deptID] = true
+		list = append(list, &system.RoleDept{RoleID: roleID, DeptID: deptID})
+	}
+
+	if err := a.db.ORM.Create(&list).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteByDeptIDs 删除部门时移除引用这些部门的自定义数据权限
+func (a RoleDeptRepository) DeleteByDeptIDs(deptIDs []uint64) error {
+	if len(deptIDs) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Where("dept_id IN (?)", deptIDs).Delete(&system.RoleDept{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteByRoleID 删除角色的自定义部门
+func (a RoleDeptRepository) DeleteByRoleID(roleID uint64) error {
+	if err := a.db.ORM.Where("role_id = ?", roleID).Delete(&system.RoleDept{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -116,3 +116,50 @@ func (a RoleMenuRepository) DeleteByMenuID(menuID uint64) error {
 
 	return nil
 }
+
+// DeleteByMenuIDs 批量删除菜单的角色授权
+func (a RoleMenuRepository) DeleteByMenuIDs(menuIDs []uint64) error {
+	if len(menuIDs) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Where("menu_id IN (?)", menuIDs).Delete(&system.RoleMenu{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// GetRoleIDsByMenuIDs 获取被授权了指定菜单的角色ID（去重）
+func (a RoleMenuRepository) GetRoleIDsByMenuIDs(menuIDs []uint64) ([]uint64, error) {
+	var roleIDs []uint64
+	if len(menuIDs) == 0 {
+		return roleIDs, nil
+	}
+
+	result := a.db.ORM.Model(&system.RoleMenu{}).
+		Where("menu_id IN (?)", menuIDs).
+		Distinct().
+		Pluck("role_id", &roleIDs)
+
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return roleIDs, nil
+}
+
+// CountByMenuIDs 统计菜单的角色授权数
+func (a RoleMenuRepository) CountByMenuIDs(menuIDs []uint64) (int64, error) {
+	var count int64
+	if len(menuIDs) == 0 {
+		return 0, nil
+	}
+
+	if err := a.db.ORM.Model(&system.RoleMenu{}).Where("menu_id IN (?)", menuIDs).Count(&count).Error; err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return count, nil
+}
@@ -143,3 +143,41 @@ func (a RoleRepository) UpdateStatus(id uint64, status int) error {
 
 	return nil
 }
+
+// GetByCodes 根据编码批量获取角色Map
+func (a RoleRepository) GetByCodes(codes []string) (map[string]*system.Role, error) {
+	result := make(map[string]*system.Role)
+	if len(codes) == 0 {
+		return result, nil
+	}
+
+	var list []*system.Role
+	if err := a.db.ORM.Model(&system.Role{}).Where("code IN (?) AND is_deleted = ?", codes, 0).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, role := range list {
+		result[role.Code] = role
+	}
+
+	return result, nil
+}
+
+// Revive 恢复按编码软删除的角色，不存在已删除记录时返回 nil
+// 编码上有唯一索引，重新启用同一编码时需复用已删除的记录
+func (a RoleRepository) Revive(code string) (*system.Role, error) {
+	role := new(system.Role)
+
+	if ok, err := QueryOne(a.db.ORM.Model(role).Where("code=? AND is_deleted=?", code, 1), role); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	if err := a.db.ORM.Model(&system.Role{}).Where("id=?", role.ID).Update("is_deleted", 0).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+	role.IsDeleted = 0
+
+	return role, nil
+}
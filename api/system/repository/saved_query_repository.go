@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// SavedQueryRepository 保存查询仓库
+type SavedQueryRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewSavedQueryRepository creates a new saved query repository
+func NewSavedQueryRepository(db lib.Database, logger lib.Logger) SavedQueryRepository {
+	return SavedQueryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a SavedQueryRepository) WithTrx(trxHandle *gorm.DB) SavedQueryRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Get 获取保存查询
+func (a SavedQueryRepository) Get(id uint64) (*system.SavedQuery, error) {
+	query := new(system.SavedQuery)
+
+	if ok, err := QueryOne(a.db.ORM.Model(query).Where("id = ?", id), query); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.SavedQueryRecordNotFound
+	}
+
+	return query, nil
+}
+
+// GetByName 根据名称获取用户在某个列表下的保存查询，不存在时返回 nil
+func (a SavedQueryRepository) GetByName(userID uint64, resource, name string) (*system.SavedQuery, error) {
+	query := new(system.SavedQuery)
+
+	db := a.db.ORM.Model(query).Where("user_id = ? AND resource = ? AND name = ?", userID, resource, name)
+	if ok, err := QueryOne(db, query); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return query, nil
+}
+
+// ListByUser 获取用户的保存查询，resource 为空时返回全部列表的
+func (a SavedQueryRepository) ListByUser(userID uint64, resource string) (system.SavedQueries, error) {
+	db := a.db.ORM.Where("user_id = ?", userID)
+	if resource != "" {
+		db = db.Where("resource = ?", resource)
+	}
+
+	list := make(system.SavedQueries, 0)
+	if err := db.Order("resource, name").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+func (a SavedQueryRepository) Create(query *system.SavedQuery) error {
+	if err := a.db.ORM.Create(query).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a SavedQueryRepository) Update(id uint64, values map[string]interface{}) error {
+	if err := a.db.ORM.Model(&system.SavedQuery{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a SavedQueryRepository) Delete(id uint64) error {
+	if err := a.db.ORM.Where("id = ?", id).Delete(&system.SavedQuery{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
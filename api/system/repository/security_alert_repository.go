@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// SecurityAlertRepository database structure
+type SecurityAlertRepository struct {
+	db       lib.Database
+	logger   lib.Logger
+	dbCompat lib.DBCompat
+}
+
+// NewSecurityAlertRepository creates a new security alert repository
+func NewSecurityAlertRepository(db lib.Database, logger lib.Logger, dbCompat lib.DBCompat) SecurityAlertRepository {
+	return SecurityAlertRepository{
+		db:       db,
+		logger:   logger,
+		dbCompat: dbCompat,
+	}
+}
+
+// Create 创建告警
+func (a SecurityAlertRepository) Create(alert *system.SecurityAlert) error {
+	result := a.db.ORM.Create(alert)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Get 获取告警
+func (a SecurityAlertRepository) Get(id uint64) (*system.SecurityAlert, error) {
+	alert := new(system.SecurityAlert)
+
+	if ok, err := QueryOne(a.db.ORM.Model(alert).Where("id=?", id), alert); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.DatabaseRecordNotFound
+	}
+
+	return alert, nil
+}
+
+// Query 分页查询告警
+func (a SecurityAlertRepository) Query(param *system.SecurityAlertQueryParam) (*system.SecurityAlertQueryResult, error) {
+	db := a.db.ORM.Model(&system.SecurityAlert{})
+
+	if v := param.Rule; v != "" {
+		db = db.Where("rule = ?", v)
+	}
+
+	if v := param.Username; v != "" {
+		db = db.Where("username LIKE ?", "%"+v+"%")
+	}
+
+	if v := param.Status; v != nil {
+		db = db.Where("status = ?", *v)
+	}
+
+	if v := param.From; v != "" {
+		db = db.Where("create_time >= ?", v)
+	}
+
+	if v := param.To; v != "" {
+		if len(v) == len("2006-01-02") {
+			v += " 23:59:59"
+		}
+		db = db.Where("create_time <= ?", v)
+	}
+
+	db = db.Order("id DESC")
+
+	list := make(system.SecurityAlerts, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.SecurityAlertQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
+
+// ExistsSince 用户在 since 之后是否已有同一规则的待处理告警
+func (a SecurityAlertRepository) ExistsSince(userID uint64, rule string, since time.Time) (bool, error) {
+	var count int64
+
+	result := a.db.ORM.Model(&system.SecurityAlert{}).
+		Where("user_id = ? AND rule = ? AND status = ? AND create_time >= ?", userID, rule, system.SecurityAlertOpen, since).
+		Count(&count)
+	if result.Error != nil {
+		return false, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return count > 0, nil
+}
+
+// Ack 确认告警
+func (a SecurityAlertRepository) Ack(id uint64, ackBy uint64) error {
+	result := a.db.ORM.Model(&system.SecurityAlert{}).
+		Where("id = ? AND status = ?", id, system.SecurityAlertOpen).
+		Updates(map[string]interface{}{
+			"status":   system.SecurityAlertAcknowledged,
+			"ack_by":   ackBy,
+			"ack_time": a.dbCompat.Now(),
+		})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// CountByRule 按规则统计 since 之后的告警数
+func (a SecurityAlertRepository) CountByRule(since time.Time) ([]*system.SecurityRuleCount, error) {
+	var list []*system.SecurityRuleCount
+
+	result := a.db.ORM.Model(&system.SecurityAlert{}).
+		Select("rule, COUNT(*) AS count").
+		Where("create_time >= ?", since).
+		Group("rule").
+		Order("count DESC").
+		Scan(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// CountOpen 待处理告警总数
+func (a SecurityAlertRepository) CountOpen() (int64, error) {
+	var count int64
+
+	result := a.db.ORM.Model(&system.SecurityAlert{}).Where("status = ?", system.SecurityAlertOpen).Count(&count)
+	if result.Error != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return count, nil
+}
+
+// Recent 最近的告警
+func (a SecurityAlertRepository) Recent(limit int) (system.SecurityAlerts, error) {
+	list := make(system.SecurityAlerts, 0)
+
+	result := a.db.ORM.Order("id DESC").Limit(limit).Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
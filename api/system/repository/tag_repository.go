@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TagRepository 标签及资源关联仓库
+type TagRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db lib.Database, logger lib.Logger) TagRepository {
+	return TagRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a TagRepository) WithTrx(trxHandle *gorm.DB) TagRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// TaggedResourceIDs 返回带有任一指定标签的资源 ID 子查询，用于各资源列表的标签过滤
+// 用法：db.Where("id IN (?)", TaggedResourceIDs(db, system.TagResourceUser, tagIDs))
+func TaggedResourceIDs(db *gorm.DB, resourceType string, tagIDs []uint64) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&system.Tagging{}).
+		Select("resource_id").
+		Where("resource_type = ? AND tag_id IN (?)", resourceType, tagIDs)
+}
+
+// Query 查询标签分页列表
+func (a TagRepository) Query(param *system.TagQueryParam) (*system.TagQueryResult, error) {
+	db := a.db.ORM.Model(&system.Tag{})
+
+	if v := param.Keywords; v != "" {
+		db = db.Where("name LIKE ?", "%"+v+"%")
+	}
+
+	db = db.Order("name")
+
+	list := make(system.Tags, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.TagQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// Get 获取标签
+func (a TagRepository) Get(id uint64) (*system.Tag, error) {
+	tag := new(system.Tag)
+
+	if ok, err := QueryOne(a.db.ORM.Model(tag).Where("id = ?", id), tag); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.TagRecordNotFound
+	}
+
+	return tag, nil
+}
+
+// GetByName 根据名称获取标签，不存在时返回 nil
+func (a TagRepository) GetByName(name string) (*system.Tag, error) {
+	tag := new(system.Tag)
+
+	if ok, err := QueryOne(a.db.ORM.Model(tag).Where("name = ?", name), tag); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, nil
+	}
+
+	return tag, nil
+}
+
+// GetByIDs 根据 ID 列表获取标签
+func (a TagRepository) GetByIDs(ids []uint64) (system.Tags, error) {
+	list := make(system.Tags, 0)
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	if err := a.db.ORM.Model(&system.Tag{}).Where("id IN (?)", ids).Order("name").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+func (a TagRepository) Create(tag *system.Tag) error {
+	if err := a.db.ORM.Create(tag).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a TagRepository) Update(id uint64, tag *system.Tag) error {
+	result := a.db.ORM.Model(tag).Where("id = ?", id).Select("name", "color", "remark", "update_by").Updates(tag)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Delete 删除标签及其全部关联
+func (a TagRepository) Delete(id uint64) error {
+	err := a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&system.Tagging{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&system.Tag{}).Error
+	})
+	if err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// ListByResource 获取资源的标签
+func (a TagRepository) ListByResource(resourceType string, resourceID uint64) (system.Tags, error) {
+	subQuery := a.db.ORM.Model(&system.Tagging{}).
+		Select("tag_id").
+		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID)
+
+	list := make(system.Tags, 0)
+	if err := a.db.ORM.Model(&system.Tag{}).Where("id IN (?)", subQuery).Order("name").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// ReplaceTaggings 整体替换资源的标签关联
+func (a TagRepository) ReplaceTaggings(resourceType string, resourceID uint64, tagIDs []uint64, createBy uint64) error {
+	err := a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
+			Delete(&system.Tagging{}).Error; err != nil {
+			return err
+		}
+
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		list := make(system.Taggings, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			list = append(list, &system.Tagging{
+				TagID:        tagID,
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
+				CreateBy:     createBy,
+			})
+		}
+		return tx.Create(&list).Error
+	})
+	if err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteByResources 删除资源的全部标签关联
+func (a TagRepository) DeleteByResources(resourceType string, resourceIDs []uint64) error {
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Where("resource_type = ? AND resource_id IN (?)", resourceType, resourceIDs).
+		Delete(&system.Tagging{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// CountByTags 统计各标签关联的资源数
+func (a TagRepository) CountByTags(tagIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64)
+	if len(tagIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TagID uint64
+		Count int64
+	}
+	if err := a.db.ORM.Model(&system.Tagging{}).
+		Select("tag_id, COUNT(*) AS count").
+		Where("tag_id IN (?)", tagIDs).
+		Group("tag_id").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, row := range rows {
+		counts[row.TagID] = row.Count
+	}
+
+	return counts, nil
+}
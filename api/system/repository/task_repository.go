@@ -1,25 +1,39 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/pkg/queue"
 )
 
-// TaskRepository database structure
+// finishedTaskStatuses 已结束的队列任务状态，按保留策略清理
+var finishedTaskStatuses = []queue.Status{queue.StatusCompleted, queue.StatusError, queue.StatusCanceled}
+
+// TaskRepository 队列任务的查询与删除，按 Queue.Storage 配置读写数据库或 Redis 中的任务
 type TaskRepository struct {
 	db     lib.Database
 	logger lib.Logger
+	store  queue.TaskRepository
 }
 
 // NewTaskRepository creates a new task repository
-func NewTaskRepository(db lib.Database, logger lib.Logger) TaskRepository {
+func NewTaskRepository(db lib.Database, logger lib.Logger, taskQueue lib.TaskQueue) TaskRepository {
+	store := taskQueue.Repository
+	if store == nil {
+		store = queue.NewGormTaskRepository(db.ORM)
+	}
+
 	return TaskRepository{
 		db:     db,
 		logger: logger,
+		store:  store,
 	}
 }
 
@@ -31,72 +45,86 @@ func (a TaskRepository) WithTrx(trxHandle *gorm.DB) TaskRepository {
 	}
 
 	a.db.ORM = trxHandle
+	if _, ok := a.store.(*queue.GormTaskRepository); ok {
+		a.store = queue.NewGormTaskRepository(trxHandle)
+	}
 	return a
 }
 
 // Query 查询任务列表
 func (a TaskRepository) Query(param *system.TaskQueryParam) (*system.TaskQueryResult, error) {
-	db := a.db.ORM.Model(&system.Task{})
-
-	if v := param.Type; v != "" {
-		db = db.Where("type = ?", v)
+	filter := &queue.TaskFilter{
+		Type:          param.Type,
+		CorrelationID: param.CorrelationID,
+		Keywords:      param.Keywords,
+		CreatedFrom:   parseTaskDate(param.CreateTimeFrom),
 	}
-
 	if v := param.Status; v != "" {
-		db = db.Where("status = ?", v)
+		filter.Statuses = []queue.Status{queue.Status(v)}
 	}
-
-	if v := param.CorrelationID; v != "" {
-		db = db.Where("correlation_id = ?", v)
+	if to := parseTaskDate(param.CreateTimeTo); !to.IsZero() {
+		filter.CreatedTo = to.Add(24*time.Hour - time.Second)
 	}
-
-	if v := param.Keywords; v != "" {
-		v = "%" + v + "%"
-		db = db.Where("type LIKE ? OR correlation_id LIKE ? OR public_error LIKE ?", v, v, v)
+	pageNum, pageSize := param.GetPageNum(), param.GetPageSize()
+	if pageSize > 0 {
+		filter.Offset = max(pageNum-1, 0) * pageSize
+		filter.Limit = pageSize
 	}
 
-	if v := param.CreateTimeFrom; v != "" {
-		db = db.Where("created_at >= ?", v)
+	models, total, err := a.store.List(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
 	}
 
-	if v := param.CreateTimeTo; v != "" {
-		db = db.Where("created_at <= ?", v+" 23:59:59")
+	list := make(system.Tasks, 0, len(models))
+	for _, model := range models {
+		list = append(list, system.NewTaskFromModel(model))
 	}
 
-	db = db.Order("created_at DESC")
-
-	list := make(system.Tasks, 0)
-	pagination, err := QueryPagination(db, param.PaginationParam, &list)
-	if err != nil {
-		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	pagination := &dto.Pagination{
+		Total:    total,
+		PageNum:  pageNum,
+		PageSize: pageSize,
+		HasNext:  pageSize > 0 && int64(max(pageNum, 1)*pageSize) < total,
+	}
+	if !param.CountTotal() {
+		pagination.Total = -1
 	}
 
-	qr := &system.TaskQueryResult{
+	return &system.TaskQueryResult{
 		Pagination: pagination,
 		List:       list,
-	}
+	}, nil
+}
 
-	return qr, nil
+// parseTaskDate 解析查询参数中的日期，格式错误时不作为条件
+func parseTaskDate(v string) time.Time {
+	if v == "" {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
 // Get 获取任务详情
 func (a TaskRepository) Get(id uint64) (*system.Task, error) {
-	task := new(system.Task)
-
-	if ok, err := QueryOne(a.db.ORM.Model(task).Where("id=?", id), task); err != nil {
-		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
-	} else if !ok {
+	model, err := a.store.GetByID(context.Background(), id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.DatabaseRecordNotFound
+	} else if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
 	}
 
-	return task, nil
+	return system.NewTaskFromModel(model), nil
 }
 
 // Delete 删除任务
 func (a TaskRepository) Delete(id uint64) error {
-	result := a.db.ORM.Where("id=?", id).Delete(&system.Task{})
-	if result.Error != nil {
-		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	if err := a.store.Delete(context.Background(), id); err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
 	}
 
 	return nil
@@ -104,20 +132,52 @@ func (a TaskRepository) Delete(id uint64) error {
 
 // BatchDelete 批量删除任务
 func (a TaskRepository) BatchDelete(ids []uint64) error {
-	result := a.db.ORM.Where("id IN ?", ids).Delete(&system.Task{})
-	if result.Error != nil {
-		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	for _, id := range ids {
+		if err := a.Delete(id); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// CountFinished 最后更新早于 before 的已结束任务数量
+func (a TaskRepository) CountFinished(before time.Time) (int64, error) {
+	_, total, err := a.store.List(context.Background(), &queue.TaskFilter{
+		Statuses:      finishedTaskStatuses,
+		UpdatedBefore: before,
+		Limit:         1,
+	})
+	if err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return total, nil
+}
+
+// FinishedIDs 最后更新早于 before 的一批已结束任务ID
+func (a TaskRepository) FinishedIDs(before time.Time, limit int) ([]uint64, error) {
+	models, _, err := a.store.List(context.Background(), &queue.TaskFilter{
+		Statuses:      finishedTaskStatuses,
+		UpdatedBefore: before,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	ids := make([]uint64, 0, len(models))
+	for _, model := range models {
+		ids = append(ids, model.ID)
+	}
+	return ids, nil
+}
+
 // GetTaskTypes 获取所有任务类型
 func (a TaskRepository) GetTaskTypes() ([]system.TaskTypeVO, error) {
-	var types []string
-	result := a.db.ORM.Model(&system.Task{}).Distinct("type").Pluck("type", &types)
-	if result.Error != nil {
-		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	types, err := a.store.Types(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
 	}
 
 	taskTypes := make([]system.TaskTypeVO, 0, len(types))
@@ -135,34 +195,19 @@ func (a TaskRepository) GetTaskTypes() ([]system.TaskTypeVO, error) {
 func (a TaskRepository) GetStatusCounts() (*system.TaskStatsVO, error) {
 	stats := &system.TaskStatsVO{}
 
-	// 查询各状态数量
-	var counts []struct {
-		Status string
-		Count  int64
-	}
-
-	result := a.db.ORM.Model(&system.Task{}).
-		Select("status, count(*) as count").
-		Group("status").
-		Scan(&counts)
-
-	if result.Error != nil {
-		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
-	}
-
-	for _, c := range counts {
-		switch queue.Status(c.Status) {
-		case queue.StatusQueued:
-			stats.QueuedCount = c.Count
-		case queue.StatusProcessing:
-			stats.ProcessingCount = c.Count
-		case queue.StatusCompleted:
-			stats.CompletedCount = c.Count
-		case queue.StatusError:
-			stats.ErrorCount = c.Count
-		case queue.StatusCanceled:
-			stats.CanceledCount = c.Count
+	counts := map[queue.Status]*int64{
+		queue.StatusQueued:     &stats.QueuedCount,
+		queue.StatusProcessing: &stats.ProcessingCount,
+		queue.StatusCompleted:  &stats.CompletedCount,
+		queue.StatusError:      &stats.ErrorCount,
+		queue.StatusCanceled:   &stats.CanceledCount,
+	}
+	for status, count := range counts {
+		_, total, err := a.store.List(context.Background(), &queue.TaskFilter{Statuses: []queue.Status{status}, Limit: 1})
+		if err != nil {
+			return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
 		}
+		*count = total
 	}
 
 	return stats, nil
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// UserJobRepository 用户定时任务仓库
+type UserJobRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewUserJobRepository creates a new user job repository
+func NewUserJobRepository(db lib.Database, logger lib.Logger) UserJobRepository {
+	return UserJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a UserJobRepository) WithTrx(trxHandle *gorm.DB) UserJobRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Get 获取用户定时任务
+func (a UserJobRepository) Get(id uint64) (*system.UserJob, error) {
+	job := new(system.UserJob)
+
+	if ok, err := QueryOne(a.db.ORM.Model(job).Where("id = ?", id), job); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.UserJobRecordNotFound
+	}
+
+	return job, nil
+}
+
+// ListByUser 获取用户的全部定时任务
+func (a UserJobRepository) ListByUser(userID uint64) (system.UserJobs, error) {
+	list := make(system.UserJobs, 0)
+
+	if err := a.db.ORM.Where("user_id = ?", userID).Order("id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// ListEnabled 获取全部启用的定时任务
+func (a UserJobRepository) ListEnabled() (system.UserJobs, error) {
+	list := make(system.UserJobs, 0)
+
+	if err := a.db.ORM.Where("status = ?", 1).Order("id").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// CountByUser 统计用户的定时任务数
+func (a UserJobRepository) CountByUser(userID uint64) (int64, error) {
+	var count int64
+
+	if err := a.db.ORM.Model(&system.UserJob{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return count, nil
+}
+
+func (a UserJobRepository) Create(job *system.UserJob) error {
+	if err := a.db.ORM.Create(job).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a UserJobRepository) Update(id uint64, values map[string]interface{}) error {
+	if err := a.db.ORM.Model(&system.UserJob{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// IncrRun 记录一次执行结果
+func (a UserJobRepository) IncrRun(id uint64, values map[string]interface{}) error {
+	values["run_count"] = gorm.Expr("run_count + ?", 1)
+	return a.Update(id, values)
+}
+
+func (a UserJobRepository) Delete(id uint64) error {
+	if err := a.db.ORM.Where("id = ?", id).Delete(&system.UserJob{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -40,9 +40,9 @@ func (a UserNoticeRepository) GetMyNoticePage(param *system.NoticeQueryParam) ([
 	var list []system.UserNoticePageVO
 	var total int64
 
-	db := a.db.ORM.Table("t_user_notice un").
+	db := a.db.ORM.Table(a.db.TableName(&system.UserNotice{})+" un").
 		Select("un.id, un.notice_id, n.title, n.type, n.level, n.publish_time, un.is_read").
-		Joins("LEFT JOIN t_notice n ON un.notice_id = n.id").
+		Joins("LEFT JOIN "+a.db.TableName(&system.Notice{})+" n ON un.notice_id = n.id").
 		Where("un.user_id = ?", param.UserID).
 		Where("un.is_deleted = ?", 0).
 		Where("n.is_deleted = ?", 0).
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// UserPositionRepository 用户岗位关联仓库
+type UserPositionRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewUserPositionRepository creates a new user position repository
+func NewUserPositionRepository(db lib.Database, logger lib.Logger) UserPositionRepository {
+	return UserPositionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a UserPositionRepository) WithTrx(trxHandle *gorm.DB) UserPositionRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// GetPositionIDsByUserID 获取用户的岗位ID列表
+func (a UserPositionRepository) GetPositionIDsByUserID(userID uint64) ([]uint64, error) {
+	var positionIDs []uint64
+	result := a.db.ORM.Model(&system.UserPosition{}).
+		Where("user_id = ?", userID).
+		Pluck("position_id", &positionIDs)
+
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return positionIDs, nil
+}
+
+func (a UserPositionRepository) BatchCreate(userPositions []*system.UserPosition) error {
+	if len(userPositions) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Create(&userPositions).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteByUserIDs 删除多个用户的全部岗位关联
+func (a UserPositionRepository) DeleteByUserIDs(userIDs []uint64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Where("user_id IN (?)", userIDs).Delete(&system.UserPosition{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// CountByPositions 统计各岗位的任职用户数
+func (a UserPositionRepository) CountByPositions(positionIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64)
+	if len(positionIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PositionID uint64
+		Count      int64
+	}
+	if err := a.db.ORM.Model(&system.UserPosition{}).
+		Select("position_id, COUNT(*) AS count").
+		Where("position_id IN (?)", positionIDs).
+		Group("position_id").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, row := range rows {
+		counts[row.PositionID] = row.Count
+	}
+
+	return counts, nil
+}
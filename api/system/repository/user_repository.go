@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"strings"
+
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/errors"
@@ -38,7 +40,7 @@ func (a UserRepository) Query(param *system.UserQueryParam) (*system.UserQueryRe
 	db := a.db.ORM.Model(&system.User{}).Where("is_deleted = ?", 0)
 
 	if v := param.QueryPassword; !v {
-		db = db.Omit("password")
+		db = db.Omit("password", "two_factor_secret")
 	}
 
 	if v := param.Username; v != "" {
@@ -65,6 +67,10 @@ func (a UserRepository) Query(param *system.UserQueryParam) (*system.UserQueryRe
 		db = db.Where("id IN (?)", subQuery)
 	}
 
+	if v := param.TagIDs; len(v) > 0 {
+		db = db.Where("id IN (?)", TaggedResourceIDs(db, system.TagResourceUser, v))
+	}
+
 	if v := param.QueryValue; v != "" {
 		v = "%" + v + "%"
 		db = db.Where("username LIKE ? OR nickname LIKE ? OR mobile LIKE ? OR email LIKE ?", v, v, v, v)
@@ -83,6 +89,8 @@ func (a UserRepository) Query(param *system.UserQueryParam) (*system.UserQueryRe
 		db = db.Where("create_time <= ?", v+" 23:59:59")
 	}
 
+	db = ScopeByDept(db, param.DataScope, "dept_id", "id")
+
 	db = db.Order(param.OrderParam.ParseOrder())
 
 	list := make(system.Users, 0)
@@ -142,6 +150,17 @@ func (a UserRepository) Delete(id uint64) error {
 	return nil
 }
 
+// DeleteByIDs 批量软删除用户
+func (a UserRepository) DeleteByIDs(ids []uint64, updateBy uint64) error {
+	result := a.db.ORM.Model(&system.User{}).Where("id IN (?)", ids).
+		Updates(map[string]interface{}{"is_deleted": 1, "update_by": updateBy})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 func (a UserRepository) UpdateStatus(id uint64, status int) error {
 	result := a.db.ORM.Model(&system.User{}).Where("id=?", id).Update("status", status)
 	if result.Error != nil {
@@ -151,6 +170,94 @@ func (a UserRepository) UpdateStatus(id uint64, status int) error {
 	return nil
 }
 
+// GetByIDs 根据ID列表获取未删除的用户（不含密码）
+func (a UserRepository) GetByIDs(ids []uint64) (system.Users, error) {
+	list := make(system.Users, 0)
+	if len(ids) == 0 {
+		return list, nil
+	}
+
+	result := a.db.ORM.Model(&system.User{}).Omit("password").
+		Where("id IN (?) AND is_deleted = ?", ids, 0).
+		Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// ScopedIDs 返回 ids 中在数据权限范围内的未删除用户ID，scope 为 nil 或全部数据时不过滤
+func (a UserRepository) ScopedIDs(ids []uint64, scope *system.DataScope) ([]uint64, error) {
+	scoped := make([]uint64, 0, len(ids))
+	if len(ids) == 0 {
+		return scoped, nil
+	}
+
+	db := a.db.ORM.Model(&system.User{}).Where("id IN (?) AND is_deleted = ?", ids, 0)
+	db = ScopeByDept(db, scope, "dept_id", "id")
+	if err := db.Pluck("id", &scoped).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return scoped, nil
+}
+
+// GetByDeptIDs 获取部门下未删除的用户（不含密码）
+func (a UserRepository) GetByDeptIDs(deptIDs []uint64) (system.Users, error) {
+	list := make(system.Users, 0)
+	if len(deptIDs) == 0 {
+		return list, nil
+	}
+
+	result := a.db.ORM.Model(&system.User{}).Omit("password").
+		Where("dept_id IN (?) AND is_deleted = ?", deptIDs, 0).
+		Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// CountByDeptIDs 统计部门下未删除的用户数
+func (a UserRepository) CountByDeptIDs(deptIDs []uint64) (int64, error) {
+	var count int64
+	if len(deptIDs) == 0 {
+		return 0, nil
+	}
+
+	if err := a.db.ORM.Model(&system.User{}).
+		Where("dept_id IN (?) AND is_deleted = ?", deptIDs, 0).
+		Count(&count).Error; err != nil {
+		return 0, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return count, nil
+}
+
+// BatchUpdateStatus 批量更新用户状态
+func (a UserRepository) BatchUpdateStatus(ids []uint64, status int, updateBy uint64) error {
+	result := a.db.ORM.Model(&system.User{}).Where("id IN (?)", ids).
+		Updates(map[string]interface{}{"status": status, "update_by": updateBy})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// BatchUpdateDept 批量调整用户部门
+func (a UserRepository) BatchUpdateDept(ids []uint64, deptID uint64, updateBy uint64) error {
+	result := a.db.ORM.Model(&system.User{}).Where("id IN (?)", ids).
+		Updates(map[string]interface{}{"dept_id": deptID, "update_by": updateBy})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 func (a UserRepository) UpdatePassword(id uint64, password string) error {
 	result := a.db.ORM.Model(&system.User{}).Where("id=?", id).Update("password", password)
 	if result.Error != nil {
@@ -160,6 +267,19 @@ func (a UserRepository) UpdatePassword(id uint64, password string) error {
 	return nil
 }
 
+// UpdateTwoFactor 更新两步验证密钥与启用状态，secret 为空表示解除绑定
+func (a UserRepository) UpdateTwoFactor(id uint64, secret string, enabled bool) error {
+	result := a.db.ORM.Model(&system.User{}).Where("id=?", id).Updates(map[string]interface{}{
+		"two_factor_secret":  secret,
+		"two_factor_enabled": enabled,
+	})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 func (a UserRepository) UpdateProfile(id uint64, profile *system.ProfileForm) error {
 	updates := make(map[string]interface{})
 
@@ -190,3 +310,81 @@ func (a UserRepository) UpdateProfile(id uint64, profile *system.ProfileForm) er
 
 	return nil
 }
+
+// ExistingUsernames 返回已被占用的用户名集合
+func (a UserRepository) ExistingUsernames(usernames []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(usernames) == 0 {
+		return result, nil
+	}
+
+	var list []string
+	if err := a.db.ORM.Model(&system.User{}).
+		Where("username IN (?) AND is_deleted = ?", usernames, 0).
+		Pluck("username", &list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, username := range list {
+		result[username] = true
+	}
+
+	return result, nil
+}
+
+// QueryDirectory 分页查询通讯录中的在职用户（不含密码）
+// deptIDs 为空时不限部门，keywords 只匹配 columns 中的字段
+func (a UserRepository) QueryDirectory(param *system.DirectoryQueryParam, deptIDs []uint64, columns []string) (*system.UserQueryResult, error) {
+	db := a.db.ORM.Model(&system.User{}).Omit("password").
+		Where("is_deleted = ? AND status = ?", 0, 1)
+
+	if len(deptIDs) > 0 {
+		db = db.Where("dept_id IN (?)", deptIDs)
+	}
+
+	if v := param.Keywords; v != "" && len(columns) > 0 {
+		v = "%" + v + "%"
+		conds := make([]string, 0, len(columns))
+		args := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			conds = append(conds, column+" LIKE ?")
+			args = append(args, v)
+		}
+		db = db.Where(strings.Join(conds, " OR "), args...)
+	}
+
+	db = db.Order("nickname ASC, id ASC")
+
+	list := make(system.Users, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.UserQueryResult{
+		Pagination: pagination,
+		List:       list,
+	}, nil
+}
+
+// CountActiveByDept 按部门统计在职用户数
+func (a UserRepository) CountActiveByDept() (map[uint64]int64, error) {
+	var rows []struct {
+		DeptID uint64
+		Total  int64
+	}
+	if err := a.db.ORM.Model(&system.User{}).
+		Select("dept_id, COUNT(*) AS total").
+		Where("is_deleted = ? AND status = ?", 0, 1).
+		Group("dept_id").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	result := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		result[row.DeptID] = row.Total
+	}
+
+	return result, nil
+}
@@ -121,6 +121,45 @@ func (a UserRoleRepository) DeleteByUserID(userID uint64) error {
 	return nil
 }
 
+// DeleteByUserIDs 删除多个用户的全部角色关联
+func (a UserRoleRepository) DeleteByUserIDs(userIDs []uint64) error {
+	result := a.db.ORM.Where("user_id IN (?)", userIDs).Delete(&system.UserRole{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// GetByUserIDs 获取多个用户的角色关联
+func (a UserRoleRepository) GetByUserIDs(userIDs []uint64) (system.UserRoles, error) {
+	list := make(system.UserRoles, 0)
+	if len(userIDs) == 0 {
+		return list, nil
+	}
+
+	result := a.db.ORM.Model(&system.UserRole{}).Where("user_id IN (?)", userIDs).Find(&list)
+	if result.Error != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return list, nil
+}
+
+// DeleteByUserIDsAndRoleIDs 移除多个用户的指定角色
+func (a UserRoleRepository) DeleteByUserIDsAndRoleIDs(userIDs, roleIDs []uint64) error {
+	if len(userIDs) == 0 || len(roleIDs) == 0 {
+		return nil
+	}
+
+	result := a.db.ORM.Where("user_id IN (?) AND role_id IN (?)", userIDs, roleIDs).Delete(&system.UserRole{})
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
 func (a UserRoleRepository) DeleteByRoleID(roleID uint64) error {
 	result := a.db.ORM.Where("role_id=?", roleID).Delete(&system.UserRole{})
 	if result.Error != nil {
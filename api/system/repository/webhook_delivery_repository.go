@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// WebhookDeliveryRepository Webhook 投递记录仓库
+type WebhookDeliveryRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db lib.Database, logger lib.Logger) WebhookDeliveryRepository {
+	return WebhookDeliveryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a WebhookDeliveryRepository) WithTrx(trxHandle *gorm.DB) WebhookDeliveryRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 分页查询投递记录，按时间倒序
+func (a WebhookDeliveryRepository) Query(param *system.WebhookDeliveryQueryParam) (*system.WebhookDeliveryQueryResult, error) {
+	db := a.db.ORM.Model(&system.WebhookDelivery{})
+
+	if v := param.WebhookID; v > 0 {
+		db = db.Where("webhook_id = ?", v)
+	}
+
+	if v := param.Event; v != "" {
+		db = db.Where("event = ?", v)
+	}
+
+	if v := param.Status; v != "" {
+		db = db.Where("status = ?", v)
+	}
+
+	if v := param.EventID; v != "" {
+		db = db.Where("event_id = ?", v)
+	}
+
+	db = db.Order("id DESC")
+
+	list := make(system.WebhookDeliveries, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.WebhookDeliveryQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// Get 获取投递记录
+func (a WebhookDeliveryRepository) Get(id uint64) (*system.WebhookDelivery, error) {
+	delivery := new(system.WebhookDelivery)
+
+	if ok, err := QueryOne(a.db.ORM.Model(delivery).Where("id = ?", id), delivery); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.WebhookDeliveryNotFound
+	}
+
+	return delivery, nil
+}
+
+func (a WebhookDeliveryRepository) Create(delivery *system.WebhookDelivery) error {
+	if err := a.db.ORM.Create(delivery).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// Updates 更新投递记录的部分字段
+func (a WebhookDeliveryRepository) Updates(id uint64, updates map[string]interface{}) error {
+	if err := a.db.ORM.Model(&system.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// WebhookRepository Webhook 仓库
+type WebhookRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db lib.Database, logger lib.Logger) WebhookRepository {
+	return WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a WebhookRepository) WithTrx(trxHandle *gorm.DB) WebhookRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 查询 Webhook 分页列表
+func (a WebhookRepository) Query(param *system.WebhookQueryParam) (*system.WebhookQueryResult, error) {
+	db := a.db.ORM.Model(&system.Webhook{})
+
+	if v := param.Keywords; v != "" {
+		v = "%" + v + "%"
+		db = db.Where("name LIKE ? OR url LIKE ?", v, v)
+	}
+
+	if v := param.Status; v != nil {
+		db = db.Where("status = ?", *v)
+	}
+
+	db = db.Order("id ASC")
+
+	list := make(system.Webhooks, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.WebhookQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// Get 获取 Webhook
+func (a WebhookRepository) Get(id uint64) (*system.Webhook, error) {
+	webhook := new(system.Webhook)
+
+	if ok, err := QueryOne(a.db.ORM.Model(webhook).Where("id = ?", id), webhook); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.WebhookRecordNotFound
+	}
+
+	return webhook, nil
+}
+
+// ListEnabled 全部启用的 Webhook
+func (a WebhookRepository) ListEnabled() (system.Webhooks, error) {
+	list := make(system.Webhooks, 0)
+	if err := a.db.ORM.Where("status = ?", 1).Order("id ASC").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+func (a WebhookRepository) Create(webhook *system.Webhook) error {
+	if err := a.db.ORM.Create(webhook).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+func (a WebhookRepository) Update(id uint64, webhook *system.Webhook) error {
+	result := a.db.ORM.Model(webhook).Where("id = ?", id).
+		Select("name", "url", "secret", "events", "status", "remark", "update_by").Updates(webhook)
+	if result.Error != nil {
+		return errors.Wrap(errors.DatabaseInternalError, result.Error.Error())
+	}
+
+	return nil
+}
+
+// Delete 删除 Webhook，投递记录保留
+func (a WebhookRepository) Delete(id uint64) error {
+	if err := a.db.ORM.Where("id = ?", id).Delete(&system.Webhook{}).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// WsEventRepository 推送事件及送达记录仓库
+type WsEventRepository struct {
+	db     lib.Database
+	logger lib.Logger
+}
+
+// NewWsEventRepository creates a new websocket event repository
+func NewWsEventRepository(db lib.Database, logger lib.Logger) WsEventRepository {
+	return WsEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithTrx enables repository with transaction
+func (a WsEventRepository) WithTrx(trxHandle *gorm.DB) WsEventRepository {
+	if trxHandle == nil {
+		a.logger.Zap.Error("Transaction Database not found in echo context.")
+		return a
+	}
+
+	a.db.ORM = trxHandle
+	return a
+}
+
+// Query 分页查询推送事件
+func (a WsEventRepository) Query(param *system.WsEventQueryParam) (*system.WsEventQueryResult, error) {
+	db := a.db.ORM.Model(&system.WsEvent{})
+
+	if v := param.Kind; v != "" {
+		db = db.Where("kind = ?", v)
+	}
+
+	if v := param.From; v != "" {
+		db = db.Where("create_time >= ?", v)
+	}
+
+	if v := param.To; v != "" {
+		db = db.Where("create_time <= ?", v+" 23:59:59")
+	}
+
+	db = db.Order("id DESC")
+
+	list := make(system.WsEvents, 0)
+	pagination, err := QueryPagination(db, param.PaginationParam, &list)
+	if err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return &system.WsEventQueryResult{
+		List:       list,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetByEventID 根据事件 ID 获取推送事件
+func (a WsEventRepository) GetByEventID(eventID string) (*system.WsEvent, error) {
+	event := new(system.WsEvent)
+
+	if ok, err := QueryOne(a.db.ORM.Model(event).Where("event_id = ?", eventID), event); err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	} else if !ok {
+		return nil, errors.WsEventRecordNotFound
+	}
+
+	return event, nil
+}
+
+// Create 记录推送事件及推送对象
+func (a WsEventRepository) Create(event *system.WsEvent, deliveries system.WsEventDeliveries) error {
+	err := a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&deliveries, 500).Error
+	})
+	if err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// ListDeliveries 获取事件的送达记录
+func (a WsEventRepository) ListDeliveries(eventID string) (system.WsEventDeliveries, error) {
+	list := make(system.WsEventDeliveries, 0)
+
+	if err := a.db.ORM.Where("event_id = ?", eventID).Order("username").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return list, nil
+}
+
+// Ack 记录回执，displayed 同时视为已收到；已记录的时间不会被后续回执覆盖
+// 只有推送对象可以回执；横幅推送给全部在线用户，推送后才上线的会话回执时补建送达记录，补建的记录没有 userId
+func (a WsEventRepository) Ack(event *system.WsEvent, username string, displayed bool, at time.Time) error {
+	delivery := new(system.WsEventDelivery)
+	db := a.db.ORM.Model(delivery).Where("event_id = ? AND username = ?", event.EventID, username)
+	ok, err := QueryOne(db, delivery)
+	if err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	if !ok {
+		if event.Kind != system.WsEventBanner {
+			return errors.WsEventNotTargeted
+		}
+
+		delivery = &system.WsEventDelivery{
+			EventID:      event.EventID,
+			Username:     username,
+			Online:       true,
+			ReceivedTime: dto.NullDateTime{Time: at, Valid: true},
+		}
+		if displayed {
+			delivery.DisplayedTime = dto.NullDateTime{Time: at, Valid: true}
+		}
+		if err := a.db.ORM.Create(delivery).Error; err != nil {
+			return errors.Wrap(errors.DatabaseInternalError, err.Error())
+		}
+		return nil
+	}
+
+	values := make(map[string]interface{})
+	if !delivery.ReceivedTime.Valid {
+		values["received_time"] = dto.NullDateTime{Time: at, Valid: true}
+	}
+	if displayed && !delivery.DisplayedTime.Valid {
+		values["displayed_time"] = dto.NullDateTime{Time: at, Valid: true}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	if err := a.db.ORM.Model(&system.WsEventDelivery{}).Where("id = ?", delivery.ID).Updates(values).Error; err != nil {
+		return errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// CountAcks 统计各事件已收到、已展示的对象数
+func (a WsEventRepository) CountAcks(eventIDs []string) (received, displayed map[string]int64, err error) {
+	received = make(map[string]int64)
+	displayed = make(map[string]int64)
+	if len(eventIDs) == 0 {
+		return received, displayed, nil
+	}
+
+	var rows []struct {
+		EventID   string
+		Received  int64
+		Displayed int64
+	}
+	if err := a.db.ORM.Model(&system.WsEventDelivery{}).
+		Select("event_id, COUNT(received_time) AS received, COUNT(displayed_time) AS displayed").
+		Where("event_id IN (?)", eventIDs).
+		Group("event_id").
+		Scan(&rows).Error; err != nil {
+		return nil, nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+	}
+
+	for _, row := range rows {
+		received[row.EventID] = row.Received
+		displayed[row.EventID] = row.Displayed
+	}
+
+	return received, displayed, nil
+}
@@ -0,0 +1,40 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+)
+
+// AnalyticsRoutes struct
+type AnalyticsRoutes struct {
+	logger              lib.Logger
+	handler             lib.HttpHandler
+	analyticsController controller.AnalyticsController
+	permMiddleware      middlewares.PermissionMiddleware
+}
+
+// NewAnalyticsRoutes creates new analytics routes
+func NewAnalyticsRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	analyticsController controller.AnalyticsController,
+	permMiddleware middlewares.PermissionMiddleware,
+) AnalyticsRoutes {
+	return AnalyticsRoutes{
+		logger:              logger,
+		handler:             handler,
+		analyticsController: analyticsController,
+		permMiddleware:      permMiddleware,
+	}
+}
+
+// Setup analytics routes
+func (a AnalyticsRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/analytics"))
+	{
+		api.GET("/api-usage", a.analyticsController.QueryApiUsage, "sys:analytics:query")
+		api.GET("/api-usage/users", a.analyticsController.TopUsers, "sys:analytics:query")
+		api.GET("/api-usage/routes", a.analyticsController.TopRoutes, "sys:analytics:query")
+	}
+}
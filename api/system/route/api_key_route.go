@@ -0,0 +1,42 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ApiKeyRoutes struct
+type ApiKeyRoutes struct {
+	logger           lib.Logger
+	handler          lib.HttpHandler
+	apiKeyController controller.ApiKeyController
+	permMiddleware   middlewares.PermissionMiddleware
+}
+
+// NewApiKeyRoutes creates new api key routes
+func NewApiKeyRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	apiKeyController controller.ApiKeyController,
+	permMiddleware middlewares.PermissionMiddleware,
+) ApiKeyRoutes {
+	return ApiKeyRoutes{
+		logger:           logger,
+		handler:          handler,
+		apiKeyController: apiKeyController,
+		permMiddleware:   permMiddleware,
+	}
+}
+
+// Setup api key routes
+// 用户管理自己的 API Key，登录即可访问，使用 API Key 认证的请求不能管理密钥
+func (a ApiKeyRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/api-keys"))
+	{
+		api.GET("", a.apiKeyController.List, "")
+		api.Describe("创建 API Key", system.ApiKeyForm{}).POST("", a.apiKeyController.Create, "")
+		api.DELETE("/:id", a.apiKeyController.Revoke, "")
+	}
+}
@@ -0,0 +1,41 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+)
+
+// ComplianceRoutes struct
+type ComplianceRoutes struct {
+	logger               lib.Logger
+	handler              lib.HttpHandler
+	complianceController controller.ComplianceController
+	permMiddleware       middlewares.PermissionMiddleware
+}
+
+// NewComplianceRoutes creates new compliance routes
+func NewComplianceRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	complianceController controller.ComplianceController,
+	permMiddleware middlewares.PermissionMiddleware,
+) ComplianceRoutes {
+	return ComplianceRoutes{
+		logger:               logger,
+		handler:              handler,
+		complianceController: complianceController,
+		permMiddleware:       permMiddleware,
+	}
+}
+
+// Setup compliance routes
+func (a ComplianceRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/compliance"))
+	{
+		api.POST("/reports", a.complianceController.Generate, "sys:compliance:generate")
+		api.GET("/reports", a.complianceController.Query, "sys:compliance:query")
+		api.GET("/reports/:id", a.complianceController.Get, "sys:compliance:query")
+		api.GET("/reports/:id/download", a.complianceController.Download, "sys:compliance:query")
+	}
+}
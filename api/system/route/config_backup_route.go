@@ -0,0 +1,44 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ConfigBackupRoutes struct
+type ConfigBackupRoutes struct {
+	logger                 lib.Logger
+	handler                lib.HttpHandler
+	configBackupController controller.ConfigBackupController
+	permMiddleware         middlewares.PermissionMiddleware
+}
+
+// NewConfigBackupRoutes creates new config backup routes
+func NewConfigBackupRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	configBackupController controller.ConfigBackupController,
+	permMiddleware middlewares.PermissionMiddleware,
+) ConfigBackupRoutes {
+	return ConfigBackupRoutes{
+		logger:                 logger,
+		handler:                handler,
+		configBackupController: configBackupController,
+		permMiddleware:         permMiddleware,
+	}
+}
+
+// Setup config backup routes
+func (a ConfigBackupRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/config-backups"))
+	{
+		api.Describe("备份配置", system.ConfigBackupForm{}).POST("", a.configBackupController.Create, "sys:config-backup:add")
+		api.GET("", a.configBackupController.Query, "sys:config-backup:query")
+		api.GET("/:id", a.configBackupController.Get, "sys:config-backup:query")
+		api.GET("/:id/download", a.configBackupController.Download, "sys:config-backup:query")
+		api.GET("/:id/diff", a.configBackupController.Diff, "sys:config-backup:query")
+		api.Describe("恢复配置", nil).POST("/:id/restore", a.configBackupController.Restore, "sys:config-backup:restore")
+	}
+}
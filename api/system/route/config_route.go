@@ -30,13 +30,13 @@ func NewConfigRoutes(
 
 // Setup config routes
 func (a ConfigRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/configs")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/configs"))
 	{
-		api.GET("", a.configController.Query, a.permMiddleware.RequirePerm("sys:config:query"))
-		api.GET("/:id/form", a.configController.GetForm, a.permMiddleware.RequirePerm("sys:config:query"))
-		api.POST("", a.configController.Create, a.permMiddleware.RequirePerm("sys:config:add"))
-		api.PUT("/:id", a.configController.Update, a.permMiddleware.RequirePerm("sys:config:update"))
-		api.DELETE("/:id", a.configController.Delete, a.permMiddleware.RequirePerm("sys:config:delete"))
-		api.PUT("/refresh", a.configController.RefreshCache, a.permMiddleware.RequirePerm("sys:config:refresh"))
+		api.GET("", a.configController.Query, "sys:config:query")
+		api.GET("/:id/form", a.configController.GetForm, "sys:config:query")
+		api.POST("", a.configController.Create, "sys:config:add")
+		api.PUT("/:id", a.configController.Update, "sys:config:update")
+		api.DELETE("/:id", a.configController.Delete, "sys:config:delete")
+		api.PUT("/refresh", a.configController.RefreshCache, "sys:config:refresh")
 	}
 }
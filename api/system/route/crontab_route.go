@@ -0,0 +1,47 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// CrontabRoutes struct
+type CrontabRoutes struct {
+	logger            lib.Logger
+	handler           lib.HttpHandler
+	crontabController controller.CrontabController
+	permMiddleware    middlewares.PermissionMiddleware
+}
+
+// NewCrontabRoutes creates new crontab routes
+func NewCrontabRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	crontabController controller.CrontabController,
+	permMiddleware middlewares.PermissionMiddleware,
+) CrontabRoutes {
+	return CrontabRoutes{
+		logger:            logger,
+		handler:           handler,
+		crontabController: crontabController,
+		permMiddleware:    permMiddleware,
+	}
+}
+
+// Setup crontab routes
+func (a CrontabRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/crontab"))
+	{
+		api.GET("/handlers", a.crontabController.Handlers, "sys:crontab:query")
+		api.GET("/tasks", a.crontabController.List, "sys:crontab:query")
+		api.GET("/tasks/:name/history", a.crontabController.History, "sys:crontab:query")
+		api.Describe("新增定时任务", system.CronTaskForm{}).POST("/tasks", a.crontabController.Create, "sys:crontab:add")
+		api.Describe("修改定时任务", system.CronTaskUpdateForm{}).PUT("/tasks/:name", a.crontabController.Update, "sys:crontab:edit")
+		api.Describe("启用/停用定时任务", system.CronTaskStatusForm{}).PUT("/tasks/:name/status", a.crontabController.SetStatus, "sys:crontab:edit")
+		api.Describe("立即执行定时任务", nil).POST("/tasks/:name/run", a.crontabController.Run, "sys:crontab:run")
+		api.Describe("删除定时任务", nil).DELETE("/tasks/:name", a.crontabController.Delete, "sys:crontab:delete")
+		api.Describe("重新加载定时任务", nil).POST("/reload", a.crontabController.Reload, "sys:crontab:edit")
+	}
+}
@@ -0,0 +1,44 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DeptRoleRoutes struct
+type DeptRoleRoutes struct {
+	logger             lib.Logger
+	handler            lib.HttpHandler
+	deptRoleController controller.DeptRoleController
+	permMiddleware     middlewares.PermissionMiddleware
+}
+
+// NewDeptRoleRoutes creates new dept role routes
+func NewDeptRoleRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	deptRoleController controller.DeptRoleController,
+	permMiddleware middlewares.PermissionMiddleware,
+) DeptRoleRoutes {
+	return DeptRoleRoutes{
+		logger:             logger,
+		handler:            handler,
+		deptRoleController: deptRoleController,
+		permMiddleware:     permMiddleware,
+	}
+}
+
+// Setup dept role routes
+func (a DeptRoleRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/dept-role-rules"))
+	{
+		api.Describe("查询部门默认角色规则", system.DeptRoleRuleQueryParam{}).GET("", a.deptRoleController.Query, "sys:dept-role:query")
+		api.Describe("新增部门默认角色规则", system.DeptRoleRuleForm{}).POST("", a.deptRoleController.Create, "sys:dept-role:add")
+		api.Describe("预览部门默认角色变更", system.DeptRolePreviewForm{}).POST("/preview", a.deptRoleController.Preview, "sys:dept-role:query")
+		api.Describe("同步部门默认角色", system.DeptRoleApplyForm{}).POST("/apply", a.deptRoleController.Apply, "sys:dept-role:apply")
+		api.Describe("修改部门默认角色规则", system.DeptRoleRuleForm{}).PUT("/:id", a.deptRoleController.Update, "sys:dept-role:edit")
+		api.DELETE("/:ids", a.deptRoleController.Delete, "sys:dept-role:delete")
+	}
+}
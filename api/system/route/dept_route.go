@@ -4,6 +4,7 @@ import (
 	"github.com/top-system/light-admin/api/system/controller"
 	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
 )
 
 type DeptRoutes struct {
@@ -30,13 +31,13 @@ func NewDeptRoutes(
 
 // Setup dept routes
 func (a DeptRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/depts")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/depts"))
 	{
-		api.GET("", a.deptController.Query, a.permMiddleware.RequirePerm("sys:dept:query"))
-		api.GET("/options", a.deptController.GetOptions) // 下拉选项，无需权限
-		api.GET("/:deptId/form", a.deptController.GetForm, a.permMiddleware.RequirePerm("sys:dept:query"))
-		api.POST("", a.deptController.Create, a.permMiddleware.RequirePerm("sys:dept:add"))
-		api.PUT("/:deptId", a.deptController.Update, a.permMiddleware.RequirePerm("sys:dept:edit"))
-		api.DELETE("/:ids", a.deptController.Delete, a.permMiddleware.RequirePerm("sys:dept:delete"))
+		api.Describe("查询部门", system.DeptQueryParam{}).GET("", a.deptController.Query, "sys:dept:query")
+		api.AllowApiKey().GET("/options", a.deptController.GetOptions, "") // 下拉选项，无需权限
+		api.GET("/:deptId/form", a.deptController.GetForm, "sys:dept:query")
+		api.Describe("新增部门", system.DeptForm{}).POST("", a.deptController.Create, "sys:dept:add")
+		api.Describe("修改部门", system.DeptForm{}).PUT("/:deptId", a.deptController.Update, "sys:dept:edit")
+		api.DELETE("/:ids", a.deptController.Delete, "sys:dept:delete")
 	}
 }
@@ -7,10 +7,11 @@ import (
 )
 
 type DictRoutes struct {
-	logger         lib.Logger
-	handler        lib.HttpHandler
-	dictController controller.DictController
-	permMiddleware middlewares.PermissionMiddleware
+	logger          lib.Logger
+	handler         lib.HttpHandler
+	dictController  controller.DictController
+	permMiddleware  middlewares.PermissionMiddleware
+	cacheMiddleware middlewares.ResponseCacheMiddleware
 }
 
 // NewDictRoutes creates new dict routes
@@ -19,32 +20,37 @@ func NewDictRoutes(
 	handler lib.HttpHandler,
 	dictController controller.DictController,
 	permMiddleware middlewares.PermissionMiddleware,
+	cacheMiddleware middlewares.ResponseCacheMiddleware,
 ) DictRoutes {
 	return DictRoutes{
-		handler:        handler,
-		logger:         logger,
-		dictController: dictController,
-		permMiddleware: permMiddleware,
+		handler:         handler,
+		logger:          logger,
+		dictController:  dictController,
+		permMiddleware:  permMiddleware,
+		cacheMiddleware: cacheMiddleware,
 	}
 }
 
 // Setup dict routes
 func (a DictRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/dicts")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/dicts"))
 	{
 		// 字典相关接口
-		api.GET("", a.dictController.GetDictPage, a.permMiddleware.RequirePerm("sys:dict:query"))
-		api.GET("/:id/form", a.dictController.GetDictForm, a.permMiddleware.RequirePerm("sys:dict:query"))
-		api.POST("", a.dictController.SaveDict, a.permMiddleware.RequirePerm("sys:dict:add"))
-		api.PUT("/:id", a.dictController.UpdateDict, a.permMiddleware.RequirePerm("sys:dict:edit"))
-		api.DELETE("/:ids", a.dictController.DeleteDict, a.permMiddleware.RequirePerm("sys:dict:delete"))
+		api.GET("", a.dictController.GetDictPage, "sys:dict:query")
+		api.GET("/:id/form", a.dictController.GetDictForm, "sys:dict:query")
+		api.POST("", a.dictController.SaveDict, "sys:dict:add")
+		api.PUT("/:id", a.dictController.UpdateDict, "sys:dict:edit")
+		api.DELETE("/:ids", a.dictController.DeleteDict, "sys:dict:delete")
+		api.AllowApiKey().GET("/delta", a.dictController.GetDelta, "") // 字典及字典项增量同步，无需权限
 
 		// 字典项相关接口
-		api.GET("/:dictCode/items", a.dictController.GetDictItems, a.permMiddleware.RequirePerm("sys:dict-item:query"))
-		api.GET("/:dictCode/items/options", a.dictController.GetDictItemOptions) // 无需权限，用于下拉选项
-		api.GET("/:dictCode/items/:itemId/form", a.dictController.GetDictItemForm, a.permMiddleware.RequirePerm("sys:dict-item:query"))
-		api.POST("/:dictCode/items", a.dictController.SaveDictItem, a.permMiddleware.RequirePerm("sys:dict-item:add"))
-		api.PUT("/:dictCode/items/:itemId", a.dictController.UpdateDictItem, a.permMiddleware.RequirePerm("sys:dict-item:edit"))
-		api.DELETE("/:dictCode/items/:itemIds", a.dictController.DeleteDictItem, a.permMiddleware.RequirePerm("sys:dict-item:delete"))
+		api.GET("/:dictCode/items", a.dictController.GetDictItems, "sys:dict-item:query")
+		// 无需权限，用于下拉选项
+		api.GET("/:dictCode/items/options", a.dictController.GetDictItemOptions, "", a.cacheMiddleware.Cache(lib.ResponseCacheGroupDict, middlewares.CacheScopeGlobal))
+		api.GET("/:dictCode/items/:itemId/form", a.dictController.GetDictItemForm, "sys:dict-item:query")
+		api.POST("/:dictCode/items", a.dictController.SaveDictItem, "sys:dict-item:add")
+		api.POST("/:dictCode/items/export", a.dictController.ExportDictItems, "sys:dict-item:export")
+		api.PUT("/:dictCode/items/:itemId", a.dictController.UpdateDictItem, "sys:dict-item:edit")
+		api.DELETE("/:dictCode/items/:itemIds", a.dictController.DeleteDictItem, "sys:dict-item:delete")
 	}
 }
@@ -0,0 +1,46 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DirectoryRoutes struct
+type DirectoryRoutes struct {
+	logger              lib.Logger
+	handler             lib.HttpHandler
+	directoryController controller.DirectoryController
+	permMiddleware      middlewares.PermissionMiddleware
+	cacheMiddleware     middlewares.ResponseCacheMiddleware
+}
+
+// NewDirectoryRoutes creates new directory routes
+func NewDirectoryRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	directoryController controller.DirectoryController,
+	permMiddleware middlewares.PermissionMiddleware,
+	cacheMiddleware middlewares.ResponseCacheMiddleware,
+) DirectoryRoutes {
+	return DirectoryRoutes{
+		logger:              logger,
+		handler:             handler,
+		directoryController: directoryController,
+		permMiddleware:      permMiddleware,
+		cacheMiddleware:     cacheMiddleware,
+	}
+}
+
+// Setup directory routes
+// 人员列表按角色缓存，相同角色组合可见的联系方式字段一致
+func (a DirectoryRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/directory"))
+	{
+		api.Describe("组织架构", nil).GET("/depts", a.directoryController.Depts, "sys:directory:query",
+			a.cacheMiddleware.Cache(lib.ResponseCacheGroupDirectory, middlewares.CacheScopeGlobal))
+		api.Describe("通讯录人员", system.DirectoryQueryParam{}).GET("/users", a.directoryController.Users, "sys:directory:query",
+			a.cacheMiddleware.Cache(lib.ResponseCacheGroupDirectory, middlewares.CacheScopeRole))
+	}
+}
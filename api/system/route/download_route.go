@@ -4,6 +4,7 @@ import (
 	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/api/system/controller"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
 )
 
 type DownloadRoutes struct {
@@ -31,17 +32,35 @@ func NewDownloadRoutes(
 // Setup download routes
 func (a DownloadRoutes) Setup() {
 	a.logger.Zap.Info("Setting up download routes")
-	api := a.handler.RouterV1.Group("/downloads")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/downloads"))
 	{
-		api.GET("/stats", a.downloadController.GetStats)                // 获取统计信息
-		api.GET("/downloaders", a.downloadController.GetDownloaders)    // 获取下载器列表
-		api.GET("/test/:name", a.downloadController.TestDownloader)     // 测试下载器
-		api.GET("", a.downloadController.Query, a.permMiddleware.RequirePerm("sys:download:query"))
-		api.GET("/:id", a.downloadController.Get, a.permMiddleware.RequirePerm("sys:download:query"))
-		api.POST("", a.downloadController.Create, a.permMiddleware.RequirePerm("sys:download:add"))
-		api.POST("/:id/cancel", a.downloadController.Cancel, a.permMiddleware.RequirePerm("sys:download:edit"))
-		api.PUT("/:id/files", a.downloadController.SetFiles, a.permMiddleware.RequirePerm("sys:download:edit"))
-		api.POST("/:id/sync", a.downloadController.Sync, a.permMiddleware.RequirePerm("sys:download:query"))
-		api.DELETE("/:id", a.downloadController.Delete, a.permMiddleware.RequirePerm("sys:download:delete"))
+		api.GET("/stats", a.downloadController.GetStats, "")             // 获取统计信息
+		api.GET("/downloaders", a.downloadController.GetDownloaders, "") // 获取下载器列表
+		api.GET("/test/:name", a.downloadController.TestDownloader, "")  // 测试下载器
+		api.GET("/downloaders/health", a.downloadController.GetDownloaderHealth, "sys:download:query")
+		api.GET("/downloaders/configs", a.downloadController.GetDownloaderConfigs, "sys:download:downloader")
+		api.Describe("新增下载器", system.DownloaderConfigForm{}).POST("/downloaders", a.downloadController.CreateDownloader, "sys:download:downloader")
+		api.Describe("修改下载器", system.DownloaderConfigForm{}).PUT("/downloaders/:id", a.downloadController.UpdateDownloader, "sys:download:downloader")
+		api.DELETE("/downloaders/:id", a.downloadController.DeleteDownloader, "sys:download:downloader")
+		api.GET("/session/:name", a.downloadController.GetSession, "sys:download:session")
+		api.POST("/session/:name/save", a.downloadController.SaveSession, "sys:download:session")
+		api.Describe("下载器会话对账", system.DownloadSessionReconcileForm{}).POST("/session/:name/reconcile", a.downloadController.ReconcileSession, "sys:download:session")
+		api.GET("/usage/me", a.downloadController.UsageMe, "sys:download:query")
+		api.Describe("下载流量统计", system.DownloadUsageQueryParam{}).GET("/usage", a.downloadController.Usage, "sys:download:usage")
+		api.Describe("查询下载任务", system.DownloadTaskQueryParam{}).GET("", a.downloadController.Query, "sys:download:query")
+		api.Describe("媒体库", system.DownloadMediaQueryParam{}).GET("/media", a.downloadController.QueryMedia, "sys:download:query")
+		api.GET("/:id", a.downloadController.Get, "sys:download:query")
+		api.Describe("新建下载任务", system.DownloadTaskCreateForm{}).POST("", a.downloadController.Create, "sys:download:add")
+		api.Describe("导出下载任务", system.DownloadTaskQueryParam{}).POST("/export", a.downloadController.Export, "sys:download:export")
+		api.POST("/:id/cancel", a.downloadController.Cancel, "sys:download:edit")
+		api.POST("/:id/pause", a.downloadController.Pause, "sys:download:edit")
+		api.POST("/:id/resume", a.downloadController.Resume, "sys:download:edit")
+		api.PUT("/:id/files", a.downloadController.SetFiles, "sys:download:edit")
+		api.Describe("设置任务限速", system.DownloadSpeedLimitForm{}).PUT("/:id/limits", a.downloadController.SetSpeedLimit, "sys:download:edit")
+		api.Describe("浏览压缩包", system.DownloadArchiveQueryParam{}).GET("/:id/archive", a.downloadController.ListArchive, "sys:download:query")
+		api.Describe("解压压缩包", system.DownloadArchiveExtractForm{}).POST("/:id/archive/extract", a.downloadController.ExtractArchive, "sys:download:edit")
+		api.POST("/:id/media", a.downloadController.ExtractMedia, "sys:download:edit") // 重新提取音视频元数据
+		api.POST("/:id/sync", a.downloadController.Sync, "sys:download:query")
+		api.DELETE("/:id", a.downloadController.Delete, "sys:download:delete")
 	}
 }
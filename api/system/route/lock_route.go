@@ -0,0 +1,43 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// LockRoutes struct
+type LockRoutes struct {
+	logger         lib.Logger
+	handler        lib.HttpHandler
+	lockController controller.LockController
+	permMiddleware middlewares.PermissionMiddleware
+}
+
+// NewLockRoutes creates new lock routes
+func NewLockRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	lockController controller.LockController,
+	permMiddleware middlewares.PermissionMiddleware,
+) LockRoutes {
+	return LockRoutes{
+		logger:         logger,
+		handler:        handler,
+		lockController: lockController,
+		permMiddleware: permMiddleware,
+	}
+}
+
+// Setup lock routes
+func (a LockRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/locks"))
+	{
+		api.GET("", a.lockController.List, "sys:lock:query")
+		api.GET("/:name", a.lockController.Get, "sys:lock:query")
+		api.Describe("获取资源锁", system.LockAcquireForm{}).POST("/:name", a.lockController.Acquire, "sys:lock:acquire")
+		api.Describe("释放资源锁", system.LockReleaseParam{}).DELETE("/:name", a.lockController.Release, "sys:lock:acquire")
+		api.DELETE("/:name/force", a.lockController.ForceRelease, "sys:lock:force")
+	}
+}
@@ -31,8 +31,11 @@ func NewLogRoute(
 
 // Setup log routes
 func (a LogRoute) Setup() {
-	api := a.handler.RouterV1.Group("/logs")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/logs"))
 	{
-		api.GET("", a.logController.Query, a.permMiddleware.RequirePerm("sys:log:query"))
+		api.GET("", a.logController.Query, "sys:log:query")
+		api.POST("/export", a.logController.Export, "sys:log:export")
+		api.GET("/login", a.logController.QueryLogin, "sys:log:login")
+		api.GET("/operations", a.logController.QueryOperations, "sys:log:operation")
 	}
 }
@@ -0,0 +1,45 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// MailTemplateRoutes struct
+type MailTemplateRoutes struct {
+	logger                 lib.Logger
+	handler                lib.HttpHandler
+	mailTemplateController controller.MailTemplateController
+	permMiddleware         middlewares.PermissionMiddleware
+}
+
+// NewMailTemplateRoutes creates new mail template routes
+func NewMailTemplateRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	mailTemplateController controller.MailTemplateController,
+	permMiddleware middlewares.PermissionMiddleware,
+) MailTemplateRoutes {
+	return MailTemplateRoutes{
+		logger:                 logger,
+		handler:                handler,
+		mailTemplateController: mailTemplateController,
+		permMiddleware:         permMiddleware,
+	}
+}
+
+// Setup mail template routes
+func (a MailTemplateRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/mail-templates"))
+	{
+		api.Describe("查询邮件模板", system.MailTemplateQueryParam{}).GET("", a.mailTemplateController.Query, "sys:mail-template:query")
+		api.Describe("预览邮件模板", system.MailTemplatePreviewForm{}).POST("/preview", a.mailTemplateController.Preview, "sys:mail-template:query")
+		api.GET("/:id", a.mailTemplateController.Get, "sys:mail-template:query")
+		api.Describe("新增邮件模板", system.MailTemplateForm{}).POST("", a.mailTemplateController.Create, "sys:mail-template:add")
+		api.Describe("修改邮件模板", system.MailTemplateForm{}).PUT("/:id", a.mailTemplateController.Update, "sys:mail-template:edit")
+		api.DELETE("/:id", a.mailTemplateController.Delete, "sys:mail-template:delete")
+		api.Describe("发送测试邮件", system.MailTemplateTestForm{}).POST("/:id/test", a.mailTemplateController.Test, "sys:mail-template:test")
+	}
+}
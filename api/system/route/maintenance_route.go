@@ -0,0 +1,50 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+type MaintenanceRoutes struct {
+	logger                lib.Logger
+	handler               lib.HttpHandler
+	maintenanceController controller.MaintenanceController
+	permMiddleware        middlewares.PermissionMiddleware
+}
+
+// NewMaintenanceRoutes creates new maintenance routes
+func NewMaintenanceRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	maintenanceController controller.MaintenanceController,
+	permMiddleware middlewares.PermissionMiddleware,
+) MaintenanceRoutes {
+	return MaintenanceRoutes{
+		handler:               handler,
+		logger:                logger,
+		maintenanceController: maintenanceController,
+		permMiddleware:        permMiddleware,
+	}
+}
+
+// Setup maintenance routes
+func (a MaintenanceRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/maintenance"))
+	{
+		api.AllowApiKey().GET("/read-only", a.maintenanceController.GetReadOnly, "")               // 只读状态，前端据此提示
+		api.PUT("/read-only", a.maintenanceController.SetReadOnly, "sys:maintenance:read-only")    // 只读模式切换
+		api.GET("/config", a.maintenanceController.GetConfig, "sys:maintenance:config")            // 生效配置（已脱敏）
+		api.GET("/log-shipping", a.maintenanceController.GetLogShipping, "sys:maintenance:config") // 日志转发统计
+
+		// 功能模块启停，禁用的模块接口返回 404、菜单不再返回、后台任务停止
+		api.GET("/modules", a.maintenanceController.GetModules, "")
+		api.Describe("功能模块启停", dto.FeatureModuleForm{}).PUT("/modules/:name", a.maintenanceController.SetModule, "sys:maintenance:module")
+
+		// 性能分析采集，文件通过文件服务保存
+		api.Describe("采集性能分析", dto.ProfileForm{}).POST("/profiles", a.maintenanceController.CreateProfile, "sys:maintenance:profile")
+		api.GET("/profiles", a.maintenanceController.QueryProfiles, "sys:maintenance:profile")
+		api.GET("/profiles/:id/download", a.maintenanceController.DownloadProfile, "sys:maintenance:profile")
+	}
+}
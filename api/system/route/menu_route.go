@@ -7,10 +7,11 @@ import (
 )
 
 type MenuRoutes struct {
-	logger         lib.Logger
-	handler        lib.HttpHandler
-	menuController controller.MenuController
-	permMiddleware middlewares.PermissionMiddleware
+	logger          lib.Logger
+	handler         lib.HttpHandler
+	menuController  controller.MenuController
+	permMiddleware  middlewares.PermissionMiddleware
+	cacheMiddleware middlewares.ResponseCacheMiddleware
 }
 
 // NewMenuRoutes creates new menu routes
@@ -19,26 +20,34 @@ func NewMenuRoutes(
 	handler lib.HttpHandler,
 	menuController controller.MenuController,
 	permMiddleware middlewares.PermissionMiddleware,
+	cacheMiddleware middlewares.ResponseCacheMiddleware,
 ) MenuRoutes {
 	return MenuRoutes{
-		handler:        handler,
-		logger:         logger,
-		menuController: menuController,
-		permMiddleware: permMiddleware,
+		handler:         handler,
+		logger:          logger,
+		menuController:  menuController,
+		permMiddleware:  permMiddleware,
+		cacheMiddleware: cacheMiddleware,
 	}
 }
 
 // Setup menu routes
 func (a MenuRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/menus")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/menus"))
 	{
-		api.GET("", a.menuController.Query, a.permMiddleware.RequirePerm("sys:menu:query"))
-		api.GET("/routes", a.menuController.Routes)      // 获取路由，无需权限（用于动态路由）
-		api.GET("/options", a.menuController.GetOptions) // 下拉选项，无需权限
+		api.GET("", a.menuController.Query, "sys:menu:query")
+		api.AllowApiKey().GET("/routes", a.menuController.Routes, "")            // 获取路由，无需权限（用于动态路由）
+		api.AllowApiKey().GET("/routes/delta", a.menuController.RoutesDelta, "") // 路由菜单增量同步
+		// 下拉选项，无需权限
+		api.GET("/options", a.menuController.GetOptions, "", a.cacheMiddleware.Cache(lib.ResponseCacheGroupMenu, middlewares.CacheScopeRole))
 
-		api.POST("", a.menuController.Create, a.permMiddleware.RequirePerm("sys:menu:add"))
-		api.GET("/:id/form", a.menuController.GetForm, a.permMiddleware.RequirePerm("sys:menu:query"))
-		api.PUT("/:id", a.menuController.Update, a.permMiddleware.RequirePerm("sys:menu:edit"))
-		api.DELETE("/:id", a.menuController.Delete, a.permMiddleware.RequirePerm("sys:menu:delete"))
+		api.GET("/export", a.menuController.Export, "sys:menu:export")
+		api.POST("/import", a.menuController.Import, "sys:menu:import")
+
+		api.POST("", a.menuController.Create, "sys:menu:add")
+		api.GET("/:id/form", a.menuController.GetForm, "sys:menu:query")
+		api.PUT("/:id", a.menuController.Update, "sys:menu:edit")
+		api.GET("/:id/delete-preview", a.menuController.DeletePreview, "sys:menu:delete")
+		api.DELETE("/:id", a.menuController.Delete, "sys:menu:delete")
 	}
 }
@@ -0,0 +1,40 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+)
+
+// MetaRoutes struct
+type MetaRoutes struct {
+	logger         lib.Logger
+	handler        lib.HttpHandler
+	metaController controller.MetaController
+	permMiddleware middlewares.PermissionMiddleware
+}
+
+// NewMetaRoutes creates new meta routes
+func NewMetaRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	metaController controller.MetaController,
+	permMiddleware middlewares.PermissionMiddleware,
+) MetaRoutes {
+	return MetaRoutes{
+		logger:         logger,
+		handler:        handler,
+		metaController: metaController,
+		permMiddleware: permMiddleware,
+	}
+}
+
+// Setup meta routes
+func (a MetaRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/meta"))
+	{
+		api.AllowApiKey().GET("/actions", a.metaController.Actions, "")            // 按当前用户权限过滤的操作描述
+		api.AllowApiKey().GET("/schema", a.metaController.Schema, "")              // 全部接口的类型定义，不按权限过滤
+		api.AllowApiKey().GET("/client.ts", a.metaController.TypeScriptClient, "") // TypeScript 客户端
+	}
+}
@@ -0,0 +1,43 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/lib"
+)
+
+type MetricsRoutes struct {
+	logger         lib.Logger
+	handler        lib.HttpHandler
+	metrics        lib.Metrics
+	permMiddleware middlewares.PermissionMiddleware
+}
+
+// NewMetricsRoutes creates new metrics routes
+func NewMetricsRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	metrics lib.Metrics,
+	permMiddleware middlewares.PermissionMiddleware,
+) MetricsRoutes {
+	return MetricsRoutes{
+		logger:         logger,
+		handler:        handler,
+		metrics:        metrics,
+		permMiddleware: permMiddleware,
+	}
+}
+
+// Setup metrics routes
+// 未启用 Metrics 时不注册；需要登录及 sys:maintenance:metrics 权限，
+// Prometheus 等采集端使用 API Key（X-API-Key）或 mTLS 客户端证书认证
+func (a MetricsRoutes) Setup() {
+	if !a.metrics.IsEnabled() {
+		return
+	}
+
+	a.logger.Zap.Info("Setting up metrics routes")
+	r := a.permMiddleware.Group(a.handler.Engine.Group("/metrics"))
+	{
+		r.GET("", handler(a.metrics.Handler().ServeHTTP), "sys:maintenance:metrics")
+	}
+}
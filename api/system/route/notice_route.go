@@ -4,6 +4,7 @@ import (
 	"github.com/top-system/light-admin/api/system/controller"
 	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
 )
 
 type NoticeRoutes struct {
@@ -30,20 +31,33 @@ func NewNoticeRoutes(
 
 // Setup notice routes
 func (a NoticeRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/notices")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/notices"))
 	{
 		// 管理端接口
-		api.GET("", a.noticeController.Query, a.permMiddleware.RequirePerm("sys:notice:query"))
-		api.GET("/:id/form", a.noticeController.GetForm, a.permMiddleware.RequirePerm("sys:notice:query"))
-		api.GET("/:id/detail", a.noticeController.GetDetail, a.permMiddleware.RequirePerm("sys:notice:query"))
-		api.POST("", a.noticeController.Create, a.permMiddleware.RequirePerm("sys:notice:add"))
-		api.PUT("/:id", a.noticeController.Update, a.permMiddleware.RequirePerm("sys:notice:edit"))
-		api.DELETE("/:ids", a.noticeController.Delete, a.permMiddleware.RequirePerm("sys:notice:delete"))
-		api.PUT("/:id/publish", a.noticeController.Publish, a.permMiddleware.RequirePerm("sys:notice:publish"))
-		api.PUT("/:id/revoke", a.noticeController.Revoke, a.permMiddleware.RequirePerm("sys:notice:revoke"))
+		api.Describe("查询通知公告", system.NoticeQueryParam{}).GET("", a.noticeController.Query, "sys:notice:query")
+		api.GET("/:id/form", a.noticeController.GetForm, "sys:notice:query")
+		api.GET("/:id/detail", a.noticeController.GetDetail, "sys:notice:query")
+		api.Describe("导出通知公告", system.NoticeQueryParam{}).POST("/export", a.noticeController.Export, "sys:notice:export")
+		api.Describe("新增通知公告", system.NoticeForm{}).POST("", a.noticeController.Create, "sys:notice:add")
+		api.Describe("修改通知公告", system.NoticeForm{}).PUT("/:id", a.noticeController.Update, "sys:notice:edit")
+		api.DELETE("/:ids", a.noticeController.Delete, "sys:notice:delete")
+		api.PUT("/:id/publish", a.noticeController.Publish, "sys:notice:publish")
+		api.PUT("/:id/revoke", a.noticeController.Revoke, "sys:notice:revoke")
+		api.POST("/attachments", a.noticeController.UploadAttachment, "sys:notice:add")
+		// 签名下载地址，由签名校验访问权限，免登录（见 middlewares.signedPathPrefixes）
+		api.Public().GET("/attachments/download", a.noticeController.DownloadAttachment, "")
+
+		// 草稿自动保存、修订记录与编辑锁
+		api.Describe("保存通知公告草稿", system.NoticeDraftForm{}).PATCH("/:id/draft", a.noticeController.SaveDraft, "sys:notice:edit")
+		api.GET("/:id/revisions", a.noticeController.QueryRevisions, "sys:notice:query")
+		api.GET("/:id/revisions/:revision", a.noticeController.GetRevision, "sys:notice:query")
+		api.PUT("/:id/revisions/:revision/restore", a.noticeController.RestoreRevision, "sys:notice:edit")
+		api.GET("/:id/lock", a.noticeController.GetLock, "sys:notice:edit")
+		api.PUT("/:id/lock", a.noticeController.Lock, "sys:notice:edit")
+		api.DELETE("/:id/lock", a.noticeController.Unlock, "sys:notice:edit")
 
 		// 用户端接口（无需特殊权限，登录即可）
-		api.GET("/my", a.noticeController.GetMyNoticePage)
-		api.PUT("/read-all", a.noticeController.ReadAll)
+		api.GET("/my", a.noticeController.GetMyNoticePage, "")
+		api.PUT("/read-all", a.noticeController.ReadAll, "")
 	}
 }
@@ -0,0 +1,37 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+)
+
+type PermissionRoutes struct {
+	logger               lib.Logger
+	handler              lib.HttpHandler
+	permissionController controller.PermissionController
+	permMiddleware       middlewares.PermissionMiddleware
+}
+
+// NewPermissionRoutes creates new permission routes
+func NewPermissionRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	permissionController controller.PermissionController,
+	permMiddleware middlewares.PermissionMiddleware,
+) PermissionRoutes {
+	return PermissionRoutes{
+		handler:              handler,
+		logger:               logger,
+		permissionController: permissionController,
+		permMiddleware:       permMiddleware,
+	}
+}
+
+// Setup permission routes
+func (a PermissionRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/permissions"))
+	{
+		api.GET("/routes", a.permissionController.RouteMatrix, "sys:menu:audit") // 路由→权限矩阵
+	}
+}
@@ -0,0 +1,47 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// PolicyRoutes struct
+type PolicyRoutes struct {
+	logger           lib.Logger
+	handler          lib.HttpHandler
+	policyController controller.PolicyController
+	permMiddleware   middlewares.PermissionMiddleware
+}
+
+// NewPolicyRoutes creates new policy routes
+func NewPolicyRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	policyController controller.PolicyController,
+	permMiddleware middlewares.PermissionMiddleware,
+) PolicyRoutes {
+	return PolicyRoutes{
+		logger:           logger,
+		handler:          handler,
+		policyController: policyController,
+		permMiddleware:   permMiddleware,
+	}
+}
+
+// Setup policy routes
+func (a PolicyRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/security/policies"))
+	{
+		api.Describe("查询访问策略", system.PolicyQueryParam{}).GET("", a.policyController.Query, "sys:policy:query")
+		api.GET("/export", a.policyController.Export, "sys:policy:query")
+		api.GET("/:id", a.policyController.Get, "sys:policy:query")
+		api.Describe("新增访问策略", system.PolicyForm{}).POST("", a.policyController.Create, "sys:policy:add")
+		api.Describe("测试访问策略", system.PolicyTestForm{}).POST("/test", a.policyController.Test, "sys:policy:query")
+		api.POST("/import", a.policyController.Import, "sys:policy:import")
+		api.POST("/reload", a.policyController.Reload, "sys:policy:edit")
+		api.Describe("修改访问策略", system.PolicyForm{}).PUT("/:id", a.policyController.Update, "sys:policy:edit")
+		api.DELETE("/:ids", a.policyController.Delete, "sys:policy:delete")
+	}
+}
@@ -0,0 +1,44 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// PositionRoutes struct
+type PositionRoutes struct {
+	logger             lib.Logger
+	handler            lib.HttpHandler
+	positionController controller.PositionController
+	permMiddleware     middlewares.PermissionMiddleware
+}
+
+// NewPositionRoutes creates new position routes
+func NewPositionRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	positionController controller.PositionController,
+	permMiddleware middlewares.PermissionMiddleware,
+) PositionRoutes {
+	return PositionRoutes{
+		logger:             logger,
+		handler:            handler,
+		positionController: positionController,
+		permMiddleware:     permMiddleware,
+	}
+}
+
+// Setup position routes
+func (a PositionRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/positions"))
+	{
+		api.Describe("查询岗位", system.PositionQueryParam{}).GET("", a.positionController.Query, "sys:position:query")
+		api.AllowApiKey().GET("/options", a.positionController.GetOptions, "") // 下拉选项，无需权限
+		api.GET("/:id", a.positionController.Get, "sys:position:query")
+		api.Describe("新增岗位", system.PositionForm{}).POST("", a.positionController.Create, "sys:position:add")
+		api.Describe("修改岗位", system.PositionForm{}).PUT("/:id", a.positionController.Update, "sys:position:edit")
+		api.DELETE("/:id", a.positionController.Delete, "sys:position:delete")
+	}
+}
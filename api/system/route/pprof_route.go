@@ -6,41 +6,47 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/lib"
 )
 
 type PprofRoutes struct {
-	logger  lib.Logger
-	handler lib.HttpHandler
+	logger         lib.Logger
+	handler        lib.HttpHandler
+	permMiddleware middlewares.PermissionMiddleware
 }
 
-// NewUserRoutes creates new pprof routes
+// NewPprofRoutes creates new pprof routes
 func NewPprofRoutes(
 	logger lib.Logger,
 	handler lib.HttpHandler,
+	permMiddleware middlewares.PermissionMiddleware,
 ) PprofRoutes {
 	return PprofRoutes{
-		handler: handler,
-		logger:  logger,
+		handler:        handler,
+		logger:         logger,
+		permMiddleware: permMiddleware,
 	}
 }
 
 // Setup pprof routes
+// 需要登录及 sys:maintenance:pprof 权限，不向 API Key 开放；/profile、/trace 的 seconds 受 HTTP 写超时限制，
+// 长时间采集使用 POST /api/v1/maintenance/profiles
 func (a PprofRoutes) Setup() {
-	r := a.handler.Engine.Group("/pprof")
+	r := a.permMiddleware.Group(a.handler.RouterV1.Group("/debug/pprof", a.permMiddleware.DenyApiKey()))
 	{
-		r.GET("/", handler(pprof.Index))
-		r.GET("/allocs", handler(pprof.Handler("allocs").ServeHTTP))
-		r.GET("/block", handler(pprof.Handler("block").ServeHTTP))
-		r.GET("/cmdline", handler(pprof.Cmdline))
-		r.GET("/goroutine", handler(pprof.Handler("goroutine").ServeHTTP))
-		r.GET("/heap", handler(pprof.Handler("heap").ServeHTTP))
-		r.GET("/mutex", handler(pprof.Handler("mutex").ServeHTTP))
-		r.GET("/profile", handler(pprof.Profile))
-		r.POST("/symbol", handler(pprof.Symbol))
-		r.GET("/symbol", handler(pprof.Symbol))
-		r.GET("/threadcreate", handler(pprof.Handler("threadcreate").ServeHTTP))
-		r.GET("/trace", handler(pprof.Trace))
+		r.GET("/", handler(pprof.Index), "sys:maintenance:pprof")
+		r.GET("/allocs", handler(pprof.Handler("allocs").ServeHTTP), "sys:maintenance:pprof")
+		r.GET("/block", handler(pprof.Handler("block").ServeHTTP), "sys:maintenance:pprof")
+		r.GET("/cmdline", handler(pprof.Cmdline), "sys:maintenance:pprof")
+		r.GET("/goroutine", handler(pprof.Handler("goroutine").ServeHTTP), "sys:maintenance:pprof")
+		r.GET("/heap", handler(pprof.Handler("heap").ServeHTTP), "sys:maintenance:pprof")
+		r.GET("/mutex", handler(pprof.Handler("mutex").ServeHTTP), "sys:maintenance:pprof")
+		r.GET("/profile", handler(pprof.Profile), "sys:maintenance:pprof")
+		r.POST("/symbol", handler(pprof.Symbol), "sys:maintenance:pprof")
+		r.GET("/symbol", handler(pprof.Symbol), "sys:maintenance:pprof")
+		r.GET("/threadcreate", handler(pprof.Handler("threadcreate").ServeHTTP), "sys:maintenance:pprof")
+		r.GET("/trace", handler(pprof.Trace), "sys:maintenance:pprof")
 	}
 }
 
@@ -0,0 +1,38 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+)
+
+// ProvisionRoutes struct
+type ProvisionRoutes struct {
+	logger              lib.Logger
+	handler             lib.HttpHandler
+	provisionController controller.ProvisionController
+	permMiddleware      middlewares.PermissionMiddleware
+}
+
+// NewProvisionRoutes creates new provision routes
+func NewProvisionRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	provisionController controller.ProvisionController,
+	permMiddleware middlewares.PermissionMiddleware,
+) ProvisionRoutes {
+	return ProvisionRoutes{
+		logger:              logger,
+		handler:             handler,
+		provisionController: provisionController,
+		permMiddleware:      permMiddleware,
+	}
+}
+
+// Setup provision routes
+func (a ProvisionRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/users/provision"))
+	{
+		api.Describe("批量开通部门和用户", nil).POST("", a.provisionController.Provision, "sys:user:import")
+	}
+}
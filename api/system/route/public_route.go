@@ -1,6 +1,7 @@
 package route
 
 import (
+	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/api/system/controller"
 	"github.com/top-system/light-admin/lib"
 )
@@ -10,6 +11,7 @@ type PublicRoutes struct {
 	handler           lib.HttpHandler
 	publicController  controller.PublicController
 	captchaController controller.CaptchaController
+	permMiddleware    middlewares.PermissionMiddleware
 }
 
 // NewUserRoutes creates new public routes
@@ -18,23 +20,33 @@ func NewPublicRoutes(
 	handler lib.HttpHandler,
 	publicController controller.PublicController,
 	captchaController controller.CaptchaController,
+	permMiddleware middlewares.PermissionMiddleware,
 ) PublicRoutes {
 	return PublicRoutes{
 		handler:           handler,
 		logger:            logger,
 		publicController:  publicController,
 		captchaController: captchaController,
+		permMiddleware:    permMiddleware,
 	}
 }
 
 // Setup public routes
 func (a PublicRoutes) Setup() {
-	// /api/v1/auth 路由组
-	auth := a.handler.RouterV1.Group("/auth")
+	// /api/v1/auth 路由组，登录、刷新令牌、验证码与公钥免登录访问，登录设备与登出接口不向 API Key 开放
+	auth := a.permMiddleware.Group(a.handler.RouterV1.Group("/auth"))
 	{
-		auth.POST("/login", a.publicController.UserLogin)
-		auth.DELETE("/logout", a.publicController.UserLogout)
-		auth.GET("/captcha", a.captchaController.GetCaptcha)
-		auth.POST("/captcha/verify", a.captchaController.VerifyCaptcha)
+		auth.Public().POST("/login", a.publicController.UserLogin, "")
+		auth.Public().POST("/refresh", a.publicController.RefreshToken, "")
+		auth.DELETE("/logout", a.publicController.UserLogout, "")
+		auth.GET("/devices", a.publicController.GetDevices, "")
+		auth.DELETE("/devices/:id", a.publicController.RevokeDevice, "")
+		auth.Public().GET("/captcha", a.captchaController.GetCaptcha, "")
+		auth.Public().POST("/captcha/verify", a.captchaController.VerifyCaptcha, "")
+		auth.Public().GET("/jwks", a.publicController.JWKS, "")
 	}
+
+	// 标准 JWKS 发现地址
+	wellKnown := a.permMiddleware.Group(a.handler.Engine.Group("/.well-known"))
+	wellKnown.Public().GET("/jwks.json", a.publicController.JWKS, "")
 }
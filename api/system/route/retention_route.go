@@ -0,0 +1,42 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// RetentionRoutes struct
+type RetentionRoutes struct {
+	logger              lib.Logger
+	handler             lib.HttpHandler
+	retentionController controller.RetentionController
+	permMiddleware      middlewares.PermissionMiddleware
+}
+
+// NewRetentionRoutes creates new retention routes
+func NewRetentionRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	retentionController controller.RetentionController,
+	permMiddleware middlewares.PermissionMiddleware,
+) RetentionRoutes {
+	return RetentionRoutes{
+		logger:              logger,
+		handler:             handler,
+		retentionController: retentionController,
+		permMiddleware:      permMiddleware,
+	}
+}
+
+// Setup retention routes
+func (a RetentionRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/retention-policies"))
+	{
+		api.GET("", a.retentionController.List, "sys:retention:query")
+		api.GET("/preview", a.retentionController.Preview, "sys:retention:query")
+		api.Describe("执行数据保留策略", system.RetentionRunForm{}).POST("/run", a.retentionController.Run, "sys:retention:run")
+		api.Describe("修改数据保留策略", system.RetentionPolicyForm{}).PUT("/:dataType", a.retentionController.Update, "sys:retention:edit")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/top-system/light-admin/api/system/controller"
 	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
 )
 
 type RoleRoutes struct {
@@ -30,16 +31,16 @@ func NewRoleRoutes(
 
 // Setup role routes
 func (a RoleRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/roles")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/roles"))
 	{
-		api.GET("", a.roleController.Query, a.permMiddleware.RequirePerm("sys:role:query"))
-		api.GET("/options", a.roleController.GetOptions) // 下拉选项，无需权限
+		api.Describe("查询角色", system.RoleQueryParam{}).GET("", a.roleController.Query, "sys:role:query")
+		api.AllowApiKey().GET("/options", a.roleController.GetOptions, "") // 下拉选项，无需权限
 
-		api.POST("", a.roleController.Create, a.permMiddleware.RequirePerm("sys:role:add"))
-		api.GET("/:id/form", a.roleController.GetForm, a.permMiddleware.RequirePerm("sys:role:query"))
-		api.PUT("/:id", a.roleController.Update, a.permMiddleware.RequirePerm("sys:role:edit"))
-		api.DELETE("/:id", a.roleController.Delete, a.permMiddleware.RequirePerm("sys:role:delete"))
-		api.GET("/:id/menuIds", a.roleController.GetMenuIds, a.permMiddleware.RequirePerm("sys:role:query"))
-		api.PUT("/:id/menus", a.roleController.AssignMenus, a.permMiddleware.RequirePerm("sys:role:edit"))
+		api.Describe("新增角色", system.Role{}).POST("", a.roleController.Create, "sys:role:add")
+		api.GET("/:id/form", a.roleController.GetForm, "sys:role:query")
+		api.Describe("修改角色", system.Role{}).PUT("/:id", a.roleController.Update, "sys:role:edit")
+		api.DELETE("/:id", a.roleController.Delete, "sys:role:delete")
+		api.GET("/:id/menuIds", a.roleController.GetMenuIds, "sys:role:query")
+		api.PUT("/:id/menus", a.roleController.AssignMenus, "sys:role:edit")
 	}
 }
@@ -5,6 +5,7 @@ import "go.uber.org/fx"
 // Module exports dependency to container
 var Module = fx.Options(
 	fx.Provide(NewPprofRoutes),
+	fx.Provide(NewMetricsRoutes),
 	fx.Provide(NewSwaggerRoutes),
 	fx.Provide(NewPublicRoutes),
 	fx.Provide(NewUserRoutes),
@@ -17,6 +18,28 @@ var Module = fx.Options(
 	fx.Provide(NewLogRoute),
 	fx.Provide(NewTaskRoutes),
 	fx.Provide(NewDownloadRoutes),
+	fx.Provide(NewPermissionRoutes),
+	fx.Provide(NewMaintenanceRoutes),
+	fx.Provide(NewAnalyticsRoutes),
+	fx.Provide(NewSecurityRoutes),
+	fx.Provide(NewComplianceRoutes),
+	fx.Provide(NewTagRoutes),
+	fx.Provide(NewPositionRoutes),
+	fx.Provide(NewApiKeyRoutes),
+	fx.Provide(NewUserJobRoutes),
+	fx.Provide(NewProvisionRoutes),
+	fx.Provide(NewConfigBackupRoutes),
+	fx.Provide(NewPolicyRoutes),
+	fx.Provide(NewDeptRoleRoutes),
+	fx.Provide(NewMetaRoutes),
+	fx.Provide(NewSavedQueryRoutes),
+	fx.Provide(NewWsEventRoutes),
+	fx.Provide(NewDirectoryRoutes),
+	fx.Provide(NewLockRoutes),
+	fx.Provide(NewRetentionRoutes),
+	fx.Provide(NewCrontabRoutes),
+	fx.Provide(NewMailTemplateRoutes),
+	fx.Provide(NewWebhookRoutes),
 	fx.Provide(NewRoutes),
 )
 
@@ -31,6 +54,7 @@ type Route interface {
 // NewRoutes sets up routes
 func NewRoutes(
 	pprofRoutes PprofRoutes,
+	metricsRoutes MetricsRoutes,
 	swaggerRoutes SwaggerRoutes,
 	publicRoutes PublicRoutes,
 	userRoutes UserRoutes,
@@ -43,9 +67,32 @@ func NewRoutes(
 	logRoutes LogRoute,
 	taskRoutes TaskRoutes,
 	downloadRoutes DownloadRoutes,
+	permissionRoutes PermissionRoutes,
+	maintenanceRoutes MaintenanceRoutes,
+	analyticsRoutes AnalyticsRoutes,
+	securityRoutes SecurityRoutes,
+	complianceRoutes ComplianceRoutes,
+	tagRoutes TagRoutes,
+	positionRoutes PositionRoutes,
+	apiKeyRoutes ApiKeyRoutes,
+	userJobRoutes UserJobRoutes,
+	provisionRoutes ProvisionRoutes,
+	configBackupRoutes ConfigBackupRoutes,
+	policyRoutes PolicyRoutes,
+	deptRoleRoutes DeptRoleRoutes,
+	metaRoutes MetaRoutes,
+	savedQueryRoutes SavedQueryRoutes,
+	wsEventRoutes WsEventRoutes,
+	directoryRoutes DirectoryRoutes,
+	lockRoutes LockRoutes,
+	retentionRoutes RetentionRoutes,
+	crontabRoutes CrontabRoutes,
+	mailTemplateRoutes MailTemplateRoutes,
+	webhookRoutes WebhookRoutes,
 ) Routes {
 	return Routes{
 		pprofRoutes,
+		metricsRoutes,
 		swaggerRoutes,
 		publicRoutes,
 		userRoutes,
@@ -58,6 +105,28 @@ func NewRoutes(
 		logRoutes,
 		taskRoutes,
 		downloadRoutes,
+		permissionRoutes,
+		maintenanceRoutes,
+		analyticsRoutes,
+		securityRoutes,
+		complianceRoutes,
+		tagRoutes,
+		positionRoutes,
+		apiKeyRoutes,
+		userJobRoutes,
+		provisionRoutes,
+		configBackupRoutes,
+		policyRoutes,
+		deptRoleRoutes,
+		metaRoutes,
+		savedQueryRoutes,
+		wsEventRoutes,
+		directoryRoutes,
+		lockRoutes,
+		retentionRoutes,
+		crontabRoutes,
+		mailTemplateRoutes,
+		webhookRoutes,
 	}
 }
 
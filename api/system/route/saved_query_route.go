@@ -0,0 +1,44 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// SavedQueryRoutes struct
+type SavedQueryRoutes struct {
+	logger               lib.Logger
+	handler              lib.HttpHandler
+	savedQueryController controller.SavedQueryController
+	permMiddleware       middlewares.PermissionMiddleware
+}
+
+// NewSavedQueryRoutes creates new saved query routes
+func NewSavedQueryRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	savedQueryController controller.SavedQueryController,
+	permMiddleware middlewares.PermissionMiddleware,
+) SavedQueryRoutes {
+	return SavedQueryRoutes{
+		logger:               logger,
+		handler:              handler,
+		savedQueryController: savedQueryController,
+		permMiddleware:       permMiddleware,
+	}
+}
+
+// Setup saved query routes
+// 自助接口只需登录，服务层按当前用户隔离保存查询；使用时仍受列表接口本身的权限控制
+func (a SavedQueryRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/my/saved-queries"))
+	{
+		api.Describe("我的保存查询", system.SavedQueryQueryParam{}).GET("", a.savedQueryController.List, "")
+		api.GET("/:id", a.savedQueryController.Get, "")
+		api.Describe("新建保存查询", system.SavedQueryForm{}).POST("", a.savedQueryController.Create, "")
+		api.Describe("修改保存查询", system.SavedQueryForm{}).PUT("/:id", a.savedQueryController.Update, "")
+		api.DELETE("/:id", a.savedQueryController.Delete, "")
+	}
+}
@@ -0,0 +1,40 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+)
+
+// SecurityRoutes struct
+type SecurityRoutes struct {
+	logger             lib.Logger
+	handler            lib.HttpHandler
+	securityController controller.SecurityController
+	permMiddleware     middlewares.PermissionMiddleware
+}
+
+// NewSecurityRoutes creates new security routes
+func NewSecurityRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	securityController controller.SecurityController,
+	permMiddleware middlewares.PermissionMiddleware,
+) SecurityRoutes {
+	return SecurityRoutes{
+		logger:             logger,
+		handler:            handler,
+		securityController: securityController,
+		permMiddleware:     permMiddleware,
+	}
+}
+
+// Setup security routes
+func (a SecurityRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/security"))
+	{
+		api.GET("/dashboard", a.securityController.Dashboard, "sys:security:query")
+		api.GET("/alerts", a.securityController.QueryAlerts, "sys:security:query")
+		api.PUT("/alerts/:id/ack", a.securityController.AckAlert, "sys:security:ack")
+	}
+}
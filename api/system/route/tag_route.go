@@ -0,0 +1,47 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TagRoutes struct
+type TagRoutes struct {
+	logger         lib.Logger
+	handler        lib.HttpHandler
+	tagController  controller.TagController
+	permMiddleware middlewares.PermissionMiddleware
+}
+
+// NewTagRoutes creates new tag routes
+func NewTagRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	tagController controller.TagController,
+	permMiddleware middlewares.PermissionMiddleware,
+) TagRoutes {
+	return TagRoutes{
+		logger:         logger,
+		handler:        handler,
+		tagController:  tagController,
+		permMiddleware: permMiddleware,
+	}
+}
+
+// Setup tag routes
+func (a TagRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/tags"))
+	{
+		api.Describe("查询标签", system.TagQueryParam{}).GET("", a.tagController.Query, "sys:tag:query")
+		api.GET("/:id", a.tagController.Get, "sys:tag:query")
+		api.Describe("新增标签", system.TagForm{}).POST("", a.tagController.Create, "sys:tag:add")
+		api.Describe("修改标签", system.TagForm{}).PUT("/:id", a.tagController.Update, "sys:tag:edit")
+		api.DELETE("/:id", a.tagController.Delete, "sys:tag:delete")
+
+		// 资源打标签
+		api.GET("/resources/:type/:id", a.tagController.ResourceTags, "sys:tag:query")
+		api.Describe("设置资源标签", system.ResourceTagsForm{}).PUT("/resources/:type/:id", a.tagController.SetResourceTags, "sys:tag:assign")
+	}
+}
@@ -31,12 +31,15 @@ func NewTaskRoutes(
 // Setup task routes
 func (a TaskRoutes) Setup() {
 	a.logger.Zap.Info("Setting up task routes")
-	api := a.handler.RouterV1.Group("/tasks")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/tasks"))
 	{
-		api.GET("/stats", a.taskController.GetStats)   // 获取队列统计信息，无需特定权限
-		api.GET("/types", a.taskController.GetTypes)   // 获取任务类型列表，无需特定权限
-		api.GET("", a.taskController.Query, a.permMiddleware.RequirePerm("sys:task:query"))
-		api.GET("/:id", a.taskController.Get, a.permMiddleware.RequirePerm("sys:task:query"))
-		api.DELETE("/:id", a.taskController.Delete, a.permMiddleware.RequirePerm("sys:task:delete"))
+		api.AllowApiKey().GET("/stats", a.taskController.GetStats, "") // 获取队列统计信息，无需特定权限
+		api.AllowApiKey().GET("/types", a.taskController.GetTypes, "") // 获取任务类型列表，无需特定权限
+		api.GET("", a.taskController.Query, "sys:task:query")
+		api.GET("/:id", a.taskController.Get, "sys:task:query")
+		api.GET("/:id/progress", a.taskController.Progress, "sys:task:query")
+		api.POST("/:id/cancel", a.taskController.Cancel, "sys:task:cancel")
+		api.POST("/:id/retry", a.taskController.Retry, "sys:task:retry")
+		api.DELETE("/:id", a.taskController.Delete, "sys:task:delete")
 	}
 }
@@ -0,0 +1,45 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// UserJobRoutes struct
+type UserJobRoutes struct {
+	logger            lib.Logger
+	handler           lib.HttpHandler
+	userJobController controller.UserJobController
+	permMiddleware    middlewares.PermissionMiddleware
+}
+
+// NewUserJobRoutes creates new user job routes
+func NewUserJobRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	userJobController controller.UserJobController,
+	permMiddleware middlewares.PermissionMiddleware,
+) UserJobRoutes {
+	return UserJobRoutes{
+		logger:            logger,
+		handler:           handler,
+		userJobController: userJobController,
+		permMiddleware:    permMiddleware,
+	}
+}
+
+// Setup user job routes
+// 自助接口只需登录，服务层按当前用户隔离任务
+func (a UserJobRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/my/jobs"))
+	{
+		api.Describe("我的定时任务", nil).GET("", a.userJobController.List, "")
+		api.GET("/types", a.userJobController.Catalog, "")
+		api.Describe("新建定时任务", system.UserJobForm{}).POST("", a.userJobController.Create, "")
+		api.Describe("修改定时任务", system.UserJobForm{}).PUT("/:id", a.userJobController.Update, "")
+		api.DELETE("/:id", a.userJobController.Delete, "")
+		api.Describe("立即执行定时任务", nil).POST("/:id/run", a.userJobController.Run, "")
+	}
+}
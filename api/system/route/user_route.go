@@ -4,13 +4,15 @@ import (
 	"github.com/top-system/light-admin/api/system/controller"
 	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
 )
 
 type UserRoutes struct {
-	logger         lib.Logger
-	handler        lib.HttpHandler
-	userController controller.UserController
-	permMiddleware middlewares.PermissionMiddleware
+	logger          lib.Logger
+	handler         lib.HttpHandler
+	userController  controller.UserController
+	permMiddleware  middlewares.PermissionMiddleware
+	cacheMiddleware middlewares.ResponseCacheMiddleware
 }
 
 // NewUserRoutes creates new user routes
@@ -19,28 +21,40 @@ func NewUserRoutes(
 	handler lib.HttpHandler,
 	userController controller.UserController,
 	permMiddleware middlewares.PermissionMiddleware,
+	cacheMiddleware middlewares.ResponseCacheMiddleware,
 ) UserRoutes {
 	return UserRoutes{
-		handler:        handler,
-		logger:         logger,
-		userController: userController,
-		permMiddleware: permMiddleware,
+		handler:         handler,
+		logger:          logger,
+		userController:  userController,
+		permMiddleware:  permMiddleware,
+		cacheMiddleware: cacheMiddleware,
 	}
 }
 
 // Setup user routes
 func (a UserRoutes) Setup() {
-	api := a.handler.RouterV1.Group("/users")
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/users"))
 	{
-		api.GET("/me", a.userController.Me)                  // 获取当前用户信息，无需权限
-		api.GET("/profile", a.userController.Me)             // 兼容 /profile 路径
-		api.PUT("/profile", a.userController.UpdateProfile)  // 更新当前用户资料，无需权限
-		api.GET("/options", a.userController.GetOptions)     // 用户下拉选项，无需权限
-		api.GET("", a.userController.Query, a.permMiddleware.RequirePerm("sys:user:query"))
-		api.POST("", a.userController.Create, a.permMiddleware.RequirePerm("sys:user:add"))
-		api.GET("/:id/form", a.userController.GetForm, a.permMiddleware.RequirePerm("sys:user:query"))
-		api.PUT("/:id", a.userController.Update, a.permMiddleware.RequirePerm("sys:user:edit"))
-		api.DELETE("/:id", a.userController.Delete, a.permMiddleware.RequirePerm("sys:user:delete"))
-		api.PUT("/:id/password/reset", a.userController.ResetPassword, a.permMiddleware.RequirePerm("sys:user:reset-password"))
+		api.AllowApiKey().GET("/me", a.userController.Me, "")      // 获取当前用户信息，无需权限
+		api.AllowApiKey().GET("/profile", a.userController.Me, "") // 兼容 /profile 路径
+		api.PUT("/profile", a.userController.UpdateProfile, "")    // 更新当前用户资料，无需权限
+		// 当前用户的两步验证：绑定、启用、关闭
+		api.POST("/me/two-factor", a.userController.EnrollTwoFactor, "")
+		api.PUT("/me/two-factor", a.userController.ActivateTwoFactor, "")
+		api.DELETE("/me/two-factor", a.userController.DisableTwoFactor, "")
+		// 用户下拉选项，无需权限
+		api.GET("/options", a.userController.GetOptions, "", a.cacheMiddleware.Cache(lib.ResponseCacheGroupUser, middlewares.CacheScopeGlobal))
+		api.Describe("查询用户", system.UserQueryParam{}).GET("", a.userController.Query, "sys:user:query")
+		api.Describe("导出用户", system.UserQueryParam{}).POST("/export", a.userController.Export, "sys:user:export")
+		api.Describe("新增用户", system.User{}).POST("", a.userController.Create, "sys:user:add")
+		api.Describe("批量操作用户", system.UserBulkForm{}).POST("/bulk", a.userController.Bulk, "sys:user:edit") // 批量启用/禁用、分配角色、调整部门
+		api.Describe("批量启用/禁用用户", system.UserStatusForm{}).PUT("/status", a.userController.UpdateStatus, "sys:user:edit")
+		api.Describe("批量设置用户角色", system.UserRolesForm{}).PUT("/roles", a.userController.UpdateRoles, "sys:user:edit")
+		api.GET("/:id/form", a.userController.GetForm, "sys:user:query")
+		api.Describe("修改用户", system.User{}).PUT("/:id", a.userController.Update, "sys:user:edit")
+		api.DELETE("/:ids", a.userController.Delete, "sys:user:delete") // 多个ID以英文逗号分割
+		api.PUT("/:id/password/reset", a.userController.ResetPassword, "sys:user:reset-password")
+		api.DELETE("/:id/two-factor", a.userController.ResetTwoFactor, "sys:user:reset-2fa")
 	}
 }
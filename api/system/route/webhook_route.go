@@ -0,0 +1,48 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// WebhookRoutes struct
+type WebhookRoutes struct {
+	logger            lib.Logger
+	handler           lib.HttpHandler
+	webhookController controller.WebhookController
+	permMiddleware    middlewares.PermissionMiddleware
+}
+
+// NewWebhookRoutes creates new webhook routes
+func NewWebhookRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	webhookController controller.WebhookController,
+	permMiddleware middlewares.PermissionMiddleware,
+) WebhookRoutes {
+	return WebhookRoutes{
+		logger:            logger,
+		handler:           handler,
+		webhookController: webhookController,
+		permMiddleware:    permMiddleware,
+	}
+}
+
+// Setup webhook routes
+func (a WebhookRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/webhooks"))
+	{
+		api.Describe("查询Webhook", system.WebhookQueryParam{}).GET("", a.webhookController.Query, "sys:webhook:query")
+		api.GET("/events", a.webhookController.GetEvents, "sys:webhook:query")
+		api.Describe("查询Webhook投递记录", system.WebhookDeliveryQueryParam{}).GET("/deliveries", a.webhookController.QueryDeliveries, "sys:webhook:query")
+		api.GET("/deliveries/:id", a.webhookController.GetDelivery, "sys:webhook:query")
+		api.POST("/deliveries/:id/redeliver", a.webhookController.Redeliver, "sys:webhook:deliver")
+		api.GET("/:id", a.webhookController.Get, "sys:webhook:query")
+		api.Describe("新增Webhook", system.WebhookForm{}).POST("", a.webhookController.Create, "sys:webhook:add")
+		api.Describe("修改Webhook", system.WebhookForm{}).PUT("/:id", a.webhookController.Update, "sys:webhook:edit")
+		api.DELETE("/:id", a.webhookController.Delete, "sys:webhook:delete")
+		api.POST("/:id/ping", a.webhookController.Ping, "sys:webhook:deliver")
+	}
+}
@@ -0,0 +1,41 @@
+package route
+
+import (
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// WsEventRoutes struct
+type WsEventRoutes struct {
+	logger            lib.Logger
+	handler           lib.HttpHandler
+	wsEventController controller.WsEventController
+	permMiddleware    middlewares.PermissionMiddleware
+}
+
+// NewWsEventRoutes creates new websocket event routes
+func NewWsEventRoutes(
+	logger lib.Logger,
+	handler lib.HttpHandler,
+	wsEventController controller.WsEventController,
+	permMiddleware middlewares.PermissionMiddleware,
+) WsEventRoutes {
+	return WsEventRoutes{
+		logger:            logger,
+		handler:           handler,
+		wsEventController: wsEventController,
+		permMiddleware:    permMiddleware,
+	}
+}
+
+// Setup websocket event routes
+func (a WsEventRoutes) Setup() {
+	api := a.permMiddleware.Group(a.handler.RouterV1.Group("/ws-events"))
+	{
+		api.Describe("推送送达报告", system.WsEventQueryParam{}).GET("", a.wsEventController.Query, "sys:ws-event:query")
+		api.Describe("推送系统横幅", system.WsBannerForm{}).POST("/banner", a.wsEventController.Banner, "sys:ws-event:banner")
+		api.GET("/:eventId", a.wsEventController.Get, "sys:ws-event:query")
+	}
+}
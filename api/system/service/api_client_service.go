@@ -0,0 +1,77 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/top-system/light-admin/docs"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/tsgen"
+)
+
+// ApiClientService 前端客户端生成服务
+// 根据当前注册的路由、路由声明的参数结构以及 swagger 注解生成类型描述与 TypeScript 客户端，
+// 前端构建时下载使用，保证类型与 Go 结构体一致
+type ApiClientService struct {
+	logger   lib.Logger
+	handler  lib.HttpHandler
+	registry lib.PermRegistry
+}
+
+// NewApiClientService 创建前端客户端生成服务
+func NewApiClientService(logger lib.Logger, handler lib.HttpHandler, registry lib.PermRegistry) ApiClientService {
+	return ApiClientService{
+		logger:   logger,
+		handler:  handler,
+		registry: registry,
+	}
+}
+
+// Document 导出全部类型定义（JSON Schema）与接口描述
+func (a ApiClientService) Document() *tsgen.Document {
+	return a.generator().Document()
+}
+
+// TypeScript 生成 TypeScript 类型定义与客户端
+func (a ApiClientService) TypeScript() []byte {
+	return a.generator().TypeScript()
+}
+
+func (a ApiClientService) generator() *tsgen.Generator {
+	g := tsgen.New()
+	// MenuType 序列化为 M/C/B，日期类型序列化为字符串
+	g.Override(dto.MenuType(0), &tsgen.Schema{Type: "string", Enum: []interface{}{"M", "C", "B", ""}})
+	g.Define("dto.DateTime", &tsgen.Schema{Type: "string", Format: "date-time"})
+	g.Define("dto.NullDateTime", &tsgen.Schema{Type: "string", Format: "date-time"})
+
+	if err := g.LoadSwagger([]byte(docs.SwaggerInfo.ReadDoc())); err != nil {
+		a.logger.Zap.Warnf("Failed to load swagger doc for client generation: %v", err)
+	}
+
+	routes := a.handler.Engine.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	// 只包含通过权限路由组注册的接口，与 /meta/actions 一致
+	for _, route := range routes {
+		rp, declared := a.registry.Get(route.Method, route.Path)
+		if !declared {
+			continue
+		}
+
+		g.AddRoute(tsgen.Route{
+			Method:  route.Method,
+			Path:    route.Path,
+			Handler: route.Name,
+			Title:   rp.Title,
+			Perm:    rp.Perm,
+			Params:  rp.Params,
+		})
+	}
+
+	return g
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+const (
+	// apiKeyPrefix 密钥固定前缀，便于在日志、代码仓库中识别泄露的密钥
+	apiKeyPrefix = "la_"
+	// apiKeyDisplayLen 列表中展示的密钥前缀长度
+	apiKeyDisplayLen = 12
+	// apiKeyTouchInterval 最近使用时间的最小更新间隔，避免每次请求都写库
+	apiKeyTouchInterval = time.Minute
+)
+
+// ApiKeyService 个人访问令牌（API Key）服务
+type ApiKeyService struct {
+	logger            lib.Logger
+	config            lib.Config
+	apiKeyRepository  repository.ApiKeyRepository
+	userRepository    repository.UserRepository
+	permissionService PermissionService
+}
+
+// NewApiKeyService creates a new api key service
+func NewApiKeyService(
+	logger lib.Logger,
+	config lib.Config,
+	apiKeyRepository repository.ApiKeyRepository,
+	userRepository repository.UserRepository,
+	permissionService PermissionService,
+) ApiKeyService {
+	return ApiKeyService{
+		logger:            logger,
+		config:            config,
+		apiKeyRepository:  apiKeyRepository,
+		userRepository:    userRepository,
+		permissionService: permissionService,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a ApiKeyService) WithTrx(trxHandle *gorm.DB) ApiKeyService {
+	a.apiKeyRepository = a.apiKeyRepository.WithTrx(trxHandle)
+	a.userRepository = a.userRepository.WithTrx(trxHandle)
+	return a
+}
+
+// List 当前用户的 API Key
+func (a ApiKeyService) List(claims *dto.JwtClaims) (system.ApiKeys, error) {
+	if claims.ApiKeyID != 0 || claims.ServiceAccount {
+		return nil, errors.ApiKeyNotAllowed
+	}
+
+	list, err := a.apiKeyRepository.ListByUser(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range list {
+		item.PermList = item.SplitPerms()
+	}
+
+	return list, nil
+}
+
+// Create 为当前用户创建 API Key，授权范围不能超出用户当前的权限，返回结果中的明文密钥只出现这一次
+// 配置文件中的超级管理员（ID 为 0）没有用户记录，认证时无法解析，不允许创建
+func (a ApiKeyService) Create(claims *dto.JwtClaims, form *system.ApiKeyForm) (*system.ApiKey, error) {
+	if claims.ApiKeyID != 0 || claims.ServiceAccount {
+		return nil, errors.ApiKeyNotAllowed
+	}
+	if claims.ID == 0 {
+		return nil, errors.ApiKeyNoAccount
+	}
+
+	perms := make([]string, 0, len(form.Perms))
+	seen := make(map[string]bool, len(form.Perms))
+	for _, perm := range form.Perms {
+		if perm = strings.TrimSpace(perm); perm != "" && !strings.Contains(perm, ",") && !seen[perm] {
+			seen[perm] = true
+			perms = append(perms, perm)
+		}
+	}
+	if len(perms) == 0 {
+		return nil, errors.ApiKeyPermsInvalid
+	}
+
+	if claims.Username != a.config.SuperAdmin.Username {
+		userPerms, err := a.permissionService.GetUserPerms(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, perm := range perms {
+			if !MatchPerm(userPerms, perm) {
+				return nil, errors.ApiKeyPermDenied
+			}
+		}
+	}
+
+	key, err := newApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &system.ApiKey{
+		UserID:  claims.ID,
+		Name:    strings.TrimSpace(form.Name),
+		Prefix:  key[:apiKeyDisplayLen],
+		KeyHash: hashApiKey(key),
+		Perms:   strings.Join(perms, ","),
+	}
+	if form.ExpireDays > 0 {
+		apiKey.ExpireTime = dto.NullDateTime{Time: time.Now().AddDate(0, 0, form.ExpireDays), Valid: true}
+	}
+
+	if err := a.apiKeyRepository.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	apiKey.PermList = perms
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+// Revoke 吊销当前用户的 API Key，立即失效
+func (a ApiKeyService) Revoke(claims *dto.JwtClaims, id uint64) error {
+	if claims.ApiKeyID != 0 || claims.ServiceAccount {
+		return errors.ApiKeyNotAllowed
+	}
+
+	return a.apiKeyRepository.DeleteByUser(claims.ID, id)
+}
+
+// Authenticate 校验请求携带的 API Key 并转换为当前身份
+// 可用权限为授权范围与用户当前权限的交集，用户被禁用、删除或权限收回后随之失效
+func (a ApiKeyService) Authenticate(key, ip string) (*dto.JwtClaims, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, errors.ApiKeyInvalid
+	}
+
+	apiKey, err := a.apiKeyRepository.GetByHash(hashApiKey(key))
+	if errors.Is(err, errors.ApiKeyNotFound) {
+		return nil, errors.ApiKeyInvalid
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if apiKey.ExpireTime.Valid && now.After(apiKey.ExpireTime.Time) {
+		return nil, errors.ApiKeyExpired
+	}
+
+	user, err := a.userRepository.Get(apiKey.UserID)
+	if errors.Is(err, errors.DatabaseRecordNotFound) {
+		return nil, errors.ApiKeyInvalid
+	} else if err != nil {
+		return nil, err
+	} else if user.Status == constants.StatusDisable {
+		return nil, errors.UserIsDisable
+	}
+
+	scopes := apiKey.SplitPerms()
+	if user.Username != a.config.SuperAdmin.Username {
+		userPerms, err := a.permissionService.GetUserPerms(user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		granted := make([]string, 0, len(scopes))
+		for _, perm := range scopes {
+			if MatchPerm(userPerms, perm) {
+				granted = append(granted, perm)
+			}
+		}
+		scopes = granted
+	}
+
+	if !apiKey.LastUsedTime.Valid || now.Sub(apiKey.LastUsedTime.Time) >= apiKeyTouchInterval || apiKey.LastUsedIP != ip {
+		if err := a.apiKeyRepository.UpdateLastUsed(apiKey.ID, ip, now); err != nil {
+			a.logger.Zap.Warnf("Failed to update api key last used time: %v", err)
+		}
+	}
+
+	return &dto.JwtClaims{
+		ID:       user.ID,
+		Username: user.Username,
+		ApiKeyID: apiKey.ID,
+		Scopes:   scopes,
+	}, nil
+}
+
+func newApiKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// ApiUsageService 接口调用统计服务
+type ApiUsageService struct {
+	logger             lib.Logger
+	apiUsageRepository repository.ApiUsageRepository
+}
+
+// NewApiUsageService creates a new api usage service
+func NewApiUsageService(
+	logger lib.Logger,
+	apiUsageRepository repository.ApiUsageRepository,
+) ApiUsageService {
+	return ApiUsageService{
+		logger:             logger,
+		apiUsageRepository: apiUsageRepository,
+	}
+}
+
+// Accumulate 批量写入聚合后的统计
+func (a ApiUsageService) Accumulate(list system.ApiUsages) error {
+	return a.apiUsageRepository.Accumulate(list)
+}
+
+// Query 分页查询统计明细
+func (a ApiUsageService) Query(param *system.ApiUsageQueryParam) (*system.ApiUsageQueryResult, error) {
+	return a.apiUsageRepository.Query(param)
+}
+
+// TopUsers 调用量最高的用户（含服务账号）
+func (a ApiUsageService) TopUsers(param *system.ApiUsageTopParam) ([]*system.ApiUsageUserVO, error) {
+	return a.apiUsageRepository.TopUsers(param)
+}
+
+// TopRoutes 调用量或错误率最高的接口
+func (a ApiUsageService) TopRoutes(param *system.ApiUsageTopParam) ([]*system.ApiUsageRouteVO, error) {
+	return a.apiUsageRepository.TopRoutes(param)
+}
@@ -1,63 +1,347 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/constants"
 	apperrors "github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	"github.com/top-system/light-admin/pkg/websocket"
 )
 
 type options struct {
 	issuer        string
 	signingMethod jwt.SigningMethod
 	signingKey    interface{}
+	keyID         string
 	keyfunc       jwt.Keyfunc
+	keySet        *jwtKeySet
 	expired       int
 	tokenType     string
+
+	refreshExpired time.Duration
+
+	// 客户端证书 CN -> 服务账号
+	serviceAccounts map[string]*lib.ServiceAccountConfig
 }
 
+const (
+	// RefreshTokenPath 刷新令牌接口，访问令牌过期后仍需可以访问
+	RefreshTokenPath = "/api/v1/auth/refresh"
+	// refreshTokenDefaultExpired 刷新令牌默认有效期
+	refreshTokenDefaultExpired = 7 * 24 * time.Hour
+)
+
 type AuthService struct {
-	opts  *options
-	cache lib.Cache
+	opts   *options
+	config lib.Config
+	cache  lib.Cache
+	logger lib.Logger
+	ws     *websocket.WebSocket
+
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
 }
 
-func NewAuthService(cache lib.Cache, config lib.Config) AuthService {
+func NewAuthService(
+	cache lib.Cache,
+	config lib.Config,
+	logger lib.Logger,
+	ws *websocket.WebSocket,
+	bus *eventbus.Bus,
+	userRepository repository.UserRepository,
+	refreshTokenRepository repository.RefreshTokenRepository,
+) AuthService {
 	issuer := config.Name
-	signingKey := fmt.Sprintf("Jwt:%s", issuer)
 
 	opts := &options{
-		issuer:        issuer,
-		tokenType:     "Bearer",
-		expired:       config.Auth.TokenExpired,
-		signingMethod: jwt.SigningMethodHS512,
-		signingKey:    []byte(signingKey),
-		keyfunc: func(t *jwt.Token) (interface{}, error) {
+		issuer:         issuer,
+		tokenType:      "Bearer",
+		expired:        config.Auth.TokenExpired,
+		refreshExpired: time.Duration(config.Auth.RefreshTokenExpired) * time.Second,
+	}
+	if opts.refreshExpired <= 0 {
+		opts.refreshExpired = refreshTokenDefaultExpired
+	}
+
+	switch config.Auth.Algorithm {
+	case "RS256", "ES256":
+		method := jwt.GetSigningMethod(config.Auth.Algorithm)
+		keySet, err := loadJwtKeySet(method, config.Auth.Keys)
+		if err != nil {
+			logger.Zap.Fatalf("Error to load jwt keys: %v", err)
+		}
+
+		opts.signingMethod = method
+		opts.signingKey = keySet.signing.privateKey
+		opts.keyID = keySet.signing.kid
+		opts.keyfunc = keySet.keyfunc
+		opts.keySet = keySet
+	default:
+		signingKey := config.Auth.Secret
+		if signingKey == "" {
+			signingKey = fmt.Sprintf("Jwt:%s", issuer)
+		}
+
+		opts.signingMethod = jwt.SigningMethodHS512
+		opts.signingKey = []byte(signingKey)
+		opts.keyfunc = func(t *jwt.Token) (interface{}, error) {
 			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, apperrors.AuthTokenInvalid
 			}
 			return []byte(signingKey), nil
-		},
+		}
+	}
+
+	if mtls := config.Http.MTLS; mtls != nil && mtls.Enable {
+		opts.serviceAccounts = make(map[string]*lib.ServiceAccountConfig, len(mtls.Accounts))
+		for _, account := range mtls.Accounts {
+			if account != nil && account.Subject != "" {
+				opts.serviceAccounts[account.Subject] = account
+			}
+		}
+	}
+
+	a := AuthService{
+		cache:                  cache,
+		config:                 config,
+		opts:                   opts,
+		logger:                 logger,
+		ws:                     ws,
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+	}
+
+	// 用户被禁用后立即失效其令牌并断开 WebSocket 连接
+	system.EventUserDisabled.Subscribe(bus, "auth", func(ctx context.Context, event system.UserDisabledEvent) error {
+		a.ForceLogout(event.Username, forceLogoutDisabled)
+		return nil
+	})
+
+	return a
+}
+
+// ParseClientCert 将已校验的客户端证书映射为服务账号身份
+func (a AuthService) ParseClientCert(cert *x509.Certificate) (*dto.JwtClaims, error) {
+	account, ok := a.opts.serviceAccounts[cert.Subject.CommonName]
+	if !ok {
+		return nil, apperrors.AuthCertNotBound
+	}
+
+	name := account.Name
+	if name == "" {
+		name = account.Subject
+	}
+
+	return &dto.JwtClaims{
+		Username:       "sa:" + name,
+		ServiceAccount: true,
+		Roles:          account.Roles,
+	}, nil
+}
+
+// JWKS 返回令牌验证公钥集合，HMAC 签名时为空
+func (a AuthService) JWKS() *dto.JWKS {
+	if a.opts.keySet == nil {
+		return &dto.JWKS{Keys: []dto.JWK{}}
 	}
 
-	return AuthService{cache: cache, opts: opts}
+	return a.opts.keySet.JWKS()
 }
 
 func wrapperAuthKey(key string) string {
 	return fmt.Sprintf("auth:%s", key)
 }
 
-func (a AuthService) GenerateToken(user *system.User) (*dto.LoginResponse, error) {
+func wrapperRevokedKey(username string) string {
+	return fmt.Sprintf("auth:revoked:%s", username)
+}
+
+func wrapperRevokedSessionKey(sessionID uint64) string {
+	return fmt.Sprintf("auth:revoked-session:%d", sessionID)
+}
+
+// GenerateToken 登录后签发访问令牌与刷新令牌，每次登录记录为一个登录设备
+func (a AuthService) GenerateToken(user *system.User, device, ip string) (*dto.LoginResponse, error) {
+	if err := a.refreshTokenRepository.DeleteExpired(); err != nil {
+		a.logger.Zap.Warnf("Failed to delete expired refresh tokens: %v", err)
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if len(device) > 255 {
+		device = device[:255]
+	}
+
+	now := time.Now()
+	record := &system.RefreshToken{
+		UserID:     user.ID,
+		Username:   user.Username,
+		TokenHash:  hash,
+		Device:     device,
+		IP:         ip,
+		LoginTime:  dto.DateTime(now),
+		ExpireTime: dto.DateTime(now.Add(a.opts.refreshExpired)),
+	}
+	if err := a.refreshTokenRepository.Create(record); err != nil {
+		return nil, err
+	}
+
+	return a.signToken(user, record.ID, refreshToken)
+}
+
+// RefreshToken 使用刷新令牌换取新的令牌对，旧的刷新令牌随即失效
+// 用户已被禁用、删除或刷新令牌已过期时删除该登录设备
+func (a AuthService) RefreshToken(refreshToken, ip string) (*dto.LoginResponse, error) {
+	hash := hashRefreshToken(refreshToken)
+	record, err := a.refreshTokenRepository.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	} else if record == nil {
+		return nil, apperrors.AuthRefreshInvalid
+	}
+
+	if !time.Now().Before(time.Time(record.ExpireTime)) {
+		a.refreshTokenRepository.Delete(record.UserID, record.ID)
+		return nil, apperrors.AuthRefreshExpired
+	}
+
+	user, err := a.refreshUser(record)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		a.refreshTokenRepository.Delete(record.UserID, record.ID)
+		return nil, apperrors.AuthRefreshInvalid
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := a.refreshTokenRepository.Rotate(record.ID, hash, &system.RefreshToken{
+		TokenHash:  newHash,
+		IP:         ip,
+		ExpireTime: dto.DateTime(time.Now().Add(a.opts.refreshExpired)),
+	})
+	if err != nil {
+		return nil, err
+	} else if !rotated {
+		return nil, apperrors.AuthRefreshInvalid
+	}
+
+	return a.signToken(user, record.ID, newToken)
+}
+
+// refreshUser 刷新令牌所属的用户，用户已被禁用、删除时返回 nil
+// 配置文件中的超级管理员 ID 为 0，不在用户表中，按用户名确认仍是当前配置的超级管理员
+func (a AuthService) refreshUser(record *system.RefreshToken) (*system.User, error) {
+	if record.UserID == 0 {
+		if a.config.SuperAdmin == nil {
+			return nil, nil
+		}
+		if admin := superAdminUser(a.config); admin.Username != "" && admin.Username == record.Username {
+			return admin, nil
+		}
+		return nil, nil
+	}
+
+	user, err := a.userRepository.Get(record.UserID)
+	if err != nil && !errors.Is(err, apperrors.DatabaseRecordNotFound) {
+		return nil, err
+	}
+	if user == nil || user.Status != constants.StatusEnable {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// ListDevices 用户的登录设备，标记当前令牌所属的设备
+func (a AuthService) ListDevices(claims *dto.JwtClaims) (system.RefreshTokens, error) {
+	list, err := a.refreshTokenRepository.ListByUserID(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range list {
+		item.Current = item.ID == claims.SessionID
+	}
+	return list, nil
+}
+
+// RevokeDevice 移除用户的一个登录设备，刷新令牌与该设备上的访问令牌立即失效
+func (a AuthService) RevokeDevice(userID, id uint64) error {
+	ok, err := a.refreshTokenRepository.Delete(userID, id)
+	if err != nil {
+		return err
+	} else if !ok {
+		return apperrors.AuthDeviceNotFound
+	}
+
+	return a.revokeSession(id)
+}
+
+// Logout 登出当前设备
+func (a AuthService) Logout(claims *dto.JwtClaims) error {
+	if err := a.DestroyToken(claims.Username); err != nil {
+		return err
+	}
+	if claims.SessionID == 0 {
+		return nil
+	}
+
+	if _, err := a.refreshTokenRepository.Delete(claims.ID, claims.SessionID); err != nil {
+		return err
+	}
+	return a.revokeSession(claims.SessionID)
+}
+
+// revokeSession 使登录设备上的访问令牌失效，记录保留到这些令牌过期为止
+func (a AuthService) revokeSession(sessionID uint64) error {
+	return a.cache.Set(wrapperRevokedSessionKey(sessionID), time.Now().Unix(), time.Duration(a.opts.expired)*time.Second)
+}
+
+// newRefreshToken 生成随机的刷新令牌，返回令牌及其摘要
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signToken 签发访问令牌，sessionID 为登录设备 ID
+func (a AuthService) signToken(user *system.User, sessionID uint64, refreshToken string) (*dto.LoginResponse, error) {
 	now := time.Now()
 	expiresAt := now.Add(time.Duration(a.opts.expired) * time.Second)
 	claims := &dto.JwtClaims{
-		ID:       user.ID,
-		Username: user.Username,
+		ID:            user.ID,
+		Username:      user.Username,
+		SessionID:     sessionID,
+		RevokeVersion: a.revokeVersion(user.Username),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -66,6 +350,9 @@ func (a AuthService) GenerateToken(user *system.User) (*dto.LoginResponse, error
 	}
 
 	token := jwt.NewWithClaims(a.opts.signingMethod, claims)
+	if a.opts.keyID != "" {
+		token.Header["kid"] = a.opts.keyID
+	}
 	expired := expiresAt.Sub(time.Now())
 
 	err := a.cache.Set(wrapperAuthKey(claims.Username), 1, expired)
@@ -80,7 +367,7 @@ func (a AuthService) GenerateToken(user *system.User) (*dto.LoginResponse, error
 
 	return &dto.LoginResponse{
 		AccessToken:  accessToken,
-		RefreshToken: "", // 暂未实现刷新令牌
+		RefreshToken: refreshToken,
 		TokenType:    a.opts.tokenType,
 		ExpiresIn:    a.opts.expired,
 	}, nil
@@ -102,6 +389,9 @@ func (a AuthService) ParseToken(tokenString string) (*dto.JwtClaims, error) {
 
 	if token != nil {
 		if claims, ok := token.Claims.(*dto.JwtClaims); ok && token.Valid {
+			if a.isRevoked(claims) {
+				return nil, apperrors.AuthTokenRevoked
+			}
 			return claims, nil
 		}
 	}
@@ -109,6 +399,43 @@ func (a AuthService) ParseToken(tokenString string) (*dto.JwtClaims, error) {
 	return nil, apperrors.AuthTokenInvalid
 }
 
+// RevokeUserTokens 使用户此前签发的令牌全部失效：更新用户的吊销版本，记录保留到这些令牌过期为止
+// 按版本而不是签发时间判断，吊销后同一秒内重新签发的令牌仍然有效
+func (a AuthService) RevokeUserTokens(username string) error {
+	return a.cache.Set(wrapperRevokedKey(username), time.Now().UnixNano(), time.Duration(a.opts.expired)*time.Second)
+}
+
+// revokeVersion 用户当前的令牌吊销版本，从未吊销或记录已过期时为 0
+func (a AuthService) revokeVersion(username string) int64 {
+	var version int64
+	if err := a.cache.Get(wrapperRevokedKey(username), &version); err != nil {
+		return 0
+	}
+	return version
+}
+
+// isRevoked 令牌是否在用户被禁用、删除之前签发，或所属的登录设备已登出、移除
+func (a AuthService) isRevoked(claims *dto.JwtClaims) bool {
+	var revokedAt int64
+	if claims.SessionID != 0 && a.cache.Get(wrapperRevokedSessionKey(claims.SessionID), &revokedAt) == nil {
+		return true
+	}
+	return claims.RevokeVersion != a.revokeVersion(claims.Username)
+}
+
+// ForceLogout 禁用、删除用户后强制下线：访问令牌与刷新令牌失效，并发送 ERROR 帧断开全部 WebSocket 会话
+func (a AuthService) ForceLogout(username, reason string) {
+	if err := a.RevokeUserTokens(username); err != nil {
+		a.logger.Zap.Errorf("Failed to revoke tokens of %s: %v", username, err)
+	}
+	if err := a.refreshTokenRepository.DeleteByUsername(username); err != nil {
+		a.logger.Zap.Errorf("Failed to delete refresh tokens of %s: %v", username, err)
+	}
+	if n := a.ws.Broker.CloseUserSessions(username, reason); n > 0 {
+		a.logger.Zap.Infof("Closed %d websocket sessions of %s: %s", n, username, reason)
+	}
+}
+
 func (a AuthService) DestroyToken(username string) error {
 	_, err := a.cache.Delete(wrapperAuthKey(username))
 	return err
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/hash"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/report"
+)
+
+const (
+	// ComplianceReportTaskType 合规报表队列任务类型
+	ComplianceReportTaskType = "compliance_report"
+
+	// 分批读取用户的每页数量
+	complianceUserPageSize = 1000
+	// 报表中安全告警和敏感操作的最大行数
+	complianceAuditLimit = 200
+	// 超级管理员（配置文件账号）密码最短长度
+	complianceMinPasswordLength = 8
+)
+
+// complianceAuditModules 审计摘要中关注的操作模块（另外包含所有删除操作）
+var complianceAuditModules = []string{"用户密码重置", "角色管理", "角色菜单分配", "菜单管理", "配置管理"}
+
+// ComplianceService 安全合规报表服务
+type ComplianceService struct {
+	logger                     lib.Logger
+	config                     lib.Config
+	taskQueue                  lib.TaskQueue
+	fileService                platformservice.FileService
+	complianceReportRepository repository.ComplianceReportRepository
+	userRepository             repository.UserRepository
+	roleRepository             repository.RoleRepository
+	userRoleRepository         repository.UserRoleRepository
+	menuRepository             repository.MenuRepository
+	logRepository              repository.LogRepository
+	loginEventRepository       repository.LoginEventRepository
+	securityAlertRepository    repository.SecurityAlertRepository
+}
+
+// NewComplianceService creates a new compliance service
+func NewComplianceService(
+	logger lib.Logger,
+	config lib.Config,
+	taskQueue lib.TaskQueue,
+	fileService platformservice.FileService,
+	complianceReportRepository repository.ComplianceReportRepository,
+	userRepository repository.UserRepository,
+	roleRepository repository.RoleRepository,
+	userRoleRepository repository.UserRoleRepository,
+	menuRepository repository.MenuRepository,
+	logRepository repository.LogRepository,
+	loginEventRepository repository.LoginEventRepository,
+	securityAlertRepository repository.SecurityAlertRepository,
+) ComplianceService {
+	return ComplianceService{
+		logger:                     logger,
+		config:                     config,
+		taskQueue:                  taskQueue,
+		fileService:                fileService,
+		complianceReportRepository: complianceReportRepository,
+		userRepository:             userRepository,
+		roleRepository:             roleRepository,
+		userRoleRepository:         userRoleRepository,
+		menuRepository:             menuRepository,
+		logRepository:              logRepository,
+		loginEventRepository:       loginEventRepository,
+		securityAlertRepository:    securityAlertRepository,
+	}
+}
+
+// Generate 创建报表记录并提交生成任务；未启用任务队列时在后台协程中生成
+func (a ComplianceService) Generate(form *system.ComplianceReportForm, createBy uint64) (*system.ComplianceReport, error) {
+	format := strings.ToLower(form.Format)
+	if format == "" {
+		format = report.FormatXLSX
+	}
+	if !report.IsFormatSupported(format) {
+		return nil, errors.ComplianceReportFormatInvalid
+	}
+
+	record := &system.ComplianceReport{
+		Format:   format,
+		Status:   system.ComplianceReportPending,
+		CreateBy: createBy,
+	}
+	if err := a.complianceReportRepository.Create(record); err != nil {
+		return nil, err
+	}
+
+	run := func(ctx context.Context) error {
+		return a.run(ctx, record.ID, format)
+	}
+
+	if a.taskQueue.IsEnabled() {
+		task := queue.NewFuncTask(ComplianceReportTaskType, &queue.TaskOwner{ID: createBy}, run)
+		if err := a.taskQueue.QueueTask(context.Background(), task); err != nil {
+			return nil, errors.Wrap(err, "failed to queue compliance report task")
+		}
+	} else {
+		go func() {
+			_ = run(context.Background())
+		}()
+	}
+
+	return record, nil
+}
+
+// run 生成报表并上传，失败时记录错误信息
+func (a ComplianceService) run(ctx context.Context, id uint64, format string) error {
+	if err := a.complianceReportRepository.Update(id, map[string]interface{}{
+		"status": system.ComplianceReportRunning,
+	}); err != nil {
+		return err
+	}
+
+	info, size, err := a.generate(ctx, id, format)
+	if err != nil {
+		a.logger.Zap.Errorf("Failed to generate compliance report %d: %v", id, err)
+
+		message := err.Error()
+		if len(message) > 500 {
+			message = message[:500]
+		}
+		_ = a.complianceReportRepository.Update(id, map[string]interface{}{
+			"status":      system.ComplianceReportFailed,
+			"error":       message,
+			"finish_time": time.Now(),
+		})
+		return err
+	}
+
+	return a.complianceReportRepository.Update(id, map[string]interface{}{
+		"status":      system.ComplianceReportCompleted,
+		"file_name":   info.Name,
+		"file_url":    info.URL,
+		"file_size":   size,
+		"error":       "",
+		"finish_time": time.Now(),
+	})
+}
+
+func (a ComplianceService) generate(ctx context.Context, id uint64, format string) (*platform.FileInfo, int64, error) {
+	now := time.Now()
+
+	r, err := a.Build(ctx, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, format); err != nil {
+		return nil, 0, err
+	}
+
+	filename := fmt.Sprintf("compliance-report-%s-%d.%s", now.Format("20060102150405"), id, format)
+	size := int64(buf.Len())
+	info, err := a.fileService.UploadFile(filename, &buf, size, report.ContentType(format))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return info, size, nil
+}
+
+// Query 分页查询报表记录
+func (a ComplianceService) Query(param *system.ComplianceReportQueryParam) (*system.ComplianceReportQueryResult, error) {
+	return a.complianceReportRepository.Query(param)
+}
+
+// Get 获取报表记录
+func (a ComplianceService) Get(id uint64) (*system.ComplianceReport, error) {
+	return a.complianceReportRepository.Get(id)
+}
+
+// Open 打开已生成的报表文件，使用后需关闭
+func (a ComplianceService) Open(id uint64) (*system.ComplianceReport, *platformservice.StoredObject, error) {
+	record, err := a.complianceReportRepository.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record.Status != system.ComplianceReportCompleted || record.FileURL == "" {
+		return nil, nil, errors.ComplianceReportNotReady
+	}
+
+	object, err := a.fileService.OpenFile(record.FileURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, object, nil
+}
+
+func (a ComplianceService) adminRoles() []string {
+	if a.config.Compliance != nil && len(a.config.Compliance.AdminRoles) > 0 {
+		return a.config.Compliance.AdminRoles
+	}
+	return []string{"ROOT"}
+}
+
+func (a ComplianceService) staleDays() int {
+	if a.config.Compliance != nil && a.config.Compliance.StaleDays > 0 {
+		return a.config.Compliance.StaleDays
+	}
+	return 90
+}
+
+func (a ComplianceService) auditDays() int {
+	if a.config.Compliance != nil && a.config.Compliance.AuditDays > 0 {
+		return a.config.Compliance.AuditDays
+	}
+	return 30
+}
+
+// allUsers 分批读取全部用户（含密码哈希）
+func (a ComplianceService) allUsers() (system.Users, error) {
+	var users system.Users
+	for page := 1; ; page++ {
+		qr, err := a.userRepository.Query(&system.UserQueryParam{
+			PaginationParam: dto.PaginationParam{PageNum: page, PageSize: complianceUserPageSize},
+			QueryPassword:   true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, qr.List...)
+		if len(qr.List) < complianceUserPageSize {
+			return users, nil
+		}
+	}
+}
+
+// Build 汇总角色权限、管理员账号、闲置账号、密码合规情况和近期审计摘要
+func (a ComplianceService) Build(ctx context.Context, now time.Time) (*report.Report, error) {
+	users, err := a.allUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	roleQR, err := a.roleRepository.Query(&system.RoleQueryParam{
+		PaginationParam: dto.PaginationParam{PageNum: 1, PageSize: 1000},
+	})
+	if err != nil {
+		return nil, err
+	}
+	roles := roleQR.List
+
+	userRoleQR, err := a.userRoleRepository.Query(&system.UserRoleQueryParam{
+		PaginationParam: dto.PaginationParam{PageNum: 1, PageSize: 1000000},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := a.loginEventRepository.ListLatest()
+	if err != nil {
+		return nil, err
+	}
+	lastLogin := make(map[uint64]time.Time, len(latest))
+	for _, event := range latest {
+		lastLogin[event.UserID] = event.CreateTime.Time()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	roleByID := make(map[uint64]*system.Role, len(roles))
+	for _, role := range roles {
+		roleByID[role.ID] = role
+	}
+	userRoles := make(map[uint64][]*system.Role)
+	roleUsers := make(map[uint64]int)
+	for _, ur := range userRoleQR.List {
+		if role, ok := roleByID[ur.RoleID]; ok {
+			userRoles[ur.UserID] = append(userRoles[ur.UserID], role)
+			roleUsers[ur.RoleID]++
+		}
+	}
+
+	formatLogin := func(userID uint64) string {
+		if t, ok := lastLogin[userID]; ok {
+			return t.Format(dto.DateTimeFormat)
+		}
+		return "从未登录"
+	}
+
+	r := &report.Report{
+		Title:    "安全合规报告",
+		Subtitle: "生成时间：" + now.Format(dto.DateTimeFormat),
+	}
+	overview := r.AddSection("概览", "项目", "值")
+
+	// 角色与权限
+	roleSection := r.AddSection("角色与权限", "角色编码", "角色名称", "状态", "用户数", "权限数", "权限标识")
+	for _, role := range roles {
+		perms, err := a.menuRepository.GetButtonPermsByRoleIDs([]uint64{role.ID})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(perms)
+
+		roleSection.AddRow(role.Code, role.Name, complianceStatus(role.Status),
+			strconv.Itoa(roleUsers[role.ID]), strconv.Itoa(len(perms)), strings.Join(perms, ", "))
+	}
+
+	// 管理员账号
+	adminCodes := make(map[string]bool)
+	for _, code := range a.adminRoles() {
+		adminCodes[code] = true
+	}
+	adminSection := r.AddSection("管理员账号", "用户名", "昵称", "管理员角色", "状态", "最近登录")
+	if admin := a.config.SuperAdmin; admin != nil && admin.Username != "" {
+		adminSection.AddRow(admin.Username, admin.Realname, "超级管理员（配置文件）", "正常", "-")
+	}
+	for _, user := range users {
+		var codes []string
+		for _, role := range userRoles[user.ID] {
+			if adminCodes[role.Code] {
+				codes = append(codes, role.Code)
+			}
+		}
+		if len(codes) > 0 {
+			adminSection.AddRow(user.Username, user.Nickname, strings.Join(codes, ", "),
+				complianceStatus(user.Status), formatLogin(user.ID))
+		}
+	}
+
+	// 闲置账号：启用且超过 StaleDays 未登录（新建账号在 StaleDays 内不计入）
+	staleDays := a.staleDays()
+	staleBefore := now.AddDate(0, 0, -staleDays)
+	staleSection := r.AddSection("闲置账号", "用户名", "昵称", "创建时间", "最近登录", "闲置天数")
+	for _, user := range users {
+		if user.Status != 1 || user.CreateTime.Time().After(staleBefore) {
+			continue
+		}
+
+		since := user.CreateTime.Time()
+		if t, ok := lastLogin[user.ID]; ok {
+			if t.After(staleBefore) {
+				continue
+			}
+			since = t
+		}
+
+		staleSection.AddRow(user.Username, user.Nickname, user.CreateTime.Time().Format(dto.DateTimeFormat),
+			formatLogin(user.ID), strconv.Itoa(int(now.Sub(since).Hours()/24)))
+	}
+
+	// 密码策略合规
+	passwordSection := r.AddSection("密码合规", "用户名", "昵称", "状态", "问题")
+	if admin := a.config.SuperAdmin; admin != nil && admin.Username != "" &&
+		len([]rune(admin.Password)) < complianceMinPasswordLength {
+		passwordSection.AddRow(admin.Username, admin.Realname, "正常",
+			fmt.Sprintf("配置文件中的超级管理员密码少于 %d 位", complianceMinPasswordLength))
+	}
+	for _, user := range users {
+		if issue := compliancePasswordIssue(user.Password); issue != "" {
+			passwordSection.AddRow(user.Username, user.Nickname, complianceStatus(user.Status), issue)
+		}
+	}
+
+	// 近期审计摘要
+	auditDays := a.auditDays()
+	auditSince := now.AddDate(0, 0, -auditDays)
+
+	alertQR, err := a.securityAlertRepository.Query(&system.SecurityAlertQueryParam{
+		PaginationParam: dto.PaginationParam{PageNum: 1, PageSize: complianceAuditLimit},
+		From:            auditSince.Format(dto.DateTimeFormat),
+	})
+	if err != nil {
+		return nil, err
+	}
+	alertSection := r.AddSection("安全告警", "时间", "规则", "等级", "用户", "IP", "状态", "详情")
+	for _, alert := range alertQR.List {
+		status := "待处理"
+		if alert.Status == system.SecurityAlertAcknowledged {
+			status = "已确认"
+		}
+		alertSection.AddRow(alert.CreateTime.Time().Format(dto.DateTimeFormat), alert.Rule, alert.Level,
+			alert.Username, alert.IP, status, alert.Detail)
+	}
+
+	logs, err := a.logRepository.ListHighlights(auditSince, complianceAuditModules, complianceAuditLimit)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[uint64]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+	logSection := r.AddSection("敏感操作", "时间", "操作人", "模块", "内容", "IP", "请求路径")
+	for _, log := range logs {
+		operator := usernames[log.CreateBy]
+		if operator == "" {
+			operator = strconv.FormatUint(log.CreateBy, 10)
+		}
+		logSection.AddRow(log.CreateTime.Time().Format(dto.DateTimeFormat), operator, log.Module,
+			log.Content, log.IP, log.RequestURI)
+	}
+
+	enabled := 0
+	for _, user := range users {
+		if user.Status == 1 {
+			enabled++
+		}
+	}
+	overview.AddRow("用户总数", strconv.Itoa(len(users)))
+	overview.AddRow("启用用户数", strconv.Itoa(enabled))
+	overview.AddRow("角色数", strconv.Itoa(len(roles)))
+	overview.AddRow("管理员账号数", strconv.Itoa(len(adminSection.Rows)))
+	overview.AddRow(fmt.Sprintf("闲置账号数（%d 天未登录）", staleDays), strconv.Itoa(len(staleSection.Rows)))
+	overview.AddRow("密码不合规账号数", strconv.Itoa(len(passwordSection.Rows)))
+	overview.AddRow(fmt.Sprintf("近 %d 天安全告警数", auditDays), strconv.FormatInt(alertQR.Pagination.Total, 10))
+	overview.AddRow(fmt.Sprintf("近 %d 天敏感操作数", auditDays), strconv.Itoa(len(logs)))
+
+	return r, nil
+}
+
+func complianceStatus(status int) string {
+	if status == 1 {
+		return "正常"
+	}
+	return "禁用"
+}
+
+// compliancePasswordIssue 检查密码哈希是否符合当前策略（bcrypt 且 cost 不低于默认值）
+func compliancePasswordIssue(password string) string {
+	switch {
+	case password == "":
+		return "未设置密码"
+	case !hash.IsBcryptHash(password):
+		return "使用旧版 SHA256 哈希，需重置密码"
+	}
+
+	cost, err := bcrypt.Cost([]byte(password))
+	if err != nil {
+		return "密码哈希无法解析"
+	}
+	if cost < bcrypt.DefaultCost {
+		return fmt.Sprintf("bcrypt cost %d 低于 %d", cost, bcrypt.DefaultCost)
+	}
+
+	return ""
+}
@@ -0,0 +1,840 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/eventbus"
+)
+
+const (
+	configBackupTaskName    = "config_backup"
+	configBackupDefaultSpec = "0 0 2 * * *"
+	configBackupDefaultKeep = 30
+	// configBackupPageSize 快照一次读取的最大行数，超出时报错而不是生成不完整的备份
+	configBackupPageSize = 9999
+)
+
+// ConfigBackupService 配置备份服务
+// 将菜单、角色（含角色菜单）、字典、字典项和系统配置导出为 JSON 备份文件，
+// 支持定时备份、按份数保留，以及对比差异后恢复到指定备份
+type ConfigBackupService struct {
+	logger                 lib.Logger
+	config                 *lib.ConfigBackupConfig
+	fileService            platformservice.FileService
+	permissionCache        PermissionCache
+	responseCache          lib.ResponseCache
+	configService          ConfigService
+	bus                    *eventbus.Bus
+	configBackupRepository repository.ConfigBackupRepository
+	menuRepository         repository.MenuRepository
+	roleRepository         repository.RoleRepository
+	roleMenuRepository     repository.RoleMenuRepository
+	dictRepository         repository.DictRepository
+	dictItemRepository     repository.DictItemRepository
+	configRepository       repository.ConfigRepository
+	lockService            LockService
+}
+
+// NewConfigBackupService 创建配置备份服务，启用时注册定时备份任务
+func NewConfigBackupService(
+	logger lib.Logger,
+	config lib.Config,
+	crontab lib.Crontab,
+	fileService platformservice.FileService,
+	permissionCache PermissionCache,
+	responseCache lib.ResponseCache,
+	configService ConfigService,
+	bus *eventbus.Bus,
+	configBackupRepository repository.ConfigBackupRepository,
+	menuRepository repository.MenuRepository,
+	roleRepository repository.RoleRepository,
+	roleMenuRepository repository.RoleMenuRepository,
+	dictRepository repository.DictRepository,
+	dictItemRepository repository.DictItemRepository,
+	configRepository repository.ConfigRepository,
+	lockService LockService,
+) ConfigBackupService {
+	cfg := &lib.ConfigBackupConfig{}
+	if config.ConfigBackup != nil {
+		cfg = config.ConfigBackup
+	}
+
+	svc := ConfigBackupService{
+		logger:                 logger,
+		config:                 cfg,
+		fileService:            fileService,
+		permissionCache:        permissionCache,
+		responseCache:          responseCache,
+		configService:          configService,
+		bus:                    bus,
+		configBackupRepository: configBackupRepository,
+		menuRepository:         menuRepository,
+		roleRepository:         roleRepository,
+		roleMenuRepository:     roleMenuRepository,
+		dictRepository:         dictRepository,
+		dictItemRepository:     dictItemRepository,
+		configRepository:       configRepository,
+		lockService:            lockService,
+	}
+
+	if cfg.Enable && crontab.IsEnabled() {
+		spec := cfg.Spec
+		if spec == "" {
+			spec = configBackupDefaultSpec
+		}
+
+		if err := crontab.AddTask(configBackupTaskName, spec, svc.runScheduled); err != nil {
+			logger.Zap.Errorf("Failed to register config backup task: %v", err)
+		}
+	}
+
+	return svc
+}
+
+// WithTrx delegates transaction to repository database
+func (a ConfigBackupService) WithTrx(trxHandle *gorm.DB) ConfigBackupService {
+	a.configService = a.configService.WithTrx(trxHandle)
+	a.configBackupRepository = a.configBackupRepository.WithTrx(trxHandle)
+	a.menuRepository = a.menuRepository.WithTrx(trxHandle)
+	a.roleRepository = a.roleRepository.WithTrx(trxHandle)
+	a.roleMenuRepository = a.roleMenuRepository.WithTrx(trxHandle)
+	a.dictRepository = a.dictRepository.WithTrx(trxHandle)
+	a.dictItemRepository = a.dictItemRepository.WithTrx(trxHandle)
+	a.configRepository = a.configRepository.WithTrx(trxHandle)
+	return a
+}
+
+// runScheduled 定时任务入口，配置未变化时跳过
+func (a ConfigBackupService) runScheduled(ctx context.Context) {
+	backup, err := a.Backup(system.ConfigBackupScheduled, "", 0)
+	if err != nil {
+		a.logger.Zap.Errorf("Config backup failed: %v", err)
+		crontab.SetError(ctx, err)
+		return
+	}
+	if backup == nil {
+		a.logger.Zap.Info("Config backup skipped: no changes since last backup")
+	} else {
+		a.logger.Zap.Infof("Config backup finished: id=%d, file=%s", backup.ID, backup.FileName)
+	}
+
+	if err := a.Cleanup(); err != nil {
+		a.logger.Zap.Errorf("Config backup cleanup failed: %v", err)
+		crontab.SetError(ctx, err)
+	}
+}
+
+// keep 保留的备份份数
+func (a ConfigBackupService) keep() int {
+	if a.config.Keep > 0 {
+		return a.config.Keep
+	}
+	return configBackupDefaultKeep
+}
+
+// Backup 导出当前配置并上传备份文件
+// 定时备份在内容与最近一次备份相同时跳过，返回 nil；备份期间持有配置备份锁，与恢复互斥
+func (a ConfigBackupService) Backup(trigger, remark string, createBy uint64) (record *system.ConfigBackup, err error) {
+	err = a.lockService.WithLock(system.LockConfigBackup, createBy, trigger+" backup", func() error {
+		record, err = a.backup(trigger, remark, createBy)
+		return err
+	})
+	return record, err
+}
+
+func (a ConfigBackupService) backup(trigger, remark string, createBy uint64) (*system.ConfigBackup, error) {
+	bundle, err := a.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验和不含生成时间，内容不变时相同
+	raw, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	checksum := hex.EncodeToString(sum[:])
+
+	if trigger == system.ConfigBackupScheduled {
+		latest, err := a.configBackupRepository.Latest()
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil && latest.Checksum == checksum {
+			return nil, nil
+		}
+	}
+
+	now := time.Now()
+	bundle.CreatedAt = dto.DateTime(now)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return nil, err
+	}
+
+	filename := fmt.Sprintf("config-backup-%s.json", now.Format("20060102150405"))
+	size := int64(buf.Len())
+	info, err := a.fileService.UploadFile(filename, &buf, size, "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	record := &system.ConfigBackup{
+		Trigger:   trigger,
+		FileName:  info.Name,
+		FileURL:   info.URL,
+		FileSize:  size,
+		Checksum:  checksum,
+		Menus:     len(bundle.Menus),
+		Roles:     len(bundle.Roles),
+		Dicts:     len(bundle.Dicts),
+		DictItems: len(bundle.DictItems),
+		Configs:   len(bundle.Configs),
+		Remark:    remark,
+		CreateBy:  createBy,
+	}
+	if err := a.configBackupRepository.Create(record); err != nil {
+		if derr := a.fileService.DeleteFile(info.URL); derr != nil {
+			a.logger.Zap.Warnf("Failed to delete config backup file %s: %v", info.URL, derr)
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Cleanup 删除保留份数之外的旧备份及其文件
+func (a ConfigBackupService) Cleanup() error {
+	outdated, err := a.configBackupRepository.ListOutdated(a.keep())
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range outdated {
+		if err := a.fileService.DeleteFile(backup.FileURL); err != nil {
+			a.logger.Zap.Warnf("Failed to delete config backup file %s: %v", backup.FileURL, err)
+		}
+		if err := a.configBackupRepository.Delete(backup.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshotPage 快照的分页参数，一次读取全部数据
+func snapshotPage() dto.PaginationParam {
+	return dto.PaginationParam{PageNum: 1, PageSize: configBackupPageSize}
+}
+
+// checkSnapshotPage 数据超过一页时返回错误，避免备份被截断后恢复（prune）删除其余数据
+func checkSnapshotPage(kind string, pagination *dto.Pagination, rows int) error {
+	if pagination != nil && pagination.Total > int64(rows) {
+		return errors.Wrapf(errors.ConfigBackupTooLarge, "%s: %d rows", kind, pagination.Total)
+	}
+	return nil
+}
+
+// Snapshot 读取当前配置，各类数据按恢复时使用的键排序，保证相同内容生成相同的备份
+func (a ConfigBackupService) Snapshot() (*system.ConfigBundle, error) {
+	menuQR, err := a.menuRepository.Query(&system.MenuQueryParam{PaginationParam: snapshotPage()})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSnapshotPage("menu", menuQR.Pagination, len(menuQR.List)); err != nil {
+		return nil, err
+	}
+	menus := menuQR.List
+	sort.Slice(menus, func(i, j int) bool { return menus[i].ID < menus[j].ID })
+
+	roleQR, err := a.roleRepository.Query(&system.RoleQueryParam{PaginationParam: snapshotPage()})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSnapshotPage("role", roleQR.Pagination, len(roleQR.List)); err != nil {
+		return nil, err
+	}
+	roles := roleQR.List
+	for _, role := range roles {
+		menuIDs, err := a.roleMenuRepository.GetMenuIDsByRoleID(role.ID)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(menuIDs, func(i, j int) bool { return menuIDs[i] < menuIDs[j] })
+		role.MenuIds = menuIDs
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Code < roles[j].Code })
+
+	dictQR, err := a.dictRepository.Query(&system.DictQueryParam{PaginationParam: snapshotPage()})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSnapshotPage("dict", dictQR.Pagination, len(dictQR.List)); err != nil {
+		return nil, err
+	}
+	dicts := dictQR.List
+	sort.Slice(dicts, func(i, j int) bool { return dicts[i].DictCode < dicts[j].DictCode })
+
+	itemQR, err := a.dictItemRepository.Query(&system.DictItemQueryParam{PaginationParam: snapshotPage()})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSnapshotPage("dict item", itemQR.Pagination, len(itemQR.List)); err != nil {
+		return nil, err
+	}
+	items := itemQR.List
+	sort.Slice(items, func(i, j int) bool { return dictItemKey(items[i]) < dictItemKey(items[j]) })
+
+	configs, err := a.configRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].ConfigKey < configs[j].ConfigKey })
+
+	return &system.ConfigBundle{
+		Version:   system.ConfigBundleVersion,
+		Menus:     menus,
+		Roles:     roles,
+		Dicts:     dicts,
+		DictItems: items,
+		Configs:   configs,
+	}, nil
+}
+
+// Query 分页查询备份记录
+func (a ConfigBackupService) Query(param *system.ConfigBackupQueryParam) (*system.ConfigBackupQueryResult, error) {
+	return a.configBackupRepository.Query(param)
+}
+
+// Get 获取备份记录
+func (a ConfigBackupService) Get(id uint64) (*system.ConfigBackup, error) {
+	return a.configBackupRepository.Get(id)
+}
+
+// Open 打开备份文件，使用后需关闭
+func (a ConfigBackupService) Open(id uint64) (*system.ConfigBackup, *platformservice.StoredObject, error) {
+	record, err := a.configBackupRepository.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := a.fileService.OpenFile(record.FileURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, object, nil
+}
+
+// Load 读取并解析备份文件
+func (a ConfigBackupService) Load(id uint64) (*system.ConfigBundle, error) {
+	_, object, err := a.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Reader.Close()
+
+	bundle := new(system.ConfigBundle)
+	if err := json.NewDecoder(object.Reader).Decode(bundle); err != nil {
+		return nil, errors.Wrap(errors.ConfigBackupInvalid, err.Error())
+	}
+	if bundle.Version < 1 || bundle.Version > system.ConfigBundleVersion {
+		return nil, errors.Wrapf(errors.ConfigBackupVersionUnsupported, "version %d", bundle.Version)
+	}
+
+	return bundle, nil
+}
+
+// Diff 对比备份与当前配置
+func (a ConfigBackupService) Diff(id uint64, prune bool) (*system.ConfigDiff, error) {
+	bundle, err := a.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := a.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffConfigBundles(current, bundle)
+	diff.BackupID = id
+	diff.Prune = prune
+
+	return diff, nil
+}
+
+// Restore 将配置恢复到指定备份，只写入有差异的数据
+// prune 为 true 时删除备份中不存在的数据，否则保留
+// 需要在事务中调用（WithTrx），任一写入失败时整体回滚；恢复期间持有配置备份锁
+func (a ConfigBackupService) Restore(id uint64, prune bool, operator uint64) (diff *system.ConfigDiff, err error) {
+	err = a.lockService.WithLock(system.LockConfigBackup, operator, fmt.Sprintf("restore backup %d", id), func() error {
+		diff, err = a.restore(id, prune, operator)
+		return err
+	})
+	return diff, err
+}
+
+func (a ConfigBackupService) restore(id uint64, prune bool, operator uint64) (*system.ConfigDiff, error) {
+	bundle, err := a.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := a.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffConfigBundles(current, bundle)
+	diff.BackupID = id
+	diff.Prune = prune
+
+	actions := make(map[string]string, len(diff.Items))
+	for _, item := range diff.Items {
+		actions[item.Kind+":"+item.Key] = item.Action
+	}
+	action := func(kind, key string) string {
+		return actions[kind+":"+key]
+	}
+
+	if err := a.restoreMenus(current, bundle, action, prune); err != nil {
+		return nil, err
+	}
+	dictCodes, err := a.restoreDicts(current, bundle, action, prune, operator)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.restoreConfigs(current, bundle, action, prune, operator); err != nil {
+		return nil, err
+	}
+	roleIDs, err := a.restoreRoles(current, bundle, action, prune, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	// 刷新缓存
+	a.permissionCache.InvalidateRoutesCache()
+	for _, roleID := range roleIDs {
+		a.permissionCache.InvalidateRoleCache(roleID)
+	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDict)
+	if err := a.configService.RefreshCache(); err != nil {
+		a.logger.Zap.Warnf("Failed to refresh config cache after restore: %v", err)
+	}
+	for _, code := range dictCodes {
+		system.EventDictChanged.Publish(context.Background(), a.bus, system.DictChangedEvent{DictCode: code})
+	}
+
+	a.logger.Zap.Infof("Config restored from backup %d by %d: added=%d, updated=%d, removed=%d, prune=%v",
+		id, operator, diff.Added, diff.Updated, diff.Removed, prune)
+
+	return diff, nil
+}
+
+func (a ConfigBackupService) restoreMenus(current, bundle *system.ConfigBundle, action func(kind, key string) string, prune bool) error {
+	for _, menu := range bundle.Menus {
+		switch action(system.ConfigBackupKindMenu, menuKey(menu)) {
+		case system.ConfigDiffAdded:
+			if err := a.menuRepository.Create(menu); err != nil {
+				return err
+			}
+		case system.ConfigDiffUpdated:
+			if err := a.menuRepository.Update(menu.ID, menu); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+
+	var removed []uint64
+	for _, menu := range current.Menus {
+		if action(system.ConfigBackupKindMenu, menuKey(menu)) == system.ConfigDiffRemoved {
+			removed = append(removed, menu.ID)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := a.roleMenuRepository.DeleteByMenuIDs(removed); err != nil {
+		return err
+	}
+	return a.menuRepository.DeleteByIDs(removed)
+}
+
+// restoreDicts 恢复字典和字典项，返回有变化的字典编码
+func (a ConfigBackupService) restoreDicts(current, bundle *system.ConfigBundle, action func(kind, key string) string, prune bool, operator uint64) ([]string, error) {
+	changed := make(map[string]bool)
+
+	for _, dict := range bundle.Dicts {
+		act := action(system.ConfigBackupKindDict, dict.DictCode)
+		if act == "" {
+			continue
+		}
+		changed[dict.DictCode] = true
+
+		existing, err := a.dictRepository.GetByCode(dict.DictCode)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			if existing, err = a.dictRepository.Revive(dict.DictCode); err != nil {
+				return nil, err
+			}
+		}
+
+		target := &system.Dict{
+			DictCode: dict.DictCode,
+			Name:     dict.Name,
+			Status:   dict.Status,
+			Remark:   dict.Remark,
+			CreateBy: operator,
+			UpdateBy: operator,
+		}
+		if existing == nil {
+			err = a.dictRepository.Create(target)
+		} else {
+			err = a.dictRepository.Update(existing.ID, target)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	currentItems := make(map[string]*system.DictItem, len(current.DictItems))
+	for _, item := range current.DictItems {
+		currentItems[dictItemKey(item)] = item
+	}
+	for _, item := range bundle.DictItems {
+		key := dictItemKey(item)
+		act := action(system.ConfigBackupKindDictItem, key)
+		if act == "" {
+			continue
+		}
+		changed[item.DictCode] = true
+
+		target := &system.DictItem{
+			DictCode: item.DictCode,
+			Label:    item.Label,
+			Value:    item.Value,
+			TagType:  item.TagType,
+			Sort:     item.Sort,
+			Status:   item.Status,
+			Remark:   item.Remark,
+			CreateBy: operator,
+			UpdateBy: operator,
+		}
+		var err error
+		if existing, ok := currentItems[key]; ok {
+			err = a.dictItemRepository.Update(existing.ID, target)
+		} else {
+			err = a.dictItemRepository.Create(target)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if prune {
+		var dictIDs, itemIDs []uint64
+		for _, dict := range current.Dicts {
+			if action(system.ConfigBackupKindDict, dict.DictCode) == system.ConfigDiffRemoved {
+				dictIDs = append(dictIDs, dict.ID)
+				changed[dict.DictCode] = true
+			}
+		}
+		for _, item := range current.DictItems {
+			if action(system.ConfigBackupKindDictItem, dictItemKey(item)) == system.ConfigDiffRemoved {
+				itemIDs = append(itemIDs, item.ID)
+				changed[item.DictCode] = true
+			}
+		}
+		if len(dictIDs) > 0 {
+			if err := a.dictRepository.DeleteByIDs(dictIDs, operator); err != nil {
+				return nil, err
+			}
+		}
+		if len(itemIDs) > 0 {
+			if err := a.dictItemRepository.DeleteByIDs(itemIDs, operator); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	codes := make([]string, 0, len(changed))
+	for code := range changed {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes, nil
+}
+
+func (a ConfigBackupService) restoreConfigs(current, bundle *system.ConfigBundle, action func(kind, key string) string, prune bool, operator uint64) error {
+	for _, config := range bundle.Configs {
+		if action(system.ConfigBackupKindConfig, config.ConfigKey) == "" {
+			continue
+		}
+
+		existing, err := a.configRepository.GetByKey(config.ConfigKey)
+		if err != nil && !errors.Is(err, errors.DatabaseRecordNotFound) {
+			return err
+		}
+		if existing == nil {
+			if existing, err = a.configRepository.Revive(config.ConfigKey); err != nil {
+				return err
+			}
+		}
+
+		target := &system.Config{
+			ConfigName:  config.ConfigName,
+			ConfigKey:   config.ConfigKey,
+			ConfigValue: config.ConfigValue,
+			Remark:      config.Remark,
+			CreateBy:    operator,
+			UpdateBy:    operator,
+		}
+		if existing == nil {
+			err = a.configRepository.Create(target)
+		} else {
+			err = a.configRepository.Update(existing.ID, target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+
+	for _, config := range current.Configs {
+		if action(system.ConfigBackupKindConfig, config.ConfigKey) == system.ConfigDiffRemoved {
+			if err := a.configRepository.Delete(config.ID, operator); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreRoles 恢复角色及角色菜单，返回有变化的角色ID
+// 角色菜单只保留恢复后仍存在的菜单
+func (a ConfigBackupService) restoreRoles(current, bundle *system.ConfigBundle, action func(kind, key string) string, prune bool, operator uint64) ([]uint64, error) {
+	menuIDs := make(map[uint64]bool)
+	for _, menu := range bundle.Menus {
+		menuIDs[menu.ID] = true
+	}
+	if !prune {
+		for _, menu := range current.Menus {
+			menuIDs[menu.ID] = true
+		}
+	}
+
+	var changed []uint64
+	for _, role := range bundle.Roles {
+		if action(system.ConfigBackupKindRole, role.Code) == "" {
+			continue
+		}
+
+		existing, err := a.roleRepository.GetByCode(role.Code)
+		if err != nil && !errors.Is(err, errors.DatabaseRecordNotFound) {
+			return nil, err
+		}
+		if existing == nil {
+			if existing, err = a.roleRepository.Revive(role.Code); err != nil {
+				return nil, err
+			}
+		}
+
+		target := &system.Role{
+			Name:      role.Name,
+			Code:      role.Code,
+			Sort:      role.Sort,
+			Status:    role.Status,
+			DataScope: role.DataScope,
+			CreateBy:  operator,
+			UpdateBy:  operator,
+		}
+		if existing == nil {
+			if err := a.roleRepository.Create(target); err != nil {
+				return nil, err
+			}
+		} else {
+			target.ID = existing.ID
+			if err := a.roleRepository.Update(existing.ID, target); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := a.roleMenuRepository.DeleteByRoleID(target.ID); err != nil {
+			return nil, err
+		}
+		roleMenus := make([]*system.RoleMenu, 0, len(role.MenuIds))
+		for _, menuID := range role.MenuIds {
+			if menuIDs[menuID] {
+				roleMenus = append(roleMenus, &system.RoleMenu{RoleID: target.ID, MenuID: menuID})
+			}
+		}
+		if err := a.roleMenuRepository.BatchCreate(roleMenus); err != nil {
+			return nil, err
+		}
+		changed = append(changed, target.ID)
+	}
+
+	if prune {
+		for _, role := range current.Roles {
+			if action(system.ConfigBackupKindRole, role.Code) != system.ConfigDiffRemoved {
+				continue
+			}
+			if err := a.roleRepository.Delete(role.ID); err != nil {
+				return nil, err
+			}
+			if err := a.roleMenuRepository.DeleteByRoleID(role.ID); err != nil {
+				return nil, err
+			}
+			changed = append(changed, role.ID)
+		}
+	}
+
+	return changed, nil
+}
+
+// configEntry 参与对比的一条数据，fingerprint 为影响恢复结果的字段
+type configEntry struct {
+	key         string
+	name        string
+	fingerprint string
+}
+
+// diffConfigBundles 计算把 current 恢复为 target 需要的变更
+func diffConfigBundles(current, target *system.ConfigBundle) *system.ConfigDiff {
+	diff := &system.ConfigDiff{Items: make([]*system.ConfigDiffItem, 0)}
+
+	diffConfigEntries(diff, system.ConfigBackupKindMenu, menuEntries(current.Menus), menuEntries(target.Menus))
+	diffConfigEntries(diff, system.ConfigBackupKindRole, roleEntries(current.Roles), roleEntries(target.Roles))
+	diffConfigEntries(diff, system.ConfigBackupKindDict, dictEntries(current.Dicts), dictEntries(target.Dicts))
+	diffConfigEntries(diff, system.ConfigBackupKindDictItem, dictItemEntries(current.DictItems), dictItemEntries(target.DictItems))
+	diffConfigEntries(diff, system.ConfigBackupKindConfig, configEntries(current.Configs), configEntries(target.Configs))
+
+	return diff
+}
+
+func diffConfigEntries(diff *system.ConfigDiff, kind string, current, target []configEntry) {
+	currentByKey := make(map[string]configEntry, len(current))
+	for _, entry := range current {
+		currentByKey[entry.key] = entry
+	}
+	targetKeys := make(map[string]bool, len(target))
+
+	for _, entry := range target {
+		targetKeys[entry.key] = true
+
+		existing, ok := currentByKey[entry.key]
+		switch {
+		case !ok:
+			diff.Added++
+			diff.Items = append(diff.Items, &system.ConfigDiffItem{Kind: kind, Key: entry.key, Name: entry.name, Action: system.ConfigDiffAdded})
+		case existing.fingerprint != entry.fingerprint:
+			diff.Updated++
+			diff.Items = append(diff.Items, &system.ConfigDiffItem{Kind: kind, Key: entry.key, Name: entry.name, Action: system.ConfigDiffUpdated})
+		}
+	}
+
+	for _, entry := range current {
+		if !targetKeys[entry.key] {
+			diff.Removed++
+			diff.Items = append(diff.Items, &system.ConfigDiffItem{Kind: kind, Key: entry.key, Name: entry.name, Action: system.ConfigDiffRemoved})
+		}
+	}
+}
+
+func configFingerprint(v interface{}) string {
+	raw, _ := json.Marshal(v)
+	return string(raw)
+}
+
+func menuKey(menu *system.Menu) string {
+	return fmt.Sprintf("%d", menu.ID)
+}
+
+func dictItemKey(item *system.DictItem) string {
+	return item.DictCode + "/" + item.Value
+}
+
+func menuEntries(menus system.Menus) []configEntry {
+	entries := make([]configEntry, 0, len(menus))
+	for _, menu := range menus {
+		m := *menu
+		m.CreateTime, m.UpdateTime = dto.DateTime{}, dto.DateTime{}
+		entries = append(entries, configEntry{key: menuKey(menu), name: menu.Name, fingerprint: configFingerprint(m)})
+	}
+	return entries
+}
+
+func roleEntries(roles system.Roles) []configEntry {
+	entries := make([]configEntry, 0, len(roles))
+	for _, role := range roles {
+		menuIDs := append([]uint64(nil), role.MenuIds...)
+		sort.Slice(menuIDs, func(i, j int) bool { return menuIDs[i] < menuIDs[j] })
+		entries = append(entries, configEntry{key: role.Code, name: role.Name, fingerprint: configFingerprint([]interface{}{
+			role.Name, role.Sort, role.Status, role.DataScope, menuIDs,
+		})})
+	}
+	return entries
+}
+
+func dictEntries(dicts system.Dicts) []configEntry {
+	entries := make([]configEntry, 0, len(dicts))
+	for _, dict := range dicts {
+		entries = append(entries, configEntry{key: dict.DictCode, name: dict.Name, fingerprint: configFingerprint([]interface{}{
+			dict.Name, dict.Status, dict.Remark,
+		})})
+	}
+	return entries
+}
+
+func dictItemEntries(items system.DictItems) []configEntry {
+	entries := make([]configEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, configEntry{key: dictItemKey(item), name: item.Label, fingerprint: configFingerprint([]interface{}{
+			item.Label, item.TagType, item.Sort, item.Status, item.Remark,
+		})})
+	}
+	return entries
+}
+
+func configEntries(configs system.Configs) []configEntry {
+	entries := make([]configEntry, 0, len(configs))
+	for _, config := range configs {
+		entries = append(entries, configEntry{key: config.ConfigKey, name: config.ConfigName, fingerprint: configFingerprint([]interface{}{
+			config.ConfigName, config.ConfigValue, config.Remark,
+		})})
+	}
+	return entries
+}
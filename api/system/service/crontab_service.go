@@ -0,0 +1,532 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+// cronTaskHistoryDefaultLimit 执行记录默认返回条数
+const cronTaskHistoryDefaultLimit = 20
+
+// cronTaskNamePattern 运行时创建的任务名称，不允许冒号，避免与 user_job:<id> 等自动生成的名称冲突
+var cronTaskNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// CrontabService 定时任务管理，查看、启停、立即执行定时任务，修改执行时间
+// 运行时创建的任务及对内置任务的修改保存在 t_cron_job，启动后重新应用到调度器，并按 Crontab.ReloadInterval 定时重新加载；
+// 每次执行记录在 t_cron_task_record
+// 用户自助定时任务（user_job:<id>）由 UserJobService 管理，不在此列出
+type CrontabService struct {
+	logger             lib.Logger
+	crontab            lib.Crontab
+	metrics            lib.Metrics
+	cronTaskRepository repository.CronTaskRepository
+	webhookService     WebhookService
+
+	// runtime 本实例已注册到调度器的运行时任务名称，重新加载时移除数据库中已删除的任务
+	runtime *sync.Map
+}
+
+// NewCrontabService 创建定时任务管理服务，启用定时任务时在启动阶段恢复持久化的任务
+// 内置任务在各服务的构造函数中注册，恢复放在 OnStart 中以确保它们都已注册
+func NewCrontabService(
+	lc fx.Lifecycle,
+	config lib.Config,
+	logger lib.Logger,
+	crontab lib.Crontab,
+	metrics lib.Metrics,
+	cronTaskRepository repository.CronTaskRepository,
+	webhookService WebhookService,
+) CrontabService {
+	svc := CrontabService{
+		logger:             logger,
+		crontab:            crontab,
+		metrics:            metrics,
+		cronTaskRepository: cronTaskRepository,
+		webhookService:     webhookService,
+		runtime:            new(sync.Map),
+	}
+
+	if crontab.IsEnabled() {
+		crontab.Cron.SetRecorder(svc.record)
+
+		interval := config.Crontab.GetReloadInterval()
+		done := make(chan struct{})
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := svc.Reload(); err != nil {
+					svc.logger.Zap.Errorf("Failed to load cron tasks: %v", err)
+				}
+				if interval > 0 {
+					go svc.reloadLoop(interval, done)
+				}
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				close(done)
+				return nil
+			},
+		})
+	}
+
+	return svc
+}
+
+// reloadLoop 定时重新加载持久化的任务，直到 done 关闭
+func (a CrontabService) reloadLoop(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := a.Reload(); err != nil {
+				a.logger.Zap.Warnf("Failed to reload cron tasks: %v", err)
+			}
+		}
+	}
+}
+
+// Reload 按 t_cron_job 同步调度器：注册新增的运行时任务，移除已删除的运行时任务，应用执行时间与启用状态的修改，
+// 用于多实例部署时获取其他实例的修改，或直接修改数据库后生效；单个任务失败只记录日志
+// 删除内置任务的记录不会恢复其默认执行时间，需重启后生效
+func (a CrontabService) Reload() error {
+	if !a.crontab.IsEnabled() {
+		return errors.CrontabNotEnabled
+	}
+
+	list, err := a.cronTaskRepository.List()
+	if err != nil {
+		return err
+	}
+
+	saved := make(map[string]struct{}, len(list))
+	for _, task := range list {
+		saved[task.Name] = struct{}{}
+		if err := a.apply(task); err != nil {
+			a.logger.Zap.Errorf("Failed to apply cron task %s: %v", task.Name, err)
+		}
+	}
+
+	a.runtime.Range(func(key, _ any) bool {
+		name := key.(string)
+		if _, ok := saved[name]; !ok {
+			a.runtime.Delete(name)
+			_ = a.crontab.Cron.RemoveTask(name)
+		}
+		return true
+	})
+
+	return nil
+}
+
+// apply 将持久化的任务应用到调度器，已注册的任务只更新执行时间与启用状态
+func (a CrontabService) apply(task *system.CronTask) error {
+	cron := a.crontab.Cron
+
+	info, err := cron.GetTask(task.Name)
+	if err != nil && task.Handler != "" {
+		if _, ok := crontab.GetHandler(task.Handler); !ok {
+			return errors.Wrapf(errors.CronTaskUnknownHandler, "%s", task.Handler)
+		}
+		if err := cron.AddTask(task.Name, task.Spec, a.runner(task.Name)); err != nil {
+			return err
+		}
+		a.runtime.Store(task.Name, struct{}{})
+	} else {
+		if err != nil {
+			// 内置任务未注册，如对应功能已关闭
+			return errors.Wrapf(errors.CronTaskNotFound, "%s", task.Name)
+		}
+		if info.Spec != task.Spec {
+			if err := cron.UpdateTaskSpec(task.Name, task.Spec); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !task.Enable {
+		return cron.DisableTask(task.Name)
+	}
+	return cron.EnableTask(task.Name)
+}
+
+// runner 运行时任务的执行函数，每次执行时重新读取任务，参数修改后无需重新注册
+func (a CrontabService) runner(name string) crontab.CronTaskFunc {
+	return func(ctx context.Context) {
+		task, err := a.cronTaskRepository.GetByName(name)
+		if err != nil {
+			a.logger.Zap.Errorf("Failed to load cron task %s: %v", name, err)
+			return
+		}
+
+		lastError := ""
+		if h, ok := crontab.GetHandler(task.Handler); !ok {
+			lastError = errors.Wrapf(errors.CronTaskUnknownHandler, "%s", task.Handler).Error()
+		} else if err := h.Run(ctx, json.RawMessage(task.Params)); err != nil {
+			lastError = str.S(err.Error()).Truncate(500)
+		}
+		if lastError != "" {
+			a.logger.Zap.Warnf("Cron task %s failed: %s", name, lastError)
+			crontab.SetError(ctx, errors.New(lastError))
+		}
+
+		now := time.Now()
+		if err := a.cronTaskRepository.RecordRun(name, map[string]interface{}{
+			"last_run_time": dto.NewNullDateTime(&now),
+			"last_error":    lastError,
+		}); err != nil {
+			a.logger.Zap.Warnf("Failed to record cron task %s: %v", name, err)
+		}
+	}
+}
+
+// record 写入执行记录并更新执行指标，写入失败只记录日志；失败或 panic 的执行投递 cron.failed 事件
+func (a CrontabService) record(e crontab.Execution) {
+	a.metrics.ObserveCron(e)
+
+	record := &system.CronTaskRecord{
+		TaskName:      e.Name,
+		CorrelationID: e.CorrelationID.String(),
+		Manual:        e.Manual,
+		StartTime:     dto.DateTime(e.Start),
+		Duration:      e.Duration.Milliseconds(),
+		Status:        system.CronRunSuccess,
+		Result:        str.S(e.Result).Truncate(500),
+	}
+	switch {
+	case e.Panic != "":
+		record.Status = system.CronRunPanicked
+		record.Panic = str.S(e.Panic).Truncate(500)
+	case e.Err != nil:
+		record.Status = system.CronRunFailed
+		record.Error = str.S(e.Err.Error()).Truncate(500)
+	}
+
+	if err := a.cronTaskRepository.CreateRecord(record); err != nil {
+		a.logger.Zap.Warnf("Failed to record execution of cron task %s: %v", e.Name, err)
+	}
+
+	if record.Status != system.CronRunSuccess {
+		a.webhookService.Dispatch(system.WebhookEventCronFailed, record)
+	}
+}
+
+// History 任务最近的执行记录，已删除的任务仍可查询
+func (a CrontabService) History(name string, param *system.CronTaskHistoryParam) (system.CronTaskRecords, error) {
+	limit := param.Limit
+	if limit <= 0 {
+		limit = cronTaskHistoryDefaultLimit
+	}
+
+	return a.cronTaskRepository.ListRecords(name, limit)
+}
+
+// check 定时任务已启用且 name 是可管理的任务
+func (a CrontabService) check(name string) (*crontab.TaskInfo, error) {
+	if !a.crontab.IsEnabled() {
+		return nil, errors.CrontabNotEnabled
+	}
+	if strings.HasPrefix(name, userJobCronPrefix) {
+		return nil, errors.Wrapf(errors.CronTaskNotFound, "%s", name)
+	}
+
+	info, err := a.crontab.Cron.GetTask(name)
+	if err != nil {
+		return nil, errors.Wrapf(errors.CronTaskNotFound, "%s", name)
+	}
+	return info, nil
+}
+
+// List 全部定时任务，按名称排序；处理器已不存在而未能注册的运行时任务也会列出，便于删除
+func (a CrontabService) List() ([]*system.CronTaskVO, error) {
+	if !a.crontab.IsEnabled() {
+		return nil, errors.CrontabNotEnabled
+	}
+
+	rows, err := a.cronTaskRepository.List()
+	if err != nil {
+		return nil, err
+	}
+	saved := make(map[string]*system.CronTask, len(rows))
+	for _, row := range rows {
+		saved[row.Name] = row
+	}
+	latest, err := a.cronTaskRepository.LatestRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*system.CronTaskVO, 0)
+	for _, info := range a.crontab.Cron.GetTasks() {
+		if strings.HasPrefix(info.Name, userJobCronPrefix) {
+			continue
+		}
+
+		vo := &system.CronTaskVO{
+			Name:          info.Name,
+			Spec:          info.Spec,
+			Enable:        info.Enable,
+			Builtin:       true,
+			Timeout:       info.Timeout.Milliseconds(),
+			SkipIfRunning: info.SkipIfRunning,
+			Running:       info.Running,
+			LastDuration:  info.LastDuration.Milliseconds(),
+		}
+		if !info.Next.IsZero() {
+			vo.NextRunTime = dto.NewNullDateTime(&info.Next)
+		}
+		if !info.Prev.IsZero() {
+			vo.PrevRunTime = dto.NewNullDateTime(&info.Prev)
+		}
+		if row, ok := saved[info.Name]; ok {
+			delete(saved, info.Name)
+			fillCronTaskVO(vo, row)
+		}
+		vo.LastRun = latest[info.Name]
+		result = append(result, vo)
+	}
+
+	for _, row := range saved {
+		if row.Handler == "" {
+			continue
+		}
+		vo := &system.CronTaskVO{Name: row.Name, Spec: row.Spec, LastRun: latest[row.Name]}
+		fillCronTaskVO(vo, row)
+		result = append(result, vo)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func fillCronTaskVO(vo *system.CronTaskVO, row *system.CronTask) {
+	if row.Handler == "" {
+		return
+	}
+	vo.Builtin = false
+	vo.Handler = row.Handler
+	vo.Params = row.Params
+	vo.Remark = row.Remark
+	vo.LastRunTime = row.LastRunTime
+	vo.LastError = row.LastError
+}
+
+// Handlers 可用于创建定时任务的处理器
+func (a CrontabService) Handlers() []*system.CronHandlerVO {
+	names := crontab.Handlers()
+	result := make([]*system.CronHandlerVO, 0, len(names))
+	for _, name := range names {
+		h, _ := crontab.GetHandler(name)
+		result = append(result, &system.CronHandlerVO{Name: name, Description: h.Description})
+	}
+	return result
+}
+
+// validateCronParams 按处理器校验任务参数
+func validateCronParams(handler string, params json.RawMessage) error {
+	h, ok := crontab.GetHandler(handler)
+	if !ok {
+		return errors.Wrapf(errors.CronTaskUnknownHandler, "%s", handler)
+	}
+	if h.Validate != nil {
+		if err := h.Validate(params); err != nil {
+			return errors.Wrap(errors.CronTaskParamsInvalid, err.Error())
+		}
+	}
+	return nil
+}
+
+// Create 创建运行时任务，保存后注册到调度器
+func (a CrontabService) Create(form *system.CronTaskForm, operatorID uint64) error {
+	if !a.crontab.IsEnabled() {
+		return errors.CrontabNotEnabled
+	}
+	if !cronTaskNamePattern.MatchString(form.Name) {
+		return errors.Wrapf(errors.CronTaskInvalidName, "%s", form.Name)
+	}
+	if _, err := a.crontab.Cron.GetTask(form.Name); err == nil {
+		return errors.Wrapf(errors.CronTaskAlreadyExists, "%s", form.Name)
+	}
+	if _, err := a.cronTaskRepository.GetByName(form.Name); err == nil {
+		return errors.Wrapf(errors.CronTaskAlreadyExists, "%s", form.Name)
+	} else if !errors.Is(err, errors.CronTaskNotFound) {
+		return err
+	}
+	if err := crontab.ValidateSpec(form.Spec); err != nil {
+		return errors.Wrap(errors.CronTaskSpecInvalid, err.Error())
+	}
+	if err := validateCronParams(form.Handler, json.RawMessage(form.Params)); err != nil {
+		return err
+	}
+
+	task := &system.CronTask{
+		Name:     form.Name,
+		Spec:     form.Spec,
+		Handler:  form.Handler,
+		Params:   form.Params,
+		Enable:   form.Enable == nil || *form.Enable,
+		Remark:   form.Remark,
+		CreateBy: operatorID,
+		UpdateBy: operatorID,
+	}
+	if err := a.cronTaskRepository.Create(task); err != nil {
+		return err
+	}
+
+	if err := a.apply(task); err != nil {
+		a.runtime.Delete(task.Name)
+		_ = a.crontab.Cron.RemoveTask(task.Name)
+		if err := a.cronTaskRepository.Delete(task.Name); err != nil {
+			a.logger.Zap.Warnf("Failed to delete cron task %s: %v", task.Name, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Update 修改执行时间；运行时任务还可以修改参数和备注
+func (a CrontabService) Update(name string, form *system.CronTaskUpdateForm, operatorID uint64) error {
+	info, err := a.check(name)
+	if err != nil {
+		return err
+	}
+	if err := crontab.ValidateSpec(form.Spec); err != nil {
+		return errors.Wrap(errors.CronTaskSpecInvalid, err.Error())
+	}
+
+	values := map[string]interface{}{
+		"spec":      form.Spec,
+		"update_by": operatorID,
+	}
+
+	task, err := a.saved(name, info, operatorID)
+	if err != nil {
+		return err
+	}
+	if task.Handler != "" {
+		params := task.Params
+		if len(form.Params) > 0 {
+			params = form.Params
+		}
+		if err := validateCronParams(task.Handler, json.RawMessage(params)); err != nil {
+			return err
+		}
+		values["params"] = params
+		values["remark"] = form.Remark
+	}
+
+	if err := a.cronTaskRepository.Update(name, values); err != nil {
+		return err
+	}
+	return a.crontab.Cron.UpdateTaskSpec(name, form.Spec)
+}
+
+// SetStatus 启用或停用任务
+func (a CrontabService) SetStatus(name string, enable bool, operatorID uint64) error {
+	info, err := a.check(name)
+	if err != nil {
+		return err
+	}
+	if _, err := a.saved(name, info, operatorID); err != nil {
+		return err
+	}
+
+	if err := a.cronTaskRepository.Update(name, map[string]interface{}{
+		"enable":    enable,
+		"update_by": operatorID,
+	}); err != nil {
+		return err
+	}
+
+	if enable {
+		return a.crontab.Cron.EnableTask(name)
+	}
+	return a.crontab.Cron.DisableTask(name)
+}
+
+// saved 任务的持久化记录，内置任务首次修改时按当前状态创建
+func (a CrontabService) saved(name string, info *crontab.TaskInfo, operatorID uint64) (*system.CronTask, error) {
+	task, err := a.cronTaskRepository.GetByName(name)
+	if err == nil {
+		return task, nil
+	}
+	if !errors.Is(err, errors.CronTaskNotFound) {
+		return nil, err
+	}
+
+	task = &system.CronTask{
+		Name:     name,
+		Spec:     info.Spec,
+		Enable:   info.Enable,
+		CreateBy: operatorID,
+		UpdateBy: operatorID,
+	}
+	if err := a.cronTaskRepository.Create(task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Run 立即执行一次任务，不影响定时调度；不允许重叠执行的任务仍在执行时返回 CronTaskRunning
+func (a CrontabService) Run(name string) error {
+	if _, err := a.check(name); err != nil {
+		return err
+	}
+
+	if err := a.crontab.Cron.RunTask(name); err != nil {
+		if errors.Is(err, crontab.ErrTaskRunning) {
+			return errors.Wrapf(errors.CronTaskRunning, "%s", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete 删除运行时任务，内置任务只能停用
+func (a CrontabService) Delete(name string) error {
+	if !a.crontab.IsEnabled() {
+		return errors.CrontabNotEnabled
+	}
+
+	task, err := a.cronTaskRepository.GetByName(name)
+	if err != nil {
+		if errors.Is(err, errors.CronTaskNotFound) {
+			if _, err := a.check(name); err == nil {
+				return errors.Wrapf(errors.CronTaskBuiltin, "%s", name)
+			}
+			return errors.Wrapf(errors.CronTaskNotFound, "%s", name)
+		}
+		return err
+	}
+	if task.Handler == "" {
+		return errors.Wrapf(errors.CronTaskBuiltin, "%s", name)
+	}
+
+	if err := a.cronTaskRepository.Delete(name); err != nil {
+		return err
+	}
+	a.runtime.Delete(name)
+	_ = a.crontab.Cron.RemoveTask(name)
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 增量同步版本号格式：<最后变更时间（Unix 秒）>[.<数据范围指纹>]
+// 变更时间按秒比较且使用 >=，边界上的记录可能被重复返回，客户端按 ID 覆盖即可
+
+// formatDeltaVersion 生成增量同步版本号
+func formatDeltaVersion(latest time.Time, fingerprint string) string {
+	var ts int64
+	if !latest.IsZero() {
+		ts = latest.Unix()
+	}
+
+	version := strconv.FormatInt(ts, 10)
+	if fingerprint != "" {
+		version += "." + fingerprint
+	}
+	return version
+}
+
+// parseDeltaVersion 解析客户端携带的版本号，无效时 since 为 nil（按全量处理）
+func parseDeltaVersion(version string) (*time.Time, string) {
+	ts, fingerprint, _ := strings.Cut(strings.TrimSpace(version), ".")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sec <= 0 {
+		return nil, ""
+	}
+
+	since := time.Unix(sec, 0)
+	return &since, fingerprint
+}
+
+// deltaFingerprint 计算 ID 集合的指纹，ids 需已排序
+func deltaFingerprint(ids []uint64) string {
+	h := fnv.New32a()
+	for _, id := range ids {
+		_, _ = h.Write([]byte(strconv.FormatUint(id, 10) + ","))
+	}
+	return fmt.Sprintf("%08x", h.Sum32())
+}
@@ -0,0 +1,527 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+// deptRoleLogModule 部门默认角色变更写入操作日志时使用的模块名
+const deptRoleLogModule = "部门默认角色"
+
+// DeptRoleService 部门默认角色服务
+// 规则映射的角色视为部门所有：用户创建或调入部门时授予目标部门映射的角色，
+// 调离时撤销原部门映射且目标部门未映射的角色；每个用户的变更都会写入操作日志
+type DeptRoleService struct {
+	logger                 lib.Logger
+	logShipper             lib.LogShipper
+	permissionCache        PermissionCache
+	deptRepository         repository.DeptRepository
+	roleRepository         repository.RoleRepository
+	userRepository         repository.UserRepository
+	userRoleRepository     repository.UserRoleRepository
+	deptRoleRuleRepository repository.DeptRoleRuleRepository
+	logRepository          repository.LogRepository
+	lockService            LockService
+}
+
+// NewDeptRoleService creates a new dept role service
+func NewDeptRoleService(
+	logger lib.Logger,
+	logShipper lib.LogShipper,
+	permissionCache PermissionCache,
+	deptRepository repository.DeptRepository,
+	roleRepository repository.RoleRepository,
+	userRepository repository.UserRepository,
+	userRoleRepository repository.UserRoleRepository,
+	deptRoleRuleRepository repository.DeptRoleRuleRepository,
+	logRepository repository.LogRepository,
+	lockService LockService,
+) DeptRoleService {
+	return DeptRoleService{
+		logger:                 logger,
+		logShipper:             logShipper,
+		permissionCache:        permissionCache,
+		deptRepository:         deptRepository,
+		roleRepository:         roleRepository,
+		userRepository:         userRepository,
+		userRoleRepository:     userRoleRepository,
+		deptRoleRuleRepository: deptRoleRuleRepository,
+		logRepository:          logRepository,
+		lockService:            lockService,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a DeptRoleService) WithTrx(trxHandle *gorm.DB) DeptRoleService {
+	a.deptRepository = a.deptRepository.WithTrx(trxHandle)
+	a.roleRepository = a.roleRepository.WithTrx(trxHandle)
+	a.userRepository = a.userRepository.WithTrx(trxHandle)
+	a.userRoleRepository = a.userRoleRepository.WithTrx(trxHandle)
+	a.deptRoleRuleRepository = a.deptRoleRuleRepository.WithTrx(trxHandle)
+	a.logRepository = a.logRepository.WithTrx(trxHandle)
+	return a
+}
+
+// Query 分页查询规则，附带部门与角色名称
+func (a DeptRoleService) Query(param *system.DeptRoleRuleQueryParam) (*system.DeptRoleRuleQueryResult, error) {
+	qr, err := a.deptRoleRuleRepository.Query(param)
+	if err != nil {
+		return nil, err
+	}
+
+	deptIDs := make([]uint64, 0, len(qr.List))
+	roleIDs := make([]uint64, 0, len(qr.List))
+	for _, rule := range qr.List {
+		deptIDs = append(deptIDs, rule.DeptID)
+		roleIDs = append(roleIDs, rule.RoleID)
+	}
+
+	depts, err := a.deptRepository.GetByIDs(deptIDs)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := a.roleNames(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range qr.List {
+		if dept, ok := depts[rule.DeptID]; ok {
+			rule.DeptName = dept.Name
+		}
+		rule.RoleName = roles[rule.RoleID]
+	}
+
+	return qr, nil
+}
+
+// checkForm 校验部门、角色是否存在以及规则是否重复
+func (a DeptRoleService) checkForm(form *system.DeptRoleRuleForm, excludeID uint64) error {
+	if _, err := a.deptRepository.Get(form.DeptID); err != nil {
+		return err
+	}
+
+	if _, err := a.roleRepository.Get(form.RoleID); err != nil {
+		if errors.Is(err, errors.DatabaseRecordNotFound) {
+			return errors.RoleRecordNotFound
+		}
+		return err
+	}
+
+	if exists, err := a.deptRoleRuleRepository.Exists(form.DeptID, form.RoleID, excludeID); err != nil {
+		return err
+	} else if exists {
+		return errors.DeptRoleRuleAlreadyExists
+	}
+
+	return nil
+}
+
+// Create 创建规则，已在部门中的用户可通过 Apply 同步
+func (a DeptRoleService) Create(form *system.DeptRoleRuleForm, createBy uint64) (uint64, error) {
+	if err := a.checkForm(form, 0); err != nil {
+		return 0, err
+	}
+
+	rule := &system.DeptRoleRule{
+		DeptID:          form.DeptID,
+		RoleID:          form.RoleID,
+		IncludeChildren: form.IncludeChildren,
+		Remark:          form.Remark,
+		CreateBy:        createBy,
+	}
+	if err := a.deptRoleRuleRepository.Create(rule); err != nil {
+		return 0, err
+	}
+
+	return rule.ID, nil
+}
+
+// Update 修改规则
+func (a DeptRoleService) Update(id uint64, form *system.DeptRoleRuleForm) error {
+	if _, err := a.deptRoleRuleRepository.Get(id); err != nil {
+		return err
+	}
+
+	if err := a.checkForm(form, id); err != nil {
+		return err
+	}
+
+	return a.deptRoleRuleRepository.Update(id, &system.DeptRoleRule{
+		DeptID:          form.DeptID,
+		RoleID:          form.RoleID,
+		IncludeChildren: form.IncludeChildren,
+		Remark:          form.Remark,
+	})
+}
+
+// Delete 批量删除规则，ids 以英文逗号分割；已授予用户的角色保留
+func (a DeptRoleService) Delete(ids string) error {
+	idList := make([]uint64, 0)
+	for _, idStr := range strings.Split(ids, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			continue
+		}
+		idList = append(idList, id)
+	}
+
+	if len(idList) == 0 {
+		return errors.New("删除的规则数据为空")
+	}
+
+	return a.deptRoleRuleRepository.BatchDelete(idList)
+}
+
+// Preview 预览将用户调入部门时的角色变更
+func (a DeptRoleService) Preview(form *system.DeptRolePreviewForm) (*system.DeptRolePlan, error) {
+	if len(form.UserIDs) == 0 || form.DeptID == 0 {
+		return nil, errors.New("用户和目标部门不能为空")
+	}
+
+	if _, err := a.deptRepository.Get(form.DeptID); err != nil {
+		return nil, err
+	}
+
+	users, err := a.userRepository.GetByIDs(form.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.plan(users, form.DeptID)
+}
+
+// Apply 按用户当前所在部门补齐默认角色，DryRun 时只返回变更计划
+// 实际执行时持有权限同步锁，与其他权限同步操作互斥
+func (a DeptRoleService) Apply(form *system.DeptRoleApplyForm, operatorID uint64) (plan *system.DeptRolePlan, err error) {
+	if form.DryRun {
+		return a.apply(form, operatorID)
+	}
+
+	err = a.lockService.WithLock(system.LockPermissionSync, operatorID, "apply dept roles", func() error {
+		plan, err = a.apply(form, operatorID)
+		return err
+	})
+	return plan, err
+}
+
+func (a DeptRoleService) apply(form *system.DeptRoleApplyForm, operatorID uint64) (*system.DeptRolePlan, error) {
+	var users system.Users
+	var err error
+
+	if len(form.UserIDs) > 0 {
+		users, err = a.userRepository.GetByIDs(form.UserIDs)
+	} else {
+		resolver, rerr := a.resolver()
+		if rerr != nil {
+			return nil, rerr
+		}
+		users, err = a.userRepository.GetByDeptIDs(resolver.coveredDepts())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := a.plan(users, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.DryRun {
+		return plan, nil
+	}
+
+	return plan, a.execute(plan, operatorID)
+}
+
+// OnTransfer 用户创建或调整部门后调用，users 中的 DeptID 为调整前的部门（新建用户为 0）
+func (a DeptRoleService) OnTransfer(users system.Users, toDeptID uint64, operatorID uint64) error {
+	if len(users) == 0 || toDeptID == 0 {
+		return nil
+	}
+
+	plan, err := a.plan(users, toDeptID)
+	if err != nil {
+		return err
+	}
+
+	return a.execute(plan, operatorID)
+}
+
+// plan 计算用户调入 toDeptID（为 0 时保持当前部门）后的角色变更
+func (a DeptRoleService) plan(users system.Users, toDeptID uint64) (*system.DeptRolePlan, error) {
+	plan := &system.DeptRolePlan{DryRun: true, Changes: make([]*system.DeptRoleChange, 0)}
+	if len(users) == 0 {
+		return plan, nil
+	}
+
+	resolver, err := a.resolver()
+	if err != nil {
+		return nil, err
+	}
+	if len(resolver.rules) == 0 {
+		return plan, nil
+	}
+
+	userIDs := make([]uint64, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+	userRoles, err := a.userRoleRepository.GetByUserIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	current := userRoles.ToUserIDMap()
+
+	// 规则中的角色可能已被删除，只处理仍然存在的角色
+	names, err := a.roleNames(resolver.roleIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		from, to := user.DeptID, toDeptID
+		if to == 0 {
+			to = from
+		}
+
+		has := current[user.ID].ToMap()
+		target := resolver.roles(to)
+
+		change := &system.DeptRoleChange{UserID: user.ID, Username: user.Username, FromDeptID: from, ToDeptID: to}
+		for _, roleID := range target {
+			if _, ok := has[roleID]; !ok && names[roleID] != "" {
+				change.Grant = append(change.Grant, system.RoleOption{Value: roleID, Label: names[roleID]})
+			}
+		}
+
+		if from != to {
+			keep := make(map[uint64]bool, len(target))
+			for _, roleID := range target {
+				keep[roleID] = true
+			}
+			for _, roleID := range resolver.roles(from) {
+				if _, ok := has[roleID]; ok && !keep[roleID] {
+					change.Revoke = append(change.Revoke, system.RoleOption{Value: roleID, Label: names[roleID]})
+				}
+			}
+		}
+
+		if len(change.Grant) == 0 && len(change.Revoke) == 0 {
+			continue
+		}
+
+		plan.Granted += len(change.Grant)
+		plan.Revoked += len(change.Revoke)
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	return plan, nil
+}
+
+// execute 执行变更计划并写入操作日志
+func (a DeptRoleService) execute(plan *system.DeptRolePlan, operatorID uint64) error {
+	plan.DryRun = false
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+
+	deptIDs := make([]uint64, 0, len(plan.Changes)*2)
+	for _, change := range plan.Changes {
+		deptIDs = append(deptIDs, change.FromDeptID, change.ToDeptID)
+	}
+	depts, err := a.deptRepository.GetByIDs(deptIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range plan.Changes {
+		grants := make([]*system.UserRole, 0, len(change.Grant))
+		for _, role := range change.Grant {
+			grants = append(grants, &system.UserRole{UserID: change.UserID, RoleID: role.Value})
+		}
+		if err := a.userRoleRepository.BatchCreate(grants); err != nil {
+			return err
+		}
+
+		revokes := make([]uint64, 0, len(change.Revoke))
+		for _, role := range change.Revoke {
+			revokes = append(revokes, role.Value)
+		}
+		if err := a.userRoleRepository.DeleteByUserIDsAndRoleIDs([]uint64{change.UserID}, revokes); err != nil {
+			return err
+		}
+
+		a.permissionCache.InvalidateUserCache(change.UserID)
+
+		log := &system.Log{
+			Module:        deptRoleLogModule,
+			RequestMethod: "SYSTEM",
+			Content:       str.S(deptRoleChangeContent(change, depts)).Truncate(255),
+			Method:        "DeptRoleService",
+			CreateBy:      operatorID,
+		}
+		if err := a.logRepository.Create(log); err != nil {
+			return err
+		}
+		ShipOperationLog(a.logShipper, log)
+	}
+
+	return nil
+}
+
+func deptRoleChangeContent(change *system.DeptRoleChange, depts map[uint64]*system.Dept) string {
+	deptName := func(id uint64) string {
+		if dept, ok := depts[id]; ok {
+			return dept.Name
+		}
+		return strconv.FormatUint(id, 10)
+	}
+	roleLabels := func(roles []system.RoleOption) string {
+		labels := make([]string, 0, len(roles))
+		for _, role := range roles {
+			labels = append(labels, role.Label)
+		}
+		return strings.Join(labels, "、")
+	}
+
+	var b strings.Builder
+	if change.FromDeptID == change.ToDeptID {
+		fmt.Fprintf(&b, "用户 %s 按部门 %s 补齐默认角色", change.Username, deptName(change.ToDeptID))
+	} else if change.FromDeptID == 0 {
+		fmt.Fprintf(&b, "用户 %s 加入部门 %s", change.Username, deptName(change.ToDeptID))
+	} else {
+		fmt.Fprintf(&b, "用户 %s 由部门 %s 调入 %s", change.Username, deptName(change.FromDeptID), deptName(change.ToDeptID))
+	}
+	if len(change.Grant) > 0 {
+		fmt.Fprintf(&b, "，授予 %s", roleLabels(change.Grant))
+	}
+	if len(change.Revoke) > 0 {
+		fmt.Fprintf(&b, "，撤销 %s", roleLabels(change.Revoke))
+	}
+	return b.String()
+}
+
+// roleNames 角色 ID 到名称的映射，不存在（已删除）的角色不在结果中
+func (a DeptRoleService) roleNames(ids []uint64) (map[uint64]string, error) {
+	names := make(map[uint64]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	roleQR, err := a.roleRepository.Query(&system.RoleQueryParam{
+		PaginationParam: dto.PaginationParam{PageSize: 1000, PageNum: 1},
+		IDs:             ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roleQR.List {
+		names[role.ID] = role.Name
+	}
+	return names, nil
+}
+
+// deptRoleResolver 根据部门树解析部门适用的规则
+type deptRoleResolver struct {
+	rules system.DeptRoleRules
+	depts map[uint64]*system.Dept
+}
+
+func (a DeptRoleService) resolver() (*deptRoleResolver, error) {
+	rules, err := a.deptRoleRuleRepository.List()
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &deptRoleResolver{rules: rules, depts: make(map[uint64]*system.Dept)}
+	if len(rules) == 0 {
+		return resolver, nil
+	}
+
+	depts, err := a.deptRepository.Query(&system.DeptQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+	for _, dept := range depts {
+		resolver.depts[dept.ID] = dept
+	}
+
+	return resolver, nil
+}
+
+// ancestors 部门的上级部门 ID（来自 tree_path，如 0,1,5）
+func (r *deptRoleResolver) ancestors(deptID uint64) map[uint64]bool {
+	result := make(map[uint64]bool)
+	dept, ok := r.depts[deptID]
+	if !ok {
+		return result
+	}
+
+	for _, s := range strings.Split(dept.TreePath, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64); err == nil && id != 0 {
+			result[id] = true
+		}
+	}
+	return result
+}
+
+// roles 部门适用的角色：直接映射到该部门的规则，以及上级部门中 IncludeChildren 的规则
+func (r *deptRoleResolver) roles(deptID uint64) []uint64 {
+	if deptID == 0 {
+		return nil
+	}
+
+	ancestors := r.ancestors(deptID)
+	seen := make(map[uint64]bool)
+	roles := make([]uint64, 0)
+	for _, rule := range r.rules {
+		if rule.DeptID == deptID || (rule.IncludeChildren && ancestors[rule.DeptID]) {
+			if !seen[rule.RoleID] {
+				seen[rule.RoleID] = true
+				roles = append(roles, rule.RoleID)
+			}
+		}
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i] < roles[j] })
+	return roles
+}
+
+// roleIDs 规则中出现的全部角色
+func (r *deptRoleResolver) roleIDs() []uint64 {
+	seen := make(map[uint64]bool)
+	ids := make([]uint64, 0)
+	for _, rule := range r.rules {
+		if !seen[rule.RoleID] {
+			seen[rule.RoleID] = true
+			ids = append(ids, rule.RoleID)
+		}
+	}
+	return ids
+}
+
+// coveredDepts 规则覆盖的全部部门（含 IncludeChildren 规则的子部门）
+func (r *deptRoleResolver) coveredDepts() []uint64 {
+	ids := make([]uint64, 0)
+	for id := range r.depts {
+		if len(r.roles(id)) > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -15,28 +15,39 @@ import (
 
 // DeptService service layer
 type DeptService struct {
-	logger         lib.Logger
-	deptRepository repository.DeptRepository
+	logger             lib.Logger
+	deptRepository     repository.DeptRepository
+	userRepository     repository.UserRepository
+	roleDeptRepository repository.RoleDeptRepository
+	responseCache      lib.ResponseCache
 }
 
 // NewDeptService creates a new dept service
 func NewDeptService(
 	logger lib.Logger,
 	deptRepository repository.DeptRepository,
+	userRepository repository.UserRepository,
+	roleDeptRepository repository.RoleDeptRepository,
+	responseCache lib.ResponseCache,
 ) DeptService {
 	return DeptService{
-		logger:         logger,
-		deptRepository: deptRepository,
+		logger:             logger,
+		deptRepository:     deptRepository,
+		userRepository:     userRepository,
+		roleDeptRepository: roleDeptRepository,
+		responseCache:      responseCache,
 	}
 }
 
 // WithTrx delegates transaction to repository database
 func (a DeptService) WithTrx(trxHandle *gorm.DB) DeptService {
 	a.deptRepository = a.deptRepository.WithTrx(trxHandle)
+	a.userRepository = a.userRepository.WithTrx(trxHandle)
+	a.roleDeptRepository = a.roleDeptRepository.WithTrx(trxHandle)
 	return a
 }
 
-// GetDeptList 获取部门列表（树形）
+// GetDeptList 获取部门列表，默认返回树形结构，tree=false 时返回扁平列表
 func (a DeptService) GetDeptList(param *system.DeptQueryParam) ([]*system.DeptVO, error) {
 	deptList, err := a.deptRepository.Query(param)
 	if err != nil {
@@ -47,6 +58,14 @@ func (a DeptService) GetDeptList(param *system.DeptQueryParam) ([]*system.DeptVO
 		return []*system.DeptVO{}, nil
 	}
 
+	if param.Tree != nil && !*param.Tree {
+		result := make([]*system.DeptVO, 0, len(deptList))
+		for _, dept := range deptList {
+			result = append(result, newDeptVO(dept))
+		}
+		return result, nil
+	}
+
 	// 获取所有部门ID
 	deptIds := make(map[uint64]bool)
 	for _, dept := range deptList {
@@ -88,16 +107,7 @@ func buildDeptTree(parentId uint64, childMap map[uint64][]*system.Dept) []*syste
 
 	result := make([]*system.DeptVO, 0, len(children))
 	for _, dept := range children {
-		deptVO := &system.DeptVO{
-			ID:         dept.ID,
-			Name:       dept.Name,
-			Code:       dept.Code,
-			ParentID:   dept.ParentID,
-			Sort:       dept.Sort,
-			Status:     dept.Status,
-			CreateTime: dept.CreateTime,
-			UpdateTime: dept.UpdateTime,
-		}
+		deptVO := newDeptVO(dept)
 		subChildren := buildDeptTree(dept.ID, childMap)
 		if len(subChildren) > 0 {
 			deptVO.Children = subChildren
@@ -108,6 +118,20 @@ func buildDeptTree(parentId uint64, childMap map[uint64][]*system.Dept) []*syste
 	return result
 }
 
+// newDeptVO 转换为部门视图对象（不含子部门）
+func newDeptVO(dept *system.Dept) *system.DeptVO {
+	return &system.DeptVO{
+		ID:         dept.ID,
+		Name:       dept.Name,
+		Code:       dept.Code,
+		ParentID:   dept.ParentID,
+		Sort:       dept.Sort,
+		Status:     dept.Status,
+		CreateTime: dept.CreateTime,
+		UpdateTime: dept.UpdateTime,
+	}
+}
+
 // buildChildMap 预构建 parentID -> children 映射
 func buildDeptChildMap(deptList system.Depts) map[uint64][]*system.Dept {
 	childMap := make(map[uint64][]*system.Dept, len(deptList))
@@ -214,6 +238,7 @@ func (a DeptService) SaveDept(form *system.DeptForm, createdBy uint64) (uint64,
 	if err := a.deptRepository.Create(dept); err != nil {
 		return 0, err
 	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDirectory)
 
 	return dept.ID, nil
 }
@@ -238,7 +263,7 @@ func (a DeptService) GetDeptForm(id uint64) (*system.DeptForm, error) {
 // UpdateDept 更新部门
 func (a DeptService) UpdateDept(id uint64, form *system.DeptForm, updatedBy uint64) (uint64, error) {
 	// 检查部门是否存在
-	_, err := a.deptRepository.Get(id)
+	oDept, err := a.deptRepository.Get(id)
 	if err != nil {
 		return 0, err
 	}
@@ -252,12 +277,21 @@ func (a DeptService) UpdateDept(id uint64, form *system.DeptForm, updatedBy uint
 		return 0, errors.New("部门编号已存在")
 	}
 
-	// 生成部门路径
+	// 生成部门路径，上级不能是自身或子孙部门
 	parentID := form.ParentID.Value()
 	treePath, err := a.generateDeptTreePath(parentID)
 	if err != nil {
 		return 0, err
 	}
+	if parentID == id || strings.Contains(","+treePath+",", ","+strconv.FormatUint(id, 10)+",") {
+		return 0, errors.DeptInvalidParent
+	}
+
+	if treePath != oDept.TreePath {
+		if err := a.updateChildTreePath(id, oDept.TreePath, treePath); err != nil {
+			return 0, err
+		}
+	}
 
 	dept := &system.Dept{
 		ID:       id,
@@ -273,6 +307,7 @@ func (a DeptService) UpdateDept(id uint64, form *system.DeptForm, updatedBy uint
 	if err := a.deptRepository.Update(id, dept); err != nil {
 		return 0, err
 	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDirectory)
 
 	return id, nil
 }
@@ -290,10 +325,64 @@ func (a DeptService) DeleteByIds(ids string, deletedBy uint64) error {
 			continue
 		}
 
-		// 删除部门及子部门
+		// 部门及子部门下仍有用户时不允许删除
+		deptIDs, err := a.checkDeptUsers(id)
+		if err != nil {
+			return err
+		}
+
+		// 删除部门及子部门，并移除角色自定义数据权限中对这些部门的引用
 		if err := a.deptRepository.DeleteByTreePath(id, deletedBy); err != nil {
 			return err
 		}
+		if err := a.roleDeptRepository.DeleteByDeptIDs(deptIDs); err != nil {
+			return err
+		}
+	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDirectory)
+
+	return nil
+}
+
+// checkDeptUsers 检查部门及其子孙部门下是否还有用户，返回部门及其子孙部门ID
+func (a DeptService) checkDeptUsers(id uint64) ([]uint64, error) {
+	subtree, err := a.deptRepository.ListSubtree(id)
+	if err != nil {
+		return nil, err
+	}
+
+	deptIDs := []uint64{id}
+	for _, dept := range subtree {
+		deptIDs = append(deptIDs, dept.ID)
+	}
+
+	count, err := a.userRepository.CountByDeptIDs(deptIDs)
+	if err != nil {
+		return nil, err
+	} else if count > 0 {
+		return nil, errors.DeptHasUsers
+	}
+
+	return deptIDs, nil
+}
+
+// updateChildTreePath 部门上级变更时同步修改子孙部门的 tree_path
+func (a DeptService) updateChildTreePath(id uint64, oTreePath, nTreePath string) error {
+	subtree, err := a.deptRepository.ListSubtree(id)
+	if err != nil {
+		return err
+	}
+
+	idStr := strconv.FormatUint(id, 10)
+	oPath := oTreePath + "," + idStr
+	nPath := nTreePath + "," + idStr
+	for _, dept := range subtree {
+		if !strings.HasPrefix(dept.TreePath, oPath) {
+			continue
+		}
+		if err := a.deptRepository.UpdateTreePath(dept.ID, nPath+dept.TreePath[len(oPath):]); err != nil {
+			return err
+		}
 	}
 
 	return nil
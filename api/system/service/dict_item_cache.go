@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+const (
+	// 字典项缓存过期时间，事务提交前被并发请求回填的旧数据最多保留这么久
+	dictCacheExpiration = 10 * time.Minute
+	// 字典编码不存在或没有启用的字典项时的负缓存过期时间
+	dictNegativeExpiration = time.Minute
+
+	// 字典项下拉选项缓存键
+	dictCacheKeyItems = "dict:items:%s"
+)
+
+// DictItemCache 字典项下拉选项缓存，按 CacheConfig 使用内存或 Redis
+// 字典变更事件转发为 WebSocket 字典变更广播，通知客户端与其他实例；
+// 监听该广播清除对应字典编码的缓存，多实例部署时其他实例修改字典后同样清除本实例的缓存
+type DictItemCache struct {
+	logger lib.Logger
+	cache  lib.Cache
+	group  *singleflight.Group
+}
+
+// NewDictItemCache creates a new dict item cache
+func NewDictItemCache(logger lib.Logger, cache lib.Cache, websocket *ws.WebSocket, bus *eventbus.Bus) DictItemCache {
+	a := DictItemCache{
+		logger: logger,
+		cache:  cache,
+		group:  &singleflight.Group{},
+	}
+
+	system.EventDictChanged.Subscribe(bus, "websocket", func(ctx context.Context, event system.DictChangedEvent) error {
+		websocket.BroadcastDictChange(event.DictCode)
+		return nil
+	})
+	websocket.OnDictChange(func(dictCode string) {
+		a.Invalidate(dictCode)
+	})
+
+	return a
+}
+
+// Load 获取字典项下拉选项（带缓存），未命中时合并并发请求通过 loader 加载并写回缓存
+func (a DictItemCache) Load(dictCode string, loader func() ([]*system.DictItemOptionVO, error)) ([]*system.DictItemOptionVO, error) {
+	cacheKey := fmt.Sprintf(dictCacheKeyItems, dictCode)
+
+	var list []*system.DictItemOptionVO
+	if err := a.cache.Get(cacheKey, &list); err == nil {
+		return list, nil
+	}
+
+	v, err, _ := a.group.Do(cacheKey, func() (interface{}, error) {
+		list, err := loader()
+		if err != nil {
+			return nil, err
+		}
+
+		expiration := dictCacheExpiration
+		if len(list) == 0 {
+			expiration = dictNegativeExpiration
+		}
+		if err := a.cache.Set(cacheKey, list, jitterExpiration(expiration)); err != nil {
+			a.logger.Zap.Warnf("Failed to cache %s: %v", cacheKey, err)
+		}
+
+		return list, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]*system.DictItemOptionVO), nil
+}
+
+// Invalidate 清除字典编码对应的缓存
+func (a DictItemCache) Invalidate(dictCodes ...string) {
+	keys := make([]string, 0, len(dictCodes))
+	for _, code := range dictCodes {
+		if code != "" {
+			keys = append(keys, fmt.Sprintf(dictCacheKeyItems, code))
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	if _, err := a.cache.Delete(keys...); err != nil {
+		a.logger.Zap.Warnf("Failed to invalidate dict cache %v: %v", dictCodes, err)
+	}
+}
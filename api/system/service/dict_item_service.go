@@ -16,16 +16,22 @@ import (
 type DictItemService struct {
 	logger             lib.Logger
 	dictItemRepository repository.DictItemRepository
+	responseCache      lib.ResponseCache
+	dictItemCache      DictItemCache
 }
 
 // NewDictItemService creates a new dict item service
 func NewDictItemService(
 	logger lib.Logger,
 	dictItemRepository repository.DictItemRepository,
+	responseCache lib.ResponseCache,
+	dictItemCache DictItemCache,
 ) DictItemService {
 	return DictItemService{
 		logger:             logger,
 		dictItemRepository: dictItemRepository,
+		responseCache:      responseCache,
+		dictItemCache:      dictItemCache,
 	}
 }
 
@@ -40,14 +46,16 @@ func (a DictItemService) GetDictItemPage(param *system.DictItemQueryParam) (*sys
 	return a.dictItemRepository.Query(param)
 }
 
-// GetDictItems 获取字典项列表（下拉选项）
+// GetDictItems 获取字典项列表（下拉选项），优先读取缓存
 func (a DictItemService) GetDictItems(dictCode string) ([]*system.DictItemOptionVO, error) {
-	list, err := a.dictItemRepository.GetByDictCode(dictCode)
-	if err != nil {
-		return nil, err
-	}
+	return a.dictItemCache.Load(dictCode, func() ([]*system.DictItemOptionVO, error) {
+		list, err := a.dictItemRepository.GetByDictCode(dictCode)
+		if err != nil {
+			return nil, err
+		}
 
-	return list.ToOptionList(), nil
+		return list.ToOptionList(), nil
+	})
 }
 
 // GetDictItemForm 获取字典项表单数据
@@ -82,13 +90,19 @@ func (a DictItemService) SaveDictItem(form *system.DictItemForm, createdBy uint6
 		CreateBy: createdBy,
 	}
 
-	return a.dictItemRepository.Create(item)
+	if err := a.dictItemRepository.Create(item); err != nil {
+		return err
+	}
+
+	a.dictItemCache.Invalidate(item.DictCode)
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDict)
+	return nil
 }
 
 // UpdateDictItem 更新字典项
 func (a DictItemService) UpdateDictItem(id uint64, form *system.DictItemForm, updatedBy uint64) error {
 	// 检查字典项是否存在
-	_, err := a.dictItemRepository.Get(id)
+	existItem, err := a.dictItemRepository.Get(id)
 	if err != nil {
 		return err
 	}
@@ -105,7 +119,13 @@ func (a DictItemService) UpdateDictItem(id uint64, form *system.DictItemForm, up
 		UpdateBy: updatedBy,
 	}
 
-	return a.dictItemRepository.Update(id, item)
+	if err := a.dictItemRepository.Update(id, item); err != nil {
+		return err
+	}
+
+	a.dictItemCache.Invalidate(existItem.DictCode, item.DictCode)
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDict)
+	return nil
 }
 
 // DeleteDictItemByIds 删除字典项
@@ -128,5 +148,16 @@ func (a DictItemService) DeleteDictItemByIds(ids string, deletedBy uint64) error
 		return errors.New("删除的字典项数据为空")
 	}
 
-	return a.dictItemRepository.DeleteByIDs(idList, deletedBy)
+	dictCodes, err := a.dictItemRepository.GetDictCodesByIDs(idList)
+	if err != nil {
+		return err
+	}
+
+	if err := a.dictItemRepository.DeleteByIDs(idList, deletedBy); err != nil {
+		return err
+	}
+
+	a.dictItemCache.Invalidate(dictCodes...)
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDict)
+	return nil
 }
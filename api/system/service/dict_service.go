@@ -17,6 +17,8 @@ type DictService struct {
 	logger             lib.Logger
 	dictRepository     repository.DictRepository
 	dictItemRepository repository.DictItemRepository
+	responseCache      lib.ResponseCache
+	dictItemCache      DictItemCache
 }
 
 // NewDictService creates a new dict service
@@ -24,11 +26,15 @@ func NewDictService(
 	logger lib.Logger,
 	dictRepository repository.DictRepository,
 	dictItemRepository repository.DictItemRepository,
+	responseCache lib.ResponseCache,
+	dictItemCache DictItemCache,
 ) DictService {
 	return DictService{
 		logger:             logger,
 		dictRepository:     dictRepository,
 		dictItemRepository: dictItemRepository,
+		responseCache:      responseCache,
+		dictItemCache:      dictItemCache,
 	}
 }
 
@@ -54,6 +60,58 @@ func (a DictService) GetDictList() ([]*system.DictOption, error) {
 	return list.ToOptions(), nil
 }
 
+// GetDelta 字典及字典项增量同步，version 为客户端上次同步返回的版本号，为空时返回全量
+func (a DictService) GetDelta(version string) (*system.DictDeltaVO, error) {
+	since, _ := parseDeltaVersion(version)
+
+	dicts, err := a.dictRepository.ListChanged(since)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.dictItemRepository.ListChanged(since)
+	if err != nil {
+		return nil, err
+	}
+
+	// 版本号取两张表（含已删除记录）的最后变更时间
+	latest, err := a.dictRepository.LatestUpdateTime()
+	if err != nil {
+		return nil, err
+	}
+	if t, err := a.dictItemRepository.LatestUpdateTime(); err != nil {
+		return nil, err
+	} else if t.After(latest) {
+		latest = t
+	}
+
+	delta := &system.DictDeltaVO{
+		Version:        formatDeltaVersion(latest, ""),
+		Full:           since == nil,
+		Dicts:          make(system.Dicts, 0, len(dicts)),
+		Items:          make(system.DictItems, 0, len(items)),
+		RemovedDictIDs: make([]uint64, 0),
+		RemovedItemIDs: make([]uint64, 0),
+	}
+
+	for _, dict := range dicts {
+		if dict.IsDeleted == 1 {
+			delta.RemovedDictIDs = append(delta.RemovedDictIDs, dict.ID)
+		} else {
+			delta.Dicts = append(delta.Dicts, dict)
+		}
+	}
+	for _, item := range items {
+		if item.IsDeleted == 1 {
+			delta.RemovedItemIDs = append(delta.RemovedItemIDs, item.ID)
+		} else {
+			delta.Items = append(delta.Items, item)
+		}
+	}
+
+	return delta, nil
+}
+
 // GetDictForm 获取字典表单数据
 func (a DictService) GetDictForm(id uint64) (*system.DictForm, error) {
 	dict, err := a.dictRepository.Get(id)
@@ -125,7 +183,13 @@ func (a DictService) UpdateDict(id uint64, form *system.DictForm, updatedBy uint
 		UpdateBy: updatedBy,
 	}
 
-	return a.dictRepository.Update(id, dict)
+	if err := a.dictRepository.Update(id, dict); err != nil {
+		return err
+	}
+
+	a.dictItemCache.Invalidate(existDict.DictCode, form.DictCode)
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDict)
+	return nil
 }
 
 // DeleteDictByIds 删除字典
@@ -168,8 +232,10 @@ func (a DictService) DeleteDictByIds(ids string, deletedBy uint64) error {
 		if err := a.dictItemRepository.DeleteByDictCodes(dictCodes, deletedBy); err != nil {
 			return err
 		}
+		a.dictItemCache.Invalidate(dictCodes...)
 	}
 
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDict)
 	return nil
 }
 
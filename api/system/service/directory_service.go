@@ -0,0 +1,166 @@
+package service
+
+import (
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// DirectoryService 只读的组织架构与通讯录，供其他内部系统嵌入通讯录组件使用
+// 只包含启用的部门与在职用户，联系方式按字段权限返回
+type DirectoryService struct {
+	logger            lib.Logger
+	userRepository    repository.UserRepository
+	deptRepository    repository.DeptRepository
+	permissionService PermissionService
+}
+
+// NewDirectoryService creates a new directory service
+func NewDirectoryService(
+	logger lib.Logger,
+	userRepository repository.UserRepository,
+	deptRepository repository.DeptRepository,
+	permissionService PermissionService,
+) DirectoryService {
+	return DirectoryService{
+		logger:            logger,
+		userRepository:    userRepository,
+		deptRepository:    deptRepository,
+		permissionService: permissionService,
+	}
+}
+
+// GetFields 当前身份可查看的联系方式字段，superAdmin 为 true 时全部可见
+func (a DirectoryService) GetFields(claims *dto.JwtClaims, superAdmin bool) (*system.DirectoryFields, error) {
+	if superAdmin {
+		return &system.DirectoryFields{Mobile: true, Email: true}, nil
+	}
+
+	perms, err := a.permissionService.GetClaimsPerms(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &system.DirectoryFields{
+		Mobile: MatchPerm(perms, system.DirectoryFieldMobilePerm),
+		Email:  MatchPerm(perms, system.DirectoryFieldEmailPerm),
+	}, nil
+}
+
+// GetDeptTree 组织架构树，附带各部门（含子部门）的在职人数
+func (a DirectoryService) GetDeptTree() ([]*system.DirectoryDeptVO, error) {
+	depts, err := a.deptRepository.GetAllEnabled()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := a.userRepository.CountActiveByDept()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDirectoryTree(0, buildDeptChildMap(depts), counts), nil
+}
+
+func buildDirectoryTree(parentId uint64, childMap map[uint64][]*system.Dept, counts map[uint64]int64) []*system.DirectoryDeptVO {
+	children, ok := childMap[parentId]
+	if !ok {
+		return nil
+	}
+
+	result := make([]*system.DirectoryDeptVO, 0, len(children))
+	for _, dept := range children {
+		node := &system.DirectoryDeptVO{
+			ID:        dept.ID,
+			Name:      dept.Name,
+			ParentID:  dept.ParentID,
+			UserCount: counts[dept.ID],
+			Children:  buildDirectoryTree(dept.ID, childMap, counts),
+		}
+		for _, child := range node.Children {
+			node.UserCount += child.UserCount
+		}
+		result = append(result, node)
+	}
+
+	return result
+}
+
+// QueryUsers 分页查询通讯录人员，按部门筛选时包含子部门
+func (a DirectoryService) QueryUsers(param *system.DirectoryQueryParam, fields *system.DirectoryFields) (*system.DirectoryUserQueryResult, error) {
+	depts, err := a.deptRepository.GetAllEnabled()
+	if err != nil {
+		return nil, err
+	}
+
+	deptNames := make(map[uint64]string, len(depts))
+	for _, dept := range depts {
+		deptNames[dept.ID] = dept.Name
+	}
+
+	var deptIDs []uint64
+	if param.DeptID > 0 {
+		if _, ok := deptNames[param.DeptID]; !ok {
+			return &system.DirectoryUserQueryResult{
+				List:       []*system.DirectoryUserVO{},
+				Pagination: &dto.Pagination{PageNum: param.GetPageNum(), PageSize: param.GetPageSize()},
+			}, nil
+		}
+		deptIDs = collectDeptIDs(param.DeptID, buildDeptChildMap(depts))
+	}
+
+	// 不能查看的联系方式也不参与搜索，避免通过搜索结果推断字段内容
+	columns := []string{"username", "nickname"}
+	if fields.Mobile {
+		columns = append(columns, "mobile")
+	}
+	if fields.Email {
+		columns = append(columns, "email")
+	}
+
+	qr, err := a.userRepository.QueryDirectory(param, deptIDs, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*system.DirectoryUserVO, 0, len(qr.List))
+	for _, user := range qr.List {
+		vo := &system.DirectoryUserVO{
+			ID:       user.ID,
+			Username: user.Username,
+			Name:     user.Nickname,
+			Gender:   user.Gender,
+			Avatar:   user.Avatar,
+			DeptID:   user.DeptID,
+			DeptName: deptNames[user.DeptID],
+		}
+		if vo.Name == "" {
+			vo.Name = user.Username
+		}
+		if fields.Mobile {
+			vo.Mobile = user.Mobile
+		}
+		if fields.Email {
+			vo.Email = user.Email
+		}
+		list = append(list, vo)
+	}
+
+	return &system.DirectoryUserQueryResult{
+		List:       list,
+		Pagination: qr.Pagination,
+	}, nil
+}
+
+// collectDeptIDs 部门及其全部子部门ID
+func collectDeptIDs(deptID uint64, childMap map[uint64][]*system.Dept) []uint64 {
+	ids := []uint64{deptID}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range childMap[ids[i]] {
+			ids = append(ids, child.ID)
+		}
+	}
+
+	return ids
+}
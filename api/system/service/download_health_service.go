@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+const (
+	downloadHealthTaskName    = "download_health"
+	downloadHealthDefaultSpec = "*/30 * * * * *"
+)
+
+// DownloadHealthService 定时调用各下载器的 Test 检查可用性并记录耗时，
+// 状态变化时推送到 /topic/downloaders/health，下载器恢复可用后立即同步它的活跃任务
+// 检查结果只保存在当前实例的内存中
+type DownloadHealthService struct {
+	logger          lib.Logger
+	ws              *ws.WebSocket
+	downloadService DownloadService
+	featureModules  lib.FeatureModules
+	mu              *sync.RWMutex
+	health          map[string]*system.DownloaderHealthVO
+}
+
+// NewDownloadHealthService 创建下载器健康检查服务，启动时检查一次，之后按配置定时检查
+func NewDownloadHealthService(
+	lc fx.Lifecycle,
+	logger lib.Logger,
+	config lib.Config,
+	cron lib.Crontab,
+	featureModules lib.FeatureModules,
+	websocket *ws.WebSocket,
+	downloadService DownloadService,
+) DownloadHealthService {
+	svc := DownloadHealthService{
+		logger:          logger,
+		ws:              websocket,
+		downloadService: downloadService,
+		featureModules:  featureModules,
+		mu:              new(sync.RWMutex),
+		health:          make(map[string]*system.DownloaderHealthVO),
+	}
+
+	websocket.RegisterTopic(ws.Topic{
+		Destination: ws.TopicDownloaderHealth,
+		Module:      "download",
+		Description: "下载器可用性，订阅时推送全部下载器最近一次检查的结果（snapshot），之后推送变为可用或不可用的下载器（changed）",
+		Permission:  "sys:download:query",
+		Payload:     system.DownloaderHealthEvent{},
+	})
+	websocket.RegisterSnapshot(ws.TopicDownloaderHealth, svc.snapshot)
+
+	// 下载器可以在运行时添加，只要启用了下载功能就定时检查
+	if config.Downloader == nil {
+		return svc
+	}
+
+	cfg := &lib.DownloadHealthConfig{}
+	if config.Downloader != nil && config.Downloader.HealthCheck != nil {
+		cfg = config.Downloader.HealthCheck
+	}
+	if !cfg.Disable && cron.IsEnabled() {
+		spec := cfg.Spec
+		if spec == "" {
+			spec = downloadHealthDefaultSpec
+		}
+		if err := cron.AddTask(downloadHealthTaskName, spec, svc.runScheduled); err != nil {
+			logger.Zap.Errorf("Failed to register downloader health task: %v", err)
+		}
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if featureModules.IsEnabled(lib.FeatureModuleDownload) {
+				go svc.Check(context.Background())
+			}
+			return nil
+		},
+	})
+
+	return svc
+}
+
+// runScheduled 定时检查，有不可用的下载器时记录为执行失败；离线下载模块禁用时跳过
+func (a DownloadHealthService) runScheduled(ctx context.Context) {
+	if !a.featureModules.IsEnabled(lib.FeatureModuleDownload) {
+		return
+	}
+
+	var down []string
+	for _, vo := range a.Check(ctx) {
+		if !vo.Available {
+			down = append(down, fmt.Sprintf("%s: %s", vo.Name, vo.Error))
+		}
+	}
+	if len(down) > 0 {
+		crontab.SetError(ctx, fmt.Errorf("downloaders unavailable: %s", strings.Join(down, "; ")))
+	}
+}
+
+// List 全部下载器最近一次检查的结果，还没有检查过时立即检查
+func (a DownloadHealthService) List(ctx context.Context) []*system.DownloaderHealthVO {
+	names := a.downloadService.DownloaderNames()
+
+	a.mu.RLock()
+	list := make([]*system.DownloaderHealthVO, 0, len(names))
+	for _, name := range names {
+		if vo, ok := a.health[name]; ok {
+			voCopy := *vo
+			list = append(list, &voCopy)
+		}
+	}
+	a.mu.RUnlock()
+
+	if len(list) < len(names) {
+		return a.Check(ctx)
+	}
+	return list
+}
+
+// Check 并发检查全部下载器并记录结果，推送可用性变化，返回本次的结果
+func (a DownloadHealthService) Check(ctx context.Context) []*system.DownloaderHealthVO {
+	names := a.downloadService.DownloaderNames()
+	results := make([]*system.DownloaderHealthVO, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = a.test(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	var changed []*system.DownloaderHealthVO
+	a.mu.Lock()
+	for _, vo := range results {
+		prev, ok := a.health[vo.Name]
+		switch {
+		case !ok:
+			// 首次检查只在不可用时推送
+			if !vo.Available {
+				changed = append(changed, vo)
+			}
+		case prev.Available != vo.Available:
+			changed = append(changed, vo)
+			if vo.Available {
+				go a.recovered(vo.Name)
+			}
+		default:
+			vo.ChangedAt = prev.ChangedAt
+		}
+		voCopy := *vo
+		a.health[vo.Name] = &voCopy
+	}
+	a.mu.Unlock()
+
+	for _, vo := range changed {
+		if vo.Available {
+			a.logger.Zap.Infof("Downloader %s is available again (%s)", vo.Name, vo.Version)
+		} else {
+			a.logger.Zap.Warnf("Downloader %s is unavailable: %s", vo.Name, vo.Error)
+		}
+	}
+	if len(changed) > 0 && a.ws.Broker.HasSubscribers(ws.TopicDownloaderHealth) {
+		a.ws.Broker.Publish(ws.TopicDownloaderHealth, &system.DownloaderHealthEvent{
+			Type:        system.DownloaderHealthEventChanged,
+			Downloaders: changed,
+			Timestamp:   time.Now().UnixMilli(),
+		})
+	}
+
+	return results
+}
+
+// test 调用下载器的 Test，超时时间与测试接口相同
+func (a DownloadHealthService) test(ctx context.Context, name string) *system.DownloaderHealthVO {
+	begin := time.Now()
+	version, err := a.downloadService.TestDownloader(ctx, name)
+	now := time.Now().Format("2006-01-02 15:04:05")
+
+	vo := &system.DownloaderHealthVO{
+		Name:      name,
+		Available: err == nil,
+		Version:   version,
+		Latency:   time.Since(begin).Milliseconds(),
+		CheckedAt: now,
+		ChangedAt: now,
+	}
+	if err != nil {
+		vo.Error = err.Error()
+	}
+	return vo
+}
+
+// recovered 下载器恢复可用后同步它的活跃任务，唤醒因查询失败而等待重试的队列任务
+func (a DownloadHealthService) recovered(name string) {
+	count, err := a.downloadService.RefreshDownloaderTasks(context.Background(), name)
+	if err != nil {
+		a.logger.Zap.Warnf("Failed to refresh tasks of downloader %s: %v", name, err)
+		return
+	}
+	a.logger.Zap.Infof("Refreshed %d tasks of downloader %s", count, name)
+}
+
+// snapshot 订阅 /topic/downloaders/health 时推送的快照
+func (a DownloadHealthService) snapshot(session *stomp.Session, destination string) (interface{}, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	list := make([]*system.DownloaderHealthVO, 0, len(a.health))
+	for _, name := range a.downloadService.DownloaderNames() {
+		if vo, ok := a.health[name]; ok {
+			voCopy := *vo
+			list = append(list, &voCopy)
+		}
+	}
+
+	return &system.DownloaderHealthEvent{
+		Type:        system.DownloaderHealthEventSnapshot,
+		Downloaders: list,
+		Timestamp:   time.Now().UnixMilli(),
+	}, true
+}
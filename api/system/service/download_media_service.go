@@ -0,0 +1,264 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	apperrors "github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/media"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+// DownloadMediaTaskType 音视频元数据提取队列任务类型
+const DownloadMediaTaskType = "download_media"
+
+// DownloadMediaService 下载完成后提取音视频文件的元数据并生成缩略图，供媒体库展示
+type DownloadMediaService struct {
+	logger                  lib.Logger
+	config                  lib.Config
+	taskQueue               lib.TaskQueue
+	downloadMediaRepository repository.DownloadMediaRepository
+	downloadRepository      repository.DownloadRepository
+	fileService             platformservice.FileService
+}
+
+// NewDownloadMediaService creates a new download media service
+func NewDownloadMediaService(
+	logger lib.Logger,
+	config lib.Config,
+	taskQueue lib.TaskQueue,
+	downloadMediaRepository repository.DownloadMediaRepository,
+	downloadRepository repository.DownloadRepository,
+	fileService platformservice.FileService,
+) DownloadMediaService {
+	return DownloadMediaService{
+		logger:                  logger,
+		config:                  config,
+		taskQueue:               taskQueue,
+		downloadMediaRepository: downloadMediaRepository,
+		downloadRepository:      downloadRepository,
+		fileService:             fileService,
+	}
+}
+
+// Enabled 是否启用音视频元数据提取
+func (a DownloadMediaService) Enabled() bool {
+	return a.config.Downloader.MediaEnabled()
+}
+
+// Schedule 提交任务中音视频文件的提取，启用队列时通过队列执行，否则在后台协程中执行
+// 返回待处理的文件数，没有音视频文件时不提交
+func (a DownloadMediaService) Schedule(task *system.DownloadTask, savePath string, files []downloader.TaskFile) (int, error) {
+	if !a.Enabled() {
+		return 0, apperrors.DownloadMediaNotEnabled
+	}
+
+	paths := mediaFiles(files, a.config.Downloader.Media.GetMaxFiles())
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	taskID := task.ID
+	run := func(ctx context.Context) error {
+		return a.extract(ctx, taskID, savePath, paths)
+	}
+
+	if a.taskQueue.IsEnabled() {
+		queueTask := queue.NewFuncTask(DownloadMediaTaskType, &queue.TaskOwner{ID: task.OwnerID}, run)
+		if err := a.taskQueue.QueueTask(context.Background(), queueTask); err != nil {
+			return 0, apperrors.Wrap(err, "failed to queue media task")
+		}
+	} else {
+		go func() {
+			_ = run(context.Background())
+		}()
+	}
+
+	a.logger.Zap.Infof("Media extraction scheduled for download task %d: %d files", taskID, len(paths))
+	return len(paths), nil
+}
+
+// mediaFiles 已选择下载的音视频文件，最多 limit 个
+func mediaFiles(files []downloader.TaskFile, limit int) []string {
+	paths := make([]string, 0)
+	for _, f := range files {
+		if !f.Selected || media.KindOf(f.Name) == "" {
+			continue
+		}
+		paths = append(paths, f.Name)
+		if len(paths) >= limit {
+			break
+		}
+	}
+
+	return paths
+}
+
+// extract 逐个文件提取元数据、生成缩略图，完成后替换任务原有的记录
+// 单个文件失败只记录原因，不影响其他文件
+func (a DownloadMediaService) extract(ctx context.Context, taskID uint64, savePath string, paths []string) error {
+	conf := a.config.Downloader.Media
+
+	list := make([]*system.DownloadMedia, 0, len(paths))
+	for _, rel := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		item := &system.DownloadMedia{
+			DownloadTaskID: taskID,
+			Path:           rel,
+			Kind:           string(media.KindOf(rel)),
+		}
+		list = append(list, item)
+
+		file, err := savePathJoin(savePath, rel)
+		if err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			item.Error = "file not found"
+			continue
+		}
+
+		fileCtx, cancel := context.WithTimeout(ctx, conf.GetTimeout())
+		err = a.extractFile(fileCtx, conf, taskID, file, item)
+		cancel()
+		if err != nil {
+			a.logger.Zap.Warnf("Failed to extract media %s of download task %d: %v", rel, taskID, err)
+			item.Error = str.S(err.Error()).Truncate(500)
+		}
+	}
+
+	old, err := a.downloadMediaRepository.Replace(taskID, list)
+	if err != nil {
+		a.deleteThumbnails(list)
+		return err
+	}
+	a.deleteThumbnails(old)
+
+	return nil
+}
+
+// extractFile 提取单个文件的元数据，并按配置生成缩略图
+func (a DownloadMediaService) extractFile(ctx context.Context, conf *lib.DownloadMediaConfig, taskID uint64, file string, item *system.DownloadMedia) error {
+	info, err := media.Probe(ctx, conf.GetFFprobe(), file)
+	if err != nil {
+		return err
+	}
+
+	item.Kind = string(info.Kind)
+	item.Format = str.S(info.Format).Truncate(100)
+	item.Duration = info.Duration
+	item.BitRate = info.BitRate
+	item.Width = info.Width
+	item.Height = info.Height
+	item.VideoCodec = str.S(info.VideoCodec).Truncate(50)
+	item.AudioCodec = str.S(info.AudioCodec).Truncate(50)
+
+	if !conf.Thumbnail || !info.HasPicture() {
+		return nil
+	}
+
+	data, err := media.Thumbnail(ctx, conf.GetFFmpeg(), file, media.ThumbnailAt(info), conf.GetThumbnailWidth())
+	if err != nil {
+		return fmt.Errorf("thumbnail: %w", err)
+	}
+
+	name := fmt.Sprintf("download-%d-%s.jpg", taskID, path.Base(item.Path))
+	uploaded, err := a.fileService.UploadFile(name, bytes.NewReader(data), int64(len(data)), "image/jpeg")
+	if err != nil {
+		return fmt.Errorf("thumbnail: %w", err)
+	}
+	item.Thumbnail = uploaded.URL
+
+	return nil
+}
+
+// deleteThumbnails 删除记录对应的缩略图，相同内容的缩略图由文件服务按引用计数管理
+func (a DownloadMediaService) deleteThumbnails(list []*system.DownloadMedia) {
+	for _, item := range list {
+		if item.Thumbnail == "" {
+			continue
+		}
+		if err := a.fileService.DeleteFile(item.Thumbnail); err != nil {
+			a.logger.Zap.Warnf("Failed to delete media thumbnail %s: %v", item.Thumbnail, err)
+		}
+	}
+}
+
+// ListByTask 任务的音视频文件
+func (a DownloadMediaService) ListByTask(taskID uint64) ([]*system.DownloadMediaVO, error) {
+	list, err := a.downloadMediaRepository.ListByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*system.DownloadMediaVO, 0, len(list))
+	for _, item := range list {
+		result = append(result, item.ToVO())
+	}
+
+	return result, nil
+}
+
+// Query 媒体库：分页查询全部未删除任务的音视频文件
+func (a DownloadMediaService) Query(param *system.DownloadMediaQueryParam) (*system.DownloadMediaQueryResult, error) {
+	list, pagination, err := a.downloadMediaRepository.Query(param)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(list))
+	for _, item := range list {
+		ids = append(ids, item.DownloadTaskID)
+	}
+	tasks, err := a.downloadRepository.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint64]string, len(tasks))
+	for _, task := range tasks {
+		names[task.ID] = task.Name
+	}
+
+	result := &system.DownloadMediaQueryResult{
+		List:       make([]*system.DownloadMediaVO, 0, len(list)),
+		Pagination: pagination,
+	}
+	for _, item := range list {
+		vo := item.ToVO()
+		vo.TaskName = names[item.DownloadTaskID]
+		result.List = append(result.List, vo)
+	}
+
+	return result, nil
+}
+
+// DeleteByTasks 删除任务的音视频记录及缩略图
+func (a DownloadMediaService) DeleteByTasks(taskIDs []uint64) error {
+	list, err := a.downloadMediaRepository.ListByTasks(taskIDs)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return nil
+	}
+
+	if err := a.downloadMediaRepository.DeleteByTasks(taskIDs); err != nil {
+		return err
+	}
+	a.deleteThumbnails(list)
+
+	return nil
+}
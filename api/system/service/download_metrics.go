@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/lib"
+)
+
+// downloadTaskCollector 按下载器和状态导出下载任务数量，每次采集时查询数据库
+type downloadTaskCollector struct {
+	logger             lib.Logger
+	downloadRepository repository.DownloadRepository
+	tasks              *prometheus.Desc
+}
+
+// newDownloadTaskCollector 创建下载任务指标采集器
+func newDownloadTaskCollector(namespace string, logger lib.Logger, downloadRepository repository.DownloadRepository) *downloadTaskCollector {
+	return &downloadTaskCollector{
+		logger:             logger,
+		downloadRepository: downloadRepository,
+		tasks: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "downloader", "tasks"),
+			"Number of download tasks by downloader and status.",
+			[]string{"downloader", "status"}, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *downloadTaskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.tasks
+}
+
+// Collect implements prometheus.Collector，查询失败时只记录日志，本次不输出该指标
+func (c *downloadTaskCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.downloadRepository.CountByDownloaderStatus()
+	if err != nil {
+		c.logger.Zap.Warnf("Failed to collect download task metrics: %v", err)
+		return
+	}
+
+	for _, item := range counts {
+		ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.GaugeValue, float64(item.Count), item.Downloader, item.Status)
+	}
+}
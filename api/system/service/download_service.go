@@ -3,20 +3,57 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"go.uber.org/fx"
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/api/system/repository"
 	apperrors "github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/archive"
+	"github.com/top-system/light-admin/pkg/crontab"
 	"github.com/top-system/light-admin/pkg/downloader"
 	"github.com/top-system/light-admin/pkg/downloader/aria2"
+	"github.com/top-system/light-admin/pkg/downloader/native"
 	"github.com/top-system/light-admin/pkg/downloader/qbittorrent"
+	"github.com/top-system/light-admin/pkg/downloader/sabnzbd"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	"github.com/top-system/light-admin/pkg/file"
+	"github.com/top-system/light-admin/pkg/netguard"
 	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/secret"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/str"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
 )
 
+// downloadSessionTaskName 定时保存 aria2 会话的任务名称
+const downloadSessionTaskName = "download_session"
+
+// downloadMaxStartDelay 下载任务计划开始时间的上限
+const downloadMaxStartDelay = 30 * 24 * time.Hour
+
+// downloadMaxSourceFileSize 上传的 .torrent / .metalink 文件大小上限
+const downloadMaxSourceFileSize = 10 * 1024 * 1024
+
+// downloadNoticeType 任务完成、出错通知的类型（字典 notice_type：其他）
+const downloadNoticeType = 99
+
 // downloaderLogger 是一个适配器，将 lib.Logger 转换为 downloader 需要的 Logger 接口
 type downloaderLogger struct {
 	logger lib.Logger
@@ -44,32 +81,161 @@ type DownloadService struct {
 	config               lib.Config
 	db                   lib.Database
 	downloadRepository   repository.DownloadRepository
+	tagRepository        repository.TagRepository
+	dlConfigRepository   repository.DownloaderConfigRepository
 	downloaders          map[string]downloader.Downloader
+	managed              map[string]string // 数据库中配置的下载器名称与临时目录
+	secretBox            *secret.Box       // 解密数据库中下载器的密钥与密码，未配置 EncryptKey 时为 nil
 	downloaderRegistry   *queue.DownloaderRegistry
 	taskQueue            lib.TaskQueue
-	mu                   sync.RWMutex
+	usageService         DownloadUsageService
+	mediaService         DownloadMediaService
+	transferService      DownloadTransferService
+	wsEventService       WsEventService
+	noticeService        NoticeService
+	bus                  *eventbus.Bus
+	userRepository       repository.UserRepository
+	ws                   *ws.WebSocket
+	featureModules       lib.FeatureModules
+	watch                *downloaderWatch
+	mu                   *sync.RWMutex
+}
+
+// downloaderWatch 接收下载器推送的协程，重新加载下载器后重启
+type downloaderWatch struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// start 停止之前的协程并通过 run 重新开始接收
+func (w *downloaderWatch) start(run func(ctx context.Context)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		w.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	run(ctx)
+}
+
+// restart 已经开始接收时重新开始，服务启动前或停止后不做处理
+func (w *downloaderWatch) restart(run func(ctx context.Context)) {
+	w.mu.Lock()
+	running := w.cancel != nil
+	w.mu.Unlock()
+
+	if running {
+		w.start(run)
+	}
+}
+
+// stop 停止接收
+func (w *downloaderWatch) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
 }
 
 // NewDownloadService creates a new download service
 func NewDownloadService(
+	lc fx.Lifecycle,
 	logger lib.Logger,
 	config lib.Config,
 	db lib.Database,
 	downloadRepository repository.DownloadRepository,
+	tagRepository repository.TagRepository,
+	dlConfigRepository repository.DownloaderConfigRepository,
 	taskQueue lib.TaskQueue,
+	crontab lib.Crontab,
+	featureModules lib.FeatureModules,
+	usageService DownloadUsageService,
+	mediaService DownloadMediaService,
+	transferService DownloadTransferService,
+	wsEventService WsEventService,
+	noticeService NoticeService,
+	bus *eventbus.Bus,
+	userRepository repository.UserRepository,
+	websocket *ws.WebSocket,
+	metrics lib.Metrics,
 ) DownloadService {
 	svc := DownloadService{
 		logger:             logger,
 		config:             config,
 		db:                 db,
 		downloadRepository: downloadRepository,
+		tagRepository:      tagRepository,
+		dlConfigRepository: dlConfigRepository,
 		downloaders:        make(map[string]downloader.Downloader),
+		managed:            make(map[string]string),
 		downloaderRegistry: queue.NewDownloaderRegistry(),
 		taskQueue:          taskQueue,
+		usageService:       usageService,
+		mediaService:       mediaService,
+		transferService:    transferService,
+		wsEventService:     wsEventService,
+		noticeService:      noticeService,
+		bus:                bus,
+		userRepository:     userRepository,
+		ws:                 websocket,
+		featureModules:     featureModules,
+		watch:              &downloaderWatch{},
+		mu:                 new(sync.RWMutex),
+	}
+
+	box, err := newDownloaderSecretBox(config)
+	if err != nil {
+		logger.Zap.Fatalf("Error to create downloader secret box: %v", err)
 	}
+	svc.secretBox = box
 
 	// 初始化下载器
 	svc.initDownloaders()
+	svc.registerSessionTasks(crontab)
+	metrics.MustRegister(newDownloadTaskCollector(metrics.Namespace, logger, downloadRepository))
+
+	// 离线下载模块禁用时停止接收下载器推送，启用后重新开始
+	featureModules.OnChange(lib.FeatureModuleDownload, func(enabled bool) {
+		if enabled {
+			svc.watch.start(svc.watchDownloaders)
+			return
+		}
+		svc.watch.stop()
+	})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if featureModules.IsEnabled(lib.FeatureModuleDownload) {
+				svc.watch.start(svc.watchDownloaders)
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			svc.watch.stop()
+			return nil
+		},
+	})
+
+	// 注册下载任务推送主题，订阅时先推送活跃任务快照
+	websocket.RegisterTopic(ws.Topic{
+		Destination: ws.TopicDownloads,
+		Module:      "download",
+		Description: "下载任务状态，订阅时推送统计与活跃任务快照（snapshot），之后推送单个任务的变化（updated/removed）",
+		Permission:  "sys:download:query",
+		Payload:     system.DownloadTaskEvent{},
+	})
+	websocket.RegisterSnapshot(ws.TopicDownloads, svc.snapshot)
+	websocket.RegisterTopic(ws.Topic{
+		Destination: ws.UserQueueDownloads,
+		Module:      "download",
+		Description: "自己创建的活跃下载任务的实时进度与速度（progress），定时推送，任务结束时推送最终状态",
+		Payload:     system.DownloadTaskEvent{},
+	})
 
 	return svc
 }
@@ -112,6 +278,186 @@ func (a *DownloadService) initDownloaders() {
 			a.logger.Zap.Info("qBittorrent downloader initialized")
 		}
 	}
+
+	if a.config.Downloader.SABnzbd != nil && a.config.Downloader.SABnzbd.Server != "" {
+		sabDownloader, err := sabnzbd.New(dlLogger, &sabnzbd.Settings{
+			Server:   a.config.Downloader.SABnzbd.Server,
+			APIKey:   a.config.Downloader.SABnzbd.APIKey,
+			Category: a.config.Downloader.SABnzbd.Category,
+			Options:  a.config.Downloader.SABnzbd.Options,
+		})
+		if err != nil {
+			a.logger.Zap.Errorf("Failed to initialize SABnzbd downloader: %v", err)
+		} else {
+			a.downloaders["sabnzbd"] = sabDownloader
+			a.downloaderRegistry.Register("sabnzbd", sabDownloader)
+			a.logger.Zap.Info("SABnzbd downloader initialized")
+		}
+	}
+
+	if a.config.Downloader.Native != nil && a.config.Downloader.Native.Enable {
+		nativeDownloader := native.New(dlLogger, &native.Settings{
+			TempPath:       a.config.Downloader.Native.TempPath,
+			Segments:       a.config.Downloader.Native.Segments,
+			MinSegmentSize: a.config.Downloader.Native.MinSegmentSize,
+			Options:        a.config.Downloader.Native.Options,
+		})
+		a.downloaders["native"] = nativeDownloader
+		a.downloaderRegistry.Register("native", nativeDownloader)
+		a.logger.Zap.Info("Native downloader initialized")
+	}
+
+	// 数据库中配置的下载器，数据表尚未迁移时只记录错误
+	if list, err := a.dlConfigRepository.List(); err != nil {
+		a.logger.Zap.Errorf("Failed to load downloader configs: %v", err)
+	} else {
+		a.applyManaged(list)
+	}
+
+	// 从任务存储恢复或由其他实例分发的下载任务按名称取回下载器与做种规则
+	a.downloaderRegistry.SetSeedingPolicy(a.seedingPolicy())
+	queue.RegisterResumableTaskFactory(queue.RemoteDownloadTaskType, queue.NewRemoteDownloadTaskFactory(a.downloaderRegistry))
+}
+
+// ReloadDownloaders 按数据库中的配置重新创建运行时管理的下载器，并重启下载器推送的接收
+// 配置文件中的下载器保持不变；正在执行的队列任务继续使用原有的下载器实例，直到任务结束或服务重启
+func (a DownloadService) ReloadDownloaders() error {
+	if a.config.Downloader == nil {
+		return nil
+	}
+
+	list, err := a.dlConfigRepository.List()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.applyManaged(list)
+	a.mu.Unlock()
+
+	a.watch.restart(a.watchDownloaders)
+	return nil
+}
+
+// applyManaged 替换运行时管理的下载器，与配置文件中的下载器同名的配置被忽略，调用方需持有写锁
+func (a DownloadService) applyManaged(list system.DownloaderConfigs) {
+	for name := range a.managed {
+		delete(a.downloaders, name)
+		delete(a.managed, name)
+		a.downloaderRegistry.Unregister(name)
+	}
+
+	dlLogger := &downloaderLogger{logger: a.logger}
+	for _, conf := range list {
+		if _, ok := a.downloaders[conf.Name]; ok {
+			a.logger.Zap.Warnf("Downloader %s is already configured in the config file, ignoring the database config", conf.Name)
+			continue
+		}
+
+		conf, err := a.decryptDownloaderConfig(conf)
+		if err != nil {
+			a.logger.Zap.Errorf("Failed to initialize downloader %s: %v", conf.Name, err)
+			continue
+		}
+
+		dl, err := newManagedDownloader(dlLogger, conf)
+		if err != nil {
+			a.logger.Zap.Errorf("Failed to initialize downloader %s: %v", conf.Name, err)
+			continue
+		}
+		a.downloaders[conf.Name] = dl
+		a.managed[conf.Name] = conf.TempPath
+		a.downloaderRegistry.Register(conf.Name, dl)
+		a.logger.Zap.Infof("Downloader %s (%s) initialized", conf.Name, conf.Type)
+	}
+}
+
+// decryptDownloaderConfig 返回解密密钥与密码后的配置副本
+func (a DownloadService) decryptDownloaderConfig(conf *system.DownloaderConfig) (*system.DownloaderConfig, error) {
+	plain := *conf
+	for _, field := range []*string{&plain.Token, &plain.Password} {
+		if *field == "" {
+			continue
+		}
+		if a.secretBox == nil {
+			return conf, apperrors.DownloaderConfigNoKey
+		}
+
+		value, err := a.secretBox.Decrypt(*field)
+		if err != nil {
+			return conf, fmt.Errorf("decrypt secrets: %w", err)
+		}
+		*field = value
+	}
+
+	return &plain, nil
+}
+
+// newManagedDownloader 按数据库中的配置创建下载器
+func newManagedDownloader(l *downloaderLogger, conf *system.DownloaderConfig) (downloader.Downloader, error) {
+	var options map[string]interface{}
+	if len(conf.Options) > 0 {
+		if err := json.Unmarshal(conf.Options, &options); err != nil {
+			return nil, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+
+	switch conf.Type {
+	case system.DownloaderTypeAria2:
+		return aria2.New(l, &aria2.Settings{
+			Server:   conf.Server,
+			Token:    conf.Token,
+			TempPath: conf.TempPath,
+			Options:  options,
+		}), nil
+	case system.DownloaderTypeQBittorrent:
+		return qbittorrent.New(l, &qbittorrent.Settings{
+			Server:   conf.Server,
+			User:     conf.User,
+			Password: conf.Password,
+			TempPath: conf.TempPath,
+			Options:  options,
+		})
+	case system.DownloaderTypeSABnzbd:
+		return sabnzbd.New(l, &sabnzbd.Settings{
+			Server:   conf.Server,
+			APIKey:   conf.Token,
+			Category: conf.Category,
+			Options:  options,
+		})
+	default:
+		return nil, fmt.Errorf("unknown downloader type: %q", conf.Type)
+	}
+}
+
+// IsManagedDownloader 下载器是否由数据库中的配置创建
+func (a DownloadService) IsManagedDownloader(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.managed[name]
+	return ok
+}
+
+// HasDownloader 下载器是否已初始化
+func (a DownloadService) HasDownloader(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	_, ok := a.downloaders[name]
+	return ok
+}
+
+// seedingPolicy 配置的做种规则，未配置时返回 nil
+func (a DownloadService) seedingPolicy() *queue.SeedingPolicy {
+	conf := a.config.Downloader.Seeding
+	if conf == nil || (conf.MaxRatio <= 0 && conf.MaxSeedTime <= 0) {
+		return nil
+	}
+	return &queue.SeedingPolicy{
+		MaxRatio:    conf.MaxRatio,
+		MaxSeedTime: time.Duration(conf.MaxSeedTime) * time.Minute,
+	}
 }
 
 // GetDownloaderRegistry returns the downloader registry
@@ -134,9 +480,45 @@ func (a *DownloadService) getDefaultDownloader() string {
 	return ""
 }
 
+// downloaderContext 为下载器调用附加对应操作的超时，请求结束（包括客户端断开）时同样取消
+func (a DownloadService) downloaderContext(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, a.config.Downloader.OperationTimeout(op))
+}
+
+// downloaderError 将下载器调用超时转换为 504 错误
+func downloaderError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apperrors.Wrap(apperrors.DownloadDownloaderTimeout, err.Error())
+	}
+	return err
+}
+
+// registerSessionTasks 为配置了 SaveSessionSpec 的 aria2 注册定时保存会话任务，离线下载模块禁用时跳过
+func (a *DownloadService) registerSessionTasks(cron lib.Crontab) {
+	if a.config.Downloader == nil || a.config.Downloader.Aria2 == nil || a.config.Downloader.Aria2.SaveSessionSpec == "" {
+		return
+	}
+	if _, ok := a.downloaders["aria2"]; !ok || !cron.IsEnabled() {
+		return
+	}
+
+	if err := cron.AddTask(downloadSessionTaskName, a.config.Downloader.Aria2.SaveSessionSpec, func(ctx context.Context) {
+		if !a.featureModules.IsEnabled(lib.FeatureModuleDownload) {
+			return
+		}
+		if _, err := a.SaveSession(ctx, "aria2"); err != nil {
+			a.logger.Zap.Errorf("Failed to save aria2 session: %v", err)
+			crontab.SetError(ctx, err)
+		}
+	}); err != nil {
+		a.logger.Zap.Errorf("Failed to register aria2 session task: %v", err)
+	}
+}
+
 // WithTrx delegates transaction to repository database
 func (a DownloadService) WithTrx(trxHandle *gorm.DB) DownloadService {
 	a.downloadRepository = a.downloadRepository.WithTrx(trxHandle)
+	a.tagRepository = a.tagRepository.WithTrx(trxHandle)
 	return a
 }
 
@@ -151,6 +533,19 @@ func (a DownloadService) Get(id uint64) (*system.DownloadTask, error) {
 	return a.downloadRepository.Get(id)
 }
 
+// CheckDataScope 检查下载任务是否在数据权限范围内（按创建人所在部门），超出范围按不存在处理
+func (a DownloadService) CheckDataScope(scope *system.DataScope, ids ...uint64) error {
+	if scope == nil || scope.All {
+		return nil
+	}
+
+	scoped, err := a.downloadRepository.ScopedIDs(ids, scope)
+	if err != nil {
+		return err
+	}
+	return requireInScope(ids, scoped)
+}
+
 // GetDetail 获取下载任务详情（包含文件列表）
 func (a DownloadService) GetDetail(ctx context.Context, id uint64) (*system.DownloadTaskDetailVO, error) {
 	task, err := a.downloadRepository.Get(id)
@@ -165,26 +560,34 @@ func (a DownloadService) GetDetail(ctx context.Context, id uint64) (*system.Down
 
 	detail := &system.DownloadTaskDetailVO{
 		DownloadTaskPageVO: system.DownloadTaskPageVO{
-			ID:            task.ID,
-			TaskID:        task.TaskID,
-			Hash:          task.Hash,
-			Name:          task.Name,
-			URL:           task.URL,
-			Downloader:    task.Downloader,
-			Status:        task.Status,
-			Total:         task.Total,
-			Downloaded:    task.Downloaded,
-			DownloadSpeed: task.DownloadSpeed,
-			Uploaded:      task.Uploaded,
-			UploadSpeed:   task.UploadSpeed,
-			SavePath:      task.SavePath,
-			ErrorMessage:  task.ErrorMessage,
-			Progress:      progress,
-			CreatedAt:     task.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:     task.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ID:             task.ID,
+			TaskID:         task.TaskID,
+			Hash:           task.Hash,
+			Name:           task.Name,
+			URL:            task.URL,
+			Downloader:     task.Downloader,
+			Status:         task.Status,
+			Total:          task.Total,
+			Downloaded:     task.Downloaded,
+			DownloadSpeed:  task.DownloadSpeed,
+			Uploaded:       task.Uploaded,
+			UploadSpeed:    task.UploadSpeed,
+			SavePath:       task.SavePath,
+			ErrorMessage:   task.ErrorMessage,
+			Progress:       progress,
+			TransferStatus: task.TransferStatus,
+			TransferPath:   task.TransferPath,
+			TransferError:  task.TransferError,
+			CreatedAt:      task.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:      task.UpdatedAt.Format("2006-01-02 15:04:05"),
 		},
 		Files: make([]system.DownloadTaskFileVO, 0),
 	}
+	if len(task.TransferFiles) > 0 {
+		if err := json.Unmarshal(task.TransferFiles, &detail.TransferFiles); err != nil {
+			a.logger.Zap.Warnf("Failed to parse transferred files of task %d: %v", task.ID, err)
+		}
+	}
 
 	// 从下载器获取实时文件列表
 	dl, ok := a.downloaders[task.Downloader]
@@ -193,7 +596,9 @@ func (a DownloadService) GetDetail(ctx context.Context, id uint64) (*system.Down
 			ID:   task.TaskID,
 			Hash: task.Hash,
 		}
-		status, err := dl.Info(ctx, handle)
+		infoCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpInfo)
+		status, err := dl.Info(infoCtx, handle)
+		cancel()
 		if err == nil && status != nil {
 			for _, f := range status.Files {
 				detail.Files = append(detail.Files, system.DownloadTaskFileVO{
@@ -202,11 +607,16 @@ func (a DownloadService) GetDetail(ctx context.Context, id uint64) (*system.Down
 					Size:     f.Size,
 					Progress: f.Progress * 100,
 					Selected: f.Selected,
+					Archive:  archive.IsArchive(f.Name),
 				})
 			}
 		}
 	}
 
+	if detail.Media, err = a.mediaService.ListByTask(task.ID); err != nil {
+		return nil, err
+	}
+
 	return detail, nil
 }
 
@@ -217,49 +627,73 @@ func (a DownloadService) Create(ctx context.Context, form *system.DownloadTaskCr
 		return nil, apperrors.DownloadQueueNotEnabled
 	}
 
-	// 如果没有指定下载器，使用默认下载器
-	downloaderName := form.Downloader
-	if downloaderName == "" {
-		downloaderName = a.getDefaultDownloader()
-		if downloaderName == "" {
-			return nil, apperrors.DownloadNoDownloaderConfig
+	// 上传文件与下载地址二选一，同时提交时使用文件
+	var source *downloader.SourceFile
+	if form.File != nil {
+		var err error
+		if source, err = readSourceFile(form.File); err != nil {
+			return nil, err
 		}
+	} else if _, err := validateDownloadURL(ctx, form.URL); err != nil {
+		return nil, err
 	}
 
-	a.mu.RLock()
-	dl, ok := a.downloaders[downloaderName]
-	a.mu.RUnlock()
+	startAt := time.Time(form.StartAt)
+	if startAt.After(time.Now().Add(downloadMaxStartDelay)) {
+		return nil, apperrors.DownloadStartAtInvalid
+	}
 
-	if !ok {
-		return nil, apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", downloaderName)
+	dl, err := a.resolveDownloader(form)
+	if err != nil {
+		return nil, err
+	}
+	if source != nil {
+		if err := checkFileCreator(dl, form.Downloader); err != nil {
+			return nil, err
+		}
+	}
+
+	// 按实际流量检查配额
+	if err := a.usageService.CheckQuota(ownerID); err != nil {
+		return nil, err
 	}
-	form.Downloader = downloaderName // 回填下载器名称
 
 	// 创建队列任务
 	owner := &queue.TaskOwner{
 		ID: ownerID,
 	}
 
-	queueTask, err := queue.NewRemoteDownloadTask(ctx, form.URL, form.Downloader, form.Options, owner)
+	var queueTask queue.Task
+	if source != nil {
+		queueTask, err = queue.NewRemoteDownloadTaskFromFile(ctx, source, form.Downloader, form.Options, owner)
+	} else {
+		queueTask, err = queue.NewRemoteDownloadTask(ctx, form.URL, form.Downloader, form.Options, owner)
+	}
 	if err != nil {
 		return nil, apperrors.Wrap(err, "failed to create queue task")
 	}
 
-	// 设置下载器
+	// 设置下载器、做种规则与优先级
 	if remoteTask, ok := queueTask.(*queue.RemoteDownloadTask); ok {
 		remoteTask.SetDownloader(dl)
+		remoteTask.SetSeedingPolicy(a.downloaderRegistry.SeedingPolicy())
+		remoteTask.SetPriority(form.Priority)
 	}
 
-	// 提交到队列
-	if err := a.taskQueue.Queue.QueueTask(ctx, queueTask); err != nil {
+	// 提交到队列，指定开始时间时到时间后才执行
+	if err := a.taskQueue.Queue.QueueTaskAt(ctx, queueTask, startAt); err != nil {
 		return nil, apperrors.Wrap(err, "failed to queue download task")
 	}
 
 	// 同时保存到下载任务表（用于界面查询）
+	name, taskURL := form.URL, form.URL // 初始名称为URL，后续同步时更新
+	if source != nil {
+		name, taskURL = source.Name, ""
+	}
 	task := &system.DownloadTask{
 		QueueTaskID: uint64(queueTask.ID()),
-		Name:        form.URL, // 初始名称为URL，后续同步时更新
-		URL:         form.URL,
+		Name:        name,
+		URL:         taskURL,
 		Downloader:  form.Downloader,
 		Status:      "queued",
 		OwnerID:     ownerID,
@@ -271,226 +705,1248 @@ func (a DownloadService) Create(ctx context.Context, form *system.DownloadTaskCr
 	}
 
 	a.logger.Zap.Infof("Download task created and queued: %d", task.ID)
+	a.publishUpdated(task)
 	return task, nil
 }
 
-// Cancel 取消下载任务
-func (a DownloadService) Cancel(ctx context.Context, id uint64) error {
-	task, err := a.downloadRepository.Get(id)
-	if err != nil {
-		return err
+// DryRun 预检创建下载任务：校验地址，读取磁力链接或 HEAD 响应中的元数据，
+// 检查队列、下载器、配额与磁盘空间，不创建下载器任务和队列任务
+// 权限与创建一样由路由校验（sys:download:add）
+func (a DownloadService) DryRun(ctx context.Context, form *system.DownloadTaskCreateForm, ownerID uint64) (*system.DownloadDryRunVO, error) {
+	vo := &system.DownloadDryRunVO{URL: form.URL, Checks: make([]system.DownloadDryRunCheckVO, 0)}
+	check := func(name string, err error, message string) {
+		c := system.DownloadDryRunCheckVO{Name: name, Passed: err == nil, Message: message}
+		if err != nil {
+			c.Message = err.Error()
+		}
+		vo.Checks = append(vo.Checks, c)
+	}
+	skip := func(name, message string) {
+		vo.Checks = append(vo.Checks, system.DownloadDryRunCheckVO{Name: name, Skipped: true, Message: message})
 	}
 
-	// 尝试从队列注册表获取任务并取消
-	if task.QueueTaskID > 0 && a.taskQueue.Registry != nil {
-		if qTask, ok := a.taskQueue.Registry.Get(int(task.QueueTaskID)); ok && qTask != nil {
-			if remoteTask, ok := qTask.(*queue.RemoteDownloadTask); ok {
-				if err := remoteTask.CancelDownload(ctx); err != nil {
-					a.logger.Zap.Warnf("Failed to cancel download in downloader: %v", err)
-				}
+	if form.File != nil {
+		// 上传的文件不解析内容，只校验类型与大小
+		source, err := readSourceFile(form.File)
+		check("file", err, "")
+		if err != nil {
+			return vo, nil
+		}
+		vo.Name, vo.Scheme = source.Name, string(source.Kind())
+	} else {
+		u, err := validateDownloadURL(ctx, form.URL)
+		check("url", err, "")
+		if err != nil {
+			return vo, nil
+		}
+		vo.Scheme = u.Scheme
+
+		// 元数据：磁力链接直接解析，HTTP 下载发起 HEAD 请求，获取失败不影响创建
+		switch u.Scheme {
+		case "magnet":
+			if m, err := downloader.ParseMagnet(form.URL); err == nil {
+				vo.Name, vo.Hash, vo.Size, vo.Trackers = m.Name, m.Hash, m.Size, len(m.Trackers)
 			}
+		case "http", "https":
+			vo.Name = path.Base(u.Path)
+			if err := a.probe(ctx, u, vo); err != nil {
+				skip("probe", err.Error())
+			} else {
+				check("probe", nil, "")
+			}
+		default:
+			vo.Name = path.Base(u.Path)
+			skip("probe", "metadata is only probed for http(s) urls")
 		}
 	}
+	if vo.Name == "/" || vo.Name == "." {
+		vo.Name = ""
+	}
 
-	// 直接从下载器取消（如果有 handle）
-	a.mu.RLock()
-	dl, ok := a.downloaders[task.Downloader]
-	a.mu.RUnlock()
-
-	if ok && task.TaskID != "" {
-		handle := &downloader.TaskHandle{
-			ID:   task.TaskID,
-			Hash: task.Hash,
-		}
-		if err := dl.Cancel(ctx, handle); err != nil {
-			a.logger.Zap.Warnf("Failed to cancel task in downloader: %v", err)
-		}
+	if a.taskQueue.Queue == nil {
+		check("queue", apperrors.DownloadQueueNotEnabled, "")
+	} else {
+		check("queue", nil, "")
 	}
 
-	// 更新数据库状态
-	return a.downloadRepository.UpdateStatus(id, "canceled", task.Downloaded, task.Total, 0, task.Uploaded, 0, "")
-}
+	dl, err := a.resolveDownloader(form)
+	if err == nil && form.File != nil {
+		err = checkFileCreator(dl, form.Downloader)
+	}
+	check("downloader", err, form.Downloader)
+	vo.Downloader = form.Downloader
 
-// SetFilesToDownload 设置要下载的文件
-func (a DownloadService) SetFilesToDownload(ctx context.Context, id uint64, form *system.SetFileDownloadForm) error {
-	task, err := a.downloadRepository.Get(id)
+	quota, err := a.usageService.Quota(ownerID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	vo.Quota = quota
+	check("quota", QuotaError(quota), "")
+
+	// 下载目录与服务在同一台机器时检查剩余空间
+	if dir := a.tempPath(form.Downloader); dir == "" {
+		skip("disk", "TempPath is not configured")
+	} else if free, err := file.FreeSpace(dir); err != nil {
+		skip("disk", fmt.Sprintf("TempPath is not accessible from this host: %v", err))
+	} else if vo.Size > 0 && uint64(vo.Size) > free {
+		check("disk", fmt.Errorf("not enough space in %s: %d bytes free, %d bytes needed", dir, free, vo.Size), "")
+	} else {
+		check("disk", nil, fmt.Sprintf("%d bytes free in %s", free, dir))
 	}
 
-	// 尝试从队列任务操作
-	if task.QueueTaskID > 0 && a.taskQueue.Registry != nil {
-		if qTask, ok := a.taskQueue.Registry.Get(int(task.QueueTaskID)); ok && qTask != nil {
-			if remoteTask, ok := qTask.(*queue.RemoteDownloadTask); ok {
-				args := make([]*downloader.SetFileToDownloadArgs, 0, len(form.Files))
-				for _, f := range form.Files {
-					args = append(args, &downloader.SetFileToDownloadArgs{
-						Index:    f.Index,
-						Download: f.Download,
-					})
-				}
-				return remoteTask.SetFilesToDownload(ctx, args...)
-			}
+	vo.WouldCreate = true
+	for _, c := range vo.Checks {
+		if !c.Passed && !c.Skipped {
+			vo.WouldCreate = false
 		}
 	}
 
-	// 直接从下载器操作
-	a.mu.RLock()
-	dl, ok := a.downloaders[task.Downloader]
-	a.mu.RUnlock()
+	return vo, nil
+}
 
-	if !ok {
-		return apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", task.Downloader)
+// probeClient 探测用户提交的下载地址，只连接公网地址且不跟随重定向，避免被用来探测内网
+var probeClient = netguard.NewClient(30 * time.Second)
+
+// probe 对 HTTP 下载地址发起 HEAD 请求，读取大小、类型与文件名
+func (a DownloadService) probe(ctx context.Context, u *url.URL, vo *system.DownloadDryRunVO) error {
+	probeCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpProbe)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := probeClient.Do(req)
+	if err != nil {
+		return err
 	}
+	resp.Body.Close()
 
-	handle := &downloader.TaskHandle{
-		ID:   task.TaskID,
-		Hash: task.Hash,
+	if resp.StatusCode >= http.StatusMultipleChoices && resp.StatusCode < http.StatusBadRequest {
+		return fmt.Errorf("HEAD %s: redirects are not followed", resp.Status)
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HEAD %s", resp.Status)
 	}
 
-	args := make([]*downloader.SetFileToDownloadArgs, 0, len(form.Files))
-	for _, f := range form.Files {
-		args = append(args, &downloader.SetFileToDownloadArgs{
-			Index:    f.Index,
-			Download: f.Download,
-		})
+	if resp.ContentLength > 0 {
+		vo.Size = resp.ContentLength
+	}
+	vo.ContentType = resp.Header.Get("Content-Type")
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		vo.Name = params["filename"]
+	} else {
+		vo.Name = path.Base(resp.Request.URL.Path)
 	}
 
-	return dl.SetFilesToDownload(ctx, handle, args...)
+	return nil
 }
 
-// Delete 删除下载任务
-func (a DownloadService) Delete(ctx context.Context, id uint64) error {
-	// 先取消下载器中的任务
-	_ = a.cancelDownloaderTask(ctx, id)
-	return a.downloadRepository.Delete(id)
+// readSourceFile 读取上传的 .torrent / .metalink 文件
+func readSourceFile(fh *multipart.FileHeader) (*downloader.SourceFile, error) {
+	source := &downloader.SourceFile{Name: filepath.Base(fh.Filename)}
+	if source.Kind() == downloader.SourceUnknown {
+		return nil, apperrors.Wrapf(apperrors.DownloadFileUnsupported, "%q", fh.Filename)
+	}
+	if fh.Size > downloadMaxSourceFileSize {
+		return nil, apperrors.Wrapf(apperrors.DownloadFileTooLarge, "max %d bytes", downloadMaxSourceFileSize)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	source.Content, err = io.ReadAll(io.LimitReader(src, downloadMaxSourceFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(source.Content) > downloadMaxSourceFileSize {
+		return nil, apperrors.Wrapf(apperrors.DownloadFileTooLarge, "max %d bytes", downloadMaxSourceFileSize)
+	}
+	return source, nil
 }
 
-// BatchDelete 批量删除下载任务
-func (a DownloadService) BatchDelete(ctx context.Context, ids []uint64) error {
-	// 先取消所有任务
-	for _, id := range ids {
-		_ = a.cancelDownloaderTask(ctx, id)
+// checkFileCreator 检查下载器能否通过上传的文件创建任务
+func checkFileCreator(dl downloader.Downloader, name string) error {
+	if _, ok := dl.(downloader.FileCreator); !ok {
+		return apperrors.Wrapf(apperrors.DownloadFileUnsupported, "downloader %q only accepts urls", name)
 	}
-	return a.downloadRepository.BatchDelete(ids)
+	return nil
 }
 
-// cancelDownloaderTask 取消下载器中的任务
-func (a DownloadService) cancelDownloaderTask(ctx context.Context, id uint64) error {
-	task, err := a.downloadRepository.Get(id)
+// validateDownloadURL 校验下载地址，支持 http(s)、ftp、sftp 与磁力链接；
+// 下载由服务端发起，主机解析到内网等非公网地址时拒绝
+func validateDownloadURL(ctx context.Context, raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
 	if err != nil {
-		return err
+		return nil, apperrors.Wrap(apperrors.DownloadInvalidURL, err.Error())
 	}
 
-	// 尝试从队列任务获取 handle
-	var handle *downloader.TaskHandle
-	if task.QueueTaskID > 0 {
-		state := a.getRemoteDownloadState(int(task.QueueTaskID))
-		if state != nil && state.Handle != nil {
-			handle = state.Handle
+	u.Scheme = strings.ToLower(u.Scheme)
+	switch u.Scheme {
+	case "magnet":
+		if _, err := downloader.ParseMagnet(u.String()); err != nil {
+			return nil, apperrors.Wrap(apperrors.DownloadInvalidURL, err.Error())
+		}
+	case "http", "https", "ftp", "sftp":
+		if u.Host == "" {
+			return nil, apperrors.Wrapf(apperrors.DownloadInvalidURL, "missing host: %s", raw)
 		}
+		if err := netguard.CheckHost(ctx, u.Hostname()); err != nil {
+			return nil, apperrors.Wrap(apperrors.DownloadInvalidURL, err.Error())
+		}
+	default:
+		return nil, apperrors.Wrapf(apperrors.DownloadInvalidURL, "scheme: %q", u.Scheme)
 	}
 
-	// 如果没有从队列获取到，使用数据库中的
-	if handle == nil && (task.TaskID != "" || task.Hash != "") {
-		handle = &downloader.TaskHandle{
+	return u, nil
+}
+
+// resolveDownloader 取得表单指定的下载器，未指定时使用默认下载器并回填名称
+func (a DownloadService) resolveDownloader(form *system.DownloadTaskCreateForm) (downloader.Downloader, error) {
+	if form.Downloader == "" {
+		form.Downloader = a.getDefaultDownloader()
+		if form.Downloader == "" {
+			return nil, apperrors.DownloadNoDownloaderConfig
+		}
+	}
+
+	a.mu.RLock()
+	dl, ok := a.downloaders[form.Downloader]
+	a.mu.RUnlock()
+
+	if !ok {
+		return nil, apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", form.Downloader)
+	}
+	return dl, nil
+}
+
+// tempPath 下载器的临时下载目录
+func (a DownloadService) tempPath(name string) string {
+	if a.config.Downloader == nil {
+		return ""
+	}
+
+	a.mu.RLock()
+	dir, ok := a.managed[name]
+	a.mu.RUnlock()
+	if ok {
+		return dir
+	}
+
+	switch name {
+	case "aria2":
+		if a.config.Downloader.Aria2 != nil {
+			return a.config.Downloader.Aria2.TempPath
+		}
+	case "qbittorrent":
+		if a.config.Downloader.QBittorrent != nil {
+			return a.config.Downloader.QBittorrent.TempPath
+		}
+	case "native":
+		if a.config.Downloader.Native != nil {
+			return a.config.Downloader.Native.TempPath
+		}
+	}
+	return ""
+}
+
+// downloadTempFolders 下载器在临时下载目录下创建的子目录，每个任务保存在其中单独的目录中
+var downloadTempFolders = []string{aria2.Aria2TempFolder, qbittorrent.QBittorrentTempFolder, native.NativeTempFolder}
+
+// OrphanedTempFolders 下载器临时目录中没有任何任务引用、且 before 之后没有修改过的任务目录，
+// 通常是任务记录已删除（如按保留策略清理）而文件没有随之删除
+func (a DownloadService) OrphanedTempFolders(before time.Time) ([]string, error) {
+	a.mu.RLock()
+	names := make([]string, 0, len(a.downloaders))
+	for name := range a.downloaders {
+		names = append(names, name)
+	}
+	a.mu.RUnlock()
+
+	bases := make(map[string]bool, len(names))
+	for _, name := range names {
+		if dir := a.tempPath(name); dir != "" {
+			bases[filepath.Clean(dir)] = true
+		}
+	}
+	if len(bases) == 0 {
+		return nil, nil
+	}
+
+	savePaths, err := a.downloadRepository.ListSavePaths()
+	if err != nil {
+		return nil, err
+	}
+	referenced := func(dir string) bool {
+		for _, p := range savePaths {
+			p = filepath.Clean(filepath.FromSlash(p))
+			if p == dir || strings.HasPrefix(p, dir+string(filepath.Separator)) || strings.HasPrefix(dir, p+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	orphans := make([]string, 0)
+	for base := range bases {
+		for _, folder := range downloadTempFolders {
+			root := filepath.Join(base, folder)
+			entries, err := os.ReadDir(root)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return orphans, err
+			}
+
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					continue
+				}
+				info, err := entry.Info()
+				if err != nil || info.ModTime().After(before) {
+					continue
+				}
+				dir := filepath.Join(root, entry.Name())
+				if !referenced(dir) {
+					orphans = append(orphans, dir)
+				}
+			}
+		}
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// ListArchive 列出已完成任务中压缩包的内容，不解压
+func (a DownloadService) ListArchive(id uint64, param *system.DownloadArchiveQueryParam) (*system.DownloadArchiveVO, error) {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := archiveFile(task, param.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := archive.List(file)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.DownloadArchiveUnsupported, err.Error())
+	}
+
+	vo := &system.DownloadArchiveVO{
+		Path:    param.Path,
+		Entries: make([]*system.DownloadArchiveEntryVO, 0, len(entries)),
+	}
+	for _, e := range entries {
+		vo.Entries = append(vo.Entries, &system.DownloadArchiveEntryVO{
+			Name:     e.Name,
+			Size:     e.Size,
+			Dir:      e.Dir,
+			Modified: e.Modified.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return vo, nil
+}
+
+// ExtractArchive 通过队列任务将压缩包中选中的条目解压到任务保存目录
+func (a DownloadService) ExtractArchive(ctx context.Context, id uint64, form *system.DownloadArchiveExtractForm, ownerID uint64) (*system.DownloadArchiveExtractVO, error) {
+	if a.taskQueue.Queue == nil {
+		return nil, apperrors.DownloadQueueNotEnabled
+	}
+
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := archiveFile(task, form.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := form.Dst
+	if dst == "" {
+		dst = path.Join(path.Dir(form.Path), archive.TrimExt(form.Path))
+	}
+	dstPath, err := savePathJoin(task.SavePath, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	queueTask, err := queue.NewExtractArchiveTask(file, dstPath, form.Entries, &queue.TaskOwner{ID: ownerID})
+	if err != nil {
+		return nil, err
+	}
+	if err := a.taskQueue.Queue.QueueTask(ctx, queueTask); err != nil {
+		return nil, apperrors.Wrap(err, "failed to queue extract task")
+	}
+
+	a.logger.Zap.Infof("Extract task queued for download task %d: %s", id, form.Path)
+	return &system.DownloadArchiveExtractVO{QueueTaskID: uint64(queueTask.ID()), Dst: dst}, nil
+}
+
+// ExtractMedia 重新提取已完成任务中音视频文件的元数据与缩略图
+func (a DownloadService) ExtractMedia(ctx context.Context, id uint64) (*system.DownloadMediaExtractVO, error) {
+	if !a.mediaService.Enabled() {
+		return nil, apperrors.DownloadMediaNotEnabled
+	}
+
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != string(downloader.StatusCompleted) && task.Status != string(downloader.StatusSeeding) {
+		return nil, apperrors.Wrapf(apperrors.DownloadTaskNotCompleted, "status: %s", task.Status)
+	}
+
+	a.mu.RLock()
+	dl, ok := a.downloaders[task.Downloader]
+	a.mu.RUnlock()
+	if !ok {
+		return nil, apperrors.DownloadDownloaderNotFound
+	}
+
+	infoCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpInfo)
+	status, err := dl.Info(infoCtx, &downloader.TaskHandle{ID: task.TaskID, Hash: task.Hash})
+	cancel()
+	if err != nil {
+		return nil, downloaderError(err)
+	}
+
+	savePath := status.SavePath
+	if savePath == "" {
+		savePath = task.SavePath
+	}
+	files, err := a.mediaService.Schedule(task, savePath, status.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &system.DownloadMediaExtractVO{Files: files}, nil
+}
+
+// archiveFile 校验任务已完成并返回压缩包在本机的路径
+func archiveFile(task *system.DownloadTask, rel string) (string, error) {
+	if task.Status != string(downloader.StatusCompleted) && task.Status != string(downloader.StatusSeeding) {
+		return "", apperrors.Wrapf(apperrors.DownloadTaskNotCompleted, "status: %s", task.Status)
+	}
+	if !archive.IsArchive(rel) {
+		return "", apperrors.Wrapf(apperrors.DownloadArchiveUnsupported, "path: %s", rel)
+	}
+
+	file, err := savePathJoin(task.SavePath, rel)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(file); err != nil || info.IsDir() {
+		return "", apperrors.Wrapf(apperrors.DownloadArchiveNotFound, "path: %s", rel)
+	}
+
+	return file, nil
+}
+
+// savePathJoin 拼接任务保存目录与相对路径，拒绝指向保存目录之外的路径
+func savePathJoin(savePath, rel string) (string, error) {
+	if savePath == "" {
+		return "", apperrors.Wrap(apperrors.DownloadArchiveNotFound, "task has no save path")
+	}
+
+	rel = filepath.ToSlash(rel)
+	for _, seg := range strings.Split(rel, "/") {
+		if seg == ".." {
+			return "", apperrors.Wrapf(apperrors.DownloadPathInvalid, "path: %s", rel)
+		}
+	}
+
+	clean := path.Clean("/" + rel)
+	if clean == "/" {
+		return "", apperrors.Wrapf(apperrors.DownloadPathInvalid, "path: %s", rel)
+	}
+	return filepath.Join(filepath.FromSlash(savePath), filepath.FromSlash(clean)), nil
+}
+
+// Cancel 取消下载任务
+func (a DownloadService) Cancel(ctx context.Context, id uint64) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// 尝试从队列注册表获取任务并取消
+	if task.QueueTaskID > 0 && a.taskQueue.Registry != nil {
+		if qTask, ok := a.taskQueue.Registry.Get(int(task.QueueTaskID)); ok && qTask != nil {
+			if remoteTask, ok := qTask.(*queue.RemoteDownloadTask); ok {
+				cancelCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpCancel)
+				if err := remoteTask.CancelDownload(cancelCtx); err != nil {
+					a.logger.Zap.Warnf("Failed to cancel download in downloader: %v", err)
+				}
+				cancel()
+			}
+		}
+	}
+
+	// 直接从下载器取消（如果有 handle）
+	a.mu.RLock()
+	dl, ok := a.downloaders[task.Downloader]
+	a.mu.RUnlock()
+
+	if ok && task.TaskID != "" {
+		handle := &downloader.TaskHandle{
+			ID:   task.TaskID,
+			Hash: task.Hash,
+		}
+		cancelCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpCancel)
+		if err := dl.Cancel(cancelCtx, handle); err != nil {
+			a.logger.Zap.Warnf("Failed to cancel task in downloader: %v", err)
+		}
+		cancel()
+	}
+
+	// 更新数据库状态
+	if err := a.downloadRepository.UpdateStatus(id, "canceled", task.Downloaded, task.Total, 0, task.Uploaded, 0, ""); err != nil {
+		return err
+	}
+	a.publishTask(id)
+	return nil
+}
+
+// Pause 暂停下载任务，已下载的数据保留在下载器中
+func (a DownloadService) Pause(ctx context.Context, id uint64) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+	if task.Status != string(downloader.StatusDownloading) && task.Status != string(downloader.StatusSeeding) {
+		return apperrors.Wrapf(apperrors.DownloadTaskNotActive, "status: %s", task.Status)
+	}
+
+	if err := a.control(ctx, task, func(ctx context.Context, dl downloader.Downloader, handle *downloader.TaskHandle) error {
+		return dl.Pause(ctx, handle)
+	}); err != nil {
+		return err
+	}
+
+	if err := a.downloadRepository.UpdateStatus(id, string(downloader.StatusPaused), task.Downloaded, task.Total, 0, task.Uploaded, 0, ""); err != nil {
+		return err
+	}
+	a.publishTask(id)
+	return nil
+}
+
+// Resume 恢复已暂停的下载任务，并立即唤醒对应的队列任务查询状态
+func (a DownloadService) Resume(ctx context.Context, id uint64) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+	if task.Status != string(downloader.StatusPaused) {
+		return apperrors.Wrapf(apperrors.DownloadTaskNotPaused, "status: %s", task.Status)
+	}
+
+	if err := a.control(ctx, task, func(ctx context.Context, dl downloader.Downloader, handle *downloader.TaskHandle) error {
+		return dl.Resume(ctx, handle)
+	}); err != nil {
+		return err
+	}
+
+	if err := a.downloadRepository.UpdateStatus(id, string(downloader.StatusDownloading), task.Downloaded, task.Total, 0, task.Uploaded, 0, ""); err != nil {
+		return err
+	}
+	a.publishTask(id)
+
+	if task.QueueTaskID > 0 && a.taskQueue.IsEnabled() {
+		if err := a.taskQueue.Queue.Wake(ctx, int(task.QueueTaskID)); err != nil && !errors.Is(err, queue.ErrTaskNotFound) {
+			a.logger.Zap.Warnf("Failed to wake queue task %d: %v", task.QueueTaskID, err)
+		}
+	}
+	return nil
+}
+
+// SetSpeedLimit 设置下载中、做种中或已暂停任务的限速（KB/s），0 表示不限速
+func (a DownloadService) SetSpeedLimit(ctx context.Context, id uint64, form *system.DownloadSpeedLimitForm) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+	if !isActiveDownloadStatus(task.Status) {
+		return apperrors.Wrapf(apperrors.DownloadTaskNotActive, "status: %s", task.Status)
+	}
+
+	if err := a.control(ctx, task, func(ctx context.Context, dl downloader.Downloader, handle *downloader.TaskHandle) error {
+		return dl.SetSpeedLimit(ctx, handle, form.DownloadLimit, form.UploadLimit)
+	}); err != nil {
+		return err
+	}
+
+	if err := a.downloadRepository.UpdateSpeedLimit(id, form.DownloadLimit, form.UploadLimit); err != nil {
+		return err
+	}
+	a.publishTask(id)
+	return nil
+}
+
+// control 通过下载器操作任务，优先使用队列任务中的最新 handle（如磁力链接下载元数据后切换的任务）
+func (a DownloadService) control(ctx context.Context, task *system.DownloadTask, op func(context.Context, downloader.Downloader, *downloader.TaskHandle) error) error {
+	a.mu.RLock()
+	dl, ok := a.downloaders[task.Downloader]
+	a.mu.RUnlock()
+
+	if !ok {
+		return apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", task.Downloader)
+	}
+
+	handle := &downloader.TaskHandle{
+		ID:   task.TaskID,
+		Hash: task.Hash,
+	}
+	if task.QueueTaskID > 0 && a.taskQueue.Registry != nil {
+		if qTask, ok := a.taskQueue.Registry.Get(int(task.QueueTaskID)); ok && qTask != nil {
+			if remoteTask, ok := qTask.(*queue.RemoteDownloadTask); ok && remoteTask.GetHandle() != nil {
+				handle = remoteTask.GetHandle()
+			}
+		}
+	}
+	if handle.ID == "" && handle.Hash == "" {
+		return apperrors.Wrapf(apperrors.DownloadTaskNotActive, "task %d has not been created in the downloader", task.ID)
+	}
+
+	ctx, cancel := a.downloaderContext(ctx, lib.DownloaderOpControl)
+	defer cancel()
+	return downloaderError(op(ctx, dl, handle))
+}
+
+// SetFilesToDownload 设置要下载的文件
+func (a DownloadService) SetFilesToDownload(ctx context.Context, id uint64, form *system.SetFileDownloadForm) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.downloaderContext(ctx, lib.DownloaderOpSetFiles)
+	defer cancel()
+
+	// 尝试从队列任务操作
+	if task.QueueTaskID > 0 && a.taskQueue.Registry != nil {
+		if qTask, ok := a.taskQueue.Registry.Get(int(task.QueueTaskID)); ok && qTask != nil {
+			if remoteTask, ok := qTask.(*queue.RemoteDownloadTask); ok {
+				args := make([]*downloader.SetFileToDownloadArgs, 0, len(form.Files))
+				for _, f := range form.Files {
+					args = append(args, &downloader.SetFileToDownloadArgs{
+						Index:    f.Index,
+						Download: f.Download,
+					})
+				}
+				return downloaderError(remoteTask.SetFilesToDownload(ctx, args...))
+			}
+		}
+	}
+
+	// 直接从下载器操作
+	a.mu.RLock()
+	dl, ok := a.downloaders[task.Downloader]
+	a.mu.RUnlock()
+
+	if !ok {
+		return apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", task.Downloader)
+	}
+
+	handle := &downloader.TaskHandle{
+		ID:   task.TaskID,
+		Hash: task.Hash,
+	}
+
+	args := make([]*downloader.SetFileToDownloadArgs, 0, len(form.Files))
+	for _, f := range form.Files {
+		args = append(args, &downloader.SetFileToDownloadArgs{
+			Index:    f.Index,
+			Download: f.Download,
+		})
+	}
+
+	return downloaderError(dl.SetFilesToDownload(ctx, handle, args...))
+}
+
+// Delete 删除下载任务
+func (a DownloadService) Delete(ctx context.Context, id uint64) error {
+	// 先取消下载器中的任务
+	_ = a.cancelDownloaderTask(ctx, id)
+	if err := a.downloadRepository.Delete(id); err != nil {
+		return err
+	}
+	if err := a.tagRepository.DeleteByResources(system.TagResourceDownload, []uint64{id}); err != nil {
+		return err
+	}
+	if err := a.mediaService.DeleteByTasks([]uint64{id}); err != nil {
+		return err
+	}
+	a.publishRemoved([]uint64{id})
+	return nil
+}
+
+// BatchDelete 批量删除下载任务
+func (a DownloadService) BatchDelete(ctx context.Context, ids []uint64) error {
+	// 先取消所有任务
+	for _, id := range ids {
+		_ = a.cancelDownloaderTask(ctx, id)
+	}
+	if err := a.downloadRepository.BatchDelete(ids); err != nil {
+		return err
+	}
+	if err := a.tagRepository.DeleteByResources(system.TagResourceDownload, ids); err != nil {
+		return err
+	}
+	if err := a.mediaService.DeleteByTasks(ids); err != nil {
+		return err
+	}
+	a.publishRemoved(ids)
+	return nil
+}
+
+// cancelDownloaderTask 取消下载器中的任务
+func (a DownloadService) cancelDownloaderTask(ctx context.Context, id uint64) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// 尝试从队列任务获取 handle
+	var handle *downloader.TaskHandle
+	if task.QueueTaskID > 0 {
+		state := a.getRemoteDownloadState(int(task.QueueTaskID))
+		if state != nil && state.Handle != nil {
+			handle = state.Handle
+		}
+	}
+
+	// 如果没有从队列获取到，使用数据库中的
+	if handle == nil && (task.TaskID != "" || task.Hash != "") {
+		handle = &downloader.TaskHandle{
 			ID:   task.TaskID,
 			Hash: task.Hash,
 		}
 	}
 
-	if handle == nil {
-		return nil
+	if handle == nil {
+		return nil
+	}
+
+	// 调用下载器取消
+	a.mu.RLock()
+	dl, ok := a.downloaders[task.Downloader]
+	a.mu.RUnlock()
+
+	if ok {
+		ctx, cancel := a.downloaderContext(ctx, lib.DownloaderOpCancel)
+		defer cancel()
+		if err := dl.Cancel(ctx, handle); err != nil {
+			a.logger.Zap.Warnf("Failed to cancel task in downloader: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// GetStats 获取任务统计信息
+func (a DownloadService) GetStats() (*system.DownloadTaskStatsVO, error) {
+	return a.downloadRepository.GetStatusCounts()
+}
+
+// SyncTaskStatus 同步任务状态（从下载器同步到数据库）
+func (a DownloadService) SyncTaskStatus(ctx context.Context, id uint64) error {
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return err
+	}
+	// 文件已经转移（或正在转移），下载器中的任务会被删除，保留最后同步的状态
+	if task.TransferStatus == system.DownloadTransferStatusPending || task.TransferStatus == system.DownloadTransferStatusTransferred {
+		return nil
+	}
+
+	// 尝试从队列任务获取状态（先从内存 Registry，再从数据库）
+	if task.QueueTaskID > 0 {
+		state := a.getRemoteDownloadState(int(task.QueueTaskID))
+		if state != nil && state.Status != nil {
+			var taskID, hash string
+			if state.Handle != nil {
+				taskID = state.Handle.ID
+				hash = state.Handle.Hash
+			}
+
+			if err := a.saveStatus(id, taskID, hash, state.Status); err != nil {
+				return err
+			}
+			a.publishTask(id)
+			a.onFinished(task, state.Status)
+			return nil
+		}
+	}
+
+	// 如果有 taskID，直接从下载器获取状态
+	return a.syncFromDownloader(ctx, task)
+}
+
+// saveStatus 保存从下载器同步的状态并累加所有者的流量
+// 在同一事务中锁定任务行，按库中已同步的计数计算差值，同一任务并发同步时不会重复计入
+func (a DownloadService) saveStatus(id uint64, taskID, hash string, status *downloader.TaskStatus) error {
+	return a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		downloadRepository := a.downloadRepository.WithTrx(tx)
+
+		counters, err := downloadRepository.GetCountersForUpdate(id)
+		if err != nil {
+			return err
+		}
+		if err := a.usageService.WithTrx(tx).Record(counters, status.Downloaded, status.Uploaded); err != nil {
+			return err
+		}
+
+		return downloadRepository.UpdateFromDownloader(
+			id,
+			taskID,
+			hash,
+			status.Name,
+			status.SavePath,
+			string(status.State),
+			status.Downloaded,
+			status.Total,
+			status.DownloadSpeed,
+			status.Uploaded,
+			status.UploadSpeed,
+			status.ErrorMessage,
+		)
+	})
+}
+
+// syncFromDownloader 直接从下载器查询任务状态并同步到数据库，下载器不可用或查询失败时不更新
+func (a DownloadService) syncFromDownloader(ctx context.Context, task *system.DownloadTask) error {
+	if task.TaskID == "" && task.Hash == "" {
+		return nil
+	}
+
+	a.mu.RLock()
+	dl, ok := a.downloaders[task.Downloader]
+	a.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	handle := &downloader.TaskHandle{
+		ID:   task.TaskID,
+		Hash: task.Hash,
+	}
+
+	infoCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpInfo)
+	status, err := dl.Info(infoCtx, handle)
+	cancel()
+	if err != nil {
+		return nil
+	}
+
+	if err := a.saveStatus(task.ID, handle.ID, handle.Hash, status); err != nil {
+		return err
+	}
+	a.publishTask(task.ID)
+	a.onFinished(task, status)
+	return nil
+}
+
+// watchDownloaders 接收支持推送的下载器（aria2）的任务完成、出错通知，直到 ctx 结束
+func (a DownloadService) watchDownloaders(ctx context.Context) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for name, dl := range a.downloaders {
+		watcher, ok := dl.(downloader.Watcher)
+		if !ok {
+			continue
+		}
+		if _, ok := a.managed[name]; !ok && name == "aria2" && a.config.Downloader.Aria2.DisableNotify {
+			continue
+		}
+
+		go func(name string) {
+			if err := watcher.Watch(ctx, a.onDownloaderEvent); err != nil {
+				a.logger.Zap.Warnf("Failed to watch %s notifications: %v", name, err)
+			}
+		}(name)
+	}
+}
+
+// onDownloaderEvent 下载器推送任务完成或出错时立即同步任务状态，并唤醒对应的队列任务，不必等待下一次定时查询
+func (a DownloadService) onDownloaderEvent(event downloader.Event) {
+	task, err := a.downloadRepository.GetByTaskID(event.Handle.ID)
+	if err != nil {
+		return
 	}
+	a.logger.Zap.Debugf("Download task %d reported %s by downloader", task.ID, event.State)
 
-	// 调用下载器取消
-	a.mu.RLock()
-	dl, ok := a.downloaders[task.Downloader]
-	a.mu.RUnlock()
+	a.refreshTask(context.Background(), task)
+}
 
-	if ok {
-		if err := dl.Cancel(ctx, handle); err != nil {
-			a.logger.Zap.Warnf("Failed to cancel task in downloader: %v", err)
+// refreshTask 立即从下载器同步任务状态，并唤醒对应的队列任务
+func (a DownloadService) refreshTask(ctx context.Context, task *system.DownloadTask) {
+	if err := a.syncFromDownloader(ctx, task); err != nil {
+		a.logger.Zap.Warnf("Failed to sync task %d: %v", task.ID, err)
+	}
+
+	if task.QueueTaskID > 0 && a.taskQueue.IsEnabled() {
+		if err := a.taskQueue.Queue.Wake(ctx, int(task.QueueTaskID)); err != nil && !errors.Is(err, queue.ErrTaskNotFound) {
+			a.logger.Zap.Warnf("Failed to wake queue task %d: %v", task.QueueTaskID, err)
 		}
 	}
+}
+
+// RefreshDownloaderTasks 下载器恢复可用后立即同步它的活跃任务，不必等待队列任务的下一次轮询，返回同步的任务数
+func (a DownloadService) RefreshDownloaderTasks(ctx context.Context, name string) (int, error) {
+	tasks, err := a.downloadRepository.GetActiveTasks()
+	if err != nil {
+		return 0, err
+	}
 
-	return nil
+	count := 0
+	for _, task := range tasks {
+		if task.Downloader != name {
+			continue
+		}
+		a.refreshTask(ctx, task)
+		count++
+	}
+	return count, nil
 }
 
-// GetStats 获取任务统计信息
-func (a DownloadService) GetStats() (*system.DownloadTaskStatsVO, error) {
-	return a.downloadRepository.GetStatusCounts()
+// onFinished 任务首次同步到完成（或做种）、出错状态时通知所有者，完成时提交音视频元数据提取
+// task 为同步前的任务记录
+func (a DownloadService) onFinished(task *system.DownloadTask, status *downloader.TaskStatus) {
+	if status.State == downloader.StatusError {
+		if task.Status != string(downloader.StatusError) {
+			a.notifyOwner(task.ID, system.DownloadEventFailed)
+		}
+		return
+	}
+	if status.State != downloader.StatusCompleted && status.State != downloader.StatusSeeding {
+		return
+	}
+	if task.Status == string(downloader.StatusCompleted) || task.Status == string(downloader.StatusSeeding) {
+		return
+	}
+
+	a.notifyCompleted(task)
+	a.notifyOwner(task.ID, system.DownloadEventCompleted)
+
+	// 启用文件转移时在转移后提取
+	if a.mediaService.Enabled() && !(status.State == downloader.StatusCompleted && a.transferService.Enabled()) {
+		savePath := status.SavePath
+		if savePath == "" {
+			savePath = task.SavePath
+		}
+		if _, err := a.mediaService.Schedule(task, savePath, status.Files); err != nil {
+			a.logger.Zap.Warnf("Failed to schedule media extraction for task %d: %v", task.ID, err)
+		}
+	}
 }
 
-// SyncTaskStatus 同步任务状态（从下载器同步到数据库）
-func (a DownloadService) SyncTaskStatus(ctx context.Context, id uint64) error {
+// notifyCompleted 向任务所有者推送需要回执的完成提示
+func (a DownloadService) notifyCompleted(task *system.DownloadTask) {
+	if task.OwnerID == 0 {
+		return
+	}
+
+	owner, err := a.userRepository.Get(task.OwnerID)
+	if err != nil {
+		return
+	}
+
+	title := downloadTitle(task)
+	message := map[string]interface{}{
+		"type":       system.WsEventDownloadCompleted,
+		"downloadId": task.ID,
+		"title":      title,
+		"timestamp":  time.Now().UnixMilli(),
+	}
+	if _, err := a.wsEventService.Notify(system.WsEventDownloadCompleted, title, system.Users{owner}, message, 0); err != nil {
+		a.logger.Zap.Warnf("Failed to push download completed event for task %d: %v", task.ID, err)
+	}
+}
+
+// notifyOwner 发布下载结束事件，向所有者的 /user/queue/downloads 推送任务完成或出错，按配置同时创建通知公告
+// 重新读取任务以带上同步后的名称、保存目录和错误信息；事件不论任务有无所有者都会发布，邮件、Webhook 由订阅者发送
+func (a DownloadService) notifyOwner(id uint64, eventType string) {
 	task, err := a.downloadRepository.Get(id)
 	if err != nil {
-		return err
+		return
 	}
 
-	// 尝试从队列任务获取状态（先从内存 Registry，再从数据库）
-	if task.QueueTaskID > 0 {
-		state := a.getRemoteDownloadState(int(task.QueueTaskID))
-		if state != nil && state.Status != nil {
-			var taskID, hash string
-			if state.Handle != nil {
-				taskID = state.Handle.ID
-				hash = state.Handle.Hash
-			}
+	system.EventDownloadFinished.Publish(context.Background(), a.bus, system.DownloadFinishedEvent{Type: eventType, Task: task})
+
+	if task.OwnerID == 0 {
+		return
+	}
+	owner, err := a.userRepository.Get(task.OwnerID)
+	if err != nil {
+		return
+	}
 
-			return a.downloadRepository.UpdateFromDownloader(
-				id,
-				taskID,
-				hash,
-				state.Status.Name,
-				state.Status.SavePath,
-				string(state.Status.State),
-				state.Status.Downloaded,
-				state.Status.Total,
-				state.Status.DownloadSpeed,
-				state.Status.Uploaded,
-				state.Status.UploadSpeed,
-				state.Status.ErrorMessage,
-			)
+	if a.ws.IsUserOnline(owner.Username) {
+		a.ws.Broker.SendToUser(owner.Username, ws.UserQueueDownloads, &system.DownloadTaskEvent{
+			Type:      eventType,
+			Tasks:     system.DownloadTasks{task}.ToPageVOList(),
+			Timestamp: time.Now().UnixMilli(),
+		})
+	}
+
+	if a.config.Downloader.NoticeEnabled() {
+		if err := a.noticeService.Send(downloadNotice(task, eventType), []uint64{owner.ID}); err != nil {
+			a.logger.Zap.Warnf("Failed to send download notice for task %d: %v", task.ID, err)
 		}
 	}
+}
 
-	// 如果有 taskID，直接从下载器获取状态
-	if task.TaskID != "" || task.Hash != "" {
-		a.mu.RLock()
-		dl, ok := a.downloaders[task.Downloader]
-		a.mu.RUnlock()
-
-		if ok {
-			handle := &downloader.TaskHandle{
-				ID:   task.TaskID,
-				Hash: task.Hash,
-			}
+// downloadNotice 任务完成或出错的通知公告
+func downloadNotice(task *system.DownloadTask, eventType string) *system.Notice {
+	title := downloadTitle(task)
+	if eventType == system.DownloadEventFailed {
+		return &system.Notice{
+			Title:   "下载失败：" + title,
+			Content: fmt.Sprintf("下载任务 %s 出错：%s", title, task.ErrorMessage),
+			Type:    downloadNoticeType,
+			Level:   "M",
+		}
+	}
+
+	content := fmt.Sprintf("下载任务 %s 已完成", title)
+	if task.SavePath != "" {
+		content += "，保存目录：" + task.SavePath
+	}
+	return &system.Notice{
+		Title:   "下载完成：" + title,
+		Content: content,
+		Type:    downloadNoticeType,
+		Level:   "L",
+	}
+}
+
+// downloadTitle 任务名称，尚未从下载器同步名称时使用下载地址
+func downloadTitle(task *system.DownloadTask) string {
+	if task.Name != "" {
+		return task.Name
+	}
+	return task.URL
+}
+
+// snapshot 订阅 /topic/downloads 时推送的快照：统计信息与全部活跃任务
+func (a DownloadService) snapshot(session *stomp.Session, destination string) (interface{}, bool) {
+	stats, err := a.downloadRepository.GetStatusCounts()
+	if err != nil {
+		a.logger.Zap.Warnf("Failed to load download stats for snapshot: %v", err)
+		return nil, false
+	}
+	tasks, err := a.downloadRepository.GetActiveTasks()
+	if err != nil {
+		a.logger.Zap.Warnf("Failed to load active downloads for snapshot: %v", err)
+		return nil, false
+	}
+
+	return &system.DownloadTaskEvent{
+		Type:      system.DownloadEventSnapshot,
+		Stats:     stats,
+		Tasks:     tasks.ToPageVOList(),
+		Timestamp: time.Now().UnixMilli(),
+	}, true
+}
+
+// publishTask 重新读取任务并推送变化
+func (a DownloadService) publishTask(id uint64) {
+	if !a.ws.Broker.HasSubscribers(ws.TopicDownloads) {
+		return
+	}
+	task, err := a.downloadRepository.Get(id)
+	if err != nil {
+		return
+	}
+	a.publishUpdated(task)
+}
+
+// publishUpdated 推送单个任务的变化
+func (a DownloadService) publishUpdated(task *system.DownloadTask) {
+	a.ws.Broker.Publish(ws.TopicDownloads, &system.DownloadTaskEvent{
+		Type:      system.DownloadEventUpdated,
+		Tasks:     system.DownloadTasks{task}.ToPageVOList(),
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
+
+// publishRemoved 推送任务删除
+func (a DownloadService) publishRemoved(ids []uint64) {
+	a.ws.Broker.Publish(ws.TopicDownloads, &system.DownloadTaskEvent{
+		Type:      system.DownloadEventRemoved,
+		IDs:       ids,
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
+
+// PublishProgress 向在线的任务所有者推送活跃任务的实时进度与速度
+// 运行中的任务使用队列中的最新状态，状态发生变化时先同步到数据库
+func (a DownloadService) PublishProgress(ctx context.Context) {
+	if a.ws.Broker.GetOnlineUserCount() == 0 {
+		return
+	}
+	tasks, err := a.downloadRepository.GetActiveTasks()
+	if err != nil {
+		a.logger.Zap.Warnf("Failed to load active downloads for progress: %v", err)
+		return
+	}
 
-			status, err := dl.Info(ctx, handle)
-			if err == nil {
-				return a.downloadRepository.UpdateFromDownloader(
-					id,
-					handle.ID,
-					handle.Hash,
-					status.Name,
-					status.SavePath,
-					string(status.State),
-					status.Downloaded,
-					status.Total,
-					status.DownloadSpeed,
-					status.Uploaded,
-					status.UploadSpeed,
-					status.ErrorMessage,
-				)
+	for _, task := range tasks {
+		status := a.liveStatus(task.QueueTaskID)
+		if status == nil {
+			continue
+		}
+		if string(status.State) != task.Status {
+			if err := a.SyncTaskStatus(ctx, task.ID); err != nil {
+				a.logger.Zap.Warnf("Failed to sync task %d: %v", task.ID, err)
 			}
 		}
+
+		task.Status = string(status.State)
+		task.Total = status.Total
+		task.Downloaded = status.Downloaded
+		task.DownloadSpeed = status.DownloadSpeed
+		task.Uploaded = status.Uploaded
+		task.UploadSpeed = status.UploadSpeed
+		task.ErrorMessage = status.ErrorMessage
+		if status.Name != "" {
+			task.Name = status.Name
+		}
+	}
+
+	a.sendProgress(tasks)
+}
+
+// SyncQueueTask 队列任务结束后同步对应下载任务的最终状态，并推送给所有者
+func (a DownloadService) SyncQueueTask(ctx context.Context, queueTaskID uint64) error {
+	task, err := a.downloadRepository.GetByQueueTaskID(queueTaskID)
+	if err != nil {
+		return err
+	}
+	if err := a.SyncTaskStatus(ctx, task.ID); err != nil {
+		return err
+	}
+
+	task, err = a.downloadRepository.Get(task.ID)
+	if err != nil {
+		return err
 	}
+	if task, err = a.syncQueueError(task); err != nil {
+		return err
+	}
+	a.sendProgress(system.DownloadTasks{task})
 
+	if a.transferService.Enabled() && task.Status == string(downloader.StatusCompleted) && task.TransferStatus == "" {
+		a.scheduleTransfer(task)
+	}
 	return nil
 }
 
+// syncQueueError 队列任务出错结束、下载器没有报告结束状态时（如创建下载任务失败），
+// 将下载任务标记为出错并通知所有者，返回更新后的任务
+func (a DownloadService) syncQueueError(task *system.DownloadTask) (*system.DownloadTask, error) {
+	switch task.Status {
+	case string(downloader.StatusCompleted), string(downloader.StatusSeeding), string(downloader.StatusError):
+		return task, nil
+	}
+	model := a.getQueueTaskModel(int(task.QueueTaskID))
+	if model == nil || model.Status != queue.StatusError {
+		return task, nil
+	}
+
+	if err := a.downloadRepository.UpdateStatus(task.ID, string(downloader.StatusError),
+		task.Downloaded, task.Total, 0, task.Uploaded, 0, model.PublicState.Error); err != nil {
+		return nil, err
+	}
+	a.publishTask(task.ID)
+	a.notifyOwner(task.ID, system.DownloadEventFailed)
+
+	return a.downloadRepository.Get(task.ID)
+}
+
+// scheduleTransfer 提交已完成任务的文件转移，转移成功后删除下载器中的任务
+func (a DownloadService) scheduleTransfer(task *system.DownloadTask) {
+	savePath := task.SavePath
+	var files []downloader.TaskFile
+	if state := a.getRemoteDownloadState(int(task.QueueTaskID)); state != nil && state.Status != nil {
+		files = state.Status.Files
+		if state.Status.SavePath != "" {
+			savePath = state.Status.SavePath
+		}
+	}
+
+	id := task.ID
+	done := func(ctx context.Context, transferred bool) {
+		if transferred {
+			a.mu.RLock()
+			dl, ok := a.downloaders[task.Downloader]
+			a.mu.RUnlock()
+			if ok && (task.TaskID != "" || task.Hash != "") {
+				cancelCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpCancel)
+				if err := dl.Cancel(cancelCtx, &downloader.TaskHandle{ID: task.TaskID, Hash: task.Hash}); err != nil {
+					a.logger.Zap.Warnf("Failed to remove transferred task %d from downloader: %v", id, err)
+				}
+				cancel()
+			}
+		}
+		a.publishTask(id)
+	}
+
+	if _, err := a.transferService.Schedule(task, savePath, a.tempPath(task.Downloader), files, done); err != nil {
+		a.logger.Zap.Warnf("Failed to schedule file transfer for task %d: %v", id, err)
+	}
+}
+
+// liveStatus 当前实例中运行的下载任务的最新状态，任务不在当前实例时返回 nil
+func (a DownloadService) liveStatus(queueTaskID uint64) *downloader.TaskStatus {
+	if queueTaskID == 0 || !a.taskQueue.IsEnabled() {
+		return nil
+	}
+	qTask, ok := a.taskQueue.Registry.Get(int(queueTaskID))
+	if !ok {
+		return nil
+	}
+	remoteTask, ok := qTask.(*queue.RemoteDownloadTask)
+	if !ok {
+		return nil
+	}
+	return remoteTask.GetState().Status
+}
+
+// sendProgress 按所有者分组推送进度，不在线的用户跳过
+func (a DownloadService) sendProgress(tasks system.DownloadTasks) {
+	owners := make(map[uint64]system.DownloadTasks)
+	for _, task := range tasks {
+		if task.OwnerID == 0 {
+			continue
+		}
+		owners[task.OwnerID] = append(owners[task.OwnerID], task)
+	}
+
+	now := time.Now().UnixMilli()
+	for ownerID, list := range owners {
+		owner, err := a.userRepository.Get(ownerID)
+		if err != nil || !a.ws.IsUserOnline(owner.Username) {
+			continue
+		}
+		a.ws.Broker.SendToUser(owner.Username, ws.UserQueueDownloads, &system.DownloadTaskEvent{
+			Type:      system.DownloadEventProgress,
+			Tasks:     list.ToPageVOList(),
+			Timestamp: now,
+		})
+	}
+}
+
 // getRemoteDownloadState 获取远程下载任务状态（从 Registry 或数据库）
 func (a DownloadService) getRemoteDownloadState(queueTaskID int) *queue.RemoteDownloadTaskState {
 	// 先尝试从 Registry 获取（任务还在运行中）
@@ -502,9 +1958,9 @@ func (a DownloadService) getRemoteDownloadState(queueTaskID int) *queue.RemoteDo
 		}
 	}
 
-	// 从数据库获取队列任务的 PrivateState
-	var taskModel queue.TaskModel
-	if err := a.db.ORM.First(&taskModel, queueTaskID).Error; err == nil && taskModel.PrivateState != "" {
+	// 从任务仓库（数据库或 Redis）获取队列任务的 PrivateState
+	taskModel := a.getQueueTaskModel(queueTaskID)
+	if taskModel != nil && taskModel.PrivateState != "" {
 		state := &queue.RemoteDownloadTaskState{}
 		if err := json.Unmarshal([]byte(taskModel.PrivateState), state); err == nil {
 			return state
@@ -514,6 +1970,26 @@ func (a DownloadService) getRemoteDownloadState(queueTaskID int) *queue.RemoteDo
 	return nil
 }
 
+// getQueueTaskModel 从任务仓库（数据库或 Redis）获取队列任务记录，不存在时返回 nil
+func (a DownloadService) getQueueTaskModel(queueTaskID int) *queue.TaskModel {
+	if queueTaskID <= 0 {
+		return nil
+	}
+	if a.taskQueue.Repository != nil {
+		model, err := a.taskQueue.Repository.GetByID(context.Background(), uint64(queueTaskID))
+		if err != nil {
+			return nil
+		}
+		return model
+	}
+
+	taskModel := new(queue.TaskModel)
+	if err := a.db.ORM.First(taskModel, queueTaskID).Error; err != nil {
+		return nil
+	}
+	return taskModel
+}
+
 // SyncAllActiveTasks 同步所有活跃任务的状态
 func (a DownloadService) SyncAllActiveTasks(ctx context.Context) error {
 	tasks, err := a.downloadRepository.GetActiveTaskIDs()
@@ -542,6 +2018,10 @@ func (a DownloadService) GetAvailableDownloaders() []map[string]string {
 			label = "Aria2"
 		} else if name == "qbittorrent" {
 			label = "qBittorrent"
+		} else if name == "sabnzbd" {
+			label = "SABnzbd"
+		} else if name == "native" {
+			label = "Native"
 		}
 		result = append(result, map[string]string{
 			"label": label,
@@ -551,6 +2031,19 @@ func (a DownloadService) GetAvailableDownloaders() []map[string]string {
 	return result
 }
 
+// DownloaderNames 已初始化的下载器名称，按名称排序
+func (a DownloadService) DownloaderNames() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.downloaders))
+	for name := range a.downloaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // TestDownloader 测试下载器连接
 func (a DownloadService) TestDownloader(ctx context.Context, name string) (string, error) {
 	a.mu.RLock()
@@ -561,7 +2054,174 @@ func (a DownloadService) TestDownloader(ctx context.Context, name string) (strin
 		return "", apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", name)
 	}
 
-	return dl.Test(ctx)
+	ctx, cancel := a.downloaderContext(ctx, lib.DownloaderOpTest)
+	defer cancel()
+
+	version, err := dl.Test(ctx)
+	return version, downloaderError(err)
+}
+
+// sessionManager 支持会话的下载器
+func (a DownloadService) sessionManager(name string) (downloader.SessionManager, error) {
+	a.mu.RLock()
+	dl, ok := a.downloaders[name]
+	a.mu.RUnlock()
+
+	if !ok {
+		return nil, apperrors.Wrapf(apperrors.DownloadDownloaderNotFound, "downloader: %s", name)
+	}
+	sm, ok := dl.(downloader.SessionManager)
+	if !ok {
+		return nil, apperrors.Wrapf(apperrors.DownloadSessionUnsupported, "downloader: %s", name)
+	}
+
+	return sm, nil
+}
+
+// GetSession 查询下载器当前会话
+func (a DownloadService) GetSession(ctx context.Context, name string) (*system.DownloadSessionVO, error) {
+	sm, err := a.sessionManager(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.downloaderContext(ctx, lib.DownloaderOpSession)
+	defer cancel()
+
+	info, err := sm.SessionInfo(ctx)
+	if err != nil {
+		return nil, downloaderError(err)
+	}
+
+	return &system.DownloadSessionVO{Downloader: name, ID: info.ID, File: info.File}, nil
+}
+
+// SaveSession 保存下载器会话，下载器重启后可以从会话文件恢复任务
+func (a DownloadService) SaveSession(ctx context.Context, name string) (*system.DownloadSessionVO, error) {
+	sm, err := a.sessionManager(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := a.downloaderContext(ctx, lib.DownloaderOpSession)
+	defer cancel()
+
+	info, err := sm.SaveSession(ctx)
+	if err != nil {
+		return nil, downloaderError(err)
+	}
+
+	a.logger.Zap.Infof("Downloader %s session saved: id=%s, file=%s", name, info.ID, info.File)
+	return &system.DownloadSessionVO{Downloader: name, ID: info.ID, File: info.File}, nil
+}
+
+// ReconcileSession 对比下载器中的任务与下载任务表：
+// 下载器中存在、任务表中缺失的任务（如下载器重启后从会话文件恢复、但任务表已回滚）导入任务表，所有者为操作人，之后按任务ID同步状态；
+// 任务表中仍处于活跃状态、下载器中已不存在的任务只报告，由管理员决定取消或重新创建
+func (a DownloadService) ReconcileSession(ctx context.Context, name string, form *system.DownloadSessionReconcileForm, operatorID uint64) (*system.DownloadSessionReconcileVO, error) {
+	sm, err := a.sessionManager(name)
+	if err != nil {
+		return nil, err
+	}
+
+	listCtx, cancel := a.downloaderContext(ctx, lib.DownloaderOpSession)
+	sessionTasks, err := sm.ListTasks(listCtx)
+	cancel()
+	if err != nil {
+		return nil, downloaderError(err)
+	}
+
+	tasks, err := a.downloadRepository.ListByDownloader(name)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(tasks)*2)
+	for _, task := range tasks {
+		if task.TaskID != "" {
+			known["id:"+task.TaskID] = true
+		}
+		if task.Hash != "" {
+			known["hash:"+strings.ToLower(task.Hash)] = true
+		}
+	}
+
+	result := &system.DownloadSessionReconcileVO{
+		Downloader: name,
+		DryRun:     form.DryRun,
+		Imported:   make([]*system.DownloadSessionTaskVO, 0),
+		Missing:    make(system.DownloadTasks, 0),
+	}
+	present := make(map[string]bool, len(sessionTasks)*2)
+	for _, st := range sessionTasks {
+		present["id:"+st.Handle.ID] = true
+		if st.Following != "" {
+			present["id:"+st.Following] = true
+		}
+		if st.Handle.Hash != "" {
+			present["hash:"+strings.ToLower(st.Handle.Hash)] = true
+		}
+
+		if known["id:"+st.Handle.ID] || (st.Following != "" && known["id:"+st.Following]) ||
+			(st.Handle.Hash != "" && known["hash:"+strings.ToLower(st.Handle.Hash)]) {
+			result.Matched++
+			continue
+		}
+
+		vo := &system.DownloadSessionTaskVO{
+			TaskID:     st.Handle.ID,
+			Hash:       st.Handle.Hash,
+			Name:       st.Name,
+			URL:        st.URL,
+			Status:     string(st.State),
+			Total:      st.Total,
+			Downloaded: st.Downloaded,
+		}
+		result.Imported = append(result.Imported, vo)
+		if form.DryRun {
+			continue
+		}
+
+		task := &system.DownloadTask{
+			TaskID:     st.Handle.ID,
+			Hash:       st.Handle.Hash,
+			Name:       str.S(st.Name).Truncate(500),
+			URL:        st.URL,
+			Downloader: name,
+			Status:     string(st.State),
+			Total:      st.Total,
+			Downloaded: st.Downloaded,
+			SavePath:   str.S(st.SavePath).Truncate(500),
+			OwnerID:    operatorID,
+		}
+		if task.Name == "" {
+			task.Name = st.Handle.ID
+		}
+		if err := a.downloadRepository.Create(task); err != nil {
+			return nil, err
+		}
+		vo.ID = task.ID
+		a.publishUpdated(task)
+	}
+
+	for _, task := range tasks {
+		if task.TaskID == "" || !isActiveDownloadStatus(task.Status) {
+			continue
+		}
+		if present["id:"+task.TaskID] || (task.Hash != "" && present["hash:"+strings.ToLower(task.Hash)]) {
+			continue
+		}
+		result.Missing = append(result.Missing, task)
+	}
+
+	a.logger.Zap.Infof("Downloader %s session reconciled: matched=%d, imported=%d, missing=%d, dryRun=%t",
+		name, result.Matched, len(result.Imported), len(result.Missing), form.DryRun)
+	return result, nil
+}
+
+// isActiveDownloadStatus 任务是否仍应存在于下载器中
+func isActiveDownloadStatus(status string) bool {
+	return status == string(downloader.StatusDownloading) || status == string(downloader.StatusSeeding) ||
+		status == string(downloader.StatusPaused)
 }
 
 // GetQueueStats 获取队列统计信息
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	apperrors "github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/file"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// DownloadTransferTaskType 下载文件转移队列任务类型
+const DownloadTransferTaskType = "download_transfer"
+
+// DownloadTransferService 下载完成后把文件从下载器的临时目录转移到配置的目录或文件服务，
+// 在任务记录中保存转移结果，并清理临时目录
+type DownloadTransferService struct {
+	logger             lib.Logger
+	config             lib.Config
+	taskQueue          lib.TaskQueue
+	downloadRepository repository.DownloadRepository
+	mediaService       DownloadMediaService
+	fileService        platformservice.FileService
+}
+
+// NewDownloadTransferService creates a new download transfer service
+func NewDownloadTransferService(
+	logger lib.Logger,
+	config lib.Config,
+	taskQueue lib.TaskQueue,
+	downloadRepository repository.DownloadRepository,
+	mediaService DownloadMediaService,
+	fileService platformservice.FileService,
+) DownloadTransferService {
+	return DownloadTransferService{
+		logger:             logger,
+		config:             config,
+		taskQueue:          taskQueue,
+		downloadRepository: downloadRepository,
+		mediaService:       mediaService,
+		fileService:        fileService,
+	}
+}
+
+// Enabled 是否启用下载完成后的文件转移
+func (a DownloadTransferService) Enabled() bool {
+	return a.config.Downloader.TransferEnabled()
+}
+
+// Schedule 提交已完成任务的文件转移，启用队列时通过队列执行，否则在后台协程中执行
+// savePath 为下载器报告的保存目录，files 为下载器报告的文件列表，只转移选择下载的文件，为空时转移保存目录中的全部文件；
+// done 在保存转移结果后调用，transferred 表示文件已经转移，此后清理任务的保存目录（task.SavePath），
+// 只有该目录位于下载器临时目录 tempPath 下各下载器的任务目录中时才会删除
+// 任务已经提交过转移时不重复提交，返回 false
+func (a DownloadTransferService) Schedule(task *system.DownloadTask, savePath, tempPath string, files []downloader.TaskFile, done func(ctx context.Context, transferred bool)) (bool, error) {
+	if !a.Enabled() {
+		return false, nil
+	}
+	if savePath == "" {
+		return false, fmt.Errorf("download task %d has no save path", task.ID)
+	}
+
+	ok, err := a.downloadRepository.MarkTransferPending(task.ID)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	taskCopy := *task
+	run := func(ctx context.Context) error {
+		err := a.transfer(ctx, &taskCopy, savePath, files)
+		if done != nil {
+			done(ctx, err == nil)
+		}
+		if err == nil {
+			a.cleanup(taskCopy.SavePath, tempPath)
+		}
+		return err
+	}
+
+	if a.taskQueue.IsEnabled() {
+		queueTask := queue.NewFuncTask(DownloadTransferTaskType, &queue.TaskOwner{ID: task.OwnerID}, run)
+		if err := a.taskQueue.QueueTask(context.Background(), queueTask); err != nil {
+			return false, apperrors.Wrap(err, "failed to queue transfer task")
+		}
+	} else {
+		go func() {
+			_ = run(context.Background())
+		}()
+	}
+
+	a.logger.Zap.Infof("File transfer scheduled for download task %d from %s", task.ID, savePath)
+	return true, nil
+}
+
+// transfer 转移文件并保存结果，失败时记录原因，文件保留在临时目录中
+// 重试时跳过已经移动到目标目录的文件
+func (a DownloadTransferService) transfer(ctx context.Context, task *system.DownloadTask, savePath string, files []downloader.TaskFile) error {
+	conf := a.config.Downloader.Transfer
+
+	var (
+		dst      string
+		uploaded []system.DownloadTransferFile
+	)
+	rels, err := transferFiles(savePath, files)
+	if err == nil {
+		switch conf.GetTarget() {
+		case lib.DownloadTransferTargetDir:
+			dst, err = a.moveFiles(ctx, task, savePath, rels, files)
+		case lib.DownloadTransferTargetOSS:
+			uploaded, err = a.uploadFiles(ctx, task, savePath, rels, files)
+		default:
+			err = fmt.Errorf("unknown transfer target: %q", conf.Target)
+		}
+	}
+
+	if err != nil {
+		a.logger.Zap.Warnf("Failed to transfer files of download task %d: %v", task.ID, err)
+		if uerr := a.downloadRepository.UpdateTransfer(task.ID, system.DownloadTransferStatusFailed, "", nil, err.Error()); uerr != nil {
+			a.logger.Zap.Warnf("Failed to save transfer result of download task %d: %v", task.ID, uerr)
+		}
+		return err
+	}
+
+	var filesJSON []byte
+	if uploaded != nil {
+		if filesJSON, err = json.Marshal(uploaded); err != nil {
+			return err
+		}
+	}
+	if err := a.downloadRepository.UpdateTransfer(task.ID, system.DownloadTransferStatusTransferred, dst, filesJSON, ""); err != nil {
+		return err
+	}
+
+	a.logger.Zap.Infof("Transferred %d files of download task %d", len(rels), task.ID)
+	return nil
+}
+
+// moveFiles 移动到目标目录下以任务 ID 命名的目录，保留相对路径，返回该目录
+// 移动后在新的位置提取音视频元数据
+func (a DownloadTransferService) moveFiles(ctx context.Context, task *system.DownloadTask, savePath string, rels []string, files []downloader.TaskFile) (string, error) {
+	dir := a.config.Downloader.Transfer.Dir
+	if dir == "" {
+		return "", fmt.Errorf("transfer dir is not configured")
+	}
+	dst, err := filepath.Abs(filepath.Join(dir, strconv.FormatUint(task.ID, 10)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		src, err := savePathJoin(savePath, rel)
+		if err != nil {
+			return "", err
+		}
+		target, err := savePathJoin(dst, rel)
+		if err != nil {
+			return "", err
+		}
+		if !file.IsExist(src) && file.IsExist(target) {
+			continue
+		}
+		if err := file.Move(src, target); err != nil {
+			return "", fmt.Errorf("failed to move %s: %w", rel, err)
+		}
+	}
+
+	if a.needsMedia(task.ID) {
+		if _, err := a.mediaService.Schedule(task, dst, files); err != nil {
+			a.logger.Zap.Warnf("Failed to schedule media extraction for task %d: %v", task.ID, err)
+		}
+	}
+
+	return dst, nil
+}
+
+// uploadFiles 逐个上传到文件服务，本地文件在上传后随临时目录一起清理，因此先提取音视频元数据
+func (a DownloadTransferService) uploadFiles(ctx context.Context, task *system.DownloadTask, savePath string, rels []string, files []downloader.TaskFile) ([]system.DownloadTransferFile, error) {
+	if a.needsMedia(task.ID) {
+		paths := mediaFiles(files, a.config.Downloader.Media.GetMaxFiles())
+		if len(paths) > 0 {
+			if err := a.mediaService.extract(ctx, task.ID, savePath, paths); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	uploaded := make([]system.DownloadTransferFile, 0, len(rels))
+	for _, rel := range rels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		src, err := savePathJoin(savePath, rel)
+		if err != nil {
+			return nil, err
+		}
+		info, err := a.uploadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload %s: %w", rel, err)
+		}
+		uploaded = append(uploaded, system.DownloadTransferFile{Path: rel, URL: info})
+	}
+
+	return uploaded, nil
+}
+
+// uploadFile 上传单个文件，返回文件地址
+func (a DownloadTransferService) uploadFile(src string) (string, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	info, err := a.fileService.UploadFile(filepath.Base(src), f, stat.Size(), mime.TypeByExtension(filepath.Ext(src)))
+	if err != nil {
+		return "", err
+	}
+	return info.URL, nil
+}
+
+// needsMedia 启用音视频元数据提取且任务还没有提取结果（如做种时已经提取过）
+func (a DownloadTransferService) needsMedia(taskID uint64) bool {
+	if !a.mediaService.Enabled() {
+		return false
+	}
+	list, err := a.mediaService.ListByTask(taskID)
+	return err == nil && len(list) == 0
+}
+
+// cleanup 删除任务的保存目录，目录不在下载器临时目录下的任务目录中时（如导入的任务、
+// 下载器把任务移动到了分类或默认保存目录）跳过，避免删除其他任务的文件
+func (a DownloadTransferService) cleanup(savePath, tempPath string) {
+	if savePath == "" {
+		return
+	}
+	dir, err := filepath.Abs(filepath.FromSlash(savePath))
+	if err != nil || !inDownloadTempFolder(dir, tempPath) {
+		a.logger.Zap.Warnf("Skip deleting download folder %s outside the downloader temp folder", savePath)
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		a.logger.Zap.Warnf("Failed to delete download folder %s: %v", dir, err)
+	}
+}
+
+// inDownloadTempFolder dir 是否位于 tempPath 下某个下载器任务目录之中（不含任务目录本身）
+func inDownloadTempFolder(dir, tempPath string) bool {
+	if tempPath == "" {
+		return false
+	}
+	base, err := filepath.Abs(tempPath)
+	if err != nil {
+		return false
+	}
+	for _, folder := range downloadTempFolders {
+		rel, err := filepath.Rel(filepath.Join(base, folder), dir)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// transferFiles 需要转移的文件（相对于保存目录），下载器报告了文件列表时只取选择下载的文件，
+// 否则遍历保存目录
+func transferFiles(savePath string, files []downloader.TaskFile) ([]string, error) {
+	rels := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.Selected {
+			rels = append(rels, f.Name)
+		}
+	}
+	if len(files) > 0 {
+		return rels, nil
+	}
+
+	root := filepath.FromSlash(savePath)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			rels = append(rels, path.Clean(filepath.ToSlash(rel)))
+		}
+		return nil
+	})
+	return rels, err
+}
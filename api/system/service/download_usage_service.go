@@ -0,0 +1,198 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+const (
+	usageDayLayout = "2006-01-02"
+	// usageDefaultDays 未指定日期范围时统计最近 30 天
+	usageDefaultDays = 30
+	megabyte         = int64(1024 * 1024)
+)
+
+// DownloadUsageService 下载流量统计与配额
+type DownloadUsageService struct {
+	logger                  lib.Logger
+	config                  lib.Config
+	downloadUsageRepository repository.DownloadUsageRepository
+}
+
+// NewDownloadUsageService creates a new download usage service
+func NewDownloadUsageService(
+	logger lib.Logger,
+	config lib.Config,
+	downloadUsageRepository repository.DownloadUsageRepository,
+) DownloadUsageService {
+	return DownloadUsageService{
+		logger:                  logger,
+		config:                  config,
+		downloadUsageRepository: downloadUsageRepository,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a DownloadUsageService) WithTrx(trxHandle *gorm.DB) DownloadUsageService {
+	a.downloadUsageRepository = a.downloadUsageRepository.WithTrx(trxHandle)
+	return a
+}
+
+// Record 按同步前后的下载量、上传量之差累加任务所有者当天的流量
+// task 的计数需要在同一事务中锁定任务行后读取，否则并发同步会重复计入
+// 下载器重置计数（如任务重新添加）时差值为负，不计入
+func (a DownloadUsageService) Record(task *system.DownloadTask, downloaded, uploaded int64) error {
+	deltaDown := downloaded - task.Downloaded
+	deltaUp := uploaded - task.Uploaded
+	if deltaDown < 0 {
+		deltaDown = 0
+	}
+	if deltaUp < 0 {
+		deltaUp = 0
+	}
+	if deltaDown == 0 && deltaUp == 0 {
+		return nil
+	}
+
+	day := time.Now().Format(usageDayLayout)
+	return a.downloadUsageRepository.Accumulate(day, task.OwnerID, deltaDown, deltaUp)
+}
+
+// Me 用户在日期范围内的每日流量、合计与配额
+func (a DownloadUsageService) Me(userID uint64, param *system.DownloadUsageQueryParam) (*system.DownloadUsageMeVO, error) {
+	from, to := usageRange(param.From, param.To)
+
+	days, err := a.downloadUsageRepository.Daily(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	vo := &system.DownloadUsageMeVO{From: from, To: to, Days: days}
+	for _, d := range days {
+		vo.Total.Downloaded += d.Downloaded
+		vo.Total.Uploaded += d.Uploaded
+	}
+
+	quota, err := a.Quota(userID)
+	if err != nil {
+		return nil, err
+	}
+	vo.Quota = *quota
+
+	return vo, nil
+}
+
+// Breakdown 按用户或部门汇总日期范围内的流量
+func (a DownloadUsageService) Breakdown(param *system.DownloadUsageQueryParam) (interface{}, error) {
+	param.From, param.To = usageRange(param.From, param.To)
+	if param.Limit <= 0 || param.Limit > 100 {
+		param.Limit = 20
+	}
+
+	if param.GroupBy == "dept" {
+		return a.downloadUsageRepository.ByDept(param)
+	}
+	return a.downloadUsageRepository.ByUser(param)
+}
+
+// Quota 用户当天、当月的配额使用情况
+func (a DownloadUsageService) Quota(userID uint64) (*system.DownloadQuotaVO, error) {
+	vo := &system.DownloadQuotaVO{}
+
+	cfg := a.quotaConfig()
+	if cfg == nil {
+		return vo, nil
+	}
+
+	daily := cfg.Daily
+	if v, ok := cfg.UserOverrides[userID]; ok {
+		daily = v
+	}
+	vo.DailyLimit = daily * megabyte
+	vo.MonthlyLimit = cfg.Monthly * megabyte
+	if vo.DailyLimit <= 0 && vo.MonthlyLimit <= 0 {
+		return vo, nil
+	}
+
+	now := time.Now()
+	today := now.Format(usageDayLayout)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format(usageDayLayout)
+
+	var err error
+	if vo.DailyLimit > 0 {
+		if vo.DailyUsed, err = a.used(cfg, userID, today, today); err != nil {
+			return nil, err
+		}
+	}
+	if vo.MonthlyLimit > 0 {
+		if vo.MonthlyUsed, err = a.used(cfg, userID, monthStart, today); err != nil {
+			return nil, err
+		}
+	}
+
+	vo.Exceeded = (vo.DailyLimit > 0 && vo.DailyUsed >= vo.DailyLimit) ||
+		(vo.MonthlyLimit > 0 && vo.MonthlyUsed >= vo.MonthlyLimit)
+	return vo, nil
+}
+
+// CheckQuota 用户已超出配额时返回 DownloadQuotaExceeded
+func (a DownloadUsageService) CheckQuota(userID uint64) error {
+	quota, err := a.Quota(userID)
+	if err != nil {
+		return err
+	}
+
+	return QuotaError(quota)
+}
+
+// QuotaError 配额已超出时返回 DownloadQuotaExceeded，说明超出的是当天还是当月配额
+func QuotaError(quota *system.DownloadQuotaVO) error {
+	if !quota.Exceeded {
+		return nil
+	}
+
+	if quota.DailyLimit > 0 && quota.DailyUsed >= quota.DailyLimit {
+		return errors.Wrapf(errors.DownloadQuotaExceeded, "daily limit %d MB", quota.DailyLimit/megabyte)
+	}
+	return errors.Wrapf(errors.DownloadQuotaExceeded, "monthly limit %d MB", quota.MonthlyLimit/megabyte)
+}
+
+func (a DownloadUsageService) quotaConfig() *lib.DownloadQuotaConfig {
+	if a.config.Downloader == nil {
+		return nil
+	}
+	return a.config.Downloader.Quota
+}
+
+// used 计入配额的流量
+func (a DownloadUsageService) used(cfg *lib.DownloadQuotaConfig, userID uint64, from, to string) (int64, error) {
+	downloaded, uploaded, err := a.downloadUsageRepository.Sum(userID, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	if cfg.CountUpload {
+		return downloaded + uploaded, nil
+	}
+	return downloaded, nil
+}
+
+// usageRange 校验日期范围，缺省或格式错误时使用最近 30 天
+func usageRange(from, to string) (string, string) {
+	now := time.Now()
+
+	if _, err := time.Parse(usageDayLayout, to); err != nil {
+		to = now.Format(usageDayLayout)
+	}
+	if _, err := time.Parse(usageDayLayout, from); err != nil {
+		from = now.AddDate(0, 0, 1-usageDefaultDays).Format(usageDayLayout)
+	}
+
+	return from, to
+}
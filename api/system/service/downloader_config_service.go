@@ -0,0 +1,286 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/secret"
+)
+
+// downloaderConfigValidate 控制器绑定的是表单指针，validate 标签不会生效，在服务中校验
+var downloaderConfigValidate = validator.New()
+
+// DownloaderConfigService 运行时管理下载器连接配置，保存后重新加载下载服务中的下载器
+// 配置保存在数据库中，其他实例在重启后生效；密钥与密码使用 Downloader.EncryptKey 加密保存
+type DownloaderConfigService struct {
+	logger                     lib.Logger
+	box                        *secret.Box
+	downloaderConfigRepository repository.DownloaderConfigRepository
+	downloadRepository         repository.DownloadRepository
+	downloadService            DownloadService
+}
+
+// NewDownloaderConfigService creates a new downloader config service
+func NewDownloaderConfigService(
+	logger lib.Logger,
+	config lib.Config,
+	downloaderConfigRepository repository.DownloaderConfigRepository,
+	downloadRepository repository.DownloadRepository,
+	downloadService DownloadService,
+) DownloaderConfigService {
+	box, err := newDownloaderSecretBox(config)
+	if err != nil {
+		logger.Zap.Fatalf("Error to create downloader secret box: %v", err)
+	}
+
+	return DownloaderConfigService{
+		logger:                     logger,
+		box:                        box,
+		downloaderConfigRepository: downloaderConfigRepository,
+		downloadRepository:         downloadRepository,
+		downloadService:            downloadService,
+	}
+}
+
+// List 全部下载器配置，附带密钥是否已设置以及当前实例是否已加载
+func (a DownloaderConfigService) List() (system.DownloaderConfigs, error) {
+	list, err := a.downloaderConfigRepository.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conf := range list {
+		fillDownloaderConfig(conf)
+		conf.Loaded = a.downloadService.IsManagedDownloader(conf.Name)
+	}
+
+	return list, nil
+}
+
+// Create 新增下载器
+func (a DownloaderConfigService) Create(form *system.DownloaderConfigForm, createBy uint64) (*system.DownloaderConfig, error) {
+	if err := validateDownloaderConfigForm(form); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(form.Name)
+	if err := a.checkName(name, 0); err != nil {
+		return nil, err
+	}
+
+	conf := &system.DownloaderConfig{
+		Name:     name,
+		CreateBy: createBy,
+	}
+	if err := a.applySecrets(conf, form); err != nil {
+		return nil, err
+	}
+	if err := applyDownloaderConfigForm(conf, form); err != nil {
+		return nil, err
+	}
+	if err := a.downloaderConfigRepository.Create(conf); err != nil {
+		return nil, err
+	}
+
+	a.reload()
+	return a.get(conf.ID)
+}
+
+// Update 修改下载器，有活跃任务时不能修改名称与类型
+func (a DownloaderConfigService) Update(id uint64, form *system.DownloaderConfigForm, updateBy uint64) (*system.DownloaderConfig, error) {
+	if err := validateDownloaderConfigForm(form); err != nil {
+		return nil, err
+	}
+
+	conf, err := a.downloaderConfigRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(form.Name)
+	if name != conf.Name {
+		if err := a.checkName(name, id); err != nil {
+			return nil, err
+		}
+	}
+	if name != conf.Name || form.Type != conf.Type {
+		if err := a.checkNotInUse(conf.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	conf.Name = name
+	conf.UpdateBy = updateBy
+	if err := a.applySecrets(conf, form); err != nil {
+		return nil, err
+	}
+	if err := applyDownloaderConfigForm(conf, form); err != nil {
+		return nil, err
+	}
+	if err := a.downloaderConfigRepository.Update(id, conf); err != nil {
+		return nil, err
+	}
+
+	a.reload()
+	return a.get(id)
+}
+
+// Delete 删除下载器，有活跃任务时拒绝
+func (a DownloaderConfigService) Delete(id uint64) error {
+	conf, err := a.downloaderConfigRepository.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := a.checkNotInUse(conf.Name); err != nil {
+		return err
+	}
+
+	if err := a.downloaderConfigRepository.Delete(id); err != nil {
+		return err
+	}
+
+	a.reload()
+	return nil
+}
+
+func (a DownloaderConfigService) get(id uint64) (*system.DownloaderConfig, error) {
+	conf, err := a.downloaderConfigRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fillDownloaderConfig(conf)
+	conf.Loaded = a.downloadService.IsManagedDownloader(conf.Name)
+	return conf, nil
+}
+
+// checkName 名称不能与其他配置或配置文件中的下载器重复
+func (a DownloaderConfigService) checkName(name string, id uint64) error {
+	if exist, err := a.downloaderConfigRepository.GetByName(name); err != nil {
+		return err
+	} else if exist != nil && exist.ID != id {
+		return errors.DownloaderConfigNameExists
+	}
+
+	if a.downloadService.HasDownloader(name) && !a.downloadService.IsManagedDownloader(name) {
+		return errors.DownloaderConfigNameExists
+	}
+
+	return nil
+}
+
+// checkNotInUse 下载器没有活跃任务
+func (a DownloaderConfigService) checkNotInUse(name string) error {
+	count, err := a.downloadRepository.CountActiveByDownloader(name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.Wrapf(errors.DownloaderConfigInUse, "downloader %s has %d active tasks", name, count)
+	}
+
+	return nil
+}
+
+// applySecrets 加密表单中非空的密钥与密码，未配置 Downloader.EncryptKey 时拒绝保存
+func (a DownloaderConfigService) applySecrets(conf *system.DownloaderConfig, form *system.DownloaderConfigForm) error {
+	for _, field := range []struct {
+		value  string
+		target *string
+	}{
+		{form.Token, &conf.Token},
+		{form.Password, &conf.Password},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if a.box == nil {
+			return errors.DownloaderConfigNoKey
+		}
+
+		encrypted, err := a.box.Encrypt(field.value)
+		if err != nil {
+			return err
+		}
+		*field.target = encrypted
+	}
+
+	return nil
+}
+
+// reload 配置已经保存，重新加载失败时只记录日志，重启后生效
+func (a DownloaderConfigService) reload() {
+	if err := a.downloadService.ReloadDownloaders(); err != nil {
+		a.logger.Zap.Errorf("Failed to reload downloaders: %v", err)
+	}
+}
+
+// validateDownloaderConfigForm 校验表单字段，aria2 的 RPC 地址可以是 ws/wss
+func validateDownloaderConfigForm(form *system.DownloaderConfigForm) error {
+	if err := downloaderConfigValidate.Struct(form); err != nil {
+		return errors.Wrap(errors.DownloaderConfigInvalid, err.Error())
+	}
+	if strings.TrimSpace(form.Name) == "" {
+		return errors.Wrap(errors.DownloaderConfigInvalid, "name is required")
+	}
+
+	u, err := url.Parse(strings.TrimSpace(form.Server))
+	if err != nil || u.Host == "" {
+		return errors.Wrap(errors.DownloaderConfigInvalid, "server must be an absolute url")
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "ws", "wss":
+		if form.Type != system.DownloaderTypeAria2 {
+			return errors.Wrapf(errors.DownloaderConfigInvalid, "%s server must use http or https", form.Type)
+		}
+	default:
+		return errors.Wrapf(errors.DownloaderConfigInvalid, "unsupported server scheme %q", u.Scheme)
+	}
+
+	return nil
+}
+
+// newDownloaderSecretBox 按 Downloader.EncryptKey 创建加密下载器密钥的 Box，未配置时返回 nil
+func newDownloaderSecretBox(config lib.Config) (*secret.Box, error) {
+	if config.Downloader == nil || config.Downloader.EncryptKey == "" {
+		return nil, nil
+	}
+
+	return secret.NewBox(config.Downloader.EncryptKey)
+}
+
+// applyDownloaderConfigForm 将表单中除名称与密钥外的字段写入配置
+func applyDownloaderConfigForm(conf *system.DownloaderConfig, form *system.DownloaderConfigForm) error {
+	conf.Type = form.Type
+	conf.Server = strings.TrimSpace(form.Server)
+	conf.User = form.User
+	conf.TempPath = form.TempPath
+	conf.Category = form.Category
+	conf.Remark = form.Remark
+
+	conf.Options = nil
+	if len(form.Options) > 0 {
+		options, err := json.Marshal(form.Options)
+		if err != nil {
+			return err
+		}
+		conf.Options = database.JSONB(options)
+	}
+
+	return nil
+}
+
+// fillDownloaderConfig 标记密钥是否已设置
+func fillDownloaderConfig(conf *system.DownloaderConfig) {
+	conf.HasToken = conf.Token != ""
+	conf.HasPassword = conf.Password != ""
+}
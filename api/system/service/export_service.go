@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/report"
+)
+
+// 导出数据来源，与导出接口一一对应
+const (
+	ExportSourceUser         = "users"
+	ExportSourceNotice       = "notices"
+	ExportSourceDictItem     = "dict_items"
+	ExportSourceDownloadTask = "downloads"
+	ExportSourceLog          = "logs"
+)
+
+const (
+	// 分批读取的每页数量
+	exportPageSize = 500
+	// 默认单次导出的最大行数
+	defaultExportMaxRows = 100000
+)
+
+// ExportService 列表数据导出服务
+// 导出以队列任务执行（未启用任务队列时在后台协程中执行），文件通过文件服务保存，完成后向发起人推送下载地址
+type ExportService struct {
+	logger             lib.Logger
+	config             lib.Config
+	taskQueue          lib.TaskQueue
+	fileService        platformservice.FileService
+	wsEventService     WsEventService
+	userRepository     repository.UserRepository
+	deptRepository     repository.DeptRepository
+	noticeRepository   repository.NoticeRepository
+	dictItemRepository repository.DictItemRepository
+	downloadRepository repository.DownloadRepository
+	logRepository      repository.LogRepository
+	registry           *queue.ExportRegistry
+}
+
+// NewExportService creates a new export service
+func NewExportService(
+	logger lib.Logger,
+	config lib.Config,
+	taskQueue lib.TaskQueue,
+	fileService platformservice.FileService,
+	wsEventService WsEventService,
+	userRepository repository.UserRepository,
+	deptRepository repository.DeptRepository,
+	noticeRepository repository.NoticeRepository,
+	dictItemRepository repository.DictItemRepository,
+	downloadRepository repository.DownloadRepository,
+	logRepository repository.LogRepository,
+) ExportService {
+	a := ExportService{
+		logger:             logger,
+		config:             config,
+		taskQueue:          taskQueue,
+		fileService:        fileService,
+		wsEventService:     wsEventService,
+		userRepository:     userRepository,
+		deptRepository:     deptRepository,
+		noticeRepository:   noticeRepository,
+		dictItemRepository: dictItemRepository,
+		downloadRepository: downloadRepository,
+		logRepository:      logRepository,
+	}
+
+	a.registry = queue.NewExportRegistry(a.store, a.notify, a.maxRows())
+	a.registry.Register(ExportSourceUser, a.userSource())
+	a.registry.Register(ExportSourceNotice, a.noticeSource())
+	a.registry.Register(ExportSourceDictItem, a.dictItemSource())
+	a.registry.Register(ExportSourceDownloadTask, a.downloadSource())
+	a.registry.Register(ExportSourceLog, a.logSource())
+
+	// 从任务存储恢复或由其他实例分发的导出任务使用同一份数据来源
+	queue.RegisterResumableTaskFactory(queue.ExportTaskType, queue.NewExportTaskFactory(a.registry))
+
+	return a
+}
+
+// Submit 提交导出任务，param 为列表查询参数（已合并保存的查询与数据权限），返回队列任务 ID
+// 未启用任务队列时在后台协程中导出，返回的任务 ID 为 0
+func (a ExportService) Submit(owner *queue.TaskOwner, source, format string, param interface{}) (int, error) {
+	format = strings.ToLower(format)
+	if format == "" {
+		format = report.FormatXLSX
+	}
+	if format != report.FormatXLSX && format != report.FormatCSV {
+		return 0, errors.ExportFormatInvalid
+	}
+
+	params, err := json.Marshal(param)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to marshal export params")
+	}
+
+	task, err := queue.NewExportTask(a.registry, queue.ExportQuery{Source: source, Params: params}, format, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	if a.taskQueue.IsEnabled() {
+		if err := a.taskQueue.QueueTask(context.Background(), task); err != nil {
+			return 0, errors.Wrap(err, "failed to queue export task")
+		}
+		return task.ID(), nil
+	}
+
+	go func() {
+		exportTask := task.(*queue.ExportTask)
+		if _, err := exportTask.Do(context.Background()); err != nil {
+			a.logger.Zap.Errorf("Failed to export %s: %v", source, err)
+			a.notify(owner, 0, nil, err)
+			return
+		}
+		a.notify(owner, 0, exportTask.Result(), nil)
+	}()
+
+	return 0, nil
+}
+
+func (a ExportService) maxRows() int64 {
+	if a.config.Export != nil && a.config.Export.MaxRows > 0 {
+		return a.config.Export.MaxRows
+	}
+	return defaultExportMaxRows
+}
+
+// store 通过文件服务保存导出文件
+func (a ExportService) store(_ context.Context, name string, r io.Reader, size int64, contentType string) (string, error) {
+	info, err := a.fileService.UploadFile(name, r, size, contentType)
+	if err != nil {
+		return "", err
+	}
+	return info.URL, nil
+}
+
+// notify 向发起人推送需要回执的导出结果，失败时带上错误信息
+func (a ExportService) notify(owner *queue.TaskOwner, taskID int, result *queue.ExportResult, err error) {
+	if owner == nil {
+		return
+	}
+
+	user := &system.User{ID: owner.ID, Username: owner.Username}
+	if user.Username == "" {
+		if owner.ID == 0 {
+			return
+		}
+		u, err := a.userRepository.Get(owner.ID)
+		if err != nil {
+			a.logger.Zap.Warnf("Failed to get owner %d of export task %d: %v", owner.ID, taskID, err)
+			return
+		}
+		user = u
+	}
+
+	title := "数据导出失败"
+	message := map[string]interface{}{
+		"type":      system.WsEventExportCompleted,
+		"taskId":    taskID,
+		"timestamp": time.Now().UnixMilli(),
+	}
+	if err != nil {
+		message["error"] = err.Error()
+	} else if result != nil {
+		title = "数据导出完成：" + result.Name
+		message["name"] = result.Name
+		message["url"] = result.URL
+		message["rows"] = result.Rows
+	}
+	message["title"] = title
+
+	if _, err := a.wsEventService.Notify(system.WsEventExportCompleted, title, system.Users{user}, message, 0); err != nil {
+		a.logger.Zap.Warnf("Failed to push export event for task %d: %v", taskID, err)
+	}
+}
+
+// exportSource 分页读取列表数据的导出来源，query 解码查询参数并返回按页读取的函数
+type exportSource struct {
+	title   string
+	columns []string
+	query   func(params json.RawMessage) (exportPager, error)
+}
+
+// exportPager 读取一页数据，返回格式化后的行以及是否还有下一页
+type exportPager func(pp dto.PaginationParam) ([][]string, bool, error)
+
+func (s exportSource) Title() string {
+	return s.title
+}
+
+func (s exportSource) Columns() []string {
+	return s.columns
+}
+
+func (s exportSource) Rows(ctx context.Context, params json.RawMessage, fn func(rows [][]string) error) error {
+	pager, err := s.query(params)
+	if err != nil {
+		return err
+	}
+
+	withTotal := false
+	for pageNum := 1; ; pageNum++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows, hasNext, err := pager(dto.PaginationParam{PageNum: pageNum, PageSize: exportPageSize, WithTotal: &withTotal})
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := fn(rows); err != nil {
+				return err
+			}
+		}
+		if !hasNext {
+			return nil
+		}
+	}
+}
+
+// decodeExportParams 解码导出任务保存的查询参数
+func decodeExportParams(params json.RawMessage, out interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return json.Unmarshal(params, out)
+}
+
+func (a ExportService) userSource() exportSource {
+	return exportSource{
+		title:   "用户",
+		columns: []string{"用户名", "昵称", "部门", "手机号", "邮箱", "状态", "创建时间"},
+		query: func(params json.RawMessage) (exportPager, error) {
+			param := new(system.UserQueryParam)
+			if err := decodeExportParams(params, param); err != nil {
+				return nil, err
+			}
+			param.QueryPassword = false
+
+			return func(pp dto.PaginationParam) ([][]string, bool, error) {
+				param.PaginationParam = pp
+				qr, err := a.userRepository.Query(param)
+				if err != nil {
+					return nil, false, err
+				}
+
+				deptIDs := make([]uint64, 0)
+				for _, user := range qr.List {
+					if user.DeptID > 0 {
+						deptIDs = append(deptIDs, user.DeptID)
+					}
+				}
+				deptMap := make(map[uint64]*system.Dept)
+				if len(deptIDs) > 0 {
+					if deptMap, err = a.deptRepository.GetByIDs(deptIDs); err != nil {
+						return nil, false, err
+					}
+				}
+
+				rows := make([][]string, 0, len(qr.List))
+				for _, user := range qr.List {
+					var deptName string
+					if dept, ok := deptMap[user.DeptID]; ok {
+						deptName = dept.Name
+					}
+					rows = append(rows, []string{user.Username, user.Nickname, deptName, user.Mobile, user.Email,
+						complianceStatus(user.Status), formatExportTime(user.CreateTime.Time())})
+				}
+				return rows, qr.Pagination.HasNext, nil
+			}, nil
+		},
+	}
+}
+
+func (a ExportService) noticeSource() exportSource {
+	return exportSource{
+		title:   "通知公告",
+		columns: []string{"标题", "类型", "等级", "发布状态", "发布时间", "创建时间"},
+		query: func(params json.RawMessage) (exportPager, error) {
+			param := new(system.NoticeQueryParam)
+			if err := decodeExportParams(params, param); err != nil {
+				return nil, err
+			}
+
+			return func(pp dto.PaginationParam) ([][]string, bool, error) {
+				param.PaginationParam = pp
+				qr, err := a.noticeRepository.Query(param)
+				if err != nil {
+					return nil, false, err
+				}
+
+				rows := make([][]string, 0, len(qr.List))
+				for _, notice := range qr.List {
+					var publishTime string
+					if notice.PublishTime.Valid {
+						publishTime = formatExportTime(notice.PublishTime.Time)
+					}
+					rows = append(rows, []string{notice.Title, strconv.Itoa(notice.Type), notice.Level,
+						noticePublishStatus(notice.PublishStatus), publishTime, formatExportTime(notice.CreateTime.Time())})
+				}
+				return rows, qr.Pagination.HasNext, nil
+			}, nil
+		},
+	}
+}
+
+func (a ExportService) dictItemSource() exportSource {
+	return exportSource{
+		title:   "字典项",
+		columns: []string{"字典编码", "标签", "值", "标签类型", "排序", "状态", "备注"},
+		query: func(params json.RawMessage) (exportPager, error) {
+			param := new(system.DictItemQueryParam)
+			if err := decodeExportParams(params, param); err != nil {
+				return nil, err
+			}
+
+			return func(pp dto.PaginationParam) ([][]string, bool, error) {
+				param.PaginationParam = pp
+				qr, err := a.dictItemRepository.Query(param)
+				if err != nil {
+					return nil, false, err
+				}
+
+				rows := make([][]string, 0, len(qr.List))
+				for _, item := range qr.List {
+					rows = append(rows, []string{item.DictCode, item.Label, item.Value, item.TagType,
+						strconv.Itoa(item.Sort), complianceStatus(item.Status), item.Remark})
+				}
+				return rows, qr.Pagination.HasNext, nil
+			}, nil
+		},
+	}
+}
+
+func (a ExportService) downloadSource() exportSource {
+	return exportSource{
+		title:   "下载任务",
+		columns: []string{"名称", "地址", "下载器", "状态", "大小（字节）", "已下载（字节）", "保存目录", "错误信息", "创建时间"},
+		query: func(params json.RawMessage) (exportPager, error) {
+			param := new(system.DownloadTaskQueryParam)
+			if err := decodeExportParams(params, param); err != nil {
+				return nil, err
+			}
+
+			return func(pp dto.PaginationParam) ([][]string, bool, error) {
+				param.PaginationParam = pp
+				qr, err := a.downloadRepository.Query(param)
+				if err != nil {
+					return nil, false, err
+				}
+
+				rows := make([][]string, 0, len(qr.List))
+				for _, task := range qr.List {
+					rows = append(rows, []string{task.Name, task.URL, task.Downloader, task.Status,
+						strconv.FormatInt(task.Total, 10), strconv.FormatInt(task.Downloaded, 10),
+						task.SavePath, task.ErrorMessage, formatExportTime(task.CreatedAt)})
+				}
+				return rows, qr.Pagination.HasNext, nil
+			}, nil
+		},
+	}
+}
+
+func (a ExportService) logSource() exportSource {
+	return exportSource{
+		title:   "操作日志",
+		columns: []string{"时间", "操作人", "模块", "内容", "请求方法", "请求地址", "IP", "地区", "耗时（毫秒）", "浏览器", "操作系统"},
+		query: func(params json.RawMessage) (exportPager, error) {
+			param := new(system.LogQueryParam)
+			if err := decodeExportParams(params, param); err != nil {
+				return nil, err
+			}
+
+			return func(pp dto.PaginationParam) ([][]string, bool, error) {
+				param.PaginationParam = pp
+				qr, err := a.logRepository.Query(param)
+				if err != nil {
+					return nil, false, err
+				}
+
+				userIDs := make([]uint64, 0)
+				for _, log := range qr.List {
+					if log.CreateBy > 0 {
+						userIDs = append(userIDs, log.CreateBy)
+					}
+				}
+				usernames := make(map[uint64]string)
+				if len(userIDs) > 0 {
+					users, err := a.userRepository.GetByIDs(userIDs)
+					if err != nil {
+						return nil, false, err
+					}
+					for _, user := range users {
+						usernames[user.ID] = user.Username
+					}
+				}
+
+				rows := make([][]string, 0, len(qr.List))
+				for _, log := range qr.List {
+					rows = append(rows, []string{formatExportTime(log.CreateTime.Time()), usernames[log.CreateBy],
+						log.Module, log.Content, log.RequestMethod, log.RequestURI, log.IP,
+						strings.TrimSpace(log.Province + " " + log.City), strconv.FormatInt(log.ExecutionTime, 10),
+						strings.TrimSpace(log.Browser + " " + log.BrowserVersion), log.OS})
+				}
+				return rows, qr.Pagination.HasNext, nil
+			}, nil
+		},
+	}
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dto.DateTimeFormat)
+}
+
+func noticePublishStatus(status int) string {
+	switch status {
+	case 1:
+		return "已发布"
+	case -1:
+		return "已撤回"
+	default:
+		return "未发布"
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+)
+
+const (
+	// 启用状态保存在系统配置中，键名为 module.<name>.enabled，值为 true/false
+	featureModuleConfigKeyPrefix = "module."
+	featureModuleConfigKeySuffix = ".enabled"
+
+	// 定时从系统配置重新加载，使多实例部署中的其他实例同步启用状态
+	featureModuleReloadTaskName = "feature_module_reload"
+	featureModuleReloadSpec     = "@every 60s"
+)
+
+// FeatureModuleService 功能模块启停服务
+// 小型部署可以禁用不需要的功能模块：接口返回 404、菜单接口不再返回对应菜单、后台任务停止，无需重新编译
+type FeatureModuleService struct {
+	logger           lib.Logger
+	featureModules   lib.FeatureModules
+	configRepository repository.ConfigRepository
+	permissionCache  PermissionCache
+	responseCache    lib.ResponseCache
+}
+
+// NewFeatureModuleService 创建功能模块启停服务，加载系统配置中保存的启用状态
+func NewFeatureModuleService(
+	logger lib.Logger,
+	crontab lib.Crontab,
+	featureModules lib.FeatureModules,
+	configRepository repository.ConfigRepository,
+	permissionCache PermissionCache,
+	responseCache lib.ResponseCache,
+) FeatureModuleService {
+	svc := FeatureModuleService{
+		logger:           logger,
+		featureModules:   featureModules,
+		configRepository: configRepository,
+		permissionCache:  permissionCache,
+		responseCache:    responseCache,
+	}
+
+	if err := svc.Reload(); err != nil {
+		logger.Zap.Errorf("Failed to load feature module settings: %v", err)
+	}
+
+	if crontab.IsEnabled() {
+		if err := crontab.AddTask(featureModuleReloadTaskName, featureModuleReloadSpec, svc.runReload); err != nil {
+			logger.Zap.Errorf("Failed to register feature module reload task: %v", err)
+		}
+	}
+
+	return svc
+}
+
+func featureModuleConfigKey(name string) string {
+	return featureModuleConfigKeyPrefix + name + featureModuleConfigKeySuffix
+}
+
+func (a FeatureModuleService) runReload(ctx context.Context) {
+	if err := a.Reload(); err != nil {
+		a.logger.Zap.Errorf("Failed to reload feature module settings: %v", err)
+		crontab.SetError(ctx, err)
+	}
+}
+
+// Reload 从系统配置加载各模块的启用状态，没有配置的模块保持启用
+func (a FeatureModuleService) Reload() error {
+	for _, module := range a.featureModules.List() {
+		config, err := a.configRepository.GetByKey(featureModuleConfigKey(module.Name))
+		if err != nil {
+			if errors.Is(err, errors.DatabaseRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		enabled, err := strconv.ParseBool(config.ConfigValue)
+		if err != nil {
+			a.logger.Zap.Warnf("Invalid feature module setting %s=%s", config.ConfigKey, config.ConfigValue)
+			continue
+		}
+
+		if err := a.apply(module.Name, enabled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List 全部功能模块及启用状态
+func (a FeatureModuleService) List() []lib.FeatureModule {
+	return a.featureModules.List()
+}
+
+// SetEnabled 启用或禁用功能模块，保存到系统配置后立即在当前实例生效
+func (a FeatureModuleService) SetEnabled(name string, enabled bool, operator uint64) (*lib.FeatureModule, error) {
+	module, ok := a.featureModules.Get(name)
+	if !ok {
+		return nil, errors.FeatureModuleNotFound
+	}
+
+	key := featureModuleConfigKey(name)
+	existing, err := a.configRepository.GetByKey(key)
+	if err != nil && !errors.Is(err, errors.DatabaseRecordNotFound) {
+		return nil, err
+	}
+	if existing == nil {
+		// 键名上有唯一索引，复用已删除的记录
+		if existing, err = a.configRepository.Revive(key); err != nil {
+			return nil, err
+		}
+	}
+
+	config := &system.Config{
+		ConfigName:  "功能模块：" + module.Title,
+		ConfigKey:   key,
+		ConfigValue: strconv.FormatBool(enabled),
+		Remark:      "由功能模块启停接口维护",
+		CreateBy:    operator,
+		UpdateBy:    operator,
+	}
+	if existing == nil {
+		err = a.configRepository.Create(config)
+	} else {
+		err = a.configRepository.Update(existing.ID, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.apply(name, enabled); err != nil {
+		return nil, err
+	}
+
+	module.Enabled = enabled
+	return &module, nil
+}
+
+// apply 更新注册表中的启用状态，状态变化时使菜单路由缓存失效
+func (a FeatureModuleService) apply(name string, enabled bool) error {
+	if a.featureModules.IsEnabled(name) == enabled {
+		return nil
+	}
+
+	if err := a.featureModules.SetEnabled(name, enabled); err != nil {
+		return err
+	}
+
+	a.logger.Zap.Infof("Feature module %s enabled=%v", name, enabled)
+	a.permissionCache.InvalidateRoutesCache()
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// jwtKey 非对称签名密钥
+type jwtKey struct {
+	kid        string
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// jwtKeySet 非对称签名密钥集合
+// signing 为当前签名密钥，verify 包含所有可用于验证的密钥（按 kid 索引）
+type jwtKeySet struct {
+	method  jwt.SigningMethod
+	signing *jwtKey
+	verify  map[string]*jwtKey
+	order   []string
+}
+
+// loadJwtKeySet 根据配置加载 RS256/ES256 密钥
+func loadJwtKeySet(method jwt.SigningMethod, configs []*lib.JwtKeyConfig) (*jwtKeySet, error) {
+	set := &jwtKeySet{
+		method: method,
+		verify: make(map[string]*jwtKey),
+	}
+
+	for i, cfg := range configs {
+		if cfg == nil {
+			continue
+		}
+
+		key := &jwtKey{kid: cfg.KID}
+		if key.kid == "" {
+			key.kid = fmt.Sprintf("key-%d", i+1)
+		}
+
+		if _, ok := set.verify[key.kid]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id: %s", key.kid)
+		}
+
+		if cfg.PrivateKeyFile != "" {
+			pem, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read jwt private key %s: %w", key.kid, err)
+			}
+
+			if key.privateKey, err = parsePrivateKey(method, pem); err != nil {
+				return nil, fmt.Errorf("failed to parse jwt private key %s: %w", key.kid, err)
+			}
+			key.publicKey = key.privateKey.Public()
+		}
+
+		if cfg.PublicKeyFile != "" {
+			pem, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read jwt public key %s: %w", key.kid, err)
+			}
+
+			if key.publicKey, err = parsePublicKey(method, pem); err != nil {
+				return nil, fmt.Errorf("failed to parse jwt public key %s: %w", key.kid, err)
+			}
+		}
+
+		if key.publicKey == nil {
+			return nil, fmt.Errorf("jwt key %s has neither private nor public key", key.kid)
+		}
+
+		if set.signing == nil && key.privateKey != nil {
+			set.signing = key
+		}
+
+		set.verify[key.kid] = key
+		set.order = append(set.order, key.kid)
+	}
+
+	if set.signing == nil {
+		return nil, fmt.Errorf("no jwt signing key configured for %s", method.Alg())
+	}
+
+	return set, nil
+}
+
+func parsePrivateKey(method jwt.SigningMethod, pem []byte) (crypto.Signer, error) {
+	if _, ok := method.(*jwt.SigningMethodECDSA); ok {
+		return jwt.ParseECPrivateKeyFromPEM(pem)
+	}
+
+	return jwt.ParseRSAPrivateKeyFromPEM(pem)
+}
+
+func parsePublicKey(method jwt.SigningMethod, pem []byte) (crypto.PublicKey, error) {
+	if _, ok := method.(*jwt.SigningMethodECDSA); ok {
+		return jwt.ParseECPublicKeyFromPEM(pem)
+	}
+
+	return jwt.ParseRSAPublicKeyFromPEM(pem)
+}
+
+// keyfunc 根据令牌头中的 kid 选择验证密钥，未携带 kid 时使用当前签名密钥
+func (s *jwtKeySet) keyfunc(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() != s.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %s", t.Method.Alg())
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return s.signing.publicKey, nil
+	}
+
+	key, ok := s.verify[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	return key.publicKey, nil
+}
+
+// JWKS 导出所有验证公钥
+func (s *jwtKeySet) JWKS() *dto.JWKS {
+	jwks := &dto.JWKS{Keys: make([]dto.JWK, 0, len(s.order))}
+	for _, kid := range s.order {
+		key := s.verify[kid]
+		jwk := dto.JWK{
+			Kid: kid,
+			Use: "sig",
+			Alg: s.method.Alg(),
+		}
+
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = curveName(pub.Curve)
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+func curveName(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return "P-256"
+	case elliptic.P384():
+		return "P-384"
+	case elliptic.P521():
+		return "P-521"
+	default:
+		return curve.Params().Name
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/uuid"
+)
+
+const (
+	// LockDefaultTTL 未指定持有时长时的默认值，到期自动释放，避免进程异常退出后锁无法释放
+	LockDefaultTTL = 10 * time.Minute
+	// LockMaxTTL 持有时长上限
+	LockMaxTTL = 24 * time.Hour
+
+	lockKeyPrefix = "lock:"
+	lockIndexKey  = "lock:index" // 锁名 -> 持有者信息，用于列出当前持有的锁
+)
+
+var lockNamePattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,100}$`)
+
+// LockService 基于缓存的资源锁，多实例共用 Redis 缓存时跨实例生效
+// 用于菜单导入、权限同步、配置备份与恢复等不能并发执行的操作，并发获取时返回持有者信息
+type LockService struct {
+	logger         lib.Logger
+	cache          lib.Cache
+	userRepository repository.UserRepository
+}
+
+// NewLockService creates a new lock service
+func NewLockService(logger lib.Logger, cache lib.Cache, userRepository repository.UserRepository) LockService {
+	return LockService{
+		logger:         logger,
+		cache:          cache,
+		userRepository: userRepository,
+	}
+}
+
+// Acquire 获取锁，已被持有时返回 LockHeld 及持有者信息；ownerID 为 0 表示系统任务
+func (a LockService) Acquire(name string, ownerID uint64, reason string, ttl time.Duration) (*system.Lock, error) {
+	if !lockNamePattern.MatchString(name) {
+		return nil, errors.Wrapf(errors.LockInvalidName, "name: %s", name)
+	}
+	if ttl <= 0 {
+		ttl = LockDefaultTTL
+	} else if ttl > LockMaxTTL {
+		ttl = LockMaxTTL
+	}
+
+	now := time.Now()
+	lock := &system.Lock{
+		Name:       name,
+		OwnerID:    ownerID,
+		Owner:      a.ownerName(ownerID),
+		Reason:     reason,
+		AcquiredAt: now.UnixMilli(),
+		ExpiresAt:  now.Add(ttl).UnixMilli(),
+		Token:      uuid.MustString(),
+	}
+
+	ok, err := a.cache.SetNX(lockKeyPrefix+name, lock.Token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, a.heldError(name)
+	}
+
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.cache.HSet(lockIndexKey, name, string(data)); err != nil {
+		a.logger.Zap.Warnf("Failed to index lock %s: %v", name, err)
+	}
+
+	return lock, nil
+}
+
+// heldError 锁已被持有的错误，包含持有者与获取时间
+func (a LockService) heldError(name string) error {
+	holder, err := a.Get(name)
+	if err != nil || holder.Owner == "" {
+		return errors.Wrapf(errors.LockHeld, "%s", name)
+	}
+
+	return errors.Wrapf(errors.LockHeld, "%s by %s since %s",
+		name, holder.Owner, time.UnixMilli(holder.AcquiredAt).Format("2006-01-02 15:04:05"))
+}
+
+// Release 释放自己持有的锁，锁已过期或已被他人重新获取时返回错误
+func (a LockService) Release(name, token string) error {
+	ok, err := a.cache.CompareAndDelete(lockKeyPrefix+name, token)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if held, _ := a.cache.Check(lockKeyPrefix + name); held {
+			return errors.Wrapf(errors.LockNotOwner, "%s", name)
+		}
+		return errors.Wrapf(errors.LockNotFound, "%s", name)
+	}
+
+	if err := a.cache.HDel(lockIndexKey, name); err != nil {
+		a.logger.Zap.Warnf("Failed to remove lock index %s: %v", name, err)
+	}
+	return nil
+}
+
+// ForceRelease 强制释放锁，用于持有者异常退出后提前解除
+func (a LockService) ForceRelease(name string) error {
+	deleted, err := a.cache.Delete(lockKeyPrefix + name)
+	if err != nil {
+		return err
+	}
+	if err := a.cache.HDel(lockIndexKey, name); err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.Wrapf(errors.LockNotFound, "%s", name)
+	}
+
+	a.logger.Zap.Infof("Lock %s force released", name)
+	return nil
+}
+
+// WithLock 持有锁执行 fn，执行结束后释放；锁已被持有时不执行 fn
+func (a LockService) WithLock(name string, ownerID uint64, reason string, fn func() error) error {
+	lock, err := a.Acquire(name, ownerID, reason, LockDefaultTTL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := a.Release(name, lock.Token); err != nil {
+			a.logger.Zap.Warnf("Failed to release lock %s: %v", name, err)
+		}
+	}()
+
+	return fn()
+}
+
+// Get 查询锁的持有者，未被持有时返回 LockNotFound
+func (a LockService) Get(name string) (*system.Lock, error) {
+	held, err := a.cache.Check(lockKeyPrefix + name)
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		return nil, errors.Wrapf(errors.LockNotFound, "%s", name)
+	}
+
+	var data string
+	if err := a.cache.HGet(lockIndexKey, name, &data); err != nil || data == "" {
+		return &system.Lock{Name: name}, nil
+	}
+
+	lock := new(system.Lock)
+	if err := json.Unmarshal([]byte(data), lock); err != nil {
+		return &system.Lock{Name: name}, nil
+	}
+	return lock, nil
+}
+
+// List 当前持有的全部锁，顺带清理已过期的索引
+func (a LockService) List() ([]*system.Lock, error) {
+	index, err := a.cache.HGetAll(lockIndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*system.Lock, 0, len(index))
+	for name, data := range index {
+		held, err := a.cache.Check(lockKeyPrefix + name)
+		if err != nil {
+			return nil, err
+		}
+		if !held {
+			_ = a.cache.HDel(lockIndexKey, name)
+			continue
+		}
+
+		lock := &system.Lock{Name: name}
+		_ = json.Unmarshal([]byte(data), lock)
+		list = append(list, lock)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// ownerName 持有者用户名，系统任务为 system
+func (a LockService) ownerName(ownerID uint64) string {
+	if ownerID == 0 {
+		return "system"
+	}
+
+	user, err := a.userRepository.Get(ownerID)
+	if err != nil {
+		return ""
+	}
+	return user.Username
+}
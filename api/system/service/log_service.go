@@ -6,21 +6,25 @@ import (
 	"github.com/top-system/light-admin/api/system/repository"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/logship"
 )
 
 // LogService service layer
 type LogService struct {
 	logger        lib.Logger
+	logShipper    lib.LogShipper
 	logRepository repository.LogRepository
 }
 
 // NewLogService creates a new log service
 func NewLogService(
 	logger lib.Logger,
+	logShipper lib.LogShipper,
 	logRepository repository.LogRepository,
 ) LogService {
 	return LogService{
 		logger:        logger,
+		logShipper:    logShipper,
 		logRepository: logRepository,
 	}
 }
@@ -41,9 +45,28 @@ func (a LogService) Get(id uint64) (*system.Log, error) {
 	return a.logRepository.Get(id)
 }
 
-// Create 创建日志
+// Create 创建日志，启用日志转发时同时转发到 SIEM
 func (a LogService) Create(log *system.Log) error {
-	return a.logRepository.Create(log)
+	if err := a.logRepository.Create(log); err != nil {
+		return err
+	}
+
+	ShipOperationLog(a.logShipper, log)
+	return nil
+}
+
+// ShipOperationLog 转发操作日志，未开启 IncludePayload 时去掉请求参数和响应内容
+func ShipOperationLog(shipper lib.LogShipper, log *system.Log) {
+	if !shipper.IsEnabled() {
+		return
+	}
+
+	entry := *log
+	if !shipper.IncludePayload() {
+		entry.RequestParams = ""
+		entry.ResponseContent = ""
+	}
+	shipper.Ship(lib.LogShipOperation, logship.SeverityInfo, &entry)
 }
 
 // Delete 删除日志
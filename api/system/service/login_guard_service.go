@@ -0,0 +1,200 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mssola/useragent"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+const (
+	loginLockoutDefaultMaxFailures   = 5
+	loginLockoutDefaultIPMaxFailures = 20
+	loginLockoutDefaultWindow        = 15 * time.Minute
+	loginLockoutDefaultLockDuration  = 15 * time.Minute
+)
+
+// LoginGuardService 登录防暴力破解与登录审计
+// 失败计数与锁定标记保存在缓存中，每次登录尝试记录到登录日志
+type LoginGuardService struct {
+	cache              lib.Cache
+	config             lib.Config
+	logger             lib.Logger
+	loginLogRepository repository.LoginLogRepository
+}
+
+// NewLoginGuardService creates a new login guard service
+func NewLoginGuardService(
+	cache lib.Cache,
+	config lib.Config,
+	logger lib.Logger,
+	loginLogRepository repository.LoginLogRepository,
+) LoginGuardService {
+	return LoginGuardService{
+		cache:              cache,
+		config:             config,
+		logger:             logger,
+		loginLogRepository: loginLogRepository,
+	}
+}
+
+func wrapperLoginFailureKey(username string) string {
+	return fmt.Sprintf("login:failures:user:%s", username)
+}
+
+func wrapperLoginLockKey(username string) string {
+	return fmt.Sprintf("login:lock:user:%s", username)
+}
+
+func wrapperLoginIPFailureKey(ip string) string {
+	return fmt.Sprintf("login:failures:ip:%s", ip)
+}
+
+func wrapperLoginIPLockKey(ip string) string {
+	return fmt.Sprintf("login:lock:ip:%s", ip)
+}
+
+// lockout 登录失败锁定配置，未启用时返回 nil
+func (a LoginGuardService) lockout() *lib.LoginLockoutConfig {
+	if c := a.config.Auth.Lockout; c != nil && c.Enable {
+		return c
+	}
+	return nil
+}
+
+func (a LoginGuardService) maxFailures(c *lib.LoginLockoutConfig) int64 {
+	if c.MaxFailures > 0 {
+		return int64(c.MaxFailures)
+	}
+	return loginLockoutDefaultMaxFailures
+}
+
+func (a LoginGuardService) ipMaxFailures(c *lib.LoginLockoutConfig) int64 {
+	if c.IPMaxFailures > 0 {
+		return int64(c.IPMaxFailures)
+	} else if c.IPMaxFailures < 0 {
+		return 0
+	}
+	return loginLockoutDefaultIPMaxFailures
+}
+
+func (a LoginGuardService) window(c *lib.LoginLockoutConfig) time.Duration {
+	if c.Window > 0 {
+		return time.Duration(c.Window) * time.Second
+	}
+	return loginLockoutDefaultWindow
+}
+
+func (a LoginGuardService) lockDuration(c *lib.LoginLockoutConfig) time.Duration {
+	if c.LockDuration > 0 {
+		return time.Duration(c.LockDuration) * time.Second
+	}
+	return loginLockoutDefaultLockDuration
+}
+
+// Check 登录前检查用户名与 IP 是否被锁定，缓存不可用时放行
+func (a LoginGuardService) Check(username, ip string) error {
+	if a.lockout() == nil {
+		return nil
+	}
+
+	if locked, err := a.cache.Check(wrapperLoginIPLockKey(ip)); err != nil {
+		a.logger.Zap.Warnf("Failed to check login lock of %s: %v", ip, err)
+	} else if locked {
+		return errors.AuthLoginThrottled
+	}
+
+	if locked, err := a.cache.Check(wrapperLoginLockKey(username)); err != nil {
+		a.logger.Zap.Warnf("Failed to check login lock of %s: %v", username, err)
+	} else if locked {
+		return errors.AuthAccountLocked
+	}
+
+	return nil
+}
+
+// RecordFailure 记录失败的登录尝试，失败次数达到上限时锁定用户名或 IP
+// 被锁定而拒绝的尝试以及密码正确、尚未提交两步验证码的尝试只记录日志，不计数
+func (a LoginGuardService) RecordFailure(username, ip, userAgent string, reason error) {
+	a.record(&system.LoginLog{
+		Username: username,
+		IP:       ip,
+		Status:   system.LoginStatusFailure,
+		Message:  reason.Error(),
+	}, userAgent)
+
+	c := a.lockout()
+	if c == nil || errors.Is(reason, errors.AuthAccountLocked) || errors.Is(reason, errors.AuthLoginThrottled) ||
+		errors.Is(reason, errors.AuthTwoFactorRequired) {
+		return
+	}
+
+	if a.countFailure(wrapperLoginFailureKey(username), wrapperLoginLockKey(username), a.maxFailures(c), c) {
+		a.logger.Zap.Warnf("Account %s locked after too many failed logins, last from %s", username, ip)
+	}
+	if max := a.ipMaxFailures(c); max > 0 && ip != "" {
+		if a.countFailure(wrapperLoginIPFailureKey(ip), wrapperLoginIPLockKey(ip), max, c) {
+			a.logger.Zap.Warnf("Login from %s throttled after too many failed logins", ip)
+		}
+	}
+}
+
+// countFailure 失败计数加一，达到上限时设置锁定标记并清空计数，返回是否锁定
+func (a LoginGuardService) countFailure(counterKey, lockKey string, max int64, c *lib.LoginLockoutConfig) bool {
+	n, err := a.cache.Incr(counterKey, a.window(c))
+	if err != nil {
+		a.logger.Zap.Warnf("Failed to count login failure %s: %v", counterKey, err)
+		return false
+	}
+	if n < max {
+		return false
+	}
+
+	if err := a.cache.Set(lockKey, time.Now().Unix(), a.lockDuration(c)); err != nil {
+		a.logger.Zap.Warnf("Failed to set login lock %s: %v", lockKey, err)
+		return false
+	}
+	_, _ = a.cache.Delete(counterKey)
+	return true
+}
+
+// RecordSuccess 记录成功的登录，清空该用户名的失败计数
+func (a LoginGuardService) RecordSuccess(user *system.User, ip, userAgent string) {
+	a.record(&system.LoginLog{
+		UserID:   user.ID,
+		Username: user.Username,
+		IP:       ip,
+		Status:   system.LoginStatusSuccess,
+	}, userAgent)
+
+	if a.lockout() != nil {
+		if _, err := a.cache.Delete(wrapperLoginFailureKey(user.Username)); err != nil {
+			a.logger.Zap.Warnf("Failed to reset login failures of %s: %v", user.Username, err)
+		}
+	}
+}
+
+// record 保存登录日志，失败只记录日志，不影响登录
+func (a LoginGuardService) record(log *system.LoginLog, userAgent string) {
+	ua := useragent.New(userAgent)
+	log.Browser, log.BrowserVersion = ua.Browser()
+	log.OS = ua.OS()
+	log.UserAgent = str.S(userAgent).Truncate(500)
+	log.Username = str.S(log.Username).Truncate(64)
+	log.Message = str.S(log.Message).Truncate(255)
+
+	if err := a.loginLogRepository.Create(log); err != nil {
+		a.logger.Zap.Warnf("Failed to record login of %s: %v", log.Username, err)
+	}
+}
+
+// Query 分页查询登录日志
+func (a LoginGuardService) Query(param *system.LoginLogQueryParam) (*system.LoginLogQueryResult, error) {
+	return a.loginLogRepository.Query(param)
+}
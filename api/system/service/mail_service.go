@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	"github.com/top-system/light-admin/pkg/mailer"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// 内置邮件模板，数据库中有同编码且启用的模板时被覆盖
+var builtinMailTemplates = map[string]*system.MailTemplate{
+	system.MailTemplateNoticePublished: {
+		Code:        system.MailTemplateNoticePublished,
+		Name:        "通知公告发布",
+		Subject:     "[通知公告] {{.Notice.Title}}",
+		Content:     "{{.User.Nickname}}，您好：\n\n{{.Notice.Content}}\n",
+		ContentType: system.MailContentText,
+		Status:      1,
+	},
+	system.MailTemplateDownloadFailed: {
+		Code:        system.MailTemplateDownloadFailed,
+		Name:        "下载任务出错",
+		Subject:     "[下载失败] {{if .Task.Name}}{{.Task.Name}}{{else}}{{.Task.URL}}{{end}}",
+		Content:     "{{.User.Nickname}}，您好：\n\n下载任务 {{if .Task.Name}}{{.Task.Name}}{{else}}{{.Task.URL}}{{end}} 出错：{{.Task.ErrorMessage}}\n",
+		ContentType: system.MailContentText,
+		Status:      1,
+	},
+}
+
+// MailService 邮件通知服务
+// 邮件按模板渲染后以队列任务发送，发送失败由队列按退避重试；未启用任务队列时在后台协程中发送
+// 订阅下载结束事件，按下载器配置向所有者发送下载出错邮件
+type MailService struct {
+	logger                 lib.Logger
+	config                 lib.Config
+	mailer                 lib.Mailer
+	taskQueue              lib.TaskQueue
+	mailTemplateRepository repository.MailTemplateRepository
+	userRepository         repository.UserRepository
+}
+
+// NewMailService creates a new mail service
+func NewMailService(
+	logger lib.Logger,
+	config lib.Config,
+	mailer lib.Mailer,
+	taskQueue lib.TaskQueue,
+	bus *eventbus.Bus,
+	mailTemplateRepository repository.MailTemplateRepository,
+	userRepository repository.UserRepository,
+) MailService {
+	// 从任务存储恢复或由其他实例分发的邮件任务使用本实例的 SMTP 配置发送
+	queue.RegisterResumableTaskFactory(queue.EmailTaskType, queue.NewEmailTaskFactory(mailer.SendMessage))
+
+	a := MailService{
+		logger:                 logger,
+		config:                 config,
+		mailer:                 mailer,
+		taskQueue:              taskQueue,
+		mailTemplateRepository: mailTemplateRepository,
+		userRepository:         userRepository,
+	}
+
+	system.EventDownloadFinished.Subscribe(bus, "mail", a.onDownloadFinished)
+
+	return a
+}
+
+// WithTrx delegates transaction to repository database
+func (a MailService) WithTrx(trxHandle *gorm.DB) MailService {
+	a.mailTemplateRepository = a.mailTemplateRepository.WithTrx(trxHandle)
+	return a
+}
+
+// onDownloadFinished 下载任务出错时按下载器配置向所有者发送邮件
+func (a MailService) onDownloadFinished(ctx context.Context, event system.DownloadFinishedEvent) error {
+	task := event.Task
+	if event.Type != system.DownloadEventFailed || task == nil || task.OwnerID == 0 {
+		return nil
+	}
+	if !a.config.Downloader.EmailEnabled() {
+		return nil
+	}
+
+	owner, err := a.userRepository.Get(task.OwnerID)
+	if err != nil || owner.Email == "" {
+		return nil
+	}
+
+	data := map[string]interface{}{"Task": task, "User": owner}
+	err = a.SendTemplate(system.MailTemplateDownloadFailed, []string{owner.Email}, data,
+		&queue.TaskOwner{ID: owner.ID, Username: owner.Username})
+	if err != nil {
+		return fmt.Errorf("failed to mail download failure for task %d: %w", task.ID, err)
+	}
+	return nil
+}
+
+// IsEnabled 是否已启用邮件发送
+func (a MailService) IsEnabled() bool {
+	return a.mailer.IsEnabled()
+}
+
+// Send 异步发送邮件，owner 为任务所有者，可以为 nil
+func (a MailService) Send(msg mailer.Message, owner *queue.TaskOwner) error {
+	if !a.mailer.IsEnabled() {
+		return errors.MailDisabled
+	}
+
+	task, err := queue.NewEmailTask(a.mailer.SendMessage, msg, owner)
+	if err != nil {
+		return err
+	}
+
+	if a.taskQueue.IsEnabled() {
+		if err := a.taskQueue.QueueTask(context.Background(), task); err != nil {
+			return errors.Wrap(err, "failed to queue email task")
+		}
+		return nil
+	}
+
+	go func() {
+		if _, err := task.Do(context.Background()); err != nil {
+			a.logger.Zap.Errorf("Failed to send mail: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// SendTemplate 按编码渲染模板并异步发送给 to，模板不存在或已停用时不发送
+func (a MailService) SendTemplate(code string, to []string, data interface{}, owner *queue.TaskOwner) error {
+	if len(to) == 0 {
+		return nil
+	}
+	if !a.mailer.IsEnabled() {
+		return errors.MailDisabled
+	}
+
+	preview, err := a.Render(code, data)
+	if err != nil || preview == nil {
+		return err
+	}
+
+	return a.Send(mailer.Message{To: to, Subject: preview.Subject, Text: preview.Text, HTML: preview.HTML}, owner)
+}
+
+// Render 按编码渲染模板，数据库中启用的模板优先，其次为内置模板，都没有时返回 nil
+func (a MailService) Render(code string, data interface{}) (*system.MailPreview, error) {
+	template, err := a.mailTemplateRepository.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil || template.Status != 1 {
+		template = builtinMailTemplates[code]
+	}
+	if template == nil {
+		return nil, nil
+	}
+
+	return renderMailTemplate(template, data)
+}
+
+// Query 查询邮件模板分页列表
+func (a MailService) Query(param *system.MailTemplateQueryParam) (*system.MailTemplateQueryResult, error) {
+	return a.mailTemplateRepository.Query(param)
+}
+
+// Get 获取邮件模板
+func (a MailService) Get(id uint64) (*system.MailTemplate, error) {
+	return a.mailTemplateRepository.Get(id)
+}
+
+// Create 新增邮件模板
+func (a MailService) Create(form *system.MailTemplateForm, createBy uint64) (uint64, error) {
+	template := mailTemplateFromForm(form)
+	if _, err := renderMailTemplate(template, nil); err != nil {
+		return 0, err
+	}
+
+	if exist, err := a.mailTemplateRepository.GetByCode(template.Code); err != nil {
+		return 0, err
+	} else if exist != nil {
+		return 0, errors.MailTemplateCodeAlreadyExists
+	}
+
+	template.CreateBy = createBy
+	if err := a.mailTemplateRepository.Create(template); err != nil {
+		return 0, err
+	}
+
+	return template.ID, nil
+}
+
+// Update 修改邮件模板
+func (a MailService) Update(id uint64, form *system.MailTemplateForm, updateBy uint64) error {
+	if _, err := a.mailTemplateRepository.Get(id); err != nil {
+		return err
+	}
+
+	template := mailTemplateFromForm(form)
+	if _, err := renderMailTemplate(template, nil); err != nil {
+		return err
+	}
+
+	if exist, err := a.mailTemplateRepository.GetByCode(template.Code); err != nil {
+		return err
+	} else if exist != nil && exist.ID != id {
+		return errors.MailTemplateCodeAlreadyExists
+	}
+
+	template.UpdateBy = updateBy
+	return a.mailTemplateRepository.Update(id, template)
+}
+
+// Delete 删除邮件模板，内置编码删除后恢复使用内置模板
+func (a MailService) Delete(id uint64) error {
+	if _, err := a.mailTemplateRepository.Get(id); err != nil {
+		return err
+	}
+
+	return a.mailTemplateRepository.Delete(id)
+}
+
+// Preview 使用给定数据渲染表单中的模板，不保存
+func (a MailService) Preview(form *system.MailTemplatePreviewForm) (*system.MailPreview, error) {
+	return renderMailTemplate(mailTemplateFromForm(&form.MailTemplateForm), form.Data)
+}
+
+// SendTest 使用已保存的模板向 to 发送一封测试邮件，停用的模板同样可以测试
+func (a MailService) SendTest(id uint64, form *system.MailTemplateTestForm, owner *queue.TaskOwner) error {
+	if !a.mailer.IsEnabled() {
+		return errors.MailDisabled
+	}
+
+	template, err := a.mailTemplateRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	preview, err := renderMailTemplate(template, form.Data)
+	if err != nil {
+		return err
+	}
+
+	return a.Send(mailer.Message{To: []string{form.To}, Subject: preview.Subject, Text: preview.Text, HTML: preview.HTML}, owner)
+}
+
+func mailTemplateFromForm(form *system.MailTemplateForm) *system.MailTemplate {
+	contentType := form.ContentType
+	if contentType == "" {
+		contentType = system.MailContentText
+	}
+
+	return &system.MailTemplate{
+		Code:        strings.TrimSpace(form.Code),
+		Name:        strings.TrimSpace(form.Name),
+		Subject:     form.Subject,
+		Content:     form.Content,
+		ContentType: contentType,
+		Status:      form.Status,
+		Remark:      form.Remark,
+	}
+}
+
+// renderMailTemplate 渲染主题与正文，data 为 nil 时只校验模板语法
+// HTML 正文按 html/template 渲染，对数据做转义
+func renderMailTemplate(template *system.MailTemplate, data interface{}) (*system.MailPreview, error) {
+	subject, err := texttemplate.New("subject").Option("missingkey=zero").Parse(template.Subject)
+	if err != nil {
+		return nil, errors.Wrap(errors.MailTemplateInvalid, err.Error())
+	}
+
+	html := template.ContentType == system.MailContentHTML
+	var execute func(buf *bytes.Buffer, data interface{}) error
+	if html {
+		content, err := htmltemplate.New("content").Option("missingkey=zero").Parse(template.Content)
+		if err != nil {
+			return nil, errors.Wrap(errors.MailTemplateInvalid, err.Error())
+		}
+		execute = func(buf *bytes.Buffer, data interface{}) error { return content.Execute(buf, data) }
+	} else {
+		content, err := texttemplate.New("content").Option("missingkey=zero").Parse(template.Content)
+		if err != nil {
+			return nil, errors.Wrap(errors.MailTemplateInvalid, err.Error())
+		}
+		execute = func(buf *bytes.Buffer, data interface{}) error { return content.Execute(buf, data) }
+	}
+
+	preview := new(system.MailPreview)
+	if data == nil {
+		return preview, nil
+	}
+
+	var buf bytes.Buffer
+	if err := subject.Execute(&buf, data); err != nil {
+		return nil, errors.Wrap(errors.MailTemplateInvalid, err.Error())
+	}
+	// 主题只允许单行
+	preview.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := execute(&buf, data); err != nil {
+		return nil, errors.Wrap(errors.MailTemplateInvalid, err.Error())
+	}
+	if html {
+		preview.HTML = buf.String()
+	} else {
+		preview.Text = buf.String()
+	}
+
+	return preview, nil
+}
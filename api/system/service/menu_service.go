@@ -3,7 +3,11 @@ package service
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/api/system/repository"
@@ -19,6 +23,10 @@ type MenuService struct {
 	logger             lib.Logger
 	menuRepository     repository.MenuRepository
 	roleMenuRepository repository.RoleMenuRepository
+	permissionCache    PermissionCache
+	responseCache      lib.ResponseCache
+	featureModules     lib.FeatureModules
+	lockService        LockService
 }
 
 // NewMenuService creates a new menu service
@@ -26,11 +34,19 @@ func NewMenuService(
 	logger lib.Logger,
 	menuRepository repository.MenuRepository,
 	roleMenuRepository repository.RoleMenuRepository,
+	permissionCache PermissionCache,
+	responseCache lib.ResponseCache,
+	featureModules lib.FeatureModules,
+	lockService LockService,
 ) MenuService {
 	return MenuService{
 		logger:             logger,
 		menuRepository:     menuRepository,
 		roleMenuRepository: roleMenuRepository,
+		permissionCache:    permissionCache,
+		responseCache:      responseCache,
+		featureModules:     featureModules,
+		lockService:        lockService,
 	}
 }
 
@@ -78,6 +94,8 @@ func (a MenuService) Create(menu *system.Menu) (uint64, error) {
 		return 0, err
 	}
 
+	a.permissionCache.InvalidateRoutesCache()
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
 	return menu.ID, nil
 }
 
@@ -147,6 +165,38 @@ func (a MenuService) FindByNameAndParent(name string, parentID uint64) (*system.
 	return result.List[0], nil
 }
 
+// Export 导出全部菜单为 YAML 树（与 config/menu.yaml 格式一致）
+func (a MenuService) Export() ([]byte, error) {
+	menus, err := a.menuRepository.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(menus.ToMenuTrees())
+}
+
+// Import 将 YAML 菜单树合并到现有菜单，同一上级下已存在的同名菜单保留原配置，仅继续合并其子菜单
+// 导入期间持有菜单导入锁，与其他实例的导入及启动时的菜单初始化互斥
+func (a MenuService) Import(data []byte, operatorID uint64) error {
+	var menuTrees system.MenuTrees
+	if err := yaml.Unmarshal(data, &menuTrees); err != nil {
+		return errors.Wrap(errors.MenuImportInvalid, err.Error())
+	}
+	if len(menuTrees) == 0 {
+		return errors.MenuImportInvalid
+	}
+
+	if err := a.lockService.WithLock(system.LockMenuImport, operatorID, "import menus", func() error {
+		return a.CreateMenus(0, menuTrees)
+	}); err != nil {
+		return err
+	}
+
+	a.permissionCache.InvalidateRoutesCache()
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
+	return nil
+}
+
 func (a MenuService) Update(id uint64, menu *system.Menu) error {
 	if id == menu.ParentID {
 		return errors.MenuInvalidParent
@@ -184,6 +234,8 @@ func (a MenuService) Update(id uint64, menu *system.Menu) error {
 		return err
 	}
 
+	a.permissionCache.InvalidateRoutesCache()
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
 	return nil
 }
 
@@ -203,6 +255,11 @@ func (a MenuService) Delete(id uint64) error {
 		return errors.MenuNotAllowDeleteWithChild
 	}
 
+	roleIDs, err := a.roleMenuRepository.GetRoleIDsByMenuIDs([]uint64{id})
+	if err != nil {
+		return err
+	}
+
 	// Delete role_menu associations
 	if err = a.roleMenuRepository.DeleteByMenuID(id); err != nil {
 		return err
@@ -212,6 +269,139 @@ func (a MenuService) Delete(id uint64) error {
 		return err
 	}
 
+	a.invalidateMenuCaches(roleIDs)
+	return nil
+}
+
+// DeletePreview 统计删除菜单的影响范围
+func (a MenuService) DeletePreview(id uint64) (*system.MenuDeletePreview, error) {
+	menu, err := a.menuRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subtree, err := a.menuRepository.ListSubtree(a.JoinTreePath(menu.TreePath, menu.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &system.MenuDeletePreview{
+		MenuID:       id,
+		SubtreeCount: len(subtree) + 1,
+		MenuIDs:      []uint64{id},
+	}
+	if menu.Type == constants.MenuTypeButton {
+		preview.ButtonCount++
+	}
+	for _, item := range subtree {
+		preview.MenuIDs = append(preview.MenuIDs, item.ID)
+		if item.ParentID == id {
+			preview.ChildCount++
+		}
+		if item.Type == constants.MenuTypeButton {
+			preview.ButtonCount++
+		}
+	}
+
+	if preview.RoleMenus, err = a.roleMenuRepository.CountByMenuIDs(preview.MenuIDs); err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+// DeleteWithMode 按指定方式删除菜单
+// cascade 删除整棵子树及其角色授权；reparent 将直接子节点挂到被删除菜单的上级；
+// 默认方式与 Delete 一致，存在子节点时拒绝删除。需在事务中调用以保证整体回滚
+func (a MenuService) DeleteWithMode(id uint64, mode string) (*system.MenuDeletePreview, error) {
+	switch mode {
+	case system.MenuDeleteModeDefault:
+		if err := a.Delete(id); err != nil {
+			return nil, err
+		}
+		return &system.MenuDeletePreview{MenuID: id, SubtreeCount: 1, MenuIDs: []uint64{id}}, nil
+	case system.MenuDeleteModeCascade, system.MenuDeleteModeReparent:
+	default:
+		return nil, errors.MenuInvalidDeleteMode
+	}
+
+	preview, err := a.DeletePreview(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == system.MenuDeleteModeReparent {
+		if err = a.reparentChildren(id); err != nil {
+			return nil, err
+		}
+
+		preview.MenuIDs = []uint64{id}
+		preview.SubtreeCount = 1
+		preview.ButtonCount = 0
+		if preview.RoleMenus, err = a.roleMenuRepository.CountByMenuIDs(preview.MenuIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// 删除前记录受影响的角色，用于清除其用户的权限缓存
+	roleIDs, err := a.roleMenuRepository.GetRoleIDsByMenuIDs(preview.MenuIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.roleMenuRepository.DeleteByMenuIDs(preview.MenuIDs); err != nil {
+		return nil, err
+	}
+
+	if err = a.menuRepository.DeleteByIDs(preview.MenuIDs); err != nil {
+		return nil, err
+	}
+
+	a.invalidateMenuCaches(roleIDs)
+	return preview, nil
+}
+
+// invalidateMenuCaches 菜单删除后清除路由、菜单响应缓存及受影响角色下用户的权限缓存
+func (a MenuService) invalidateMenuCaches(roleIDs []uint64) {
+	a.permissionCache.InvalidateRoutesCache()
+	for _, roleID := range roleIDs {
+		a.permissionCache.InvalidateRoleCache(roleID)
+	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
+}
+
+// reparentChildren 将菜单的直接子节点挂到其上级，并修正整棵子树的 tree_path
+func (a MenuService) reparentChildren(id uint64) error {
+	menu, err := a.menuRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	oPath := a.JoinTreePath(menu.TreePath, menu.ID)
+	subtree, err := a.menuRepository.ListSubtree(oPath)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range subtree {
+		treePath := strings.TrimPrefix(menu.TreePath+item.TreePath[len(oPath):], ",")
+
+		parentID := item.ParentID
+		if parentID == id {
+			parentID = menu.ParentID
+			// 同一上级下菜单名称不能重复（被删除的菜单自身除外）
+			if exist, err := a.FindByNameAndParent(item.Name, parentID); err == nil && exist.ID != id {
+				return errors.MenuAlreadyExists
+			} else if err != nil && !errors.Is(err, errors.DatabaseRecordNotFound) {
+				return err
+			}
+		}
+
+		if err = a.menuRepository.UpdateParent(item.ID, parentID, treePath); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -221,7 +411,13 @@ func (a MenuService) UpdateVisible(id uint64, visible int) error {
 		return err
 	}
 
-	return a.menuRepository.UpdateVisible(id, visible)
+	if err = a.menuRepository.UpdateVisible(id, visible); err != nil {
+		return err
+	}
+
+	a.permissionCache.InvalidateRoutesCache()
+	a.responseCache.Invalidate(lib.ResponseCacheGroupMenu)
+	return nil
 }
 
 func (a MenuService) GetTreePath(parentID uint64) (string, error) {
@@ -333,8 +529,75 @@ func buildMenuOptions(parentID uint64, childMap map[uint64][]*system.Menu) []dto
 	return options
 }
 
-// GetUserRoutes 获取用户的路由列表
+// GetUserRoutes 获取用户的路由列表（按角色组合缓存）
 func (a MenuService) GetUserRoutes(roleIDs []uint64, isSuperAdmin bool) ([]*dto.RouteVO, error) {
+	return a.permissionCache.LoadRoutes(roleIDs, isSuperAdmin, func() ([]*dto.RouteVO, error) {
+		return a.buildUserRoutes(roleIDs, isSuperAdmin)
+	})
+}
+
+// GetUserRoutesDelta 路由菜单增量同步，version 为客户端上次同步返回的版本号
+// 版本号包含可见菜单集合的指纹，菜单删除、角色菜单或用户角色变更导致可见范围变化时返回全量
+func (a MenuService) GetUserRoutesDelta(roleIDs []uint64, isSuperAdmin bool, version string) (*system.MenuDeltaVO, error) {
+	menus, err := a.userRouteMenus(roleIDs, isSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(menus, func(i, j int) bool {
+		return menus[i].ID < menus[j].ID
+	})
+
+	var latest time.Time
+	ids := make([]uint64, 0, len(menus))
+	for _, menu := range menus {
+		ids = append(ids, menu.ID)
+		if t := menu.UpdateTime.Time(); t.After(latest) {
+			latest = t
+		}
+	}
+	fingerprint := deltaFingerprint(ids)
+
+	delta := &system.MenuDeltaVO{
+		Version: formatDeltaVersion(latest, fingerprint),
+		Menus:   make(system.Menus, 0),
+	}
+
+	since, clientFingerprint := parseDeltaVersion(version)
+	if since == nil || clientFingerprint != fingerprint {
+		delta.Full = true
+		delta.Menus = menus
+		return delta, nil
+	}
+
+	for _, menu := range menus {
+		if !menu.UpdateTime.Time().Before(*since) {
+			delta.Menus = append(delta.Menus, menu)
+		}
+	}
+
+	return delta, nil
+}
+
+// buildUserRoutes 从数据库构建用户的路由列表
+func (a MenuService) buildUserRoutes(roleIDs []uint64, isSuperAdmin bool) ([]*dto.RouteVO, error) {
+	routeMenus, err := a.userRouteMenus(roleIDs, isSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按 sort 排序
+	sort.Slice(routeMenus, func(i, j int) bool {
+		return routeMenus[i].Sort < routeMenus[j].Sort
+	})
+
+	// 预构建 parentID -> children 映射（O(n) 复杂度）
+	routeChildMap := buildMenuChildMap(routeMenus)
+	return a.buildRoutes(routeChildMap, 0), nil
+}
+
+// userRouteMenus 获取用户可见的路由菜单（不含按钮）
+func (a MenuService) userRouteMenus(roleIDs []uint64, isSuperAdmin bool) (system.Menus, error) {
 	var menus system.Menus
 	var err error
 
@@ -370,14 +633,40 @@ func (a MenuService) GetUserRoutes(roleIDs []uint64, isSuperAdmin bool) ([]*dto.
 		}
 	}
 
-	// 按 sort 排序
-	sort.Slice(routeMenus, func(i, j int) bool {
-		return routeMenus[i].Sort < routeMenus[j].Sort
-	})
+	return a.filterFeatureMenus(routeMenus), nil
+}
 
-	// 预构建 parentID -> children 映射（O(n) 复杂度）
-	routeChildMap := buildMenuChildMap(routeMenus)
-	return a.buildRoutes(routeChildMap, 0), nil
+// filterFeatureMenus 移除已禁用功能模块的菜单及其子菜单
+func (a MenuService) filterFeatureMenus(menus system.Menus) system.Menus {
+	disabled := make(map[string]bool)
+	for _, menu := range menus {
+		if a.featureModules.IsComponentDisabled(menu.Component) {
+			disabled[strconv.FormatUint(menu.ID, 10)] = true
+		}
+	}
+	if len(disabled) == 0 {
+		return menus
+	}
+
+	result := make(system.Menus, 0, len(menus))
+	for _, menu := range menus {
+		if disabled[strconv.FormatUint(menu.ID, 10)] {
+			continue
+		}
+
+		inDisabled := false
+		for _, id := range strings.Split(menu.TreePath, ",") {
+			if disabled[id] {
+				inDisabled = true
+				break
+			}
+		}
+		if !inDisabled {
+			result = append(result, menu)
+		}
+	}
+
+	return result
 }
 
 func (a MenuService) fillParentMenus(menus system.Menus) (system.Menus, error) {
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+const (
+	noticeLockTTL      = 2 * time.Minute // 编辑锁有效期，编辑页需定时续期
+	noticeRevisionKeep = 50              // 每条通知公告保留的草稿修订数
+)
+
+func noticeLockKey(id uint64) string {
+	return fmt.Sprintf("notice:lock:%d", id)
+}
+
+// NoticeDraftService 通知公告草稿服务
+// 提供草稿自动保存（每次有变化的保存生成一条修订记录）以及编辑锁。
+// 编辑锁是软锁：只用于提示同时编辑同一条未发布通知的其他用户，不阻止保存，
+// 锁变化通过 /topic/notice-locks 推送
+type NoticeDraftService struct {
+	logger                   lib.Logger
+	cache                    lib.Cache
+	ws                       *ws.WebSocket
+	noticeRepository         repository.NoticeRepository
+	noticeRevisionRepository repository.NoticeRevisionRepository
+	userRepository           repository.UserRepository
+}
+
+// NewNoticeDraftService 创建通知公告草稿服务
+func NewNoticeDraftService(
+	logger lib.Logger,
+	cache lib.Cache,
+	websocket *ws.WebSocket,
+	noticeRepository repository.NoticeRepository,
+	noticeRevisionRepository repository.NoticeRevisionRepository,
+	userRepository repository.UserRepository,
+) NoticeDraftService {
+	websocket.RegisterTopic(ws.Topic{
+		Destination: ws.TopicNoticeLocks,
+		Module:      "notice",
+		Description: "通知公告编辑锁变化（locked/released），用于提示正在编辑同一条通知的其他用户",
+		Permission:  "sys:notice:edit",
+		Payload:     system.NoticeLockEvent{},
+	})
+
+	return NoticeDraftService{
+		logger:                   logger,
+		cache:                    cache,
+		ws:                       websocket,
+		noticeRepository:         noticeRepository,
+		noticeRevisionRepository: noticeRevisionRepository,
+		userRepository:           userRepository,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a NoticeDraftService) WithTrx(trxHandle *gorm.DB) NoticeDraftService {
+	a.noticeRepository = a.noticeRepository.WithTrx(trxHandle)
+	a.noticeRevisionRepository = a.noticeRevisionRepository.WithTrx(trxHandle)
+	return a
+}
+
+// SaveDraft 自动保存草稿，只更新表单中传入的字段，内容有变化时生成修订记录
+func (a NoticeDraftService) SaveDraft(id uint64, form *system.NoticeDraftForm, claims *dto.JwtClaims) (*system.NoticeDraftResult, error) {
+	notice, err := a.editableNotice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := *notice
+	if v := form.Title; v != nil {
+		if len([]rune(*v)) > 50 {
+			return nil, errors.New("标题长度不能超过50个字符")
+		}
+		draft.Title = *v
+	}
+	if v := form.Content; v != nil {
+		draft.Content = *v
+	}
+	if v := form.Type; v != nil {
+		draft.Type = v.Value()
+	}
+	if v := form.Level; v != nil {
+		draft.Level = *v
+	}
+	if v := form.TargetType; v != nil {
+		draft.TargetType = *v
+	}
+	if v := form.TargetUserIds; v != nil {
+		draft.TargetUserIds = strings.Join(*v, ",")
+	}
+
+	return a.save(notice, &draft, claims)
+}
+
+// QueryRevisions 分页查询草稿修订记录
+func (a NoticeDraftService) QueryRevisions(param *system.NoticeRevisionQueryParam) (*system.NoticeRevisionQueryResult, error) {
+	return a.noticeRevisionRepository.Query(param)
+}
+
+// GetRevision 获取指定版本的草稿
+func (a NoticeDraftService) GetRevision(id uint64, revision int) (*system.NoticeRevision, error) {
+	return a.noticeRevisionRepository.Get(id, revision)
+}
+
+// RestoreRevision 将通知公告恢复到指定版本，恢复本身也会生成一条新的修订记录
+func (a NoticeDraftService) RestoreRevision(id uint64, revision int, claims *dto.JwtClaims) (*system.NoticeDraftResult, error) {
+	notice, err := a.editableNotice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := a.noticeRevisionRepository.Get(id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := *notice
+	draft.Title = item.Title
+	draft.Content = item.Content
+	draft.Type = item.Type
+	draft.Level = item.Level
+	draft.TargetType = item.TargetType
+	draft.TargetUserIds = item.TargetUserIds
+
+	return a.save(notice, &draft, claims)
+}
+
+// Lock 获取或续期编辑锁
+// 锁由其他用户持有且未指定 force 时不接管，返回对方的锁信息并标记 Conflict
+func (a NoticeDraftService) Lock(id uint64, claims *dto.JwtClaims, force bool) (*system.NoticeEditLockStatus, error) {
+	if _, err := a.editableNotice(id); err != nil {
+		return nil, err
+	}
+
+	current, err := a.getLock(id)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := current != nil && current.Username == claims.Username
+	if current != nil && !owned && !force {
+		return &system.NoticeEditLockStatus{Lock: current, Conflict: true}, nil
+	}
+
+	now := time.Now()
+	lock := &system.NoticeEditLock{
+		NoticeID:  id,
+		UserID:    claims.ID,
+		Username:  claims.Username,
+		Nickname:  a.nickname(claims),
+		LockedAt:  now.UnixMilli(),
+		ExpiresAt: now.Add(noticeLockTTL).UnixMilli(),
+	}
+	if owned {
+		lock.LockedAt = current.LockedAt
+	}
+
+	if err := a.cache.Set(noticeLockKey(id), lock, noticeLockTTL); err != nil {
+		return nil, err
+	}
+
+	// 续期不推送
+	if !owned {
+		a.publishLock(system.NoticeLockAcquired, id, lock)
+	}
+
+	return &system.NoticeEditLockStatus{Lock: lock, Owned: true}, nil
+}
+
+// Unlock 释放编辑锁，只释放自己持有的锁
+func (a NoticeDraftService) Unlock(id uint64, claims *dto.JwtClaims) error {
+	current, err := a.getLock(id)
+	if err != nil || current == nil || current.Username != claims.Username {
+		return err
+	}
+
+	if _, err := a.cache.Delete(noticeLockKey(id)); err != nil {
+		return err
+	}
+
+	a.publishLock(system.NoticeLockReleased, id, current)
+	return nil
+}
+
+// LockStatus 查询编辑锁状态
+func (a NoticeDraftService) LockStatus(id uint64, claims *dto.JwtClaims) (*system.NoticeEditLockStatus, error) {
+	current, err := a.getLock(id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &system.NoticeEditLockStatus{Lock: current}
+	if current != nil {
+		status.Owned = current.Username == claims.Username
+		status.Conflict = !status.Owned
+	}
+	return status, nil
+}
+
+// editableNotice 获取可编辑的通知公告，已发布的通知需先撤回
+func (a NoticeDraftService) editableNotice(id uint64) (*system.Notice, error) {
+	notice, err := a.noticeRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if notice.PublishStatus == 1 {
+		return nil, errors.New("通知公告已发布，请先撤回")
+	}
+	return notice, nil
+}
+
+// save 保存草稿并生成修订记录，内容没有变化时不保存（首次保存总会生成基准版本）
+func (a NoticeDraftService) save(notice, draft *system.Notice, claims *dto.JwtClaims) (*system.NoticeDraftResult, error) {
+	lock, err := a.LockStatus(notice.ID, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := a.noticeRevisionRepository.Latest(notice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &system.NoticeDraftResult{Lock: lock}
+	if latest != nil {
+		result.Revision = latest.Revision
+	}
+
+	if sameNoticeDraft(notice, draft) && latest != nil {
+		return result, nil
+	}
+
+	draft.UpdateBy = claims.ID
+	if err := a.noticeRepository.Update(notice.ID, draft); err != nil {
+		return nil, err
+	}
+
+	revision := &system.NoticeRevision{
+		NoticeID:      notice.ID,
+		Revision:      result.Revision + 1,
+		Title:         draft.Title,
+		Content:       draft.Content,
+		Type:          draft.Type,
+		Level:         draft.Level,
+		TargetType:    draft.TargetType,
+		TargetUserIds: draft.TargetUserIds,
+		CreateBy:      claims.ID,
+	}
+	if err := a.noticeRevisionRepository.Create(revision); err != nil {
+		return nil, err
+	}
+
+	if revision.Revision > noticeRevisionKeep {
+		if err := a.noticeRevisionRepository.DeleteBefore(notice.ID, revision.Revision-noticeRevisionKeep+1); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Saved = true
+	result.Revision = revision.Revision
+	return result, nil
+}
+
+func (a NoticeDraftService) getLock(id uint64) (*system.NoticeEditLock, error) {
+	lock := new(system.NoticeEditLock)
+	if err := a.cache.Get(noticeLockKey(id), lock); err != nil {
+		if errors.Is(err, errors.RedisKeyNoExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if lock.ExpiresAt < time.Now().UnixMilli() {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+func (a NoticeDraftService) nickname(claims *dto.JwtClaims) string {
+	if claims.ID > 0 {
+		if user, err := a.userRepository.Get(claims.ID); err == nil && user.Nickname != "" {
+			return user.Nickname
+		}
+	}
+	return claims.Username
+}
+
+func (a NoticeDraftService) publishLock(eventType string, id uint64, lock *system.NoticeEditLock) {
+	a.ws.Broker.Publish(ws.TopicNoticeLocks, &system.NoticeLockEvent{
+		Type:      eventType,
+		NoticeID:  id,
+		Lock:      lock,
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
+
+func sameNoticeDraft(a, b *system.Notice) bool {
+	return a.Title == b.Title &&
+		a.Content == b.Content &&
+		a.Type == b.Type &&
+		a.Level == b.Level &&
+		a.TargetType == b.TargetType &&
+		a.TargetUserIds == b.TargetUserIds
+}
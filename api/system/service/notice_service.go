@@ -1,45 +1,148 @@
 package service
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
+	platformservice "github.com/top-system/light-admin/api/platform/service"
 	"github.com/top-system/light-admin/api/system/repository"
 	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/queue"
 )
 
+const (
+	noticeAttachmentDefaultMaxSize  = 20 // MB
+	noticeAttachmentDefaultMaxCount = 10
+	noticeAttachmentDefaultExpire   = 30 // 分钟
+	noticeAttachmentDefaultTTL      = 24 // 小时
+
+	noticeAttachmentSweepTaskName    = "notice_attachment_sweep"
+	noticeAttachmentSweepDefaultSpec = "0 0 * * * *"
+	noticeAttachmentSweepBatchSize   = 500
+
+	// NoticeAttachmentDownloadPath 附件签名下载地址，由签名校验访问权限，无需登录
+	NoticeAttachmentDownloadPath = "/api/v1/notices/attachments/download"
+)
+
+// 未配置 AllowedTypes 时允许的附件扩展名
+var noticeAttachmentDefaultTypes = []string{
+	"pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "txt", "csv", "md",
+	"zip", "rar", "7z",
+	"png", "jpg", "jpeg", "gif", "bmp", "webp",
+}
+
 // NoticeService service layer
 type NoticeService struct {
-	logger               lib.Logger
-	noticeRepository     repository.NoticeRepository
-	userNoticeRepository repository.UserNoticeRepository
-	userRepository       repository.UserRepository
+	logger                     lib.Logger
+	attachmentConfig           *lib.NoticeAttachmentConfig
+	signKey                    []byte
+	featureModules             lib.FeatureModules
+	fileService                platformservice.FileService
+	noticeRepository           repository.NoticeRepository
+	userNoticeRepository       repository.UserNoticeRepository
+	userRepository             repository.UserRepository
+	noticeAttachmentRepository repository.NoticeAttachmentRepository
+	noticeRevisionRepository   repository.NoticeRevisionRepository
+	wsEventService             WsEventService
+	mailService                MailService
+	webhookService             WebhookService
 }
 
 // NewNoticeService creates a new notice service
 func NewNoticeService(
 	logger lib.Logger,
+	config lib.Config,
+	cron lib.Crontab,
+	featureModules lib.FeatureModules,
+	fileService platformservice.FileService,
 	noticeRepository repository.NoticeRepository,
 	userNoticeRepository repository.UserNoticeRepository,
 	userRepository repository.UserRepository,
+	noticeAttachmentRepository repository.NoticeAttachmentRepository,
+	noticeRevisionRepository repository.NoticeRevisionRepository,
+	wsEventService WsEventService,
+	mailService MailService,
+	webhookService WebhookService,
 ) NoticeService {
-	return NoticeService{
-		logger:               logger,
-		noticeRepository:     noticeRepository,
-		userNoticeRepository: userNoticeRepository,
-		userRepository:       userRepository,
+	cfg := lib.NoticeAttachmentConfig{}
+	if config.NoticeAttachment != nil {
+		cfg = *config.NoticeAttachment
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = noticeAttachmentDefaultMaxSize
+	}
+	if cfg.MaxCount <= 0 {
+		cfg.MaxCount = noticeAttachmentDefaultMaxCount
+	}
+	if len(cfg.AllowedTypes) == 0 {
+		cfg.AllowedTypes = noticeAttachmentDefaultTypes
+	}
+	if cfg.URLExpire <= 0 {
+		cfg.URLExpire = noticeAttachmentDefaultExpire
+	}
+	if cfg.UnlinkedTTL <= 0 {
+		cfg.UnlinkedTTL = noticeAttachmentDefaultTTL
 	}
+
+	// 未配置密钥时随机生成，只在本次启动内有效，不与其他用途共用密钥
+	signKey := []byte(cfg.Secret)
+	if len(signKey) == 0 {
+		signKey = make([]byte, 32)
+		_, _ = rand.Read(signKey) // crypto/rand 自 Go 1.24 起不会返回错误
+		logger.Zap.Warn("NoticeAttachment.Secret is not configured, attachment download urls are valid until restart on this instance only")
+	}
+
+	svc := NoticeService{
+		logger:                     logger,
+		attachmentConfig:           &cfg,
+		signKey:                    signKey,
+		featureModules:             featureModules,
+		fileService:                fileService,
+		noticeRepository:           noticeRepository,
+		userNoticeRepository:       userNoticeRepository,
+		userRepository:             userRepository,
+		noticeAttachmentRepository: noticeAttachmentRepository,
+		noticeRevisionRepository:   noticeRevisionRepository,
+		wsEventService:             wsEventService,
+		mailService:                mailService,
+		webhookService:             webhookService,
+	}
+
+	if cron.IsEnabled() {
+		spec := cfg.SweepSpec
+		if spec == "" {
+			spec = noticeAttachmentSweepDefaultSpec
+		}
+
+		if err := cron.AddTask(noticeAttachmentSweepTaskName, spec, svc.runSweep, crontab.WithSkipIfRunning()); err != nil {
+			logger.Zap.Errorf("Failed to register notice attachment sweep task: %v", err)
+		}
+	}
+
+	return svc
 }
 
 // WithTrx delegates transaction to repository database
 func (a NoticeService) WithTrx(trxHandle *gorm.DB) NoticeService {
 	a.noticeRepository = a.noticeRepository.WithTrx(trxHandle)
 	a.userNoticeRepository = a.userNoticeRepository.WithTrx(trxHandle)
+	a.noticeAttachmentRepository = a.noticeAttachmentRepository.WithTrx(trxHandle)
+	a.noticeRevisionRepository = a.noticeRevisionRepository.WithTrx(trxHandle)
 	return a
 }
 
@@ -64,6 +167,20 @@ func (a NoticeService) GetForm(id uint64) (*system.NoticeForm, error) {
 	if notice.TargetUserIds != "" {
 		targetUserIds = strings.Split(notice.TargetUserIds, ",")
 	}
+	var channels []string
+	if notice.Channels != "" {
+		channels = strings.Split(notice.Channels, ",")
+	}
+
+	attachments, err := a.noticeAttachmentRepository.ListByNoticeIDs([]uint64{id})
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentIds := make([]uint64, 0, len(attachments))
+	for _, item := range attachments {
+		attachmentIds = append(attachmentIds, item.ID)
+	}
 
 	return &system.NoticeForm{
 		ID:            notice.ID,
@@ -73,9 +190,25 @@ func (a NoticeService) GetForm(id uint64) (*system.NoticeForm, error) {
 		Level:         notice.Level,
 		TargetType:    notice.TargetType,
 		TargetUserIds: targetUserIds,
+		Channels:      channels,
+		AttachmentIds: attachmentIds,
+		Attachments:   a.attachmentVOs(attachments),
 	}, nil
 }
 
+// CheckDataScope 检查通知公告是否在数据权限范围内（按创建人所在部门），超出范围按不存在处理
+func (a NoticeService) CheckDataScope(scope *system.DataScope, ids ...uint64) error {
+	if scope == nil || scope.All {
+		return nil
+	}
+
+	scoped, err := a.noticeRepository.ScopedIDs(ids, scope)
+	if err != nil {
+		return err
+	}
+	return requireInScope(ids, scoped)
+}
+
 // GetDetail 获取通知公告详情并标记为已读
 func (a NoticeService) GetDetail(id uint64, userID uint64) (*system.NoticeDetailVO, error) {
 	notice, err := a.noticeRepository.Get(id)
@@ -95,6 +228,11 @@ func (a NoticeService) GetDetail(id uint64, userID uint64) (*system.NoticeDetail
 		}
 	}
 
+	attachments, err := a.noticeAttachmentRepository.ListByNoticeIDs([]uint64{id})
+	if err != nil {
+		return nil, err
+	}
+
 	return &system.NoticeDetailVO{
 		ID:            notice.ID,
 		Title:         notice.Title,
@@ -104,6 +242,7 @@ func (a NoticeService) GetDetail(id uint64, userID uint64) (*system.NoticeDetail
 		PublisherId:   notice.PublisherId,
 		PublisherName: publisherName,
 		PublishTime:   notice.PublishTime,
+		Attachments:   a.attachmentVOs(attachments),
 	}, nil
 }
 
@@ -121,12 +260,17 @@ func (a NoticeService) Create(form *system.NoticeForm, createdBy uint64) error {
 		Level:         form.Level,
 		TargetType:    form.TargetType,
 		TargetUserIds: strings.Join(form.TargetUserIds, ","),
+		Channels:      strings.Join(form.Channels, ","),
 		PublishStatus: 0, // 未发布
 		CreateBy:      createdBy,
 		IsDeleted:     0,
 	}
 
-	return a.noticeRepository.Create(notice)
+	if err := a.noticeRepository.Create(notice); err != nil {
+		return err
+	}
+
+	return a.linkAttachments(notice.ID, form.AttachmentIds)
 }
 
 // Update 更新通知公告
@@ -150,10 +294,15 @@ func (a NoticeService) Update(id uint64, form *system.NoticeForm, updatedBy uint
 		Level:         form.Level,
 		TargetType:    form.TargetType,
 		TargetUserIds: strings.Join(form.TargetUserIds, ","),
+		Channels:      strings.Join(form.Channels, ","),
 		UpdateBy:      updatedBy,
 	}
 
-	return a.noticeRepository.Update(id, notice)
+	if err := a.noticeRepository.Update(id, notice); err != nil {
+		return err
+	}
+
+	return a.linkAttachments(id, form.AttachmentIds)
 }
 
 // Delete 删除通知公告
@@ -182,11 +331,31 @@ func (a NoticeService) Delete(ids string, deletedBy uint64) error {
 	}
 
 	// 删除用户通知状态
-	return a.userNoticeRepository.DeleteByNoticeIDs(idList)
+	if err := a.userNoticeRepository.DeleteByNoticeIDs(idList); err != nil {
+		return err
+	}
+
+	// 删除草稿修订记录
+	if err := a.noticeRevisionRepository.DeleteByNoticeIDs(idList); err != nil {
+		return err
+	}
+
+	// 移除附件，文件由定时清理任务删除
+	attachments, err := a.noticeAttachmentRepository.ListByNoticeIDs(idList)
+	if err != nil {
+		return err
+	}
+
+	return a.removeAttachments(attachments)
 }
 
-// Publish 发布通知公告
+// Publish 发布通知公告，并向在线的目标用户推送需要回执的通知消息
 func (a NoticeService) Publish(id uint64, publisherId uint64) error {
+	return a.publish(id, publisherId, true)
+}
+
+// publish 发布通知公告，push 为 false 时不推送 WebSocket 消息
+func (a NoticeService) publish(id uint64, publisherId uint64, push bool) error {
 	notice, err := a.noticeRepository.Get(id)
 	if err != nil {
 		return err
@@ -253,13 +422,87 @@ func (a NoticeService) Publish(id uint64, publisherId uint64) error {
 		})
 	}
 
-	if len(userNotices) > 0 {
-		return a.userNoticeRepository.BatchCreate(userNotices)
+	if len(userNotices) == 0 {
+		return nil
+	}
+	if err := a.userNoticeRepository.BatchCreate(userNotices); err != nil {
+		return err
+	}
+
+	if push {
+		message := map[string]interface{}{
+			"type":      system.WsEventNotice,
+			"noticeId":  notice.ID,
+			"title":     notice.Title,
+			"level":     notice.Level,
+			"timestamp": time.Now().UnixMilli(),
+		}
+		if _, err := a.wsEventService.Notify(system.WsEventNotice, notice.Title, targetUsers, message, publisherId); err != nil {
+			a.logger.Zap.Warnf("Failed to push notice %d: %v", notice.ID, err)
+		}
+	}
+
+	if notice.HasChannel(system.NoticeChannelEmail) {
+		a.sendMails(notice, targetUsers)
+	}
+
+	// 系统自动产生的通知（push 为 false）由各自的事件通知，不重复投递
+	if push {
+		a.webhookService.Dispatch(system.WebhookEventNoticePublished, map[string]interface{}{
+			"id":          notice.ID,
+			"title":       notice.Title,
+			"type":        notice.Type,
+			"level":       notice.Level,
+			"targetType":  notice.TargetType,
+			"audience":    len(targetUsers),
+			"publisherId": publisherId,
+		})
 	}
 
 	return nil
 }
 
+// sendMails 向有邮箱的目标用户逐个发送通知邮件，邮件以队列任务异步发送
+func (a NoticeService) sendMails(notice *system.Notice, users system.Users) {
+	if !a.mailService.IsEnabled() {
+		a.logger.Zap.Warnf("Notice %d requests email channel but mail is not enabled", notice.ID)
+		return
+	}
+
+	for _, user := range users {
+		if user.Email == "" {
+			continue
+		}
+		data := map[string]interface{}{"Notice": notice, "User": user}
+		owner := &queue.TaskOwner{ID: user.ID, Username: user.Username}
+		if err := a.mailService.SendTemplate(system.MailTemplateNoticePublished, []string{user.Email}, data, owner); err != nil {
+			a.logger.Zap.Warnf("Failed to mail notice %d to user %d: %v", notice.ID, user.ID, err)
+		}
+	}
+}
+
+// Send 创建并立即发布指定用户的通知，用于系统自动产生的通知（如安全告警）
+func (a NoticeService) Send(notice *system.Notice, userIDs []uint64) error {
+	if len(userIDs) == 0 {
+		return errors.New("推送指定用户不能为空")
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		ids = append(ids, strconv.FormatUint(id, 10))
+	}
+
+	notice.TargetType = 2
+	notice.TargetUserIds = strings.Join(ids, ",")
+	notice.PublishStatus = 0
+	if err := a.noticeRepository.Create(notice); err != nil {
+		return err
+	}
+
+	// 系统通知由调用方按需推送（如安全告警的 WebSocket 渠道）
+	return a.publish(notice.ID, notice.CreateBy, false)
+}
+
 // Revoke 撤回通知公告
 func (a NoticeService) Revoke(id uint64, updatedBy uint64) error {
 	notice, err := a.noticeRepository.Get(id)
@@ -289,3 +532,205 @@ func (a NoticeService) GetMyNoticePage(param *system.NoticeQueryParam) ([]system
 func (a NoticeService) ReadAll(userID uint64) error {
 	return a.userNoticeRepository.MarkAllAsRead(userID)
 }
+
+// UploadAttachment 上传通知公告附件，上传后需在新增或修改通知公告时通过 AttachmentIds 关联
+func (a NoticeService) UploadAttachment(filename string, reader io.Reader, size int64, contentType string, createBy uint64) (*system.NoticeAttachmentVO, error) {
+	if limit := a.attachmentConfig.MaxSize * 1024 * 1024; size > limit {
+		return nil, errors.Wrapf(errors.NoticeAttachmentTooLarge, "max %dMB", a.attachmentConfig.MaxSize)
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filename), "."))
+	if !a.allowedType(ext) {
+		return nil, errors.Wrapf(errors.NoticeAttachmentTypeNotAllowed, "%q", ext)
+	}
+
+	info, err := a.fileService.UploadFile(filename, reader, size, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &system.NoticeAttachment{
+		FileName:    filename,
+		FileURL:     info.URL,
+		FileSize:    size,
+		ContentType: contentType,
+		CreateBy:    createBy,
+	}
+	if err := a.noticeAttachmentRepository.Create(attachment); err != nil {
+		_ = a.fileService.DeleteFile(info.URL)
+		return nil, err
+	}
+
+	return a.attachmentVO(attachment), nil
+}
+
+// OpenAttachment 校验签名下载地址并打开附件文件
+func (a NoticeService) OpenAttachment(param *system.NoticeAttachmentDownloadParam) (*system.NoticeAttachment, *platformservice.StoredObject, error) {
+	if param.Expires < time.Now().Unix() {
+		return nil, nil, errors.NoticeAttachmentSignInvalid
+	}
+
+	expected := a.signAttachment(param.ID, param.Expires)
+	if !hmac.Equal([]byte(expected), []byte(param.Sign)) {
+		return nil, nil, errors.NoticeAttachmentSignInvalid
+	}
+
+	attachment, err := a.noticeAttachmentRepository.Get(param.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, err := a.fileService.OpenFile(attachment.FileURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, object, nil
+}
+
+// AttachmentURL 生成附件的签名下载地址
+func (a NoticeService) AttachmentURL(id uint64) string {
+	expires := time.Now().Add(time.Duration(a.attachmentConfig.URLExpire) * time.Minute).Unix()
+	return fmt.Sprintf("%s?id=%d&expires=%d&sign=%s", NoticeAttachmentDownloadPath, id, expires, a.signAttachment(id, expires))
+}
+
+func (a NoticeService) signAttachment(id uint64, expires int64) string {
+	mac := hmac.New(sha256.New, a.signKey)
+	_, _ = fmt.Fprintf(mac, "notice-attachment:%d:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (a NoticeService) allowedType(ext string) bool {
+	if ext == "" {
+		return false
+	}
+
+	for _, t := range a.attachmentConfig.AllowedTypes {
+		if strings.EqualFold(strings.TrimPrefix(t, "."), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a NoticeService) attachmentVO(attachment *system.NoticeAttachment) *system.NoticeAttachmentVO {
+	return &system.NoticeAttachmentVO{
+		ID:          attachment.ID,
+		FileName:    attachment.FileName,
+		FileSize:    attachment.FileSize,
+		ContentType: attachment.ContentType,
+		URL:         a.AttachmentURL(attachment.ID),
+	}
+}
+
+func (a NoticeService) attachmentVOs(attachments system.NoticeAttachments) []*system.NoticeAttachmentVO {
+	list := make([]*system.NoticeAttachmentVO, 0, len(attachments))
+	for _, item := range attachments {
+		list = append(list, a.attachmentVO(item))
+	}
+	return list
+}
+
+// linkAttachments 按表单同步通知公告的附件：关联新增的附件，删除表单中已移除的附件
+func (a NoticeService) linkAttachments(noticeID uint64, attachmentIds []uint64) error {
+	ids := make([]uint64, 0, len(attachmentIds))
+	seen := make(map[uint64]struct{}, len(attachmentIds))
+	for _, id := range attachmentIds {
+		if _, ok := seen[id]; ok || id == 0 {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	if len(ids) > a.attachmentConfig.MaxCount {
+		return errors.Wrapf(errors.NoticeAttachmentTooMany, "max %d", a.attachmentConfig.MaxCount)
+	}
+
+	linkable, err := a.noticeAttachmentRepository.ListLinkable(noticeID, ids)
+	if err != nil {
+		return err
+	}
+	if len(linkable) != len(ids) {
+		return errors.New("附件不存在或已关联到其他通知公告")
+	}
+
+	current, err := a.noticeAttachmentRepository.ListByNoticeIDs([]uint64{noticeID})
+	if err != nil {
+		return err
+	}
+
+	removed := make(system.NoticeAttachments, 0)
+	for _, item := range current {
+		if _, ok := seen[item.ID]; !ok {
+			removed = append(removed, item)
+		}
+	}
+
+	if err := a.noticeAttachmentRepository.Link(noticeID, ids); err != nil {
+		return err
+	}
+
+	return a.removeAttachments(removed)
+}
+
+// removeAttachments 将附件标记为已移除；文件删除不能回滚，由 SweepAttachments 在事务提交后删除记录与文件
+func (a NoticeService) removeAttachments(attachments system.NoticeAttachments) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, 0, len(attachments))
+	for _, item := range attachments {
+		ids = append(ids, item.ID)
+	}
+
+	return a.noticeAttachmentRepository.Detach(ids, time.Now())
+}
+
+// runSweep 定时任务入口，删除数量作为执行摘要；通知公告模块禁用时跳过
+func (a NoticeService) runSweep(ctx context.Context) {
+	if !a.featureModules.IsEnabled(lib.FeatureModuleNotice) {
+		return
+	}
+
+	count, err := a.SweepAttachments()
+	crontab.SetResult(ctx, fmt.Sprintf("deleted %d attachments", count))
+	if err != nil {
+		a.logger.Zap.Errorf("Notice attachment sweep failed: %v", err)
+		crontab.SetError(ctx, err)
+	}
+}
+
+// SweepAttachments 删除已移除的附件以及超过 UnlinkedTTL 仍未关联的上传，先删除记录再释放文件，
+// 文件删除失败只记录日志，由孤立文件清理处理
+func (a NoticeService) SweepAttachments() (int, error) {
+	cutoff := time.Now().Add(-time.Duration(a.attachmentConfig.UnlinkedTTL) * time.Hour)
+
+	count := 0
+	for {
+		attachments, err := a.noticeAttachmentRepository.ListSweepable(cutoff, noticeAttachmentSweepBatchSize)
+		if err != nil || len(attachments) == 0 {
+			return count, err
+		}
+
+		ids := make([]uint64, 0, len(attachments))
+		for _, item := range attachments {
+			ids = append(ids, item.ID)
+		}
+		if err := a.noticeAttachmentRepository.BatchDelete(ids); err != nil {
+			return count, err
+		}
+
+		for _, item := range attachments {
+			if err := a.fileService.DeleteFile(item.FileURL); err != nil {
+				a.logger.Zap.Warnf("Failed to delete notice attachment file %s: %v", item.FileURL, err)
+			}
+		}
+
+		count += len(attachments)
+		if len(attachments) < noticeAttachmentSweepBatchSize {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// OperationLogService 操作审计日志
+type OperationLogService struct {
+	logger                 lib.Logger
+	operationLogRepository repository.OperationLogRepository
+}
+
+// NewOperationLogService creates a new operation log service
+func NewOperationLogService(
+	logger lib.Logger,
+	operationLogRepository repository.OperationLogRepository,
+) OperationLogService {
+	return OperationLogService{
+		logger:                 logger,
+		operationLogRepository: operationLogRepository,
+	}
+}
+
+// Save 批量写入操作日志
+func (a OperationLogService) Save(list system.OperationLogs) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	return a.operationLogRepository.CreateBatch(list)
+}
+
+// Query 分页查询操作日志
+func (a OperationLogService) Query(param *system.OperationLogQueryParam) (*system.OperationLogQueryResult, error) {
+	return a.operationLogRepository.Query(param)
+}
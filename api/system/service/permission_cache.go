@@ -2,25 +2,42 @@ package service
 
 import (
 	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+
 	"github.com/top-system/light-admin/api/system/repository"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 )
 
 const (
 	// 缓存过期时间
 	permCacheExpiration = 30 * time.Minute
+	// 空结果（负缓存）过期时间，避免不存在的数据反复穿透到数据库
+	permNegativeExpiration = time.Minute
+	// 过期时间随机抖动比例，避免大量 key 同时过期
+	permCacheJitter = 0.1
 
 	// 缓存键前缀
-	permCacheKeyUserRoles = "perm:user:%d:roles" // 用户角色ID列表
-	permCacheKeyUserPerms = "perm:user:%d:perms" // 用户权限标识列表
+	permCacheKeyUserRoles     = "perm:user:%d:roles"  // 用户角色ID列表
+	permCacheKeyUserPerms     = "perm:user:%d:perms"  // 用户权限标识列表
+	permCacheKeyUserGen       = "perm:user:%d:gen"    // 用户缓存的失效代数，清除用户缓存时更新
+	permCacheKeyRoutes        = "perm:routes:%d:%s"   // 角色组合对应的路由列表（带版本号）
+	permCacheKeyRoutesVersion = "perm:routes:version" // 路由缓存版本号，菜单或角色菜单变更时递增
 )
 
 // PermissionCache 权限缓存服务
+// 缓存未命中时通过 singleflight 合并同一 key 的并发加载，防止缓存击穿；
+// 加载期间缓存被清除时不写回加载结果，避免旧数据覆盖失效操作
 type PermissionCache struct {
 	logger             lib.Logger
 	cache              lib.Cache
+	group              *singleflight.Group
 	userRoleRepository repository.UserRoleRepository
 }
 
@@ -33,38 +50,90 @@ func NewPermissionCache(
 	return PermissionCache{
 		logger:             logger,
 		cache:              cache,
+		group:              &singleflight.Group{},
 		userRoleRepository: userRoleRepository,
 	}
 }
 
-// GetUserRoleIDs 获取用户角色ID列表（带缓存）
-func (a PermissionCache) GetUserRoleIDs(userID uint64) ([]uint64, error) {
-	cacheKey := fmt.Sprintf(permCacheKeyUserRoles, userID)
+// jitterExpiration 为过期时间增加随机抖动
+func jitterExpiration(expiration time.Duration) time.Duration {
+	return expiration + time.Duration(rand.Int64N(int64(float64(expiration)*permCacheJitter)+1))
+}
 
-	// 尝试从缓存获取
-	var roleIDs []uint64
-	if err := a.cache.Get(cacheKey, &roleIDs); err == nil {
-		return roleIDs, nil
+// loadWithCache 读取缓存，未命中时合并并发请求回源加载并写回缓存
+// 空结果使用较短的过期时间进行负缓存；genKey 不为空时为缓存的失效代数，加载期间代数变化说明缓存已被清除，此时不写回
+func loadWithCache[T any](a PermissionCache, cacheKey, genKey string, loader func() ([]T, error)) ([]T, error) {
+	var list []T
+	if err := a.cache.Get(cacheKey, &list); err == nil {
+		return list, nil
 	}
 
-	// 从数据库获取
-	roleIDs, err := a.userRoleRepository.GetRoleIDsByUserID(userID)
+	v, err, _ := a.group.Do(cacheKey, func() (interface{}, error) {
+		gen := a.generation(genKey)
+		list, err := loader()
+		if err != nil {
+			return nil, err
+		}
+		if a.generation(genKey) != gen {
+			return list, nil
+		}
+
+		expiration := permCacheExpiration
+		if len(list) == 0 {
+			expiration = permNegativeExpiration
+		}
+
+		if err := a.cache.Set(cacheKey, list, jitterExpiration(expiration)); err != nil {
+			a.logger.Zap.Warnf("Failed to cache %s: %v", cacheKey, err)
+		}
+		// 检查代数与写回之间缓存被清除时删除刚写回的数据
+		if a.generation(genKey) != gen {
+			if _, err := a.cache.Delete(cacheKey); err != nil {
+				a.logger.Zap.Warnf("Failed to invalidate %s: %v", cacheKey, err)
+			}
+		}
+
+		return list, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 写入缓存
-	if err := a.cache.Set(cacheKey, roleIDs, permCacheExpiration); err != nil {
-		a.logger.Zap.Warn("Failed to cache user roles: " + err.Error())
+	return v.([]T), nil
+}
+
+// generation 缓存的失效代数，genKey 为空或未设置时为 0
+func (a PermissionCache) generation(genKey string) int64 {
+	if genKey == "" {
+		return 0
+	}
+
+	var gen int64
+	if err := a.cache.Get(genKey, &gen); err != nil {
+		return 0
 	}
+	return gen
+}
+
+// GetUserRoleIDs 获取用户角色ID列表（带缓存）
+func (a PermissionCache) GetUserRoleIDs(userID uint64) ([]uint64, error) {
+	cacheKey := fmt.Sprintf(permCacheKeyUserRoles, userID)
 
-	return roleIDs, nil
+	return loadWithCache(a, cacheKey, fmt.Sprintf(permCacheKeyUserGen, userID), func() ([]uint64, error) {
+		return a.userRoleRepository.GetRoleIDsByUserID(userID)
+	})
+}
+
+// LoadUserPerms 获取用户权限标识（带缓存），未命中时通过 loader 加载
+func (a PermissionCache) LoadUserPerms(userID uint64, loader func() ([]string, error)) ([]string, error) {
+	cacheKey := fmt.Sprintf(permCacheKeyUserPerms, userID)
+	return loadWithCache(a, cacheKey, fmt.Sprintf(permCacheKeyUserGen, userID), loader)
 }
 
 // SetUserPerms 缓存用户权限
 func (a PermissionCache) SetUserPerms(userID uint64, perms []string) {
 	cacheKey := fmt.Sprintf(permCacheKeyUserPerms, userID)
-	if err := a.cache.Set(cacheKey, perms, permCacheExpiration); err != nil {
+	if err := a.cache.Set(cacheKey, perms, jitterExpiration(permCacheExpiration)); err != nil {
 		a.logger.Zap.Warn("Failed to cache user perms: " + err.Error())
 	}
 }
@@ -79,18 +148,64 @@ func (a PermissionCache) GetUserPerms(userID uint64) ([]string, bool) {
 	return nil, false
 }
 
-// InvalidateUserCache 清除用户权限缓存
-func (a PermissionCache) InvalidateUserCache(userID uint64) {
-	rolesKey := fmt.Sprintf(permCacheKeyUserRoles, userID)
-	permsKey := fmt.Sprintf(permCacheKeyUserPerms, userID)
+// LoadRoutes 获取角色组合对应的路由列表（带缓存），未命中时通过 loader 加载
+// 相同角色组合的用户共享同一份缓存；缓存键带版本号，失效后加载的旧数据写入旧版本的键，不需要失效代数
+func (a PermissionCache) LoadRoutes(roleIDs []uint64, isSuperAdmin bool, loader func() ([]*dto.RouteVO, error)) ([]*dto.RouteVO, error) {
+	cacheKey := fmt.Sprintf(permCacheKeyRoutes, a.routesVersion(), routesRoleKey(roleIDs, isSuperAdmin))
+	return loadWithCache(a, cacheKey, "", loader)
+}
 
-	if _, err := a.cache.Delete(rolesKey, permsKey); err != nil {
-		a.logger.Zap.Warn("Failed to invalidate user cache: " + err.Error())
+// routesRoleKey 生成角色组合的缓存键（与角色顺序无关）
+func routesRoleKey(roleIDs []uint64, isSuperAdmin bool) string {
+	if isSuperAdmin {
+		return "root"
 	}
+
+	ids := make([]uint64, len(roleIDs))
+	copy(ids, roleIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(id, 10)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// routesVersion 获取路由缓存版本号
+func (a PermissionCache) routesVersion() int64 {
+	var version int64
+	if err := a.cache.Get(permCacheKeyRoutesVersion, &version); err == nil {
+		return version
+	}
+
+	version = time.Now().UnixNano()
+	if err := a.cache.Set(permCacheKeyRoutesVersion, version, 0); err != nil {
+		a.logger.Zap.Warn("Failed to init routes cache version: " + err.Error())
+	}
+
+	return version
+}
+
+// InvalidateRoutesCache 使所有路由缓存失效（菜单变更时调用）
+// 通过递增版本号实现，旧版本的缓存会自然过期
+func (a PermissionCache) InvalidateRoutesCache() {
+	if err := a.cache.Set(permCacheKeyRoutesVersion, time.Now().UnixNano(), 0); err != nil {
+		a.logger.Zap.Warn("Failed to invalidate routes cache: " + err.Error())
+	}
+}
+
+// InvalidateUserCache 清除用户权限缓存
+func (a PermissionCache) InvalidateUserCache(userID uint64) {
+	a.invalidateUsers([]uint64{userID})
 }
 
 // InvalidateRoleCache 清除角色权限缓存（角色权限变更时调用）
 func (a PermissionCache) InvalidateRoleCache(roleID uint64) {
+	// 角色菜单变更会影响路由
+	a.InvalidateRoutesCache()
+
 	// 角色权限变更时，需要清除所有拥有该角色的用户的权限缓存
 	userIDs, err := a.userRoleRepository.GetUserIDsByRoleID(roleID)
 	if err != nil {
@@ -98,20 +213,32 @@ func (a PermissionCache) InvalidateRoleCache(roleID uint64) {
 		return
 	}
 
+	a.invalidateUsers(userIDs)
+}
+
+// invalidateUsers 先更新失效代数再删除缓存，正在进行的加载不会写回旧数据，之后的请求重新加载
+func (a PermissionCache) invalidateUsers(userIDs []uint64) {
 	if len(userIDs) == 0 {
 		return
 	}
 
-	// 批量收集所有需要删除的 cache key，一次性删除
+	gen := time.Now().UnixNano()
 	keys := make([]string, 0, len(userIDs)*2)
 	for _, userID := range userIDs {
+		if err := a.cache.Set(fmt.Sprintf(permCacheKeyUserGen, userID), gen, permCacheExpiration); err != nil {
+			a.logger.Zap.Warn("Failed to update user cache generation: " + err.Error())
+		}
 		keys = append(keys,
 			fmt.Sprintf(permCacheKeyUserRoles, userID),
 			fmt.Sprintf(permCacheKeyUserPerms, userID),
 		)
 	}
 
+	// 批量删除，同时不再合并到正在进行的加载
 	if _, err := a.cache.Delete(keys...); err != nil {
-		a.logger.Zap.Warn("Failed to batch invalidate role cache: " + err.Error())
+		a.logger.Zap.Warn("Failed to invalidate user cache: " + err.Error())
+	}
+	for _, key := range keys {
+		a.group.Forget(key)
 	}
 }
@@ -1,14 +1,25 @@
 package service
 
 import (
+	"net/http"
+	"sort"
+	"strings"
+
 	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/apimeta"
 )
 
 // PermissionService 基于 perm 标识的权限服务
 type PermissionService struct {
 	logger             lib.Logger
+	handler            lib.HttpHandler
+	registry           lib.PermRegistry
+	featureModules     lib.FeatureModules
 	cache              PermissionCache
 	menuRepository     repository.MenuRepository
 	roleMenuRepository repository.RoleMenuRepository
@@ -19,6 +30,9 @@ type PermissionService struct {
 // NewPermissionService creates a new permission service
 func NewPermissionService(
 	logger lib.Logger,
+	handler lib.HttpHandler,
+	registry lib.PermRegistry,
+	featureModules lib.FeatureModules,
 	cache PermissionCache,
 	menuRepository repository.MenuRepository,
 	roleMenuRepository repository.RoleMenuRepository,
@@ -27,6 +41,9 @@ func NewPermissionService(
 ) PermissionService {
 	return PermissionService{
 		logger:             logger,
+		handler:            handler,
+		registry:           registry,
+		featureModules:     featureModules,
 		cache:              cache,
 		menuRepository:     menuRepository,
 		roleMenuRepository: roleMenuRepository,
@@ -40,6 +57,40 @@ func (a PermissionService) GetRolePerms(roleIDs []uint64) ([]string, error) {
 	return a.menuRepository.GetButtonPermsByRoleIDs(roleIDs)
 }
 
+// GetRoleCodePerms 根据角色编码获取权限标识列表（服务账号使用）
+func (a PermissionService) GetRoleCodePerms(codes []string) ([]string, error) {
+	roleIDs := make([]uint64, 0, len(codes))
+	for _, code := range codes {
+		role, err := a.roleRepository.GetByCode(code)
+		if errors.Is(err, errors.DatabaseRecordNotFound) {
+			a.logger.Zap.Warnf("service account role %s not found", code)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	if len(roleIDs) == 0 {
+		return []string{}, nil
+	}
+
+	return a.GetRolePerms(roleIDs)
+}
+
+// GetClaimsPerms 获取当前身份的权限标识，服务账号按绑定的角色计算，API Key 使用认证时得出的授权范围
+func (a PermissionService) GetClaimsPerms(claims *dto.JwtClaims) ([]string, error) {
+	if claims.ApiKeyID != 0 {
+		return claims.Scopes, nil
+	}
+
+	if claims.ServiceAccount {
+		return a.GetRoleCodePerms(claims.Roles)
+	}
+
+	return a.GetUserPerms(claims.ID)
+}
+
 // HasPerm 检查角色是否有指定权限
 func (a PermissionService) HasPerm(roleIDs []uint64, perm string) (bool, error) {
 	if perm == "" {
@@ -51,13 +102,22 @@ func (a PermissionService) HasPerm(roleIDs []uint64, perm string) (bool, error)
 		return false, err
 	}
 
+	return MatchPerm(perms, perm), nil
+}
+
+// MatchPerm 检查权限标识列表中是否包含指定权限（支持 *:*:* 通配）
+func MatchPerm(perms []string, perm string) bool {
+	if perm == "" {
+		return true
+	}
+
 	for _, p := range perms {
 		if p == perm || p == "*:*:*" {
-			return true, nil
+			return true
 		}
 	}
 
-	return false, nil
+	return false
 }
 
 // GetUserRoleIDs 获取用户的角色ID列表（带缓存）
@@ -67,26 +127,14 @@ func (a PermissionService) GetUserRoleIDs(userID uint64) ([]uint64, error) {
 
 // GetUserPerms 获取用户的所有权限标识（带缓存）
 func (a PermissionService) GetUserPerms(userID uint64) ([]string, error) {
-	// 尝试从缓存获取
-	if perms, ok := a.cache.GetUserPerms(userID); ok {
-		return perms, nil
-	}
-
-	// 从数据库获取
-	roleIDs, err := a.GetUserRoleIDs(userID)
-	if err != nil {
-		return nil, err
-	}
-
-	perms, err := a.GetRolePerms(roleIDs)
-	if err != nil {
-		return nil, err
-	}
-
-	// 写入缓存
-	a.cache.SetUserPerms(userID, perms)
+	return a.cache.LoadUserPerms(userID, func() ([]string, error) {
+		roleIDs, err := a.GetUserRoleIDs(userID)
+		if err != nil {
+			return nil, err
+		}
 
-	return perms, nil
+		return a.GetRolePerms(roleIDs)
+	})
 }
 
 // GetUserRoleCodes 获取用户的角色编码列表
@@ -110,3 +158,127 @@ func (a PermissionService) GetUserRoleCodes(userID uint64) ([]string, error) {
 
 	return roleQR.List.ToCodes(), nil
 }
+
+// GetRouteMatrix 导出 API 路由→权限矩阵，用于权限审计
+// 未通过 PermRegistry 声明的路由 Declared 为 false
+func (a PermissionService) GetRouteMatrix() ([]*dto.RoutePermVO, error) {
+	menuQR, err := a.menuRepository.Query(&system.MenuQueryParam{
+		PaginationParam: dto.PaginationParam{PageNum: 1, PageSize: 9999},
+		Type:            constants.MenuTypeButton,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permMenus := make(map[string]string, len(menuQR.List))
+	for _, menu := range menuQR.List {
+		if menu.Perm != "" {
+			permMenus[menu.Perm] = menu.Name
+		}
+	}
+
+	matrix := make([]*dto.RoutePermVO, 0)
+	for _, route := range a.handler.Engine.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") || strings.HasPrefix(route.Method, "echo_") {
+			continue
+		}
+
+		rp, declared := a.registry.Get(route.Method, route.Path)
+		matrix = append(matrix, &dto.RoutePermVO{
+			Method:   route.Method,
+			Path:     route.Path,
+			Perm:     rp.Perm,
+			Public:   rp.Public,
+			Declared: declared,
+			MenuName: permMenus[rp.Perm],
+		})
+	}
+
+	sort.Slice(matrix, func(i, j int) bool {
+		if matrix[i].Path != matrix[j].Path {
+			return matrix[i].Path < matrix[j].Path
+		}
+		return matrix[i].Method < matrix[j].Method
+	})
+
+	return matrix, nil
+}
+
+// GetActions 导出当前身份可访问的操作描述（用于前端命令面板及命令行工具生成）
+// superAdmin 为 true 时返回全部已声明的操作（不含已禁用功能模块的接口）；标题依次取路由声明的标题、权限对应的菜单按钮名称、控制器方法名
+func (a PermissionService) GetActions(claims *dto.JwtClaims, superAdmin bool) ([]*dto.ActionVO, error) {
+	var perms []string
+	if !superAdmin {
+		var err error
+		if perms, err = a.GetClaimsPerms(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	menuQR, err := a.menuRepository.Query(&system.MenuQueryParam{
+		PaginationParam: dto.PaginationParam{PageNum: 1, PageSize: 9999},
+		Type:            constants.MenuTypeButton,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permMenus := make(map[string]string, len(menuQR.List))
+	for _, menu := range menuQR.List {
+		if menu.Perm != "" {
+			permMenus[menu.Perm] = menu.Name
+		}
+	}
+
+	actions := make([]*dto.ActionVO, 0)
+	for _, route := range a.handler.Engine.Routes() {
+		rp, declared := a.registry.Get(route.Method, route.Path)
+		if !declared || rp.Public {
+			continue
+		}
+		if !superAdmin && !MatchPerm(perms, rp.Perm) {
+			continue
+		}
+		// 已禁用模块的接口视为未注册
+		if _, disabled := a.featureModules.DisabledByPath(route.Path); disabled {
+			continue
+		}
+
+		controller, action := apimeta.ParseHandlerName(route.Name)
+
+		title := rp.Title
+		if title == "" {
+			title = permMenus[rp.Perm]
+		}
+		if title == "" {
+			title = strings.TrimSuffix(controller, "Controller") + "." + action
+		}
+
+		params := apimeta.PathParams(route.Path)
+		in := apimeta.InBody
+		if route.Method == http.MethodGet || route.Method == http.MethodDelete {
+			in = apimeta.InQuery
+		}
+		params = append(params, apimeta.StructParams(rp.Params, in)...)
+
+		actions = append(actions, &dto.ActionVO{
+			Key:        route.Method + " " + route.Path,
+			Title:      title,
+			Method:     route.Method,
+			Path:       route.Path,
+			Perm:       rp.Perm,
+			Controller: controller,
+			Action:     action,
+			Params:     params,
+		})
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].Path != actions[j].Path {
+			return actions[i].Path < actions[j].Path
+		}
+		return actions[i].Method < actions[j].Method
+	})
+
+	return actions, nil
+}
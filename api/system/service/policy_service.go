@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/policy"
+)
+
+const policyReloadTaskName = "casbin_policy_reload"
+
+// PolicyService 访问策略服务
+// 策略保存在 t_casbin_rule 中，每次修改后重新加载到评估器；
+// 主体可以是用户名或角色编码，用户在系统中分配的角色自动参与匹配
+type PolicyService struct {
+	logger               lib.Logger
+	config               *lib.CasbinConfig
+	enforcer             *policy.Enforcer
+	userService          UserService
+	permissionService    PermissionService
+	casbinRuleRepository repository.CasbinRuleRepository
+	lockService          LockService
+}
+
+// NewPolicyService 创建访问策略服务，开启 AutoLoad 时注册定时重新加载任务
+func NewPolicyService(
+	logger lib.Logger,
+	config lib.Config,
+	crontab lib.Crontab,
+	userService UserService,
+	permissionService PermissionService,
+	casbinRuleRepository repository.CasbinRuleRepository,
+	lockService LockService,
+) PolicyService {
+	cfg := &lib.CasbinConfig{}
+	if config.Casbin != nil {
+		cfg = config.Casbin
+	}
+
+	svc := PolicyService{
+		logger:               logger,
+		config:               cfg,
+		enforcer:             policy.NewEnforcer(),
+		userService:          userService,
+		permissionService:    permissionService,
+		casbinRuleRepository: casbinRuleRepository,
+		lockService:          lockService,
+	}
+
+	if cfg.Enable && cfg.AutoLoad && cfg.AutoLoadInternal > 0 && crontab.IsEnabled() {
+		spec := fmt.Sprintf("@every %ds", cfg.AutoLoadInternal)
+		if err := crontab.AddTask(policyReloadTaskName, spec, svc.runReload); err != nil {
+			logger.Zap.Errorf("Failed to register policy reload task: %v", err)
+		}
+	}
+
+	return svc
+}
+
+// WithTrx delegates transaction to repository database
+// 修改后在同一事务中重新加载，事务回滚时（重新加载失败）评估器保留原有策略
+func (a PolicyService) WithTrx(trxHandle *gorm.DB) PolicyService {
+	a.casbinRuleRepository = a.casbinRuleRepository.WithTrx(trxHandle)
+	return a
+}
+
+func (a PolicyService) runReload(ctx context.Context) {
+	if err := a.Reload(); err != nil {
+		a.logger.Zap.Errorf("Failed to reload policies: %v", err)
+		crontab.SetError(ctx, err)
+	}
+}
+
+// Reload 从数据库重新加载策略
+func (a PolicyService) Reload() error {
+	list, err := a.casbinRuleRepository.List()
+	if err != nil {
+		return err
+	}
+
+	if err := a.enforcer.Load(list.ToRules()); err != nil {
+		return errors.Wrap(errors.PolicyInvalid, err.Error())
+	}
+
+	return nil
+}
+
+func (a PolicyService) ensureLoaded() error {
+	if a.enforcer.Loaded() {
+		return nil
+	}
+
+	return a.Reload()
+}
+
+// Query 分页查询策略
+func (a PolicyService) Query(param *system.PolicyQueryParam) (*system.PolicyQueryResult, error) {
+	return a.casbinRuleRepository.Query(param)
+}
+
+// Get 获取策略
+func (a PolicyService) Get(id uint64) (*system.CasbinRule, error) {
+	return a.casbinRuleRepository.Get(id)
+}
+
+// checkRule 校验策略格式并检查是否重复
+func (a PolicyService) checkRule(rule *system.CasbinRule, excludeID uint64) error {
+	if err := rule.ToRule().Validate(); err != nil {
+		return errors.Wrap(errors.PolicyInvalid, err.Error())
+	}
+
+	if exists, err := a.casbinRuleRepository.Exists(rule, excludeID); err != nil {
+		return err
+	} else if exists {
+		return errors.PolicyAlreadyExists
+	}
+
+	return nil
+}
+
+// Create 创建策略
+func (a PolicyService) Create(form *system.PolicyForm) (uint64, error) {
+	rule := &system.CasbinRule{PType: form.PType, V0: form.V0, V1: form.V1, V2: form.V2}
+	if err := a.checkRule(rule, 0); err != nil {
+		return 0, err
+	}
+
+	if err := a.casbinRuleRepository.Create(rule); err != nil {
+		return 0, err
+	}
+
+	return rule.ID, a.Reload()
+}
+
+// Update 更新策略
+func (a PolicyService) Update(id uint64, form *system.PolicyForm) error {
+	if _, err := a.casbinRuleRepository.Get(id); err != nil {
+		return err
+	}
+
+	rule := &system.CasbinRule{PType: form.PType, V0: form.V0, V1: form.V1, V2: form.V2}
+	if err := a.checkRule(rule, id); err != nil {
+		return err
+	}
+
+	if err := a.casbinRuleRepository.Update(id, rule); err != nil {
+		return err
+	}
+
+	return a.Reload()
+}
+
+// Delete 批量删除策略，ids 以英文逗号分割
+func (a PolicyService) Delete(ids string) error {
+	idList := make([]uint64, 0)
+	for _, idStr := range strings.Split(ids, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 64)
+		if err != nil {
+			continue
+		}
+		idList = append(idList, id)
+	}
+
+	if len(idList) == 0 {
+		return errors.New("删除的策略数据为空")
+	}
+
+	if err := a.casbinRuleRepository.BatchDelete(idList); err != nil {
+		return err
+	}
+
+	return a.Reload()
+}
+
+// Export 按 casbin 策略文件格式导出全部策略
+func (a PolicyService) Export(w io.Writer) error {
+	list, err := a.casbinRuleRepository.List()
+	if err != nil {
+		return err
+	}
+
+	return policy.WriteCSV(w, list.ToRules())
+}
+
+// Import 导入 casbin 策略文件，文件中任意一行无效时整体不导入
+// 导入期间持有权限同步锁，与其他权限同步操作互斥
+func (a PolicyService) Import(r io.Reader, replace bool, operator uint64) (result *system.PolicyImportResult, err error) {
+	err = a.lockService.WithLock(system.LockPermissionSync, operator, "import policies", func() error {
+		result, err = a.importRules(r, replace)
+		return err
+	})
+	return result, err
+}
+
+func (a PolicyService) importRules(r io.Reader, replace bool) (*system.PolicyImportResult, error) {
+	rules, err := policy.ParseCSV(r)
+	if err != nil {
+		return nil, errors.Wrap(errors.PolicyInvalid, err.Error())
+	}
+
+	list := make(system.CasbinRules, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, &system.CasbinRule{PType: rule.PType, V0: rule.V0, V1: rule.V1, V2: rule.V2})
+	}
+
+	imported, err := a.casbinRuleRepository.Import(list, replace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &system.PolicyImportResult{
+		Replace:  replace,
+		Total:    len(rules),
+		Imported: imported,
+		Skipped:  len(rules) - imported,
+	}, nil
+}
+
+// Test 测试用户能否以 Method 访问 Path
+func (a PolicyService) Test(form *system.PolicyTestForm) (*system.PolicyTestResult, error) {
+	if form.Username == "" || form.Method == "" || form.Path == "" {
+		return nil, errors.New("用户名、请求方法和路径不能为空")
+	}
+
+	result := &system.PolicyTestResult{
+		Username: form.Username,
+		Method:   strings.ToUpper(form.Method),
+		Path:     form.Path,
+		Enforced: a.config.Enable && a.config.Enforce,
+	}
+
+	if a.userService.IsSuperAdmin(form.Username) {
+		result.SuperAdmin = true
+		result.Allowed = true
+		result.Subjects = []string{form.Username}
+		return result, nil
+	}
+
+	user, err := a.userService.GetByUsername(form.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := a.permissionService.GetUserRoleCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.Roles = roles
+
+	if err := a.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	result.Result = a.enforcer.Enforce(policy.Request{
+		Sub:   form.Username,
+		Roles: roles,
+		Obj:   form.Path,
+		Act:   result.Method,
+	})
+	return result, nil
+}
+
+// Enforce 按策略检查当前身份能否访问请求，服务账号按绑定的角色匹配
+func (a PolicyService) Enforce(claims *dto.JwtClaims, r *http.Request) (bool, error) {
+	roles := claims.Roles
+	if !claims.ServiceAccount {
+		var err error
+		if roles, err = a.permissionService.GetUserRoleCodes(claims.ID); err != nil {
+			return false, err
+		}
+	}
+
+	if err := a.ensureLoaded(); err != nil {
+		return false, err
+	}
+
+	result := a.enforcer.Enforce(policy.Request{
+		Sub:   claims.Username,
+		Roles: roles,
+		Obj:   r.URL.Path,
+		Act:   r.Method,
+	})
+	if a.config.Debug {
+		a.logger.Zap.Debugf("policy %s %s %s: allowed=%v matched=%v", claims.Username, r.Method, r.URL.Path, result.Allowed, result.Matched)
+	}
+
+	return result.Allowed, nil
+}
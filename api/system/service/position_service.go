@@ -0,0 +1,151 @@
+package service
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// PositionService 岗位服务
+type PositionService struct {
+	logger                 lib.Logger
+	positionRepository     repository.PositionRepository
+	userPositionRepository repository.UserPositionRepository
+}
+
+// NewPositionService creates a new position service
+func NewPositionService(
+	logger lib.Logger,
+	positionRepository repository.PositionRepository,
+	userPositionRepository repository.UserPositionRepository,
+) PositionService {
+	return PositionService{
+		logger:                 logger,
+		positionRepository:     positionRepository,
+		userPositionRepository: userPositionRepository,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a PositionService) WithTrx(trxHandle *gorm.DB) PositionService {
+	a.positionRepository = a.positionRepository.WithTrx(trxHandle)
+	a.userPositionRepository = a.userPositionRepository.WithTrx(trxHandle)
+	return a
+}
+
+// Query 查询岗位分页列表，附带每个岗位的任职用户数
+func (a PositionService) Query(param *system.PositionQueryParam) (*system.PositionQueryResult, error) {
+	qr, err := a.positionRepository.Query(param)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(qr.List))
+	for _, position := range qr.List {
+		ids = append(ids, position.ID)
+	}
+
+	counts, err := a.userPositionRepository.CountByPositions(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, position := range qr.List {
+		position.UserCount = counts[position.ID]
+	}
+
+	return qr, nil
+}
+
+// Get 获取岗位
+func (a PositionService) Get(id uint64) (*system.Position, error) {
+	position, err := a.positionRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := a.userPositionRepository.CountByPositions([]uint64{id})
+	if err != nil {
+		return nil, err
+	}
+	position.UserCount = counts[id]
+
+	return position, nil
+}
+
+// ListOptions 岗位下拉选项（仅启用的岗位）
+func (a PositionService) ListOptions() ([]*system.PositionOption, error) {
+	list, err := a.positionRepository.GetAllEnabled()
+	if err != nil {
+		return nil, err
+	}
+
+	return list.ToOptions(), nil
+}
+
+// Create 新增岗位
+func (a PositionService) Create(form *system.PositionForm, createBy uint64) (uint64, error) {
+	code := strings.TrimSpace(form.Code)
+	if exist, err := a.positionRepository.GetByCode(code); err != nil {
+		return 0, err
+	} else if exist != nil {
+		return 0, errors.PositionCodeAlreadyExists
+	}
+
+	position := &system.Position{
+		Name:     strings.TrimSpace(form.Name),
+		Code:     code,
+		Sort:     form.Sort,
+		Status:   form.Status,
+		Remark:   form.Remark,
+		CreateBy: createBy,
+	}
+	if err := a.positionRepository.Create(position); err != nil {
+		return 0, err
+	}
+
+	return position.ID, nil
+}
+
+// Update 修改岗位
+func (a PositionService) Update(id uint64, form *system.PositionForm, updateBy uint64) error {
+	if _, err := a.positionRepository.Get(id); err != nil {
+		return err
+	}
+
+	code := strings.TrimSpace(form.Code)
+	if exist, err := a.positionRepository.GetByCode(code); err != nil {
+		return err
+	} else if exist != nil && exist.ID != id {
+		return errors.PositionCodeAlreadyExists
+	}
+
+	return a.positionRepository.Update(id, &system.Position{
+		Name:     strings.TrimSpace(form.Name),
+		Code:     code,
+		Sort:     form.Sort,
+		Status:   form.Status,
+		Remark:   form.Remark,
+		UpdateBy: updateBy,
+	})
+}
+
+// Delete 删除岗位，仍有用户任职时不允许删除
+func (a PositionService) Delete(id uint64) error {
+	if _, err := a.positionRepository.Get(id); err != nil {
+		return err
+	}
+
+	counts, err := a.userPositionRepository.CountByPositions([]uint64{id})
+	if err != nil {
+		return err
+	} else if counts[id] > 0 {
+		return errors.PositionHasUsers
+	}
+
+	return a.positionRepository.Delete(id)
+}
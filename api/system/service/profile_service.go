@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+	"time"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+const (
+	// cpu、trace 默认采集时长（秒）
+	profileDefaultSeconds = 30
+	// 内存中保留的采集记录数
+	profileKeep = 20
+)
+
+// ProfileService 性能分析服务
+// 采集 CPU、堆、goroutine 等 profile 及运行时 trace，通过文件服务保存供下载。
+// cpu、trace 在后台采集，不受 HTTP 写超时限制；采集记录只保存在当前实例内存中
+type ProfileService struct {
+	logger      lib.Logger
+	fileService platformservice.FileService
+	state       *profileState
+}
+
+type profileState struct {
+	mu       sync.Mutex
+	nextID   uint64
+	captures []*dto.ProfileCapture // 按创建时间倒序
+}
+
+// NewProfileService creates a new profile service
+func NewProfileService(logger lib.Logger, fileService platformservice.FileService) ProfileService {
+	return ProfileService{
+		logger:      logger,
+		fileService: fileService,
+		state:       &profileState{},
+	}
+}
+
+// Capture 采集 profile
+// heap、allocs、goroutine 立即生成快照并保存，cpu、trace 开始采集后立即返回，
+// 采集结束后保存文件，通过 List 查看状态
+func (a ProfileService) Capture(form *dto.ProfileForm, createBy uint64) (*dto.ProfileCapture, error) {
+	seconds := form.Seconds
+	if seconds <= 0 {
+		seconds = profileDefaultSeconds
+	}
+
+	buf := new(bytes.Buffer)
+	switch form.Type {
+	case dto.ProfileTypeCPU:
+		if err := pprof.StartCPUProfile(buf); err != nil {
+			return nil, errors.ProfileInProgress
+		}
+		record := a.state.add(form.Type, seconds, createBy)
+		go a.finish(record, buf, seconds, pprof.StopCPUProfile)
+		return a.state.get(record.ID)
+	case dto.ProfileTypeTrace:
+		if err := trace.Start(buf); err != nil {
+			return nil, errors.ProfileInProgress
+		}
+		record := a.state.add(form.Type, seconds, createBy)
+		go a.finish(record, buf, seconds, trace.Stop)
+		return a.state.get(record.ID)
+	case dto.ProfileTypeHeap, dto.ProfileTypeAllocs, dto.ProfileTypeGoroutine:
+		// 快照无采集时长
+		record := a.state.add(form.Type, 0, createBy)
+		if form.Type != dto.ProfileTypeGoroutine {
+			runtime.GC()
+		}
+		if err := pprof.Lookup(form.Type).WriteTo(buf, 0); err != nil {
+			a.state.fail(record.ID, err)
+		} else {
+			a.save(record, buf)
+		}
+		return a.state.get(record.ID)
+	default:
+		return nil, errors.ProfileTypeInvalid
+	}
+}
+
+// List 返回当前实例的采集记录，按创建时间倒序
+func (a ProfileService) List() []dto.ProfileCapture {
+	a.state.mu.Lock()
+	defer a.state.mu.Unlock()
+
+	list := make([]dto.ProfileCapture, 0, len(a.state.captures))
+	for _, c := range a.state.captures {
+		list = append(list, *c)
+	}
+	return list
+}
+
+// Open 打开已采集的 profile 文件，使用后需关闭
+func (a ProfileService) Open(id uint64) (*dto.ProfileCapture, *platformservice.StoredObject, error) {
+	record, err := a.state.get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if record.Status != dto.ProfileCompleted || record.FileURL == "" {
+		return nil, nil, errors.ProfileNotReady
+	}
+
+	object, err := a.fileService.OpenFile(record.FileURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, object, nil
+}
+
+// finish 等待采集时长结束后停止采集并保存
+func (a ProfileService) finish(record *dto.ProfileCapture, buf *bytes.Buffer, seconds int, stop func()) {
+	time.Sleep(time.Duration(seconds) * time.Second)
+	stop()
+	a.save(record, buf)
+}
+
+func (a ProfileService) save(record *dto.ProfileCapture, buf *bytes.Buffer) {
+	ext := "pprof"
+	if record.Type == dto.ProfileTypeTrace {
+		ext = "trace"
+	}
+
+	filename := fmt.Sprintf("profile-%s-%s-%d.%s", record.Type, record.StartTime.Format("20060102150405"), record.ID, ext)
+	size := int64(buf.Len())
+	info, err := a.fileService.UploadFile(filename, buf, size, "application/octet-stream")
+	if err != nil {
+		a.logger.Zap.Errorf("Failed to save %s profile %d: %v", record.Type, record.ID, err)
+		a.state.fail(record.ID, err)
+		return
+	}
+
+	a.state.update(record.ID, func(c *dto.ProfileCapture) {
+		now := time.Now()
+		c.Status = dto.ProfileCompleted
+		c.FileName = info.Name
+		c.FileURL = info.URL
+		c.FileSize = size
+		c.FinishTime = &now
+	})
+}
+
+// add 新增采集记录，超出保留数时丢弃最早的记录
+func (s *profileState) add(typ string, seconds int, createBy uint64) *dto.ProfileCapture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	record := &dto.ProfileCapture{
+		ID:        s.nextID,
+		Type:      typ,
+		Seconds:   seconds,
+		Status:    dto.ProfileRunning,
+		CreateBy:  createBy,
+		StartTime: time.Now(),
+	}
+
+	s.captures = append([]*dto.ProfileCapture{record}, s.captures...)
+	if len(s.captures) > profileKeep {
+		s.captures = s.captures[:profileKeep]
+	}
+
+	return record
+}
+
+// get 返回采集记录的副本
+func (s *profileState) get(id uint64) (*dto.ProfileCapture, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.captures {
+		if c.ID == id {
+			record := *c
+			return &record, nil
+		}
+	}
+	return nil, errors.ProfileNotFound
+}
+
+func (s *profileState) update(id uint64, fn func(c *dto.ProfileCapture)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.captures {
+		if c.ID == id {
+			fn(c)
+			return
+		}
+	}
+}
+
+func (s *profileState) fail(id uint64, err error) {
+	s.update(id, func(c *dto.ProfileCapture) {
+		now := time.Now()
+		c.Status = dto.ProfileFailed
+		c.Error = err.Error()
+		c.FinishTime = &now
+	})
+}
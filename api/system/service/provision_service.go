@@ -0,0 +1,606 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/hash"
+	"github.com/top-system/light-admin/pkg/random"
+)
+
+// provisionPasswordLength 生成的初始密码长度
+const provisionPasswordLength = 12
+
+// ProvisionService 部门与用户批量开通，用于系统初次上线时导入大量账号
+// 所有行校验通过后才在同一事务中创建，任一行失败则不创建任何数据
+type ProvisionService struct {
+	logger             lib.Logger
+	config             lib.Config
+	db                 lib.Database
+	mailer             lib.Mailer
+	deptRepository     repository.DeptRepository
+	userRepository     repository.UserRepository
+	userRoleRepository repository.UserRoleRepository
+	roleRepository     repository.RoleRepository
+	responseCache      lib.ResponseCache
+}
+
+// NewProvisionService creates a new provision service
+func NewProvisionService(
+	logger lib.Logger,
+	config lib.Config,
+	db lib.Database,
+	mailer lib.Mailer,
+	deptRepository repository.DeptRepository,
+	userRepository repository.UserRepository,
+	userRoleRepository repository.UserRoleRepository,
+	roleRepository repository.RoleRepository,
+	responseCache lib.ResponseCache,
+) ProvisionService {
+	return ProvisionService{
+		logger:             logger,
+		config:             config,
+		db:                 db,
+		mailer:             mailer,
+		deptRepository:     deptRepository,
+		userRepository:     userRepository,
+		userRoleRepository: userRoleRepository,
+		roleRepository:     roleRepository,
+		responseCache:      responseCache,
+	}
+}
+
+// ParseDepts 解析部门 CSV，表头: code,name,parent_code,sort
+func (a ProvisionService) ParseDepts(r io.Reader) ([]*system.ProvisionDeptRow, error) {
+	rows := make([]*system.ProvisionDeptRow, 0)
+	err := readProvisionCSV(r, []string{"code", "name"}, func(line int, get func(string) string) error {
+		sort, err := parseProvisionInt(get("sort"))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid sort %q", line, get("sort"))
+		}
+
+		rows = append(rows, &system.ProvisionDeptRow{
+			Line:       line,
+			Code:       get("code"),
+			Name:       get("name"),
+			ParentCode: get("parent_code"),
+			Sort:       sort,
+		})
+		return nil
+	})
+	return rows, err
+}
+
+// ParseUsers 解析用户 CSV，表头: username,nickname,email,mobile,gender,dept_code,role_codes
+func (a ProvisionService) ParseUsers(r io.Reader) ([]*system.ProvisionUserRow, error) {
+	rows := make([]*system.ProvisionUserRow, 0)
+	err := readProvisionCSV(r, []string{"username"}, func(line int, get func(string) string) error {
+		gender, err := parseProvisionInt(get("gender"))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid gender %q", line, get("gender"))
+		}
+
+		roleCodes := make([]string, 0)
+		for _, code := range strings.FieldsFunc(get("role_codes"), func(r rune) bool { return r == '|' || r == ';' }) {
+			if code = strings.TrimSpace(code); code != "" {
+				roleCodes = append(roleCodes, code)
+			}
+		}
+
+		rows = append(rows, &system.ProvisionUserRow{
+			Line:      line,
+			Username:  get("username"),
+			Nickname:  get("nickname"),
+			Email:     get("email"),
+			Mobile:    get("mobile"),
+			Gender:    gender,
+			DeptCode:  get("dept_code"),
+			RoleCodes: roleCodes,
+		})
+		return nil
+	})
+	return rows, err
+}
+
+// Provision 校验并创建部门和用户
+// 部门按 parent_code 引用文件内或已有部门，用户按 dept_code、role_codes 引用部门和角色；
+// 已存在的部门编号直接复用，已存在的用户名视为错误
+func (a ProvisionService) Provision(
+	depts []*system.ProvisionDeptRow,
+	users []*system.ProvisionUserRow,
+	opts system.ProvisionOptions,
+) (*system.ProvisionResult, error) {
+	if len(depts) == 0 && len(users) == 0 {
+		return nil, errors.ProvisionEmpty
+	}
+
+	switch opts.Notify {
+	case "":
+		opts.Notify = system.ProvisionNotifyReturn
+	case system.ProvisionNotifyReturn:
+	case system.ProvisionNotifyEmail:
+		if !a.mailer.IsEnabled() {
+			return nil, errors.ProvisionMailDisabled
+		}
+	default:
+		return nil, errors.ProvisionInvalidNotify
+	}
+
+	plan, err := a.validate(depts, users)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &system.ProvisionResult{DryRun: opts.DryRun, Rows: plan.rows}
+	for _, row := range result.Rows {
+		if row.Status == system.ProvisionRowError {
+			result.Failed++
+		}
+	}
+	if result.Failed > 0 || opts.DryRun {
+		return result, nil
+	}
+
+	if err = a.hashPasswords(plan.users); err != nil {
+		return nil, err
+	}
+
+	if err = a.db.ORM.Transaction(func(tx *gorm.DB) error {
+		return a.create(tx, plan, opts.Operator)
+	}); err != nil {
+		return nil, err
+	}
+
+	result.Committed = true
+	for _, d := range plan.depts {
+		if d.result.Status == system.ProvisionRowCreated {
+			result.Depts++
+		}
+	}
+	result.Users = len(plan.users)
+
+	a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
+	a.notify(plan.users, opts.Notify)
+
+	return result, nil
+}
+
+// provisionDept 部门行处理计划
+type provisionDept struct {
+	row      *system.ProvisionDeptRow
+	result   *system.ProvisionRowResult
+	existing *system.Dept
+	parent   *provisionDept
+	dept     *system.Dept
+}
+
+// provisionUser 用户行处理计划
+type provisionUser struct {
+	row      *system.ProvisionUserRow
+	result   *system.ProvisionRowResult
+	dept     *provisionDept
+	roleIDs  []uint64
+	password string
+	user     *system.User
+}
+
+type provisionPlan struct {
+	depts []*provisionDept // 按父级在前的顺序排列
+	users []*provisionUser
+	rows  []*system.ProvisionRowResult
+}
+
+func provisionFail(result *system.ProvisionRowResult, format string, args ...interface{}) {
+	if result.Status == system.ProvisionRowError {
+		return
+	}
+	result.Status = system.ProvisionRowError
+	result.Message = fmt.Sprintf(format, args...)
+}
+
+func (a ProvisionService) validate(depts []*system.ProvisionDeptRow, users []*system.ProvisionUserRow) (*provisionPlan, error) {
+	plan := &provisionPlan{}
+
+	// 预加载文件中引用到的部门、角色和用户名
+	deptCodes := make([]string, 0, len(depts)*2+len(users))
+	for _, row := range depts {
+		deptCodes = append(deptCodes, row.Code, row.ParentCode)
+	}
+	roleCodes := make([]string, 0)
+	usernames := make([]string, 0, len(users))
+	for _, row := range users {
+		deptCodes = append(deptCodes, row.DeptCode)
+		roleCodes = append(roleCodes, row.RoleCodes...)
+		usernames = append(usernames, row.Username)
+	}
+
+	existingDepts, err := a.deptRepository.GetByCodes(deptCodes)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := a.roleRepository.GetByCodes(roleCodes)
+	if err != nil {
+		return nil, err
+	}
+	takenUsernames, err := a.userRepository.ExistingUsernames(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	// 部门
+	byCode := make(map[string]*provisionDept, len(depts))
+	fileDepts := make([]*provisionDept, 0, len(depts))
+	for _, row := range depts {
+		d := &provisionDept{
+			row:    row,
+			result: &system.ProvisionRowResult{Kind: system.ProvisionKindDept, Line: row.Line, Key: row.Code, Status: system.ProvisionRowValid},
+		}
+		plan.rows = append(plan.rows, d.result)
+		fileDepts = append(fileDepts, d)
+
+		switch {
+		case row.Code == "":
+			provisionFail(d.result, "code is required")
+			continue
+		case byCode[row.Code] != nil:
+			provisionFail(d.result, "duplicate code, first defined on line %d", byCode[row.Code].row.Line)
+			continue
+		case row.Name == "":
+			provisionFail(d.result, "name is required")
+		}
+		byCode[row.Code] = d
+
+		if exist, ok := existingDepts[row.Code]; ok {
+			if exist.IsDeleted != 0 {
+				provisionFail(d.result, "code is used by a deleted department")
+				continue
+			}
+			d.existing = exist
+			d.result.ID = exist.ID
+			if d.result.Status != system.ProvisionRowError {
+				d.result.Status = system.ProvisionRowExists
+			}
+		}
+	}
+
+	// 解析部门上级，已有部门保持原上级不变
+	for _, d := range fileDepts {
+		if d.result.Status == system.ProvisionRowError || d.existing != nil || d.row.ParentCode == "" {
+			continue
+		}
+		if d.row.ParentCode == d.row.Code {
+			provisionFail(d.result, "department cannot be its own parent")
+			continue
+		}
+		if parent, ok := byCode[d.row.ParentCode]; ok {
+			d.parent = parent
+		} else if exist, ok := existingDepts[d.row.ParentCode]; ok && exist.IsDeleted == 0 {
+			d.parent = &provisionDept{existing: exist, result: &system.ProvisionRowResult{Status: system.ProvisionRowExists}}
+		} else {
+			provisionFail(d.result, "parent department %q not found", d.row.ParentCode)
+		}
+	}
+
+	// 按父级在前排序，同时检测循环引用和无效上级
+	state := make(map[*provisionDept]int) // 0 未访问 1 访问中 2 已完成
+	var visit func(d *provisionDept) bool
+	visit = func(d *provisionDept) bool {
+		switch state[d] {
+		case 1:
+			provisionFail(d.result, "circular parent reference")
+			return false
+		case 2:
+			return d.result.Status != system.ProvisionRowError
+		}
+		state[d] = 1
+		ok := d.result.Status != system.ProvisionRowError
+		if d.parent != nil && d.parent.row != nil && !visit(d.parent) {
+			if ok {
+				provisionFail(d.result, "parent department %q is invalid", d.row.ParentCode)
+			}
+			ok = false
+		}
+		state[d] = 2
+		if d.row != nil {
+			plan.depts = append(plan.depts, d)
+		}
+		return ok
+	}
+	for _, d := range fileDepts {
+		visit(d)
+	}
+
+	// 用户
+	seenUsers := make(map[string]int, len(users))
+	superAdmin := a.config.SuperAdmin
+	for _, row := range users {
+		u := &provisionUser{
+			row:    row,
+			result: &system.ProvisionRowResult{Kind: system.ProvisionKindUser, Line: row.Line, Key: row.Username, Status: system.ProvisionRowValid},
+		}
+		plan.rows = append(plan.rows, u.result)
+
+		if row.Username == "" {
+			provisionFail(u.result, "username is required")
+			continue
+		}
+		if line, ok := seenUsers[row.Username]; ok {
+			provisionFail(u.result, "duplicate username, first defined on line %d", line)
+			continue
+		}
+		seenUsers[row.Username] = row.Line
+
+		if takenUsernames[row.Username] || (superAdmin != nil && superAdmin.Username == row.Username) {
+			provisionFail(u.result, "username already exists")
+		}
+		if row.Email != "" {
+			if _, err := mail.ParseAddress(row.Email); err != nil {
+				provisionFail(u.result, "invalid email %q", row.Email)
+			}
+		}
+		if row.Gender < 0 || row.Gender > 2 {
+			provisionFail(u.result, "invalid gender %d", row.Gender)
+		}
+
+		if row.DeptCode != "" {
+			if d, ok := byCode[row.DeptCode]; ok {
+				if d.result.Status == system.ProvisionRowError {
+					provisionFail(u.result, "department %q is invalid", row.DeptCode)
+				}
+				u.dept = d
+			} else if exist, ok := existingDepts[row.DeptCode]; ok && exist.IsDeleted == 0 {
+				u.dept = &provisionDept{existing: exist}
+			} else {
+				provisionFail(u.result, "department %q not found", row.DeptCode)
+			}
+		}
+
+		for _, code := range row.RoleCodes {
+			role, ok := roles[code]
+			if !ok {
+				provisionFail(u.result, "role %q not found", code)
+				break
+			}
+			u.roleIDs = append(u.roleIDs, role.ID)
+		}
+
+		plan.users = append(plan.users, u)
+	}
+
+	return plan, nil
+}
+
+// hashPasswords 生成初始密码并并发计算 bcrypt 哈希
+func (a ProvisionService) hashPasswords(users []*provisionUser) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	for _, u := range users {
+		password, err := random.SecureString(provisionPasswordLength, random.Alphanumeric)
+		if err != nil {
+			return err
+		}
+		u.password = password
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(u *provisionUser) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			hashed, err := hash.BcryptHash(u.password)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			u.user = &system.User{Password: hashed}
+		}(u)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+func (a ProvisionService) create(tx *gorm.DB, plan *provisionPlan, operator uint64) error {
+	deptRepository := a.deptRepository.WithTrx(tx)
+	userRepository := a.userRepository.WithTrx(tx)
+	userRoleRepository := a.userRoleRepository.WithTrx(tx)
+
+	for _, d := range plan.depts {
+		if d.existing != nil {
+			continue
+		}
+
+		treePath := "0"
+		var parentID uint64
+		if p := d.parent; p != nil {
+			parent := p.existing
+			if parent == nil {
+				parent = p.dept
+			}
+			parentID = parent.ID
+			treePath = parent.TreePath + "," + strconv.FormatUint(parent.ID, 10)
+		}
+
+		d.dept = &system.Dept{
+			Name:     d.row.Name,
+			Code:     d.row.Code,
+			ParentID: parentID,
+			TreePath: treePath,
+			Sort:     d.row.Sort,
+			Status:   1,
+			CreateBy: operator,
+		}
+		if err := deptRepository.Create(d.dept); err != nil {
+			return err
+		}
+		d.result.ID = d.dept.ID
+		d.result.Status = system.ProvisionRowCreated
+	}
+
+	for _, u := range plan.users {
+		user := u.user
+		user.Username = u.row.Username
+		user.Nickname = u.row.Nickname
+		if user.Nickname == "" {
+			user.Nickname = u.row.Username
+		}
+		user.Email = u.row.Email
+		user.Mobile = u.row.Mobile
+		user.Gender = u.row.Gender
+		user.Status = 1
+		user.CreateBy = operator
+		if d := u.dept; d != nil {
+			if d.existing != nil {
+				user.DeptID = d.existing.ID
+			} else {
+				user.DeptID = d.dept.ID
+			}
+		}
+
+		if err := userRepository.Create(user); err != nil {
+			return err
+		}
+
+		if len(u.roleIDs) > 0 {
+			userRoles := make([]*system.UserRole, 0, len(u.roleIDs))
+			for _, roleID := range u.roleIDs {
+				userRoles = append(userRoles, &system.UserRole{UserID: user.ID, RoleID: roleID})
+			}
+			if err := userRoleRepository.BatchCreate(userRoles); err != nil {
+				return err
+			}
+		}
+
+		u.result.ID = user.ID
+		u.result.Status = system.ProvisionRowCreated
+	}
+
+	return nil
+}
+
+// notify 下发初始密码：邮件发送成功的不再在结果中返回密码
+func (a ProvisionService) notify(users []*provisionUser, mode string) {
+	for _, u := range users {
+		if mode != system.ProvisionNotifyEmail || u.row.Email == "" {
+			u.result.Password = u.password
+			continue
+		}
+
+		subject := fmt.Sprintf("[%s] 账号开通通知", a.config.Name)
+		body := fmt.Sprintf("您好，%s：\n\n您的账号已开通。\n用户名：%s\n初始密码：%s\n\n请登录后尽快修改密码。",
+			u.user.Nickname, u.row.Username, u.password)
+		if err := a.mailer.Send([]string{u.row.Email}, subject, body); err != nil {
+			a.logger.Zap.Warnf("Failed to email initial password to %s: %v", u.row.Username, err)
+			u.result.Password = u.password
+			u.result.Message = "email failed: " + err.Error()
+			continue
+		}
+		u.result.Emailed = true
+	}
+}
+
+// WriteProvisionReport 以 CSV 输出逐行处理结果
+func WriteProvisionReport(w io.Writer, result *system.ProvisionResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"kind", "line", "key", "status", "id", "password", "emailed", "message"}); err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		id := ""
+		if row.ID > 0 {
+			id = strconv.FormatUint(row.ID, 10)
+		}
+		if err := cw.Write([]string{
+			row.Kind, strconv.Itoa(row.Line), row.Key, row.Status, id,
+			row.Password, strconv.FormatBool(row.Emailed), row.Message,
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// readProvisionCSV 按表头读取 CSV，列名不区分大小写，回调中的行号为文件中的实际行号（表头为第 1 行）
+func readProvisionCSV(r io.Reader, required []string, fn func(line int, get func(string) string) error) error {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(errors.ProvisionInvalidCSV, err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return errors.Wrapf(errors.ProvisionInvalidCSV, "missing column %q", name)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(errors.ProvisionInvalidCSV, err.Error())
+		}
+
+		// 跳过空行
+		if len(strings.TrimSpace(strings.Join(record, ""))) == 0 {
+			continue
+		}
+
+		line, _ := cr.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if err := fn(line, get); err != nil {
+			return errors.Wrap(errors.ProvisionInvalidCSV, err.Error())
+		}
+	}
+}
+
+func parseProvisionInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
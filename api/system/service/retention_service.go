@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+const (
+	// RetentionPurgeTaskType 按保留策略清理数据的队列任务类型
+	RetentionPurgeTaskType = "retention_purge"
+
+	retentionTaskName          = "retention_purge"
+	retentionDefaultSpec       = "0 30 3 * * *"
+	retentionDefaultBatchSize  = 1000
+	retentionDefaultBatchDelay = 100 * time.Millisecond
+	// retentionLockTTL 清理锁的持有时长，大表首次清理耗时较长，进程异常退出时到期自动释放
+	retentionLockTTL = 2 * time.Hour
+)
+
+// RetentionService 数据保留策略
+// 管理员按数据类型配置保留天数，定时任务（或手动执行）分批删除超过保留天数的数据，
+// 并删除下载器临时目录中没有任务引用的任务目录；定时任务的执行摘要记录在定时任务执行记录中
+type RetentionService struct {
+	logger              lib.Logger
+	config              *lib.RetentionConfig
+	taskQueue           lib.TaskQueue
+	lockService         LockService
+	mediaService        DownloadMediaService
+	downloadService     DownloadService
+	tagRepository       repository.TagRepository
+	taskRepository      repository.TaskRepository
+	retentionRepository repository.RetentionRepository
+}
+
+// NewRetentionService 创建数据保留策略服务，启用时注册定时清理任务
+func NewRetentionService(
+	logger lib.Logger,
+	config lib.Config,
+	cron lib.Crontab,
+	taskQueue lib.TaskQueue,
+	lockService LockService,
+	mediaService DownloadMediaService,
+	downloadService DownloadService,
+	tagRepository repository.TagRepository,
+	taskRepository repository.TaskRepository,
+	retentionRepository repository.RetentionRepository,
+) RetentionService {
+	cfg := &lib.RetentionConfig{}
+	if config.Retention != nil {
+		cfg = config.Retention
+	}
+
+	svc := RetentionService{
+		logger:              logger,
+		config:              cfg,
+		taskQueue:           taskQueue,
+		lockService:         lockService,
+		mediaService:        mediaService,
+		downloadService:     downloadService,
+		tagRepository:       tagRepository,
+		taskRepository:      taskRepository,
+		retentionRepository: retentionRepository,
+	}
+
+	if cfg.Enable && cron.IsEnabled() {
+		spec := cfg.Spec
+		if spec == "" {
+			spec = retentionDefaultSpec
+		}
+
+		if err := cron.AddTask(retentionTaskName, spec, svc.runScheduled, crontab.WithSkipIfRunning()); err != nil {
+			logger.Zap.Errorf("Failed to register retention task: %v", err)
+		}
+	}
+
+	return svc
+}
+
+// WithTrx delegates transaction to repository database
+func (a RetentionService) WithTrx(trxHandle *gorm.DB) RetentionService {
+	a.retentionRepository = a.retentionRepository.WithTrx(trxHandle)
+	return a
+}
+
+// runScheduled 定时任务入口，执行全部已启用的策略，各数据类型删除的数量作为执行摘要
+func (a RetentionService) runScheduled(ctx context.Context) {
+	policies, err := a.List()
+	if err != nil {
+		a.logger.Zap.Errorf("Retention purge failed: %v", err)
+		crontab.SetError(ctx, err)
+		return
+	}
+
+	summary, err := a.purge(ctx, enabledPolicies(policies, nil), 0)
+	crontab.SetResult(ctx, summary)
+	if err != nil {
+		a.logger.Zap.Errorf("Retention purge failed: %v", err)
+		crontab.SetError(ctx, err)
+	}
+}
+
+// List 全部保留策略，缺失的数据类型按默认保留天数创建
+func (a RetentionService) List() (system.RetentionPolicies, error) {
+	list, err := a.retentionRepository.List()
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(list))
+	for _, policy := range list {
+		exists[policy.DataType] = true
+	}
+	for _, item := range system.RetentionDataTypes {
+		if exists[item.DataType] {
+			continue
+		}
+
+		days := item.Days
+		if d := a.config.Days[item.DataType]; d > 0 {
+			days = d
+		}
+
+		policy := &system.RetentionPolicy{DataType: item.DataType, Days: days, Enable: true}
+		if err := a.retentionRepository.Create(policy); err != nil {
+			return nil, err
+		}
+		list = append(list, policy)
+	}
+
+	return list, nil
+}
+
+// Update 修改数据类型的保留天数与启用状态
+func (a RetentionService) Update(dataType string, form *system.RetentionPolicyForm, operator uint64) error {
+	if !isRetentionDataType(dataType) {
+		return errors.Wrapf(errors.RetentionUnknownDataType, "%s", dataType)
+	}
+	// 确保策略已创建
+	if _, err := a.List(); err != nil {
+		return err
+	}
+
+	return a.retentionRepository.Update(dataType, form.Days, form.Enable, operator)
+}
+
+// Preview 按当前策略估算各数据类型将被删除的行数，不删除数据
+func (a RetentionService) Preview() ([]*system.RetentionPreviewVO, error) {
+	policies, err := a.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*system.RetentionPreviewVO, 0, len(policies))
+	for _, policy := range policies {
+		if !isRetentionDataType(policy.DataType) {
+			continue
+		}
+
+		cutoff := retentionCutoff(now, policy.Days)
+		var rows int64
+		if policy.DataType == system.RetentionDownloadTemp {
+			dirs, err := a.downloadService.OrphanedTempFolders(cutoff)
+			if err != nil {
+				return nil, err
+			}
+			rows = int64(len(dirs))
+		} else if policy.DataType == system.RetentionQueueTask {
+			if rows, err = a.taskRepository.CountFinished(cutoff); err != nil {
+				return nil, err
+			}
+		} else if rows, err = a.retentionRepository.CountExpired(policy.DataType, cutoff); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &system.RetentionPreviewVO{
+			DataType: policy.DataType,
+			Days:     policy.Days,
+			Enable:   policy.Enable,
+			Cutoff:   cutoff.Format(dto.DateTimeFormat),
+			Rows:     rows,
+		})
+	}
+
+	return result, nil
+}
+
+// Run 手动执行保留策略，启用队列时通过队列执行，否则在后台协程中执行
+func (a RetentionService) Run(form *system.RetentionRunForm, operator uint64) (*system.RetentionRunVO, error) {
+	for _, dataType := range form.DataTypes {
+		if !isRetentionDataType(dataType) {
+			return nil, errors.Wrapf(errors.RetentionUnknownDataType, "%s", dataType)
+		}
+	}
+
+	policies, err := a.List()
+	if err != nil {
+		return nil, err
+	}
+	policies = enabledPolicies(policies, form.DataTypes)
+
+	result := &system.RetentionRunVO{DataTypes: make([]string, 0, len(policies))}
+	for _, policy := range policies {
+		result.DataTypes = append(result.DataTypes, policy.DataType)
+	}
+	if len(policies) == 0 {
+		return result, nil
+	}
+
+	run := func(ctx context.Context) error {
+		// 单个数据类型的失败记录在策略的 lastError 中，不重试整个清理
+		if _, err := a.purge(ctx, policies, operator); err != nil && !errors.Is(err, errors.RetentionPurgeFailed) {
+			return err
+		}
+		return nil
+	}
+
+	if a.taskQueue.IsEnabled() {
+		task := queue.NewFuncTask(RetentionPurgeTaskType, &queue.TaskOwner{ID: operator}, run)
+		if err := a.taskQueue.QueueTask(context.Background(), task); err != nil {
+			return nil, errors.Wrap(err, "failed to queue retention task")
+		}
+		result.QueueTaskID = uint64(task.ID())
+	} else {
+		go func() {
+			if err := run(context.Background()); err != nil {
+				a.logger.Zap.Errorf("Retention purge failed: %v", err)
+			}
+		}()
+	}
+
+	return result, nil
+}
+
+// purge 持有清理锁依次执行策略，多实例同时触发时只有一个实例执行
+// 返回各数据类型删除数量的摘要，部分数据类型失败时其他类型继续执行，最后返回失败的类型
+func (a RetentionService) purge(ctx context.Context, policies system.RetentionPolicies, operator uint64) (string, error) {
+	lock, err := a.lockService.Acquire(system.LockRetentionPurge, operator, "retention purge", retentionLockTTL)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := a.lockService.Release(lock.Name, lock.Token); err != nil {
+			a.logger.Zap.Warnf("Failed to release lock %s: %v", lock.Name, err)
+		}
+	}()
+
+	summary := make([]string, 0, len(policies))
+	failed := make([]string, 0)
+	for _, policy := range policies {
+		if err := ctx.Err(); err != nil {
+			return strings.Join(summary, ", "), err
+		}
+
+		start := time.Now()
+		purged, err := a.purgeDataType(ctx, policy)
+		summary = append(summary, fmt.Sprintf("%s=%d", policy.DataType, purged))
+
+		lastError := ""
+		if err != nil {
+			lastError = str.S(err.Error()).Truncate(500)
+			failed = append(failed, fmt.Sprintf("%s: %v", policy.DataType, err))
+			a.logger.Zap.Errorf("Retention purge of %s failed after %d rows: %v", policy.DataType, purged, err)
+		} else {
+			a.logger.Zap.Infof("Retention purge of %s finished: %d rows older than %d days in %s",
+				policy.DataType, purged, policy.Days, time.Since(start).Round(time.Millisecond))
+		}
+		if err := a.retentionRepository.RecordRun(policy.DataType, start, purged, lastError); err != nil {
+			a.logger.Zap.Warnf("Failed to record retention run of %s: %v", policy.DataType, err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return strings.Join(summary, ", "), errors.Wrapf(errors.RetentionPurgeFailed, "%s", strings.Join(failed, "; "))
+	}
+	return strings.Join(summary, ", "), nil
+}
+
+// purgeDataType 分批删除单个数据类型中超过保留天数的数据，返回删除的行数
+// 删除下载任务时同时删除其标签和音视频提取结果
+func (a RetentionService) purgeDataType(ctx context.Context, policy *system.RetentionPolicy) (int64, error) {
+	cutoff := retentionCutoff(time.Now(), policy.Days)
+	if policy.DataType == system.RetentionDownloadTemp {
+		return a.purgeDownloadTemp(ctx, cutoff)
+	}
+	batchSize, batchDelay := a.batchSize(), a.batchDelay()
+
+	var purged int64
+	for batch := 1; ; batch++ {
+		ids, err := a.expiredIDs(policy.DataType, cutoff, batchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		if policy.DataType == system.RetentionDownloadTask {
+			if err := a.tagRepository.DeleteByResources(system.TagResourceDownload, ids); err != nil {
+				return purged, err
+			}
+			if err := a.mediaService.DeleteByTasks(ids); err != nil {
+				return purged, err
+			}
+		}
+
+		n, err := a.deleteExpired(policy.DataType, ids)
+		if err != nil {
+			return purged, err
+		}
+		purged += n
+		a.logger.Zap.Infof("Retention purge of %s: batch %d deleted %d rows, %d in total", policy.DataType, batch, n, purged)
+
+		if len(ids) < batchSize {
+			return purged, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return purged, ctx.Err()
+		case <-time.After(batchDelay):
+		}
+	}
+}
+
+// expiredIDs 一批超过保留天数的数据ID，队列任务从任务存储（数据库或 Redis）中读取
+func (a RetentionService) expiredIDs(dataType string, cutoff time.Time, limit int) ([]uint64, error) {
+	if dataType == system.RetentionQueueTask {
+		return a.taskRepository.FinishedIDs(cutoff, limit)
+	}
+	return a.retentionRepository.ExpiredIDs(dataType, cutoff, limit)
+}
+
+// deleteExpired 删除一批数据，返回删除的行数
+func (a RetentionService) deleteExpired(dataType string, ids []uint64) (int64, error) {
+	if dataType == system.RetentionQueueTask {
+		if err := a.taskRepository.BatchDelete(ids); err != nil {
+			return 0, err
+		}
+		return int64(len(ids)), nil
+	}
+	return a.retentionRepository.DeleteByIDs(dataType, ids)
+}
+
+// purgeDownloadTemp 删除下载器临时目录中没有任务引用、cutoff 之后没有修改过的任务目录，返回删除的目录数
+func (a RetentionService) purgeDownloadTemp(ctx context.Context, cutoff time.Time) (int64, error) {
+	dirs, err := a.downloadService.OrphanedTempFolders(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return purged, err
+		}
+		purged++
+		a.logger.Zap.Infof("Retention purge of %s: deleted orphaned folder %s", system.RetentionDownloadTemp, dir)
+	}
+
+	return purged, nil
+}
+
+func (a RetentionService) batchSize() int {
+	if a.config.BatchSize <= 0 {
+		return retentionDefaultBatchSize
+	}
+	return a.config.BatchSize
+}
+
+func (a RetentionService) batchDelay() time.Duration {
+	if a.config.BatchDelay <= 0 {
+		return retentionDefaultBatchDelay
+	}
+	return time.Duration(a.config.BatchDelay) * time.Millisecond
+}
+
+// enabledPolicies 已启用的策略，dataTypes 不为空时只保留其中的数据类型
+func enabledPolicies(policies system.RetentionPolicies, dataTypes []string) system.RetentionPolicies {
+	selected := make(map[string]bool, len(dataTypes))
+	for _, dataType := range dataTypes {
+		selected[dataType] = true
+	}
+
+	result := make(system.RetentionPolicies, 0, len(policies))
+	for _, policy := range policies {
+		if !policy.Enable || policy.Days <= 0 || !isRetentionDataType(policy.DataType) {
+			continue
+		}
+		if len(selected) > 0 && !selected[policy.DataType] {
+			continue
+		}
+		result = append(result, policy)
+	}
+
+	return result
+}
+
+// isRetentionDataType 是否为支持的数据类型
+func isRetentionDataType(dataType string) bool {
+	for _, item := range system.RetentionDataTypes {
+		if item.DataType == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// retentionCutoff 保留天数对应的截止时间，早于该时间的数据会被删除
+func retentionCutoff(now time.Time, days int) time.Time {
+	return now.AddDate(0, 0, -days)
+}
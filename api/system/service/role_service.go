@@ -16,8 +16,12 @@ type RoleService struct {
 	userRepository     repository.UserRepository
 	roleRepository     repository.RoleRepository
 	roleMenuRepository repository.RoleMenuRepository
+	roleDeptRepository repository.RoleDeptRepository
 	menuRepository     repository.MenuRepository
 	permissionCache    PermissionCache
+	responseCache      lib.ResponseCache
+
+	deptRoleRuleRepository repository.DeptRoleRuleRepository
 }
 
 // NewRoleService creates a new role service
@@ -26,16 +30,22 @@ func NewRoleService(
 	userRepository repository.UserRepository,
 	roleRepository repository.RoleRepository,
 	roleMenuRepository repository.RoleMenuRepository,
+	roleDeptRepository repository.RoleDeptRepository,
 	menuRepository repository.MenuRepository,
 	permissionCache PermissionCache,
+	responseCache lib.ResponseCache,
+	deptRoleRuleRepository repository.DeptRoleRuleRepository,
 ) RoleService {
 	return RoleService{
-		logger:             logger,
-		userRepository:     userRepository,
-		roleRepository:     roleRepository,
-		roleMenuRepository: roleMenuRepository,
-		menuRepository:     menuRepository,
-		permissionCache:    permissionCache,
+		logger:                 logger,
+		userRepository:         userRepository,
+		roleRepository:         roleRepository,
+		roleMenuRepository:     roleMenuRepository,
+		roleDeptRepository:     roleDeptRepository,
+		menuRepository:         menuRepository,
+		permissionCache:        permissionCache,
+		responseCache:          responseCache,
+		deptRoleRuleRepository: deptRoleRuleRepository,
 	}
 }
 
@@ -44,6 +54,8 @@ func (a RoleService) WithTrx(trxHandle *gorm.DB) RoleService {
 	a.roleRepository = a.roleRepository.WithTrx(trxHandle)
 	a.userRepository = a.userRepository.WithTrx(trxHandle)
 	a.roleMenuRepository = a.roleMenuRepository.WithTrx(trxHandle)
+	a.roleDeptRepository = a.roleDeptRepository.WithTrx(trxHandle)
+	a.deptRoleRuleRepository = a.deptRoleRuleRepository.WithTrx(trxHandle)
 
 	return a
 }
@@ -65,6 +77,14 @@ func (a RoleService) Get(id uint64) (*system.Role, error) {
 	}
 	role.MenuIds = menuIDs
 
+	if role.DataScope == system.DataScopeCustom {
+		deptIDs, err := a.roleDeptRepository.GetDeptIDsByRoleIDs([]uint64{id})
+		if err != nil {
+			return nil, err
+		}
+		role.DeptIds = deptIDs
+	}
+
 	return role, nil
 }
 
@@ -103,6 +123,10 @@ func (a RoleService) CheckCode(item *system.Role) error {
 }
 
 func (a RoleService) Create(role *system.Role) (uint64, error) {
+	if !system.ValidDataScope(role.DataScope) {
+		return 0, errors.RoleInvalidDataScope
+	}
+
 	if err := a.CheckName(role); err != nil {
 		return 0, err
 	}
@@ -122,10 +146,20 @@ func (a RoleService) Create(role *system.Role) (uint64, error) {
 		}
 	}
 
+	if role.DataScope == system.DataScopeCustom {
+		if err := a.roleDeptRepository.Replace(role.ID, role.DeptIds); err != nil {
+			return 0, err
+		}
+	}
+
 	return role.ID, nil
 }
 
 func (a RoleService) Update(id uint64, role *system.Role) error {
+	if !system.ValidDataScope(role.DataScope) {
+		return errors.RoleInvalidDataScope
+	}
+
 	oRole, err := a.Get(id)
 	if err != nil {
 		return err
@@ -149,6 +183,15 @@ func (a RoleService) Update(id uint64, role *system.Role) error {
 		return err
 	}
 
+	// 只有自定义数据权限保留部门，切换到其他范围时清除
+	var deptIDs []uint64
+	if role.DataScope == system.DataScopeCustom {
+		deptIDs = role.DeptIds
+	}
+	if err := a.roleDeptRepository.Replace(id, deptIDs); err != nil {
+		return err
+	}
+
 	// 清除该角色相关用户的权限缓存
 	a.permissionCache.InvalidateRoleCache(id)
 
@@ -179,6 +222,15 @@ func (a RoleService) Delete(id uint64) error {
 		return err
 	}
 
+	if err := a.roleDeptRepository.DeleteByRoleID(id); err != nil {
+		return err
+	}
+
+	// 删除映射到该角色的部门默认角色规则
+	if err := a.deptRoleRuleRepository.DeleteByRoleID(id); err != nil {
+		return err
+	}
+
 	if err := a.roleRepository.Delete(id); err != nil {
 		return err
 	}
@@ -216,8 +268,9 @@ func (a RoleService) AssignMenusToRole(roleID uint64, menuIDs []uint64) error {
 		return err
 	}
 
-	// 清除该角色相关用户的权限缓存
+	// 清除该角色相关用户的权限缓存，通讯录可见字段随权限变化
 	a.permissionCache.InvalidateRoleCache(roleID)
+	a.responseCache.Invalidate(lib.ResponseCacheGroupDirectory)
 
 	return nil
 }
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// 不随保存查询保存的列表参数
+var savedQueryIgnoredParams = map[string]bool{
+	"pageNum":         true,
+	"pageSize":        true,
+	"savedQueryId":    true,
+	"order_key":       true,
+	"order_direction": true,
+}
+
+// SavedQueryService 保存查询服务，用户只能访问自己的保存查询
+type SavedQueryService struct {
+	logger               lib.Logger
+	savedQueryRepository repository.SavedQueryRepository
+}
+
+// NewSavedQueryService creates a new saved query service
+func NewSavedQueryService(
+	logger lib.Logger,
+	savedQueryRepository repository.SavedQueryRepository,
+) SavedQueryService {
+	return SavedQueryService{
+		logger:               logger,
+		savedQueryRepository: savedQueryRepository,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a SavedQueryService) WithTrx(trxHandle *gorm.DB) SavedQueryService {
+	a.savedQueryRepository = a.savedQueryRepository.WithTrx(trxHandle)
+	return a
+}
+
+// List 获取用户的保存查询
+func (a SavedQueryService) List(userID uint64, param *system.SavedQueryQueryParam) (system.SavedQueries, error) {
+	if param.Resource != "" && !system.IsSavedQueryResource(param.Resource) {
+		return nil, errors.SavedQueryResourceInvalid
+	}
+
+	return a.savedQueryRepository.ListByUser(userID, param.Resource)
+}
+
+// Get 获取用户的保存查询，非本人的视为不存在
+func (a SavedQueryService) Get(id, userID uint64) (*system.SavedQuery, error) {
+	query, err := a.savedQueryRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if query.UserID != userID {
+		return nil, errors.SavedQueryRecordNotFound
+	}
+
+	return query, nil
+}
+
+// Create 新增保存查询
+func (a SavedQueryService) Create(form *system.SavedQueryForm, userID uint64) (*system.SavedQuery, error) {
+	query, err := a.build(form)
+	if err != nil {
+		return nil, err
+	}
+
+	if exist, err := a.savedQueryRepository.GetByName(userID, query.Resource, query.Name); err != nil {
+		return nil, err
+	} else if exist != nil {
+		return nil, errors.SavedQueryNameExists
+	}
+
+	query.UserID = userID
+	if err := a.savedQueryRepository.Create(query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// Update 修改保存查询
+func (a SavedQueryService) Update(id uint64, form *system.SavedQueryForm, userID uint64) error {
+	if _, err := a.Get(id, userID); err != nil {
+		return err
+	}
+
+	query, err := a.build(form)
+	if err != nil {
+		return err
+	}
+
+	if exist, err := a.savedQueryRepository.GetByName(userID, query.Resource, query.Name); err != nil {
+		return err
+	} else if exist != nil && exist.ID != id {
+		return errors.SavedQueryNameExists
+	}
+
+	return a.savedQueryRepository.Update(id, map[string]interface{}{
+		"resource":   query.Resource,
+		"name":       query.Name,
+		"filter":     query.Filter,
+		"sort_key":   query.SortKey,
+		"sort_order": query.SortOrder,
+		"columns":    query.Columns,
+	})
+}
+
+// Delete 删除保存查询
+func (a SavedQueryService) Delete(id, userID uint64) error {
+	if _, err := a.Get(id, userID); err != nil {
+		return err
+	}
+
+	return a.savedQueryRepository.Delete(id)
+}
+
+// Params 返回保存查询对应的列表 query 参数（含排序），供列表接口合并到请求参数中
+func (a SavedQueryService) Params(id, userID uint64, resource string) (url.Values, error) {
+	query, err := a.Get(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if query.Resource != resource {
+		return nil, errors.SavedQueryResourceMismatch
+	}
+
+	values := make(url.Values)
+	if len(query.Filter) > 0 {
+		filter := make(map[string]string)
+		if err := json.Unmarshal(query.Filter, &filter); err != nil {
+			return nil, errors.Wrap(errors.DatabaseInternalError, err.Error())
+		}
+		for k, v := range filter {
+			values.Set(k, v)
+		}
+	}
+	if query.SortKey != "" {
+		values.Set("order_key", query.SortKey)
+		values.Set("order_direction", query.SortOrder)
+	}
+
+	return values, nil
+}
+
+// build 校验表单并转换为模型，排序字段限定为列表允许的字段
+func (a SavedQueryService) build(form *system.SavedQueryForm) (*system.SavedQuery, error) {
+	if !system.IsSavedQueryResource(form.Resource) {
+		return nil, errors.SavedQueryResourceInvalid
+	}
+
+	query := &system.SavedQuery{
+		Resource: form.Resource,
+		Name:     strings.TrimSpace(form.Name),
+	}
+
+	if form.SortKey != "" {
+		allowed := false
+		for _, key := range system.SavedQuerySortKeys[form.Resource] {
+			if key == form.SortKey {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return nil, errors.Wrapf(errors.SavedQuerySortInvalid, "sort key: %s", form.SortKey)
+		}
+
+		switch order := dto.OrderDirection(strings.ToUpper(form.SortOrder)); order {
+		case "", dto.OrderByDESC:
+			query.SortOrder = string(dto.OrderByDESC)
+		case dto.OrderByASC:
+			query.SortOrder = string(order)
+		default:
+			return nil, errors.Wrapf(errors.SavedQuerySortInvalid, "sort order: %s", form.SortOrder)
+		}
+		query.SortKey = form.SortKey
+	}
+
+	filter := make(map[string]string, len(form.Filter))
+	for k, v := range form.Filter {
+		if k != "" && v != "" && !savedQueryIgnoredParams[k] {
+			filter[k] = v
+		}
+	}
+	b, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	query.Filter = database.JSONB(b)
+
+	columns := form.Columns
+	if columns == nil {
+		columns = []string{}
+	}
+	if b, err = json.Marshal(columns); err != nil {
+		return nil, err
+	}
+	query.Columns = database.JSONB(b)
+
+	return query, nil
+}
@@ -0,0 +1,502 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/geoip"
+	"github.com/top-system/light-admin/pkg/logship"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+const (
+	// 单次检测读取的历史登录记录上限
+	securityHistoryLimit = 500
+	// 概览中展示的最近告警数
+	securityRecentLimit = 10
+	// 告警通知类型（字典 notice_type：安全警告）
+	securityNoticeType = 3
+)
+
+// 告警推送渠道
+const (
+	SecurityChannelNotice    = "notice"
+	SecurityChannelWebSocket = "websocket"
+	SecurityChannelEmail     = "email"
+)
+
+// securityRuleLevels 规则对应的告警等级
+var securityRuleLevels = map[string]string{
+	system.SecurityRuleNewCountry:       "M",
+	system.SecurityRuleImpossibleTravel: "H",
+	system.SecurityRuleUnusualHour:      "L",
+	system.SecurityRuleDistinctIPs:      "M",
+}
+
+// securityRuleTitles 规则对应的通知标题
+var securityRuleTitles = map[string]string{
+	system.SecurityRuleNewCountry:       "新国家/地区登录",
+	system.SecurityRuleImpossibleTravel: "异地登录（不可能的移动速度）",
+	system.SecurityRuleUnusualHour:      "非常用时段登录",
+	system.SecurityRuleDistinctIPs:      "短时间内多个 IP 登录",
+}
+
+// SecurityService 登录异常检测与安全告警服务
+type SecurityService struct {
+	logger                  lib.Logger
+	config                  lib.Config
+	geo                     *geoip.DB
+	ws                      *ws.WebSocket
+	mailer                  lib.Mailer
+	logShipper              lib.LogShipper
+	noticeService           NoticeService
+	wsEventService          WsEventService
+	userRepository          repository.UserRepository
+	loginEventRepository    repository.LoginEventRepository
+	securityAlertRepository repository.SecurityAlertRepository
+}
+
+// NewSecurityService creates a new security service
+func NewSecurityService(
+	logger lib.Logger,
+	config lib.Config,
+	geo *geoip.DB,
+	websocket *ws.WebSocket,
+	mailer lib.Mailer,
+	logShipper lib.LogShipper,
+	noticeService NoticeService,
+	wsEventService WsEventService,
+	userRepository repository.UserRepository,
+	loginEventRepository repository.LoginEventRepository,
+	securityAlertRepository repository.SecurityAlertRepository,
+) SecurityService {
+	return SecurityService{
+		logger:                  logger,
+		config:                  config,
+		geo:                     geo,
+		ws:                      websocket,
+		mailer:                  mailer,
+		logShipper:              logShipper,
+		noticeService:           noticeService,
+		wsEventService:          wsEventService,
+		userRepository:          userRepository,
+		loginEventRepository:    loginEventRepository,
+		securityAlertRepository: securityAlertRepository,
+	}
+}
+
+// IsEnabled 是否启用登录异常检测
+func (a SecurityService) IsEnabled() bool {
+	return a.config.Security != nil && a.config.Security.Enable
+}
+
+func (a SecurityService) rules() *lib.SecurityRulesConfig {
+	if a.config.Security == nil || a.config.Security.Rules == nil {
+		return &lib.SecurityRulesConfig{}
+	}
+	return a.config.Security.Rules
+}
+
+// EnabledRules 已启用的规则
+func (a SecurityService) EnabledRules() []string {
+	if !a.IsEnabled() {
+		return []string{}
+	}
+
+	rules := a.rules()
+	enabled := make([]string, 0, 4)
+	if rules.NewCountry != nil && rules.NewCountry.Enable {
+		enabled = append(enabled, system.SecurityRuleNewCountry)
+	}
+	if rules.ImpossibleTravel != nil && rules.ImpossibleTravel.Enable {
+		enabled = append(enabled, system.SecurityRuleImpossibleTravel)
+	}
+	if rules.UnusualHour != nil && rules.UnusualHour.Enable {
+		enabled = append(enabled, system.SecurityRuleUnusualHour)
+	}
+	if rules.DistinctIPs != nil && rules.DistinctIPs.Enable {
+		enabled = append(enabled, system.SecurityRuleDistinctIPs)
+	}
+
+	return enabled
+}
+
+// RecordLogin 异步记录登录成功事件（用于最近登录时间统计），启用时同时执行异常检测，不影响登录响应
+func (a SecurityService) RecordLogin(user *system.User, ip string) {
+	if user == nil {
+		return
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				a.logger.Zap.Errorf("Record login panic: %v", r)
+			}
+		}()
+
+		if err := a.recordLogin(user.ID, user.Username, ip, time.Now()); err != nil {
+			a.logger.Zap.Warnf("Failed to record login of %s: %v", user.Username, err)
+		}
+	}()
+}
+
+func (a SecurityService) recordLogin(userID uint64, username, ip string, now time.Time) error {
+	location, _ := a.geo.Lookup(ip)
+	event := &system.LoginEvent{
+		UserID:    userID,
+		Username:  username,
+		IP:        ip,
+		Country:   location.Country,
+		Region:    location.Region,
+		City:      location.City,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+	}
+
+	if !a.IsEnabled() {
+		if err := a.loginEventRepository.Create(event); err != nil {
+			return err
+		}
+		a.logShipper.Ship(lib.LogShipLogin, logship.SeverityInfo, event)
+		return nil
+	}
+
+	history, err := a.loginEventRepository.ListSince(userID, now.Add(-a.lookback()), securityHistoryLimit)
+	if err != nil {
+		return err
+	}
+
+	alerts := a.evaluate(event, history, now)
+
+	if err := a.loginEventRepository.Create(event); err != nil {
+		return err
+	}
+	a.logShipper.Ship(lib.LogShipLogin, logship.SeverityInfo, event)
+
+	for _, alert := range alerts {
+		// 多 IP 规则在窗口内持续命中，同一窗口只告警一次
+		if alert.Rule == system.SecurityRuleDistinctIPs {
+			exists, err := a.securityAlertRepository.ExistsSince(userID, alert.Rule, now.Add(-a.distinctIPsWindow()))
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		if err := a.securityAlertRepository.Create(alert); err != nil {
+			return err
+		}
+		a.logShipper.Ship(lib.LogShipSecurityAlert, logship.SeverityWarning, alert)
+		a.notify(alert)
+	}
+
+	return nil
+}
+
+// lookback 检测所需的历史登录时间范围
+func (a SecurityService) lookback() time.Duration {
+	days := 90
+	if rule := a.rules().NewCountry; rule != nil && rule.LookbackDays > 0 {
+		days = rule.LookbackDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func (a SecurityService) distinctIPsWindow() time.Duration {
+	minutes := 60
+	if rule := a.rules().DistinctIPs; rule != nil && rule.Window > 0 {
+		minutes = rule.Window
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// evaluate 根据历史登录记录（按时间倒序）匹配异常规则
+func (a SecurityService) evaluate(event *system.LoginEvent, history system.LoginEvents, now time.Time) system.SecurityAlerts {
+	rules := a.rules()
+	alerts := make(system.SecurityAlerts, 0)
+
+	newAlert := func(rule, detail string) {
+		alerts = append(alerts, &system.SecurityAlert{
+			Rule:     rule,
+			Level:    securityRuleLevels[rule],
+			UserID:   event.UserID,
+			Username: event.Username,
+			IP:       event.IP,
+			Country:  event.Country,
+			City:     event.City,
+			Detail:   detail,
+			Status:   system.SecurityAlertOpen,
+		})
+	}
+
+	// 首次登录没有可对比的历史
+	if len(history) == 0 {
+		return alerts
+	}
+
+	if rule := rules.NewCountry; rule != nil && rule.Enable &&
+		event.Country != "" && event.Country != geoip.CountryLAN {
+		seen := false
+		for _, h := range history {
+			if h.Country == event.Country {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			newAlert(system.SecurityRuleNewCountry,
+				fmt.Sprintf("用户 %s 首次从 %s 登录（IP %s），近 %d 天内未出现过该国家/地区",
+					event.Username, event.Country, event.IP, int(a.lookback().Hours()/24)))
+		}
+	}
+
+	if rule := rules.ImpossibleTravel; rule != nil && rule.Enable {
+		maxSpeed, minDistance := rule.MaxSpeedKmh, rule.MinDistanceKm
+		if maxSpeed <= 0 {
+			maxSpeed = 900
+		}
+		if minDistance <= 0 {
+			minDistance = 300
+		}
+
+		current := geoip.Location{Latitude: event.Latitude, Longitude: event.Longitude}
+		for _, h := range history {
+			previous := geoip.Location{Latitude: h.Latitude, Longitude: h.Longitude}
+			if !previous.HasCoordinates() {
+				continue
+			}
+			if current.HasCoordinates() {
+				distance := geoip.Distance(previous, current)
+				hours := now.Sub(h.CreateTime.Time()).Hours()
+				if distance >= minDistance && (hours <= 0 || distance/hours > maxSpeed) {
+					newAlert(system.SecurityRuleImpossibleTravel,
+						fmt.Sprintf("用户 %s 在 %.1f 小时内从 %s（%s）移动到 %s（%s），距离 %.0f km",
+							event.Username, hours, securityPlace(h.Country, h.City), h.IP,
+							securityPlace(event.Country, event.City), event.IP, distance))
+				}
+			}
+			// 只与最近一次有坐标的登录比较
+			break
+		}
+	}
+
+	if rule := rules.UnusualHour; rule != nil && rule.Enable {
+		start, end := rule.StartHour, rule.EndHour
+		if start == 0 && end == 0 {
+			end = 6
+		}
+
+		hour := now.Hour()
+		if securityHourInRange(hour, start, end) {
+			habitual := false
+			for _, h := range history {
+				if h.CreateTime.Time().Hour() == hour {
+					habitual = true
+					break
+				}
+			}
+			if !habitual {
+				newAlert(system.SecurityRuleUnusualHour,
+					fmt.Sprintf("用户 %s 于 %s 登录（IP %s），该时段近期没有登录记录",
+						event.Username, now.Format("15:04"), event.IP))
+			}
+		}
+	}
+
+	if rule := rules.DistinctIPs; rule != nil && rule.Enable {
+		threshold := rule.Threshold
+		if threshold <= 0 {
+			threshold = 5
+		}
+
+		since := now.Add(-a.distinctIPsWindow())
+		ips := map[string]struct{}{event.IP: {}}
+		for _, h := range history {
+			if h.CreateTime.Time().Before(since) {
+				break
+			}
+			ips[h.IP] = struct{}{}
+		}
+		if len(ips) >= threshold {
+			newAlert(system.SecurityRuleDistinctIPs,
+				fmt.Sprintf("用户 %s 在 %d 分钟内从 %d 个不同 IP 登录",
+					event.Username, int(a.distinctIPsWindow().Minutes()), len(ips)))
+		}
+	}
+
+	return alerts
+}
+
+// securityHourInRange 判断小时是否在 [start, end) 内，支持跨零点（如 22-6）
+func securityHourInRange(hour, start, end int) bool {
+	if start <= end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+func securityPlace(country, city string) string {
+	place := strings.TrimSpace(country + " " + city)
+	if place == "" {
+		return "未知位置"
+	}
+	return place
+}
+
+// channelEnabled 未配置 Channels 时启用全部渠道
+func (a SecurityService) channelEnabled(channel string) bool {
+	if len(a.config.Security.Channels) == 0 {
+		return true
+	}
+	for _, c := range a.config.Security.Channels {
+		if strings.EqualFold(c, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// recipients 告警接收人
+func (a SecurityService) recipients(alert *system.SecurityAlert) system.Users {
+	var users system.Users
+	seen := make(map[string]bool)
+
+	usernames := append([]string{}, a.config.Security.Recipients...)
+	if a.config.Security.NotifyUser {
+		usernames = append(usernames, alert.Username)
+	}
+
+	for _, username := range usernames {
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		qr, err := a.userRepository.Query(&system.UserQueryParam{Username: username})
+		if err != nil {
+			a.logger.Zap.Warnf("Failed to load security alert recipient %s: %v", username, err)
+			continue
+		}
+		for _, user := range qr.List {
+			if user.Username == username {
+				users = append(users, user)
+				break
+			}
+		}
+	}
+
+	return users
+}
+
+// notify 按配置的渠道推送告警，推送失败只记录日志
+func (a SecurityService) notify(alert *system.SecurityAlert) {
+	users := a.recipients(alert)
+	if len(users) == 0 {
+		a.logger.Zap.Warnf("Security alert %d (%s) has no recipients", alert.ID, alert.Rule)
+		return
+	}
+
+	title := securityRuleTitles[alert.Rule]
+
+	if a.channelEnabled(SecurityChannelNotice) {
+		userIDs := make([]uint64, 0, len(users))
+		for _, user := range users {
+			userIDs = append(userIDs, user.ID)
+		}
+
+		notice := &system.Notice{
+			Title:   "安全告警：" + title,
+			Content: alert.Detail,
+			Type:    securityNoticeType,
+			Level:   alert.Level,
+		}
+		if err := a.noticeService.Send(notice, userIDs); err != nil {
+			a.logger.Zap.Warnf("Failed to send security alert notice: %v", err)
+		}
+	}
+
+	if a.channelEnabled(SecurityChannelWebSocket) && a.ws != nil {
+		message := map[string]interface{}{
+			"type":      system.WsEventSecurityAlert,
+			"title":     title,
+			"alert":     alert,
+			"timestamp": time.Now().UnixMilli(),
+		}
+		if _, err := a.wsEventService.Notify(system.WsEventSecurityAlert, "安全告警："+title, users, message, 0); err != nil {
+			a.logger.Zap.Warnf("Failed to push security alert: %v", err)
+		}
+	}
+
+	if a.channelEnabled(SecurityChannelEmail) && a.mailer.IsEnabled() {
+		to := make([]string, 0, len(users))
+		for _, user := range users {
+			if user.Email != "" {
+				to = append(to, user.Email)
+			}
+		}
+
+		if len(to) > 0 {
+			body := fmt.Sprintf("%s\n\n规则：%s\n用户：%s\nIP：%s\n时间：%s\n",
+				alert.Detail, alert.Rule, alert.Username, alert.IP, time.Now().Format("2006-01-02 15:04:05"))
+			if err := a.mailer.Send(to, "[安全告警] "+title, body); err != nil {
+				a.logger.Zap.Warnf("Failed to send security alert mail: %v", err)
+			}
+		}
+	}
+}
+
+// QueryAlerts 分页查询安全告警
+func (a SecurityService) QueryAlerts(param *system.SecurityAlertQueryParam) (*system.SecurityAlertQueryResult, error) {
+	return a.securityAlertRepository.Query(param)
+}
+
+// AckAlert 确认告警
+func (a SecurityService) AckAlert(id uint64, userID uint64) error {
+	if _, err := a.securityAlertRepository.Get(id); err != nil {
+		return err
+	}
+
+	return a.securityAlertRepository.Ack(id, userID)
+}
+
+// Dashboard 近 days 天的安全概览
+func (a SecurityService) Dashboard(days int) (*system.SecurityDashboardVO, error) {
+	if days <= 0 || days > 365 {
+		days = 7
+	}
+
+	byRule, err := a.securityAlertRepository.CountByRule(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, item := range byRule {
+		total += item.Count
+	}
+
+	open, err := a.securityAlertRepository.CountOpen()
+	if err != nil {
+		return nil, err
+	}
+
+	recent, err := a.securityAlertRepository.Recent(securityRecentLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &system.SecurityDashboardVO{
+		Days:         days,
+		Total:        total,
+		Open:         open,
+		ByRule:       byRule,
+		Recent:       recent,
+		EnabledRules: a.EnabledRules(),
+	}, nil
+}
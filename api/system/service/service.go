@@ -5,17 +5,52 @@ import "go.uber.org/fx"
 // Module exports services present
 var Module = fx.Options(
 	fx.Provide(NewPermissionCache),
+	fx.Provide(NewDictItemCache),
 	fx.Provide(NewUserService),
 	fx.Provide(NewRoleService),
 	fx.Provide(NewMenuService),
 	fx.Provide(NewPermissionService),
+	fx.Provide(NewDataScopeService),
+	fx.Provide(NewApiClientService),
 	fx.Provide(NewAuthService),
+	fx.Provide(NewApiKeyService),
 	fx.Provide(NewConfigService),
+	fx.Provide(NewMailService),
+	fx.Provide(NewWebhookService),
 	fx.Provide(NewNoticeService),
+	fx.Provide(NewNoticeDraftService),
 	fx.Provide(NewDeptService),
+	fx.Provide(NewPositionService),
 	fx.Provide(NewDictService),
 	fx.Provide(NewDictItemService),
 	fx.Provide(NewLogService),
 	fx.Provide(NewTaskService),
+	fx.Provide(NewTaskProgressService),
 	fx.Provide(NewDownloadService),
+	fx.Provide(NewDownloadUsageService),
+	fx.Provide(NewDownloadMediaService),
+	fx.Provide(NewDownloadTransferService),
+	fx.Provide(NewDownloadHealthService),
+	fx.Provide(NewDownloaderConfigService),
+	fx.Provide(NewLockService),
+	fx.Provide(NewRetentionService),
+	fx.Provide(NewCrontabService),
+	fx.Provide(NewApiUsageService),
+	fx.Provide(NewSecurityService),
+	fx.Provide(NewLoginGuardService),
+	fx.Provide(NewOperationLogService),
+	fx.Provide(NewTwoFactorService),
+	fx.Provide(NewComplianceService),
+	fx.Provide(NewExportService),
+	fx.Provide(NewTagService),
+	fx.Provide(NewUserJobService),
+	fx.Provide(NewProvisionService),
+	fx.Provide(NewConfigBackupService),
+	fx.Provide(NewPolicyService),
+	fx.Provide(NewDeptRoleService),
+	fx.Provide(NewProfileService),
+	fx.Provide(NewSavedQueryService),
+	fx.Provide(NewWsEventService),
+	fx.Provide(NewDirectoryService),
+	fx.Provide(NewFeatureModuleService),
 )
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	platformrepository "github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TagService 标签服务，提供标签管理以及用户、文件、通知、下载任务的打标签能力
+type TagService struct {
+	logger               lib.Logger
+	tagRepository        repository.TagRepository
+	userRepository       repository.UserRepository
+	noticeRepository     repository.NoticeRepository
+	downloadRepository   repository.DownloadRepository
+	fileObjectRepository platformrepository.FileObjectRepository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(
+	logger lib.Logger,
+	tagRepository repository.TagRepository,
+	userRepository repository.UserRepository,
+	noticeRepository repository.NoticeRepository,
+	downloadRepository repository.DownloadRepository,
+	fileObjectRepository platformrepository.FileObjectRepository,
+) TagService {
+	return TagService{
+		logger:               logger,
+		tagRepository:        tagRepository,
+		userRepository:       userRepository,
+		noticeRepository:     noticeRepository,
+		downloadRepository:   downloadRepository,
+		fileObjectRepository: fileObjectRepository,
+	}
+}
+
+// WithTrx delegates transaction to repository database
+func (a TagService) WithTrx(trxHandle *gorm.DB) TagService {
+	a.tagRepository = a.tagRepository.WithTrx(trxHandle)
+	a.userRepository = a.userRepository.WithTrx(trxHandle)
+	a.noticeRepository = a.noticeRepository.WithTrx(trxHandle)
+	a.downloadRepository = a.downloadRepository.WithTrx(trxHandle)
+	a.fileObjectRepository = a.fileObjectRepository.WithTrx(trxHandle)
+	return a
+}
+
+// Query 查询标签分页列表，附带每个标签关联的资源数
+func (a TagService) Query(param *system.TagQueryParam) (*system.TagQueryResult, error) {
+	qr, err := a.tagRepository.Query(param)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(qr.List))
+	for _, tag := range qr.List {
+		ids = append(ids, tag.ID)
+	}
+
+	counts, err := a.tagRepository.CountByTags(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range qr.List {
+		tag.ResourceCount = counts[tag.ID]
+	}
+
+	return qr, nil
+}
+
+// Get 获取标签
+func (a TagService) Get(id uint64) (*system.Tag, error) {
+	tag, err := a.tagRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := a.tagRepository.CountByTags([]uint64{id})
+	if err != nil {
+		return nil, err
+	}
+	tag.ResourceCount = counts[id]
+
+	return tag, nil
+}
+
+// Create 新增标签
+func (a TagService) Create(form *system.TagForm, createBy uint64) (uint64, error) {
+	name := strings.TrimSpace(form.Name)
+	if exist, err := a.tagRepository.GetByName(name); err != nil {
+		return 0, err
+	} else if exist != nil {
+		return 0, errors.TagNameAlreadyExists
+	}
+
+	tag := &system.Tag{
+		Name:     name,
+		Color:    form.Color,
+		Remark:   form.Remark,
+		CreateBy: createBy,
+	}
+	if err := a.tagRepository.Create(tag); err != nil {
+		return 0, err
+	}
+
+	return tag.ID, nil
+}
+
+// Update 修改标签
+func (a TagService) Update(id uint64, form *system.TagForm, updateBy uint64) error {
+	if _, err := a.tagRepository.Get(id); err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(form.Name)
+	if exist, err := a.tagRepository.GetByName(name); err != nil {
+		return err
+	} else if exist != nil && exist.ID != id {
+		return errors.TagNameAlreadyExists
+	}
+
+	return a.tagRepository.Update(id, &system.Tag{
+		Name:     name,
+		Color:    form.Color,
+		Remark:   form.Remark,
+		UpdateBy: updateBy,
+	})
+}
+
+// Delete 删除标签，同时移除其与资源的关联
+func (a TagService) Delete(id uint64) error {
+	if _, err := a.tagRepository.Get(id); err != nil {
+		return err
+	}
+
+	return a.tagRepository.Delete(id)
+}
+
+// ResourceTags 获取资源的标签
+func (a TagService) ResourceTags(resourceType string, resourceID uint64) (system.Tags, error) {
+	if !system.IsTagResourceType(resourceType) {
+		return nil, errors.TagResourceTypeInvalid
+	}
+
+	return a.tagRepository.ListByResource(resourceType, resourceID)
+}
+
+// SetResourceTags 整体替换资源的标签，tagIDs 为空时清除全部标签
+func (a TagService) SetResourceTags(resourceType string, resourceID uint64, tagIDs []uint64, createBy uint64) (system.Tags, error) {
+	if err := a.checkResource(resourceType, resourceID); err != nil {
+		return nil, err
+	}
+
+	tags, err := a.tagRepository.GetByIDs(tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验标签均存在（tagIDs 可能有重复）
+	ids := make([]uint64, 0, len(tags))
+	found := make(map[uint64]bool, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+		found[tag.ID] = true
+	}
+	for _, id := range tagIDs {
+		if !found[id] {
+			return nil, errors.TagRecordNotFound
+		}
+	}
+
+	if err := a.tagRepository.ReplaceTaggings(resourceType, resourceID, ids, createBy); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// checkResource 校验资源类型及资源是否存在
+func (a TagService) checkResource(resourceType string, resourceID uint64) error {
+	var err error
+	switch resourceType {
+	case system.TagResourceUser:
+		_, err = a.userRepository.Get(resourceID)
+	case system.TagResourceNotice:
+		_, err = a.noticeRepository.Get(resourceID)
+	case system.TagResourceDownload:
+		_, err = a.downloadRepository.Get(resourceID)
+	case system.TagResourceFile:
+		object, ferr := a.fileObjectRepository.Get(resourceID)
+		if ferr != nil {
+			return ferr
+		} else if object == nil {
+			return errors.TagResourceNotFound
+		}
+	default:
+		return errors.TagResourceTypeInvalid
+	}
+
+	if errors.Is(err, errors.DatabaseRecordNotFound) {
+		return errors.TagResourceNotFound
+	}
+
+	return err
+}
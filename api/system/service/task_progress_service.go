@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/queue"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+// TaskProgressInterval 队列任务与下载进度的推送间隔
+const TaskProgressInterval = 2 * time.Second
+
+// TaskProgressService 定时推送当前实例中队列任务的进度（/topic/queue/tasks）
+// 以及下载任务的实时进度（/user/queue/downloads），替代前端轮询
+type TaskProgressService struct {
+	logger          lib.Logger
+	ws              *ws.WebSocket
+	taskQueue       lib.TaskQueue
+	taskRepository  repository.TaskRepository
+	downloadService DownloadService
+	// 上一次推送时在队列中的任务，用于发现已结束的任务
+	mu   *sync.Mutex
+	live map[int]string
+}
+
+// NewTaskProgressService 创建任务进度推送服务，随应用启动与停止
+func NewTaskProgressService(
+	lc fx.Lifecycle,
+	logger lib.Logger,
+	websocket *ws.WebSocket,
+	taskQueue lib.TaskQueue,
+	taskRepository repository.TaskRepository,
+	downloadService DownloadService,
+) TaskProgressService {
+	svc := TaskProgressService{
+		logger:          logger,
+		ws:              websocket,
+		taskQueue:       taskQueue,
+		taskRepository:  taskRepository,
+		downloadService: downloadService,
+		mu:              new(sync.Mutex),
+		live:            make(map[int]string),
+	}
+
+	websocket.RegisterTopic(ws.Topic{
+		Destination: ws.TopicQueueTasks,
+		Module:      "task",
+		Description: "当前实例中队列任务的状态与进度，订阅时推送快照（snapshot），之后定时推送（progress），任务结束时推送最终状态（finished）",
+		Permission:  "sys:task:query",
+		Payload:     system.TaskProgressEvent{},
+	})
+	websocket.RegisterSnapshot(ws.TopicQueueTasks, svc.snapshot)
+
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go svc.run(done)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+
+	return svc
+}
+
+// run 定时推送，直到 done 关闭
+func (a TaskProgressService) run(done chan struct{}) {
+	ticker := time.NewTicker(TaskProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			a.Publish(context.Background())
+		}
+	}
+}
+
+// Publish 推送一次队列任务与下载任务的进度
+func (a TaskProgressService) Publish(ctx context.Context) {
+	if !a.taskQueue.IsEnabled() {
+		return
+	}
+
+	tasks := a.taskQueue.Registry.List()
+	finished := a.finished(tasks)
+	for id, taskType := range finished {
+		if taskType != queue.RemoteDownloadTaskType {
+			continue
+		}
+		if err := a.downloadService.SyncQueueTask(ctx, uint64(id)); err != nil {
+			a.logger.Zap.Debugf("Failed to sync download of queue task %d: %v", id, err)
+		}
+	}
+
+	if a.ws.Broker.HasSubscribers(ws.TopicQueueTasks) {
+		now := time.Now().UnixMilli()
+		if len(finished) > 0 {
+			a.ws.Broker.Publish(ws.TopicQueueTasks, &system.TaskProgressEvent{
+				Type:      system.TaskEventFinished,
+				Tasks:     a.finalProgress(finished),
+				Timestamp: now,
+			})
+		}
+		if len(tasks) > 0 {
+			a.ws.Broker.Publish(ws.TopicQueueTasks, &system.TaskProgressEvent{
+				Type:      system.TaskEventProgress,
+				Tasks:     liveProgress(ctx, tasks),
+				Timestamp: now,
+			})
+		}
+	}
+
+	a.downloadService.PublishProgress(ctx)
+}
+
+// finished 记录本次在队列中的任务，返回上次推送后离开队列的任务 ID 与类型
+func (a TaskProgressService) finished(tasks []queue.Task) map[int]string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current := make(map[int]string, len(tasks))
+	for _, t := range tasks {
+		current[t.ID()] = t.Type()
+	}
+
+	finished := make(map[int]string)
+	for id, taskType := range a.live {
+		if _, ok := current[id]; !ok {
+			finished[id] = taskType
+		}
+	}
+	a.live = current
+	return finished
+}
+
+// finalProgress 已结束任务的最终状态，任务记录已删除时只返回 ID 与类型
+func (a TaskProgressService) finalProgress(finished map[int]string) []*system.TaskProgressVO {
+	list := make([]*system.TaskProgressVO, 0, len(finished))
+	for id, taskType := range finished {
+		vo := &system.TaskProgressVO{ID: uint64(id), Type: taskType}
+		if task, err := a.taskRepository.Get(uint64(id)); err == nil {
+			vo.Status = string(task.Status)
+			vo.Progress = task.ProgressSnapshot()
+			vo.ProgressUpdatedAt = task.ProgressAt
+		}
+		list = append(list, vo)
+	}
+	return list
+}
+
+// snapshot 订阅 /topic/queue/tasks 时推送的快照
+func (a TaskProgressService) snapshot(session *stomp.Session, destination string) (interface{}, bool) {
+	if !a.taskQueue.IsEnabled() {
+		return nil, false
+	}
+
+	return &system.TaskProgressEvent{
+		Type:      system.TaskEventSnapshot,
+		Tasks:     liveProgress(context.Background(), a.taskQueue.Registry.List()),
+		Timestamp: time.Now().UnixMilli(),
+	}, true
+}
+
+// liveProgress 运行中任务的实时摘要与进度
+func liveProgress(ctx context.Context, tasks []queue.Task) []*system.TaskProgressVO {
+	now := time.Now().UnixMilli()
+	list := make([]*system.TaskProgressVO, 0, len(tasks))
+	for _, t := range tasks {
+		list = append(list, &system.TaskProgressVO{
+			ID:                uint64(t.ID()),
+			Type:              t.Type(),
+			Status:            string(t.Status()),
+			Live:              true,
+			Summary:           t.Summarize(),
+			Progress:          t.Progress(ctx),
+			ProgressUpdatedAt: now,
+		})
+	}
+	return list
+}
@@ -1,27 +1,38 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/queue"
 )
 
 // TaskService service layer
 type TaskService struct {
 	logger         lib.Logger
 	taskRepository repository.TaskRepository
+	taskQueue      lib.TaskQueue
+	queues         lib.QueueManager
 }
 
 // NewTaskService creates a new task service
 func NewTaskService(
 	logger lib.Logger,
 	taskRepository repository.TaskRepository,
+	taskQueue lib.TaskQueue,
+	queues lib.QueueManager,
 ) TaskService {
 	return TaskService{
 		logger:         logger,
 		taskRepository: taskRepository,
+		taskQueue:      taskQueue,
+		queues:         queues,
 	}
 }
 
@@ -60,3 +71,77 @@ func (a TaskService) GetTaskTypes() ([]system.TaskTypeVO, error) {
 func (a TaskService) GetStats() (*system.TaskStatsVO, error) {
 	return a.taskRepository.GetStatusCounts()
 }
+
+// Progress 获取任务摘要与进度，任务在当前实例运行时返回实时进度，否则返回最后一次持久化的快照
+func (a TaskService) Progress(ctx context.Context, id uint64) (*system.TaskProgressVO, error) {
+	task, err := a.taskRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	vo := &system.TaskProgressVO{
+		ID:                task.ID,
+		Type:              task.Type,
+		Status:            string(task.Status),
+		Progress:          task.ProgressSnapshot(),
+		ProgressUpdatedAt: task.ProgressAt,
+	}
+	if !a.taskQueue.IsEnabled() {
+		return vo, nil
+	}
+
+	if t, ok := a.taskQueue.Registry.Get(int(id)); ok {
+		vo.Live = true
+		vo.Status = string(t.Status())
+		vo.Summary = t.Summarize()
+		vo.Progress = t.Progress(ctx)
+		vo.ProgressUpdatedAt = time.Now().UnixMilli()
+		return vo, nil
+	}
+
+	// 不在当前实例运行的任务从持久化记录恢复摘要，未注册恢复方式的任务类型没有摘要
+	if model, err := a.taskQueue.Repository.GetByID(ctx, id); err == nil && model != nil {
+		if t, err := queue.NewTaskFromModel(model); err == nil {
+			vo.Summary = t.Summarize()
+		}
+	}
+	return vo, nil
+}
+
+// Cancel 取消排队中或挂起中的任务，只能取消在当前实例中的任务
+func (a TaskService) Cancel(ctx context.Context, id uint64) error {
+	if !a.taskQueue.IsEnabled() {
+		return errors.TaskQueueNotEnabled
+	}
+	task, err := a.taskRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// 命名队列中的任务由执行该类型任务的队列取消
+	q := a.queues.ForType(task.Type)
+	err = q.Queue.Cancel(ctx, int(id))
+	if errors.Is(err, queue.ErrTaskNotFound) || errors.Is(err, queue.ErrTaskNotCancelable) {
+		return errors.Wrap(errors.TaskNotCancelable, err.Error())
+	}
+	return err
+}
+
+// Retry 重新执行失败的任务，任务类型需要注册恢复方式（queue.RegisterResumableTaskFactory）
+func (a TaskService) Retry(ctx context.Context, id uint64) error {
+	if !a.taskQueue.IsEnabled() {
+		return errors.TaskQueueNotEnabled
+	}
+	task, err := a.taskRepository.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// 重新提交到执行该类型任务的队列
+	q := a.queues.ForType(task.Type)
+	err = q.Queue.Retry(ctx, int(id))
+	if errors.Is(err, queue.ErrTaskNotFound) || errors.Is(err, queue.ErrTaskNotRetryable) {
+		return errors.Wrap(errors.TaskNotRetryable, err.Error())
+	}
+	return err
+}
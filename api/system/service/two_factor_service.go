@@ -0,0 +1,204 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/secret"
+	"github.com/top-system/light-admin/pkg/totp"
+)
+
+// TwoFactorService TOTP 两步验证，配置 Auth.TwoFactor 后启用
+// 密钥加密保存在用户表中，已使用的时间步记录在缓存中防止验证码被重放
+type TwoFactorService struct {
+	cache          lib.Cache
+	logger         lib.Logger
+	issuer         string
+	box            *secret.Box
+	userRepository repository.UserRepository
+}
+
+// NewTwoFactorService creates a new two-factor service
+func NewTwoFactorService(
+	cache lib.Cache,
+	config lib.Config,
+	logger lib.Logger,
+	userRepository repository.UserRepository,
+) TwoFactorService {
+	issuer := config.Name
+	var box *secret.Box
+	if c := config.Auth.TwoFactor; c != nil {
+		if c.Issuer != "" {
+			issuer = c.Issuer
+		}
+		// 不沿用签名密钥或可推导的默认值，泄露其一不会连带暴露 TOTP 密钥
+		if c.EncryptKey == "" {
+			logger.Zap.Fatal("Auth.TwoFactor.EncryptKey is required when two-factor authentication is enabled")
+		}
+
+		var err error
+		if box, err = secret.NewBox(c.EncryptKey); err != nil {
+			logger.Zap.Fatalf("Error to create two-factor secret box: %v", err)
+		}
+	}
+
+	return TwoFactorService{
+		cache:          cache,
+		logger:         logger,
+		issuer:         issuer,
+		box:            box,
+		userRepository: userRepository,
+	}
+}
+
+func wrapperTwoFactorUsedKey(userID uint64) string {
+	return fmt.Sprintf("auth:totp-used:%d", userID)
+}
+
+// Enroll 生成新的密钥并保存（未启用），验证码校验通过后才启用，已启用时需先关闭
+func (a TwoFactorService) Enroll(claims *dto.JwtClaims) (*dto.TwoFactorEnrollResponse, error) {
+	if a.box == nil {
+		return nil, errors.TwoFactorDisabled
+	}
+
+	user, err := a.user(claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.TwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := a.box.Encrypt(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.userRepository.UpdateTwoFactor(user.ID, encrypted, false); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollResponse{
+		Secret: key,
+		URI:    totp.ProvisioningURI(a.issuer, user.Username, key),
+	}, nil
+}
+
+// Activate 校验绑定后的第一个验证码并启用两步验证
+func (a TwoFactorService) Activate(claims *dto.JwtClaims, code string) error {
+	if a.box == nil {
+		return errors.TwoFactorDisabled
+	}
+
+	user, err := a.user(claims)
+	if err != nil {
+		return err
+	}
+	if user.TwoFactorEnabled {
+		return errors.TwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return errors.TwoFactorNotEnrolled
+	}
+
+	if !a.validate(user, code) {
+		return errors.TwoFactorInvalidCode
+	}
+
+	return a.userRepository.UpdateTwoFactor(user.ID, user.TwoFactorSecret, true)
+}
+
+// Disable 用户凭当前验证码关闭两步验证
+func (a TwoFactorService) Disable(claims *dto.JwtClaims, code string) error {
+	if a.box == nil {
+		return errors.TwoFactorDisabled
+	}
+
+	user, err := a.user(claims)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return errors.TwoFactorNotEnabled
+	}
+
+	if !a.validate(user, code) {
+		return errors.TwoFactorInvalidCode
+	}
+
+	return a.userRepository.UpdateTwoFactor(user.ID, "", false)
+}
+
+// Reset 管理员重置用户的两步验证（用户丢失验证器时），用户下次登录不再需要验证码
+func (a TwoFactorService) Reset(userID uint64) error {
+	if _, err := a.userRepository.Get(userID); err != nil {
+		return err
+	}
+
+	if err := a.userRepository.UpdateTwoFactor(userID, "", false); err != nil {
+		return err
+	}
+	_, _ = a.cache.Delete(wrapperTwoFactorUsedKey(userID))
+	return nil
+}
+
+// VerifyLogin 登录时校验验证码，未启用两步验证的用户直接通过
+// 已启用的用户在移除 Auth.TwoFactor 配置后无法校验，需要管理员重置后才能登录
+func (a TwoFactorService) VerifyLogin(user *system.User, code string) error {
+	if !user.TwoFactorEnabled {
+		return nil
+	}
+	if a.box == nil {
+		return errors.TwoFactorDisabled
+	}
+	if code == "" {
+		return errors.AuthTwoFactorRequired
+	}
+	if !a.validate(user, code) {
+		return errors.AuthTwoFactorInvalid
+	}
+
+	return nil
+}
+
+// user 当前登录的用户，配置文件中的超级管理员不在用户表中，不能绑定
+func (a TwoFactorService) user(claims *dto.JwtClaims) (*system.User, error) {
+	if claims.ID == 0 || claims.ServiceAccount {
+		return nil, errors.TwoFactorUnavailable
+	}
+
+	return a.userRepository.Get(claims.ID)
+}
+
+// validate 校验验证码，同一时间步及更早的验证码只能使用一次
+func (a TwoFactorService) validate(user *system.User, code string) bool {
+	key, err := a.box.Decrypt(user.TwoFactorSecret)
+	if err != nil {
+		a.logger.Zap.Errorf("Failed to decrypt two-factor secret of %s: %v", user.Username, err)
+		return false
+	}
+
+	counter, ok := totp.Validate(key, code, time.Now())
+	if !ok {
+		return false
+	}
+
+	var used uint64
+	if err := a.cache.Get(wrapperTwoFactorUsedKey(user.ID), &used); err == nil && counter <= used {
+		return false
+	}
+	ttl := time.Duration(2*totp.Skew+1) * totp.Period * time.Second
+	if err := a.cache.Set(wrapperTwoFactorUsedKey(user.ID), counter, ttl); err != nil {
+		a.logger.Zap.Warnf("Failed to record used two-factor code of %s: %v", user.Username, err)
+	}
+
+	return true
+}
@@ -0,0 +1,660 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/report"
+)
+
+const (
+	// UserJobTaskType 用户定时任务队列任务类型
+	UserJobTaskType = "user_job"
+
+	// 定时任务名称前缀，完整名称为 user_job:<id>
+	userJobCronPrefix = "user_job:"
+	// 默认配额
+	userJobDefaultMaxPerUser  = 5
+	userJobDefaultMinInterval = 60
+	// 计算 cron 表达式最小间隔时采样的执行次数
+	userJobIntervalSamples = 100
+
+	// activity_report 参数默认值及上限
+	userJobReportDefaultDays = 7
+	userJobReportMaxDays     = 90
+	userJobReportLimit       = 1000
+
+	// 任务结果通知类型（字典 notice_type：其他）
+	userJobNoticeType = 99
+)
+
+// userJobTypes 任务类型目录
+var userJobTypes = []*system.UserJobTypeVO{
+	{
+		Type:        system.UserJobActivityReport,
+		Description: "导出本人最近的登录记录及接口调用统计，完成后通过站内通知发送下载地址",
+		Example:     system.UserJobActivityReportPayload{Days: userJobReportDefaultDays, Format: report.FormatXLSX},
+	},
+	{
+		Type:        system.UserJobURLDownload,
+		Description: "定时将指定 URL 提交到下载器，下载任务归属本人",
+		Example:     system.UserJobURLDownloadPayload{URL: "https://example.com/file.zip"},
+	},
+}
+
+// userJobTypePerms 任务类型所需的权限标识，创建、修改及每次执行时按所有者当前的权限校验
+var userJobTypePerms = map[string]string{
+	system.UserJobURLDownload: "sys:download:add",
+}
+
+// UserJobService 用户自助定时任务服务，普通用户可在配额内创建受限类型的周期任务
+type UserJobService struct {
+	logger               lib.Logger
+	config               lib.Config
+	crontab              lib.Crontab
+	taskQueue            lib.TaskQueue
+	featureModules       lib.FeatureModules
+	fileService          platformservice.FileService
+	noticeService        NoticeService
+	downloadService      DownloadService
+	userService          UserService
+	permissionService    PermissionService
+	userJobRepository    repository.UserJobRepository
+	userRepository       repository.UserRepository
+	loginEventRepository repository.LoginEventRepository
+	apiUsageRepository   repository.ApiUsageRepository
+}
+
+// NewUserJobService 创建用户定时任务服务，启用时将已启用的任务注册到定时任务
+func NewUserJobService(
+	logger lib.Logger,
+	config lib.Config,
+	crontab lib.Crontab,
+	taskQueue lib.TaskQueue,
+	featureModules lib.FeatureModules,
+	fileService platformservice.FileService,
+	noticeService NoticeService,
+	downloadService DownloadService,
+	userService UserService,
+	permissionService PermissionService,
+	userJobRepository repository.UserJobRepository,
+	userRepository repository.UserRepository,
+	loginEventRepository repository.LoginEventRepository,
+	apiUsageRepository repository.ApiUsageRepository,
+) UserJobService {
+	svc := UserJobService{
+		logger:               logger,
+		config:               config,
+		crontab:              crontab,
+		taskQueue:            taskQueue,
+		featureModules:       featureModules,
+		fileService:          fileService,
+		noticeService:        noticeService,
+		downloadService:      downloadService,
+		userService:          userService,
+		permissionService:    permissionService,
+		userJobRepository:    userJobRepository,
+		userRepository:       userRepository,
+		loginEventRepository: loginEventRepository,
+		apiUsageRepository:   apiUsageRepository,
+	}
+
+	if svc.enabled() {
+		jobs, err := userJobRepository.ListEnabled()
+		if err != nil {
+			logger.Zap.Errorf("Failed to load user jobs: %v", err)
+			return svc
+		}
+
+		for _, job := range jobs {
+			if err := svc.schedule(job); err != nil {
+				logger.Zap.Errorf("Failed to schedule user job %d: %v", job.ID, err)
+			}
+		}
+	}
+
+	return svc
+}
+
+// enabled 功能开关，同时要求启用定时任务
+func (a UserJobService) enabled() bool {
+	return a.config.UserJobs != nil && a.config.UserJobs.Enable && a.crontab.IsEnabled()
+}
+
+func (a UserJobService) maxPerUser() int {
+	if a.config.UserJobs != nil && a.config.UserJobs.MaxPerUser > 0 {
+		return a.config.UserJobs.MaxPerUser
+	}
+	return userJobDefaultMaxPerUser
+}
+
+// minInterval 最小执行间隔（分钟）
+func (a UserJobService) minInterval() int {
+	if a.config.UserJobs != nil && a.config.UserJobs.MinInterval > 0 {
+		return a.config.UserJobs.MinInterval
+	}
+	return userJobDefaultMinInterval
+}
+
+// typeAllowed 判断任务类型是否开放，离线下载模块禁用时不开放定时下载
+func (a UserJobService) typeAllowed(taskType string) bool {
+	if taskType == system.UserJobURLDownload && !a.featureModules.IsEnabled(lib.FeatureModuleDownload) {
+		return false
+	}
+
+	known := false
+	for _, t := range userJobTypes {
+		if t.Type == taskType {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return false
+	}
+
+	if a.config.UserJobs == nil || len(a.config.UserJobs.Types) == 0 {
+		return true
+	}
+	for _, t := range a.config.UserJobs.Types {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTypePerm 校验用户是否具备任务类型所需的权限，超级管理员不受限制
+func (a UserJobService) checkTypePerm(taskType string, userID uint64) error {
+	perm, ok := userJobTypePerms[taskType]
+	if !ok {
+		return nil
+	}
+
+	owner, err := a.userRepository.Get(userID)
+	if err != nil {
+		if errors.Is(err, errors.DatabaseRecordNotFound) {
+			return errors.UserRecordNotFound
+		}
+		return err
+	}
+	if a.userService.IsSuperAdmin(owner.Username) {
+		return nil
+	}
+
+	perms, err := a.permissionService.GetUserPerms(userID)
+	if err != nil {
+		return err
+	}
+	if !MatchPerm(perms, perm) {
+		return errors.Wrapf(errors.UserJobPermDenied, "%s requires %s", taskType, perm)
+	}
+
+	return nil
+}
+
+// Catalog 获取可用任务类型及用户配额
+func (a UserJobService) Catalog(userID uint64) (*system.UserJobCatalogVO, error) {
+	if !a.enabled() {
+		return nil, errors.UserJobDisabled
+	}
+
+	used, err := a.userJobRepository.CountByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]*system.UserJobTypeVO, 0, len(userJobTypes))
+	for _, t := range userJobTypes {
+		if a.typeAllowed(t.Type) && a.checkTypePerm(t.Type, userID) == nil {
+			types = append(types, t)
+		}
+	}
+
+	return &system.UserJobCatalogVO{
+		Types:       types,
+		MaxPerUser:  a.maxPerUser(),
+		MinInterval: a.minInterval(),
+		Used:        used,
+	}, nil
+}
+
+// List 获取用户的定时任务，附带下次执行时间
+func (a UserJobService) List(userID uint64) (system.UserJobs, error) {
+	if !a.enabled() {
+		return nil, errors.UserJobDisabled
+	}
+
+	jobs, err := a.userJobRepository.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if info, err := a.crontab.Cron.GetTask(userJobCronName(job.ID)); err == nil && !info.Next.IsZero() {
+			job.NextRunTime = dto.NullDateTime{Time: info.Next, Valid: true}
+		}
+	}
+
+	return jobs, nil
+}
+
+// Get 获取用户的定时任务，非本人任务视为不存在
+func (a UserJobService) Get(id, userID uint64) (*system.UserJob, error) {
+	if !a.enabled() {
+		return nil, errors.UserJobDisabled
+	}
+
+	job, err := a.userJobRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, errors.UserJobRecordNotFound
+	}
+
+	return job, nil
+}
+
+// Create 创建定时任务
+func (a UserJobService) Create(form *system.UserJobForm, userID uint64) (*system.UserJob, error) {
+	if !a.enabled() {
+		return nil, errors.UserJobDisabled
+	}
+
+	if err := a.validate(form); err != nil {
+		return nil, err
+	}
+	if err := a.checkTypePerm(form.TaskType, userID); err != nil {
+		return nil, err
+	}
+
+	count, err := a.userJobRepository.CountByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= int64(a.maxPerUser()) {
+		return nil, errors.Wrapf(errors.UserJobQuotaExceeded, "at most %d jobs per user", a.maxPerUser())
+	}
+
+	job := &system.UserJob{
+		UserID:   userID,
+		Name:     strings.TrimSpace(form.Name),
+		TaskType: form.TaskType,
+		Spec:     strings.TrimSpace(form.Spec),
+		Payload:  form.Payload,
+		Status:   1,
+	}
+	if form.Status != nil && *form.Status == 0 {
+		job.Status = 0
+	}
+
+	if err := a.userJobRepository.Create(job); err != nil {
+		return nil, err
+	}
+
+	if err := a.schedule(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Update 修改定时任务并重新调度
+func (a UserJobService) Update(id uint64, form *system.UserJobForm, userID uint64) error {
+	job, err := a.Get(id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := a.validate(form); err != nil {
+		return err
+	}
+	if err := a.checkTypePerm(form.TaskType, userID); err != nil {
+		return err
+	}
+
+	job.Name = strings.TrimSpace(form.Name)
+	job.TaskType = form.TaskType
+	job.Spec = strings.TrimSpace(form.Spec)
+	job.Payload = form.Payload
+	if form.Status != nil && *form.Status == 0 {
+		job.Status = 0
+	} else if form.Status != nil {
+		job.Status = 1
+	}
+
+	if err := a.userJobRepository.Update(id, map[string]interface{}{
+		"name":      job.Name,
+		"task_type": job.TaskType,
+		"spec":      job.Spec,
+		"payload":   job.Payload,
+		"status":    job.Status,
+	}); err != nil {
+		return err
+	}
+
+	return a.schedule(job)
+}
+
+// Delete 删除定时任务
+func (a UserJobService) Delete(id, userID uint64) error {
+	if _, err := a.Get(id, userID); err != nil {
+		return err
+	}
+
+	if err := a.userJobRepository.Delete(id); err != nil {
+		return err
+	}
+
+	a.unschedule(id)
+	return nil
+}
+
+// RunNow 立即执行一次，同样受最小执行间隔限制
+func (a UserJobService) RunNow(id, userID uint64) error {
+	job, err := a.Get(id, userID)
+	if err != nil {
+		return err
+	}
+
+	if !a.typeAllowed(job.TaskType) {
+		return errors.UserJobTypeNotAllowed
+	}
+
+	interval := time.Duration(a.minInterval()) * time.Minute
+	if job.LastRunTime.Valid && time.Since(job.LastRunTime.Time) < interval {
+		return errors.Wrapf(errors.UserJobIntervalTooShort, "jobs can run at most once every %d minutes", a.minInterval())
+	}
+
+	return a.dispatch(job)
+}
+
+// validate 校验任务类型、执行频率及参数
+func (a UserJobService) validate(form *system.UserJobForm) error {
+	if !a.typeAllowed(form.TaskType) {
+		return errors.UserJobTypeNotAllowed
+	}
+
+	spec := strings.TrimSpace(form.Spec)
+	if err := crontab.ValidateSpec(spec); err != nil {
+		return errors.Wrap(errors.UserJobSpecInvalid, err.Error())
+	}
+
+	interval, err := crontab.MinInterval(spec, userJobIntervalSamples)
+	if err != nil {
+		return errors.Wrap(errors.UserJobSpecInvalid, err.Error())
+	}
+	if interval < time.Duration(a.minInterval())*time.Minute {
+		return errors.Wrapf(errors.UserJobIntervalTooShort, "jobs can run at most once every %d minutes", a.minInterval())
+	}
+
+	switch form.TaskType {
+	case system.UserJobActivityReport:
+		payload, err := activityReportPayload(form.Payload)
+		if err != nil {
+			return err
+		}
+		if payload.Days > userJobReportMaxDays {
+			return errors.Wrapf(errors.UserJobPayloadInvalid, "days must not exceed %d", userJobReportMaxDays)
+		}
+		if !report.IsFormatSupported(payload.Format) {
+			return errors.Wrapf(errors.UserJobPayloadInvalid, "unsupported format: %s", payload.Format)
+		}
+	case system.UserJobURLDownload:
+		payload := new(system.UserJobURLDownloadPayload)
+		if err := decodeUserJobPayload(form.Payload, payload); err != nil {
+			return err
+		}
+		u, err := url.Parse(payload.URL)
+		if err != nil || u.Host == "" {
+			return errors.Wrap(errors.UserJobPayloadInvalid, "a valid url is required")
+		}
+		if payload.Downloader != "" {
+			found := false
+			for _, d := range a.downloadService.GetAvailableDownloaders() {
+				if d["value"] == payload.Downloader {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return errors.Wrapf(errors.UserJobPayloadInvalid, "unknown downloader: %s", payload.Downloader)
+			}
+		}
+	}
+
+	return nil
+}
+
+// schedule 按任务状态注册或移除定时任务
+func (a UserJobService) schedule(job *system.UserJob) error {
+	a.unschedule(job.ID)
+
+	if job.Status != 1 {
+		return nil
+	}
+
+	id := job.ID
+	return a.crontab.AddTask(userJobCronName(id), job.Spec, func(ctx context.Context) {
+		job, err := a.userJobRepository.Get(id)
+		if err != nil {
+			a.logger.Zap.Errorf("Failed to load user job %d: %v", id, err)
+			crontab.SetError(ctx, err)
+			return
+		}
+
+		if err := a.dispatch(job); err != nil {
+			a.logger.Zap.Errorf("Failed to dispatch user job %d: %v", id, err)
+			crontab.SetError(ctx, err)
+		}
+	})
+}
+
+func (a UserJobService) unschedule(id uint64) {
+	_ = a.crontab.RemoveTask(userJobCronName(id))
+}
+
+// dispatch 以任务所有者身份提交到任务队列；未启用任务队列时在后台协程中执行
+func (a UserJobService) dispatch(job *system.UserJob) error {
+	run := func(ctx context.Context) error {
+		return a.execute(ctx, job)
+	}
+
+	if a.taskQueue.IsEnabled() {
+		task := queue.NewFuncTask(UserJobTaskType, &queue.TaskOwner{ID: job.UserID}, run)
+		if err := a.taskQueue.QueueTask(context.Background(), task); err != nil {
+			return errors.Wrap(err, "failed to queue user job")
+		}
+	} else {
+		go func() {
+			_ = run(context.Background())
+		}()
+	}
+
+	return nil
+}
+
+// execute 执行任务并记录结果；任务所有者不存在或已停用时不执行
+func (a UserJobService) execute(ctx context.Context, job *system.UserJob) error {
+	err := a.run(ctx, job)
+
+	values := map[string]interface{}{
+		"last_run_time": time.Now(),
+		"last_status":   system.UserJobRunSuccess,
+		"last_error":    "",
+	}
+	if err != nil {
+		a.logger.Zap.Errorf("User job %d failed: %v", job.ID, err)
+
+		message := err.Error()
+		if len(message) > 500 {
+			message = message[:500]
+		}
+		values["last_status"] = system.UserJobRunFailed
+		values["last_error"] = message
+	}
+
+	if uerr := a.userJobRepository.IncrRun(job.ID, values); uerr != nil {
+		a.logger.Zap.Errorf("Failed to record user job %d result: %v", job.ID, uerr)
+	}
+
+	return err
+}
+
+func (a UserJobService) run(ctx context.Context, job *system.UserJob) error {
+	owner, err := a.userRepository.Get(job.UserID)
+	if err != nil {
+		if errors.Is(err, errors.DatabaseRecordNotFound) {
+			return errors.UserRecordNotFound
+		}
+		return err
+	}
+	if owner.Status != 1 {
+		return errors.UserIsDisable
+	}
+
+	if !a.typeAllowed(job.TaskType) {
+		return errors.UserJobTypeNotAllowed
+	}
+	if err := a.checkTypePerm(job.TaskType, job.UserID); err != nil {
+		return err
+	}
+
+	switch job.TaskType {
+	case system.UserJobActivityReport:
+		return a.runActivityReport(ctx, job, owner)
+	case system.UserJobURLDownload:
+		return a.runURLDownload(ctx, job)
+	default:
+		return errors.UserJobTypeNotAllowed
+	}
+}
+
+// runActivityReport 生成本人的活动报表并通过站内通知发送下载地址
+func (a UserJobService) runActivityReport(ctx context.Context, job *system.UserJob, owner *system.User) error {
+	payload, err := activityReportPayload(job.Payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -payload.Days)
+
+	events, err := a.loginEventRepository.ListSince(owner.ID, since, userJobReportLimit)
+	if err != nil {
+		return err
+	}
+
+	usage, err := a.apiUsageRepository.Query(&system.ApiUsageQueryParam{
+		PaginationParam: dto.PaginationParam{PageNum: 1, PageSize: userJobReportLimit},
+		UserID:          owner.ID,
+		From:            since.Format(dto.DateTimeFormat),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r := &report.Report{
+		Title:    "个人活动报告：" + owner.Username,
+		Subtitle: fmt.Sprintf("统计区间：%s 至 %s", since.Format(dto.DateTimeFormat), now.Format(dto.DateTimeFormat)),
+	}
+
+	logins := r.AddSection("登录记录", "时间", "IP", "国家", "地区", "城市")
+	for _, event := range events {
+		logins.AddRow(event.CreateTime.Time().Format(dto.DateTimeFormat), event.IP, event.Country, event.Region, event.City)
+	}
+
+	calls := r.AddSection("接口调用", "时间", "方法", "路由", "请求数", "错误数")
+	for _, item := range usage.List {
+		calls.AddRow(item.BucketTime.Format(dto.DateTimeFormat), item.Method, item.Route,
+			strconv.FormatInt(item.RequestCount, 10), strconv.FormatInt(item.ErrorCount, 10))
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, payload.Format); err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("activity-report-%d-%s.%s", owner.ID, now.Format("20060102150405"), payload.Format)
+	info, err := a.fileService.UploadFile(filename, &buf, int64(buf.Len()), report.ContentType(payload.Format))
+	if err != nil {
+		return err
+	}
+
+	return a.noticeService.Send(&system.Notice{
+		Title:   "定时任务完成：" + job.Name,
+		Content: fmt.Sprintf("个人活动报告 %s 已生成，下载地址：%s", info.Name, info.URL),
+		Type:    userJobNoticeType,
+		Level:   "L",
+	}, []uint64{owner.ID})
+}
+
+// runURLDownload 以任务所有者身份创建下载任务
+func (a UserJobService) runURLDownload(ctx context.Context, job *system.UserJob) error {
+	payload := new(system.UserJobURLDownloadPayload)
+	if err := decodeUserJobPayload(job.Payload, payload); err != nil {
+		return err
+	}
+
+	_, err := a.downloadService.Create(ctx, &system.DownloadTaskCreateForm{
+		URL:        payload.URL,
+		Downloader: payload.Downloader,
+	}, job.UserID)
+	return err
+}
+
+func userJobCronName(id uint64) string {
+	return userJobCronPrefix + strconv.FormatUint(id, 10)
+}
+
+// decodeUserJobPayload 解析任务参数，拒绝未知字段
+func decodeUserJobPayload(raw []byte, v interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return errors.Wrap(errors.UserJobPayloadInvalid, err.Error())
+	}
+
+	return nil
+}
+
+// activityReportPayload 解析 activity_report 参数并填充默认值
+func activityReportPayload(raw []byte) (*system.UserJobActivityReportPayload, error) {
+	payload := new(system.UserJobActivityReportPayload)
+	if err := decodeUserJobPayload(raw, payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Days <= 0 {
+		payload.Days = userJobReportDefaultDays
+	}
+	payload.Format = strings.ToLower(payload.Format)
+	if payload.Format == "" {
+		payload.Format = report.FormatXLSX
+	}
+
+	return payload, nil
+}
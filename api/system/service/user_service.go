@@ -1,30 +1,48 @@
 package service
 
 import (
+	"context"
 	"sort"
 
 	"gorm.io/gorm"
 
 	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/constants"
 	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 	"github.com/top-system/light-admin/models/system"
 	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/eventbus"
 	"github.com/top-system/light-admin/pkg/hash"
+	"github.com/top-system/light-admin/pkg/str"
+)
+
+// 强制下线时 ERROR 帧中的原因
+const (
+	forceLogoutDisabled = "Account disabled"
+	forceLogoutDeleted  = "Account deleted"
 )
 
 // UserService service layer
 type UserService struct {
-	logger             lib.Logger
-	config             lib.Config
-	db                 lib.Database
-	userRepository     repository.UserRepository
-	userRoleRepository repository.UserRoleRepository
-	menuRepository     repository.MenuRepository
-	roleRepository     repository.RoleRepository
-	roleMenuRepository repository.RoleMenuRepository
-	deptRepository     repository.DeptRepository
-	permissionCache    PermissionCache
+	logger                 lib.Logger
+	config                 lib.Config
+	db                     lib.Database
+	userRepository         repository.UserRepository
+	userRoleRepository     repository.UserRoleRepository
+	userPositionRepository repository.UserPositionRepository
+	apiKeyRepository       repository.ApiKeyRepository
+	menuRepository         repository.MenuRepository
+	roleRepository         repository.RoleRepository
+	roleMenuRepository     repository.RoleMenuRepository
+	deptRepository         repository.DeptRepository
+	permissionCache        PermissionCache
+	permissionService      PermissionService
+	responseCache          lib.ResponseCache
+	deptRoleService        DeptRoleService
+	authService            AuthService
+	webhookService         WebhookService
+	bus                    *eventbus.Bus
 }
 
 // NewUserService creates a new user service
@@ -34,28 +52,49 @@ func NewUserService(
 	db lib.Database,
 	userRepository repository.UserRepository,
 	userRoleRepository repository.UserRoleRepository,
+	userPositionRepository repository.UserPositionRepository,
+	apiKeyRepository repository.ApiKeyRepository,
 	roleRepository repository.RoleRepository,
 	roleMenuRepository repository.RoleMenuRepository,
 	menuRepository repository.MenuRepository,
 	deptRepository repository.DeptRepository,
 	permissionCache PermissionCache,
+	permissionService PermissionService,
+	responseCache lib.ResponseCache,
+	deptRoleService DeptRoleService,
+	authService AuthService,
+	webhookService WebhookService,
+	bus *eventbus.Bus,
 ) UserService {
 	return UserService{
-		logger:             logger,
-		config:             config,
-		db:                 db,
-		userRepository:     userRepository,
-		userRoleRepository: userRoleRepository,
-		roleRepository:     roleRepository,
-		roleMenuRepository: roleMenuRepository,
-		menuRepository:     menuRepository,
-		deptRepository:     deptRepository,
-		permissionCache:    permissionCache,
+		logger:                 logger,
+		config:                 config,
+		db:                     db,
+		userRepository:         userRepository,
+		userRoleRepository:     userRoleRepository,
+		userPositionRepository: userPositionRepository,
+		apiKeyRepository:       apiKeyRepository,
+		roleRepository:         roleRepository,
+		roleMenuRepository:     roleMenuRepository,
+		menuRepository:         menuRepository,
+		deptRepository:         deptRepository,
+		permissionCache:        permissionCache,
+		permissionService:      permissionService,
+		responseCache:          responseCache,
+		deptRoleService:        deptRoleService,
+		authService:            authService,
+		webhookService:         webhookService,
+		bus:                    bus,
 	}
 }
 
 func (a UserService) GetSuperAdmin() *system.User {
-	admin := a.config.SuperAdmin
+	return superAdminUser(a.config)
+}
+
+// superAdminUser 配置文件中的超级管理员，ID 为 0，不在用户表中
+func superAdminUser(config lib.Config) *system.User {
+	admin := config.SuperAdmin
 	return &system.User{
 		Username: admin.Username,
 		Nickname: admin.Realname,
@@ -67,6 +106,11 @@ func (a UserService) IsSuperAdmin(username string) bool {
 	return a.config.SuperAdmin.Username == username
 }
 
+// IsSuperAdminClaims 当前身份是否以超级管理员身份访问，超级管理员的 API Key 仍按授权范围鉴权
+func (a UserService) IsSuperAdminClaims(claims *dto.JwtClaims) bool {
+	return claims != nil && claims.ApiKeyID == 0 && a.IsSuperAdmin(claims.Username)
+}
+
 // GetUserRoleIDs 获取用户角色ID列表
 func (a UserService) GetUserRoleIDs(userID uint64) ([]uint64, error) {
 	return a.userRoleRepository.GetRoleIDsByUserID(userID)
@@ -76,6 +120,9 @@ func (a UserService) GetUserRoleIDs(userID uint64) ([]uint64, error) {
 func (a UserService) WithTrx(trxHandle *gorm.DB) UserService {
 	a.userRepository = a.userRepository.WithTrx(trxHandle)
 	a.userRoleRepository = a.userRoleRepository.WithTrx(trxHandle)
+	a.userPositionRepository = a.userPositionRepository.WithTrx(trxHandle)
+	a.apiKeyRepository = a.apiKeyRepository.WithTrx(trxHandle)
+	a.deptRoleService = a.deptRoleService.WithTrx(trxHandle)
 
 	return a
 }
@@ -237,6 +284,7 @@ func (a UserService) GetCurrentUserInfo(ID uint64, username string) (*dto.Curren
 		Email:           user.Email,
 		CreateTime:      user.CreateTime,
 		CanSwitchTenant: false,
+		TwoFactor:       user.TwoFactorEnabled,
 		Roles:           []string{},
 		Perms:           []string{},
 	}
@@ -364,6 +412,12 @@ func (a UserService) Get(id uint64) (*system.User, error) {
 	}
 	user.RoleIds = roleIDs
 
+	positionIDs, err := a.userPositionRepository.GetPositionIDsByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.PositionIds = positionIDs
+
 	return user, nil
 }
 
@@ -389,6 +443,25 @@ func (a UserService) Create(user *system.User) (uint64, error) {
 		}
 	}
 
+	if err := a.assignPositionsToUser(user.ID, user.PositionIds); err != nil {
+		return 0, err
+	}
+
+	// 按部门默认角色规则授予角色
+	joined := system.Users{{ID: user.ID, Username: user.Username}}
+	if err := a.deptRoleService.OnTransfer(joined, user.DeptID, user.CreateBy); err != nil {
+		return 0, err
+	}
+
+	a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
+	a.webhookService.Dispatch(system.WebhookEventUserCreated, map[string]interface{}{
+		"id":       user.ID,
+		"username": user.Username,
+		"nickname": user.Nickname,
+		"email":    user.Email,
+		"deptId":   user.DeptID,
+		"createBy": user.CreateBy,
+	})
 	return user.ID, nil
 }
 
@@ -416,6 +489,13 @@ func (a UserService) Update(id uint64, user *system.User) error {
 
 	user.ID = oUser.ID
 	user.CreateTime = oUser.CreateTime
+	disabled := user.Status == constants.StatusDisable && oUser.Status != constants.StatusDisable
+
+	// 调整部门时按部门默认角色规则授予、撤销角色
+	var moved system.Users
+	if user.DeptID != oUser.DeptID {
+		moved = system.Users{{ID: oUser.ID, Username: oUser.Username, DeptID: oUser.DeptID}}
+	}
 
 	// Update user role associations if provided
 	if user.RoleIds != nil {
@@ -439,12 +519,26 @@ func (a UserService) Update(id uint64, user *system.User) error {
 			return err
 		}
 
+		if err := svc.replaceUserPositions(id, user.PositionIds); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := svc.deptRoleService.OnTransfer(moved, user.DeptID, user.UpdateBy); err != nil {
+			tx.Rollback()
+			return err
+		}
+
 		if err := tx.Commit().Error; err != nil {
 			return err
 		}
 
 		// 清除用户权限缓存
 		a.permissionCache.InvalidateUserCache(id)
+		if disabled {
+			a.publishDisabled(oUser, user.UpdateBy)
+		}
+		a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
 		return nil
 	}
 
@@ -452,6 +546,18 @@ func (a UserService) Update(id uint64, user *system.User) error {
 		return err
 	}
 
+	if err := a.replaceUserPositions(id, user.PositionIds); err != nil {
+		return err
+	}
+
+	if err := a.deptRoleService.OnTransfer(moved, user.DeptID, user.UpdateBy); err != nil {
+		return err
+	}
+
+	if disabled {
+		a.publishDisabled(oUser, user.UpdateBy)
+	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
 	return nil
 }
 
@@ -471,29 +577,330 @@ func (a UserService) assignRolesToUser(userID uint64, roleIDs []uint64) error {
 	return a.userRoleRepository.BatchCreate(userRoles)
 }
 
-func (a UserService) Delete(id uint64) error {
-	_, err := a.userRepository.Get(id)
+func (a UserService) assignPositionsToUser(userID uint64, positionIDs []uint64) error {
+	userPositions := make([]*system.UserPosition, 0, len(positionIDs))
+	seen := make(map[uint64]bool, len(positionIDs))
+	for _, positionID := range positionIDs {
+		if !seen[positionID] {
+			seen[positionID] = true
+			userPositions = append(userPositions, &system.UserPosition{UserID: userID, PositionID: positionID})
+		}
+	}
+
+	return a.userPositionRepository.BatchCreate(userPositions)
+}
+
+// replaceUserPositions 替换用户的岗位，positionIDs 为 nil 时保持不变
+func (a UserService) replaceUserPositions(userID uint64, positionIDs []uint64) error {
+	if positionIDs == nil {
+		return nil
+	}
+
+	if err := a.userPositionRepository.DeleteByUserIDs([]uint64{userID}); err != nil {
+		return err
+	}
+
+	return a.assignPositionsToUser(userID, positionIDs)
+}
+
+// publishDisabled 发布用户禁用事件，由认证服务强制下线，Webhook 等其他订阅者各自处理
+func (a UserService) publishDisabled(user *system.User, operatorID uint64) {
+	system.EventUserDisabled.Publish(context.Background(), a.bus, system.UserDisabledEvent{
+		ID:         user.ID,
+		Username:   user.Username,
+		OperatorID: operatorID,
+	})
+}
+
+func (a UserService) UpdateStatus(id uint64, status int) error {
+	user, err := a.userRepository.Get(id)
 	if err != nil {
 		return err
 	}
 
-	// 清除用户权限缓存
-	a.permissionCache.InvalidateUserCache(id)
+	if err := a.userRepository.UpdateStatus(id, status); err != nil {
+		return err
+	}
+
+	if status == constants.StatusDisable && user.Status != constants.StatusDisable {
+		a.publishDisabled(user, 0)
+	}
+	a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
+	return nil
+}
+
+// CheckDataScope 检查用户是否都在数据权限范围内，不存在的用户同样视为超出范围
+func (a UserService) CheckDataScope(scope *system.DataScope, ids ...uint64) error {
+	if scope == nil || scope.All {
+		return nil
+	}
 
-	if err := a.userRoleRepository.DeleteByUserID(id); err != nil {
+	scoped, err := a.userRepository.ScopedIDs(ids, scope)
+	if err != nil {
 		return err
 	}
 
-	return a.userRepository.Delete(id)
+	allowed := make(map[uint64]bool, len(scoped))
+	for _, id := range scoped {
+		allowed[id] = true
+	}
+	for _, id := range ids {
+		if !allowed[id] {
+			return errors.UserOutOfDataScope
+		}
+	}
+
+	return nil
 }
 
-func (a UserService) UpdateStatus(id uint64, status int) error {
-	_, err := a.userRepository.Get(id)
+// CheckDeptScope 检查用户所属部门是否在数据权限范围内，修改用户（userID 不为 0）时保持原部门不变总是允许
+func (a UserService) CheckDeptScope(scope *system.DataScope, userID, deptID uint64) error {
+	if scope.HasDept(deptID) {
+		return nil
+	}
+
+	if userID > 0 {
+		user, err := a.userRepository.Get(userID)
+		if err != nil {
+			return err
+		} else if user.DeptID == deptID {
+			return nil
+		}
+	}
+
+	return errors.UserDeptOutOfDataScope
+}
+
+// DeleteByIds 批量删除用户，ids 为英文逗号分割的用户ID，超出数据权限的用户被跳过
+func (a UserService) DeleteByIds(ids string, operatorID uint64, scope *system.DataScope) (*system.UserBulkResult, error) {
+	return a.Bulk(&system.UserBulkForm{
+		UserIDs:   str.S(ids).Uint64s(","),
+		Operation: system.UserBulkDelete,
+		DataScope: scope,
+	}, operatorID)
+}
+
+// Bulk 批量用户操作（启用/禁用、追加/移除/设置角色、调整部门、删除）
+// 在同一事务中执行，不存在或不允许操作的用户会被跳过并在结果中说明原因
+func (a UserService) Bulk(form *system.UserBulkForm, operatorID uint64) (*system.UserBulkResult, error) {
+	ids := make([]uint64, 0, len(form.UserIDs))
+	seen := make(map[uint64]bool, len(form.UserIDs))
+	for _, id := range form.UserIDs {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.UserBulkEmpty
+	}
+
+	switch form.Operation {
+	case system.UserBulkEnable, system.UserBulkDisable:
+	case system.UserBulkDelete:
+	case system.UserBulkAssignRoles, system.UserBulkRemoveRoles, system.UserBulkSetRoles:
+		if len(form.RoleIDs) == 0 {
+			return nil, errors.UserBulkInvalidOperation
+		}
+		for _, roleID := range form.RoleIDs {
+			if _, err := a.roleRepository.Get(roleID); err != nil {
+				if errors.Is(err, errors.DatabaseRecordNotFound) {
+					return nil, errors.RoleRecordNotFound
+				}
+				return nil, err
+			}
+		}
+		if form.Operation != system.UserBulkRemoveRoles {
+			if err := a.checkRoleGrant(form.Operator, form.RoleIDs); err != nil {
+				return nil, err
+			}
+		}
+	case system.UserBulkTransferDept:
+		if form.DeptID == 0 {
+			return nil, errors.UserBulkInvalidOperation
+		}
+		if !form.DataScope.HasDept(form.DeptID) {
+			return nil, errors.UserDeptOutOfDataScope
+		}
+		if _, err := a.deptRepository.Get(form.DeptID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.UserBulkInvalidOperation
+	}
+
+	users, err := a.userRepository.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	userMap := make(map[uint64]*system.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+
+	var scoped map[uint64]bool
+	if form.DataScope != nil && !form.DataScope.All {
+		scopedIDs, err := a.userRepository.ScopedIDs(ids, form.DataScope)
+		if err != nil {
+			return nil, err
+		}
+		scoped = make(map[uint64]bool, len(scopedIDs))
+		for _, id := range scopedIDs {
+			scoped[id] = true
+		}
+	}
+
+	result := &system.UserBulkResult{
+		Operation: form.Operation,
+		Total:     len(ids),
+		Results:   make([]*system.UserBulkItemResult, 0, len(ids)),
+	}
+	validIDs := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		item := &system.UserBulkItemResult{UserID: id}
+		if user, ok := userMap[id]; !ok {
+			item.Message = errors.UserRecordNotFound.Error()
+		} else if scoped != nil && !scoped[id] {
+			item.Message = errors.UserOutOfDataScope.Error()
+		} else if form.Operation == system.UserBulkDisable && id == operatorID {
+			item.Username = user.Username
+			item.Message = errors.UserBulkSelfDisable.Error()
+		} else if form.Operation == system.UserBulkDelete && id == operatorID {
+			item.Username = user.Username
+			item.Message = errors.UserBulkSelfDelete.Error()
+		} else {
+			item.Username = user.Username
+			item.Success = true
+			validIDs = append(validIDs, id)
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	if len(validIDs) > 0 {
+		if err := a.applyBulk(form, validIDs, operatorID); err != nil {
+			return nil, err
+		}
+
+		if form.Operation == system.UserBulkTransferDept {
+			moved := make(system.Users, 0, len(validIDs))
+			for _, id := range validIDs {
+				if user := userMap[id]; user.DeptID != form.DeptID {
+					moved = append(moved, user)
+				}
+			}
+			if err := a.deptRoleService.OnTransfer(moved, form.DeptID, operatorID); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, id := range validIDs {
+			a.permissionCache.InvalidateUserCache(id)
+			switch form.Operation {
+			case system.UserBulkDisable:
+				if userMap[id].Status != constants.StatusDisable {
+					a.publishDisabled(userMap[id], operatorID)
+				}
+			case system.UserBulkDelete:
+				a.authService.ForceLogout(userMap[id].Username, forceLogoutDeleted)
+			}
+		}
+		a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
+	}
+
+	result.Succeeded = len(validIDs)
+	result.Failed = result.Total - result.Succeeded
+	return result, nil
+}
+
+// checkRoleGrant 授予的角色包含的权限必须是当前身份权限的子集，避免通过分配角色提升权限
+// 超级管理员不受限制，与创建 API Key 的规则一致
+func (a UserService) checkRoleGrant(operator *dto.JwtClaims, roleIDs []uint64) error {
+	if operator == nil {
+		return errors.UserRoleGrantDenied
+	}
+	if a.IsSuperAdminClaims(operator) {
+		return nil
+	}
+
+	rolePerms, err := a.permissionService.GetRolePerms(roleIDs)
 	if err != nil {
 		return err
 	}
+	operatorPerms, err := a.permissionService.GetClaimsPerms(operator)
+	if err != nil {
+		return err
+	}
+	for _, perm := range rolePerms {
+		if !MatchPerm(operatorPerms, perm) {
+			return errors.UserRoleGrantDenied
+		}
+	}
+
+	return nil
+}
+
+// applyBulk 对已校验的用户执行批量操作
+func (a UserService) applyBulk(form *system.UserBulkForm, userIDs []uint64, operatorID uint64) error {
+	switch form.Operation {
+	case system.UserBulkEnable:
+		return a.userRepository.BatchUpdateStatus(userIDs, constants.StatusEnable, operatorID)
+	case system.UserBulkDisable:
+		return a.userRepository.BatchUpdateStatus(userIDs, constants.StatusDisable, operatorID)
+	case system.UserBulkTransferDept:
+		return a.userRepository.BatchUpdateDept(userIDs, form.DeptID, operatorID)
+	case system.UserBulkRemoveRoles:
+		return a.userRoleRepository.DeleteByUserIDsAndRoleIDs(userIDs, form.RoleIDs)
+	case system.UserBulkDelete:
+		if err := a.userRoleRepository.DeleteByUserIDs(userIDs); err != nil {
+			return err
+		}
+		if err := a.userPositionRepository.DeleteByUserIDs(userIDs); err != nil {
+			return err
+		}
+		if err := a.apiKeyRepository.DeleteByUserIDs(userIDs); err != nil {
+			return err
+		}
+		return a.userRepository.DeleteByIDs(userIDs, operatorID)
+	case system.UserBulkSetRoles:
+		if err := a.userRoleRepository.DeleteByUserIDs(userIDs); err != nil {
+			return err
+		}
+		userRoles := make([]*system.UserRole, 0, len(userIDs)*len(form.RoleIDs))
+		for _, userID := range userIDs {
+			seen := make(map[uint64]bool, len(form.RoleIDs))
+			for _, roleID := range form.RoleIDs {
+				if !seen[roleID] {
+					seen[roleID] = true
+					userRoles = append(userRoles, &system.UserRole{UserID: userID, RoleID: roleID})
+				}
+			}
+		}
+		return a.userRoleRepository.BatchCreate(userRoles)
+	case system.UserBulkAssignRoles:
+		existing, err := a.userRoleRepository.GetByUserIDs(userIDs)
+		if err != nil {
+			return err
+		}
+		has := make(map[[2]uint64]bool, len(existing))
+		for _, ur := range existing {
+			has[[2]uint64{ur.UserID, ur.RoleID}] = true
+		}
+
+		userRoles := make([]*system.UserRole, 0, len(userIDs)*len(form.RoleIDs))
+		for _, userID := range userIDs {
+			for _, roleID := range form.RoleIDs {
+				key := [2]uint64{userID, roleID}
+				if has[key] {
+					continue
+				}
+				has[key] = true
+				userRoles = append(userRoles, &system.UserRole{UserID: userID, RoleID: roleID})
+			}
+		}
+		return a.userRoleRepository.BatchCreate(userRoles)
+	}
 
-	return a.userRepository.UpdateStatus(id, status)
+	return errors.UserBulkInvalidOperation
 }
 
 // ResetPassword 重置用户密码
@@ -518,16 +925,17 @@ func (a UserService) GetUserForm(id uint64) (*system.UserForm, error) {
 	}
 
 	return &system.UserForm{
-		ID:       user.ID,
-		Username: user.Username,
-		Nickname: user.Nickname,
-		Mobile:   user.Mobile,
-		Gender:   user.Gender,
-		Avatar:   user.Avatar,
-		Email:    user.Email,
-		Status:   user.Status,
-		DeptId:   user.DeptID,
-		RoleIds:  user.RoleIds,
+		ID:          user.ID,
+		Username:    user.Username,
+		Nickname:    user.Nickname,
+		Mobile:      user.Mobile,
+		Gender:      user.Gender,
+		Avatar:      user.Avatar,
+		Email:       user.Email,
+		Status:      user.Status,
+		DeptId:      user.DeptID,
+		RoleIds:     user.RoleIds,
+		PositionIds: user.PositionIds,
 	}, nil
 }
 
@@ -557,5 +965,10 @@ func (a UserService) UpdateProfile(id uint64, username string, profile *system.P
 		return err
 	}
 
-	return a.userRepository.UpdateProfile(id, profile)
+	if err := a.userRepository.UpdateProfile(id, profile); err != nil {
+		return err
+	}
+
+	a.responseCache.Invalidate(lib.ResponseCacheGroupUser, lib.ResponseCacheGroupDirectory)
+	return nil
 }
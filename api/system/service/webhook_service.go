@@ -0,0 +1,477 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/str"
+	"github.com/top-system/light-admin/pkg/webhook"
+)
+
+const (
+	webhookDefaultTimeout       = 10   // 秒
+	webhookDefaultMaxAttempts   = 6    // 含首次
+	webhookDefaultRetryDelay    = 30   // 秒
+	webhookDefaultMaxRetryDelay = 3600 // 秒
+
+	webhookSecretPrefix = "whsec_"
+
+	webhookURLCheckTimeout = 5 * time.Second
+)
+
+// WebhookService Webhook 管理与事件投递
+// 每个订阅了事件的启用 Webhook 创建一条投递记录并提交一个队列任务，失败时任务按指数退避重试；
+// 未启用任务队列时在后台协程中投递并重试，进程退出后不再继续
+// 下载结束、用户禁用通过异步订阅事件总线投递，不阻塞产生事件的业务
+type WebhookService struct {
+	logger                    lib.Logger
+	taskQueue                 lib.TaskQueue
+	webhookRepository         repository.WebhookRepository
+	webhookDeliveryRepository repository.WebhookDeliveryRepository
+	client                    *webhook.Client
+	policy                    queue.WebhookRetryPolicy
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	logger lib.Logger,
+	config lib.Config,
+	taskQueue lib.TaskQueue,
+	bus *eventbus.Bus,
+	webhookRepository repository.WebhookRepository,
+	webhookDeliveryRepository repository.WebhookDeliveryRepository,
+) WebhookService {
+	cfg := lib.WebhookConfig{}
+	if config.Webhook != nil {
+		cfg = *config.Webhook
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = webhookDefaultTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = webhookDefaultMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = webhookDefaultRetryDelay
+	}
+	if cfg.MaxRetryDelay <= 0 {
+		cfg.MaxRetryDelay = webhookDefaultMaxRetryDelay
+	}
+
+	a := WebhookService{
+		logger:                    logger,
+		taskQueue:                 taskQueue,
+		webhookRepository:         webhookRepository,
+		webhookDeliveryRepository: webhookDeliveryRepository,
+		client:                    webhook.NewClient(time.Duration(cfg.Timeout) * time.Second),
+		policy: queue.WebhookRetryPolicy{
+			MaxAttempts:   cfg.MaxAttempts,
+			RetryDelay:    time.Duration(cfg.RetryDelay) * time.Second,
+			MaxRetryDelay: time.Duration(cfg.MaxRetryDelay) * time.Second,
+		},
+	}
+
+	// 从任务存储恢复或由其他实例分发的投递任务使用本实例读取 Webhook 地址与密钥
+	queue.RegisterResumableTaskFactory(queue.WebhookTaskType, queue.NewWebhookTaskFactory(a.deliver))
+
+	system.EventDownloadFinished.SubscribeAsync(bus, "webhook", a.onDownloadFinished)
+	system.EventUserDisabled.SubscribeAsync(bus, "webhook", a.onUserDisabled)
+
+	return a
+}
+
+// WithTrx delegates transaction to repository database
+func (a WebhookService) WithTrx(trxHandle *gorm.DB) WebhookService {
+	a.webhookRepository = a.webhookRepository.WithTrx(trxHandle)
+	a.webhookDeliveryRepository = a.webhookDeliveryRepository.WithTrx(trxHandle)
+	return a
+}
+
+// Dispatch 向订阅了 event 的启用 Webhook 异步投递事件，data 为事件数据
+// 只在创建投递记录时访问数据库，失败只记录日志，不影响产生事件的业务
+func (a WebhookService) Dispatch(event string, data interface{}) {
+	hooks, err := a.webhookRepository.ListEnabled()
+	if err != nil {
+		a.logger.Zap.Errorf("Failed to load webhooks for %s: %v", event, err)
+		return
+	}
+
+	var (
+		eventID string
+		payload string
+	)
+	for _, hook := range hooks {
+		if event == system.WebhookEventPing || !hook.Subscribes(event) {
+			continue
+		}
+		if payload == "" {
+			if eventID, payload, err = newWebhookPayload(event, data); err != nil {
+				a.logger.Zap.Errorf("Failed to encode webhook event %s: %v", event, err)
+				return
+			}
+		}
+		if _, err := a.enqueue(hook, eventID, event, payload); err != nil {
+			a.logger.Zap.Errorf("Failed to dispatch %s to webhook %d: %v", event, hook.ID, err)
+		}
+	}
+}
+
+// onDownloadFinished 投递 download.completed 或 download.failed 事件
+func (a WebhookService) onDownloadFinished(ctx context.Context, event system.DownloadFinishedEvent) error {
+	if event.Task == nil {
+		return nil
+	}
+
+	name := system.WebhookEventDownloadCompleted
+	if event.Type == system.DownloadEventFailed {
+		name = system.WebhookEventDownloadFailed
+	}
+	a.Dispatch(name, system.DownloadTasks{event.Task}.ToPageVOList()[0])
+	return nil
+}
+
+// onUserDisabled 投递 user.disabled 事件
+func (a WebhookService) onUserDisabled(ctx context.Context, event system.UserDisabledEvent) error {
+	a.Dispatch(system.WebhookEventUserDisabled, event)
+	return nil
+}
+
+// Ping 向 Webhook 投递一个 ping 事件，停用的 Webhook 同样可以测试
+func (a WebhookService) Ping(id uint64) (*system.WebhookDelivery, error) {
+	hook, err := a.webhookRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	eventID, payload, err := newWebhookPayload(system.WebhookEventPing, map[string]interface{}{
+		"webhookId": hook.ID,
+		"name":      hook.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.enqueue(hook, eventID, system.WebhookEventPing, payload)
+}
+
+// Redeliver 以原有的事件 ID 与内容重新投递，创建新的投递记录
+func (a WebhookService) Redeliver(deliveryID uint64) (*system.WebhookDelivery, error) {
+	delivery, err := a.webhookDeliveryRepository.Get(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	hook, err := a.webhookRepository.Get(delivery.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.enqueue(hook, delivery.EventID, delivery.Event, delivery.Payload)
+}
+
+// enqueue 创建投递记录并提交投递
+func (a WebhookService) enqueue(hook *system.Webhook, eventID, event, payload string) (*system.WebhookDelivery, error) {
+	delivery := &system.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventID:   eventID,
+		Event:     event,
+		URL:       hook.URL,
+		Payload:   payload,
+		Status:    system.WebhookDeliveryPending,
+	}
+	if err := a.webhookDeliveryRepository.Create(delivery); err != nil {
+		return nil, err
+	}
+
+	if !a.taskQueue.IsEnabled() {
+		go a.deliverInBackground(delivery.ID)
+		return delivery, nil
+	}
+
+	task, err := queue.NewWebhookTask(a.deliver, delivery.ID, a.policy, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.taskQueue.QueueTask(context.Background(), task); err != nil {
+		return nil, errors.Wrap(err, "failed to queue webhook task")
+	}
+
+	delivery.QueueTaskID = task.ID()
+	if err := a.webhookDeliveryRepository.Updates(delivery.ID, map[string]interface{}{"queue_task_id": task.ID()}); err != nil {
+		a.logger.Zap.Warnf("Failed to record queue task of webhook delivery %d: %v", delivery.ID, err)
+	}
+
+	return delivery, nil
+}
+
+// deliverInBackground 未启用任务队列时按同样的退避规则投递
+func (a WebhookService) deliverInBackground(id uint64) {
+	for attempt := 1; ; attempt++ {
+		final := attempt >= a.policy.MaxAttempts
+		err := a.deliver(context.Background(), id, attempt, final)
+		if err == nil || final || errors.Is(err, queue.CriticalErr) {
+			return
+		}
+		time.Sleep(webhook.Backoff(attempt, a.policy.RetryDelay, a.policy.MaxRetryDelay))
+	}
+}
+
+// deliver 进行一次投递并记录请求结果，Webhook 已删除或停用时以不可重试错误结束
+func (a WebhookService) deliver(ctx context.Context, id uint64, attempt int, final bool) error {
+	delivery, err := a.webhookDeliveryRepository.Get(id)
+	if errors.Is(err, errors.WebhookDeliveryNotFound) {
+		return errors.Wrapf(queue.CriticalErr, "webhook delivery %d", id)
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":          attempt,
+		"last_attempt_time": dto.NewNullDateTime(&now),
+	}
+
+	hook, err := a.webhookRepository.Get(delivery.WebhookID)
+	if err == nil && hook.Status != 1 && delivery.Event != system.WebhookEventPing {
+		err = errors.New("webhook is disabled")
+	}
+	if err != nil {
+		updates["status"] = system.WebhookDeliveryFailed
+		updates["error"] = str.S(err.Error()).Truncate(500)
+		if updateErr := a.webhookDeliveryRepository.Updates(id, updates); updateErr != nil {
+			a.logger.Zap.Warnf("Failed to update webhook delivery %d: %v", id, updateErr)
+		}
+		return errors.Wrapf(queue.CriticalErr, "webhook delivery %d: %v", id, err)
+	}
+
+	resp, err := a.client.Deliver(ctx, webhook.Request{
+		URL:        hook.URL,
+		Secret:     hook.Secret,
+		Event:      delivery.Event,
+		DeliveryID: strconv.FormatUint(delivery.ID, 10),
+		Body:       []byte(delivery.Payload),
+	})
+
+	updates["url"] = hook.URL
+	updates["response_status"] = 0
+	updates["response_body"] = ""
+	updates["duration"] = int64(0)
+	if resp != nil {
+		updates["response_status"] = resp.StatusCode
+		updates["response_body"] = resp.Body
+		updates["duration"] = resp.Duration.Milliseconds()
+	}
+	switch {
+	case err == nil:
+		updates["status"] = system.WebhookDeliverySuccess
+		updates["error"] = ""
+	case final:
+		updates["status"] = system.WebhookDeliveryFailed
+		updates["error"] = str.S(err.Error()).Truncate(500)
+	default:
+		updates["status"] = system.WebhookDeliveryRetrying
+		updates["error"] = str.S(err.Error()).Truncate(500)
+	}
+	if updateErr := a.webhookDeliveryRepository.Updates(id, updates); updateErr != nil {
+		a.logger.Zap.Warnf("Failed to update webhook delivery %d: %v", id, updateErr)
+	}
+
+	if err != nil {
+		a.logger.Zap.Warnf("Webhook delivery %d (%s) attempt %d failed: %v", id, delivery.Event, attempt, err)
+	}
+	return err
+}
+
+// Query 查询 Webhook 分页列表
+func (a WebhookService) Query(param *system.WebhookQueryParam) (*system.WebhookQueryResult, error) {
+	qr, err := a.webhookRepository.Query(param)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range qr.List {
+		item.HasSecret = item.Secret != ""
+	}
+	return qr, nil
+}
+
+// Get 获取 Webhook
+func (a WebhookService) Get(id uint64) (*system.Webhook, error) {
+	hook, err := a.webhookRepository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	hook.HasSecret = hook.Secret != ""
+	return hook, nil
+}
+
+// Events 可订阅的事件
+func (a WebhookService) Events() []*system.WebhookEventOption {
+	return system.WebhookEvents
+}
+
+// Create 新增 Webhook，未填写密钥时自动生成，返回的密钥之后不能再查看
+func (a WebhookService) Create(form *system.WebhookForm, createBy uint64) (*system.WebhookCreatedVO, error) {
+	if err := validateWebhookURL(form.URL); err != nil {
+		return nil, err
+	}
+
+	events, err := normalizeWebhookEvents(form.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := form.Secret
+	if secret == "" {
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	hook := &system.Webhook{
+		Name:     strings.TrimSpace(form.Name),
+		URL:      strings.TrimSpace(form.URL),
+		Secret:   secret,
+		Events:   events,
+		Status:   form.Status,
+		Remark:   form.Remark,
+		CreateBy: createBy,
+	}
+	if err := a.webhookRepository.Create(hook); err != nil {
+		return nil, err
+	}
+
+	return &system.WebhookCreatedVO{ID: hook.ID, Secret: secret}, nil
+}
+
+// Update 修改 Webhook，密钥为空时保持不变
+func (a WebhookService) Update(id uint64, form *system.WebhookForm, updateBy uint64) error {
+	hook, err := a.webhookRepository.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := validateWebhookURL(form.URL); err != nil {
+		return err
+	}
+
+	events, err := normalizeWebhookEvents(form.Events)
+	if err != nil {
+		return err
+	}
+
+	secret := hook.Secret
+	if form.Secret != "" {
+		secret = form.Secret
+	}
+
+	return a.webhookRepository.Update(id, &system.Webhook{
+		Name:     strings.TrimSpace(form.Name),
+		URL:      strings.TrimSpace(form.URL),
+		Secret:   secret,
+		Events:   events,
+		Status:   form.Status,
+		Remark:   form.Remark,
+		UpdateBy: updateBy,
+	})
+}
+
+// Delete 删除 Webhook，尚未完成的投递在下一次尝试时结束
+func (a WebhookService) Delete(id uint64) error {
+	if _, err := a.webhookRepository.Get(id); err != nil {
+		return err
+	}
+
+	return a.webhookRepository.Delete(id)
+}
+
+// QueryDeliveries 查询投递记录
+func (a WebhookService) QueryDeliveries(param *system.WebhookDeliveryQueryParam) (*system.WebhookDeliveryQueryResult, error) {
+	return a.webhookDeliveryRepository.Query(param)
+}
+
+// GetDelivery 获取投递记录
+func (a WebhookService) GetDelivery(id uint64) (*system.WebhookDelivery, error) {
+	return a.webhookDeliveryRepository.Get(id)
+}
+
+// validateWebhookURL 投递由服务端发起且会记录响应内容，只允许解析到公网地址的 http(s) 地址，
+// 投递时仍会再次检查实际连接的地址
+func validateWebhookURL(raw string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookURLCheckTimeout)
+	defer cancel()
+
+	if err := webhook.ValidateURL(ctx, strings.TrimSpace(raw)); err != nil {
+		return errors.Wrap(errors.WebhookURLInvalid, err.Error())
+	}
+	return nil
+}
+
+// normalizeWebhookEvents 校验订阅的事件并去重，包含 * 时只保留 *
+func normalizeWebhookEvents(events []string) (string, error) {
+	known := make(map[string]bool, len(system.WebhookEvents))
+	for _, item := range system.WebhookEvents {
+		known[item.Event] = true
+	}
+
+	seen := make(map[string]bool, len(events))
+	list := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if event == system.WebhookEventAll {
+			return system.WebhookEventAll, nil
+		}
+		if !known[event] {
+			return "", errors.Wrapf(errors.WebhookEventInvalid, "%s", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			list = append(list, event)
+		}
+	}
+	if len(list) == 0 {
+		return "", errors.WebhookEventInvalid
+	}
+
+	return strings.Join(list, ","), nil
+}
+
+// newWebhookPayload 生成事件 ID 与请求体
+func newWebhookPayload(event string, data interface{}) (string, string, error) {
+	eventID := uuid.Must(uuid.NewV4()).String()
+	body, err := json.Marshal(webhook.Envelope{
+		ID:        eventID,
+		Event:     event,
+		Timestamp: time.Now().UnixMilli(),
+		Data:      data,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return eventID, string(body), nil
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
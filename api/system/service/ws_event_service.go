@@ -0,0 +1,209 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+// WsEventService 需要回执的 WebSocket 推送：推送时记录事件及推送对象，
+// 客户端通过 /app/events/ack 回执收到、展示，汇总为送达报告
+type WsEventService struct {
+	logger            lib.Logger
+	ws                *ws.WebSocket
+	wsEventRepository repository.WsEventRepository
+}
+
+// NewWsEventService creates a new websocket event service
+func NewWsEventService(
+	logger lib.Logger,
+	websocket *ws.WebSocket,
+	wsEventRepository repository.WsEventRepository,
+) WsEventService {
+	svc := WsEventService{
+		logger:            logger,
+		ws:                websocket,
+		wsEventRepository: wsEventRepository,
+	}
+
+	websocket.RegisterHandler(ws.AppEventsAck, svc.handleAck)
+
+	return svc
+}
+
+// WithTrx delegates transaction to repository database
+func (a WsEventService) WithTrx(trxHandle *gorm.DB) WsEventService {
+	a.wsEventRepository = a.wsEventRepository.WithTrx(trxHandle)
+	return a
+}
+
+// Notify 向用户的 /user/queue/messages 推送需要回执的消息，message 中加入 eventId
+// 推送对象全部记录，不在线的用户不会收到推送，报告中单独统计
+func (a WsEventService) Notify(kind, title string, users system.Users, message map[string]interface{}, createBy uint64) (string, error) {
+	eventID := uuid.NewString()
+
+	event := &system.WsEvent{
+		EventID:     eventID,
+		Kind:        kind,
+		Title:       title,
+		Destination: ws.UserQueueMessages,
+		Audience:    len(users),
+		CreateBy:    createBy,
+	}
+
+	online := make([]string, 0, len(users))
+	deliveries := make(system.WsEventDeliveries, 0, len(users))
+	for _, user := range users {
+		isOnline := a.ws.IsUserOnline(user.Username)
+		if isOnline {
+			online = append(online, user.Username)
+		}
+		deliveries = append(deliveries, &system.WsEventDelivery{
+			EventID:  eventID,
+			UserID:   user.ID,
+			Username: user.Username,
+			Online:   isOnline,
+		})
+	}
+	event.Online = len(online)
+
+	if err := a.wsEventRepository.Create(event, deliveries); err != nil {
+		return "", err
+	}
+
+	message["eventId"] = eventID
+	for _, username := range online {
+		a.ws.SendNotification(username, message)
+	}
+
+	return eventID, nil
+}
+
+// Banner 向当前全部在线用户推送系统横幅
+func (a WsEventService) Banner(form *system.WsBannerForm, createBy uint64) (string, error) {
+	eventID := uuid.NewString()
+
+	users := a.ws.GetOnlineUsers()
+	event := &system.WsEvent{
+		EventID:     eventID,
+		Kind:        system.WsEventBanner,
+		Title:       form.Title,
+		Destination: ws.TopicBanner,
+		Audience:    len(users),
+		Online:      len(users),
+		CreateBy:    createBy,
+	}
+
+	deliveries := make(system.WsEventDeliveries, 0, len(users))
+	for _, user := range users {
+		deliveries = append(deliveries, &system.WsEventDelivery{
+			EventID:  eventID,
+			Username: user.Username,
+			Online:   true,
+		})
+	}
+
+	if err := a.wsEventRepository.Create(event, deliveries); err != nil {
+		return "", err
+	}
+
+	level := form.Level
+	if level == "" {
+		level = "L"
+	}
+	a.ws.Broker.Broadcast(ws.TopicBanner, ws.BannerEvent{
+		EventID:   eventID,
+		Title:     form.Title,
+		Content:   form.Content,
+		Level:     level,
+		Timestamp: time.Now().UnixMilli(),
+	})
+
+	return eventID, nil
+}
+
+// Ack 记录用户的回执，不是推送对象的用户回执返回 WsEventNotTargeted
+func (a WsEventService) Ack(username string, ack *ws.EventAck) error {
+	if ack.EventID == "" || (ack.Status != ws.EventAckReceived && ack.Status != ws.EventAckDisplayed) {
+		return errors.WsEventAckInvalid
+	}
+
+	event, err := a.wsEventRepository.GetByEventID(ack.EventID)
+	if err != nil {
+		return err
+	}
+
+	return a.wsEventRepository.Ack(event, username, ack.Status == ws.EventAckDisplayed, time.Now())
+}
+
+// handleAck 处理客户端发送到 /app/events/ack 的回执
+func (a WsEventService) handleAck(session *stomp.Session, destination string, body []byte) {
+	ack := new(ws.EventAck)
+	if err := json.Unmarshal(body, ack); err != nil {
+		a.logger.Zap.Debugf("Invalid event ack from %s: %v", session.Username, err)
+		return
+	}
+
+	if err := a.Ack(session.Username, ack); err != nil {
+		a.logger.Zap.Debugf("Failed to record event ack from %s: %v", session.Username, err)
+	}
+}
+
+// Query 分页查询推送事件，附带回执统计
+func (a WsEventService) Query(param *system.WsEventQueryParam) (*system.WsEventQueryResult, error) {
+	qr, err := a.wsEventRepository.Query(param)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(qr.List))
+	for _, event := range qr.List {
+		ids = append(ids, event.EventID)
+	}
+
+	received, displayed, err := a.wsEventRepository.CountAcks(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range qr.List {
+		event.Received = received[event.EventID]
+		event.Displayed = displayed[event.EventID]
+	}
+
+	return qr, nil
+}
+
+// Get 推送事件的送达详情
+func (a WsEventService) Get(eventID string) (*system.WsEventDetailVO, error) {
+	event, err := a.wsEventRepository.GetByEventID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := a.wsEventRepository.ListDeliveries(eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		if d.ReceivedTime.Valid {
+			event.Received++
+		}
+		if d.DisplayedTime.Valid {
+			event.Displayed++
+		}
+	}
+
+	return &system.WsEventDetailVO{
+		WsEvent:    event,
+		Deliveries: deliveries,
+	}, nil
+}
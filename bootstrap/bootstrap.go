@@ -8,6 +8,7 @@ import (
 	"github.com/top-system/light-admin/api"
 	"github.com/top-system/light-admin/api/middlewares"
 	"github.com/top-system/light-admin/api/platform/controller"
+	platformservice "github.com/top-system/light-admin/api/platform/service"
 	"github.com/top-system/light-admin/errors"
 	"github.com/top-system/light-admin/lib"
 
@@ -18,18 +19,49 @@ import (
 var Module = fx.Options(
 	lib.Module,
 	api.Module,
+	fx.Provide(NewHandler),
+	fx.Supply(Listener{}),
 	fx.Invoke(bootstrap),
 )
 
+// Listener HTTP 监听选项
+// 嵌入使用时可以关闭监听，改为将 Handler 挂载到调用方自己的 http.Server
+type Listener struct {
+	Disabled bool
+}
+
+// Handler 应用的 HTTP 入口：/ws 直接交给 WebSocket 控制器，不经过 Echo 中间件，其他请求交给 Echo
+type Handler struct {
+	http.Handler
+}
+
+// NewHandler creates the application http handler
+func NewHandler(handler lib.HttpHandler, websocketController controller.WebSocketController) Handler {
+	return Handler{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/ws" {
+				websocketController.HandleWebSocket(w, r)
+				return
+			}
+			handler.Engine.ServeHTTP(w, r)
+		}),
+	}
+}
+
 func bootstrap(
 	lifecycle fx.Lifecycle,
 	handler lib.HttpHandler,
+	appHandler Handler,
+	listener Listener,
 	routes api.Routes,
 	logger lib.Logger,
 	config lib.Config,
 	middlewares middlewares.Middlewares,
 	database lib.Database,
-	websocketController controller.WebSocketController, // 注入 WebSocket 控制器
+	cache lib.Cache,
+	storage platformservice.FileStorage,
+	downloader lib.Downloader,
+	crontab lib.Crontab,
 ) {
 	db, err := database.ORM.DB()
 	if err != nil {
@@ -37,10 +69,10 @@ func bootstrap(
 	}
 
 	// server 提升到外层，供 OnStop 使用
-	var server *http.Server
+	var server, mtlsServer *http.Server
 
 	lifecycle.Append(fx.Hook{
-		OnStart: func(context.Context) error {
+		OnStart: func(ctx context.Context) error {
 			if err := db.Ping(); err != nil {
 				logger.Zap.Fatalf("Error to ping database connection: %v", err)
 			}
@@ -51,30 +83,60 @@ func bootstrap(
 			db.SetConnMaxLifetime(time.Duration(config.Database.MaxLifetime) * time.Second)
 			db.SetConnMaxIdleTime(10 * time.Minute)
 
-			go func() {
-				middlewares.Setup()
-				routes.Setup()
-
-				// 创建自定义 HTTP 处理器，在 Echo 之前拦截 WebSocket 请求
-				wsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					// 拦截 /ws 请求，直接处理 WebSocket，不经过 Echo 中间件
-					if r.URL.Path == "/ws" {
-						websocketController.HandleWebSocket(w, r)
-						return
-					}
-					// 其他请求交给 Echo 处理
-					handler.Engine.ServeHTTP(w, r)
+			// 启动自检：在监听端口前检查依赖，严格模式下存在失败项则终止启动
+			if sc := config.SelfCheck; sc != nil && sc.Enable {
+				report := RunDoctor(ctx, config, DoctorDeps{
+					DB:         database.ORM,
+					Cache:      cache,
+					Storage:    storage,
+					Downloader: downloader,
+					Crontab:    crontab,
 				})
+				for _, result := range report {
+					if result.Status == DoctorFail {
+						logger.Zap.Errorf("Self-check %s failed: %s (hint: %s)", result.Name, result.Detail, result.Hint)
+					} else {
+						logger.Zap.Infof("Self-check %s %s: %s", result.Name, result.Status, result.Detail)
+					}
+				}
+				if report.Failed() && sc.Strict {
+					return errors.New("startup self-check failed")
+				}
+			}
+
+			middlewares.Setup()
+			routes.Setup()
+
+			if listener.Disabled {
+				return nil
+			}
 
+			go func() {
 				server = &http.Server{
 					Addr:           config.Http.ListenAddr(),
-					Handler:        wsHandler,
+					Handler:        appHandler,
 					ReadTimeout:    15 * time.Second,
 					WriteTimeout:   15 * time.Second,
 					IdleTimeout:    60 * time.Second,
 					MaxHeaderBytes: 1 << 20, // 1MB
 				}
 
+				// 服务账号专用的 mTLS 监听，只处理 API 请求
+				if mtls := config.Http.MTLS; mtls != nil && mtls.Enable {
+					srv, err := newMTLSServer(mtls, handler.Engine)
+					if err != nil {
+						logger.Zap.Fatalf("Error to init mTLS server: %v", err)
+					}
+					mtlsServer = srv
+
+					go func() {
+						logger.Zap.Infof("mTLS server started on %s", mtls.ListenAddr())
+						if err := mtlsServer.ListenAndServeTLS(mtls.CertFile, mtls.KeyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+							logger.Zap.Fatalf("Error to start mTLS server: %v", err)
+						}
+					}()
+				}
+
 				logger.Zap.Infof("Server started on %s", config.Http.ListenAddr())
 				if err := server.ListenAndServe(); err != nil {
 					if errors.Is(err, http.ErrServerClosed) {
@@ -99,6 +161,14 @@ func bootstrap(
 				}
 			}
 
+			if mtlsServer != nil {
+				shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+				defer cancel()
+				if err := mtlsServer.Shutdown(shutdownCtx); err != nil {
+					logger.Zap.Errorf("mTLS server forced shutdown: %v", err)
+				}
+			}
+
 			db.Close()
 			return nil
 		},
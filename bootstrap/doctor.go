@@ -0,0 +1,344 @@
+package bootstrap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/pkg/crontab"
+)
+
+// DoctorStatus 自检结果状态
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "PASS"
+	DoctorFail DoctorStatus = "FAIL"
+	DoctorSkip DoctorStatus = "SKIP"
+)
+
+// DoctorResult 单项自检结果，Hint 为失败时的修复建议
+type DoctorResult struct {
+	Name   string       `json:"name"`
+	Status DoctorStatus `json:"status"`
+	Detail string       `json:"detail"`
+	Hint   string       `json:"hint,omitempty"`
+}
+
+// DoctorReport 自检报告
+type DoctorReport []DoctorResult
+
+// Failed 是否存在失败项
+func (r DoctorReport) Failed() bool {
+	for _, result := range r {
+		if result.Status == DoctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print 输出文本格式的自检报告
+func (r DoctorReport) Print(w io.Writer) {
+	passed, failed, skipped := 0, 0, 0
+	for _, result := range r {
+		fmt.Fprintf(w, "[%s] %-12s %s\n", result.Status, result.Name, result.Detail)
+		switch result.Status {
+		case DoctorPass:
+			passed++
+		case DoctorFail:
+			failed++
+			if result.Hint != "" {
+				fmt.Fprintf(w, "       %-12s hint: %s\n", "", result.Hint)
+			}
+		case DoctorSkip:
+			skipped++
+		}
+	}
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+}
+
+// DoctorDeps 自检依赖的组件，初始化失败的组件通过对应的 Err 字段传入
+type DoctorDeps struct {
+	DB         *gorm.DB
+	DBErr      error
+	Cache      lib.Cache
+	CacheErr   error
+	Storage    platformservice.FileStorage
+	Downloader lib.Downloader
+	Crontab    lib.Crontab
+}
+
+// errDoctorSkip 检查项不适用（如功能未启用）
+var errDoctorSkip = errors.New("skipped")
+
+type doctorCheck struct {
+	name string
+	hint string
+	run  func(ctx context.Context) (string, error)
+}
+
+// RunDoctor 依次检查数据库连接与迁移、缓存、对象存储写权限、下载器连通性、casbin 模型和定时任务表达式
+func RunDoctor(ctx context.Context, config lib.Config, deps DoctorDeps) DoctorReport {
+	checks := []doctorCheck{
+		{
+			name: "database",
+			hint: "check Database.Engine/Host/Port/Username/Password and that the database server is reachable",
+			run:  func(ctx context.Context) (string, error) { return checkDatabase(ctx, deps) },
+		},
+		{
+			name: "migrations",
+			hint: "run `light-admin migrate -c <config>` to create missing tables",
+			run:  func(ctx context.Context) (string, error) { return checkMigrations(deps) },
+		},
+		{
+			name: "cache",
+			hint: "check Cache.Type and Redis Host/Port/Password, or use Type: memory",
+			run:  func(ctx context.Context) (string, error) { return checkCache(config, deps) },
+		},
+		{
+			name: "oss",
+			hint: "check OSS settings and write permission of the storage directory or bucket",
+			run:  func(ctx context.Context) (string, error) { return checkStorage(deps) },
+		},
+		{
+			name: "downloader",
+			hint: "check Downloader server address and token/credentials, and that the downloader is running",
+			run:  func(ctx context.Context) (string, error) { return checkDownloader(ctx, config, deps) },
+		},
+		{
+			name: "casbin_model",
+			hint: "check the casbin model file passed by -m; it needs request_definition, policy_definition, policy_effect and matchers sections",
+			run:  func(ctx context.Context) (string, error) { return checkCasbinModel(config) },
+		},
+		{
+			name: "cron",
+			hint: "fix the cron expression; 6 fields with seconds are expected, e.g. \"0 0 3 * * *\"",
+			run:  func(ctx context.Context) (string, error) { return checkCronSpecs(config, deps) },
+		},
+	}
+
+	report := make(DoctorReport, 0, len(checks))
+	for _, check := range checks {
+		detail, err := check.run(ctx)
+
+		result := DoctorResult{Name: check.name, Status: DoctorPass, Detail: detail}
+		switch {
+		case errors.Is(err, errDoctorSkip):
+			result.Status = DoctorSkip
+		case err != nil:
+			result.Status = DoctorFail
+			result.Detail = err.Error()
+			result.Hint = check.hint
+		}
+		report = append(report, result)
+	}
+
+	return report
+}
+
+func checkDatabase(ctx context.Context, deps DoctorDeps) (string, error) {
+	if deps.DBErr != nil {
+		return "", deps.DBErr
+	}
+	if deps.DB == nil {
+		return "", errors.New("database is not initialized")
+	}
+
+	sqlDB, err := deps.DB.DB()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("connected (engine: %s)", lib.CurrentDatabaseEngine), nil
+}
+
+func checkMigrations(deps DoctorDeps) (string, error) {
+	if deps.DB == nil {
+		return "database unavailable", errDoctorSkip
+	}
+
+	models := MigrateModels()
+	var missing []string
+	for _, model := range models {
+		if deps.DB.Migrator().HasTable(model) {
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: deps.DB}
+		if err := stmt.Parse(model); err != nil {
+			return "", err
+		}
+		missing = append(missing, stmt.Schema.Table)
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+	}
+
+	return fmt.Sprintf("%d tables present", len(models)), nil
+}
+
+func checkCache(config lib.Config, deps DoctorDeps) (string, error) {
+	if deps.CacheErr != nil {
+		return "", deps.CacheErr
+	}
+	if deps.Cache == nil {
+		return "", errors.New("cache is not initialized")
+	}
+
+	key := fmt.Sprintf("doctor:probe:%d", time.Now().UnixNano())
+	if err := deps.Cache.Set(key, "ok", time.Minute); err != nil {
+		return "", fmt.Errorf("write: %w", err)
+	}
+	defer deps.Cache.Delete(key)
+
+	var value string
+	if err := deps.Cache.Get(key, &value); err != nil || value != "ok" {
+		return "", fmt.Errorf("read back failed: %v", err)
+	}
+
+	return fmt.Sprintf("read/write ok (type: %s)", config.Cache.Type), nil
+}
+
+func checkStorage(deps DoctorDeps) (string, error) {
+	if deps.Storage == nil {
+		return "storage unavailable", errDoctorSkip
+	}
+
+	content := "light-admin doctor probe"
+	objectKey := fmt.Sprintf("doctor/probe-%d.txt", time.Now().UnixNano())
+
+	url, err := deps.Storage.PutObject(objectKey, strings.NewReader(content), int64(len(content)), "text/plain")
+	if err != nil {
+		return "", fmt.Errorf("write: %w", err)
+	}
+
+	if err := deps.Storage.DeleteFile(url); err != nil {
+		return "", fmt.Errorf("delete probe %s: %w", url, err)
+	}
+
+	return "write/delete ok", nil
+}
+
+func checkDownloader(ctx context.Context, config lib.Config, deps DoctorDeps) (string, error) {
+	if config.Downloader == nil || !config.Downloader.Enable {
+		return "disabled", errDoctorSkip
+	}
+	if !deps.Downloader.IsEnabled() {
+		return "", fmt.Errorf("%s downloader failed to initialize", config.Downloader.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := deps.Downloader.Test(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s reachable (version: %s)", deps.Downloader.Type, version), nil
+}
+
+// casbinModelSections casbin 模型文件必需的段
+var casbinModelSections = []string{"request_definition", "policy_definition", "policy_effect", "matchers"}
+
+func checkCasbinModel(config lib.Config) (string, error) {
+	if config.Casbin == nil || config.Casbin.Model == "" {
+		return "model path not set", errDoctorSkip
+	}
+
+	f, err := os.Open(config.Casbin.Model)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sections := make(map[string]int)
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			sections[current] += 0
+		case current == "":
+			return "", fmt.Errorf("definition outside of section: %q", line)
+		case strings.Contains(line, "=") || strings.HasPrefix(line, "&&") || strings.HasPrefix(line, "||"):
+			sections[current]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	var problems []string
+	for _, name := range casbinModelSections {
+		if count, ok := sections[name]; !ok {
+			problems = append(problems, "missing ["+name+"]")
+		} else if count == 0 {
+			problems = append(problems, "empty ["+name+"]")
+		}
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%s: %s", config.Casbin.Model, strings.Join(problems, ", "))
+	}
+
+	return config.Casbin.Model, nil
+}
+
+func checkCronSpecs(config lib.Config, deps DoctorDeps) (string, error) {
+	specs := crontab.RegisteredSpecs()
+	if deps.Crontab.Cron != nil {
+		for _, task := range deps.Crontab.Cron.GetTasks() {
+			specs[task.Name] = task.Spec
+		}
+	}
+	if oss := config.OSS; oss != nil && oss.Cleanup != nil && oss.Cleanup.Enable && oss.Cleanup.Spec != "" {
+		specs["file_orphan_cleanup"] = oss.Cleanup.Spec
+	}
+	if backup := config.ConfigBackup; backup != nil && backup.Enable && backup.Spec != "" {
+		specs["config_backup"] = backup.Spec
+	}
+
+	if len(specs) == 0 {
+		return "no cron jobs configured", errDoctorSkip
+	}
+
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var invalid []string
+	for _, name := range names {
+		if err := crontab.ValidateSpec(specs[name]); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s %q: %v", name, specs[name], err))
+		}
+	}
+	if len(invalid) > 0 {
+		return "", errors.New(strings.Join(invalid, "; "))
+	}
+
+	return fmt.Sprintf("%d jobs valid", len(specs)), nil
+}
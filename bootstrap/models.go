@@ -0,0 +1,62 @@
+package bootstrap
+
+import (
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// MigrateModels 需要迁移的数据表模型，migrate 命令与自检共用
+func MigrateModels() []interface{} {
+	return []interface{}{
+		&system.User{},
+		&system.UserRole{},
+		&system.Role{},
+		&system.RoleMenu{},
+		&system.RoleDept{},
+		&system.Menu{},
+		&system.Config{},
+		&system.Notice{},
+		&system.UserNotice{},
+		&system.NoticeAttachment{},
+		&system.NoticeRevision{},
+		&system.Dept{},
+		&system.Position{},
+		&system.UserPosition{},
+		&system.Dict{},
+		&system.DictItem{},
+		&system.Log{},
+		&system.ApiUsage{},
+		&system.LoginEvent{},
+		&system.LoginLog{},
+		&system.OperationLog{},
+		&system.SecurityAlert{},
+		&system.ComplianceReport{},
+		&system.ConfigBackup{},
+		&system.Tag{},
+		&system.Tagging{},
+		&system.CasbinRule{},
+		&system.DeptRoleRule{},
+		&system.SavedQuery{},
+		&system.RefreshToken{},
+		&system.ApiKey{},
+		&system.WsEvent{},
+		&system.WsEventDelivery{},
+		&system.RetentionPolicy{},
+		&system.CronTask{},
+		&system.CronTaskRecord{},
+		&system.MailTemplate{},
+		&system.Webhook{},
+		&system.WebhookDelivery{},
+		&platform.FileObject{},
+		&platform.StompMessage{},
+
+		// 扩展功能模型 (可选)
+		&queue.TaskModel{},         // 任务队列
+		&system.DownloadTask{},     // 下载任务
+		&system.DownloadUsage{},    // 下载流量统计
+		&system.DownloadMedia{},    // 下载文件音视频元数据
+		&system.DownloaderConfig{}, // 运行时管理的下载器
+		&system.UserJob{},          // 用户定时任务
+	}
+}
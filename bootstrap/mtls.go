@@ -0,0 +1,40 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/top-system/light-admin/lib"
+)
+
+// newMTLSServer 创建双向 TLS 监听，要求并校验客户端证书
+// 证书主题到服务账号的映射在认证中间件中完成
+func newMTLSServer(config *lib.MTLSConfig, handler http.Handler) (*http.Server, error) {
+	caPEM, err := os.ReadFile(config.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA: %w", err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", config.ClientCAFile)
+	}
+
+	return &http.Server{
+		Addr:    config.ListenAddr(),
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+		},
+		ReadTimeout:    15 * time.Second,
+		WriteTimeout:   15 * time.Second,
+		IdleTimeout:    60 * time.Second,
+		MaxHeaderBytes: 1 << 20, // 1MB
+	}, nil
+}
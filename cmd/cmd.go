@@ -4,7 +4,10 @@ import (
 	"errors"
 	"os"
 
+	"github.com/top-system/light-admin/cmd/config"
+	"github.com/top-system/light-admin/cmd/doctor"
 	"github.com/top-system/light-admin/cmd/migrate"
+	"github.com/top-system/light-admin/cmd/provision"
 	"github.com/top-system/light-admin/cmd/runserver"
 	"github.com/top-system/light-admin/cmd/setup"
 	"github.com/spf13/cobra"
@@ -14,6 +17,9 @@ func init() {
 	rootCmd.AddCommand(runserver.StartCmd)
 	rootCmd.AddCommand(migrate.StartCmd)
 	rootCmd.AddCommand(setup.StartCmd)
+	rootCmd.AddCommand(config.StartCmd)
+	rootCmd.AddCommand(doctor.StartCmd)
+	rootCmd.AddCommand(provision.StartCmd)
 }
 
 var rootCmd = &cobra.Command{
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/top-system/light-admin/lib"
+)
+
+var configFile string
+var profile string
+var redacted bool
+
+func init() {
+	pf := StartCmd.PersistentFlags()
+	pf.StringVarP(&configFile, "config", "c",
+		"config/config.yaml", "this parameter is used to start the service application")
+	pf.StringVarP(&profile, "profile", "p",
+		"", "config profile, loads config.{profile}.yaml on top of the base config")
+
+	dumpCmd.Flags().BoolVar(&redacted, "redacted", false, "hide passwords, secrets and tokens")
+	StartCmd.AddCommand(dumpCmd)
+}
+
+var StartCmd = &cobra.Command{
+	Use:          "config",
+	Short:        "Inspect application configuration",
+	SilenceUsage: true,
+}
+
+var dumpCmd = &cobra.Command{
+	Use:          "dump",
+	Short:        "Print effective configuration after applying profile and environment overrides",
+	Example:      "{execfile} config dump -c config/config.yaml -p production --redacted",
+	SilenceUsage: true,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		lib.SetConfigPath(configFile)
+		lib.SetConfigProfile(profile)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := lib.NewConfig()
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		defer encoder.Close()
+
+		return encoder.Encode(lib.DumpConfig(config, redacted))
+	},
+}
@@ -0,0 +1,68 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/bootstrap"
+	"github.com/top-system/light-admin/lib"
+)
+
+var configFile string
+var profile string
+var casbinModel string
+
+func init() {
+	pf := StartCmd.PersistentFlags()
+	pf.StringVarP(&configFile, "config", "c",
+		"config/config.yaml", "this parameter is used to start the service application")
+	pf.StringVarP(&profile, "profile", "p",
+		"", "config profile, loads config.{profile}.yaml on top of the base config")
+	pf.StringVarP(&casbinModel, "casbin_model", "m",
+		"config/casbin_model.conf", "this parameter is used for the running configuration of casbin")
+}
+
+var StartCmd = &cobra.Command{
+	Use:          "doctor",
+	Short:        "Check configuration and external dependencies",
+	Example:      "{execfile} doctor -c config/config.yaml",
+	SilenceUsage: true,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		lib.SetConfigPath(configFile)
+		lib.SetConfigProfile(profile)
+		// 模型文件缺失由自检报告，不在此处 panic
+		lib.SetConfigCasbinModelPathUnchecked(casbinModel)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := lib.NewConfig()
+		logger := lib.NewLogger(config)
+
+		deps := bootstrap.DoctorDeps{
+			Storage:    platformservice.NewFileStorage(config, logger),
+			Downloader: lib.NewDownloader(config, logger),
+		}
+
+		db, err := lib.OpenDatabase(config, logger)
+		deps.DB, deps.DBErr = db.ORM, err
+
+		// Redis 不可用时 NewCache 会直接退出，先探测连通性
+		if config.Cache.IsRedis() {
+			deps.CacheErr = lib.PingRedis(config)
+		}
+		if deps.CacheErr == nil {
+			deps.Cache = lib.NewCache(config, logger)
+		}
+
+		report := bootstrap.RunDoctor(context.Background(), config, deps)
+		report.Print(os.Stdout)
+
+		if report.Failed() {
+			return errors.New("doctor found failed checks")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,135 @@
+package provision
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+var configFile string
+var profile string
+var deptFile string
+var userFile string
+var notify string
+var output string
+var dryRun bool
+
+func init() {
+	pf := StartCmd.PersistentFlags()
+	pf.StringVarP(&configFile, "config", "c",
+		"config/config.yaml", "this parameter is used to start the service application")
+	pf.StringVarP(&profile, "profile", "p",
+		"", "config profile, loads config.{profile}.yaml on top of the base config")
+	pf.StringVar(&deptFile, "depts", "", "department csv: code,name,parent_code,sort")
+	pf.StringVar(&userFile, "users", "", "user csv: username,nickname,email,mobile,gender,dept_code,role_codes")
+	pf.StringVar(&notify, "notify", system.ProvisionNotifyReturn, "how to deliver initial passwords: return or email")
+	pf.StringVarP(&output, "output", "o", "", "write the per-row report csv to this file instead of stdout")
+	pf.BoolVar(&dryRun, "dry-run", false, "validate only, do not create anything")
+}
+
+var StartCmd = &cobra.Command{
+	Use:          "provision",
+	Short:        "Provision departments and users from CSV",
+	Example:      "{execfile} provision -c config/config.yaml --depts depts.csv --users users.csv -o report.csv",
+	SilenceUsage: true,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		lib.SetConfigPath(configFile)
+		lib.SetConfigProfile(profile)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if deptFile == "" && userFile == "" {
+			return errors.New("at least one of --depts or --users is required")
+		}
+
+		config := lib.NewConfig()
+		logger := lib.NewLogger(config)
+		db := lib.NewDatabase(config, logger)
+		cache := lib.NewCache(config, logger)
+
+		provisionService := service.NewProvisionService(
+			logger,
+			config,
+			db,
+			lib.NewMailer(config),
+			repository.NewDeptRepository(db, logger, lib.NewDBCompat()),
+			repository.NewUserRepository(db, logger),
+			repository.NewUserRoleRepository(db, logger),
+			repository.NewRoleRepository(db, logger),
+			lib.NewResponseCache(config, cache, logger),
+		)
+
+		var (
+			depts []*system.ProvisionDeptRow
+			users []*system.ProvisionUserRow
+		)
+		if deptFile != "" {
+			if err := parseFile(deptFile, func(r io.Reader) (err error) {
+				depts, err = provisionService.ParseDepts(r)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+		if userFile != "" {
+			if err := parseFile(userFile, func(r io.Reader) (err error) {
+				users, err = provisionService.ParseUsers(r)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+
+		result, err := provisionService.Provision(depts, users, system.ProvisionOptions{
+			DryRun: dryRun,
+			Notify: notify,
+		})
+		if err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if output != "" {
+			// 报告中可能包含初始密码，仅所有者可读
+			f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		if err = service.WriteProvisionReport(w, result); err != nil {
+			return err
+		}
+
+		switch {
+		case result.Failed > 0:
+			return fmt.Errorf("%d rows failed validation, nothing was created", result.Failed)
+		case result.DryRun:
+			logger.Zap.Info("Dry run: all rows are valid, nothing was created")
+		default:
+			logger.Zap.Infof("Provisioned %d departments and %d users", result.Depts, result.Users)
+		}
+		return nil
+	},
+}
+
+func parseFile(path string, parse func(r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = parse(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
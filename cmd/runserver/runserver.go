@@ -1,20 +1,21 @@
 package runserver
 
 import (
-	"github.com/top-system/light-admin/bootstrap"
 	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/server"
 	"github.com/spf13/cobra"
-
-	"go.uber.org/fx"
 )
 
 var configFile string
+var profile string
 var casbinModel string
 
 func init() {
 	pf := StartCmd.PersistentFlags()
 	pf.StringVarP(&configFile, "config", "c",
 		"config/config.yaml", "this parameter is used to start the service application")
+	pf.StringVarP(&profile, "profile", "p",
+		"", "config profile, loads config.{profile}.yaml on top of the base config")
 
 	pf.StringVarP(&casbinModel, "casbin_model", "m",
 		"config/casbin_model.conf", "this parameter is used for the running configuration of casbin")
@@ -27,6 +28,7 @@ var StartCmd = &cobra.Command{
 	SilenceUsage: true,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		lib.SetConfigPath(configFile)
+		lib.SetConfigProfile(profile)
 		lib.SetConfigCasbinModelPath(casbinModel)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -35,5 +37,9 @@ var StartCmd = &cobra.Command{
 }
 
 func runApplication() {
-	fx.New(bootstrap.Module, fx.NopLogger).Run()
+	srv, err := server.New(lib.NewConfig())
+	if err != nil {
+		panic(err)
+	}
+	srv.Run()
 }
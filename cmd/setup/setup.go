@@ -16,12 +16,15 @@ import (
 )
 
 var configFile string
+var profile string
 var menuFile string
 
 func init() {
 	pf := StartCmd.PersistentFlags()
 	pf.StringVarP(&configFile, "config", "c",
 		"config/config.yaml", "this parameter is used to start the service application")
+	pf.StringVarP(&profile, "profile", "p",
+		"", "config profile, loads config.{profile}.yaml on top of the base config")
 	pf.StringVarP(&menuFile, "menu", "m",
 		"config/menu.yaml", "this parameter is used to set the initialized menu data.")
 }
@@ -33,6 +36,7 @@ var StartCmd = &cobra.Command{
 	SilenceUsage: true,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		lib.SetConfigPath(configFile)
+		lib.SetConfigProfile(profile)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		config := lib.NewConfig()
@@ -49,10 +53,20 @@ var StartCmd = &cobra.Command{
 		dictItemRepo := repository.NewDictItemRepository(db, logger)
 
 		// 初始化 services
+		cache := lib.NewCache(config, logger)
+		permissionCache := service.NewPermissionCache(
+			logger,
+			cache,
+			userRoleRepo,
+		)
 		menuService := service.NewMenuService(
 			logger,
 			menuRepo,
 			roleMenuRepo,
+			permissionCache,
+			lib.NewResponseCache(config, cache, logger),
+			lib.NewFeatureModules(),
+			service.NewLockService(logger, cache, userRepo),
 		)
 
 		// Step 1: 导入菜单数据
@@ -0,0 +1,23 @@
+package errors
+
+import "net/http"
+
+var (
+	ApiKeyInvalid      = New("invalid api key")
+	ApiKeyExpired      = New("api key has expired")
+	ApiKeyNotFound     = New("api key not found")
+	ApiKeyPermDenied   = New("api key perms exceed the permissions of the current user")
+	ApiKeyNotAllowed   = New("api keys cannot be managed with an api key")
+	ApiKeyPermsInvalid = New("api key perms are required")
+	ApiKeyNoAccount    = New("api keys can only be created for user accounts")
+)
+
+func init() {
+	RegisterHTTPStatus(ApiKeyInvalid, http.StatusUnauthorized)
+	RegisterHTTPStatus(ApiKeyExpired, http.StatusUnauthorized)
+	RegisterHTTPStatus(ApiKeyNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(ApiKeyPermDenied, http.StatusForbidden)
+	RegisterHTTPStatus(ApiKeyNotAllowed, http.StatusForbidden)
+	RegisterHTTPStatus(ApiKeyPermsInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(ApiKeyNoAccount, http.StatusForbidden)
+}
@@ -0,0 +1,13 @@
+package errors
+
+import "net/http"
+
+var (
+	ComplianceReportFormatInvalid = New("unsupported report format, expected xlsx, pdf or csv")
+	ComplianceReportNotReady      = New("report is not ready")
+)
+
+func init() {
+	RegisterHTTPStatus(ComplianceReportFormatInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(ComplianceReportNotReady, http.StatusConflict)
+}
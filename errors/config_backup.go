@@ -0,0 +1,15 @@
+package errors
+
+import "net/http"
+
+var (
+	ConfigBackupInvalid            = New("invalid config backup file")
+	ConfigBackupVersionUnsupported = New("unsupported config backup version")
+	ConfigBackupTooLarge           = New("too many rows to back up")
+)
+
+func init() {
+	RegisterHTTPStatus(ConfigBackupInvalid, http.StatusUnprocessableEntity)
+	RegisterHTTPStatus(ConfigBackupVersionUnsupported, http.StatusUnprocessableEntity)
+	RegisterHTTPStatus(ConfigBackupTooLarge, http.StatusInternalServerError)
+}
@@ -0,0 +1,27 @@
+package errors
+
+import "net/http"
+
+var (
+	CrontabNotEnabled      = New("crontab is not enabled")
+	CronTaskNotFound       = New("cron task not found")
+	CronTaskAlreadyExists  = New("cron task already exists")
+	CronTaskInvalidName    = New("invalid cron task name")
+	CronTaskSpecInvalid    = New("invalid cron spec, expected 6 fields with seconds")
+	CronTaskUnknownHandler = New("unknown cron task handler")
+	CronTaskParamsInvalid  = New("invalid cron task params")
+	CronTaskBuiltin        = New("builtin cron task cannot be deleted")
+	CronTaskRunning        = New("cron task is still running")
+)
+
+func init() {
+	RegisterHTTPStatus(CrontabNotEnabled, http.StatusServiceUnavailable)
+	RegisterHTTPStatus(CronTaskNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(CronTaskAlreadyExists, http.StatusConflict)
+	RegisterHTTPStatus(CronTaskInvalidName, http.StatusBadRequest)
+	RegisterHTTPStatus(CronTaskSpecInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(CronTaskUnknownHandler, http.StatusBadRequest)
+	RegisterHTTPStatus(CronTaskParamsInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(CronTaskBuiltin, http.StatusBadRequest)
+	RegisterHTTPStatus(CronTaskRunning, http.StatusConflict)
+}
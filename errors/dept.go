@@ -0,0 +1,13 @@
+package errors
+
+import "net/http"
+
+var (
+	DeptInvalidParent = New("dept invalid parent")
+	DeptHasUsers      = New("dept or its children still have users, cannot be deleted")
+)
+
+func init() {
+	RegisterHTTPStatus(DeptInvalidParent, http.StatusBadRequest)
+	RegisterHTTPStatus(DeptHasUsers, http.StatusConflict)
+}
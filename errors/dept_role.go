@@ -0,0 +1,13 @@
+package errors
+
+import "net/http"
+
+var (
+	DeptRoleRuleNotFound      = New("dept role rule not found")
+	DeptRoleRuleAlreadyExists = New("dept role rule already exists")
+)
+
+func init() {
+	RegisterHTTPStatus(DeptRoleRuleNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(DeptRoleRuleAlreadyExists, http.StatusConflict)
+}
@@ -6,10 +6,51 @@ var (
 	DownloadQueueNotEnabled    = New("task queue is not enabled")
 	DownloadNoDownloaderConfig = New("no downloader configured")
 	DownloadDownloaderNotFound = New("downloader not found")
+	DownloadDownloaderTimeout  = New("downloader did not respond in time")
+	DownloadQuotaExceeded      = New("download quota exceeded")
+	DownloadInvalidURL         = New("unsupported download url")
+	DownloadFileUnsupported    = New("unsupported download file, expected .torrent or .metalink")
+	DownloadFileTooLarge       = New("download file exceeds the size limit")
+	DownloadTaskNotCompleted   = New("download task is not completed")
+	DownloadTaskNotActive      = New("download task is not downloading")
+	DownloadTaskNotPaused      = New("download task is not paused")
+	DownloadArchiveNotFound    = New("archive not found")
+	DownloadArchiveUnsupported = New("unsupported archive format")
+	DownloadPathInvalid        = New("path is outside the task save path")
+	DownloadMediaNotEnabled    = New("media extraction is not enabled")
+	DownloadSessionUnsupported = New("downloader does not support sessions")
+	DownloadPriorityForbidden  = New("setting the priority requires sys:download:priority")
+	DownloadStartAtInvalid     = New("start time must be within 30 days")
+
+	DownloaderConfigNotFound   = New("downloader config not found")
+	DownloaderConfigNameExists = New("downloader name already exists")
+	DownloaderConfigInUse      = New("downloader has active download tasks")
+	DownloaderConfigInvalid    = New("invalid downloader config")
+	DownloaderConfigNoKey      = New("Downloader.EncryptKey is required to save downloader tokens and passwords")
 )
 
 func init() {
 	RegisterHTTPStatus(DownloadDownloaderNotFound, http.StatusNotFound)
 	RegisterHTTPStatus(DownloadQueueNotEnabled, http.StatusServiceUnavailable)
 	RegisterHTTPStatus(DownloadNoDownloaderConfig, http.StatusServiceUnavailable)
+	RegisterHTTPStatus(DownloadDownloaderTimeout, http.StatusGatewayTimeout)
+	RegisterHTTPStatus(DownloadQuotaExceeded, http.StatusForbidden)
+	RegisterHTTPStatus(DownloadInvalidURL, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloadFileUnsupported, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloadFileTooLarge, http.StatusRequestEntityTooLarge)
+	RegisterHTTPStatus(DownloadTaskNotCompleted, http.StatusConflict)
+	RegisterHTTPStatus(DownloadTaskNotActive, http.StatusConflict)
+	RegisterHTTPStatus(DownloadTaskNotPaused, http.StatusConflict)
+	RegisterHTTPStatus(DownloadArchiveNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(DownloadArchiveUnsupported, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloadPathInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloadMediaNotEnabled, http.StatusServiceUnavailable)
+	RegisterHTTPStatus(DownloadSessionUnsupported, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloadPriorityForbidden, http.StatusForbidden)
+	RegisterHTTPStatus(DownloadStartAtInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloaderConfigNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(DownloaderConfigNameExists, http.StatusConflict)
+	RegisterHTTPStatus(DownloaderConfigInUse, http.StatusConflict)
+	RegisterHTTPStatus(DownloaderConfigInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(DownloaderConfigNoKey, http.StatusBadRequest)
 }
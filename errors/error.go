@@ -42,7 +42,16 @@ var (
 	AuthTokenExpired      = errors.New("auth token is expired")
 	AuthTokenNotValidYet  = errors.New("auth token not active yet")
 	AuthTokenMalformed    = errors.New("auth token is malformed")
+	AuthTokenRevoked      = errors.New("auth token has been revoked")
+	AuthRefreshInvalid    = errors.New("refresh token is invalid")
+	AuthRefreshExpired    = errors.New("refresh token is expired")
+	AuthDeviceNotFound    = errors.New("login device not found")
 	AuthTokenGenerateFail = errors.New("failed to generate auth token")
+	AuthCertNotBound      = errors.New("client certificate is not bound to a service account")
+	AuthAccountLocked     = errors.New("account is temporarily locked after too many failed logins")
+	AuthLoginThrottled    = errors.New("too many failed logins from this address, try again later")
+	AuthTwoFactorRequired = errors.New("two-factor authentication code is required")
+	AuthTwoFactorInvalid  = errors.New("two-factor authentication code is invalid")
 )
 
 // errorHTTPStatus 错误到 HTTP 状态码的映射表
@@ -56,12 +65,23 @@ var errorHTTPStatus = []struct {
 
 	// 404 Not Found
 	{DatabaseRecordNotFound, http.StatusNotFound},
+	{AuthDeviceNotFound, http.StatusNotFound},
 
 	// 401 Unauthorized
 	{AuthTokenInvalid, http.StatusUnauthorized},
 	{AuthTokenExpired, http.StatusUnauthorized},
 	{AuthTokenNotValidYet, http.StatusUnauthorized},
 	{AuthTokenMalformed, http.StatusUnauthorized},
+	{AuthTokenRevoked, http.StatusUnauthorized},
+	{AuthRefreshInvalid, http.StatusUnauthorized},
+	{AuthRefreshExpired, http.StatusUnauthorized},
+	{AuthCertNotBound, http.StatusUnauthorized},
+	{AuthTwoFactorRequired, http.StatusUnauthorized},
+	{AuthTwoFactorInvalid, http.StatusUnauthorized},
+
+	// 429 Too Many Requests
+	{AuthAccountLocked, http.StatusTooManyRequests},
+	{AuthLoginThrottled, http.StatusTooManyRequests},
 }
 
 // RegisterHTTPStatus 注册错误到 HTTP 状态码的映射（供各模块 init 时调用）
@@ -0,0 +1,11 @@
+package errors
+
+import "net/http"
+
+var (
+	ExportFormatInvalid = New("unsupported export format, expected xlsx or csv")
+)
+
+func init() {
+	RegisterHTTPStatus(ExportFormatInvalid, http.StatusBadRequest)
+}
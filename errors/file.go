@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	FileNotFound     = New("file not found")
+	FileInvalidPath  = New("invalid file path")
+	FileNotSupported = New("file storage operation not supported")
+	FileSignInvalid  = New("invalid or expired file download signature")
+)
+
+func init() {
+	RegisterHTTPStatus(FileNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(FileInvalidPath, http.StatusBadRequest)
+	RegisterHTTPStatus(FileNotSupported, http.StatusNotImplemented)
+	RegisterHTTPStatus(FileSignInvalid, http.StatusForbidden)
+}
@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	LockHeld        = New("operation already in progress")
+	LockNotFound    = New("lock is not held")
+	LockNotOwner    = New("lock is held by another owner")
+	LockInvalidName = New("invalid lock name")
+)
+
+func init() {
+	RegisterHTTPStatus(LockHeld, http.StatusConflict)
+	RegisterHTTPStatus(LockNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(LockNotOwner, http.StatusConflict)
+	RegisterHTTPStatus(LockInvalidName, http.StatusBadRequest)
+}
@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	MailTemplateRecordNotFound    = New("mail template record not found")
+	MailTemplateCodeAlreadyExists = New("mail template code already exists")
+	MailTemplateInvalid           = New("mail template is invalid")
+	MailDisabled                  = New("mail is not enabled")
+)
+
+func init() {
+	RegisterHTTPStatus(MailTemplateRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(MailTemplateCodeAlreadyExists, http.StatusConflict)
+	RegisterHTTPStatus(MailTemplateInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(MailDisabled, http.StatusServiceUnavailable)
+}
@@ -7,9 +7,13 @@ var (
 	MenuAlreadyExists           = New("menu already exists")
 	MenuInvalidParent           = New("menu invalid parent")
 	MenuNotAllowDeleteWithChild = New("contains children, cannot be deleted")
+	MenuInvalidDeleteMode       = New("menu invalid delete mode")
+	MenuImportInvalid           = New("menu import file invalid")
 )
 
 func init() {
 	RegisterHTTPStatus(MenuRecordNotFound, http.StatusNotFound)
 	RegisterHTTPStatus(MenuAlreadyExists, http.StatusConflict)
+	RegisterHTTPStatus(MenuInvalidDeleteMode, http.StatusBadRequest)
+	RegisterHTTPStatus(MenuImportInvalid, http.StatusBadRequest)
 }
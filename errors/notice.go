@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	NoticeAttachmentTooLarge       = New("notice attachment exceeds the size limit")
+	NoticeAttachmentTypeNotAllowed = New("notice attachment type is not allowed")
+	NoticeAttachmentTooMany        = New("too many notice attachments")
+	NoticeAttachmentSignInvalid    = New("invalid or expired attachment download link")
+)
+
+func init() {
+	RegisterHTTPStatus(NoticeAttachmentTooLarge, http.StatusRequestEntityTooLarge)
+	RegisterHTTPStatus(NoticeAttachmentTypeNotAllowed, http.StatusUnsupportedMediaType)
+	RegisterHTTPStatus(NoticeAttachmentTooMany, http.StatusUnprocessableEntity)
+	RegisterHTTPStatus(NoticeAttachmentSignInvalid, http.StatusForbidden)
+}
@@ -0,0 +1,15 @@
+package errors
+
+import "net/http"
+
+var (
+	PolicyRecordNotFound = New("policy record not found")
+	PolicyAlreadyExists  = New("policy already exists")
+	PolicyInvalid        = New("invalid policy")
+)
+
+func init() {
+	RegisterHTTPStatus(PolicyRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(PolicyAlreadyExists, http.StatusConflict)
+	RegisterHTTPStatus(PolicyInvalid, http.StatusUnprocessableEntity)
+}
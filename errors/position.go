@@ -0,0 +1,15 @@
+package errors
+
+import "net/http"
+
+var (
+	PositionRecordNotFound    = New("position record not found")
+	PositionCodeAlreadyExists = New("position code already exists")
+	PositionHasUsers          = New("position still has users, cannot be deleted")
+)
+
+func init() {
+	RegisterHTTPStatus(PositionRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(PositionCodeAlreadyExists, http.StatusConflict)
+	RegisterHTTPStatus(PositionHasUsers, http.StatusConflict)
+}
@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	ProfileTypeInvalid = New("unsupported profile type, expected cpu, heap, allocs, goroutine or trace")
+	ProfileInProgress  = New("another cpu profile or trace is in progress")
+	ProfileNotFound    = New("profile not found")
+	ProfileNotReady    = New("profile is not ready")
+)
+
+func init() {
+	RegisterHTTPStatus(ProfileTypeInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(ProfileInProgress, http.StatusConflict)
+	RegisterHTTPStatus(ProfileNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(ProfileNotReady, http.StatusConflict)
+}
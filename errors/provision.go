@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	ProvisionEmpty         = New("provision csv contains no rows")
+	ProvisionInvalidCSV    = New("invalid provision csv")
+	ProvisionInvalidNotify = New("invalid provision notify mode")
+	ProvisionMailDisabled  = New("mail is not enabled, cannot email initial passwords")
+)
+
+func init() {
+	RegisterHTTPStatus(ProvisionEmpty, http.StatusBadRequest)
+	RegisterHTTPStatus(ProvisionInvalidCSV, http.StatusBadRequest)
+	RegisterHTTPStatus(ProvisionInvalidNotify, http.StatusBadRequest)
+	RegisterHTTPStatus(ProvisionMailDisabled, http.StatusServiceUnavailable)
+}
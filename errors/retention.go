@@ -0,0 +1,13 @@
+package errors
+
+import "net/http"
+
+var (
+	RetentionUnknownDataType = New("unknown retention data type")
+	RetentionPurgeFailed     = New("retention purge failed")
+)
+
+func init() {
+	RegisterHTTPStatus(RetentionUnknownDataType, http.StatusNotFound)
+	RegisterHTTPStatus(RetentionPurgeFailed, http.StatusInternalServerError)
+}
@@ -8,6 +8,7 @@ var (
 	RoleAlreadyExists          = New("role already exists")
 	RoleCodeAlreadyExists      = New("role code already exists")
 	RoleNotAllowDeleteWithUser = New("used by users, cannot be deleted")
+	RoleInvalidDataScope       = New("invalid role data scope")
 )
 
 func init() {
@@ -15,4 +16,5 @@ func init() {
 	RegisterHTTPStatus(RoleAlreadyExists, http.StatusConflict)
 	RegisterHTTPStatus(RoleCodeAlreadyExists, http.StatusConflict)
 	RegisterHTTPStatus(RoleIsDisable, http.StatusForbidden)
+	RegisterHTTPStatus(RoleInvalidDataScope, http.StatusBadRequest)
 }
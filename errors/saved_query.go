@@ -0,0 +1,19 @@
+package errors
+
+import "net/http"
+
+var (
+	SavedQueryRecordNotFound   = New("saved query record not found")
+	SavedQueryNameExists       = New("saved query name already exists")
+	SavedQueryResourceInvalid  = New("unsupported saved query resource")
+	SavedQuerySortInvalid      = New("unsupported saved query sort")
+	SavedQueryResourceMismatch = New("saved query belongs to another list")
+)
+
+func init() {
+	RegisterHTTPStatus(SavedQueryRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(SavedQueryNameExists, http.StatusConflict)
+	RegisterHTTPStatus(SavedQueryResourceInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(SavedQuerySortInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(SavedQueryResourceMismatch, http.StatusBadRequest)
+}
@@ -0,0 +1,16 @@
+package errors
+
+import "net/http"
+
+var (
+	SystemReadOnly = New("system is in read-only mode, write operations are temporarily unavailable")
+
+	FeatureModuleNotFound = New("feature module not found")
+	FeatureModuleDisabled = New("feature module is disabled")
+)
+
+func init() {
+	RegisterHTTPStatus(SystemReadOnly, http.StatusServiceUnavailable)
+	RegisterHTTPStatus(FeatureModuleNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(FeatureModuleDisabled, http.StatusNotFound)
+}
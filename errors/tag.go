@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	TagRecordNotFound      = New("tag record not found")
+	TagNameAlreadyExists   = New("tag name already exists")
+	TagResourceTypeInvalid = New("unsupported tag resource type")
+	TagResourceNotFound    = New("tagged resource not found")
+)
+
+func init() {
+	RegisterHTTPStatus(TagRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(TagNameAlreadyExists, http.StatusConflict)
+	RegisterHTTPStatus(TagResourceTypeInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(TagResourceNotFound, http.StatusNotFound)
+}
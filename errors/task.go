@@ -0,0 +1,15 @@
+package errors
+
+import "net/http"
+
+var (
+	TaskQueueNotEnabled = New("task queue is not enabled")
+	TaskNotCancelable   = New("only queued or suspending tasks can be canceled")
+	TaskNotRetryable    = New("task cannot be retried")
+)
+
+func init() {
+	RegisterHTTPStatus(TaskQueueNotEnabled, http.StatusServiceUnavailable)
+	RegisterHTTPStatus(TaskNotCancelable, http.StatusConflict)
+	RegisterHTTPStatus(TaskNotRetryable, http.StatusConflict)
+}
@@ -0,0 +1,21 @@
+package errors
+
+import "net/http"
+
+var (
+	TwoFactorUnavailable    = New("two-factor authentication is not available for this account")
+	TwoFactorAlreadyEnabled = New("two-factor authentication is already enabled")
+	TwoFactorNotEnrolled    = New("two-factor authentication is not enrolled")
+	TwoFactorNotEnabled     = New("two-factor authentication is not enabled")
+	TwoFactorInvalidCode    = New("invalid two-factor authentication code")
+	TwoFactorDisabled       = New("two-factor authentication is not configured")
+)
+
+func init() {
+	RegisterHTTPStatus(TwoFactorUnavailable, http.StatusBadRequest)
+	RegisterHTTPStatus(TwoFactorAlreadyEnabled, http.StatusConflict)
+	RegisterHTTPStatus(TwoFactorNotEnrolled, http.StatusBadRequest)
+	RegisterHTTPStatus(TwoFactorNotEnabled, http.StatusBadRequest)
+	RegisterHTTPStatus(TwoFactorInvalidCode, http.StatusBadRequest)
+	RegisterHTTPStatus(TwoFactorDisabled, http.StatusServiceUnavailable)
+}
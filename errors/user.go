@@ -3,14 +3,21 @@ package errors
 import "net/http"
 
 var (
-	UserRecordNotFound   = New("user record not found")
-	UserInvalidPassword  = New("invalid user password")
-	UserIsDisable        = New("user is disabled")
-	UserPasswordRequired = New("user password is required")
-	UserInvalidUsername   = New("invalid username")
-	UserAlreadyExists    = New("user already exists")
-	UserNoPermission     = New("user no permission")
-	UserCannotUpdate     = New("super admin cannot update profile")
+	UserRecordNotFound       = New("user record not found")
+	UserInvalidPassword      = New("invalid user password")
+	UserIsDisable            = New("user is disabled")
+	UserPasswordRequired     = New("user password is required")
+	UserInvalidUsername      = New("invalid username")
+	UserAlreadyExists        = New("user already exists")
+	UserNoPermission         = New("user no permission")
+	UserCannotUpdate         = New("super admin cannot update profile")
+	UserBulkInvalidOperation = New("invalid bulk user operation")
+	UserBulkEmpty            = New("no users selected")
+	UserBulkSelfDisable      = New("cannot disable the current user")
+	UserBulkSelfDelete       = New("cannot delete the current user")
+	UserOutOfDataScope       = New("user is outside the data scope")
+	UserDeptOutOfDataScope   = New("dept is outside the data scope")
+	UserRoleGrantDenied      = New("roles grant permissions the current user does not have")
 )
 
 func init() {
@@ -20,4 +27,9 @@ func init() {
 	RegisterHTTPStatus(UserNoPermission, http.StatusForbidden)
 	RegisterHTTPStatus(UserIsDisable, http.StatusForbidden)
 	RegisterHTTPStatus(UserCannotUpdate, http.StatusForbidden)
+	RegisterHTTPStatus(UserBulkInvalidOperation, http.StatusBadRequest)
+	RegisterHTTPStatus(UserBulkEmpty, http.StatusBadRequest)
+	RegisterHTTPStatus(UserOutOfDataScope, http.StatusForbidden)
+	RegisterHTTPStatus(UserDeptOutOfDataScope, http.StatusForbidden)
+	RegisterHTTPStatus(UserRoleGrantDenied, http.StatusForbidden)
 }
@@ -0,0 +1,25 @@
+package errors
+
+import "net/http"
+
+var (
+	UserJobDisabled         = New("user scheduled jobs are disabled")
+	UserJobRecordNotFound   = New("user job record not found")
+	UserJobTypeNotAllowed   = New("user job type is not allowed")
+	UserJobSpecInvalid      = New("invalid cron spec, expected 6 fields with seconds")
+	UserJobIntervalTooShort = New("user job runs more often than allowed")
+	UserJobQuotaExceeded    = New("user job quota exceeded")
+	UserJobPayloadInvalid   = New("invalid user job payload")
+	UserJobPermDenied       = New("user job type requires a permission the owner does not have")
+)
+
+func init() {
+	RegisterHTTPStatus(UserJobDisabled, http.StatusServiceUnavailable)
+	RegisterHTTPStatus(UserJobRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(UserJobTypeNotAllowed, http.StatusBadRequest)
+	RegisterHTTPStatus(UserJobSpecInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(UserJobIntervalTooShort, http.StatusBadRequest)
+	RegisterHTTPStatus(UserJobQuotaExceeded, http.StatusForbidden)
+	RegisterHTTPStatus(UserJobPayloadInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(UserJobPermDenied, http.StatusForbidden)
+}
@@ -0,0 +1,17 @@
+package errors
+
+import "net/http"
+
+var (
+	WebhookRecordNotFound   = New("webhook record not found")
+	WebhookDeliveryNotFound = New("webhook delivery record not found")
+	WebhookEventInvalid     = New("webhook event is invalid")
+	WebhookURLInvalid       = New("webhook url must be a public http(s) address")
+)
+
+func init() {
+	RegisterHTTPStatus(WebhookRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(WebhookDeliveryNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(WebhookEventInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(WebhookURLInvalid, http.StatusBadRequest)
+}
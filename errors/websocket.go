@@ -0,0 +1,13 @@
+package errors
+
+import "net/http"
+
+var (
+	WebSocketSessionNotFound = New("websocket session not found")
+	EventStreamForbidden     = New("no permitted destinations for event stream")
+)
+
+func init() {
+	RegisterHTTPStatus(WebSocketSessionNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(EventStreamForbidden, http.StatusForbidden)
+}
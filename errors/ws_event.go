@@ -0,0 +1,15 @@
+package errors
+
+import "net/http"
+
+var (
+	WsEventRecordNotFound = New("websocket event record not found")
+	WsEventAckInvalid     = New("invalid websocket event ack")
+	WsEventNotTargeted    = New("websocket event was not sent to the user")
+)
+
+func init() {
+	RegisterHTTPStatus(WsEventRecordNotFound, http.StatusNotFound)
+	RegisterHTTPStatus(WsEventAckInvalid, http.StatusBadRequest)
+	RegisterHTTPStatus(WsEventNotTargeted, http.StatusForbidden)
+}
@@ -19,6 +19,16 @@ type Cache interface {
 	// Check verifies if keys exist
 	Check(keys ...string) (bool, error)
 
+	// SetNX stores a value only if the key does not exist, reports whether it was stored
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+
+	// CompareAndDelete removes the key only if it holds value, reports whether it was removed
+	CompareAndDelete(key string, value interface{}) (bool, error)
+
+	// Incr atomically increments a counter and returns the new value,
+	// expiration is set when the counter is created and not extended afterwards
+	Incr(key string, expiration time.Duration) (int64, error)
+
 	// Close closes the cache connection
 	Close() error
 
@@ -27,6 +37,7 @@ type Cache interface {
 	HGet(key, field string, value interface{}) error
 	HMSet(key string, values map[string]interface{}) error
 	HDel(key string, fields ...string) error
+	HGetAll(key string) (map[string]string, error)
 }
 
 // NewCache creates a cache instance based on configuration
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -34,6 +35,7 @@ type MemoryCache struct {
 	items     sync.Map
 	hashItems sync.Map
 	hashMu    sync.Mutex // protects concurrent map read/write inside hashItems
+	casMu     sync.Mutex // serializes SetNX, CompareAndDelete and Incr
 	prefix    string
 	logger    Logger
 	stopCh    chan struct{}
@@ -178,6 +180,94 @@ func (m *MemoryCache) Check(keys ...string) (bool, error) {
 	return false, nil
 }
 
+// SetNX stores a value only if the key does not exist or has expired
+func (m *MemoryCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	var exp int64
+	if expiration > 0 {
+		exp = time.Now().Add(expiration).UnixNano()
+	}
+
+	wKey := m.wrapperKey(key)
+
+	m.casMu.Lock()
+	defer m.casMu.Unlock()
+
+	if v, ok := m.items.Load(wKey); ok && !v.(cacheItem).isExpired() {
+		return false, nil
+	}
+
+	m.items.Store(wKey, cacheItem{
+		Value:      data,
+		Expiration: exp,
+	})
+
+	return true, nil
+}
+
+// CompareAndDelete removes the key only if it holds value
+func (m *MemoryCache) CompareAndDelete(key string, value interface{}) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	wKey := m.wrapperKey(key)
+
+	m.casMu.Lock()
+	defer m.casMu.Unlock()
+
+	v, ok := m.items.Load(wKey)
+	if !ok {
+		return false, nil
+	}
+
+	item := v.(cacheItem)
+	if item.isExpired() || !bytes.Equal(item.Value, data) {
+		return false, nil
+	}
+
+	m.items.Delete(wKey)
+	return true, nil
+}
+
+// Incr atomically increments a counter, the expiration is kept from its creation
+func (m *MemoryCache) Incr(key string, expiration time.Duration) (int64, error) {
+	wKey := m.wrapperKey(key)
+
+	m.casMu.Lock()
+	defer m.casMu.Unlock()
+
+	var n int64
+	var exp int64
+	if v, ok := m.items.Load(wKey); ok && !v.(cacheItem).isExpired() {
+		item := v.(cacheItem)
+		if err := json.Unmarshal(item.Value, &n); err != nil {
+			return 0, err
+		}
+		exp = item.Expiration
+	} else if expiration > 0 {
+		exp = time.Now().Add(expiration).UnixNano()
+	}
+
+	n++
+	data, err := json.Marshal(n)
+	if err != nil {
+		return 0, err
+	}
+
+	m.items.Store(wKey, cacheItem{
+		Value:      data,
+		Expiration: exp,
+	})
+
+	return n, nil
+}
+
 // Close stops the cleanup goroutine
 func (m *MemoryCache) Close() error {
 	close(m.stopCh)
@@ -325,3 +415,34 @@ func (m *MemoryCache) HDel(key string, fields ...string) error {
 
 	return nil
 }
+
+// HGetAll gets all fields of a hash, values are returned as strings like HGet with *string
+func (m *MemoryCache) HGetAll(key string) (map[string]string, error) {
+	wKey := m.wrapperKey(key)
+	result := make(map[string]string)
+
+	m.hashMu.Lock()
+	v, ok := m.hashItems.Load(wKey)
+	if !ok {
+		m.hashMu.Unlock()
+		return result, nil
+	}
+
+	item := v.(hashItem)
+	if item.Expiration > 0 && time.Now().UnixNano() > item.Expiration {
+		m.hashItems.Delete(wKey)
+		m.hashMu.Unlock()
+		return result, nil
+	}
+	m.hashMu.Unlock()
+
+	for field, data := range item.Fields {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			str = string(data)
+		}
+		result[field] = str
+	}
+
+	return result, nil
+}
@@ -46,6 +46,21 @@ func NewRedisCache(config Config, logger Logger) *RedisCache {
 	}
 }
 
+// PingRedis 检查 Redis 连通性，失败时返回错误而不退出（供自检命令使用）
+func PingRedis(config Config) error {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Cache.Addr(),
+		DB:       constants.RedisMainDB,
+		Password: config.Cache.Password,
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return client.Ping(ctx).Err()
+}
+
 func (r *RedisCache) wrapperKey(key string) string {
 	if r.prefix == "" {
 		return key
@@ -102,6 +117,53 @@ func (r *RedisCache) Check(keys ...string) (bool, error) {
 	return cmd.Val() > 0, nil
 }
 
+// SetNX stores a value only if the key does not exist
+func (r *RedisCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	data, err := r.cache.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	return r.client.SetNX(context.TODO(), r.wrapperKey(key), data, expiration).Result()
+}
+
+// compareAndDeleteScript 原子地比较并删除
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// CompareAndDelete removes the key only if it holds value
+func (r *RedisCache) CompareAndDelete(key string, value interface{}) (bool, error) {
+	data, err := r.cache.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := compareAndDeleteScript.Run(context.TODO(), r.client, []string{r.wrapperKey(key)}, data).Int()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// incrScript 原子地递增计数并在创建时设置过期时间
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Incr atomically increments a counter, the expiration is set when it is created.
+// The counter is stored as a plain integer and can only be read back through Incr
+func (r *RedisCache) Incr(key string, expiration time.Duration) (int64, error) {
+	return incrScript.Run(context.TODO(), r.client, []string{r.wrapperKey(key)}, expiration.Milliseconds()).Int64()
+}
+
 // Close closes the Redis connection
 func (r *RedisCache) Close() error {
 	return r.client.Close()
@@ -141,6 +203,11 @@ func (r *RedisCache) HDel(key string, fields ...string) error {
 	return r.client.HDel(context.TODO(), r.wrapperKey(key), fields...).Err()
 }
 
+// HGetAll gets all fields of a hash
+func (r *RedisCache) HGetAll(key string) (map[string]string, error) {
+	return r.client.HGetAll(context.TODO(), r.wrapperKey(key)).Result()
+}
+
 // GetClient returns the underlying Redis client (for advanced operations)
 func (r *RedisCache) GetClient() *redis.Client {
 	return r.client
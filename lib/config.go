@@ -2,6 +2,10 @@ package lib
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/top-system/light-admin/pkg/file"
 	"github.com/go-playground/validator/v10"
@@ -9,8 +13,13 @@ import (
 )
 
 var configPath = "./config.yml"
+var configProfile = ""
 var casbinModelPath = "./casbin_model.conf"
 
+// ConfigEnvPrefix 环境变量覆盖配置的前缀，如 LIGHT_ADMIN_DATABASE_HOST 覆盖 Database.Host
+// 未通过 -p 指定 profile 时读取 LIGHT_ADMIN_PROFILE
+const ConfigEnvPrefix = "LIGHT_ADMIN"
+
 var defaultConfig = Config{
 	Name: "app",
 	Http: &HttpConfig{
@@ -36,6 +45,8 @@ var defaultConfig = Config{
 	OSS: &OSSConfig{Type: "local", Local: &LocalOSSConfig{StoragePath: "./uploads"}},
 }
 
+// NewConfig 分层加载配置，优先级从低到高：
+// 内置默认值 < config.yaml < config.{profile}.yaml < LIGHT_ADMIN_* 环境变量
 func NewConfig() Config {
 	config := defaultConfig
 
@@ -43,15 +54,47 @@ func NewConfig() Config {
 	if err := viper.ReadInConfig(); err != nil {
 		panic(fmt.Sprintf("Failed to read configuration file: %s\nError: %v\nPlease ensure the config file exists and is valid YAML format.", configPath, err))
 	}
+	sources := []string{configPath}
+
+	profile := configProfile
+	if profile == "" {
+		profile = os.Getenv(ConfigEnvPrefix + "_PROFILE")
+	}
+
+	// profile 文件只需包含与基础配置不同的部分
+	if profile != "" {
+		profilePath := ProfileConfigPath(configPath, profile)
+		if file.IsFile(profilePath) {
+			viper.SetConfigFile(profilePath)
+			if err := viper.MergeInConfig(); err != nil {
+				panic(fmt.Sprintf("Failed to read profile configuration file: %s\nError: %v\nPlease ensure the config file is valid YAML format.", profilePath, err))
+			}
+			sources = append(sources, profilePath)
+		}
+	}
+
+	// 仅覆盖配置文件中已存在的键
+	viper.SetEnvPrefix(ConfigEnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	sources = append(sources, "env:"+ConfigEnvPrefix+"_*")
 
 	if err := viper.Unmarshal(&config); err != nil {
 		panic(fmt.Sprintf("Failed to parse configuration file: %s\nError: %v\nPlease check your config file syntax.", configPath, err))
 	}
 
+	config.Profile = profile
+	config.Sources = sources
 	config.Casbin.Model = casbinModelPath
 	return config
 }
 
+// ProfileConfigPath 返回 profile 配置文件路径，如 config/config.yaml -> config/config.production.yaml
+func ProfileConfigPath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
 func SetConfigPath(path string) {
 	if !file.IsFile(path) {
 		panic(fmt.Sprintf("Configuration file not found: %s\nPlease create a config file or specify a valid path using the -c flag.\nExample: ./app runserver -c /path/to/config.yaml", path))
@@ -60,6 +103,11 @@ func SetConfigPath(path string) {
 	configPath = path
 }
 
+// SetConfigProfile 指定配置 profile（对应 config.{profile}.yaml）
+func SetConfigProfile(profile string) {
+	configProfile = profile
+}
+
 func SetConfigCasbinModelPath(path string) {
 	if !file.IsFile(path) {
 		panic(fmt.Sprintf("Casbin model file not found: %s\nPlease create the casbin model file or specify a valid path using the -m flag.\nExample: ./app runserver -m /path/to/casbin_model.conf", path))
@@ -68,8 +116,15 @@ func SetConfigCasbinModelPath(path string) {
 	casbinModelPath = path
 }
 
+// SetConfigCasbinModelPathUnchecked 设置 casbin 模型路径但不检查文件是否存在（供自检命令使用）
+func SetConfigCasbinModelPathUnchecked(path string) {
+	casbinModelPath = path
+}
+
 // Configuration are the available config values
 type Config struct {
+	Profile    string            `mapstructure:"-"` // 生效的 profile
+	Sources    []string          `mapstructure:"-"` // 按优先级排列的配置来源
 	Name       string            `mapstructure:"Name"`
 	Http       *HttpConfig       `mapstructure:"Http"`
 	Log        *LogConfig        `mapstructure:"Log"`
@@ -81,6 +136,24 @@ type Config struct {
 	Database   *DatabaseConfig   `mapstructure:"Database"`
 	OSS        *OSSConfig        `mapstructure:"OSS"`
 
+	ResponseCache    *ResponseCacheConfig    `mapstructure:"ResponseCache"`
+	SelfCheck        *SelfCheckConfig        `mapstructure:"SelfCheck"`
+	Analytics        *AnalyticsConfig        `mapstructure:"Analytics"`
+	OperationLog     *OperationLogConfig     `mapstructure:"OperationLog"`
+	Security         *SecurityConfig         `mapstructure:"Security"`
+	Mail             *MailConfig             `mapstructure:"Mail"`
+	Webhook          *WebhookConfig          `mapstructure:"Webhook"`
+	Compliance       *ComplianceConfig       `mapstructure:"Compliance"`
+	Export           *ExportConfig           `mapstructure:"Export"`
+	UserJobs         *UserJobConfig          `mapstructure:"UserJobs"`
+	ConfigBackup     *ConfigBackupConfig     `mapstructure:"ConfigBackup"`
+	NoticeAttachment *NoticeAttachmentConfig `mapstructure:"NoticeAttachment"`
+	LogShipping      *LogShippingConfig      `mapstructure:"LogShipping"`
+	Retention        *RetentionConfig        `mapstructure:"Retention"`
+	WebSocket        *WebSocketConfig        `mapstructure:"WebSocket"`
+	Metrics          *MetricsConfig          `mapstructure:"Metrics"`
+	Telemetry        *TelemetryConfig        `mapstructure:"Telemetry"`
+
 	// ====== 扩展功能配置 (可选) ======
 	Queue      *QueueConfig      `mapstructure:"Queue"`
 	Crontab    *CrontabConfig    `mapstructure:"Crontab"`
@@ -95,6 +168,33 @@ type HttpConfig struct {
 	Host         string   `mapstructure:"Host" validate:"ipv4"`
 	Port         int      `mapstructure:"Port" validate:"gte=1,lte=65535"`
 	AllowOrigins []string `mapstructure:"AllowOrigins"` // CORS 允许的域名列表，为空则允许所有
+	ProblemJSON  bool     `mapstructure:"ProblemJSON"`  // 错误响应使用 RFC 7807 problem+json，请求头 X-API-Version: 1 时仍返回旧结构
+
+	MTLS *MTLSConfig `mapstructure:"MTLS"`
+}
+
+// MTLSConfig 双向 TLS 监听配置
+// 在独立端口上要求客户端证书，证书主题（CN）映射到服务账号，供监控代理等机器调用方免 JWT 访问
+type MTLSConfig struct {
+	Enable       bool                    `mapstructure:"Enable"`
+	Host         string                  `mapstructure:"Host"`
+	Port         int                     `mapstructure:"Port"`
+	CertFile     string                  `mapstructure:"CertFile"`     // 服务端证书
+	KeyFile      string                  `mapstructure:"KeyFile"`      // 服务端私钥
+	ClientCAFile string                  `mapstructure:"ClientCAFile"` // 用于校验客户端证书的 CA
+	Accounts     []*ServiceAccountConfig `mapstructure:"Accounts"`
+}
+
+// ServiceAccountConfig 服务账号，Subject 为客户端证书的 CN，Roles 为绑定的角色编码
+type ServiceAccountConfig struct {
+	Subject string   `mapstructure:"Subject"`
+	Name    string   `mapstructure:"Name"`
+	Roles   []string `mapstructure:"Roles"`
+}
+
+// ListenAddr returns mTLS listen address
+func (a *MTLSConfig) ListenAddr() string {
+	return fmt.Sprintf("%s:%d", a.Host, a.Port)
 }
 
 // LogLevel     : debug,info,warn,error,dpanic,panic,fatal
@@ -120,10 +220,56 @@ type AuthConfig struct {
 	Enable             bool     `mapstructure:"Enable"`
 	TokenExpired       int      `mapstructure:"TokenExpired"`
 	IgnorePathPrefixes []string `mapstructure:"IgnorePathPrefixes"`
+
+	// 刷新令牌有效期（秒），每次刷新后重新计算，默认 7 天
+	RefreshTokenExpired int `mapstructure:"RefreshTokenExpired"`
+
+	// 登录失败锁定，未配置时不限制
+	Lockout *LoginLockoutConfig `mapstructure:"Lockout"`
+
+	// 两步验证（TOTP），用户自行绑定后登录需要验证码
+	TwoFactor *TwoFactorConfig `mapstructure:"TwoFactor"`
+
+	// 签名算法：HS512（默认）、RS256、ES256
+	Algorithm string `mapstructure:"Algorithm"`
+	// HS512 签名密钥，为空时沿用基于应用名称生成的密钥
+	Secret string `mapstructure:"Secret"`
+	// RS256/ES256 密钥列表，第一个配置了私钥的为当前签名密钥，
+	// 其余密钥仅用于验证，密钥轮换时保留旧公钥直至旧令牌过期
+	Keys []*JwtKeyConfig `mapstructure:"Keys"`
+}
+
+// LoginLockoutConfig 登录失败锁定配置，计数保存在缓存中，多实例部署时需使用 Redis 缓存
+// 同一用户名在 Window 秒内失败 MaxFailures 次后锁定 LockDuration 秒；
+// 同一 IP 在 Window 秒内失败 IPMaxFailures 次后，该 IP 的登录请求被拒绝 LockDuration 秒
+type LoginLockoutConfig struct {
+	Enable        bool `mapstructure:"Enable"`
+	MaxFailures   int  `mapstructure:"MaxFailures"`   // 默认 5
+	IPMaxFailures int  `mapstructure:"IPMaxFailures"` // 默认 20，小于 0 表示不按 IP 限制
+	Window        int  `mapstructure:"Window"`        // 默认 900
+	LockDuration  int  `mapstructure:"LockDuration"`  // 默认 900
 }
 
+// TwoFactorConfig 两步验证配置，配置后启用
+// EncryptKey 用于加密保存的 TOTP 密钥，修改后已绑定的用户需要重置两步验证
+type TwoFactorConfig struct {
+	Issuer     string `mapstructure:"Issuer"`     // 验证器应用中显示的名称，默认为应用名称
+	EncryptKey string `mapstructure:"EncryptKey"` // 必填，未配置时拒绝启动
+}
+
+// JwtKeyConfig 非对称签名密钥配置（PEM 文件）
+type JwtKeyConfig struct {
+	KID            string `mapstructure:"KID"`
+	PrivateKeyFile string `mapstructure:"PrivateKeyFile"`
+	PublicKeyFile  string `mapstructure:"PublicKeyFile"`
+}
+
+// CasbinConfig 权限中间件配置
+// Enforce 为 true 时在 perm 标识检查之外按 t_casbin_rule 中的策略拦截请求；
+// AutoLoad 开启后每 AutoLoadInternal 秒从数据库重新加载策略（多实例部署时同步其他实例的修改）
 type CasbinConfig struct {
 	Enable             bool     `mapstructure:"Enable"`
+	Enforce            bool     `mapstructure:"Enforce"`
 	Debug              bool     `mapstructure:"Debug"`
 	Model              string   `mapstructure:"Model"`
 	AutoLoad           bool     `mapstructure:"AutoLoad"`
@@ -144,6 +290,19 @@ type DatabaseConfig struct {
 	MaxLifetime  int `mapstructure:"MaxLifetime"`
 	MaxOpenConns int `mapstructure:"MaxOpenConns"`
 	MaxIdleConns int `mapstructure:"MaxIdleConns"`
+
+	// 只读模式（主库故障切换/数据恢复期间使用），运行时可通过接口切换
+	ReadOnly bool `mapstructure:"ReadOnly"`
+
+	// 按模块（system, platform, queue, log）单独配置表前缀和 Schema
+	Modules map[string]*DatabaseModuleConfig `mapstructure:"Modules"`
+}
+
+// DatabaseModuleConfig 模块级数据库命名配置
+// TablePrefix 为空时使用模块默认前缀；Schema 对应 PostgreSQL 的 schema 或 MySQL 的库名，SQLite 忽略
+type DatabaseModuleConfig struct {
+	TablePrefix string `mapstructure:"TablePrefix"`
+	Schema      string `mapstructure:"Schema"`
 }
 
 // IsSQLite returns true if the database engine is SQLite
@@ -188,6 +347,229 @@ func (c *CacheConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
 
+// ResponseCacheConfig 路由级响应缓存配置
+// TTL 为默认缓存秒数，GroupTTLs 可按分组（dict, menu, user）单独配置
+type ResponseCacheConfig struct {
+	Enable    bool           `mapstructure:"Enable"`
+	TTL       int            `mapstructure:"TTL"`
+	GroupTTLs map[string]int `mapstructure:"GroupTTLs"`
+}
+
+// AnalyticsConfig 接口调用统计配置
+// 请求按小时、用户、路由聚合在内存中，每 FlushInterval 秒批量写入汇总表
+type AnalyticsConfig struct {
+	Enable        bool `mapstructure:"Enable"`
+	FlushInterval int  `mapstructure:"FlushInterval"` // 秒，默认 30
+	MaxPending    int  `mapstructure:"MaxPending"`    // 内存中待写入的聚合行上限，超过时提前写入，默认 5000
+}
+
+// MetricsConfig Prometheus 指标配置
+// 启用后在 GET /metrics 输出指标，需要 sys:maintenance:metrics 权限，采集端可使用 API Key 或 mTLS 客户端证书
+type MetricsConfig struct {
+	Enable    bool   `mapstructure:"Enable"`
+	Namespace string `mapstructure:"Namespace"` // 指标名称前缀，默认 light_admin
+}
+
+// TelemetryConfig OpenTelemetry 链路追踪配置
+// 请求、队列任务及下载器调用的 span 通过 OTLP/HTTP 导出到 Endpoint（如 Jaeger、OpenTelemetry Collector）
+type TelemetryConfig struct {
+	Enable      bool              `mapstructure:"Enable"`
+	Endpoint    string            `mapstructure:"Endpoint"`    // host:port，默认 localhost:4318
+	URLPath     string            `mapstructure:"URLPath"`     // 默认 /v1/traces
+	Insecure    bool              `mapstructure:"Insecure"`    // 使用 HTTP 而不是 HTTPS
+	Headers     map[string]string `mapstructure:"Headers"`     // 导出请求附加的请求头，如认证信息
+	ServiceName string            `mapstructure:"ServiceName"` // 默认使用 Name
+	SampleRatio float64           `mapstructure:"SampleRatio"` // 采样比例 (0, 1]，默认 1；上游已采样的请求始终采样
+}
+
+// OperationLogConfig 操作审计日志配置
+// 记录所有修改类请求，由日志中间件与操作日志一起记录，启用任务队列时通过队列任务写库，否则由日志 worker 写库
+type OperationLogConfig struct {
+	Enable             bool     `mapstructure:"Enable"`
+	MaxBodySize        int      `mapstructure:"MaxBodySize"` // 请求体摘要的最大字符数，默认 1000
+	IgnorePathPrefixes []string `mapstructure:"IgnorePathPrefixes"`
+}
+
+// SecurityConfig 登录异常检测配置
+// 登录成功后根据历史登录记录匹配异常规则，命中时生成安全告警并推送给 Recipients
+type SecurityConfig struct {
+	Enable     bool                 `mapstructure:"Enable"`
+	GeoIPFile  string               `mapstructure:"GeoIPFile"`  // 离线 IP 段 CSV：start_ip,end_ip,country,region,city,latitude,longitude
+	Recipients []string             `mapstructure:"Recipients"` // 接收告警的用户名
+	NotifyUser bool                 `mapstructure:"NotifyUser"` // 是否同时通知登录用户本人
+	Channels   []string             `mapstructure:"Channels"`   // notice、websocket、email，默认全部
+	Rules      *SecurityRulesConfig `mapstructure:"Rules"`
+}
+
+// SecurityRulesConfig 登录异常规则
+type SecurityRulesConfig struct {
+	NewCountry       *NewCountryRuleConfig       `mapstructure:"NewCountry"`
+	ImpossibleTravel *ImpossibleTravelRuleConfig `mapstructure:"ImpossibleTravel"`
+	UnusualHour      *UnusualHourRuleConfig      `mapstructure:"UnusualHour"`
+	DistinctIPs      *DistinctIPsRuleConfig      `mapstructure:"DistinctIPs"`
+}
+
+// NewCountryRuleConfig 从近期未出现过的国家登录
+type NewCountryRuleConfig struct {
+	Enable       bool `mapstructure:"Enable"`
+	LookbackDays int  `mapstructure:"LookbackDays"` // 默认 90
+}
+
+// ImpossibleTravelRuleConfig 两次登录间的移动速度超过阈值
+type ImpossibleTravelRuleConfig struct {
+	Enable        bool    `mapstructure:"Enable"`
+	MaxSpeedKmh   float64 `mapstructure:"MaxSpeedKmh"`   // 默认 900
+	MinDistanceKm float64 `mapstructure:"MinDistanceKm"` // 小于该距离不判定，避免 IP 定位误差，默认 300
+}
+
+// UnusualHourRuleConfig 在 [StartHour, EndHour) 时段登录且该用户近期没有同一小时的登录记录
+type UnusualHourRuleConfig struct {
+	Enable    bool `mapstructure:"Enable"`
+	StartHour int  `mapstructure:"StartHour"` // 默认 0
+	EndHour   int  `mapstructure:"EndHour"`   // 默认 6
+}
+
+// DistinctIPsRuleConfig 时间窗口内登录 IP 数达到阈值
+type DistinctIPsRuleConfig struct {
+	Enable    bool `mapstructure:"Enable"`
+	Window    int  `mapstructure:"Window"`    // 分钟，默认 60
+	Threshold int  `mapstructure:"Threshold"` // 默认 5
+}
+
+// ComplianceConfig 安全合规报表配置
+type ComplianceConfig struct {
+	AdminRoles []string `mapstructure:"AdminRoles"` // 视为管理员的角色编码，默认 ROOT
+	StaleDays  int      `mapstructure:"StaleDays"`  // 超过该天数未登录的启用账号视为闲置，默认 90
+	AuditDays  int      `mapstructure:"AuditDays"`  // 审计摘要统计的天数，默认 30
+}
+
+// ExportConfig 列表数据导出配置
+type ExportConfig struct {
+	MaxRows int64 `mapstructure:"MaxRows"` // 单次导出的最大行数，超出时导出失败，默认 100000
+}
+
+// WebhookConfig Webhook 投递配置，投递失败后按 RetryDelay 指数退避重试，间隔不超过 MaxRetryDelay
+type WebhookConfig struct {
+	Timeout       int `mapstructure:"Timeout"`       // 单次请求超时（秒），默认 10
+	MaxAttempts   int `mapstructure:"MaxAttempts"`   // 最多尝试次数（含首次），默认 6
+	RetryDelay    int `mapstructure:"RetryDelay"`    // 首次重试间隔（秒），之后翻倍，默认 30
+	MaxRetryDelay int `mapstructure:"MaxRetryDelay"` // 最大重试间隔（秒），默认 3600
+}
+
+// ConfigBackupConfig 菜单、角色、字典、系统配置定时备份（依赖 Crontab）
+type ConfigBackupConfig struct {
+	Enable bool   `mapstructure:"Enable"` // 是否启用定时备份，手动备份不受影响
+	Spec   string `mapstructure:"Spec"`   // cron 表达式（秒级），默认每天凌晨 2 点
+	Keep   int    `mapstructure:"Keep"`   // 保留最近的备份份数，默认 30
+}
+
+// RetentionConfig 数据保留策略定时清理（依赖 Crontab），各数据类型的保留天数通过 /api/v1/retention-policies 配置
+type RetentionConfig struct {
+	Enable     bool   `mapstructure:"Enable"`     // 是否启用定时清理，预览与手动执行不受影响
+	Spec       string `mapstructure:"Spec"`       // cron 表达式（秒级），默认每天凌晨 3 点 30 分
+	BatchSize  int    `mapstructure:"BatchSize"`  // 每批删除的行数，默认 1000
+	BatchDelay int    `mapstructure:"BatchDelay"` // 相邻两批之间的间隔（毫秒），降低对数据库的压力，默认 100
+	// Days 各数据类型的默认保留天数（如 queue_task: 7），创建策略时使用，已创建的策略以接口修改的为准
+	Days map[string]int `mapstructure:"Days"`
+}
+
+// WebSocketConfig WebSocket（STOMP）配置
+type WebSocketConfig struct {
+	// MaxRedeliveries client / client-individual 订阅的消息被 NACK 或会话断开后重新投递的次数上限，
+	// 默认 3，小于 0 时不重新投递
+	MaxRedeliveries int `mapstructure:"MaxRedeliveries"`
+	// OfflineMessages 发送给离线用户的用户队列消息，未配置时直接丢弃
+	OfflineMessages *OfflineMessageConfig `mapstructure:"OfflineMessages"`
+	// Relay 多实例部署时通过 Redis pub/sub 转发消息，Redis 地址与密码复用 Cache 配置
+	Relay *WebSocketRelayConfig `mapstructure:"Relay"`
+}
+
+// WebSocketRelayConfig 实例间的 STOMP 消息转发配置
+type WebSocketRelayConfig struct {
+	Enable     bool   `mapstructure:"Enable"`
+	Channel    string `mapstructure:"Channel"`    // Redis 频道，同一集群的实例必须相同，默认 light-admin:stomp
+	InstanceID string `mapstructure:"InstanceID"` // 实例标识，同一集群内唯一，默认 主机名-进程号
+}
+
+// OfflineMessageConfig 离线消息配置，保存在数据库中，用户下次订阅对应队列时投递
+type OfflineMessageConfig struct {
+	Enable       bool `mapstructure:"Enable"`
+	TTL          int  `mapstructure:"TTL"`          // 保留时长（小时），默认 72
+	MaxQueueSize int  `mapstructure:"MaxQueueSize"` // 每个用户最多保留的消息数，超过时丢弃最早的消息，默认 100
+}
+
+// NoticeAttachmentConfig 通知公告附件配置
+type NoticeAttachmentConfig struct {
+	MaxSize      int64    `mapstructure:"MaxSize"`      // 单个附件大小上限（MB），默认 20
+	MaxCount     int      `mapstructure:"MaxCount"`     // 每条通知公告的附件数上限，默认 10
+	AllowedTypes []string `mapstructure:"AllowedTypes"` // 允许的文件扩展名（不含点），为空时使用内置的常用文档和图片类型
+	URLExpire    int      `mapstructure:"URLExpire"`    // 签名下载地址有效期（分钟），默认 30
+	Secret       string   `mapstructure:"Secret"`       // 下载地址签名密钥，为空时启动时随机生成（重启后已签发的地址失效，多实例部署需配置）
+	SweepSpec    string   `mapstructure:"SweepSpec"`    // 清理已移除及长期未关联附件的定时任务（依赖 Crontab），默认每小时
+	UnlinkedTTL  int      `mapstructure:"UnlinkedTTL"`  // 上传后未关联到通知公告的附件保留时长（小时），默认 24
+}
+
+// UserJobConfig 用户自助定时任务配置（依赖 Crontab）
+type UserJobConfig struct {
+	Enable      bool     `mapstructure:"Enable"`
+	MaxPerUser  int      `mapstructure:"MaxPerUser"`  // 每个用户最多可创建的任务数，默认 5
+	MinInterval int      `mapstructure:"MinInterval"` // 相邻两次执行的最小间隔（分钟），默认 60
+	Types       []string `mapstructure:"Types"`       // 开放给用户的任务类型，为空时开放全部
+}
+
+// LogShippingConfig 操作日志、登录事件、安全告警转发到外部 SIEM
+type LogShippingConfig struct {
+	Enable         bool     `mapstructure:"Enable"`
+	Types          []string `mapstructure:"Types"`          // operation、login、security_alert，为空时转发全部
+	IncludePayload bool     `mapstructure:"IncludePayload"` // 操作日志是否包含请求参数和响应内容，默认不包含
+	BatchSize      int      `mapstructure:"BatchSize"`      // 单批最大事件数，默认 100
+	FlushInterval  int      `mapstructure:"FlushInterval"`  // 未满一批时的最长等待时间（毫秒），默认 2000
+	BufferSize     int      `mapstructure:"BufferSize"`     // 每个接收端的缓冲队列长度，默认 10000
+	Backpressure   string   `mapstructure:"Backpressure"`   // 队列满时 drop 丢弃（默认）或 block 阻塞写日志的调用方
+	BlockTimeout   int      `mapstructure:"BlockTimeout"`   // block 模式最长阻塞时间（毫秒），默认 1000
+	MaxRetry       int      `mapstructure:"MaxRetry"`       // 单批最大重试次数，默认 5
+	RetryDelay     int      `mapstructure:"RetryDelay"`     // 首次重试间隔（毫秒），之后翻倍，默认 1000
+	SendTimeout    int      `mapstructure:"SendTimeout"`    // 单次发送超时（秒），默认 10
+
+	Syslog *LogShippingSyslogConfig `mapstructure:"Syslog"`
+	HTTP   *LogShippingHTTPConfig   `mapstructure:"HTTP"`
+	Kafka  *LogShippingKafkaConfig  `mapstructure:"Kafka"`
+}
+
+// LogShippingSyslogConfig RFC 5424 syslog 接收端
+type LogShippingSyslogConfig struct {
+	Enable   bool   `mapstructure:"Enable"`
+	Network  string `mapstructure:"Network"`  // udp（默认）或 tcp
+	Address  string `mapstructure:"Address"`  // host:port
+	AppName  string `mapstructure:"AppName"`  // 默认 light-admin
+	Facility int    `mapstructure:"Facility"` // 默认 16（local0）
+}
+
+// LogShippingHTTPConfig HTTP 批量接口接收端
+type LogShippingHTTPConfig struct {
+	Enable  bool              `mapstructure:"Enable"`
+	URL     string            `mapstructure:"URL"`
+	Format  string            `mapstructure:"Format"`  // ndjson（默认）或 json
+	Headers map[string]string `mapstructure:"Headers"` // 如 Authorization
+}
+
+// LogShippingKafkaConfig Kafka 接收端
+type LogShippingKafkaConfig struct {
+	Enable  bool     `mapstructure:"Enable"`
+	Brokers []string `mapstructure:"Brokers"`
+	Topic   string   `mapstructure:"Topic"`
+}
+
+// MailConfig SMTP 邮件配置
+type MailConfig struct {
+	Enable   bool   `mapstructure:"Enable"`
+	Host     string `mapstructure:"Host"`
+	Port     int    `mapstructure:"Port"` // 465 使用隐式 TLS，其他端口在服务端支持时使用 STARTTLS
+	Username string `mapstructure:"Username"`
+	Password string `mapstructure:"Password"`
+	From     string `mapstructure:"From"`
+}
+
 func (a *DatabaseConfig) DSN() string {
 	if a.IsPostgreSQL() {
 		return a.PostgresDSN()
@@ -215,6 +597,20 @@ type OSSConfig struct {
 	Local  *LocalOSSConfig `mapstructure:"Local"`
 	Minio  *MinioOSSConfig `mapstructure:"Minio"`
 	Aliyun *AliyunOSSConfig `mapstructure:"Aliyun"`
+
+	Cleanup *OSSCleanupConfig `mapstructure:"Cleanup"`
+
+	// SignKey 上传后返回的签名下载地址的密钥，未配置时只有 sys:file:query 权限可以下载文件
+	SignKey   string `mapstructure:"SignKey"`
+	URLExpire int    `mapstructure:"URLExpire"` // 签名下载地址有效期（分钟），默认 60
+}
+
+// OSSCleanupConfig 孤立文件清理配置（依赖 Crontab）
+type OSSCleanupConfig struct {
+	Enable bool   `mapstructure:"Enable"` // 是否启用定时扫描
+	Spec   string `mapstructure:"Spec"`   // cron 表达式（秒级），默认每天凌晨 3 点
+	Delete bool   `mapstructure:"Delete"` // 是否自动删除，为 false 时只输出报告
+	MinAge int    `mapstructure:"MinAge"` // 存储对象最小存在时长（小时），避免误删刚上传的文件，默认 24
 }
 
 // LocalOSSConfig 本地存储配置
@@ -243,25 +639,287 @@ type AliyunOSSConfig struct {
 // 扩展功能配置 (可选)
 // ============================================================================
 
+// SelfCheckConfig 启动自检配置
+// 启用后在监听端口前执行与 doctor 命令相同的检查，Strict 为 true 时存在失败项则终止启动
+type SelfCheckConfig struct {
+	Enable bool `mapstructure:"Enable"`
+	Strict bool `mapstructure:"Strict"`
+}
+
 // QueueConfig 任务队列配置
 type QueueConfig struct {
 	Enable    bool   `mapstructure:"Enable"`    // 是否启用
 	Name      string `mapstructure:"Name"`      // 队列名称
 	WorkerNum int    `mapstructure:"WorkerNum"` // 工作线程数
 	MaxRetry  int    `mapstructure:"MaxRetry"`  // 最大重试次数
+
+	// 任务持久化存储: database（默认，使用主数据库）或 redis（复用 Cache 的 Redis 连接配置）
+	Storage   string `mapstructure:"Storage"`
+	RedisDB   int    `mapstructure:"RedisDB"`   // Redis 库序号
+	KeyPrefix string `mapstructure:"KeyPrefix"` // Redis 键前缀，默认 queue
+	// 已结束任务在 Redis 中的保留天数，默认 14，负数表示不过期
+	FinishedTTL int `mapstructure:"FinishedTTL"`
+
+	// 调度策略: fifo（默认）、fair（按任务所有者加权轮询，同一用户内保持提交顺序）
+	// 或 priority（优先级高的任务先执行，同优先级保持提交顺序）
+	Scheduler    string         `mapstructure:"Scheduler"`
+	OwnerWeights map[uint64]int `mapstructure:"OwnerWeights"` // 用户ID -> 每轮可连续获取的任务数，未配置为 1
+
+	// 分布式传输: 为空时任务只在本进程内调度；redis（Redis Streams，复用 Cache 的 Redis 连接配置）
+	// 或 nats（NATS JetStream），多个实例以同一消费组消费任务，需共享同一任务存储
+	Transport string `mapstructure:"Transport"`
+	Stream    string `mapstructure:"Stream"`   // Redis Stream 键名 / JetStream 流名称，默认 light-admin-queue
+	Group     string `mapstructure:"Group"`    // 消费组名称，所有实例相同，默认 workers
+	Consumer  string `mapstructure:"Consumer"` // 当前实例的消费者名称，每个实例唯一，默认 主机名-进程号
+	NATSURL   string `mapstructure:"NATSURL"`  // Transport 为 nats 时的服务地址，默认 nats://127.0.0.1:4222
+
+	// 启动时从存储恢复的待执行任务类型
+	ResumeTaskTypes []string `mapstructure:"ResumeTaskTypes"`
+	// 命名队列，各自拥有独立的工作线程，与默认队列共用任务存储；只在本进程内调度，不使用 Transport
+	Queues []NamedQueueConfig `mapstructure:"Queues"`
+}
+
+// NamedQueueConfig 命名队列配置，WorkerNum、MaxRetry、Scheduler 未配置时使用默认队列的值
+type NamedQueueConfig struct {
+	Name            string   `mapstructure:"Name"`
+	WorkerNum       int      `mapstructure:"WorkerNum"`
+	MaxRetry        int      `mapstructure:"MaxRetry"`
+	Scheduler       string   `mapstructure:"Scheduler"`
+	ResumeTaskTypes []string `mapstructure:"ResumeTaskTypes"` // 启动时恢复的任务类型，同时决定后台重试、取消该类型任务时使用的队列
+}
+
+// IsRedis 任务是否存储在 Redis 中
+func (c *QueueConfig) IsRedis() bool {
+	return c.Storage == "redis"
+}
+
+// FinishedTaskTTL 已结束任务在 Redis 中的保留时长，0 表示不过期
+func (c *QueueConfig) FinishedTaskTTL() time.Duration {
+	switch {
+	case c.FinishedTTL < 0:
+		return 0
+	case c.FinishedTTL == 0:
+		return 14 * 24 * time.Hour
+	}
+	return time.Duration(c.FinishedTTL) * 24 * time.Hour
+}
+
+// IsDistributed 任务是否通过 Redis Streams 或 NATS JetStream 分发到多个实例
+func (c *QueueConfig) IsDistributed() bool {
+	return c.Transport == "redis" || c.Transport == "nats"
 }
 
 // CrontabConfig 定时任务配置
 type CrontabConfig struct {
-	Enable bool `mapstructure:"Enable"` // 是否启用
+	Enable         bool `mapstructure:"Enable"`         // 是否启用
+	ReloadInterval int  `mapstructure:"ReloadInterval"` // 重新加载 t_cron_job 的间隔（秒），默认 60，小于 0 表示只在启动时加载
+}
+
+// GetReloadInterval 重新加载 t_cron_job 的间隔，0 表示不定时重新加载
+func (c *CrontabConfig) GetReloadInterval() time.Duration {
+	switch {
+	case c.ReloadInterval < 0:
+		return 0
+	case c.ReloadInterval == 0:
+		return 60 * time.Second
+	}
+	return time.Duration(c.ReloadInterval) * time.Second
 }
 
 // DownloaderConfig 下载器配置
 type DownloaderConfig struct {
 	Enable      bool               `mapstructure:"Enable"` // 是否启用
-	Type        string             `mapstructure:"Type"`   // 类型: aria2, qbittorrent
+	Type        string             `mapstructure:"Type"`   // 类型: aria2, qbittorrent, sabnzbd, native
 	Aria2       *Aria2Config       `mapstructure:"Aria2"`
 	QBittorrent *QBittorrentConfig `mapstructure:"QBittorrent"`
+	SABnzbd     *SABnzbdConfig     `mapstructure:"SABnzbd"`
+	Native      *NativeConfig      `mapstructure:"Native"`
+
+	Timeouts *DownloaderTimeoutConfig `mapstructure:"Timeouts"` // 接口请求中调用下载器的超时时间
+	Quota    *DownloadQuotaConfig     `mapstructure:"Quota"`    // 按实际流量限制用户创建下载任务
+	Media    *DownloadMediaConfig     `mapstructure:"Media"`    // 下载完成后提取音视频元数据、生成缩略图
+	Transfer *DownloadTransferConfig  `mapstructure:"Transfer"` // 下载完成后将文件转移到目标目录或文件服务
+	Notify   *DownloadNotifyConfig    `mapstructure:"Notify"`   // 任务完成或出错时通知所有者
+	Seeding  *DownloadSeedingConfig   `mapstructure:"Seeding"`  // BT 任务的做种规则
+
+	HealthCheck *DownloadHealthConfig `mapstructure:"HealthCheck"` // 定时检查下载器是否可用（依赖 Crontab）
+
+	// EncryptKey 用于加密运行时管理的下载器保存在数据库中的密钥与密码，未配置时不能保存带密钥或密码的下载器
+	EncryptKey string `mapstructure:"EncryptKey"`
+}
+
+// DownloadMediaConfig 下载完成后通过 ffprobe/ffmpeg 提取音视频文件的元数据并生成缩略图
+// 只处理下载器保存目录在本机可访问的任务，远程下载器的文件会被跳过
+type DownloadMediaConfig struct {
+	Enable         bool   `mapstructure:"Enable"`         // 是否启用
+	FFprobe        string `mapstructure:"FFprobe"`        // ffprobe 路径，默认从 PATH 查找
+	FFmpeg         string `mapstructure:"FFmpeg"`         // ffmpeg 路径，默认从 PATH 查找
+	Thumbnail      bool   `mapstructure:"Thumbnail"`      // 是否生成缩略图
+	ThumbnailWidth int    `mapstructure:"ThumbnailWidth"` // 缩略图宽度（像素），默认 320
+	Timeout        int    `mapstructure:"Timeout"`        // 单个文件的处理超时（秒），默认 60
+	MaxFiles       int    `mapstructure:"MaxFiles"`       // 单个任务最多处理的文件数，默认 100
+}
+
+// GetFFprobe ffprobe 路径
+func (c *DownloadMediaConfig) GetFFprobe() string {
+	if c.FFprobe == "" {
+		return "ffprobe"
+	}
+	return c.FFprobe
+}
+
+// GetFFmpeg ffmpeg 路径
+func (c *DownloadMediaConfig) GetFFmpeg() string {
+	if c.FFmpeg == "" {
+		return "ffmpeg"
+	}
+	return c.FFmpeg
+}
+
+// GetThumbnailWidth 缩略图宽度
+func (c *DownloadMediaConfig) GetThumbnailWidth() int {
+	if c.ThumbnailWidth <= 0 {
+		return 320
+	}
+	return c.ThumbnailWidth
+}
+
+// GetTimeout 单个文件的处理超时
+func (c *DownloadMediaConfig) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// GetMaxFiles 单个任务最多处理的文件数
+func (c *DownloadMediaConfig) GetMaxFiles() int {
+	if c.MaxFiles <= 0 {
+		return 100
+	}
+	return c.MaxFiles
+}
+
+// MediaEnabled 是否启用音视频元数据提取
+func (c *DownloaderConfig) MediaEnabled() bool {
+	return c != nil && c.Media != nil && c.Media.Enable
+}
+
+// DownloadTransferConfig 下载完成后将文件从下载器的临时目录转移出来，并清理临时目录
+// 只处理下载器保存目录在本机可访问的任务，做种中的任务在做种结束（完成）后才转移
+type DownloadTransferConfig struct {
+	Enable bool   `mapstructure:"Enable"` // 是否启用
+	Target string `mapstructure:"Target"` // 转移目标：dir（默认，移动到 Dir 下以任务 ID 命名的目录）或 oss（上传到文件服务）
+	Dir    string `mapstructure:"Dir"`    // Target 为 dir 时的目标目录
+}
+
+// GetTarget 转移目标
+func (c *DownloadTransferConfig) GetTarget() string {
+	if c.Target == "" {
+		return DownloadTransferTargetDir
+	}
+	return c.Target
+}
+
+// 下载文件的转移目标
+const (
+	DownloadTransferTargetDir = "dir"
+	DownloadTransferTargetOSS = "oss"
+)
+
+// TransferEnabled 是否启用下载完成后的文件转移
+func (c *DownloaderConfig) TransferEnabled() bool {
+	return c != nil && c.Transfer != nil && c.Transfer.Enable
+}
+
+// DownloadHealthConfig 定时调用下载器的 Test 检查可用性，状态变化时推送到 /topic/downloaders/health
+type DownloadHealthConfig struct {
+	Disable bool   `mapstructure:"Disable"` // 关闭定时检查，查询接口仍会在没有结果时检查一次
+	Spec    string `mapstructure:"Spec"`    // cron 表达式（秒级），默认每 30 秒
+}
+
+// DownloadSeedingConfig BT 任务的做种规则，达到任一目标后暂停做种并完成任务，0 表示不限制
+// 未配置时任务一直做种，直到手动取消
+type DownloadSeedingConfig struct {
+	MaxRatio    float64 `mapstructure:"MaxRatio"`    // 分享率：上传量 / 下载大小
+	MaxSeedTime int     `mapstructure:"MaxSeedTime"` // 做种时长（分钟），从首次同步到做种状态开始计算
+}
+
+// DownloadNotifyConfig 任务完成或出错时的通知，WebSocket 消息总是推送到所有者的 /user/queue/downloads
+type DownloadNotifyConfig struct {
+	Notice bool `mapstructure:"Notice"` // 同时创建一条发给所有者的通知公告，离线用户登录后也能看到
+	Email  bool `mapstructure:"Email"`  // 任务出错时按 download_failed 模板给有邮箱的所有者发送邮件（需启用 Mail）
+}
+
+// NoticeEnabled 任务完成或出错时是否创建通知公告
+func (c *DownloaderConfig) NoticeEnabled() bool {
+	return c != nil && c.Notify != nil && c.Notify.Notice
+}
+
+// EmailEnabled 任务出错时是否发送邮件
+func (c *DownloaderConfig) EmailEnabled() bool {
+	return c != nil && c.Notify != nil && c.Notify.Email
+}
+
+// DownloadQuotaConfig 下载流量配额（MB），按同步任务状态时统计的实际流量计算，0 表示不限制
+// 超出当天或当月配额的用户不能再创建下载任务，已有任务不受影响
+type DownloadQuotaConfig struct {
+	Daily         int64            `mapstructure:"Daily"`         // 每天
+	Monthly       int64            `mapstructure:"Monthly"`       // 每自然月
+	CountUpload   bool             `mapstructure:"CountUpload"`   // 上传（做种）流量是否计入配额
+	UserOverrides map[uint64]int64 `mapstructure:"UserOverrides"` // 用户ID -> 每天配额，覆盖 Daily，0 表示不限制
+}
+
+// 下载器操作，用于选择超时时间
+const (
+	DownloaderOpInfo     = "info"
+	DownloaderOpCancel   = "cancel"
+	DownloaderOpControl  = "control"
+	DownloaderOpSetFiles = "setFiles"
+	DownloaderOpTest     = "test"
+	DownloaderOpProbe    = "probe"
+	DownloaderOpSession  = "session"
+)
+
+// defaultDownloaderTimeout 未配置时的下载器调用超时，小于 HTTP 写超时以便返回错误响应
+const defaultDownloaderTimeout = 10 * time.Second
+
+// DownloaderTimeoutConfig 下载器各操作的超时时间（秒），未配置或为 0 时使用 10 秒
+// 超时或客户端断开连接时取消对下载器的调用
+type DownloaderTimeoutConfig struct {
+	Info     int `mapstructure:"Info"`     // 查询任务状态、文件列表
+	Cancel   int `mapstructure:"Cancel"`   // 取消、删除、暂停、恢复任务，设置限速
+	SetFiles int `mapstructure:"SetFiles"` // 选择要下载的文件
+	Test     int `mapstructure:"Test"`     // 测试连接
+	Probe    int `mapstructure:"Probe"`    // 预检时对下载地址发起的 HEAD 请求
+	Session  int `mapstructure:"Session"`  // 保存会话、列出下载器中的全部任务
+}
+
+// OperationTimeout 返回下载器操作的超时时间
+func (c *DownloaderConfig) OperationTimeout(op string) time.Duration {
+	if c == nil || c.Timeouts == nil {
+		return defaultDownloaderTimeout
+	}
+
+	var seconds int
+	switch op {
+	case DownloaderOpInfo:
+		seconds = c.Timeouts.Info
+	case DownloaderOpCancel, DownloaderOpControl:
+		seconds = c.Timeouts.Cancel
+	case DownloaderOpSetFiles:
+		seconds = c.Timeouts.SetFiles
+	case DownloaderOpTest:
+		seconds = c.Timeouts.Test
+	case DownloaderOpProbe:
+		seconds = c.Timeouts.Probe
+	case DownloaderOpSession:
+		seconds = c.Timeouts.Session
+	}
+	if seconds <= 0 {
+		return defaultDownloaderTimeout
+	}
+	return time.Duration(seconds) * time.Second
 }
 
 // Aria2Config aria2 配置
@@ -270,6 +928,13 @@ type Aria2Config struct {
 	Token    string                 `mapstructure:"Token"`    // RPC 密钥
 	TempPath string                 `mapstructure:"TempPath"` // 临时下载路径
 	Options  map[string]interface{} `mapstructure:"Options"`  // 额外选项
+
+	// SaveSessionSpec 定时保存 aria2 会话的 cron 表达式，为空时不保存
+	// 需要 aria2 以 --save-session 启动，会话文件保存在 aria2 所在的机器上
+	SaveSessionSpec string `mapstructure:"SaveSessionSpec"`
+
+	// DisableNotify 不通过 aria2 的 WebSocket 接收任务完成、出错通知，只依赖定时查询任务状态
+	DisableNotify bool `mapstructure:"DisableNotify"`
 }
 
 // QBittorrentConfig qBittorrent 配置
@@ -280,3 +945,20 @@ type QBittorrentConfig struct {
 	TempPath string                 `mapstructure:"TempPath"` // 临时下载路径
 	Options  map[string]interface{} `mapstructure:"Options"`  // 额外选项
 }
+
+// SABnzbdConfig SABnzbd 配置，用于下载 Usenet 的 NZB 任务
+type SABnzbdConfig struct {
+	Server   string                 `mapstructure:"Server"`   // Web UI 地址
+	APIKey   string                 `mapstructure:"APIKey"`   // API 密钥，在 Config > General 中查看
+	Category string                 `mapstructure:"Category"` // 新任务的默认分类，保存目录由 SABnzbd 按分类决定
+	Options  map[string]interface{} `mapstructure:"Options"`  // 额外选项，如 priority、pp
+}
+
+// NativeConfig 内置 HTTP 下载器配置，在本进程中下载 http/https 链接，无需部署外部下载器
+type NativeConfig struct {
+	Enable         bool                   `mapstructure:"Enable"`         // 是否启用
+	TempPath       string                 `mapstructure:"TempPath"`       // 下载路径，每个任务保存在单独的子目录中
+	Segments       int                    `mapstructure:"Segments"`       // 每个任务的并发分段数，默认 4
+	MinSegmentSize int64                  `mapstructure:"MinSegmentSize"` // 分段的最小字节数，默认 4MB，较小的文件不分段
+	Options        map[string]interface{} `mapstructure:"Options"`        // 额外选项，如 user-agent、segments
+}
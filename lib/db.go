@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
@@ -9,7 +10,6 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/schema"
 )
 
 // DatabaseEngine represents the database engine type
@@ -30,6 +30,16 @@ type Database struct {
 
 // NewDatabase creates a new database instance
 func NewDatabase(config Config, logger Logger) Database {
+	database, err := OpenDatabase(config, logger)
+	if err != nil {
+		logger.Zap.Fatalf("Error to open database connection: %v", err)
+	}
+
+	return database
+}
+
+// OpenDatabase 打开数据库连接，失败时返回错误而不退出（供自检命令使用）
+func OpenDatabase(config Config, logger Logger) (Database, error) {
 	var db *gorm.DB
 	var err error
 
@@ -39,10 +49,7 @@ func NewDatabase(config Config, logger Logger) Database {
 		},
 		SkipDefaultTransaction:                   true,
 		DisableForeignKeyConstraintWhenMigrating: true,
-		NamingStrategy: schema.NamingStrategy{
-			SingularTable: true,
-			TablePrefix:   config.Database.TablePrefix + "_",
-		},
+		NamingStrategy:                           NewModuleNamingStrategy(config.Database),
 	}
 
 	switch {
@@ -58,13 +65,13 @@ func NewDatabase(config Config, logger Logger) Database {
 	}
 
 	if err != nil {
-		logger.Zap.Fatalf("Error to open database connection: %v", err)
+		return Database{}, err
 	}
 
 	// Apply connection pool settings
 	sqlDB, err := db.DB()
 	if err != nil {
-		logger.Zap.Fatalf("Error to get underlying sql.DB: %v", err)
+		return Database{}, fmt.Errorf("get underlying sql.DB: %w", err)
 	}
 
 	sqlDB.SetMaxIdleConns(config.Database.MaxIdleConns)
@@ -79,7 +86,17 @@ func NewDatabase(config Config, logger Logger) Database {
 	logger.Zap.Infof("Database connection established (engine: %s)", CurrentDatabaseEngine)
 	return Database{
 		ORM: db,
+	}, nil
+}
+
+// TableName 返回模型在当前命名策略下的表名，用于拼接原生 SQL
+func (a Database) TableName(model interface{}) string {
+	stmt := &gorm.Statement{DB: a.ORM}
+	if err := stmt.Parse(model); err != nil {
+		return ""
 	}
+
+	return stmt.Schema.Table
 }
 
 // openMySQL opens a MySQL database connection
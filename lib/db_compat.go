@@ -63,3 +63,23 @@ func (DBCompat) TreePathLike(column string) string {
 	}
 	return "CONCAT(',', " + column + ", ',')"
 }
+
+// Excluded returns the reference to the value proposed for insertion in an upsert
+// MySQL: VALUES(column)
+// PostgreSQL: excluded.column
+// SQLite: excluded.column
+func (DBCompat) Excluded(column string) string {
+	if IsSQLite() || IsPostgreSQL() {
+		return "excluded." + column
+	}
+	return "VALUES(" + column + ")"
+}
+
+// Increment returns "table.column + excluded value" for upserts that accumulate counters
+// The column is qualified with the table name, PostgreSQL rejects the bare column as ambiguous
+func (c DBCompat) Increment(column string) clause.Expr {
+	return clause.Expr{
+		SQL:  "? + " + c.Excluded(column),
+		Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: column}},
+	}
+}
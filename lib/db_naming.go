@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// 各模块的默认表前缀，未在 Database.Modules 中配置时使用（保持已有表名不变）
+// system、platform 模块默认使用全局 TablePrefix
+var defaultModuleTablePrefixes = map[string]string{
+	"queue": "sys",
+	"log":   "sys",
+}
+
+// ModuleNamingStrategy 按模块区分表前缀和 Schema 的命名策略
+// 模型通过 schema.TablerWithNamer 调用 ModuleTableName 生成表名，
+// 其余表名（如多对多关联表）沿用内嵌的 NamingStrategy
+type ModuleNamingStrategy struct {
+	schema.NamingStrategy
+
+	prefix        string
+	modules       map[string]*DatabaseModuleConfig
+	supportSchema bool
+}
+
+// NewModuleNamingStrategy creates a naming strategy from database config
+func NewModuleNamingStrategy(config *DatabaseConfig) ModuleNamingStrategy {
+	prefix := config.TablePrefix
+	if prefix == "" {
+		prefix = "t"
+	}
+
+	return ModuleNamingStrategy{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+			TablePrefix:   prefix + "_",
+		},
+		prefix:        prefix,
+		modules:       config.Modules,
+		supportSchema: !config.IsSQLite(),
+	}
+}
+
+// ModuleTableName 生成模块表名，格式为 [schema.]prefix_table
+func (ns ModuleNamingStrategy) ModuleTableName(module, table string) string {
+	prefix, ok := defaultModuleTablePrefixes[module]
+	if !ok {
+		prefix = ns.prefix
+	}
+
+	var dbSchema string
+	if cfg, ok := ns.modules[module]; ok && cfg != nil {
+		if cfg.TablePrefix != "" {
+			prefix = cfg.TablePrefix
+		}
+		dbSchema = cfg.Schema
+	}
+
+	name := table
+	if prefix != "" {
+		name = strings.TrimSuffix(prefix, "_") + "_" + table
+	}
+
+	// SQLite 不支持 Schema
+	if dbSchema != "" && ns.supportSchema {
+		return dbSchema + "." + name
+	}
+
+	return name
+}
@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"context"
+
+	"github.com/top-system/light-admin/pkg/eventbus"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// NewEventBus 创建进程内事件总线
+// 启用任务队列时异步订阅者以 event 类型的队列任务执行，失败按队列的重试策略重试，重启后从任务存储恢复；
+// 未启用时在后台协程中执行
+func NewEventBus(logger Logger, taskQueue TaskQueue) *eventbus.Bus {
+	bus := eventbus.New(logger.DesugarZap)
+	if !taskQueue.IsEnabled() {
+		return bus
+	}
+
+	queue.RegisterResumableTaskFactory(queue.EventTaskType, queue.NewEventTaskFactory(bus.Deliver))
+	bus.SetDispatcher(func(ctx context.Context, d eventbus.Delivery) error {
+		task, err := queue.NewEventTask(bus.Deliver, d, nil)
+		if err != nil {
+			return err
+		}
+		return taskQueue.QueueTask(context.WithoutCancel(ctx), task)
+	})
+
+	return bus
+}
@@ -2,13 +2,20 @@ package lib
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
+	"github.com/go-redis/redis/v8"
+	"github.com/nats-io/nats.go"
 	"go.uber.org/fx"
 
 	"github.com/top-system/light-admin/pkg/crontab"
 	"github.com/top-system/light-admin/pkg/downloader"
 	"github.com/top-system/light-admin/pkg/downloader/aria2"
+	"github.com/top-system/light-admin/pkg/downloader/native"
 	"github.com/top-system/light-admin/pkg/downloader/qbittorrent"
+	"github.com/top-system/light-admin/pkg/downloader/sabnzbd"
 	"github.com/top-system/light-admin/pkg/queue"
 )
 
@@ -33,6 +40,7 @@ import (
 // 在服务中使用：
 //   - 将 lib.TaskQueue / lib.Crontab / lib.Downloader 注入到你的服务中
 //   - 例如：func NewMyService(queue lib.TaskQueue, cron lib.Crontab) *MyService
+//   - 使用命名队列时注入 lib.QueueManager，通过 GetOrDefault("emails") 获取
 // ============================================================================
 
 // ExtrasModule 扩展功能模块
@@ -42,6 +50,7 @@ var ExtrasModule = fx.Options(
 	// 用于异步任务处理，如发送邮件、处理文件等
 	// 如不需要，注释下面这行
 	fx.Provide(NewTaskQueue),
+	fx.Provide(NewQueueManager),
 
 	// ====== 定时任务 ======
 	// 用于定时执行任务，如数据清理、报表生成等
@@ -60,8 +69,9 @@ var ExtrasModule = fx.Options(
 
 // TaskQueue 任务队列封装
 type TaskQueue struct {
-	Queue    queue.Queue
-	Registry queue.TaskRegistry
+	Queue      queue.Queue
+	Registry   queue.TaskRegistry
+	Repository queue.TaskRepository // 任务持久化仓库，按 Queue.Storage 配置选择数据库或 Redis
 }
 
 // queueLogger 适配器 - 实现 queue.Logger 接口
@@ -94,19 +104,36 @@ func NewTaskQueue(lc fx.Lifecycle, config Config, logger Logger, db Database) Ta
 	registry := queue.NewTaskRegistry()
 
 	// 创建任务仓库（用于持久化）
+	var redisClient *redis.Client
 	taskRepo := queue.NewGormTaskRepository(db.ORM)
-
-	// 配置选项
-	opts := []queue.Option{
-		queue.WithWorkerCount(cfg.WorkerNum),
+	if cfg.IsRedis() {
+		redisClient = newQueueRedisClient(config, logger)
+		taskRepo = queue.NewRedisTaskRepository(redisClient, cfg.KeyPrefix, cfg.FinishedTaskTTL())
 	}
 
-	if cfg.MaxRetry > 0 {
-		opts = append(opts, queue.WithMaxRetry(cfg.MaxRetry))
-	}
+	// 配置选项
+	opts := queueOptions(cfg, NamedQueueConfig{
+		Name:            cfg.Name,
+		WorkerNum:       cfg.WorkerNum,
+		MaxRetry:        cfg.MaxRetry,
+		Scheduler:       cfg.Scheduler,
+		ResumeTaskTypes: cfg.ResumeTaskTypes,
+	})
 
-	if cfg.Name != "" {
-		opts = append(opts, queue.WithName(cfg.Name))
+	// 分布式传输，多个实例共同消费任务
+	var natsConn *nats.Conn
+	if cfg.IsDistributed() {
+		var broker queue.Broker
+		if cfg.Transport == "redis" {
+			if redisClient == nil {
+				redisClient = newQueueRedisClient(config, logger)
+			}
+			broker = queue.NewRedisStreamBroker(redisClient, cfg.Stream, cfg.Group, queueConsumerName(cfg))
+		} else {
+			natsConn, broker = newQueueNATSBroker(cfg, logger)
+		}
+		opts = append(opts, queue.WithBroker(broker, 0))
+		logger.Zap.Infof("Task Queue transport: %s, consumer %q", cfg.Transport, queueConsumerName(cfg))
 	}
 
 	// 创建队列
@@ -121,12 +148,116 @@ func NewTaskQueue(lc fx.Lifecycle, config Config, logger Logger, db Database) Ta
 		OnStop: func(ctx context.Context) error {
 			logger.Zap.Info("Stopping Task Queue")
 			q.Shutdown()
+			if natsConn != nil {
+				natsConn.Close()
+			}
+			if redisClient != nil {
+				return redisClient.Close()
+			}
 			return nil
 		},
 	})
 
 	logger.Zap.Infof("Task Queue initialized with %d workers", cfg.WorkerNum)
-	return TaskQueue{Queue: q, Registry: registry}
+	return TaskQueue{Queue: q, Registry: registry, Repository: taskRepo}
+}
+
+// queueOptions 按队列配置生成选项，命名队列未配置的项使用默认队列的值
+func queueOptions(cfg *QueueConfig, named NamedQueueConfig) []queue.Option {
+	workerNum := named.WorkerNum
+	if workerNum == 0 {
+		workerNum = cfg.WorkerNum
+	}
+	opts := []queue.Option{
+		queue.WithWorkerCount(workerNum),
+	}
+
+	maxRetry := named.MaxRetry
+	if maxRetry == 0 {
+		maxRetry = cfg.MaxRetry
+	}
+	if maxRetry > 0 {
+		opts = append(opts, queue.WithMaxRetry(maxRetry))
+	}
+
+	if named.Name != "" {
+		opts = append(opts, queue.WithName(named.Name))
+	}
+
+	if len(named.ResumeTaskTypes) > 0 {
+		opts = append(opts, queue.WithResumeTaskType(named.ResumeTaskTypes...))
+	}
+
+	scheduler := named.Scheduler
+	if scheduler == "" {
+		scheduler = cfg.Scheduler
+	}
+	switch queue.SchedulerKind(scheduler) {
+	case queue.SchedulerFair:
+		weights := cfg.OwnerWeights
+		opts = append(opts, queue.WithFairScheduling(func(ownerID uint64) int {
+			return weights[ownerID]
+		}))
+	case queue.SchedulerPriority:
+		opts = append(opts, queue.WithScheduler(queue.SchedulerPriority))
+	}
+
+	return opts
+}
+
+// newQueueRedisClient 创建任务存储使用的 Redis 连接，地址与密码复用 Cache 配置
+func newQueueRedisClient(config Config, logger Logger) *redis.Client {
+	if config.Cache == nil {
+		logger.Zap.Fatal("Queue storage is redis but Cache redis settings are missing")
+	}
+
+	addr := config.Cache.Addr()
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		DB:       config.Queue.RedisDB,
+		Password: config.Cache.Password,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		logger.Zap.Fatalf("Failed to connect to queue Redis[%s]: %v", addr, err)
+	}
+
+	logger.Zap.Infof("Task Queue storage: redis[%s]", addr)
+	return client
+}
+
+// newQueueNATSBroker 连接 NATS 并创建 JetStream 流与消费组
+func newQueueNATSBroker(cfg *QueueConfig, logger Logger) (*nats.Conn, queue.Broker) {
+	url := cfg.NATSURL
+	if url == "" {
+		url = nats.DefaultURL
+	}
+
+	nc, err := nats.Connect(url, nats.Name(queueConsumerName(cfg)), nats.MaxReconnects(-1))
+	if err != nil {
+		logger.Zap.Fatalf("Failed to connect to queue NATS[%s]: %v", url, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	broker, err := queue.NewNATSBroker(ctx, nc, cfg.Stream, cfg.Group)
+	if err != nil {
+		logger.Zap.Fatalf("Failed to create queue JetStream broker: %v", err)
+	}
+	return nc, broker
+}
+
+// queueConsumerName 当前实例在消费组中的名称，未配置时为 主机名-进程号
+func queueConsumerName(cfg *QueueConfig) string {
+	if cfg.Consumer != "" {
+		return cfg.Consumer
+	}
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
 }
 
 // IsEnabled 检查任务队列是否启用
@@ -137,7 +268,7 @@ func (q *TaskQueue) IsEnabled() bool {
 // QueueTask 提交任务到队列
 // 示例:
 //
-//	task := queue.NewSimpleTask("send_email", payload, owner)
+//	task := queue.NewFuncTask("send_email", owner, func(ctx context.Context) error { ... })
 //	err := taskQueue.QueueTask(ctx, task)
 func (q *TaskQueue) QueueTask(ctx context.Context, t queue.Task) error {
 	if q.Queue != nil {
@@ -146,6 +277,14 @@ func (q *TaskQueue) QueueTask(ctx context.Context, t queue.Task) error {
 	return nil
 }
 
+// QueueTaskAt 提交任务到队列，at 之前不会执行；at 为零值或已过去时立即执行
+func (q *TaskQueue) QueueTaskAt(ctx context.Context, t queue.Task, at time.Time) error {
+	if q.Queue != nil {
+		return q.Queue.QueueTaskAt(ctx, t, at)
+	}
+	return nil
+}
+
 // Stats 获取队列统计信息
 func (q *TaskQueue) Stats() map[string]int {
 	if q.Queue == nil {
@@ -160,6 +299,99 @@ func (q *TaskQueue) Stats() map[string]int {
 	}
 }
 
+// QueueManager 按名称管理默认队列与 Queue.Queues 中配置的命名队列
+// 命名队列与默认队列共用任务注册表和存储，因此任务 ID 全局唯一，后台任务管理可以查看所有队列的任务
+type QueueManager struct {
+	queues  map[string]TaskQueue
+	types   map[string]string // 任务类型 -> 恢复该类型任务的命名队列
+	names   []string
+	primary TaskQueue
+}
+
+// NewQueueManager 创建命名队列，默认队列未启用时所有名称都返回未启用的队列
+func NewQueueManager(lc fx.Lifecycle, config Config, logger Logger, taskQueue TaskQueue) QueueManager {
+	m := QueueManager{
+		queues:  make(map[string]TaskQueue),
+		types:   make(map[string]string),
+		primary: taskQueue,
+	}
+	cfg := config.Queue
+	if !taskQueue.IsEnabled() {
+		return m
+	}
+
+	for _, named := range cfg.Queues {
+		if named.Name == "" {
+			logger.Zap.Warn("Skipping named queue without name")
+			continue
+		}
+		if _, ok := m.queues[named.Name]; ok || named.Name == cfg.Name {
+			logger.Zap.Warnf("Skipping duplicated queue %q", named.Name)
+			continue
+		}
+
+		q := queue.New(&queueLogger{logger: logger}, taskQueue.Repository, taskQueue.Registry, queueOptions(cfg, named)...)
+		m.queues[named.Name] = TaskQueue{Queue: q, Registry: taskQueue.Registry, Repository: taskQueue.Repository}
+		m.names = append(m.names, named.Name)
+		for _, taskType := range named.ResumeTaskTypes {
+			m.types[taskType] = named.Name
+		}
+
+		name := named.Name
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				logger.Zap.Infof("Starting Task Queue %q", name)
+				q.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				logger.Zap.Infof("Stopping Task Queue %q", name)
+				q.Shutdown()
+				return nil
+			},
+		})
+		logger.Zap.Infof("Task Queue %q initialized", name)
+	}
+
+	return m
+}
+
+// Default 默认队列
+func (m QueueManager) Default() TaskQueue {
+	return m.primary
+}
+
+// Get 按名称获取命名队列，空名称返回默认队列
+func (m QueueManager) Get(name string) (TaskQueue, bool) {
+	if name == "" {
+		return m.primary, true
+	}
+	q, ok := m.queues[name]
+	return q, ok
+}
+
+// GetOrDefault 按名称获取命名队列，未配置时使用默认队列
+// 服务可以约定自己的队列名称，部署时按需在配置中拆分出独立的队列
+func (m QueueManager) GetOrDefault(name string) TaskQueue {
+	if q, ok := m.queues[name]; ok {
+		return q
+	}
+	return m.primary
+}
+
+// ForType 执行指定类型任务的队列，即 ResumeTaskTypes 包含该类型的命名队列，没有时为默认队列
+func (m QueueManager) ForType(taskType string) TaskQueue {
+	if name, ok := m.types[taskType]; ok {
+		return m.queues[name]
+	}
+	return m.primary
+}
+
+// Names 命名队列的名称，按配置顺序
+func (m QueueManager) Names() []string {
+	return append([]string(nil), m.names...)
+}
+
 // ============================================================================
 // 定时任务 (Crontab)
 // ============================================================================
@@ -223,9 +455,9 @@ func NewCrontab(lc fx.Lifecycle, config Config, logger Logger) Crontab {
 //	crontab.AddTask("cleanup", "0 0 * * * *", func(ctx context.Context) {
 //	    // 每天凌晨执行清理
 //	})
-func (c *Crontab) AddTask(name, spec string, fn crontab.CronTaskFunc) error {
+func (c *Crontab) AddTask(name, spec string, fn crontab.CronTaskFunc, opts ...crontab.TaskOption) error {
 	if c.Cron != nil {
-		return c.Cron.AddTask(name, spec, fn)
+		return c.Cron.AddTask(name, spec, fn, opts...)
 	}
 	return nil
 }
@@ -250,7 +482,7 @@ func (c *Crontab) IsEnabled() bool {
 // Downloader 下载器封装
 type Downloader struct {
 	Client downloader.Downloader
-	Type   string // "aria2" 或 "qbittorrent"
+	Type   string // "aria2"、"qbittorrent"、"sabnzbd" 或 "native"
 }
 
 // downloaderLogger 适配器
@@ -315,6 +547,38 @@ func NewDownloader(config Config, logger Logger) Downloader {
 		logger.Zap.Infof("QBittorrent downloader initialized: %s", cfg.QBittorrent.Server)
 		return Downloader{Client: client, Type: "qbittorrent"}
 
+	case "sabnzbd":
+		if cfg.SABnzbd == nil {
+			logger.Zap.Error("SABnzbd config is missing")
+			return Downloader{}
+		}
+		client, err := sabnzbd.New(dl, &sabnzbd.Settings{
+			Server:   cfg.SABnzbd.Server,
+			APIKey:   cfg.SABnzbd.APIKey,
+			Category: cfg.SABnzbd.Category,
+			Options:  cfg.SABnzbd.Options,
+		})
+		if err != nil {
+			logger.Zap.Errorf("Failed to create SABnzbd client: %v", err)
+			return Downloader{}
+		}
+		logger.Zap.Infof("SABnzbd downloader initialized: %s", cfg.SABnzbd.Server)
+		return Downloader{Client: client, Type: "sabnzbd"}
+
+	case "native":
+		if cfg.Native == nil {
+			logger.Zap.Error("Native downloader config is missing")
+			return Downloader{}
+		}
+		client := native.New(dl, &native.Settings{
+			TempPath:       cfg.Native.TempPath,
+			Segments:       cfg.Native.Segments,
+			MinSegmentSize: cfg.Native.MinSegmentSize,
+			Options:        cfg.Native.Options,
+		})
+		logger.Zap.Infof("Native downloader initialized: %s", cfg.Native.TempPath)
+		return Downloader{Client: client, Type: "native"}
+
 	default:
 		logger.Zap.Errorf("Unknown downloader type: %s", cfg.Type)
 		return Downloader{}
@@ -329,6 +593,17 @@ func (d *Downloader) CreateTask(ctx context.Context, url string, options map[str
 	return nil, nil
 }
 
+// CreateTaskFromFile 通过上传的 .torrent / .metalink 文件创建下载任务，下载器不支持时返回 downloader.ErrUnsupportedFile
+func (d *Downloader) CreateTaskFromFile(ctx context.Context, file *downloader.SourceFile, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	if creator, ok := d.Client.(downloader.FileCreator); ok {
+		return creator.CreateTaskFromFile(ctx, file, options)
+	}
+	if d.Client != nil {
+		return nil, downloader.ErrUnsupportedFile
+	}
+	return nil, nil
+}
+
 // Info 获取任务状态
 func (d *Downloader) Info(ctx context.Context, handle *downloader.TaskHandle) (*downloader.TaskStatus, error) {
 	if d.Client != nil {
@@ -345,6 +620,30 @@ func (d *Downloader) Cancel(ctx context.Context, handle *downloader.TaskHandle)
 	return nil
 }
 
+// Pause 暂停任务
+func (d *Downloader) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	if d.Client != nil {
+		return d.Client.Pause(ctx, handle)
+	}
+	return nil
+}
+
+// Resume 恢复任务
+func (d *Downloader) Resume(ctx context.Context, handle *downloader.TaskHandle) error {
+	if d.Client != nil {
+		return d.Client.Resume(ctx, handle)
+	}
+	return nil
+}
+
+// SetSpeedLimit 设置任务限速（KB/s），0 表示不限速
+func (d *Downloader) SetSpeedLimit(ctx context.Context, handle *downloader.TaskHandle, downloadKBps, uploadKBps int64) error {
+	if d.Client != nil {
+		return d.Client.SetSpeedLimit(ctx, handle, downloadKBps, uploadKBps)
+	}
+	return nil
+}
+
 // Test 测试连接
 func (d *Downloader) Test(ctx context.Context) (string, error) {
 	if d.Client != nil {
@@ -0,0 +1,191 @@
+package lib
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/top-system/light-admin/errors"
+)
+
+// 可在运行时启停的功能模块
+const (
+	FeatureModuleDownload  = "download"
+	FeatureModuleNotice    = "notice"
+	FeatureModuleWebSocket = "websocket"
+	FeatureModuleFile      = "file"
+)
+
+// FeatureModule 功能模块声明
+// 禁用后 RoutePrefixes 下的接口返回 404，Components 前缀匹配的菜单不再通过菜单接口返回，
+// 模块的后台任务通过 OnChange 回调或执行前检查 IsEnabled 停止
+type FeatureModule struct {
+	Name          string   `json:"name"`
+	Title         string   `json:"title"`
+	Enabled       bool     `json:"enabled"`
+	RoutePrefixes []string `json:"routePrefixes"`
+	Components    []string `json:"components"`
+}
+
+// FeatureModules 功能模块注册表，启用状态由系统配置持久化，启动时加载
+type FeatureModules struct {
+	mu      *sync.RWMutex
+	modules map[string]*FeatureModule
+	order   *[]string
+	hooks   map[string][]func(enabled bool)
+}
+
+// NewFeatureModules creates the feature module registry with built-in modules
+func NewFeatureModules() FeatureModules {
+	a := FeatureModules{
+		mu:      &sync.RWMutex{},
+		modules: make(map[string]*FeatureModule),
+		order:   &[]string{},
+		hooks:   make(map[string][]func(enabled bool)),
+	}
+
+	a.Register(FeatureModule{
+		Name:          FeatureModuleDownload,
+		Title:         "离线下载",
+		RoutePrefixes: []string{"/api/v1/downloads"},
+		Components:    []string{"system/downloader/"},
+	})
+	a.Register(FeatureModule{
+		Name:          FeatureModuleNotice,
+		Title:         "通知公告",
+		RoutePrefixes: []string{"/api/v1/notices"},
+		Components:    []string{"system/notice/"},
+	})
+	a.Register(FeatureModule{
+		Name:          FeatureModuleWebSocket,
+		Title:         "WebSocket 消息",
+		RoutePrefixes: []string{"/ws", "/api/v1/websocket", "/api/v1/ws-events", "/api/v1/events"},
+		Components:    []string{"demo/websocket"},
+	})
+	a.Register(FeatureModule{
+		Name:          FeatureModuleFile,
+		Title:         "文件管理",
+		RoutePrefixes: []string{"/api/v1/files"},
+	})
+
+	return a
+}
+
+// Register 注册功能模块，默认启用
+func (a FeatureModules) Register(module FeatureModule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.modules[module.Name]; !ok {
+		*a.order = append(*a.order, module.Name)
+	}
+	module.Enabled = true
+	a.modules[module.Name] = &module
+}
+
+// OnChange 注册模块启用状态变化时的回调
+func (a FeatureModules) OnChange(name string, fn func(enabled bool)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.hooks[name] = append(a.hooks[name], fn)
+}
+
+// IsEnabled 模块是否启用，未注册的模块视为启用
+func (a FeatureModules) IsEnabled(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	module, ok := a.modules[name]
+	return !ok || module.Enabled
+}
+
+// SetEnabled 切换模块启用状态，状态变化时依次调用回调
+func (a FeatureModules) SetEnabled(name string, enabled bool) error {
+	a.mu.Lock()
+	module, ok := a.modules[name]
+	if !ok {
+		a.mu.Unlock()
+		return errors.FeatureModuleNotFound
+	}
+
+	changed := module.Enabled != enabled
+	module.Enabled = enabled
+	hooks := append([]func(bool){}, a.hooks[name]...)
+	a.mu.Unlock()
+
+	if changed {
+		for _, fn := range hooks {
+			fn(enabled)
+		}
+	}
+
+	return nil
+}
+
+// Get 查询模块声明
+func (a FeatureModules) Get(name string) (FeatureModule, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	module, ok := a.modules[name]
+	if !ok {
+		return FeatureModule{}, false
+	}
+	return *module, true
+}
+
+// List 按注册顺序返回全部模块
+func (a FeatureModules) List() []FeatureModule {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	list := make([]FeatureModule, 0, len(*a.order))
+	for _, name := range *a.order {
+		list = append(list, *a.modules[name])
+	}
+	return list
+}
+
+// DisabledByPath 返回请求路径所属的已禁用模块，路径需与前缀完全相同或以 "/" 分隔
+func (a FeatureModules) DisabledByPath(path string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, name := range *a.order {
+		module := a.modules[name]
+		if module.Enabled {
+			continue
+		}
+		for _, prefix := range module.RoutePrefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// IsComponentDisabled 菜单组件是否属于已禁用的模块
+func (a FeatureModules) IsComponentDisabled(component string) bool {
+	if component == "" {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, name := range *a.order {
+		module := a.modules[name]
+		if module.Enabled {
+			continue
+		}
+		for _, prefix := range module.Components {
+			if strings.HasPrefix(component, prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
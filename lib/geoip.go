@@ -0,0 +1,22 @@
+package lib
+
+import (
+	"github.com/top-system/light-admin/pkg/geoip"
+)
+
+// NewGeoIP 加载离线 IP 归属地数据库
+// 未配置或加载失败时返回空库，此时只能识别内网地址
+func NewGeoIP(config Config, logger Logger) *geoip.DB {
+	if config.Security == nil || config.Security.GeoIPFile == "" {
+		return &geoip.DB{}
+	}
+
+	db, err := geoip.Open(config.Security.GeoIPFile)
+	if err != nil {
+		logger.Zap.Warnf("Failed to load GeoIP file %s: %v", config.Security.GeoIPFile, err)
+		return &geoip.DB{}
+	}
+
+	logger.Zap.Infof("GeoIP loaded: %d ranges from %s", db.Len(), config.Security.GeoIPFile)
+	return db
+}
@@ -36,6 +36,8 @@ func (a *Validator) Validate(i interface{}) error {
 
 // NewHttpHandler creates a new request handler
 func NewHttpHandler(logger Logger, config Config) HttpHandler {
+	echox.EnableProblemJSON(config.Http.ProblemJSON)
+
 	// Error handlers
 	echo.NotFoundHandler = func(ctx echo.Context) error {
 		return echox.Response{Code: http.StatusNotFound}.JSON(ctx)
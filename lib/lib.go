@@ -9,8 +9,18 @@ var Module = fx.Options(
 	fx.Provide(NewLogger),
 	fx.Provide(NewDatabase),
 	fx.Provide(NewDBCompat),
+	fx.Provide(NewReadOnly),
 	fx.Provide(NewCache),
+	fx.Provide(NewPermRegistry),
+	fx.Provide(NewFeatureModules),
+	fx.Provide(NewResponseCache),
 	fx.Provide(NewCaptcha),
 	fx.Provide(NewWebSocket),
+	fx.Provide(NewGeoIP),
+	fx.Provide(NewMailer),
+	fx.Provide(NewLogShipper),
+	fx.Provide(NewMetrics),
+	fx.Provide(NewTelemetry),
+	fx.Provide(NewEventBus),
 	ExtrasModule, // 启用扩展模块（队列、定时任务、下载器）
 )
@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/pkg/logship"
+)
+
+// 转发的日志事件类型
+const (
+	LogShipOperation     = "operation"
+	LogShipLogin         = "login"
+	LogShipSecurityAlert = "security_alert"
+)
+
+// LogShipper 将操作日志、登录事件、安全告警转发到外部 SIEM（syslog、HTTP、Kafka）
+// 未启用时 Ship 不做任何处理
+type LogShipper struct {
+	shipper        *logship.Shipper
+	types          map[string]bool
+	includePayload bool
+	source         string
+	host           string
+}
+
+// NewLogShipper creates a new log shipper
+func NewLogShipper(lc fx.Lifecycle, config Config, logger Logger) LogShipper {
+	cfg := config.LogShipping
+	if cfg == nil || !cfg.Enable {
+		return LogShipper{}
+	}
+
+	sinks := make([]logship.Sink, 0, 3)
+	if c := cfg.Syslog; c != nil && c.Enable && c.Address != "" {
+		sinks = append(sinks, logship.NewSyslogSink(c.Network, c.Address, c.AppName, c.Facility))
+	}
+	if c := cfg.HTTP; c != nil && c.Enable && c.URL != "" {
+		sinks = append(sinks, logship.NewHTTPSink(c.URL, c.Format, c.Headers))
+	}
+	if c := cfg.Kafka; c != nil && c.Enable && len(c.Brokers) > 0 && c.Topic != "" {
+		sinks = append(sinks, logship.NewKafkaSink(c.Brokers, c.Topic))
+	}
+	if len(sinks) == 0 {
+		logger.Zap.Warn("Log shipping is enabled but no sink is configured")
+		return LogShipper{}
+	}
+
+	shipper := logship.New(logship.Options{
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: time.Duration(cfg.FlushInterval) * time.Millisecond,
+		BufferSize:    cfg.BufferSize,
+		Block:         cfg.Backpressure == "block",
+		BlockTimeout:  time.Duration(cfg.BlockTimeout) * time.Millisecond,
+		MaxRetry:      cfg.MaxRetry,
+		RetryDelay:    time.Duration(cfg.RetryDelay) * time.Millisecond,
+		SendTimeout:   time.Duration(cfg.SendTimeout) * time.Second,
+	}, logger.Zap, sinks...)
+
+	var types map[string]bool
+	if len(cfg.Types) > 0 {
+		types = make(map[string]bool, len(cfg.Types))
+		for _, t := range cfg.Types {
+			types[t] = true
+		}
+	}
+
+	host, _ := os.Hostname()
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Zap.Infof("Starting log shipping to %d sinks", len(sinks))
+			shipper.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Zap.Info("Stopping log shipping")
+			return shipper.Stop(ctx)
+		},
+	})
+
+	return LogShipper{
+		shipper:        shipper,
+		types:          types,
+		includePayload: cfg.IncludePayload,
+		source:         config.Name,
+		host:           host,
+	}
+}
+
+// IsEnabled 是否已启用
+func (a LogShipper) IsEnabled() bool {
+	return a.shipper != nil
+}
+
+// IncludePayload 操作日志是否包含请求参数和响应内容
+func (a LogShipper) IncludePayload() bool {
+	return a.includePayload
+}
+
+// Ship 转发一条事件，data 以 JSON 序列化；未启用或类型未开启时忽略
+func (a LogShipper) Ship(eventType string, severity logship.Severity, data interface{}) {
+	if a.shipper == nil || (a.types != nil && !a.types[eventType]) {
+		return
+	}
+
+	a.shipper.Ship(logship.Event{
+		Type:     eventType,
+		Time:     time.Now(),
+		Severity: severity,
+		Source:   a.source,
+		Host:     a.host,
+		Data:     data,
+	})
+}
+
+// Stats 各接收端的发送统计，未启用时返回空
+func (a LogShipper) Stats() []logship.Stats {
+	if a.shipper == nil {
+		return nil
+	}
+	return a.shipper.Stats()
+}
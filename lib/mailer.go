@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"context"
+	"errors"
+
+	"github.com/top-system/light-admin/pkg/mailer"
+)
+
+// ErrMailDisabled 未启用邮件发送
+var ErrMailDisabled = errors.New("mail is not enabled")
+
+// Mailer SMTP 邮件发送
+type Mailer struct {
+	config *MailConfig
+	client *mailer.Mailer
+}
+
+// NewMailer creates a new mailer
+func NewMailer(config Config) Mailer {
+	a := Mailer{config: config.Mail}
+	if a.IsEnabled() {
+		a.client = mailer.New(mailer.Config{
+			Host:     a.config.Host,
+			Port:     a.config.Port,
+			Username: a.config.Username,
+			Password: a.config.Password,
+			From:     a.config.From,
+		})
+	}
+	return a
+}
+
+// IsEnabled 是否已启用
+func (a Mailer) IsEnabled() bool {
+	return a.config != nil && a.config.Enable && a.config.Host != ""
+}
+
+// Send 发送纯文本邮件
+func (a Mailer) Send(to []string, subject, body string) error {
+	if !a.IsEnabled() {
+		return ErrMailDisabled
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	return a.SendMessage(context.Background(), mailer.Message{To: to, Subject: subject, Text: body})
+}
+
+// SendMessage 发送邮件，可以同时包含纯文本与 HTML 正文
+func (a Mailer) SendMessage(ctx context.Context, msg mailer.Message) error {
+	if !a.IsEnabled() {
+		return ErrMailDisabled
+	}
+
+	return a.client.Send(ctx, msg)
+}
@@ -0,0 +1,167 @@
+package lib
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/queue"
+	"github.com/top-system/light-admin/pkg/websocket"
+)
+
+// defaultMetricsNamespace 指标名称默认前缀
+const defaultMetricsNamespace = "light_admin"
+
+// Metrics Prometheus 指标注册表
+// 未启用时 Registry 为空，各 Observe 方法不做任何事，/metrics 路由不注册
+type Metrics struct {
+	Registry  *prometheus.Registry
+	Namespace string
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	cronRuns     *prometheus.CounterVec
+	cronDuration *prometheus.HistogramVec
+}
+
+// NewMetrics 创建指标注册表，注册 HTTP、定时任务、任务队列、WebSocket 及数据库连接池指标
+// 下载任务等业务指标由各服务通过 MustRegister 注册
+func NewMetrics(config Config, logger Logger, db Database, ws *websocket.WebSocket, queues QueueManager) Metrics {
+	cfg := config.Metrics
+	if cfg == nil || !cfg.Enable {
+		return Metrics{}
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = defaultMetricsNamespace
+	}
+
+	m := Metrics{
+		Registry:  prometheus.NewRegistry(),
+		Namespace: namespace,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		cronRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "crontab",
+			Name:      "executions_total",
+			Help:      "Total number of cron task executions by task and result.",
+		}, []string{"task", "result"}),
+		cronDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "crontab",
+			Name:      "execution_duration_seconds",
+			Help:      "Cron task execution duration by task.",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900},
+		}, []string{"task"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.cronRuns,
+		m.cronDuration,
+	)
+
+	// 数据库连接池
+	if sqlDB, err := db.ORM.DB(); err != nil {
+		logger.Zap.Warnf("Metrics: failed to get database connection pool: %v", err)
+	} else {
+		m.Registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, config.Database.Name))
+	}
+
+	// 任务队列，直接读取各队列的 Metric
+	if primary := queues.Default(); primary.IsEnabled() {
+		name := config.Queue.Name
+		if name == "" {
+			name = "default"
+		}
+		collector := queue.NewMetricCollector(namespace)
+		collector.Add(name, primary.Queue.Metric())
+		for _, name := range queues.Names() {
+			q, _ := queues.Get(name)
+			collector.Add(name, q.Queue.Metric())
+		}
+		m.Registry.MustRegister(collector)
+	}
+
+	// WebSocket 会话
+	m.Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "websocket",
+			Name:      "sessions",
+			Help:      "Number of open WebSocket sessions.",
+		}, func() float64 { return float64(ws.Broker.GetTotalSessionCount()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "websocket",
+			Name:      "online_users",
+			Help:      "Number of users with at least one WebSocket session.",
+		}, func() float64 { return float64(ws.Broker.GetOnlineUserCount()) }),
+	)
+
+	logger.Zap.Info("Prometheus metrics enabled")
+	return m
+}
+
+// IsEnabled 检查指标是否启用
+func (m Metrics) IsEnabled() bool {
+	return m.Registry != nil
+}
+
+// MustRegister 注册采集器，未启用时忽略
+func (m Metrics) MustRegister(cs ...prometheus.Collector) {
+	if m.Registry != nil {
+		m.Registry.MustRegister(cs...)
+	}
+}
+
+// ObserveHTTP 记录一次 HTTP 请求，route 为路由模板而不是实际路径，避免标签数量随 ID 增长
+func (m Metrics) ObserveHTTP(route, method string, status int, duration time.Duration) {
+	if m.Registry == nil {
+		return
+	}
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+// ObserveCron 记录一次定时任务执行，result 为 success、failed 或 panicked
+func (m Metrics) ObserveCron(e crontab.Execution) {
+	if m.Registry == nil {
+		return
+	}
+	result := "success"
+	switch {
+	case e.Panic != "":
+		result = "panicked"
+	case e.Err != nil:
+		result = "failed"
+	}
+	m.cronRuns.WithLabelValues(e.Name, result).Inc()
+	m.cronDuration.WithLabelValues(e.Name).Observe(e.Duration.Seconds())
+}
+
+// Handler 以 Prometheus 文本格式输出指标
+func (m Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
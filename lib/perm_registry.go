@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"sort"
+	"sync"
+)
+
+// RoutePerm 路由权限声明
+// Perm 为空表示该路由只需登录即可访问；Public 表示免登录访问（登录、签名下载等由接口自身校验的路由）
+type RoutePerm struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Perm   string      `json:"perm"`
+	Public bool        `json:"public"`
+	Title  string      `json:"title,omitempty"` // 操作标题，用于生成操作描述
+	Params interface{} `json:"-"`               // 查询参数或请求体结构示例，用于生成参数描述
+}
+
+// PermRegistry 路由权限注册表
+// 各模块路由在 Setup 时声明所需的 perm 标识（与菜单按钮 perm 一致），
+// 供权限中间件校验以及审计接口导出路由→权限矩阵
+type PermRegistry struct {
+	mu     *sync.RWMutex
+	routes map[string]RoutePerm
+}
+
+// NewPermRegistry creates a new route permission registry
+func NewPermRegistry() PermRegistry {
+	return PermRegistry{
+		mu:     &sync.RWMutex{},
+		routes: make(map[string]RoutePerm),
+	}
+}
+
+func permRegistryKey(method, path string) string {
+	return method + " " + path
+}
+
+// Register 声明路由所需权限
+func (a PermRegistry) Register(method, path, perm string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := permRegistryKey(method, path)
+	rp := a.routes[key]
+	rp.Method = method
+	rp.Path = path
+	rp.Perm = perm
+	a.routes[key] = rp
+}
+
+// RegisterPublic 声明免登录访问的路由
+func (a PermRegistry) RegisterPublic(method, path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := permRegistryKey(method, path)
+	rp := a.routes[key]
+	rp.Method = method
+	rp.Path = path
+	rp.Perm = ""
+	rp.Public = true
+	a.routes[key] = rp
+}
+
+// Describe 补充路由的操作标题及参数结构
+func (a PermRegistry) Describe(method, path, title string, params interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := permRegistryKey(method, path)
+	rp := a.routes[key]
+	rp.Method = method
+	rp.Path = path
+	rp.Title = title
+	rp.Params = params
+	a.routes[key] = rp
+}
+
+// Get 查询路由的完整声明
+func (a PermRegistry) Get(method, path string) (RoutePerm, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rp, ok := a.routes[permRegistryKey(method, path)]
+	return rp, ok
+}
+
+// Lookup 查询路由声明的权限，第二个返回值表示路由是否已声明
+func (a PermRegistry) Lookup(method, path string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	rp, ok := a.routes[permRegistryKey(method, path)]
+	return rp.Perm, ok
+}
+
+// List 返回所有已声明的路由权限（按路径、方法排序）
+func (a PermRegistry) List() []RoutePerm {
+	a.mu.RLock()
+	list := make([]RoutePerm, 0, len(a.routes))
+	for _, rp := range a.routes {
+		list = append(list, rp)
+	}
+	a.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Method < list[j].Method
+	})
+
+	return list
+}
@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/errors"
+)
+
+// ReadOnlyStatus 只读模式状态
+type ReadOnlyStatus struct {
+	Enabled bool      `json:"enabled"`
+	Reason  string    `json:"reason"`
+	Since   time.Time `json:"since"`
+}
+
+// ReadOnlyExempt 只读模式下仍允许写入的模型，例如登录会话，否则只读期间无法登录
+type ReadOnlyExempt interface {
+	ReadOnlyExempt()
+}
+
+// isReadOnlyExempt 写操作的模型是否实现了 ReadOnlyExempt，原生 SQL 没有模型，总是拦截
+func isReadOnlyExempt(tx *gorm.DB) bool {
+	if tx.Statement.Schema == nil {
+		return false
+	}
+	_, ok := reflect.New(tx.Statement.Schema.ModelType).Interface().(ReadOnlyExempt)
+	return ok
+}
+
+// ReadOnly 数据库只读模式
+// 主库故障切换或数据恢复期间开启，写接口返回 503，读接口照常可用；
+// 同时在 GORM 层拦截写操作，避免后台任务写入，实现 ReadOnlyExempt 的模型除外。状态仅对当前实例生效
+type ReadOnly struct {
+	status *atomic.Pointer[ReadOnlyStatus]
+	logger Logger
+}
+
+// NewReadOnly creates read-only mode switch and registers GORM write guards
+func NewReadOnly(config Config, db Database, logger Logger) ReadOnly {
+	a := ReadOnly{
+		status: &atomic.Pointer[ReadOnlyStatus]{},
+		logger: logger,
+	}
+	a.status.Store(&ReadOnlyStatus{})
+
+	if config.Database.ReadOnly {
+		a.Enable("enabled by configuration")
+	}
+
+	guard := func(tx *gorm.DB) {
+		if a.IsEnabled() && !isReadOnlyExempt(tx) {
+			_ = tx.AddError(errors.SystemReadOnly)
+		}
+	}
+
+	callbacks := db.ORM.Callback()
+	for name, err := range map[string]error{
+		"create": callbacks.Create().Before("gorm:create").Register("readonly:create", guard),
+		"update": callbacks.Update().Before("gorm:update").Register("readonly:update", guard),
+		"delete": callbacks.Delete().Before("gorm:delete").Register("readonly:delete", guard),
+		"raw":    callbacks.Raw().Before("gorm:raw").Register("readonly:raw", guard),
+	} {
+		if err != nil {
+			logger.Zap.Errorf("Failed to register read-only %s callback: %v", name, err)
+		}
+	}
+
+	return a
+}
+
+// IsEnabled 是否处于只读模式
+func (a ReadOnly) IsEnabled() bool {
+	return a.status.Load().Enabled
+}
+
+// Status 获取只读模式状态
+func (a ReadOnly) Status() ReadOnlyStatus {
+	return *a.status.Load()
+}
+
+// Enable 开启只读模式
+func (a ReadOnly) Enable(reason string) {
+	a.status.Store(&ReadOnlyStatus{Enabled: true, Reason: reason, Since: time.Now()})
+	a.logger.Zap.Warnf("Read-only mode enabled: %s", reason)
+}
+
+// Disable 关闭只读模式
+func (a ReadOnly) Disable() {
+	a.status.Store(&ReadOnlyStatus{Since: time.Now()})
+	a.logger.Zap.Info("Read-only mode disabled")
+}
@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+)
+
+// 响应缓存分组，服务层数据变更时按分组失效
+const (
+	ResponseCacheGroupDict      = "dict"
+	ResponseCacheGroupMenu      = "menu"
+	ResponseCacheGroupUser      = "user"
+	ResponseCacheGroupDirectory = "directory"
+)
+
+const (
+	responseCacheDefaultTTL = 300
+	responseCacheKeyVersion = "resp:version:%s"
+	responseCacheKey        = "resp:%s:%d:%s"
+)
+
+// ResponseCache 路由级响应缓存
+// 缓存键包含分组版本号，分组失效时只需递增版本号，旧缓存自然过期
+type ResponseCache struct {
+	config *ResponseCacheConfig
+	cache  Cache
+	logger Logger
+}
+
+// NewResponseCache creates a new response cache
+func NewResponseCache(config Config, cache Cache, logger Logger) ResponseCache {
+	cfg := config.ResponseCache
+	if cfg == nil {
+		cfg = &ResponseCacheConfig{}
+	}
+
+	return ResponseCache{
+		config: cfg,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// IsEnabled 检查响应缓存是否启用
+func (a ResponseCache) IsEnabled() bool {
+	return a.config.Enable
+}
+
+// TTL 获取分组的缓存时间，未单独配置时使用默认值
+func (a ResponseCache) TTL(group string) time.Duration {
+	if ttl, ok := a.config.GroupTTLs[group]; ok && ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+
+	if a.config.TTL > 0 {
+		return time.Duration(a.config.TTL) * time.Second
+	}
+
+	return responseCacheDefaultTTL * time.Second
+}
+
+// Key 生成带分组版本号的缓存键
+func (a ResponseCache) Key(group, key string) string {
+	return fmt.Sprintf(responseCacheKey, group, a.version(group), key)
+}
+
+// Get 读取缓存
+func (a ResponseCache) Get(key string, value interface{}) error {
+	return a.cache.Get(key, value)
+}
+
+// Set 写入缓存
+func (a ResponseCache) Set(group, key string, value interface{}) error {
+	return a.cache.Set(key, value, a.TTL(group))
+}
+
+// Invalidate 使分组下的所有响应缓存失效
+func (a ResponseCache) Invalidate(groups ...string) {
+	if !a.config.Enable {
+		return
+	}
+
+	for _, group := range groups {
+		versionKey := fmt.Sprintf(responseCacheKeyVersion, group)
+		if err := a.cache.Set(versionKey, time.Now().UnixNano(), 0); err != nil {
+			a.logger.Zap.Warnf("Failed to invalidate response cache %s: %v", group, err)
+		}
+	}
+}
+
+func (a ResponseCache) version(group string) int64 {
+	versionKey := fmt.Sprintf(responseCacheKeyVersion, group)
+
+	var version int64
+	if err := a.cache.Get(versionKey, &version); err == nil {
+		return version
+	}
+
+	version = time.Now().UnixNano()
+	if err := a.cache.Set(versionKey, version, 0); err != nil {
+		a.logger.Zap.Warnf("Failed to init response cache version %s: %v", group, err)
+	}
+
+	return version
+}
@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+)
+
+// Telemetry OpenTelemetry 链路追踪
+// 启用后设置全局 TracerProvider 与 W3C Trace Context 传播器，任务队列、下载器等 pkg 通过 otel 全局对象创建 span；
+// 未启用时使用 noop 实现，不产生任何开销
+type Telemetry struct {
+	Provider trace.TracerProvider
+	enabled  bool
+}
+
+// NewTelemetry 创建链路追踪，span 通过 OTLP/HTTP 批量导出，应用停止时导出剩余的 span
+func NewTelemetry(lc fx.Lifecycle, config Config, logger Logger) Telemetry {
+	cfg := config.Telemetry
+	if cfg == nil || !cfg.Enable {
+		return Telemetry{Provider: noop.NewTracerProvider()}
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "localhost:4318"
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if cfg.URLPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(cfg.URLPath))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		logger.Zap.Fatalf("Failed to create OTLP trace exporter: %v", err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = config.Name
+	}
+	resource, err := sdkresource.Merge(sdkresource.Default(), sdkresource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		logger.Zap.Warnf("Failed to merge telemetry resource: %v", err)
+		resource = sdkresource.Default()
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			return provider.Shutdown(ctx)
+		},
+	})
+
+	logger.Zap.Infof("Telemetry enabled, exporting traces to %s", endpoint)
+	return Telemetry{Provider: provider, enabled: true}
+}
+
+// IsEnabled 检查链路追踪是否启用
+func (t Telemetry) IsEnabled() bool {
+	return t.enabled
+}
+
+// Tracer 返回指定名称（通常为包路径）的 Tracer
+func (t Telemetry) Tracer(name string) trace.Tracer {
+	return t.Provider.Tracer(name)
+}
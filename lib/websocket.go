@@ -1,10 +1,100 @@
 package lib
 
 import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/constants"
 	"github.com/top-system/light-admin/pkg/websocket"
+	"github.com/top-system/light-admin/pkg/websocket/stomp/relay"
 )
 
 // NewWebSocket 创建WebSocket管理器
-func NewWebSocket(logger Logger) *websocket.WebSocket {
-	return websocket.New(logger.DesugarZap)
+// 模块禁用时停止指标推送并断开全部连接，重新启用后恢复推送
+func NewWebSocket(lc fx.Lifecycle, logger Logger, config Config, featureModules FeatureModules) *websocket.WebSocket {
+	ws := websocket.New(logger.DesugarZap)
+	if config.WebSocket != nil && config.WebSocket.MaxRedeliveries != 0 {
+		ws.Broker.SetMaxRedeliveries(config.WebSocket.MaxRedeliveries)
+	}
+	if config.WebSocket != nil && config.WebSocket.Relay != nil && config.WebSocket.Relay.Enable {
+		newWebSocketRelay(lc, logger, config, ws)
+	}
+
+	var mu sync.Mutex
+	var stopMetrics func()
+	start := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopMetrics == nil {
+			stopMetrics = ws.StartMetrics(websocket.DefaultMetricsInterval)
+		}
+	}
+	stop := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopMetrics != nil {
+			stopMetrics()
+			stopMetrics = nil
+		}
+	}
+
+	featureModules.OnChange(FeatureModuleWebSocket, func(enabled bool) {
+		if enabled {
+			start()
+			return
+		}
+		stop()
+		ws.Broker.CloseAll()
+	})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if featureModules.IsEnabled(FeatureModuleWebSocket) {
+				start()
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stop()
+			return nil
+		},
+	})
+
+	return ws
+}
+
+// newWebSocketRelay 创建实例间的消息转发，随应用启动与停止
+func newWebSocketRelay(lc fx.Lifecycle, logger Logger, config Config, ws *websocket.WebSocket) {
+	if config.Cache == nil || config.Cache.Host == "" {
+		logger.Zap.Fatal("WebSocket relay is enabled but Cache redis settings are missing")
+	}
+
+	cfg := config.WebSocket.Relay
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Cache.Addr(),
+		DB:       constants.RedisMainDB,
+		Password: config.Cache.Password,
+	})
+	r := relay.New(client, ws.Broker, logger.DesugarZap, relay.Options{
+		Channel:    cfg.Channel,
+		InstanceID: cfg.InstanceID,
+	})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+			defer cancel()
+			return r.Start(ctx)
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := r.Close(); err != nil {
+				logger.Zap.Warnf("Failed to clear WebSocket relay presence: %v", err)
+			}
+			return client.Close()
+		},
+	})
 }
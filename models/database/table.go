@@ -0,0 +1,26 @@
+package database
+
+import "gorm.io/gorm/schema"
+
+// 表所属模块，可在 Database.Modules 中为每个模块单独配置表前缀和 Schema
+const (
+	ModuleSystem   = "system"   // 用户、角色、菜单、字典等核心表，默认前缀 t
+	ModulePlatform = "platform" // 文件等平台功能表，默认前缀 t
+	ModuleQueue    = "queue"    // 任务队列、下载任务表，默认前缀 sys
+	ModuleLog      = "log"      // 操作日志表，默认前缀 sys
+)
+
+// ModuleNamer 支持按模块生成表名的命名策略（见 lib.ModuleNamingStrategy）
+type ModuleNamer interface {
+	ModuleTableName(module, table string) string
+}
+
+// ModuleTableName 根据命名策略生成模块表名
+// 命名策略不支持模块时使用 fallback（即原有的固定表名）
+func ModuleTableName(namer schema.Namer, module, table, fallback string) string {
+	if mn, ok := namer.(ModuleNamer); ok {
+		return mn.ModuleTableName(module, table)
+	}
+
+	return fallback
+}
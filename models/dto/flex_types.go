@@ -173,6 +173,20 @@ func (t *DateTime) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// UnmarshalParam 实现 echo.BindUnmarshaler 接口，用于绑定表单与查询参数
+func (t *DateTime) UnmarshalParam(param string) error {
+	if param == "" {
+		*t = DateTime(time.Time{})
+		return nil
+	}
+	tt, err := time.ParseInLocation(DateTimeFormat, param, time.Local)
+	if err != nil {
+		return err
+	}
+	*t = DateTime(tt)
+	return nil
+}
+
 // Value 实现 driver.Valuer 接口，用于数据库写入
 func (t DateTime) Value() (driver.Value, error) {
 	return time.Time(t), nil
@@ -7,5 +7,36 @@ import (
 type JwtClaims struct {
 	ID       uint64 `json:"id"`
 	Username string `json:"username"`
+	// SessionID 登录设备（刷新令牌记录）ID，登出或移除设备时使该设备的访问令牌失效
+	SessionID uint64 `json:"sid,omitempty"`
+	// RevokeVersion 签发时用户的令牌吊销版本，用户的令牌被全部吊销后版本不一致的令牌失效
+	RevokeVersion int64 `json:"rv,omitempty"`
+
+	// 服务账号（mTLS 客户端证书认证）按绑定的角色编码鉴权
+	ServiceAccount bool     `json:"serviceAccount,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+
+	// API Key 认证时为密钥 ID，按 Scopes（授权范围与用户当前权限的交集）鉴权
+	ApiKeyID uint64   `json:"akid,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+
 	jwt.RegisteredClaims
 }
+
+// JWK JSON Web Key（RFC 7517），用于对外发布令牌验证公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
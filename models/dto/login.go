@@ -5,6 +5,24 @@ type Login struct {
 	Password    string `json:"password" validate:"required"`
 	CaptchaID   string `json:"captchaId" validate:"required"`
 	CaptchaCode string `json:"captchaCode" validate:"required"`
+	// 启用两步验证的用户需要提供验证器应用中的 6 位验证码
+	TwoFactorCode string `json:"twoFactorCode"`
+}
+
+// TwoFactorEnrollResponse 两步验证绑定信息，密钥只在绑定时返回一次
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// 地址，前端据此生成二维码
+}
+
+// TwoFactorCodeForm 两步验证码
+type TwoFactorCodeForm struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// RefreshTokenForm 刷新令牌请求
+type RefreshTokenForm struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+// ReadOnlyForm 只读模式切换表单
+type ReadOnlyForm struct {
+	Enable bool   `json:"enable"`
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+// FeatureModuleForm 功能模块启停表单
+type FeatureModuleForm struct {
+	Enabled bool `json:"enabled"`
+}
+
+// 性能分析类型
+const (
+	ProfileTypeCPU       = "cpu"
+	ProfileTypeHeap      = "heap"
+	ProfileTypeAllocs    = "allocs"
+	ProfileTypeGoroutine = "goroutine"
+	ProfileTypeTrace     = "trace"
+)
+
+// 性能分析采集状态
+const (
+	ProfileRunning   = "running"
+	ProfileCompleted = "completed"
+	ProfileFailed    = "failed"
+)
+
+// ProfileForm 性能分析采集表单
+// Seconds 仅对 cpu、trace 有效，默认 30 秒
+type ProfileForm struct {
+	Type    string `json:"type" validate:"required,in=cpu;heap;allocs;goroutine;trace"`
+	Seconds int    `json:"seconds" validate:"min=0,max=300"`
+}
+
+// ProfileCapture 性能分析采集记录，仅保存在当前实例内存中
+type ProfileCapture struct {
+	ID         uint64     `json:"id"`
+	Type       string     `json:"type"`
+	Seconds    int        `json:"seconds"`
+	Status     string     `json:"status"`
+	FileName   string     `json:"fileName"`
+	FileURL    string     `json:"fileUrl"`
+	FileSize   int64      `json:"fileSize"`
+	Error      string     `json:"error"`
+	CreateBy   uint64     `json:"createBy"`
+	StartTime  time.Time  `json:"startTime"`
+	FinishTime *time.Time `json:"finishTime"`
+}
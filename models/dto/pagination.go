@@ -1,14 +1,17 @@
 package dto
 
 type Pagination struct {
-	Total    int64 `json:"total"`
+	Total    int64 `json:"total"` // 未统计总数（withTotal=false）时为 -1
 	PageNum  int   `json:"pageNum"`
 	PageSize int   `json:"pageSize"`
+	HasNext  bool  `json:"hasNext"`
 }
 
 type PaginationParam struct {
 	PageNum  int `query:"pageNum"`
 	PageSize int `query:"pageSize" validate:"max=128"`
+	// WithTotal 为 false 时不执行 COUNT(*)，多查询一行判断是否有下一页，用于数据量大的列表
+	WithTotal *bool `query:"withTotal"`
 }
 
 func (a *PaginationParam) GetPageNum() int {
@@ -23,3 +26,8 @@ func (a *PaginationParam) GetPageSize() int {
 
 	return pageSize
 }
+
+// CountTotal 是否统计总数，默认统计
+func (a *PaginationParam) CountTotal() bool {
+	return a.WithTotal == nil || *a.WithTotal
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/top-system/light-admin/pkg/apimeta"
+
 // RouteVO 路由视图对象
 type RouteVO struct {
 	Name      string     `json:"name"`               // 路由名称
@@ -26,3 +28,25 @@ type MenuOption struct {
 	Label    string       `json:"label"`
 	Children []MenuOption `json:"children,omitempty"`
 }
+
+// RoutePermVO 路由权限矩阵项（用于权限审计）
+type RoutePermVO struct {
+	Method   string `json:"method"`             // 请求方法
+	Path     string `json:"path"`               // 路由路径
+	Perm     string `json:"perm"`               // 所需权限标识，为空表示登录即可访问
+	Public   bool   `json:"public"`             // 路由是否免登录访问
+	Declared bool   `json:"declared"`           // 路由是否声明了权限
+	MenuName string `json:"menuName,omitempty"` // 定义该权限的菜单按钮名称
+}
+
+// ActionVO 操作描述（用于前端命令面板及命令行工具生成）
+type ActionVO struct {
+	Key        string          `json:"key"`                  // 唯一标识：METHOD PATH
+	Title      string          `json:"title"`                // 操作标题
+	Method     string          `json:"method"`               // 请求方法
+	Path       string          `json:"path"`                 // 路由路径
+	Perm       string          `json:"perm"`                 // 所需权限标识，为空表示登录即可访问
+	Controller string          `json:"controller,omitempty"` // 控制器名称
+	Action     string          `json:"action,omitempty"`     // 控制器方法名称
+	Params     []apimeta.Param `json:"params"`               // 参数描述
+}
@@ -12,6 +12,7 @@ type CurrentUserInfo struct {
 	DeptName        string   `json:"deptName"`
 	CreateTime      DateTime `json:"createTime"`
 	CanSwitchTenant bool     `json:"canSwitchTenant"`
+	TwoFactor       bool     `json:"twoFactorEnabled"`
 	Roles           []string `json:"roles"`
 	Perms           []string `json:"perms"`
 }
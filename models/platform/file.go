@@ -1,7 +1,76 @@
 package platform
 
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
 // FileInfo 文件信息对象
 type FileInfo struct {
-	Name string `json:"name"` // 文件名称
-	URL  string `json:"url"`  // 文件URL
+	Name        string `json:"name"`                  // 文件名称
+	URL         string `json:"url"`                   // 文件URL
+	DownloadURL string `json:"downloadUrl,omitempty"` // 签名下载地址，无需登录，未配置 OSS.SignKey 时为空
+}
+
+// FileSignedDownloadParam 签名下载参数
+type FileSignedDownloadParam struct {
+	FilePath string `query:"filePath"`
+	Expires  int64  `query:"expires"`
+	Sign     string `query:"sign"`
+}
+
+// FileObject 文件存储对象
+// 相同内容（SHA256 相同）的文件只存储一份，通过引用计数管理，计数归零时删除存储文件
+type FileObject struct {
+	ID          uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Hash        string       `gorm:"column:hash;size:64;not null;uniqueIndex:uk_hash" json:"hash"`
+	URL         string       `gorm:"column:url;size:512;not null;index:idx_url" json:"url"`
+	Size        int64        `gorm:"column:size;not null" json:"size"`
+	ContentType string       `gorm:"column:content_type;size:128" json:"contentType"`
+	RefCount    int          `gorm:"column:ref_count;not null;default:1" json:"refCount"`
+	CreateTime  dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime  dto.DateTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+// TableName 指定表名
+func (FileObject) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModulePlatform, "file_object", "t_file_object")
+}
+
+type FileObjects []*FileObject
+
+// FileObjectQueryParam 文件记录查询参数
+type FileObjectQueryParam struct {
+	dto.PaginationParam
+
+	Keywords    string   `query:"keywords"`
+	ContentType string   `query:"contentType"`
+	TagIDs      []uint64 `query:"-"` // 按标签过滤，带有任一标签即匹配
+}
+
+// FileObjectQueryResult 文件记录查询结果
+type FileObjectQueryResult struct {
+	List       FileObjects     `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// StoredEntry 存储后端中的对象
+type StoredEntry struct {
+	URL     string       `json:"url"`
+	Size    int64        `json:"size"`
+	ModTime dto.DateTime `json:"modTime"`
+}
+
+// OrphanReport 孤立文件扫描报告
+// OrphanObjects: 存储中存在但没有登记记录的对象
+// DanglingRecords: 有登记记录但存储中已不存在的对象
+type OrphanReport struct {
+	ScannedObjects  int            `json:"scannedObjects"`
+	ScannedRecords  int            `json:"scannedRecords"`
+	OrphanObjects   []*StoredEntry `json:"orphanObjects"`
+	DanglingRecords FileObjects    `json:"danglingRecords"`
+	DryRun          bool           `json:"dryRun"`
+	ScanTime        dto.DateTime   `json:"scanTime"`
 }
@@ -0,0 +1,24 @@
+package platform
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// StompMessage 发送给离线用户的 STOMP 用户队列消息，用户订阅对应队列后投递并删除
+type StompMessage struct {
+	ID          uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username    string       `gorm:"column:username;size:64;not null;index:idx_stomp_message_username" json:"username"`
+	Destination string       `gorm:"column:destination;size:128;not null" json:"destination"` // 用户队列目标，如 /queue/messages
+	Body        []byte       `gorm:"column:body" json:"body"`
+	CreateTime  dto.DateTime `gorm:"column:create_time;autoCreateTime;index:idx_stomp_message_create_time" json:"createTime"`
+}
+
+// TableName 指定表名
+func (StompMessage) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModulePlatform, "stomp_message", "t_stomp_message")
+}
+
+type StompMessages []*StompMessage
@@ -0,0 +1,57 @@
+package system
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// ApiKeyHeader 机器客户端携带 API Key 的请求头
+const ApiKeyHeader = "X-API-Key"
+
+// ApiKey 个人访问令牌，供脚本、CI 等机器客户端免登录调用接口
+// 只保存密钥的 SHA-256 摘要，明文仅在创建时返回一次；可用权限为授权范围与创建者当前权限的交集
+type ApiKey struct {
+	ID           uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       uint64           `gorm:"column:user_id;not null;index:idx_api_key_user_id" json:"userId"`
+	Name         string           `gorm:"column:name;size:64;not null" json:"name"`
+	Prefix       string           `gorm:"column:prefix;size:16;not null" json:"prefix"` // 密钥前缀，用于识别
+	KeyHash      string           `gorm:"column:key_hash;size:64;not null;uniqueIndex:uk_api_key_hash" json:"-"`
+	Perms        string           `gorm:"column:perms;type:text" json:"-"`      // 英文逗号分隔的授权范围
+	ExpireTime   dto.NullDateTime `gorm:"column:expire_time" json:"expireTime"` // 为空表示永不过期
+	LastUsedTime dto.NullDateTime `gorm:"column:last_used_time" json:"lastUsedTime"`
+	LastUsedIP   string           `gorm:"column:last_used_ip;size:64" json:"lastUsedIp"`
+	CreateTime   dto.DateTime     `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+
+	// 非数据库字段
+	PermList []string `gorm:"-" json:"perms"`
+	Key      string   `gorm:"-" json:"key,omitempty"` // 明文密钥，仅创建时返回
+}
+
+// TableName 指定表名
+func (ApiKey) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "api_key", "t_api_key")
+}
+
+// SplitPerms 解析授权范围
+func (a *ApiKey) SplitPerms() []string {
+	perms := make([]string, 0)
+	for _, perm := range strings.Split(a.Perms, ",") {
+		if perm = strings.TrimSpace(perm); perm != "" {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+type ApiKeys []*ApiKey
+
+// ApiKeyForm 创建 API Key 表单
+type ApiKeyForm struct {
+	Name       string   `json:"name" validate:"required,max=64"`
+	Perms      []string `json:"perms" validate:"required,min=1"`
+	ExpireDays int      `json:"expireDays" validate:"min=0,max=3650"` // 有效天数，0 表示永不过期
+}
@@ -0,0 +1,78 @@
+package system
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// ApiUsage 接口调用统计（按小时、用户、路由汇总）
+type ApiUsage struct {
+	ID               uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	BucketTime       time.Time `gorm:"column:bucket_time;not null;uniqueIndex:uk_api_usage,priority:1" json:"bucketTime"`
+	UserID           uint64    `gorm:"column:user_id;not null;default:0;uniqueIndex:uk_api_usage,priority:2;index:idx_api_usage_user" json:"userId"`
+	Username         string    `gorm:"column:username;size:64;not null;default:'';uniqueIndex:uk_api_usage,priority:3" json:"username"`
+	Method           string    `gorm:"column:method;size:10;not null;uniqueIndex:uk_api_usage,priority:4" json:"method"`
+	Route            string    `gorm:"column:route;size:191;not null;uniqueIndex:uk_api_usage,priority:5" json:"route"`
+	RequestCount     int64     `gorm:"column:request_count;not null;default:0" json:"requestCount"`
+	ErrorCount       int64     `gorm:"column:error_count;not null;default:0" json:"errorCount"`              // 状态码 >= 400
+	ServerErrorCount int64     `gorm:"column:server_error_count;not null;default:0" json:"serverErrorCount"` // 状态码 >= 500
+	DurationMs       int64     `gorm:"column:duration_ms;not null;default:0" json:"durationMs"`              // 累计耗时
+}
+
+// TableName 指定表名
+func (ApiUsage) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "api_usage", "sys_api_usage")
+}
+
+type ApiUsages []*ApiUsage
+
+// ApiUsageQueryParam 接口调用统计查询参数
+type ApiUsageQueryParam struct {
+	dto.PaginationParam
+
+	UserID uint64 `query:"userId"`
+	Route  string `query:"route"`
+	From   string `query:"from"` // 起始时间，如 2024-01-01 或 2024-01-01 08:00:00
+	To     string `query:"to"`
+}
+
+// ApiUsageQueryResult 接口调用统计查询结果
+type ApiUsageQueryResult struct {
+	List       ApiUsages       `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// ApiUsageTopParam 排行查询参数
+// Sort 可选 requests（默认）、errors、errorRate
+type ApiUsageTopParam struct {
+	From  string `query:"from"`
+	To    string `query:"to"`
+	Sort  string `query:"sort"`
+	Limit int    `query:"limit"`
+}
+
+// ApiUsageUserVO 用户调用排行
+type ApiUsageUserVO struct {
+	UserID           uint64  `json:"userId"`
+	Username         string  `json:"username"`
+	RequestCount     int64   `json:"requestCount"`
+	ErrorCount       int64   `json:"errorCount"`
+	ServerErrorCount int64   `json:"serverErrorCount"`
+	ErrorRate        float64 `json:"errorRate"`
+	AvgDurationMs    float64 `json:"avgDurationMs"`
+}
+
+// ApiUsageRouteVO 路由调用排行
+type ApiUsageRouteVO struct {
+	Method           string  `json:"method"`
+	Route            string  `json:"route"`
+	RequestCount     int64   `json:"requestCount"`
+	ErrorCount       int64   `json:"errorCount"`
+	ServerErrorCount int64   `json:"serverErrorCount"`
+	ErrorRate        float64 `json:"errorRate"`
+	AvgDurationMs    float64 `json:"avgDurationMs"`
+}
@@ -0,0 +1,97 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/pkg/policy"
+)
+
+// CasbinRule 访问策略，字段与 casbin 策略 CSV 一致（见 pkg/policy）
+// PType: p-访问策略 g-角色继承
+type CasbinRule struct {
+	ID    uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	PType string `gorm:"column:ptype;size:10;not null;uniqueIndex:idx_casbin_rule,priority:1" json:"ptype"`
+	V0    string `gorm:"column:v0;size:100;not null;uniqueIndex:idx_casbin_rule,priority:2" json:"v0"`
+	V1    string `gorm:"column:v1;size:255;not null;uniqueIndex:idx_casbin_rule,priority:3" json:"v1"`
+	V2    string `gorm:"column:v2;size:100;not null;default:'';uniqueIndex:idx_casbin_rule,priority:4" json:"v2"`
+}
+
+// TableName 指定表名
+func (CasbinRule) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "casbin_rule", "t_casbin_rule")
+}
+
+// ToRule 转换为策略
+func (a CasbinRule) ToRule() policy.Rule {
+	return policy.Rule{PType: a.PType, V0: a.V0, V1: a.V1, V2: a.V2}
+}
+
+type CasbinRules []*CasbinRule
+
+// ToRules 转换为策略列表
+func (a CasbinRules) ToRules() []policy.Rule {
+	rules := make([]policy.Rule, 0, len(a))
+	for _, item := range a {
+		rules = append(rules, item.ToRule())
+	}
+	return rules
+}
+
+// PolicyQueryParam 策略查询参数
+type PolicyQueryParam struct {
+	dto.PaginationParam
+
+	PType string `query:"ptype"`
+	V0    string `query:"v0"` // 主体，模糊匹配
+	V1    string `query:"v1"` // 资源路径或继承的角色，模糊匹配
+}
+
+// PolicyQueryResult 策略查询结果
+type PolicyQueryResult struct {
+	List       CasbinRules     `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// PolicyForm 策略表单
+type PolicyForm struct {
+	PType string `json:"ptype" validate:"required,oneof=p g"`
+	V0    string `json:"v0" validate:"required,max=100"`
+	V1    string `json:"v1" validate:"required,max=255"`
+	V2    string `json:"v2" validate:"max=100"`
+}
+
+// ToRule 转换为策略
+func (a PolicyForm) ToRule() policy.Rule {
+	return policy.Rule{PType: a.PType, V0: a.V0, V1: a.V1, V2: a.V2}
+}
+
+// PolicyImportResult 策略导入结果
+type PolicyImportResult struct {
+	Replace  bool `json:"replace"`
+	Total    int  `json:"total"`
+	Imported int  `json:"imported"`
+	Skipped  int  `json:"skipped"`
+}
+
+// PolicyTestForm 策略测试参数：用户能否以 Method 访问 Path
+type PolicyTestForm struct {
+	Username string `json:"username" validate:"required"`
+	Method   string `json:"method" validate:"required"`
+	Path     string `json:"path" validate:"required"`
+}
+
+// PolicyTestResult 策略测试结果
+// Roles 为用户在系统中分配的角色编码，与 g 策略一起参与匹配；超级管理员不受策略限制；
+// Enforced 为 false 表示当前未启用按策略拦截，结果仅供参考
+type PolicyTestResult struct {
+	policy.Result
+
+	Username   string   `json:"username"`
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Roles      []string `json:"roles"`
+	SuperAdmin bool     `json:"superAdmin"`
+	Enforced   bool     `json:"enforced"`
+}
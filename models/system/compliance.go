@@ -0,0 +1,55 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 合规报表生成状态
+const (
+	ComplianceReportPending   = "pending"
+	ComplianceReportRunning   = "running"
+	ComplianceReportCompleted = "completed"
+	ComplianceReportFailed    = "failed"
+)
+
+// ComplianceReport 安全合规报表生成记录，报表文件通过文件服务存储
+type ComplianceReport struct {
+	ID         uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Format     string           `gorm:"column:format;size:10;not null" json:"format"`
+	Status     string           `gorm:"column:status;size:20;not null;index:idx_compliance_report_status" json:"status"`
+	FileName   string           `gorm:"column:file_name;size:255" json:"fileName"`
+	FileURL    string           `gorm:"column:file_url;size:500" json:"-"`
+	FileSize   int64            `gorm:"column:file_size" json:"fileSize"`
+	Error      string           `gorm:"column:error;size:500" json:"error"`
+	CreateBy   uint64           `gorm:"column:create_by;index:idx_compliance_report_create_by" json:"createBy"`
+	CreateTime dto.DateTime     `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	FinishTime dto.NullDateTime `gorm:"column:finish_time" json:"finishTime"`
+}
+
+// TableName 指定表名
+func (ComplianceReport) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "compliance_report", "sys_compliance_report")
+}
+
+type ComplianceReports []*ComplianceReport
+
+// ComplianceReportForm 生成报表参数，Format 可选 xlsx（默认）、pdf、csv
+type ComplianceReportForm struct {
+	Format string `json:"format"`
+}
+
+// ComplianceReportQueryParam 报表记录查询参数
+type ComplianceReportQueryParam struct {
+	dto.PaginationParam
+
+	Status string `query:"status"`
+}
+
+// ComplianceReportQueryResult 报表记录查询结果
+type ComplianceReportQueryResult struct {
+	List       ComplianceReports `json:"list"`
+	Pagination *dto.Pagination   `json:"pagination"`
+}
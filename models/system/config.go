@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -19,8 +22,8 @@ type Config struct {
 }
 
 // TableName 指定表名
-func (Config) TableName() string {
-	return "t_config"
+func (Config) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "config", "t_config")
 }
 
 type Configs []*Config
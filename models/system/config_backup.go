@@ -0,0 +1,113 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// ConfigBundleVersion 配置备份文件格式版本
+const ConfigBundleVersion = 1
+
+// 配置备份触发方式
+const (
+	ConfigBackupManual    = "manual"    // 手动备份
+	ConfigBackupScheduled = "scheduled" // 定时备份
+)
+
+// 配置差异中的数据类型
+const (
+	ConfigBackupKindMenu     = "menu"
+	ConfigBackupKindRole     = "role"
+	ConfigBackupKindDict     = "dict"
+	ConfigBackupKindDictItem = "dict_item"
+	ConfigBackupKindConfig   = "config"
+)
+
+// 配置差异动作
+const (
+	ConfigDiffAdded   = "added"   // 备份中有、当前没有，恢复时新建
+	ConfigDiffUpdated = "updated" // 两边都有但内容不同，恢复时覆盖
+	ConfigDiffRemoved = "removed" // 当前有、备份中没有，仅 prune 时删除
+)
+
+// ConfigBackup 配置备份记录，备份文件通过文件服务存储
+type ConfigBackup struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Trigger    string       `gorm:"column:trigger_type;size:20;not null" json:"trigger"`
+	FileName   string       `gorm:"column:file_name;size:255;not null" json:"fileName"`
+	FileURL    string       `gorm:"column:file_url;size:500;not null" json:"-"`
+	FileSize   int64        `gorm:"column:file_size" json:"fileSize"`
+	Checksum   string       `gorm:"column:checksum;size:64;index:idx_config_backup_checksum" json:"checksum"` // 备份内容 SHA256，不含生成时间
+	Menus      int          `gorm:"column:menus" json:"menus"`
+	Roles      int          `gorm:"column:roles" json:"roles"`
+	Dicts      int          `gorm:"column:dicts" json:"dicts"`
+	DictItems  int          `gorm:"column:dict_items" json:"dictItems"`
+	Configs    int          `gorm:"column:configs" json:"configs"`
+	Remark     string       `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy   uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime dto.DateTime `gorm:"column:create_time;autoCreateTime;index:idx_config_backup_create_time" json:"createTime"`
+}
+
+// TableName 指定表名
+func (ConfigBackup) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "config_backup", "sys_config_backup")
+}
+
+type ConfigBackups []*ConfigBackup
+
+// ConfigBackupForm 手动备份参数
+type ConfigBackupForm struct {
+	Remark string `json:"remark" validate:"max=255"`
+}
+
+// ConfigBackupQueryParam 备份记录查询参数
+type ConfigBackupQueryParam struct {
+	dto.PaginationParam
+
+	Trigger string `query:"trigger"`
+}
+
+// ConfigBackupQueryResult 备份记录查询结果
+type ConfigBackupQueryResult struct {
+	List       ConfigBackups   `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// ConfigBundle 配置备份文件内容
+// 菜单按 ID 恢复（角色菜单、菜单层级均引用菜单 ID），其余数据按业务编码恢复：
+// 角色按 code，字典按 dictCode，字典项按 dictCode + value，系统配置按 configKey
+type ConfigBundle struct {
+	Version   int          `json:"version"`
+	CreatedAt dto.DateTime `json:"createdAt"`
+	Menus     Menus        `json:"menus"`
+	Roles     Roles        `json:"roles"` // MenuIds 为角色关联的菜单
+	Dicts     Dicts        `json:"dicts"`
+	DictItems DictItems    `json:"dictItems"`
+	Configs   Configs      `json:"configs"`
+}
+
+// ConfigRestoreParam 恢复参数
+type ConfigRestoreParam struct {
+	Prune bool `query:"prune"` // 是否删除备份中不存在的数据
+}
+
+// ConfigDiffItem 单条差异
+type ConfigDiffItem struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+// ConfigDiff 备份与当前配置的差异
+// Prune 为 false 时 Removed 中的数据在恢复时保留
+type ConfigDiff struct {
+	BackupID uint64            `json:"backupId"`
+	Prune    bool              `json:"prune"`
+	Added    int               `json:"added"`
+	Updated  int               `json:"updated"`
+	Removed  int               `json:"removed"`
+	Items    []*ConfigDiffItem `json:"items"`
+}
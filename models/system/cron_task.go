@@ -0,0 +1,115 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// CronTask 持久化的定时任务，重启后重新注册到定时任务调度器
+// Handler 非空为运行时创建的任务，按处理器名称查找执行函数；为空表示对内置任务执行时间、启用状态的修改
+type CronTask struct {
+	ID          uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string           `gorm:"column:name;size:64;not null;uniqueIndex:uk_cron_job_name" json:"name"`
+	Spec        string           `gorm:"column:spec;size:64;not null" json:"spec"`
+	Handler     string           `gorm:"column:handler;size:64" json:"handler"`
+	Params      database.JSONB   `gorm:"column:params" json:"params"`
+	Enable      bool             `gorm:"column:enable;not null" json:"enable"`
+	Remark      string           `gorm:"column:remark;size:200" json:"remark"`
+	LastRunTime dto.NullDateTime `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastError   string           `gorm:"column:last_error;size:500" json:"lastError"`
+	CreateBy    uint64           `gorm:"column:create_by" json:"createBy"`
+	UpdateBy    uint64           `gorm:"column:update_by" json:"updateBy"`
+	CreateTime  dto.DateTime     `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime  dto.DateTime     `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+// TableName 指定表名
+func (CronTask) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "cron_job", "t_cron_job")
+}
+
+type CronTasks []*CronTask
+
+// 定时任务执行结果
+const (
+	CronRunSuccess  = "success"
+	CronRunFailed   = "failed"
+	CronRunPanicked = "panicked"
+)
+
+// CronTaskRecord 定时任务执行记录，每次执行（包括立即执行）写入一条，由数据保留策略 cron_record 清理
+type CronTaskRecord struct {
+	ID            uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TaskName      string       `gorm:"column:task_name;size:100;not null;index:idx_cron_task_record_name" json:"taskName"`
+	CorrelationID string       `gorm:"column:correlation_id;size:36" json:"correlationId"` // 与日志中的 Cid 对应
+	Manual        bool         `gorm:"column:manual;not null" json:"manual"`               // 通过立即执行触发
+	StartTime     dto.DateTime `gorm:"column:start_time;not null;index:idx_cron_task_record_start" json:"startTime"`
+	Duration      int64        `gorm:"column:duration;not null" json:"duration"` // 毫秒
+	Status        string       `gorm:"column:status;size:20;not null" json:"status"`
+	Error         string       `gorm:"column:error;size:500" json:"error"`
+	Panic         string       `gorm:"column:panic;size:500" json:"panic"`
+	Result        string       `gorm:"column:result;size:500" json:"result"` // 任务报告的执行摘要，如清理的行数
+}
+
+// TableName 指定表名
+func (CronTaskRecord) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "cron_task_record", "t_cron_task_record")
+}
+
+type CronTaskRecords []*CronTaskRecord
+
+// CronTaskHistoryParam 执行记录查询参数，Limit 默认 20，最大 100
+type CronTaskHistoryParam struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// CronTaskVO 定时任务，合并调度器中的状态与持久化的配置
+type CronTaskVO struct {
+	Name        string           `json:"name"`
+	Spec        string           `json:"spec"`
+	Enable      bool             `json:"enable"`
+	Builtin     bool             `json:"builtin"` // 代码中注册的内置任务，不能删除，不能修改处理器和参数
+	Handler     string           `json:"handler"`
+	Params      database.JSONB   `json:"params"`
+	Remark      string           `json:"remark"`
+	NextRunTime dto.NullDateTime `json:"nextRunTime"`
+	PrevRunTime dto.NullDateTime `json:"prevRunTime"` // 本次启动以来最近一次执行时间
+	LastRunTime dto.NullDateTime `json:"lastRunTime"` // 运行时创建的任务最近一次执行时间，重启后保留
+	LastError   string           `json:"lastError"`
+	LastRun     *CronTaskRecord  `json:"lastRun"` // 最近一次执行记录（包括内置任务），没有执行过或记录已清理时为 null
+
+	Timeout       int64 `json:"timeout"`       // 最长执行时间（毫秒），超时后取消任务的 context，0 表示不限制
+	SkipIfRunning bool  `json:"skipIfRunning"` // 上一次执行尚未结束时跳过本次执行
+	Running       bool  `json:"running"`       // 本实例中正在执行
+	LastDuration  int64 `json:"lastDuration"`  // 本次启动以来最近一次执行的耗时（毫秒）
+}
+
+// CronTaskForm 创建定时任务，Spec 为 6 位（含秒）cron 表达式，Handler 取值见 /api/v1/crontab/handlers
+type CronTaskForm struct {
+	Name    string         `json:"name" validate:"required,max=64"`
+	Spec    string         `json:"spec" validate:"required,max=64"`
+	Handler string         `json:"handler" validate:"required,max=64"`
+	Params  database.JSONB `json:"params"`
+	Enable  *bool          `json:"enable"` // 不填时默认启用
+	Remark  string         `json:"remark" validate:"max=200"`
+}
+
+// CronTaskUpdateForm 修改定时任务，内置任务只能修改执行时间，Params、Remark 会被忽略
+type CronTaskUpdateForm struct {
+	Spec   string         `json:"spec" validate:"required,max=64"`
+	Params database.JSONB `json:"params"`
+	Remark string         `json:"remark" validate:"max=200"`
+}
+
+// CronTaskStatusForm 启用或停用定时任务
+type CronTaskStatusForm struct {
+	Enable bool `json:"enable"`
+}
+
+// CronHandlerVO 可用于创建定时任务的处理器
+type CronHandlerVO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
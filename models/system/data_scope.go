@@ -0,0 +1,38 @@
+package system
+
+// 角色数据权限范围，用户有多个角色时取并集
+// 未设置（0）的角色视为全部数据，兼容增加数据权限之前创建的角色
+const (
+	DataScopeAll          = 1 // 全部数据
+	DataScopeDeptAndChild = 2 // 本部门及子部门
+	DataScopeDept         = 3 // 本部门
+	DataScopeSelf         = 4 // 仅本人
+	DataScopeCustom       = 5 // 自定义部门，见 RoleDept
+)
+
+// DataScope 当前身份可访问的数据范围，由控制器根据角色计算后设置到查询参数中
+// nil 或 All 为 true 时不限制；否则只能访问 DeptIDs 部门内用户的数据以及 UserID 本人的数据
+type DataScope struct {
+	All     bool
+	DeptIDs []uint64
+	UserID  uint64
+}
+
+// HasDept 部门是否在数据范围内
+func (s *DataScope) HasDept(deptID uint64) bool {
+	if s == nil || s.All {
+		return true
+	}
+
+	for _, id := range s.DeptIDs {
+		if id == deptID {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidDataScope 是否为有效的数据权限范围
+func ValidDataScope(v int) bool {
+	return v >= 0 && v <= DataScopeCustom
+}
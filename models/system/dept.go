@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -22,8 +25,8 @@ type Dept struct {
 }
 
 // TableName 指定表名
-func (Dept) TableName() string {
-	return "t_dept"
+func (Dept) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "dept", "t_dept")
 }
 
 type Depts []*Dept
@@ -34,6 +37,7 @@ type DeptQueryParam struct {
 
 	Keywords string `query:"keywords"`
 	Status   *int   `query:"status"`
+	Tree     *bool  `query:"tree"` // 是否返回树形结构，默认 true
 }
 
 type DeptQueryResult struct {
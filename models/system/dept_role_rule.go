@@ -0,0 +1,85 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// DeptRoleRule 部门默认角色规则
+// 用户创建或调入部门时自动授予规则映射的角色，调离时撤销原部门映射且新部门未映射的角色；
+// IncludeChildren 为 true 时规则同样适用于子部门
+type DeptRoleRule struct {
+	ID              uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeptID          uint64       `gorm:"column:dept_id;not null;uniqueIndex:uk_dept_role_rule,priority:1" json:"deptId"`
+	RoleID          uint64       `gorm:"column:role_id;not null;uniqueIndex:uk_dept_role_rule,priority:2" json:"roleId"`
+	IncludeChildren bool         `gorm:"column:include_children;not null;default:false" json:"includeChildren"`
+	Remark          string       `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy        uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime      dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+
+	// 非数据库字段
+	DeptName string `gorm:"-" json:"deptName,omitempty"`
+	RoleName string `gorm:"-" json:"roleName,omitempty"`
+}
+
+// TableName 指定表名
+func (DeptRoleRule) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "dept_role_rule", "t_dept_role_rule")
+}
+
+type DeptRoleRules []*DeptRoleRule
+
+// DeptRoleRuleQueryParam 规则查询参数
+type DeptRoleRuleQueryParam struct {
+	dto.PaginationParam
+
+	DeptID uint64 `query:"deptId"`
+	RoleID uint64 `query:"roleId"`
+}
+
+// DeptRoleRuleQueryResult 规则查询结果
+type DeptRoleRuleQueryResult struct {
+	List       DeptRoleRules   `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// DeptRoleRuleForm 规则表单
+type DeptRoleRuleForm struct {
+	DeptID          uint64 `json:"deptId" validate:"required"`
+	RoleID          uint64 `json:"roleId" validate:"required"`
+	IncludeChildren bool   `json:"includeChildren"`
+	Remark          string `json:"remark" validate:"max=255"`
+}
+
+// DeptRolePreviewForm 预览将用户调入部门时的角色变更（不实际执行）
+type DeptRolePreviewForm struct {
+	UserIDs []uint64 `json:"userIds"`
+	DeptID  uint64   `json:"deptId"`
+}
+
+// DeptRoleApplyForm 按用户当前所在部门补齐默认角色（用于新增规则后同步已有用户）
+// UserIDs 为空时处理规则覆盖的全部部门的用户
+type DeptRoleApplyForm struct {
+	UserIDs []uint64 `json:"userIds"`
+	DryRun  bool     `json:"dryRun"`
+}
+
+// DeptRoleChange 单个用户的角色变更
+type DeptRoleChange struct {
+	UserID     uint64       `json:"userId"`
+	Username   string       `json:"username"`
+	FromDeptID uint64       `json:"fromDeptId"`
+	ToDeptID   uint64       `json:"toDeptId"`
+	Grant      []RoleOption `json:"grant"`
+	Revoke     []RoleOption `json:"revoke"`
+}
+
+// DeptRolePlan 部门默认角色变更计划，DryRun 为 true 时未实际执行
+type DeptRolePlan struct {
+	DryRun  bool              `json:"dryRun"`
+	Granted int               `json:"granted"`
+	Revoked int               `json:"revoked"`
+	Changes []*DeptRoleChange `json:"changes"`
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -19,8 +22,8 @@ type Dict struct {
 }
 
 // TableName 指定表名
-func (Dict) TableName() string {
-	return "t_dict"
+func (Dict) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "dict", "t_dict")
 }
 
 type Dicts []*Dict
@@ -87,3 +90,15 @@ func (list Dicts) ToPageVOList() []*DictPageVO {
 	}
 	return result
 }
+
+// DictDeltaVO 字典增量同步结果
+// Full 为 true 时 Dicts、Items 为全部未删除的数据，客户端应整体替换；
+// 否则只包含 version 之后变更的数据，已删除的记录通过 RemovedDictIDs、RemovedItemIDs 返回
+type DictDeltaVO struct {
+	Version        string    `json:"version"` // 下次同步时携带的版本号
+	Full           bool      `json:"full"`
+	Dicts          Dicts     `json:"dicts"`
+	Items          DictItems `json:"items"`
+	RemovedDictIDs []uint64  `json:"removedDictIds"`
+	RemovedItemIDs []uint64  `json:"removedItemIds"`
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -22,8 +25,8 @@ type DictItem struct {
 }
 
 // TableName 指定表名
-func (DictItem) TableName() string {
-	return "t_dict_item"
+func (DictItem) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "dict_item", "t_dict_item")
 }
 
 type DictItems []*DictItem
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 通讯录联系方式的字段权限，没有对应权限时不返回该字段，也不能按该字段搜索
+const (
+	DirectoryFieldMobilePerm = "sys:directory:mobile"
+	DirectoryFieldEmailPerm  = "sys:directory:email"
+)
+
+// DirectoryQueryParam 通讯录人员查询参数
+// DeptID 包含子部门，Keywords 匹配用户名、昵称以及有权限查看的联系方式
+type DirectoryQueryParam struct {
+	dto.PaginationParam
+
+	Keywords string `query:"keywords"`
+	DeptID   uint64 `query:"deptId"`
+}
+
+// DirectoryFields 当前身份可查看的联系方式字段
+type DirectoryFields struct {
+	Mobile bool
+	Email  bool
+}
+
+// DirectoryDeptVO 组织架构节点，UserCount 为本部门及子部门的在职人数
+type DirectoryDeptVO struct {
+	ID        uint64             `json:"id"`
+	Name      string             `json:"name"`
+	ParentID  uint64             `json:"parentId"`
+	UserCount int64              `json:"userCount"`
+	Children  []*DirectoryDeptVO `json:"children,omitempty"`
+}
+
+// DirectoryUserVO 通讯录人员，Name 优先取昵称
+type DirectoryUserVO struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Gender   int    `json:"gender"`
+	Avatar   string `json:"avatar"`
+	DeptID   uint64 `json:"deptId"`
+	DeptName string `json:"deptName"`
+	Mobile   string `json:"mobile,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// DirectoryUserQueryResult 通讯录人员查询结果
+type DirectoryUserQueryResult struct {
+	List       []*DirectoryUserVO `json:"list"`
+	Pagination *dto.Pagination    `json:"pagination"`
+}
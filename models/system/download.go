@@ -1,8 +1,12 @@
 package system
 
 import (
+	"mime/multipart"
 	"time"
 
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -14,24 +18,46 @@ type DownloadTask struct {
 	Hash          string       `gorm:"column:hash;size:100;index" json:"hash"`
 	Name          string       `gorm:"column:name;size:500" json:"name"`
 	URL           string       `gorm:"column:url;type:text" json:"url"`
-	Downloader    string       `gorm:"column:downloader;size:50;not null" json:"downloader"` // aria2 / qbittorrent
+	Downloader    string       `gorm:"column:downloader;size:50;not null" json:"downloader"` // aria2 / qbittorrent / sabnzbd / native
 	Status        string       `gorm:"column:status;size:50;not null;index" json:"status"`
 	Total         int64        `gorm:"column:total;default:0" json:"total"`
 	Downloaded    int64        `gorm:"column:downloaded;default:0" json:"downloaded"`
 	DownloadSpeed int64        `gorm:"column:download_speed;default:0" json:"downloadSpeed"`
 	Uploaded      int64        `gorm:"column:uploaded;default:0" json:"uploaded"`
 	UploadSpeed   int64        `gorm:"column:upload_speed;default:0" json:"uploadSpeed"`
+	DownloadLimit int64        `gorm:"column:download_limit;default:0" json:"downloadLimit"` // 下载限速（KB/s），0 表示不限速
+	UploadLimit   int64        `gorm:"column:upload_limit;default:0" json:"uploadLimit"`     // 上传限速（KB/s），0 表示不限速
 	SavePath      string       `gorm:"column:save_path;size:500" json:"savePath"`
 	ErrorMessage  string       `gorm:"column:error_message;type:text" json:"errorMessage"`
 	OwnerID       uint64       `gorm:"column:owner_id;index" json:"ownerId"`
 	CreatedAt     time.Time    `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
 	UpdatedAt     time.Time    `gorm:"column:updated_at;autoUpdateTime" json:"updatedAt"`
 	DeletedAt     dto.DateTime `gorm:"column:deleted_at;index" json:"-"`
+
+	// 下载完成后的文件转移，见 DownloadTransferStatus*；转移到目录时 TransferPath 为目标目录（同时更新 SavePath），
+	// 上传到文件服务时 TransferFiles 为各文件的地址（[]DownloadTransferFile）
+	TransferStatus string         `gorm:"column:transfer_status;size:20" json:"transferStatus"`
+	TransferPath   string         `gorm:"column:transfer_path;size:500" json:"transferPath"`
+	TransferFiles  database.JSONB `gorm:"column:transfer_files" json:"transferFiles"`
+	TransferError  string         `gorm:"column:transfer_error;type:text" json:"transferError"`
+}
+
+// 下载文件的转移状态，未启用转移时为空
+const (
+	DownloadTransferStatusPending     = "pending"     // 已提交，等待执行
+	DownloadTransferStatusTransferred = "transferred" // 文件已转移，临时目录已清理
+	DownloadTransferStatusFailed      = "failed"      // 转移失败，文件保留在下载器的临时目录中
+)
+
+// DownloadTransferFile 上传到文件服务的文件
+type DownloadTransferFile struct {
+	Path string `json:"path"` // 相对于下载任务保存目录的路径
+	URL  string `json:"url"`
 }
 
 // TableName 指定表名
-func (DownloadTask) TableName() string {
-	return "sys_download_tasks"
+func (DownloadTask) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleQueue, "download_tasks", "sys_download_tasks")
 }
 
 type DownloadTasks []*DownloadTask
@@ -41,11 +67,14 @@ type DownloadTaskQueryParam struct {
 	dto.PaginationParam
 	dto.OrderParam
 
-	Keywords       string `query:"keywords"`
-	Status         string `query:"status"`
-	Downloader     string `query:"downloader"`
-	CreateTimeFrom string `query:"createdAt[0]"`
-	CreateTimeTo   string `query:"createdAt[1]"`
+	Keywords       string   `query:"keywords"`
+	Status         string   `query:"status"`
+	Downloader     string   `query:"downloader"`
+	CreateTimeFrom string   `query:"createdAt[0]"`
+	CreateTimeTo   string   `query:"createdAt[1]"`
+	TagIDs         []uint64 `query:"-"` // 按标签过滤，带有任一标签即匹配
+
+	DataScope *DataScope `query:"-"` // 按创建人所在部门过滤
 }
 
 // DownloadTaskQueryResult 下载任务查询结果
@@ -68,11 +97,17 @@ type DownloadTaskPageVO struct {
 	DownloadSpeed int64   `json:"downloadSpeed"`
 	Uploaded      int64   `json:"uploaded"`
 	UploadSpeed   int64   `json:"uploadSpeed"`
+	DownloadLimit int64   `json:"downloadLimit"`
+	UploadLimit   int64   `json:"uploadLimit"`
 	SavePath      string  `json:"savePath"`
 	ErrorMessage  string  `json:"errorMessage"`
 	Progress      float64 `json:"progress"`
 	CreatedAt     string  `json:"createdAt"`
 	UpdatedAt     string  `json:"updatedAt"`
+
+	TransferStatus string `json:"transferStatus"`
+	TransferPath   string `json:"transferPath"`
+	TransferError  string `json:"transferError"`
 }
 
 // ToPageVOList 转换为分页视图对象列表
@@ -96,11 +131,17 @@ func (list DownloadTasks) ToPageVOList() []*DownloadTaskPageVO {
 			DownloadSpeed: item.DownloadSpeed,
 			Uploaded:      item.Uploaded,
 			UploadSpeed:   item.UploadSpeed,
+			DownloadLimit: item.DownloadLimit,
+			UploadLimit:   item.UploadLimit,
 			SavePath:      item.SavePath,
 			ErrorMessage:  item.ErrorMessage,
 			Progress:      progress,
 			CreatedAt:     item.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:     item.UpdatedAt.Format("2006-01-02 15:04:05"),
+
+			TransferStatus: item.TransferStatus,
+			TransferPath:   item.TransferPath,
+			TransferError:  item.TransferError,
 		})
 	}
 	return result
@@ -110,22 +151,106 @@ func (list DownloadTasks) ToPageVOList() []*DownloadTaskPageVO {
 type DownloadTaskStatsVO struct {
 	DownloadingCount int64 `json:"downloadingCount"`
 	SeedingCount     int64 `json:"seedingCount"`
+	PausedCount      int64 `json:"pausedCount"`
 	CompletedCount   int64 `json:"completedCount"`
 	ErrorCount       int64 `json:"errorCount"`
 	TotalCount       int64 `json:"totalCount"`
 }
 
+// DownloadStatusCount 下载器各状态的任务数量，用于导出指标
+type DownloadStatusCount struct {
+	Downloader string
+	Status     string
+	Count      int64
+}
+
+// 下载任务推送事件类型（/topic/downloads）
+const (
+	DownloadEventSnapshot  = "snapshot"  // 订阅时推送：统计信息与全部活跃任务
+	DownloadEventUpdated   = "updated"   // 任务新建或状态变化
+	DownloadEventRemoved   = "removed"   // 任务已删除
+	DownloadEventProgress  = "progress"  // 定时推送到所有者的 /user/queue/downloads：活跃任务的实时进度与速度
+	DownloadEventCompleted = "completed" // 推送到所有者的 /user/queue/downloads：任务下载完成（或开始做种）
+	DownloadEventFailed    = "failed"    // 推送到所有者的 /user/queue/downloads：任务出错
+)
+
+// DownloadTaskEvent 下载任务推送消息
+type DownloadTaskEvent struct {
+	Type      string                `json:"type"`
+	Stats     *DownloadTaskStatsVO  `json:"stats,omitempty"`
+	Tasks     []*DownloadTaskPageVO `json:"tasks,omitempty"`
+	IDs       []uint64              `json:"ids,omitempty"` // removed 时的任务ID
+	Timestamp int64                 `json:"timestamp"`
+}
+
+// 下载器健康状态推送事件类型（/topic/downloaders/health）
+const (
+	DownloaderHealthEventSnapshot = "snapshot" // 订阅时推送：全部下载器最近一次检查的结果
+	DownloaderHealthEventChanged  = "changed"  // 下载器变为可用或不可用
+)
+
+// DownloaderHealthVO 下载器最近一次健康检查的结果
+type DownloaderHealthVO struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Version   string `json:"version,omitempty"`
+	Latency   int64  `json:"latency"` // Test 耗时（毫秒）
+	Error     string `json:"error,omitempty"`
+	CheckedAt string `json:"checkedAt"`
+	ChangedAt string `json:"changedAt"` // 最近一次变为可用或不可用的时间
+}
+
+// DownloaderHealthEvent 下载器健康状态推送消息
+type DownloaderHealthEvent struct {
+	Type        string                `json:"type"`
+	Downloaders []*DownloaderHealthVO `json:"downloaders"`
+	Timestamp   int64                 `json:"timestamp"`
+}
+
 // DownloadTaskCreateForm 创建下载任务表单
+// 也可以 multipart/form-data 提交，用 file 上传 .torrent / .metalink 文件代替 url，options 为 JSON 字符串
 type DownloadTaskCreateForm struct {
-	URL        string                 `json:"url" validate:"required"`
-	Downloader string                 `json:"downloader"` // 可选，不填则使用默认下载器
+	URL string `json:"url" form:"url" validate:"required_without=File"`
+	// 上传的 .torrent / .metalink 文件，与 url 二选一，需要下载器支持（aria2、qBittorrent 仅支持 .torrent）
+	File       *multipart.FileHeader  `json:"-" form:"file" swaggerignore:"true"`
+	Downloader string                 `json:"downloader" form:"downloader"` // 可选，不填则使用默认下载器
 	Options    map[string]interface{} `json:"options"`
+	DryRun     bool                   `json:"dryRun" form:"dryRun"` // 仅预检，不创建任务，也可通过查询参数 dryRun=true 指定
+	// 队列优先级，越大越先执行，需要 sys:download:priority 权限，队列调度策略为 priority 时生效
+	Priority int `json:"priority" form:"priority" validate:"min=0,max=100"`
+	// 计划开始时间，为空或已过去时立即开始，最晚 30 天后，用于把大文件安排在闲时下载
+	StartAt dto.DateTime `json:"startAt" form:"startAt"`
+}
+
+// DownloadDryRunVO 创建下载任务预检结果
+type DownloadDryRunVO struct {
+	URL         string                  `json:"url"`
+	Scheme      string                  `json:"scheme"` // 上传文件时为 torrent 或 metalink
+	Downloader  string                  `json:"downloader"`
+	Name        string                  `json:"name"`
+	Hash        string                  `json:"hash"`        // 磁力链接的 info hash
+	Size        int64                   `json:"size"`        // 未知时为 0
+	ContentType string                  `json:"contentType"` // HTTP 下载的 Content-Type
+	Trackers    int                     `json:"trackers"`
+	Quota       *DownloadQuotaVO        `json:"quota"`
+	Checks      []DownloadDryRunCheckVO `json:"checks"`
+	WouldCreate bool                    `json:"wouldCreate"` // 所有检查通过，实际提交时会创建任务
+}
+
+// DownloadDryRunCheckVO 单项预检结果，Skipped 表示无法检查（如下载目录不在本机），不影响 WouldCreate
+type DownloadDryRunCheckVO struct {
+	Name    string `json:"name"` // url 或 file, queue, downloader, quota, disk, probe
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped"`
+	Message string `json:"message"`
 }
 
 // DownloadTaskDetailVO 下载任务详情视图对象
 type DownloadTaskDetailVO struct {
 	DownloadTaskPageVO
-	Files []DownloadTaskFileVO `json:"files"`
+	Files         []DownloadTaskFileVO   `json:"files"`
+	Media         []*DownloadMediaVO     `json:"media"`         // 已提取的音视频元数据与缩略图
+	TransferFiles []DownloadTransferFile `json:"transferFiles"` // 上传到文件服务的文件
 }
 
 // DownloadTaskFileVO 下载任务文件视图对象
@@ -135,6 +260,40 @@ type DownloadTaskFileVO struct {
 	Size     int64   `json:"size"`
 	Progress float64 `json:"progress"`
 	Selected bool    `json:"selected"`
+	Archive  bool    `json:"archive"` // zip/tar 压缩包，下载完成后可以浏览、解压
+}
+
+// DownloadArchiveQueryParam 浏览压缩包参数，Path 为相对任务保存目录的路径，与文件列表中的 name 一致
+type DownloadArchiveQueryParam struct {
+	Path string `query:"path"`
+}
+
+// DownloadArchiveEntryVO 压缩包内的文件或目录
+type DownloadArchiveEntryVO struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Dir      bool   `json:"dir"`
+	Modified string `json:"modified"`
+}
+
+// DownloadArchiveVO 压缩包内容
+type DownloadArchiveVO struct {
+	Path    string                    `json:"path"`
+	Entries []*DownloadArchiveEntryVO `json:"entries"`
+}
+
+// DownloadArchiveExtractForm 解压表单，Entries 为空时解压全部，选中目录时包含其下所有文件
+// Dst 为相对任务保存目录的解压目录，默认为压缩包所在目录下与压缩包同名（去掉扩展名）的目录
+type DownloadArchiveExtractForm struct {
+	Path    string   `json:"path" validate:"required"`
+	Entries []string `json:"entries"`
+	Dst     string   `json:"dst"`
+}
+
+// DownloadArchiveExtractVO 解压任务，进度通过队列任务查询
+type DownloadArchiveExtractVO struct {
+	QueueTaskID uint64 `json:"queueTaskId"`
+	Dst         string `json:"dst"`
 }
 
 // SetFileDownloadForm 设置文件下载表单
@@ -147,3 +306,43 @@ type SetFileDownloadItem struct {
 	Index    int  `json:"index"`
 	Download bool `json:"download"`
 }
+
+// DownloadSpeedLimitForm 设置任务限速表单，单位 KB/s，0 表示不限速
+type DownloadSpeedLimitForm struct {
+	DownloadLimit int64 `json:"downloadLimit" validate:"min=0"`
+	UploadLimit   int64 `json:"uploadLimit" validate:"min=0"`
+}
+
+// DownloadSessionVO 下载器会话
+type DownloadSessionVO struct {
+	Downloader string `json:"downloader"`
+	ID         string `json:"id"`
+	File       string `json:"file"` // 下载器所在机器上的会话文件，未配置 --save-session 时为空
+}
+
+// DownloadSessionReconcileForm 会话对账参数，DryRun 为 true 时只返回对账结果，不导入任务
+type DownloadSessionReconcileForm struct {
+	DryRun bool `json:"dryRun"`
+}
+
+// DownloadSessionTaskVO 下载器中存在、下载任务表中缺失的任务
+type DownloadSessionTaskVO struct {
+	ID         uint64 `json:"id,omitempty"` // 导入后的下载任务ID，DryRun 时为 0
+	TaskID     string `json:"taskId"`
+	Hash       string `json:"hash"`
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Status     string `json:"status"`
+	Total      int64  `json:"total"`
+	Downloaded int64  `json:"downloaded"`
+}
+
+// DownloadSessionReconcileVO 会话对账结果
+// Imported 为下载器中存在、任务表中缺失的任务；Missing 为任务表中仍处于活跃状态、下载器中已不存在的任务，只报告不修改
+type DownloadSessionReconcileVO struct {
+	Downloader string                   `json:"downloader"`
+	DryRun     bool                     `json:"dryRun"`
+	Matched    int                      `json:"matched"`
+	Imported   []*DownloadSessionTaskVO `json:"imported"`
+	Missing    DownloadTasks            `json:"missing"`
+}
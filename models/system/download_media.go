@@ -0,0 +1,92 @@
+package system
+
+import (
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// DownloadMedia 下载任务中音视频文件的元数据与缩略图，下载完成后由队列任务通过 ffprobe/ffmpeg 提取
+// 重新提取时整体替换同一任务的记录
+type DownloadMedia struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	DownloadTaskID uint64    `gorm:"column:download_task_id;not null;index" json:"downloadTaskId"`
+	Path           string    `gorm:"column:path;size:500;not null" json:"path"`      // 相对任务保存目录的路径，与文件列表中的 name 一致
+	Kind           string    `gorm:"column:kind;size:10;not null;index" json:"kind"` // video / audio
+	Format         string    `gorm:"column:format;size:100" json:"format"`           // 容器格式
+	Duration       float64   `gorm:"column:duration;default:0" json:"duration"`      // 时长（秒）
+	BitRate        int64     `gorm:"column:bit_rate;default:0" json:"bitRate"`
+	Width          int       `gorm:"column:width;default:0" json:"width"`
+	Height         int       `gorm:"column:height;default:0" json:"height"`
+	VideoCodec     string    `gorm:"column:video_codec;size:50" json:"videoCodec"`
+	AudioCodec     string    `gorm:"column:audio_codec;size:50" json:"audioCodec"`
+	Thumbnail      string    `gorm:"column:thumbnail;size:500" json:"thumbnail"` // 缩略图 URL，音频文件取内嵌封面
+	Error          string    `gorm:"column:error;size:500" json:"error"`         // 提取失败原因
+	CreatedAt      time.Time `gorm:"column:created_at;autoCreateTime" json:"createdAt"`
+}
+
+// TableName 指定表名
+func (DownloadMedia) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleQueue, "download_media", "sys_download_media")
+}
+
+// DownloadMediaQueryParam 媒体库查询参数，只包含未删除任务的文件
+type DownloadMediaQueryParam struct {
+	dto.PaginationParam
+
+	Kind     string `query:"kind"`     // video / audio
+	Keywords string `query:"keywords"` // 匹配文件路径
+}
+
+// DownloadMediaVO 音视频文件视图对象
+type DownloadMediaVO struct {
+	ID             uint64  `json:"id"`
+	DownloadTaskID uint64  `json:"downloadTaskId"`
+	TaskName       string  `json:"taskName,omitempty"`
+	Path           string  `json:"path"`
+	Kind           string  `json:"kind"`
+	Format         string  `json:"format"`
+	Duration       float64 `json:"duration"`
+	BitRate        int64   `json:"bitRate"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	VideoCodec     string  `json:"videoCodec"`
+	AudioCodec     string  `json:"audioCodec"`
+	Thumbnail      string  `json:"thumbnail"`
+	Error          string  `json:"error,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+// ToVO 转换为视图对象
+func (m *DownloadMedia) ToVO() *DownloadMediaVO {
+	return &DownloadMediaVO{
+		ID:             m.ID,
+		DownloadTaskID: m.DownloadTaskID,
+		Path:           m.Path,
+		Kind:           m.Kind,
+		Format:         m.Format,
+		Duration:       m.Duration,
+		BitRate:        m.BitRate,
+		Width:          m.Width,
+		Height:         m.Height,
+		VideoCodec:     m.VideoCodec,
+		AudioCodec:     m.AudioCodec,
+		Thumbnail:      m.Thumbnail,
+		Error:          m.Error,
+		CreatedAt:      m.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// DownloadMediaQueryResult 媒体库查询结果
+type DownloadMediaQueryResult struct {
+	List       []*DownloadMediaVO `json:"list"`
+	Pagination *dto.Pagination    `json:"pagination"`
+}
+
+// DownloadMediaExtractVO 提交音视频元数据提取的结果
+type DownloadMediaExtractVO struct {
+	Files int `json:"files"` // 待处理的音视频文件数
+}
@@ -0,0 +1,76 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+)
+
+// DownloadUsage 下载流量统计（按天、用户汇总），数据来自同步任务状态时下载量、上传量的增量
+type DownloadUsage struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	Day        string `gorm:"column:day;size:10;not null;uniqueIndex:uk_download_usage,priority:1" json:"day"` // 2006-01-02
+	UserID     uint64 `gorm:"column:user_id;not null;default:0;uniqueIndex:uk_download_usage,priority:2;index:idx_download_usage_user" json:"userId"`
+	Downloaded int64  `gorm:"column:downloaded;not null;default:0" json:"downloaded"`
+	Uploaded   int64  `gorm:"column:uploaded;not null;default:0" json:"uploaded"`
+}
+
+// TableName 指定表名
+func (DownloadUsage) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleQueue, "download_usage", "sys_download_usage")
+}
+
+// DownloadUsageQueryParam 流量统计查询参数，From/To 为日期（2006-01-02），默认最近 30 天
+// GroupBy 可选 user（默认）、dept
+type DownloadUsageQueryParam struct {
+	From    string `query:"from"`
+	To      string `query:"to"`
+	GroupBy string `query:"groupBy"`
+	DeptID  uint64 `query:"deptId"`
+	Limit   int    `query:"limit"`
+}
+
+// DownloadUsageDayVO 单日流量
+type DownloadUsageDayVO struct {
+	Day        string `json:"day"`
+	Downloaded int64  `json:"downloaded"`
+	Uploaded   int64  `json:"uploaded"`
+}
+
+// DownloadQuotaVO 流量配额使用情况，Limit 为 0 表示不限制
+type DownloadQuotaVO struct {
+	DailyLimit   int64 `json:"dailyLimit"`
+	DailyUsed    int64 `json:"dailyUsed"`
+	MonthlyLimit int64 `json:"monthlyLimit"`
+	MonthlyUsed  int64 `json:"monthlyUsed"`
+	Exceeded     bool  `json:"exceeded"`
+}
+
+// DownloadUsageMeVO 当前用户的流量统计
+type DownloadUsageMeVO struct {
+	From  string                `json:"from"`
+	To    string                `json:"to"`
+	Total DownloadUsageDayVO    `json:"total"`
+	Days  []*DownloadUsageDayVO `json:"days"`
+	Quota DownloadQuotaVO       `json:"quota"`
+}
+
+// DownloadUsageUserVO 按用户汇总的流量
+type DownloadUsageUserVO struct {
+	UserID     uint64 `json:"userId"`
+	Username   string `json:"username"`
+	Nickname   string `json:"nickname"`
+	DeptID     uint64 `json:"deptId"`
+	DeptName   string `json:"deptName"`
+	Downloaded int64  `json:"downloaded"`
+	Uploaded   int64  `json:"uploaded"`
+}
+
+// DownloadUsageDeptVO 按部门汇总的流量
+type DownloadUsageDeptVO struct {
+	DeptID     uint64 `json:"deptId"`
+	DeptName   string `json:"deptName"`
+	Users      int64  `json:"users"`
+	Downloaded int64  `json:"downloaded"`
+	Uploaded   int64  `json:"uploaded"`
+}
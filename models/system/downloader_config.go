@@ -0,0 +1,59 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 可在运行时配置的下载器类型
+const (
+	DownloaderTypeAria2       = "aria2"
+	DownloaderTypeQBittorrent = "qbittorrent"
+	DownloaderTypeSABnzbd     = "sabnzbd"
+)
+
+// DownloaderConfig 运行时管理的下载器连接配置，以 Name 作为下载器名称，与配置文件中的下载器并存
+type DownloaderConfig struct {
+	ID         uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string         `gorm:"column:name;size:50;not null;uniqueIndex:uk_downloader_config_name" json:"name"`
+	Type       string         `gorm:"column:type;size:20;not null" json:"type"` // aria2 / qbittorrent / sabnzbd
+	Server     string         `gorm:"column:server;size:255;not null" json:"server"`
+	Token      string         `gorm:"column:token;size:255" json:"-"` // aria2 RPC 密钥 / SABnzbd API Key
+	User       string         `gorm:"column:user;size:100" json:"user"`
+	Password   string         `gorm:"column:password;size:255" json:"-"`
+	TempPath   string         `gorm:"column:temp_path;size:500" json:"tempPath"`
+	Category   string         `gorm:"column:category;size:100" json:"category"` // SABnzbd 新任务的默认分类
+	Options    database.JSONB `gorm:"column:options" json:"options"`
+	Remark     string         `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy   uint64         `gorm:"column:create_by" json:"createBy"`
+	CreateTime dto.DateTime   `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateBy   uint64         `gorm:"column:update_by" json:"updateBy"`
+	UpdateTime dto.DateTime   `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+
+	HasToken    bool `gorm:"-" json:"hasToken"`    // 是否已设置密钥，密钥本身不返回
+	HasPassword bool `gorm:"-" json:"hasPassword"` // 是否已设置密码，密码本身不返回
+	Loaded      bool `gorm:"-" json:"loaded"`      // 当前实例是否已加载该下载器
+}
+
+// TableName 指定表名
+func (DownloaderConfig) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "downloader_config", "t_downloader_config")
+}
+
+type DownloaderConfigs []*DownloaderConfig
+
+// DownloaderConfigForm 下载器配置表单，修改时 token、password 为空表示保持不变
+type DownloaderConfigForm struct {
+	Name     string                 `json:"name" validate:"required,max=50"`
+	Type     string                 `json:"type" validate:"required,oneof=aria2 qbittorrent sabnzbd"`
+	Server   string                 `json:"server" validate:"required,url,max=255"`
+	Token    string                 `json:"token" validate:"max=255"`
+	User     string                 `json:"user" validate:"max=100"`
+	Password string                 `json:"password" validate:"max=255"`
+	TempPath string                 `json:"tempPath" validate:"max=500"`
+	Category string                 `json:"category" validate:"max=100"`
+	Options  map[string]interface{} `json:"options"`
+	Remark   string                 `json:"remark" validate:"max=255"`
+}
@@ -0,0 +1,34 @@
+package system
+
+import (
+	"github.com/top-system/light-admin/pkg/eventbus"
+)
+
+// 事件总线主题，发布方只发布事件，WebSocket 推送、邮件、Webhook 等各自订阅
+// 异步订阅者收到的是 JSON 编码后的事件，事件类型中不要放不能序列化的字段
+var (
+	// EventDictChanged 字典或字典项已变更
+	EventDictChanged = eventbus.NewTopic[DictChangedEvent]("dict.changed")
+	// EventDownloadFinished 下载任务完成（或开始做种）、出错
+	EventDownloadFinished = eventbus.NewTopic[DownloadFinishedEvent]("download.finished")
+	// EventUserDisabled 用户被禁用
+	EventUserDisabled = eventbus.NewTopic[UserDisabledEvent]("user.disabled")
+)
+
+// DictChangedEvent 字典变更事件
+type DictChangedEvent struct {
+	DictCode string `json:"dictCode"`
+}
+
+// DownloadFinishedEvent 下载任务结束事件
+type DownloadFinishedEvent struct {
+	Type string        `json:"type"` // DownloadEventCompleted 或 DownloadEventFailed
+	Task *DownloadTask `json:"task"`
+}
+
+// UserDisabledEvent 用户禁用事件
+type UserDisabledEvent struct {
+	ID         uint64 `json:"id"`
+	Username   string `json:"username"`
+	OperatorID uint64 `json:"operatorId"` // 操作人，未知时为 0
+}
@@ -0,0 +1,37 @@
+package system
+
+// 内置的资源锁名称
+const (
+	LockMenuImport     = "menu.import"     // 导入菜单
+	LockPermissionSync = "permission.sync" // 导入访问策略、按部门同步角色
+	LockConfigBackup   = "config.backup"   // 配置备份与恢复
+	LockRetentionPurge = "retention.purge" // 按保留策略清理数据
+)
+
+// Lock 资源锁（建议锁），持有期间其他人获取同名锁会失败并得到持有者信息
+type Lock struct {
+	Name       string `json:"name"`
+	OwnerID    uint64 `json:"ownerId"` // 0 表示系统任务
+	Owner      string `json:"owner"`   // 持有者用户名
+	Reason     string `json:"reason"`
+	AcquiredAt int64  `json:"acquiredAt"` // 毫秒时间戳
+	ExpiresAt  int64  `json:"expiresAt"`  // 毫秒时间戳，到期自动释放
+	Token      string `json:"-"`          // 释放锁的凭证，只返回给获取者
+}
+
+// LockAcquireForm 获取锁参数，TTL 为持有时长（秒），默认 600，最长 86400
+type LockAcquireForm struct {
+	TTL    int    `json:"ttl" validate:"omitempty,min=1,max=86400"`
+	Reason string `json:"reason" validate:"max=200"`
+}
+
+// LockAcquireVO 获取锁的结果，释放时需要提供 Token
+type LockAcquireVO struct {
+	*Lock
+	Token string `json:"token"`
+}
+
+// LockReleaseParam 释放锁参数
+type LockReleaseParam struct {
+	Token string `query:"token" validate:"required"`
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -26,8 +29,8 @@ type Log struct {
 }
 
 // TableName 指定表名
-func (Log) TableName() string {
-	return "sys_log"
+func (Log) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "log", "sys_log")
 }
 
 type Logs []*Log
@@ -40,6 +43,8 @@ type LogQueryParam struct {
 	Module         string `query:"module"`
 	CreateTimeFrom string `query:"createTime[0]"`
 	CreateTimeTo   string `query:"createTime[1]"`
+
+	DataScope *DataScope `query:"-"` // 按操作人所在部门过滤
 }
 
 type LogQueryResult struct {
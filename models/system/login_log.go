@@ -0,0 +1,56 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 登录结果
+const (
+	LoginStatusFailure = 0
+	LoginStatusSuccess = 1
+)
+
+// LoginLog 登录审计日志，记录每一次登录尝试（成功与失败）
+// 与 LoginEvent 不同，LoginEvent 只记录成功的登录，用于异常检测
+type LoginLog struct {
+	ID             uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         uint64       `gorm:"column:user_id" json:"userId"` // 登录失败且用户不存在时为 0
+	Username       string       `gorm:"column:username;size:64;not null;index:idx_login_log_username" json:"username"`
+	IP             string       `gorm:"column:ip;size:45;index:idx_login_log_ip" json:"ip"`
+	Status         int          `gorm:"column:status;not null;index:idx_login_log_status" json:"status"` // 0-失败 1-成功
+	Message        string       `gorm:"column:message;size:255" json:"message"`                          // 失败原因
+	UserAgent      string       `gorm:"column:user_agent;size:500" json:"userAgent"`
+	Browser        string       `gorm:"column:browser;size:100" json:"browser"`
+	BrowserVersion string       `gorm:"column:browser_version;size:100" json:"browserVersion"`
+	OS             string       `gorm:"column:os;size:100" json:"os"`
+	CreateTime     dto.DateTime `gorm:"column:create_time;autoCreateTime;index:idx_login_log_create_time" json:"createTime"`
+}
+
+// TableName 指定表名
+func (LoginLog) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "login_log", "t_login_log")
+}
+
+type LoginLogs []*LoginLog
+
+// LoginLogQueryParam 登录日志查询参数
+type LoginLogQueryParam struct {
+	dto.PaginationParam
+
+	Username       string `query:"username"`
+	IP             string `query:"ip"`
+	Status         *int   `query:"status"`
+	CreateTimeFrom string `query:"createTime[0]"`
+	CreateTimeTo   string `query:"createTime[1]"`
+
+	DataScope *DataScope `query:"-"` // 按登录用户所在部门过滤
+}
+
+// LoginLogQueryResult 登录日志查询结果
+type LoginLogQueryResult struct {
+	List       LoginLogs       `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
@@ -0,0 +1,89 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 内置邮件模板编码，数据库中没有同编码的模板时使用内置内容
+const (
+	MailTemplateNoticePublished = "notice_published" // 通知公告发布，数据：Notice、User
+	MailTemplateDownloadFailed  = "download_failed"  // 下载任务出错，数据：Task、User
+)
+
+// 邮件模板正文格式
+const (
+	MailContentText = "text"
+	MailContentHTML = "html"
+)
+
+// MailTemplate 邮件模板，Subject 与 Content 为 Go 模板
+// ContentType 为 html 时正文按 html/template 渲染并转义数据，否则按 text/template 渲染
+// Status: 1-正常 0-停用，停用后使用内置模板，没有内置模板时不发送
+type MailTemplate struct {
+	ID          uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string       `gorm:"column:code;size:64;not null;uniqueIndex:uk_mail_template_code" json:"code"`
+	Name        string       `gorm:"column:name;size:64;not null" json:"name"`
+	Subject     string       `gorm:"column:subject;size:255;not null" json:"subject"`
+	Content     string       `gorm:"column:content;type:text" json:"content"`
+	ContentType string       `gorm:"column:content_type;size:8;default:text" json:"contentType"`
+	Status      int          `gorm:"column:status;default:1" json:"status"`
+	Remark      string       `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy    uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime  dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateBy    uint64       `gorm:"column:update_by" json:"updateBy"`
+	UpdateTime  dto.DateTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+// TableName 指定表名
+func (MailTemplate) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "mail_template", "t_mail_template")
+}
+
+type MailTemplates []*MailTemplate
+
+// MailTemplateQueryParam 邮件模板查询参数
+type MailTemplateQueryParam struct {
+	dto.PaginationParam
+
+	Keywords string `query:"keywords"` // 名称或编码
+	Status   *int   `query:"status"`
+}
+
+// MailTemplateQueryResult 邮件模板查询结果
+type MailTemplateQueryResult struct {
+	List       MailTemplates   `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// MailTemplateForm 邮件模板表单
+type MailTemplateForm struct {
+	Code        string `json:"code" validate:"required,max=64"`
+	Name        string `json:"name" validate:"required,max=64"`
+	Subject     string `json:"subject" validate:"required,max=255"`
+	Content     string `json:"content" validate:"required"`
+	ContentType string `json:"contentType" validate:"omitempty,oneof=text html"`
+	Status      int    `json:"status" validate:"oneof=0 1"`
+	Remark      string `json:"remark" validate:"max=255"`
+}
+
+// MailTemplatePreviewForm 邮件模板预览，Data 为渲染使用的数据
+type MailTemplatePreviewForm struct {
+	MailTemplateForm
+	Data map[string]interface{} `json:"data"`
+}
+
+// MailTemplateTestForm 使用模板发送测试邮件
+type MailTemplateTestForm struct {
+	To   string                 `json:"to" validate:"required,email"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// MailPreview 渲染后的邮件
+type MailPreview struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text,omitempty"`
+	HTML    string `json:"html,omitempty"`
+}
@@ -5,6 +5,9 @@ import (
 	"strconv"
 	"strings"
 
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -33,8 +36,8 @@ type Menu struct {
 }
 
 // TableName 指定表名
-func (Menu) TableName() string {
-	return "t_menu"
+func (Menu) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "menu", "t_menu")
 }
 
 // MenuTree 菜单树结构(用于展示和YAML解析)
@@ -290,3 +293,29 @@ func (a *Menu) GetParamsMap() map[string]string {
 	}
 	return params
 }
+
+// MenuDeltaVO 路由菜单增量同步结果（不含按钮）
+// Full 为 true 时 Menus 为当前用户可见的全部菜单，客户端应整体替换后按 parentId 构建路由树；
+// 否则只包含 version 之后变更的菜单。菜单被删除或用户可见范围变化时总是返回全量
+type MenuDeltaVO struct {
+	Version string `json:"version"` // 下次同步时携带的版本号
+	Full    bool   `json:"full"`
+	Menus   Menus  `json:"menus"`
+}
+
+// 菜单删除方式
+const (
+	MenuDeleteModeDefault  = ""         // 仅删除无子节点的菜单
+	MenuDeleteModeCascade  = "cascade"  // 删除整棵子树
+	MenuDeleteModeReparent = "reparent" // 子节点挂到被删除菜单的上级
+)
+
+// MenuDeletePreview 菜单删除预览，用于在级联删除前向用户确认影响范围
+type MenuDeletePreview struct {
+	MenuID       uint64   `json:"menuId"`
+	ChildCount   int      `json:"childCount"`   // 直接子节点数
+	SubtreeCount int      `json:"subtreeCount"` // 子树菜单总数（含自身）
+	ButtonCount  int      `json:"buttonCount"`  // 子树中的按钮数
+	RoleMenus    int64    `json:"roleMenus"`    // 级联删除时将清除的角色授权数
+	MenuIDs      []uint64 `json:"menuIds"`      // 级联删除时将删除的菜单ID
+}
@@ -1,6 +1,11 @@
 package system
 
 import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -9,6 +14,7 @@ import (
 // Level: 通知等级（字典code：notice_level）L-低 M-中 H-高
 // TargetType: 目标类型（1: 全体, 2: 指定）
 // PublishStatus: 发布状态（0: 未发布, 1: 已发布, -1: 已撤回）
+// Channels: 站内通知之外的发布渠道，逗号分隔，目前支持 email
 type Notice struct {
 	ID            uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Title         string           `gorm:"column:title;size:50" json:"title"`
@@ -17,6 +23,7 @@ type Notice struct {
 	Level         string           `gorm:"column:level;size:5;not null" json:"level"`
 	TargetType    int              `gorm:"column:target_type;not null" json:"targetType"`
 	TargetUserIds string           `gorm:"column:target_user_ids;size:255" json:"targetUserIds"`
+	Channels      string           `gorm:"column:channels;size:64" json:"channels"`
 	PublisherId   uint64           `gorm:"column:publisher_id" json:"publisherId"`
 	PublishStatus int              `gorm:"column:publish_status;default:0;index:idx_publish_status" json:"publishStatus"`
 	PublishTime   dto.NullDateTime `gorm:"column:publish_time" json:"publishTime"`
@@ -28,9 +35,22 @@ type Notice struct {
 	IsDeleted     int              `gorm:"column:is_deleted;default:0" json:"isDeleted"`
 }
 
+// NoticeChannelEmail 发布时同时发送邮件
+const NoticeChannelEmail = "email"
+
+// HasChannel 是否启用了指定的发布渠道
+func (a *Notice) HasChannel(channel string) bool {
+	for _, item := range strings.Split(a.Channels, ",") {
+		if strings.TrimSpace(item) == channel {
+			return true
+		}
+	}
+	return false
+}
+
 // TableName 指定表名
-func (Notice) TableName() string {
-	return "t_notice"
+func (Notice) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "notice", "t_notice")
 }
 
 type Notices []*Notice
@@ -39,10 +59,13 @@ type NoticeQueryParam struct {
 	dto.PaginationParam
 	dto.OrderParam
 
-	Title         string `query:"title"`
-	Type          int    `query:"type"`
-	PublishStatus *int   `query:"publishStatus"`
-	UserID        uint64 `query:"-"` // 用于查询我的通知
+	Title         string   `query:"title"`
+	Type          int      `query:"type"`
+	PublishStatus *int     `query:"publishStatus"`
+	UserID        uint64   `query:"-"` // 用于查询我的通知
+	TagIDs        []uint64 `query:"-"` // 按标签过滤，带有任一标签即匹配
+
+	DataScope *DataScope `query:"-"` // 按创建人所在部门过滤
 }
 
 type NoticeQueryResult struct {
@@ -59,6 +82,10 @@ type NoticeForm struct {
 	Level         string      `json:"level" validate:"required"`
 	TargetType    int         `json:"targetType" validate:"required"`
 	TargetUserIds []string    `json:"targetUserIds"`
+	Channels      []string    `json:"channels" validate:"dive,oneof=email"` // 额外的发布渠道，email: 同时发送邮件给有邮箱的目标用户
+	AttachmentIds []uint64    `json:"attachmentIds"`                        // 已上传的附件 ID，修改时未包含的原有附件会被删除
+
+	Attachments []*NoticeAttachmentVO `json:"attachments,omitempty"` // 仅用于回显
 }
 
 // NoticePageVO 通知公告分页视图对象
@@ -84,4 +111,6 @@ type NoticeDetailVO struct {
 	PublisherId   uint64           `json:"publisherId"`
 	PublisherName string           `json:"publisherName"`
 	PublishTime   dto.NullDateTime `json:"publishTime"`
+
+	Attachments []*NoticeAttachmentVO `json:"attachments"`
 }
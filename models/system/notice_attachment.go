@@ -0,0 +1,46 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// NoticeAttachment 通知公告附件，文件通过文件服务存储
+// NoticeID 为 0 表示已上传但尚未关联到通知公告；DetachTime 有值表示已从通知公告中移除，
+// 记录与文件由定时清理任务在事务提交后删除
+type NoticeAttachment struct {
+	ID          uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	NoticeID    uint64           `gorm:"column:notice_id;index:idx_notice_attachment_notice" json:"noticeId"`
+	FileName    string           `gorm:"column:file_name;size:255;not null" json:"fileName"`
+	FileURL     string           `gorm:"column:file_url;size:500;not null" json:"-"`
+	FileSize    int64            `gorm:"column:file_size" json:"fileSize"`
+	ContentType string           `gorm:"column:content_type;size:100" json:"contentType"`
+	CreateBy    uint64           `gorm:"column:create_by" json:"createBy"`
+	CreateTime  dto.DateTime     `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	DetachTime  dto.NullDateTime `gorm:"column:detach_time" json:"-"`
+}
+
+// TableName 指定表名
+func (NoticeAttachment) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "notice_attachment", "t_notice_attachment")
+}
+
+type NoticeAttachments []*NoticeAttachment
+
+// NoticeAttachmentVO 通知公告附件视图对象，URL 为带有效期的签名下载地址
+type NoticeAttachmentVO struct {
+	ID          uint64 `json:"id"`
+	FileName    string `json:"fileName"`
+	FileSize    int64  `json:"fileSize"`
+	ContentType string `json:"contentType"`
+	URL         string `json:"url"`
+}
+
+// NoticeAttachmentDownloadParam 附件签名下载参数
+type NoticeAttachmentDownloadParam struct {
+	ID      uint64 `query:"id"`
+	Expires int64  `query:"expires"` // 过期时间（Unix 秒）
+	Sign    string `query:"sign"`
+}
@@ -0,0 +1,98 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// NoticeRevision 通知公告草稿修订记录，每次自动保存（内容有变化时）生成一条
+type NoticeRevision struct {
+	ID            uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	NoticeID      uint64       `gorm:"column:notice_id;not null;index:idx_notice_revision_notice" json:"noticeId"`
+	Revision      int          `gorm:"column:revision;not null" json:"revision"` // 通知内递增的版本号
+	Title         string       `gorm:"column:title;size:50" json:"title"`
+	Content       string       `gorm:"column:content;type:text" json:"content"`
+	Type          int          `gorm:"column:type" json:"type"`
+	Level         string       `gorm:"column:level;size:5" json:"level"`
+	TargetType    int          `gorm:"column:target_type" json:"targetType"`
+	TargetUserIds string       `gorm:"column:target_user_ids;size:255" json:"targetUserIds"`
+	CreateBy      uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime    dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+// TableName 指定表名
+func (NoticeRevision) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "notice_revision", "t_notice_revision")
+}
+
+type NoticeRevisions []*NoticeRevision
+
+// NoticeRevisionQueryParam 修订记录查询参数
+type NoticeRevisionQueryParam struct {
+	dto.PaginationParam
+
+	NoticeID uint64 `query:"-"`
+}
+
+// NoticeRevisionQueryResult 修订记录查询结果
+type NoticeRevisionQueryResult struct {
+	List       NoticeRevisions `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// NoticeDraftForm 草稿自动保存表单，只更新传入的字段
+type NoticeDraftForm struct {
+	Title         *string      `json:"title"`
+	Content       *string      `json:"content"`
+	Type          *dto.FlexInt `json:"type"`
+	Level         *string      `json:"level"`
+	TargetType    *int         `json:"targetType"`
+	TargetUserIds *[]string    `json:"targetUserIds"`
+}
+
+// NoticeDraftResult 草稿保存结果
+// Saved 为 false 表示内容没有变化，未生成修订记录
+type NoticeDraftResult struct {
+	Saved    bool                  `json:"saved"`
+	Revision int                   `json:"revision"`
+	Lock     *NoticeEditLockStatus `json:"lock"`
+}
+
+// 编辑锁事件类型
+const (
+	NoticeLockAcquired = "locked"
+	NoticeLockReleased = "released"
+)
+
+// NoticeEditLock 通知公告编辑锁（软锁），只用于提示其他编辑者，不阻止保存
+type NoticeEditLock struct {
+	NoticeID  uint64 `json:"noticeId"`
+	UserID    uint64 `json:"userId"`
+	Username  string `json:"username"`
+	Nickname  string `json:"nickname"`
+	LockedAt  int64  `json:"lockedAt"`  // Unix 毫秒
+	ExpiresAt int64  `json:"expiresAt"` // Unix 毫秒，编辑页需在过期前续期
+}
+
+// NoticeEditLockStatus 编辑锁状态
+// Conflict 为 true 表示锁由其他用户持有，前端应提示可能覆盖对方的修改
+type NoticeEditLockStatus struct {
+	Lock     *NoticeEditLock `json:"lock"`
+	Owned    bool            `json:"owned"`
+	Conflict bool            `json:"conflict"`
+}
+
+// NoticeLockParam 获取编辑锁参数
+type NoticeLockParam struct {
+	Force bool `query:"force"` // 强制接管其他用户持有的锁
+}
+
+// NoticeLockEvent 编辑锁变化事件，推送到 /topic/notice-locks
+type NoticeLockEvent struct {
+	Type      string          `json:"type"`
+	NoticeID  uint64          `json:"noticeId"`
+	Lock      *NoticeEditLock `json:"lock,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+}
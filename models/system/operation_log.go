@@ -0,0 +1,52 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// OperationLog 操作审计日志，记录每一个修改类请求（POST/PUT/PATCH/DELETE）
+// 与 Log 不同，Log 只记录配置了模块名称的接口并保存完整的请求与响应
+type OperationLog struct {
+	ID          uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint64       `gorm:"column:user_id;index:idx_operation_log_user_id" json:"userId"`
+	Username    string       `gorm:"column:username;size:64;index:idx_operation_log_username" json:"username"`
+	Module      string       `gorm:"column:module;size:50;index:idx_operation_log_module" json:"module"` // 路径 /api/v1 之后的第一段
+	Method      string       `gorm:"column:method;size:10;not null" json:"method"`
+	Path        string       `gorm:"column:path;size:255" json:"path"`                 // 路由模板，如 /api/v1/users/:id
+	URI         string       `gorm:"column:uri;size:500" json:"uri"`                   // 实际请求地址（含查询参数）
+	RequestBody string       `gorm:"column:request_body;size:2000" json:"requestBody"` // 请求体摘要，敏感字段已脱敏
+	StatusCode  int          `gorm:"column:status_code" json:"statusCode"`
+	Latency     int64        `gorm:"column:latency" json:"latency"` // 毫秒
+	IP          string       `gorm:"column:ip;size:45" json:"ip"`
+	CreateTime  dto.DateTime `gorm:"column:create_time;index:idx_operation_log_create_time" json:"createTime"`
+}
+
+// TableName 指定表名
+func (OperationLog) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "operation_log", "t_operation_log")
+}
+
+type OperationLogs []*OperationLog
+
+// OperationLogQueryParam 操作审计日志查询参数
+type OperationLogQueryParam struct {
+	dto.PaginationParam
+
+	UserID         uint64 `query:"userId"`
+	Username       string `query:"username"`
+	Module         string `query:"module"`
+	Method         string `query:"method"`
+	CreateTimeFrom string `query:"createTime[0]"`
+	CreateTimeTo   string `query:"createTime[1]"`
+
+	DataScope *DataScope `query:"-"` // 按操作人所在部门过滤
+}
+
+// OperationLogQueryResult 操作审计日志查询结果
+type OperationLogQueryResult struct {
+	List       OperationLogs   `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// Position 岗位模型，用户可同时属于部门、角色和多个岗位
+// Status: 1-正常 0-停用
+type Position struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string       `gorm:"column:name;size:64;not null" json:"name"`
+	Code       string       `gorm:"column:code;size:32;not null;uniqueIndex:uk_position_code" json:"code"`
+	Sort       int          `gorm:"column:sort;default:0" json:"sort"`
+	Status     int          `gorm:"column:status;default:1" json:"status"`
+	Remark     string       `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy   uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateBy   uint64       `gorm:"column:update_by" json:"updateBy"`
+	UpdateTime dto.DateTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+
+	UserCount int64 `gorm:"-" json:"userCount"` // 任职的用户数
+}
+
+// TableName 指定表名
+func (Position) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "position", "t_position")
+}
+
+type Positions []*Position
+
+// PositionQueryParam 岗位查询参数
+type PositionQueryParam struct {
+	dto.PaginationParam
+
+	Keywords string `query:"keywords"` // 名称或编号
+	Status   *int   `query:"status"`
+}
+
+// PositionQueryResult 岗位查询结果
+type PositionQueryResult struct {
+	List       Positions       `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// PositionForm 岗位表单
+type PositionForm struct {
+	Name   string `json:"name" validate:"required,max=64"`
+	Code   string `json:"code" validate:"required,max=32"`
+	Sort   int    `json:"sort"`
+	Status int    `json:"status" validate:"oneof=0 1"`
+	Remark string `json:"remark" validate:"max=255"`
+}
+
+// PositionOption 岗位下拉选项
+type PositionOption struct {
+	Value uint64 `json:"value"`
+	Label string `json:"label"`
+}
+
+// ToOptions 转换为下拉选项列表
+func (a Positions) ToOptions() []*PositionOption {
+	options := make([]*PositionOption, len(a))
+	for i, item := range a {
+		options[i] = &PositionOption{
+			Value: item.ID,
+			Label: item.Name,
+		}
+	}
+	return options
+}
@@ -0,0 +1,76 @@
+package system
+
+// 批量开通初始密码的下发方式
+const (
+	ProvisionNotifyReturn = "return" // 在结果中返回（仅此一次）
+	ProvisionNotifyEmail  = "email"  // 发送到用户邮箱，无邮箱或发送失败时在结果中返回
+)
+
+// 批量开通行处理状态
+const (
+	ProvisionRowCreated = "created" // 已创建
+	ProvisionRowValid   = "valid"   // 校验通过（试运行未创建）
+	ProvisionRowExists  = "exists"  // 部门已存在，直接复用
+	ProvisionRowError   = "error"   // 校验失败
+)
+
+// 批量开通数据类型
+const (
+	ProvisionKindDept = "dept"
+	ProvisionKindUser = "user"
+)
+
+// ProvisionDeptRow 部门 CSV 行
+// 表头: code,name,parent_code,sort
+type ProvisionDeptRow struct {
+	Line       int
+	Code       string
+	Name       string
+	ParentCode string
+	Sort       int
+}
+
+// ProvisionUserRow 用户 CSV 行
+// 表头: username,nickname,email,mobile,gender,dept_code,role_codes
+// role_codes 多个角色以 | 或 ; 分隔
+type ProvisionUserRow struct {
+	Line      int
+	Username  string
+	Nickname  string
+	Email     string
+	Mobile    string
+	Gender    int
+	DeptCode  string
+	RoleCodes []string
+}
+
+// ProvisionOptions 批量开通选项
+type ProvisionOptions struct {
+	DryRun   bool   `query:"dryRun"` // 仅校验不创建
+	Notify   string `query:"notify"` // 初始密码下发方式: return、email
+	Operator uint64 `query:"-"`      // 操作人
+}
+
+// ProvisionRowResult 单行处理结果
+// Password 为生成的初始密码，只在本次结果中出现，不会再次获取
+type ProvisionRowResult struct {
+	Kind     string `json:"kind"`
+	Line     int    `json:"line"`
+	Key      string `json:"key"` // 部门编号或用户名
+	Status   string `json:"status"`
+	ID       uint64 `json:"id,omitempty"`
+	Password string `json:"password,omitempty"`
+	Emailed  bool   `json:"emailed,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// ProvisionResult 批量开通结果
+// 存在任一校验失败时不会创建任何数据，Committed 为 false
+type ProvisionResult struct {
+	DryRun    bool                  `json:"dryRun"`
+	Committed bool                  `json:"committed"`
+	Depts     int                   `json:"depts"`  // 新建部门数
+	Users     int                   `json:"users"`  // 新建用户数
+	Failed    int                   `json:"failed"` // 校验失败行数
+	Rows      []*ProvisionRowResult `json:"rows"`
+}
@@ -0,0 +1,34 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// RefreshToken 登录设备的刷新令牌，每次登录创建一条，刷新时轮换令牌并延长有效期
+// 只保存令牌的 SHA-256 摘要，ID 写入访问令牌的 sid 声明
+type RefreshToken struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint64       `gorm:"column:user_id;not null;index:idx_refresh_token_user_id" json:"userId"`
+	Username   string       `gorm:"column:username;size:64;not null;index:idx_refresh_token_username" json:"username"`
+	TokenHash  string       `gorm:"column:token_hash;size:64;not null;uniqueIndex:uk_refresh_token_hash" json:"-"`
+	Device     string       `gorm:"column:device;size:255" json:"device"` // 登录时的 User-Agent
+	IP         string       `gorm:"column:ip;size:64" json:"ip"`          // 最近一次刷新的客户端地址
+	LoginTime  dto.DateTime `gorm:"column:login_time" json:"loginTime"`
+	ExpireTime dto.DateTime `gorm:"column:expire_time;index:idx_refresh_token_expire_time" json:"expireTime"`
+	UpdateTime dto.DateTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"` // 最近一次刷新时间
+
+	Current bool `gorm:"-" json:"current"` // 是否为当前请求所在的设备
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "refresh_token", "t_refresh_token")
+}
+
+// ReadOnlyExempt 只读模式下仍可登录、刷新与登出，登录设备照常写入
+func (RefreshToken) ReadOnlyExempt() {}
+
+type RefreshTokens []*RefreshToken
@@ -0,0 +1,82 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 数据保留策略的数据类型
+const (
+	RetentionOperationLog   = "operation_log"   // 操作日志
+	RetentionLoginLog       = "login_log"       // 登录记录
+	RetentionLoginAudit     = "login_audit"     // 登录审计日志（每次登录尝试）
+	RetentionOperationAudit = "operation_audit" // 操作审计日志（每个修改类请求）
+	RetentionDownloadTask   = "download_task"   // 已结束（完成、失败、取消）的下载任务
+	RetentionQueueTask      = "queue_task"      // 已结束（完成、失败、取消）的队列任务
+	RetentionCronRecord     = "cron_record"     // 定时任务执行记录
+	RetentionDownloadTemp   = "download_temp"   // 下载器临时目录中没有任务引用的任务目录，按最后修改时间计算
+)
+
+// RetentionDataTypes 支持的数据类型及默认保留天数，首次列出策略时为缺失的类型创建策略，
+// 默认天数可以通过配置 Retention.Days 修改
+var RetentionDataTypes = []struct {
+	DataType string
+	Days     int
+}{
+	{RetentionOperationLog, 90},
+	{RetentionLoginLog, 180},
+	{RetentionLoginAudit, 180},
+	{RetentionOperationAudit, 90},
+	{RetentionDownloadTask, 30},
+	{RetentionQueueTask, 14},
+	{RetentionCronRecord, 30},
+	{RetentionDownloadTemp, 7},
+}
+
+// RetentionPolicy 数据保留策略，定时任务删除超过保留天数的数据
+type RetentionPolicy struct {
+	ID         uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	DataType   string           `gorm:"column:data_type;size:32;not null;uniqueIndex:uk_retention_policy_data_type" json:"dataType"`
+	Days       int              `gorm:"column:days;not null" json:"days"`
+	Enable     bool             `gorm:"column:enable;not null;default:true" json:"enable"`
+	LastRunAt  dto.NullDateTime `gorm:"column:last_run_at" json:"lastRunAt"`
+	LastPurged int64            `gorm:"column:last_purged;default:0" json:"lastPurged"` // 最近一次执行删除的行数
+	LastError  string           `gorm:"column:last_error;size:500" json:"lastError"`
+	UpdateBy   uint64           `gorm:"column:update_by" json:"updateBy"`
+	UpdateTime dto.DateTime     `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+// TableName 指定表名
+func (RetentionPolicy) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "retention_policy", "t_retention_policy")
+}
+
+type RetentionPolicies []*RetentionPolicy
+
+// RetentionPolicyForm 修改保留策略
+type RetentionPolicyForm struct {
+	Days   int  `json:"days" validate:"required,min=1,max=3650"`
+	Enable bool `json:"enable"`
+}
+
+// RetentionPreviewVO 按当前策略将被删除的数据行数，download_temp 为目录数
+type RetentionPreviewVO struct {
+	DataType string `json:"dataType"`
+	Days     int    `json:"days"`
+	Enable   bool   `json:"enable"`
+	Cutoff   string `json:"cutoff"` // 早于该时间的数据会被删除
+	Rows     int64  `json:"rows"`
+}
+
+// RetentionRunForm 手动执行保留策略，DataTypes 为空时执行全部已启用的策略，未启用的策略不会执行
+type RetentionRunForm struct {
+	DataTypes []string `json:"dataTypes"`
+}
+
+// RetentionRunVO 手动执行保留策略，删除在后台分批进行，结果记录在策略的 lastRunAt、lastPurged 中
+type RetentionRunVO struct {
+	DataTypes   []string `json:"dataTypes"`
+	QueueTaskID uint64   `json:"queueTaskId,omitempty"` // 未启用任务队列时为 0
+}
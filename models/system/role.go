@@ -1,12 +1,15 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
 // Role 角色模型
 // Status: 1-正常 0-停用
-// DataScope: 数据权限范围
+// DataScope: 数据权限范围，见 DataScope* 常量
 type Role struct {
 	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name       string       `gorm:"column:name;size:64;not null;uniqueIndex:uk_role_name" json:"name"`
@@ -22,11 +25,12 @@ type Role struct {
 
 	// 非数据库字段
 	MenuIds []uint64 `gorm:"-" json:"menuIds,omitempty"`
+	DeptIds []uint64 `gorm:"-" json:"deptIds,omitempty"` // 自定义数据权限的部门
 }
 
 // TableName 指定表名
-func (Role) TableName() string {
-	return "t_role"
+func (Role) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "role", "t_role")
 }
 
 type Roles []*Role
@@ -0,0 +1,20 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+)
+
+// RoleDept 角色自定义数据权限的部门
+type RoleDept struct {
+	RoleID uint64 `gorm:"column:role_id;not null;uniqueIndex:uk_roleid_deptid" json:"roleId"`
+	DeptID uint64 `gorm:"column:dept_id;not null;uniqueIndex:uk_roleid_deptid" json:"deptId"`
+}
+
+// TableName 指定表名
+func (RoleDept) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "role_dept", "t_role_dept")
+}
+
+type RoleDepts []*RoleDept
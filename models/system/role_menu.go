@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -11,8 +14,8 @@ type RoleMenu struct {
 }
 
 // TableName 指定表名
-func (RoleMenu) TableName() string {
-	return "t_role_menu"
+func (RoleMenu) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "role_menu", "t_role_menu")
 }
 
 type RoleMenus []*RoleMenu
@@ -0,0 +1,64 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 支持保存查询的列表资源
+const (
+	SavedQueryResourceUser     = "user"
+	SavedQueryResourceDownload = "download"
+	SavedQueryResourceLog      = "log"
+)
+
+// SavedQuerySortKeys 各列表资源允许保存的排序字段
+var SavedQuerySortKeys = map[string][]string{
+	SavedQueryResourceUser:     {"id", "username", "nickname", "create_time", "update_time"},
+	SavedQueryResourceDownload: {"id", "name", "status", "total", "created_at", "updated_at"},
+	SavedQueryResourceLog:      {"id", "module", "execution_time", "create_time"},
+}
+
+// IsSavedQueryResource 判断列表资源是否支持保存查询
+func IsSavedQueryResource(resource string) bool {
+	_, ok := SavedQuerySortKeys[resource]
+	return ok
+}
+
+// SavedQuery 用户保存的列表查询条件、排序与列设置
+type SavedQuery struct {
+	ID         uint64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint64         `gorm:"column:user_id;not null;uniqueIndex:uk_saved_query_name,priority:1" json:"userId"`
+	Resource   string         `gorm:"column:resource;size:32;not null;uniqueIndex:uk_saved_query_name,priority:2" json:"resource"`
+	Name       string         `gorm:"column:name;size:64;not null;uniqueIndex:uk_saved_query_name,priority:3" json:"name"`
+	Filter     database.JSONB `gorm:"column:filter" json:"filter"` // 查询参数，键为列表接口的 query 参数名，值为字符串
+	SortKey    string         `gorm:"column:sort_key;size:64" json:"sortKey"`
+	SortOrder  string         `gorm:"column:sort_order;size:4" json:"sortOrder"` // ASC、DESC
+	Columns    database.JSONB `gorm:"column:columns" json:"columns"`             // 显示的列，由前端解释
+	CreateTime dto.DateTime   `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime dto.DateTime   `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+}
+
+// TableName 指定表名
+func (SavedQuery) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "saved_query", "t_saved_query")
+}
+
+type SavedQueries []*SavedQuery
+
+// SavedQueryQueryParam 保存查询列表参数
+type SavedQueryQueryParam struct {
+	Resource string `query:"resource"`
+}
+
+// SavedQueryForm 保存查询表单
+type SavedQueryForm struct {
+	Resource  string            `json:"resource" validate:"required"`
+	Name      string            `json:"name" validate:"required,max=64"`
+	Filter    map[string]string `json:"filter"`
+	SortKey   string            `json:"sortKey" validate:"max=64"`
+	SortOrder string            `json:"sortOrder"`
+	Columns   []string          `json:"columns"`
+}
@@ -0,0 +1,102 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 登录异常规则
+const (
+	SecurityRuleNewCountry       = "new_country"
+	SecurityRuleImpossibleTravel = "impossible_travel"
+	SecurityRuleUnusualHour      = "unusual_hour"
+	SecurityRuleDistinctIPs      = "distinct_ips"
+)
+
+// 安全告警状态
+const (
+	SecurityAlertOpen         = 0
+	SecurityAlertAcknowledged = 1
+)
+
+// LoginEvent 登录成功记录（含 IP 归属地），作为异常检测的历史数据
+type LoginEvent struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint64       `gorm:"column:user_id;not null;index:idx_login_event_user_time,priority:1" json:"userId"`
+	Username   string       `gorm:"column:username;size:64;not null" json:"username"`
+	IP         string       `gorm:"column:ip;size:45" json:"ip"`
+	Country    string       `gorm:"column:country;size:64" json:"country"`
+	Region     string       `gorm:"column:region;size:100" json:"region"`
+	City       string       `gorm:"column:city;size:100" json:"city"`
+	Latitude   float64      `gorm:"column:latitude" json:"latitude"`
+	Longitude  float64      `gorm:"column:longitude" json:"longitude"`
+	CreateTime dto.DateTime `gorm:"column:create_time;autoCreateTime;index:idx_login_event_user_time,priority:2" json:"createTime"`
+}
+
+// TableName 指定表名
+func (LoginEvent) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "login_event", "sys_login_event")
+}
+
+type LoginEvents []*LoginEvent
+
+// SecurityAlert 安全告警
+// Level: 与通知等级一致，L-低 M-中 H-高
+// Status: 0-待处理 1-已确认
+type SecurityAlert struct {
+	ID         uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Rule       string           `gorm:"column:rule;size:32;not null;index:idx_security_alert_rule" json:"rule"`
+	Level      string           `gorm:"column:level;size:5;not null" json:"level"`
+	UserID     uint64           `gorm:"column:user_id;not null;index:idx_security_alert_user" json:"userId"`
+	Username   string           `gorm:"column:username;size:64;not null" json:"username"`
+	IP         string           `gorm:"column:ip;size:45" json:"ip"`
+	Country    string           `gorm:"column:country;size:64" json:"country"`
+	City       string           `gorm:"column:city;size:100" json:"city"`
+	Detail     string           `gorm:"column:detail;size:500" json:"detail"`
+	Status     int              `gorm:"column:status;not null;default:0;index:idx_security_alert_status" json:"status"`
+	AckBy      uint64           `gorm:"column:ack_by" json:"ackBy"`
+	AckTime    dto.NullDateTime `gorm:"column:ack_time" json:"ackTime"`
+	CreateTime dto.DateTime     `gorm:"column:create_time;autoCreateTime;index:idx_security_alert_create_time" json:"createTime"`
+}
+
+// TableName 指定表名
+func (SecurityAlert) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "security_alert", "sys_security_alert")
+}
+
+type SecurityAlerts []*SecurityAlert
+
+// SecurityAlertQueryParam 安全告警查询参数
+type SecurityAlertQueryParam struct {
+	dto.PaginationParam
+
+	Rule     string `query:"rule"`
+	Username string `query:"username"`
+	Status   *int   `query:"status"`
+	From     string `query:"from"`
+	To       string `query:"to"`
+}
+
+// SecurityAlertQueryResult 安全告警查询结果
+type SecurityAlertQueryResult struct {
+	List       SecurityAlerts  `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// SecurityRuleCount 按规则统计的告警数
+type SecurityRuleCount struct {
+	Rule  string `json:"rule"`
+	Count int64  `json:"count"`
+}
+
+// SecurityDashboardVO 安全概览
+type SecurityDashboardVO struct {
+	Days         int                  `json:"days"`
+	Total        int64                `json:"total"`
+	Open         int64                `json:"open"`
+	ByRule       []*SecurityRuleCount `json:"byRule"`
+	Recent       SecurityAlerts       `json:"recent"`
+	EnabledRules []string             `json:"enabledRules"`
+}
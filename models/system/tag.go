@@ -0,0 +1,92 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 可打标签的资源类型
+const (
+	TagResourceUser     = "user"
+	TagResourceFile     = "file"
+	TagResourceNotice   = "notice"
+	TagResourceDownload = "download"
+)
+
+// TagResourceTypes 全部可打标签的资源类型
+var TagResourceTypes = []string{TagResourceUser, TagResourceFile, TagResourceNotice, TagResourceDownload}
+
+// IsTagResourceType 判断资源类型是否支持打标签
+func IsTagResourceType(resourceType string) bool {
+	for _, t := range TagResourceTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag 标签，可关联到用户、文件、通知、下载任务等资源
+type Tag struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string       `gorm:"column:name;size:50;not null;uniqueIndex:uk_tag_name" json:"name"`
+	Color      string       `gorm:"column:color;size:20" json:"color"`
+	Remark     string       `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy   uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateBy   uint64       `gorm:"column:update_by" json:"updateBy"`
+	UpdateTime dto.DateTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+
+	ResourceCount int64 `gorm:"-" json:"resourceCount"` // 关联的资源数
+}
+
+// TableName 指定表名
+func (Tag) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "tag", "t_tag")
+}
+
+type Tags []*Tag
+
+// Tagging 标签与资源的多态关联
+type Tagging struct {
+	ID           uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TagID        uint64       `gorm:"column:tag_id;not null;uniqueIndex:uk_tagging,priority:1" json:"tagId"`
+	ResourceType string       `gorm:"column:resource_type;size:32;not null;uniqueIndex:uk_tagging,priority:2;index:idx_tagging_resource,priority:1" json:"resourceType"`
+	ResourceID   uint64       `gorm:"column:resource_id;not null;uniqueIndex:uk_tagging,priority:3;index:idx_tagging_resource,priority:2" json:"resourceId"`
+	CreateBy     uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime   dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+}
+
+// TableName 指定表名
+func (Tagging) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "tagging", "t_tagging")
+}
+
+type Taggings []*Tagging
+
+// TagQueryParam 标签查询参数
+type TagQueryParam struct {
+	dto.PaginationParam
+
+	Keywords string `query:"keywords"`
+}
+
+// TagQueryResult 标签查询结果
+type TagQueryResult struct {
+	List       Tags            `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// TagForm 标签表单
+type TagForm struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Color  string `json:"color" validate:"max=20"`
+	Remark string `json:"remark" validate:"max=255"`
+}
+
+// ResourceTagsForm 设置资源标签（整体替换）
+type ResourceTagsForm struct {
+	TagIDs []uint64 `json:"tagIds"`
+}
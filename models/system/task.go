@@ -1,8 +1,12 @@
 package system
 
 import (
+	"encoding/json"
 	"time"
 
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 	"github.com/top-system/light-admin/pkg/queue"
 )
@@ -20,13 +24,58 @@ type Task struct {
 	Error            string       `gorm:"column:public_error;type:text" json:"error"`
 	ErrorHistory     string       `gorm:"column:public_error_history;type:text" json:"errorHistory"`
 	ResumeTime       int64        `gorm:"column:public_resume_time;default:0" json:"resumeTime"`
+	Priority         int          `gorm:"column:public_priority;default:0" json:"priority"`
+	Progress         string       `gorm:"column:public_progress;type:text" json:"progress"`
+	ProgressAt       int64        `gorm:"column:public_progress_updated_at;default:0" json:"progressUpdatedAt"`
 	CreatedAt        time.Time    `gorm:"column:created_at" json:"createdAt"`
 	UpdatedAt        time.Time    `gorm:"column:updated_at" json:"updatedAt"`
 }
 
 // TableName 指定表名
-func (Task) TableName() string {
-	return "sys_tasks"
+func (Task) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleQueue, "tasks", "sys_tasks")
+}
+
+// ProgressSnapshot 解析最后一次持久化的进度快照
+func (a *Task) ProgressSnapshot() queue.Progresses {
+	if a.Progress == "" {
+		return nil
+	}
+
+	var progress queue.Progresses
+	if err := json.Unmarshal([]byte(a.Progress), &progress); err != nil {
+		return nil
+	}
+	return progress
+}
+
+// NewTaskFromModel 由任务存储中的模型转换，字段与数据库表的列一致
+func NewTaskFromModel(model *queue.TaskModel) *Task {
+	task := &Task{
+		ID:               model.ID,
+		Type:             model.Type,
+		Status:           model.Status,
+		CorrelationID:    model.CorrelationID.String(),
+		OwnerID:          model.OwnerID,
+		PrivateState:     model.PrivateState,
+		RetryCount:       model.PublicState.RetryCount,
+		ExecutedDuration: int64(model.PublicState.ExecutedDuration),
+		Error:            model.PublicState.Error,
+		ResumeTime:       model.PublicState.ResumeTime,
+		Priority:         model.PublicState.Priority,
+		ProgressAt:       model.PublicState.ProgressUpdatedAt,
+		CreatedAt:        model.CreatedAt,
+		UpdatedAt:        model.UpdatedAt,
+	}
+	if history, err := model.PublicState.ErrorHistory.Value(); err == nil {
+		task.ErrorHistory, _ = history.(string)
+	}
+	if len(model.PublicState.Progress) > 0 {
+		if progress, err := json.Marshal(model.PublicState.Progress); err == nil {
+			task.Progress = string(progress)
+		}
+	}
+	return task
 }
 
 type Tasks []*Task
@@ -62,8 +111,27 @@ type TaskPageVO struct {
 	Error            string `json:"error"`
 	ErrorHistory     string `json:"errorHistory"`
 	ResumeTime       int64  `json:"resumeTime"`
+	Priority         int    `json:"priority"`
 	CreatedAt        string `json:"createdAt"`
 	UpdatedAt        string `json:"updatedAt"`
+
+	// 最后一次持久化的进度快照（重启后或挂起等待恢复时仍可查看）
+	Progress          queue.Progresses `json:"progress,omitempty"`
+	ProgressUpdatedAt int64            `json:"progressUpdatedAt,omitempty"`
+}
+
+// 队列任务推送事件类型（/topic/queue/tasks）
+const (
+	TaskEventSnapshot = "snapshot" // 订阅时推送：当前实例中全部任务
+	TaskEventProgress = "progress" // 定时推送：当前实例中全部任务的状态与进度
+	TaskEventFinished = "finished" // 任务离开队列（完成、失败或取消），状态为持久化的最终状态
+)
+
+// TaskProgressEvent 队列任务推送消息
+type TaskProgressEvent struct {
+	Type      string            `json:"type"`
+	Tasks     []*TaskProgressVO `json:"tasks"`
+	Timestamp int64             `json:"timestamp"`
 }
 
 // ToPageVOList 转换为分页视图对象列表
@@ -71,18 +139,21 @@ func (list Tasks) ToPageVOList() []*TaskPageVO {
 	result := make([]*TaskPageVO, 0, len(list))
 	for _, item := range list {
 		result = append(result, &TaskPageVO{
-			ID:               item.ID,
-			Type:             item.Type,
-			Status:           string(item.Status),
-			CorrelationID:    item.CorrelationID,
-			OwnerID:          item.OwnerID,
-			RetryCount:       item.RetryCount,
-			ExecutedDuration: item.ExecutedDuration,
-			Error:            item.Error,
-			ErrorHistory:     item.ErrorHistory,
-			ResumeTime:       item.ResumeTime,
-			CreatedAt:        item.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:        item.UpdatedAt.Format("2006-01-02 15:04:05"),
+			ID:                item.ID,
+			Type:              item.Type,
+			Status:            string(item.Status),
+			CorrelationID:     item.CorrelationID,
+			OwnerID:           item.OwnerID,
+			RetryCount:        item.RetryCount,
+			ExecutedDuration:  item.ExecutedDuration,
+			Error:             item.Error,
+			ErrorHistory:      item.ErrorHistory,
+			ResumeTime:        item.ResumeTime,
+			Priority:          item.Priority,
+			CreatedAt:         item.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:         item.UpdatedAt.Format("2006-01-02 15:04:05"),
+			Progress:          item.ProgressSnapshot(),
+			ProgressUpdatedAt: item.ProgressAt,
 		})
 	}
 	return result
@@ -107,3 +178,14 @@ type TaskTypeVO struct {
 	Label string `json:"label"`
 	Value string `json:"value"`
 }
+
+// TaskProgressVO 任务摘要与进度，任务在当前实例运行时为实时数据，否则为最后一次持久化的快照
+type TaskProgressVO struct {
+	ID                uint64           `json:"id"`
+	Type              string           `json:"type"`
+	Status            string           `json:"status"`
+	Live              bool             `json:"live"`
+	Summary           *queue.Summary   `json:"summary,omitempty"`
+	Progress          queue.Progresses `json:"progress,omitempty"`
+	ProgressUpdatedAt int64            `json:"progressUpdatedAt,omitempty"`
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -25,14 +28,19 @@ type User struct {
 	IsDeleted  int          `gorm:"column:is_deleted;default:0;index:idx_is_deleted" json:"isDeleted"`
 	OpenID     string       `gorm:"column:openid;size:28" json:"openid,omitempty"`
 
+	// 两步验证：密钥加密保存，绑定后验证通过前 TwoFactorEnabled 为 false
+	TwoFactorSecret  string `gorm:"column:two_factor_secret;size:255" json:"-"`
+	TwoFactorEnabled bool   `gorm:"column:two_factor_enabled;not null;default:false" json:"twoFactorEnabled"`
+
 	// 非数据库字段
-	RoleIds  []uint64 `gorm:"-" json:"roleIds,omitempty"`
-	DeptName string   `gorm:"-" json:"deptName,omitempty"`
+	RoleIds     []uint64 `gorm:"-" json:"roleIds,omitempty"`
+	PositionIds []uint64 `gorm:"-" json:"positionIds,omitempty"`
+	DeptName    string   `gorm:"-" json:"deptName,omitempty"`
 }
 
 // TableName 指定表名
-func (User) TableName() string {
-	return "t_user"
+func (User) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "user", "t_user")
 }
 
 type Users []*User
@@ -56,8 +64,11 @@ type UserQueryParam struct {
 	Status         *int     `query:"status"`
 	DeptID         uint64   `query:"deptId"`
 	RoleIDs        []uint64 `query:"-"`
+	TagIDs         []uint64 `query:"-"` // 按标签过滤，带有任一标签即匹配
 	CreateTimeFrom string   `query:"createTime[0]"`
 	CreateTimeTo   string   `query:"createTime[1]"`
+
+	DataScope *DataScope `query:"-"` // 按用户所在部门过滤
 }
 
 type UserQueryResult struct {
@@ -80,16 +91,17 @@ func (a Users) ToIDs() []uint64 {
 
 // UserForm 用户表单
 type UserForm struct {
-	ID       uint64   `json:"id"`
-	Username string   `json:"username"`
-	Nickname string   `json:"nickname"`
-	Mobile   string   `json:"mobile"`
-	Gender   int      `json:"gender"`
-	Avatar   string   `json:"avatar"`
-	Email    string   `json:"email"`
-	Status   int      `json:"status"`
-	DeptId   uint64   `json:"deptId"`
-	RoleIds  []uint64 `json:"roleIds"`
+	ID          uint64   `json:"id"`
+	Username    string   `json:"username"`
+	Nickname    string   `json:"nickname"`
+	Mobile      string   `json:"mobile"`
+	Gender      int      `json:"gender"`
+	Avatar      string   `json:"avatar"`
+	Email       string   `json:"email"`
+	Status      int      `json:"status"`
+	DeptId      uint64   `json:"deptId"`
+	RoleIds     []uint64 `json:"roleIds"`
+	PositionIds []uint64 `json:"positionIds"`
 }
 
 // UserOption 用户下拉选项
@@ -118,3 +130,55 @@ func (a Users) ToOptions() []*UserOption {
 	}
 	return options
 }
+
+// 批量用户操作类型
+const (
+	UserBulkEnable       = "enable"        // 批量启用
+	UserBulkDisable      = "disable"       // 批量禁用
+	UserBulkAssignRoles  = "assign_roles"  // 批量追加角色
+	UserBulkRemoveRoles  = "remove_roles"  // 批量移除角色
+	UserBulkTransferDept = "transfer_dept" // 批量调整部门
+	UserBulkSetRoles     = "set_roles"     // 批量设置角色，替换原有角色
+	UserBulkDelete       = "delete"        // 批量删除
+)
+
+// UserBulkForm 批量用户操作表单
+// RoleIDs 用于 assign_roles/remove_roles/set_roles，DeptID 用于 transfer_dept
+type UserBulkForm struct {
+	UserIDs   []uint64 `json:"userIds"`
+	Operation string   `json:"operation" validate:"required"`
+	RoleIDs   []uint64 `json:"roleIds"`
+	DeptID    uint64   `json:"deptId"`
+
+	DataScope *DataScope     `json:"-"` // 超出数据权限的用户被跳过
+	Operator  *dto.JwtClaims `json:"-"` // 当前身份，分配角色时不能授予超出其权限的角色
+}
+
+// UserStatusForm 批量启用/禁用用户表单
+type UserStatusForm struct {
+	UserIDs []uint64 `json:"userIds"`
+	Status  int      `json:"status" validate:"oneof=0 1"`
+}
+
+// UserRolesForm 批量设置用户角色表单，用户原有的角色被替换为 RoleIDs
+type UserRolesForm struct {
+	UserIDs []uint64 `json:"userIds"`
+	RoleIDs []uint64 `json:"roleIds"`
+}
+
+// UserBulkItemResult 单个用户的批量操作结果
+type UserBulkItemResult struct {
+	UserID   uint64 `json:"userId"`
+	Username string `json:"username"`
+	Success  bool   `json:"success"`
+	Message  string `json:"message,omitempty"`
+}
+
+// UserBulkResult 批量用户操作结果
+type UserBulkResult struct {
+	Operation string                `json:"operation"`
+	Total     int                   `json:"total"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []*UserBulkItemResult `json:"results"`
+}
@@ -0,0 +1,82 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 用户定时任务类型
+const (
+	UserJobActivityReport = "activity_report" // 导出本人的登录及接口调用记录
+	UserJobURLDownload    = "url_download"    // 定时下载指定 URL
+)
+
+// 用户定时任务最近一次执行结果
+const (
+	UserJobRunSuccess = "success"
+	UserJobRunFailed  = "failed"
+)
+
+// UserJob 用户自助创建的定时任务，由定时任务调度、以任务所有者身份提交到任务队列执行
+type UserJob struct {
+	ID          uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint64           `gorm:"column:user_id;not null;index:idx_user_job_user" json:"userId"`
+	Name        string           `gorm:"column:name;size:64;not null" json:"name"`
+	TaskType    string           `gorm:"column:task_type;size:32;not null" json:"taskType"`
+	Spec        string           `gorm:"column:spec;size:64;not null" json:"spec"`
+	Payload     database.JSONB   `gorm:"column:payload" json:"payload"`
+	Status      int              `gorm:"column:status;not null;default:1" json:"status"` // 1 启用 0 停用
+	LastRunTime dto.NullDateTime `gorm:"column:last_run_time" json:"lastRunTime"`
+	LastStatus  string           `gorm:"column:last_status;size:20" json:"lastStatus"`
+	LastError   string           `gorm:"column:last_error;size:500" json:"lastError"`
+	RunCount    int64            `gorm:"column:run_count;not null;default:0" json:"runCount"`
+	CreateTime  dto.DateTime     `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateTime  dto.DateTime     `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+
+	NextRunTime dto.NullDateTime `gorm:"-" json:"nextRunTime"` // 下次执行时间，未调度时为空
+}
+
+// TableName 指定表名
+func (UserJob) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleQueue, "user_job", "sys_user_job")
+}
+
+type UserJobs []*UserJob
+
+// UserJobForm 用户定时任务表单，Spec 为 6 位（含秒）cron 表达式
+type UserJobForm struct {
+	Name     string         `json:"name" validate:"required,max=64"`
+	TaskType string         `json:"taskType" validate:"required"`
+	Spec     string         `json:"spec" validate:"required,max=64"`
+	Payload  database.JSONB `json:"payload"`
+	Status   *int           `json:"status"` // 不填时默认启用
+}
+
+// UserJobActivityReportPayload activity_report 任务参数
+type UserJobActivityReportPayload struct {
+	Days   int    `json:"days"`   // 统计最近天数，默认 7，最大 90
+	Format string `json:"format"` // xlsx（默认）、pdf
+}
+
+// UserJobURLDownloadPayload url_download 任务参数
+type UserJobURLDownloadPayload struct {
+	URL        string `json:"url"`
+	Downloader string `json:"downloader"` // 可选，不填则使用默认下载器
+}
+
+// UserJobTypeVO 可用任务类型
+type UserJobTypeVO struct {
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	Example     interface{} `json:"example"` // 参数示例
+}
+
+// UserJobCatalogVO 任务类型目录及配额
+type UserJobCatalogVO struct {
+	Types       []*UserJobTypeVO `json:"types"`
+	MaxPerUser  int              `json:"maxPerUser"`
+	MinInterval int              `json:"minInterval"` // 最小执行间隔（分钟）
+	Used        int64            `json:"used"`        // 已创建数量
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -18,8 +21,8 @@ type UserNotice struct {
 }
 
 // TableName 指定表名
-func (UserNotice) TableName() string {
-	return "t_user_notice"
+func (UserNotice) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "user_notice", "t_user_notice")
 }
 
 type UserNotices []*UserNotice
@@ -0,0 +1,28 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+)
+
+// UserPosition 用户岗位关联模型
+type UserPosition struct {
+	UserID     uint64 `gorm:"column:user_id;primaryKey" json:"userId"`
+	PositionID uint64 `gorm:"column:position_id;primaryKey;index:idx_user_position_position" json:"positionId"`
+}
+
+// TableName 指定表名
+func (UserPosition) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "user_position", "t_user_position")
+}
+
+type UserPositions []*UserPosition
+
+func (a UserPositions) ToPositionIDs() []uint64 {
+	list := make([]uint64, len(a))
+	for i, item := range a {
+		list[i] = item.PositionID
+	}
+	return list
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
 	"github.com/top-system/light-admin/models/dto"
 )
 
@@ -11,8 +14,8 @@ type UserRole struct {
 }
 
 // TableName 指定表名
-func (UserRole) TableName() string {
-	return "t_user_role"
+func (UserRole) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "user_role", "t_user_role")
 }
 
 type UserRoles []*UserRole
@@ -0,0 +1,160 @@
+package system
+
+import (
+	"strings"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// Webhook 事件
+const (
+	WebhookEventUserCreated       = "user.created"
+	WebhookEventUserDisabled      = "user.disabled"
+	WebhookEventDownloadCompleted = "download.completed"
+	WebhookEventDownloadFailed    = "download.failed"
+	WebhookEventNoticePublished   = "notice.published"
+	WebhookEventCronFailed        = "cron.failed"
+	WebhookEventPing              = "ping" // 测试投递，总是发送，不需要订阅
+
+	// WebhookEventAll 订阅全部事件
+	WebhookEventAll = "*"
+)
+
+// WebhookEvents 可订阅的事件及说明
+var WebhookEvents = []*WebhookEventOption{
+	{Event: WebhookEventUserCreated, Description: "新增用户"},
+	{Event: WebhookEventUserDisabled, Description: "禁用用户"},
+	{Event: WebhookEventDownloadCompleted, Description: "下载任务完成"},
+	{Event: WebhookEventDownloadFailed, Description: "下载任务出错"},
+	{Event: WebhookEventNoticePublished, Description: "通知公告发布"},
+	{Event: WebhookEventCronFailed, Description: "定时任务执行失败"},
+}
+
+// 投递状态
+const (
+	WebhookDeliveryPending  = "pending"  // 等待投递
+	WebhookDeliveryRetrying = "retrying" // 投递失败，等待重试
+	WebhookDeliverySuccess  = "success"
+	WebhookDeliveryFailed   = "failed" // 重试次数用尽或 Webhook 已删除、停用
+)
+
+// Webhook 事件回调地址，订阅的事件发生时以签名的 POST 请求推送
+// Events 为逗号分隔的事件名，* 表示全部事件；Status: 1-启用 0-停用
+type Webhook struct {
+	ID         uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name       string       `gorm:"column:name;size:64;not null" json:"name"`
+	URL        string       `gorm:"column:url;size:500;not null" json:"url"`
+	Secret     string       `gorm:"column:secret;size:128" json:"-"` // 签名密钥，不返回
+	Events     string       `gorm:"column:events;size:500;not null" json:"events"`
+	Status     int          `gorm:"column:status;default:1" json:"status"`
+	Remark     string       `gorm:"column:remark;size:255" json:"remark"`
+	CreateBy   uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime dto.DateTime `gorm:"column:create_time;autoCreateTime" json:"createTime"`
+	UpdateBy   uint64       `gorm:"column:update_by" json:"updateBy"`
+	UpdateTime dto.DateTime `gorm:"column:update_time;autoUpdateTime" json:"updateTime"`
+
+	HasSecret bool `gorm:"-" json:"hasSecret"` // 是否已设置密钥，密钥本身不返回
+}
+
+// TableName 指定表名
+func (Webhook) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleSystem, "webhook", "t_webhook")
+}
+
+// Subscribes 是否订阅了事件
+func (a *Webhook) Subscribes(event string) bool {
+	if event == WebhookEventPing {
+		return true
+	}
+	for _, item := range strings.Split(a.Events, ",") {
+		item = strings.TrimSpace(item)
+		if item == WebhookEventAll || item == event {
+			return true
+		}
+	}
+	return false
+}
+
+type Webhooks []*Webhook
+
+// WebhookDelivery 一次事件投递，记录最后一次尝试的请求与响应，用于排查
+// 重新投递时创建新的记录，EventID 与原记录相同
+type WebhookDelivery struct {
+	ID              uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID       uint64           `gorm:"column:webhook_id;not null;index:idx_webhook_delivery_webhook" json:"webhookId"`
+	EventID         string           `gorm:"column:event_id;size:36;not null;index:idx_webhook_delivery_event" json:"eventId"`
+	Event           string           `gorm:"column:event;size:64;not null" json:"event"`
+	URL             string           `gorm:"column:url;size:500" json:"url"` // 投递时的地址
+	Payload         string           `gorm:"column:payload;type:text" json:"payload"`
+	Status          string           `gorm:"column:status;size:16;not null;index:idx_webhook_delivery_status" json:"status"`
+	Attempts        int              `gorm:"column:attempts;default:0" json:"attempts"`
+	ResponseStatus  int              `gorm:"column:response_status" json:"responseStatus"`
+	ResponseBody    string           `gorm:"column:response_body;type:text" json:"responseBody"`
+	Error           string           `gorm:"column:error;size:500" json:"error"`
+	Duration        int64            `gorm:"column:duration" json:"duration"` // 最后一次请求耗时（毫秒）
+	QueueTaskID     int              `gorm:"column:queue_task_id" json:"queueTaskId"`
+	LastAttemptTime dto.NullDateTime `gorm:"column:last_attempt_time" json:"lastAttemptTime"`
+	CreateTime      dto.DateTime     `gorm:"column:create_time;autoCreateTime;index:idx_webhook_delivery_create_time" json:"createTime"`
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "webhook_delivery", "sys_webhook_delivery")
+}
+
+type WebhookDeliveries []*WebhookDelivery
+
+// WebhookEventOption 可订阅的事件
+type WebhookEventOption struct {
+	Event       string `json:"event"`
+	Description string `json:"description"`
+}
+
+// WebhookQueryParam Webhook 查询参数
+type WebhookQueryParam struct {
+	dto.PaginationParam
+
+	Keywords string `query:"keywords"` // 名称或地址
+	Status   *int   `query:"status"`
+}
+
+// WebhookQueryResult Webhook 查询结果
+type WebhookQueryResult struct {
+	List       Webhooks        `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// WebhookForm Webhook 表单，修改时 secret 为空表示保持不变；新增时为空则自动生成
+type WebhookForm struct {
+	Name   string   `json:"name" validate:"required,max=64"`
+	URL    string   `json:"url" validate:"required,url,max=500"`
+	Secret string   `json:"secret" validate:"max=128"`
+	Events []string `json:"events" validate:"required,min=1"`
+	Status int      `json:"status" validate:"oneof=0 1"`
+	Remark string   `json:"remark" validate:"max=255"`
+}
+
+// WebhookCreatedVO 新增 Webhook 的结果，Secret 只在此返回一次
+type WebhookCreatedVO struct {
+	ID     uint64 `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryQueryParam 投递记录查询参数
+type WebhookDeliveryQueryParam struct {
+	dto.PaginationParam
+
+	WebhookID uint64 `query:"webhookId"`
+	Event     string `query:"event"`
+	Status    string `query:"status"`
+	EventID   string `query:"eventId"`
+}
+
+// WebhookDeliveryQueryResult 投递记录查询结果
+type WebhookDeliveryQueryResult struct {
+	List       WebhookDeliveries `json:"list"`
+	Pagination *dto.Pagination   `json:"pagination"`
+}
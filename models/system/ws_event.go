@@ -0,0 +1,88 @@
+package system
+
+import (
+	"gorm.io/gorm/schema"
+
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/dto"
+)
+
+// 需要回执的推送事件类型
+const (
+	WsEventNotice            = "notice"
+	WsEventDownloadCompleted = "download_completed"
+	WsEventBanner            = "system_banner"
+	WsEventSecurityAlert     = "security_alert"
+	WsEventExportCompleted   = "export_completed"
+)
+
+// WsEvent 通过 WebSocket 推送、需要客户端回执的事件
+// Audience 为推送对象数，Online 为推送时在线的对象数，只有在线用户能收到推送
+type WsEvent struct {
+	ID          uint64       `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID     string       `gorm:"column:event_id;size:36;not null;uniqueIndex:uk_ws_event_event_id" json:"eventId"`
+	Kind        string       `gorm:"column:kind;size:32;not null;index:idx_ws_event_kind" json:"kind"`
+	Title       string       `gorm:"column:title;size:255" json:"title"`
+	Destination string       `gorm:"column:destination;size:128;not null" json:"destination"`
+	Audience    int          `gorm:"column:audience;not null;default:0" json:"audience"`
+	Online      int          `gorm:"column:online;not null;default:0" json:"online"`
+	CreateBy    uint64       `gorm:"column:create_by" json:"createBy"`
+	CreateTime  dto.DateTime `gorm:"column:create_time;autoCreateTime;index:idx_ws_event_create_time" json:"createTime"`
+
+	Received  int64 `gorm:"-" json:"received"`  // 回执收到的对象数
+	Displayed int64 `gorm:"-" json:"displayed"` // 回执已展示的对象数
+}
+
+// TableName 指定表名
+func (WsEvent) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "ws_event", "sys_ws_event")
+}
+
+type WsEvents []*WsEvent
+
+// WsEventDelivery 事件对单个用户的送达情况，推送时为每个推送对象创建，收到回执时更新
+type WsEventDelivery struct {
+	ID            uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID       string           `gorm:"column:event_id;size:36;not null;uniqueIndex:uk_ws_event_delivery,priority:1" json:"eventId"`
+	UserID        uint64           `gorm:"column:user_id" json:"userId"` // 横幅及回执时补建的记录为 0
+	Username      string           `gorm:"column:username;size:64;not null;uniqueIndex:uk_ws_event_delivery,priority:2" json:"username"`
+	Online        bool             `gorm:"column:online" json:"online"` // 推送时是否在线
+	ReceivedTime  dto.NullDateTime `gorm:"column:received_time" json:"receivedTime"`
+	DisplayedTime dto.NullDateTime `gorm:"column:displayed_time" json:"displayedTime"`
+}
+
+// TableName 指定表名
+func (WsEventDelivery) TableName(namer schema.Namer) string {
+	return database.ModuleTableName(namer, database.ModuleLog, "ws_event_delivery", "sys_ws_event_delivery")
+}
+
+type WsEventDeliveries []*WsEventDelivery
+
+// WsEventQueryParam 推送事件查询参数
+type WsEventQueryParam struct {
+	dto.PaginationParam
+
+	Kind string `query:"kind"`
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+// WsEventQueryResult 推送事件查询结果
+type WsEventQueryResult struct {
+	List       WsEvents        `json:"list"`
+	Pagination *dto.Pagination `json:"pagination"`
+}
+
+// WsEventDetailVO 推送事件送达详情
+type WsEventDetailVO struct {
+	*WsEvent
+
+	Deliveries WsEventDeliveries `json:"deliveries"`
+}
+
+// WsBannerForm 发布系统横幅，推送给当前全部在线用户
+type WsBannerForm struct {
+	Title   string `json:"title" validate:"required,max=255"`
+	Content string `json:"content" validate:"required"`
+	Level   string `json:"level"` // 与通知等级一致，L-低 M-中 H-高
+}
@@ -0,0 +1,81 @@
+package apimeta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pagination struct {
+	PageNum  int `query:"pageNum"`
+	PageSize int `query:"pageSize"`
+}
+
+type queryParam struct {
+	pagination
+
+	Keywords string   `query:"keywords"`
+	Status   *int     `query:"status"`
+	TagIDs   []uint64 `query:"-"`
+}
+
+type form struct {
+	Name     string    `json:"name" validate:"required,max=64"`
+	RoleIDs  []uint64  `json:"roleIds"`
+	Birthday time.Time `json:"birthday"`
+	Score    float64   `json:"score,omitempty"`
+	Ignored  string    `json:"-"`
+}
+
+func TestPathParams(t *testing.T) {
+	params := PathParams("/api/v1/users/:id/password/reset")
+	assert.Equal(t, []Param{{Name: "id", In: InPath, Type: "string", Required: true}}, params)
+	assert.Empty(t, PathParams("/api/v1/users"))
+}
+
+func TestStructParams(t *testing.T) {
+	params := StructParams(&queryParam{}, InQuery)
+	assert.Equal(t, []Param{
+		{Name: "pageNum", In: InQuery, Type: "integer"},
+		{Name: "pageSize", In: InQuery, Type: "integer"},
+		{Name: "keywords", In: InQuery, Type: "string"},
+		{Name: "status", In: InQuery, Type: "integer"},
+	}, params)
+
+	params = StructParams(form{}, InBody)
+	assert.Equal(t, []Param{
+		{Name: "name", In: InBody, Type: "string", Required: true},
+		{Name: "roleIds", In: InBody, Type: "array"},
+		{Name: "birthday", In: InBody, Type: "string", Format: "date-time"},
+		{Name: "score", In: InBody, Type: "number"},
+	}, params)
+
+	assert.Nil(t, StructParams(nil, InBody))
+	assert.Nil(t, StructParams("x", InBody))
+}
+
+func TestParseHandlerName(t *testing.T) {
+	c, a := ParseHandlerName("github.com/top-system/light-admin/api/system/controller.UserController.Query-fm")
+	assert.Equal(t, "UserController", c)
+	assert.Equal(t, "Query", a)
+
+	c, a = ParseHandlerName("github.com/x/controller.(*FileController).Upload-fm")
+	assert.Equal(t, "FileController", c)
+	assert.Equal(t, "Upload", a)
+
+	c, a = ParseHandlerName("main.handler")
+	assert.Equal(t, "", c)
+	assert.Equal(t, "handler", a)
+}
+
+func TestSchemaOf(t *testing.T) {
+	assert.Equal(t, &Schema{Type: "object"}, SchemaOf(nil))
+	assert.Equal(t, &Schema{Type: "integer"}, SchemaOf(0))
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, SchemaOf(time.Time{}))
+
+	schema := SchemaOf(&form{})
+	assert.Equal(t, "object", schema.Type)
+	assert.Len(t, schema.Fields, 4)
+	assert.Equal(t, Param{Name: "name", In: InBody, Type: "string", Required: true}, schema.Fields[0])
+}
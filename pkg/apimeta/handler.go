@@ -0,0 +1,22 @@
+package apimeta
+
+import "strings"
+
+// ParseHandlerName 从 echo 路由名称（处理函数全名）中解析控制器与方法名
+// 如 github.com/x/api/system/controller.UserController.Query-fm 解析为 UserController、Query
+func ParseHandlerName(name string) (controller, action string) {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+
+	parts := strings.Split(name, ".")
+	switch len(parts) {
+	case 0, 1:
+		return "", name
+	case 2:
+		return "", parts[1]
+	default:
+		return strings.Trim(parts[len(parts)-2], "(*)"), parts[len(parts)-1]
+	}
+}
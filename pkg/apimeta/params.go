@@ -0,0 +1,140 @@
+package apimeta
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 参数位置
+const (
+	InPath  = "path"
+	InQuery = "query"
+	InBody  = "body"
+)
+
+// Param 接口参数描述
+type Param struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Type     string `json:"type"` // string、integer、number、boolean、array、object
+	Format   string `json:"format,omitempty"`
+	Required bool   `json:"required"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// PathParams 解析 echo 路由路径中的参数，如 /users/:id
+func PathParams(path string) []Param {
+	params := make([]Param, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") && len(segment) > 1 {
+			params = append(params, Param{Name: segment[1:], In: InPath, Type: "string", Required: true})
+		} else if segment == "*" {
+			params = append(params, Param{Name: "*", In: InPath, Type: "string", Required: true})
+		}
+	}
+	return params
+}
+
+// StructParams 通过反射解析查询参数或请求体结构的字段
+// in 为 InQuery 时读取 query 标签，否则读取 json 标签；validate 标签含 required 视为必填，
+// 匿名嵌入的结构体（如分页参数）会被展开
+func StructParams(v interface{}, in string) []Param {
+	if v == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	tagKey := "json"
+	if in == InQuery {
+		tagKey = "query"
+	}
+
+	return structParams(t, in, tagKey)
+}
+
+func structParams(t reflect.Type, in, tagKey string) []Param {
+	params := make([]Param, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous && ft.Kind() == reflect.Struct {
+			params = append(params, structParams(ft, in, tagKey)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get(tagKey), ",")[0]
+		if name == "-" || name == "" {
+			continue
+		}
+
+		typ, format := typeOf(ft)
+		params = append(params, Param{
+			Name:     name,
+			In:       in,
+			Type:     typ,
+			Format:   format,
+			Required: hasRule(field.Tag.Get("validate"), "required"),
+		})
+	}
+	return params
+}
+
+// typeOf 将 Go 类型映射为 JSON Schema 基础类型
+func typeOf(t reflect.Type) (string, string) {
+	if t.ConvertibleTo(timeType) {
+		return "string", "date-time"
+	}
+	if t.Kind() == reflect.Struct {
+		// dto.NullDateTime 等包装类型
+		if f, ok := t.FieldByName("Time"); ok && f.Type == timeType {
+			return "string", "date-time"
+		}
+		return "object", ""
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "boolean", ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", ""
+	case reflect.Float32, reflect.Float64:
+		return "number", ""
+	case reflect.Slice, reflect.Array:
+		// json.RawMessage 等原始 JSON
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "object", ""
+		}
+		return "array", ""
+	default:
+		return "object", ""
+	}
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
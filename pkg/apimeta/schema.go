@@ -0,0 +1,30 @@
+package apimeta
+
+import "reflect"
+
+// Schema 消息体结构描述
+// 结构体展开为字段列表（按 json 标签），其余类型只给出基础类型
+type Schema struct {
+	Type   string  `json:"type"`
+	Format string  `json:"format,omitempty"`
+	Fields []Param `json:"fields,omitempty"`
+}
+
+// SchemaOf 通过反射生成示例值的结构描述，nil 表示任意 JSON 对象
+func SchemaOf(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{Type: "object"}
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	typ, format := typeOf(t)
+	schema := &Schema{Type: typ, Format: format}
+	if t.Kind() == reflect.Struct && format == "" {
+		schema.Fields = structParams(t, InBody, "json")
+	}
+	return schema
+}
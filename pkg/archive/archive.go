@@ -0,0 +1,360 @@
+// Package archive lists and extracts zip and tar (optionally gzip or bzip2
+// compressed) archives. Entries are read in place, nothing is unpacked to list
+// an archive.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupported is returned for files that are not a supported archive
+	ErrUnsupported = errors.New("unsupported archive format")
+	// ErrUnsafePath is returned for entries that would be written outside the destination
+	ErrUnsafePath = errors.New("entry path escapes the destination")
+	// ErrLimitExceeded is returned when extracting would exceed the extraction limits
+	ErrLimitExceeded = errors.New("archive exceeds the extraction limits")
+)
+
+// Limits bounds what a single extraction may unpack, guarding against
+// archive bombs. Zero values disable the corresponding check.
+type Limits struct {
+	MaxSize    int64   // total uncompressed bytes written
+	MaxEntries int     // number of selected entries, directories included
+	MaxRatio   float64 // uncompressed bytes written per byte of the archive file
+}
+
+// DefaultLimits are the limits applied by Extract
+var DefaultLimits = Limits{
+	MaxSize:    20 << 30,
+	MaxEntries: 100000,
+	MaxRatio:   100,
+}
+
+// ratioMinSize is the amount written before MaxRatio applies, so small
+// archives of highly compressible text are not rejected
+const ratioMinSize = 1 << 20
+
+// Format is an archive format detected from the file name
+type Format string
+
+const (
+	FormatZip   Format = "zip"
+	FormatTar   Format = "tar"
+	FormatTarGz Format = "tar.gz"
+	FormatTarBz Format = "tar.bz2"
+)
+
+var suffixes = []struct {
+	suffix string
+	format Format
+}{
+	{".zip", FormatZip},
+	{".tar", FormatTar},
+	{".tar.gz", FormatTarGz},
+	{".tgz", FormatTarGz},
+	{".tar.bz2", FormatTarBz},
+	{".tbz2", FormatTarBz},
+}
+
+// Entry is a file or directory inside an archive
+type Entry struct {
+	Name     string    `json:"name"` // slash separated path inside the archive
+	Size     int64     `json:"size"`
+	Dir      bool      `json:"dir"`
+	Modified time.Time `json:"modified"`
+}
+
+// Progress is called while extracting with the bytes written so far and the
+// total size of the selected entries
+type Progress func(current, total int64)
+
+// Detect returns the format of the named file, or false if it is not an archive
+func Detect(name string) (Format, bool) {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format, true
+		}
+	}
+	return "", false
+}
+
+// IsArchive reports whether the named file is a supported archive
+func IsArchive(name string) bool {
+	_, ok := Detect(name)
+	return ok
+}
+
+// TrimExt returns the base name of the archive without its archive suffix
+func TrimExt(name string) string {
+	base := path.Base(filepath.ToSlash(name))
+	lower := strings.ToLower(base)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return base[:len(base)-len(s.suffix)]
+		}
+	}
+	return base
+}
+
+// List returns the entries of the archive at file
+func List(file string) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	err := walk(file, func(e Entry, _ func() (io.ReadCloser, error)) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Extract unpacks the archive at file into dst. When names is not empty only
+// the listed entries and everything below listed directories are extracted.
+// Symlinks and other special entries are skipped. Extraction stops with
+// ErrLimitExceeded once DefaultLimits are exceeded, files written so far are
+// left in place.
+func Extract(ctx context.Context, file, dst string, names []string, progress Progress) error {
+	return extract(ctx, file, dst, names, progress, DefaultLimits)
+}
+
+func extract(ctx context.Context, file, dst string, names []string, progress Progress, limits Limits) error {
+	selected := func(name string) bool {
+		if len(names) == 0 {
+			return true
+		}
+		for _, n := range names {
+			n = strings.TrimSuffix(n, "/")
+			if name == n || strings.HasPrefix(name, n+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	maxSize := limits.MaxSize
+	if limits.MaxRatio > 0 {
+		n := max(int64(limits.MaxRatio*float64(info.Size())), ratioMinSize)
+		if maxSize <= 0 || n < maxSize {
+			maxSize = n
+		}
+	}
+
+	// The declared sizes are checked up front so an oversized archive fails
+	// before anything is written, the actual bytes are still counted below as
+	// headers may lie
+	var total int64
+	count := 0
+	err = walk(file, func(e Entry, _ func() (io.ReadCloser, error)) error {
+		if !selected(e.Name) {
+			return nil
+		}
+		count++
+		if !e.Dir {
+			total += e.Size
+		}
+		return checkLimits(limits, maxSize, count, total)
+	})
+	if err != nil {
+		return err
+	}
+
+	var current int64
+	return walk(file, func(e Entry, open func() (io.ReadCloser, error)) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !selected(e.Name) {
+			return nil
+		}
+
+		target, err := safeJoin(dst, e.Name)
+		if err != nil {
+			return err
+		}
+		if e.Dir {
+			return os.MkdirAll(target, 0755)
+		}
+		if open == nil {
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		src, err := open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		var r io.Reader = src
+		if maxSize > 0 {
+			r = io.LimitReader(src, maxSize-current+1)
+		}
+		n, err := io.Copy(out, r)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", e.Name, err)
+		}
+
+		current += n
+		if maxSize > 0 && current > maxSize {
+			return fmt.Errorf("%w: more than %d bytes", ErrLimitExceeded, maxSize)
+		}
+		if progress != nil {
+			progress(current, total)
+		}
+		return nil
+	})
+}
+
+// checkLimits reports whether count entries with total declared bytes fit in
+// the limits, maxSize combines MaxSize and MaxRatio for the archive
+func checkLimits(limits Limits, maxSize int64, count int, total int64) error {
+	if limits.MaxEntries > 0 && count > limits.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrLimitExceeded, limits.MaxEntries)
+	}
+	if maxSize > 0 && total > maxSize {
+		return fmt.Errorf("%w: more than %d bytes", ErrLimitExceeded, maxSize)
+	}
+	return nil
+}
+
+// safeJoin joins an entry name to dst, rejecting names that escape dst
+func safeJoin(dst, name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if clean == "/" || strings.Contains(name, "\\") {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	target := filepath.Join(dst, filepath.FromSlash(clean))
+	rel, err := filepath.Rel(dst, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+	return target, nil
+}
+
+// walk calls fn for every entry of the archive, open is nil for entries
+// without content
+func walk(file string, fn func(e Entry, open func() (io.ReadCloser, error)) error) error {
+	format, ok := Detect(file)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupported, path.Base(filepath.ToSlash(file)))
+	}
+
+	if format == FormatZip {
+		return walkZip(file, fn)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch format {
+	case FormatTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case FormatTarBz:
+		r = bzip2.NewReader(f)
+	}
+
+	return walkTar(tar.NewReader(r), fn)
+}
+
+func walkZip(file string, fn func(e Entry, open func() (io.ReadCloser, error)) error) error {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		e := Entry{
+			Name:     strings.TrimSuffix(f.Name, "/"),
+			Size:     int64(f.UncompressedSize64),
+			Dir:      f.FileInfo().IsDir(),
+			Modified: f.Modified,
+		}
+
+		var open func() (io.ReadCloser, error)
+		if f.Mode().IsRegular() {
+			open = f.Open
+		} else if !e.Dir {
+			continue
+		}
+
+		if err := fn(e, open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTar(tr *tar.Reader, fn func(e Entry, open func() (io.ReadCloser, error)) error) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		e := Entry{
+			Name:     strings.TrimSuffix(strings.TrimPrefix(hdr.Name, "./"), "/"),
+			Size:     hdr.Size,
+			Modified: hdr.ModTime,
+		}
+
+		var open func() (io.ReadCloser, error)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			e.Dir = true
+			e.Size = 0
+		case tar.TypeReg:
+			open = func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		default:
+			continue
+		}
+		if e.Name == "" || e.Name == "." {
+			continue
+		}
+
+		if err := fn(e, open); err != nil {
+			return err
+		}
+	}
+}
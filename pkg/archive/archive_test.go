@@ -0,0 +1,185 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testFiles = map[string]string{
+	"docs/readme.txt": "hello",
+	"docs/a/b.txt":    "nested",
+	"main.go":         "package main",
+}
+
+func writeZip(t *testing.T, file string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGz(t *testing.T, file string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "docs/", Typeflag: tar.TypeDir, Mode: 0755})
+	tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func TestDetect(t *testing.T) {
+	cases := map[string]Format{
+		"a.zip":     FormatZip,
+		"a.TAR":     FormatTar,
+		"a.tar.gz":  FormatTarGz,
+		"a.tgz":     FormatTarGz,
+		"a.tar.bz2": FormatTarBz,
+	}
+	for name, want := range cases {
+		if got, ok := Detect(name); !ok || got != want {
+			t.Errorf("Detect(%q) = %q, %v", name, got, ok)
+		}
+	}
+	if IsArchive("a.iso") {
+		t.Error("a.iso should not be an archive")
+	}
+	if got := TrimExt("dir/ubuntu.tar.gz"); got != "ubuntu" {
+		t.Errorf("TrimExt = %q", got)
+	}
+}
+
+func TestListAndExtract(t *testing.T) {
+	dir := t.TempDir()
+	zipFile := filepath.Join(dir, "test.zip")
+	tarFile := filepath.Join(dir, "test.tar.gz")
+	writeZip(t, zipFile, testFiles)
+	writeTarGz(t, tarFile, testFiles)
+
+	for _, file := range []string{zipFile, tarFile} {
+		entries, err := List(file)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		files := 0
+		for _, e := range entries {
+			if e.Name == "link" {
+				t.Errorf("%s: symlink should be skipped", file)
+			}
+			if !e.Dir {
+				files++
+				if int64(len(testFiles[e.Name])) != e.Size {
+					t.Errorf("%s: unexpected size %d of %s", file, e.Size, e.Name)
+				}
+			}
+		}
+		if files != len(testFiles) {
+			t.Errorf("%s: expected %d files, got %d", file, len(testFiles), files)
+		}
+
+		dst := filepath.Join(dir, TrimExt(file)+"-out")
+		var current, total int64
+		err = Extract(context.Background(), file, dst, []string{"docs/"}, func(c, n int64) {
+			current, total = c, n
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if want := int64(len("hello") + len("nested")); current != want || total != want {
+			t.Errorf("%s: progress %d/%d, want %d", file, current, total, want)
+		}
+
+		b, err := os.ReadFile(filepath.Join(dst, "docs", "a", "b.txt"))
+		if err != nil || string(b) != "nested" {
+			t.Errorf("%s: unexpected content %q, %v", file, b, err)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "main.go")); !os.IsNotExist(err) {
+			t.Errorf("%s: main.go should not be extracted", file)
+		}
+	}
+}
+
+func TestExtractUnsafePath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "evil.zip")
+	writeZip(t, file, map[string]string{"..": "x"})
+
+	err := Extract(context.Background(), file, filepath.Join(dir, "out"), nil, nil)
+	if !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("expected ErrUnsafePath, got %v", err)
+	}
+
+	// Traversal is confined to the destination
+	writeZip(t, file, map[string]string{"../../escaped.txt": "x"})
+	if err := Extract(context.Background(), file, filepath.Join(dir, "out"), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "escaped.txt")); err != nil {
+		t.Errorf("expected entry inside the destination: %v", err)
+	}
+}
+
+func TestExtractLimits(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "bomb.zip")
+	writeZip(t, file, map[string]string{
+		"a.txt": strings.Repeat("0", 4<<20),
+		"b.txt": "small",
+	})
+
+	cases := map[string]Limits{
+		"size":    {MaxSize: 1 << 20},
+		"entries": {MaxEntries: 1},
+		"ratio":   {MaxRatio: 100},
+	}
+	for name, limits := range cases {
+		dst := filepath.Join(dir, name)
+		err := extract(context.Background(), file, dst, nil, nil, limits)
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: expected ErrLimitExceeded, got %v", name, err)
+		}
+		if _, err := os.Stat(dst); !os.IsNotExist(err) {
+			t.Errorf("%s: nothing should be written when the declared sizes exceed the limits", name)
+		}
+	}
+
+	// Limits apply to the selected entries only
+	err := extract(context.Background(), file, filepath.Join(dir, "selected"), []string{"b.txt"}, nil, Limits{MaxSize: 1 << 20, MaxEntries: 1, MaxRatio: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := extract(context.Background(), file, filepath.Join(dir, "all"), nil, nil, Limits{}); err != nil {
+		t.Fatalf("zero limits should disable the checks: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package clock abstracts time so schedulers can be driven deterministically in tests.
+//
+// Production code uses New, which simply delegates to the time package. Tests use
+// NewFake and move time forward explicitly with Advance, or let every wait return
+// immediately with SetAutoAdvance.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Clock is the subset of the time package used by the queue and crontab
+	Clock interface {
+		// Now returns the current time
+		Now() time.Time
+		// Since returns the time elapsed since t
+		Since(t time.Time) time.Duration
+		// After waits for the duration to elapse and then sends the current time on the returned channel
+		After(d time.Duration) <-chan time.Time
+		// Sleep pauses the current goroutine for at least the duration d
+		Sleep(d time.Duration)
+		// NewTicker returns a ticker firing every d
+		NewTicker(d time.Duration) Ticker
+	}
+
+	// Ticker is a time.Ticker obtained from a Clock
+	Ticker interface {
+		// C returns the channel on which the ticks are delivered
+		C() <-chan time.Time
+		// Stop turns off the ticker
+		Stop()
+	}
+)
+
+// New returns a Clock backed by the time package
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+// Fake is a manually driven Clock. Timers and tickers fire when Advance or Set
+// moves the time past their deadline, in deadline order.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	auto    bool
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+type fakeTimer struct {
+	at     time.Time
+	period time.Duration // zero for one-shot timers
+	ch     chan time.Time
+}
+
+// NewFake returns a Fake clock set to now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, changed: make(chan struct{})}
+}
+
+// SetAutoAdvance makes After and Sleep move the clock forward by the requested
+// duration instead of blocking. Useful with synchronous queue execution where
+// no other goroutine is around to call Advance.
+func (f *Fake) SetAutoAdvance(on bool) {
+	f.mu.Lock()
+	f.auto = on
+	f.mu.Unlock()
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel receiving the fake time once it passed d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	timer := f.addTimer(d, 0)
+	auto := f.auto
+	f.mu.Unlock()
+
+	if auto {
+		f.Advance(d)
+	}
+	return timer.ch
+}
+
+// Sleep blocks until the fake time passed d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTicker returns a ticker firing every d of fake time, ticks are dropped
+// when the receiver is not keeping up, like time.Ticker
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &fakeTicker{clock: f, timer: f.addTimer(d, d)}
+}
+
+// Advance moves the clock forward by d and fires every timer due until then
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.set(f.now.Add(d))
+	f.mu.Unlock()
+}
+
+// Set moves the clock to t, a t before the current time only changes Now
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	f.set(t)
+	f.mu.Unlock()
+}
+
+// Waiters returns the number of pending timers and tickers
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil blocks until at least n timers or tickers are pending, so a test
+// knows the goroutine under test reached its wait before calling Advance
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+// addTimer must be called with the lock held
+func (f *Fake) addTimer(d, period time.Duration) *fakeTimer {
+	timer := &fakeTimer{at: f.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 && period == 0 {
+		timer.ch <- f.now
+		return timer
+	}
+
+	f.timers = append(f.timers, timer)
+	f.notify()
+	return timer
+}
+
+// set must be called with the lock held
+func (f *Fake) set(t time.Time) {
+	for {
+		sort.SliceStable(f.timers, func(i, j int) bool { return f.timers[i].at.Before(f.timers[j].at) })
+		if len(f.timers) == 0 || f.timers[0].at.After(t) {
+			break
+		}
+
+		timer := f.timers[0]
+		if timer.at.After(f.now) {
+			f.now = timer.at
+		}
+		select {
+		case timer.ch <- f.now:
+		default:
+		}
+
+		if timer.period > 0 {
+			timer.at = timer.at.Add(timer.period)
+		} else {
+			f.timers = f.timers[1:]
+		}
+	}
+
+	if t.After(f.now) {
+		f.now = t
+	}
+	f.notify()
+}
+
+// removeTimer must be called with the lock held
+func (f *Fake) removeTimer(timer *fakeTimer) {
+	for i, t := range f.timers {
+		if t == timer {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.notify()
+			return
+		}
+	}
+}
+
+// notify wakes up BlockUntil, must be called with the lock held
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTicker struct {
+	clock *Fake
+	timer *fakeTimer
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.timer.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.removeTimer(t.timer)
+}
@@ -0,0 +1,87 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeAfter(t *testing.T) {
+	f := NewFake(epoch)
+
+	late := f.After(2 * time.Second)
+	early := f.After(time.Second)
+
+	f.Advance(500 * time.Millisecond)
+	select {
+	case <-early:
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	f.Advance(2 * time.Second)
+	if got := <-early; !got.Equal(epoch.Add(time.Second)) {
+		t.Fatalf("early fired at %s", got)
+	}
+	if got := <-late; !got.Equal(epoch.Add(2 * time.Second)) {
+		t.Fatalf("late fired at %s", got)
+	}
+	if got := f.Now(); !got.Equal(epoch.Add(2500 * time.Millisecond)) {
+		t.Fatalf("Now = %s", got)
+	}
+	if f.Waiters() != 0 {
+		t.Fatalf("expected no pending timers, got %d", f.Waiters())
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Minute)
+
+	for i := 1; i <= 3; i++ {
+		f.Advance(time.Minute)
+		if got := <-ticker.C(); !got.Equal(epoch.Add(time.Duration(i) * time.Minute)) {
+			t.Fatalf("tick %d at %s", i, got)
+		}
+	}
+
+	ticker.Stop()
+	f.Advance(time.Minute)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker fired")
+	default:
+	}
+}
+
+func TestFakeAutoAdvance(t *testing.T) {
+	f := NewFake(epoch)
+	f.SetAutoAdvance(true)
+
+	f.Sleep(time.Hour)
+	<-f.After(30 * time.Minute)
+
+	if got := f.Now(); !got.Equal(epoch.Add(90 * time.Minute)) {
+		t.Fatalf("Now = %s", got)
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+
+	go func() {
+		f.Sleep(time.Second)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sleeper not woken")
+	}
+}
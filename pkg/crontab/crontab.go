@@ -2,12 +2,17 @@ package crontab
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/robfig/cron/v3"
+
+	"github.com/top-system/light-admin/pkg/clock"
 )
 
 type (
@@ -19,10 +24,19 @@ type (
 
 	// cronRegistration represents a cron task registration
 	cronRegistration struct {
-		name   string
-		spec   string
-		fn     CronTaskFunc
-		enable bool
+		name          string
+		spec          string
+		fn            CronTaskFunc
+		enable        bool
+		timeout       time.Duration
+		skipIfRunning bool
+		state         *taskState
+	}
+
+	// taskState tracks the runs of a task, shared by the copies of its registration
+	taskState struct {
+		running      atomic.Int32
+		lastDuration atomic.Int64
 	}
 
 	// Logger interface for crontab logging
@@ -43,25 +57,75 @@ type (
 		mu            sync.RWMutex
 		started       bool
 		contextData   map[string]interface{}
+		recorder      Recorder
+
+		// Set by WithClock, entries are then driven by the clock instead of the cron runner
+		clock        clock.Clock
+		clockEntries map[cron.EntryID]*clockEntry
+		wake         chan struct{}
+		stop         chan struct{}
+		loopDone     chan struct{}
+	}
+
+	// clockEntry tracks the activations of an entry scheduled on a custom clock
+	clockEntry struct {
+		name     string
+		schedule cron.Schedule
+		job      cron.Job
+		next     time.Time
+		prev     time.Time
+	}
+
+	// Execution describes a finished run of a task
+	Execution struct {
+		Name          string
+		CorrelationID uuid.UUID
+		Manual        bool // started by RunTask
+		Start         time.Time
+		Duration      time.Duration
+		Err           error  // reported by the task through SetError
+		Result        string // summary reported by the task through SetResult
+		Panic         string // recovered panic value, empty if the task did not panic
+	}
+
+	// Recorder receives every finished run, it is called in the goroutine that ran the task
+	Recorder func(Execution)
+
+	// executionState collects what a running task reports about itself
+	executionState struct {
+		mu     sync.Mutex
+		err    error
+		result string
 	}
 
 	// Option configures a Crontab
 	Option func(*Crontab)
 
+	// TaskOption configures a single task
+	TaskOption func(*cronRegistration)
+
 	// TaskInfo represents information about a scheduled task
 	TaskInfo struct {
-		Name     string        `json:"name"`
-		Spec     string        `json:"spec"`
-		Enable   bool          `json:"enable"`
-		EntryID  cron.EntryID  `json:"entryId"`
-		Next     time.Time     `json:"next"`
-		Prev     time.Time     `json:"prev"`
+		Name          string        `json:"name"`
+		Spec          string        `json:"spec"`
+		Enable        bool          `json:"enable"`
+		EntryID       cron.EntryID  `json:"entryId"`
+		Next          time.Time     `json:"next"`
+		Prev          time.Time     `json:"prev"`
+		Timeout       time.Duration `json:"timeout"`       // zero without WithTimeout
+		SkipIfRunning bool          `json:"skipIfRunning"` // set by WithSkipIfRunning
+		Running       bool          `json:"running"`       // a run, scheduled or manual, is in progress
+		LastDuration  time.Duration `json:"lastDuration"`  // duration of the last finished run, zero if it never ran
 	}
 )
 
+// ErrTaskRunning is returned by RunTask for a WithSkipIfRunning task that is still running
+var ErrTaskRunning = errors.New("crontab: task is still running")
+
 // Context keys
 type (
 	CorrelationIDCtx struct{}
+	executionCtx     struct{}
 	LoggerCtx        struct{}
 	UserCtx          struct{}
 )
@@ -81,21 +145,96 @@ var (
 )
 
 // Register registers a global cron task that will be added to all new Crontab instances
-func Register(name string, spec string, fn CronTaskFunc) {
+func Register(name string, spec string, fn CronTaskFunc, opts ...TaskOption) {
 	globalMu.Lock()
 	defer globalMu.Unlock()
 
-	globalRegistrations = append(globalRegistrations, cronRegistration{
+	globalRegistrations = append(globalRegistrations, newRegistration(name, spec, fn, opts))
+}
+
+// newRegistration creates an enabled registration with its own run state
+func newRegistration(name, spec string, fn CronTaskFunc, opts []TaskOption) cronRegistration {
+	r := cronRegistration{
 		name:   name,
 		spec:   spec,
 		fn:     fn,
 		enable: true,
-	})
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	r.state = &taskState{}
+	return r
+}
+
+// WithTimeout cancels the context of a run after d. The task has to watch
+// ctx.Done() to stop early; a run outliving its timeout is reported as failed.
+func WithTimeout(d time.Duration) TaskOption {
+	return func(r *cronRegistration) {
+		r.timeout = d
+	}
+}
+
+// WithSkipIfRunning skips an activation while the previous run of the task
+// has not finished, so a slow task never runs concurrently with itself
+func WithSkipIfRunning() TaskOption {
+	return func(r *cronRegistration) {
+		r.skipIfRunning = true
+	}
+}
+
+// RegisteredSpecs returns the specs of global cron tasks keyed by task name
+func RegisteredSpecs() map[string]string {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	specs := make(map[string]string, len(globalRegistrations))
+	for _, r := range globalRegistrations {
+		specs[r.name] = r.spec
+	}
+	return specs
+}
+
+// secondsParser is the parser used by New (cron.WithSeconds)
+var secondsParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateSpec checks whether spec is a valid 6-field (with seconds) cron expression
+func ValidateSpec(spec string) error {
+	_, err := secondsParser.Parse(spec)
+	return err
+}
+
+// MinInterval returns the shortest gap between consecutive activations of spec
+// among its next samples runs, used to enforce a frequency limit on user-defined specs
+func MinInterval(spec string, samples int) (time.Duration, error) {
+	schedule, err := secondsParser.Parse(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	if samples < 2 {
+		samples = 2
+	}
+
+	var min time.Duration
+	prev := schedule.Next(time.Now())
+	for i := 1; i < samples; i++ {
+		next := schedule.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if gap := next.Sub(prev); min == 0 || gap < min {
+			min = gap
+		}
+		prev = next
+	}
+
+	return min, nil
 }
 
 // RegisterWithType registers a global cron task with a CronType
-func RegisterWithType(t CronType, spec string, fn CronTaskFunc) {
-	Register(string(t), spec, fn)
+func RegisterWithType(t CronType, spec string, fn CronTaskFunc, opts ...TaskOption) {
+	Register(string(t), spec, fn, opts...)
 }
 
 // New creates a new Crontab instance
@@ -115,6 +254,7 @@ func New(logger Logger, opts ...Option) *Crontab {
 	// Copy global registrations
 	globalMu.Lock()
 	for _, r := range globalRegistrations {
+		r.state = &taskState{}
 		c.registrations = append(c.registrations, r)
 	}
 	globalMu.Unlock()
@@ -139,6 +279,7 @@ func NewWithStandardParser(logger Logger, opts ...Option) *Crontab {
 	// Copy global registrations
 	globalMu.Lock()
 	for _, r := range globalRegistrations {
+		r.state = &taskState{}
 		c.registrations = append(c.registrations, r)
 	}
 	globalMu.Unlock()
@@ -153,8 +294,19 @@ func WithContextData(key string, value interface{}) Option {
 	}
 }
 
+// WithClock drives the schedule with the given clock instead of the wall clock,
+// tests pass a clock.Fake and call Advance (optionally followed by RunDue) to
+// fire tasks deterministically. Due tasks run one after another.
+func WithClock(clk clock.Clock) Option {
+	return func(c *Crontab) {
+		c.clock = clk
+		c.clockEntries = make(map[cron.EntryID]*clockEntry)
+		c.wake = make(chan struct{}, 1)
+	}
+}
+
 // AddTask adds a new cron task
-func (c *Crontab) AddTask(name string, spec string, fn CronTaskFunc) error {
+func (c *Crontab) AddTask(name string, spec string, fn CronTaskFunc, opts ...TaskOption) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -165,12 +317,7 @@ func (c *Crontab) AddTask(name string, spec string, fn CronTaskFunc) error {
 		}
 	}
 
-	reg := cronRegistration{
-		name:   name,
-		spec:   spec,
-		fn:     fn,
-		enable: true,
-	}
+	reg := newRegistration(name, spec, fn, opts)
 
 	c.registrations = append(c.registrations, reg)
 
@@ -183,8 +330,8 @@ func (c *Crontab) AddTask(name string, spec string, fn CronTaskFunc) error {
 }
 
 // AddTaskWithType adds a new cron task with CronType
-func (c *Crontab) AddTaskWithType(t CronType, spec string, fn CronTaskFunc) error {
-	return c.AddTask(string(t), spec, fn)
+func (c *Crontab) AddTaskWithType(t CronType, spec string, fn CronTaskFunc, opts ...TaskOption) error {
+	return c.AddTask(string(t), spec, fn, opts...)
 }
 
 // RemoveTask removes a cron task by name
@@ -210,10 +357,7 @@ func (c *Crontab) RemoveTask(name string) error {
 	c.registrations = newRegs
 
 	// Remove from cron if started
-	if entryID, ok := c.entryIDs[name]; ok {
-		c.cron.Remove(entryID)
-		delete(c.entryIDs, name)
-	}
+	c.unscheduleTask(name)
 
 	c.logger.Info("Cron task %q removed", name)
 	return nil
@@ -261,10 +405,7 @@ func (c *Crontab) DisableTask(name string) error {
 			c.registrations[i].enable = false
 
 			// Remove from cron if started
-			if entryID, ok := c.entryIDs[name]; ok {
-				c.cron.Remove(entryID)
-				delete(c.entryIDs, name)
-			}
+			c.unscheduleTask(name)
 
 			c.logger.Info("Cron task %q disabled", name)
 			return nil
@@ -282,10 +423,7 @@ func (c *Crontab) UpdateTaskSpec(name string, newSpec string) error {
 	for i, r := range c.registrations {
 		if r.name == name {
 			// Remove old entry
-			if entryID, ok := c.entryIDs[name]; ok {
-				c.cron.Remove(entryID)
-				delete(c.entryIDs, name)
-			}
+			c.unscheduleTask(name)
 
 			// Update spec
 			c.registrations[i].spec = newSpec
@@ -325,7 +463,13 @@ func (c *Crontab) Start() error {
 		}
 	}
 
-	c.cron.Start()
+	if c.clock != nil {
+		c.stop = make(chan struct{})
+		c.loopDone = make(chan struct{})
+		go c.runClock(c.stop, c.loopDone)
+	} else {
+		c.cron.Start()
+	}
 	c.started = true
 
 	c.logger.Info("Crontab started with %d tasks", len(c.entryIDs))
@@ -342,21 +486,36 @@ func (c *Crontab) Stop() context.Context {
 	}
 
 	c.logger.Info("Stopping crontab...")
-	ctx := c.cron.Stop()
+	var ctx context.Context
+	if c.clock != nil {
+		close(c.stop)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(context.Background())
+		go func(done <-chan struct{}) {
+			<-done
+			cancel()
+		}(c.loopDone)
+	} else {
+		ctx = c.cron.Stop()
+	}
 	c.started = false
 	c.logger.Info("Crontab stopped")
 
 	return ctx
 }
 
-// RunTask runs a task immediately by name
+// RunTask runs a task immediately by name, it returns ErrTaskRunning
+// instead for a WithSkipIfRunning task whose previous run has not finished
 func (c *Crontab) RunTask(name string) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	for _, r := range c.registrations {
 		if r.name == name {
-			go c.taskWrapper(r.name, r.spec, r.fn)()
+			if r.skipIfRunning && r.state.running.Load() > 0 {
+				return fmt.Errorf("%w: %q", ErrTaskRunning, name)
+			}
+			go c.taskWrapper(r, true)()
 			return nil
 		}
 	}
@@ -377,15 +536,13 @@ func (c *Crontab) GetTasks() []TaskInfo {
 	}
 
 	for _, r := range c.registrations {
-		info := TaskInfo{
-			Name:   r.name,
-			Spec:   r.spec,
-			Enable: r.enable,
-		}
+		info := r.info()
 
 		if entryID, ok := c.entryIDs[r.name]; ok {
 			info.EntryID = entryID
-			if entry, ok := entryMap[entryID]; ok {
+			if e, ok := c.clockEntries[entryID]; ok {
+				info.Next, info.Prev = e.next, e.prev
+			} else if entry, ok := entryMap[entryID]; ok {
 				info.Next = entry.Next
 				info.Prev = entry.Prev
 			}
@@ -404,26 +561,39 @@ func (c *Crontab) GetTask(name string) (*TaskInfo, error) {
 
 	for _, r := range c.registrations {
 		if r.name == name {
-			info := &TaskInfo{
-				Name:   r.name,
-				Spec:   r.spec,
-				Enable: r.enable,
-			}
+			info := r.info()
 
 			if entryID, ok := c.entryIDs[r.name]; ok {
 				info.EntryID = entryID
-				entry := c.cron.Entry(entryID)
-				info.Next = entry.Next
-				info.Prev = entry.Prev
+				if e, ok := c.clockEntries[entryID]; ok {
+					info.Next, info.Prev = e.next, e.prev
+				} else {
+					entry := c.cron.Entry(entryID)
+					info.Next = entry.Next
+					info.Prev = entry.Prev
+				}
 			}
 
-			return info, nil
+			return &info, nil
 		}
 	}
 
 	return nil, fmt.Errorf("crontab: task %q not found", name)
 }
 
+// info returns the task information known without the cron entry
+func (r cronRegistration) info() TaskInfo {
+	return TaskInfo{
+		Name:          r.name,
+		Spec:          r.spec,
+		Enable:        r.enable,
+		Timeout:       r.timeout,
+		SkipIfRunning: r.skipIfRunning,
+		Running:       r.state.running.Load() > 0,
+		LastDuration:  time.Duration(r.state.lastDuration.Load()),
+	}
+}
+
 // IsRunning returns whether the crontab is running
 func (c *Crontab) IsRunning() bool {
 	c.mu.RLock()
@@ -453,20 +623,132 @@ func (c *Crontab) ActiveTaskCount() int {
 
 // scheduleTask schedules a single task (must be called with lock held)
 func (c *Crontab) scheduleTask(r cronRegistration) error {
-	wrappedFn := c.taskWrapper(r.name, r.spec, r.fn)
+	wrappedFn := c.taskWrapper(r, false)
 	entryID, err := c.cron.AddFunc(r.spec, wrappedFn)
 	if err != nil {
 		return fmt.Errorf("failed to add cron task %q with spec %q: %w", r.name, r.spec, err)
 	}
 
 	c.entryIDs[r.name] = entryID
+	if c.clock != nil {
+		entry := c.cron.Entry(entryID)
+		c.clockEntries[entryID] = &clockEntry{
+			name:     r.name,
+			schedule: entry.Schedule,
+			job:      entry.WrappedJob,
+			next:     entry.Schedule.Next(c.clock.Now()),
+		}
+		c.wakeClock()
+	}
+
 	c.logger.Info("Cron task %q scheduled with spec %q", r.name, r.spec)
 	return nil
 }
 
-// taskWrapper wraps a task function with logging and context
-func (c *Crontab) taskWrapper(name, spec string, task CronTaskFunc) func() {
+// unscheduleTask removes the cron entry of a task (must be called with lock held)
+func (c *Crontab) unscheduleTask(name string) {
+	entryID, ok := c.entryIDs[name]
+	if !ok {
+		return
+	}
+
+	c.cron.Remove(entryID)
+	delete(c.entryIDs, name)
+	if c.clock != nil {
+		delete(c.clockEntries, entryID)
+		c.wakeClock()
+	}
+}
+
+// RunDue runs the tasks whose next activation is due on the clock set by WithClock
+// and returns how many ran. Tasks run in the calling goroutine, in activation order.
+// A task missing several activations runs once, like with the cron runner.
+func (c *Crontab) RunDue() int {
+	if c.clock == nil {
+		return 0
+	}
+
+	now := c.clock.Now()
+	c.mu.Lock()
+	due := make([]*clockEntry, 0)
+	for _, e := range c.clockEntries {
+		if e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		due = append(due, &clockEntry{name: e.name, job: e.job, prev: e.next})
+		e.prev = e.next
+		e.next = e.schedule.Next(now)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].prev.Equal(due[j].prev) {
+			return due[i].name < due[j].name
+		}
+		return due[i].prev.Before(due[j].prev)
+	})
+	for _, e := range due {
+		e.job.Run()
+	}
+
+	return len(due)
+}
+
+// runClock fires entries on the custom clock until stop is closed
+func (c *Crontab) runClock(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		var timer <-chan time.Time
+		if next := c.nextActivation(); !next.IsZero() {
+			timer = c.clock.After(next.Sub(c.clock.Now()))
+		}
+
+		select {
+		case <-timer:
+			c.RunDue()
+		case <-c.wake:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// nextActivation returns the earliest next activation, zero without entries
+func (c *Crontab) nextActivation() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var next time.Time
+	for _, e := range c.clockEntries {
+		if !e.next.IsZero() && (next.IsZero() || e.next.Before(next)) {
+			next = e.next
+		}
+	}
+	return next
+}
+
+// wakeClock makes runClock recompute its timer
+func (c *Crontab) wakeClock() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// taskWrapper wraps a task function with logging, context and the task options
+func (c *Crontab) taskWrapper(r cronRegistration, manual bool) func() {
+	name, task := r.name, r.fn
 	return func() {
+		if r.skipIfRunning {
+			if !r.state.running.CompareAndSwap(0, 1) {
+				c.logger.Warning("Cron task %q skipped, the previous run is still running", name)
+				return
+			}
+		} else {
+			r.state.running.Add(1)
+		}
+
 		cid := uuid.Must(uuid.NewV4())
 		c.logger.Info("Executing cron task %q with Cid %q", name, cid)
 
@@ -485,10 +767,21 @@ func (c *Crontab) taskWrapper(name, spec string, task CronTaskFunc) func() {
 			ctx = context.WithValue(ctx, key, value)
 		}
 
+		state := &executionState{}
+		ctx = context.WithValue(ctx, executionCtx{}, state)
+
+		if r.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+		}
+
 		// Execute task with panic recovery
+		var panicked string
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
+					panicked = fmt.Sprint(r)
 					c.logger.Error("Cron task %q panicked: %v", name, r)
 				}
 			}()
@@ -496,7 +789,66 @@ func (c *Crontab) taskWrapper(name, spec string, task CronTaskFunc) func() {
 		}()
 
 		duration := time.Since(startTime)
+		r.state.lastDuration.Store(int64(duration))
+		r.state.running.Add(-1)
 		c.logger.Info("Cron task %q completed in %s", name, duration)
+
+		if r.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.logger.Warning("Cron task %q exceeded its timeout of %s", name, r.timeout)
+			state.mu.Lock()
+			if state.err == nil {
+				state.err = fmt.Errorf("crontab: task %q exceeded its timeout of %s", name, r.timeout)
+			}
+			state.mu.Unlock()
+		}
+
+		c.mu.RLock()
+		recorder := c.recorder
+		c.mu.RUnlock()
+		if recorder != nil {
+			state.mu.Lock()
+			err, result := state.err, state.result
+			state.mu.Unlock()
+
+			recorder(Execution{
+				Name:          name,
+				CorrelationID: cid,
+				Manual:        manual,
+				Start:         startTime,
+				Duration:      duration,
+				Err:           err,
+				Result:        result,
+				Panic:         panicked,
+			})
+		}
+	}
+}
+
+// SetRecorder sets the function that receives every finished run, nil stops recording
+func (c *Crontab) SetRecorder(r Recorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recorder = r
+}
+
+// SetError reports that the running task failed, the error is passed to the recorder.
+// Tasks return nothing, so this is how they surface errors they only logged before.
+// Calling it outside a task does nothing.
+func SetError(ctx context.Context, err error) {
+	if state, ok := ctx.Value(executionCtx{}).(*executionState); ok {
+		state.mu.Lock()
+		state.err = err
+		state.mu.Unlock()
+	}
+}
+
+// SetResult reports a short summary of what the running task did (e.g. the
+// number of purged rows), the summary is passed to the recorder.
+func SetResult(ctx context.Context, result string) {
+	if state, ok := ctx.Value(executionCtx{}).(*executionState); ok {
+		state.mu.Lock()
+		state.result = result
+		state.mu.Unlock()
 	}
 }
 
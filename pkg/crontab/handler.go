@@ -0,0 +1,150 @@
+package crontab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// HandlerFunc runs a task created at runtime, params is the JSON stored with the task
+	HandlerFunc func(ctx context.Context, params json.RawMessage) error
+
+	// Handler describes a kind of task that can be created at runtime (e.g. through the admin API)
+	// and persisted, since only its name and params are stored the function is looked up again on restart
+	Handler struct {
+		Description string
+		// Validate checks params before the task is saved, nil accepts any params
+		Validate func(params json.RawMessage) error
+		Run      HandlerFunc
+	}
+)
+
+var handlers sync.Map
+
+// RegisterHandler registers a runtime task handler, registering the same name again replaces it
+func RegisterHandler(name string, h Handler) {
+	handlers.Store(name, h)
+}
+
+// GetHandler returns the handler registered under name
+func GetHandler(name string) (Handler, bool) {
+	if h, ok := handlers.Load(name); ok {
+		return h.(Handler), true
+	}
+	return Handler{}, false
+}
+
+// Handlers returns the names of all registered handlers in order
+func Handlers() []string {
+	names := make([]string, 0)
+	handlers.Range(func(key, _ interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// HTTPHandlerName is the name of the built-in handler that sends an HTTP request
+const HTTPHandlerName = "http"
+
+const (
+	httpHandlerDefaultTimeout = 30 * time.Second
+	httpHandlerMaxTimeout     = 10 * time.Minute
+)
+
+// HTTPParams are the params of the http handler
+type HTTPParams struct {
+	Method  string            `json:"method"` // GET (default), POST, PUT, DELETE
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	Timeout int               `json:"timeout"` // seconds, default 30, at most 600
+}
+
+func init() {
+	RegisterHandler(HTTPHandlerName, Handler{
+		Description: "Send an HTTP request, responses with status >= 400 are failures",
+		Validate: func(params json.RawMessage) error {
+			_, err := parseHTTPParams(params)
+			return err
+		},
+		Run: runHTTP,
+	})
+}
+
+func parseHTTPParams(raw json.RawMessage) (*HTTPParams, error) {
+	p := new(HTTPParams)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("url is required")
+	}
+	if err := json.Unmarshal(raw, p); err != nil {
+		return nil, fmt.Errorf("invalid params: %w", err)
+	}
+
+	p.Method = strings.ToUpper(p.Method)
+	switch p.Method {
+	case "":
+		p.Method = http.MethodGet
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete:
+	default:
+		return nil, fmt.Errorf("unsupported method: %s", p.Method)
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid url: %s", p.URL)
+	}
+
+	if p.Timeout < 0 || time.Duration(p.Timeout)*time.Second > httpHandlerMaxTimeout {
+		return nil, fmt.Errorf("timeout must be between 0 and %d seconds", int(httpHandlerMaxTimeout.Seconds()))
+	}
+
+	return p, nil
+}
+
+func runHTTP(ctx context.Context, raw json.RawMessage) error {
+	p, err := parseHTTPParams(raw)
+	if err != nil {
+		return err
+	}
+
+	timeout := httpHandlerDefaultTimeout
+	if p.Timeout > 0 {
+		timeout = time.Duration(p.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if p.Body != "" {
+		body = strings.NewReader(p.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, p.Method, p.URL, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
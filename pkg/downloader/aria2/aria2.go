@@ -23,6 +23,8 @@ const (
 	Aria2TempFolder = "aria2"
 	// deleteTempFileDuration is the delay before deleting temp files
 	deleteTempFileDuration = 120 * time.Second
+	// listPageSize is the number of waiting/stopped tasks fetched per call when listing tasks
+	listPageSize = 1000
 )
 
 // Logger is the interface for logging
@@ -87,7 +89,78 @@ func (a *Client) CreateTask(ctx context.Context, url string, options map[string]
 		a.l.Info("Creating aria2 task with url %q saving to %q...", url, path)
 	}
 
-	// Create the download task options
+	gid, err := caller.AddURI(url, a.downloadOptions(path, options))
+	if err != nil || gid == "" {
+		return nil, err
+	}
+
+	return &downloader.TaskHandle{
+		ID: gid,
+	}, nil
+}
+
+// CreateTaskFromFile creates a new download task from an uploaded .torrent or .metalink file
+func (a *Client) CreateTaskFromFile(ctx context.Context, file *downloader.SourceFile, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	kind := file.Kind()
+	if kind != downloader.SourceTorrent && kind != downloader.SourceMetalink {
+		return nil, fmt.Errorf("%w: %q", downloader.ErrUnsupportedFile, file.Name)
+	}
+
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	path := a.tempPath()
+	if a.l != nil {
+		a.l.Info("Creating aria2 task with %s file %q saving to %q...", kind, file.Name, path)
+	}
+
+	// The rpc client uploads the file from disk
+	f, err := os.CreateTemp("", "aria2-*"+filepath.Ext(file.Name))
+	if err != nil {
+		return nil, fmt.Errorf("cannot write %s file: %w", kind, err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(file.Content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot write %s file: %w", kind, err)
+	}
+
+	downloadOptions := a.downloadOptions(path, options)
+	var gid string
+	if kind == downloader.SourceTorrent {
+		// The second parameter are web seed URIs
+		gid, err = caller.AddTorrent(f.Name(), []string{}, downloadOptions)
+	} else {
+		// aria2 creates one task per file of the metalink, the task follows the first one
+		var gids []string
+		gids, err = caller.AddMetalink(f.Name(), downloadOptions)
+		if len(gids) > 0 {
+			gid = gids[0]
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("aria2 rpc error: %w", err)
+	}
+	if gid == "" {
+		return nil, fmt.Errorf("aria2 did not return a task for %q", file.Name)
+	}
+
+	return &downloader.TaskHandle{
+		ID: gid,
+	}, nil
+}
+
+// downloadOptions merges the default and request options of a new task saved to path
+func (a *Client) downloadOptions(path string, options map[string]interface{}) map[string]interface{} {
 	downloadOptions := map[string]interface{}{}
 	for k, v := range a.settings.Options {
 		downloadOptions[k] = v
@@ -97,15 +170,7 @@ func (a *Client) CreateTask(ctx context.Context, url string, options map[string]
 	}
 	downloadOptions["dir"] = path
 	downloadOptions["follow-torrent"] = "mem"
-
-	gid, err := caller.AddURI(url, downloadOptions)
-	if err != nil || gid == "" {
-		return nil, err
-	}
-
-	return &downloader.TaskHandle{
-		ID: gid,
-	}, nil
+	return downloadOptions
 }
 
 // Info returns the status of a download task
@@ -124,21 +189,8 @@ func (a *Client) Info(ctx context.Context, handle *downloader.TaskHandle) (*down
 		return nil, fmt.Errorf("aria2 rpc error: %w", err)
 	}
 
-	state := downloader.StatusDownloading
-	switch status.Status {
-	case "active":
-		if status.BitTorrent.Mode != "" && status.CompletedLength == status.TotalLength {
-			state = downloader.StatusSeeding
-		} else {
-			state = downloader.StatusDownloading
-		}
-	case "waiting", "paused":
-		state = downloader.StatusDownloading
-	case "complete":
-		state = downloader.StatusCompleted
-	case "error":
-		state = downloader.StatusError
-	case "cancelled", "removed":
+	state := stateOf(status)
+	if state == downloader.StatusUnknown {
 		if a.l != nil {
 			a.l.Debug("Task %q is cancelled", handle.ID)
 		}
@@ -282,6 +334,63 @@ func (a *Client) SetFilesToDownload(ctx context.Context, handle *downloader.Task
 	return err
 }
 
+// Pause pauses a download task, aria2 keeps it in the waiting list until it is resumed
+func (a *Client) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	if _, err := caller.Pause(handle.ID); err != nil {
+		return fmt.Errorf("aria2 rpc error: %w", err)
+	}
+
+	return nil
+}
+
+// Resume resumes a paused download task
+func (a *Client) Resume(ctx context.Context, handle *downloader.TaskHandle) error {
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	if _, err := caller.Unpause(handle.ID); err != nil {
+		return fmt.Errorf("aria2 rpc error: %w", err)
+	}
+
+	return nil
+}
+
+// SetSpeedLimit changes max-download-limit and max-upload-limit of a task, 0 means unrestricted
+func (a *Client) SetSpeedLimit(ctx context.Context, handle *downloader.TaskHandle, downloadKBps, uploadKBps int64) error {
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	if _, err := caller.ChangeOption(handle.ID, map[string]interface{}{
+		"max-download-limit": fmt.Sprintf("%dK", downloadKBps),
+		"max-upload-limit":   fmt.Sprintf("%dK", uploadKBps),
+	}); err != nil {
+		return fmt.Errorf("aria2 rpc error: %w", err)
+	}
+
+	return nil
+}
+
 // Test tests the connection to aria2
 func (a *Client) Test(ctx context.Context) (string, error) {
 	caller := a.caller
@@ -301,6 +410,138 @@ func (a *Client) Test(ctx context.Context) (string, error) {
 	return version.Version, nil
 }
 
+// SaveSession saves the current session to the file configured by --save-session
+func (a *Client) SaveSession(ctx context.Context) (*downloader.SessionInfo, error) {
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	if _, err := caller.SaveSession(); err != nil {
+		return nil, fmt.Errorf("aria2 rpc error: %w", err)
+	}
+
+	return a.sessionInfo(caller)
+}
+
+// SessionInfo returns the current session ID and session file
+func (a *Client) SessionInfo(ctx context.Context) (*downloader.SessionInfo, error) {
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	return a.sessionInfo(caller)
+}
+
+func (a *Client) sessionInfo(caller rpc.Client) (*downloader.SessionInfo, error) {
+	session, err := caller.GetSessionInfo()
+	if err != nil {
+		return nil, fmt.Errorf("aria2 rpc error: %w", err)
+	}
+
+	info := &downloader.SessionInfo{ID: session.Id}
+	if options, err := caller.GetGlobalOption(); err == nil {
+		if file, ok := options["save-session"].(string); ok {
+			info.File = file
+		}
+	}
+
+	return info, nil
+}
+
+// ListTasks returns active, waiting and stopped tasks, removed tasks are skipped
+// Metadata tasks of magnet links (followed by the actual download) are skipped as well
+func (a *Client) ListTasks(ctx context.Context) ([]downloader.SessionTask, error) {
+	caller := a.caller
+	if caller == nil {
+		var err error
+		caller, err = rpc.New(ctx, a.settings.Server, a.settings.Token, a.timeout, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create rpc client: %w", err)
+		}
+	}
+
+	statuses, err := caller.TellActive()
+	if err != nil {
+		return nil, fmt.Errorf("aria2 rpc error: %w", err)
+	}
+	for _, tell := range []func(offset, num int, keys ...string) ([]rpc.StatusInfo, error){caller.TellWaiting, caller.TellStopped} {
+		for offset := 0; ; offset += listPageSize {
+			page, err := tell(offset, listPageSize)
+			if err != nil {
+				return nil, fmt.Errorf("aria2 rpc error: %w", err)
+			}
+			statuses = append(statuses, page...)
+			if len(page) < listPageSize {
+				break
+			}
+		}
+	}
+
+	tasks := make([]downloader.SessionTask, 0, len(statuses))
+	for _, status := range statuses {
+		state := stateOf(status)
+		if state == downloader.StatusUnknown || len(status.FollowedBy) > 0 {
+			continue
+		}
+
+		total, _ := strconv.ParseInt(status.TotalLength, 10, 64)
+		downloaded, _ := strconv.ParseInt(status.CompletedLength, 10, 64)
+		task := downloader.SessionTask{
+			Handle:     downloader.TaskHandle{ID: status.Gid, Hash: status.InfoHash},
+			Following:  status.Following,
+			Name:       status.BitTorrent.Info.Name,
+			State:      state,
+			Total:      total,
+			Downloaded: downloaded,
+			SavePath:   filepath.ToSlash(status.Dir),
+		}
+		if status.InfoHash != "" {
+			task.URL = "magnet:?xt=urn:btih:" + status.InfoHash
+		} else if len(status.Files) > 0 && len(status.Files[0].URIs) > 0 {
+			task.URL = status.Files[0].URIs[0].URI
+		}
+		if task.Name == "" && len(status.Files) == 1 {
+			task.Name = path.Base(filepath.ToSlash(status.Files[0].Path))
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// stateOf converts the aria2 task status, removed tasks are reported as StatusUnknown
+func stateOf(status rpc.StatusInfo) downloader.Status {
+	switch status.Status {
+	case "active":
+		if status.BitTorrent.Mode != "" && status.CompletedLength == status.TotalLength {
+			return downloader.StatusSeeding
+		}
+		return downloader.StatusDownloading
+	case "complete":
+		return downloader.StatusCompleted
+	case "error":
+		return downloader.StatusError
+	case "paused":
+		return downloader.StatusPaused
+	case "cancelled", "removed":
+		return downloader.StatusUnknown
+	default:
+		// waiting
+		return downloader.StatusDownloading
+	}
+}
+
 func (a *Client) tempPath() string {
 	guid, _ := uuid.NewV4()
 
@@ -0,0 +1,78 @@
+package aria2
+
+import (
+	"testing"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/downloader/aria2/rpc"
+)
+
+func TestStateOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		status rpc.StatusInfo
+		want   downloader.Status
+	}{
+		{"active", rpc.StatusInfo{Status: "active", TotalLength: "10", CompletedLength: "5"}, downloader.StatusDownloading},
+		{"seeding", rpc.StatusInfo{Status: "active", TotalLength: "10", CompletedLength: "10", BitTorrent: rpc.BitTorrentInfo{Mode: "single"}}, downloader.StatusSeeding},
+		{"http complete length", rpc.StatusInfo{Status: "active", TotalLength: "10", CompletedLength: "10"}, downloader.StatusDownloading},
+		{"waiting", rpc.StatusInfo{Status: "waiting"}, downloader.StatusDownloading},
+		{"paused", rpc.StatusInfo{Status: "paused"}, downloader.StatusPaused},
+		{"complete", rpc.StatusInfo{Status: "complete"}, downloader.StatusCompleted},
+		{"error", rpc.StatusInfo{Status: "error"}, downloader.StatusError},
+		{"removed", rpc.StatusInfo{Status: "removed"}, downloader.StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := stateOf(tt.status); got != tt.want {
+			t.Errorf("%s: stateOf() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNotifyURL(t *testing.T) {
+	tests := []struct {
+		server string
+		want   string
+	}{
+		{"http://localhost:6800/jsonrpc", "ws://localhost:6800/jsonrpc"},
+		{"https://aria2.example.com/jsonrpc", "wss://aria2.example.com/jsonrpc"},
+		{"ws://localhost:6800/jsonrpc", "ws://localhost:6800/jsonrpc"},
+	}
+
+	for _, tt := range tests {
+		if got, err := notifyURL(tt.server); err != nil || got != tt.want {
+			t.Errorf("notifyURL(%q) = %q, %v, want %q", tt.server, got, err, tt.want)
+		}
+	}
+	if _, err := notifyURL("ftp://localhost:6800"); err == nil {
+		t.Error("notifyURL() should reject unsupported schemes")
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	var events []downloader.Event
+	n := notifier{handler: func(e downloader.Event) {
+		events = append(events, e)
+	}}
+
+	n.OnDownloadStart([]rpc.Event{{Gid: "a"}})
+	n.OnDownloadComplete([]rpc.Event{{Gid: "b"}, {Gid: "c"}})
+	n.OnDownloadError([]rpc.Event{{Gid: "d"}})
+	n.OnBtDownloadComplete([]rpc.Event{{Gid: "e"}})
+
+	want := []downloader.Event{
+		{Handle: downloader.TaskHandle{ID: "b"}, State: downloader.StatusCompleted},
+		{Handle: downloader.TaskHandle{ID: "c"}, State: downloader.StatusCompleted},
+		{Handle: downloader.TaskHandle{ID: "d"}, State: downloader.StatusError},
+		{Handle: downloader.TaskHandle{ID: "e"}, State: downloader.StatusSeeding},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
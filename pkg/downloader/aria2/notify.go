@@ -0,0 +1,113 @@
+package aria2
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/downloader/aria2/rpc"
+)
+
+// notifyCheckInterval is how often the notification connection is checked, it is re-established when lost
+const notifyCheckInterval = 30 * time.Second
+
+// notifier forwards the aria2 notifications of finished tasks
+type notifier struct {
+	handler func(downloader.Event)
+}
+
+func (n notifier) OnDownloadStart(events []rpc.Event) {}
+func (n notifier) OnDownloadPause(events []rpc.Event) {}
+func (n notifier) OnDownloadStop(events []rpc.Event)  {}
+
+func (n notifier) OnDownloadComplete(events []rpc.Event) {
+	n.emit(events, downloader.StatusCompleted)
+}
+
+func (n notifier) OnDownloadError(events []rpc.Event) {
+	n.emit(events, downloader.StatusError)
+}
+
+func (n notifier) OnBtDownloadComplete(events []rpc.Event) {
+	n.emit(events, downloader.StatusSeeding)
+}
+
+func (n notifier) emit(events []rpc.Event, state downloader.Status) {
+	for _, event := range events {
+		n.handler(downloader.Event{
+			Handle: downloader.TaskHandle{ID: event.Gid},
+			State:  state,
+		})
+	}
+}
+
+// notifyURL returns the WebSocket address of the RPC server, aria2 serves both on the same port
+func notifyURL(server string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	case "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported rpc scheme %q", u.Scheme)
+	}
+	return u.String(), nil
+}
+
+// Watch listens to aria2 notifications over WebSocket and calls handler for completed and failed tasks
+func (a *Client) Watch(ctx context.Context, handler func(downloader.Event)) error {
+	uri, err := notifyURL(a.settings.Server)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(notifyCheckInterval)
+	defer ticker.Stop()
+
+	var caller rpc.Client
+	defer func() {
+		if caller != nil {
+			caller.Close()
+		}
+	}()
+
+	for {
+		if caller == nil {
+			c, err := rpc.New(ctx, uri, a.settings.Token, a.timeout, notifier{handler: handler})
+			if err != nil {
+				if a.l != nil {
+					a.l.Warning("Cannot connect to aria2 notifications at %q: %s", uri, err)
+				}
+			} else {
+				caller = c
+				if a.l != nil {
+					a.l.Info("Listening to aria2 notifications at %q", uri)
+				}
+			}
+		} else if _, err := caller.GetVersion(); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if a.l != nil {
+				a.l.Warning("Lost aria2 notification connection: %s, reconnecting...", err)
+			}
+			caller.Close()
+			caller = nil
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
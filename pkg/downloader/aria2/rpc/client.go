@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/top-system/light-admin/pkg/downloader"
 )
 
 // Client is the aria2 RPC client interface
@@ -475,6 +477,7 @@ type caller interface {
 type httpCaller struct {
 	uri    string
 	c      *http.Client
+	ctx    context.Context
 	cancel context.CancelFunc
 	wg     *sync.WaitGroup
 	once   sync.Once
@@ -495,7 +498,7 @@ func newHTTPCaller(ctx context.Context, u *url.URL, timeout time.Duration, notif
 	}
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(ctx)
-	h := &httpCaller{uri: u.String(), c: c, cancel: cancel, wg: &wg}
+	h := &httpCaller{uri: u.String(), c: c, ctx: ctx, cancel: cancel, wg: &wg}
 	if notifier != nil {
 		h.setNotifier(ctx, *u, notifier)
 	}
@@ -576,7 +579,17 @@ func (h httpCaller) Call(method string, params, reply interface{}) (err error) {
 	if err != nil {
 		return
 	}
-	r, err := h.c.Post(h.uri, "application/json", payload)
+	// requests are canceled together with the context the caller was created with,
+	// which also carries the span of the operation that created it
+	ctx, span := downloader.StartRPCSpan(h.ctx, "aria2", method)
+	defer func() { downloader.EndRPCSpan(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.uri, payload)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	r, err := h.c.Do(req)
 	if err != nil {
 		return
 	}
@@ -589,6 +602,7 @@ func (h httpCaller) Call(method string, params, reply interface{}) (err error) {
 type websocketCaller struct {
 	conn     *websocket.Conn
 	sendChan chan *sendRequest
+	ctx      context.Context
 	cancel   context.CancelFunc
 	wg       *sync.WaitGroup
 	once     sync.Once
@@ -597,7 +611,7 @@ type websocketCaller struct {
 
 func newWebsocketCaller(ctx context.Context, uri string, timeout time.Duration, notifier Notifier) (*websocketCaller, error) {
 	var header = http.Header{}
-	conn, _, err := websocket.DefaultDialer.Dial(uri, header)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, uri, header)
 	if err != nil {
 		return nil, err
 	}
@@ -605,7 +619,7 @@ func newWebsocketCaller(ctx context.Context, uri string, timeout time.Duration,
 	sendChan := make(chan *sendRequest, 16)
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(ctx)
-	w := &websocketCaller{conn: conn, wg: &wg, cancel: cancel, sendChan: sendChan, timeout: timeout}
+	w := &websocketCaller{conn: conn, wg: &wg, ctx: ctx, cancel: cancel, sendChan: sendChan, timeout: timeout}
 	processor := NewResponseProcessor()
 	wg.Add(1)
 	go func() {
@@ -689,7 +703,7 @@ func (w *websocketCaller) Close() (err error) {
 }
 
 func (w websocketCaller) Call(method string, params, reply interface{}) (err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	ctx, cancel := context.WithTimeout(w.ctx, w.timeout)
 	defer cancel()
 	select {
 	case w.sendChan <- &sendRequest{cancel: cancel, request: &clientRequest{
@@ -708,6 +722,10 @@ func (w websocketCaller) Call(method string, params, reply interface{}) (err err
 		if err := ctx.Err(); err == context.DeadlineExceeded {
 			return err
 		}
+		// the context of the caller ended before the response arrived
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
 	}
 	return
 }
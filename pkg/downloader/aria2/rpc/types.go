@@ -19,6 +19,7 @@ type StatusInfo struct {
 	ErrorCode       string         `json:"errorCode"`       // Error code if any
 	ErrorMessage    string         `json:"errorMessage"`    // Error message
 	FollowedBy      []string       `json:"followedBy"`      // List of GIDs generated as result of this download
+	Following       string         `json:"following"`       // GID of the download this download is following (e.g. metadata download of a magnet link)
 	BelongsTo       string         `json:"belongsTo"`       // GID of parent download
 	Dir             string         `json:"dir"`             // Directory to save files
 	Files           []FileInfo     `json:"files"`           // List of files
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/gob"
 	"fmt"
+	"path/filepath"
+	"strings"
 )
 
 var (
 	// ErrTaskNotFound is returned when task is not found
 	ErrTaskNotFound = fmt.Errorf("task not found")
+	// ErrUnsupportedFile is returned when a downloader cannot create a task from the uploaded file type
+	ErrUnsupportedFile = fmt.Errorf("unsupported file")
 )
 
 type (
@@ -22,6 +26,12 @@ type (
 		Cancel(ctx context.Context, handle *TaskHandle) error
 		// SetFilesToDownload sets the files to download for the task with the given handle
 		SetFilesToDownload(ctx context.Context, handle *TaskHandle, args ...*SetFileToDownloadArgs) error
+		// Pause pauses the task with the given handle, the downloaded data is kept
+		Pause(ctx context.Context, handle *TaskHandle) error
+		// Resume resumes the paused task with the given handle
+		Resume(ctx context.Context, handle *TaskHandle) error
+		// SetSpeedLimit limits the download and upload speed of the task in KB/s, 0 removes the limit
+		SetSpeedLimit(ctx context.Context, handle *TaskHandle, downloadKBps, uploadKBps int64) error
 		// Test tests the connection to the downloader
 		Test(ctx context.Context) (string, error)
 	}
@@ -67,12 +77,73 @@ type (
 		Index    int  `json:"index"`
 		Download bool `json:"download"`
 	}
+
+	// SessionManager is implemented by downloaders that persist their task list in a session file
+	// (e.g. aria2 --save-session), so the tasks can be restored after the downloader restarts
+	SessionManager interface {
+		// SaveSession writes the current task list to the session file
+		SaveSession(ctx context.Context) (*SessionInfo, error)
+		// SessionInfo returns the current session
+		SessionInfo(ctx context.Context) (*SessionInfo, error)
+		// ListTasks returns all tasks known to the downloader, including stopped ones still in memory
+		ListTasks(ctx context.Context) ([]SessionTask, error)
+	}
+
+	// Watcher is implemented by downloaders that push task events (e.g. aria2 over its WebSocket RPC),
+	// so finished tasks are noticed without waiting for the next poll
+	Watcher interface {
+		// Watch calls handler when a task completes or fails until ctx is done, the connection is
+		// re-established when it is lost
+		Watch(ctx context.Context, handler func(Event)) error
+	}
+
+	// FileCreator is implemented by downloaders that create tasks from uploaded .torrent or .metalink
+	// files, so the source does not need to be reachable by URL
+	FileCreator interface {
+		// CreateTaskFromFile creates a task from the file content and options, returns ErrUnsupportedFile
+		// for file kinds the downloader does not accept
+		CreateTaskFromFile(ctx context.Context, file *SourceFile, options map[string]interface{}) (*TaskHandle, error)
+	}
+
+	// SourceFile is an uploaded file a task is created from
+	SourceFile struct {
+		Name    string `json:"name"`
+		Content []byte `json:"content,omitempty"`
+	}
+
+	// SourceKind is the kind of an uploaded source file
+	SourceKind string
+
+	// Event represents a task event pushed by the downloader
+	Event struct {
+		Handle TaskHandle `json:"handle"`
+		State  Status     `json:"state"` // completed, seeding (BitTorrent download completed) or error
+	}
+
+	// SessionInfo represents the downloader session
+	SessionInfo struct {
+		ID   string `json:"id"`
+		File string `json:"file,omitempty"` // Session file path on the downloader host, empty if not configured
+	}
+
+	// SessionTask represents a task listed from the downloader session
+	SessionTask struct {
+		Handle     TaskHandle `json:"handle"`
+		Following  string     `json:"following,omitempty"` // ID of the task this task was created from, e.g. magnet metadata download
+		Name       string     `json:"name"`
+		URL        string     `json:"url,omitempty"`
+		State      Status     `json:"state"`
+		Total      int64      `json:"total"`
+		Downloaded int64      `json:"downloaded"`
+		SavePath   string     `json:"save_path,omitempty"`
+	}
 )
 
 // Download status constants
 const (
 	StatusDownloading Status = "downloading"
 	StatusSeeding     Status = "seeding"
+	StatusPaused      Status = "paused"
 	StatusCompleted   Status = "completed"
 	StatusError       Status = "error"
 	StatusUnknown     Status = "unknown"
@@ -80,6 +151,13 @@ const (
 	DownloaderCtxKey = "downloader"
 )
 
+// Source file kinds
+const (
+	SourceTorrent  SourceKind = "torrent"
+	SourceMetalink SourceKind = "metalink"
+	SourceUnknown  SourceKind = ""
+)
+
 func init() {
 	gob.Register(TaskHandle{})
 	gob.Register(TaskStatus{})
@@ -103,7 +181,24 @@ func (s *TaskStatus) IsError() bool {
 	return s.State == StatusError
 }
 
+// IsPaused returns true if the download is paused
+func (s *TaskStatus) IsPaused() bool {
+	return s.State == StatusPaused
+}
+
 // IsActive returns true if the download is active
 func (s *TaskStatus) IsActive() bool {
 	return s.State == StatusDownloading || s.State == StatusSeeding
 }
+
+// Kind returns the kind of the file by its extension
+func (f *SourceFile) Kind() SourceKind {
+	switch strings.ToLower(filepath.Ext(f.Name)) {
+	case ".torrent":
+		return SourceTorrent
+	case ".metalink", ".meta4":
+		return SourceMetalink
+	default:
+		return SourceUnknown
+	}
+}
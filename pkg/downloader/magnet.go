@@ -0,0 +1,98 @@
+package downloader
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMagnet is returned when a magnet link has no usable BitTorrent info hash
+var ErrInvalidMagnet = fmt.Errorf("invalid magnet link")
+
+// btmhSHA256Prefix is the multihash prefix of a BitTorrent v2 info hash (sha2-256, 32 bytes)
+const btmhSHA256Prefix = "1220"
+
+// Magnet holds the fields of a magnet link that can be read without contacting peers
+type Magnet struct {
+	Hash     string   `json:"hash"` // lower case hex v1 info hash, or the v2 SHA-256 info hash for v2-only links
+	Name     string   `json:"name"`
+	Size     int64    `json:"size"`
+	Trackers []string `json:"trackers"`
+}
+
+// ParseMagnet parses a magnet link, the info hash may be hex or base32 encoded
+func ParseMagnet(link string) (*Magnet, error) {
+	u, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(u.Scheme, "magnet") {
+		return nil, ErrInvalidMagnet
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, ErrInvalidMagnet
+	}
+
+	m := &Magnet{
+		Name:     query.Get("dn"),
+		Trackers: query["tr"],
+	}
+	if xl, err := strconv.ParseInt(query.Get("xl"), 10, 64); err == nil && xl > 0 {
+		m.Size = xl
+	}
+
+	// hybrid links carry both hashes, the v1 hash is preferred as it is understood by every downloader
+	var v2 string
+	for _, xt := range query["xt"] {
+		if len(xt) < 9 {
+			continue
+		}
+		switch strings.ToLower(xt[:9]) {
+		case "urn:btih:":
+			if hash, ok := decodeInfoHash(xt[9:]); ok {
+				m.Hash = hash
+				return m, nil
+			}
+		case "urn:btmh:":
+			if hash, ok := decodeMultihash(xt[9:]); ok && v2 == "" {
+				v2 = hash
+			}
+		}
+	}
+
+	if v2 != "" {
+		m.Hash = v2
+		return m, nil
+	}
+	return nil, ErrInvalidMagnet
+}
+
+// decodeMultihash decodes a hex multihash, only the SHA-256 hashes used by BitTorrent v2 are accepted
+func decodeMultihash(s string) (string, bool) {
+	if len(s) != len(btmhSHA256Prefix)+64 || !strings.HasPrefix(s, btmhSHA256Prefix) {
+		return "", false
+	}
+	if _, err := hex.DecodeString(s[len(btmhSHA256Prefix):]); err != nil {
+		return "", false
+	}
+	return strings.ToLower(s[len(btmhSHA256Prefix):]), true
+}
+
+func decodeInfoHash(s string) (string, bool) {
+	switch len(s) {
+	case 40:
+		if _, err := hex.DecodeString(s); err != nil {
+			return "", false
+		}
+		return strings.ToLower(s), true
+	case 32:
+		b, err := base32.StdEncoding.DecodeString(strings.ToUpper(s))
+		if err != nil {
+			return "", false
+		}
+		return hex.EncodeToString(b), true
+	}
+	return "", false
+}
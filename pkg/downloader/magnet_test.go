@@ -0,0 +1,59 @@
+package downloader
+
+import "testing"
+
+func TestParseMagnet(t *testing.T) {
+	m, err := ParseMagnet("magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A&dn=ubuntu.iso&xl=1024&tr=udp%3A%2F%2Ftracker.example.com%3A80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Hash != "c12fe1c06bba254a9dc9f519b335aa7c1367a88a" {
+		t.Errorf("unexpected hash %q", m.Hash)
+	}
+	if m.Name != "ubuntu.iso" || m.Size != 1024 {
+		t.Errorf("unexpected name %q or size %d", m.Name, m.Size)
+	}
+	if len(m.Trackers) != 1 || m.Trackers[0] != "udp://tracker.example.com:80" {
+		t.Errorf("unexpected trackers %v", m.Trackers)
+	}
+
+	// base32 encoded hash
+	m, err = ParseMagnet("magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Hash != "c12fe1c06bba254a9dc9f519b335aa7c1367a88a" {
+		t.Errorf("unexpected hash %q", m.Hash)
+	}
+
+	// v2-only link
+	v2 := "caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e"
+	m, err = ParseMagnet("magnet:?xt=urn:btmh:1220" + v2 + "&dn=bittorrent-v2-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Hash != v2 || m.Name != "bittorrent-v2-test" {
+		t.Errorf("unexpected hash %q or name %q", m.Hash, m.Name)
+	}
+
+	// hybrid link prefers the v1 hash
+	m, err = ParseMagnet("magnet:?xt=urn:btmh:1220" + v2 + "&xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Hash != "c12fe1c06bba254a9dc9f519b335aa7c1367a88a" {
+		t.Errorf("unexpected hash %q", m.Hash)
+	}
+
+	for _, link := range []string{
+		"https://example.com/a.torrent",
+		"magnet:?dn=missing-hash",
+		"magnet:?xt=urn:btih:not-a-hash",
+		"magnet:?xt=urn:btmh:1114" + v2[:40],
+		"magnet:?xt=urn:btmh:1220" + v2[:62] + "zz",
+	} {
+		if _, err := ParseMagnet(link); err != ErrInvalidMagnet {
+			t.Errorf("%s: expected ErrInvalidMagnet, got %v", link, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Package native downloads plain HTTP/HTTPS URLs in process, so small installs do not need an external daemon.
+// Servers that accept ranged requests are downloaded in concurrent segments, interrupted segments are
+// resumed from where they stopped, also after a restart of the application.
+package native
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/netguard"
+)
+
+const (
+	// NativeTempFolder is the subfolder name for native downloads
+	NativeTempFolder = "native"
+	// Version is reported by Test
+	Version = "native/1.0"
+
+	// DefaultSegments is the number of concurrent segments of a download
+	DefaultSegments = 4
+	// DefaultMinSegmentSize files smaller than two segments of this size are downloaded in one request
+	DefaultMinSegmentSize = 4 * 1024 * 1024
+	// maxRetries is how often a failed segment is retried before the task fails
+	maxRetries = 5
+)
+
+// ErrNotSupported is returned for operations a plain HTTP download does not offer, e.g. selecting files
+var ErrNotSupported = errors.New("not supported by the native downloader")
+
+// Logger is the interface for logging
+type Logger interface {
+	Info(format string, args ...interface{})
+	Debug(format string, args ...interface{})
+	Warning(format string, args ...interface{})
+	Error(format string, args ...interface{})
+}
+
+// Settings contains native downloader settings
+type Settings struct {
+	// TempPath is the base path for downloads, each task is saved in its own folder
+	TempPath string
+	// Segments is the number of concurrent segments of a download, DefaultSegments if 0
+	Segments int
+	// MinSegmentSize is the minimal size of a segment in bytes, DefaultMinSegmentSize if 0
+	MinSegmentSize int64
+	// Options are default options for all downloads: out (file name), segments, user-agent
+	Options map[string]interface{}
+}
+
+// Client implements the Downloader interface by downloading in process
+type Client struct {
+	l          Logger
+	settings   *Settings
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	tasks map[string]*task
+}
+
+// New creates a new native downloader
+func New(l Logger, settings *Settings) downloader.Downloader {
+	// URLs are user supplied: only public addresses are reached and redirects are not followed.
+	// Only the connection is limited, the body of large files may take long to read
+	transport := netguard.NewTransport(10 * time.Second)
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	transport.MaxIdleConnsPerHost = DefaultSegments
+
+	return newClient(l, settings, &http.Client{
+		Transport:     transport,
+		CheckRedirect: netguard.NoRedirect,
+	})
+}
+
+func newClient(l Logger, settings *Settings, httpClient *http.Client) *Client {
+	return &Client{
+		l:          l,
+		settings:   settings,
+		httpClient: httpClient,
+		tasks:      make(map[string]*task),
+	}
+}
+
+// CreateTask starts downloading the URL in the background
+func (c *Client) CreateTask(ctx context.Context, taskURL string, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	u, err := url.Parse(taskURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("native downloader only supports http and https urls: %q", taskURL)
+	}
+
+	opts := make(map[string]interface{})
+	for _, o := range []map[string]interface{}{c.settings.Options, options} {
+		for k, v := range o {
+			opts[k] = v
+		}
+	}
+
+	// The file name is user supplied, only its base name is used and it must stay in the task folder
+	name := optionString(opts, "out")
+	if name != "" {
+		if name = safeName(name); name == "" {
+			return nil, fmt.Errorf("invalid file name: %q", optionString(opts, "out"))
+		}
+	}
+
+	id := uuid.Must(uuid.NewV4()).String()
+	dir := c.taskPath(id)
+	if !inDir(dir, name) {
+		return nil, fmt.Errorf("file name %q leaves the download folder", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create download folder: %w", err)
+	}
+
+	t := newTask(c, dir, &taskState{
+		URL:          taskURL,
+		Name:         name,
+		UserAgent:    optionString(opts, "user-agent"),
+		SegmentCount: c.segments(opts),
+		State:        downloader.StatusDownloading,
+	})
+	if err := t.save(); err != nil {
+		return nil, fmt.Errorf("cannot save task state: %w", err)
+	}
+
+	if c.l != nil {
+		c.l.Info("Creating native task with url %q saving to %q...", taskURL, dir)
+	}
+
+	c.mu.Lock()
+	c.tasks[id] = t
+	c.mu.Unlock()
+	t.start()
+
+	return &downloader.TaskHandle{ID: id}, nil
+}
+
+// Info returns the status of a task, tasks of a previous run are restored from their folder and resumed
+func (c *Client) Info(ctx context.Context, handle *downloader.TaskHandle) (*downloader.TaskStatus, error) {
+	t, err := c.get(handle.ID)
+	if err != nil {
+		return nil, err
+	}
+	return t.status(), nil
+}
+
+// Cancel stops the task and deletes its folder
+func (c *Client) Cancel(ctx context.Context, handle *downloader.TaskHandle) error {
+	t, err := c.get(handle.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get task: %w", err)
+	}
+
+	t.stop()
+	c.mu.Lock()
+	delete(c.tasks, handle.ID)
+	c.mu.Unlock()
+
+	if err := os.RemoveAll(t.dir); err != nil && c.l != nil {
+		c.l.Warning("Failed to delete download folder: %q: %s", t.dir, err)
+	}
+	return nil
+}
+
+// SetFilesToDownload is not supported, a task downloads a single file
+func (c *Client) SetFilesToDownload(ctx context.Context, handle *downloader.TaskHandle, args ...*downloader.SetFileToDownloadArgs) error {
+	return fmt.Errorf("select files: %w", ErrNotSupported)
+}
+
+// Pause stops downloading, the downloaded segments are kept until the task is resumed
+func (c *Client) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	t, err := c.get(handle.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get task: %w", err)
+	}
+	return t.pause()
+}
+
+// Resume continues a paused task from where it stopped
+func (c *Client) Resume(ctx context.Context, handle *downloader.TaskHandle) error {
+	t, err := c.get(handle.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get task: %w", err)
+	}
+	return t.resume()
+}
+
+// SetSpeedLimit limits the download speed of the task, the upload limit is ignored
+func (c *Client) SetSpeedLimit(ctx context.Context, handle *downloader.TaskHandle, downloadKBps, uploadKBps int64) error {
+	t, err := c.get(handle.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get task: %w", err)
+	}
+	return t.setSpeedLimit(downloadKBps)
+}
+
+// Test checks that the download folder is writable
+func (c *Client) Test(ctx context.Context) (string, error) {
+	base := c.basePath()
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return "", fmt.Errorf("download folder is not writable: %w", err)
+	}
+	f, err := os.CreateTemp(base, ".test-*")
+	if err != nil {
+		return "", fmt.Errorf("download folder is not writable: %w", err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return Version, nil
+}
+
+// get returns the running task or restores it from its folder
+func (c *Client) get(id string) (*task, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.tasks[id]; ok {
+		return t, nil
+	}
+
+	dir := c.taskPath(id)
+	state, err := loadState(dir)
+	if err != nil {
+		return nil, fmt.Errorf("task %q: %w", id, downloader.ErrTaskNotFound)
+	}
+
+	t := newTask(c, dir, state)
+	c.tasks[id] = t
+	if state.State == downloader.StatusDownloading {
+		if c.l != nil {
+			c.l.Info("Resuming native task %q from %q...", id, dir)
+		}
+		t.start()
+	}
+	return t, nil
+}
+
+func (c *Client) basePath() string {
+	base := c.settings.TempPath
+	if base == "" {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, NativeTempFolder)
+}
+
+func (c *Client) taskPath(id string) string {
+	// The ID comes from the handle, keep it inside the download folder
+	return filepath.Join(c.basePath(), filepath.Base(filepath.Clean("/"+id)))
+}
+
+// segments returns the number of segments of a new task
+func (c *Client) segments(opts map[string]interface{}) int {
+	n := c.settings.Segments
+	if v := optionString(opts, "segments"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			n = i
+		}
+	}
+	if n <= 0 {
+		n = DefaultSegments
+	}
+	return n
+}
+
+func (c *Client) minSegmentSize() int64 {
+	if c.settings.MinSegmentSize > 0 {
+		return c.settings.MinSegmentSize
+	}
+	return DefaultMinSegmentSize
+}
+
+func optionString(opts map[string]interface{}, key string) string {
+	v, ok := opts[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,352 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+)
+
+var content = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c := newClient(nil, &Settings{TempPath: t.TempDir(), Segments: 4, MinSegmentSize: 1024}, server.Client())
+	return c, server
+}
+
+// rangedHandler serves content with support for ranged requests and counts them
+func rangedHandler(ranges *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(ranges, 1)
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}
+}
+
+func waitFor(t *testing.T, c *Client, handle *downloader.TaskHandle, state downloader.Status) *downloader.TaskStatus {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := c.Info(context.Background(), handle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.State == state {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %s, want %s (%s)", status.State, state, status.ErrorMessage)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDownloadSegments(t *testing.T) {
+	var ranges int32
+	c, server := newTestClient(t, rangedHandler(&ranges))
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/files/file.bin?token=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitFor(t, c, handle, downloader.StatusCompleted)
+
+	if status.Name != "file.bin" || status.Total != int64(len(content)) || status.Downloaded != status.Total {
+		t.Errorf("status = %+v", status)
+	}
+	if len(status.Files) != 1 || !status.Files[0].Selected || status.Files[0].Progress != 1 {
+		t.Errorf("files = %+v", status.Files)
+	}
+	if n := atomic.LoadInt32(&ranges); n != 4 {
+		t.Errorf("ranged requests = %d, want 4", n)
+	}
+
+	data, err := os.ReadFile(filepath.Join(status.SavePath, "file.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("downloaded content differs")
+	}
+}
+
+func TestDownloadWithoutRanges(t *testing.T) {
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			t.Errorf("unexpected ranged request")
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="../report.txt"`)
+		_, _ = w.Write(content)
+	})
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/export", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitFor(t, c, handle, downloader.StatusCompleted)
+
+	if status.Name != "report.txt" || status.Downloaded != int64(len(content)) {
+		t.Errorf("status = %+v", status)
+	}
+	data, err := os.ReadFile(filepath.Join(status.SavePath, "report.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("downloaded content differs")
+	}
+}
+
+func TestResume(t *testing.T) {
+	var ranges int32
+	c, server := newTestClient(t, rangedHandler(&ranges))
+
+	// State of a previous run that stopped in the middle of both segments
+	half := int64(len(content) / 2)
+	dir := c.taskPath("previous")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	part := make([]byte, len(content))
+	copy(part[:100], content[:100])
+	copy(part[half:half+200], content[half:half+200])
+	if err := os.WriteFile(filepath.Join(dir, "file.bin"+partSuffix), part, 0644); err != nil {
+		t.Fatal(err)
+	}
+	prev := newTask(c, dir, &taskState{
+		URL:    server.URL + "/file.bin",
+		Name:   "file.bin",
+		Total:  int64(len(content)),
+		Ranged: true,
+		Parts: []*segment{
+			{Start: 0, End: half - 1, Done: 100},
+			{Start: half, End: int64(len(content)) - 1, Done: 200},
+		},
+		State: downloader.StatusDownloading,
+	})
+	if err := prev.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	status := waitFor(t, c, &downloader.TaskHandle{ID: "previous"}, downloader.StatusCompleted)
+	if status.Downloaded != int64(len(content)) {
+		t.Errorf("downloaded = %d", status.Downloaded)
+	}
+	if n := atomic.LoadInt32(&ranges); n != 2 {
+		t.Errorf("ranged requests = %d, want 2", n)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "file.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("resumed content differs")
+	}
+}
+
+func TestDownloadError(t *testing.T) {
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/missing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitFor(t, c, handle, downloader.StatusError)
+	if !strings.Contains(status.ErrorMessage, "404") {
+		t.Errorf("error = %q", status.ErrorMessage)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	release := make(chan struct{})
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			return
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/slow", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Cancel(context.Background(), handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(c.taskPath(handle.ID)); !os.IsNotExist(err) {
+		t.Errorf("task folder was not deleted: %v", err)
+	}
+	if _, err := c.Info(context.Background(), handle); !errors.Is(err, downloader.ErrTaskNotFound) {
+		t.Errorf("Info after cancel: %v", err)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	gate := make(chan struct{})
+	requested := make(chan struct{}, 16)
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requested <- struct{}{}
+			select {
+			case <-gate:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	})
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/file.bin", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-requested
+
+	if err := c.Pause(context.Background(), handle); err != nil {
+		t.Fatal(err)
+	}
+	status := waitFor(t, c, handle, downloader.StatusPaused)
+	if status.DownloadSpeed != 0 {
+		t.Errorf("paused speed = %d", status.DownloadSpeed)
+	}
+	state, err := loadState(c.taskPath(handle.ID))
+	if err != nil || state.State != downloader.StatusPaused {
+		t.Errorf("saved state = %+v, %v", state, err)
+	}
+
+	close(gate)
+	if err := c.Resume(context.Background(), handle); err != nil {
+		t.Fatal(err)
+	}
+	status = waitFor(t, c, handle, downloader.StatusCompleted)
+
+	data, err := os.ReadFile(filepath.Join(status.SavePath, "file.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Error("downloaded content differs")
+	}
+	if err := c.Pause(context.Background(), handle); err == nil {
+		t.Error("pausing a completed task succeeded")
+	}
+}
+
+func TestSpeedLimit(t *testing.T) {
+	gate := make(chan struct{})
+	requested := make(chan struct{}, 16)
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requested <- struct{}{}
+			<-gate
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	})
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/file.bin", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-requested
+
+	// 64 KB at 64 KB/s, the first 32 KB are the burst
+	if err := c.SetSpeedLimit(context.Background(), handle, 64, 0); err != nil {
+		t.Fatal(err)
+	}
+	begin := time.Now()
+	close(gate)
+	waitFor(t, c, handle, downloader.StatusCompleted)
+
+	if elapsed := time.Since(begin); elapsed < 400*time.Millisecond {
+		t.Errorf("download took %s, expected the limit to slow it down", elapsed)
+	}
+	state, err := loadState(c.taskPath(handle.ID))
+	if err != nil || state.SpeedLimit != 64 {
+		t.Errorf("saved state = %+v, %v", state, err)
+	}
+}
+
+func TestCreateTaskInvalidURL(t *testing.T) {
+	c := newClient(nil, &Settings{TempPath: t.TempDir()}, http.DefaultClient)
+	for _, u := range []string{"magnet:?xt=urn:btih:abc", "ftp://host/file", "/relative"} {
+		if _, err := c.CreateTask(context.Background(), u, nil); err == nil {
+			t.Errorf("CreateTask(%q) succeeded", u)
+		}
+	}
+}
+
+func TestCreateTaskOutName(t *testing.T) {
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	})
+
+	handle, err := c.CreateTask(context.Background(), server.URL+"/file.bin", map[string]interface{}{"out": "../../etc/x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitFor(t, c, handle, downloader.StatusCompleted)
+	if status.Name != "x" {
+		t.Errorf("name = %q, want x", status.Name)
+	}
+	if _, err := os.Stat(filepath.Join(status.SavePath, "x")); err != nil {
+		t.Error(err)
+	}
+
+	for _, out := range []string{"..", stateFile} {
+		if _, err := c.CreateTask(context.Background(), server.URL+"/file.bin", map[string]interface{}{"out": out}); err == nil {
+			t.Errorf("CreateTask with out %q succeeded", out)
+		}
+	}
+}
+
+func TestNewRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("loopback server should not be requested")
+	}))
+	defer server.Close()
+
+	c := New(nil, &Settings{TempPath: t.TempDir()}).(*Client)
+	handle, err := c.CreateTask(context.Background(), server.URL+"/file.bin", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := waitFor(t, c, handle, downloader.StatusError)
+	if !strings.Contains(status.ErrorMessage, "not publicly routable") {
+		t.Errorf("error = %q", status.ErrorMessage)
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	cases := map[string]string{
+		"file.zip":       "file.zip",
+		"../../etc/pass": "pass",
+		`..\win\a.exe`:   "a.exe",
+		"..":             "",
+		stateFile:        "",
+	}
+	for in, want := range cases {
+		if got := safeName(in); got != want {
+			t.Errorf("safeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
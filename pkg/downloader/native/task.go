@@ -0,0 +1,569 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+)
+
+const (
+	// stateFile keeps the progress of a task in its folder, so it can be resumed after a restart
+	stateFile = ".native.json"
+	// partSuffix is appended to the file name until the download is complete
+	partSuffix = ".part"
+	// defaultName is used when neither the response nor the URL names the file
+	defaultName = "download"
+
+	progressInterval = time.Second
+	// bufferSize is read from the response at once, it is also the burst of the speed limit
+	bufferSize = 32 * 1024
+)
+
+type (
+	// taskState is the persisted state of a task
+	taskState struct {
+		URL          string            `json:"url"`
+		Name         string            `json:"name"`
+		UserAgent    string            `json:"user_agent,omitempty"`
+		SegmentCount int               `json:"segment_count"`
+		Total        int64             `json:"total"`  // -1 if the server does not report the size
+		Ranged       bool              `json:"ranged"` // the server accepts ranged requests
+		Parts        []*segment        `json:"parts"`  // empty until the first response was received
+		State        downloader.Status `json:"state"`
+		Error        string            `json:"error,omitempty"`
+		SpeedLimit   int64             `json:"speed_limit,omitempty"` // KB/s, 0 if unlimited
+	}
+
+	// segment is a byte range of the file, End is -1 if the size is unknown
+	segment struct {
+		Start int64 `json:"start"`
+		End   int64 `json:"end"`
+		Done  int64 `json:"done"` // accessed atomically while downloading
+	}
+
+	task struct {
+		c   *Client
+		dir string
+
+		mu    sync.Mutex
+		state *taskState
+		speed int64
+		// limiter is shared by all segments of the task
+		limiter *rate.Limiter
+
+		// ctl serializes stopping, pausing and resuming the download
+		ctl    sync.Mutex
+		cancel context.CancelFunc
+		done   chan struct{}
+	}
+)
+
+func newTask(c *Client, dir string, state *taskState) *task {
+	return &task{c: c, dir: dir, state: state, limiter: rate.NewLimiter(speedLimit(state.SpeedLimit), bufferSize)}
+}
+
+// speedLimit converts a limit in KB/s, 0 if unlimited
+func speedLimit(kbps int64) rate.Limit {
+	if kbps <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(kbps * 1024)
+}
+
+// loadState reads the state saved in the task folder
+func loadState(dir string) (*taskState, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	state := new(taskState)
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if !inDir(dir, state.Name) {
+		return nil, fmt.Errorf("file name %q leaves the download folder", state.Name)
+	}
+	return state, nil
+}
+
+// start downloads in the background until the task completes, fails or is stopped
+func (t *task) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	go t.run(ctx)
+}
+
+// stop interrupts the download and waits for it to return
+func (t *task) stop() {
+	t.ctl.Lock()
+	defer t.ctl.Unlock()
+	t.halt()
+}
+
+func (t *task) halt() {
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+	<-t.done
+}
+
+// pause stops the download and keeps the downloaded segments for resume
+func (t *task) pause() error {
+	t.ctl.Lock()
+	defer t.ctl.Unlock()
+
+	t.mu.Lock()
+	state := t.state.State
+	t.mu.Unlock()
+	switch state {
+	case downloader.StatusPaused:
+		return nil
+	case downloader.StatusDownloading:
+	default:
+		return fmt.Errorf("cannot pause %s task", state)
+	}
+
+	t.halt()
+
+	t.mu.Lock()
+	// The download may have finished in the meantime
+	if t.state.State == downloader.StatusDownloading {
+		t.state.State = downloader.StatusPaused
+		t.speed = 0
+	}
+	t.mu.Unlock()
+	return t.save()
+}
+
+// setSpeedLimit changes the speed limit of the running download and saves it for resume
+func (t *task) setSpeedLimit(kbps int64) error {
+	t.mu.Lock()
+	t.state.SpeedLimit = kbps
+	t.mu.Unlock()
+
+	t.limiter.SetLimit(speedLimit(kbps))
+	return t.save()
+}
+
+// resume continues a paused download from the downloaded segments
+func (t *task) resume() error {
+	t.ctl.Lock()
+	defer t.ctl.Unlock()
+
+	t.mu.Lock()
+	state := t.state.State
+	if state == downloader.StatusPaused {
+		t.state.State = downloader.StatusDownloading
+	}
+	t.mu.Unlock()
+	switch state {
+	case downloader.StatusDownloading:
+		return nil
+	case downloader.StatusPaused:
+	default:
+		return fmt.Errorf("cannot resume %s task", state)
+	}
+
+	if err := t.save(); err != nil {
+		return err
+	}
+	t.start()
+	return nil
+}
+
+func (t *task) run(ctx context.Context) {
+	defer close(t.done)
+
+	progressDone := make(chan struct{})
+	go t.trackProgress(progressDone)
+
+	err := t.download(ctx)
+	close(progressDone)
+	if ctx.Err() != nil {
+		// Stopped, the saved state is kept for Cancel to delete
+		return
+	}
+
+	t.mu.Lock()
+	t.speed = 0
+	if err != nil {
+		t.state.State = downloader.StatusError
+		t.state.Error = err.Error()
+	} else {
+		t.state.State = downloader.StatusCompleted
+	}
+	t.mu.Unlock()
+
+	if err != nil && t.c.l != nil {
+		t.c.l.Warning("Native task %q failed: %s", t.state.URL, err)
+	}
+	if err := t.save(); err != nil && t.c.l != nil {
+		t.c.l.Warning("Failed to save native task state: %s", err)
+	}
+}
+
+// trackProgress updates the speed and saves the progress every second
+func (t *task) trackProgress(done chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	last := t.downloaded()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			current := t.downloaded()
+			t.mu.Lock()
+			t.speed = int64(float64(current-last) / progressInterval.Seconds())
+			t.mu.Unlock()
+			last = current
+
+			if err := t.save(); err != nil && t.c.l != nil {
+				t.c.l.Warning("Failed to save native task state: %s", err)
+			}
+		}
+	}
+}
+
+func (t *task) download(ctx context.Context) error {
+	t.mu.Lock()
+	prepared := len(t.state.Parts) > 0
+	t.mu.Unlock()
+	if !prepared {
+		if err := t.prepare(ctx); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	state := t.state
+	parts := state.Parts
+	if !state.Ranged {
+		// A response without ranges can not be resumed, start over
+		atomic.StoreInt64(&parts[0].Done, 0)
+	}
+	t.mu.Unlock()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if !state.Ranged {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(t.partPath(), flags, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open file: %w", err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, part := range parts {
+		part := part
+		g.Go(func() error {
+			return t.fetch(gctx, f, part)
+		})
+	}
+	err = g.Wait()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	if state.Total < 0 {
+		state.Total = t.downloadedLocked()
+	}
+	t.mu.Unlock()
+
+	return os.Rename(t.partPath(), t.filePath())
+}
+
+// prepare requests the size and name of the file and splits it into segments
+func (t *task) prepare(ctx context.Context) error {
+	req, err := t.newRequest(ctx, http.MethodHead)
+	if err != nil {
+		return err
+	}
+
+	total := int64(-1)
+	ranged := false
+	name := ""
+	if resp, err := t.c.httpClient.Do(req); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			if resp.ContentLength > 0 {
+				total = resp.ContentLength
+				ranged = resp.Header.Get("Accept-Ranges") == "bytes"
+			}
+			name = fileName(resp)
+		}
+	}
+	if name == "" {
+		name = urlFileName(t.state.URL)
+	}
+
+	n := int64(1)
+	if ranged {
+		n = int64(t.state.SegmentCount)
+		if limit := total / t.c.minSegmentSize(); n > limit {
+			n = limit
+		}
+		if n < 1 {
+			n = 1
+		}
+	}
+
+	parts := make([]*segment, 0, n)
+	if total < 0 {
+		parts = append(parts, &segment{Start: 0, End: -1})
+	} else {
+		size := total / n
+		for i := int64(0); i < n; i++ {
+			end := (i+1)*size - 1
+			if i == n-1 {
+				end = total - 1
+			}
+			parts = append(parts, &segment{Start: i * size, End: end})
+		}
+	}
+
+	t.mu.Lock()
+	if t.state.Name == "" {
+		t.state.Name = name
+	}
+	t.state.Total = total
+	t.state.Ranged = ranged
+	t.state.Parts = parts
+	t.mu.Unlock()
+
+	return t.save()
+}
+
+// fetch downloads the rest of a segment, ranged segments are retried from where they stopped
+func (t *task) fetch(ctx context.Context, f *os.File, part *segment) error {
+	for attempt := 1; ; attempt++ {
+		err := t.fetchOnce(ctx, f, part)
+		if err == nil || ctx.Err() != nil || !t.state.Ranged || attempt > maxRetries {
+			return err
+		}
+
+		if t.c.l != nil {
+			t.c.l.Debug("Native task %q segment %d failed: %s, retrying...", t.state.URL, part.Start, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * time.Second):
+		}
+	}
+}
+
+func (t *task) fetchOnce(ctx context.Context, f *os.File, part *segment) error {
+	offset := part.Start + atomic.LoadInt64(&part.Done)
+	if part.End >= 0 && offset > part.End {
+		return nil
+	}
+
+	req, err := t.newRequest(ctx, http.MethodGet)
+	if err != nil {
+		return err
+	}
+	want := http.StatusOK
+	if t.state.Ranged {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, part.End))
+		want = http.StatusPartialContent
+	}
+
+	resp, err := t.c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var body io.Reader = resp.Body
+	if part.End >= 0 {
+		body = io.LimitReader(resp.Body, part.End-offset+1)
+	}
+
+	buf := make([]byte, bufferSize)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if werr := t.limiter.WaitN(ctx, n); werr != nil {
+				return werr
+			}
+			if _, werr := f.WriteAt(buf[:n], offset); werr != nil {
+				return werr
+			}
+			offset += int64(n)
+			atomic.AddInt64(&part.Done, int64(n))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if part.End >= 0 && offset <= part.End {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (t *task) newRequest(ctx context.Context, method string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.state.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if t.state.UserAgent != "" {
+		req.Header.Set("User-Agent", t.state.UserAgent)
+	}
+	return req, nil
+}
+
+// status returns the current status of the task
+func (t *task) status() *downloader.TaskStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	downloaded := t.downloadedLocked()
+	total := t.state.Total
+	if total < 0 {
+		total = 0
+	}
+
+	status := &downloader.TaskStatus{
+		Name:          t.state.Name,
+		SavePath:      filepath.ToSlash(t.dir),
+		State:         t.state.State,
+		Total:         total,
+		Downloaded:    downloaded,
+		DownloadSpeed: t.speed,
+		ErrorMessage:  t.state.Error,
+	}
+	if t.state.Name != "" {
+		progress := 0.0
+		if total > 0 {
+			progress = float64(downloaded) / float64(total)
+		}
+		status.Files = []downloader.TaskFile{{
+			Index:    1,
+			Name:     t.state.Name,
+			Size:     total,
+			Progress: progress,
+			Selected: true,
+		}}
+	}
+	return status
+}
+
+// save writes the state to the task folder
+func (t *task) save() error {
+	t.mu.Lock()
+	state := *t.state
+	state.Parts = make([]*segment, len(t.state.Parts))
+	for i, part := range t.state.Parts {
+		state.Parts[i] = &segment{Start: part.Start, End: part.End, Done: atomic.LoadInt64(&part.Done)}
+	}
+	t.mu.Unlock()
+
+	data, err := json.Marshal(&state)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(t.dir, stateFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.dir, stateFile))
+}
+
+func (t *task) downloaded() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.downloadedLocked()
+}
+
+func (t *task) downloadedLocked() int64 {
+	var n int64
+	for _, part := range t.state.Parts {
+		n += atomic.LoadInt64(&part.Done)
+	}
+	return n
+}
+
+func (t *task) filePath() string {
+	return filepath.Join(t.dir, t.state.Name)
+}
+
+func (t *task) partPath() string {
+	return t.filePath() + partSuffix
+}
+
+// fileName returns the file name from the Content-Disposition header or the final URL after redirects
+func fileName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := safeName(params["filename"]); name != "" {
+			return name
+		}
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		return urlFileName(resp.Request.URL.String())
+	}
+	return ""
+}
+
+func urlFileName(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return defaultName
+	}
+	if name := safeName(path.Base(u.Path)); name != "" {
+		return name
+	}
+	return defaultName
+}
+
+// safeName strips directories, so the file stays in the task folder
+func safeName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == ".." || strings.HasPrefix(name, stateFile) {
+		return ""
+	}
+	return name
+}
+
+// inDir reports whether the file name stays in dir once joined, an empty name is not set yet
+func inDir(dir, name string) bool {
+	if name == "" {
+		return true
+	}
+	rel, err := filepath.Rel(dir, filepath.Join(dir, name))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
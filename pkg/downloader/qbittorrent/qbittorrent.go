@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -22,6 +23,9 @@ import (
 )
 
 const (
+	// QBittorrentTempFolder is the subfolder name for qBittorrent downloads
+	QBittorrentTempFolder = "qbittorrent"
+
 	apiPrefix       = "/api/v2"
 	successResponse = "Ok."
 	tagPrefix       = "dl-"
@@ -31,6 +35,9 @@ const (
 )
 
 var (
+	// errEndpointNotFound is returned for API endpoints the qBittorrent version does not offer
+	errEndpointNotFound = errors.New("unexpected status code: 404")
+
 	downloadOptionFormatTypes = map[string]string{
 		"cookie":             "%s",
 		"skip_checking":      "%s",
@@ -40,7 +47,6 @@ var (
 		"dlLimit":            "%.0f",
 		"ratioLimit":         "%f",
 		"seedingTimeLimit":   "%.0f",
-		"autoTMM":            "%t",
 		"sequentialDownload": "%s",
 		"firstLastPiecePrio": "%t",
 	}
@@ -104,6 +110,29 @@ func New(l Logger, settings *Settings) (downloader.Downloader, error) {
 
 // CreateTask creates a new download task
 func (c *Client) CreateTask(ctx context.Context, taskURL string, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	return c.addTask(ctx, taskURL, options, func(formWriter *multipart.Writer) error {
+		return formWriter.WriteField("urls", taskURL)
+	})
+}
+
+// CreateTaskFromFile creates a new download task by uploading a .torrent file
+func (c *Client) CreateTaskFromFile(ctx context.Context, file *downloader.SourceFile, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	if file.Kind() != downloader.SourceTorrent {
+		return nil, fmt.Errorf("%w: qbittorrent only accepts .torrent files", downloader.ErrUnsupportedFile)
+	}
+
+	return c.addTask(ctx, file.Name, options, func(formWriter *multipart.Writer) error {
+		part, err := formWriter.CreateFormFile("torrents", filepath.Base(file.Name))
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(file.Content)
+		return err
+	})
+}
+
+// addTask adds a torrent with the source written by addSource, the task is tagged with a new ID
+func (c *Client) addTask(ctx context.Context, source string, options map[string]interface{}, addSource func(*multipart.Writer) error) (*downloader.TaskHandle, error) {
 	guid, _ := uuid.NewV4()
 
 	// Generate a unique path for the task
@@ -113,19 +142,23 @@ func (c *Client) CreateTask(ctx context.Context, taskURL string, options map[str
 	}
 	path := filepath.Join(
 		base,
-		"qbittorrent",
+		QBittorrentTempFolder,
 		guid.String(),
 	)
 
 	if c.l != nil {
-		c.l.Info("Creating QBitTorrent task with url %q saving to %q...", taskURL, path)
+		c.l.Info("Creating QBitTorrent task with %q saving to %q...", source, path)
 	}
 
 	buffer := bytes.Buffer{}
 	formWriter := multipart.NewWriter(&buffer)
-	_ = formWriter.WriteField("urls", taskURL)
+	if err := addSource(formWriter); err != nil {
+		return nil, fmt.Errorf("create task qbittorrent failed: %w", err)
+	}
 	_ = formWriter.WriteField("savepath", path)
 	_ = formWriter.WriteField("tags", tagPrefix+guid.String())
+	// Automatic torrent management would move the task to its category or the default save path
+	_ = formWriter.WriteField("autoTMM", "false")
 
 	// Apply global options
 	for k, v := range c.settings.Options {
@@ -231,7 +264,9 @@ func (c *Client) Info(ctx context.Context, handle *downloader.TaskHandle) (*down
 	// Combining and converting all info
 	state := downloader.StatusDownloading
 	switch torrents[0].State {
-	case "downloading", "pausedDL", "allocating", "metaDL", "queuedDL", "stalledDL", "checkingDL", "forcedDL", "checkingResumeData", "moving", "forcedMetaDL":
+	case "pausedDL", "stoppedDL":
+		state = downloader.StatusPaused
+	case "downloading", "allocating", "metaDL", "queuedDL", "stalledDL", "checkingDL", "forcedDL", "checkingResumeData", "moving", "forcedMetaDL":
 		state = downloader.StatusDownloading
 	case "uploading", "queuedUP", "stalledUP", "checkingUP", "forcedUP":
 		state = downloader.StatusSeeding
@@ -353,6 +388,53 @@ func (c *Client) SetFilesToDownload(ctx context.Context, handle *downloader.Task
 	return nil
 }
 
+// Pause pauses a download task, qBittorrent 5 renamed torrents/pause to torrents/stop
+func (c *Client) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	if err := c.torrentsAction(ctx, handle.Hash, "torrents/pause", "torrents/stop"); err != nil {
+		return fmt.Errorf("failed to pause task with hash %q: %w", handle.Hash, err)
+	}
+
+	return nil
+}
+
+// Resume resumes a paused download task, qBittorrent 5 renamed torrents/resume to torrents/start
+func (c *Client) Resume(ctx context.Context, handle *downloader.TaskHandle) error {
+	if err := c.torrentsAction(ctx, handle.Hash, "torrents/resume", "torrents/start"); err != nil {
+		return fmt.Errorf("failed to resume task with hash %q: %w", handle.Hash, err)
+	}
+
+	return nil
+}
+
+// SetSpeedLimit sets the download and upload limit of a torrent, 0 means unlimited
+func (c *Client) SetSpeedLimit(ctx context.Context, handle *downloader.TaskHandle, downloadKBps, uploadKBps int64) error {
+	limits := []struct {
+		path string
+		kbps int64
+	}{
+		{"torrents/setDownloadLimit", downloadKBps},
+		{"torrents/setUploadLimit", uploadKBps},
+	}
+
+	for _, limit := range limits {
+		buffer := bytes.Buffer{}
+		formWriter := multipart.NewWriter(&buffer)
+		_ = formWriter.WriteField("hashes", handle.Hash)
+		_ = formWriter.WriteField("limit", fmt.Sprintf("%d", limit.kbps*1024))
+		formWriter.Close()
+
+		headers := http.Header{
+			"Content-Type": []string{formWriter.FormDataContentType()},
+		}
+
+		if _, err := c.request(ctx, http.MethodPost, limit.path, &buffer, headers); err != nil {
+			return fmt.Errorf("failed to set speed limit of task with hash %q: %w", handle.Hash, err)
+		}
+	}
+
+	return nil
+}
+
 // Test tests the connection to qBittorrent
 func (c *Client) Test(ctx context.Context) (string, error) {
 	res, err := c.request(ctx, http.MethodGet, "app/version", nil, nil)
@@ -386,7 +468,31 @@ func (c *Client) setFilePriority(ctx context.Context, hash string, priority int,
 	return nil
 }
 
-func (c *Client) login(ctx context.Context) error {
+// torrentsAction posts the hash to the first of the given endpoints the qBittorrent version offers
+func (c *Client) torrentsAction(ctx context.Context, hash string, paths ...string) error {
+	var err error
+	for _, path := range paths {
+		buffer := bytes.Buffer{}
+		formWriter := multipart.NewWriter(&buffer)
+		_ = formWriter.WriteField("hashes", hash)
+		formWriter.Close()
+
+		headers := http.Header{
+			"Content-Type": []string{formWriter.FormDataContentType()},
+		}
+
+		if _, err = c.request(ctx, http.MethodPost, path, &buffer, headers); !errors.Is(err, errEndpointNotFound) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) login(ctx context.Context) (err error) {
+	ctx, span := downloader.StartRPCSpan(ctx, "qbittorrent", "auth/login")
+	defer func() { downloader.EndRPCSpan(span, err) }()
+
 	form := url.Values{}
 	form.Add("username", c.settings.User)
 	form.Add("password", c.settings.Password)
@@ -420,7 +526,10 @@ func (c *Client) login(ctx context.Context) error {
 	return nil
 }
 
-func (c *Client) request(ctx context.Context, method, path string, body io.Reader, headers http.Header) (string, error) {
+func (c *Client) request(ctx context.Context, method, path string, body io.Reader, headers http.Header) (_ string, err error) {
+	ctx, span := downloader.StartRPCSpan(ctx, "qbittorrent", path)
+	defer func() { downloader.EndRPCSpan(span, err) }()
+
 	fullURL := c.baseURL + "/" + path
 
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
@@ -461,6 +570,10 @@ func (c *Client) request(ctx context.Context, method, path string, body io.Reade
 	case http.StatusUnsupportedMediaType:
 		return "", fmt.Errorf("invalid torrent file")
 
+	case http.StatusNotFound:
+		content, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%w, content: %s", errEndpointNotFound, string(content))
+
 	default:
 		content, _ := io.ReadAll(resp.Body)
 		return "", fmt.Errorf("unexpected status code: %d, content: %s", resp.StatusCode, string(content))
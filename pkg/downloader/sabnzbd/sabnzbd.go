@@ -0,0 +1,320 @@
+package sabnzbd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+)
+
+const (
+	apiPath = "/api"
+	// queue and history of SABnzbd report sizes in MB
+	megabyte = 1024 * 1024
+)
+
+var (
+	// downloadOptionFormatTypes are the options accepted by mode=addurl
+	downloadOptionFormatTypes = map[string]string{
+		"nzbname":  "%s",
+		"cat":      "%s",
+		"priority": "%v", // -100 default, -2 paused, -1 low, 0 normal, 1 high, 2 force
+		"pp":       "%v", // post-processing: 0 none, 1 repair, 2 repair+unpack, 3 repair+unpack+delete
+		"script":   "%s",
+		"password": "%s",
+	}
+
+	// ErrNotSupported is returned for operations SABnzbd does not offer, e.g. selecting files
+	ErrNotSupported = errors.New("not supported by sabnzbd")
+)
+
+// Logger is the interface for logging
+type Logger interface {
+	Info(format string, args ...interface{})
+	Debug(format string, args ...interface{})
+	Warning(format string, args ...interface{})
+	Error(format string, args ...interface{})
+}
+
+// Settings contains SABnzbd connection settings
+type Settings struct {
+	// Server is the SABnzbd Web UI URL (e.g., http://localhost:8080)
+	Server string
+	// APIKey is the API key found in Config > General
+	APIKey string
+	// Category is the default category of new jobs, which decides where SABnzbd saves them
+	Category string
+	// Options are default options for all downloads
+	Options map[string]interface{}
+}
+
+// Client implements the Downloader interface for SABnzbd
+type Client struct {
+	httpClient *http.Client
+	l          Logger
+	settings   *Settings
+	apiURL     string
+}
+
+// New creates a new SABnzbd downloader client
+func New(l Logger, settings *Settings) (downloader.Downloader, error) {
+	serverURL, err := url.Parse(settings.Server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sabnzbd server URL: %w", err)
+	}
+	if serverURL.Scheme != "http" && serverURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid sabnzbd server URL: %q", settings.Server)
+	}
+	serverURL.Path = strings.TrimSuffix(serverURL.Path, "/") + apiPath
+
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		l:        l,
+		settings: settings,
+		apiURL:   serverURL.String(),
+	}, nil
+}
+
+// CreateTask adds the NZB at the given URL, the handle ID is the nzo_id of the job
+func (c *Client) CreateTask(ctx context.Context, taskURL string, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	params := url.Values{}
+	params.Set("name", taskURL)
+	if c.settings.Category != "" {
+		params.Set("cat", c.settings.Category)
+	}
+
+	// Apply global options, then request-specific options
+	for _, opts := range []map[string]interface{}{c.settings.Options, options} {
+		for k, v := range opts {
+			if format, ok := downloadOptionFormatTypes[k]; ok {
+				params.Set(k, fmt.Sprintf(format, v))
+			}
+		}
+	}
+
+	if c.l != nil {
+		c.l.Info("Creating SABnzbd task with url %q...", taskURL)
+	}
+
+	var resp addResponse
+	if err := c.request(ctx, "addurl", params, &resp); err != nil {
+		return nil, fmt.Errorf("create task sabnzbd failed: %w", err)
+	}
+	if !resp.Status || len(resp.NzoIDs) == 0 {
+		return nil, fmt.Errorf("create task sabnzbd failed: %s", resp.Error)
+	}
+
+	return &downloader.TaskHandle{
+		ID: resp.NzoIDs[0],
+	}, nil
+}
+
+// Info returns the status of a job, looked up in the queue first and then in the history
+func (c *Client) Info(ctx context.Context, handle *downloader.TaskHandle) (*downloader.TaskStatus, error) {
+	params := url.Values{}
+	params.Set("nzo_ids", handle.ID)
+
+	var queue QueueResponse
+	if err := c.request(ctx, "queue", params, &queue); err != nil {
+		return nil, fmt.Errorf("failed to get queue: %w", err)
+	}
+	for i, slot := range queue.Queue.Slots {
+		if slot.NzoID == handle.ID {
+			return queueStatus(queue.Queue, i), nil
+		}
+	}
+
+	var history HistoryResponse
+	if err := c.request(ctx, "history", params, &history); err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	for _, slot := range history.History.Slots {
+		if slot.NzoID == handle.ID {
+			return historyStatus(slot), nil
+		}
+	}
+
+	return nil, fmt.Errorf("no job with nzo_id %q: %w", handle.ID, downloader.ErrTaskNotFound)
+}
+
+// Cancel deletes the job and its files from the queue, or from the history if it already finished
+func (c *Client) Cancel(ctx context.Context, handle *downloader.TaskHandle) error {
+	for _, mode := range []string{"queue", "history"} {
+		params := url.Values{}
+		params.Set("name", "delete")
+		params.Set("value", handle.ID)
+		params.Set("del_files", "1")
+
+		var resp addResponse
+		if err := c.request(ctx, mode, params, &resp); err != nil {
+			return fmt.Errorf("failed to cancel task with nzo_id %q: %w", handle.ID, err)
+		}
+		if len(resp.NzoIDs) > 0 {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// SetFilesToDownload is not supported, an NZB job is always downloaded as a whole
+func (c *Client) SetFilesToDownload(ctx context.Context, handle *downloader.TaskHandle, args ...*downloader.SetFileToDownloadArgs) error {
+	return fmt.Errorf("select files: %w", ErrNotSupported)
+}
+
+// Pause pauses a job in the queue, finished jobs can not be paused
+func (c *Client) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	return c.queueAction(ctx, "pause", handle)
+}
+
+// Resume resumes a paused job in the queue
+func (c *Client) Resume(ctx context.Context, handle *downloader.TaskHandle) error {
+	return c.queueAction(ctx, "resume", handle)
+}
+
+// queueAction applies the queue action, e.g. pause or resume, to a job
+func (c *Client) queueAction(ctx context.Context, action string, handle *downloader.TaskHandle) error {
+	params := url.Values{}
+	params.Set("name", action)
+	params.Set("value", handle.ID)
+
+	var resp addResponse
+	if err := c.request(ctx, "queue", params, &resp); err != nil {
+		return fmt.Errorf("failed to %s task with nzo_id %q: %w", action, handle.ID, err)
+	}
+	if !resp.Status || len(resp.NzoIDs) == 0 {
+		return fmt.Errorf("failed to %s task with nzo_id %q: %w", action, handle.ID, downloader.ErrTaskNotFound)
+	}
+
+	return nil
+}
+
+// SetSpeedLimit is not supported, SABnzbd only limits the speed of the whole queue
+func (c *Client) SetSpeedLimit(ctx context.Context, handle *downloader.TaskHandle, downloadKBps, uploadKBps int64) error {
+	return fmt.Errorf("speed limit: %w", ErrNotSupported)
+}
+
+// Test tests the connection to SABnzbd, the API key is checked as well
+func (c *Client) Test(ctx context.Context) (string, error) {
+	var version versionResponse
+	if err := c.request(ctx, "version", nil, &version); err != nil {
+		return "", fmt.Errorf("test sabnzbd failed: %w", err)
+	}
+
+	// mode=version does not require the API key
+	var queue QueueResponse
+	params := url.Values{}
+	params.Set("limit", "1")
+	if err := c.request(ctx, "queue", params, &queue); err != nil {
+		return "", fmt.Errorf("test sabnzbd failed: %w", err)
+	}
+
+	return version.Version, nil
+}
+
+// queueStatus converts the i-th slot of the queue. SABnzbd downloads one job at a time,
+// so the speed of the queue is reported for the job that is downloading, paused jobs are reported as paused.
+func queueStatus(queue Queue, i int) *downloader.TaskStatus {
+	slot := queue.Slots[i]
+	total := parseMB(slot.MB)
+	left := parseMB(slot.MBLeft)
+
+	status := &downloader.TaskStatus{
+		Name:       slot.Filename,
+		State:      downloader.StatusDownloading,
+		Total:      total,
+		Downloaded: total - left,
+	}
+	switch slot.Status {
+	case "Downloading":
+		kb, _ := strconv.ParseFloat(queue.KBPerSec, 64)
+		status.DownloadSpeed = int64(kb * 1024)
+	case "Paused":
+		status.State = downloader.StatusPaused
+	}
+	return status
+}
+
+// historyStatus converts a history slot, jobs still in post-processing are reported as downloading
+func historyStatus(slot HistorySlot) *downloader.TaskStatus {
+	status := &downloader.TaskStatus{
+		Name:       slot.Name,
+		Total:      slot.Bytes,
+		Downloaded: slot.Bytes,
+	}
+	if slot.Storage != "" {
+		status.SavePath = filepath.ToSlash(slot.Storage)
+		if status.Name == "" {
+			status.Name = path.Base(status.SavePath)
+		}
+	}
+
+	switch slot.Status {
+	case "Completed":
+		status.State = downloader.StatusCompleted
+	case "Failed":
+		status.State = downloader.StatusError
+		status.ErrorMessage = slot.FailMessage
+	default:
+		status.State = downloader.StatusDownloading
+	}
+	return status
+}
+
+// parseMB converts a size in MB as reported by SABnzbd to bytes
+func parseMB(mb string) int64 {
+	v, _ := strconv.ParseFloat(mb, 64)
+	return int64(v * megabyte)
+}
+
+func (c *Client) request(ctx context.Context, mode string, params url.Values, v interface{}) error {
+	query := url.Values{}
+	for k, values := range params {
+		query[k] = values
+	}
+	query.Set("mode", mode)
+	query.Set("output", "json")
+	query.Set("apikey", c.settings.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d, content: %s", resp.StatusCode, string(body))
+	}
+
+	// Rejected requests, e.g. with a wrong API key, are reported with status false
+	var rejected errorResponse
+	if err := json.Unmarshal(body, &rejected); err == nil && rejected.Status != nil && !*rejected.Status && rejected.Error != "" {
+		return errors.New(rejected.Error)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", mode, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package sabnzbd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	d, err := New(nil, &Settings{Server: server.URL, APIKey: "key", Category: "movies"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.(*Client)
+}
+
+func TestCreateTask(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api" || q.Get("mode") != "addurl" || q.Get("apikey") != "key" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if q.Get("name") != "https://indexer/nzb/1" || q.Get("cat") != "movies" || q.Get("priority") != "1" {
+			t.Errorf("unexpected params %v", q)
+		}
+		_, _ = w.Write([]byte(`{"status": true, "nzo_ids": ["SABnzbd_nzo_abc"]}`))
+	})
+
+	handle, err := c.CreateTask(context.Background(), "https://indexer/nzb/1", map[string]interface{}{"priority": 1, "unknown": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handle.ID != "SABnzbd_nzo_abc" {
+		t.Errorf("handle = %+v", handle)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("mode") == "queue" && q.Get("nzo_ids") == "downloading":
+			_, _ = w.Write([]byte(`{"queue": {"kbpersec": "1024.0", "slots": [
+				{"nzo_id": "downloading", "filename": "a", "status": "Downloading", "mb": "100", "mbleft": "25"}]}}`))
+		case q.Get("mode") == "queue" && q.Get("nzo_ids") == "paused":
+			_, _ = w.Write([]byte(`{"queue": {"kbpersec": "1024.0", "slots": [
+				{"nzo_id": "paused", "filename": "d", "status": "Paused", "mb": "100", "mbleft": "60"}]}}`))
+		case q.Get("mode") == "queue":
+			_, _ = w.Write([]byte(`{"queue": {"kbpersec": "0", "slots": []}}`))
+		case q.Get("nzo_ids") == "completed":
+			_, _ = w.Write([]byte(`{"history": {"slots": [
+				{"nzo_id": "completed", "name": "b", "status": "Completed", "bytes": 2048, "storage": "/data/movies/b"}]}}`))
+		case q.Get("nzo_ids") == "failed":
+			_, _ = w.Write([]byte(`{"history": {"slots": [
+				{"nzo_id": "failed", "name": "c", "status": "Failed", "bytes": 10, "fail_message": "Out of retention"}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"history": {"slots": []}}`))
+		}
+	})
+	ctx := context.Background()
+
+	status, err := c.Info(ctx, &downloader.TaskHandle{ID: "downloading"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != downloader.StatusDownloading || status.Total != 100*megabyte ||
+		status.Downloaded != 75*megabyte || status.DownloadSpeed != 1024*1024 {
+		t.Errorf("downloading status = %+v", status)
+	}
+
+	status, err = c.Info(ctx, &downloader.TaskHandle{ID: "paused"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != downloader.StatusPaused || status.Downloaded != 40*megabyte || status.DownloadSpeed != 0 {
+		t.Errorf("paused status = %+v", status)
+	}
+
+	status, err = c.Info(ctx, &downloader.TaskHandle{ID: "completed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != downloader.StatusCompleted || status.SavePath != "/data/movies/b" || status.Downloaded != 2048 {
+		t.Errorf("completed status = %+v", status)
+	}
+
+	status, err = c.Info(ctx, &downloader.TaskHandle{ID: "failed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != downloader.StatusError || status.ErrorMessage != "Out of retention" {
+		t.Errorf("failed status = %+v", status)
+	}
+
+	if _, err := c.Info(ctx, &downloader.TaskHandle{ID: "missing"}); !errors.Is(err, downloader.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	var actions []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("mode") != "queue" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if q.Get("value") != "SABnzbd_nzo_abc" {
+			_, _ = w.Write([]byte(`{"status": true, "nzo_ids": []}`))
+			return
+		}
+		actions = append(actions, q.Get("name"))
+		_, _ = w.Write([]byte(`{"status": true, "nzo_ids": ["SABnzbd_nzo_abc"]}`))
+	})
+	ctx := context.Background()
+	handle := &downloader.TaskHandle{ID: "SABnzbd_nzo_abc"}
+
+	if err := c.Pause(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Resume(ctx, handle); err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || actions[0] != "pause" || actions[1] != "resume" {
+		t.Errorf("actions = %v", actions)
+	}
+
+	if err := c.Pause(ctx, &downloader.TaskHandle{ID: "finished"}); !errors.Is(err, downloader.ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestNotSupported(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	})
+	handle := &downloader.TaskHandle{ID: "SABnzbd_nzo_abc"}
+
+	if err := c.SetSpeedLimit(context.Background(), handle, 100, 0); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SetSpeedLimit: expected ErrNotSupported, got %v", err)
+	}
+	if err := c.SetFilesToDownload(context.Background(), handle); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("SetFilesToDownload: expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestRejectedRequest(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("mode") == "version" {
+			_, _ = w.Write([]byte(`{"version": "4.2.1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": false, "error": "API Key Incorrect"}`))
+	})
+
+	if _, err := c.Test(context.Background()); err == nil || err.Error() != "test sabnzbd failed: API Key Incorrect" {
+		t.Errorf("expected rejected API key, got %v", err)
+	}
+}
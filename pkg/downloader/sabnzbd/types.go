@@ -0,0 +1,67 @@
+package sabnzbd
+
+// addResponse is the response of mode=addurl
+type addResponse struct {
+	Status bool     `json:"status"`
+	NzoIDs []string `json:"nzo_ids"`
+	Error  string   `json:"error"`
+}
+
+// errorResponse is returned by any mode when the request is rejected, e.g. a wrong API key
+type errorResponse struct {
+	Status *bool  `json:"status"`
+	Error  string `json:"error"`
+}
+
+// QueueResponse is the response of mode=queue
+type QueueResponse struct {
+	Queue Queue `json:"queue"`
+}
+
+// Queue represents the SABnzbd download queue
+type Queue struct {
+	Status   string      `json:"status"`
+	KBPerSec string      `json:"kbpersec"` // speed of the whole queue in KB/s
+	Paused   bool        `json:"paused"`
+	Slots    []QueueSlot `json:"slots"`
+}
+
+// QueueSlot represents a job in the download queue
+type QueueSlot struct {
+	NzoID      string `json:"nzo_id"`
+	Index      int    `json:"index"`
+	Filename   string `json:"filename"`
+	Status     string `json:"status"` // Downloading, Queued, Paused, Fetching, Propagating, Grabbing, Checking
+	MB         string `json:"mb"`
+	MBLeft     string `json:"mbleft"`
+	Percentage string `json:"percentage"`
+	Category   string `json:"cat"`
+	Priority   string `json:"priority"`
+	TimeLeft   string `json:"timeleft"`
+}
+
+// HistoryResponse is the response of mode=history
+type HistoryResponse struct {
+	History History `json:"history"`
+}
+
+// History represents finished and post-processing jobs
+type History struct {
+	Slots []HistorySlot `json:"slots"`
+}
+
+// HistorySlot represents a job in the history
+type HistorySlot struct {
+	NzoID       string `json:"nzo_id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"` // Completed, Failed, Queued, QuickCheck, Verifying, Repairing, Fetching, Extracting, Moving, Running
+	Bytes       int64  `json:"bytes"`
+	Storage     string `json:"storage"` // final location of the job
+	Category    string `json:"category"`
+	FailMessage string `json:"fail_message"`
+}
+
+// versionResponse is the response of mode=version
+type versionResponse struct {
+	Version string `json:"version"`
+}
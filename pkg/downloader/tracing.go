@@ -0,0 +1,35 @@
+package downloader
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/top-system/light-admin/pkg/downloader"
+
+// StartRPCSpan starts a client span around a request sent to a downloader,
+// system names the downloader (aria2, qbittorrent) and method the RPC method
+// or API path. Spans are dropped unless a tracer provider is installed with
+// otel.SetTracerProvider
+func StartRPCSpan(ctx context.Context, system, method string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, system+" "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", system),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+// EndRPCSpan ends the span, marking it failed when err is not nil
+func EndRPCSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
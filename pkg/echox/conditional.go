@@ -0,0 +1,38 @@
+package echox
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetETag 设置 ETag 响应头，并判断请求的 If-None-Match 是否命中（命中时应返回 304）
+func SetETag(ctx echo.Context, version string) bool {
+	etag := strconv.Quote(version)
+	ctx.Response().Header().Set("ETag", etag)
+
+	for _, v := range strings.Split(ctx.Request().Header.Get("If-None-Match"), ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// IfModifiedSince 解析 If-Modified-Since 请求头，缺失或格式无效时返回 false
+func IfModifiedSince(ctx echo.Context) (time.Time, bool) {
+	v := ctx.Request().Header.Get("If-Modified-Since")
+	if v == "" {
+		return time.Time{}, false
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
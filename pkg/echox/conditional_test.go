@@ -0,0 +1,35 @@
+package echox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetETag(t *testing.T) {
+	ctx, rec := newContext(nil)
+	assert.False(t, SetETag(ctx, "100.abc"))
+	assert.Equal(t, `"100.abc"`, rec.Header().Get("ETag"))
+
+	ctx, _ = newContext(map[string]string{"If-None-Match": `"99.abc", W/"100.abc"`})
+	assert.True(t, SetETag(ctx, "100.abc"))
+
+	ctx, _ = newContext(map[string]string{"If-None-Match": `"99.abc"`})
+	assert.False(t, SetETag(ctx, "100.abc"))
+}
+
+func TestIfModifiedSince(t *testing.T) {
+	ctx, _ := newContext(nil)
+	_, ok := IfModifiedSince(ctx)
+	assert.False(t, ok)
+
+	ctx, _ = newContext(map[string]string{"If-Modified-Since": "Wed, 21 Oct 2015 07:28:00 GMT"})
+	since, ok := IfModifiedSince(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC), since)
+
+	ctx, _ = newContext(map[string]string{"If-Modified-Since": "yesterday"})
+	_, ok = IfModifiedSince(ctx)
+	assert.False(t, ok)
+}
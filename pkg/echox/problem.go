@@ -0,0 +1,88 @@
+package echox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMEApplicationProblemJSON RFC 7807 错误响应类型
+	MIMEApplicationProblemJSON = "application/problem+json"
+
+	// HeaderAPIVersion 客户端声明的接口版本，启用 problem+json 后
+	// 携带 LegacyAPIVersion 的请求仍返回旧的统一响应结构
+	HeaderAPIVersion = "X-API-Version"
+	LegacyAPIVersion = "1"
+)
+
+var problemJSON atomic.Bool
+
+// EnableProblemJSON 设置错误响应是否使用 RFC 7807 problem+json 格式
+func EnableProblemJSON(enable bool) {
+	problemJSON.Store(enable)
+}
+
+// Problem RFC 7807 错误响应结构
+type Problem struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	Instance      string `json:"instance,omitempty"`
+	Code          string `json:"code,omitempty"`
+	CorrelationID string `json:"correlationId,omitempty"`
+}
+
+// useProblem 判断当前请求的错误响应是否使用 problem+json
+func useProblem(ctx echo.Context) bool {
+	if !problemJSON.Load() {
+		return false
+	}
+
+	return ctx.Request().Header.Get(HeaderAPIVersion) != LegacyAPIVersion
+}
+
+// correlationID 获取请求关联ID，优先使用响应头中的 X-Request-ID
+func correlationID(ctx echo.Context) string {
+	if id := ctx.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+
+	return ctx.Request().Header.Get(echo.HeaderXRequestID)
+}
+
+// problem 将统一响应转换为 problem+json 响应
+func (a Response) problem(ctx echo.Context) error {
+	p := Problem{
+		Type:          "about:blank",
+		Title:         http.StatusText(a.Code),
+		Status:        a.Code,
+		Instance:      ctx.Request().URL.Path,
+		Code:          a.BizCode,
+		CorrelationID: correlationID(ctx),
+	}
+
+	if detail := fmt.Sprint(a.Message); detail != p.Title {
+		p.Detail = detail
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+	if a.Pretty {
+		body, err = json.MarshalIndent(p, "", "\t")
+	} else {
+		body, err = json.Marshal(p)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Response().Header().Add(echo.HeaderVary, HeaderAPIVersion)
+	return ctx.Blob(a.Code, MIMEApplicationProblemJSON, body)
+}
@@ -0,0 +1,52 @@
+package echox
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newContext(header map[string]string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func TestProblemJSON(t *testing.T) {
+	EnableProblemJSON(true)
+	defer EnableProblemJSON(false)
+
+	ctx, rec := newContext(map[string]string{echo.HeaderXRequestID: "req-1"})
+	err := FailWithCode(ctx, http.StatusNotFound, errors.New("user not found"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var p Problem
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, http.StatusText(http.StatusNotFound), p.Title)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "user not found", p.Detail)
+	assert.Equal(t, "/api/v1/users/1", p.Instance)
+	assert.Equal(t, "req-1", p.CorrelationID)
+
+	// 旧版本客户端仍返回统一响应结构
+	ctx, rec = newContext(map[string]string{HeaderAPIVersion: LegacyAPIVersion})
+	assert.Nil(t, FailWithCode(ctx, http.StatusNotFound, errors.New("user not found")))
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+	assert.Contains(t, rec.Body.String(), `"message":"user not found"`)
+
+	// 成功响应不受影响
+	ctx, rec = newContext(nil)
+	assert.Nil(t, OK(ctx, "ok"))
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+}
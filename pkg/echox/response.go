@@ -26,11 +26,12 @@ type Response struct {
 	Message interface{} `json:"message"`
 }
 
-// PageInfo 分页信息
+// PageInfo 分页信息，未统计总数时 Total 为 -1，通过 HasNext 判断是否有下一页
 type PageInfo struct {
 	Total    int64 `json:"total"`
 	PageNum  int   `json:"pageNum"`
 	PageSize int   `json:"pageSize"`
+	HasNext  bool  `json:"hasNext"`
 }
 
 // sends a JSON response with status code.
@@ -55,6 +56,11 @@ func (a Response) JSON(ctx echo.Context) error {
 		}
 	}
 
+	// 启用 problem+json 时错误响应按 RFC 7807 输出
+	if a.Code >= http.StatusBadRequest && useProblem(ctx) {
+		return a.problem(ctx)
+	}
+
 	if a.Pretty {
 		return ctx.JSONPretty(a.Code, a, "\t")
 	}
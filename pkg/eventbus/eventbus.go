@@ -0,0 +1,219 @@
+// Package eventbus 进程内事件总线，模块之间通过带类型的主题发布、订阅事件，不直接互相调用
+//
+// 同步订阅者在发布者的协程中依次执行；异步订阅者收到的是 JSON 编码后的事件，
+// 交给 Dispatcher 投递（默认在后台协程中执行，可以替换为任务队列以便失败重试和重启后继续），
+// 因此异步订阅需要在总线内唯一的名称，投递时按主题与名称找到订阅者解码并执行
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ErrUnknownSubscriber 投递的主题没有该名称的异步订阅者，例如订阅已在新版本中移除
+var ErrUnknownSubscriber = errors.New("eventbus: unknown subscriber")
+
+// Topic 事件主题，T 为事件类型
+//
+//	var UserDisabled = eventbus.NewTopic[UserDisabledEvent]("user.disabled")
+//
+//	UserDisabled.Subscribe(bus, "auth", func(ctx context.Context, e UserDisabledEvent) error { ... })
+//	UserDisabled.Publish(ctx, bus, UserDisabledEvent{ID: 1})
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic 创建主题，name 在总线内唯一
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name 主题名称
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Subscribe 同步订阅，fn 在发布者的协程中执行，返回的错误只记录日志
+func (t Topic[T]) Subscribe(bus *Bus, name string, fn func(ctx context.Context, event T) error) {
+	bus.subscribe(t.name, &subscriber{
+		name: name,
+		handle: func(ctx context.Context, event any) error {
+			return fn(ctx, event.(T))
+		},
+	})
+}
+
+// SubscribeAsync 异步订阅，事件经 JSON 编码后交给 Dispatcher 投递，fn 的错误由 Dispatcher 处理
+// name 用于投递时找到订阅者，同一主题内不能重复，修改名称后尚未投递的事件将无法投递
+func (t Topic[T]) SubscribeAsync(bus *Bus, name string, fn func(ctx context.Context, event T) error) {
+	bus.subscribe(t.name, &subscriber{
+		name:  name,
+		async: true,
+		decode: func(ctx context.Context, payload []byte) error {
+			var event T
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return fmt.Errorf("failed to decode %s event: %w", t.name, err)
+			}
+			return fn(ctx, event)
+		},
+	})
+}
+
+// Publish 发布事件，同步订阅者执行完成后返回
+func (t Topic[T]) Publish(ctx context.Context, bus *Bus, event T) {
+	bus.publish(ctx, t.name, event)
+}
+
+// Delivery 一次异步投递
+type Delivery struct {
+	Topic      string `json:"topic"`
+	Subscriber string `json:"subscriber"`
+	Payload    []byte `json:"payload"`
+}
+
+// Dispatcher 投递异步事件，最终调用 Bus.Deliver
+type Dispatcher func(ctx context.Context, d Delivery) error
+
+type subscriber struct {
+	name   string
+	async  bool
+	handle func(ctx context.Context, event any) error
+	decode func(ctx context.Context, payload []byte) error
+}
+
+// Bus 事件总线
+type Bus struct {
+	logger *zap.Logger
+
+	mu          sync.RWMutex
+	subscribers map[string][]*subscriber
+	dispatch    Dispatcher
+}
+
+// New 创建事件总线，异步事件默认在后台协程中投递
+func New(logger *zap.Logger) *Bus {
+	b := &Bus{
+		logger:      logger,
+		subscribers: make(map[string][]*subscriber),
+	}
+	b.dispatch = b.dispatchInBackground
+	return b
+}
+
+// SetDispatcher 替换异步事件的投递方式，nil 恢复为后台协程投递
+func (b *Bus) SetDispatcher(dispatch Dispatcher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if dispatch == nil {
+		dispatch = b.dispatchInBackground
+	}
+	b.dispatch = dispatch
+}
+
+// Topics 已有订阅者的主题及其订阅者名称
+func (b *Bus) Topics() map[string][]string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	topics := make(map[string][]string, len(b.subscribers))
+	for topic, subs := range b.subscribers {
+		for _, sub := range subs {
+			topics[topic] = append(topics[topic], sub.name)
+		}
+	}
+	return topics
+}
+
+// Deliver 执行一次异步投递
+func (b *Bus) Deliver(ctx context.Context, d Delivery) error {
+	sub := b.lookup(d.Topic, d.Subscriber)
+	if sub == nil || !sub.async {
+		return fmt.Errorf("%w: %s/%s", ErrUnknownSubscriber, d.Topic, d.Subscriber)
+	}
+
+	return invoke(func() error {
+		return sub.decode(ctx, d.Payload)
+	})
+}
+
+func (b *Bus) subscribe(topic string, sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, item := range b.subscribers[topic] {
+		if item.name == sub.name {
+			panic(fmt.Sprintf("eventbus: duplicate subscriber %q of topic %q", sub.name, topic))
+		}
+	}
+	b.subscribers[topic] = append(b.subscribers[topic], sub)
+}
+
+func (b *Bus) lookup(topic, name string) *subscriber {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers[topic] {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (b *Bus) publish(ctx context.Context, topic string, event any) {
+	b.mu.RLock()
+	subs := b.subscribers[topic]
+	dispatch := b.dispatch
+	b.mu.RUnlock()
+
+	var payload []byte
+	for _, sub := range subs {
+		if !sub.async {
+			if err := invoke(func() error { return sub.handle(ctx, event) }); err != nil {
+				b.logger.Warn("Event subscriber failed",
+					zap.String("topic", topic), zap.String("subscriber", sub.name), zap.Error(err))
+			}
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				b.logger.Error("Failed to encode event", zap.String("topic", topic), zap.Error(err))
+				return
+			}
+		}
+		if err := dispatch(ctx, Delivery{Topic: topic, Subscriber: sub.name, Payload: payload}); err != nil {
+			b.logger.Warn("Failed to dispatch event",
+				zap.String("topic", topic), zap.String("subscriber", sub.name), zap.Error(err))
+		}
+	}
+}
+
+// dispatchInBackground 在后台协程中投递，不随发布者的请求结束而取消，失败只记录日志
+func (b *Bus) dispatchInBackground(ctx context.Context, d Delivery) error {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := b.Deliver(ctx, d); err != nil {
+			b.logger.Warn("Event subscriber failed",
+				zap.String("topic", d.Topic), zap.String("subscriber", d.Subscriber), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// invoke 执行订阅者，panic 转换为错误，避免影响发布者与其他订阅者
+func invoke(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,93 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type userEvent struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+}
+
+var topicUser = NewTopic[userEvent]("user.test")
+
+func TestPublishSync(t *testing.T) {
+	bus := New(zap.NewNop())
+
+	var got []string
+	topicUser.Subscribe(bus, "first", func(ctx context.Context, e userEvent) error {
+		got = append(got, "first:"+e.Username)
+		return errors.New("ignored")
+	})
+	topicUser.Subscribe(bus, "panics", func(ctx context.Context, e userEvent) error {
+		panic("boom")
+	})
+	topicUser.Subscribe(bus, "second", func(ctx context.Context, e userEvent) error {
+		got = append(got, "second:"+e.Username)
+		return nil
+	})
+
+	topicUser.Publish(context.Background(), bus, userEvent{ID: 1, Username: "alice"})
+	assert.Equal(t, []string{"first:alice", "second:alice"}, got)
+
+	assert.Panics(t, func() {
+		topicUser.Subscribe(bus, "first", func(ctx context.Context, e userEvent) error { return nil })
+	})
+}
+
+func TestPublishAsync(t *testing.T) {
+	bus := New(zap.NewNop())
+
+	received := make(chan userEvent, 1)
+	topicUser.SubscribeAsync(bus, "webhook", func(ctx context.Context, e userEvent) error {
+		received <- e
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	topicUser.Publish(ctx, bus, userEvent{ID: 2, Username: "bob"})
+	cancel()
+
+	select {
+	case e := <-received:
+		assert.Equal(t, userEvent{ID: 2, Username: "bob"}, e)
+	case <-time.After(time.Second):
+		t.Fatal("async subscriber not called")
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	bus := New(zap.NewNop())
+
+	var deliveries []Delivery
+	bus.SetDispatcher(func(ctx context.Context, d Delivery) error {
+		deliveries = append(deliveries, d)
+		return nil
+	})
+
+	var got userEvent
+	topicUser.SubscribeAsync(bus, "webhook", func(ctx context.Context, e userEvent) error {
+		got = e
+		return errors.New("retry me")
+	})
+
+	topicUser.Publish(context.Background(), bus, userEvent{ID: 3, Username: "carol"})
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, "user.test", deliveries[0].Topic)
+	assert.Equal(t, "webhook", deliveries[0].Subscriber)
+	assert.JSONEq(t, `{"id":3,"username":"carol"}`, string(deliveries[0].Payload))
+	assert.Zero(t, got)
+
+	err := bus.Deliver(context.Background(), deliveries[0])
+	assert.EqualError(t, err, "retry me")
+	assert.Equal(t, userEvent{ID: 3, Username: "carol"}, got)
+
+	err = bus.Deliver(context.Background(), Delivery{Topic: "user.test", Subscriber: "removed"})
+	assert.True(t, errors.Is(err, ErrUnknownSubscriber))
+}
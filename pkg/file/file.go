@@ -1,7 +1,9 @@
 package file
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -104,6 +106,48 @@ func Rename(src string, target string) error {
 	return os.Rename(src, target)
 }
 
+// Move moves the file to target, creating the parent directories of target.
+// When renaming is not possible (e.g. target is on another device) the file is
+// copied and the source removed afterwards.
+func Move(src string, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	err := os.Rename(src, target)
+	var linkErr *os.LinkError
+	if err == nil || !errors.As(err, &linkErr) {
+		return err
+	}
+
+	if err := copyFile(src, target); err != nil {
+		os.Remove(target)
+		return err
+	}
+	return os.Remove(src)
+}
+
+func copyFile(src string, target string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
 // delete file
 func Unlink(fp string) error {
 	return os.Remove(fp)
@@ -1,7 +1,9 @@
 package file
 
 import (
+	"os"
 	"os/user"
+	"path/filepath"
 	"testing"
 )
 
@@ -40,3 +42,32 @@ func TestEnsureDirRW(t *testing.T) {
 		t.Error("error, EnsureDirRW", err1)
 	}
 }
+
+func TestMove(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(dir, "a", "b", "dst.txt")
+	if err := Move(src, target); err != nil {
+		t.Fatal(err)
+	}
+	if IsExist(src) {
+		t.Error("source still exists")
+	}
+	if data, err := os.ReadFile(target); err != nil || string(data) != "content" {
+		t.Errorf("target = %q, %v", data, err)
+	}
+
+	if err := copyFile(target, src); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(src); err != nil || string(data) != "content" {
+		t.Errorf("copy = %q, %v", data, err)
+	}
+	if err := Move(filepath.Join(dir, "missing"), target); err == nil {
+		t.Error("moving a missing file succeeded")
+	}
+}
@@ -0,0 +1,94 @@
+package file
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxFilenameBytes 文件名最大字节数（大多数文件系统的限制）
+const maxFilenameBytes = 255
+
+// NormalizeFilename 规范化上传文件名
+// 统一为 NFC 形式，去除路径部分（防止路径穿越）、控制字符和保留字符，
+// 去掉开头的点（避免隐藏文件），并将长度限制在 255 字节内（保留扩展名）
+func NormalizeFilename(name string) string {
+	name = norm.NFC.String(strings.ToValidUTF8(name, ""))
+
+	// 同时处理 Windows 路径分隔符
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			return -1
+		case strings.ContainsRune(`<>:"/\|?*`, r):
+			return '_'
+		}
+		return r
+	}, name)
+
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		return "file"
+	}
+
+	if len(name) <= maxFilenameBytes {
+		return name
+	}
+
+	ext := path.Ext(name)
+	if len(ext) > 16 {
+		ext = ""
+	}
+
+	base := name[:len(name)-len(ext)]
+	base = base[:maxFilenameBytes-len(ext)]
+	for !utf8.ValidString(base) {
+		base = base[:len(base)-1]
+	}
+
+	return base + ext
+}
+
+// SafeExt 返回规范化后的小写扩展名，仅保留字母和数字
+func SafeExt(name string) string {
+	ext := strings.ToLower(path.Ext(NormalizeFilename(name)))
+	if len(ext) <= 1 || len(ext) > 16 {
+		return ""
+	}
+
+	for _, r := range ext[1:] {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return ""
+		}
+	}
+
+	return ext
+}
+
+// ShardPath 根据日期目录和内容哈希生成分片存储路径，如 20240101/ab/abcdef....png
+// 哈希前两位作为子目录，避免单个目录下文件过多
+func ShardPath(dateFolder, hash, ext string) string {
+	shard := hash
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+
+	return dateFolder + "/" + shard + "/" + hash + ext
+}
+
+// shardPathPattern 匹配 ShardPath 生成的路径（日期目录/哈希前两位/SHA256 哈希[.扩展名]）
+var shardPathPattern = regexp.MustCompile(`(?:^|/)\d{8}/([0-9a-f]{2})/([0-9a-f]{64})(?:\.[a-z0-9]{1,15})?$`)
+
+// IsShardPath 判断存储路径或访问地址是否由 ShardPath 生成，
+// 去重功能上线前上传的文件（日期目录/UUID.扩展名）返回 false
+func IsShardPath(p string) bool {
+	m := shardPathPattern.FindStringSubmatch(p)
+	return m != nil && strings.HasPrefix(m[2], m[1])
+}
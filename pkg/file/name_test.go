@@ -0,0 +1,54 @@
+package file
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeFilename(t *testing.T) {
+	cases := map[string]string{
+		"report.pdf":               "report.pdf",
+		"../../etc/passwd":         "passwd",
+		`..\..\windows\system.ini`: "system.ini",
+		".htaccess":                "htaccess",
+		"a<b>c:d|e?.txt":           "a_b_c_d_e_.txt",
+		"evil\x00name‮.txt":        "evilname.txt",
+		"café.txt":                "café.txt",
+		"":                         "file",
+		"..":                       "file",
+		"name. ":                   "name",
+	}
+
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeFilename(in), in)
+	}
+
+	long := NormalizeFilename(strings.Repeat("文", 200) + ".png")
+	assert.LessOrEqual(t, len(long), 255)
+	assert.True(t, strings.HasSuffix(long, ".png"))
+}
+
+func TestSafeExt(t *testing.T) {
+	assert.Equal(t, ".png", SafeExt("a.PNG"))
+	assert.Equal(t, "", SafeExt("noext"))
+	assert.Equal(t, "", SafeExt("a.p$p"))
+}
+
+func TestShardPath(t *testing.T) {
+	assert.Equal(t, "20240101/ab/abcdef.png", ShardPath("20240101", "abcdef", ".png"))
+}
+
+func TestIsShardPath(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	assert.True(t, IsShardPath(ShardPath("20240101", hash, ".png")))
+	assert.True(t, IsShardPath("/"+ShardPath("20240101", hash, "")))
+	assert.True(t, IsShardPath("http://minio:9000/bucket/"+ShardPath("20240101", hash, ".pdf")))
+
+	// 去重功能上线前上传的文件
+	assert.False(t, IsShardPath("/20231231/0f8fad5b-d9cb-469f-a165-70867728950e.png"))
+	// 分片目录与哈希不一致
+	assert.False(t, IsShardPath("/20240101/cd/"+hash+".png"))
+	assert.False(t, IsShardPath("/avatar/"+hash+".png"))
+}
@@ -0,0 +1,167 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CountryLAN 内网地址的国家标识
+const CountryLAN = "LAN"
+
+// earthRadiusKm 地球平均半径
+const earthRadiusKm = 6371.0
+
+// Location IP 归属地
+type Location struct {
+	Country   string  `json:"country"`
+	Region    string  `json:"region"`
+	City      string  `json:"city"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// HasCoordinates 是否带有经纬度
+func (l Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+type ipRange struct {
+	start    netip.Addr
+	end      netip.Addr
+	location Location
+}
+
+// DB 离线 IP 段数据库
+// 数据来自 CSV，每行为 start_ip,end_ip,country,region,city,latitude,longitude，
+// 以 # 开头的行和表头会被忽略
+type DB struct {
+	ranges []ipRange
+}
+
+// Open 从 CSV 文件加载
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load 从 CSV 读取 IP 段
+func Load(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	db := &DB{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+		if err != nil {
+			// 表头
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least 3 fields, got %d", line, len(record))
+		}
+
+		end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if start.Is4() != end.Is4() || end.Less(start) {
+			return nil, fmt.Errorf("line %d: invalid range %s-%s", line, start, end)
+		}
+
+		location := Location{Country: strings.TrimSpace(record[2])}
+		if len(record) > 3 {
+			location.Region = strings.TrimSpace(record[3])
+		}
+		if len(record) > 4 {
+			location.City = strings.TrimSpace(record[4])
+		}
+		if len(record) > 6 {
+			location.Latitude, _ = strconv.ParseFloat(strings.TrimSpace(record[5]), 64)
+			location.Longitude, _ = strconv.ParseFloat(strings.TrimSpace(record[6]), 64)
+		}
+
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, location: location})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+
+	return db, nil
+}
+
+// Len IP 段数量
+func (a *DB) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.ranges)
+}
+
+// Lookup 查询 IP 归属地，内网和回环地址返回 CountryLAN
+func (a *DB) Lookup(ip string) (Location, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return Location{}, false
+	}
+	addr = addr.Unmap()
+
+	if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return Location{Country: CountryLAN}, true
+	}
+	if a == nil || len(a.ranges) == 0 {
+		return Location{}, false
+	}
+
+	// 找到最后一个 start <= addr 的段
+	i := sort.Search(len(a.ranges), func(i int) bool {
+		return addr.Less(a.ranges[i].start)
+	}) - 1
+	if i < 0 {
+		return Location{}, false
+	}
+
+	r := a.ranges[i]
+	if addr.Is4() != r.start.Is4() || bytes.Compare(addr.AsSlice(), r.end.AsSlice()) > 0 {
+		return Location{}, false
+	}
+
+	return r.location, true
+}
+
+// Distance 两地之间的球面距离（km）
+func Distance(a, b Location) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
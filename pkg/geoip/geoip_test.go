@@ -0,0 +1,57 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRanges = `start_ip,end_ip,country,region,city,latitude,longitude
+# comment
+1.0.1.0,1.0.3.255,CN,Fujian,Fuzhou,26.0614,119.3061
+8.8.8.0,8.8.8.255,US,California,Mountain View,37.386,-122.0838
+2001:db8::,2001:db8::ffff,DE,Hesse,Frankfurt,50.1109,8.6821
+`
+
+func TestLookup(t *testing.T) {
+	db, err := Load(strings.NewReader(testRanges))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, db.Len())
+
+	loc, ok := db.Lookup("1.0.2.3")
+	assert.True(t, ok)
+	assert.Equal(t, "CN", loc.Country)
+	assert.Equal(t, "Fuzhou", loc.City)
+
+	loc, ok = db.Lookup("8.8.8.8")
+	assert.True(t, ok)
+	assert.Equal(t, "US", loc.Country)
+
+	loc, ok = db.Lookup("2001:db8::1")
+	assert.True(t, ok)
+	assert.Equal(t, "DE", loc.Country)
+
+	_, ok = db.Lookup("1.0.4.1")
+	assert.False(t, ok)
+
+	_, ok = db.Lookup("not-an-ip")
+	assert.False(t, ok)
+
+	loc, ok = db.Lookup("192.168.1.10")
+	assert.True(t, ok)
+	assert.Equal(t, CountryLAN, loc.Country)
+
+	var empty *DB
+	loc, ok = empty.Lookup("127.0.0.1")
+	assert.True(t, ok)
+	assert.Equal(t, CountryLAN, loc.Country)
+}
+
+func TestDistance(t *testing.T) {
+	fuzhou := Location{Latitude: 26.0614, Longitude: 119.3061}
+	mountainView := Location{Latitude: 37.386, Longitude: -122.0838}
+
+	assert.InDelta(t, 10400, Distance(fuzhou, mountainView), 200)
+	assert.Zero(t, Distance(fuzhou, fuzhou))
+}
@@ -0,0 +1,88 @@
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTP 批量接口的请求体格式
+const (
+	FormatNDJSON = "ndjson" // 每行一个事件，Logstash、Vector、Fluent Bit 等的 HTTP 输入均支持
+	FormatJSON   = "json"   // 事件 JSON 数组
+)
+
+// HTTPSink 以 POST 批量发送到 HTTP 接口，非 2xx 响应视为失败
+type HTTPSink struct {
+	url     string
+	format  string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPSink 创建 HTTP 接收端，headers 用于携带认证信息（如 Authorization）
+func NewHTTPSink(url, format string, headers map[string]string) *HTTPSink {
+	if format != FormatJSON {
+		format = FormatNDJSON
+	}
+
+	return &HTTPSink{
+		url:     url,
+		format:  format,
+		headers: headers,
+		client:  &http.Client{},
+	}
+}
+
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+func (s *HTTPSink) Send(ctx context.Context, batch []Event) error {
+	var buf bytes.Buffer
+	contentType := "application/x-ndjson"
+	if s.format == FormatJSON {
+		contentType = "application/json"
+		if err := json.NewEncoder(&buf).Encode(batch); err != nil {
+			return err
+		}
+	} else {
+		enc := json.NewEncoder(&buf)
+		for _, event := range batch {
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+func (s *HTTPSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
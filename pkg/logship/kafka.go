@@ -0,0 +1,49 @@
+package logship
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSink 发送到 Kafka topic，消息 key 为事件类型，同类事件进入同一分区以保持顺序
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink 创建 Kafka 接收端
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireOne,
+			// 批量由 Shipper 控制，不再等待凑满 Writer 的批次
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Send(ctx context.Context, batch []Event) error {
+	messages := make([]kafka.Message, 0, len(batch))
+	for _, event := range batch {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, kafka.Message{Key: []byte(event.Type), Value: value, Time: event.Time})
+	}
+
+	return s.writer.WriteMessages(ctx, messages...)
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
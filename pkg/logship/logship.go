@@ -0,0 +1,298 @@
+// Package logship 将结构化日志事件批量转发到外部接收端（syslog、HTTP 批量接口、Kafka），
+// 供安全团队接入 SIEM。
+//
+// 每个接收端有独立的缓冲队列和发送协程，单个接收端变慢或不可用不影响其他接收端；
+// 发送失败按指数退避重试，队列满时按 Options.Block 丢弃事件或阻塞调用方。
+package logship
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jpillora/backoff"
+)
+
+// Severity 事件级别
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityNotice  Severity = "notice"
+	SeverityWarning Severity = "warning"
+)
+
+// ErrStopped 发送器已停止
+var ErrStopped = errors.New("logship: shipper is stopped")
+
+type (
+	// Event 待转发的日志事件，以 JSON 发送
+	Event struct {
+		Type     string      `json:"type"`
+		Time     time.Time   `json:"@timestamp"`
+		Severity Severity    `json:"severity"`
+		Source   string      `json:"source,omitempty"` // 应用名称
+		Host     string      `json:"host,omitempty"`
+		Data     interface{} `json:"data"`
+	}
+
+	// Sink 日志接收端
+	Sink interface {
+		// Name 接收端名称，用于日志和统计
+		Name() string
+		// Send 发送一批事件，返回错误时整批重试
+		Send(ctx context.Context, batch []Event) error
+		// Close 释放连接
+		Close() error
+	}
+
+	// Logger 发送器日志
+	Logger interface {
+		Warnf(template string, args ...interface{})
+		Errorf(template string, args ...interface{})
+	}
+
+	// Options 批量、重试及背压参数，零值使用默认值
+	Options struct {
+		BatchSize     int           // 单批最大事件数，默认 100
+		FlushInterval time.Duration // 未满一批时的最长等待时间，默认 2 秒
+		BufferSize    int           // 每个接收端的缓冲队列长度，默认 10000
+		Block         bool          // 队列满时阻塞调用方（最长 BlockTimeout）而不是直接丢弃
+		BlockTimeout  time.Duration // 默认 1 秒
+		MaxRetry      int           // 单批最大重试次数，超过后丢弃该批，默认 5
+		RetryDelay    time.Duration // 首次重试间隔，之后翻倍，最长 RetryMaxDelay，默认 1 秒
+		RetryMaxDelay time.Duration // 默认 1 分钟
+		SendTimeout   time.Duration // 单次发送超时，默认 10 秒
+	}
+
+	// Stats 接收端统计
+	Stats struct {
+		Sink    string `json:"sink"`
+		Queued  int    `json:"queued"`  // 当前队列长度
+		Shipped uint64 `json:"shipped"` // 已发送的事件数
+		Dropped uint64 `json:"dropped"` // 队列满或重试耗尽后丢弃的事件数
+		Retries uint64 `json:"retries"` // 发送失败重试次数
+	}
+)
+
+// Shipper 日志发送器
+type Shipper struct {
+	opts    Options
+	logger  Logger
+	workers []*sinkWorker
+
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+type sinkWorker struct {
+	sink    Sink
+	ch      chan Event
+	shipped uint64
+	dropped uint64
+	retries uint64
+}
+
+// New 创建发送器，调用 Start 后开始发送
+func New(opts Options, logger Logger, sinks ...Sink) *Shipper {
+	opts = opts.withDefaults()
+
+	s := &Shipper{opts: opts, logger: logger}
+	for _, sink := range sinks {
+		s.workers = append(s.workers, &sinkWorker{sink: sink, ch: make(chan Event, opts.BufferSize)})
+	}
+	return s
+}
+
+func (o Options) withDefaults() Options {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = 2 * time.Second
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = 10000
+	}
+	if o.BlockTimeout <= 0 {
+		o.BlockTimeout = time.Second
+	}
+	if o.MaxRetry <= 0 {
+		o.MaxRetry = 5
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = time.Second
+	}
+	if o.RetryMaxDelay <= 0 {
+		o.RetryMaxDelay = time.Minute
+	}
+	if o.SendTimeout <= 0 {
+		o.SendTimeout = 10 * time.Second
+	}
+	return o
+}
+
+// Start 启动各接收端的发送协程
+func (s *Shipper) Start() {
+	for _, w := range s.workers {
+		s.wg.Add(1)
+		go s.run(w)
+	}
+}
+
+// Ship 将事件加入各接收端队列，任一接收端丢弃该事件时返回 false
+func (s *Shipper) Ship(event Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.stopped {
+		return false
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Severity == "" {
+		event.Severity = SeverityInfo
+	}
+
+	ok := true
+	for _, w := range s.workers {
+		if !s.enqueue(w, event) {
+			atomic.AddUint64(&w.dropped, 1)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (s *Shipper) enqueue(w *sinkWorker, event Event) bool {
+	select {
+	case w.ch <- event:
+		return true
+	default:
+	}
+
+	if !s.opts.Block {
+		return false
+	}
+
+	timer := time.NewTimer(s.opts.BlockTimeout)
+	defer timer.Stop()
+	select {
+	case w.ch <- event:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// Stop 停止接收新事件，发送队列中剩余的事件后关闭接收端，ctx 结束时不再等待
+func (s *Shipper) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return ErrStopped
+	}
+	s.stopped = true
+	for _, w := range s.workers {
+		close(w.ch)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var errs []error
+	for _, w := range s.workers {
+		if err := w.sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats 返回各接收端的统计
+func (s *Shipper) Stats() []Stats {
+	stats := make([]Stats, 0, len(s.workers))
+	for _, w := range s.workers {
+		stats = append(stats, Stats{
+			Sink:    w.sink.Name(),
+			Queued:  len(w.ch),
+			Shipped: atomic.LoadUint64(&w.shipped),
+			Dropped: atomic.LoadUint64(&w.dropped),
+			Retries: atomic.LoadUint64(&w.retries),
+		})
+	}
+	return stats
+}
+
+// run 攒批发送，队列关闭后发送剩余事件
+func (s *Shipper) run(w *sinkWorker) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, s.opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.send(w, batch)
+		batch = make([]Event, 0, s.opts.BatchSize)
+	}
+
+	for {
+		select {
+		case event, ok := <-w.ch:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= s.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send 发送一批事件，失败时按指数退避重试
+func (s *Shipper) send(w *sinkWorker, batch []Event) {
+	b := &backoff.Backoff{Min: s.opts.RetryDelay, Max: s.opts.RetryMaxDelay, Factor: 2, Jitter: true}
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.opts.SendTimeout)
+		err := w.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			atomic.AddUint64(&w.shipped, uint64(len(batch)))
+			return
+		}
+
+		if attempt >= s.opts.MaxRetry {
+			atomic.AddUint64(&w.dropped, uint64(len(batch)))
+			s.logger.Errorf("Log shipping to %s failed after %d retries, dropping %d events: %v", w.sink.Name(), attempt, len(batch), err)
+			return
+		}
+
+		atomic.AddUint64(&w.retries, 1)
+		delay := b.Duration()
+		s.logger.Warnf("Log shipping to %s failed, retry in %s: %v", w.sink.Name(), delay, err)
+		time.Sleep(delay)
+	}
+}
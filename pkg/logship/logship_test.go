@@ -0,0 +1,247 @@
+package logship
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testLogger struct{}
+
+func (testLogger) Warnf(string, ...interface{})  {}
+func (testLogger) Errorf(string, ...interface{}) {}
+
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Event
+	fail    int // 前 fail 次发送返回错误
+	block   chan struct{}
+}
+
+func (s *memorySink) Name() string { return "memory" }
+func (s *memorySink) Close() error { return nil }
+
+func (s *memorySink) Send(ctx context.Context, batch []Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("unavailable")
+	}
+	s.batches = append(s.batches, append([]Event(nil), batch...))
+	return nil
+}
+
+func (s *memorySink) events() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, b := range s.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestShipperBatching(t *testing.T) {
+	sink := &memorySink{}
+	s := New(Options{BatchSize: 3, FlushInterval: time.Hour}, testLogger{}, sink)
+	s.Start()
+
+	for i := 0; i < 7; i++ {
+		if !s.Ship(Event{Type: "operation", Data: i}) {
+			t.Fatalf("event %d dropped", i)
+		}
+	}
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	if len(sink.batches) != 3 {
+		t.Fatalf("expected 3 batches, got %d", len(sink.batches))
+	}
+	if len(sink.batches[0]) != 3 || len(sink.batches[2]) != 1 {
+		t.Fatalf("unexpected batch sizes %d/%d", len(sink.batches[0]), len(sink.batches[2]))
+	}
+	if sink.batches[0][0].Severity != SeverityInfo || sink.batches[0][0].Time.IsZero() {
+		t.Fatalf("defaults not applied: %+v", sink.batches[0][0])
+	}
+	if s.Ship(Event{}) {
+		t.Fatal("stopped shipper accepted an event")
+	}
+}
+
+func TestShipperRetry(t *testing.T) {
+	sink := &memorySink{fail: 2}
+	s := New(Options{BatchSize: 1, RetryDelay: time.Millisecond, RetryMaxDelay: time.Millisecond}, testLogger{}, sink)
+	s.Start()
+
+	s.Ship(Event{Type: "login"})
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	stats := s.Stats()[0]
+	if stats.Shipped != 1 || stats.Retries != 2 || stats.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestShipperRetryExhausted(t *testing.T) {
+	sink := &memorySink{fail: 10}
+	s := New(Options{BatchSize: 2, MaxRetry: 1, RetryDelay: time.Millisecond}, testLogger{}, sink)
+	s.Start()
+
+	s.Ship(Event{})
+	s.Ship(Event{})
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	if stats := s.Stats()[0]; stats.Dropped != 2 || stats.Shipped != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestShipperBackpressure(t *testing.T) {
+	block := make(chan struct{})
+	sink := &memorySink{block: block}
+	s := New(Options{BatchSize: 1, BufferSize: 1, BlockTimeout: 20 * time.Millisecond}, testLogger{}, sink)
+	s.Start()
+
+	// 第一条被发送协程取走并阻塞在 Send，第二条占满队列
+	s.Ship(Event{})
+	deadline := time.Now().Add(time.Second)
+	for s.Stats()[0].Queued != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !s.Ship(Event{}) {
+		t.Fatal("queue should accept one event")
+	}
+	if s.Ship(Event{}) {
+		t.Fatal("full queue should drop without Block")
+	}
+
+	s.opts.Block = true
+	start := time.Now()
+	if s.Ship(Event{}) {
+		t.Fatal("full queue should drop after BlockTimeout")
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("Ship did not block")
+	}
+
+	close(block)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if stats := s.Stats()[0]; stats.Shipped != 2 || stats.Dropped != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var lines []string
+	var contentType, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		lines = strings.Split(strings.TrimSpace(string(body)), "\n")
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, "", map[string]string{"Authorization": "Bearer token"})
+	err := sink.Send(context.Background(), []Event{{Type: "operation"}, {Type: "login"}})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if contentType != "application/x-ndjson" || auth != "Bearer token" {
+		t.Fatalf("unexpected headers %q %q", contentType, auth)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[1], `"type":"login"`) {
+		t.Fatalf("unexpected body %q", lines)
+	}
+}
+
+func TestHTTPSinkStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	err := NewHTTPSink(srv.URL, FormatJSON, nil).Send(context.Background(), []Event{{}})
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	sink := NewSyslogSink("tcp", ln.Addr().String(), "light-admin", 0)
+	defer sink.Close()
+
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	batch := []Event{
+		{Type: "operation", Time: at, Severity: SeverityInfo, Data: map[string]string{"module": "用户管理"}},
+		{Type: "security_alert", Time: at, Severity: SeverityWarning},
+	}
+	if err := sink.Send(context.Background(), batch); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	first := <-received
+	if !strings.HasPrefix(first, "<134>1 2024-01-01T08:00:00Z ") || !strings.Contains(first, " light-admin ") || !strings.Contains(first, " operation - {") {
+		t.Fatalf("unexpected message %q", first)
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(first[strings.Index(first, "{"):]), &event); err != nil {
+		t.Fatalf("message body is not JSON: %v", err)
+	}
+
+	if second := <-received; !strings.HasPrefix(second, "<132>1 ") {
+		t.Fatalf("unexpected priority in %q", second)
+	}
+}
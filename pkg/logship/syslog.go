@@ -0,0 +1,135 @@
+package logship
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// syslog facility local0
+const defaultSyslogFacility = 16
+
+// SyslogSink 以 RFC 5424 格式发送到 syslog 服务器，消息体为事件 JSON
+// udp 每条事件一个数据报，tcp 使用 RFC 6587 的长度前缀分帧
+type SyslogSink struct {
+	network  string
+	address  string
+	appName  string
+	facility int
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink 创建 syslog 接收端，network 为 udp 或 tcp，facility 为 0 时使用 local0
+func NewSyslogSink(network, address, appName string, facility int) *SyslogSink {
+	if network == "" {
+		network = "udp"
+	}
+	if appName == "" {
+		appName = "light-admin"
+	}
+	if facility <= 0 || facility > 23 {
+		facility = defaultSyslogFacility
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogSink{
+		network:  network,
+		address:  address,
+		appName:  appName,
+		facility: facility,
+		hostname: hostname,
+	}
+}
+
+func (s *SyslogSink) Name() string {
+	return "syslog"
+}
+
+func (s *SyslogSink) Send(ctx context.Context, batch []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+
+	for _, event := range batch {
+		msg, err := s.format(event)
+		if err != nil {
+			return err
+		}
+		if s.network != "udp" {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+
+		if _, err := s.conn.Write(msg); err != nil {
+			// 连接断开后下次重连
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+
+	return nil
+}
+
+// format 生成 RFC 5424 消息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+func (s *SyslogSink) format(event Event) ([]byte, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	pri := s.facility*8 + syslogSeverity(event.Severity)
+	msgID := event.Type
+	if msgID == "" {
+		msgID = "-"
+	}
+
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		pri, event.Time.UTC().Format(time.RFC3339Nano), s.hostname, s.appName, os.Getpid(), msgID)
+	return append([]byte(header), body...), nil
+}
+
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func syslogSeverity(severity Severity) int {
+	switch severity {
+	case SeverityWarning:
+		return 4
+	case SeverityNotice:
+		return 5
+	default:
+		return 6
+	}
+}
@@ -0,0 +1,218 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoRecipients 邮件没有收件人
+var ErrNoRecipients = errors.New("mailer: no recipients")
+
+// Config SMTP 服务配置
+type Config struct {
+	Host     string
+	Port     int // 465 使用隐式 TLS，其他端口在服务端支持时使用 STARTTLS，默认 25
+	Username string
+	Password string
+	From     string // 发件人，如 Light Admin <noreply@example.com>
+	Timeout  time.Duration
+}
+
+// Message 邮件内容，Text 与 HTML 同时设置时发送 multipart/alternative
+type Message struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"text,omitempty"`
+	HTML    string   `json:"html,omitempty"`
+}
+
+// Mailer SMTP 邮件发送
+type Mailer struct {
+	config Config
+}
+
+// New 创建邮件发送器
+func New(config Config) *Mailer {
+	if config.Port == 0 {
+		config.Port = 25
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &Mailer{config: config}
+}
+
+// Send 发送邮件，ctx 取消或超时后中断连接
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return fmt.Errorf("mailer: invalid from address: %w", err)
+	}
+	for _, to := range msg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("mailer: invalid recipient %q: %w", to, err)
+		}
+	}
+
+	data, err := Build(from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	return m.send(ctx, from.Address, msg.To, data)
+}
+
+// Build 生成邮件原文，换行统一为 CRLF
+func Build(from *mail.Address, msg Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := []string{
+		"From: " + from.String(),
+		"To: " + strings.Join(msg.To, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date: " + now.Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+	}
+	for _, line := range header {
+		buf.WriteString(line + "\r\n")
+	}
+
+	switch {
+	case msg.HTML != "" && msg.Text != "":
+		boundary, err := newBoundary()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("Content-Type: multipart/alternative; boundary=\"" + boundary + "\"\r\n\r\n")
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", msg.Text},
+			{"text/html", msg.HTML},
+		} {
+			buf.WriteString("--" + boundary + "\r\n")
+			if err := writePart(&buf, part.contentType, part.body); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteString("--" + boundary + "--\r\n")
+	case msg.HTML != "":
+		if err := writePart(&buf, "text/html", msg.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		if err := writePart(&buf, "text/plain", msg.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writePart 写入一段正文，使用 quoted-printable 编码，避免长行被 SMTP 服务截断
+func writePart(buf *bytes.Buffer, contentType, body string) error {
+	buf.WriteString("Content-Type: " + contentType + "; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(buf)
+	body = strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+func newBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "light-admin-" + hex.EncodeToString(b), nil
+}
+
+func (m *Mailer) send(ctx context.Context, from string, to []string, msg []byte) error {
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	tlsConfig := &tls.Config{ServerName: m.config.Host}
+
+	dialer := &net.Dialer{Timeout: m.config.Timeout}
+	var conn net.Conn
+	var err error
+	if m.config.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 整个会话受 ctx 与超时限制，SMTP 服务无响应时不会一直阻塞
+	deadline := time.Now().Add(m.config.Timeout * 3)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.config.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
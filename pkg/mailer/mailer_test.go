@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildPlainText(t *testing.T) {
+	from := &mail.Address{Name: "Light Admin", Address: "noreply@example.com"}
+	data, err := Build(from, Message{
+		To:      []string{"alice@example.com"},
+		Subject: "下载失败",
+		Text:    "line 1\nline 2",
+	}, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	assert.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "下载失败", subject)
+	assert.Equal(t, "alice@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+
+	body, _ := io.ReadAll(msg.Body)
+	assert.Contains(t, string(body), "line 1\r\nline 2")
+}
+
+func TestBuildAlternative(t *testing.T) {
+	from := &mail.Address{Address: "noreply@example.com"}
+	data, err := Build(from, Message{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		Subject: "Notice",
+		Text:    "hello",
+		HTML:    "<p>hello</p>",
+	}, time.Now())
+	assert.NoError(t, err)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	assert.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(part)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, types)
+	assert.Equal(t, []string{"hello", "<p>hello</p>"}, bodies)
+}
+
+func TestSendValidatesRecipients(t *testing.T) {
+	m := New(Config{Host: "127.0.0.1", From: "noreply@example.com"})
+	assert.ErrorIs(t, m.Send(t.Context(), Message{Subject: "empty"}), ErrNoRecipients)
+	assert.Error(t, m.Send(t.Context(), Message{To: []string{"not an address"}}))
+}
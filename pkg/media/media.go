@@ -0,0 +1,206 @@
+// Package media reads metadata and grabs thumbnails from video and audio files
+// by running the ffprobe and ffmpeg command line tools.
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned for files that are not a known video or audio format
+var ErrUnsupported = errors.New("unsupported media format")
+
+// Kind is the media type detected from the file name
+type Kind string
+
+const (
+	KindVideo Kind = "video"
+	KindAudio Kind = "audio"
+)
+
+var extensions = map[string]Kind{
+	".mp4":  KindVideo,
+	".m4v":  KindVideo,
+	".mkv":  KindVideo,
+	".webm": KindVideo,
+	".mov":  KindVideo,
+	".avi":  KindVideo,
+	".wmv":  KindVideo,
+	".flv":  KindVideo,
+	".ts":   KindVideo,
+	".m2ts": KindVideo,
+	".mpg":  KindVideo,
+	".mpeg": KindVideo,
+	".3gp":  KindVideo,
+	".mp3":  KindAudio,
+	".m4a":  KindAudio,
+	".aac":  KindAudio,
+	".flac": KindAudio,
+	".wav":  KindAudio,
+	".ogg":  KindAudio,
+	".opus": KindAudio,
+	".wma":  KindAudio,
+	".ape":  KindAudio,
+}
+
+// KindOf detects the media type from the file extension, returns "" for other files
+func KindOf(name string) Kind {
+	return extensions[strings.ToLower(path.Ext(name))]
+}
+
+// Info is the metadata of a media file
+type Info struct {
+	Kind       Kind    `json:"kind"`
+	Format     string  `json:"format"`   // container format, e.g. "matroska,webm"
+	Duration   float64 `json:"duration"` // seconds
+	BitRate    int64   `json:"bitRate"`  // bits per second
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	VideoCodec string  `json:"videoCodec"`
+	AudioCodec string  `json:"audioCodec"`
+	Cover      bool    `json:"cover"` // audio file with embedded cover art
+}
+
+// HasPicture reports whether a thumbnail can be grabbed from the file
+func (i *Info) HasPicture() bool {
+	return i.VideoCodec != "" || i.Cover
+}
+
+type probeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		CodecType   string `json:"codec_type"`
+		CodecName   string `json:"codec_name"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+		Disposition struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+}
+
+// ParseProbe parses the JSON output of
+// "ffprobe -print_format json -show_format -show_streams"
+func ParseProbe(data []byte) (*Info, error) {
+	var out probeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+
+	info := &Info{Format: out.Format.FormatName}
+	info.Duration, _ = strconv.ParseFloat(out.Format.Duration, 64)
+	info.BitRate, _ = strconv.ParseInt(out.Format.BitRate, 10, 64)
+
+	for _, s := range out.Streams {
+		switch s.CodecType {
+		case "video":
+			// cover art of audio files is reported as a single frame video stream
+			if s.Disposition.AttachedPic == 1 {
+				info.Cover = true
+				continue
+			}
+			if info.VideoCodec == "" {
+				info.VideoCodec = s.CodecName
+				info.Width = s.Width
+				info.Height = s.Height
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = s.CodecName
+			}
+		}
+	}
+
+	switch {
+	case info.VideoCodec != "":
+		info.Kind = KindVideo
+	case info.AudioCodec != "":
+		info.Kind = KindAudio
+	default:
+		return nil, ErrUnsupported
+	}
+
+	return info, nil
+}
+
+// Probe reads the metadata of file with ffprobe
+func Probe(ctx context.Context, ffprobe, file string) (*Info, error) {
+	cmd := exec.CommandContext(ctx, ffprobe,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		file,
+	)
+	output, err := run(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseProbe(output)
+}
+
+// ThumbnailAt returns the position in seconds to grab the thumbnail from,
+// a tenth into the media but no later than 30 seconds, to skip black intro frames
+func ThumbnailAt(info *Info) float64 {
+	if info.Kind != KindVideo || info.Duration <= 0 {
+		return 0
+	}
+	at := info.Duration / 10
+	if at > 30 {
+		at = 30
+	}
+	return at
+}
+
+// Thumbnail grabs a single frame at the given position in seconds with ffmpeg,
+// scaled to width (keeping the aspect ratio) and encoded as JPEG.
+// For audio files the embedded cover art is used
+func Thumbnail(ctx context.Context, ffmpeg, file string, at float64, width int) ([]byte, error) {
+	args := []string{"-v", "error"}
+	if at > 0 {
+		args = append(args, "-ss", strconv.FormatFloat(at, 'f', 3, 64))
+	}
+	args = append(args, "-i", file, "-an", "-frames:v", "1")
+	if width > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=%d:-2", width))
+	}
+	args = append(args, "-f", "image2", "-c:v", "mjpeg", "pipe:1")
+
+	output, err := run(exec.CommandContext(ctx, ffmpeg, args...))
+	if err != nil {
+		return nil, err
+	}
+	if len(output) == 0 {
+		return nil, errors.New("ffmpeg produced no thumbnail")
+	}
+
+	return output, nil
+}
+
+func run(cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("%s: %w", path.Base(cmd.Path), err)
+		}
+		return nil, fmt.Errorf("%s: %w: %s", path.Base(cmd.Path), err, msg)
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+package media
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	cases := map[string]Kind{
+		"movie.MKV":        KindVideo,
+		"dir/clip.mp4":     KindVideo,
+		"album/01.flac":    KindAudio,
+		"song.mp3":         KindAudio,
+		"readme.txt":       "",
+		"archive.tar.gz":   "",
+		"no-extension":     "",
+		"subtitles.en.srt": "",
+	}
+	for name, want := range cases {
+		if got := KindOf(name); got != want {
+			t.Errorf("KindOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseProbeVideo(t *testing.T) {
+	data := []byte(`{
+		"streams": [
+			{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "disposition": {"attached_pic": 0}},
+			{"codec_type": "audio", "codec_name": "aac"},
+			{"codec_type": "audio", "codec_name": "ac3"},
+			{"codec_type": "subtitle", "codec_name": "subrip"}
+		],
+		"format": {"format_name": "matroska,webm", "duration": "5400.250000", "bit_rate": "4500000"}
+	}`)
+
+	info, err := ParseProbe(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Kind != KindVideo || info.VideoCodec != "h264" || info.AudioCodec != "aac" {
+		t.Errorf("unexpected streams: %+v", info)
+	}
+	if info.Width != 1920 || info.Height != 1080 {
+		t.Errorf("unexpected resolution: %dx%d", info.Width, info.Height)
+	}
+	if info.Duration != 5400.25 || info.BitRate != 4500000 || info.Format != "matroska,webm" {
+		t.Errorf("unexpected format: %+v", info)
+	}
+	if !info.HasPicture() {
+		t.Error("video should have a picture")
+	}
+	if at := ThumbnailAt(info); at != 30 {
+		t.Errorf("ThumbnailAt = %v, want 30", at)
+	}
+}
+
+func TestParseProbeAudioWithCover(t *testing.T) {
+	data := []byte(`{
+		"streams": [
+			{"codec_type": "audio", "codec_name": "mp3"},
+			{"codec_type": "video", "codec_name": "mjpeg", "width": 500, "height": 500, "disposition": {"attached_pic": 1}}
+		],
+		"format": {"format_name": "mp3", "duration": "215.4"}
+	}`)
+
+	info, err := ParseProbe(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Kind != KindAudio || info.AudioCodec != "mp3" || info.VideoCodec != "" {
+		t.Errorf("unexpected streams: %+v", info)
+	}
+	if info.Width != 0 || info.Height != 0 {
+		t.Errorf("cover art should not set the resolution: %dx%d", info.Width, info.Height)
+	}
+	if !info.Cover || !info.HasPicture() {
+		t.Error("audio with cover art should have a picture")
+	}
+	if at := ThumbnailAt(info); at != 0 {
+		t.Errorf("ThumbnailAt = %v, want 0", at)
+	}
+}
+
+func TestParseProbeUnsupported(t *testing.T) {
+	if _, err := ParseProbe([]byte(`{"streams": [], "format": {}}`)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := ParseProbe([]byte(`not json`)); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
@@ -0,0 +1,105 @@
+// Package netguard builds HTTP clients for requests to user supplied URLs.
+//
+// The dialer checks every address it connects to after DNS resolution, so
+// loopback, private, link-local and other non-public ranges are refused even
+// when a public host name resolves to them. Redirects are never followed and
+// proxies from the environment are ignored, the caller decides what to do
+// with a 3xx response.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned when a request would connect to a non-public address
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598)
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Blocked reports whether addr is an address user supplied URLs must not reach
+func Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsValid() ||
+		addr.IsUnspecified() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// control rejects the connection before it is made when the resolved address is blocked
+func control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if Blocked(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
+
+// NewClient creates a client that only connects to public addresses and does not follow redirects
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     NewTransport(timeout),
+		CheckRedirect: NoRedirect,
+	}
+}
+
+// NewTransport creates a transport that only connects to public addresses, timeout limits dialing and
+// the TLS handshake. Use it with NoRedirect when the whole request must not be limited, e.g. for large downloads
+func NewTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	return &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     30 * time.Second,
+	}
+}
+
+// NoRedirect is a CheckRedirect policy that returns the 3xx response instead of following it
+func NoRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// CheckHost resolves host and returns ErrBlockedAddress when any of its addresses is not public.
+// The dialer checks again when connecting, this catches bad URLs before they are stored or handed
+// to an external downloader
+func CheckHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if Blocked(addr) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if Blocked(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestBlocked(t *testing.T) {
+	blocked := []string{
+		"127.0.0.1", "::1", "0.0.0.0", "::",
+		"10.1.2.3", "172.16.0.1", "192.168.1.1", "fd00::1",
+		"169.254.169.254", "fe80::1", "100.64.0.1",
+		"224.0.0.1", "::ffff:127.0.0.1", "::ffff:10.0.0.1",
+	}
+	for _, s := range blocked {
+		if !Blocked(netip.MustParseAddr(s)) {
+			t.Errorf("%s should be blocked", s)
+		}
+	}
+
+	for _, s := range []string{"1.1.1.1", "93.184.216.34", "2606:4700:4700::1111"} {
+		if Blocked(netip.MustParseAddr(s)) {
+			t.Errorf("%s should not be blocked", s)
+		}
+	}
+}
+
+func TestClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Head(server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected ErrBlockedAddress, got %v", err)
+	}
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	client := NewClient(time.Second)
+	// allow loopback for the test server, the redirect policy is what is being checked
+	client.Transport = http.DefaultTransport
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect target should not be requested")
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer server.Close()
+
+	resp, err := client.Head(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("expected 302, got %d", resp.StatusCode)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "::1", "10.0.0.1", "169.254.169.254", "localhost"} {
+		if err := CheckHost(context.Background(), host); !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("CheckHost(%q) = %v, want ErrBlockedAddress", host, err)
+		}
+	}
+	if err := CheckHost(context.Background(), "93.184.216.34"); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}
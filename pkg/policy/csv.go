@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseCSV 解析 casbin 策略文件，每行一条：p, sub, obj, act 或 g, user, role
+// 空行与 # 开头的注释行会被忽略
+func ParseCSV(r io.Reader) ([]Rule, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rules := make([]Rule, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) < 3 || len(record) > 4 {
+			return nil, fmt.Errorf("line %d: expected 3 or 4 fields, got %d", line, len(record))
+		}
+
+		rule := Rule{PType: record[0], V0: record[1], V1: record[2]}
+		if len(record) == 4 {
+			rule.V2 = record[3]
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// WriteCSV 按 casbin 策略文件格式输出
+func WriteCSV(w io.Writer, rules []Rule) error {
+	writer := csv.NewWriter(w)
+	for _, rule := range rules {
+		record := []string{rule.PType, rule.V0, rule.V1}
+		if rule.PType == TypePolicy {
+			record = append(record, rule.V2)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,214 @@
+// Package policy 按 config/casbin_model.conf 中的模型评估访问策略
+//
+//	r = sub, obj, act
+//	p = sub, obj, act
+//	g = _, _
+//	m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && regexMatch(r.act, p.act) || r.sub == "root"
+//
+// 策略与 casbin 的 CSV 格式一致，可以直接与 casbin 工具链互相导入导出
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// 策略类型
+const (
+	TypePolicy = "p" // p, sub, obj, act
+	TypeGroup  = "g" // g, user, role
+)
+
+// RootSubject 模型中跳过策略检查的主体
+const RootSubject = "root"
+
+// Rule 一条策略
+// p 策略：V0-主体（用户名或角色编码） V1-资源路径（keyMatch2） V2-请求方法（正则）
+// g 策略：V0-用户名或角色编码 V1-继承的角色编码
+type Rule struct {
+	PType string `json:"ptype"`
+	V0    string `json:"v0"`
+	V1    string `json:"v1"`
+	V2    string `json:"v2"`
+}
+
+// Validate 校验策略格式，资源路径与请求方法必须能编译为正则
+func (r Rule) Validate() error {
+	switch r.PType {
+	case TypePolicy:
+		if r.V0 == "" || r.V1 == "" || r.V2 == "" {
+			return fmt.Errorf("policy requires sub, obj and act")
+		}
+		if _, err := compileKey(r.V1); err != nil {
+			return fmt.Errorf("invalid obj %q: %w", r.V1, err)
+		}
+		if _, err := regexp.Compile(r.V2); err != nil {
+			return fmt.Errorf("invalid act %q: %w", r.V2, err)
+		}
+	case TypeGroup:
+		if r.V0 == "" || r.V1 == "" {
+			return fmt.Errorf("grouping policy requires user and role")
+		}
+		if r.V2 != "" {
+			return fmt.Errorf("grouping policy does not support domains")
+		}
+	default:
+		return fmt.Errorf("unknown policy type %q", r.PType)
+	}
+
+	return nil
+}
+
+func (r Rule) String() string {
+	fields := []string{r.PType, r.V0, r.V1}
+	if r.PType == TypePolicy {
+		fields = append(fields, r.V2)
+	}
+	return strings.Join(fields, ", ")
+}
+
+type compiledRule struct {
+	rule Rule
+	obj  *regexp.Regexp
+	act  *regexp.Regexp
+}
+
+// Request 待评估的请求
+// Roles 为策略之外的角色（如用户在系统中分配的角色编码），与 g 策略一起参与匹配
+type Request struct {
+	Sub   string
+	Roles []string
+	Obj   string
+	Act   string
+}
+
+// Result 评估结果
+type Result struct {
+	Allowed  bool     `json:"allowed"`
+	Root     bool     `json:"root"`              // 主体为 root，跳过策略检查
+	Subjects []string `json:"subjects"`          // 参与匹配的主体：请求主体及其全部角色
+	Matched  *Rule    `json:"matched,omitempty"` // 第一条匹配的策略
+}
+
+// Enforcer 策略评估器，可并发使用，Load 会整体替换已加载的策略
+type Enforcer struct {
+	mu       sync.RWMutex
+	loaded   bool
+	policies []compiledRule
+	groups   map[string][]string
+}
+
+// NewEnforcer 创建评估器
+func NewEnforcer() *Enforcer {
+	return &Enforcer{groups: make(map[string][]string)}
+}
+
+// Load 加载策略，存在无效策略时返回错误且保留原有策略
+func (e *Enforcer) Load(rules []Rule) error {
+	policies := make([]compiledRule, 0, len(rules))
+	groups := make(map[string][]string)
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", rule, err)
+		}
+
+		switch rule.PType {
+		case TypePolicy:
+			obj, _ := compileKey(rule.V1)
+			act, _ := regexp.Compile(rule.V2)
+			policies = append(policies, compiledRule{rule: rule, obj: obj, act: act})
+		case TypeGroup:
+			groups[rule.V0] = append(groups[rule.V0], rule.V1)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.loaded = true
+	e.policies = policies
+	e.groups = groups
+	return nil
+}
+
+// Loaded 是否已加载过策略
+func (e *Enforcer) Loaded() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.loaded
+}
+
+// Enforce 评估请求
+func (e *Enforcer) Enforce(req Request) Result {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	result := Result{Subjects: e.subjects(req.Sub, req.Roles)}
+	if req.Sub == RootSubject {
+		result.Allowed = true
+		result.Root = true
+		return result
+	}
+
+	inSubjects := make(map[string]bool, len(result.Subjects))
+	for _, s := range result.Subjects {
+		inSubjects[s] = true
+	}
+
+	for i := range e.policies {
+		p := &e.policies[i]
+		if inSubjects[p.rule.V0] && p.obj.MatchString(req.Obj) && p.act.MatchString(req.Act) {
+			matched := p.rule
+			result.Allowed = true
+			result.Matched = &matched
+			break
+		}
+	}
+
+	return result
+}
+
+// subjects 请求主体及通过 g 策略继承的全部角色（广度优先，去重）
+func (e *Enforcer) subjects(sub string, roles []string) []string {
+	seen := map[string]bool{sub: true}
+	queue := []string{sub}
+	for _, r := range roles {
+		if !seen[r] {
+			seen[r] = true
+			queue = append(queue, r)
+		}
+	}
+
+	for i := 0; i < len(queue); i++ {
+		for _, r := range e.groups[queue[i]] {
+			if !seen[r] {
+				seen[r] = true
+				queue = append(queue, r)
+			}
+		}
+	}
+
+	return queue
+}
+
+var keyParam = regexp.MustCompile(`:[^/]+`)
+
+// compileKey 按 casbin 的 keyMatch2 规则编译资源路径：/* 匹配任意后缀，:id 匹配单个路径段
+func compileKey(key string) (*regexp.Regexp, error) {
+	key = strings.ReplaceAll(key, "/*", "/.*")
+	key = keyParam.ReplaceAllString(key, "[^/]+")
+	return regexp.Compile("^" + key + "$")
+}
+
+// KeyMatch2 与 casbin 的 keyMatch2 一致，如 /api/v1/users/:id 匹配 /api/v1/users/1
+func KeyMatch2(key1, key2 string) bool {
+	re, err := compileKey(key2)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(key1)
+}
@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const policyCSV = `# 管理员
+p, admin, /api/v1/*, GET|POST|PUT|DELETE
+p, editor, /api/v1/notices/:id, (GET)|(PUT)
+p, alice, /api/v1/users, GET
+
+g, bob, editor
+g, editor, viewer
+p, viewer, /api/v1/dashboard, GET
+`
+
+func TestKeyMatch2(t *testing.T) {
+	assert.True(t, KeyMatch2("/api/v1/users/1", "/api/v1/users/:id"))
+	assert.False(t, KeyMatch2("/api/v1/users/1/roles", "/api/v1/users/:id"))
+	assert.True(t, KeyMatch2("/api/v1/users/1/roles", "/api/v1/users/*"))
+	assert.True(t, KeyMatch2("/api/v1/users", "/api/v1/users"))
+	assert.False(t, KeyMatch2("/api/v1/users2", "/api/v1/users"))
+}
+
+func TestEnforce(t *testing.T) {
+	rules, err := ParseCSV(strings.NewReader(policyCSV))
+	require.NoError(t, err)
+	require.Len(t, rules, 6)
+
+	e := NewEnforcer()
+	assert.False(t, e.Loaded())
+	require.NoError(t, e.Load(rules))
+	assert.True(t, e.Loaded())
+
+	r := e.Enforce(Request{Sub: "alice", Obj: "/api/v1/users", Act: "GET"})
+	assert.True(t, r.Allowed)
+	assert.Equal(t, "p, alice, /api/v1/users, GET", r.Matched.String())
+	assert.False(t, e.Enforce(Request{Sub: "alice", Obj: "/api/v1/users", Act: "POST"}).Allowed)
+
+	// 通过 g 策略继承 editor、viewer
+	r = e.Enforce(Request{Sub: "bob", Obj: "/api/v1/notices/3", Act: "PUT"})
+	assert.True(t, r.Allowed)
+	assert.Equal(t, []string{"bob", "editor", "viewer"}, r.Subjects)
+	assert.True(t, e.Enforce(Request{Sub: "bob", Obj: "/api/v1/dashboard", Act: "GET"}).Allowed)
+	assert.False(t, e.Enforce(Request{Sub: "bob", Obj: "/api/v1/notices", Act: "GET"}).Allowed)
+
+	// 请求携带的角色
+	assert.True(t, e.Enforce(Request{Sub: "carol", Roles: []string{"admin"}, Obj: "/api/v1/roles/1", Act: "DELETE"}).Allowed)
+	assert.False(t, e.Enforce(Request{Sub: "carol", Obj: "/api/v1/roles/1", Act: "DELETE"}).Allowed)
+
+	r = e.Enforce(Request{Sub: RootSubject, Obj: "/anything", Act: "PATCH"})
+	assert.True(t, r.Allowed)
+	assert.True(t, r.Root)
+	assert.Nil(t, r.Matched)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	e := NewEnforcer()
+	require.NoError(t, e.Load([]Rule{{PType: TypePolicy, V0: "alice", V1: "/a", V2: "GET"}}))
+
+	err := e.Load([]Rule{{PType: TypePolicy, V0: "alice", V1: "/a", V2: "*"}})
+	assert.Error(t, err)
+	// 加载失败保留原有策略
+	assert.True(t, e.Enforce(Request{Sub: "alice", Obj: "/a", Act: "GET"}).Allowed)
+
+	_, err = ParseCSV(strings.NewReader("p, alice, /a, GET\nx, alice, bob\n"))
+	assert.EqualError(t, err, `line 2: unknown policy type "x"`)
+}
+
+func TestWriteCSV(t *testing.T) {
+	rules, err := ParseCSV(strings.NewReader(policyCSV))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, rules))
+
+	again, err := ParseCSV(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, rules, again)
+}
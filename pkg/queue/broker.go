@@ -0,0 +1,169 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/top-system/light-admin/pkg/clock"
+)
+
+const (
+	// DefaultBrokerFetchWait is how long Request waits on the broker for a task
+	DefaultBrokerFetchWait = time.Second
+	// brokerPublishTimeout bounds publishing a task to the broker
+	brokerPublishTimeout = 5 * time.Second
+)
+
+type (
+	// Broker carries the IDs of queued tasks between queue instances sharing one
+	// TaskRepository. Every instance consumes as a member of the same worker
+	// group, so each published task is delivered to a single instance.
+	Broker interface {
+		// Publish announces a persisted task that is ready to run
+		Publish(ctx context.Context, taskID uint64) error
+		// Fetch waits up to wait for the next task of the group, it returns
+		// ErrNoTaskInQueue when nothing was published in time
+		Fetch(ctx context.Context, wait time.Duration) (*Delivery, error)
+		// Ack confirms the delivery has been taken over by a worker of this instance
+		Ack(ctx context.Context, d *Delivery) error
+		// Close releases the resources of the broker, the underlying
+		// connection is owned and closed by the caller
+		Close() error
+	}
+
+	// Delivery is a task ID received from the broker
+	Delivery struct {
+		TaskID uint64
+		// Ref identifies the message in the broker, it is only interpreted by the broker
+		Ref interface{}
+	}
+
+	// distributedScheduler publishes persisted tasks to a broker and builds the
+	// tasks delivered to this instance from the repository. Tasks that can not
+	// be restored on another instance (in-memory tasks) and suspended tasks
+	// waiting for their resume time stay in the local scheduler.
+	distributedScheduler struct {
+		local      Scheduler
+		broker     Broker
+		repository TaskRepository
+		logger     Logger
+		clock      clock.Clock
+		fetchWait  time.Duration
+		onDeliver  func(Task)
+		ctx        context.Context
+		cancel     context.CancelFunc
+		stopFlag   int32
+	}
+)
+
+func newDistributedScheduler(local Scheduler, broker Broker, repository TaskRepository, logger Logger, clk clock.Clock, fetchWait time.Duration, onDeliver func(Task)) *distributedScheduler {
+	if fetchWait <= 0 {
+		fetchWait = DefaultBrokerFetchWait
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &distributedScheduler{
+		local:      local,
+		broker:     broker,
+		repository: repository,
+		logger:     logger,
+		clock:      clk,
+		fetchWait:  fetchWait,
+		onDeliver:  onDeliver,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// distributes reports whether the task is handed over to the broker
+func (s *distributedScheduler) distributes(task Task) bool {
+	return task.ShouldPersist() && task.Persisted() && task.Status() == StatusQueued &&
+		task.ResumeTime() <= s.clock.Now().Unix()
+}
+
+// Queue publishes the task to the broker, or keeps it local if it can not be distributed
+func (s *distributedScheduler) Queue(task Task) error {
+	if atomic.LoadInt32(&s.stopFlag) == 1 {
+		return ErrQueueShutdown
+	}
+
+	if !s.distributes(task) {
+		return s.local.Queue(task)
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, brokerPublishTimeout)
+	defer cancel()
+
+	return s.broker.Publish(ctx, uint64(task.ID()))
+}
+
+// Request returns a local task if one is due, otherwise waits for a task from the broker
+func (s *distributedScheduler) Request() (Task, error) {
+	if atomic.LoadInt32(&s.stopFlag) == 1 {
+		return nil, ErrQueueShutdown
+	}
+
+	if t, err := s.local.Request(); t != nil || !errors.Is(err, ErrNoTaskInQueue) {
+		return t, err
+	}
+
+	d, err := s.broker.Fetch(s.ctx, s.fetchWait)
+	if err != nil {
+		if atomic.LoadInt32(&s.stopFlag) == 1 {
+			return nil, ErrQueueShutdown
+		}
+		if !errors.Is(err, ErrNoTaskInQueue) {
+			s.logger.Warning("Failed to fetch task from broker: %s", err)
+		}
+		return nil, ErrNoTaskInQueue
+	}
+
+	t, err := s.restore(d)
+	if ackErr := s.broker.Ack(s.ctx, d); ackErr != nil {
+		s.logger.Warning("Failed to ack task %d in broker: %s", d.TaskID, ackErr)
+	}
+	if err != nil {
+		s.logger.Warning("Skip task %d delivered by broker: %s", d.TaskID, err)
+		return nil, ErrNoTaskInQueue
+	}
+
+	if s.onDeliver != nil {
+		s.onDeliver(t)
+	}
+	return t, nil
+}
+
+// Wake passes the task on to the local scheduler, suspending tasks are never distributed
+func (s *distributedScheduler) Wake(task Task) {
+	if w, ok := s.local.(waker); ok {
+		w.Wake(task)
+	}
+}
+
+// restore builds the delivered task from the repository
+func (s *distributedScheduler) restore(d *Delivery) (Task, error) {
+	model, err := s.repository.GetByID(s.ctx, d.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Duplicated deliveries, e.g. a task resumed by several instances,
+	// are dropped once one of them has started the task
+	if model.Status != StatusQueued {
+		return nil, errors.New("task is " + model.Status.String())
+	}
+
+	return NewTaskFromModel(model)
+}
+
+// Shutdown stops the local scheduler and interrupts the pending fetch
+func (s *distributedScheduler) Shutdown() error {
+	if !atomic.CompareAndSwapInt32(&s.stopFlag, 0, 1) {
+		return ErrQueueShutdown
+	}
+
+	s.cancel()
+	return s.local.Shutdown()
+}
@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/mailer"
+)
+
+const (
+	// EmailTaskType is the type of mail sending tasks
+	EmailTaskType = "email"
+
+	// Summary keys
+	SummaryKeyEmailTo      = "to"
+	SummaryKeyEmailSubject = "subject"
+)
+
+type (
+	// EmailTask sends a rendered mail message, a failed delivery is retried by
+	// the queue with backoff until its retry limit is reached
+	EmailTask struct {
+		*DBTask
+
+		send  EmailSender
+		state *EmailTaskState
+	}
+
+	// EmailTaskState represents the internal state of an email task
+	EmailTaskState struct {
+		Message mailer.Message `json:"message"`
+	}
+
+	// EmailSender delivers a mail message
+	EmailSender func(ctx context.Context, msg mailer.Message) error
+)
+
+func init() {
+	RegisterResumableTaskFactory(EmailTaskType, NewEmailTaskFromModel)
+}
+
+// NewEmailTask creates a task sending msg with send
+func NewEmailTask(send EmailSender, msg mailer.Message, owner *TaskOwner) (Task, error) {
+	if len(msg.To) == 0 {
+		return nil, mailer.ErrNoRecipients
+	}
+
+	state := &EmailTaskState{Message: msg}
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	return &EmailTask{
+		DBTask: &DBTask{
+			TaskModel: &TaskModel{
+				Type:          EmailTaskType,
+				CorrelationID: uuid.Must(uuid.NewV4()),
+				PrivateState:  string(stateBytes),
+				PublicState:   TaskPublicState{},
+			},
+			DirectOwner: owner,
+		},
+		send:  send,
+		state: state,
+	}, nil
+}
+
+// NewEmailTaskFromModel creates an EmailTask from model, it fails until a
+// sender is set, register NewEmailTaskFactory to restore runnable tasks
+func NewEmailTaskFromModel(model *TaskModel) Task {
+	t := &EmailTask{
+		DBTask: &DBTask{
+			TaskModel: model,
+		},
+	}
+	if model.OwnerID > 0 {
+		t.DirectOwner = &TaskOwner{ID: model.OwnerID}
+	}
+	return t
+}
+
+// NewEmailTaskFactory returns a factory restoring email tasks with the sender,
+// register it to run email tasks resumed from the repository or delivered by a broker
+func NewEmailTaskFactory(send EmailSender) ResumableTaskFactory {
+	return func(model *TaskModel) Task {
+		t := NewEmailTaskFromModel(model).(*EmailTask)
+		t.send = send
+		return t
+	}
+}
+
+// Do sends the message
+func (m *EmailTask) Do(ctx context.Context) (Status, error) {
+	state := m.getState()
+	if state == nil {
+		return StatusError, fmt.Errorf("failed to unmarshal state (%w)", CriticalErr)
+	}
+	if m.send == nil {
+		return StatusError, fmt.Errorf("email sender not set (%w)", CriticalErr)
+	}
+
+	if err := m.send(ctx, state.Message); err != nil {
+		return StatusError, fmt.Errorf("failed to send mail to %s: %w", strings.Join(state.Message.To, ", "), err)
+	}
+
+	return StatusCompleted, nil
+}
+
+func (m *EmailTask) getState() *EmailTaskState {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.state == nil {
+		state := &EmailTaskState{}
+		if err := json.Unmarshal([]byte(m.TaskModel.PrivateState), state); err != nil {
+			return nil
+		}
+		m.state = state
+	}
+	return m.state
+}
+
+func (m *EmailTask) Summarize() *Summary {
+	state := m.getState()
+	if state == nil {
+		return nil
+	}
+
+	return &Summary{Props: map[string]any{
+		SummaryKeyEmailTo:      state.Message.To,
+		SummaryKeyEmailSubject: state.Message.Subject,
+	}}
+}
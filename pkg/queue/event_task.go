@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/eventbus"
+)
+
+const (
+	// EventTaskType is the type of async event bus deliveries
+	EventTaskType = "event"
+
+	// Summary keys
+	SummaryKeyEventTopic      = "topic"
+	SummaryKeyEventSubscriber = "subscriber"
+)
+
+type (
+	// EventTask runs an async event bus subscriber, a failed subscriber is
+	// retried by the queue with backoff until its retry limit is reached
+	EventTask struct {
+		*DBTask
+
+		deliver EventDeliverer
+		state   *EventTaskState
+	}
+
+	// EventTaskState represents the internal state of an event task
+	EventTaskState struct {
+		Delivery eventbus.Delivery `json:"delivery"`
+	}
+
+	// EventDeliverer runs the subscriber of a delivery, usually (*eventbus.Bus).Deliver
+	EventDeliverer func(ctx context.Context, d eventbus.Delivery) error
+)
+
+func init() {
+	RegisterResumableTaskFactory(EventTaskType, NewEventTaskFromModel)
+}
+
+// NewEventTask creates a task running the subscriber of d with deliver
+func NewEventTask(deliver EventDeliverer, d eventbus.Delivery, owner *TaskOwner) (Task, error) {
+	state := &EventTaskState{Delivery: d}
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	return &EventTask{
+		DBTask: &DBTask{
+			TaskModel: &TaskModel{
+				Type:          EventTaskType,
+				CorrelationID: uuid.Must(uuid.NewV4()),
+				PrivateState:  string(stateBytes),
+				PublicState:   TaskPublicState{},
+			},
+			DirectOwner: owner,
+		},
+		deliver: deliver,
+		state:   state,
+	}, nil
+}
+
+// NewEventTaskFromModel creates an EventTask from model, it fails until a
+// deliverer is set, register NewEventTaskFactory to restore runnable tasks
+func NewEventTaskFromModel(model *TaskModel) Task {
+	t := &EventTask{
+		DBTask: &DBTask{
+			TaskModel: model,
+		},
+	}
+	if model.OwnerID > 0 {
+		t.DirectOwner = &TaskOwner{ID: model.OwnerID}
+	}
+	return t
+}
+
+// NewEventTaskFactory returns a factory restoring event tasks with the deliverer,
+// register it to run event tasks resumed from the repository or delivered by a broker
+func NewEventTaskFactory(deliver EventDeliverer) ResumableTaskFactory {
+	return func(model *TaskModel) Task {
+		t := NewEventTaskFromModel(model).(*EventTask)
+		t.deliver = deliver
+		return t
+	}
+}
+
+// Do runs the subscriber
+func (m *EventTask) Do(ctx context.Context) (Status, error) {
+	state := m.getState()
+	if state == nil {
+		return StatusError, fmt.Errorf("failed to unmarshal state (%w)", CriticalErr)
+	}
+	if m.deliver == nil {
+		return StatusError, fmt.Errorf("event deliverer not set (%w)", CriticalErr)
+	}
+
+	if err := m.deliver(ctx, state.Delivery); err != nil {
+		if errors.Is(err, eventbus.ErrUnknownSubscriber) {
+			return StatusError, fmt.Errorf("%w (%w)", err, CriticalErr)
+		}
+		return StatusError, fmt.Errorf("subscriber %s of %s failed: %w", state.Delivery.Subscriber, state.Delivery.Topic, err)
+	}
+
+	return StatusCompleted, nil
+}
+
+func (m *EventTask) getState() *EventTaskState {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.state == nil {
+		state := &EventTaskState{}
+		if err := json.Unmarshal([]byte(m.TaskModel.PrivateState), state); err != nil {
+			return nil
+		}
+		m.state = state
+	}
+	return m.state
+}
+
+func (m *EventTask) Summarize() *Summary {
+	state := m.getState()
+	if state == nil {
+		return nil
+	}
+
+	return &Summary{Props: map[string]any{
+		SummaryKeyEventTopic:      state.Delivery.Topic,
+		SummaryKeyEventSubscriber: state.Delivery.Subscriber,
+	}}
+}
@@ -0,0 +1,319 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/report"
+)
+
+const (
+	// ExportTaskType is the type of list export tasks
+	ExportTaskType = "export"
+
+	// Progress and summary keys
+	ProgressKeyExport      = "export"
+	SummaryKeyExportSource = "source"
+	SummaryKeyExportFormat = "format"
+	SummaryKeyExportResult = "result"
+)
+
+// ErrExportTooLarge is returned when an export exceeds the row limit of the registry
+var ErrExportTooLarge = errors.New("too many rows to export")
+
+type (
+	// ExportTask writes the rows of a list query to a CSV or XLSX file, stores
+	// the file and notifies the owner with its link. The file is built from
+	// scratch on every run, so a retried or resumed task starts over
+	ExportTask struct {
+		*DBTask
+
+		registry *ExportRegistry
+		state    *ExportTaskState
+		progress Progresses
+		notified bool
+	}
+
+	// ExportQuery describes what to export: the source registered to the
+	// ExportRegistry and its list query parameters, encoded by the caller and
+	// decoded by the source, so restrictions resolved at request time (such as
+	// data permissions) are kept when the task runs later or on another instance
+	ExportQuery struct {
+		Source string          `json:"source"`
+		Params json.RawMessage `json:"params,omitempty"`
+	}
+
+	// ExportTaskState represents the internal state of an export task
+	ExportTaskState struct {
+		Query  ExportQuery   `json:"query"`
+		Format string        `json:"format"`
+		Result *ExportResult `json:"result,omitempty"`
+	}
+
+	// ExportResult is the stored file of a completed export
+	ExportResult struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+		Rows int64  `json:"rows"`
+		Size int64  `json:"size"`
+	}
+
+	// ExportSource produces the rows of an export
+	ExportSource interface {
+		// Title names the exported file and its sheet
+		Title() string
+		// Columns returns the header row
+		Columns() []string
+		// Rows decodes params and passes the matched rows to fn page by page,
+		// it stops and returns the error once fn fails
+		Rows(ctx context.Context, params json.RawMessage, fn func(rows [][]string) error) error
+	}
+
+	// ExportStore saves the exported file and returns its download link
+	ExportStore func(ctx context.Context, name string, r io.Reader, size int64, contentType string) (string, error)
+
+	// ExportNotifier tells the owner the export is ready, err is set when it failed
+	ExportNotifier func(owner *TaskOwner, taskID int, result *ExportResult, err error)
+
+	// ExportRegistry holds the sources of export tasks and where their files go,
+	// the same registry is used to run new tasks and tasks restored from a model
+	ExportRegistry struct {
+		mu      sync.RWMutex
+		sources map[string]ExportSource
+		store   ExportStore
+		notify  ExportNotifier
+		maxRows int64
+	}
+)
+
+func init() {
+	RegisterResumableTaskFactory(ExportTaskType, NewExportTaskFromModel)
+}
+
+// NewExportRegistry creates an export registry, maxRows limits the rows of
+// one export, zero means unlimited
+func NewExportRegistry(store ExportStore, notify ExportNotifier, maxRows int64) *ExportRegistry {
+	return &ExportRegistry{
+		sources: make(map[string]ExportSource),
+		store:   store,
+		notify:  notify,
+		maxRows: maxRows,
+	}
+}
+
+// Register registers an export source
+func (r *ExportRegistry) Register(name string, source ExportSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources[name] = source
+}
+
+// Get returns an export source by name
+func (r *ExportRegistry) Get(name string) (ExportSource, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.sources[name]
+	return s, ok
+}
+
+// NewExportTask creates a task exporting the rows of query in format, csv or xlsx
+func NewExportTask(registry *ExportRegistry, query ExportQuery, format string, owner *TaskOwner) (Task, error) {
+	format = strings.ToLower(format)
+	if format != report.FormatCSV && format != report.FormatXLSX {
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+	if _, ok := registry.Get(query.Source); !ok {
+		return nil, fmt.Errorf("unknown export source: %s", query.Source)
+	}
+
+	stateBytes, err := json.Marshal(&ExportTaskState{
+		Query:  query,
+		Format: format,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	return &ExportTask{
+		DBTask: &DBTask{
+			TaskModel: &TaskModel{
+				Type:          ExportTaskType,
+				CorrelationID: uuid.Must(uuid.NewV4()),
+				PrivateState:  string(stateBytes),
+				PublicState:   TaskPublicState{},
+			},
+			DirectOwner: owner,
+		},
+		registry: registry,
+		progress: make(Progresses),
+	}, nil
+}
+
+// NewExportTaskFromModel creates an ExportTask from model, it fails until a
+// registry is set, register NewExportTaskFactory to restore runnable tasks
+func NewExportTaskFromModel(model *TaskModel) Task {
+	t := &ExportTask{
+		DBTask: &DBTask{
+			TaskModel: model,
+		},
+		progress: make(Progresses),
+	}
+	if model.OwnerID > 0 {
+		t.DirectOwner = &TaskOwner{ID: model.OwnerID}
+	}
+	return t
+}
+
+// NewExportTaskFactory returns a factory restoring export tasks with the
+// registry, register it to run export tasks resumed from the repository or
+// delivered by a broker
+func NewExportTaskFactory(registry *ExportRegistry) ResumableTaskFactory {
+	return func(model *TaskModel) Task {
+		t := NewExportTaskFromModel(model).(*ExportTask)
+		t.registry = registry
+		return t
+	}
+}
+
+// Do writes the rows to a file and stores it
+func (m *ExportTask) Do(ctx context.Context) (Status, error) {
+	state := m.getState()
+	if state == nil {
+		return StatusError, fmt.Errorf("failed to unmarshal state (%w)", CriticalErr)
+	}
+	if m.registry == nil {
+		return StatusError, fmt.Errorf("export registry not set (%w)", CriticalErr)
+	}
+
+	source, ok := m.registry.Get(state.Query.Source)
+	if !ok {
+		return StatusError, fmt.Errorf("unknown export source %s (%w)", state.Query.Source, CriticalErr)
+	}
+
+	r := &report.Report{}
+	section := r.AddSection(source.Title(), source.Columns()...)
+	var count int64
+	err := source.Rows(ctx, state.Query.Params, func(rows [][]string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		count += int64(len(rows))
+		if m.registry.maxRows > 0 && count > m.registry.maxRows {
+			return fmt.Errorf("%w, limit %d (%w)", ErrExportTooLarge, m.registry.maxRows, CriticalErr)
+		}
+
+		for _, row := range rows {
+			section.AddRow(row...)
+		}
+		m.Lock()
+		m.progress[ProgressKeyExport] = &Progress{Current: count, Identifier: state.Query.Source}
+		m.Unlock()
+		return nil
+	})
+	if err != nil {
+		return StatusError, fmt.Errorf("failed to query rows of %s: %w", state.Query.Source, err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf, state.Format); err != nil {
+		return StatusError, fmt.Errorf("failed to write %s: %w", state.Format, err)
+	}
+
+	name := fmt.Sprintf("%s-%s.%s", source.Title(), time.Now().Format("20060102150405"), state.Format)
+	size := int64(buf.Len())
+	url, err := m.registry.store(ctx, name, &buf, size, report.ContentType(state.Format))
+	if err != nil {
+		return StatusError, fmt.Errorf("failed to store %s: %w", name, err)
+	}
+
+	result := &ExportResult{Name: name, URL: url, Rows: count, Size: size}
+	state.Result = result
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return StatusError, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	m.Lock()
+	m.TaskModel.PrivateState = string(stateBytes)
+	m.Unlock()
+
+	return StatusCompleted, nil
+}
+
+// OnPersisted notifies the owner once the task completes or finally fails
+func (m *ExportTask) OnPersisted(task *TaskModel) {
+	m.DBTask.OnPersisted(task)
+
+	if task.Status != StatusCompleted && task.Status != StatusError {
+		return
+	}
+
+	m.Lock()
+	if m.notified || m.registry == nil || m.registry.notify == nil || m.DirectOwner == nil {
+		m.Unlock()
+		return
+	}
+	m.notified = true
+	owner := m.DirectOwner
+	m.Unlock()
+
+	var err error
+	if task.Status == StatusError {
+		err = errors.New(task.PublicState.Error)
+	}
+	m.registry.notify(owner, int(task.ID), m.Result(), err)
+}
+
+// Result returns the stored file, nil until the task completes
+func (m *ExportTask) Result() *ExportResult {
+	if state := m.getState(); state != nil {
+		return state.Result
+	}
+	return nil
+}
+
+func (m *ExportTask) getState() *ExportTaskState {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.state == nil {
+		state := &ExportTaskState{}
+		if err := json.Unmarshal([]byte(m.TaskModel.PrivateState), state); err != nil {
+			return nil
+		}
+		m.state = state
+	}
+	return m.state
+}
+
+func (m *ExportTask) Summarize() *Summary {
+	state := m.getState()
+	if state == nil {
+		return nil
+	}
+
+	props := map[string]any{
+		SummaryKeyExportSource: state.Query.Source,
+		SummaryKeyExportFormat: state.Format,
+	}
+	if state.Result != nil {
+		props[SummaryKeyExportResult] = state.Result
+	}
+	return &Summary{Props: props}
+}
+
+func (m *ExportTask) Progress(ctx context.Context) Progresses {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.progress.Clone()
+}
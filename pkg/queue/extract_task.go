@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/archive"
+)
+
+const (
+	// ExtractArchiveTaskType is the type of archive extraction tasks
+	ExtractArchiveTaskType = "extract_archive"
+
+	// Progress and summary keys
+	ProgressKeyExtract     = "extract"
+	SummaryKeyArchive      = "archive"
+	SummaryKeyExtractEntry = "entries"
+)
+
+type (
+	// ExtractArchiveTask unpacks selected entries of an archive on the local
+	// filesystem, extraction overwrites existing files so a retried or resumed
+	// task starts over
+	ExtractArchiveTask struct {
+		*DBTask
+
+		state    *ExtractArchiveTaskState
+		progress Progresses
+	}
+
+	// ExtractArchiveTaskState represents the internal state of an extraction task
+	ExtractArchiveTaskState struct {
+		Archive string   `json:"archive"`
+		Dst     string   `json:"dst"`
+		Entries []string `json:"entries,omitempty"` // empty means all entries
+	}
+)
+
+func init() {
+	RegisterResumableTaskFactory(ExtractArchiveTaskType, NewExtractArchiveTaskFromModel)
+}
+
+// NewExtractArchiveTask creates a task extracting entries of the archive into dst
+func NewExtractArchiveTask(archivePath, dst string, entries []string, owner *TaskOwner) (Task, error) {
+	stateBytes, err := json.Marshal(&ExtractArchiveTaskState{
+		Archive: archivePath,
+		Dst:     dst,
+		Entries: entries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	return &ExtractArchiveTask{
+		DBTask: &DBTask{
+			TaskModel: &TaskModel{
+				Type:          ExtractArchiveTaskType,
+				CorrelationID: uuid.Must(uuid.NewV4()),
+				PrivateState:  string(stateBytes),
+				PublicState:   TaskPublicState{},
+			},
+			DirectOwner: owner,
+		},
+		progress: make(Progresses),
+	}, nil
+}
+
+// NewExtractArchiveTaskFromModel creates an ExtractArchiveTask from model
+func NewExtractArchiveTaskFromModel(model *TaskModel) Task {
+	return &ExtractArchiveTask{
+		DBTask: &DBTask{
+			TaskModel: model,
+		},
+		progress: make(Progresses),
+	}
+}
+
+// Do extracts the archive
+func (m *ExtractArchiveTask) Do(ctx context.Context) (Status, error) {
+	state := m.getState()
+	if state == nil {
+		return StatusError, fmt.Errorf("failed to unmarshal state (%w)", CriticalErr)
+	}
+
+	name := filepath.Base(state.Archive)
+	err := archive.Extract(ctx, state.Archive, state.Dst, state.Entries, func(current, total int64) {
+		m.Lock()
+		m.progress[ProgressKeyExtract] = &Progress{Total: total, Current: current, Identifier: name}
+		m.Unlock()
+	})
+	if err != nil {
+		return StatusError, fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+
+	return StatusCompleted, nil
+}
+
+func (m *ExtractArchiveTask) getState() *ExtractArchiveTaskState {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.state == nil {
+		state := &ExtractArchiveTaskState{}
+		if err := json.Unmarshal([]byte(m.TaskModel.PrivateState), state); err != nil {
+			return nil
+		}
+		m.state = state
+	}
+	return m.state
+}
+
+func (m *ExtractArchiveTask) Summarize() *Summary {
+	state := m.getState()
+	if state == nil {
+		return nil
+	}
+
+	// Only names relative to the task are exposed, not local paths
+	return &Summary{
+		Props: map[string]any{
+			SummaryKeyArchive:      filepath.Base(state.Archive),
+			SummaryKeyExtractEntry: state.Entries,
+		},
+	}
+}
+
+func (m *ExtractArchiveTask) Progress(ctx context.Context) Progresses {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.progress.Clone()
+}
@@ -0,0 +1,184 @@
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/top-system/light-admin/pkg/clock"
+)
+
+// OwnerWeightFunc returns the number of consecutive tasks an owner may take
+// per round-robin turn. Values below 1 are treated as 1.
+type OwnerWeightFunc func(ownerID uint64) int
+
+type (
+	// fairScheduler interleaves tasks across owners with weighted round-robin,
+	// so a single owner submitting many tasks cannot starve the others.
+	// Tasks of the same owner are handed out in submission order.
+	fairScheduler struct {
+		sync.Mutex
+		owners   map[uint64]*ownerTasks
+		ring     []uint64 // owners with queued tasks, in turn order
+		cursor   int      // index in ring of the owner whose turn it is
+		served   int      // tasks handed to the current owner in this turn
+		capacity int
+		count    int
+		weight   OwnerWeightFunc
+		logger   Logger
+		clock    clock.Clock
+		stopFlag int32
+	}
+
+	ownerTasks struct {
+		tasks []Task
+	}
+)
+
+// NewFairScheduler creates an owner-aware Scheduler. weight may be nil, in
+// which case every owner gets one task per turn.
+func NewFairScheduler(queueSize int, weight OwnerWeightFunc, logger Logger) Scheduler {
+	return newFairScheduler(queueSize, weight, logger, clock.New())
+}
+
+func newFairScheduler(queueSize int, weight OwnerWeightFunc, logger Logger, clk clock.Clock) *fairScheduler {
+	return &fairScheduler{
+		owners:   make(map[uint64]*ownerTasks),
+		ring:     make([]uint64, 0),
+		capacity: queueSize,
+		weight:   weight,
+		logger:   logger,
+		clock:    clk,
+	}
+}
+
+// Queue appends the task to the queue of its owner
+func (s *fairScheduler) Queue(task Task) error {
+	if atomic.LoadInt32(&s.stopFlag) == 1 {
+		return ErrQueueShutdown
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.capacity > 0 && s.count >= s.capacity {
+		return ErrMaxCapacity
+	}
+
+	id := taskOwnerID(task)
+	owner, ok := s.owners[id]
+	if !ok {
+		owner = &ownerTasks{}
+		s.owners[id] = owner
+		s.ring = append(s.ring, id)
+	}
+	owner.tasks = append(owner.tasks, task)
+	s.count++
+
+	return nil
+}
+
+// Request returns the next ready task of the owner whose turn it is. Owners
+// without a ready task (e.g. all waiting for a retry backoff) are skipped.
+func (s *fairScheduler) Request() (Task, error) {
+	if atomic.LoadInt32(&s.stopFlag) == 1 {
+		return nil, ErrQueueShutdown
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.count == 0 {
+		return nil, ErrNoTaskInQueue
+	}
+
+	now := s.clock.Now().Unix()
+	for tried := len(s.ring); tried > 0; tried-- {
+		id := s.ring[s.cursor]
+		owner := s.owners[id]
+
+		idx := owner.ready(now)
+		if idx < 0 {
+			s.advance()
+			continue
+		}
+
+		task := owner.take(idx)
+		s.count--
+		s.served++
+
+		if len(owner.tasks) == 0 {
+			s.remove(s.cursor)
+		} else if s.served >= s.weightOf(id) {
+			s.advance()
+		}
+
+		return task, nil
+	}
+
+	return nil, ErrNoTaskInQueue
+}
+
+// Shutdown the worker
+func (s *fairScheduler) Shutdown() error {
+	if !atomic.CompareAndSwapInt32(&s.stopFlag, 0, 1) {
+		return ErrQueueShutdown
+	}
+
+	return nil
+}
+
+func (s *fairScheduler) weightOf(id uint64) int {
+	if s.weight == nil {
+		return 1
+	}
+	if w := s.weight(id); w > 1 {
+		return w
+	}
+	return 1
+}
+
+// advance passes the turn to the next owner
+func (s *fairScheduler) advance() {
+	s.served = 0
+	if len(s.ring) > 0 {
+		s.cursor = (s.cursor + 1) % len(s.ring)
+	}
+}
+
+// remove drops the owner at index i from the ring; the turn passes to the owner that follows it
+func (s *fairScheduler) remove(i int) {
+	delete(s.owners, s.ring[i])
+	s.ring = append(s.ring[:i], s.ring[i+1:]...)
+	s.served = 0
+	if s.cursor >= len(s.ring) {
+		s.cursor = 0
+	}
+}
+
+// ready returns the index of the earliest submitted task that may run now, or -1
+func (o *ownerTasks) ready(now int64) int {
+	for i, t := range o.tasks {
+		if t.ResumeTime() <= now {
+			return i
+		}
+	}
+	return -1
+}
+
+func (o *ownerTasks) take(i int) Task {
+	t := o.tasks[i]
+	o.tasks = append(o.tasks[:i], o.tasks[i+1:]...)
+	return t
+}
+
+// taskOwnerID resolves the owner of a task, falling back to the persisted
+// model for tasks resumed from the repository. Tasks without owner share ID 0.
+func taskOwnerID(t Task) uint64 {
+	if owner := t.Owner(); owner != nil {
+		return owner.ID
+	}
+	if model := t.Model(); model != nil {
+		return model.OwnerID
+	}
+	return 0
+}
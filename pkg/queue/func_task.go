@@ -0,0 +1,36 @@
+package queue
+
+import (
+	"context"
+)
+
+// FuncTask 执行一个函数的内存任务（不持久化，服务重启后不会恢复）
+// 适用于报表生成等一次性后台作业，函数返回错误时按队列配置重试，因此需保证可重复执行
+type FuncTask struct {
+	*InMemoryTask
+	fn func(ctx context.Context) error
+}
+
+// NewFuncTask creates a new FuncTask
+func NewFuncTask(taskType string, owner *TaskOwner, fn func(ctx context.Context) error) *FuncTask {
+	return &FuncTask{
+		InMemoryTask: &InMemoryTask{
+			DBTask: &DBTask{
+				TaskModel: &TaskModel{
+					Type: taskType,
+				},
+				DirectOwner: owner,
+			},
+		},
+		fn: fn,
+	}
+}
+
+// Do 执行函数
+func (t *FuncTask) Do(ctx context.Context) (Status, error) {
+	if err := t.fn(ctx); err != nil {
+		return StatusError, err
+	}
+
+	return StatusCompleted, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // TaskModel represents the task model in database
@@ -13,6 +14,7 @@ type TaskModel struct {
 	Type          string         `gorm:"size:100;not null;index" json:"type"`
 	Status        Status         `gorm:"size:50;not null;index" json:"status"`
 	CorrelationID uuid.UUID      `gorm:"type:char(36);index" json:"correlationId"`
+	TraceContext  string         `gorm:"size:512" json:"traceContext,omitempty"` // Propagated trace context of the submitter, see injectTraceContext
 	OwnerID       uint64         `gorm:"index" json:"ownerId"`
 	PrivateState  string         `gorm:"type:text" json:"privateState"`
 	PublicState   TaskPublicState `gorm:"embedded;embeddedPrefix:public_" json:"publicState"`
@@ -21,8 +23,17 @@ type TaskModel struct {
 	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// moduleNamer naming strategy that supports per-module table prefixes
+type moduleNamer interface {
+	ModuleTableName(module, table string) string
+}
+
 // TableName returns the table name for TaskModel
-func (TaskModel) TableName() string {
+// The table belongs to the "queue" module when the naming strategy supports modules
+func (TaskModel) TableName(namer schema.Namer) string {
+	if mn, ok := namer.(moduleNamer); ok {
+		return mn.ModuleTableName("queue", "tasks")
+	}
 	return "sys_tasks"
 }
 
@@ -33,6 +44,11 @@ type TaskPublicState struct {
 	Error            string        `gorm:"type:text" json:"error"`
 	ErrorHistory     StringSlice   `gorm:"type:text" json:"errorHistory"`
 	ResumeTime       int64         `gorm:"default:0" json:"resumeTime"`
+	Priority         int           `gorm:"default:0" json:"priority"` // Higher runs first under the priority scheduler
+
+	// Last-known progress snapshot, kept for inspection after restart or while suspended
+	Progress          Progresses `gorm:"type:text;serializer:json" json:"progress,omitempty"`
+	ProgressUpdatedAt int64      `gorm:"default:0" json:"progressUpdatedAt,omitempty"`
 }
 
 // TaskOwner represents the owner of a task (simplified user interface)
@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultNATSAckWait is how long a delivery may stay unacknowledged before
+// JetStream redelivers it to another consumer
+const DefaultNATSAckWait = time.Minute
+
+// NATSBroker distributes tasks through a JetStream work queue stream consumed
+// by a durable pull consumer shared by all instances.
+type NATSBroker struct {
+	js       jetstream.JetStream
+	consumer jetstream.Consumer
+	subject  string
+}
+
+// NewNATSBroker creates a JetStream broker, the stream and the durable consumer
+// named after group are created or updated as needed
+func NewNATSBroker(ctx context.Context, nc *nats.Conn, stream, group string) (*NATSBroker, error) {
+	if stream == "" {
+		stream = DefaultBrokerStream
+	}
+	if group == "" {
+		group = DefaultBrokerGroup
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := stream + ".tasks"
+	if _, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      stream,
+		Subjects:  []string{subject},
+		Retention: jetstream.WorkQueuePolicy,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create stream %q: %w", stream, err)
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:   group,
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   DefaultNATSAckWait,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer %q: %w", group, err)
+	}
+
+	return &NATSBroker{js: js, consumer: consumer, subject: subject}, nil
+}
+
+func (b *NATSBroker) Publish(ctx context.Context, taskID uint64) error {
+	_, err := b.js.Publish(ctx, b.subject, []byte(strconv.FormatUint(taskID, 10)))
+	return err
+}
+
+func (b *NATSBroker) Fetch(ctx context.Context, wait time.Duration) (*Delivery, error) {
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+
+	batch, err := b.consumer.Fetch(1, jetstream.FetchMaxWait(wait))
+	if err != nil {
+		return nil, err
+	}
+
+	for msg := range batch.Messages() {
+		id, err := strconv.ParseUint(string(msg.Data()), 10, 64)
+		if err != nil {
+			_ = msg.Term()
+			return nil, fmt.Errorf("invalid message %q: %w", msg.Data(), err)
+		}
+		return &Delivery{TaskID: id, Ref: msg}, nil
+	}
+
+	if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+		return nil, err
+	}
+	return nil, ErrNoTaskInQueue
+}
+
+func (b *NATSBroker) Ack(ctx context.Context, d *Delivery) error {
+	msg, ok := d.Ref.(jetstream.Msg)
+	if !ok {
+		return nil
+	}
+	return msg.Ack()
+}
+
+func (b *NATSBroker) Close() error {
+	return nil
+}
@@ -3,6 +3,8 @@ package queue
 import (
 	"runtime"
 	"time"
+
+	"github.com/top-system/light-admin/pkg/clock"
 )
 
 // Option configures a queue
@@ -22,12 +24,19 @@ type options struct {
 	maxTaskExecution   time.Duration // Maximum execution time for a task
 	retryDelay         time.Duration
 	taskPullInterval   time.Duration
+	progressInterval   time.Duration // Interval of progress snapshots persisted while a task is running
 	backoffFactor      float64
 	backoffMaxDuration time.Duration
 	maxRetry           int
 	resumeTaskType     []string
 	workerCount        int
 	name               string
+	scheduler          SchedulerKind   // Ordering of queued tasks, FIFO by default
+	ownerWeight        OwnerWeightFunc // Per-owner weight of the fair scheduler
+	clock              clock.Clock     // Time source of retry backoff, suspension and scheduling
+	synchronous        bool            // Run tasks in the goroutine calling QueueTask
+	broker             Broker          // Distributes queued tasks across instances, nil keeps them in process
+	brokerFetchWait    time.Duration   // How long a worker waits on the broker for a task
 }
 
 func newDefaultOptions() *options {
@@ -38,7 +47,9 @@ func newDefaultOptions() *options {
 		backoffMaxDuration: 60 * time.Second,
 		resumeTaskType:     []string{},
 		taskPullInterval:   1 * time.Second,
+		progressInterval:   10 * time.Second,
 		name:               "default",
+		clock:              clock.New(),
 	}
 }
 
@@ -101,9 +112,68 @@ func WithName(name string) Option {
 	})
 }
 
+// WithProgressSnapshotInterval set the interval of persisting progress snapshots,
+// zero disables snapshots during execution
+func WithProgressSnapshotInterval(d time.Duration) Option {
+	return OptionFunc(func(q *options) {
+		q.progressInterval = d
+	})
+}
+
 // WithTaskPullInterval set task pull interval
 func WithTaskPullInterval(d time.Duration) Option {
 	return OptionFunc(func(q *options) {
 		q.taskPullInterval = d
 	})
 }
+
+// WithFairScheduling enables owner-aware weighted round-robin scheduling,
+// weight may be nil to give every owner the same share
+func WithFairScheduling(weight OwnerWeightFunc) Option {
+	return OptionFunc(func(q *options) {
+		q.scheduler = SchedulerFair
+		q.ownerWeight = weight
+	})
+}
+
+// WithScheduler set the ordering of queued tasks, use WithFairScheduling to
+// pick the fair scheduler with owner weights
+func WithScheduler(kind SchedulerKind) Option {
+	return OptionFunc(func(q *options) {
+		q.scheduler = kind
+	})
+}
+
+// WithClock set the time source used for retry backoff, task suspension and
+// scheduling, tests pass a clock.Fake to control them
+func WithClock(c clock.Clock) Option {
+	return OptionFunc(func(q *options) {
+		if c != nil {
+			q.clock = c
+		}
+	})
+}
+
+// WithSynchronousExecution runs tasks in the goroutine calling QueueTask instead
+// of the worker pool. QueueTask returns once the task and everything it queued
+// reached a final status, suspended tasks are resumed after waiting on the
+// queue clock, so combine with an auto-advancing clock.Fake to skip the waits.
+func WithSynchronousExecution() Option {
+	return OptionFunc(func(q *options) {
+		q.synchronous = true
+	})
+}
+
+// WithBroker distributes queued tasks through a broker so that every queue
+// sharing the broker and the task repository consumes them as one worker group.
+// Tasks are restored on the consuming instance with NewTaskFromModel, so only
+// persisted tasks of registered resumable types are distributed, in-memory
+// tasks and suspended tasks keep running in the local process. fetchWait is
+// how long an idle worker waits on the broker, zero uses DefaultBrokerFetchWait.
+// The broker is ignored without a task repository or in synchronous mode.
+func WithBroker(b Broker, fetchWait time.Duration) Option {
+	return OptionFunc(func(q *options) {
+		q.broker = b
+		q.brokerFetchWait = fetchWait
+	})
+}
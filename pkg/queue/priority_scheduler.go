@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/top-system/light-admin/pkg/clock"
+)
+
+// SchedulerKind selects how a queue orders its queued tasks
+type SchedulerKind string
+
+const (
+	// SchedulerFIFO hands out tasks by resume time
+	SchedulerFIFO SchedulerKind = "fifo"
+	// SchedulerFair interleaves tasks across owners, see WithFairScheduling
+	SchedulerFair SchedulerKind = "fair"
+	// SchedulerPriority hands out tasks with higher Priority first
+	SchedulerPriority SchedulerKind = "priority"
+)
+
+type (
+	// priorityScheduler hands out the ready task with the highest priority,
+	// tasks of the same priority are handed out in submission order. Tasks
+	// waiting for a retry backoff do not block tasks of lower priority.
+	priorityScheduler struct {
+		sync.Mutex
+		tasks    []prioritizedTask // ordered by priority desc, then submission order
+		capacity int
+		logger   Logger
+		clock    clock.Clock
+		stopFlag int32
+	}
+
+	prioritizedTask struct {
+		task     Task
+		priority int
+	}
+)
+
+// NewPriorityScheduler creates a Scheduler ordering tasks by Task.Priority
+func NewPriorityScheduler(queueSize int, logger Logger) Scheduler {
+	return newPriorityScheduler(queueSize, logger, clock.New())
+}
+
+func newPriorityScheduler(queueSize int, logger Logger, clk clock.Clock) *priorityScheduler {
+	return &priorityScheduler{
+		tasks:    make([]prioritizedTask, 0),
+		capacity: queueSize,
+		logger:   logger,
+		clock:    clk,
+	}
+}
+
+// Queue inserts the task after the queued tasks of the same or higher priority
+func (s *priorityScheduler) Queue(task Task) error {
+	if atomic.LoadInt32(&s.stopFlag) == 1 {
+		return ErrQueueShutdown
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.capacity > 0 && len(s.tasks) >= s.capacity {
+		return ErrMaxCapacity
+	}
+
+	item := prioritizedTask{task: task, priority: task.Priority()}
+
+	i := len(s.tasks)
+	for i > 0 && s.tasks[i-1].priority < item.priority {
+		i--
+	}
+	s.tasks = append(s.tasks, prioritizedTask{})
+	copy(s.tasks[i+1:], s.tasks[i:])
+	s.tasks[i] = item
+
+	return nil
+}
+
+// Request returns the first ready task in priority order
+func (s *priorityScheduler) Request() (Task, error) {
+	if atomic.LoadInt32(&s.stopFlag) == 1 {
+		return nil, ErrQueueShutdown
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.clock.Now().Unix()
+	for i, item := range s.tasks {
+		if item.task.ResumeTime() > now {
+			continue
+		}
+		s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+		return item.task, nil
+	}
+
+	return nil, ErrNoTaskInQueue
+}
+
+// Shutdown the worker
+func (s *priorityScheduler) Shutdown() error {
+	if !atomic.CompareAndSwapInt32(&s.stopFlag, 0, 1) {
+		return ErrQueueShutdown
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricCollector exports the Metric of queues to Prometheus, labelled by
+// queue name. Values are read from the metric on every scrape, so the queue
+// keeps no copy of its own
+type MetricCollector struct {
+	mu      sync.RWMutex
+	metrics map[string]Metric
+
+	busyWorkers     *prometheus.Desc
+	suspendingTasks *prometheus.Desc
+	submittedTasks  *prometheus.Desc
+	successTasks    *prometheus.Desc
+	failureTasks    *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*MetricCollector)(nil)
+
+// NewMetricCollector creates a collector, metric names are prefixed with namespace
+func NewMetricCollector(namespace string) *MetricCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "queue", name), help, []string{"queue"}, nil)
+	}
+	return &MetricCollector{
+		metrics:         make(map[string]Metric),
+		busyWorkers:     desc("busy_workers", "Number of workers running a task."),
+		suspendingTasks: desc("suspending_tasks", "Number of tasks waiting to be resumed."),
+		submittedTasks:  desc("submitted_tasks_total", "Total number of tasks submitted to the queue."),
+		successTasks:    desc("success_tasks_total", "Total number of tasks completed successfully."),
+		failureTasks:    desc("failure_tasks_total", "Total number of tasks that failed."),
+	}
+}
+
+// Add exports the metric under the queue name, replacing the one added before
+func (c *MetricCollector) Add(name string, m Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics[name] = m
+}
+
+// Describe implements prometheus.Collector
+func (c *MetricCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.busyWorkers
+	ch <- c.suspendingTasks
+	ch <- c.submittedTasks
+	ch <- c.successTasks
+	ch <- c.failureTasks
+}
+
+// Collect implements prometheus.Collector
+func (c *MetricCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.metrics))
+	for name := range c.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m := c.metrics[name]
+		ch <- prometheus.MustNewConstMetric(c.busyWorkers, prometheus.GaugeValue, float64(m.BusyWorkers()), name)
+		ch <- prometheus.MustNewConstMetric(c.suspendingTasks, prometheus.GaugeValue, float64(m.SuspendingTasks()), name)
+		ch <- prometheus.MustNewConstMetric(c.submittedTasks, prometheus.CounterValue, float64(m.SubmittedTasks()), name)
+		ch <- prometheus.MustNewConstMetric(c.successTasks, prometheus.CounterValue, float64(m.SuccessTasks()), name)
+		ch <- prometheus.MustNewConstMetric(c.failureTasks, prometheus.CounterValue, float64(m.FailureTasks()), name)
+	}
+}
@@ -21,6 +21,10 @@ type (
 		Shutdown()
 		// QueueTask submits a task to the queue
 		QueueTask(ctx context.Context, t Task) error
+		// QueueTaskAt submits a task that is not run before at
+		QueueTaskAt(ctx context.Context, t Task, at time.Time) error
+		// QueueTaskAfter submits a task that is not run before d has passed
+		QueueTaskAfter(ctx context.Context, t Task, d time.Duration) error
 		// BusyWorkers returns the numbers of workers in the running process
 		BusyWorkers() int
 		// SuccessTasks returns the numbers of success tasks
@@ -31,6 +35,14 @@ type (
 		SubmittedTasks() int
 		// SuspendingTasks returns the numbers of suspending tasks
 		SuspendingTasks() int
+		// Metric returns the counters behind the numbers above, for exporting them
+		Metric() Metric
+		// Cancel cancels a queued or suspending task known to this instance, it is skipped when dequeued
+		Cancel(ctx context.Context, id int) error
+		// Retry queues a failed persisted task again, its type must have a resumable task factory
+		Retry(ctx context.Context, id int) error
+		// Wake resumes a suspending task known to this instance now instead of at its resume time
+		Wake(ctx context.Context, id int) error
 	}
 
 	queue struct {
@@ -45,6 +57,11 @@ type (
 		rootCtx      context.Context
 		cancel       context.CancelFunc
 
+		// Synchronous execution, tasks waiting to be run by the draining caller
+		syncMu   sync.Mutex
+		pending  []Task
+		draining bool
+
 		// Dependencies
 		logger         Logger
 		taskRepository TaskRepository
@@ -63,6 +80,12 @@ type (
 var (
 	// CriticalErr is a non-retryable error
 	CriticalErr = errors.New("non-retryable error")
+	// ErrTaskNotFound the task is not known to this queue
+	ErrTaskNotFound = errors.New("queue: task not found")
+	// ErrTaskNotCancelable only queued or suspending tasks can be canceled
+	ErrTaskNotCancelable = errors.New("queue: task is not queued or suspending")
+	// ErrTaskNotRetryable only failed tasks of resumable types can be retried
+	ErrTaskNotRetryable = errors.New("queue: task cannot be retried")
 )
 
 // New creates a new queue
@@ -74,9 +97,35 @@ func New(l Logger, taskRepository TaskRepository, registry TaskRegistry, opts ..
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	var scheduler Scheduler
+	switch o.scheduler {
+	case SchedulerFair:
+		scheduler = newFairScheduler(0, o.ownerWeight, l, o.clock)
+	case SchedulerPriority:
+		scheduler = newPriorityScheduler(0, l, o.clock)
+	default:
+		scheduler = newFifoScheduler(0, l, o.clock)
+	}
+
+	if o.broker != nil && !o.synchronous {
+		if taskRepository == nil {
+			l.Warning("Queue %q has a broker but no task repository, tasks are scheduled in process.", o.name)
+		} else {
+			scheduler = newDistributedScheduler(scheduler, o.broker, taskRepository, l, o.clock, o.brokerFetchWait, func(t Task) {
+				// Tasks delivered by the broker are only known to this instance from now on
+				if c, ok := t.(clockAware); ok {
+					c.SetClock(o.clock)
+				}
+				if registry != nil {
+					registry.Set(t.ID(), t)
+				}
+			})
+		}
+	}
+
 	return &queue{
 		routineGroup:   newRoutineGroup(),
-		scheduler:      NewFifoScheduler(0, l),
+		scheduler:      scheduler,
 		quit:           make(chan struct{}),
 		ready:          make(chan struct{}, 1),
 		metric:         &metric{},
@@ -122,6 +171,11 @@ func (q *queue) Start() {
 			q.logger.Info("Resumed %d tasks from DB.", resumed)
 		}
 
+		// Tasks are run by the callers of QueueTask
+		if q.synchronous {
+			return
+		}
+
 		q.start()
 	})
 	q.logger.Info("Queue %q started with %d workers.", q.name, q.workerCount)
@@ -176,12 +230,22 @@ func (q *queue) SuspendingTasks() int {
 	return int(q.metric.SuspendingTasks())
 }
 
+// Metric returns the metric of the queue
+func (q *queue) Metric() Metric {
+	return q.metric
+}
+
 // QueueTask to queue single task
 func (q *queue) QueueTask(ctx context.Context, t Task) error {
 	if atomic.LoadInt32(&q.stopFlag) == 1 {
 		return ErrQueueShutdown
 	}
 
+	if c, ok := t.(clockAware); ok {
+		c.SetClock(q.clock)
+	}
+	injectTraceContext(ctx, t)
+
 	if t.Status() != StatusSuspending {
 		q.metric.IncSubmittedTask()
 		if err := q.transitStatus(ctx, t, StatusQueued); err != nil {
@@ -189,6 +253,19 @@ func (q *queue) QueueTask(ctx context.Context, t Task) error {
 		}
 	}
 
+	if q.synchronous {
+		if q.registry != nil {
+			q.registry.Set(t.ID(), t)
+		}
+		return q.runSynchronously(t)
+	}
+
+	// Distributed tasks are registered by the instance consuming them
+	distributed := false
+	if d, ok := q.scheduler.(*distributedScheduler); ok {
+		distributed = d.distributes(t)
+	}
+
 	if err := q.scheduler.Queue(t); err != nil {
 		return err
 	}
@@ -197,13 +274,93 @@ func (q *queue) QueueTask(ctx context.Context, t Task) error {
 		owner = t.Owner().Email
 	}
 	q.logger.Info("New Task with type %q submitted to queue %q by %q", t.Type(), q.name, owner)
-	if q.registry != nil {
+	if q.registry != nil && !distributed {
 		q.registry.Set(t.ID(), t)
 	}
 
 	return nil
 }
 
+// QueueTaskAt submits a task now but keeps it waiting until at, the start time is
+// persisted as the resume time, so a delayed task survives a restart like a suspended one.
+// Delayed tasks stay in the local scheduler until they are due, a broker does not carry them.
+func (q *queue) QueueTaskAt(ctx context.Context, t Task, at time.Time) error {
+	if at.After(q.clock.Now()) {
+		t.OnSuspend(at.Unix())
+	}
+	return q.QueueTask(ctx, t)
+}
+
+// QueueTaskAfter submits a task now but keeps it waiting until d has passed
+func (q *queue) QueueTaskAfter(ctx context.Context, t Task, d time.Duration) error {
+	return q.QueueTaskAt(ctx, t, q.clock.Now().Add(d))
+}
+
+// Cancel cancels a queued or suspending task, the task stays in the scheduler and is skipped when dequeued.
+// Only tasks in the registry of this instance can be canceled, a task delivered to another instance by the
+// broker is not known here.
+func (q *queue) Cancel(ctx context.Context, id int) error {
+	if q.registry == nil {
+		return ErrTaskNotFound
+	}
+
+	t, ok := q.registry.Get(id)
+	if !ok || t == nil {
+		return ErrTaskNotFound
+	}
+	if s := t.Status(); s != StatusQueued && s != StatusSuspending {
+		return fmt.Errorf("%w: %s", ErrTaskNotCancelable, s)
+	}
+
+	return q.transitStatus(q.newContext(t), t, StatusCanceled)
+}
+
+// Retry loads a failed task from the repository and queues it again. The retry count is kept,
+// so a task that used up its automatic retries gets exactly one more attempt.
+func (q *queue) Retry(ctx context.Context, id int) error {
+	if q.taskRepository == nil {
+		return ErrTaskNotFound
+	}
+
+	model, err := q.taskRepository.GetByID(ctx, uint64(id))
+	if err != nil || model == nil {
+		return ErrTaskNotFound
+	}
+	if model.Status != StatusError {
+		return fmt.Errorf("%w: status is %s", ErrTaskNotRetryable, model.Status)
+	}
+
+	t, err := NewTaskFromModel(model)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrTaskNotRetryable, err)
+	}
+
+	return q.QueueTask(ctx, t)
+}
+
+// Wake makes a suspending task due now, e.g. when an external event reports that the work it
+// waits for is done. Tasks that are not suspending are left alone. In synchronous mode a wait
+// that already started is not shortened.
+func (q *queue) Wake(ctx context.Context, id int) error {
+	if q.registry == nil {
+		return ErrTaskNotFound
+	}
+
+	t, ok := q.registry.Get(id)
+	if !ok || t == nil {
+		return ErrTaskNotFound
+	}
+	if t.Status() != StatusSuspending {
+		return nil
+	}
+
+	t.OnSuspend(q.clock.Now().Unix())
+	if w, ok := q.scheduler.(waker); ok {
+		w.Wake(t)
+	}
+	return nil
+}
+
 // newContext creates a new context for a new task iteration
 func (q *queue) newContext(t Task) context.Context {
 	l := q.logger.CopyWithPrefix(fmt.Sprintf("[Cid: %s TaskID: %d Queue: %s]", t.CorrelationID(), t.ID(), q.name))
@@ -214,7 +371,8 @@ func (q *queue) newContext(t Task) context.Context {
 }
 
 func (q *queue) work(t Task) {
-	ctx := q.newContext(t)
+	ctx, span := q.startTaskSpan(q.newContext(t), t)
+	defer endTaskSpan(span, t)
 	l := loggerFromContext(ctx)
 	timeIterationStart := time.Now()
 
@@ -233,6 +391,12 @@ func (q *queue) work(t Task) {
 		q.schedule()
 	}()
 
+	// Canceled while waiting in the scheduler
+	if t.Status() == StatusCanceled {
+		l.Info("Task %d was canceled before it started, skipped.", t.ID())
+		return
+	}
+
 	err = q.transitStatus(ctx, t, StatusProcessing)
 	if err != nil {
 		l.Error("failed to transit task %d to processing: %s", t.ID(), err.Error())
@@ -275,6 +439,11 @@ func (q *queue) run(ctx context.Context, t Task) (Status, error) {
 		cancel()
 	}()
 
+	// persist progress snapshots while the iteration is running
+	if q.progressInterval > 0 && q.taskRepository != nil && t.ShouldPersist() {
+		go q.watchProgress(ctx, t)
+	}
+
 	// run the job
 	go func() {
 		// handle panic issue
@@ -301,7 +470,7 @@ func (q *queue) run(ctx context.Context, t Task) (Status, error) {
 
 			// Resume after to retry
 			l.Info("Will be retried in %s", delay)
-			t.OnSuspend(time.Now().Add(delay).Unix())
+			t.OnSuspend(q.clock.Now().Add(delay).Unix())
 			err = nil
 			next = StatusSuspending
 		}
@@ -336,6 +505,44 @@ func (q *queue) run(ctx context.Context, t Task) (Status, error) {
 	}
 }
 
+// watchProgress periodically persists the task progress until ctx is done
+func (q *queue) watchProgress(ctx context.Context, t Task) {
+	ticker := q.clock.NewTicker(q.progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			q.snapshotProgress(ctx, t)
+		}
+	}
+}
+
+// snapshotProgress persists the current task progress into its public state
+func (q *queue) snapshotProgress(ctx context.Context, t Task) {
+	progress := t.Progress(ctx).Clone()
+	if len(progress) == 0 {
+		return
+	}
+
+	model := t.Model()
+	if model == nil || model.ID == 0 {
+		return
+	}
+
+	updatedAt := q.clock.Now().Unix()
+	t.Lock()
+	model.PublicState.Progress = progress
+	model.PublicState.ProgressUpdatedAt = updatedAt
+	t.Unlock()
+
+	if err := q.taskRepository.UpdateProgress(ctx, model.ID, progress, updatedAt); err != nil {
+		loggerFromContext(ctx).Warning("Failed to persist progress of task %d: %s", model.ID, err)
+	}
+}
+
 // transitStatus updates task status
 func (q *queue) transitStatus(ctx context.Context, task Task, to Status) (err error) {
 	old := task.Status()
@@ -398,7 +605,7 @@ func (q *queue) start() {
 								close(tasks)
 								return
 							}
-						case <-time.After(q.taskPullInterval):
+						case <-q.clock.After(q.taskPullInterval):
 							// sleep to fetch new task
 						}
 					}
@@ -432,6 +639,66 @@ func (q *queue) start() {
 	}
 }
 
+// runSynchronously runs t and every task queued meanwhile in the calling
+// goroutine, waiting on the queue clock for suspended tasks to resume.
+// A concurrent caller only appends its task, which is then run by the
+// goroutine already draining.
+func (q *queue) runSynchronously(t Task) error {
+	q.syncMu.Lock()
+	q.pending = append(q.pending, t)
+	if q.draining {
+		q.syncMu.Unlock()
+		return nil
+	}
+	q.draining = true
+	q.syncMu.Unlock()
+
+	defer func() {
+		q.syncMu.Lock()
+		q.draining = false
+		q.syncMu.Unlock()
+	}()
+
+	for {
+		next := q.nextPending()
+		if next == nil {
+			return nil
+		}
+
+		if wait := time.Unix(next.ResumeTime(), 0).Sub(q.clock.Now()); wait > 0 {
+			select {
+			case <-q.clock.After(wait):
+			case <-q.quit:
+				return ErrQueueShutdown
+			}
+		}
+
+		q.metric.IncBusyWorker()
+		q.work(next)
+	}
+}
+
+// nextPending pops the pending task with the earliest resume time
+func (q *queue) nextPending() Task {
+	q.syncMu.Lock()
+	defer q.syncMu.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	idx := 0
+	for i, t := range q.pending {
+		if t.ResumeTime() < q.pending[idx].ResumeTime() {
+			idx = i
+		}
+	}
+
+	t := q.pending[idx]
+	q.pending = append(q.pending[:idx], q.pending[idx+1:]...)
+	return t
+}
+
 func loggerFromContext(ctx context.Context) Logger {
 	if l, ok := ctx.Value(LoggerCtx{}).(Logger); ok {
 		return l
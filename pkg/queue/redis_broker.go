@@ -0,0 +1,164 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// DefaultBrokerStream is the default stream name of the brokers
+	DefaultBrokerStream = "light-admin-queue"
+	// DefaultBrokerGroup is the default worker group of the brokers
+	DefaultBrokerGroup = "workers"
+	// DefaultRedisStreamMaxLen caps the stream length, acknowledged entries are trimmed first
+	DefaultRedisStreamMaxLen = 10000
+	// DefaultRedisStreamClaimIdle is how long a delivery may stay unacknowledged
+	// before another consumer claims it
+	DefaultRedisStreamClaimIdle = time.Minute
+)
+
+// RedisStreamBroker distributes tasks through a Redis Stream consumed by a
+// consumer group. A delivery read by a consumer that dies before acknowledging
+// it is claimed by another consumer after ClaimIdle.
+type RedisStreamBroker struct {
+	client    redis.UniversalClient
+	stream    string
+	group     string
+	consumer  string
+	maxLen    int64
+	claimIdle time.Duration
+
+	mu         sync.Mutex
+	groupReady bool
+}
+
+// NewRedisStreamBroker creates a Redis Streams broker, consumer must be unique per instance
+func NewRedisStreamBroker(client redis.UniversalClient, stream, group, consumer string) *RedisStreamBroker {
+	if stream == "" {
+		stream = DefaultBrokerStream
+	}
+	if group == "" {
+		group = DefaultBrokerGroup
+	}
+
+	return &RedisStreamBroker{
+		client:    client,
+		stream:    stream,
+		group:     group,
+		consumer:  consumer,
+		maxLen:    DefaultRedisStreamMaxLen,
+		claimIdle: DefaultRedisStreamClaimIdle,
+	}
+}
+
+// ensureGroup creates the stream and the consumer group on first use
+func (b *RedisStreamBroker) ensureGroup(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.groupReady {
+		return nil
+	}
+
+	err := b.client.XGroupCreateMkStream(ctx, b.stream, b.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %q: %w", b.group, err)
+	}
+
+	b.groupReady = true
+	return nil
+}
+
+func (b *RedisStreamBroker) Publish(ctx context.Context, taskID uint64) error {
+	return b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream,
+		MaxLen: b.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{"task_id": taskID},
+	}).Err()
+}
+
+func (b *RedisStreamBroker) Fetch(ctx context.Context, wait time.Duration) (*Delivery, error) {
+	if err := b.ensureGroup(ctx); err != nil {
+		return nil, err
+	}
+
+	// Take over deliveries left unacknowledged by a dead consumer first
+	claimed, _, err := b.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   b.stream,
+		Group:    b.group,
+		Consumer: b.consumer,
+		MinIdle:  b.claimIdle,
+		Start:    "0-0",
+		Count:    1,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		return b.delivery(claimed[0])
+	}
+
+	if wait < time.Millisecond {
+		wait = time.Millisecond
+	}
+	streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    b.group,
+		Consumer: b.consumer,
+		Streams:  []string{b.stream, ">"},
+		Count:    1,
+		Block:    wait,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNoTaskInQueue
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range streams {
+		if len(s.Messages) > 0 {
+			return b.delivery(s.Messages[0])
+		}
+	}
+	return nil, ErrNoTaskInQueue
+}
+
+// delivery parses a stream entry, malformed entries are acknowledged and skipped
+func (b *RedisStreamBroker) delivery(msg redis.XMessage) (*Delivery, error) {
+	d := &Delivery{Ref: msg.ID}
+
+	raw, _ := msg.Values["task_id"].(string)
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		_ = b.Ack(context.Background(), d)
+		return nil, fmt.Errorf("invalid stream entry %s: %w", msg.ID, err)
+	}
+
+	d.TaskID = id
+	return d, nil
+}
+
+func (b *RedisStreamBroker) Ack(ctx context.Context, d *Delivery) error {
+	id, _ := d.Ref.(string)
+	if id == "" {
+		return nil
+	}
+
+	pipe := b.client.TxPipeline()
+	pipe.XAck(ctx, b.stream, b.group, id)
+	pipe.XDel(ctx, b.stream, id)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *RedisStreamBroker) Close() error {
+	return nil
+}
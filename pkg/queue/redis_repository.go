@@ -0,0 +1,382 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// DefaultRedisKeyPrefix is the key prefix used when none is given
+const DefaultRedisKeyPrefix = "queue"
+
+// Redis hash fields of a stored task
+const (
+	redisFieldData              = "data"
+	redisFieldType              = "type"
+	redisFieldStatus            = "status"
+	redisFieldProgress          = "progress"
+	redisFieldProgressUpdatedAt = "progress_updated_at"
+)
+
+// saveTaskScript writes the task hash and moves the task in or out of the
+// pending sets in a single step, so a status transition is never observed
+// half-applied by GetPendingTasks. Finished tasks expire after the TTL, pending
+// ones never expire.
+//
+// KEYS[1] task hash, KEYS[2] global pending set, KEYS[3] pending set of the task type, KEYS[4] task index
+// ARGV[1] id, ARGV[2] data, ARGV[3] type, ARGV[4] status, ARGV[5] "1" if pending,
+// ARGV[6] progress, ARGV[7] progress updated at, ARGV[8] pending set key prefix,
+// ARGV[9] TTL of finished tasks in seconds, 0 keeps them
+var saveTaskScript = redis.NewScript(`
+local oldType = redis.call('HGET', KEYS[1], 'type')
+if oldType and oldType ~= ARGV[3] then
+	redis.call('SREM', ARGV[8] .. oldType, ARGV[1])
+end
+local oldStatus = redis.call('HGET', KEYS[1], 'status')
+redis.call('HSET', KEYS[1],
+	'data', ARGV[2],
+	'type', ARGV[3],
+	'status', ARGV[4],
+	'progress', ARGV[6],
+	'progress_updated_at', ARGV[7])
+redis.call('ZADD', KEYS[4], ARGV[1], ARGV[1])
+if ARGV[5] == '1' then
+	redis.call('SADD', KEYS[2], ARGV[1])
+	redis.call('SADD', KEYS[3], ARGV[1])
+	redis.call('PERSIST', KEYS[1])
+else
+	redis.call('SREM', KEYS[2], ARGV[1])
+	redis.call('SREM', KEYS[3], ARGV[1])
+	if tonumber(ARGV[9]) > 0 then
+		redis.call('EXPIRE', KEYS[1], ARGV[9])
+	end
+end
+return oldStatus or ''
+`)
+
+// updateProgressScript updates the progress fields of an existing task only.
+// Returns 0 when the task does not exist.
+//
+// KEYS[1] task hash
+// ARGV[1] progress, ARGV[2] progress updated at
+var updateProgressScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'progress', ARGV[1], 'progress_updated_at', ARGV[2])
+return 1
+`)
+
+// deleteTaskScript removes the task hash together with its pending set and index entries.
+//
+// KEYS[1] task hash, KEYS[2] global pending set, KEYS[3] task index
+// ARGV[1] id, ARGV[2] pending set key prefix
+var deleteTaskScript = redis.NewScript(`
+local taskType = redis.call('HGET', KEYS[1], 'type')
+if taskType then
+	redis.call('SREM', ARGV[2] .. taskType, ARGV[1])
+end
+redis.call('SREM', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+return redis.call('DEL', KEYS[1])
+`)
+
+// RedisTaskRepository implements TaskRepository on top of Redis.
+//
+// Each task is stored in a hash "<prefix>:task:<id>" holding the JSON encoded
+// model plus its type, status and progress. IDs are allocated from
+// "<prefix>:task:seq". Pending task IDs are indexed in "<prefix>:pending" and
+// "<prefix>:pending:<type>", and all task IDs in the sorted set "<prefix>:tasks"
+// used for listing; all writes that touch the status go through Lua scripts so
+// the hash and the indexes are updated atomically.
+//
+// Finished tasks expire after finishedTTL (zero keeps them); index entries of
+// expired tasks are dropped when they are next read. Deleted tasks are removed
+// from Redis rather than soft deleted.
+type RedisTaskRepository struct {
+	client      redis.UniversalClient
+	prefix      string
+	finishedTTL time.Duration
+}
+
+// NewRedisTaskRepository creates a new Redis task repository
+func NewRedisTaskRepository(client redis.UniversalClient, prefix string, finishedTTL time.Duration) TaskRepository {
+	if prefix == "" {
+		prefix = DefaultRedisKeyPrefix
+	}
+	return &RedisTaskRepository{client: client, prefix: prefix, finishedTTL: finishedTTL}
+}
+
+func (r *RedisTaskRepository) taskKey(id uint64) string {
+	return fmt.Sprintf("%s:task:%d", r.prefix, id)
+}
+
+func (r *RedisTaskRepository) seqKey() string {
+	return r.prefix + ":task:seq"
+}
+
+func (r *RedisTaskRepository) pendingKey() string {
+	return r.prefix + ":pending"
+}
+
+func (r *RedisTaskRepository) pendingTypePrefix() string {
+	return r.prefix + ":pending:"
+}
+
+func (r *RedisTaskRepository) indexKey() string {
+	return r.prefix + ":tasks"
+}
+
+func (r *RedisTaskRepository) Create(ctx context.Context, task *TaskModel) error {
+	id, err := r.client.Incr(ctx, r.seqKey()).Result()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	task.ID = uint64(id)
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now
+	}
+	task.UpdatedAt = now
+
+	return r.save(ctx, task)
+}
+
+func (r *RedisTaskRepository) Update(ctx context.Context, task *TaskModel) error {
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now()
+	}
+	task.UpdatedAt = time.Now()
+
+	return r.save(ctx, task)
+}
+
+func (r *RedisTaskRepository) save(ctx context.Context, task *TaskModel) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	progress, err := json.Marshal(task.PublicState.Progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task progress: %w", err)
+	}
+
+	pending := "0"
+	if isPendingStatus(task.Status) {
+		pending = "1"
+	}
+
+	keys := []string{r.taskKey(task.ID), r.pendingKey(), r.pendingTypePrefix() + task.Type, r.indexKey()}
+	return saveTaskScript.Run(ctx, r.client, keys,
+		task.ID, data, task.Type, string(task.Status), pending,
+		progress, task.PublicState.ProgressUpdatedAt, r.pendingTypePrefix(),
+		int64(r.finishedTTL/time.Second),
+	).Err()
+}
+
+func (r *RedisTaskRepository) GetByID(ctx context.Context, id uint64) (*TaskModel, error) {
+	values, err := r.client.HMGet(ctx, r.taskKey(id),
+		redisFieldData, redisFieldProgress, redisFieldProgressUpdatedAt).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRedisTask(values)
+}
+
+func (r *RedisTaskRepository) GetPendingTasks(ctx context.Context, types ...string) ([]*TaskModel, error) {
+	var ids []string
+	if len(types) == 0 {
+		members, err := r.client.SMembers(ctx, r.pendingKey()).Result()
+		if err != nil {
+			return nil, err
+		}
+		ids = members
+	} else {
+		keys := make([]string, 0, len(types))
+		for _, t := range types {
+			keys = append(keys, r.pendingTypePrefix()+t)
+		}
+		members, err := r.client.SUnion(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		ids = members
+	}
+
+	loaded, _, err := r.load(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*TaskModel, 0, len(loaded))
+	for _, task := range loaded {
+		// Guard against a stale index entry, mirroring the status filter of the other repositories
+		if isPendingStatus(task.Status) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+// load reads the tasks of the given IDs, returning the IDs whose hash no longer exists separately
+func (r *RedisTaskRepository) load(ctx context.Context, ids []string) ([]*TaskModel, []string, error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make(map[string]*redis.SliceCmd, len(ids))
+	for _, raw := range ids {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		cmds[raw] = pipe.HMGet(ctx, r.taskKey(id),
+			redisFieldData, redisFieldProgress, redisFieldProgressUpdatedAt)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, nil, err
+	}
+
+	tasks := make([]*TaskModel, 0, len(cmds))
+	missing := make([]string, 0)
+	for _, raw := range ids {
+		cmd, ok := cmds[raw]
+		if !ok {
+			continue
+		}
+
+		task, err := decodeRedisTask(cmd.Val())
+		if err == gorm.ErrRecordNotFound {
+			// Index entry left behind by an expired or manually removed key
+			missing = append(missing, raw)
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, missing, nil
+}
+
+// all reads every task in the index and drops the index entries of expired tasks
+func (r *RedisTaskRepository) all(ctx context.Context) ([]*TaskModel, error) {
+	ids, err := r.client.ZRevRange(ctx, r.indexKey(), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, missing, err := r.load(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		members := make([]interface{}, 0, len(missing))
+		for _, id := range missing {
+			members = append(members, id)
+		}
+		if err := r.client.ZRem(ctx, r.indexKey(), members...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return tasks, nil
+}
+
+func (r *RedisTaskRepository) List(ctx context.Context, filter *TaskFilter) ([]*TaskModel, int64, error) {
+	tasks, err := r.all(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list, total := filterTasks(tasks, filter)
+	return list, total, nil
+}
+
+func (r *RedisTaskRepository) Types(ctx context.Context) ([]string, error) {
+	tasks, err := r.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	types := make([]string, 0)
+	for _, task := range tasks {
+		if !seen[task.Type] {
+			seen[task.Type] = true
+			types = append(types, task.Type)
+		}
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+func (r *RedisTaskRepository) Delete(ctx context.Context, id uint64) error {
+	return deleteTaskScript.Run(ctx, r.client,
+		[]string{r.taskKey(id), r.pendingKey(), r.indexKey()},
+		id, r.pendingTypePrefix(),
+	).Err()
+}
+
+func (r *RedisTaskRepository) UpdateProgress(ctx context.Context, id uint64, progress Progresses, updatedAt int64) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task progress: %w", err)
+	}
+
+	updated, err := updateProgressScript.Run(ctx, r.client,
+		[]string{r.taskKey(id)}, data, updatedAt,
+	).Int()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// decodeRedisTask decodes the HMGET result of data, progress and progress_updated_at.
+// The progress fields are stored separately and take precedence over the snapshot in data.
+func decodeRedisTask(values []interface{}) (*TaskModel, error) {
+	if len(values) < 3 || values[0] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	data, _ := values[0].(string)
+	task := &TaskModel{}
+	if err := json.Unmarshal([]byte(data), task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+	}
+
+	if raw, ok := values[1].(string); ok && raw != "" {
+		var progress Progresses
+		if err := json.Unmarshal([]byte(raw), &progress); err == nil {
+			task.PublicState.Progress = progress
+		}
+	}
+
+	if raw, ok := values[2].(string); ok && raw != "" {
+		if updatedAt, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			task.PublicState.ProgressUpdatedAt = updatedAt
+		}
+	}
+
+	return task, nil
+}
+
+func isPendingStatus(status Status) bool {
+	return status == StatusQueued || status == StatusProcessing || status == StatusSuspending
+}
@@ -1,6 +1,9 @@
 package queue
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type (
 	// TaskRegistry is used to track in-memory stateful tasks
@@ -13,6 +16,8 @@ type (
 		Set(id int, t Task)
 		// Delete deletes the task by ID
 		Delete(id int)
+		// List returns the tracked tasks ordered by ID
+		List() []Task
 	}
 
 	taskRegistry struct {
@@ -58,3 +63,20 @@ func (r *taskRegistry) Delete(id int) {
 
 	delete(r.tasks, id)
 }
+
+func (r *taskRegistry) List() []Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]int, 0, len(r.tasks))
+	for id := range r.tasks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	list := make([]Task, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, r.tasks[id])
+	}
+	return list
+}
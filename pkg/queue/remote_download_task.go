@@ -20,6 +20,7 @@ type (
 		l        Logger
 		state    *RemoteDownloadTaskState
 		d        downloader.Downloader
+		seeding  *SeedingPolicy
 		progress Progresses
 	}
 
@@ -29,6 +30,7 @@ type (
 	// RemoteDownloadTaskState represents the internal state of a download task
 	RemoteDownloadTaskState struct {
 		URL                string                  `json:"url"`
+		File               *downloader.SourceFile  `json:"file,omitempty"` // Uploaded .torrent or .metalink file, the content is dropped once the task is created
 		Dst                string                  `json:"dst,omitempty"`
 		Downloader         string                  `json:"downloader"`
 		Handle             *downloader.TaskHandle  `json:"handle,omitempty"`
@@ -36,6 +38,16 @@ type (
 		Phase              RemoteDownloadTaskPhase `json:"phase,omitempty"`
 		GetTaskStatusTried int                     `json:"get_task_status_tried,omitempty"`
 		Options            map[string]interface{}  `json:"options,omitempty"`
+		SeedingSince       int64                   `json:"seeding_since,omitempty"` // Unix time the task started seeding
+	}
+
+	// SeedingPolicy stops seeding once one of its goals is reached and completes the task,
+	// zero values disable the goal
+	SeedingPolicy struct {
+		// MaxRatio is the upload ratio, uploaded bytes divided by the size of the download
+		MaxRatio float64
+		// MaxSeedTime is how long the task seeds, counted from the first time it was seen seeding
+		MaxSeedTime time.Duration
 	}
 )
 
@@ -49,6 +61,7 @@ const (
 	// Summary keys
 	SummaryKeyDownloadStatus = "download"
 	SummaryKeySrcURL         = "src_url"
+	SummaryKeySrcFile        = "src_file"
 	SummaryKeyDownloader     = "downloader"
 )
 
@@ -58,11 +71,24 @@ func init() {
 
 // NewRemoteDownloadTask creates a new RemoteDownloadTask
 func NewRemoteDownloadTask(ctx context.Context, url string, downloaderName string, options map[string]interface{}, owner *TaskOwner) (Task, error) {
-	state := &RemoteDownloadTaskState{
+	return newRemoteDownloadTask(&RemoteDownloadTaskState{
 		URL:        url,
 		Downloader: downloaderName,
 		Options:    options,
-	}
+	}, owner)
+}
+
+// NewRemoteDownloadTaskFromFile creates a new RemoteDownloadTask from an uploaded .torrent or .metalink file,
+// the downloader must implement downloader.FileCreator
+func NewRemoteDownloadTaskFromFile(ctx context.Context, file *downloader.SourceFile, downloaderName string, options map[string]interface{}, owner *TaskOwner) (Task, error) {
+	return newRemoteDownloadTask(&RemoteDownloadTaskState{
+		File:       file,
+		Downloader: downloaderName,
+		Options:    options,
+	}, owner)
+}
+
+func newRemoteDownloadTask(state *RemoteDownloadTaskState, owner *TaskOwner) (Task, error) {
 	stateBytes, err := json.Marshal(state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal state: %w", err)
@@ -95,11 +121,54 @@ func NewRemoteDownloadTaskFromModel(model *TaskModel) Task {
 	}
 }
 
+// NewRemoteDownloadTaskFactory returns a resumable task factory that restores
+// the downloader and seeding policy of the task from the registry, register it to run download
+// tasks resumed from the repository or delivered by a broker
+func NewRemoteDownloadTaskFactory(registry *DownloaderRegistry) ResumableTaskFactory {
+	return func(model *TaskModel) Task {
+		t := NewRemoteDownloadTaskFromModel(model).(*RemoteDownloadTask)
+
+		state := &RemoteDownloadTaskState{}
+		if err := json.Unmarshal([]byte(model.PrivateState), state); err == nil {
+			if d, ok := registry.Get(state.Downloader); ok {
+				t.SetDownloader(d)
+			}
+		}
+		t.SetSeedingPolicy(registry.SeedingPolicy())
+		return t
+	}
+}
+
 // SetDownloader sets the downloader instance for the task
 func (m *RemoteDownloadTask) SetDownloader(d downloader.Downloader) {
 	m.d = d
 }
 
+// SetSeedingPolicy sets when the task stops seeding, nil seeds until the task is canceled
+func (m *RemoteDownloadTask) SetSeedingPolicy(p *SeedingPolicy) {
+	m.seeding = p
+}
+
+// Reached reports whether the seeding task reached one of the goals
+func (p *SeedingPolicy) Reached(status *downloader.TaskStatus, seedTime time.Duration) bool {
+	if p == nil {
+		return false
+	}
+	if p.MaxSeedTime > 0 && seedTime >= p.MaxSeedTime {
+		return true
+	}
+	if p.MaxRatio > 0 {
+		size := status.Total
+		if size <= 0 {
+			size = status.Downloaded
+		}
+		if size > 0 && float64(status.Uploaded)/float64(size) >= p.MaxRatio {
+			return true
+		}
+	}
+	return false
+}
+
 // Do executes the download task
 func (m *RemoteDownloadTask) Do(ctx context.Context) (Status, error) {
 	// Get logger from context
@@ -150,14 +219,34 @@ func (m *RemoteDownloadTask) createDownloadTask(ctx context.Context) (Status, er
 		return StatusSuspending, nil
 	}
 
-	m.l.Info("Creating download task for URL: %s", m.state.URL)
+	var (
+		handle *downloader.TaskHandle
+		err    error
+	)
+	if m.state.File != nil {
+		creator, ok := m.d.(downloader.FileCreator)
+		if !ok {
+			return StatusError, fmt.Errorf("downloader %q cannot create tasks from files (%w)", m.state.Downloader, CriticalErr)
+		}
 
-	// Create download task
-	handle, err := m.d.CreateTask(ctx, m.state.URL, m.state.Options)
+		m.l.Info("Creating download task for file: %s", m.state.File.Name)
+		handle, err = creator.CreateTaskFromFile(ctx, m.state.File, m.state.Options)
+		if errors.Is(err, downloader.ErrUnsupportedFile) {
+			return StatusError, fmt.Errorf("failed to create download task: %w (%w)", err, CriticalErr)
+		}
+	} else {
+		m.l.Info("Creating download task for URL: %s", m.state.URL)
+		handle, err = m.d.CreateTask(ctx, m.state.URL, m.state.Options)
+	}
 	if err != nil {
 		return StatusError, fmt.Errorf("failed to create download task: %w", err)
 	}
 
+	if m.state.File != nil {
+		// The downloader keeps the file, only its name is needed from now on
+		m.state.File.Content = nil
+	}
+
 	m.state.Handle = handle
 	m.state.Phase = RemoteDownloadTaskPhaseMonitor
 
@@ -215,6 +304,26 @@ func (m *RemoteDownloadTask) monitor(ctx context.Context) (Status, error) {
 		if m.state.Phase == RemoteDownloadTaskPhaseMonitor {
 			m.state.Phase = RemoteDownloadTaskPhaseSeeding
 		}
+		now := m.Now()
+		if m.state.SeedingSince == 0 {
+			m.state.SeedingSince = now.Unix()
+		}
+
+		seedTime := now.Sub(time.Unix(m.state.SeedingSince, 0))
+		if m.seeding.Reached(status, seedTime) {
+			// Pausing keeps the files, canceling would delete them
+			if err := m.d.Pause(ctx, m.state.Handle); err != nil {
+				m.l.Warning("failed to stop seeding: %s, will retry.", err)
+				m.ResumeAfter(resumeAfter)
+				return StatusSuspending, nil
+			}
+
+			m.l.Info("Seeding goal reached after %s: %s", seedTime.Round(time.Second), status.Name)
+			status.State = downloader.StatusCompleted
+			status.UploadSpeed = 0
+			return StatusCompleted, nil
+		}
+
 		// Continue monitoring seeding
 		m.ResumeAfter(resumeAfter)
 		return StatusSuspending, nil
@@ -223,7 +332,8 @@ func (m *RemoteDownloadTask) monitor(ctx context.Context) (Status, error) {
 		m.l.Info("Download task completed: %s", status.Name)
 		return StatusCompleted, nil
 
-	case downloader.StatusDownloading:
+	case downloader.StatusDownloading, downloader.StatusPaused:
+		// Paused tasks keep being monitored until they are resumed
 		m.ResumeAfter(resumeAfter)
 		return StatusSuspending, nil
 
@@ -266,12 +376,20 @@ func (m *RemoteDownloadTask) Summarize() *Summary {
 		Phase: string(m.state.Phase),
 		Props: map[string]any{
 			SummaryKeySrcURL:         m.state.URL,
+			SummaryKeySrcFile:        fileName(m.state.File),
 			SummaryKeyDownloader:     m.state.Downloader,
 			SummaryKeyDownloadStatus: status,
 		},
 	}
 }
 
+func fileName(file *downloader.SourceFile) string {
+	if file == nil {
+		return ""
+	}
+	return file.Name
+}
+
 func (m *RemoteDownloadTask) Progress(ctx context.Context) Progresses {
 	m.Lock()
 	defer m.Unlock()
@@ -336,6 +454,7 @@ func (m *RemoteDownloadTask) CancelDownload(ctx context.Context) error {
 type DownloaderRegistry struct {
 	mu          sync.RWMutex
 	downloaders map[string]downloader.Downloader
+	seeding     *SeedingPolicy
 }
 
 // NewDownloaderRegistry creates a new downloader registry
@@ -352,6 +471,13 @@ func (r *DownloaderRegistry) Register(name string, d downloader.Downloader) {
 	r.downloaders[name] = d
 }
 
+// Unregister removes a downloader, tasks that already got it keep using it
+func (r *DownloaderRegistry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.downloaders, name)
+}
+
 // Get returns a downloader by name
 func (r *DownloaderRegistry) Get(name string) (downloader.Downloader, bool) {
 	r.mu.RLock()
@@ -360,6 +486,20 @@ func (r *DownloaderRegistry) Get(name string) (downloader.Downloader, bool) {
 	return d, ok
 }
 
+// SetSeedingPolicy sets the seeding policy of download tasks restored by the registry's factory
+func (r *DownloaderRegistry) SetSeedingPolicy(p *SeedingPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seeding = p
+}
+
+// SeedingPolicy returns the seeding policy of download tasks, nil if tasks seed until canceled
+func (r *DownloaderRegistry) SeedingPolicy() *SeedingPolicy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.seeding
+}
+
 // List returns all registered downloader names
 func (r *DownloaderRegistry) List() []string {
 	r.mu.RLock()
@@ -2,6 +2,10 @@ package queue
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
@@ -17,8 +21,84 @@ type TaskRepository interface {
 	GetByID(ctx context.Context, id uint64) (*TaskModel, error)
 	// GetPendingTasks gets all pending tasks by types
 	GetPendingTasks(ctx context.Context, types ...string) ([]*TaskModel, error)
-	// Delete deletes a task by ID
+	// Delete permanently deletes a task by ID
 	Delete(ctx context.Context, id uint64) error
+	// UpdateProgress persists the progress snapshot of a task
+	UpdateProgress(ctx context.Context, id uint64, progress Progresses, updatedAt int64) error
+	// List lists the tasks matching the filter, newest first, with the total number of matches
+	List(ctx context.Context, filter *TaskFilter) ([]*TaskModel, int64, error)
+	// Types lists the distinct types of the stored tasks
+	Types(ctx context.Context) ([]string, error)
+}
+
+// TaskFilter filters the tasks returned by TaskRepository.List
+type TaskFilter struct {
+	Type          string
+	Statuses      []Status
+	CorrelationID string
+	Keywords      string // Matched against the type, correlation ID and error
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	UpdatedBefore time.Time
+	Offset        int
+	Limit         int // Zero returns all matching tasks
+}
+
+// Match reports whether the task matches the filter, used by repositories that filter in memory
+func (f *TaskFilter) Match(task *TaskModel) bool {
+	if f.Type != "" && task.Type != f.Type {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, status := range f.Statuses {
+			if task.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.CorrelationID != "" && task.CorrelationID.String() != f.CorrelationID {
+		return false
+	}
+	if f.Keywords != "" && !strings.Contains(task.Type, f.Keywords) &&
+		!strings.Contains(task.CorrelationID.String(), f.Keywords) && !strings.Contains(task.PublicState.Error, f.Keywords) {
+		return false
+	}
+	if !f.CreatedFrom.IsZero() && task.CreatedAt.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && task.CreatedAt.After(f.CreatedTo) {
+		return false
+	}
+	if !f.UpdatedBefore.IsZero() && !task.UpdatedAt.Before(f.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+// filterTasks sorts the tasks newest first and returns the requested page of the matching ones with their total
+func filterTasks(tasks []*TaskModel, filter *TaskFilter) ([]*TaskModel, int64) {
+	matched := make([]*TaskModel, 0, len(tasks))
+	for _, task := range tasks {
+		if filter.Match(task) {
+			matched = append(matched, task)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return []*TaskModel{}, total
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total
 }
 
 // GormTaskRepository implements TaskRepository using GORM
@@ -63,7 +143,65 @@ func (r *GormTaskRepository) GetPendingTasks(ctx context.Context, types ...strin
 }
 
 func (r *GormTaskRepository) Delete(ctx context.Context, id uint64) error {
-	return r.db.WithContext(ctx).Delete(&TaskModel{}, id).Error
+	return r.db.WithContext(ctx).Unscoped().Delete(&TaskModel{}, id).Error
+}
+
+func (r *GormTaskRepository) UpdateProgress(ctx context.Context, id uint64, progress Progresses, updatedAt int64) error {
+	return r.db.WithContext(ctx).Model(&TaskModel{ID: id}).
+		Select("public_progress", "public_progress_updated_at").
+		Updates(&TaskModel{PublicState: TaskPublicState{Progress: progress, ProgressUpdatedAt: updatedAt}}).Error
+}
+
+func (r *GormTaskRepository) List(ctx context.Context, filter *TaskFilter) ([]*TaskModel, int64, error) {
+	query := r.db.WithContext(ctx).Model(&TaskModel{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.Keywords != "" {
+		v := "%" + filter.Keywords + "%"
+		query = query.Where("type LIKE ? OR correlation_id LIKE ? OR public_error LIKE ?", v, v, v)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at <= ?", filter.CreatedTo)
+	}
+	if !filter.UpdatedBefore.IsZero() {
+		query = query.Where("updated_at < ?", filter.UpdatedBefore)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	tasks := make([]*TaskModel, 0)
+	if err := query.Order("id DESC").Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (r *GormTaskRepository) Types(ctx context.Context) ([]string, error) {
+	var types []string
+	if err := r.db.WithContext(ctx).Model(&TaskModel{}).Distinct("type").Pluck("type", &types).Error; err != nil {
+		return nil, err
+	}
+	return types, nil
 }
 
 // TaskArgs represents arguments for creating or updating a task
@@ -78,6 +216,7 @@ type TaskArgs struct {
 
 // InMemoryTaskRepository implements TaskRepository using in-memory storage
 type InMemoryTaskRepository struct {
+	mu     sync.RWMutex
 	tasks  map[uint64]*TaskModel
 	nextID uint64
 }
@@ -91,6 +230,9 @@ func NewInMemoryTaskRepository() TaskRepository {
 }
 
 func (r *InMemoryTaskRepository) Create(ctx context.Context, task *TaskModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	task.ID = r.nextID
 	r.nextID++
 	r.tasks[task.ID] = task
@@ -98,11 +240,17 @@ func (r *InMemoryTaskRepository) Create(ctx context.Context, task *TaskModel) er
 }
 
 func (r *InMemoryTaskRepository) Update(ctx context.Context, task *TaskModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.tasks[task.ID] = task
 	return nil
 }
 
 func (r *InMemoryTaskRepository) GetByID(ctx context.Context, id uint64) (*TaskModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	if task, ok := r.tasks[id]; ok {
 		return task, nil
 	}
@@ -110,6 +258,9 @@ func (r *InMemoryTaskRepository) GetByID(ctx context.Context, id uint64) (*TaskM
 }
 
 func (r *InMemoryTaskRepository) GetPendingTasks(ctx context.Context, types ...string) ([]*TaskModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var result []*TaskModel
 	for _, task := range r.tasks {
 		if task.Status == StatusQueued || task.Status == StatusProcessing || task.Status == StatusSuspending {
@@ -129,6 +280,56 @@ func (r *InMemoryTaskRepository) GetPendingTasks(ctx context.Context, types ...s
 }
 
 func (r *InMemoryTaskRepository) Delete(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	delete(r.tasks, id)
 	return nil
 }
+
+// UpdateProgress replaces the stored task with a copy carrying the new progress,
+// so callers holding the previous model never observe a concurrent write
+func (r *InMemoryTaskRepository) UpdateProgress(ctx context.Context, id uint64, progress Progresses, updatedAt int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	updated := *task
+	updated.PublicState.Progress = progress
+	updated.PublicState.ProgressUpdatedAt = updatedAt
+	r.tasks[id] = &updated
+	return nil
+}
+
+func (r *InMemoryTaskRepository) List(ctx context.Context, filter *TaskFilter) ([]*TaskModel, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*TaskModel, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+
+	list, total := filterTasks(tasks, filter)
+	return list, total, nil
+}
+
+func (r *InMemoryTaskRepository) Types(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	types := make([]string, 0)
+	for _, task := range r.tasks {
+		if !seen[task.Type] {
+			seen[task.Type] = true
+			types = append(types, task.Type)
+		}
+	}
+	sort.Strings(types)
+	return types, nil
+}
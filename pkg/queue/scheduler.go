@@ -1,10 +1,12 @@
 package queue
 
 import (
+	"container/heap"
 	"errors"
 	"sync"
 	"sync/atomic"
-	"time"
+
+	"github.com/top-system/light-admin/pkg/clock"
 )
 
 var (
@@ -27,18 +29,34 @@ type (
 		Shutdown() error
 	}
 
+	// waker is implemented by schedulers that keep the resume time of queued tasks,
+	// Wake is called after the resume time of a queued task was moved
+	waker interface {
+		Wake(task Task)
+	}
+
 	fifoScheduler struct {
 		sync.Mutex
 		taskQueue taskHeap
 		capacity  int
 		count     int
+		seq       uint64
 		exit      chan struct{}
 		logger    Logger
+		clock     clock.Clock
 		stopOnce  sync.Once
 		stopFlag  int32
 	}
 
-	taskHeap []Task
+	// taskHeap is a min-heap by resume time, tasks with the same resume time
+	// keep their submission order
+	taskHeap []scheduledTask
+
+	scheduledTask struct {
+		task       Task
+		resumeTime int64
+		seq        uint64
+	}
 )
 
 // Queue send task to the buffer channel
@@ -51,33 +69,49 @@ func (s *fifoScheduler) Queue(task Task) error {
 	}
 
 	s.Lock()
-	s.taskQueue.Push(task)
+	s.seq++
+	heap.Push(&s.taskQueue, scheduledTask{task: task, resumeTime: task.ResumeTime(), seq: s.seq})
 	s.count++
 	s.Unlock()
 
 	return nil
 }
 
-// Request a new task from channel
+// Request returns the task with the earliest resume time once it is due, so a
+// delayed task does not hold back the tasks queued after it
 func (s *fifoScheduler) Request() (Task, error) {
 	if atomic.LoadInt32(&s.stopFlag) == 1 {
 		return nil, ErrQueueShutdown
 	}
 
+	s.Lock()
+	defer s.Unlock()
+
 	if s.count == 0 {
 		return nil, ErrNoTaskInQueue
 	}
-	s.Lock()
-	if s.taskQueue[s.taskQueue.Len()-1].ResumeTime() > time.Now().Unix() {
-		s.Unlock()
+	if s.taskQueue[0].resumeTime > s.clock.Now().Unix() {
 		return nil, ErrNoTaskInQueue
 	}
 
-	data := s.taskQueue.Pop()
+	data := heap.Pop(&s.taskQueue).(scheduledTask)
 	s.count--
-	s.Unlock()
 
-	return data.(Task), nil
+	return data.task, nil
+}
+
+// Wake reorders the task after its resume time was moved
+func (s *fifoScheduler) Wake(task Task) {
+	s.Lock()
+	defer s.Unlock()
+
+	for i := range s.taskQueue {
+		if s.taskQueue[i].task == task {
+			s.taskQueue[i].resumeTime = task.ResumeTime()
+			heap.Fix(&s.taskQueue, i)
+			return
+		}
+	}
 }
 
 // Shutdown the worker
@@ -91,13 +125,16 @@ func (s *fifoScheduler) Shutdown() error {
 
 // NewFifoScheduler for create new Scheduler instance
 func NewFifoScheduler(queueSize int, logger Logger) Scheduler {
-	w := &fifoScheduler{
-		taskQueue: make([]Task, 0),
+	return newFifoScheduler(queueSize, logger, clock.New())
+}
+
+func newFifoScheduler(queueSize int, logger Logger, clk clock.Clock) *fifoScheduler {
+	return &fifoScheduler{
+		taskQueue: make(taskHeap, 0),
 		capacity:  queueSize,
 		logger:    logger,
+		clock:     clk,
 	}
-
-	return w
 }
 
 // Implement heap.Interface
@@ -106,7 +143,10 @@ func (h taskHeap) Len() int {
 }
 
 func (h taskHeap) Less(i, j int) bool {
-	return h[i].ResumeTime() < h[j].ResumeTime()
+	if h[i].resumeTime != h[j].resumeTime {
+		return h[i].resumeTime < h[j].resumeTime
+	}
+	return h[i].seq < h[j].seq
 }
 
 func (h taskHeap) Swap(i, j int) {
@@ -114,7 +154,7 @@ func (h taskHeap) Swap(i, j int) {
 }
 
 func (h *taskHeap) Push(x any) {
-	*h = append(*h, x.(Task))
+	*h = append(*h, x.(scheduledTask))
 }
 
 func (h *taskHeap) Pop() any {
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/clock"
 )
 
 type (
@@ -47,6 +49,8 @@ type (
 		ResumeTime() int64
 		// ResumeAfter sets the time when the task should be resumed
 		ResumeAfter(next time.Duration)
+		// Priority returns the scheduling priority, higher runs first under the priority scheduler
+		Priority() int
 		// Progress returns the task progress
 		Progress(ctx context.Context) Progresses
 		// Summarize returns the task summary for UI display
@@ -92,6 +96,12 @@ type (
 	}
 
 	stateTransition func(ctx context.Context, task Task, newStatus Status, q *queue) error
+
+	// clockAware is implemented by tasks computing resume times, the queue hands
+	// them its clock when they are queued
+	clockAware interface {
+		SetClock(c clock.Clock)
+	}
 )
 
 var (
@@ -107,6 +117,22 @@ func init() {
 	gob.Register(Progresses{})
 }
 
+// Clone returns a deep copy of the progresses
+func (p Progresses) Clone() Progresses {
+	if p == nil {
+		return nil
+	}
+
+	result := make(Progresses, len(p))
+	for k, v := range p {
+		if v != nil {
+			c := *v
+			result[k] = &c
+		}
+	}
+	return result
+}
+
 // RegisterResumableTaskFactory registers a resumable task factory
 func RegisterResumableTaskFactory(taskType string, factory ResumableTaskFactory) {
 	taskFactories.Store(taskType, factory)
@@ -144,7 +170,8 @@ type DBTask struct {
 	DirectOwner *TaskOwner
 	TaskModel   *TaskModel
 
-	mu sync.Mutex
+	mu    sync.Mutex
+	clock clock.Clock
 }
 
 func (t *DBTask) ID() int {
@@ -320,6 +347,26 @@ func (t *DBTask) ResumeTime() int64 {
 	return 0
 }
 
+func (t *DBTask) Priority() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.TaskModel != nil {
+		return t.TaskModel.PublicState.Priority
+	}
+	return 0
+}
+
+// SetPriority sets the scheduling priority, call it before the task is queued
+func (t *DBTask) SetPriority(priority int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.TaskModel != nil {
+		t.TaskModel.PublicState.Priority = priority
+	}
+}
+
 func (t *DBTask) OnSuspend(time int64) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -354,10 +401,32 @@ func (t *DBTask) ResumeAfter(next time.Duration) {
 	defer t.mu.Unlock()
 
 	if t.TaskModel != nil {
-		t.TaskModel.PublicState.ResumeTime = time.Now().Add(next).Unix()
+		t.TaskModel.PublicState.ResumeTime = t.now().Add(next).Unix()
 	}
 }
 
+// Now returns the current time of the queue's clock, tasks use it to measure durations
+// across suspensions
+func (t *DBTask) Now() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.now()
+}
+
+func (t *DBTask) now() time.Time {
+	if t.clock != nil {
+		return t.clock.Now()
+	}
+	return time.Now()
+}
+
+// SetClock sets the time source of ResumeAfter
+func (t *DBTask) SetClock(c clock.Clock) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = c
+}
+
 var stateTransitions map[Status]map[Status]stateTransition
 
 func init() {
@@ -379,6 +448,7 @@ func init() {
 				q.metric.IncFailureTask()
 				return persistTask(ctx, task, newStatus, q)
 			},
+			StatusCanceled: cancelTask,
 		},
 		StatusProcessing: {
 			StatusQueued: persistTask,
@@ -454,10 +524,34 @@ func init() {
 				q.metric.IncFailureTask()
 				return persistTask(ctx, task, newStatus, q)
 			},
+			StatusCanceled: func(ctx context.Context, task Task, newStatus Status, q *queue) error {
+				q.metric.DecSuspendingTask()
+				return cancelTask(ctx, task, newStatus, q)
+			},
+		},
+		StatusError: {
+			// Queued again by Retry
+			StatusQueued: persistTask,
 		},
 	}
 }
 
+// cancelTask cancels a task that has not started yet (or is waiting to be resumed)
+func cancelTask(ctx context.Context, task Task, newStatus Status, q *queue) error {
+	q.logger.Info("Task %d canceled before execution, clean up...", task.ID())
+	q.metric.IncFailureTask()
+
+	if err := task.Cleanup(ctx); err != nil {
+		q.logger.Error("Task cleanup failed: %s", err.Error())
+	}
+
+	if q.registry != nil {
+		q.registry.Delete(task.ID())
+	}
+
+	return persistTask(ctx, task, newStatus, q)
+}
+
 func persistTask(ctx context.Context, task Task, newState Status, q *queue) error {
 	// Persist task into repository
 	if task.ShouldPersist() {
@@ -489,14 +583,23 @@ func saveTaskToRepository(ctx context.Context, task Task, newStatus Status, q *q
 		model = &TaskModel{}
 	}
 
+	// Keep the last snapshot unless the task reports fresh progress
+	progress, progressUpdatedAt := model.PublicState.Progress, model.PublicState.ProgressUpdatedAt
+	if p := task.Progress(ctx); len(p) > 0 {
+		progress, progressUpdatedAt = p.Clone(), q.clock.Now().Unix()
+	}
+
 	model.Status = newStatus
 	model.Type = task.Type()
 	model.PublicState = TaskPublicState{
-		RetryCount:       task.Retried(),
-		ExecutedDuration: task.Executed(),
-		ErrorHistory:     errHistory,
-		Error:            errStr,
-		ResumeTime:       task.ResumeTime(),
+		RetryCount:        task.Retried(),
+		ExecutedDuration:  task.Executed(),
+		ErrorHistory:      errHistory,
+		Error:             errStr,
+		ResumeTime:        task.ResumeTime(),
+		Priority:          task.Priority(),
+		Progress:          progress,
+		ProgressUpdatedAt: progressUpdatedAt,
 	}
 	model.PrivateState = task.State()
 	if task.Owner() != nil {
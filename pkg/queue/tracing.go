@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/top-system/light-admin/pkg/queue"
+
+// injectTraceContext records the trace context of ctx on the task model next
+// to its CorrelationID, so the run of the task joins the trace that submitted
+// it, also after a restart or on another instance. It does nothing unless a
+// propagator is installed with otel.SetTextMapPropagator
+func injectTraceContext(ctx context.Context, t Task) {
+	model := t.Model()
+	if model == nil || model.TraceContext != "" {
+		return
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return
+	}
+	if data, err := json.Marshal(carrier); err == nil && len(data) <= 512 {
+		model.TraceContext = string(data)
+	}
+}
+
+// extractTraceContext returns ctx carrying the trace context recorded on the task
+func extractTraceContext(ctx context.Context, t Task) context.Context {
+	model := t.Model()
+	if model == nil || model.TraceContext == "" {
+		return ctx
+	}
+
+	carrier := propagation.MapCarrier{}
+	if err := json.Unmarshal([]byte(model.TraceContext), &carrier); err != nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// startTaskSpan starts the span covering one run of the task
+func (q *queue) startTaskSpan(ctx context.Context, t Task) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(extractTraceContext(ctx, t), "queue.task "+t.Type(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("queue.name", q.name),
+			attribute.String("queue.task.type", t.Type()),
+			attribute.Int("queue.task.id", t.ID()),
+			attribute.String("queue.correlation_id", t.CorrelationID().String()),
+			attribute.Int("queue.task.retried", t.Retried()),
+		),
+	)
+}
+
+// endTaskSpan ends the span with the status the task reached
+func endTaskSpan(span trace.Span, t Task) {
+	span.SetAttributes(attribute.String("queue.task.status", string(t.Status())))
+	if err := t.Error(); err != nil && t.Status() == StatusError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/top-system/light-admin/pkg/webhook"
+)
+
+const (
+	// WebhookTaskType is the type of webhook delivery tasks
+	WebhookTaskType = "webhook"
+
+	// Summary keys
+	SummaryKeyWebhookDelivery = "delivery_id"
+	SummaryKeyWebhookAttempt  = "attempt"
+)
+
+type (
+	// WebhookTask delivers one webhook event. Failed attempts are retried by
+	// the task itself with exponential backoff (RetryDelay doubled per attempt,
+	// capped at MaxRetryDelay) instead of the queue-wide retry policy, so
+	// endpoints that are down for a while are not hammered
+	WebhookTask struct {
+		*DBTask
+
+		deliver WebhookDeliverer
+		state   *WebhookTaskState
+	}
+
+	// WebhookTaskState represents the internal state of a webhook task
+	WebhookTaskState struct {
+		DeliveryID    uint64        `json:"delivery_id"`
+		Attempt       int           `json:"attempt"` // attempts made so far
+		MaxAttempts   int           `json:"max_attempts"`
+		RetryDelay    time.Duration `json:"retry_delay"`
+		MaxRetryDelay time.Duration `json:"max_retry_delay"`
+	}
+
+	// WebhookDeliverer makes an attempt to deliver the delivery record, final
+	// reports whether no retry follows a failure. Errors wrapping CriticalErr
+	// (e.g. the endpoint was deleted) stop retrying
+	WebhookDeliverer func(ctx context.Context, deliveryID uint64, attempt int, final bool) error
+
+	// WebhookRetryPolicy controls the retries of a webhook task
+	WebhookRetryPolicy struct {
+		MaxAttempts   int
+		RetryDelay    time.Duration
+		MaxRetryDelay time.Duration
+	}
+)
+
+func init() {
+	RegisterResumableTaskFactory(WebhookTaskType, NewWebhookTaskFromModel)
+}
+
+// NewWebhookTask creates a task delivering the delivery record with deliver
+func NewWebhookTask(deliver WebhookDeliverer, deliveryID uint64, policy WebhookRetryPolicy, owner *TaskOwner) (Task, error) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	state := &WebhookTaskState{
+		DeliveryID:    deliveryID,
+		MaxAttempts:   policy.MaxAttempts,
+		RetryDelay:    policy.RetryDelay,
+		MaxRetryDelay: policy.MaxRetryDelay,
+	}
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	return &WebhookTask{
+		DBTask: &DBTask{
+			TaskModel: &TaskModel{
+				Type:          WebhookTaskType,
+				CorrelationID: uuid.Must(uuid.NewV4()),
+				PrivateState:  string(stateBytes),
+				PublicState:   TaskPublicState{},
+			},
+			DirectOwner: owner,
+		},
+		deliver: deliver,
+		state:   state,
+	}, nil
+}
+
+// NewWebhookTaskFromModel creates a WebhookTask from model, it fails until a
+// deliverer is set, register NewWebhookTaskFactory to restore runnable tasks
+func NewWebhookTaskFromModel(model *TaskModel) Task {
+	t := &WebhookTask{
+		DBTask: &DBTask{
+			TaskModel: model,
+		},
+	}
+	if model.OwnerID > 0 {
+		t.DirectOwner = &TaskOwner{ID: model.OwnerID}
+	}
+	return t
+}
+
+// NewWebhookTaskFactory returns a factory restoring webhook tasks with the
+// deliverer, register it to run tasks resumed from the repository or delivered by a broker
+func NewWebhookTaskFactory(deliver WebhookDeliverer) ResumableTaskFactory {
+	return func(model *TaskModel) Task {
+		t := NewWebhookTaskFromModel(model).(*WebhookTask)
+		t.deliver = deliver
+		return t
+	}
+}
+
+// Do makes the next delivery attempt
+func (m *WebhookTask) Do(ctx context.Context) (Status, error) {
+	state := m.getState()
+	if state == nil {
+		return StatusError, fmt.Errorf("failed to unmarshal state (%w)", CriticalErr)
+	}
+	if m.deliver == nil {
+		return StatusError, fmt.Errorf("webhook deliverer not set (%w)", CriticalErr)
+	}
+
+	m.Lock()
+	state.Attempt++
+	attempt := state.Attempt
+	final := attempt >= state.MaxAttempts
+	m.Unlock()
+
+	err := m.deliver(ctx, state.DeliveryID, attempt, final)
+	if saveErr := m.saveState(); saveErr != nil {
+		return StatusError, saveErr
+	}
+
+	switch {
+	case err == nil:
+		return StatusCompleted, nil
+	case errors.Is(err, CriticalErr):
+		return StatusError, err
+	case final:
+		// Retries are handled here, keep the queue from retrying once more
+		return StatusError, fmt.Errorf("webhook delivery %d failed after %d attempts: %w (%w)", state.DeliveryID, attempt, err, CriticalErr)
+	}
+
+	m.ResumeAfter(webhook.Backoff(attempt, state.RetryDelay, state.MaxRetryDelay))
+	return StatusSuspending, nil
+}
+
+func (m *WebhookTask) getState() *WebhookTaskState {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.state == nil {
+		state := &WebhookTaskState{}
+		if err := json.Unmarshal([]byte(m.TaskModel.PrivateState), state); err != nil {
+			return nil
+		}
+		m.state = state
+	}
+	return m.state
+}
+
+func (m *WebhookTask) saveState() error {
+	m.Lock()
+	defer m.Unlock()
+
+	stateBytes, err := json.Marshal(m.state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	m.TaskModel.PrivateState = string(stateBytes)
+	return nil
+}
+
+func (m *WebhookTask) Summarize() *Summary {
+	state := m.getState()
+	if state == nil {
+		return nil
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	return &Summary{Props: map[string]any{
+		SummaryKeyWebhookDelivery: state.DeliveryID,
+		SummaryKeyWebhookAttempt:  state.Attempt,
+	}}
+}
@@ -1,6 +1,8 @@
 package random
 
 import (
+	crand "crypto/rand"
+	"math/big"
 	"math/rand"
 	"strings"
 	"time"
@@ -46,3 +48,21 @@ func (*Random) String(length uint8, charsets ...string) string {
 func String(length uint8, charsets ...string) string {
 	return global.String(length, charsets...)
 }
+
+// SecureString 使用 crypto/rand 生成随机字符串，用于密码、令牌等安全场景
+func SecureString(length uint8, charsets ...string) (string, error) {
+	charset := strings.Join(charsets, "")
+	if charset == "" {
+		charset = Alphanumeric
+	}
+	max := big.NewInt(int64(len(charset)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = charset[n.Int64()]
+	}
+	return string(b), nil
+}
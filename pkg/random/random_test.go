@@ -12,3 +12,10 @@ func Test(t *testing.T) {
 	r := New()
 	assert.Regexp(t, regexp.MustCompile("[0-9]+$"), r.String(8, Numeric))
 }
+
+func TestSecureString(t *testing.T) {
+	s, err := SecureString(16, Numeric)
+	assert.NoError(t, err)
+	assert.Len(t, s, 16)
+	assert.Regexp(t, regexp.MustCompile("^[0-9]+$"), s)
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvBOM UTF-8 BOM，Excel 据此识别编码，否则中文会乱码
+const csvBOM = "\ufeff"
+
+// WriteCSV 输出 CSV，只有一个章节时不输出章节标题；多个章节之间以空行分隔，每段首行为章节标题
+func (r *Report) WriteCSV(w io.Writer) error {
+	if _, err := io.WriteString(w, csvBOM); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	for i, section := range r.Sections {
+		if len(r.Sections) > 1 {
+			if i > 0 {
+				if err := cw.Write([]string{}); err != nil {
+					return err
+				}
+			}
+			if err := cw.Write([]string{section.Title}); err != nil {
+				return err
+			}
+		}
+
+		if err := cw.Write(section.Columns); err != nil {
+			return err
+		}
+		for _, row := range section.Rows {
+			if err := cw.Write(csvEscapeFormula(row)); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvEscapeFormula 以 = + - @ 开头的单元格在 Excel 中会作为公式执行，前面加单引号按文本处理，数字（如负数）除外
+func csvEscapeFormula(row []string) []string {
+	var escaped []string
+	for i, v := range row {
+		if v == "" || !isFormulaPrefix(v[0]) {
+			continue
+		}
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			continue
+		}
+		if escaped == nil {
+			escaped = append([]string(nil), row...)
+		}
+		escaped[i] = "'" + v
+	}
+	if escaped == nil {
+		return row
+	}
+	return escaped
+}
+
+func isFormulaPrefix(c byte) bool {
+	return c == '=' || c == '+' || c == '-' || c == '@' || c == '\t' || c == '\r'
+}
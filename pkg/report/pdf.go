@@ -0,0 +1,245 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// A4 纵向页面（单位 pt）
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 40.0
+
+	pdfTitleSize   = 16.0
+	pdfHeadingSize = 12.0
+	pdfTextSize    = 8.0
+	pdfLineHeight  = 1.5
+	pdfCellPadding = 3.0
+)
+
+// PDF 使用阅读器内置的 STSong-Light（Adobe-GB1）CJK 字体，无需嵌入字体文件即可显示中文；
+// 编码为 UniGB-UCS2-H，文本以 UCS-2 大端十六进制写入，ASCII 字符按半角宽度排版
+const pdfFontObjects = `<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [%d 0 R] >>
+<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor %d 0 R /DW 1000 /W [1 95 500] >>
+<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>`
+
+// WritePDF 输出 PDF，章节依次排版，表格按内容比例分配列宽，超出列宽的内容截断
+func (r *Report) WritePDF(w io.Writer) error {
+	layout := &pdfLayout{}
+	layout.newPage()
+
+	if r.Title != "" {
+		layout.text(pdfMargin, r.Title, pdfTitleSize)
+		layout.advance(pdfTitleSize * pdfLineHeight)
+	}
+	if r.Subtitle != "" {
+		layout.text(pdfMargin, r.Subtitle, pdfTextSize+1)
+		layout.advance((pdfTextSize + 1) * pdfLineHeight)
+	}
+
+	for _, section := range r.Sections {
+		layout.section(section)
+	}
+
+	return layout.write(w)
+}
+
+type pdfLayout struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func (l *pdfLayout) page() *bytes.Buffer {
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &bytes.Buffer{})
+	l.y = pdfPageHeight - pdfMargin
+}
+
+// ensure 剩余空间不足 height 时换页，返回是否换页
+func (l *pdfLayout) ensure(height float64) bool {
+	if l.y-height < pdfMargin {
+		l.newPage()
+		return true
+	}
+	return false
+}
+
+func (l *pdfLayout) advance(height float64) {
+	l.y -= height
+}
+
+// text 在当前行写入文本（y 为行顶部）
+func (l *pdfLayout) text(x float64, s string, size float64) {
+	l.ensure(size * pdfLineHeight)
+	fmt.Fprintf(l.page(), "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, l.y-size, pdfEncode(s))
+}
+
+func (l *pdfLayout) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(l.page(), "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+func (l *pdfLayout) section(section *Section) {
+	l.advance(pdfHeadingSize * 0.5)
+	l.ensure(pdfHeadingSize*pdfLineHeight + 2*pdfTextSize*pdfLineHeight)
+	l.text(pdfMargin, section.Title, pdfHeadingSize)
+	l.advance(pdfHeadingSize * pdfLineHeight)
+
+	if len(section.Columns) == 0 {
+		return
+	}
+
+	widths := pdfColumnWidths(section, pdfPageWidth-2*pdfMargin)
+	rowHeight := pdfTextSize*pdfLineHeight + pdfCellPadding
+
+	header := func() {
+		l.row(section.Columns, widths, rowHeight)
+		l.line(pdfMargin, l.y, pdfPageWidth-pdfMargin, l.y)
+	}
+
+	header()
+	if len(section.Rows) == 0 {
+		l.row([]string{"-"}, widths, rowHeight)
+	}
+	for _, row := range section.Rows {
+		if l.ensure(rowHeight) {
+			header()
+		}
+		l.row(row, widths, rowHeight)
+	}
+}
+
+func (l *pdfLayout) row(values []string, widths []float64, height float64) {
+	l.ensure(height)
+	x := pdfMargin
+	for i, width := range widths {
+		if i < len(values) && values[i] != "" {
+			value := pdfTruncate(values[i], width-pdfCellPadding, pdfTextSize)
+			fmt.Fprintf(l.page(), "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n",
+				pdfTextSize, x, l.y-pdfTextSize-pdfCellPadding/2, pdfEncode(value))
+		}
+		x += width
+	}
+	l.advance(height)
+}
+
+// write 输出 PDF 文件结构：目录、页树、字体、各页及其内容流、交叉引用表
+func (l *pdfLayout) write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 目录，2 页树，3-5 字体，之后每页占两个对象（页、内容流）
+	const firstPage = 6
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	for _, font := range strings.Split(fmt.Sprintf(pdfFontObjects, 4, 5), "\n") {
+		object(font)
+	}
+
+	for i, content := range l.pages {
+		footer := fmt.Sprintf("BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n",
+			pdfTextSize, pdfPageWidth-pdfMargin-40, pdfMargin/2, pdfEncode(fmt.Sprintf("%d / %d", i+1, len(l.pages))))
+		stream := "0.5 w\n" + content.String() + footer
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(stream), stream))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pdfColumnWidths 按各列最长内容（上限 40 个半角字符）比例分配可用宽度
+func pdfColumnWidths(section *Section, available float64) []float64 {
+	weights := make([]float64, len(section.Columns))
+	measure := func(i int, value string) {
+		if i < len(weights) {
+			weights[i] = max(weights[i], min(pdfTextWidth(value, 1), 20))
+		}
+	}
+
+	for i, column := range section.Columns {
+		measure(i, column)
+	}
+	for _, row := range section.Rows {
+		for i, value := range row {
+			measure(i, value)
+		}
+	}
+
+	total := 0.0
+	for i := range weights {
+		weights[i] = max(weights[i], 2)
+		total += weights[i]
+	}
+
+	widths := make([]float64, len(weights))
+	for i, weight := range weights {
+		widths[i] = available * weight / total
+	}
+	return widths
+}
+
+// pdfTextWidth 估算文本宽度，半角字符 0.5em，其他 1em
+func pdfTextWidth(s string, size float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		if r < 0x80 {
+			width += 0.5
+		} else {
+			width += 1
+		}
+	}
+	return width * size
+}
+
+func pdfTruncate(s string, width, size float64) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if pdfTextWidth(s, size) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && pdfTextWidth(string(runes)+"..", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ".."
+}
+
+// pdfEncode 文本转为 UCS-2 大端十六进制，BMP 以外的字符替换为 ?
+func pdfEncode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF || utf16.IsSurrogate(r) || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 报表格式
+const (
+	FormatXLSX = "xlsx"
+	FormatPDF  = "pdf"
+	FormatCSV  = "csv"
+)
+
+// contentTypes 各格式的 MIME 类型
+var contentTypes = map[string]string{
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+	FormatCSV:  "text/csv; charset=utf-8",
+}
+
+// Report 表格型报表，每个 Section 对应 XLSX 的一个工作表、PDF 中的一个章节或 CSV 中以空行分隔的一段
+type Report struct {
+	Title    string
+	Subtitle string
+	Sections []*Section
+}
+
+// Section 报表章节
+type Section struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+// AddSection 追加章节
+func (r *Report) AddSection(title string, columns ...string) *Section {
+	section := &Section{Title: title, Columns: columns}
+	r.Sections = append(r.Sections, section)
+	return section
+}
+
+// AddRow 追加一行，不足列数时补空
+func (s *Section) AddRow(values ...string) {
+	row := make([]string, max(len(s.Columns), len(values)))
+	copy(row, values)
+	s.Rows = append(s.Rows, row)
+}
+
+// IsFormatSupported 是否支持该格式
+func IsFormatSupported(format string) bool {
+	_, ok := contentTypes[strings.ToLower(format)]
+	return ok
+}
+
+// ContentType 格式对应的 MIME 类型
+func ContentType(format string) string {
+	return contentTypes[strings.ToLower(format)]
+}
+
+// Write 按格式输出报表
+func (r *Report) Write(w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case FormatXLSX:
+		return r.WriteXLSX(w)
+	case FormatPDF:
+		return r.WritePDF(w)
+	case FormatCSV:
+		return r.WriteCSV(w)
+	}
+	return fmt.Errorf("unsupported report format: %s", format)
+}
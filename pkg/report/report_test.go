@@ -0,0 +1,99 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *Report {
+	r := &Report{Title: "安全合规报告", Subtitle: "generated for test"}
+
+	roles := r.AddSection("角色与权限", "编码", "名称", "权限")
+	roles.AddRow("ROOT", "超级管理员", "sys:user:add,sys:user:edit")
+	roles.AddRow("GUEST", "访客 <&>")
+
+	users := r.AddSection("Users: admin/stale", "username")
+	for i := 0; i < 200; i++ {
+		users.AddRow("user")
+	}
+
+	r.AddSection("角色与权限")
+	return r
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testReport().Write(&buf, "XLSX"))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	assert.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files, "xl/worksheets/sheet3.xml")
+
+	workbook := files["xl/workbook.xml"]
+	assert.Contains(t, workbook, `name="角色与权限"`)
+	assert.Contains(t, workbook, `name="Users  admin stale"`)
+	assert.Contains(t, workbook, `name="角色与权限 (2)"`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="C2" t="inlineStr"><is><t xml:space="preserve">sys:user:add,sys:user:edit</t></is></c>`)
+	assert.Contains(t, sheet, "访客 &lt;&amp;&gt;")
+	assert.Contains(t, files["xl/worksheets/sheet2.xml"], `<row r="201">`)
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, testReport().Write(&buf, FormatPDF))
+
+	pdf := buf.String()
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.Contains(t, pdf, "/BaseFont /STSong-Light")
+	// 200 行需要分页
+	assert.NotContains(t, pdf, "/Count 1 ")
+	// "ROOT" 的 UCS-2 编码
+	assert.Contains(t, pdf, "<0052004F004F0054>")
+
+	assert.Error(t, testReport().Write(&buf, "doc"))
+	assert.True(t, IsFormatSupported("PDF"))
+	assert.False(t, IsFormatSupported("doc"))
+}
+
+func TestWriteCSV(t *testing.T) {
+	r := &Report{}
+	users := r.AddSection("用户", "用户名", "备注")
+	users.AddRow("admin", "含,逗号")
+	users.AddRow("=cmd|' /C calc'!A0", "-1")
+	users.AddRow("@SUM(A1)", "-")
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Write(&buf, "CSV"))
+	assert.Equal(t, "\ufeff用户名,备注\nadmin,\"含,逗号\"\n'=cmd|' /C calc'!A0,-1\n'@SUM(A1),'-\n", buf.String())
+	assert.Equal(t, "=cmd|' /C calc'!A0", users.Rows[1][0])
+
+	buf.Reset()
+	assert.NoError(t, testReport().Write(&buf, FormatCSV))
+	assert.Contains(t, buf.String(), "\n\nUsers: admin/stale\nusername\nuser\n")
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumnName(0))
+	assert.Equal(t, "Z", xlsxColumnName(25))
+	assert.Equal(t, "AA", xlsxColumnName(26))
+	assert.Equal(t, "BA", xlsxColumnName(52))
+}
@@ -0,0 +1,29 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBox(t *testing.T) {
+	box, err := NewBox("change-me")
+	assert.NoError(t, err)
+
+	a, err := box.Encrypt("JBSWY3DPEHPK3PXP")
+	assert.NoError(t, err)
+	b, err := box.Encrypt("JBSWY3DPEHPK3PXP")
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, b, "nonce should be random")
+
+	plain, err := box.Decrypt(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plain)
+
+	other, _ := NewBox("another-key")
+	_, err = other.Decrypt(a)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = box.Decrypt("not base64!")
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
@@ -3,6 +3,7 @@ package str
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -148,6 +149,27 @@ func (a S) DefaultFloat32(defaultVal float32) float32 {
 	return f
 }
 
+// Uint64s 按分隔符拆分并转换为[]uint64，忽略空白和无法解析的项
+func (a S) Uint64s(sep string) []uint64 {
+	parts := strings.Split(a.String(), sep)
+	list := make([]uint64, 0, len(parts))
+	for _, p := range parts {
+		if v, err := strconv.ParseUint(strings.TrimSpace(p), 10, 64); err == nil {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Truncate 截断到 n 个字符
+func (a S) Truncate(n int) string {
+	runes := []rune(a.String())
+	if len(runes) <= n {
+		return a.String()
+	}
+	return string(runes[:n])
+}
+
 // ToJSON 转换为JSON
 func (a S) ToJSON(v interface{}) error {
 	return json.Unmarshal(a.Bytes(), v)
@@ -28,4 +28,10 @@ func TestS(t *testing.T) {
 	byt := []byte("test")
 	s := NewWithByte(byt).String()
 	assert.EqualValues(t, "test", s)
+
+	assert.Equal(t, []uint64{1, 2, 30}, S("1, 2,x,,30").Uint64s(","))
+	assert.Empty(t, S("").Uint64s(","))
+
+	assert.Equal(t, "操作日", S("操作日志").Truncate(3))
+	assert.Equal(t, "log", S("log").Truncate(3))
 }
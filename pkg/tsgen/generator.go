@@ -0,0 +1,213 @@
+package tsgen
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/top-system/light-admin/pkg/apimeta"
+	"github.com/top-system/light-admin/pkg/echox"
+)
+
+// Route 需要生成客户端方法的路由
+type Route struct {
+	Method  string
+	Path    string
+	Handler string      // echo 路由名称（处理函数全名），用于生成分组与方法名
+	Title   string      // 操作标题
+	Perm    string      // 所需权限
+	Params  interface{} // 查询参数或请求体结构示例（路由 Describe 声明）
+}
+
+// Operation 客户端方法描述
+type Operation struct {
+	Group      string   `json:"group"`
+	Name       string   `json:"name"`
+	Title      string   `json:"title,omitempty"`
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Perm       string   `json:"perm,omitempty"`
+	PathParams []string `json:"pathParams,omitempty"`
+	Query      *Schema  `json:"query,omitempty"`
+	Body       *Schema  `json:"body,omitempty"`
+	Multipart  bool     `json:"multipart,omitempty"` // 请求体为 multipart/form-data
+	Response   *Schema  `json:"response,omitempty"`  // echox.Response 中 data 的类型
+	Binary     bool     `json:"binary,omitempty"`    // 响应为文件流
+}
+
+// Document 可供其他工具消费的完整描述
+type Document struct {
+	Definitions map[string]*Schema `json:"definitions"`
+	Operations  []*Operation       `json:"operations"`
+}
+
+// Generator 客户端类型生成器
+// 类型来源有两处：路由通过 Describe 声明的参数结构（反射 Go 结构体及其 json 标签），
+// 以及 swag 生成的文档（接口返回类型、未声明的参数）。两者同名时以 Go 结构体为准
+type Generator struct {
+	definitions map[string]*Schema
+	reflected   map[reflect.Type]string
+	overrides   map[reflect.Type]*Schema
+	swagger     *swaggerDoc
+	operations  []*Operation
+	names       map[string]int
+}
+
+// New 创建生成器
+func New() *Generator {
+	g := &Generator{
+		definitions: make(map[string]*Schema),
+		reflected:   make(map[reflect.Type]string),
+		overrides:   make(map[reflect.Type]*Schema),
+		names:       make(map[string]int),
+	}
+	g.AddType(echox.PageInfo{})
+	return g
+}
+
+// Override 指定类型的 Schema，用于自定义 MarshalJSON 改变了输出形式的类型
+func (g *Generator) Override(v interface{}, schema *Schema) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	g.overrides[t] = schema
+}
+
+// Define 直接指定定义，用于只在 swagger 文档中出现、需要修正的定义（如日期包装类型）
+func (g *Generator) Define(name string, schema *Schema) {
+	g.definitions[name] = schema
+}
+
+// AddType 注册类型（及其引用的结构体），返回对应的 Schema
+func (g *Generator) AddType(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// AddRoute 添加路由对应的客户端方法
+func (g *Generator) AddRoute(route Route) {
+	controller, action := apimeta.ParseHandlerName(route.Handler)
+	op := &Operation{
+		Group:  groupName(controller, route.Path),
+		Name:   lowerFirst(identifier(action)),
+		Title:  route.Title,
+		Method: route.Method,
+		Path:   route.Path,
+		Perm:   route.Perm,
+	}
+	if op.Name == "" {
+		op.Name = strings.ToLower(route.Method)
+	}
+
+	// 同一分组内方法名重复时追加序号
+	key := op.Group + "." + op.Name
+	g.names[key]++
+	if n := g.names[key]; n > 1 {
+		op.Name = fmt.Sprintf("%s%d", op.Name, n)
+	}
+
+	for _, p := range apimeta.PathParams(route.Path) {
+		op.PathParams = append(op.PathParams, p.Name)
+	}
+
+	if sop := g.swaggerOperationOf(route.Method, route.Path); sop != nil {
+		if op.Title == "" {
+			op.Title = sop.Summary
+		}
+		op.Query, op.Body, op.Multipart = sop.params()
+		op.Response = sop.responseData()
+		op.Binary = sop.binary()
+	}
+
+	if route.Params != nil {
+		switch route.Method {
+		case http.MethodGet, http.MethodDelete, http.MethodHead:
+			op.Query = g.querySchemaOf(reflect.TypeOf(route.Params))
+		default:
+			op.Body = g.AddType(route.Params)
+		}
+	}
+
+	g.operations = append(g.operations, op)
+}
+
+// Document 返回全部定义与客户端方法
+func (g *Generator) Document() *Document {
+	return &Document{Definitions: g.allDefinitions(), Operations: g.operations}
+}
+
+// allDefinitions 合并文档与反射生成的定义，反射结果覆盖文档中的同名定义
+func (g *Generator) allDefinitions() map[string]*Schema {
+	defs := make(map[string]*Schema, len(g.definitions))
+	if g.swagger != nil {
+		for name, schema := range g.swagger.Definitions {
+			defs[name] = schema
+		}
+	}
+	for name, schema := range g.definitions {
+		defs[name] = schema
+	}
+	return defs
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// groupName 分组名取控制器名（去掉 Controller 后缀），没有控制器时取路径最后一个静态段
+func groupName(controller, path string) string {
+	if name := strings.TrimSuffix(controller, "Controller"); name != "" {
+		return lowerFirst(identifier(name))
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if s := segments[i]; s != "" && !strings.HasPrefix(s, ":") && s != "*" {
+			return lowerFirst(identifier(s))
+		}
+	}
+	return "root"
+}
+
+// identifier 将名称转换为驼峰标识符，去掉非字母数字字符
+func identifier(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9' && b.Len() > 0:
+			if upper && r >= 'a' && r <= 'z' {
+				r -= 'a' - 'A'
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = b.Len() > 0
+		}
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" || s[0] < 'A' || s[0] > 'Z' {
+		return s
+	}
+	return string(s[0]+'a'-'A') + s[1:]
+}
+
+func upperFirst(s string) string {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
@@ -0,0 +1,211 @@
+package tsgen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const definitionPrefix = "#/definitions/"
+
+// Schema JSON Schema 描述，取 Swagger 2.0 definitions 所用的子集
+// Type 与 Ref 均为空表示任意 JSON 值
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Description          string             `json:"description,omitempty"`
+}
+
+// RefTo 返回引用指定定义的 Schema
+func RefTo(name string) *Schema {
+	return &Schema{Ref: definitionPrefix + name}
+}
+
+// RefName 返回引用的定义名称，非引用时返回空
+func (s *Schema) RefName() string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, definitionPrefix)
+}
+
+func (s *Schema) isRequired(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// definitionName 结构体的定义名称，与 swag 生成的名称一致，如 system.NoticeForm
+func definitionName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+
+	// 泛型实例化的类型名带有类型参数，去掉特殊字符
+	name := strings.NewReplacer("[", "_", "]", "", "*", "", ",", "_", " ", "").Replace(t.Name())
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+// schemaOf 通过反射生成类型的 Schema，具名结构体注册为定义并返回引用
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if s, ok := g.overrides[t]; ok {
+		return s
+	}
+	if t.ConvertibleTo(timeType) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		// dto.NullDateTime、sql.NullTime 等包装类型
+		if f, ok := t.FieldByName("Time"); ok && f.Type == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return g.structSchema(t, "json")
+		}
+
+		name := definitionName(t)
+		if _, ok := g.reflected[t]; !ok {
+			g.reflected[t] = name
+			// 先占位，避免自引用类型（如菜单树）无限递归
+			g.definitions[name] = &Schema{Type: "object"}
+			g.definitions[name] = g.structSchema(t, "json")
+		}
+		return RefTo(name)
+	case reflect.Array:
+		// uuid 等定长字节数组按字符串输出
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Slice:
+		// json.RawMessage 及基于它的类型为任意 JSON
+		if t.ConvertibleTo(rawMessageType) && t.Implements(marshalerType) {
+			return &Schema{}
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{}
+	}
+}
+
+// querySchemaOf 查询参数结构按 query 标签生成，同样注册为定义
+func (g *Generator) querySchemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return g.schemaOf(t)
+	}
+
+	name := definitionName(t)
+	if _, ok := g.reflected[t]; !ok {
+		g.reflected[t] = name
+		g.definitions[name] = g.structSchema(t, "query")
+	}
+	return RefTo(name)
+}
+
+// structSchema 按 json（或 query）标签展开结构体字段
+// 匿名嵌入的结构体字段提升到外层；带 omitempty 或指针类型的字段为可选，查询参数均为可选
+func (g *Generator) structSchema(t reflect.Type, tagKey string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.collectFields(t, tagKey, schema)
+	return schema
+}
+
+func (g *Generator) collectFields(t reflect.Type, tagKey string, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			g.collectFields(ft, tagKey, schema)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			// 查询参数只绑定声明了标签的字段
+			if tagKey == "query" {
+				continue
+			}
+			name = field.Name
+		}
+
+		optional := field.Type.Kind() == reflect.Ptr || tagKey == "query"
+		asString := false
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "omitempty":
+				optional = true
+			case "string":
+				asString = true
+			}
+		}
+
+		prop := g.schemaOf(field.Type)
+		if asString {
+			prop = &Schema{Type: "string"}
+		}
+
+		schema.Properties[name] = prop
+		if !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tsgen
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// swaggerDoc swag 生成的 Swagger 2.0 文档中用到的部分
+type swaggerDoc struct {
+	Definitions map[string]*Schema                      `json:"definitions"`
+	Paths       map[string]map[string]*swaggerOperation `json:"paths"`
+}
+
+type swaggerOperation struct {
+	Summary    string                      `json:"summary"`
+	Produces   []string                    `json:"produces"`
+	Parameters []*swaggerParameter         `json:"parameters"`
+	Responses  map[string]*swaggerResponse `json:"responses"`
+}
+
+type swaggerParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Type        string  `json:"type"`
+	Format      string  `json:"format"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description"`
+	Items       *Schema `json:"items"`
+	Schema      *Schema `json:"schema"`
+}
+
+type swaggerResponse struct {
+	Schema *Schema `json:"schema"`
+}
+
+// LoadSwagger 加载 swag 生成的文档，补充路由的返回类型及未通过 Describe 声明的参数
+// 文档中的定义只在反射未生成同名定义时使用，Go 结构体始终优先
+func (g *Generator) LoadSwagger(doc []byte) error {
+	parsed := new(swaggerDoc)
+	if err := json.Unmarshal(doc, parsed); err != nil {
+		return err
+	}
+
+	g.swagger = parsed
+	return nil
+}
+
+// swaggerOperationOf 按 echo 路由查找文档中的接口，/users/:id 对应 /users/{id}
+func (g *Generator) swaggerOperationOf(method, path string) *swaggerOperation {
+	if g.swagger == nil {
+		return nil
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return g.swagger.Paths[strings.Join(segments, "/")][strings.ToLower(method)]
+}
+
+// responseData 从 200 响应中取出 echox.Response 的 data 类型
+// 响应声明为 echox.Response{data=X} 时 swag 生成 allOf，仅声明 echox.Response 时没有 data
+func (op *swaggerOperation) responseData() *Schema {
+	resp := op.Responses["200"]
+	if resp == nil || resp.Schema == nil {
+		return nil
+	}
+
+	schema := resp.Schema
+	for _, item := range schema.AllOf {
+		if data, ok := item.Properties["data"]; ok {
+			return data
+		}
+	}
+	if schema.RefName() == "echox.Response" {
+		return nil
+	}
+	return schema
+}
+
+// binary 响应是否为文件流
+func (op *swaggerOperation) binary() bool {
+	if resp := op.Responses["200"]; resp != nil && resp.Schema != nil && resp.Schema.Type == "file" {
+		return true
+	}
+	for _, p := range op.Produces {
+		if p != "application/json" {
+			return true
+		}
+	}
+	return false
+}
+
+// params 按位置收集参数，query 参数合并为一个对象
+func (op *swaggerOperation) params() (query, body *Schema, multipart bool) {
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "query":
+			if query == nil {
+				query = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			}
+			query.Properties[p.Name] = &Schema{Type: p.Type, Format: p.Format, Items: p.Items, Description: p.Description}
+			if p.Required {
+				query.Required = append(query.Required, p.Name)
+			}
+		case "body":
+			body = p.Schema
+		case "formData":
+			multipart = true
+		}
+	}
+	return query, body, multipart
+}
@@ -0,0 +1,191 @@
+package tsgen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+type node struct {
+	ID       uint64  `json:"id"`
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type base struct {
+	CreateTime time.Time `json:"createTime"`
+}
+
+type article struct {
+	base
+
+	ID        uint64          `json:"id"`
+	Title     string          `json:"title"`
+	Tags      []string        `json:"tags"`
+	Extra     map[string]int  `json:"extra,omitempty"`
+	Publish   nullTime        `json:"publishTime"`
+	Parent    *node           `json:"parent"`
+	Raw       json.RawMessage `json:"raw"`
+	Count     int64           `json:"count,string"`
+	Hidden    string          `json:"-"`
+	Anonymous struct{ A int } `json:"anonymous"`
+	internal  string
+}
+
+type articleQuery struct {
+	Title string `query:"title" json:"title,omitempty"`
+}
+
+func TestAddType(t *testing.T) {
+	g := New()
+	ref := g.AddType(article{})
+	assert.Equal(t, "tsgen.article", ref.RefName())
+
+	schema := g.definitions["tsgen.article"]
+	require.NotNil(t, schema)
+
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["createTime"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["publishTime"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, schema.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "integer"}}, schema.Properties["extra"])
+	assert.Equal(t, &Schema{}, schema.Properties["raw"])
+	assert.Equal(t, &Schema{Type: "string"}, schema.Properties["count"])
+	assert.Equal(t, "tsgen.node", schema.Properties["parent"].RefName())
+	assert.NotContains(t, schema.Properties, "Hidden")
+	assert.NotContains(t, schema.Properties, "internal")
+	assert.Equal(t, "object", schema.Properties["anonymous"].Type)
+
+	assert.True(t, schema.isRequired("title"))
+	assert.False(t, schema.isRequired("extra"))
+	assert.False(t, schema.isRequired("parent"))
+
+	// 自引用类型
+	tree := g.definitions["tsgen.node"]
+	require.NotNil(t, tree)
+	assert.Equal(t, "tsgen.node", tree.Properties["children"].Items.RefName())
+}
+
+const swaggerDocument = `{
+  "paths": {
+    "/api/v1/articles/{id}": {
+      "get": {
+        "summary": "Get article",
+        "produces": ["application/json"],
+        "responses": {"200": {"schema": {"allOf": [
+          {"$ref": "#/definitions/echox.Response"},
+          {"type": "object", "properties": {"data": {"$ref": "#/definitions/tsgen.article"}}}
+        ]}}}
+      }
+    },
+    "/api/v1/articles/{id}/file": {
+      "get": {
+        "produces": ["application/octet-stream"],
+        "parameters": [{"name": "inline", "in": "query", "type": "boolean"}],
+        "responses": {"200": {"schema": {"type": "file"}}}
+      }
+    },
+    "/api/v1/articles/upload": {
+      "post": {
+        "parameters": [{"name": "file", "in": "formData", "type": "file", "required": true}],
+        "responses": {"200": {"schema": {"$ref": "#/definitions/echox.Response"}}}
+      }
+    }
+  },
+  "definitions": {
+    "tsgen.article": {"type": "object", "properties": {"stale": {"type": "string"}}},
+    "other.Summary": {"type": "object", "required": ["total"], "properties": {"total": {"type": "integer"}}}
+  }
+}`
+
+func TestTypeScript(t *testing.T) {
+	g := New()
+	require.NoError(t, g.LoadSwagger([]byte(swaggerDocument)))
+
+	g.AddRoute(Route{Method: "GET", Path: "/api/v1/articles", Handler: "x/controller.ArticleController.Query-fm", Params: articleQuery{}, Perm: "sys:article:query"})
+	g.AddRoute(Route{Method: "GET", Path: "/api/v1/articles/:id", Handler: "x/controller.ArticleController.Get-fm"})
+	g.AddRoute(Route{Method: "POST", Path: "/api/v1/articles", Handler: "x/controller.ArticleController.Create-fm", Title: "Create article", Params: article{}})
+	g.AddRoute(Route{Method: "GET", Path: "/api/v1/articles/:id/file", Handler: "x/controller.ArticleController.Download-fm"})
+	g.AddRoute(Route{Method: "POST", Path: "/api/v1/articles/upload", Handler: "x/controller.ArticleController.Upload-fm"})
+	g.AddRoute(Route{Method: "DELETE", Path: "/api/v1/articles/:delete", Handler: "x/controller.ArticleController.Get-fm"})
+
+	doc := g.Document()
+	assert.Contains(t, doc.Definitions, "other.Summary")
+	// Go 结构体覆盖文档中的同名定义
+	assert.Contains(t, doc.Definitions["tsgen.article"].Properties, "title")
+	assert.NotContains(t, doc.Definitions["tsgen.article"].Properties, "stale")
+	require.Len(t, doc.Operations, 6)
+	assert.Equal(t, "article", doc.Operations[0].Group)
+	assert.Equal(t, "get2", doc.Operations[5].Name)
+
+	ts := string(g.TypeScript())
+	for _, want := range []string{
+		"export interface Article {\n",
+		"  createTime: string;\n",
+		"  extra?: Record<string, number>;\n",
+		"  parent?: Node;\n",
+		"export interface Summary {\n  total: number;\n}\n",
+		"export interface PageInfo {\n",
+		"    article: {\n",
+		"/** GET /api/v1/articles · sys:article:query */",
+		`query: (query?: ArticleQuery) =>`,
+		"request<ApiResponse<Article>>({ method: \"GET\", url: `/api/v1/articles/${encodeURIComponent(String(id))}` })",
+		"/** Create article · POST /api/v1/articles */",
+		"create: (body: Article) =>",
+		"download: (id: string | number, query?: {\n        inline?: boolean;\n      }) =>",
+		`request<Blob>({ method: "GET", url: ` + "`/api/v1/articles/${encodeURIComponent(String(id))}/file`" + `, query, responseType: "blob" })`,
+		"upload: (body: FormData) =>",
+		"get2: (deleteParam: string | number) =>",
+		"export type ApiClient = ReturnType<typeof createClient>;",
+	} {
+		assert.True(t, strings.Contains(ts, want), "missing %q in:\n%s", want, ts)
+	}
+}
+
+func TestTypeNames(t *testing.T) {
+	names := typeNames(map[string]*Schema{
+		"system.User":    {},
+		"platform.User":  {},
+		"echox.Response": {},
+		"dto.RouteVO":    {},
+	})
+
+	assert.Equal(t, map[string]string{
+		"system.User":    "SystemUser",
+		"platform.User":  "PlatformUser",
+		"echox.Response": "EchoxResponse",
+		"dto.RouteVO":    "RouteVO",
+	}, names)
+}
+
+type paging struct {
+	PageNum  int `query:"pageNum"`
+	PageSize int `query:"pageSize"`
+}
+
+type listQuery struct {
+	paging
+
+	Keywords string   `query:"keywords"`
+	IDs      []uint64 `query:"-"`
+	Untagged string
+}
+
+func TestQuerySchema(t *testing.T) {
+	g := New()
+	g.AddRoute(Route{Method: "GET", Path: "/api/v1/items", Params: listQuery{}})
+
+	schema := g.definitions["tsgen.listQuery"]
+	require.NotNil(t, schema)
+	assert.Equal(t, []string{"keywords", "pageNum", "pageSize"}, sortedKeys(schema.Properties))
+	assert.Empty(t, schema.Required)
+	assert.Equal(t, "items", g.operations[0].Group)
+}
@@ -0,0 +1,276 @@
+package tsgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const tsPrelude = `/* eslint-disable */
+// Code generated by light-admin from Go structs and swagger annotations. DO NOT EDIT.
+
+export interface ApiResponse<T = unknown> {
+  code: string;
+  message: string;
+  data: T;
+  page?: PageInfo;
+}
+
+export interface RequestOptions {
+  method: "GET" | "POST" | "PUT" | "PATCH" | "DELETE";
+  url: string;
+  query?: object;
+  body?: unknown;
+  responseType?: "json" | "blob";
+}
+
+// Requester 由调用方实现（axios、fetch 等），负责拼接查询参数、携带令牌与处理错误
+export type Requester = <T>(options: RequestOptions) => Promise<T>;
+`
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// 与 TypeScript 全局类型或生成代码中固定类型重名的定义使用带包名的名称
+var tsReservedTypes = map[string]bool{
+	"ApiResponse": true, "RequestOptions": true, "Requester": true, "ApiClient": true,
+	"Array": true, "Blob": true, "Date": true, "Error": true, "Event": true, "File": true,
+	"FormData": true, "Map": true, "Object": true, "Promise": true, "Record": true,
+	"Request": true, "Response": true, "Set": true, "String": true, "Number": true, "Boolean": true,
+}
+
+// JS 保留字不能作为参数名
+var tsReservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true,
+	"extends": true, "false": true, "finally": true, "for": true, "function": true, "if": true,
+	"import": true, "in": true, "instanceof": true, "new": true, "null": true, "return": true,
+	"super": true, "switch": true, "this": true, "throw": true, "true": true, "try": true,
+	"typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"body": true, "query": true, // 与生成方法的参数重名
+}
+
+// TypeScript 生成类型定义与客户端
+// 客户端通过 createClient(request) 创建，按控制器分组，如 client.notice.getForm(id)
+func (g *Generator) TypeScript() []byte {
+	defs := g.allDefinitions()
+	names := typeNames(defs)
+
+	var b strings.Builder
+	b.WriteString(tsPrelude)
+
+	for _, name := range sortedKeys(defs) {
+		b.WriteString("\n")
+		writeDefinition(&b, names[name], defs[name], names)
+	}
+
+	b.WriteString("\nexport function createClient(request: Requester) {\n  return {\n")
+
+	groups := make(map[string][]*Operation)
+	for _, op := range g.operations {
+		groups[op.Group] = append(groups[op.Group], op)
+	}
+	groupNames := make([]string, 0, len(groups))
+	for name := range groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	for _, group := range groupNames {
+		fmt.Fprintf(&b, "    %s: {\n", group)
+		for _, op := range groups[group] {
+			writeOperation(&b, op, names)
+		}
+		b.WriteString("    },\n")
+	}
+	b.WriteString("  };\n}\n\nexport type ApiClient = ReturnType<typeof createClient>;\n")
+
+	return []byte(b.String())
+}
+
+// typeNames 定义名到 TypeScript 类型名的映射
+// 默认取去掉包名的类型名，如 system.NoticeForm 为 NoticeForm；不同包存在同名类型时带上包名
+func typeNames(defs map[string]*Schema) map[string]string {
+	count := make(map[string]int, len(defs))
+	for name := range defs {
+		count[shortName(name)]++
+	}
+
+	names := make(map[string]string, len(defs))
+	for name := range defs {
+		short := shortName(name)
+		if count[short] > 1 || tsReservedTypes[short] {
+			names[name] = upperFirst(identifier(name))
+			continue
+		}
+		names[name] = short
+	}
+	return names
+}
+
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return upperFirst(identifier(name))
+}
+
+func writeDefinition(b *strings.Builder, name string, schema *Schema, names map[string]string) {
+	if schema.Description != "" {
+		fmt.Fprintf(b, "/** %s */\n", schema.Description)
+	}
+
+	if schema.Type != "object" || schema.Properties == nil {
+		fmt.Fprintf(b, "export type %s = %s;\n", name, tsType(schema, names, ""))
+		return
+	}
+
+	fmt.Fprintf(b, "export interface %s {\n", name)
+	writeProperties(b, schema, names, "  ")
+	b.WriteString("}\n")
+}
+
+func writeProperties(b *strings.Builder, schema *Schema, names map[string]string, indent string) {
+	for _, key := range sortedKeys(schema.Properties) {
+		prop := schema.Properties[key]
+		if prop.Description != "" {
+			fmt.Fprintf(b, "%s/** %s */\n", indent, prop.Description)
+		}
+
+		optional := "?"
+		if schema.isRequired(key) {
+			optional = ""
+		}
+		fmt.Fprintf(b, "%s%s%s: %s;\n", indent, propertyName(key), optional, tsType(prop, names, indent))
+	}
+}
+
+func propertyName(key string) string {
+	if tsIdentifier.MatchString(key) {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+// tsType 将 Schema 转换为 TypeScript 类型表达式
+func tsType(schema *Schema, names map[string]string, indent string) string {
+	if schema == nil {
+		return "unknown"
+	}
+
+	if ref := schema.RefName(); ref != "" {
+		if name, ok := names[ref]; ok {
+			return name
+		}
+		return "unknown"
+	}
+
+	if len(schema.AllOf) > 0 {
+		parts := make([]string, 0, len(schema.AllOf))
+		for _, item := range schema.AllOf {
+			parts = append(parts, tsType(item, names, indent))
+		}
+		return strings.Join(parts, " & ")
+	}
+
+	if len(schema.Enum) > 0 {
+		parts := make([]string, 0, len(schema.Enum))
+		for _, v := range schema.Enum {
+			literal, _ := json.Marshal(v)
+			parts = append(parts, string(literal))
+		}
+		return strings.Join(parts, " | ")
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "file":
+		return "Blob"
+	case "array":
+		item := tsType(schema.Items, names, indent)
+		if strings.ContainsAny(item, " |&") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(schema.Properties) > 0 {
+			var b strings.Builder
+			b.WriteString("{\n")
+			writeProperties(&b, schema, names, indent+"  ")
+			b.WriteString(indent + "}")
+			return b.String()
+		}
+		if schema.AdditionalProperties != nil {
+			return "Record<string, " + tsType(schema.AdditionalProperties, names, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	default:
+		return "unknown"
+	}
+}
+
+func writeOperation(b *strings.Builder, op *Operation, names map[string]string) {
+	const indent = "      "
+
+	doc := op.Method + " " + op.Path
+	if op.Title != "" {
+		doc = op.Title + " · " + doc
+	}
+	if op.Perm != "" {
+		doc += " · " + op.Perm
+	}
+	fmt.Fprintf(b, "%s/** %s */\n", indent, doc)
+
+	args := make([]string, 0, 4)
+	url := op.Path
+	for _, name := range op.PathParams {
+		arg := paramName(name)
+		args = append(args, arg+": string | number")
+		if name == "*" {
+			url = strings.Replace(url, "*", "${"+arg+"}", 1)
+		} else {
+			url = strings.Replace(url, ":"+name, "${encodeURIComponent(String("+arg+"))}", 1)
+		}
+	}
+
+	fields := []string{fmt.Sprintf("method: %q", op.Method), "url: `" + url + "`"}
+	switch {
+	case op.Multipart:
+		args = append(args, "body: FormData")
+		fields = append(fields, "body")
+	case op.Body != nil:
+		args = append(args, "body: "+tsType(op.Body, names, indent))
+		fields = append(fields, "body")
+	}
+	if op.Query != nil {
+		args = append(args, "query?: "+tsType(op.Query, names, indent))
+		fields = append(fields, "query")
+	}
+
+	result := "ApiResponse<" + tsType(op.Response, names, indent) + ">"
+	if op.Binary {
+		result = "Blob"
+		fields = append(fields, `responseType: "blob"`)
+	}
+
+	fmt.Fprintf(b, "%s%s: (%s) =>\n%s  request<%s>({ %s }),\n",
+		indent, op.Name, strings.Join(args, ", "), indent, result, strings.Join(fields, ", "))
+}
+
+func paramName(name string) string {
+	if name == "*" {
+		return "wildcard"
+	}
+	arg := lowerFirst(identifier(name))
+	if arg == "" || tsReservedWords[arg] {
+		arg += "Param"
+	}
+	return arg
+}
@@ -0,0 +1,177 @@
+// Package webhook delivers signed event payloads to HTTP endpoints.
+//
+// Every request is a POST with a JSON body and carries the event name, the
+// delivery id, a unix timestamp and an HMAC-SHA256 signature of
+// "<timestamp>.<body>" keyed with the endpoint secret, receivers recompute the
+// signature with Verify and should reject stale timestamps.
+//
+// Endpoints are configured by users, so requests only reach public addresses
+// and redirects are not followed, a 3xx answer is an unexpected status.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/top-system/light-admin/pkg/netguard"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// SignaturePrefix is prepended to the hex encoded signature
+	SignaturePrefix = "sha256="
+
+	// MaxResponseBody is the number of response bytes kept for the delivery log
+	MaxResponseBody = 4096
+
+	userAgent = "light-admin-webhook/1.0"
+)
+
+// ErrUnexpectedStatus is returned when the endpoint answers with a non-2xx status
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
+// Envelope is the JSON body of every delivery
+type Envelope struct {
+	ID        string      `json:"id"` // event id, shared by the deliveries of one event
+	Event     string      `json:"event"`
+	Timestamp int64       `json:"timestamp"` // unix milliseconds the event occurred at
+	Data      interface{} `json:"data"`
+}
+
+// Request is a single delivery attempt
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// Response is what the endpoint answered, Body is truncated to MaxResponseBody
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Client posts deliveries
+type Client struct {
+	http *http.Client
+	now  func() time.Time
+}
+
+// NewClient creates a client, timeout bounds a single attempt
+func NewClient(timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Client{
+		http: netguard.NewClient(timeout),
+		now:  time.Now,
+	}
+}
+
+// ValidateURL checks that raw is an absolute http(s) URL whose host resolves to public addresses only
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.New("missing host")
+	}
+	return netguard.CheckHost(ctx, u.Hostname())
+}
+
+// Deliver posts req, a response is returned whenever the endpoint answered,
+// also together with ErrUnexpectedStatus
+func (c *Client) Deliver(ctx context.Context, req Request) (*Response, error) {
+	timestamp := c.now().Unix()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", userAgent)
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if req.Secret != "" {
+		httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Body))
+	}
+
+	start := time.Now()
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(httpResp.Body, MaxResponseBody))
+	resp := &Response{
+		StatusCode: httpResp.StatusCode,
+		Body:       string(body),
+		Duration:   time.Since(start),
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return resp, fmt.Errorf("%w: %d", ErrUnexpectedStatus, httpResp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+// Sign returns the signature header value of body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body sent at timestamp
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	if !strings.HasPrefix(signature, SignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// Backoff returns the delay before the retry following attempt (counting from 1),
+// base doubles with every attempt and is capped at max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if max > 0 && delay >= max {
+			return max
+		}
+	}
+	if max > 0 && delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/pkg/netguard"
+)
+
+// newTestClient allows loopback for the test servers, the redirect policy stays in place
+func newTestClient() *Client {
+	client := NewClient(time.Second)
+	client.http.Transport = http.DefaultTransport
+	return client
+}
+
+func TestDeliverSigned(t *testing.T) {
+	var (
+		headers http.Header
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient().Deliver(t.Context(), Request{
+		URL:        server.URL,
+		Secret:     "s3cret",
+		Event:      "user.created",
+		DeliveryID: "42",
+		Body:       []byte(`{"event":"user.created"}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", resp.Body)
+
+	assert.Equal(t, "user.created", headers.Get(HeaderEvent))
+	assert.Equal(t, "42", headers.Get(HeaderDelivery))
+	timestamp, err := strconv.ParseInt(headers.Get(HeaderTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.True(t, Verify("s3cret", headers.Get(HeaderSignature), timestamp, body))
+	assert.False(t, Verify("other", headers.Get(HeaderSignature), timestamp, body))
+	assert.False(t, Verify("s3cret", headers.Get(HeaderSignature), timestamp+1, body))
+}
+
+func TestDeliverUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient().Deliver(t.Context(), Request{URL: server.URL, Event: "ping", Body: []byte(`{}`)})
+	assert.True(t, errors.Is(err, ErrUnexpectedStatus))
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, "boom\n", resp.Body)
+}
+
+func TestDeliverRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("loopback server should not be requested")
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second).Deliver(t.Context(), Request{URL: server.URL, Event: "ping", Body: []byte(`{}`)})
+	assert.True(t, errors.Is(err, netguard.ErrBlockedAddress))
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect target should not be requested")
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer server.Close()
+
+	resp, err := newTestClient().Deliver(t.Context(), Request{URL: server.URL, Event: "ping", Body: []byte(`{}`)})
+	assert.True(t, errors.Is(err, ErrUnexpectedStatus))
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, raw := range []string{"http://127.0.0.1/hook", "http://localhost:8080/hook", "http://169.254.169.254/", "ftp://93.184.216.34/", "/relative"} {
+		assert.Error(t, ValidateURL(t.Context(), raw), raw)
+	}
+	assert.NoError(t, ValidateURL(t.Context(), "https://93.184.216.34/hook"))
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+	assert.Equal(t, 30*time.Second, Backoff(1, base, max))
+	assert.Equal(t, time.Minute, Backoff(2, base, max))
+	assert.Equal(t, 4*time.Minute, Backoff(4, base, max))
+	assert.Equal(t, max, Backoff(6, base, max))
+	assert.Equal(t, max, Backoff(100, base, max))
+}
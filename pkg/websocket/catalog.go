@@ -0,0 +1,134 @@
+package websocket
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/top-system/light-admin/pkg/apimeta"
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+// 目标类型
+const (
+	KindTopic = "topic" // 广播主题，客户端订阅 /topic/*
+	KindQueue = "queue" // 用户队列，客户端订阅 /user/queue/*
+	KindApp   = "app"   // 应用目标，客户端发送 /app/*
+)
+
+// Topic 可订阅（或可发送）的目标描述
+// Payload 为消息体示例值，注册时转换为 Schema
+type Topic struct {
+	Destination string          `json:"destination"`
+	Kind        string          `json:"kind"`
+	Module      string          `json:"module"`
+	Description string          `json:"description"`
+	Permission  string          `json:"permission,omitempty"` // 订阅或发送所需权限，空表示登录即可
+	Payload     interface{}     `json:"-"`
+	Schema      *apimeta.Schema `json:"schema"`
+}
+
+// TopicCatalog 目标注册表，由发布消息的模块注册，供前端和集成方查询
+type TopicCatalog struct {
+	mu     sync.RWMutex
+	topics map[string]Topic
+}
+
+// NewTopicCatalog 创建目标注册表
+func NewTopicCatalog() *TopicCatalog {
+	return &TopicCatalog{topics: make(map[string]Topic)}
+}
+
+// Register 注册目标，重复注册时覆盖
+// 未指定 Kind 时按目标前缀推断
+func (c *TopicCatalog) Register(topic Topic) {
+	if topic.Kind == "" {
+		topic.Kind = kindOf(topic.Destination)
+	}
+	topic.Schema = apimeta.SchemaOf(topic.Payload)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.topics[topic.Destination] = topic
+}
+
+// Get 按目标查找，用户目标 /user/{username}/queue/x 与 /user/queue/x 均按 /queue/x 查找
+// 没有完全相同的注册项时查找匹配的通配符注册项（如 /app/device/*/command）
+func (c *TopicCatalog) Get(destination string) (Topic, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	destination = NormalizeDestination(destination)
+	if topic, ok := c.topics[destination]; ok {
+		return topic, true
+	}
+	for _, topic := range c.topics {
+		if stomp.IsPattern(topic.Destination) && stomp.MatchDestination(topic.Destination, destination) {
+			return topic, true
+		}
+	}
+	return Topic{}, false
+}
+
+// Match 通配符订阅可能收到的全部注册项，不含通配符时与 Get 相同
+func (c *TopicCatalog) Match(pattern string) []Topic {
+	if !stomp.IsPattern(pattern) {
+		if topic, ok := c.Get(pattern); ok {
+			return []Topic{topic}
+		}
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pattern = NormalizeDestination(pattern)
+	var list []Topic
+	for _, topic := range c.topics {
+		if stomp.MatchDestination(pattern, topic.Destination) {
+			list = append(list, topic)
+		}
+	}
+	return list
+}
+
+// List 按目标排序返回全部注册项
+func (c *TopicCatalog) List() []Topic {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]Topic, 0, len(c.topics))
+	for _, topic := range c.topics {
+		list = append(list, topic)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Destination < list[j].Destination })
+	return list
+}
+
+// NormalizeDestination 去掉用户目标的 /user 与用户名前缀
+func NormalizeDestination(destination string) string {
+	if !strings.HasPrefix(destination, "/user/") {
+		return destination
+	}
+
+	rest := strings.TrimPrefix(destination, "/user")
+	if strings.HasPrefix(rest, "/queue/") {
+		return rest
+	}
+	// /user/{username}/queue/x
+	if i := strings.Index(rest[1:], "/"); i >= 0 {
+		return rest[i+1:]
+	}
+	return destination
+}
+
+func kindOf(destination string) string {
+	switch {
+	case strings.HasPrefix(destination, "/queue/"):
+		return KindQueue
+	case strings.HasPrefix(destination, "/app/"):
+		return KindApp
+	default:
+		return KindTopic
+	}
+}
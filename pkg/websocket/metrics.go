@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"runtime"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMetricsInterval 运行时指标默认推送间隔
+const DefaultMetricsInterval = 5 * time.Second
+
+// startTime 进程启动时间，用于计算运行时长
+var startTime = time.Now()
+
+// SystemMetrics 运行时指标
+type SystemMetrics struct {
+	Goroutines  int    `json:"goroutines"`
+	HeapAlloc   uint64 `json:"heapAlloc"` // 字节
+	HeapSys     uint64 `json:"heapSys"`   // 字节
+	NumGC       uint32 `json:"numGC"`
+	Sessions    int    `json:"sessions"`    // WebSocket 连接数
+	OnlineUsers int    `json:"onlineUsers"` // WebSocket 在线用户数
+	Uptime      int64  `json:"uptime"`      // 秒
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// SystemMetrics 采集当前运行时指标
+func (ws *WebSocket) SystemMetrics() SystemMetrics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	return SystemMetrics{
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   mem.HeapAlloc,
+		HeapSys:     mem.HeapSys,
+		NumGC:       mem.NumGC,
+		Sessions:    ws.Broker.GetTotalSessionCount(),
+		OnlineUsers: ws.Broker.GetOnlineUserCount(),
+		Uptime:      int64(time.Since(startTime).Seconds()),
+		Timestamp:   time.Now().UnixMilli(),
+	}
+}
+
+// StartMetrics 定时推送运行时指标，无人订阅时跳过采集
+// 返回的函数用于停止推送
+func (ws *WebSocket) StartMetrics(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultMetricsInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if ws.Broker.HasSubscribers(TopicSystemMetrics) {
+					// 指标只反映本实例，多实例部署时不转发到其他实例
+					ws.Broker.PublishLocal(TopicSystemMetrics, ws.SystemMetrics())
+				}
+			}
+		}
+	}()
+
+	ws.logger.Debug("System metrics publisher started", zap.Duration("interval", interval))
+
+	return func() { close(done) }
+}
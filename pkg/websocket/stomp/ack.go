@@ -0,0 +1,272 @@
+package stomp
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxRedeliveries NACK 或断开连接后重新投递的默认次数上限
+	DefaultMaxRedeliveries = 3
+	// maxPendingMessages 每个会话最多保留的未确认消息数，超过时丢弃最早的消息
+	maxPendingMessages = 1000
+)
+
+// pendingMessage 发送给 client / client-individual 订阅、等待客户端确认的消息
+type pendingMessage struct {
+	ID             string // MESSAGE 帧的 ack 头，客户端在 ACK/NACK 的 id 头中回传
+	SubscriptionID string
+	Destination    string
+	Body           []byte
+	Deliveries     int // 已投递次数，首次投递为 1
+}
+
+// subscribe 订阅目标并记录确认模式，auto 模式不记录
+func (s *Session) subscribe(subscriptionID, destination, ack string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Subscriptions == nil {
+		s.Subscriptions = make(map[string]string)
+	}
+	s.Subscriptions[subscriptionID] = destination
+
+	if IsPattern(destination) {
+		if s.patterns == nil {
+			s.patterns = make(map[string]*regexp.Regexp)
+		}
+		s.patterns[subscriptionID] = compilePattern(destination)
+	} else {
+		delete(s.patterns, subscriptionID)
+	}
+
+	if ack == AckAuto {
+		delete(s.ackModes, subscriptionID)
+		return
+	}
+	if s.ackModes == nil {
+		s.ackModes = make(map[string]string)
+	}
+	s.ackModes[subscriptionID] = ack
+}
+
+// subscriptionFor 消息所属的订阅及其确认模式
+// 用户队列的目标为 /user/{username}/queue/*，对应客户端订阅的 /user/queue/*
+func (s *Session) subscriptionFor(destination string) (string, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, dest := range []string{destination, userDestination(destination)} {
+		if dest == "" {
+			continue
+		}
+		if id := s.findSubscription(dest); id != "" {
+			if ack, ok := s.ackModes[id]; ok {
+				return id, ack
+			}
+			return id, AckAuto
+		}
+	}
+	return "", AckAuto
+}
+
+// addPending 记录等待确认的消息，超过上限时返回被丢弃的最早消息
+func (s *Session) addPending(msg *pendingMessage) *pendingMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dropped *pendingMessage
+	if len(s.pending) >= maxPendingMessages {
+		dropped = s.pending[0]
+		s.pending = s.pending[1:]
+	}
+	s.pending = append(s.pending, msg)
+	return dropped
+}
+
+// ack 确认消息并返回被确认的消息，client 模式同时确认同一订阅中更早的消息
+// 消息不存在（已确认、已取消订阅或已丢弃）时返回 false
+func (s *Session) ack(id string) ([]*pendingMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := -1
+	for i, msg := range s.pending {
+		if msg.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, false
+	}
+
+	target := s.pending[idx]
+	if s.ackModes[target.SubscriptionID] != AckClient {
+		s.pending = append(s.pending[:idx], s.pending[idx+1:]...)
+		return []*pendingMessage{target}, true
+	}
+
+	var acked []*pendingMessage
+	rest := s.pending[:0]
+	for i, msg := range s.pending {
+		if i <= idx && msg.SubscriptionID == target.SubscriptionID {
+			acked = append(acked, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	s.pending = rest
+	return acked, true
+}
+
+// takePending 取出全部未确认的消息
+func (s *Session) takePending() []*pendingMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pending
+	s.pending = nil
+	return pending
+}
+
+// PendingCount 未确认的消息数
+func (s *Session) PendingCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.pending)
+}
+
+// SetMaxRedeliveries 设置 NACK 或断开连接后重新投递的次数上限，0 表示不重新投递
+func (b *Broker) SetMaxRedeliveries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.maxRedeliveries = n
+}
+
+// handleAck 处理 ACK / NACK 命令，NACK 的消息在次数上限内重新投递，否则丢弃
+// STOMP 1.2 使用 id 头，兼容 1.1 的 message-id 头
+func (b *Broker) handleAck(session *Session, frame *Frame) {
+	id := frame.GetHeader(HdrID)
+	if id == "" {
+		id = frame.GetHeader(HdrMessageID)
+	}
+	if id == "" {
+		b.sendError(session, "Missing id header")
+		return
+	}
+
+	msgs, ok := session.ack(id)
+	if !ok {
+		// 消息已被确认、已取消订阅或已丢弃，迟到的确认不影响连接
+		b.logger.Debug("ACK/NACK for unknown message",
+			zap.String("sessionID", session.ID),
+			zap.String("id", id))
+	} else if frame.Command == CmdNack {
+		for _, msg := range msgs {
+			b.redeliver(session, msg)
+		}
+	}
+
+	// 如果请求了回执，发送 RECEIPT
+	if receiptID := frame.GetHeader(HdrReceipt); receiptID != "" {
+		b.sendReceipt(session, receiptID)
+	}
+}
+
+// redeliver 在次数上限内重新投递消息，否则丢弃
+func (b *Broker) redeliver(session *Session, msg *pendingMessage) {
+	b.mu.RLock()
+	limit := b.maxRedeliveries
+	b.mu.RUnlock()
+
+	if msg.Deliveries > limit {
+		b.logger.Warn("Dropping message after redelivery limit",
+			zap.String("sessionID", session.ID),
+			zap.String("destination", msg.Destination),
+			zap.Int("deliveries", msg.Deliveries))
+		return
+	}
+
+	if err := b.deliver(session, msg.Destination, msg.Body, msg.Deliveries+1); err != nil {
+		b.logger.Error("Failed to redeliver message",
+			zap.String("sessionID", session.ID),
+			zap.String("destination", msg.Destination),
+			zap.Error(err))
+	}
+}
+
+// redeliverPending 会话断开后，将用户队列中未确认的消息重新投递到该用户其他订阅了同一队列的会话，
+// 没有这样的会话时保存为离线消息；主题消息已经发送给其他订阅者，直接丢弃
+func (b *Broker) redeliverPending(session *Session) {
+	pending := session.takePending()
+	if len(pending) == 0 {
+		return
+	}
+
+	others := b.GetUserSessions(session.Username)
+	var dropped int
+	for _, msg := range pending {
+		target := b.pickSession(others, msg.Destination)
+		if target != nil {
+			b.redeliver(target, msg)
+			continue
+		}
+		if dest := userDestination(msg.Destination); dest != "" &&
+			b.storeMessage(session.Username, strings.TrimPrefix(dest, "/user"), msg.Body) {
+			continue
+		}
+		dropped++
+	}
+
+	if dropped > 0 {
+		b.logger.Info("Dropped unacknowledged messages of closed session",
+			zap.String("sessionID", session.ID),
+			zap.String("username", session.Username),
+			zap.Int("count", dropped))
+	}
+}
+
+// pickSession 选择订阅了用户队列的会话，主题消息返回 nil
+func (b *Broker) pickSession(sessions []*Session, destination string) *Session {
+	if userDestination(destination) == "" {
+		return nil
+	}
+	for _, s := range sessions {
+		if !s.Authenticated {
+			continue
+		}
+		if id, _ := s.subscriptionFor(destination); id != "" {
+			return s
+		}
+	}
+	return nil
+}
+
+// validAckMode SUBSCRIBE 的 ack 头是否合法，未指定时为 auto
+func validAckMode(ack string) bool {
+	switch ack {
+	case AckAuto, AckClient, AckClientIndividual:
+		return true
+	}
+	return false
+}
+
+// userDestination 将 /user/{username}/queue/x 转换为 /user/queue/x，其他目标返回空
+func userDestination(destination string) string {
+	const prefix = "/user/"
+	if !strings.HasPrefix(destination, prefix) {
+		return ""
+	}
+
+	rest := destination[len(prefix):]
+	if i := strings.IndexByte(rest, '/'); i > 0 {
+		return "/user" + rest[i:]
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package stomp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+func newTestBroker() *Broker {
+	b := NewBroker(zap.NewNop())
+	b.SetTokenValidator(func(token string) (string, error) {
+		return token, nil
+	})
+	return b
+}
+
+// testSession 已认证的会话，client 读取服务端发送的帧
+type testSession struct {
+	*Session
+	client *websocket.Conn
+}
+
+func connect(t *testing.T, b *Broker, username string) *testSession {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	session := &Session{ID: username + "-" + strconv.Itoa(b.GetTotalSessionCount()), Conn: <-conns, Subscriptions: make(map[string]string)}
+	b.AddSession(session)
+	ts := &testSession{Session: session, client: client}
+
+	ts.send(b, NewFrame(CmdConnect).SetHeader(HdrLogin, username))
+	if f := ts.read(t); f.Command != CmdConnected {
+		t.Fatalf("expected CONNECTED, got %s", f.Command)
+	}
+	return ts
+}
+
+func (s *testSession) send(b *Broker, frame *Frame) {
+	b.HandleMessage(s.Session, frame.Marshal())
+}
+
+func (s *testSession) subscribe(b *Broker, id, destination, ack string) {
+	s.send(b, NewFrame(CmdSubscribe).SetHeader(HdrID, id).SetHeader(HdrDestination, destination).SetHeader(HdrAck, ack))
+}
+
+func (s *testSession) read(t *testing.T) *Frame {
+	t.Helper()
+
+	s.client.SetReadDeadline(time.Now().Add(3 * time.Second))
+	_, data, err := s.client.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := ParseFrame(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+// expectNone 短时间内没有收到帧
+func (s *testSession) expectNone(t *testing.T) {
+	t.Helper()
+
+	s.client.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, data, err := s.client.ReadMessage(); err == nil {
+		t.Fatalf("unexpected frame %q", data)
+	}
+}
+
+func TestAckClientIndividual(t *testing.T) {
+	b := newTestBroker()
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/user/queue/messages", AckClientIndividual)
+
+	b.SendToUser("alice", "/queue/messages", "hello")
+	msg := alice.read(t)
+	if msg.GetHeader(HdrSubscription) != "sub-0" || msg.GetHeader(HdrAck) == "" {
+		t.Fatalf("unexpected headers %v", msg.Headers)
+	}
+	if alice.PendingCount() != 1 {
+		t.Fatalf("pending = %d, want 1", alice.PendingCount())
+	}
+
+	// NACK 后重新投递，新的消息带 redelivered 头
+	alice.send(b, NewFrame(CmdNack).SetHeader(HdrID, msg.GetHeader(HdrAck)))
+	again := alice.read(t)
+	if string(again.Body) != "hello" || again.GetHeader(HdrRedelivered) != "true" {
+		t.Fatalf("unexpected redelivery %v %q", again.Headers, again.Body)
+	}
+
+	alice.send(b, NewFrame(CmdAck).SetHeader(HdrID, again.GetHeader(HdrAck)).SetHeader(HdrReceipt, "r-1"))
+	if f := alice.read(t); f.Command != CmdReceipt || f.GetHeader(HdrReceiptID) != "r-1" {
+		t.Fatalf("expected RECEIPT, got %s %v", f.Command, f.Headers)
+	}
+	if alice.PendingCount() != 0 {
+		t.Fatalf("pending = %d after ACK", alice.PendingCount())
+	}
+}
+
+func TestAckClientCumulative(t *testing.T) {
+	b := newTestBroker()
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/topic/notice", AckClient)
+	alice.subscribe(b, "sub-1", "/topic/banner", AckClientIndividual)
+
+	var acks []string
+	for i := 0; i < 3; i++ {
+		b.Publish("/topic/notice", strconv.Itoa(i))
+		acks = append(acks, alice.read(t).GetHeader(HdrAck))
+	}
+	b.Publish("/topic/banner", "banner")
+	alice.read(t)
+
+	// 确认第二条消息时同时确认第一条，其他订阅的消息不受影响
+	alice.send(b, NewFrame(CmdAck).SetHeader(HdrID, acks[1]))
+	if alice.PendingCount() != 2 {
+		t.Fatalf("pending = %d, want 2", alice.PendingCount())
+	}
+
+	alice.send(b, NewFrame(CmdUnsubscribe).SetHeader(HdrID, "sub-0"))
+	if alice.PendingCount() != 1 {
+		t.Fatalf("pending = %d after UNSUBSCRIBE, want 1", alice.PendingCount())
+	}
+}
+
+func TestNackRedeliveryLimit(t *testing.T) {
+	b := newTestBroker()
+	b.SetMaxRedeliveries(1)
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/topic/notice", AckClientIndividual)
+
+	b.Publish("/topic/notice", "hello")
+	msg := alice.read(t)
+	alice.send(b, NewFrame(CmdNack).SetHeader(HdrID, msg.GetHeader(HdrAck)))
+	msg = alice.read(t)
+	alice.send(b, NewFrame(CmdNack).SetHeader(HdrID, msg.GetHeader(HdrAck)))
+
+	alice.expectNone(t)
+	if alice.PendingCount() != 0 {
+		t.Fatalf("pending = %d after limit, want 0", alice.PendingCount())
+	}
+}
+
+func TestRedeliverOnDisconnect(t *testing.T) {
+	b := newTestBroker()
+	phone := connect(t, b, "alice")
+	phone.subscribe(b, "sub-0", "/user/queue/messages", AckClientIndividual)
+	laptop := connect(t, b, "alice")
+	laptop.subscribe(b, "sub-0", "/user/queue/messages", AckAuto)
+
+	b.SendToUser("alice", "/queue/messages", "hello")
+	phone.read(t)
+	laptop.read(t)
+
+	b.RemoveSession(phone.ID)
+	msg := laptop.read(t)
+	if string(msg.Body) != "hello" || msg.GetHeader(HdrRedelivered) != "true" {
+		t.Fatalf("unexpected redelivery %v %q", msg.Headers, msg.Body)
+	}
+}
+
+func TestInvalidAckMode(t *testing.T) {
+	b := newTestBroker()
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/topic/notice", "manual")
+
+	if f := alice.read(t); f.Command != CmdError {
+		t.Fatalf("expected ERROR, got %s", f.Command)
+	}
+	if b.HasSubscribers("/topic/notice") {
+		t.Fatal("subscription with invalid ack mode was accepted")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,47 +23,65 @@ type Session struct {
 	Username      string
 	Conn          *websocket.Conn
 	Subscriptions map[string]string // subscriptionID -> destination
+	RemoteIP      string            // 客户端地址
 	ConnectTime   int64
 	Authenticated bool // 是否已认证
 	mu            sync.RWMutex
+
+	patterns map[string]*regexp.Regexp // subscriptionID -> 含通配符的订阅
+	ackModes map[string]string         // subscriptionID -> client / client-individual，auto 不记录
+	pending  []*pendingMessage         // 等待确认的消息，按发送顺序
 }
 
-// Subscribe 订阅主题
+// Subscribe 订阅主题，destination 可以包含通配符
 func (s *Session) Subscribe(subscriptionID, destination string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.Subscriptions[subscriptionID] = destination
+	s.subscribe(subscriptionID, destination, AckAuto)
 }
 
-// Unsubscribe 取消订阅
+// Unsubscribe 取消订阅，丢弃该订阅未确认的消息
 func (s *Session) Unsubscribe(subscriptionID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.Subscriptions, subscriptionID)
+	delete(s.patterns, subscriptionID)
+	delete(s.ackModes, subscriptionID)
+
+	rest := s.pending[:0]
+	for _, msg := range s.pending {
+		if msg.SubscriptionID != subscriptionID {
+			rest = append(rest, msg)
+		}
+	}
+	s.pending = rest
 }
 
-// GetSubscriptionID 根据 destination 获取 subscriptionID
+// GetSubscriptionID 根据 destination 获取 subscriptionID，优先完全相同的订阅，其次匹配的通配符订阅
 func (s *Session) GetSubscriptionID(destination string) string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	for id, dest := range s.Subscriptions {
-		if dest == destination {
-			return id
-		}
-	}
-	return ""
+	return s.findSubscription(destination)
 }
 
-// IsSubscribed 检查是否订阅了某个目标
+// IsSubscribed 检查是否订阅了某个目标（包括匹配的通配符订阅）
 func (s *Session) IsSubscribed(destination string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	for _, dest := range s.Subscriptions {
+	return s.findSubscription(destination) != ""
+}
+
+// findSubscription 调用方需持有读锁
+func (s *Session) findSubscription(destination string) string {
+	for id, dest := range s.Subscriptions {
 		if dest == destination {
-			return true
+			return id
 		}
 	}
-	return false
+	for id, re := range s.patterns {
+		if re.MatchString(destination) {
+			return id
+		}
+	}
+	return ""
 }
 
 // MessageHandler 消息处理器函数
@@ -72,15 +91,33 @@ type MessageHandler func(session *Session, destination string, body []byte)
 // 返回用户名和错误，如果验证失败返回错误
 type TokenValidator func(token string) (username string, err error)
 
+// DestinationAuthorizer 目标授权函数，在 SUBSCRIBE 和 SEND 前调用，返回错误时拒绝
+type DestinationAuthorizer func(session *Session, destination string) error
+
+// PublishListener 服务端监听发布到主题的消息，本实例发布和其他实例转发的消息都会通知
+type PublishListener func(destination string, body []byte)
+
+// SnapshotProvider 快照提供函数，在客户端订阅后立即返回目标的最新状态
+// 返回 false 表示暂无快照，不发送
+type SnapshotProvider func(session *Session, destination string) (interface{}, bool)
+
 // Broker STOMP消息代理
 type Broker struct {
-	mu             sync.RWMutex
-	sessions       map[string]*Session            // sessionID -> Session
-	users          map[string]map[string]*Session // username -> sessionID -> Session
-	handlers       map[string]MessageHandler      // destination pattern -> handler
-	logger         *zap.Logger
-	tokenValidator TokenValidator // Token验证器
-	messageCounter uint64         // 消息计数器
+	mu              sync.RWMutex
+	sessions        map[string]*Session            // sessionID -> Session
+	users           map[string]map[string]*Session // username -> sessionID -> Session
+	handlers        map[string]MessageHandler      // destination -> handler
+	handlerPatterns []handlerPattern               // 含通配符的处理器，按注册顺序匹配
+	snapshots       map[string]SnapshotProvider    // destination -> snapshot provider
+	listeners       map[string][]PublishListener   // destination -> 服务端监听器
+	streams         map[string]*Stream             // streamID -> 推送通道（SSE 等）
+	logger          *zap.Logger
+	tokenValidator  TokenValidator // Token验证器
+	authorizer      DestinationAuthorizer
+	messageCounter  uint64       // 消息计数器
+	maxRedeliveries int          // NACK 或断开连接后重新投递的次数上限
+	store           MessageStore // 离线消息存储
+	relay           Relay        // 实例间的消息转发
 
 	// 回调
 	OnConnect    func(session *Session)
@@ -91,19 +128,147 @@ type Broker struct {
 // NewBroker 创建消息代理
 func NewBroker(logger *zap.Logger) *Broker {
 	return &Broker{
-		sessions: make(map[string]*Session),
-		users:    make(map[string]map[string]*Session),
-		handlers: make(map[string]MessageHandler),
-		logger:   logger.With(zap.String("module", moduleTag)),
+		sessions:        make(map[string]*Session),
+		users:           make(map[string]map[string]*Session),
+		handlers:        make(map[string]MessageHandler),
+		snapshots:       make(map[string]SnapshotProvider),
+		listeners:       make(map[string][]PublishListener),
+		streams:         make(map[string]*Stream),
+		logger:          logger.With(zap.String("module", moduleTag)),
+		maxRedeliveries: DefaultMaxRedeliveries,
 	}
 }
 
+// handlerPattern 含通配符的消息处理器
+type handlerPattern struct {
+	pattern string
+	re      *regexp.Regexp
+	handler MessageHandler
+}
+
 // RegisterHandler 注册消息处理器
-// destination 支持 /app/sendToAll 格式
+// destination 支持 /app/sendToAll 格式，也可以包含通配符，如 /app/device/*/command，
+// 处理器收到的 destination 为实际的目标；完全相同的处理器优先，其次按注册顺序匹配通配符
 func (b *Broker) RegisterHandler(destination string, handler MessageHandler) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.handlers[destination] = handler
+
+	if !IsPattern(destination) {
+		b.handlers[destination] = handler
+		return
+	}
+	for i, p := range b.handlerPatterns {
+		if p.pattern == destination {
+			b.handlerPatterns[i].handler = handler
+			return
+		}
+	}
+	b.handlerPatterns = append(b.handlerPatterns, handlerPattern{
+		pattern: destination,
+		re:      compilePattern(destination),
+		handler: handler,
+	})
+}
+
+// findHandler 查找目标的处理器
+func (b *Broker) findHandler(destination string) (MessageHandler, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if handler, ok := b.handlers[destination]; ok {
+		return handler, true
+	}
+	for _, p := range b.handlerPatterns {
+		if p.re.MatchString(destination) {
+			return p.handler, true
+		}
+	}
+	return nil, false
+}
+
+// RegisterSnapshot 注册订阅快照
+// 客户端订阅 destination 后先收到一条快照消息，再接收后续的增量消息
+func (b *Broker) RegisterSnapshot(destination string, provider SnapshotProvider) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.snapshots[destination] = provider
+}
+
+// RegisterListener 注册服务端监听器，Publish 到 destination 的消息（包括其他实例转发的）交给 listener 处理，
+// 用于各实例根据广播同步本地状态，如清除缓存
+func (b *Broker) RegisterListener(destination string, listener PublishListener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners[destination] = append(b.listeners[destination], listener)
+}
+
+// notifyListeners 通知目标的服务端监听器
+func (b *Broker) notifyListeners(destination string, body interface{}) {
+	b.mu.RLock()
+	listeners := b.listeners[destination]
+	b.mu.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+
+	bodyBytes, err := marshalBody(body)
+	if err != nil {
+		b.logger.Error("Failed to marshal message",
+			zap.String("destination", destination),
+			zap.Error(err))
+		return
+	}
+	for _, listener := range listeners {
+		listener(destination, bodyBytes)
+	}
+}
+
+// sendSnapshot 向刚订阅的会话发送快照，通配符订阅发送全部匹配目标的快照
+func (b *Broker) sendSnapshot(session *Session, destination string) {
+	b.mu.RLock()
+	providers := make(map[string]SnapshotProvider)
+	if provider, ok := b.snapshots[destination]; ok {
+		providers[destination] = provider
+	} else if IsPattern(destination) {
+		re := compilePattern(destination)
+		for dest, provider := range b.snapshots {
+			if re.MatchString(dest) {
+				providers[dest] = provider
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	for dest, provider := range providers {
+		snapshot, ok := provider(session, dest)
+		if !ok {
+			continue
+		}
+		if err := b.sendMessage(session, dest, snapshot); err != nil {
+			b.logger.Error("Failed to send snapshot",
+				zap.String("sessionID", session.ID),
+				zap.String("destination", dest),
+				zap.Error(err))
+		}
+	}
+}
+
+// HasSubscribers 是否有会话订阅了目标或推送通道接收目标，用于发布方跳过无人订阅时的数据采集
+// 其他实例上的订阅无从得知，设置了实例间转发时总是返回 true
+func (b *Broker) HasSubscribers(destination string) bool {
+	b.mu.RLock()
+	if b.relay != nil {
+		b.mu.RUnlock()
+		return true
+	}
+	for _, session := range b.sessions {
+		if session.Authenticated && session.IsSubscribed(destination) {
+			b.mu.RUnlock()
+			return true
+		}
+	}
+	b.mu.RUnlock()
+	return b.hasStreams(destination)
 }
 
 // SetTokenValidator 设置Token验证器
@@ -111,6 +276,28 @@ func (b *Broker) SetTokenValidator(validator TokenValidator) {
 	b.tokenValidator = validator
 }
 
+// SetAuthorizer 设置目标授权函数
+func (b *Broker) SetAuthorizer(authorizer DestinationAuthorizer) {
+	b.authorizer = authorizer
+}
+
+// authorize 检查会话是否允许访问目标
+func (b *Broker) authorize(session *Session, destination string) bool {
+	if b.authorizer == nil {
+		return true
+	}
+	if err := b.authorizer(session, destination); err != nil {
+		b.logger.Warn("Destination access denied",
+			zap.String("sessionID", session.ID),
+			zap.String("username", session.Username),
+			zap.String("destination", destination),
+			zap.Error(err))
+		b.sendError(session, "Access denied: "+destination)
+		return false
+	}
+	return true
+}
+
 // AddSession 添加会话（未认证状态）
 func (b *Broker) AddSession(session *Session) {
 	b.mu.Lock()
@@ -135,6 +322,7 @@ func (b *Broker) RemoveSession(sessionID string) {
 
 	delete(b.sessions, sessionID)
 
+	offline := false
 	if session.Username != "" {
 		if userSessions, ok := b.users[session.Username]; ok {
 			delete(userSessions, sessionID)
@@ -142,6 +330,7 @@ func (b *Broker) RemoveSession(sessionID string) {
 				delete(b.users, session.Username)
 			}
 		}
+		offline = session.Authenticated && !b.userOnlineLocked(session.Username)
 	}
 	b.mu.Unlock()
 
@@ -149,11 +338,49 @@ func (b *Broker) RemoveSession(sessionID string) {
 		zap.String("sessionID", sessionID),
 		zap.String("username", session.Username))
 
+	if offline {
+		b.notifyPresence(session.Username, false)
+	}
+
+	// 未确认的用户队列消息转给该用户的其他会话
+	if session.Authenticated {
+		b.redeliverPending(session)
+	}
+
 	if b.OnDisconnect != nil && session.Authenticated {
 		b.OnDisconnect(session)
 	}
 }
 
+// CloseAll 关闭全部连接，连接的读循环退出后会话随之移除
+func (b *Broker) CloseAll() {
+	b.mu.RLock()
+	sessions := make([]*Session, 0, len(b.sessions))
+	for _, session := range b.sessions {
+		sessions = append(sessions, session)
+	}
+	b.mu.RUnlock()
+
+	for _, session := range sessions {
+		if session.Conn != nil {
+			_ = session.Conn.Close()
+		}
+	}
+
+	b.mu.RLock()
+	streams := make([]*Stream, 0, len(b.streams))
+	for _, stream := range b.streams {
+		streams = append(streams, stream)
+	}
+	b.mu.RUnlock()
+
+	for _, stream := range streams {
+		stream.Close("")
+	}
+
+	b.logger.Info("All sessions closed", zap.Int("count", len(sessions)), zap.Int("streams", len(streams)))
+}
+
 // GetSession 获取会话
 func (b *Broker) GetSession(sessionID string) *Session {
 	b.mu.RLock()
@@ -178,11 +405,19 @@ func (b *Broker) GetUserSessions(username string) []*Session {
 	return result
 }
 
-// GetOnlineUserCount 获取在线用户数
+// GetOnlineUserCount 获取在线用户数，包括只通过推送通道连接的用户
 func (b *Broker) GetOnlineUserCount() int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	return len(b.users)
+	count := len(b.users)
+	counted := make(map[string]bool)
+	for _, stream := range b.streams {
+		if _, ok := b.users[stream.Username]; !ok && !counted[stream.Username] {
+			counted[stream.Username] = true
+			count++
+		}
+	}
+	return count
 }
 
 // GetTotalSessionCount 获取总会话数
@@ -192,12 +427,33 @@ func (b *Broker) GetTotalSessionCount() int {
 	return len(b.sessions)
 }
 
-// IsUserOnline 检查用户是否在线
+// IsUserOnline 检查用户是否在线（WebSocket 会话或推送通道），设置了实例间转发时包括其他实例
 func (b *Broker) IsUserOnline(username string) bool {
+	b.mu.RLock()
+	online, relay := b.userOnlineLocked(username), b.relay
+	b.mu.RUnlock()
+
+	return online || (relay != nil && relay.IsOnline(username))
+}
+
+// IsUserOnlineLocal 检查用户是否在本实例上在线
+func (b *Broker) IsUserOnlineLocal(username string) bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	sessions, ok := b.users[username]
-	return ok && len(sessions) > 0
+	return b.userOnlineLocked(username)
+}
+
+// userOnlineLocked 用户在本实例上是否有会话或推送通道，调用方需持有 b.mu
+func (b *Broker) userOnlineLocked(username string) bool {
+	if sessions, ok := b.users[username]; ok && len(sessions) > 0 {
+		return true
+	}
+	for _, stream := range b.streams {
+		if stream.Username == username {
+			return true
+		}
+	}
+	return false
 }
 
 // HandleMessage 处理客户端消息（标准 STOMP 协议）
@@ -261,9 +517,11 @@ func (b *Broker) HandleMessage(session *Session, data []byte) {
 	case CmdDisconnect:
 		b.handleDisconnect(session, frame)
 	case CmdAck, CmdNack:
-		// ACK/NACK 暂不处理
-		b.logger.Debug("Received ACK/NACK (ignored)",
-			zap.String("sessionID", session.ID))
+		if !session.Authenticated {
+			b.sendError(session, "Not authenticated. Please send CONNECT first.")
+			return
+		}
+		b.handleAck(session, frame)
 	default:
 		b.logger.Warn("Unknown STOMP command",
 			zap.String("command", frame.Command))
@@ -328,12 +586,17 @@ func (b *Broker) handleConnect(session *Session, frame *Frame) {
 
 	// 将会话添加到用户映射
 	b.mu.Lock()
+	wasOnline := b.userOnlineLocked(username)
 	if _, ok := b.users[username]; !ok {
 		b.users[username] = make(map[string]*Session)
 	}
 	b.users[username][session.ID] = session
 	b.mu.Unlock()
 
+	if !wasOnline {
+		b.notifyPresence(username, true)
+	}
+
 	b.logger.Info("Session authenticated",
 		zap.String("sessionID", session.ID),
 		zap.String("username", username))
@@ -359,20 +622,37 @@ func (b *Broker) handleSubscribe(session *Session, frame *Frame) {
 	if subscriptionID == "" {
 		subscriptionID = destination // 使用 destination 作为默认 ID
 	}
+	ack := frame.GetHeader(HdrAck)
+	if ack == "" {
+		ack = AckAuto
+	}
+	if !validAckMode(ack) {
+		b.sendError(session, "Invalid ack mode: "+ack)
+		return
+	}
+	if !b.authorize(session, destination) {
+		return
+	}
 
-	session.Subscribe(subscriptionID, destination)
+	session.subscribe(subscriptionID, destination, ack)
 
 	b.logger.Debug("Subscribed",
 		zap.String("sessionID", session.ID),
 		zap.String("destination", destination),
-		zap.String("subscriptionID", subscriptionID))
+		zap.String("subscriptionID", subscriptionID),
+		zap.String("ack", ack))
 
 	// 如果请求了回执，发送 RECEIPT
 	if receiptID := frame.GetHeader(HdrReceipt); receiptID != "" {
 		b.sendReceipt(session, receiptID)
 	}
 
-	// 触发订阅回调（用于在订阅时推送初始数据）
+	// 推送订阅快照，避免客户端在下一次推送前没有数据
+	b.sendSnapshot(session, destination)
+	// 投递用户离线期间保存的消息
+	b.deliverStored(session, destination)
+
+	// 触发订阅回调
 	if b.OnSubscribe != nil {
 		b.OnSubscribe(session, destination)
 	}
@@ -406,15 +686,21 @@ func (b *Broker) handleSend(session *Session, frame *Frame) {
 		return
 	}
 
+	if IsPattern(destination) {
+		b.sendError(session, "Wildcards are not allowed in SEND destination: "+destination)
+		return
+	}
+
 	b.logger.Debug("Received SEND",
 		zap.String("sessionID", session.ID),
 		zap.String("destination", destination))
 
-	// 查找处理器
-	b.mu.RLock()
-	handler, ok := b.handlers[destination]
-	b.mu.RUnlock()
+	if !b.authorize(session, destination) {
+		return
+	}
 
+	// 查找处理器
+	handler, ok := b.findHandler(destination)
 	if ok {
 		handler(session, destination, frame.Body)
 	} else {
@@ -503,19 +789,49 @@ func (b *Broker) SendToSession(sessionID string, destination string, body interf
 // SendToUser 发送消息给指定用户（所有会话）
 // 对应 Java 的 /user/{username}/queue/* 模式
 func (b *Broker) SendToUser(username, destination string, body interface{}) {
-	sessions := b.GetUserSessions(username)
-	if len(sessions) == 0 {
-		b.logger.Debug("User not online",
+	bodyBytes, err := marshalBody(body)
+	if err != nil {
+		b.logger.Error("Failed to marshal message",
+			zap.String("username", username),
+			zap.String("destination", destination),
+			zap.Error(err))
+		return
+	}
+
+	delivered := b.sendToUserLocal(username, destination, bodyBytes)
+	b.forward(RelayMessage{Kind: RelayUser, Username: username, Destination: destination, Body: bodyBytes})
+	if delivered {
+		return
+	}
+
+	// 用户不在任何实例上在线，设置了离线消息存储时保存，用户下次订阅该队列时投递
+	if relay := b.currentRelay(); relay != nil && relay.IsOnline(username) {
+		return
+	}
+	if b.storeMessage(username, destination, bodyBytes) {
+		b.logger.Debug("Stored message for offline user",
 			zap.String("username", username),
 			zap.String("destination", destination))
 		return
 	}
+	b.logger.Debug("User not online",
+		zap.String("username", username),
+		zap.String("destination", destination))
+}
+
+// sendToUserLocal 发送给用户在本实例上的全部会话与推送通道，用户不在本实例上在线时返回 false
+func (b *Broker) sendToUserLocal(username, destination string, body []byte) bool {
+	streamed := b.sendToUserStreams(username, destination, body)
+	sessions := b.GetUserSessions(username)
+	if len(sessions) == 0 {
+		return streamed
+	}
 
 	// 构造用户专属目标地址: /user/{username}{destination}
 	userDestination := "/user/" + username + destination
 
 	for _, session := range sessions {
-		if err := b.sendMessage(session, userDestination, body); err != nil {
+		if err := b.deliver(session, userDestination, body, 1); err != nil {
 			b.logger.Error("Failed to send to user",
 				zap.String("username", username),
 				zap.String("sessionID", session.ID),
@@ -526,11 +842,29 @@ func (b *Broker) SendToUser(username, destination string, body interface{}) {
 	b.logger.Debug("Sent to user",
 		zap.String("username", username),
 		zap.String("destination", userDestination))
+	return true
 }
 
 // Publish 发布消息到主题（广播给所有订阅者）
 // 对应 Java 的 /topic/* 模式
 func (b *Broker) Publish(destination string, body interface{}) {
+	bodyBytes, err := marshalBody(body)
+	if err != nil {
+		b.logger.Error("Failed to marshal message",
+			zap.String("destination", destination),
+			zap.Error(err))
+		return
+	}
+
+	b.PublishLocal(destination, bodyBytes)
+	b.forward(RelayMessage{Kind: RelayPublish, Destination: destination, Body: bodyBytes})
+}
+
+// PublishLocal 只发布给本实例上订阅了目标的会话，用于各实例自己的状态（如运行时指标）
+func (b *Broker) PublishLocal(destination string, body interface{}) {
+	b.notifyListeners(destination, body)
+	b.publishStreams(destination, body)
+
 	b.mu.RLock()
 	sessions := make([]*Session, 0)
 	for _, session := range b.sessions {
@@ -556,6 +890,22 @@ func (b *Broker) Publish(destination string, body interface{}) {
 
 // Broadcast 广播消息给所有已认证用户（不管是否订阅）
 func (b *Broker) Broadcast(destination string, body interface{}) {
+	bodyBytes, err := marshalBody(body)
+	if err != nil {
+		b.logger.Error("Failed to marshal message",
+			zap.String("destination", destination),
+			zap.Error(err))
+		return
+	}
+
+	b.BroadcastLocal(destination, bodyBytes)
+	b.forward(RelayMessage{Kind: RelayBroadcast, Destination: destination, Body: bodyBytes})
+}
+
+// BroadcastLocal 只广播给本实例上已认证的会话，用于各实例自己的状态（如在线人数）
+func (b *Broker) BroadcastLocal(destination string, body interface{}) {
+	b.publishStreams(destination, body)
+
 	b.mu.RLock()
 	sessions := make([]*Session, 0, len(b.sessions))
 	for _, session := range b.sessions {
@@ -581,49 +931,87 @@ func (b *Broker) Broadcast(destination string, body interface{}) {
 
 // sendMessage 发送 MESSAGE 帧
 func (b *Broker) sendMessage(session *Session, destination string, body interface{}) error {
-	// 序列化 body
-	var bodyBytes []byte
-	var err error
+	bodyBytes, err := marshalBody(body)
+	if err != nil {
+		return err
+	}
 
+	return b.deliver(session, destination, bodyBytes, 1)
+}
+
+// marshalBody 序列化消息体，[]byte 与 string 原样发送，其他类型序列化为 JSON
+func marshalBody(body interface{}) ([]byte, error) {
 	switch v := body.(type) {
 	case []byte:
-		bodyBytes = v
+		return v, nil
 	case string:
-		bodyBytes = []byte(v)
+		return []byte(v), nil
 	default:
-		bodyBytes, err = json.Marshal(body)
-		if err != nil {
-			return err
-		}
+		return json.Marshal(body)
 	}
+}
 
+// deliver 发送 MESSAGE 帧，client / client-individual 订阅的消息带 ack 头并记录到确认前
+// deliveries 为本次是第几次投递，大于 1 时带 redelivered 头
+func (b *Broker) deliver(session *Session, destination string, body []byte, deliveries int) error {
 	// 获取订阅ID
-	subscriptionID := session.GetSubscriptionID(destination)
+	subscriptionID, ack := session.subscriptionFor(destination)
+	messageID := b.nextMessageID()
 
 	// 创建 MESSAGE 帧
-	frame := NewMessageFrame(destination, subscriptionID, b.nextMessageID(), bodyBytes)
+	frame := NewMessageFrame(destination, subscriptionID, messageID, body)
+	if deliveries > 1 {
+		frame.SetHeader(HdrRedelivered, "true")
+	}
+
+	if ack != AckAuto {
+		frame.SetHeader(HdrAck, messageID)
+		dropped := session.addPending(&pendingMessage{
+			ID:             messageID,
+			SubscriptionID: subscriptionID,
+			Destination:    destination,
+			Body:           body,
+			Deliveries:     deliveries,
+		})
+		if dropped != nil {
+			b.logger.Warn("Too many unacknowledged messages, dropping the oldest",
+				zap.String("sessionID", session.ID),
+				zap.String("destination", dropped.Destination))
+		}
+	}
 
 	return b.sendFrame(session, frame)
 }
 
-// GetOnlineUsers 获取在线用户列表
+// GetOnlineUsers 获取在线用户列表，推送通道计入用户的会话数
 func (b *Broker) GetOnlineUsers() []OnlineUser {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	users := make([]OnlineUser, 0, len(b.users))
+	online := make(map[string]*OnlineUser, len(b.users))
+	join := func(username string, connectTime int64) {
+		user, ok := online[username]
+		if !ok {
+			user = &OnlineUser{Username: username}
+			online[username] = user
+		}
+		user.SessionCount++
+		if user.LoginTime == 0 || connectTime < user.LoginTime {
+			user.LoginTime = connectTime
+		}
+	}
 	for username, sessions := range b.users {
-		var earliestTime int64 = 0
 		for _, session := range sessions {
-			if earliestTime == 0 || session.ConnectTime < earliestTime {
-				earliestTime = session.ConnectTime
-			}
+			join(username, session.ConnectTime)
 		}
-		users = append(users, OnlineUser{
-			Username:     username,
-			SessionCount: len(sessions),
-			LoginTime:    earliestTime,
-		})
+	}
+	for _, stream := range b.streams {
+		join(stream.Username, stream.ConnectTime)
+	}
+
+	users := make([]OnlineUser, 0, len(online))
+	for _, user := range online {
+		users = append(users, *user)
 	}
 	return users
 }
@@ -49,6 +49,14 @@ const (
 	HdrReceiptID     = "receipt-id"
 	HdrMessage       = "message"
 	HdrAuthorization = "Authorization"
+	HdrRedelivered   = "redelivered"
+)
+
+// 订阅的确认模式（SUBSCRIBE 的 ack 头）
+const (
+	AckAuto             = "auto"              // 发送即视为送达，默认
+	AckClient           = "client"            // ACK/NACK 同时确认该订阅此前的全部消息
+	AckClientIndividual = "client-individual" // ACK/NACK 只确认单条消息
 )
 
 // NULL 字符，用于标记帧结束
@@ -0,0 +1,76 @@
+package stomp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 目标通配符，与 Spring 的目标模式一致，路径分隔符为 / 或 .：
+// * 匹配一段（不含分隔符），如 /app/device/*/command；
+// ** 或 # 占据整段时匹配零段或多段，如 /topic/download.#、/topic/**
+const patternChars = "*#"
+
+// IsPattern 目标是否包含通配符
+func IsPattern(destination string) bool {
+	return strings.ContainsAny(destination, patternChars)
+}
+
+// MatchDestination 目标是否与模式匹配，不含通配符时要求完全相同
+func MatchDestination(pattern, destination string) bool {
+	if !IsPattern(pattern) {
+		return pattern == destination
+	}
+	return compilePattern(pattern).MatchString(destination)
+}
+
+// compilePattern 将目标模式转换为正则表达式，分隔符与其他字符按字面匹配
+func compilePattern(pattern string) *regexp.Regexp {
+	var buf strings.Builder
+	buf.WriteString("^")
+
+	for i := 0; i < len(pattern); {
+		// 分隔符后的 ** 或 # 占据整段时，连同分隔符一起匹配零段或多段
+		if isSeparator(pattern[i]) {
+			if n := multiSegment(pattern, i+1); n > 0 {
+				buf.WriteString(`(?:[./].*)?`)
+				i += 1 + n
+				continue
+			}
+		}
+		if n := multiSegment(pattern, i); n > 0 && i == 0 {
+			buf.WriteString(`.*`)
+			i += n
+			continue
+		}
+
+		switch pattern[i] {
+		case '*':
+			if (i == 0 || isSeparator(pattern[i-1])) && (i+1 == len(pattern) || isSeparator(pattern[i+1])) {
+				buf.WriteString(`[^/.]+`)
+			} else {
+				buf.WriteString(`[^/.]*`)
+			}
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+		i++
+	}
+
+	buf.WriteString("$")
+	return regexp.MustCompile(buf.String())
+}
+
+// multiSegment 位置 i 处为占据整段的 ** 或 # 时返回其长度，否则返回 0
+func multiSegment(pattern string, i int) int {
+	for _, token := range []string{"**", "#"} {
+		end := i + len(token)
+		if strings.HasPrefix(pattern[i:], token) && (end == len(pattern) || isSeparator(pattern[end])) {
+			return len(token)
+		}
+	}
+	return 0
+}
+
+func isSeparator(c byte) bool {
+	return c == '/' || c == '.'
+}
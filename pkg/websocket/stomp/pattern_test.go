@@ -0,0 +1,93 @@
+package stomp
+
+import "testing"
+
+func TestMatchDestination(t *testing.T) {
+	cases := []struct {
+		pattern, destination string
+		want                 bool
+	}{
+		{"/topic/notice", "/topic/notice", true},
+		{"/topic/notice", "/topic/notices", false},
+		{"/app/device/*/command", "/app/device/42/command", true},
+		{"/app/device/*/command", "/app/device/command", false},
+		{"/app/device/*/command", "/app/device/a/b/command", false},
+		{"/topic/download.*", "/topic/download.progress", true},
+		{"/topic/download.*", "/topic/download.progress.1", false},
+		{"/topic/download.#", "/topic/download", true},
+		{"/topic/download.#", "/topic/download.progress.1", true},
+		{"/topic/download.#", "/topic/downloads", false},
+		{"/topic/**", "/topic/a/b.c", true},
+		{"/topic/**", "/queue/a", false},
+		{"/topic/job-*", "/topic/job-42", true},
+		{"/topic/job-*", "/topic/job-4/2", false},
+		{"**", "/any/destination", true},
+	}
+
+	for _, c := range cases {
+		if got := MatchDestination(c.pattern, c.destination); got != c.want {
+			t.Errorf("MatchDestination(%q, %q) = %v, want %v", c.pattern, c.destination, got, c.want)
+		}
+	}
+}
+
+func TestPatternHandler(t *testing.T) {
+	b := newTestBroker()
+	var got []string
+	b.RegisterHandler("/app/device/*/command", func(session *Session, destination string, body []byte) {
+		got = append(got, "pattern:"+destination)
+	})
+	b.RegisterHandler("/app/device/gateway/command", func(session *Session, destination string, body []byte) {
+		got = append(got, "exact:"+destination)
+	})
+
+	alice := connect(t, b, "alice")
+	alice.send(b, NewFrame(CmdSend).SetHeader(HdrDestination, "/app/device/42/command"))
+	alice.send(b, NewFrame(CmdSend).SetHeader(HdrDestination, "/app/device/gateway/command"))
+
+	want := []string{"pattern:/app/device/42/command", "exact:/app/device/gateway/command"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("handled %v, want %v", got, want)
+	}
+}
+
+func TestPatternSubscription(t *testing.T) {
+	b := newTestBroker()
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/topic/download.#", AckAuto)
+
+	if !b.HasSubscribers("/topic/download.progress") {
+		t.Fatal("pattern subscription not matched")
+	}
+
+	b.Publish("/topic/download.progress", "50")
+	msg := alice.read(t)
+	if msg.GetHeader(HdrSubscription) != "sub-0" || msg.GetHeader(HdrDestination) != "/topic/download.progress" {
+		t.Fatalf("unexpected headers %v", msg.Headers)
+	}
+
+	b.Publish("/topic/notice", "hello")
+	alice.expectNone(t)
+
+	alice.send(b, NewFrame(CmdUnsubscribe).SetHeader(HdrID, "sub-0"))
+	if b.HasSubscribers("/topic/download.progress") {
+		t.Fatal("pattern subscription still matched after UNSUBSCRIBE")
+	}
+}
+
+func TestSendRejectsPattern(t *testing.T) {
+	b := newTestBroker()
+	handled := false
+	b.RegisterHandler("/app/**", func(session *Session, destination string, body []byte) {
+		handled = true
+	})
+
+	alice := connect(t, b, "alice")
+	alice.send(b, NewFrame(CmdSend).SetHeader(HdrDestination, "/app/device/*/command"))
+	if f := alice.read(t); f.Command != CmdError {
+		t.Fatalf("expected ERROR, got %s", f.Command)
+	}
+	if handled {
+		t.Fatal("wildcard SEND reached handler")
+	}
+}
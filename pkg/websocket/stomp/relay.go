@@ -0,0 +1,93 @@
+package stomp
+
+import "go.uber.org/zap"
+
+// 转发消息类型
+const (
+	RelayPublish   = "publish"   // Publish，发送给订阅了目标的会话
+	RelayBroadcast = "broadcast" // Broadcast，发送给全部已认证的会话
+	RelayUser      = "user"      // SendToUser，发送给用户的全部会话
+	RelayClose     = "close"     // CloseUserSessions，断开用户的全部会话，Body 为原因
+)
+
+// RelayMessage 在实例之间转发的消息
+type RelayMessage struct {
+	Kind        string `json:"kind"`
+	Username    string `json:"username,omitempty"` // RelayUser 的接收用户、RelayClose 断开的用户
+	Destination string `json:"destination,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// Relay 多实例部署时将 Publish / Broadcast / SendToUser / CloseUserSessions 转发到其他实例，
+// 其他实例收到后调用 DeliverRelayed 发送给本实例的会话
+type Relay interface {
+	// Relay 将消息转发到其他实例，不包括本实例
+	Relay(msg RelayMessage) error
+	// IsOnline 用户是否在任一实例上在线，用于决定是否保存离线消息
+	IsOnline(username string) bool
+}
+
+// PresenceRelay 可选接口，用户在本实例上线、下线时立即更新在线状态，
+// 避免定时上报的间隔内发给刚断开用户的消息既未送达也未保存为离线消息
+type PresenceRelay interface {
+	UserOnline(username string)
+	UserOffline(username string)
+}
+
+// notifyPresence 用户在本实例上线或下线时通知转发
+func (b *Broker) notifyPresence(username string, online bool) {
+	presence, ok := b.currentRelay().(PresenceRelay)
+	if !ok {
+		return
+	}
+
+	if online {
+		presence.UserOnline(username)
+	} else {
+		presence.UserOffline(username)
+	}
+}
+
+// SetRelay 设置实例间的消息转发，为 nil 时只发送给本实例的会话
+func (b *Broker) SetRelay(relay Relay) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.relay = relay
+}
+
+func (b *Broker) currentRelay() Relay {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.relay
+}
+
+// forward 将消息转发到其他实例
+func (b *Broker) forward(msg RelayMessage) {
+	relay := b.currentRelay()
+	if relay == nil {
+		return
+	}
+
+	if err := relay.Relay(msg); err != nil {
+		b.logger.Error("Failed to relay message",
+			zap.String("kind", msg.Kind),
+			zap.String("destination", msg.Destination),
+			zap.Error(err))
+	}
+}
+
+// DeliverRelayed 将其他实例转发的消息发送给本实例的会话，不再转发也不保存离线消息
+func (b *Broker) DeliverRelayed(msg RelayMessage) {
+	switch msg.Kind {
+	case RelayPublish:
+		b.PublishLocal(msg.Destination, msg.Body)
+	case RelayBroadcast:
+		b.BroadcastLocal(msg.Destination, msg.Body)
+	case RelayUser:
+		b.sendToUserLocal(msg.Username, msg.Destination, msg.Body)
+	case RelayClose:
+		b.closeUserSessionsLocal(msg.Username, string(msg.Body))
+	default:
+		b.logger.Warn("Unknown relayed message", zap.String("kind", msg.Kind))
+	}
+}
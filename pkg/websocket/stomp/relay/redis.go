@@ -0,0 +1,265 @@
+// Package relay 通过 Redis pub/sub 在多个实例之间转发 STOMP 消息，
+// 使 Publish / Broadcast / SendToUser 能够到达连接在负载均衡后其他实例上的会话
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+const (
+	// DefaultChannel 默认的 Redis 频道，在线状态的键以此为前缀
+	DefaultChannel = "light-admin:stomp"
+	// presenceTTL 实例上报的在线状态有效期，实例异常退出后超过该时间视为离线
+	presenceTTL = 30 * time.Second
+	// presenceInterval 上报在线状态的间隔
+	presenceInterval = 10 * time.Second
+	// publishTimeout 发布单条消息的超时时间
+	publishTimeout = 3 * time.Second
+)
+
+// Options 转发配置
+type Options struct {
+	Channel    string // Redis 频道，同一集群的实例必须相同，默认 DefaultChannel
+	InstanceID string // 实例标识，同一集群内唯一，默认 主机名-进程号
+}
+
+// envelope 频道中的消息，Origin 为发送消息的实例
+type envelope struct {
+	Origin  string             `json:"origin"`
+	Message stomp.RelayMessage `json:"message"`
+}
+
+// RedisRelay 基于 Redis pub/sub 的消息转发
+// 每个实例订阅同一频道，收到其他实例的消息后发送给本实例的会话；
+// 用户在线状态按实例保存在有序集合中，分数为过期时间
+type RedisRelay struct {
+	client   redis.UniversalClient
+	broker   *stomp.Broker
+	logger   *zap.Logger
+	channel  string
+	instance string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// presenceMu 保证定时上报与上线、下线的即时更新依次执行，下线不会被并发的定时上报覆盖
+	presenceMu sync.Mutex
+	online     map[string]struct{} // 已上报的本实例在线用户
+}
+
+// New 创建转发，调用 Start 后生效
+func New(client redis.UniversalClient, broker *stomp.Broker, logger *zap.Logger, opts Options) *RedisRelay {
+	if opts.Channel == "" {
+		opts.Channel = DefaultChannel
+	}
+	if opts.InstanceID == "" {
+		host, _ := os.Hostname()
+		opts.InstanceID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+
+	return &RedisRelay{
+		client:   client,
+		broker:   broker,
+		logger:   logger.With(zap.String("module", "stomp-relay")),
+		channel:  opts.Channel,
+		instance: opts.InstanceID,
+		online:   make(map[string]struct{}),
+	}
+}
+
+// Start 订阅频道并设置为消息代理的转发
+func (r *RedisRelay) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return nil
+	}
+
+	pubsub := r.client.Subscribe(ctx, r.channel)
+	// 等待订阅确认，确保 Start 返回后不会漏掉其他实例的消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe %s: %w", r.channel, err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.receive(runCtx, pubsub)
+	r.broker.SetRelay(r)
+
+	r.logger.Info("STOMP relay started",
+		zap.String("channel", r.channel),
+		zap.String("instance", r.instance))
+	return nil
+}
+
+// Close 停止转发并清除本实例上报的在线状态
+func (r *RedisRelay) Close() error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel = nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	r.broker.SetRelay(nil)
+	cancel()
+	<-done
+
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancelTimeout()
+	r.presenceMu.Lock()
+	defer r.presenceMu.Unlock()
+	pipe := r.client.Pipeline()
+	for username := range r.online {
+		pipe.ZRem(ctx, r.presenceKey(username), r.instance)
+	}
+	r.online = make(map[string]struct{})
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Relay 发布消息到频道
+func (r *RedisRelay) Relay(msg stomp.RelayMessage) error {
+	payload, err := json.Marshal(envelope{Origin: r.instance, Message: msg})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	return r.client.Publish(ctx, r.channel, payload).Err()
+}
+
+// IsOnline 用户是否在任一实例上在线，查询失败时视为在线，避免重复保存离线消息
+func (r *RedisRelay) IsOnline(username string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	now := fmt.Sprintf("%d", time.Now().UnixMilli())
+	count, err := r.client.ZCount(ctx, r.presenceKey(username), now, "+inf").Result()
+	if err != nil {
+		r.logger.Warn("Failed to query presence", zap.String("username", username), zap.Error(err))
+		return true
+	}
+	return count > 0
+}
+
+// UserOnline 用户在本实例上线时立即上报
+func (r *RedisRelay) UserOnline(username string) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	r.presenceMu.Lock()
+	defer r.presenceMu.Unlock()
+
+	key := r.presenceKey(username)
+	pipe := r.client.Pipeline()
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(time.Now().Add(presenceTTL).UnixMilli()), Member: r.instance})
+	pipe.Expire(ctx, key, presenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.logger.Warn("Failed to report presence", zap.String("username", username), zap.Error(err))
+		return
+	}
+	r.online[username] = struct{}{}
+}
+
+// UserOffline 用户在本实例下线时立即移除在线状态，发给该用户的消息随即保存为离线消息
+func (r *RedisRelay) UserOffline(username string) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	r.presenceMu.Lock()
+	defer r.presenceMu.Unlock()
+
+	delete(r.online, username)
+	if err := r.client.ZRem(ctx, r.presenceKey(username), r.instance).Err(); err != nil {
+		r.logger.Warn("Failed to clear presence", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// receive 接收其他实例的消息，并定时上报本实例的在线用户
+func (r *RedisRelay) receive(ctx context.Context, pubsub *redis.PubSub) {
+	defer close(r.done)
+	defer pubsub.Close()
+
+	ticker := time.NewTicker(presenceInterval)
+	defer ticker.Stop()
+	r.reportPresence(ctx)
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.reportPresence(ctx)
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			r.handle([]byte(msg.Payload))
+		}
+	}
+}
+
+// handle 将其他实例的消息发送给本实例的会话，忽略本实例发出的消息
+func (r *RedisRelay) handle(payload []byte) {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		r.logger.Warn("Invalid relayed message", zap.Error(err))
+		return
+	}
+	if env.Origin == r.instance {
+		return
+	}
+
+	r.broker.DeliverRelayed(env.Message)
+}
+
+// reportPresence 上报本实例的在线用户，已离线的用户立即移除
+func (r *RedisRelay) reportPresence(ctx context.Context) {
+	r.presenceMu.Lock()
+	defer r.presenceMu.Unlock()
+
+	expireAt := float64(time.Now().Add(presenceTTL).UnixMilli())
+	online := make(map[string]struct{})
+
+	pipe := r.client.Pipeline()
+	for _, user := range r.broker.GetOnlineUsers() {
+		key := r.presenceKey(user.Username)
+		online[user.Username] = struct{}{}
+		pipe.ZAdd(ctx, key, &redis.Z{Score: expireAt, Member: r.instance})
+		pipe.Expire(ctx, key, presenceTTL)
+	}
+	for username := range r.online {
+		if _, ok := online[username]; !ok {
+			pipe.ZRem(ctx, r.presenceKey(username), r.instance)
+		}
+	}
+	r.online = online
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil && ctx.Err() == nil {
+		r.logger.Warn("Failed to report presence", zap.Error(err))
+	}
+}
+
+func (r *RedisRelay) presenceKey(username string) string {
+	return r.channel + ":online:" + username
+}
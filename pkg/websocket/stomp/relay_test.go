@@ -0,0 +1,136 @@
+package stomp
+
+import "testing"
+
+// linkRelay 将消息直接转发给另一个实例的消息代理
+type linkRelay struct {
+	peer   *Broker
+	online bool
+}
+
+func (r *linkRelay) Relay(msg RelayMessage) error {
+	r.peer.DeliverRelayed(msg)
+	return nil
+}
+
+func (r *linkRelay) IsOnline(username string) bool {
+	return r.online && r.peer.IsUserOnlineLocal(username)
+}
+
+func TestRelayAcrossInstances(t *testing.T) {
+	a, b := newTestBroker(), newTestBroker()
+	a.SetRelay(&linkRelay{peer: b, online: true})
+	b.SetRelay(&linkRelay{peer: a, online: true})
+	store := &memoryStore{}
+	a.SetMessageStore(store)
+
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/topic/notice", AckAuto)
+	alice.subscribe(b, "sub-1", "/user/queue/messages", AckAuto)
+
+	a.Publish("/topic/notice", "notice")
+	if msg := alice.read(t); string(msg.Body) != "notice" || msg.GetHeader(HdrSubscription) != "sub-0" {
+		t.Fatalf("unexpected message %v %q", msg.Headers, msg.Body)
+	}
+
+	// 用户在其他实例上在线时不保存离线消息
+	a.SendToUser("alice", "/queue/messages", "hello")
+	if msg := alice.read(t); string(msg.Body) != "hello" || msg.GetHeader(HdrSubscription) != "sub-1" {
+		t.Fatalf("unexpected message %v %q", msg.Headers, msg.Body)
+	}
+	if store.count("alice") != 0 {
+		t.Fatalf("stored %d messages for online user", store.count("alice"))
+	}
+
+	a.SendToUser("bob", "/queue/messages", "hello")
+	if store.count("bob") != 1 {
+		t.Fatalf("stored %d messages for offline user, want 1", store.count("bob"))
+	}
+}
+
+func TestPublishLocalNotRelayed(t *testing.T) {
+	a, b := newTestBroker(), newTestBroker()
+	a.SetRelay(&linkRelay{peer: b})
+
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/topic/metrics", AckAuto)
+
+	a.PublishLocal("/topic/metrics", "a")
+	a.BroadcastLocal("/topic/online-count", 1)
+	alice.expectNone(t)
+}
+
+func TestListenerNotifiedAcrossInstances(t *testing.T) {
+	a, b := newTestBroker(), newTestBroker()
+	a.SetRelay(&linkRelay{peer: b})
+
+	var gotA, gotB []string
+	a.RegisterListener("/topic/dict", func(destination string, body []byte) {
+		gotA = append(gotA, string(body))
+	})
+	b.RegisterListener("/topic/dict", func(destination string, body []byte) {
+		gotB = append(gotB, string(body))
+	})
+
+	a.Publish("/topic/dict", map[string]string{"dictCode": "gender"})
+	a.Publish("/topic/notice", "notice")
+
+	want := `{"dictCode":"gender"}`
+	if len(gotA) != 1 || gotA[0] != want {
+		t.Fatalf("local listener got %v, want [%s]", gotA, want)
+	}
+	if len(gotB) != 1 || gotB[0] != want {
+		t.Fatalf("relayed listener got %v, want [%s]", gotB, want)
+	}
+}
+
+// presenceRelay 记录上线、下线通知，其他实例的在线用户由 remote 指定
+type presenceRelay struct {
+	remote map[string]bool
+	events []string
+}
+
+func (r *presenceRelay) Relay(msg RelayMessage) error { return nil }
+
+func (r *presenceRelay) IsOnline(username string) bool { return r.remote[username] }
+
+func (r *presenceRelay) UserOnline(username string) {
+	r.events = append(r.events, "+"+username)
+}
+
+func (r *presenceRelay) UserOffline(username string) {
+	r.events = append(r.events, "-"+username)
+}
+
+func TestPresenceNotifiedOnConnectAndDisconnect(t *testing.T) {
+	b := newTestBroker()
+	relay := &presenceRelay{remote: map[string]bool{"bob": true}}
+	b.SetRelay(relay)
+
+	first := connect(t, b, "alice")
+	second := connect(t, b, "alice")
+	stream := NewStream("stream-alice", "alice", []string{"/topic/notice"})
+	b.AddStream(stream)
+
+	b.RemoveSession(first.ID)
+	b.RemoveSession(second.ID)
+	if len(relay.events) != 1 || relay.events[0] != "+alice" {
+		t.Fatalf("events %v, want [+alice]", relay.events)
+	}
+
+	b.RemoveStream(stream.ID)
+	if len(relay.events) != 2 || relay.events[1] != "-alice" {
+		t.Fatalf("events %v, want [+alice -alice]", relay.events)
+	}
+
+	// 其他实例上在线的用户与订阅者
+	if !b.IsUserOnline("bob") || b.IsUserOnlineLocal("bob") {
+		t.Fatal("expected bob to be online on another instance only")
+	}
+	if b.IsUserOnline("alice") {
+		t.Fatal("expected alice to be offline")
+	}
+	if !b.HasSubscribers("/topic/metrics") {
+		t.Fatal("expected subscribers to be assumed on other instances")
+	}
+}
@@ -0,0 +1,116 @@
+package stomp
+
+import (
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// SessionInfo 会话信息，用于管理接口
+type SessionInfo struct {
+	ID            string             `json:"id"`
+	Username      string             `json:"username"`
+	RemoteIP      string             `json:"remoteIp"`
+	ConnectTime   int64              `json:"connectTime"`
+	Authenticated bool               `json:"authenticated"`
+	Subscriptions []SubscriptionInfo `json:"subscriptions"`
+	Pending       int                `json:"pending"` // 等待确认的消息数
+}
+
+// SubscriptionInfo 订阅信息
+type SubscriptionInfo struct {
+	ID          string `json:"id"`
+	Destination string `json:"destination"`
+	Ack         string `json:"ack"`
+}
+
+// Info 会话的当前状态，订阅按 ID 排序
+func (s *Session) Info() SessionInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info := SessionInfo{
+		ID:            s.ID,
+		Username:      s.Username,
+		RemoteIP:      s.RemoteIP,
+		ConnectTime:   s.ConnectTime,
+		Authenticated: s.Authenticated,
+		Subscriptions: make([]SubscriptionInfo, 0, len(s.Subscriptions)),
+		Pending:       len(s.pending),
+	}
+	for id, dest := range s.Subscriptions {
+		ack, ok := s.ackModes[id]
+		if !ok {
+			ack = AckAuto
+		}
+		info.Subscriptions = append(info.Subscriptions, SubscriptionInfo{ID: id, Destination: dest, Ack: ack})
+	}
+	sort.Slice(info.Subscriptions, func(i, j int) bool {
+		return info.Subscriptions[i].ID < info.Subscriptions[j].ID
+	})
+
+	return info
+}
+
+// GetSessions 本实例的全部会话（包括未认证的），按连接时间排序
+func (b *Broker) GetSessions() []SessionInfo {
+	b.mu.RLock()
+	sessions := make([]*Session, 0, len(b.sessions))
+	for _, session := range b.sessions {
+		sessions = append(sessions, session)
+	}
+	b.mu.RUnlock()
+
+	list := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, session.Info())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ConnectTime != list[j].ConnectTime {
+			return list[i].ConnectTime < list[j].ConnectTime
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// CloseSession 发送 ERROR 帧后断开会话，会话不存在时返回 false
+// 连接关闭后读取循环退出并移除会话
+func (b *Broker) CloseSession(sessionID, reason string) bool {
+	session := b.GetSession(sessionID)
+	if session == nil {
+		return false
+	}
+
+	b.closeSession(session, reason)
+	return true
+}
+
+// CloseUserSessions 断开用户在全部实例上的会话与推送通道，返回本实例断开的数量
+// 用于禁用、删除用户后强制下线
+func (b *Broker) CloseUserSessions(username, reason string) int {
+	n := b.closeUserSessionsLocal(username, reason)
+	b.forward(RelayMessage{Kind: RelayClose, Username: username, Body: []byte(reason)})
+	return n
+}
+
+func (b *Broker) closeUserSessionsLocal(username, reason string) int {
+	sessions := b.GetUserSessions(username)
+	for _, session := range sessions {
+		b.closeSession(session, reason)
+	}
+	return len(sessions) + b.closeUserStreamsLocal(username, reason)
+}
+
+func (b *Broker) closeSession(session *Session, reason string) {
+	b.sendError(session, reason)
+	if session.Conn != nil {
+		_ = session.Conn.Close()
+	}
+
+	b.logger.Info("Session closed by server",
+		zap.String("sessionID", session.ID),
+		zap.String("username", session.Username),
+		zap.String("reason", reason))
+}
@@ -0,0 +1,50 @@
+package stomp
+
+import "testing"
+
+func TestCloseSession(t *testing.T) {
+	b := newTestBroker()
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-1", "/topic/notice", AckClient)
+	alice.subscribe(b, "sub-0", "/user/queue/messages", AckAuto)
+
+	sessions := b.GetSessions()
+	if len(sessions) != 1 || sessions[0].Username != "alice" || len(sessions[0].Subscriptions) != 2 {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+	if sub := sessions[0].Subscriptions[1]; sub.ID != "sub-1" || sub.Destination != "/topic/notice" || sub.Ack != AckClient {
+		t.Fatalf("unexpected subscription %+v", sub)
+	}
+
+	if b.CloseSession("missing", "kicked") {
+		t.Fatal("closed a missing session")
+	}
+	if !b.CloseSession(alice.ID, "kicked") {
+		t.Fatal("session not found")
+	}
+	if f := alice.read(t); f.Command != CmdError || f.GetHeader(HdrMessage) != "kicked" {
+		t.Fatalf("expected ERROR, got %s %v", f.Command, f.Headers)
+	}
+	if _, _, err := alice.client.ReadMessage(); err == nil {
+		t.Fatal("connection still open")
+	}
+}
+
+func TestCloseUserSessionsAcrossInstances(t *testing.T) {
+	a, b := newTestBroker(), newTestBroker()
+	a.SetRelay(&linkRelay{peer: b})
+
+	phone := connect(t, a, "alice")
+	laptop := connect(t, b, "alice")
+	bob := connect(t, a, "bob")
+
+	if n := a.CloseUserSessions("alice", "Account disabled"); n != 1 {
+		t.Fatalf("closed %d local sessions, want 1", n)
+	}
+	for _, s := range []*testSession{phone, laptop} {
+		if f := s.read(t); f.Command != CmdError || f.GetHeader(HdrMessage) != "Account disabled" {
+			t.Fatalf("expected ERROR, got %s %v", f.Command, f.Headers)
+		}
+	}
+	bob.expectNone(t)
+}
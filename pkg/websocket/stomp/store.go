@@ -0,0 +1,82 @@
+package stomp
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// StoredMessage 保存的离线消息，Destination 为用户队列目标，如 /queue/messages
+type StoredMessage struct {
+	Destination string
+	Body        []byte
+}
+
+// MessageStore 离线消息存储，过期时间与每个用户保留的消息数由实现决定
+type MessageStore interface {
+	// Save 保存发送给离线用户的消息
+	Save(username, destination string, body []byte) error
+	// Take 取出并删除用户目标满足 match 的消息，按保存顺序返回
+	Take(username string, match func(destination string) bool) ([]StoredMessage, error)
+}
+
+// SetMessageStore 设置离线消息存储，为 nil 时发送给离线用户的消息直接丢弃
+func (b *Broker) SetMessageStore(store MessageStore) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.store = store
+}
+
+func (b *Broker) messageStore() MessageStore {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.store
+}
+
+// storeMessage 保存发送给离线用户的消息，destination 为用户队列目标
+func (b *Broker) storeMessage(username, destination string, body []byte) bool {
+	store := b.messageStore()
+	if store == nil {
+		return false
+	}
+
+	if err := store.Save(username, destination, body); err != nil {
+		b.logger.Error("Failed to store offline message",
+			zap.String("username", username),
+			zap.String("destination", destination),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// deliverStored 会话订阅用户队列后投递匹配的离线消息
+// 离线消息在订阅时而不是认证时投递，避免客户端尚未订阅时收到的消息被丢弃
+func (b *Broker) deliverStored(session *Session, subscription string) {
+	if !strings.HasPrefix(subscription, "/user/") {
+		return
+	}
+	store := b.messageStore()
+	if store == nil {
+		return
+	}
+
+	msgs, err := store.Take(session.Username, func(destination string) bool {
+		return MatchDestination(subscription, "/user"+destination)
+	})
+	if err != nil {
+		b.logger.Error("Failed to load offline messages",
+			zap.String("username", session.Username),
+			zap.Error(err))
+		return
+	}
+
+	for _, msg := range msgs {
+		if err := b.deliver(session, "/user/"+session.Username+msg.Destination, msg.Body, 1); err != nil {
+			b.logger.Error("Failed to deliver offline message",
+				zap.String("sessionID", session.ID),
+				zap.String("destination", msg.Destination),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package stomp
+
+import (
+	"sync"
+	"testing"
+)
+
+// memoryStore 内存离线消息存储
+type memoryStore struct {
+	mu    sync.Mutex
+	saved map[string][]StoredMessage
+}
+
+func (s *memoryStore) Save(username, destination string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saved == nil {
+		s.saved = make(map[string][]StoredMessage)
+	}
+	s.saved[username] = append(s.saved[username], StoredMessage{Destination: destination, Body: body})
+	return nil
+}
+
+func (s *memoryStore) Take(username string, match func(destination string) bool) ([]StoredMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var taken, rest []StoredMessage
+	for _, msg := range s.saved[username] {
+		if match(msg.Destination) {
+			taken = append(taken, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	s.saved[username] = rest
+	return taken, nil
+}
+
+func (s *memoryStore) count(username string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.saved[username])
+}
+
+func TestOfflineMessages(t *testing.T) {
+	b := newTestBroker()
+	store := &memoryStore{}
+	b.SetMessageStore(store)
+
+	b.SendToUser("alice", "/queue/messages", "first")
+	b.SendToUser("alice", "/queue/messages", map[string]int{"n": 2})
+	b.SendToUser("alice", "/queue/downloads", "done")
+	if store.count("alice") != 3 {
+		t.Fatalf("stored %d messages, want 3", store.count("alice"))
+	}
+
+	// 订阅对应队列后按保存顺序投递
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/user/queue/messages", AckAuto)
+
+	for _, want := range []string{"first", `{"n":2}`} {
+		msg := alice.read(t)
+		if string(msg.Body) != want || msg.GetHeader(HdrSubscription) != "sub-0" ||
+			msg.GetHeader(HdrDestination) != "/user/alice/queue/messages" {
+			t.Fatalf("unexpected message %v %q", msg.Headers, msg.Body)
+		}
+	}
+	if store.count("alice") != 1 {
+		t.Fatalf("%d messages left, want 1", store.count("alice"))
+	}
+}
+
+func TestStoreUnackedOnDisconnect(t *testing.T) {
+	b := newTestBroker()
+	store := &memoryStore{}
+	b.SetMessageStore(store)
+
+	alice := connect(t, b, "alice")
+	alice.subscribe(b, "sub-0", "/user/queue/messages", AckClientIndividual)
+	alice.subscribe(b, "sub-1", "/topic/notice", AckClientIndividual)
+	b.SendToUser("alice", "/queue/messages", "hello")
+	b.Publish("/topic/notice", "notice")
+	alice.read(t)
+	alice.read(t)
+
+	// 用户队列中未确认的消息保存为离线消息，主题消息丢弃
+	b.RemoveSession(alice.ID)
+	if store.count("alice") != 1 {
+		t.Fatalf("stored %d messages, want 1", store.count("alice"))
+	}
+
+	again := connect(t, b, "alice")
+	again.subscribe(b, "sub-0", "/user/queue/**", AckAuto)
+	if msg := again.read(t); string(msg.Body) != "hello" {
+		t.Fatalf("unexpected message %q", msg.Body)
+	}
+}
@@ -0,0 +1,305 @@
+package stomp
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// DefaultStreamBuffer 推送通道的缓冲消息数，缓冲满时丢弃新消息，避免慢客户端阻塞发布方
+const DefaultStreamBuffer = 64
+
+// StreamMessage 推送通道收到的消息，Destination 与 STOMP 客户端看到的目标一致，
+// 用户队列为 /user/queue/*
+type StreamMessage struct {
+	Destination string
+	Body        []byte
+}
+
+// Stream 不经过 STOMP 协议的单向推送通道（如 Server-Sent Events），
+// 按创建时给定的目标接收与会话相同的 Publish / Broadcast / SendToUser 消息
+type Stream struct {
+	ID           string
+	Username     string
+	RemoteIP     string
+	ConnectTime  int64
+	Destinations []string // 接收的目标，可以包含通配符
+
+	messages  chan StreamMessage
+	done      chan struct{}
+	closeOnce sync.Once
+	reason    string
+}
+
+// NewStream 创建推送通道，创建后通过 Broker.AddStream 注册
+func NewStream(id, username string, destinations []string) *Stream {
+	return &Stream{
+		ID:           id,
+		Username:     username,
+		Destinations: destinations,
+		messages:     make(chan StreamMessage, DefaultStreamBuffer),
+		done:         make(chan struct{}),
+	}
+}
+
+// Messages 待发送的消息
+func (s *Stream) Messages() <-chan StreamMessage {
+	return s.messages
+}
+
+// Done 通道被服务端关闭时关闭，如用户被禁用、模块被停用
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// CloseReason 服务端关闭通道的原因
+func (s *Stream) CloseReason() string {
+	select {
+	case <-s.done:
+		return s.reason
+	default:
+		return ""
+	}
+}
+
+// Close 关闭通道，连接方收到 Done 后结束响应
+func (s *Stream) Close(reason string) {
+	s.closeOnce.Do(func() {
+		s.reason = reason
+		close(s.done)
+	})
+}
+
+// Matches 通道是否接收发往 destination 的消息
+func (s *Stream) Matches(destination string) bool {
+	for _, pattern := range s.Destinations {
+		if MatchDestination(pattern, destination) {
+			return true
+		}
+	}
+	return false
+}
+
+// offer 放入消息，缓冲满或通道已关闭时丢弃并返回 false
+func (s *Stream) offer(destination string, body []byte) bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+	}
+
+	select {
+	case s.messages <- StreamMessage{Destination: destination, Body: body}:
+		return true
+	default:
+		return false
+	}
+}
+
+// session 以会话的形式提供给快照函数与授权函数
+func (s *Stream) session() *Session {
+	return &Session{
+		ID:            s.ID,
+		Username:      s.Username,
+		Subscriptions: make(map[string]string),
+		RemoteIP:      s.RemoteIP,
+		ConnectTime:   s.ConnectTime,
+		Authenticated: true,
+	}
+}
+
+// AddStream 注册推送通道，并推送所接收目标的快照与用户队列的离线消息
+func (b *Broker) AddStream(stream *Stream) {
+	b.mu.Lock()
+	wasOnline := b.userOnlineLocked(stream.Username)
+	b.streams[stream.ID] = stream
+	b.mu.Unlock()
+
+	if !wasOnline {
+		b.notifyPresence(stream.Username, true)
+	}
+
+	b.logger.Info("Stream added",
+		zap.String("streamID", stream.ID),
+		zap.String("username", stream.Username),
+		zap.Strings("destinations", stream.Destinations))
+
+	for _, destination := range stream.Destinations {
+		b.sendStreamSnapshot(stream, destination)
+		b.sendStreamStored(stream, destination)
+	}
+}
+
+// RemoveStream 移除推送通道
+func (b *Broker) RemoveStream(streamID string) {
+	b.mu.Lock()
+	stream, ok := b.streams[streamID]
+	delete(b.streams, streamID)
+	offline := ok && !b.userOnlineLocked(stream.Username)
+	b.mu.Unlock()
+
+	if ok {
+		stream.Close("")
+		b.logger.Info("Stream removed",
+			zap.String("streamID", streamID),
+			zap.String("username", stream.Username))
+	}
+	if offline {
+		b.notifyPresence(stream.Username, false)
+	}
+}
+
+// AuthorizeStream 按会话的授权函数过滤通道接收的目标，返回被拒绝的目标
+func (b *Broker) AuthorizeStream(stream *Stream) []string {
+	if b.authorizer == nil {
+		return nil
+	}
+
+	session := stream.session()
+	allowed := stream.Destinations[:0]
+	var denied []string
+	for _, destination := range stream.Destinations {
+		if err := b.authorizer(session, destination); err != nil {
+			b.logger.Warn("Stream destination denied",
+				zap.String("streamID", stream.ID),
+				zap.String("username", stream.Username),
+				zap.String("destination", destination),
+				zap.Error(err))
+			denied = append(denied, destination)
+			continue
+		}
+		allowed = append(allowed, destination)
+	}
+	stream.Destinations = allowed
+	return denied
+}
+
+// sendStreamSnapshot 向刚注册的通道推送快照，通配符目标推送全部匹配目标的快照
+func (b *Broker) sendStreamSnapshot(stream *Stream, destination string) {
+	b.mu.RLock()
+	providers := make(map[string]SnapshotProvider)
+	for dest, provider := range b.snapshots {
+		if MatchDestination(destination, dest) {
+			providers[dest] = provider
+		}
+	}
+	b.mu.RUnlock()
+
+	for dest, provider := range providers {
+		snapshot, ok := provider(stream.session(), dest)
+		if !ok {
+			continue
+		}
+		body, err := marshalBody(snapshot)
+		if err != nil {
+			b.logger.Error("Failed to marshal snapshot",
+				zap.String("streamID", stream.ID),
+				zap.String("destination", dest),
+				zap.Error(err))
+			continue
+		}
+		stream.offer(dest, body)
+	}
+}
+
+// sendStreamStored 投递用户队列中匹配的离线消息，与会话订阅用户队列时一致
+func (b *Broker) sendStreamStored(stream *Stream, destination string) {
+	if !strings.HasPrefix(destination, "/user/") {
+		return
+	}
+	store := b.messageStore()
+	if store == nil {
+		return
+	}
+
+	msgs, err := store.Take(stream.Username, func(dest string) bool {
+		return MatchDestination(destination, "/user"+dest)
+	})
+	if err != nil {
+		b.logger.Error("Failed to load offline messages",
+			zap.String("username", stream.Username),
+			zap.Error(err))
+		return
+	}
+	for _, msg := range msgs {
+		stream.offer("/user"+msg.Destination, msg.Body)
+	}
+}
+
+// streamsFor 接收目标的推送通道，username 不为空时只返回该用户的通道
+func (b *Broker) streamsFor(username, destination string) []*Stream {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	streams := make([]*Stream, 0)
+	for _, stream := range b.streams {
+		if username != "" && stream.Username != username {
+			continue
+		}
+		if stream.Matches(destination) {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// publishStreams 发送给接收目标的全部推送通道
+func (b *Broker) publishStreams(destination string, body interface{}) {
+	streams := b.streamsFor("", destination)
+	if len(streams) == 0 {
+		return
+	}
+
+	bodyBytes, err := marshalBody(body)
+	if err != nil {
+		b.logger.Error("Failed to marshal message",
+			zap.String("destination", destination),
+			zap.Error(err))
+		return
+	}
+	for _, stream := range streams {
+		if !stream.offer(destination, bodyBytes) {
+			b.logger.Warn("Stream buffer full, dropping message",
+				zap.String("streamID", stream.ID),
+				zap.String("destination", destination))
+		}
+	}
+}
+
+// sendToUserStreams 发送给用户接收该队列的推送通道，目标为 /user/queue/*
+// 用户在本实例上有推送通道时返回 true
+func (b *Broker) sendToUserStreams(username, destination string, body []byte) bool {
+	userDestination := "/user" + destination
+	streams := b.streamsFor(username, userDestination)
+	for _, stream := range streams {
+		if !stream.offer(userDestination, body) {
+			b.logger.Warn("Stream buffer full, dropping message",
+				zap.String("streamID", stream.ID),
+				zap.String("destination", userDestination))
+		}
+	}
+	return len(streams) > 0
+}
+
+// hasStreams 是否有推送通道接收目标
+func (b *Broker) hasStreams(destination string) bool {
+	return len(b.streamsFor("", destination)) > 0
+}
+
+// closeUserStreamsLocal 关闭用户在本实例上的推送通道
+func (b *Broker) closeUserStreamsLocal(username, reason string) int {
+	b.mu.RLock()
+	streams := make([]*Stream, 0)
+	for _, stream := range b.streams {
+		if stream.Username == username {
+			streams = append(streams, stream)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, stream := range streams {
+		stream.Close(reason)
+	}
+	return len(streams)
+}
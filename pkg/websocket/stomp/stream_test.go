@@ -0,0 +1,187 @@
+package stomp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// readStream 读取推送通道的下一条消息
+func readStream(t *testing.T, s *Stream) StreamMessage {
+	t.Helper()
+
+	select {
+	case msg := <-s.Messages():
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no stream message")
+		return StreamMessage{}
+	}
+}
+
+func expectNoStream(t *testing.T, s *Stream) {
+	t.Helper()
+
+	select {
+	case msg := <-s.Messages():
+		t.Fatalf("unexpected stream message %s %q", msg.Destination, msg.Body)
+	default:
+	}
+}
+
+func TestStreamReceivesTopicsAndUserQueues(t *testing.T) {
+	b := newTestBroker()
+	b.RegisterSnapshot("/topic/downloads", func(session *Session, destination string) (interface{}, bool) {
+		return "snapshot for " + session.Username, true
+	})
+
+	alice := NewStream("s-1", "alice", []string{"/topic/dict", "/topic/downloads", "/user/queue/messages"})
+	b.AddStream(alice)
+	bob := NewStream("s-2", "bob", []string{"/topic/**"})
+	b.AddStream(bob)
+
+	if msg := readStream(t, alice); msg.Destination != "/topic/downloads" || string(msg.Body) != "snapshot for alice" {
+		t.Fatalf("unexpected snapshot %s %q", msg.Destination, msg.Body)
+	}
+	readStream(t, bob)
+
+	b.Publish("/topic/dict", map[string]string{"dictCode": "gender"})
+	if msg := readStream(t, alice); msg.Destination != "/topic/dict" || string(msg.Body) != `{"dictCode":"gender"}` {
+		t.Fatalf("unexpected message %s %q", msg.Destination, msg.Body)
+	}
+	readStream(t, bob)
+
+	// 用户队列只发送给该用户，目标带 /user 前缀
+	b.SendToUser("alice", "/queue/messages", "hello")
+	if msg := readStream(t, alice); msg.Destination != "/user/queue/messages" || string(msg.Body) != "hello" {
+		t.Fatalf("unexpected message %s %q", msg.Destination, msg.Body)
+	}
+	expectNoStream(t, bob)
+
+	// 未接收的目标不发送
+	b.Broadcast("/topic/banner", "banner")
+	expectNoStream(t, alice)
+	readStream(t, bob)
+
+	if !b.IsUserOnline("alice") || b.GetOnlineUserCount() != 2 || !b.HasSubscribers("/topic/dict") {
+		t.Fatal("streams not counted as online")
+	}
+
+	b.RemoveStream(alice.ID)
+	if b.IsUserOnline("alice") {
+		t.Fatal("alice still online")
+	}
+}
+
+func TestStreamOfflineMessages(t *testing.T) {
+	b := newTestBroker()
+	store := &memoryStore{}
+	b.SetMessageStore(store)
+
+	b.SendToUser("alice", "/queue/messages", "offline")
+	alice := NewStream("s-1", "alice", []string{"/user/queue/messages"})
+	b.AddStream(alice)
+	if msg := readStream(t, alice); msg.Destination != "/user/queue/messages" || string(msg.Body) != "offline" {
+		t.Fatalf("unexpected message %s %q", msg.Destination, msg.Body)
+	}
+
+	// 有推送通道时视为在线，不再保存离线消息
+	b.SendToUser("alice", "/queue/messages", "hello")
+	readStream(t, alice)
+	if n := store.count("alice"); n != 0 {
+		t.Fatalf("stored %d messages for a streaming user", n)
+	}
+}
+
+func TestAuthorizeStream(t *testing.T) {
+	b := newTestBroker()
+	b.SetAuthorizer(func(session *Session, destination string) error {
+		if destination == "/topic/downloads" && session.Username != "admin" {
+			return errors.New("missing permission")
+		}
+		return nil
+	})
+
+	alice := NewStream("s-1", "alice", []string{"/topic/dict", "/topic/downloads"})
+	denied := b.AuthorizeStream(alice)
+	if len(denied) != 1 || denied[0] != "/topic/downloads" {
+		t.Fatalf("denied = %v", denied)
+	}
+	if len(alice.Destinations) != 1 || alice.Destinations[0] != "/topic/dict" {
+		t.Fatalf("destinations = %v", alice.Destinations)
+	}
+
+	// 被拒绝的目标不再接收消息
+	b.AddStream(alice)
+	b.Publish("/topic/downloads", "progress")
+	expectNoStream(t, alice)
+	b.Publish("/topic/dict", "gender")
+	readStream(t, alice)
+
+	admin := NewStream("s-2", "admin", []string{"/topic/dict", "/topic/downloads"})
+	if denied := b.AuthorizeStream(admin); len(denied) != 0 || len(admin.Destinations) != 2 {
+		t.Fatalf("admin denied = %v, destinations = %v", denied, admin.Destinations)
+	}
+
+	// 全部目标被拒绝时没有可接收的目标
+	bob := NewStream("s-3", "bob", []string{"/topic/downloads"})
+	if denied := b.AuthorizeStream(bob); len(denied) != 1 || len(bob.Destinations) != 0 {
+		t.Fatalf("bob denied = %v, destinations = %v", denied, bob.Destinations)
+	}
+}
+
+func TestStreamUserQueueIsolation(t *testing.T) {
+	b := newTestBroker()
+	store := &memoryStore{}
+	b.SetMessageStore(store)
+
+	// 离线消息只投递给所属用户
+	b.SendToUser("bob", "/queue/messages", "offline for bob")
+	alice := NewStream("s-1", "alice", []string{"/user/queue/messages", "/user/**", "/**"})
+	b.AddStream(alice)
+	expectNoStream(t, alice)
+	if n := store.count("bob"); n != 1 {
+		t.Fatalf("stored %d messages for bob, want 1", n)
+	}
+
+	bob := NewStream("s-2", "bob", []string{"/user/queue/messages"})
+	b.AddStream(bob)
+	if msg := readStream(t, bob); string(msg.Body) != "offline for bob" {
+		t.Fatalf("unexpected message %s %q", msg.Destination, msg.Body)
+	}
+
+	// 其他用户的队列即使匹配通配符目标也不发送
+	b.SendToUser("bob", "/queue/messages", "hello bob")
+	if msg := readStream(t, bob); msg.Destination != "/user/queue/messages" || string(msg.Body) != "hello bob" {
+		t.Fatalf("unexpected message %s %q", msg.Destination, msg.Body)
+	}
+	expectNoStream(t, alice)
+
+	b.SendToUser("alice", "/queue/messages", "hello alice")
+	if msg := readStream(t, alice); string(msg.Body) != "hello alice" {
+		t.Fatalf("unexpected message %s %q", msg.Destination, msg.Body)
+	}
+	expectNoStream(t, alice)
+	expectNoStream(t, bob)
+}
+
+func TestCloseUserSessionsClosesStreams(t *testing.T) {
+	b := newTestBroker()
+	alice := NewStream("s-1", "alice", []string{"/topic/dict"})
+	b.AddStream(alice)
+
+	if n := b.CloseUserSessions("alice", "Account disabled"); n != 1 {
+		t.Fatalf("closed %d, want 1", n)
+	}
+	select {
+	case <-alice.Done():
+	default:
+		t.Fatal("stream not closed")
+	}
+	if alice.CloseReason() != "Account disabled" {
+		t.Fatalf("reason = %q", alice.CloseReason())
+	}
+
+	b.Publish("/topic/dict", "gender")
+	expectNoStream(t, alice)
+}
@@ -0,0 +1,535 @@
+// Package stompclient 管理后台 STOMP 端点（/ws）的 Go 客户端
+// 供内部 Go 服务和集成测试订阅后台推送的主题，无需自行拼装和解析帧
+//
+//	client, err := stompclient.Dial(ctx, stompclient.Options{URL: "ws://127.0.0.1:2222/ws", Token: token})
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//
+//	client.Subscribe(websocket.TopicOnlineCount, stompclient.Typed(func(n int, _ *stompclient.Message) error {
+//		log.Println("online:", n)
+//		return nil
+//	}))
+//
+// 连接断开后按指数退避自动重连，重连成功后重新发送全部订阅
+package stompclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+// 模块标识，用于日志
+const moduleTag = "stompclient"
+
+var (
+	// ErrClosed 客户端已关闭
+	ErrClosed = errors.New("stompclient: client closed")
+	// ErrNotConnected 当前未连接（正在重连）
+	ErrNotConnected = errors.New("stompclient: not connected")
+)
+
+// Options 客户端选项
+type Options struct {
+	URL    string      // 如 ws://127.0.0.1:2222/ws
+	Token  string      // 登录后获得的 access token，握手和 CONNECT 帧都会携带
+	Header http.Header // 握手时附加的请求头
+
+	// HeartBeat 心跳间隔，默认 20s，小于 0 时关闭
+	// 服务端只在收到 pong 时延长读超时，因此每次心跳同时发送 pong、ping 和 STOMP 空行；
+	// 超过 3 个心跳间隔未收到任何数据时视为连接断开
+	HeartBeat time.Duration
+
+	ConnectTimeout time.Duration // 建立连接（握手 + CONNECT）的超时，默认 10s
+	ReconnectMin   time.Duration // 重连的初始等待时间，默认 1s，之后每次翻倍
+	ReconnectMax   time.Duration // 重连的最大等待时间，默认 30s
+	MaxReconnects  int           // 连续重连失败的最大次数，0 表示不限，小于 0 表示不重连
+
+	Dialer *websocket.Dialer // 默认 websocket.DefaultDialer
+	Logger *zap.Logger       // 默认不输出日志
+
+	OnConnect    func()          // 每次连接（含重连）成功后调用
+	OnDisconnect func(err error) // 连接断开时调用，主动 Close 时不调用
+}
+
+func (o Options) withDefaults() Options {
+	if o.HeartBeat == 0 {
+		o.HeartBeat = 20 * time.Second
+	}
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = 10 * time.Second
+	}
+	if o.ReconnectMin <= 0 {
+		o.ReconnectMin = time.Second
+	}
+	if o.ReconnectMax < o.ReconnectMin {
+		o.ReconnectMax = 30 * time.Second
+		if o.ReconnectMax < o.ReconnectMin {
+			o.ReconnectMax = o.ReconnectMin
+		}
+	}
+	if o.Dialer == nil {
+		o.Dialer = websocket.DefaultDialer
+	}
+	if o.Logger == nil {
+		o.Logger = zap.NewNop()
+	}
+	return o
+}
+
+// Client STOMP 客户端，可并发使用
+type Client struct {
+	opts   Options
+	logger *zap.Logger
+
+	// mu 保护连接与订阅，需要同时持有时先 mu 后 writeMu
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	session string
+	subs    map[string]*Subscription
+	nextID  uint64
+
+	writeMu sync.Mutex
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+// Dial 连接并完成 STOMP 认证，首次连接失败直接返回错误，之后断开时自动重连
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	if opts.URL == "" {
+		return nil, errors.New("stompclient: url is required")
+	}
+
+	opts = opts.withDefaults()
+	c := &Client{
+		opts:   opts,
+		logger: opts.Logger.With(zap.String("module", moduleTag)),
+		subs:   make(map[string]*Subscription),
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	conn, session, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.session = session
+
+	c.logger.Info("Connected", zap.String("url", opts.URL), zap.String("session", session))
+	if opts.OnConnect != nil {
+		opts.OnConnect()
+	}
+
+	go c.run(conn)
+	return c, nil
+}
+
+// Session 当前连接的会话 ID，未连接时为空
+func (c *Client) Session() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// Connected 当前是否已连接
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil
+}
+
+// Done 客户端关闭或放弃重连后关闭
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Subscribe 订阅目标，用户队列使用 /user/queue/* 形式
+// 未连接时订阅在重连成功后发送
+func (c *Client) Subscribe(destination string, handler Handler) (*Subscription, error) {
+	if destination == "" || handler == nil {
+		return nil, errors.New("stompclient: destination and handler are required")
+	}
+	if c.isClosed() {
+		return nil, ErrClosed
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	sub := &Subscription{
+		ID:          fmt.Sprintf("sub-%d", c.nextID),
+		Destination: destination,
+		client:      c,
+		handler:     handler,
+	}
+	c.subs[sub.ID] = sub
+
+	// 写入失败说明连接已断开，重连后会重新订阅
+	if c.conn != nil {
+		if err := c.write(c.conn, subscribeFrame(sub)); err != nil {
+			c.logger.Warn("Failed to send SUBSCRIBE, will retry after reconnect",
+				zap.String("destination", destination), zap.Error(err))
+		}
+	}
+
+	return sub, nil
+}
+
+func (c *Client) unsubscribe(sub *Subscription) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subs[sub.ID]; !ok {
+		return nil
+	}
+	delete(c.subs, sub.ID)
+
+	if c.conn == nil {
+		return nil
+	}
+	return c.write(c.conn, stomp.NewFrame(stomp.CmdUnsubscribe).SetHeader(stomp.HdrID, sub.ID))
+}
+
+// Send 发送消息到目标（如 /app/sendToAll）
+// body 为 []byte 或 string 时原样发送，其他类型按 JSON 序列化
+func (c *Client) Send(destination string, body interface{}) error {
+	frame := stomp.NewFrame(stomp.CmdSend).SetHeader(stomp.HdrDestination, destination)
+	switch v := body.(type) {
+	case []byte:
+		frame.SetBody(v)
+	case string:
+		frame.SetBodyString(v)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		frame.SetHeader(stomp.HdrContentType, "application/json").SetBody(data)
+	}
+
+	if c.isClosed() {
+		return ErrClosed
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return ErrNotConnected
+	}
+	return c.write(c.conn, frame)
+}
+
+// Close 发送 DISCONNECT 并关闭连接，不再重连
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.conn == nil {
+			return
+		}
+		_ = c.write(c.conn, stomp.NewFrame(stomp.CmdDisconnect))
+		_ = c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		err = c.conn.Close()
+		c.conn = nil
+		c.session = ""
+	})
+	return err
+}
+
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// connect 握手并发送 CONNECT，等待 CONNECTED 或 ERROR
+func (c *Client) connect(ctx context.Context) (*websocket.Conn, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.ConnectTimeout)
+	defer cancel()
+
+	header := c.opts.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if c.opts.Token != "" {
+		header.Set(stomp.HdrAuthorization, "Bearer "+c.opts.Token)
+	}
+
+	conn, resp, err := c.opts.Dialer.DialContext(ctx, c.opts.URL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, "", fmt.Errorf("stompclient: dial %s: %s: %w", c.opts.URL, resp.Status, err)
+		}
+		return nil, "", fmt.Errorf("stompclient: dial %s: %w", c.opts.URL, err)
+	}
+
+	frame := stomp.NewFrame(stomp.CmdConnect).
+		SetHeader(stomp.HdrAcceptVersion, "1.2").
+		SetHeader(stomp.HdrHeartBeat, fmt.Sprintf("%d,0", max(c.opts.HeartBeat, 0).Milliseconds()))
+	if u, err := url.Parse(c.opts.URL); err == nil {
+		frame.SetHeader(stomp.HdrHost, u.Host)
+	}
+	if c.opts.Token != "" {
+		frame.SetHeader(stomp.HdrAuthorization, "Bearer "+c.opts.Token)
+	}
+
+	if err := c.write(conn, frame); err != nil {
+		conn.Close()
+		return nil, "", fmt.Errorf("stompclient: send CONNECT: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetReadDeadline(deadline)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			conn.Close()
+			return nil, "", fmt.Errorf("stompclient: wait CONNECTED: %w", err)
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		reply, err := stomp.ParseFrame(data)
+		if err != nil {
+			conn.Close()
+			return nil, "", fmt.Errorf("stompclient: parse reply: %w", err)
+		}
+
+		switch reply.Command {
+		case stomp.CmdConnected:
+			conn.SetReadDeadline(time.Time{})
+			return conn, reply.GetHeader(stomp.HdrSession), nil
+		case stomp.CmdError:
+			conn.Close()
+			return nil, "", fmt.Errorf("stompclient: connect rejected: %s", reply.GetHeader(stomp.HdrMessage))
+		}
+	}
+}
+
+// write 发送帧，WriteMessage 不支持并发调用
+func (c *Client) write(conn *websocket.Conn, frame *stomp.Frame) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return conn.WriteMessage(websocket.TextMessage, frame.Marshal())
+}
+
+// run 读取消息，连接断开后重连，直到客户端关闭或放弃重连
+func (c *Client) run(conn *websocket.Conn) {
+	defer close(c.done)
+
+	for conn != nil {
+		err := c.serve(conn)
+		conn.Close()
+
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+			c.session = ""
+		}
+		c.mu.Unlock()
+
+		if c.isClosed() {
+			return
+		}
+
+		c.logger.Warn("Connection lost", zap.Error(err))
+		if c.opts.OnDisconnect != nil {
+			c.opts.OnDisconnect(err)
+		}
+
+		conn = c.reconnect()
+	}
+}
+
+// serve 处理一个连接上的消息，返回读取错误
+func (c *Client) serve(conn *websocket.Conn) error {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	if hb := c.opts.HeartBeat; hb > 0 {
+		timeout := 3 * hb
+		conn.SetReadDeadline(time.Now().Add(timeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(timeout))
+		})
+		go c.heartbeat(conn, hb, stop)
+	}
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if hb := c.opts.HeartBeat; hb > 0 {
+			conn.SetReadDeadline(time.Now().Add(3 * hb))
+		}
+
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		frame, err := stomp.ParseFrame(data)
+		if err != nil {
+			c.logger.Warn("Failed to parse frame", zap.Error(err))
+			continue
+		}
+
+		switch frame.Command {
+		case stomp.CmdMessage:
+			c.dispatch(newMessage(frame))
+		case stomp.CmdError:
+			c.logger.Warn("Server error", zap.String("message", frame.GetHeader(stomp.HdrMessage)))
+		}
+	}
+}
+
+// heartbeat 定时发送心跳
+func (c *Client) heartbeat(conn *websocket.Conn, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			deadline := time.Now().Add(interval)
+			if err := conn.WriteControl(websocket.PongMessage, nil, deadline); err != nil {
+				return
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+
+			c.writeMu.Lock()
+			conn.SetWriteDeadline(deadline)
+			err := conn.WriteMessage(websocket.TextMessage, []byte{'\n'})
+			c.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// dispatch 将消息交给匹配的订阅处理
+func (c *Client) dispatch(msg *Message) {
+	c.mu.Lock()
+	handlers := make([]Handler, 0, 1)
+	for _, sub := range c.subs {
+		if sub.matches(msg) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(handlers) == 0 {
+		c.logger.Debug("No subscription for message", zap.String("destination", msg.Destination))
+		return
+	}
+
+	for _, handler := range handlers {
+		if err := handler(msg); err != nil {
+			c.logger.Warn("Message handler failed",
+				zap.String("destination", msg.Destination),
+				zap.Error(err))
+		}
+	}
+}
+
+// reconnect 按指数退避重连并重新订阅，客户端关闭或超过重连次数时返回 nil
+func (c *Client) reconnect() *websocket.Conn {
+	if c.opts.MaxReconnects < 0 {
+		return nil
+	}
+
+	backoff := c.opts.ReconnectMin
+	for attempt := 1; c.opts.MaxReconnects == 0 || attempt <= c.opts.MaxReconnects; attempt++ {
+		select {
+		case <-c.closed:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		conn, session, err := c.connect(context.Background())
+		if err == nil {
+			if err = c.resubscribe(conn, session); err == nil {
+				c.logger.Info("Reconnected", zap.String("session", session), zap.Int("attempt", attempt))
+				if c.opts.OnConnect != nil {
+					c.opts.OnConnect()
+				}
+				return conn
+			}
+			conn.Close()
+		}
+
+		c.logger.Warn("Reconnect failed",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		backoff *= 2
+		if backoff > c.opts.ReconnectMax {
+			backoff = c.opts.ReconnectMax
+		}
+	}
+
+	c.logger.Error("Giving up reconnecting", zap.Int("attempts", c.opts.MaxReconnects))
+	return nil
+}
+
+// resubscribe 在新连接上重新发送全部订阅，成功后切换为当前连接
+func (c *Client) resubscribe(conn *websocket.Conn, session string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isClosed() {
+		return ErrClosed
+	}
+
+	for _, sub := range c.subs {
+		if err := c.write(conn, subscribeFrame(sub)); err != nil {
+			return err
+		}
+	}
+
+	c.conn = conn
+	c.session = session
+	return nil
+}
+
+func subscribeFrame(sub *Subscription) *stomp.Frame {
+	return stomp.NewFrame(stomp.CmdSubscribe).
+		SetHeader(stomp.HdrID, sub.ID).
+		SetHeader(stomp.HdrDestination, sub.Destination).
+		SetHeader(stomp.HdrAck, "auto")
+}
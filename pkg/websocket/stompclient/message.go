@@ -0,0 +1,95 @@
+package stompclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+// Message 收到的 MESSAGE 帧
+type Message struct {
+	Destination  string
+	Subscription string
+	MessageID    string
+	Headers      map[string]string
+	Body         []byte
+}
+
+func newMessage(frame *stomp.Frame) *Message {
+	return &Message{
+		Destination:  frame.GetHeader(stomp.HdrDestination),
+		Subscription: frame.GetHeader(stomp.HdrSubscription),
+		MessageID:    frame.GetHeader(stomp.HdrMessageID),
+		Headers:      frame.Headers,
+		Body:         frame.Body,
+	}
+}
+
+// Decode 按 JSON 解析消息体
+func (m *Message) Decode(v interface{}) error {
+	if err := json.Unmarshal(m.Body, v); err != nil {
+		return fmt.Errorf("decode message from %s: %w", m.Destination, err)
+	}
+	return nil
+}
+
+// Handler 消息处理函数，返回的错误只记录日志，不影响后续消息
+type Handler func(msg *Message) error
+
+// Typed 将消息体解析为 T 后交给 fn 处理，如
+//
+//	client.Subscribe(websocket.TopicDict, stompclient.Typed(func(e websocket.DictChangeEvent, _ *stompclient.Message) error {
+//		...
+//	}))
+func Typed[T any](fn func(v T, msg *Message) error) Handler {
+	return func(msg *Message) error {
+		var v T
+		if err := msg.Decode(&v); err != nil {
+			return err
+		}
+		return fn(v, msg)
+	}
+}
+
+// Subscription 订阅
+type Subscription struct {
+	ID          string
+	Destination string
+
+	client  *Client
+	handler Handler
+}
+
+// Unsubscribe 取消订阅
+func (s *Subscription) Unsubscribe() error {
+	return s.client.unsubscribe(s)
+}
+
+// matches 消息是否属于该订阅
+// 服务端推送用户队列时目标为 /user/{username}/queue/*，对应客户端订阅的 /user/queue/*
+func (s *Subscription) matches(msg *Message) bool {
+	if msg.Subscription != "" {
+		return msg.Subscription == s.ID
+	}
+	if msg.Destination == s.Destination {
+		return true
+	}
+	return userDestination(msg.Destination) == s.Destination
+}
+
+// userDestination 将 /user/{username}/queue/x 转换为 /user/queue/x，其他目标返回空
+func userDestination(destination string) string {
+	const prefix = "/user/"
+	if len(destination) <= len(prefix) || destination[:len(prefix)] != prefix {
+		return ""
+	}
+
+	rest := destination[len(prefix):]
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == '/' {
+			return "/user" + rest[i:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,254 @@
+package stompclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/pkg/websocket/stomp"
+)
+
+type testServer struct {
+	*httptest.Server
+	broker *stomp.Broker
+
+	mu       sync.Mutex
+	sessions []*stomp.Session
+}
+
+// newTestServer 与 WebSocketController.Connect 一致：握手后由 CONNECT 帧认证，token 为 good 时通过
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	broker := stomp.NewBroker(zap.NewNop())
+	broker.SetTokenValidator(func(token string) (string, error) {
+		if token != "good" {
+			return "", errors.New("invalid token")
+		}
+		return "alice", nil
+	})
+
+	ts := &testServer{broker: broker}
+	upgrader := websocket.Upgrader{}
+	var n int
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		ts.mu.Lock()
+		n++
+		session := &stomp.Session{ID: "s" + strconv.Itoa(n), Conn: conn, Subscriptions: make(map[string]string)}
+		ts.sessions = append(ts.sessions, session)
+		ts.mu.Unlock()
+
+		broker.AddSession(session)
+		defer func() {
+			broker.RemoveSession(session.ID)
+			conn.Close()
+		}()
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			broker.HandleMessage(session, data)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *testServer) url() string {
+	return "ws" + strings.TrimPrefix(ts.URL, "http")
+}
+
+// dropAll 断开全部服务端连接
+func (ts *testServer) dropAll() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, session := range ts.sessions {
+		session.Conn.Close()
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type dictEvent struct {
+	DictCode string `json:"dictCode"`
+}
+
+func TestDialRejected(t *testing.T) {
+	ts := newTestServer(t)
+
+	_, err := Dial(context.Background(), Options{URL: ts.url(), Token: "bad"})
+	if err == nil || !strings.Contains(err.Error(), "Token validation failed") {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	ts := newTestServer(t)
+
+	client, err := Dial(context.Background(), Options{URL: ts.url(), Token: "good"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if client.Session() == "" {
+		t.Fatal("expected session id")
+	}
+
+	events := make(chan dictEvent, 1)
+	if _, err := client.Subscribe("/topic/dict", Typed(func(e dictEvent, msg *Message) error {
+		events <- e
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	greetings := make(chan string, 1)
+	if _, err := client.Subscribe("/user/queue/greeting", func(msg *Message) error {
+		greetings <- msg.Destination
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return ts.broker.HasSubscribers("/topic/dict") })
+	ts.broker.Publish("/topic/dict", dictEvent{DictCode: "gender"})
+	ts.broker.SendToUser("alice", "/queue/greeting", "hi")
+
+	select {
+	case e := <-events:
+		if e.DictCode != "gender" {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no topic message")
+	}
+
+	select {
+	case dest := <-greetings:
+		if dest != "/user/alice/queue/greeting" {
+			t.Fatalf("unexpected destination %s", dest)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no user queue message")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	ts := newTestServer(t)
+
+	var mu sync.Mutex
+	var connects, disconnects int
+	client, err := Dial(context.Background(), Options{
+		URL:          ts.url(),
+		Token:        "good",
+		ReconnectMin: 10 * time.Millisecond,
+		OnConnect: func() {
+			mu.Lock()
+			connects++
+			mu.Unlock()
+		},
+		OnDisconnect: func(error) {
+			mu.Lock()
+			disconnects++
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	received := make(chan int, 4)
+	if _, err := client.Subscribe("/topic/online-count", Typed(func(n int, _ *Message) error {
+		received <- n
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return ts.broker.HasSubscribers("/topic/online-count") })
+
+	first := client.Session()
+	ts.dropAll()
+	waitFor(t, func() bool {
+		s := client.Session()
+		return s != "" && s != first && ts.broker.HasSubscribers("/topic/online-count")
+	})
+
+	ts.broker.Publish("/topic/online-count", 7)
+	select {
+	case n := <-received:
+		if n != 7 {
+			t.Fatalf("unexpected value %d", n)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("subscription not restored after reconnect")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if connects != 2 || disconnects != 1 {
+		t.Fatalf("connects=%d disconnects=%d", connects, disconnects)
+	}
+}
+
+func TestClose(t *testing.T) {
+	ts := newTestServer(t)
+
+	client, err := Dial(context.Background(), Options{URL: ts.url(), Token: "good", ReconnectMin: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-client.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("client not stopped")
+	}
+
+	if err := client.Send("/app/sendToAll", "x"); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	waitFor(t, func() bool { return ts.broker.GetTotalSessionCount() == 0 })
+}
+
+func TestUserDestination(t *testing.T) {
+	cases := map[string]string{
+		"/user/alice/queue/greeting": "/user/queue/greeting",
+		"/user/alice":                "",
+		"/topic/dict":                "",
+	}
+	for in, want := range cases {
+		if got := userDestination(in); got != want {
+			t.Errorf("userDestination(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
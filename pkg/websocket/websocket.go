@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/top-system/light-admin/pkg/websocket/stomp"
@@ -14,21 +15,88 @@ const (
 	TopicOnlineCount = "/topic/online-count"
 	TopicPublic      = "/topic/public"
 	TopicNotice      = "/topic/notice"
+	TopicNoticeLocks = "/topic/notice-locks"
+	TopicBanner      = "/topic/banner"
+
+	// 状态主题，订阅时先推送快照
+	TopicDownloads        = "/topic/downloads"
+	TopicDownloaderHealth = "/topic/downloaders/health"
+	TopicSystemMetrics    = "/topic/system-metrics"
+	TopicQueueTasks       = "/topic/queue/tasks"
 
 	// 用户队列
-	UserQueueMessages = "/queue/messages"
-	UserQueueMessage  = "/queue/message"
-	UserQueueGreeting = "/queue/greeting"
+	UserQueueMessages  = "/queue/messages"
+	UserQueueMessage   = "/queue/message"
+	UserQueueGreeting  = "/queue/greeting"
+	UserQueueDownloads = "/queue/downloads"
 
 	// 应用目标前缀 (客户端发送消息用)
 	AppSendToAll  = "/app/sendToAll"
 	AppSendToUser = "/app/sendToUser"
+	AppEventsAck  = "/app/events/ack"
+)
+
+// EventStreamPath SSE 推送地址，EventSource 无法设置请求头，令牌可以通过 token 参数传递
+const EventStreamPath = "/api/v1/events/stream"
+
+// DefaultStreamDestinations SSE 客户端未指定目标时接收的推送：通知、字典变更与下载进度
+var DefaultStreamDestinations = []string{
+	TopicNotice,
+	TopicBanner,
+	TopicDict,
+	TopicDownloads,
+	"/user" + UserQueueMessages,
+	"/user" + UserQueueDownloads,
+}
+
+// 事件回执状态
+const (
+	EventAckReceived  = "received"  // 客户端收到消息
+	EventAckDisplayed = "displayed" // 客户端已向用户展示（toast、横幅等）
 )
 
+// 模块名称，用于目标注册表
+const moduleWebSocket = "websocket"
+
+// DictChangeEvent 字典变更事件
+type DictChangeEvent struct {
+	DictCode  string `json:"dictCode"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ChatMessage 文本消息
+type ChatMessage struct {
+	Sender    string `json:"sender"`
+	Content   string `json:"content"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// SendToUserRequest /app/sendToUser 消息体
+type SendToUserRequest struct {
+	Username string `json:"username"`
+	Message  string `json:"message"`
+}
+
+// EventAck /app/events/ack 消息体，客户端收到或展示带 eventId 的推送后回执
+type EventAck struct {
+	EventID string `json:"eventId"`
+	Status  string `json:"status"` // received、displayed
+}
+
+// BannerEvent 系统横幅
+type BannerEvent struct {
+	EventID   string `json:"eventId"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Level     string `json:"level"`
+	Timestamp int64  `json:"timestamp"`
+}
+
 // WebSocket WebSocket管理器
 type WebSocket struct {
-	Broker *stomp.Broker
-	logger *zap.Logger
+	Broker  *stomp.Broker
+	Catalog *TopicCatalog
+	logger  *zap.Logger
 }
 
 // New 创建WebSocket管理器
@@ -36,9 +104,11 @@ func New(logger *zap.Logger) *WebSocket {
 	broker := stomp.NewBroker(logger)
 
 	ws := &WebSocket{
-		Broker: broker,
-		logger: logger.With(zap.String("module", "websocket")),
+		Broker:  broker,
+		Catalog: NewTopicCatalog(),
+		logger:  logger.With(zap.String("module", "websocket")),
 	}
+	ws.registerTopics()
 
 	// 设置连接/断开回调，用于广播在线用户数
 	broker.OnConnect = func(session *stomp.Session) {
@@ -48,23 +118,48 @@ func New(logger *zap.Logger) *WebSocket {
 		ws.broadcastOnlineCount()
 	}
 
-	// 设置订阅回调，当用户订阅特定主题时推送初始数据
-	broker.OnSubscribe = func(session *stomp.Session, destination string) {
-		switch destination {
-		case TopicOnlineCount:
-			// 用户订阅在线人数主题时，立即发送当前在线连接数
-			// 这样用户登录后能立即看到包含自己的在线人数
-			count := broker.GetTotalSessionCount()
-			ws.logger.Info("User subscribed to online-count, sending current count",
-				zap.String("username", session.Username),
-				zap.Int("count", count))
-			broker.SendToSession(session.ID, TopicOnlineCount, count)
-		}
-	}
+	// 订阅在线人数主题时立即推送当前在线连接数
+	// 这样用户登录后能立即看到包含自己的在线人数
+	ws.RegisterSnapshot(TopicOnlineCount, func(session *stomp.Session, destination string) (interface{}, bool) {
+		return broker.GetTotalSessionCount(), true
+	})
+	ws.RegisterSnapshot(TopicSystemMetrics, func(session *stomp.Session, destination string) (interface{}, bool) {
+		return ws.SystemMetrics(), true
+	})
 
 	return ws
 }
 
+// registerTopics 注册内置目标
+func (ws *WebSocket) registerTopics() {
+	for _, topic := range []Topic{
+		{Destination: TopicDict, Description: "字典变更通知，收到后按 dictCode 刷新本地字典缓存", Payload: DictChangeEvent{}},
+		{Destination: TopicOnlineCount, Description: "在线连接数，订阅时立即推送当前值，连接变化时广播", Payload: 0},
+		{Destination: TopicPublic, Description: "系统公共消息", Payload: ChatMessage{}},
+		{Destination: TopicNotice, Description: "服务端广播通知（文本）", Payload: ""},
+		{Destination: TopicSystemMetrics, Description: "运行时指标，订阅时立即推送当前值，之后定时推送", Payload: SystemMetrics{}},
+		{Destination: TopicBanner, Description: "系统横幅，展示后通过 " + AppEventsAck + " 回执", Payload: BannerEvent{}},
+		{Destination: UserQueueMessages, Description: "个人通知，消息体的 type 字段区分类型（如 security_alert），带 eventId 的消息需要回执"},
+		{Destination: UserQueueGreeting, Description: "点对点消息", Payload: ChatMessage{}},
+		{Destination: AppSendToAll, Description: "发送广播通知，消息体为 JSON 字符串，转发到 " + TopicNotice, Payload: ""},
+		{Destination: AppSendToUser, Description: "发送点对点消息，转发到接收人的 " + UserQueueGreeting, Payload: SendToUserRequest{}},
+		{Destination: AppEventsAck, Description: "推送回执，收到（received）或展示（displayed）带 eventId 的消息后发送", Payload: EventAck{}},
+	} {
+		topic.Module = moduleWebSocket
+		ws.Catalog.Register(topic)
+	}
+}
+
+// RegisterTopic 注册业务模块发布的目标
+func (ws *WebSocket) RegisterTopic(topic Topic) {
+	ws.Catalog.Register(topic)
+}
+
+// RegisterSnapshot 注册订阅快照，客户端订阅目标后先收到快照再接收增量
+func (ws *WebSocket) RegisterSnapshot(destination string, provider stomp.SnapshotProvider) {
+	ws.Broker.RegisterSnapshot(destination, provider)
+}
+
 // RegisterHandler 注册消息处理器
 // destination: /app/sendToAll, /app/sendToUser 等
 func (ws *WebSocket) RegisterHandler(destination string, handler stomp.MessageHandler) {
@@ -75,11 +170,12 @@ func (ws *WebSocket) RegisterHandler(destination string, handler stomp.MessageHa
 // 使用 Broadcast 而不是 Publish，因为：
 // 1. OnConnect 触发时，新用户还没订阅 /topic/online-count
 // 2. 需要确保所有已认证用户都能收到在线人数更新
+// 在线人数只统计本实例，多实例部署时不转发到其他实例
 func (ws *WebSocket) broadcastOnlineCount() {
 	// 使用会话数（每个连接都计数，同一用户多设备登录会增加）
 	// 如果想用唯一用户数（去重），改用 GetOnlineUserCount()
 	count := ws.Broker.GetTotalSessionCount()
-	ws.Broker.Broadcast(TopicOnlineCount, count)
+	ws.Broker.BroadcastLocal(TopicOnlineCount, count)
 }
 
 // === 服务方法 (对应 Java WebSocketService) ===
@@ -89,13 +185,24 @@ func (ws *WebSocket) BroadcastDictChange(dictCode string) {
 	if dictCode == "" {
 		return
 	}
-	event := map[string]interface{}{
-		"dictCode":  dictCode,
-		"timestamp": time.Now().UnixMilli(),
+	event := DictChangeEvent{
+		DictCode:  dictCode,
+		Timestamp: time.Now().UnixMilli(),
 	}
 	ws.Broker.Publish(TopicDict, event)
 }
 
+// OnDictChange 监听字典变更广播（包括其他实例的），用于清除本实例的字典缓存
+func (ws *WebSocket) OnDictChange(fn func(dictCode string)) {
+	ws.Broker.RegisterListener(TopicDict, func(destination string, body []byte) {
+		var event DictChangeEvent
+		if err := json.Unmarshal(body, &event); err != nil || event.DictCode == "" {
+			return
+		}
+		fn(event.DictCode)
+	})
+}
+
 // SendNotification 发送通知给指定用户
 func (ws *WebSocket) SendNotification(username string, message interface{}) {
 	if username == "" || message == nil {
@@ -109,10 +216,10 @@ func (ws *WebSocket) BroadcastSystemMessage(message string) {
 	if message == "" {
 		return
 	}
-	msg := map[string]interface{}{
-		"sender":    "System",
-		"content":   message,
-		"timestamp": time.Now().UnixMilli(),
+	msg := ChatMessage{
+		Sender:    "System",
+		Content:   message,
+		Timestamp: time.Now().UnixMilli(),
 	}
 	ws.Broker.Publish(TopicPublic, msg)
 }
@@ -122,10 +229,10 @@ func (ws *WebSocket) SendToUser(sender, receiver, message string) {
 	if receiver == "" {
 		return
 	}
-	msg := map[string]interface{}{
-		"sender":    sender,
-		"content":   message,
-		"timestamp": time.Now().UnixMilli(),
+	msg := ChatMessage{
+		Sender:    sender,
+		Content:   message,
+		Timestamp: time.Now().UnixMilli(),
 	}
 	ws.Broker.SendToUser(receiver, UserQueueGreeting, msg)
 }
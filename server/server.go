@@ -0,0 +1,190 @@
+// Package server 将 light-admin 作为库嵌入其他 Go 程序
+//
+//	cfg := server.LoadConfig("config/config.yaml", "", "config/casbin_model.conf")
+//	srv, err := server.New(cfg,
+//		server.WithProviders(NewReportController),
+//		server.WithRoutes(NewReportRoutes),
+//		server.WithMenus(reportMenus),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	srv.Run()
+//
+// 默认与 runserver 命令一样监听 Http.ListenAddr；使用 WithoutListener 时由调用方将 Handler 挂载到自己的 http.Server，
+// 并通过 Start/Stop 管理生命周期
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+
+	"github.com/top-system/light-admin/api"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/bootstrap"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// Server 嵌入的应用
+type Server struct {
+	// App fx 应用，可用于获取其他依赖或自行管理生命周期
+	App *fx.App
+	// Echo 业务接口的 Echo 实例，路由在启动时注册
+	Echo *echo.Echo
+	// Handler 应用的 HTTP 入口，在 Echo 之前处理 /ws
+	Handler http.Handler
+}
+
+type options struct {
+	fx       []fx.Option
+	menus    system.MenuTrees
+	tasks    map[string]queue.ResumableTaskFactory
+	handlers map[string]crontab.Handler
+	listener bool
+}
+
+// Option 嵌入选项
+type Option func(*options)
+
+// WithProviders 注册自定义依赖，如控制器、服务，构造函数可以依赖应用内的任意组件
+func WithProviders(constructors ...interface{}) Option {
+	return func(o *options) {
+		o.fx = append(o.fx, fx.Provide(constructors...))
+	}
+}
+
+// WithRoutes 注册自定义路由，构造函数的返回值需要实现 api.Route，在内置路由之后注册
+func WithRoutes(constructors ...interface{}) Option {
+	return func(o *options) {
+		for _, constructor := range constructors {
+			o.fx = append(o.fx, fx.Provide(fx.Annotate(
+				constructor,
+				fx.As(new(api.Route)),
+				fx.ResultTags(api.RouteGroup),
+			)))
+		}
+	}
+}
+
+// WithTaskType 注册自定义队列任务类型，用于重启后从持久化的任务记录中恢复任务
+func WithTaskType(taskType string, factory queue.ResumableTaskFactory) Option {
+	return func(o *options) {
+		o.tasks[taskType] = factory
+	}
+}
+
+// WithCronHandler 注册定时任务处理器，管理员可通过 /api/v1/crontab/tasks 创建使用该处理器的定时任务
+func WithCronHandler(name string, handler crontab.Handler) Option {
+	return func(o *options) {
+		o.handlers[name] = handler
+	}
+}
+
+// WithMenus 启动时导入自定义菜单，已存在的菜单（同一父菜单下同名）会被跳过，可以重复调用
+func WithMenus(menus system.MenuTrees) Option {
+	return func(o *options) {
+		o.menus = append(o.menus, menus...)
+	}
+}
+
+// WithoutListener 不监听 Http.ListenAddr，由调用方挂载 Handler；mTLS 监听同样不启动
+func WithoutListener() Option {
+	return func(o *options) {
+		o.listener = false
+	}
+}
+
+// WithFxOptions 追加任意 fx 选项，如 fx.Decorate 替换内置组件
+func WithFxOptions(opts ...fx.Option) Option {
+	return func(o *options) {
+		o.fx = append(o.fx, opts...)
+	}
+}
+
+// LoadConfig 按与 runserver 命令相同的规则加载配置文件，文件不存在时 panic
+func LoadConfig(configFile, profile, casbinModel string) lib.Config {
+	lib.SetConfigPath(configFile)
+	lib.SetConfigProfile(profile)
+	lib.SetConfigCasbinModelPath(casbinModel)
+	return lib.NewConfig()
+}
+
+// New 使用给定配置创建应用，依赖图有误时返回错误
+func New(cfg lib.Config, opts ...Option) (*Server, error) {
+	o := &options{
+		tasks:    make(map[string]queue.ResumableTaskFactory),
+		handlers: make(map[string]crontab.Handler),
+		listener: true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	for taskType, factory := range o.tasks {
+		queue.RegisterResumableTaskFactory(taskType, factory)
+	}
+	for name, handler := range o.handlers {
+		crontab.RegisterHandler(name, handler)
+	}
+
+	s := &Server{}
+	var handler lib.HttpHandler
+	var appHandler bootstrap.Handler
+
+	fxOptions := []fx.Option{
+		bootstrap.Module,
+		fx.Replace(cfg),
+		fx.NopLogger,
+	}
+	if !o.listener {
+		fxOptions = append(fxOptions, fx.Replace(bootstrap.Listener{Disabled: true}))
+	}
+	if len(o.menus) > 0 {
+		fxOptions = append(fxOptions, fx.Invoke(seedMenus(o.menus)))
+	}
+	fxOptions = append(fxOptions, o.fx...)
+	fxOptions = append(fxOptions, fx.Populate(&handler, &appHandler))
+
+	s.App = fx.New(fxOptions...)
+	if err := s.App.Err(); err != nil {
+		return nil, err
+	}
+	s.Echo = handler.Engine
+	s.Handler = appHandler
+
+	return s, nil
+}
+
+// seedMenus 在数据库连接就绪后导入菜单，多实例同时启动时由菜单导入锁保证只有一个实例导入
+func seedMenus(menus system.MenuTrees) func(fx.Lifecycle, service.MenuService, service.LockService) {
+	return func(lifecycle fx.Lifecycle, menuService service.MenuService, lockService service.LockService) {
+		lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				return lockService.WithLock(system.LockMenuImport, 0, "seed menus", func() error {
+					return menuService.CreateMenus(0, menus)
+				})
+			},
+		})
+	}
+}
+
+// Start 启动应用
+func (s *Server) Start(ctx context.Context) error {
+	return s.App.Start(ctx)
+}
+
+// Stop 停止应用
+func (s *Server) Stop(ctx context.Context) error {
+	return s.App.Stop(ctx)
+}
+
+// Run 启动应用并阻塞到收到退出信号
+func (s *Server) Run() {
+	s.App.Run()
+}
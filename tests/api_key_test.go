@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/top-system/light-admin/api/middlewares"
+	platformcontroller "github.com/top-system/light-admin/api/platform/controller"
+	platformroute "github.com/top-system/light-admin/api/platform/route"
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/route"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+func newApiKeyTestUserService() service.UserService {
+	config := lib.Config{SuperAdmin: &lib.SuperAdminConfig{Username: "root"}}
+	return service.NewUserService(
+		newTestLogger(), config, lib.Database{},
+		repository.UserRepository{}, repository.UserRoleRepository{}, repository.UserPositionRepository{},
+		repository.ApiKeyRepository{}, repository.RoleRepository{}, repository.RoleMenuRepository{},
+		repository.MenuRepository{}, repository.DeptRepository{}, service.PermissionCache{}, service.PermissionService{},
+		lib.ResponseCache{}, service.DeptRoleService{}, service.AuthService{}, service.WebhookService{}, nil,
+	)
+}
+
+func TestApiKeyDeniedOnRoutesWithoutPerm(t *testing.T) {
+	engine := echo.New()
+	handler := lib.HttpHandler{Engine: engine, RouterV1: engine.Group("/api/v1")}
+	permMiddleware := middlewares.NewPermissionMiddleware(
+		handler, newTestLogger(), lib.Config{}, lib.NewPermRegistry(),
+		service.PermissionService{}, newApiKeyTestUserService(), service.PolicyService{},
+	)
+
+	var claims *dto.JwtClaims
+	engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set(constants.CurrentUser, claims)
+			return next(ctx)
+		}
+	})
+
+	ok := func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }
+	api := permMiddleware.Group(handler.RouterV1.Group("/test"))
+	api.GET("/profile", ok, "")
+	api.AllowApiKey().GET("/options", ok, "")
+
+	cases := []struct {
+		name   string
+		claims *dto.JwtClaims
+		path   string
+		status int
+	}{
+		{"user without perm route", &dto.JwtClaims{ID: 1, Username: "alice"}, "/api/v1/test/profile", http.StatusOK},
+		{"api key without perm route", &dto.JwtClaims{ID: 1, Username: "alice", ApiKeyID: 7}, "/api/v1/test/profile", http.StatusForbidden},
+		{"superadmin api key without perm route", &dto.JwtClaims{ID: 0, Username: "root", ApiKeyID: 8}, "/api/v1/test/profile", http.StatusForbidden},
+		{"api key opted-in route", &dto.JwtClaims{ID: 1, Username: "alice", ApiKeyID: 7}, "/api/v1/test/options", http.StatusOK},
+	}
+
+	for _, c := range cases {
+		claims = c.claims
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if rec.Code != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.name, c.status, rec.Code)
+		}
+	}
+}
+
+// TestApiKeyDeniedOnAuthAndWebSocketRoutes 登录设备、登出与 WebSocket 管理接口不向 API Key 开放
+func TestApiKeyDeniedOnAuthAndWebSocketRoutes(t *testing.T) {
+	engine := echo.New()
+	handler := lib.HttpHandler{Engine: engine, RouterV1: engine.Group("/api/v1")}
+	permMiddleware := middlewares.NewPermissionMiddleware(
+		handler, newTestLogger(), lib.Config{}, lib.NewPermRegistry(),
+		service.PermissionService{}, newApiKeyTestUserService(), service.PolicyService{},
+	)
+	engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set(constants.CurrentUser, &dto.JwtClaims{ID: 1, Username: "alice", ApiKeyID: 7})
+			return next(ctx)
+		}
+	})
+
+	route.NewPublicRoutes(newTestLogger(), handler, controller.PublicController{}, controller.CaptchaController{}, permMiddleware).Setup()
+	platformroute.NewWebSocketRoute(newTestLogger(), handler, platformcontroller.WebSocketController{}, permMiddleware).Setup()
+
+	for _, c := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/auth/devices"},
+		{http.MethodDelete, "/api/v1/auth/devices/1"},
+		{http.MethodDelete, "/api/v1/auth/logout"},
+		{http.MethodPost, "/api/v1/websocket/sendToAll"},
+		{http.MethodGet, "/api/v1/websocket/online-users"},
+	} {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected status 403, got %d", c.method, c.path, rec.Code)
+		}
+	}
+}
+
+// TestAuthAndWebSocketRoutesRegistered 登录、刷新令牌及 WebSocket 路由均在权限注册表中声明，免登录路由由登录及权限中间件放行
+func TestAuthAndWebSocketRoutesRegistered(t *testing.T) {
+	engine := echo.New()
+	handler := lib.HttpHandler{Engine: engine, RouterV1: engine.Group("/api/v1")}
+	registry := lib.NewPermRegistry()
+	config := newTestAuthConfig()
+	config.Auth.Enable = true
+	config.Casbin = &lib.CasbinConfig{Enable: true}
+	permMiddleware := middlewares.NewPermissionMiddleware(
+		handler, newTestLogger(), config, registry,
+		service.PermissionService{}, newApiKeyTestUserService(), service.PolicyService{},
+	)
+	middlewares.NewAuthMiddleware(config, handler, newTestLogger(), registry, newTestAuthService(t, config, newTestAuthDB(t)), service.ApiKeyService{}).Setup()
+	permMiddleware.Setup()
+
+	route.NewPublicRoutes(newTestLogger(), handler, controller.PublicController{}, controller.CaptchaController{}, permMiddleware).Setup()
+	platformroute.NewWebSocketRoute(newTestLogger(), handler, platformcontroller.WebSocketController{}, permMiddleware).Setup()
+
+	for _, c := range []struct {
+		method, path string
+		public       bool
+	}{
+		{http.MethodPost, "/api/v1/auth/login", true},
+		{http.MethodPost, "/api/v1/auth/refresh", true},
+		{http.MethodGet, "/api/v1/auth/captcha", true},
+		{http.MethodGet, "/api/v1/auth/jwks", true},
+		{http.MethodGet, "/.well-known/jwks.json", true},
+		{http.MethodDelete, "/api/v1/auth/logout", false},
+		{http.MethodGet, "/api/v1/auth/devices", false},
+		{http.MethodPost, "/api/v1/websocket/sendToAll", false},
+		{http.MethodGet, "/api/v1/websocket/online-users", false},
+	} {
+		rp, ok := registry.Get(c.method, c.path)
+		if !ok {
+			t.Errorf("%s %s: expected route to be registered", c.method, c.path)
+			continue
+		}
+		if rp.Public != c.public {
+			t.Errorf("%s %s: expected public=%v, got %v", c.method, c.path, c.public, rp.Public)
+		}
+	}
+
+	ok := func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }
+	api := permMiddleware.Group(handler.RouterV1.Group("/test"))
+	api.Public().GET("/open", ok, "")
+	api.GET("/closed", ok, "")
+
+	for _, c := range []struct {
+		path   string
+		status int
+	}{
+		{"/api/v1/test/open", http.StatusOK},
+		{"/api/v1/test/closed", http.StatusUnauthorized},
+	} {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if rec.Code != c.status {
+			t.Errorf("GET %s: expected status %d, got %d", c.path, c.status, rec.Code)
+		}
+	}
+}
+
+func TestIsSuperAdminClaimsIgnoresApiKeys(t *testing.T) {
+	userService := newApiKeyTestUserService()
+
+	if !userService.IsSuperAdminClaims(&dto.JwtClaims{Username: "root"}) {
+		t.Error("Expected the superadmin session to be recognised")
+	}
+	if userService.IsSuperAdminClaims(&dto.JwtClaims{Username: "root", ApiKeyID: 1}) {
+		t.Error("Expected a superadmin api key not to be treated as superadmin")
+	}
+	if userService.IsSuperAdminClaims(nil) {
+		t.Error("Expected nil claims not to be treated as superadmin")
+	}
+}
+
+func TestApiKeyCreateRejectsConfigSuperAdmin(t *testing.T) {
+	config := lib.Config{SuperAdmin: &lib.SuperAdminConfig{Username: "root"}}
+	apiKeyService := service.NewApiKeyService(newTestLogger(), config,
+		repository.ApiKeyRepository{}, repository.UserRepository{}, service.PermissionService{})
+
+	_, err := apiKeyService.Create(&dto.JwtClaims{ID: 0, Username: "root"}, &system.ApiKeyForm{
+		Name:  "ci",
+		Perms: []string{"sys:user:query"},
+	})
+	if !errors.Is(err, errors.ApiKeyNoAccount) {
+		t.Fatalf("Expected ApiKeyNoAccount, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+func TestApiUsageAccumulate(t *testing.T) {
+	db := newTestDB(t, &system.ApiUsage{})
+	apiUsageRepository := repository.NewApiUsageRepository(db, newTestLogger(), lib.DBCompat{})
+
+	bucket := time.Now().Truncate(time.Hour)
+	usage := func(requests, errs int64) system.ApiUsages {
+		return system.ApiUsages{{BucketTime: bucket, UserID: 1, Username: "alice", Method: "GET", Route: "/api/v1/users", RequestCount: requests, ErrorCount: errs, DurationMs: requests * 10}}
+	}
+	assert.NoError(t, apiUsageRepository.Accumulate(usage(3, 1)))
+	assert.NoError(t, apiUsageRepository.Accumulate(usage(2, 0)))
+
+	var list system.ApiUsages
+	assert.NoError(t, db.ORM.Find(&list).Error)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, int64(5), list[0].RequestCount)
+		assert.Equal(t, int64(1), list[0].ErrorCount)
+		assert.Equal(t, int64(50), list[0].DurationMs)
+	}
+}
+
+// TestApiUsageAccumulateQualifiesColumns PostgreSQL 的 ON CONFLICT 中未限定表名的列有歧义
+func TestApiUsageAccumulateQualifiesColumns(t *testing.T) {
+	engine := lib.CurrentDatabaseEngine
+	lib.CurrentDatabaseEngine = lib.DatabaseEnginePostgres
+	t.Cleanup(func() { lib.CurrentDatabaseEngine = engine })
+
+	orm, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 dbname=test"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sql string
+	orm.Callback().Create().After("gorm:create").Register("test:capture", func(db *gorm.DB) {
+		sql = db.Statement.SQL.String()
+	})
+
+	apiUsageRepository := repository.NewApiUsageRepository(lib.Database{ORM: orm}, newTestLogger(), lib.DBCompat{})
+	assert.NoError(t, apiUsageRepository.Accumulate(system.ApiUsages{{BucketTime: time.Now(), Method: "GET", Route: "/api/v1/users", RequestCount: 1}}))
+	assert.Contains(t, sql, `"request_count"="sys_api_usage"."request_count" + excluded.request_count`)
+}
+
+func TestApiUsageFlushedOnShutdown(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.ApiUsage{})
+	apiUsageService := service.NewApiUsageService(logger, repository.NewApiUsageRepository(db, logger, lib.DBCompat{}))
+
+	engine := echo.New()
+	lc := fxtest.NewLifecycle(t)
+	config := lib.Config{Analytics: &lib.AnalyticsConfig{Enable: true, FlushInterval: 3600}}
+	usageMiddleware := middlewares.NewApiUsageMiddleware(lc, lib.HttpHandler{Engine: engine}, logger, config, apiUsageService)
+	usageMiddleware.Setup()
+	engine.GET("/api/v1/ping", func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) })
+
+	lc.RequireStart()
+	for i := 0; i < 3; i++ {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/ping", nil))
+	}
+	lc.RequireStop()
+
+	var list system.ApiUsages
+	assert.NoError(t, db.ORM.Find(&list).Error)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "/api/v1/ping", list[0].Route)
+		assert.Equal(t, int64(3), list[0].RequestCount)
+	}
+}
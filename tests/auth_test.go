@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+func newTestAuthConfig() lib.Config {
+	return lib.Config{
+		Name:       "test",
+		Http:       &lib.HttpConfig{},
+		Auth:       &lib.AuthConfig{Secret: "secret", TokenExpired: 3600},
+		SuperAdmin: &lib.SuperAdminConfig{Username: "admin", Password: "admin"},
+		Database:   &lib.DatabaseConfig{},
+	}
+}
+
+func newTestAuthService(t *testing.T, config lib.Config, db lib.Database) service.AuthService {
+	t.Helper()
+
+	logger := newTestLogger()
+	return service.NewAuthService(
+		newTestCache(t), config, logger, ws.New(zap.NewNop()), eventbus.New(zap.NewNop()),
+		repository.NewUserRepository(db, logger), repository.NewRefreshTokenRepository(db, logger),
+	)
+}
+
+func newTestAuthDB(t *testing.T) lib.Database {
+	return newTestDB(t, &system.User{}, &system.RefreshToken{})
+}
+
+// TestRefreshTokenRotation 刷新后旧的刷新令牌失效，访问令牌属于同一登录设备
+func TestRefreshTokenRotation(t *testing.T) {
+	db := newTestAuthDB(t)
+	authService := newTestAuthService(t, newTestAuthConfig(), db)
+
+	user := &system.User{Username: "alice", Status: 1}
+	assert.NoError(t, db.ORM.Create(user).Error)
+
+	login, err := authService.GenerateToken(user, "curl/8.0", "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	claims, err := authService.ParseToken(login.AccessToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, user.ID, claims.ID)
+	assert.NotZero(t, claims.SessionID)
+
+	refreshed, err := authService.RefreshToken(login.RefreshToken, "127.0.0.2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	refreshedClaims, err := authService.ParseToken(refreshed.AccessToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, claims.SessionID, refreshedClaims.SessionID)
+	}
+
+	// 旧令牌不能再次使用
+	_, err = authService.RefreshToken(login.RefreshToken, "127.0.0.1")
+	assert.True(t, errors.Is(err, errors.AuthRefreshInvalid))
+
+	devices, err := authService.ListDevices(claims)
+	if assert.NoError(t, err) && assert.Len(t, devices, 1) {
+		assert.Equal(t, "127.0.0.2", devices[0].IP)
+		assert.True(t, devices[0].Current)
+	}
+}
+
+// TestRefreshTokenRevoke 移除登录设备后刷新令牌与访问令牌都失效，禁用用户后不能再刷新
+func TestRefreshTokenRevoke(t *testing.T) {
+	db := newTestAuthDB(t)
+	authService := newTestAuthService(t, newTestAuthConfig(), db)
+
+	user := &system.User{Username: "alice", Status: 1}
+	assert.NoError(t, db.ORM.Create(user).Error)
+
+	first, err := authService.GenerateToken(user, "phone", "127.0.0.1")
+	assert.NoError(t, err)
+	second, err := authService.GenerateToken(user, "laptop", "127.0.0.1")
+	assert.NoError(t, err)
+
+	claims, err := authService.ParseToken(first.AccessToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, authService.RevokeDevice(user.ID, claims.SessionID))
+	assert.True(t, errors.Is(authService.RevokeDevice(user.ID, claims.SessionID), errors.AuthDeviceNotFound))
+
+	_, err = authService.ParseToken(first.AccessToken)
+	assert.True(t, errors.Is(err, errors.AuthTokenRevoked))
+	_, err = authService.RefreshToken(first.RefreshToken, "127.0.0.1")
+	assert.True(t, errors.Is(err, errors.AuthRefreshInvalid))
+
+	// 另一台设备不受影响，直到用户被禁用
+	_, err = authService.ParseToken(second.AccessToken)
+	assert.NoError(t, err)
+	assert.NoError(t, db.ORM.Model(user).Update("status", 0).Error)
+	_, err = authService.RefreshToken(second.RefreshToken, "127.0.0.1")
+	assert.True(t, errors.Is(err, errors.AuthRefreshInvalid))
+
+	var count int64
+	assert.NoError(t, db.ORM.Model(&system.RefreshToken{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// TestRevokeUserTokens 吊销后此前签发的令牌全部失效，同一秒内重新签发的令牌仍然有效，再次吊销后也失效
+func TestRevokeUserTokens(t *testing.T) {
+	db := newTestAuthDB(t)
+	authService := newTestAuthService(t, newTestAuthConfig(), db)
+
+	user := &system.User{Username: "alice", Status: 1}
+	assert.NoError(t, db.ORM.Create(user).Error)
+
+	before, err := authService.GenerateToken(user, "phone", "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, authService.RevokeUserTokens(user.Username))
+	after, err := authService.GenerateToken(user, "phone", "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = authService.ParseToken(before.AccessToken)
+	assert.True(t, errors.Is(err, errors.AuthTokenRevoked))
+	_, err = authService.ParseToken(after.AccessToken)
+	assert.NoError(t, err, "tokens issued right after revocation should stay valid")
+
+	assert.NoError(t, authService.RevokeUserTokens(user.Username))
+	_, err = authService.ParseToken(after.AccessToken)
+	assert.True(t, errors.Is(err, errors.AuthTokenRevoked))
+
+	// 其他用户不受影响
+	bob, err := authService.GenerateToken(&system.User{ID: 2, Username: "bob", Status: 1}, "phone", "127.0.0.1")
+	if assert.NoError(t, err) {
+		_, err = authService.ParseToken(bob.AccessToken)
+		assert.NoError(t, err)
+	}
+}
+
+// TestRefreshTokenSuperAdmin 配置文件中的超级管理员（ID 为 0）可以刷新令牌，改名后旧令牌失效
+func TestRefreshTokenSuperAdmin(t *testing.T) {
+	db := newTestAuthDB(t)
+	config := newTestAuthConfig()
+	authService := newTestAuthService(t, config, db)
+
+	login, err := authService.GenerateToken(&system.User{Username: "admin"}, "", "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	refreshed, err := authService.RefreshToken(login.RefreshToken, "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	claims, err := authService.ParseToken(refreshed.AccessToken)
+	if assert.NoError(t, err) {
+		assert.Equal(t, uint64(0), claims.ID)
+		assert.Equal(t, "admin", claims.Username)
+	}
+
+	config.SuperAdmin = &lib.SuperAdminConfig{Username: "root", Password: "root"}
+	renamed := newTestAuthService(t, config, db)
+	_, err = renamed.RefreshToken(refreshed.RefreshToken, "127.0.0.1")
+	assert.True(t, errors.Is(err, errors.AuthRefreshInvalid))
+}
+
+// TestReadOnlyAllowsLogin 只读模式下仍可登录、刷新与登出，登录设备照常写入
+func TestReadOnlyAllowsLogin(t *testing.T) {
+	db := newTestAuthDB(t)
+	config := newTestAuthConfig()
+	config.Database.ReadOnly = true
+	readOnly := lib.NewReadOnly(config, db, newTestLogger())
+	authService := newTestAuthService(t, config, db)
+
+	user := &system.User{Username: "alice", Status: 1}
+	assert.True(t, errors.Is(db.ORM.Create(user).Error, errors.SystemReadOnly))
+	readOnly.Disable()
+	assert.NoError(t, db.ORM.Create(user).Error)
+	readOnly.Enable("maintenance")
+
+	login, err := authService.GenerateToken(user, "curl/8.0", "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	refreshed, err := authService.RefreshToken(login.RefreshToken, "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	claims, err := authService.ParseToken(refreshed.AccessToken)
+	if assert.NoError(t, err) {
+		assert.NoError(t, authService.Logout(claims))
+	}
+
+	// 其他表仍然只读
+	assert.True(t, errors.Is(db.ORM.Model(user).Update("nickname", "Alice").Error, errors.SystemReadOnly))
+	assert.True(t, errors.Is(db.ORM.Exec("DELETE FROM "+tableName(t, db, &system.RefreshToken{})).Error, errors.SystemReadOnly))
+}
+
+func tableName(t *testing.T, db lib.Database, model interface{}) string {
+	t.Helper()
+
+	stmt := db.ORM.Model(model).Statement
+	if err := stmt.Parse(model); err != nil {
+		t.Fatal(err)
+	}
+	return stmt.Table
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	platformrepository "github.com/top-system/light-admin/api/platform/repository"
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+)
+
+// TestConfigBackupRoundTrip 数据超过默认分页大小（15 行）时备份不能被截断，备份后立即对比应没有差异
+func TestConfigBackupRoundTrip(t *testing.T) {
+	logger := newTestLogger()
+	cache := newTestCache(t)
+	db := newTestDB(t,
+		&system.Menu{}, &system.Role{}, &system.RoleMenu{}, &system.Dict{}, &system.DictItem{},
+		&system.Config{}, &system.ConfigBackup{}, &platform.FileObject{},
+	)
+
+	const rows = 20
+	for i := 1; i <= rows; i++ {
+		assert.NoError(t, db.ORM.Create(&system.Menu{Name: fmt.Sprintf("menu-%d", i), Type: 1, RoutePath: fmt.Sprintf("/m%d", i)}).Error)
+		assert.NoError(t, db.ORM.Create(&system.Role{Name: fmt.Sprintf("role-%d", i), Code: fmt.Sprintf("R%d", i), Status: 1}).Error)
+		assert.NoError(t, db.ORM.Create(&system.RoleMenu{RoleID: uint64(i), MenuID: uint64(i)}).Error)
+		assert.NoError(t, db.ORM.Create(&system.Dict{DictCode: fmt.Sprintf("dict_%d", i), Name: fmt.Sprintf("dict-%d", i), Status: 1}).Error)
+		assert.NoError(t, db.ORM.Create(&system.DictItem{DictCode: "dict_1", Label: fmt.Sprintf("item-%d", i), Value: fmt.Sprint(i), Status: 1}).Error)
+	}
+
+	fileService := platformservice.NewObjectFileService(
+		platformservice.NewLocalFileService(t.TempDir(), logger),
+		platformrepository.NewFileObjectRepository(db, logger),
+		logger,
+	)
+	userRepository := repository.NewUserRepository(db, logger)
+	configRepository := repository.NewConfigRepository(db, logger)
+	backupService := service.NewConfigBackupService(
+		logger,
+		lib.Config{},
+		lib.Crontab{},
+		fileService,
+		service.NewPermissionCache(logger, cache, repository.NewUserRoleRepository(db, logger)),
+		lib.ResponseCache{},
+		service.NewConfigService(logger, cache, configRepository),
+		eventbus.New(logger.DesugarZap),
+		repository.NewConfigBackupRepository(db, logger),
+		repository.NewMenuRepository(db, logger),
+		repository.NewRoleRepository(db, logger),
+		repository.NewRoleMenuRepository(db, logger),
+		repository.NewDictRepository(db, logger),
+		repository.NewDictItemRepository(db, logger),
+		configRepository,
+		service.NewLockService(logger, cache, userRepository),
+	)
+
+	record, err := backupService.Backup(system.ConfigBackupManual, "", 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, rows, record.Menus)
+	assert.Equal(t, rows, record.Roles)
+	assert.Equal(t, rows, record.Dicts)
+	assert.Equal(t, rows, record.DictItems)
+
+	bundle, err := backupService.Load(record.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, bundle.Menus, rows)
+	assert.Len(t, bundle.DictItems, rows)
+
+	diff, err := backupService.Diff(record.ID, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Zero(t, diff.Added)
+	assert.Zero(t, diff.Updated)
+	assert.Zero(t, diff.Removed)
+}
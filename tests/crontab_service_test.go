@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+)
+
+// TestCrontabServiceReload 重新加载按 t_cron_job 注册新增的运行时任务、应用执行时间与启用状态、
+// 移除数据库中已删除的运行时任务，内置任务只更新不移除
+func TestCrontabServiceReload(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.CronTask{}, &system.CronTaskRecord{})
+	crontab.RegisterHandler("test-reload", crontab.Handler{Run: func(ctx context.Context, params json.RawMessage) error { return nil }})
+
+	cron := lib.Crontab{Cron: crontab.New(crontab.NewDefaultLogger())}
+	assert.NoError(t, cron.Cron.AddTask("builtin", "0 0 * * * *", func(ctx context.Context) {}))
+	config := lib.Config{Crontab: &lib.CrontabConfig{Enable: true, ReloadInterval: -1}}
+	cronTaskRepository := repository.NewCronTaskRepository(db, logger)
+	crontabService := service.NewCrontabService(fxtest.NewLifecycle(t), config, logger, cron, lib.Metrics{},
+		cronTaskRepository, service.WebhookService{})
+
+	// 其他实例写入的任务
+	assert.NoError(t, cronTaskRepository.Create(&system.CronTask{Name: "job", Spec: "0 */5 * * * *", Handler: "test-reload", Enable: true}))
+	assert.NoError(t, cronTaskRepository.Create(&system.CronTask{Name: "builtin", Spec: "0 30 * * * *", Enable: true}))
+	assert.NoError(t, cronTaskRepository.Create(&system.CronTask{Name: "orphan", Spec: "0 */5 * * * *", Handler: "missing", Enable: true}))
+	assert.NoError(t, crontabService.Reload())
+
+	info, err := cron.Cron.GetTask("job")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0 */5 * * * *", info.Spec)
+		assert.True(t, info.Enable)
+	}
+	info, err = cron.Cron.GetTask("builtin")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0 30 * * * *", info.Spec)
+	}
+	_, err = cron.Cron.GetTask("orphan")
+	assert.Error(t, err, "tasks with unknown handlers are skipped")
+
+	// 修改执行时间与启用状态
+	assert.NoError(t, cronTaskRepository.Update("job", map[string]interface{}{"spec": "0 0 2 * * *", "enable": false}))
+	assert.NoError(t, cronTaskRepository.Update("builtin", map[string]interface{}{"enable": false}))
+	assert.NoError(t, crontabService.Reload())
+
+	info, err = cron.Cron.GetTask("job")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "0 0 2 * * *", info.Spec)
+		assert.False(t, info.Enable)
+	}
+	info, err = cron.Cron.GetTask("builtin")
+	if assert.NoError(t, err) {
+		assert.False(t, info.Enable)
+	}
+
+	// 删除记录后移除运行时任务，内置任务保留
+	assert.NoError(t, cronTaskRepository.Delete("job"))
+	assert.NoError(t, cronTaskRepository.Delete("builtin"))
+	assert.NoError(t, crontabService.Reload())
+
+	_, err = cron.Cron.GetTask("job")
+	assert.Error(t, err)
+	_, err = cron.Cron.GetTask("builtin")
+	assert.NoError(t, err)
+}
+
+// TestCrontabReloadInterval 未配置时默认每 60 秒重新加载，小于 0 时只在启动时加载
+func TestCrontabReloadInterval(t *testing.T) {
+	assert.Equal(t, "1m0s", (&lib.CrontabConfig{}).GetReloadInterval().String())
+	assert.Equal(t, "10s", (&lib.CrontabConfig{ReloadInterval: 10}).GetReloadInterval().String())
+	assert.Zero(t, (&lib.CrontabConfig{ReloadInterval: -1}).GetReloadInterval())
+}
@@ -2,10 +2,16 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/top-system/light-admin/pkg/clock"
 	"github.com/top-system/light-admin/pkg/crontab"
 )
 
@@ -366,6 +372,306 @@ func TestCrontabTaskInfo(t *testing.T) {
 	}
 }
 
+// TestValidateSpec 测试 cron 表达式校验
+func TestValidateSpec(t *testing.T) {
+	tests := []struct {
+		spec  string
+		valid bool
+	}{
+		{crontab.EveryMinute, true},
+		{"0 0 3 * * *", true},
+		{"@daily", true},
+		{"0 0 3 * *", false},
+		{"invalid", false},
+	}
+
+	for _, tt := range tests {
+		err := crontab.ValidateSpec(tt.spec)
+		if (err == nil) != tt.valid {
+			t.Errorf("Spec %q: expected valid=%v, got err=%v", tt.spec, tt.valid, err)
+		}
+	}
+}
+
+// TestMinInterval 测试 cron 表达式最小执行间隔
+func TestMinInterval(t *testing.T) {
+	tests := []struct {
+		spec     string
+		interval time.Duration
+	}{
+		{crontab.EveryMinute, time.Minute},
+		{"0 */15 * * * *", 15 * time.Minute},
+		{"0 0 8,9 * * *", time.Hour},
+		{"@every 2h", 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		got, err := crontab.MinInterval(tt.spec, 50)
+		if err != nil {
+			t.Errorf("Spec %q: unexpected error %v", tt.spec, err)
+			continue
+		}
+		if got != tt.interval {
+			t.Errorf("Spec %q: expected interval %v, got %v", tt.spec, tt.interval, got)
+		}
+	}
+
+	if _, err := crontab.MinInterval("invalid", 10); err == nil {
+		t.Error("Expected error for invalid spec")
+	}
+}
+
+// TestCrontabWithClock 测试由假时钟驱动的调度
+func TestCrontabWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	c := crontab.New(crontab.NewDefaultLogger(), crontab.WithClock(fake))
+
+	var executed int32
+	if err := c.AddTask("clock-task", "*/10 * * * * *", func(ctx context.Context) {
+		atomic.AddInt32(&executed, 1)
+	}); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	if err := c.Start(); err != nil {
+		t.Fatalf("Failed to start: %v", err)
+	}
+	defer c.Stop()
+
+	waitExecuted := func(n int32) {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for atomic.LoadInt32(&executed) < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %d executions, got %d", n, atomic.LoadInt32(&executed))
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// 未到执行时间
+	if n := c.RunDue(); n != 0 {
+		t.Fatalf("Expected nothing due, %d ran", n)
+	}
+
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Second)
+	waitExecuted(1)
+
+	// 错过的多次触发只执行一次
+	fake.Advance(35 * time.Second)
+	waitExecuted(2)
+	c.RunDue()
+
+	if n := atomic.LoadInt32(&executed); n != 2 {
+		t.Errorf("Expected 2 executions, got %d", n)
+	}
+
+	info, err := c.GetTask("clock-task")
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if !info.Next.Equal(start.Add(50 * time.Second)) {
+		t.Errorf("Expected next run at %s, got %s", start.Add(50*time.Second), info.Next)
+	}
+}
+
+// TestCrontabRecorder 测试执行记录
+func TestCrontabRecorder(t *testing.T) {
+	c := crontab.New(crontab.NewDefaultLogger())
+
+	records := make(chan crontab.Execution, 3)
+	c.SetRecorder(func(e crontab.Execution) {
+		records <- e
+	})
+
+	_ = c.AddTask("ok-task", crontab.EveryHour, func(ctx context.Context) {
+		crontab.SetResult(ctx, "purged 3 rows")
+	})
+	_ = c.AddTask("error-task", crontab.EveryHour, func(ctx context.Context) {
+		crontab.SetError(ctx, errors.New("boom"))
+	})
+	_ = c.AddTask("panic-task", crontab.EveryHour, func(ctx context.Context) {
+		panic("oops")
+	})
+
+	got := make(map[string]crontab.Execution)
+	for _, name := range []string{"ok-task", "error-task", "panic-task"} {
+		if err := c.RunTask(name); err != nil {
+			t.Fatalf("Failed to run task: %v", err)
+		}
+		select {
+		case e := <-records:
+			got[e.Name] = e
+		case <-time.After(time.Second):
+			t.Fatalf("Expected execution of %s to be recorded", name)
+		}
+	}
+
+	if e := got["ok-task"]; e.Err != nil || e.Panic != "" || !e.Manual || e.CorrelationID.IsNil() || e.Start.IsZero() || e.Result != "purged 3 rows" {
+		t.Errorf("Unexpected ok-task execution: %+v", e)
+	}
+	if e := got["error-task"]; e.Err == nil || e.Err.Error() != "boom" {
+		t.Errorf("Expected error-task to record boom, got %v", e.Err)
+	}
+	if e := got["panic-task"]; e.Panic != "oops" {
+		t.Errorf("Expected panic-task to record oops, got %q", e.Panic)
+	}
+
+	// 任务之外调用不应 panic
+	crontab.SetError(context.Background(), errors.New("ignored"))
+	crontab.SetResult(context.Background(), "ignored")
+}
+
+// TestCrontabSkipIfRunning 测试不允许重叠执行的任务及运行状态
+func TestCrontabSkipIfRunning(t *testing.T) {
+	c := crontab.New(crontab.NewDefaultLogger())
+
+	records := make(chan crontab.Execution, 1)
+	c.SetRecorder(func(e crontab.Execution) {
+		records <- e
+	})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_ = c.AddTask("slow-task", crontab.EveryHour, func(ctx context.Context) {
+		close(started)
+		<-release
+	}, crontab.WithSkipIfRunning())
+
+	if err := c.RunTask("slow-task"); err != nil {
+		t.Fatalf("Failed to run task: %v", err)
+	}
+	<-started
+
+	info, _ := c.GetTask("slow-task")
+	if !info.Running || !info.SkipIfRunning || info.LastDuration != 0 {
+		t.Errorf("Expected running task without last duration, got %+v", info)
+	}
+
+	// 上一次执行尚未结束
+	if err := c.RunTask("slow-task"); !errors.Is(err, crontab.ErrTaskRunning) {
+		t.Errorf("Expected ErrTaskRunning, got %v", err)
+	}
+
+	close(release)
+	select {
+	case <-records:
+	case <-time.After(time.Second):
+		t.Fatal("Expected execution to be recorded")
+	}
+
+	info, _ = c.GetTask("slow-task")
+	if info.Running || info.LastDuration <= 0 {
+		t.Errorf("Expected finished task with last duration, got %+v", info)
+	}
+}
+
+// TestCrontabTimeout 测试超时后取消任务的 context
+func TestCrontabTimeout(t *testing.T) {
+	c := crontab.New(crontab.NewDefaultLogger())
+
+	records := make(chan crontab.Execution, 2)
+	c.SetRecorder(func(e crontab.Execution) {
+		records <- e
+	})
+
+	_ = c.AddTask("timeout-task", crontab.EveryHour, func(ctx context.Context) {
+		<-ctx.Done()
+	}, crontab.WithTimeout(50*time.Millisecond))
+	_ = c.AddTask("fast-task", crontab.EveryHour, func(ctx context.Context) {}, crontab.WithTimeout(time.Second))
+
+	got := make(map[string]crontab.Execution)
+	for _, name := range []string{"timeout-task", "fast-task"} {
+		if err := c.RunTask(name); err != nil {
+			t.Fatalf("Failed to run task: %v", err)
+		}
+		select {
+		case e := <-records:
+			got[e.Name] = e
+		case <-time.After(time.Second):
+			t.Fatalf("Expected execution of %s to be recorded", name)
+		}
+	}
+
+	if e := got["timeout-task"]; e.Err == nil || e.Duration < 50*time.Millisecond {
+		t.Errorf("Expected timeout-task to fail after its timeout, got %+v", e)
+	}
+	if e := got["fast-task"]; e.Err != nil {
+		t.Errorf("Expected fast-task to succeed, got %v", e.Err)
+	}
+
+	info, _ := c.GetTask("timeout-task")
+	if info.Timeout != 50*time.Millisecond {
+		t.Errorf("Expected timeout of 50ms, got %s", info.Timeout)
+	}
+}
+
+// TestHTTPHandler 测试内置的 http 任务处理器
+func TestHTTPHandler(t *testing.T) {
+	var method, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	h, ok := crontab.GetHandler(crontab.HTTPHandlerName)
+	if !ok {
+		t.Fatal("Expected http handler to be registered")
+	}
+
+	for _, params := range []string{``, `{"url":"ftp://example.com"}`, `{"url":"http://example.com","method":"PATCH"}`, `{"url":"http://example.com","timeout":3600}`} {
+		if err := h.Validate(json.RawMessage(params)); err == nil {
+			t.Errorf("Expected params %q to be rejected", params)
+		}
+	}
+
+	params := json.RawMessage(`{"url":"` + srv.URL + `/ok","method":"post","body":"ping"}`)
+	if err := h.Validate(params); err != nil {
+		t.Fatalf("Failed to validate params: %v", err)
+	}
+	if err := h.Run(context.Background(), params); err != nil {
+		t.Fatalf("Failed to run handler: %v", err)
+	}
+	if method != http.MethodPost || body != "ping" {
+		t.Errorf("Expected POST ping, got %s %q", method, body)
+	}
+
+	if err := h.Run(context.Background(), json.RawMessage(`{"url":"`+srv.URL+`/fail"}`)); err == nil {
+		t.Error("Expected error for 500 response")
+	}
+}
+
+// TestRegisterHandler 测试注册自定义任务处理器
+func TestRegisterHandler(t *testing.T) {
+	crontab.RegisterHandler("test-handler", crontab.Handler{
+		Run: func(ctx context.Context, params json.RawMessage) error { return nil },
+	})
+
+	if _, ok := crontab.GetHandler("test-handler"); !ok {
+		t.Fatal("Expected handler to be registered")
+	}
+	if _, ok := crontab.GetHandler("missing-handler"); ok {
+		t.Error("Expected missing handler not to be found")
+	}
+
+	found := false
+	for _, name := range crontab.Handlers() {
+		if name == "test-handler" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected Handlers to include test-handler")
+	}
+}
+
 // TestDefaultLogger 测试默认日志记录器
 func TestDefaultLogger(t *testing.T) {
 	logger := crontab.NewDefaultLogger()
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+type dataScopeFixture struct {
+	db                 lib.Database
+	userRepository     repository.UserRepository
+	deptRepository     repository.DeptRepository
+	roleDeptRepository repository.RoleDeptRepository
+	userService        service.UserService
+}
+
+// newDataScopeFixture 部门 1 下有子部门 2，部门 3 与其平级；用户 1 在部门 2，用户 2 在部门 3
+func newDataScopeFixture(t *testing.T) *dataScopeFixture {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.User{}, &system.Dept{}, &system.RoleDept{}, &system.UserRole{}, &system.Role{})
+
+	for _, dept := range []*system.Dept{
+		{ID: 1, Name: "总部", Code: "HQ", TreePath: "0"},
+		{ID: 2, Name: "研发部", Code: "RD", ParentID: 1, TreePath: "0,1"},
+		{ID: 3, Name: "分部", Code: "BR", TreePath: "0"},
+	} {
+		assert.NoError(t, db.ORM.Create(dept).Error)
+	}
+	for _, user := range []*system.User{
+		{ID: 1, Username: "alice", DeptID: 2},
+		{ID: 2, Username: "bob", DeptID: 3},
+	} {
+		assert.NoError(t, db.ORM.Create(user).Error)
+		assert.NoError(t, db.ORM.Model(user).Update("status", 0).Error)
+	}
+
+	f := &dataScopeFixture{
+		db:                 db,
+		userRepository:     repository.NewUserRepository(db, logger),
+		deptRepository:     repository.NewDeptRepository(db, logger, lib.DBCompat{}),
+		roleDeptRepository: repository.NewRoleDeptRepository(db, logger),
+	}
+	f.userService = service.NewUserService(
+		logger, lib.Config{SuperAdmin: &lib.SuperAdminConfig{Username: "root"}}, db,
+		f.userRepository, repository.NewUserRoleRepository(db, logger), repository.NewUserPositionRepository(db, logger),
+		repository.NewApiKeyRepository(db, logger), repository.NewRoleRepository(db, logger), repository.NewRoleMenuRepository(db, logger),
+		repository.NewMenuRepository(db, logger), f.deptRepository,
+		service.NewPermissionCache(logger, newTestCache(t), repository.NewUserRoleRepository(db, logger)), service.PermissionService{},
+		lib.NewResponseCache(lib.Config{}, newTestCache(t), logger), service.DeptRoleService{}, service.AuthService{}, service.WebhookService{}, nil,
+	)
+	return f
+}
+
+func TestUserCheckDataScope(t *testing.T) {
+	f := newDataScopeFixture(t)
+	scope := &system.DataScope{DeptIDs: []uint64{1, 2}, UserID: 9}
+
+	assert.NoError(t, f.userService.CheckDataScope(scope, 1))
+	assert.True(t, errors.Is(f.userService.CheckDataScope(scope, 2), errors.UserOutOfDataScope))
+	assert.True(t, errors.Is(f.userService.CheckDataScope(scope, 1, 2), errors.UserOutOfDataScope))
+	assert.True(t, errors.Is(f.userService.CheckDataScope(scope, 404), errors.UserOutOfDataScope))
+	assert.NoError(t, f.userService.CheckDataScope(&system.DataScope{All: true}, 2))
+	assert.NoError(t, f.userService.CheckDataScope(nil, 2))
+
+	// 仅本人
+	self := &system.DataScope{UserID: 2}
+	assert.NoError(t, f.userService.CheckDataScope(self, 2))
+	assert.True(t, errors.Is(f.userService.CheckDataScope(self, 1), errors.UserOutOfDataScope))
+
+	// 修改用户时部门不能超出范围，保持原部门不变除外
+	assert.NoError(t, f.userService.CheckDeptScope(scope, 0, 2))
+	assert.True(t, errors.Is(f.userService.CheckDeptScope(scope, 0, 3), errors.UserDeptOutOfDataScope))
+	assert.NoError(t, f.userService.CheckDeptScope(self, 2, 3))
+	assert.True(t, errors.Is(f.userService.CheckDeptScope(self, 2, 1), errors.UserDeptOutOfDataScope))
+}
+
+func TestUserBulkSkipsUsersOutsideDataScope(t *testing.T) {
+	f := newDataScopeFixture(t)
+
+	result, err := f.userService.Bulk(&system.UserBulkForm{
+		UserIDs:   []uint64{1, 2},
+		Operation: system.UserBulkEnable,
+		DataScope: &system.DataScope{DeptIDs: []uint64{2}},
+	}, 9)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, errors.UserOutOfDataScope.Error(), result.Results[1].Message)
+	assert.Empty(t, result.Results[1].Username)
+
+	alice, _ := f.userRepository.Get(1)
+	bob, _ := f.userRepository.Get(2)
+	assert.Equal(t, 1, alice.Status)
+	assert.Equal(t, 0, bob.Status)
+
+	_, err = f.userService.Bulk(&system.UserBulkForm{
+		UserIDs:   []uint64{1},
+		Operation: system.UserBulkTransferDept,
+		DeptID:    3,
+		DataScope: &system.DataScope{DeptIDs: []uint64{2}},
+	}, 9)
+	assert.True(t, errors.Is(err, errors.UserDeptOutOfDataScope))
+}
+
+// TestDataScopeIncludesAllChildDepts 本部门及子部门的范围不受默认分页大小限制
+func TestDataScopeIncludesAllChildDepts(t *testing.T) {
+	f := newDataScopeFixture(t)
+	logger := newTestLogger()
+
+	for i := 10; i < 30; i++ {
+		assert.NoError(t, f.db.ORM.Create(&system.Dept{ID: uint64(i), Name: fmt.Sprint(i), Code: fmt.Sprintf("D%d", i), ParentID: 2, TreePath: "0,1,2"}).Error)
+	}
+	assert.NoError(t, f.db.ORM.Create(&system.User{ID: 5, Username: "carol", DeptID: 1}).Error)
+	assert.NoError(t, f.db.ORM.Create(&system.Role{ID: 1, Name: "主管", Code: "LEAD", Status: 1, DataScope: system.DataScopeDeptAndChild}).Error)
+	assert.NoError(t, f.db.ORM.Create(&system.UserRole{UserID: 5, RoleID: 1}).Error)
+
+	roleRepository := repository.NewRoleRepository(f.db, logger)
+	userRoleRepository := repository.NewUserRoleRepository(f.db, logger)
+	permissionService := service.NewPermissionService(
+		logger, lib.HttpHandler{}, lib.NewPermRegistry(), lib.NewFeatureModules(),
+		service.NewPermissionCache(logger, newTestCache(t), userRoleRepository),
+		repository.NewMenuRepository(f.db, logger), repository.NewRoleMenuRepository(f.db, logger),
+		userRoleRepository, roleRepository,
+	)
+	dataScopeService := service.NewDataScopeService(
+		lib.Config{SuperAdmin: &lib.SuperAdminConfig{Username: "root"}}, logger,
+		f.userRepository, roleRepository, f.roleDeptRepository, f.deptRepository, permissionService,
+	)
+
+	scope, err := dataScopeService.Resolve(&dto.JwtClaims{ID: 5, Username: "carol"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, scope.All)
+	assert.Len(t, scope.DeptIDs, 22)
+	assert.True(t, scope.HasDept(29))
+	assert.False(t, scope.HasDept(3))
+}
+
+func TestDeptDeleteRemovesRoleDeptRows(t *testing.T) {
+	f := newDataScopeFixture(t)
+	assert.NoError(t, f.db.ORM.Create(&system.Dept{ID: 4, Name: "空部门", Code: "EMPTY", TreePath: "0"}).Error)
+	assert.NoError(t, f.db.ORM.Create(&system.Dept{ID: 5, Name: "空子部门", Code: "EMPTY_CHILD", ParentID: 4, TreePath: "0,4"}).Error)
+	assert.NoError(t, f.roleDeptRepository.Replace(1, []uint64{2, 4, 5}))
+
+	deptService := service.NewDeptService(newTestLogger(), f.deptRepository, f.userRepository, f.roleDeptRepository, lib.NewResponseCache(lib.Config{}, newTestCache(t), newTestLogger()))
+	assert.NoError(t, deptService.DeleteByIds("4", 1))
+
+	deptIDs, err := f.roleDeptRepository.GetDeptIDsByRoleIDs([]uint64{1})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{2}, deptIDs)
+}
+
+// TestDownloadCheckDataScope 按 ID 访问下载任务时同样按创建人所在部门限制，超出范围按不存在处理
+func TestDownloadCheckDataScope(t *testing.T) {
+	f := newDataScopeFixture(t)
+	assert.NoError(t, f.db.ORM.AutoMigrate(&system.DownloadTask{}, &system.DownloaderConfig{}))
+	for _, task := range []*system.DownloadTask{
+		{ID: 1, TaskID: "a", Name: "alice.iso", OwnerID: 1},
+		{ID: 2, TaskID: "b", Name: "bob.iso", OwnerID: 2},
+	} {
+		assert.NoError(t, f.db.ORM.Create(task).Error)
+	}
+	downloadService := newTestDownloadService(t, f.db, lib.Config{})
+
+	scope := &system.DataScope{DeptIDs: []uint64{2}, UserID: 9}
+	assert.NoError(t, downloadService.CheckDataScope(scope, 1))
+	assert.True(t, errors.Is(downloadService.CheckDataScope(scope, 2), errors.DatabaseRecordNotFound))
+	assert.True(t, errors.Is(downloadService.CheckDataScope(scope, 1, 2), errors.DatabaseRecordNotFound))
+	assert.True(t, errors.Is(downloadService.CheckDataScope(scope, 404), errors.DatabaseRecordNotFound))
+	assert.NoError(t, downloadService.CheckDataScope(&system.DataScope{All: true}, 2))
+
+	// 仅本人
+	assert.NoError(t, downloadService.CheckDataScope(&system.DataScope{UserID: 2}, 2))
+	assert.True(t, errors.Is(downloadService.CheckDataScope(&system.DataScope{UserID: 2}, 1), errors.DatabaseRecordNotFound))
+}
+
+// TestNoticeCheckDataScope 按 ID 访问通知公告时同样按创建人所在部门限制，已删除的通知公告视为不存在
+func TestNoticeCheckDataScope(t *testing.T) {
+	f := newDataScopeFixture(t)
+	assert.NoError(t, f.db.ORM.AutoMigrate(&system.Notice{}))
+	for _, notice := range []*system.Notice{
+		{ID: 1, Title: "alice", CreateBy: 1},
+		{ID: 2, Title: "bob", CreateBy: 2},
+		{ID: 3, Title: "deleted", CreateBy: 1, IsDeleted: 1},
+	} {
+		assert.NoError(t, f.db.ORM.Create(notice).Error)
+	}
+	logger := newTestLogger()
+	noticeService := service.NewNoticeService(
+		logger, lib.Config{}, lib.Crontab{}, lib.NewFeatureModules(), nil,
+		repository.NewNoticeRepository(f.db, logger, lib.DBCompat{}), repository.NewUserNoticeRepository(f.db, logger, lib.DBCompat{}),
+		f.userRepository, repository.NewNoticeAttachmentRepository(f.db, logger), repository.NewNoticeRevisionRepository(f.db, logger),
+		service.WsEventService{}, service.MailService{}, service.WebhookService{},
+	)
+
+	scope := &system.DataScope{DeptIDs: []uint64{1, 2}}
+	assert.NoError(t, noticeService.CheckDataScope(scope, 1))
+	assert.True(t, errors.Is(noticeService.CheckDataScope(scope, 2), errors.DatabaseRecordNotFound))
+	assert.True(t, errors.Is(noticeService.CheckDataScope(scope, 3), errors.DatabaseRecordNotFound))
+	assert.NoError(t, noticeService.CheckDataScope(nil, 2))
+}
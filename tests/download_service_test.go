@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TestDownloadRejectsInternalURL 下载由服务端发起，指向本机或内网的地址在提交时拒绝
+func TestDownloadRejectsInternalURL(t *testing.T) {
+	db := newTestDB(t, &system.DownloaderConfig{}, &system.DownloadTask{})
+	downloadService := newTestDownloadService(t, db, lib.Config{})
+
+	for _, u := range []string{"http://127.0.0.1:8080/file", "http://localhost/file", "https://169.254.169.254/latest", "ftp://10.0.0.1/a", "file:///etc/passwd"} {
+		vo, err := downloadService.DryRun(context.Background(), &system.DownloadTaskCreateForm{URL: u}, 1)
+		if assert.NoError(t, err) && assert.NotEmpty(t, vo.Checks) {
+			assert.Equal(t, "url", vo.Checks[0].Name)
+			assert.False(t, vo.Checks[0].Passed, u)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/downloader/qbittorrent"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// TestDownloadTransferCleanupConfinedToTempFolder 转移后只删除位于下载器临时目录中任务目录下的保存目录，
+// 保存目录在共享目录或就是任务目录本身时保留，不影响其他任务的文件
+func TestDownloadTransferCleanupConfinedToTempFolder(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.DownloadTask{})
+	dir := t.TempDir()
+	tempPath := filepath.Join(dir, "temp")
+	taskRoot := filepath.Join(tempPath, qbittorrent.QBittorrentTempFolder)
+
+	config := lib.Config{Downloader: &lib.DownloaderConfig{
+		Transfer: &lib.DownloadTransferConfig{Enable: true, Dir: filepath.Join(dir, "done")},
+	}}
+	q := queue.New(queue.NewDefaultLogger(), nil, queue.NewTaskRegistry(), queue.WithSynchronousExecution())
+	q.Start()
+	defer q.Shutdown()
+
+	downloadRepository := repository.NewDownloadRepository(db, logger)
+	transferService := service.NewDownloadTransferService(logger, config, lib.TaskQueue{Queue: q}, downloadRepository,
+		service.DownloadMediaService{}, nil)
+
+	cases := map[string]struct {
+		savePath string
+		deleted  bool
+	}{
+		"task folder":   {filepath.Join(taskRoot, "task-1"), true},
+		"shared folder": {filepath.Join(dir, "shared"), false},
+		"temp root":     {taskRoot, false},
+	}
+	for name, c := range cases {
+		assert.NoError(t, os.MkdirAll(c.savePath, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(c.savePath, name+".txt"), []byte(name), 0644))
+		other := filepath.Join(c.savePath, "other.txt")
+		assert.NoError(t, os.WriteFile(other, []byte("another download"), 0644))
+
+		task := &system.DownloadTask{Name: name, Downloader: "qbittorrent", SavePath: c.savePath, OwnerID: 1}
+		assert.NoError(t, downloadRepository.Create(task))
+		files := []downloader.TaskFile{{Name: name + ".txt", Selected: true}}
+		ok, err := transferService.Schedule(task, c.savePath, tempPath, files, nil)
+		if !assert.NoError(t, err, name) || !assert.True(t, ok, name) {
+			continue
+		}
+
+		task, err = downloadRepository.Get(task.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, system.DownloadTransferStatusTransferred, task.TransferStatus, name)
+		}
+		_, err = os.Stat(other)
+		if c.deleted {
+			assert.True(t, os.IsNotExist(err), "%s should be deleted", name)
+		} else {
+			assert.NoError(t, err, "%s is outside the downloader temp folder and must be kept", name)
+		}
+	}
+}
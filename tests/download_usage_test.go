@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// newFakeAria2 只响应 aria2.tellStatus 的 JSON-RPC 服务，completed 为当前返回的已下载量
+func newFakeAria2(t *testing.T, completed *atomic.Int64) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Method != "aria2.tellStatus" {
+			http.Error(w, "unsupported", http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": map[string]any{
+				"gid":             "gid-1",
+				"status":          "active",
+				"totalLength":     "100000",
+				"completedLength": strconv.FormatInt(completed.Load(), 10),
+				"uploadLength":    "0",
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestDownloadUsageCountedOncePerSync(t *testing.T) {
+	var completed atomic.Int64
+	server := newFakeAria2(t, &completed)
+
+	db := newTestDB(t, &system.DownloadTask{}, &system.DownloadUsage{}, &system.DownloaderConfig{})
+	assert.NoError(t, db.ORM.Create(&system.DownloaderConfig{Name: "nas", Type: system.DownloaderTypeAria2, Server: server}).Error)
+	assert.NoError(t, db.ORM.Create(&system.DownloadTask{ID: 1, TaskID: "gid-1", Downloader: "nas", Status: "downloading", OwnerID: 7}).Error)
+
+	downloadService := newTestDownloadService(t, db, lib.Config{Downloader: &lib.DownloaderConfig{}})
+	downloaded := func() int64 {
+		var usage system.DownloadUsage
+		db.ORM.Where("user_id = ?", 7).Take(&usage)
+		return usage.Downloaded
+	}
+
+	completed.Store(1000)
+	assert.NoError(t, downloadService.SyncTaskStatus(t.Context(), 1))
+	assert.Equal(t, int64(1000), downloaded())
+
+	// 并发同步同一任务，只计入一次差值
+	completed.Store(5000)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			downloadService.SyncTaskStatus(t.Context(), 1)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(5000), downloaded())
+
+	// 下载器重置计数时不计入负差值
+	completed.Store(200)
+	assert.NoError(t, downloadService.SyncTaskStatus(t.Context(), 1))
+	completed.Store(700)
+	assert.NoError(t, downloadService.SyncTaskStatus(t.Context(), 1))
+	assert.Equal(t, int64(5500), downloaded())
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+// newTestDownloadService 不连接任何下载器的下载服务，只加载数据库中的下载器配置
+func newTestDownloadService(t *testing.T, db lib.Database, config lib.Config) service.DownloadService {
+	t.Helper()
+
+	logger := newTestLogger()
+	return service.NewDownloadService(
+		fxtest.NewLifecycle(t), logger, config, db,
+		repository.NewDownloadRepository(db, logger), repository.NewTagRepository(db, logger), repository.NewDownloaderConfigRepository(db, logger),
+		lib.TaskQueue{}, lib.Crontab{}, lib.NewFeatureModules(),
+		service.NewDownloadUsageService(logger, config, repository.NewDownloadUsageRepository(db, logger, lib.DBCompat{})),
+		service.DownloadMediaService{}, service.DownloadTransferService{},
+		service.WsEventService{}, service.NoticeService{}, nil,
+		repository.NewUserRepository(db, logger), ws.New(zap.NewNop()), lib.Metrics{},
+	)
+}
+
+func newDownloaderConfigTestService(t *testing.T, encryptKey string) (service.DownloaderConfigService, lib.Database) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.DownloaderConfig{}, &system.DownloadTask{})
+	config := lib.Config{Downloader: &lib.DownloaderConfig{EncryptKey: encryptKey}}
+
+	downloadService := newTestDownloadService(t, db, config)
+	return service.NewDownloaderConfigService(
+		logger, config, repository.NewDownloaderConfigRepository(db, logger),
+		repository.NewDownloadRepository(db, logger), downloadService,
+	), db
+}
+
+func TestDownloaderConfigValidatesForm(t *testing.T) {
+	configService, _ := newDownloaderConfigTestService(t, "downloader-key")
+
+	cases := map[string]*system.DownloaderConfigForm{
+		"blank name":      {Name: "  ", Type: system.DownloaderTypeAria2, Server: "http://127.0.0.1:6800/jsonrpc"},
+		"unknown type":    {Name: "nas", Type: "transmission", Server: "http://127.0.0.1:9091"},
+		"missing server":  {Name: "nas", Type: system.DownloaderTypeAria2},
+		"relative server": {Name: "nas", Type: system.DownloaderTypeAria2, Server: "127.0.0.1:6800"},
+		"ftp server":      {Name: "nas", Type: system.DownloaderTypeAria2, Server: "ftp://127.0.0.1"},
+		"qb websocket":    {Name: "nas", Type: system.DownloaderTypeQBittorrent, Server: "ws://127.0.0.1:8080"},
+		"long name":       {Name: strings.Repeat("n", 51), Type: system.DownloaderTypeAria2, Server: "http://127.0.0.1:6800/jsonrpc"},
+	}
+	for name, form := range cases {
+		_, err := configService.Create(form, 1)
+		assert.True(t, errors.Is(err, errors.DownloaderConfigInvalid), "%s: got %v", name, err)
+	}
+
+	conf, err := configService.Create(&system.DownloaderConfigForm{Name: "nas", Type: system.DownloaderTypeAria2, Server: "ws://127.0.0.1:6800/jsonrpc"}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = configService.Update(conf.ID, &system.DownloaderConfigForm{Name: "nas", Type: "transmission", Server: "http://127.0.0.1:9091"}, 1)
+	assert.True(t, errors.Is(err, errors.DownloaderConfigInvalid))
+}
+
+func TestDownloaderConfigEncryptsSecrets(t *testing.T) {
+	configService, db := newDownloaderConfigTestService(t, "downloader-key")
+
+	conf, err := configService.Create(&system.DownloaderConfigForm{
+		Name:     "nas",
+		Type:     system.DownloaderTypeQBittorrent,
+		Server:   "http://127.0.0.1:8080",
+		User:     "admin",
+		Password: "qb-pass",
+	}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, conf.HasPassword)
+	assert.True(t, conf.Loaded)
+
+	stored := new(system.DownloaderConfig)
+	assert.NoError(t, db.ORM.First(stored, conf.ID).Error)
+	assert.NotEmpty(t, stored.Password)
+	assert.NotContains(t, stored.Password, "qb-pass")
+
+	// 密码为空时保持原密文不变
+	_, err = configService.Update(conf.ID, &system.DownloaderConfigForm{Name: "nas", Type: system.DownloaderTypeQBittorrent, Server: "http://127.0.0.1:8081", User: "admin"}, 1)
+	assert.NoError(t, err)
+	updated := new(system.DownloaderConfig)
+	assert.NoError(t, db.ORM.First(updated, conf.ID).Error)
+	assert.Equal(t, stored.Password, updated.Password)
+	assert.Equal(t, "http://127.0.0.1:8081", updated.Server)
+}
+
+func TestDownloaderConfigRequiresEncryptKeyForSecrets(t *testing.T) {
+	configService, _ := newDownloaderConfigTestService(t, "")
+
+	_, err := configService.Create(&system.DownloaderConfigForm{Name: "nas", Type: system.DownloaderTypeAria2, Server: "http://127.0.0.1:6800/jsonrpc", Token: "secret"}, 1)
+	assert.True(t, errors.Is(err, errors.DownloaderConfigNoKey))
+
+	// 不带密钥的下载器可以保存
+	_, err = configService.Create(&system.DownloaderConfigForm{Name: "nas", Type: system.DownloaderTypeAria2, Server: "http://127.0.0.1:6800/jsonrpc"}, 1)
+	assert.NoError(t, err)
+}
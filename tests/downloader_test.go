@@ -2,11 +2,14 @@ package tests
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -74,6 +77,17 @@ func TestTaskStatusMethods(t *testing.T) {
 
 		status = &downloader.TaskStatus{State: downloader.StatusCompleted}
 		assert.False(t, status.IsActive())
+
+		status = &downloader.TaskStatus{State: downloader.StatusPaused}
+		assert.False(t, status.IsActive())
+	})
+
+	t.Run("IsPaused", func(t *testing.T) {
+		status := &downloader.TaskStatus{State: downloader.StatusPaused}
+		assert.True(t, status.IsPaused())
+
+		status = &downloader.TaskStatus{State: downloader.StatusDownloading}
+		assert.False(t, status.IsPaused())
 	})
 }
 
@@ -359,6 +373,8 @@ func TestMockQBittorrentServer(t *testing.T) {
 			w.WriteHeader(http.StatusOK)
 		case "torrents/filePrio":
 			w.WriteHeader(http.StatusOK)
+		case "torrents/pause", "torrents/resume":
+			w.WriteHeader(http.StatusOK)
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
@@ -443,11 +459,181 @@ func TestMockQBittorrentServer(t *testing.T) {
 		err = client.Cancel(ctx, handle)
 		require.NoError(t, err)
 	})
+
+	t.Run("Pause and resume task", func(t *testing.T) {
+		loggedIn = true
+		client, err := qbittorrent.New(&testLogger{t: t}, &qbittorrent.Settings{
+			Server:   server.URL,
+			User:     "admin",
+			Password: "adminadmin",
+		})
+		require.NoError(t, err)
+
+		handle := &downloader.TaskHandle{ID: "test-id", Hash: "abc123"}
+		require.NoError(t, client.Pause(ctx, handle))
+		require.NoError(t, client.Resume(ctx, handle))
+	})
+}
+
+// TestQBittorrentPauseV5 qBittorrent 5 将 torrents/pause、torrents/resume 改名为 torrents/stop、torrents/start
+func TestQBittorrentPauseV5(t *testing.T) {
+	var called []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v2/")
+		switch path {
+		case "torrents/stop", "torrents/start":
+			require.NoError(t, r.ParseMultipartForm(1024))
+			assert.Equal(t, "abc123", r.FormValue("hashes"))
+			called = append(called, path)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := qbittorrent.New(&testLogger{t: t}, &qbittorrent.Settings{Server: server.URL})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	handle := &downloader.TaskHandle{ID: "test-id", Hash: "abc123"}
+	require.NoError(t, client.Pause(ctx, handle))
+	require.NoError(t, client.Resume(ctx, handle))
+	assert.Equal(t, []string{"torrents/stop", "torrents/start"}, called)
+}
+
+// TestQBittorrentCreateTaskFromFile 种子文件以 torrents 字段上传，metalink 不支持
+func TestQBittorrentCreateTaskFromFile(t *testing.T) {
+	var uploaded, tags string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/torrents/add", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1024))
+		assert.Empty(t, r.FormValue("urls"))
+		f, fh, err := r.FormFile("torrents")
+		require.NoError(t, err)
+		defer f.Close()
+		assert.Equal(t, "ubuntu.torrent", fh.Filename)
+		buf := new(strings.Builder)
+		_, _ = io.Copy(buf, f)
+		uploaded, tags = buf.String(), r.FormValue("tags")
+		w.Write([]byte("Ok."))
+	}))
+	defer server.Close()
+
+	client, err := qbittorrent.New(&testLogger{t: t}, &qbittorrent.Settings{Server: server.URL, TempPath: t.TempDir()})
+	require.NoError(t, err)
+	creator, ok := client.(downloader.FileCreator)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	handle, err := creator.CreateTaskFromFile(ctx, &downloader.SourceFile{Name: "ubuntu.torrent", Content: []byte("d4:infoe")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "d4:infoe", uploaded)
+	assert.Equal(t, "dl-"+handle.ID, tags)
+
+	_, err = creator.CreateTaskFromFile(ctx, &downloader.SourceFile{Name: "ubuntu.meta4"}, nil)
+	assert.ErrorIs(t, err, downloader.ErrUnsupportedFile)
+}
+
+// TestAria2CreateTaskFromFile 种子文件以 base64 通过 aria2.addTorrent 提交，metalink 使用返回的第一个 GID
+func TestAria2CreateTaskFromFile(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string        `json:"method"`
+			ID     uint64        `json:"id"`
+			Params []interface{} `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		methods = append(methods, req.Method)
+
+		require.GreaterOrEqual(t, len(req.Params), 3)
+		assert.Equal(t, "token:secret", req.Params[0])
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("content")), req.Params[1])
+
+		var result interface{} = "2089b05ecca3d829"
+		options := req.Params[len(req.Params)-1].(map[string]interface{})
+		if req.Method == "aria2.addTorrent" {
+			assert.Equal(t, []interface{}{}, req.Params[2])
+		} else {
+			result = []string{"d829b05ecca32089", "2089b05ecca3d829"}
+		}
+		assert.Equal(t, "mem", options["follow-torrent"])
+		assert.NotEmpty(t, options["dir"])
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer server.Close()
+
+	client := aria2.New(&testLogger{t: t}, &aria2.Settings{Server: server.URL, Token: "secret", TempPath: t.TempDir()})
+	creator, ok := client.(downloader.FileCreator)
+	require.True(t, ok)
+
+	ctx := context.Background()
+	handle, err := creator.CreateTaskFromFile(ctx, &downloader.SourceFile{Name: "ubuntu.torrent", Content: []byte("content")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", handle.ID)
+
+	handle, err = creator.CreateTaskFromFile(ctx, &downloader.SourceFile{Name: "ubuntu.metalink", Content: []byte("content")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "d829b05ecca32089", handle.ID)
+	assert.Equal(t, []string{"aria2.addTorrent", "aria2.addMetalink"}, methods)
+
+	_, err = creator.CreateTaskFromFile(ctx, &downloader.SourceFile{Name: "ubuntu.nzb"}, nil)
+	assert.ErrorIs(t, err, downloader.ErrUnsupportedFile)
+}
+
+// TestQBittorrentSpeedLimit 限速以 KB/s 传入，qBittorrent 接口使用字节/秒
+func TestQBittorrentSpeedLimit(t *testing.T) {
+	limits := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v2/")
+		require.NoError(t, r.ParseMultipartForm(1024))
+		assert.Equal(t, "abc123", r.FormValue("hashes"))
+		limits[path] = r.FormValue("limit")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := qbittorrent.New(&testLogger{t: t}, &qbittorrent.Settings{Server: server.URL})
+	require.NoError(t, err)
+
+	handle := &downloader.TaskHandle{ID: "test-id", Hash: "abc123"}
+	require.NoError(t, client.SetSpeedLimit(context.Background(), handle, 512, 0))
+	assert.Equal(t, map[string]string{
+		"torrents/setDownloadLimit": "524288",
+		"torrents/setUploadLimit":   "0",
+	}, limits)
+}
+
+func TestAria2ContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// aria2 未响应，直到请求被取消
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := aria2.New(&testLogger{t: t}, &aria2.Settings{Server: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Test(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "RPC should stop with the context instead of the client timeout")
 }
 
 func TestStatusConstants(t *testing.T) {
 	assert.Equal(t, downloader.Status("downloading"), downloader.StatusDownloading)
 	assert.Equal(t, downloader.Status("seeding"), downloader.StatusSeeding)
+	assert.Equal(t, downloader.Status("paused"), downloader.StatusPaused)
 	assert.Equal(t, downloader.Status("completed"), downloader.StatusCompleted)
 	assert.Equal(t, downloader.Status("error"), downloader.StatusError)
 	assert.Equal(t, downloader.Status("unknown"), downloader.StatusUnknown)
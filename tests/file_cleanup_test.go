@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/pkg/file"
+)
+
+// TestFileCleanupKeepsLegacyUploads 去重功能上线前上传的文件没有登记记录，不应被当作孤立对象删除
+func TestFileCleanupKeepsLegacyUploads(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &platform.FileObject{})
+	repo := repository.NewFileObjectRepository(db, logger)
+
+	root := t.TempDir()
+	storage := service.NewLocalFileService(root, logger)
+
+	old := time.Now().Add(-48 * time.Hour)
+	write := func(key string) {
+		t.Helper()
+		full := filepath.Join(root, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(key), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(full, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	legacy := "20231231/0f8fad5b-d9cb-469f-a165-70867728950e.png"
+	recorded := file.ShardPath("20240101", strings.Repeat("ab", 32), ".png")
+	orphan := file.ShardPath("20240101", strings.Repeat("cd", 32), ".png")
+	fresh := file.ShardPath("20240101", strings.Repeat("ef", 32), ".png")
+	for _, key := range []string{legacy, recorded, orphan} {
+		write(key)
+	}
+	write(fresh)
+	_ = os.Chtimes(filepath.Join(root, filepath.FromSlash(fresh)), time.Now(), time.Now())
+
+	assert.NoError(t, repo.Create(&platform.FileObject{Hash: strings.Repeat("ab", 32), URL: "/" + recorded, RefCount: 1}))
+	assert.NoError(t, repo.Create(&platform.FileObject{Hash: strings.Repeat("12", 32), URL: "/20240101/12/missing.png", RefCount: 1}))
+
+	cleanup := service.NewFileCleanupService(logger, lib.Config{}, lib.Crontab{}, storage, lib.NewFeatureModules(), repo)
+	report, err := cleanup.Run(false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 4, report.ScannedObjects)
+	if assert.Len(t, report.OrphanObjects, 1) {
+		assert.Equal(t, "/"+orphan, report.OrphanObjects[0].URL)
+	}
+	assert.Len(t, report.DanglingRecords, 1)
+
+	for key, exists := range map[string]bool{legacy: true, recorded: true, fresh: true, orphan: false} {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+		assert.Equal(t, exists, err == nil, key)
+	}
+}
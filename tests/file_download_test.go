@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/models/platform"
+)
+
+func newFileDownloadTestService(t *testing.T) *service.ObjectFileService {
+	logger := newTestLogger()
+	db := newTestDB(t, &platform.FileObject{})
+	return service.NewObjectFileService(service.NewLocalFileService(t.TempDir(), logger), repository.NewFileObjectRepository(db, logger), logger)
+}
+
+// signedParam 解析签名下载地址的参数
+func signedParam(t *testing.T, downloadURL string) *platform.FileSignedDownloadParam {
+	t.Helper()
+
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, service.FileSignedDownloadPath, u.Path)
+
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	return &platform.FileSignedDownloadParam{FilePath: u.Query().Get("filePath"), Expires: expires, Sign: u.Query().Get("sign")}
+}
+
+func TestFileSignedDownload(t *testing.T) {
+	fileService := newFileDownloadTestService(t)
+	fileService.SetSignKey("file-sign-key", time.Hour)
+
+	info, err := fileService.UploadFile("report.txt", strings.NewReader("hello"), 5, "text/plain")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	param := signedParam(t, info.DownloadURL)
+	assert.Equal(t, info.URL, param.FilePath)
+
+	object, err := fileService.OpenSigned(param)
+	if assert.NoError(t, err) {
+		data, _ := io.ReadAll(object.Reader)
+		object.Reader.Close()
+		assert.Equal(t, "hello", string(data))
+	}
+
+	// 签名只对应签发的文件与有效期
+	other := *param
+	other.FilePath = "/other.txt"
+	_, err = fileService.OpenSigned(&other)
+	assert.True(t, errors.Is(err, errors.FileSignInvalid))
+
+	extended := *param
+	extended.Expires += 3600
+	_, err = fileService.OpenSigned(&extended)
+	assert.True(t, errors.Is(err, errors.FileSignInvalid))
+
+	expired := *param
+	expired.Expires = time.Now().Add(-time.Minute).Unix()
+	_, err = fileService.OpenSigned(&expired)
+	assert.True(t, errors.Is(err, errors.FileSignInvalid))
+
+	// 复用相同内容的文件同样返回签名下载地址
+	reused, err := fileService.UploadFile("copy.txt", strings.NewReader("hello"), 5, "text/plain")
+	if assert.NoError(t, err) {
+		assert.Equal(t, info.URL, signedParam(t, reused.DownloadURL).FilePath)
+	}
+}
+
+func TestFileSignedDownloadRequiresSignKey(t *testing.T) {
+	fileService := newFileDownloadTestService(t)
+
+	info, err := fileService.UploadFile("report.txt", strings.NewReader("hello"), 5, "text/plain")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, info.DownloadURL)
+
+	_, err = fileService.OpenSigned(&platform.FileSignedDownloadParam{FilePath: info.URL, Expires: time.Now().Add(time.Hour).Unix()})
+	assert.True(t, errors.Is(err, errors.FileSignInvalid))
+}
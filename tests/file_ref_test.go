@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/platform/repository"
+	"github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+)
+
+func newFileRefTestService(t *testing.T) (*service.ObjectFileService, repository.FileObjectRepository, lib.Database, string) {
+	logger := newTestLogger()
+	db := newTestDB(t, &platform.FileObject{})
+	root := t.TempDir()
+	repo := repository.NewFileObjectRepository(db, logger)
+
+	return service.NewObjectFileService(service.NewLocalFileService(root, logger), repo, logger), repo, db, root
+}
+
+// storedFiles 存储目录中的文件数
+func storedFiles(t *testing.T, root string) int {
+	count := 0
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	return count
+}
+
+// TestFileDeleteReleasesLastReference 最后一个引用删除时同时删除记录与存储文件，已删除的记录不能再被复用
+func TestFileDeleteReleasesLastReference(t *testing.T) {
+	fileService, repo, _, root := newFileRefTestService(t)
+
+	first, err := fileService.UploadFile("a.txt", strings.NewReader("hello"), 5, "text/plain")
+	if !assert.NoError(t, err) {
+		return
+	}
+	second, err := fileService.UploadFile("b.txt", strings.NewReader("hello"), 5, "text/plain")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, first.URL, second.URL)
+
+	object, err := repo.GetByURL(first.URL)
+	if !assert.NoError(t, err) || !assert.NotNil(t, object) {
+		return
+	}
+	assert.Equal(t, 2, object.RefCount)
+
+	assert.NoError(t, fileService.DeleteFile(first.URL))
+	assert.Equal(t, 1, storedFiles(t, root))
+
+	assert.NoError(t, fileService.DeleteFile(second.URL))
+	assert.Equal(t, 0, storedFiles(t, root))
+
+	deleted, err := repo.GetByURL(first.URL)
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+
+	ok, err := repo.IncrRef(object.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// TestFileUploadRemovesObjectWhenCreateFails 登记记录失败时删除已上传的存储文件
+func TestFileUploadRemovesObjectWhenCreateFails(t *testing.T) {
+	fileService, _, db, root := newFileRefTestService(t)
+
+	stmt := &gorm.Statement{DB: db.ORM}
+	if err := stmt.Parse(&platform.FileObject{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, db.ORM.Exec("CREATE TRIGGER reject_file_object BEFORE INSERT ON "+stmt.Table+
+		" BEGIN SELECT RAISE(ABORT, 'rejected'); END").Error)
+
+	_, err := fileService.UploadFile("a.txt", strings.NewReader("hello"), 5, "text/plain")
+	assert.Error(t, err)
+	assert.Equal(t, 0, storedFiles(t, root))
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/lib"
+)
+
+// newTestLogger 不输出任何内容的日志
+func newTestLogger() lib.Logger {
+	logger := zap.NewNop()
+	return lib.Logger{Zap: logger.Sugar(), DesugarZap: logger}
+}
+
+// newTestCache 内存缓存，测试结束时关闭
+func newTestCache(t *testing.T) lib.Cache {
+	t.Helper()
+
+	cache := lib.NewMemoryCache(lib.Config{Cache: &lib.CacheConfig{}}, newTestLogger())
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+// newTestDB 在临时目录中创建 SQLite 数据库并迁移 models
+func newTestDB(t *testing.T, models ...interface{}) lib.Database {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	lib.CurrentDatabaseEngine = lib.DatabaseEngineSQLite
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return lib.Database{ORM: db}
+}
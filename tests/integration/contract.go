@@ -0,0 +1,253 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// ContractConfig describes the content a downloader backend is verified against.
+type ContractConfig struct {
+	// Torrent and TorrentURL must have no seed, so the task stays in the
+	// downloading state while files are (de)selected and the task is canceled.
+	Torrent    *Torrent
+	TorrentURL string
+
+	// FileURL is an optional plain HTTP download of FileSize bytes,
+	// leave it empty for BitTorrent only backends.
+	FileURL  string
+	FileSize int64
+
+	// Timeout bounds every wait, defaults to 2 minutes
+	Timeout time.Duration
+}
+
+func (c *ContractConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 2 * time.Minute
+	}
+	return c.Timeout
+}
+
+// RunDownloaderContract verifies the downloader.Downloader behaviour the queue relies on:
+// Test, the CreateTask/Info/SetFilesToDownload/Cancel lifecycle of a torrent and,
+// when FileURL is set, a plain HTTP download running to completion.
+func RunDownloaderContract(t *testing.T, d downloader.Downloader, cfg ContractConfig) {
+	t.Run("Test", func(t *testing.T) {
+		ctx, cancel := callCtx()
+		defer cancel()
+
+		version, err := d.Test(ctx)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		if version == "" {
+			t.Error("Test returned an empty version")
+		}
+	})
+
+	t.Run("TorrentLifecycle", func(t *testing.T) {
+		testTorrentLifecycle(t, d, cfg)
+	})
+
+	if cfg.FileURL != "" {
+		t.Run("HTTPDownload", func(t *testing.T) {
+			testHTTPDownload(t, d, cfg)
+		})
+	}
+}
+
+func testTorrentLifecycle(t *testing.T, d downloader.Downloader, cfg ContractConfig) {
+	if cfg.Torrent == nil || cfg.TorrentURL == "" {
+		t.Fatal("contract needs a torrent")
+	}
+
+	ctx, cancel := callCtx()
+	handle, err := d.CreateTask(ctx, cfg.TorrentURL, nil)
+	cancel()
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	// Metadata is fetched first, the handle may be followed by the real torrent task
+	handle, status := WaitTask(t, d, handle, cfg.timeout(), func(s *downloader.TaskStatus) bool {
+		return len(s.Files) == len(cfg.Torrent.Files)
+	})
+
+	if status.Name != cfg.Torrent.Name {
+		t.Errorf("Name = %q, want %q", status.Name, cfg.Torrent.Name)
+	}
+	if status.Hash != "" && !strings.EqualFold(status.Hash, cfg.Torrent.InfoHash) {
+		t.Errorf("Hash = %q, want %q", status.Hash, cfg.Torrent.InfoHash)
+	}
+	if status.State != downloader.StatusDownloading {
+		t.Errorf("State = %q, want %q (torrent has no seed)", status.State, downloader.StatusDownloading)
+	}
+
+	var total int64
+	for _, f := range cfg.Torrent.Files {
+		total += int64(len(f.Data))
+	}
+	if status.Total != total {
+		t.Errorf("Total = %d, want %d", status.Total, total)
+	}
+
+	for _, want := range cfg.Torrent.Files {
+		f := findFile(status.Files, want.Path)
+		if f == nil {
+			t.Fatalf("file %q missing in %+v", want.Path, status.Files)
+		}
+		if f.Size != int64(len(want.Data)) {
+			t.Errorf("file %q size = %d, want %d", want.Path, f.Size, len(want.Data))
+		}
+		if !f.Selected {
+			t.Errorf("file %q not selected by default", want.Path)
+		}
+	}
+
+	// Deselect the first file, the others must stay selected
+	skipped := findFile(status.Files, cfg.Torrent.Files[0].Path)
+	ctx, cancel = callCtx()
+	err = d.SetFilesToDownload(ctx, handle, &downloader.SetFileToDownloadArgs{Index: skipped.Index, Download: false})
+	cancel()
+	if err != nil {
+		t.Fatalf("SetFilesToDownload: %v", err)
+	}
+
+	handle, _ = WaitTask(t, d, handle, cfg.timeout(), func(s *downloader.TaskStatus) bool {
+		for _, f := range s.Files {
+			if f.Selected != (f.Index != skipped.Index) {
+				return false
+			}
+		}
+		return len(s.Files) == len(cfg.Torrent.Files)
+	})
+
+	ctx, cancel = callCtx()
+	err = d.Cancel(ctx, handle)
+	cancel()
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	Eventually(t, cfg.timeout(), time.Second, func() (bool, error) {
+		ctx, cancel := callCtx()
+		defer cancel()
+
+		_, err := d.Info(ctx, handle)
+		return errors.Is(err, downloader.ErrTaskNotFound), err
+	})
+}
+
+func testHTTPDownload(t *testing.T, d downloader.Downloader, cfg ContractConfig) {
+	ctx, cancel := callCtx()
+	handle, err := d.CreateTask(ctx, cfg.FileURL, nil)
+	cancel()
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	_, status := WaitTask(t, d, handle, cfg.timeout(), func(s *downloader.TaskStatus) bool {
+		return s.State == downloader.StatusCompleted
+	})
+
+	if status.Total != cfg.FileSize || status.Downloaded != cfg.FileSize {
+		t.Errorf("downloaded %d/%d, want %d", status.Downloaded, status.Total, cfg.FileSize)
+	}
+}
+
+// WaitTask polls Info until cond holds, following FollowedBy handles.
+// It returns the final handle and status, and fails on error states or timeout.
+func WaitTask(t *testing.T, d downloader.Downloader, handle *downloader.TaskHandle, timeout time.Duration,
+	cond func(*downloader.TaskStatus) bool) (*downloader.TaskHandle, *downloader.TaskStatus) {
+	t.Helper()
+
+	var last *downloader.TaskStatus
+	Eventually(t, timeout, time.Second, func() (bool, error) {
+		ctx, cancel := callCtx()
+		defer cancel()
+
+		status, err := d.Info(ctx, handle)
+		if err != nil {
+			return false, err
+		}
+		last = status
+
+		if status.FollowedBy != nil {
+			handle = status.FollowedBy
+			return false, nil
+		}
+		if status.State == downloader.StatusError {
+			t.Fatalf("task failed: %s", describe(status))
+		}
+		return cond(status), errors.New(describe(status))
+	})
+
+	return handle, last
+}
+
+// findFile matches by path suffix, backends report names with or without the torrent root folder
+func findFile(files []downloader.TaskFile, path string) *downloader.TaskFile {
+	for i := range files {
+		name := strings.ReplaceAll(files[i].Name, "\\", "/")
+		if name == path || strings.HasSuffix(name, "/"+path) {
+			return &files[i]
+		}
+	}
+	return nil
+}
+
+// RunRemoteDownloadTask drives a queue.RemoteDownloadTask for url through a real queue
+// until it completes, and returns the task for further assertions.
+func RunRemoteDownloadTask(t *testing.T, d downloader.Downloader, url string, options map[string]interface{},
+	timeout time.Duration) *queue.RemoteDownloadTask {
+	t.Helper()
+
+	q := queue.New(
+		Logger{T: t},
+		queue.NewInMemoryTaskRepository(),
+		queue.NewTaskRegistry(),
+		queue.WithWorkerCount(1),
+		queue.WithName("integration"),
+	)
+	q.Start()
+	t.Cleanup(q.Shutdown)
+
+	created, err := queue.NewRemoteDownloadTask(context.Background(), url, "integration", options, nil)
+	if err != nil {
+		t.Fatalf("NewRemoteDownloadTask: %v", err)
+	}
+	task := created.(*queue.RemoteDownloadTask)
+	task.SetDownloader(d)
+
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("QueueTask: %v", err)
+	}
+
+	// The task re-checks the downloader every 10 seconds
+	Eventually(t, timeout, time.Second, func() (bool, error) {
+		switch task.Status() {
+		case queue.StatusCompleted:
+			return true, nil
+		case queue.StatusError, queue.StatusCanceled:
+			t.Fatalf("task ended with %s: %v", task.Status(), task.Error())
+		}
+		return false, errors.New(string(task.Status()))
+	})
+
+	status := task.GetDownloadStatus()
+	if status == nil || status.State != downloader.StatusCompleted {
+		t.Fatalf("download status = %s", describe(status))
+	}
+	if status.Downloaded != status.Total {
+		t.Errorf("downloaded %d/%d", status.Downloaded, status.Total)
+	}
+	return task
+}
@@ -0,0 +1,15 @@
+// Package integration runs the downloader backends against real aria2 and
+// qBittorrent containers started with dockertest.
+//
+// The suite is behind the "integration" build tag and needs a reachable Docker
+// daemon (DOCKER_HOST or the default socket); tests are skipped otherwise:
+//
+//	go test -tags integration -v ./tests/integration/...
+//
+// Images can be overridden with ARIA2_IMAGE, QBITTORRENT_IMAGE and
+// FILESERVER_IMAGE (repository:tag).
+//
+// The fixtures are exported so a new backend can reuse them: start an Env,
+// publish content with Env.FileServer, build the downloader against its
+// container and hand it to RunDownloaderContract.
+package integration
@@ -0,0 +1,81 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// fixture publishes the content shared by all backends
+type fixture struct {
+	contract   ContractConfig
+	seeded     *Torrent
+	seededURL  string
+	fileURL    string
+	fileLength int64
+}
+
+func newFixture(t *testing.T, env *Env) *fixture {
+	fs := env.FileServer()
+
+	files := []TorrentFile{
+		{Path: "a.bin", Data: Payload(40*1024, 1)},
+		{Path: "sub/b.bin", Data: Payload(24*1024, 2)},
+		{Path: "c.txt", Data: []byte("light-admin integration\n")},
+	}
+
+	// The unseeded torrent never makes progress, the web seeded one completes over HTTP
+	torrent, torrentURL := fs.PublishTorrent("unseeded-"+t.Name(), files, false)
+	seeded, seededURL := fs.PublishTorrent("seeded-"+t.Name(), files, true)
+
+	data := Payload(48*1024, 3)
+	fileURL := fs.Put("plain/file.bin", data)
+
+	return &fixture{
+		contract: ContractConfig{
+			Torrent:    torrent,
+			TorrentURL: torrentURL,
+			FileURL:    fileURL,
+			FileSize:   int64(len(data)),
+		},
+		seeded:     seeded,
+		seededURL:  seededURL,
+		fileURL:    fileURL,
+		fileLength: int64(len(data)),
+	}
+}
+
+func TestAria2(t *testing.T) {
+	env := Setup(t)
+	fx := newFixture(t, env)
+	aria2 := env.StartAria2()
+
+	RunDownloaderContract(t, aria2.Downloader, fx.contract)
+
+	t.Run("RemoteDownloadTask", func(t *testing.T) {
+		task := RunRemoteDownloadTask(t, aria2.Downloader, fx.fileURL, nil, 2*time.Minute)
+		if got := task.GetDownloadStatus().Total; got != fx.fileLength {
+			t.Errorf("Total = %d, want %d", got, fx.fileLength)
+		}
+	})
+}
+
+func TestQBittorrent(t *testing.T) {
+	env := Setup(t)
+	fx := newFixture(t, env)
+	qb := env.StartQBittorrent()
+
+	// qBittorrent only speaks BitTorrent, plain URLs are not part of its contract
+	cfg := fx.contract
+	cfg.FileURL = ""
+	RunDownloaderContract(t, qb.Downloader, cfg)
+
+	t.Run("RemoteDownloadTask", func(t *testing.T) {
+		// With a zero ratio limit the torrent is paused once complete, which reports as completed
+		task := RunRemoteDownloadTask(t, qb.Downloader, fx.seededURL, map[string]interface{}{"ratioLimit": 0.0}, 3*time.Minute)
+		if status := task.GetDownloadStatus(); status.Name != fx.seeded.Name {
+			t.Errorf("Name = %q, want %q", status.Name, fx.seeded.Name)
+		}
+	})
+}
@@ -0,0 +1,373 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/downloader/aria2"
+	"github.com/top-system/light-admin/pkg/downloader/qbittorrent"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+// Default images, override with the matching environment variable.
+const (
+	defaultAria2Image       = "p3terx/aria2-pro:latest"
+	defaultQBittorrentImage = "linuxserver/qbittorrent:latest"
+	defaultFileServerImage  = "busybox:stable"
+
+	// containerTTL is a hard limit so containers are reaped even if the test binary is killed
+	containerTTL = 15 * 60
+
+	// downloadDir is where the downloaders save files inside their containers
+	downloadDir = "/downloads"
+)
+
+// Logger implements the logger interfaces of the downloaders and the queue on top of testing.TB.
+type Logger struct {
+	T      testing.TB
+	Prefix string
+}
+
+func (l Logger) Info(format string, args ...interface{})    { l.logf("INFO", format, args) }
+func (l Logger) Debug(format string, args ...interface{})   { l.logf("DEBUG", format, args) }
+func (l Logger) Warning(format string, args ...interface{}) { l.logf("WARN", format, args) }
+func (l Logger) Error(format string, args ...interface{})   { l.logf("ERROR", format, args) }
+
+// CopyWithPrefix implements queue.Logger
+func (l Logger) CopyWithPrefix(prefix string) queue.Logger {
+	return Logger{T: l.T, Prefix: prefix + " "}
+}
+
+func (l Logger) logf(level, format string, args []interface{}) {
+	l.T.Logf("[%s] %s%s", level, l.Prefix, fmt.Sprintf(format, args...))
+}
+
+// Env is a docker pool plus a private network shared by all containers of a test,
+// so downloaders can reach the file server by its network IP.
+type Env struct {
+	T       testing.TB
+	Pool    *dockertest.Pool
+	Network *dockertest.Network
+
+	fsOnce sync.Once
+	fs     *FileServer
+}
+
+// Setup connects to docker and creates the test network, the test is skipped when docker is not reachable.
+// Everything started from the returned Env is removed when the test finishes.
+func Setup(t testing.TB) *Env {
+	t.Helper()
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Skipf("docker not available: %v", err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		t.Skipf("docker not available: %v", err)
+	}
+	pool.MaxWait = 3 * time.Minute
+
+	network, err := pool.CreateNetwork("light-admin-it-" + uuid.Must(uuid.NewV4()).String()[:8])
+	if err != nil {
+		t.Fatalf("create network: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := network.Close(); err != nil {
+			t.Logf("remove network: %v", err)
+		}
+	})
+
+	return &Env{T: t, Pool: pool, Network: network}
+}
+
+// Run starts a container attached to the test network and purges it on cleanup.
+func (e *Env) Run(opts *dockertest.RunOptions) *dockertest.Resource {
+	e.T.Helper()
+
+	opts.Networks = append(opts.Networks, e.Network)
+	res, err := e.Pool.RunWithOptions(opts, func(hc *docker.HostConfig) {
+		hc.AutoRemove = true
+		hc.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		e.T.Fatalf("start %s:%s: %v", opts.Repository, opts.Tag, err)
+	}
+	_ = res.Expire(containerTTL)
+
+	e.T.Cleanup(func() {
+		if err := e.Pool.Purge(res); err != nil {
+			e.T.Logf("purge %s: %v", res.Container.Name, err)
+		}
+	})
+	return res
+}
+
+// Logs returns stdout and stderr of a container.
+func (e *Env) Logs(res *dockertest.Resource) string {
+	var buf bytes.Buffer
+	_ = e.Pool.Client.Logs(docker.LogsOptions{
+		Container:    res.Container.ID,
+		OutputStream: &buf,
+		ErrorStream:  &buf,
+		Stdout:       true,
+		Stderr:       true,
+	})
+	return buf.String()
+}
+
+// image reads repository and tag from env, falling back to def.
+func image(env, def string) (string, string) {
+	ref := os.Getenv(env)
+	if ref == "" {
+		ref = def
+	}
+
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// FileServer is a static HTTP server inside the test network serving content for the downloaders.
+type FileServer struct {
+	env      *Env
+	resource *dockertest.Resource
+
+	// BaseURL is reachable from containers on the test network
+	BaseURL string
+	// HostURL is reachable from the test process
+	HostURL string
+}
+
+// FileServer starts (once per Env) and returns the file server.
+func (e *Env) FileServer() *FileServer {
+	e.T.Helper()
+
+	e.fsOnce.Do(func() {
+		repo, tag := image("FILESERVER_IMAGE", defaultFileServerImage)
+		res := e.Run(&dockertest.RunOptions{
+			Repository:   repo,
+			Tag:          tag,
+			Cmd:          []string{"sh", "-c", "mkdir -p /srv && exec httpd -f -v -p 8000 -h /srv"},
+			ExposedPorts: []string{"8000/tcp"},
+		})
+
+		fs := &FileServer{
+			env:      e,
+			resource: res,
+			BaseURL:  "http://" + res.GetIPInNetwork(e.Network) + ":8000",
+			HostURL:  "http://" + res.GetHostPort("8000/tcp"),
+		}
+		if err := e.Pool.Retry(func() error {
+			resp, err := http.Get(fs.HostURL + "/")
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			return nil
+		}); err != nil {
+			e.T.Fatalf("file server not ready: %v", err)
+		}
+		e.fs = fs
+	})
+
+	if e.fs == nil {
+		e.T.Fatalf("file server failed to start")
+	}
+	return e.fs
+}
+
+// Put stores data at path (slash separated) and returns its URL on the test network.
+// Content is passed base64 encoded through the environment, keep files below ~64KB.
+func (s *FileServer) Put(path string, data []byte) string {
+	s.env.T.Helper()
+
+	path = strings.TrimPrefix(path, "/")
+	var stderr bytes.Buffer
+	code, err := s.resource.Exec(
+		[]string{"sh", "-c", `mkdir -p "$(dirname "/srv/$FILE")" && echo "$DATA" | base64 -d > "/srv/$FILE"`},
+		dockertest.ExecOptions{
+			Env:    []string{"FILE=" + path, "DATA=" + base64.StdEncoding.EncodeToString(data)},
+			StdErr: &stderr,
+		},
+	)
+	if err != nil || code != 0 {
+		s.env.T.Fatalf("put %s: exit=%d err=%v stderr=%s", path, code, err, stderr.String())
+	}
+
+	return s.BaseURL + "/" + path
+}
+
+// PublishTorrent serves the torrent content under /seed/ and the .torrent file under /torrents/,
+// returning the torrent and the URL of the .torrent file.
+// With webSeed the torrent can be completed over HTTP, otherwise it never gets any data.
+func (s *FileServer) PublishTorrent(name string, files []TorrentFile, webSeed bool) (*Torrent, string) {
+	s.env.T.Helper()
+
+	seed := ""
+	if webSeed {
+		seed = s.BaseURL + "/seed/"
+		for _, f := range files {
+			s.Put("seed/"+name+"/"+f.Path, f.Data)
+		}
+	}
+
+	torrent, err := MakeTorrent(name, files, seed)
+	if err != nil {
+		s.env.T.Fatalf("make torrent: %v", err)
+	}
+
+	return torrent, s.Put("torrents/"+name+".torrent", torrent.Raw)
+}
+
+// Payload returns size deterministic pseudo random bytes.
+func Payload(size int, seed int64) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+// Aria2 is an aria2 container with an RPC client.
+type Aria2 struct {
+	Resource   *dockertest.Resource
+	Settings   *aria2.Settings
+	Downloader downloader.Downloader
+}
+
+// StartAria2 starts aria2 and waits until its RPC interface answers.
+func (e *Env) StartAria2() *Aria2 {
+	e.T.Helper()
+
+	secret := uuid.Must(uuid.NewV4()).String()
+	repo, tag := image("ARIA2_IMAGE", defaultAria2Image)
+	res := e.Run(&dockertest.RunOptions{
+		Repository:   repo,
+		Tag:          tag,
+		Env:          []string{"RPC_SECRET=" + secret, "RPC_PORT=6800", "UMASK_SET=022"},
+		ExposedPorts: []string{"6800/tcp"},
+	})
+
+	settings := &aria2.Settings{
+		Server:   "http://" + res.GetHostPort("6800/tcp"),
+		Token:    secret,
+		TempPath: downloadDir,
+	}
+	d := aria2.New(Logger{T: e.T}, settings)
+
+	if err := e.Pool.Retry(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := d.Test(ctx)
+		return err
+	}); err != nil {
+		e.T.Fatalf("aria2 not ready: %v\n%s", err, e.Logs(res))
+	}
+
+	return &Aria2{Resource: res, Settings: settings, Downloader: d}
+}
+
+// QBittorrent is a qBittorrent container with a Web API client.
+type QBittorrent struct {
+	Resource   *dockertest.Resource
+	Settings   *qbittorrent.Settings
+	Downloader downloader.Downloader
+}
+
+// qBittorrent 4.6.1+ prints a random password on first start, older versions use adminadmin
+var qbTemporaryPassword = regexp.MustCompile(`temporary password is provided for this session: (\S+)`)
+
+// StartQBittorrent starts qBittorrent and waits until it accepts the admin login.
+func (e *Env) StartQBittorrent() *QBittorrent {
+	e.T.Helper()
+
+	repo, tag := image("QBITTORRENT_IMAGE", defaultQBittorrentImage)
+	res := e.Run(&dockertest.RunOptions{
+		Repository:   repo,
+		Tag:          tag,
+		Env:          []string{"PUID=0", "PGID=0", "WEBUI_PORT=8080", "TZ=UTC"},
+		ExposedPorts: []string{"8080/tcp"},
+	})
+
+	var qb *QBittorrent
+	if err := e.Pool.Retry(func() error {
+		password := "adminadmin"
+		if m := qbTemporaryPassword.FindStringSubmatch(e.Logs(res)); m != nil {
+			password = m[1]
+		}
+
+		settings := &qbittorrent.Settings{
+			Server:   "http://" + res.GetHostPort("8080/tcp"),
+			User:     "admin",
+			Password: password,
+			TempPath: downloadDir,
+		}
+		d, err := qbittorrent.New(Logger{T: e.T}, settings)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := d.Test(ctx); err != nil {
+			return err
+		}
+
+		qb = &QBittorrent{Resource: res, Settings: settings, Downloader: d}
+		return nil
+	}); err != nil {
+		e.T.Fatalf("qbittorrent not ready: %v\n%s", err, e.Logs(res))
+	}
+
+	return qb
+}
+
+// Eventually polls cond every interval until it returns true, fails the test after timeout.
+// The last error returned by cond is reported on timeout.
+func Eventually(t testing.TB, timeout, interval time.Duration, cond func() (bool, error)) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for {
+		ok, err := cond()
+		if ok {
+			return
+		}
+		lastErr = err
+
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s (last error: %v)", timeout, lastErr)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// callCtx is the context for a single downloader call
+func callCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 30*time.Second)
+}
+
+func describe(status *downloader.TaskStatus) string {
+	if status == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s state=%s %d/%d files=%d err=%q",
+		status.Name, status.State, status.Downloaded, status.Total, len(status.Files), status.ErrorMessage)
+}
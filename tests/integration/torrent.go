@@ -0,0 +1,137 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TorrentFile is a file inside a generated torrent, Path is slash separated
+// and relative to the torrent root folder.
+type TorrentFile struct {
+	Path string
+	Data []byte
+}
+
+// Torrent is a generated multi-file torrent.
+type Torrent struct {
+	Name     string
+	Files    []TorrentFile
+	InfoHash string // hex encoded v1 info hash
+	Raw      []byte // bencoded .torrent
+}
+
+// MakeTorrent builds a trackerless multi-file torrent. When webSeed is not
+// empty it is added as a BEP 19 url-list entry, clients then fetch
+// webSeed + name + "/" + path over HTTP without any peer.
+func MakeTorrent(name string, files []TorrentFile, webSeed string) (*Torrent, error) {
+	if name == "" || len(files) == 0 {
+		return nil, fmt.Errorf("torrent needs a name and at least one file")
+	}
+
+	const pieceLength = 16 * 1024
+
+	var content bytes.Buffer
+	fileList := make([]interface{}, 0, len(files))
+	for _, f := range files {
+		content.Write(f.Data)
+		path := make([]interface{}, 0)
+		for _, p := range strings.Split(f.Path, "/") {
+			path = append(path, p)
+		}
+		fileList = append(fileList, map[string]interface{}{
+			"length": len(f.Data),
+			"path":   path,
+		})
+	}
+
+	var pieces bytes.Buffer
+	data := content.Bytes()
+	for off := 0; off < len(data); off += pieceLength {
+		end := off + pieceLength
+		if end > len(data) {
+			end = len(data)
+		}
+		sum := sha1.Sum(data[off:end])
+		pieces.Write(sum[:])
+	}
+
+	info := map[string]interface{}{
+		"name":         name,
+		"piece length": pieceLength,
+		"pieces":       pieces.Bytes(),
+		"files":        fileList,
+	}
+
+	var infoBuf bytes.Buffer
+	if err := bencode(&infoBuf, info); err != nil {
+		return nil, err
+	}
+	infoHash := sha1.Sum(infoBuf.Bytes())
+
+	meta := map[string]interface{}{
+		"info":       info,
+		"created by": "light-admin integration",
+	}
+	if webSeed != "" {
+		meta["url-list"] = []interface{}{webSeed}
+	}
+
+	var raw bytes.Buffer
+	if err := bencode(&raw, meta); err != nil {
+		return nil, err
+	}
+
+	return &Torrent{
+		Name:     name,
+		Files:    files,
+		InfoHash: hex.EncodeToString(infoHash[:]),
+		Raw:      raw.Bytes(),
+	}, nil
+}
+
+// bencode writes v using the BitTorrent encoding, dictionary keys are sorted.
+func bencode(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case int:
+		fmt.Fprintf(buf, "i%de", x)
+	case int64:
+		fmt.Fprintf(buf, "i%de", x)
+	case string:
+		fmt.Fprintf(buf, "%d:%s", len(x), x)
+	case []byte:
+		fmt.Fprintf(buf, "%d:", len(x))
+		buf.Write(x)
+	case []interface{}:
+		buf.WriteByte('l')
+		for _, item := range x {
+			if err := bencode(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('d')
+		for _, k := range keys {
+			fmt.Fprintf(buf, "%d:%s", len(k), k)
+			if err := bencode(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	default:
+		return fmt.Errorf("bencode: unsupported type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// writeJwtKey 生成密钥对并写入 PEM 文件，返回私钥与公钥文件路径
+func writeJwtKey(t *testing.T, alg, kid string) (string, string) {
+	t.Helper()
+
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	if alg == "ES256" {
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	} else {
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privBlock := &pem.Block{Type: "PRIVATE KEY", Bytes: privDER}
+	dir := t.TempDir()
+	privFile, pubFile := filepath.Join(dir, kid+".pem"), filepath.Join(dir, kid+".pub")
+	if err := os.WriteFile(privFile, pem.EncodeToMemory(privBlock), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return privFile, pubFile
+}
+
+func newTestJwtAuthService(t *testing.T, db lib.Database, alg string, keys ...*lib.JwtKeyConfig) service.AuthService {
+	t.Helper()
+
+	config := newTestAuthConfig()
+	config.Auth.Algorithm = alg
+	config.Auth.Keys = keys
+	return newTestAuthService(t, config, db)
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	t.Helper()
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+// TestJwtAsymmetricKeyRotation RS256/ES256 签发的令牌携带 kid，轮换后保留为验证密钥的旧公钥仍可验证旧令牌，
+// 未知 kid 的令牌被拒绝
+func TestJwtAsymmetricKeyRotation(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256"} {
+		t.Run(alg, func(t *testing.T) {
+			db := newTestAuthDB(t)
+			user := &system.User{Username: "alice", Status: 1}
+			assert.NoError(t, db.ORM.Create(user).Error)
+
+			oldPriv, oldPub := writeJwtKey(t, alg, "old")
+			newPriv, _ := writeJwtKey(t, alg, "new")
+
+			oldService := newTestJwtAuthService(t, db, alg, &lib.JwtKeyConfig{KID: "old", PrivateKeyFile: oldPriv})
+			login, err := oldService.GenerateToken(user, "curl/8.0", "127.0.0.1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "old", tokenKeyID(t, login.AccessToken))
+			claims, err := oldService.ParseToken(login.AccessToken)
+			if assert.NoError(t, err) {
+				assert.Equal(t, user.ID, claims.ID)
+			}
+
+			// 新密钥签名，旧密钥只保留公钥用于验证
+			rotated := newTestJwtAuthService(t, db, alg,
+				&lib.JwtKeyConfig{KID: "new", PrivateKeyFile: newPriv},
+				&lib.JwtKeyConfig{KID: "old", PublicKeyFile: oldPub},
+			)
+			_, err = rotated.ParseToken(login.AccessToken)
+			assert.NoError(t, err, "tokens signed by a retired key should verify until it is removed")
+
+			fresh, err := rotated.GenerateToken(user, "curl/8.0", "127.0.0.1")
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "new", tokenKeyID(t, fresh.AccessToken))
+			_, err = rotated.ParseToken(fresh.AccessToken)
+			assert.NoError(t, err)
+
+			jwks := rotated.JWKS()
+			if assert.Len(t, jwks.Keys, 2) {
+				assert.Equal(t, "new", jwks.Keys[0].Kid)
+				assert.Equal(t, "old", jwks.Keys[1].Kid)
+				assert.Equal(t, alg, jwks.Keys[0].Alg)
+			}
+
+			// 旧密钥移除后，携带其 kid 的令牌不再被接受
+			removed := newTestJwtAuthService(t, db, alg, &lib.JwtKeyConfig{KID: "new", PrivateKeyFile: newPriv})
+			_, err = removed.ParseToken(login.AccessToken)
+			assert.True(t, errors.Is(err, errors.AuthTokenInvalid))
+			_, err = oldService.ParseToken(fresh.AccessToken)
+			assert.True(t, errors.Is(err, errors.AuthTokenInvalid))
+		})
+	}
+}
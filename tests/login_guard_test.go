@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+func newTestLoginGuard(t *testing.T, lockout *lib.LoginLockoutConfig) (service.LoginGuardService, lib.Database) {
+	t.Helper()
+
+	logger := newTestLogger()
+	db := newTestDB(t, &system.LoginLog{})
+	config := newTestAuthConfig()
+	config.Auth.Lockout = lockout
+	return service.NewLoginGuardService(newTestCache(t), config, logger, repository.NewLoginLogRepository(db, logger)), db
+}
+
+// TestLoginLockout 同一用户名连续失败达到上限后锁定，锁定期满后解除，其他用户名不受影响
+func TestLoginLockout(t *testing.T) {
+	guard, db := newTestLoginGuard(t, &lib.LoginLockoutConfig{Enable: true, MaxFailures: 3, IPMaxFailures: -1, Window: 60, LockDuration: 1})
+
+	for i := 0; i < 2; i++ {
+		guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	}
+	assert.NoError(t, guard.Check("alice", "127.0.0.1"))
+
+	guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	assert.True(t, errors.Is(guard.Check("alice", "127.0.0.1"), errors.AuthAccountLocked))
+	assert.NoError(t, guard.Check("bob", "127.0.0.1"))
+
+	// 锁定期间被拒绝的尝试只记录日志，不延长锁定
+	guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.AuthAccountLocked)
+	var logs int64
+	assert.NoError(t, db.ORM.Model(&system.LoginLog{}).Where("username = ?", "alice").Count(&logs).Error)
+	assert.Equal(t, int64(4), logs)
+
+	assert.Eventually(t, func() bool {
+		return guard.Check("alice", "127.0.0.1") == nil
+	}, 3*time.Second, 50*time.Millisecond, "lock should expire after LockDuration")
+
+	// 锁定时计数已清空，解除后重新计数
+	guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	assert.NoError(t, guard.Check("alice", "127.0.0.1"))
+}
+
+// TestLoginLockoutResetOnSuccess 登录成功清空失败计数，之前的失败不再累计
+func TestLoginLockoutResetOnSuccess(t *testing.T) {
+	guard, _ := newTestLoginGuard(t, &lib.LoginLockoutConfig{Enable: true, MaxFailures: 3, IPMaxFailures: -1})
+
+	for i := 0; i < 2; i++ {
+		guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	}
+	guard.RecordSuccess(&system.User{ID: 1, Username: "alice"}, "127.0.0.1", "curl/8.0")
+
+	for i := 0; i < 2; i++ {
+		guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	}
+	assert.NoError(t, guard.Check("alice", "127.0.0.1"))
+
+	guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	assert.True(t, errors.Is(guard.Check("alice", "127.0.0.1"), errors.AuthAccountLocked))
+}
+
+// TestLoginLockoutWindow 超出统计窗口的失败不再累计
+func TestLoginLockoutWindow(t *testing.T) {
+	guard, _ := newTestLoginGuard(t, &lib.LoginLockoutConfig{Enable: true, MaxFailures: 3, IPMaxFailures: -1, Window: 1})
+
+	for i := 0; i < 2; i++ {
+		guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	}
+	time.Sleep(1100 * time.Millisecond)
+
+	guard.RecordFailure("alice", "127.0.0.1", "curl/8.0", errors.UserInvalidPassword)
+	assert.NoError(t, guard.Check("alice", "127.0.0.1"))
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TestMenuCascadeDeleteInvalidatesRolePerms 级联删除已授权给角色的菜单后，角色下用户的权限缓存随之失效
+func TestMenuCascadeDeleteInvalidatesRolePerms(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.Menu{}, &system.RoleMenu{}, &system.UserRole{}, &system.Role{}, &system.User{})
+	cache := newTestCache(t)
+
+	for _, menu := range []*system.Menu{
+		{ID: 1, Name: "下载管理", Type: 2},
+		{ID: 2, Name: "下载任务", Type: 1, ParentID: 1, TreePath: "1"},
+		{ID: 3, Name: "新增下载", Type: 4, ParentID: 2, TreePath: "1,2", Perm: "sys:download:add"},
+		{ID: 4, Name: "查询用户", Type: 4, Perm: "sys:user:query"},
+	} {
+		assert.NoError(t, db.ORM.Create(menu).Error)
+	}
+	assert.NoError(t, db.ORM.Create(&system.Role{ID: 1, Name: "编辑", Code: "EDITOR", Status: 1}).Error)
+	for _, rm := range []*system.RoleMenu{{RoleID: 1, MenuID: 1}, {RoleID: 1, MenuID: 2}, {RoleID: 1, MenuID: 3}, {RoleID: 1, MenuID: 4}} {
+		assert.NoError(t, db.ORM.Create(rm).Error)
+	}
+	assert.NoError(t, db.ORM.Create(&system.UserRole{UserID: 1, RoleID: 1}).Error)
+
+	userRoleRepository := repository.NewUserRoleRepository(db, logger)
+	menuRepository := repository.NewMenuRepository(db, logger)
+	roleMenuRepository := repository.NewRoleMenuRepository(db, logger)
+	permissionCache := service.NewPermissionCache(logger, cache, userRoleRepository)
+	permissionService := service.NewPermissionService(logger, lib.HttpHandler{}, lib.NewPermRegistry(), lib.NewFeatureModules(),
+		permissionCache, menuRepository, roleMenuRepository, userRoleRepository, repository.NewRoleRepository(db, logger))
+	menuService := service.NewMenuService(logger, menuRepository, roleMenuRepository, permissionCache,
+		lib.NewResponseCache(lib.Config{}, cache, logger), lib.NewFeatureModules(),
+		service.NewLockService(logger, cache, repository.NewUserRepository(db, logger)))
+
+	perms, err := permissionService.GetUserPerms(1)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []string{"sys:download:add", "sys:user:query"}, perms)
+	}
+	cached, ok := permissionCache.GetUserPerms(1)
+	assert.True(t, ok)
+	assert.Contains(t, cached, "sys:download:add")
+
+	preview, err := menuService.DeleteWithMode(1, system.MenuDeleteModeCascade)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []uint64{1, 2, 3}, preview.MenuIDs)
+		assert.Equal(t, int64(3), preview.RoleMenus)
+	}
+
+	_, ok = permissionCache.GetUserPerms(1)
+	assert.False(t, ok, "cached perms of users holding the role should be invalidated")
+	perms, err = permissionService.GetUserPerms(1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"sys:user:query"}, perms)
+	}
+
+	var bindings int64
+	assert.NoError(t, db.ORM.Model(&system.RoleMenu{}).Count(&bindings).Error)
+	assert.Equal(t, int64(1), bindings)
+}
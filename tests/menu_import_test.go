@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+)
+
+func newTestMenuService(t *testing.T) (service.MenuService, service.LockService, lib.Database) {
+	t.Helper()
+
+	logger := newTestLogger()
+	db := newTestDB(t, &system.Menu{}, &system.RoleMenu{}, &system.UserRole{}, &system.User{})
+	cache := newTestCache(t)
+	lockService := service.NewLockService(logger, cache, repository.NewUserRepository(db, logger))
+	menuService := service.NewMenuService(
+		logger, repository.NewMenuRepository(db, logger), repository.NewRoleMenuRepository(db, logger),
+		service.NewPermissionCache(logger, cache, repository.NewUserRoleRepository(db, logger)),
+		lib.NewResponseCache(lib.Config{}, cache, logger), lib.NewFeatureModules(), lockService,
+	)
+	return menuService, lockService, db
+}
+
+func countMenus(t *testing.T, db lib.Database) int64 {
+	t.Helper()
+
+	var n int64
+	assert.NoError(t, db.ORM.Model(&system.Menu{}).Count(&n).Error)
+	return n
+}
+
+// TestMenuExportImportRoundTrip 导出的菜单导入到另一个环境后再次导出结果一致，重复导入不产生重复菜单
+func TestMenuExportImportRoundTrip(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("- name: 系统管理\n  type: 2\n  route_name: System\n  route_path: /system\n  sort: 1\n  visible: 1\n  children:\n")
+	b.WriteString("    - name: 用户管理\n      type: 1\n      route_name: User\n      route_path: user\n      component: system/user/index\n      sort: 1\n      visible: 1\n      children:\n")
+	// 超过默认分页大小，导出不能只包含第一页
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&b, "        - name: 按钮%02d\n          type: 4\n          perm: sys:user:op%02d\n          sort: %d\n", i, i, i)
+	}
+
+	source, _, sourceDB := newTestMenuService(t)
+	assert.NoError(t, source.Import([]byte(b.String()), 1))
+	assert.Equal(t, int64(22), countMenus(t, sourceDB))
+
+	exported, err := source.Export()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(exported), "sys:user:op19")
+
+	target, _, targetDB := newTestMenuService(t)
+	assert.NoError(t, target.Import(exported, 1))
+	assert.Equal(t, int64(22), countMenus(t, targetDB))
+	reexported, err := target.Export()
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(exported), string(reexported))
+	}
+
+	// 已存在的菜单跳过
+	assert.NoError(t, target.Import(exported, 1))
+	assert.Equal(t, int64(22), countMenus(t, targetDB))
+
+	var button system.Menu
+	assert.NoError(t, targetDB.ORM.Where("perm = ?", "sys:user:op05").First(&button).Error)
+	var parent system.Menu
+	assert.NoError(t, targetDB.ORM.First(&parent, button.ParentID).Error)
+	assert.Equal(t, "用户管理", parent.Name)
+	assert.Equal(t, fmt.Sprintf("%d,%d", parent.ParentID, parent.ID), button.TreePath)
+}
+
+// TestMenuImportInvalid 格式错误或为空的文件不导入任何菜单
+func TestMenuImportInvalid(t *testing.T) {
+	menuService, _, db := newTestMenuService(t)
+
+	for _, data := range []string{
+		`[{"name": "系统管理", "type": 2, "children": [`,
+		`{"name": "系统管理"}`,
+		`[]`,
+		``,
+	} {
+		err := menuService.Import([]byte(data), 1)
+		assert.True(t, errors.Is(err, errors.MenuImportInvalid), "%q: %v", data, err)
+	}
+	assert.Zero(t, countMenus(t, db))
+}
+
+// TestMenuImportLocked 其他导入持有菜单导入锁时拒绝导入
+func TestMenuImportLocked(t *testing.T) {
+	menuService, lockService, db := newTestMenuService(t)
+
+	lock, err := lockService.Acquire(system.LockMenuImport, 2, "seed menus", time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = menuService.Import([]byte("- name: 系统管理\n  type: 2\n"), 1)
+	assert.True(t, errors.Is(err, errors.LockHeld), "%v", err)
+	assert.Zero(t, countMenus(t, db))
+
+	assert.NoError(t, lockService.Release(system.LockMenuImport, lock.Token))
+	assert.NoError(t, menuService.Import([]byte("- name: 系统管理\n  type: 2\n"), 1))
+	assert.Equal(t, int64(1), countMenus(t, db))
+
+	// 导入结束后释放锁
+	_, err = lockService.Get(system.LockMenuImport)
+	assert.True(t, errors.Is(err, errors.LockNotFound))
+}
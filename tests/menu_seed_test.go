@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/top-system/light-admin/models/system"
+)
+
+// seededPerms config/menu.yaml 中按钮菜单的权限标识
+func seededPerms(trees system.MenuTrees, perms map[string]bool) {
+	for _, tree := range trees {
+		if tree.Type == 4 && tree.Perm != "" {
+			perms[tree.Perm] = true
+		}
+		seededPerms(tree.Children, perms)
+	}
+}
+
+// seededPermModules 接口权限需要在菜单初始化文件中声明的模块
+var seededPermModules = []string{
+	"maintenance",
+	"websocket",
+	"analytics",
+	"security",
+	"compliance",
+	"tag",
+	"config-backup",
+	"policy",
+	"download",
+	"ws-event",
+	"directory",
+	"lock",
+	"retention",
+}
+
+// TestMaintenancePermsSeeded 运维、WebSocket 会话等接口的权限需要在菜单初始化文件中，否则无法分配给角色
+func TestMaintenancePermsSeeded(t *testing.T) {
+	data, err := os.ReadFile("../config/menu.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var trees system.MenuTrees
+	if err := yaml.Unmarshal(data, &trees); err != nil {
+		t.Fatal(err)
+	}
+	seeded := make(map[string]bool)
+	seededPerms(trees, seeded)
+
+	pattern := regexp.MustCompile(`"(sys:(?:` + strings.Join(seededPermModules, "|") + `):[a-z-]+)"`)
+	used := make(map[string]bool)
+	err = filepath.WalkDir("../api", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, "_route.go") {
+			return err
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range pattern.FindAllStringSubmatch(string(src), -1) {
+			used[match[1]] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, used["sys:maintenance:metrics"])
+	assert.True(t, used["sys:websocket:session"])
+	assert.True(t, used["sys:analytics:query"])
+	assert.True(t, used["sys:security:query"])
+	assert.True(t, used["sys:compliance:query"])
+	assert.True(t, used["sys:tag:query"])
+	assert.True(t, used["sys:config-backup:query"])
+	assert.True(t, used["sys:policy:query"])
+	assert.True(t, used["sys:download:usage"])
+	assert.True(t, used["sys:ws-event:query"])
+	assert.True(t, used["sys:directory:query"])
+	assert.True(t, used["sys:lock:query"])
+	assert.True(t, used["sys:retention:query"])
+	// 通讯录联系方式字段权限在服务中检查，不出现在路由声明中
+	used[system.DirectoryFieldMobilePerm] = true
+	used[system.DirectoryFieldEmailPerm] = true
+	for perm := range used {
+		assert.True(t, seeded[perm], "%s is not seeded in config/menu.yaml", perm)
+	}
+}
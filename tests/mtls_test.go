@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TestClientCertAuth 已校验的客户端证书按 CN 映射为服务账号，未绑定的 CN 被拒绝；
+// 没有已校验证书链的 TLS 请求按 API Key、JWT 认证
+func TestClientCertAuth(t *testing.T) {
+	db := newTestAuthDB(t)
+	config := newTestAuthConfig()
+	config.Auth.Enable = true
+	config.Http.MTLS = &lib.MTLSConfig{Enable: true, Accounts: []*lib.ServiceAccountConfig{
+		{Subject: "prometheus.internal", Name: "prometheus", Roles: []string{"MONITOR"}},
+		{Subject: "backup.internal", Roles: []string{"BACKUP"}},
+	}}
+	authService := newTestAuthService(t, config, db)
+
+	engine := echo.New()
+	middlewares.NewAuthMiddleware(config, lib.HttpHandler{Engine: engine}, newTestLogger(), lib.NewPermRegistry(),
+		authService, service.ApiKeyService{}).Setup()
+	engine.GET("/api/v1/whoami", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, ctx.Get(constants.CurrentUser))
+	})
+
+	user := &system.User{Username: "alice", Status: 1}
+	assert.NoError(t, db.ORM.Create(user).Error)
+	login, err := authService.GenerateToken(user, "curl/8.0", "127.0.0.1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	serve := func(state *tls.ConnectionState, header, value string) (*httptest.ResponseRecorder, *dto.JwtClaims) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/whoami", nil)
+		req.TLS = state
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+
+		var claims dto.JwtClaims
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &claims))
+		}
+		return rec, &claims
+	}
+	verified := func(cn string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	rec, claims := serve(verified("prometheus.internal"), "", "")
+	if assert.Equal(t, http.StatusOK, rec.Code) {
+		assert.True(t, claims.ServiceAccount)
+		assert.Equal(t, "sa:prometheus", claims.Username)
+		assert.Equal(t, []string{"MONITOR"}, claims.Roles)
+		assert.Zero(t, claims.ID)
+	}
+	// 未配置名称时使用证书主题
+	_, claims = serve(verified("backup.internal"), "", "")
+	assert.Equal(t, "sa:backup.internal", claims.Username)
+
+	// 未绑定的证书不回退到令牌认证
+	rec, _ = serve(verified("unknown.internal"), "Authorization", "Bearer "+login.AccessToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), errors.AuthCertNotBound.Error())
+
+	// 普通 TLS 连接没有已校验的证书链
+	rec, claims = serve(&tls.ConnectionState{}, "Authorization", "Bearer "+login.AccessToken)
+	if assert.Equal(t, http.StatusOK, rec.Code) {
+		assert.False(t, claims.ServiceAccount)
+		assert.Equal(t, "alice", claims.Username)
+	}
+	rec, _ = serve(&tls.ConnectionState{}, system.ApiKeyHeader, "not-an-api-key")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), errors.ApiKeyInvalid.Error())
+	rec, _ = serve(&tls.ConnectionState{}, "", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	platformrepository "github.com/top-system/light-admin/api/platform/repository"
+	platformservice "github.com/top-system/light-admin/api/platform/service"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/platform"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+)
+
+type noticeAttachmentFixture struct {
+	db             lib.Database
+	root           string
+	cron           lib.Crontab
+	featureModules lib.FeatureModules
+	noticeService  service.NoticeService
+}
+
+func newNoticeAttachmentFixture(t *testing.T, config lib.Config) *noticeAttachmentFixture {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.Notice{}, &system.UserNotice{}, &system.NoticeAttachment{}, &system.NoticeRevision{}, &platform.FileObject{})
+	root := t.TempDir()
+	cron := lib.Crontab{Cron: crontab.New(crontab.NewDefaultLogger())}
+	featureModules := lib.NewFeatureModules()
+
+	fileService := platformservice.NewObjectFileService(platformservice.NewLocalFileService(root, logger), platformrepository.NewFileObjectRepository(db, logger), logger)
+	noticeService := service.NewNoticeService(
+		logger, config, cron, featureModules, fileService,
+		repository.NewNoticeRepository(db, logger, lib.DBCompat{}), repository.NewUserNoticeRepository(db, logger, lib.DBCompat{}),
+		repository.NewUserRepository(db, logger), repository.NewNoticeAttachmentRepository(db, logger),
+		repository.NewNoticeRevisionRepository(db, logger),
+		service.WsEventService{}, service.MailService{}, service.WebhookService{},
+	)
+
+	return &noticeAttachmentFixture{db: db, root: root, cron: cron, featureModules: featureModules, noticeService: noticeService}
+}
+
+func (f *noticeAttachmentFixture) upload(t *testing.T, content string) *system.NoticeAttachmentVO {
+	t.Helper()
+
+	vo, err := f.noticeService.UploadAttachment("report.txt", strings.NewReader(content), int64(len(content)), "text/plain", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vo
+}
+
+func noticeAttachmentParam(t *testing.T, downloadURL string) *system.NoticeAttachmentDownloadParam {
+	t.Helper()
+
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, _ := strconv.ParseUint(u.Query().Get("id"), 10, 64)
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	return &system.NoticeAttachmentDownloadParam{ID: id, Expires: expires, Sign: u.Query().Get("sign")}
+}
+
+// TestNoticeAttachmentSignKeyIsDedicated 未配置 Secret 时不沿用 Auth.Secret，签名只在签发的实例内有效
+func TestNoticeAttachmentSignKeyIsDedicated(t *testing.T) {
+	config := lib.Config{Auth: &lib.AuthConfig{Secret: "jwt-secret"}}
+	first := newNoticeAttachmentFixture(t, config)
+	second := newNoticeAttachmentFixture(t, config)
+
+	vo := first.upload(t, "hello")
+	_, object, err := first.noticeService.OpenAttachment(noticeAttachmentParam(t, vo.URL))
+	if assert.NoError(t, err) {
+		object.Reader.Close()
+	}
+
+	_, _, err = second.noticeService.OpenAttachment(noticeAttachmentParam(t, second.noticeService.AttachmentURL(1)))
+	assert.True(t, errors.Is(err, errors.DatabaseRecordNotFound))
+	_, _, err = second.noticeService.OpenAttachment(noticeAttachmentParam(t, vo.URL))
+	assert.True(t, errors.Is(err, errors.NoticeAttachmentSignInvalid))
+
+	// 配置了 Secret 时各实例签发的地址可以互相校验
+	config.NoticeAttachment = &lib.NoticeAttachmentConfig{Secret: "attachment-secret"}
+	third := newNoticeAttachmentFixture(t, config)
+	fourth := newNoticeAttachmentFixture(t, config)
+	fourth.upload(t, "hello")
+	_, object, err = fourth.noticeService.OpenAttachment(noticeAttachmentParam(t, third.noticeService.AttachmentURL(1)))
+	if assert.NoError(t, err) {
+		object.Reader.Close()
+	}
+}
+
+// TestNoticeAttachmentRemovedAfterSweep 修改通知公告时移除的附件先标记，文件在清理任务中删除
+func TestNoticeAttachmentRemovedAfterSweep(t *testing.T) {
+	f := newNoticeAttachmentFixture(t, lib.Config{})
+
+	vo := f.upload(t, "hello")
+	form := &system.NoticeForm{Title: "notice", Type: 1, Level: "L", TargetType: 1, AttachmentIds: []uint64{vo.ID}}
+	if !assert.NoError(t, f.noticeService.Create(form, 1)) {
+		return
+	}
+
+	var notice system.Notice
+	assert.NoError(t, f.db.ORM.First(&notice).Error)
+	form.AttachmentIds = nil
+	if !assert.NoError(t, f.noticeService.Update(notice.ID, form, 1)) {
+		return
+	}
+
+	// 移除后不再显示，也不能重新关联，文件在清理前保留
+	detail, err := f.noticeService.GetForm(notice.ID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, detail.AttachmentIds)
+	}
+	form.AttachmentIds = []uint64{vo.ID}
+	assert.Error(t, f.noticeService.Update(notice.ID, form, 1))
+	assert.Equal(t, 1, storedFiles(t, f.root))
+
+	count, err := f.noticeService.SweepAttachments()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 0, storedFiles(t, f.root))
+}
+
+// TestNoticeAttachmentSweepUnlinkedUploads 超过 UnlinkedTTL 仍未关联的上传被清理
+func TestNoticeAttachmentSweepUnlinkedUploads(t *testing.T) {
+	f := newNoticeAttachmentFixture(t, lib.Config{})
+
+	stale := f.upload(t, "stale")
+	fresh := f.upload(t, "fresh")
+	assert.NoError(t, f.db.ORM.Model(&system.NoticeAttachment{}).Where("id = ?", stale.ID).
+		Update("create_time", time.Now().Add(-25*time.Hour)).Error)
+
+	count, err := f.noticeService.SweepAttachments()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, storedFiles(t, f.root))
+
+	var ids []uint64
+	assert.NoError(t, f.db.ORM.Model(&system.NoticeAttachment{}).Pluck("id", &ids).Error)
+	assert.Equal(t, []uint64{fresh.ID}, ids)
+}
+
+// TestNoticeAttachmentSweepSkippedWhenModuleDisabled 通知公告模块禁用时定时清理不执行，重新启用后恢复
+func TestNoticeAttachmentSweepSkippedWhenModuleDisabled(t *testing.T) {
+	f := newNoticeAttachmentFixture(t, lib.Config{})
+
+	stale := f.upload(t, "stale")
+	assert.NoError(t, f.db.ORM.Model(&system.NoticeAttachment{}).Where("id = ?", stale.ID).
+		Update("create_time", time.Now().Add(-25*time.Hour)).Error)
+
+	finished := make(chan crontab.Execution, 1)
+	f.cron.Cron.SetRecorder(func(e crontab.Execution) { finished <- e })
+	run := func() crontab.Execution {
+		t.Helper()
+
+		if err := f.cron.Cron.RunTask("notice_attachment_sweep"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-finished:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("sweep task did not finish")
+			return crontab.Execution{}
+		}
+	}
+
+	assert.NoError(t, f.featureModules.SetEnabled(lib.FeatureModuleNotice, false))
+	run()
+	assert.Equal(t, 1, storedFiles(t, f.root))
+
+	assert.NoError(t, f.featureModules.SetEnabled(lib.FeatureModuleNotice, true))
+	e := run()
+	assert.Equal(t, "deleted 1 attachments", e.Result)
+	assert.Equal(t, 0, storedFiles(t, f.root))
+}
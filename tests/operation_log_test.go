@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+func newOperationLogTestEngine(t *testing.T, cfg *lib.OperationLogConfig, taskQueue lib.TaskQueue) (*echo.Echo, lib.Database) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.OperationLog{}, &system.Log{})
+	logService := service.NewLogService(logger, lib.LogShipper{}, repository.NewLogRepository(db, logger))
+	operationLogService := service.NewOperationLogService(logger, repository.NewOperationLogRepository(db, logger))
+
+	engine := echo.New()
+	logMiddleware := middlewares.NewLogMiddleware(lib.HttpHandler{Engine: engine}, logger, lib.Config{OperationLog: cfg}, taskQueue, logService, operationLogService)
+	logMiddleware.Setup()
+	engine.POST("/api/v1/users", func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) })
+	engine.POST("/api/v1/tags", func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) })
+
+	return engine, db
+}
+
+func postOperation(engine *echo.Echo, path, contentType, body string) {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set(echo.HeaderContentType, contentType)
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+}
+
+func logCount(t *testing.T, db lib.Database, model interface{}) int64 {
+	var count int64
+	assert.NoError(t, db.ORM.Model(model).Count(&count).Error)
+	return count
+}
+
+func TestOperationLogMasksSensitiveFields(t *testing.T) {
+	engine, db := newOperationLogTestEngine(t, &lib.OperationLogConfig{Enable: true}, lib.TaskQueue{})
+
+	postOperation(engine, "/api/v1/tags", echo.MIMEApplicationJSON, `{"username":"alice","password":"p@ss","code":"123456","key":"ak-secret","dictCode":"gender","captchaCode":"abcd"}`)
+	postOperation(engine, "/api/v1/tags", echo.MIMEApplicationForm, url.Values{"username": {"alice"}, "code": {"123456"}, "apiKey": {"ak-secret"}}.Encode())
+	assert.Eventually(t, func() bool { return logCount(t, db, &system.OperationLog{}) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	var list system.OperationLogs
+	assert.NoError(t, db.ORM.Order("id").Find(&list).Error)
+	if !assert.Len(t, list, 2) {
+		return
+	}
+
+	for _, log := range list {
+		assert.Contains(t, log.RequestBody, "alice")
+		assert.NotContains(t, log.RequestBody, "p@ss")
+		assert.NotContains(t, log.RequestBody, "123456")
+		assert.NotContains(t, log.RequestBody, "ak-secret")
+		assert.NotContains(t, log.RequestBody, "abcd")
+	}
+	assert.Contains(t, list[0].RequestBody, `"dictCode":"gender"`)
+}
+
+// TestOperationLogWrittenOnceThroughQueue 每个请求只记录一次：操作日志接口同时写入操作日志与操作审计日志，
+// 其他修改类请求只写入操作审计日志，启用任务队列时通过队列任务写入
+func TestOperationLogWrittenOnceThroughQueue(t *testing.T) {
+	q := queue.New(queue.NewDefaultLogger(), nil, queue.NewTaskRegistry(), queue.WithWorkerCount(1))
+	q.Start()
+	defer q.Shutdown()
+
+	engine, db := newOperationLogTestEngine(t, &lib.OperationLogConfig{Enable: true}, lib.TaskQueue{Queue: q})
+
+	postOperation(engine, "/api/v1/users", echo.MIMEApplicationJSON, `{"username":"alice"}`)
+	postOperation(engine, "/api/v1/tags", echo.MIMEApplicationJSON, `{"name":"tag"}`)
+	assert.Eventually(t, func() bool { return q.SuccessTasks() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 2, q.SubmittedTasks())
+	assert.Equal(t, int64(1), logCount(t, db, &system.Log{}))
+	assert.Equal(t, int64(2), logCount(t, db, &system.OperationLog{}))
+
+	var log system.Log
+	assert.NoError(t, db.ORM.First(&log).Error)
+	assert.Equal(t, `{"username":"alice"}`, log.RequestParams)
+}
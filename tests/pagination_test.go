@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TestQueryPaginationWithoutTotal withTotal=false 时不执行 COUNT，总数为 -1，多查询一行判断是否有下一页
+func TestQueryPaginationWithoutTotal(t *testing.T) {
+	db := newTestDB(t, &system.Tag{})
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, db.ORM.Create(&system.Tag{Name: fmt.Sprintf("tag-%d", i)}).Error)
+	}
+
+	var counts int
+	assert.NoError(t, db.ORM.Callback().Query().After("gorm:query").Register("test:count_queries", func(tx *gorm.DB) {
+		if strings.Contains(strings.ToLower(tx.Statement.SQL.String()), "count(") {
+			counts++
+		}
+	}))
+
+	query := func(pageNum int, withTotal *bool) ([]system.Tag, *dto.Pagination) {
+		var tags []system.Tag
+		pagination, err := repository.QueryPagination(db.ORM.Model(&system.Tag{}).Order("id"), dto.PaginationParam{
+			PageNum: pageNum, PageSize: 2, WithTotal: withTotal,
+		}, &tags)
+		assert.NoError(t, err)
+		return tags, pagination
+	}
+
+	withTotal := false
+	cases := []struct {
+		pageNum int
+		ids     []uint64
+		hasNext bool
+	}{
+		{1, []uint64{1, 2}, true},
+		{2, []uint64{3, 4}, true},
+		{3, []uint64{5}, false},
+		{4, nil, false},
+	}
+	for _, c := range cases {
+		tags, pagination := query(c.pageNum, &withTotal)
+		ids := make([]uint64, 0, len(tags))
+		for _, tag := range tags {
+			ids = append(ids, tag.ID)
+		}
+		assert.ElementsMatch(t, c.ids, ids, "page %d", c.pageNum)
+		assert.Equal(t, &dto.Pagination{Total: -1, PageNum: c.pageNum, PageSize: 2, HasNext: c.hasNext}, pagination, "page %d", c.pageNum)
+	}
+	assert.Zero(t, counts, "withTotal=false should skip the count query")
+
+	// 默认统计总数，HasNext 由总数推算
+	_, pagination := query(2, nil)
+	assert.Equal(t, &dto.Pagination{Total: 5, PageNum: 2, PageSize: 2, HasNext: true}, pagination)
+	_, pagination = query(3, nil)
+	assert.Equal(t, &dto.Pagination{Total: 5, PageNum: 3, PageSize: 2, HasNext: false}, pagination)
+	assert.Equal(t, 2, counts)
+}
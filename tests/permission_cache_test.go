@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+)
+
+// TestPermissionCacheInvalidateDuringLoad 加载期间清除缓存（例如收回角色权限）时，加载到的旧权限不写回缓存，
+// 清除之后的请求不合并到旧的加载，直接读取新的权限
+func TestPermissionCacheInvalidateDuringLoad(t *testing.T) {
+	permCache := service.NewPermissionCache(newTestLogger(), newTestCache(t), repository.UserRoleRepository{})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan []string)
+	go func() {
+		perms, err := permCache.LoadUserPerms(1, func() ([]string, error) {
+			close(started)
+			<-release
+			return []string{"sys:user:query"}, nil
+		})
+		assert.NoError(t, err)
+		done <- perms
+	}()
+	<-started
+
+	permCache.InvalidateUserCache(1)
+
+	perms, err := permCache.LoadUserPerms(1, func() ([]string, error) { return []string{"sys:user:add"}, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sys:user:add"}, perms)
+
+	close(release)
+	assert.Equal(t, []string{"sys:user:query"}, <-done)
+
+	perms, cached := permCache.GetUserPerms(1)
+	assert.True(t, cached)
+	assert.Equal(t, []string{"sys:user:add"}, perms, "stale perms loaded before the invalidation must not overwrite the cache")
+
+	// 没有加载在进行时清除，之后写回的缓存照常使用
+	permCache.InvalidateUserCache(1)
+	_, cached = permCache.GetUserPerms(1)
+	assert.False(t, cached)
+	perms, err = permCache.LoadUserPerms(1, func() ([]string, error) { return []string{"sys:user:edit"}, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sys:user:edit"}, perms)
+	perms, cached = permCache.GetUserPerms(1)
+	assert.True(t, cached)
+	assert.Equal(t, []string{"sys:user:edit"}, perms)
+}
@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/route"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TestPprofRoutesPermission pprof 接口需要 sys:maintenance:pprof 权限，API Key 即使授权范围包含该权限也被拒绝
+func TestPprofRoutesPermission(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.UserRole{}, &system.Role{}, &system.RoleMenu{}, &system.Menu{})
+	assert.NoError(t, db.ORM.Create(&system.Menu{ID: 1, Name: "性能分析", Type: 4, Perm: "sys:maintenance:pprof"}).Error)
+	assert.NoError(t, db.ORM.Create(&system.Role{ID: 1, Name: "运维", Code: "OPS", Status: 1}).Error)
+	assert.NoError(t, db.ORM.Create(&system.RoleMenu{RoleID: 1, MenuID: 1}).Error)
+	assert.NoError(t, db.ORM.Create(&system.UserRole{UserID: 1, RoleID: 1}).Error)
+
+	userRoleRepository := repository.NewUserRoleRepository(db, logger)
+	permissionService := service.NewPermissionService(logger, lib.HttpHandler{}, lib.NewPermRegistry(), lib.NewFeatureModules(),
+		service.NewPermissionCache(logger, newTestCache(t), userRoleRepository),
+		repository.NewMenuRepository(db, logger), repository.NewRoleMenuRepository(db, logger),
+		userRoleRepository, repository.NewRoleRepository(db, logger))
+
+	engine := echo.New()
+	handler := lib.HttpHandler{Engine: engine, RouterV1: engine.Group("/api/v1")}
+	permMiddleware := middlewares.NewPermissionMiddleware(
+		handler, logger, lib.Config{}, lib.NewPermRegistry(),
+		permissionService, newApiKeyTestUserService(), service.PolicyService{},
+	)
+	var claims *dto.JwtClaims
+	engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set(constants.CurrentUser, claims)
+			return next(ctx)
+		}
+	})
+	route.NewPprofRoutes(logger, handler, permMiddleware).Setup()
+
+	cases := []struct {
+		name   string
+		claims *dto.JwtClaims
+		status int
+	}{
+		{"user with perm", &dto.JwtClaims{ID: 1, Username: "ops"}, http.StatusOK},
+		{"superadmin", &dto.JwtClaims{Username: "root"}, http.StatusOK},
+		{"user without perm", &dto.JwtClaims{ID: 2, Username: "alice"}, http.StatusForbidden},
+		{"api key with pprof scope", &dto.JwtClaims{ID: 1, Username: "ops", ApiKeyID: 7, Scopes: []string{"sys:maintenance:pprof"}}, http.StatusForbidden},
+		{"api key with wildcard scope", &dto.JwtClaims{ID: 1, Username: "ops", ApiKeyID: 8, Scopes: []string{"*:*:*"}}, http.StatusForbidden},
+		{"superadmin api key", &dto.JwtClaims{Username: "root", ApiKeyID: 9}, http.StatusForbidden},
+	}
+	for _, c := range cases {
+		claims = c.claims
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/api/v1/debug/pprof/cmdline", nil),
+			httptest.NewRequest(http.MethodPost, "/api/v1/debug/pprof/symbol", nil),
+		} {
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			assert.Equal(t, c.status, rec.Code, "%s: %s %s", c.name, req.Method, req.URL.Path)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/queue"
+)
+
+func newRedisTaskRepository(t *testing.T, finishedTTL time.Duration) (queue.TaskRepository, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return queue.NewRedisTaskRepository(client, "test", finishedTTL), mr
+}
+
+func isMember(mr *miniredis.Miniredis, key, member string) bool {
+	ok, _ := mr.SIsMember(key, member)
+	return ok
+}
+
+func TestRedisTaskRepositorySaveMaintainsPendingSets(t *testing.T) {
+	repo, mr := newRedisTaskRepository(t, time.Hour)
+	ctx := context.Background()
+
+	task := &queue.TaskModel{Type: "export", Status: queue.StatusQueued}
+	if !assert.NoError(t, repo.Create(ctx, task)) {
+		return
+	}
+	assert.True(t, isMember(mr, "test:pending", "1"))
+	assert.True(t, isMember(mr, "test:pending:export", "1"))
+	assert.Zero(t, mr.TTL("test:task:1"))
+
+	// 类型变化时从原类型的待执行集合移除
+	task.Type = "report"
+	assert.NoError(t, repo.Update(ctx, task))
+	assert.False(t, isMember(mr, "test:pending:export", "1"))
+	assert.True(t, isMember(mr, "test:pending:report", "1"))
+
+	// 结束的任务移出待执行集合并设置过期时间
+	task.Status = queue.StatusCompleted
+	assert.NoError(t, repo.Update(ctx, task))
+	assert.False(t, isMember(mr, "test:pending", "1"))
+	assert.False(t, isMember(mr, "test:pending:report", "1"))
+	assert.Equal(t, time.Hour, mr.TTL("test:task:1"))
+
+	pending, err := repo.GetPendingTasks(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// 重新排队的任务不再过期
+	task.Status = queue.StatusQueued
+	assert.NoError(t, repo.Update(ctx, task))
+	assert.Zero(t, mr.TTL("test:task:1"))
+	pending, err = repo.GetPendingTasks(ctx, "report")
+	if assert.NoError(t, err) && assert.Len(t, pending, 1) {
+		assert.Equal(t, uint64(1), pending[0].ID)
+	}
+}
+
+func TestRedisTaskRepositoryProgressAndDelete(t *testing.T) {
+	repo, mr := newRedisTaskRepository(t, time.Hour)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, repo.UpdateProgress(ctx, 9, queue.Progresses{}, 1), gorm.ErrRecordNotFound)
+	assert.False(t, mr.Exists("test:task:9"))
+
+	task := &queue.TaskModel{Type: "export", Status: queue.StatusProcessing}
+	if !assert.NoError(t, repo.Create(ctx, task)) {
+		return
+	}
+
+	progress := queue.Progresses{"export": &queue.Progress{Total: 10, Current: 4}}
+	assert.NoError(t, repo.UpdateProgress(ctx, task.ID, progress, 1700000000))
+	stored, err := repo.GetByID(ctx, task.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(4), stored.PublicState.Progress["export"].Current)
+		assert.Equal(t, int64(1700000000), stored.PublicState.ProgressUpdatedAt)
+	}
+
+	assert.NoError(t, repo.Delete(ctx, task.ID))
+	assert.False(t, mr.Exists("test:task:1"))
+	assert.False(t, isMember(mr, "test:pending", "1"))
+	assert.False(t, isMember(mr, "test:pending:export", "1"))
+	members, _ := mr.ZMembers("test:tasks")
+	assert.Empty(t, members)
+
+	_, err = repo.GetByID(ctx, task.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRedisTaskRepositoryListDropsExpiredTasks(t *testing.T) {
+	repo, mr := newRedisTaskRepository(t, time.Hour)
+	ctx := context.Background()
+
+	for _, status := range []queue.Status{queue.StatusCompleted, queue.StatusQueued, queue.StatusError} {
+		assert.NoError(t, repo.Create(ctx, &queue.TaskModel{Type: "export", Status: status}))
+	}
+
+	list, total, err := repo.List(ctx, &queue.TaskFilter{Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, list, 2) {
+		assert.Equal(t, int64(3), total)
+		assert.Equal(t, uint64(3), list[0].ID)
+	}
+
+	mr.FastForward(2 * time.Hour)
+	list, total, err = repo.List(ctx, &queue.TaskFilter{})
+	if assert.NoError(t, err) && assert.Len(t, list, 1) {
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, queue.StatusQueued, list[0].Status)
+	}
+	members, _ := mr.ZMembers("test:tasks")
+	assert.Equal(t, []string{"2"}, members)
+}
+
+// TestTaskRepositoryUsesQueueStorage 后台任务列表与保留策略读取任务存储中的任务
+func TestTaskRepositoryUsesQueueStorage(t *testing.T) {
+	store, _ := newRedisTaskRepository(t, 0)
+	ctx := context.Background()
+
+	old := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, store.Create(ctx, &queue.TaskModel{Type: "export", Status: queue.StatusCompleted}))
+	assert.NoError(t, store.Create(ctx, &queue.TaskModel{Type: "mail", Status: queue.StatusQueued}))
+	assert.NoError(t, store.Create(ctx, &queue.TaskModel{Type: "export", Status: queue.StatusError, PublicState: queue.TaskPublicState{Error: "disk full"}}))
+
+	taskRepository := repository.NewTaskRepository(lib.Database{}, newTestLogger(), lib.TaskQueue{Repository: store})
+
+	result, err := taskRepository.Query(&system.TaskQueryParam{Type: "export"})
+	if assert.NoError(t, err) && assert.Len(t, result.List, 2) {
+		assert.Equal(t, int64(2), result.Pagination.Total)
+		assert.Equal(t, "disk full", result.List[0].Error)
+	}
+
+	task, err := taskRepository.Get(2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "mail", task.Type)
+	}
+
+	types, err := taskRepository.GetTaskTypes()
+	assert.NoError(t, err)
+	assert.Equal(t, []system.TaskTypeVO{{Label: "export", Value: "export"}, {Label: "mail", Value: "mail"}}, types)
+
+	stats, err := taskRepository.GetStatusCounts()
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1), stats.QueuedCount)
+		assert.Equal(t, int64(1), stats.CompletedCount)
+		assert.Equal(t, int64(1), stats.ErrorCount)
+	}
+
+	count, err := taskRepository.CountFinished(old)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	ids, err := taskRepository.FinishedIDs(time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{1, 3}, ids)
+
+	assert.NoError(t, taskRepository.BatchDelete(ids))
+	_, err = taskRepository.Get(1)
+	assert.ErrorIs(t, err, errors.DatabaseRecordNotFound)
+}
+
+func TestTaskRepositoryQueriesDatabaseStorage(t *testing.T) {
+	db := newTestDB(t, &queue.TaskModel{})
+	store := queue.NewGormTaskRepository(db.ORM)
+	ctx := context.Background()
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	assert.NoError(t, store.Create(ctx, &queue.TaskModel{Type: "export", Status: queue.StatusCompleted, CreatedAt: yesterday}))
+	assert.NoError(t, store.Create(ctx, &queue.TaskModel{Type: "export", Status: queue.StatusError, PublicState: queue.TaskPublicState{Error: "disk full"}}))
+
+	taskRepository := repository.NewTaskRepository(db, newTestLogger(), lib.TaskQueue{})
+	today := time.Now().Format("2006-01-02")
+	result, err := taskRepository.Query(&system.TaskQueryParam{CreateTimeFrom: today, CreateTimeTo: today})
+	if assert.NoError(t, err) && assert.Len(t, result.List, 1) {
+		assert.Equal(t, uint64(2), result.List[0].ID)
+	}
+
+	result, err = taskRepository.Query(&system.TaskQueryParam{Keywords: "disk"})
+	if assert.NoError(t, err) && assert.Len(t, result.List, 1) {
+		assert.Equal(t, "disk full", result.List[0].Error)
+	}
+
+	// 删除为物理删除，保留策略可以回收空间
+	assert.NoError(t, taskRepository.Delete(1))
+	var count int64
+	assert.NoError(t, db.ORM.Unscoped().Model(&queue.TaskModel{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestInMemoryTaskRepositoryConcurrentAccess 进度更新与列表查询并发执行，需配合 -race 检查
+func TestInMemoryTaskRepositoryConcurrentAccess(t *testing.T) {
+	repo := queue.NewInMemoryTaskRepository()
+	ctx := context.Background()
+
+	task := &queue.TaskModel{Type: "export", Status: queue.StatusProcessing}
+	if !assert.NoError(t, repo.Create(ctx, task)) {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			progress := queue.Progresses{"export": {Total: 8, Current: int64(i)}}
+			assert.NoError(t, repo.UpdateProgress(ctx, task.ID, progress, int64(i)))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _, err := repo.List(ctx, &queue.TaskFilter{})
+			assert.NoError(t, err)
+			_, err = repo.GetPendingTasks(ctx)
+			assert.NoError(t, err)
+			_, err = repo.Types(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	stored, err := repo.GetByID(ctx, task.ID)
+	if assert.NoError(t, err) {
+		assert.Len(t, stored.PublicState.Progress, 1)
+	}
+}
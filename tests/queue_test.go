@@ -3,11 +3,18 @@ package tests
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/pkg/clock"
+	"github.com/top-system/light-admin/pkg/downloader"
+	"github.com/top-system/light-admin/pkg/eventbus"
+	"github.com/top-system/light-admin/pkg/mailer"
 	"github.com/top-system/light-admin/pkg/queue"
 )
 
@@ -377,12 +384,22 @@ func TestTaskRegistry(t *testing.T) {
 		t.Error("Retrieved task should match")
 	}
 
+	// 按 ID 顺序列出任务
+	other := NewSimpleTask("registry-other")
+	registry.Set(id2, other)
+	if list := registry.List(); len(list) != 2 || list[0] != task || list[1] != other {
+		t.Errorf("Expected tasks ordered by ID, got %v", list)
+	}
+
 	// 删除任务
 	registry.Delete(id1)
 	_, ok = registry.Get(id1)
 	if ok {
 		t.Error("Should not find deleted task")
 	}
+	if list := registry.List(); len(list) != 1 {
+		t.Errorf("Expected 1 task after delete, got %d", len(list))
+	}
 }
 
 // TestScheduler 测试调度器
@@ -444,6 +461,162 @@ func TestScheduler(t *testing.T) {
 	}
 }
 
+// newOwnedTask 创建属于指定用户的简单任务
+func newOwnedTask(name string, ownerID uint64) *SimpleTask {
+	task := NewSimpleTask(name)
+	task.DirectOwner = &queue.TaskOwner{ID: ownerID}
+	return task
+}
+
+// TestFairScheduler 测试按所有者加权轮询的调度器
+func TestFairScheduler(t *testing.T) {
+	weights := map[uint64]int{1: 2}
+	scheduler := queue.NewFairScheduler(0, func(ownerID uint64) int {
+		return weights[ownerID]
+	}, queue.NewDefaultLogger())
+
+	// 用户 1 先提交大量任务，用户 2、3 随后提交
+	for _, task := range []*SimpleTask{
+		newOwnedTask("a1", 1), newOwnedTask("a2", 1), newOwnedTask("a3", 1),
+		newOwnedTask("a4", 1), newOwnedTask("a5", 1),
+		newOwnedTask("b1", 2), newOwnedTask("b2", 2),
+		newOwnedTask("c1", 3),
+	} {
+		if err := scheduler.Queue(task); err != nil {
+			t.Fatalf("Failed to queue %s: %v", task.Name, err)
+		}
+	}
+
+	// 用户 1 权重为 2，每轮连续获取两个任务；同一用户内保持提交顺序
+	expected := []string{"a1", "a2", "b1", "c1", "a3", "a4", "b2", "a5"}
+	for i, name := range expected {
+		task, err := scheduler.Request()
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		if got := task.(*SimpleTask).Name; got != name {
+			t.Errorf("Request %d: expected %s, got %s", i, name, got)
+		}
+	}
+
+	if _, err := scheduler.Request(); err != queue.ErrNoTaskInQueue {
+		t.Errorf("Expected ErrNoTaskInQueue, got %v", err)
+	}
+
+	// 等待重试的任务不阻塞其他用户
+	delayed := newOwnedTask("delayed", 1)
+	delayed.ResumeAfter(time.Hour)
+	_ = scheduler.Queue(delayed)
+	_ = scheduler.Queue(newOwnedTask("ready", 2))
+
+	task, err := scheduler.Request()
+	if err != nil {
+		t.Fatalf("Failed to request ready task: %v", err)
+	}
+	if got := task.(*SimpleTask).Name; got != "ready" {
+		t.Errorf("Expected ready task, got %s", got)
+	}
+	if _, err := scheduler.Request(); err != queue.ErrNoTaskInQueue {
+		t.Errorf("Expected ErrNoTaskInQueue for delayed task, got %v", err)
+	}
+
+	if err := scheduler.Shutdown(); err != nil {
+		t.Fatalf("Failed to shutdown scheduler: %v", err)
+	}
+	if err := scheduler.Queue(newOwnedTask("after-shutdown", 1)); err != queue.ErrQueueShutdown {
+		t.Errorf("Expected ErrQueueShutdown, got %v", err)
+	}
+}
+
+// newPriorityTask 创建指定优先级的简单任务
+func newPriorityTask(name string, priority int) *SimpleTask {
+	task := NewSimpleTask(name)
+	task.SetPriority(priority)
+	return task
+}
+
+// TestPriorityScheduler 测试按优先级调度的调度器
+func TestPriorityScheduler(t *testing.T) {
+	scheduler := queue.NewPriorityScheduler(0, queue.NewDefaultLogger())
+
+	for _, task := range []*SimpleTask{
+		newPriorityTask("low1", 0), newPriorityTask("high1", 10), newPriorityTask("low2", 0),
+		newPriorityTask("mid", 5), newPriorityTask("high2", 10),
+	} {
+		if err := scheduler.Queue(task); err != nil {
+			t.Fatalf("Failed to queue %s: %v", task.Name, err)
+		}
+	}
+
+	// 优先级高的先执行，同优先级保持提交顺序
+	expected := []string{"high1", "high2", "mid", "low1", "low2"}
+	for i, name := range expected {
+		task, err := scheduler.Request()
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		if got := task.(*SimpleTask).Name; got != name {
+			t.Errorf("Request %d: expected %s, got %s", i, name, got)
+		}
+	}
+
+	if _, err := scheduler.Request(); err != queue.ErrNoTaskInQueue {
+		t.Errorf("Expected ErrNoTaskInQueue, got %v", err)
+	}
+
+	// 等待重试的高优先级任务不阻塞低优先级任务
+	delayed := newPriorityTask("delayed", 10)
+	delayed.ResumeAfter(time.Hour)
+	_ = scheduler.Queue(delayed)
+	_ = scheduler.Queue(newPriorityTask("ready", 0))
+
+	task, err := scheduler.Request()
+	if err != nil {
+		t.Fatalf("Failed to request ready task: %v", err)
+	}
+	if got := task.(*SimpleTask).Name; got != "ready" {
+		t.Errorf("Expected ready task, got %s", got)
+	}
+	if _, err := scheduler.Request(); err != queue.ErrNoTaskInQueue {
+		t.Errorf("Expected ErrNoTaskInQueue for delayed task, got %v", err)
+	}
+
+	limited := queue.NewPriorityScheduler(1, queue.NewDefaultLogger())
+	_ = limited.Queue(newPriorityTask("first", 0))
+	if err := limited.Queue(newPriorityTask("second", 0)); err != queue.ErrMaxCapacity {
+		t.Errorf("Expected ErrMaxCapacity, got %v", err)
+	}
+
+	if err := scheduler.Shutdown(); err != nil {
+		t.Fatalf("Failed to shutdown scheduler: %v", err)
+	}
+	if err := scheduler.Queue(newPriorityTask("after-shutdown", 0)); err != queue.ErrQueueShutdown {
+		t.Errorf("Expected ErrQueueShutdown, got %v", err)
+	}
+}
+
+// TestQueuePersistsPriority 测试持久化任务保留优先级
+func TestQueuePersistsPriority(t *testing.T) {
+	repo := queue.NewInMemoryTaskRepository()
+	q := queue.New(queue.NewDefaultLogger(), repo, queue.NewTaskRegistry(),
+		queue.WithScheduler(queue.SchedulerPriority))
+
+	task := NewBlockingTask()
+	task.SetPriority(10)
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	model, err := repo.GetByID(context.Background(), uint64(task.ID()))
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if model.PublicState.Priority != 10 || task.Priority() != 10 {
+		t.Errorf("Expected priority 10, got %d in repository and %d on task", model.PublicState.Priority, task.Priority())
+	}
+	q.Shutdown()
+}
+
 // TestInMemoryRepository 测试内存任务仓库
 func TestInMemoryRepository(t *testing.T) {
 	repo := queue.NewInMemoryTaskRepository()
@@ -508,6 +681,209 @@ func TestInMemoryRepository(t *testing.T) {
 	}
 }
 
+// ProgressTask 上报进度的持久化任务
+type ProgressTask struct {
+	*queue.DBTask
+	current int64
+}
+
+func NewProgressTask() *ProgressTask {
+	return &ProgressTask{
+		DBTask: &queue.DBTask{
+			TaskModel: &queue.TaskModel{
+				Type: "progress_task",
+			},
+		},
+	}
+}
+
+func (t *ProgressTask) Do(ctx context.Context) (queue.Status, error) {
+	for i := 0; i < 5; i++ {
+		atomic.AddInt64(&t.current, 10)
+		time.Sleep(20 * time.Millisecond)
+	}
+	return queue.StatusCompleted, nil
+}
+
+func (t *ProgressTask) Progress(ctx context.Context) queue.Progresses {
+	return queue.Progresses{
+		"work": {Total: 50, Current: atomic.LoadInt64(&t.current)},
+	}
+}
+
+// TestQueueProgressSnapshot 测试进度快照持久化
+func TestQueueProgressSnapshot(t *testing.T) {
+	repo := queue.NewInMemoryTaskRepository()
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		repo,
+		queue.NewTaskRegistry(),
+		queue.WithWorkerCount(1),
+		queue.WithProgressSnapshotInterval(10*time.Millisecond),
+	)
+
+	q.Start()
+	defer q.Shutdown()
+
+	task := NewProgressTask()
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+
+	model, err := repo.GetByID(context.Background(), uint64(task.ID()))
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+
+	if model.Status != queue.StatusCompleted {
+		t.Fatalf("Expected completed task, got %s", model.Status)
+	}
+
+	progress, ok := model.PublicState.Progress["work"]
+	if !ok {
+		t.Fatal("Progress snapshot should be persisted")
+	}
+
+	if progress.Current != 50 || progress.Total != 50 {
+		t.Errorf("Expected final progress 50/50, got %d/%d", progress.Current, progress.Total)
+	}
+
+	if model.PublicState.ProgressUpdatedAt == 0 {
+		t.Error("Progress snapshot time should be set")
+	}
+}
+
+// BlockingTask 持久化任务，收到 release 后才完成，用于占住 worker
+type BlockingTask struct {
+	*queue.DBTask
+	release chan struct{}
+}
+
+func NewBlockingTask() *BlockingTask {
+	return &BlockingTask{
+		DBTask: &queue.DBTask{
+			TaskModel: &queue.TaskModel{
+				Type: "blocking_task",
+			},
+		},
+		release: make(chan struct{}),
+	}
+}
+
+func (t *BlockingTask) Do(ctx context.Context) (queue.Status, error) {
+	select {
+	case <-t.release:
+		return queue.StatusCompleted, nil
+	case <-ctx.Done():
+		return queue.StatusError, ctx.Err()
+	}
+}
+
+// TestQueueCancel 测试取消排队中的任务
+func TestQueueCancel(t *testing.T) {
+	repo := queue.NewInMemoryTaskRepository()
+	q := queue.New(queue.NewDefaultLogger(), repo, queue.NewTaskRegistry(), queue.WithWorkerCount(1))
+
+	q.Start()
+	defer q.Shutdown()
+
+	ctx := context.Background()
+	blocker := NewBlockingTask()
+	if err := q.QueueTask(ctx, blocker); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	task := NewProgressTask()
+	if err := q.QueueTask(ctx, task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	if err := q.Cancel(ctx, blocker.ID()); !errors.Is(err, queue.ErrTaskNotCancelable) {
+		t.Errorf("Expected running task not to be cancelable, got %v", err)
+	}
+	if err := q.Cancel(ctx, task.ID()); err != nil {
+		t.Fatalf("Failed to cancel task: %v", err)
+	}
+	if err := q.Cancel(ctx, task.ID()); !errors.Is(err, queue.ErrTaskNotFound) {
+		t.Errorf("Expected canceled task to be removed from registry, got %v", err)
+	}
+
+	close(blocker.release)
+	time.Sleep(300 * time.Millisecond)
+
+	model, err := repo.GetByID(ctx, uint64(task.ID()))
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if model.Status != queue.StatusCanceled {
+		t.Errorf("Expected canceled task, got %s", model.Status)
+	}
+	if atomic.LoadInt64(&task.current) != 0 {
+		t.Error("Canceled task should not run")
+	}
+}
+
+// RetryTask 第一次执行失败，从任务记录恢复后执行成功
+type RetryTask struct {
+	*queue.DBTask
+}
+
+var retryTaskRuns int32
+
+func (t *RetryTask) Do(ctx context.Context) (queue.Status, error) {
+	if atomic.AddInt32(&retryTaskRuns, 1) == 1 {
+		return queue.StatusError, fmt.Errorf("first run: %w", queue.CriticalErr)
+	}
+	return queue.StatusCompleted, nil
+}
+
+// TestQueueRetry 测试重新执行失败的任务
+func TestQueueRetry(t *testing.T) {
+	atomic.StoreInt32(&retryTaskRuns, 0)
+	queue.RegisterResumableTaskFactory("retry_task", func(model *queue.TaskModel) queue.Task {
+		return &RetryTask{DBTask: &queue.DBTask{TaskModel: model}}
+	})
+
+	repo := queue.NewInMemoryTaskRepository()
+	q := queue.New(queue.NewDefaultLogger(), repo, queue.NewTaskRegistry(),
+		queue.WithWorkerCount(1), queue.WithTaskPullInterval(10*time.Millisecond))
+
+	q.Start()
+	defer q.Shutdown()
+
+	ctx := context.Background()
+	task := &RetryTask{DBTask: &queue.DBTask{TaskModel: &queue.TaskModel{Type: "retry_task"}}}
+	if err := q.QueueTask(ctx, task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	id := task.ID()
+	if model, _ := repo.GetByID(ctx, uint64(id)); model == nil || model.Status != queue.StatusError {
+		t.Fatalf("Expected failed task, got %+v", model)
+	}
+
+	if err := q.Retry(ctx, id); err != nil {
+		t.Fatalf("Failed to retry task: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	model, _ := repo.GetByID(ctx, uint64(id))
+	if model == nil || model.Status != queue.StatusCompleted {
+		t.Fatalf("Expected completed task after retry, got %+v", model)
+	}
+
+	if err := q.Retry(ctx, id); !errors.Is(err, queue.ErrTaskNotRetryable) {
+		t.Errorf("Expected completed task not to be retryable, got %v", err)
+	}
+	if err := q.Retry(ctx, id+100); !errors.Is(err, queue.ErrTaskNotFound) {
+		t.Errorf("Expected unknown task not to be found, got %v", err)
+	}
+}
+
 // TestTaskStatus 测试任务状态
 func TestTaskStatus(t *testing.T) {
 	tests := []struct {
@@ -530,6 +906,701 @@ func TestTaskStatus(t *testing.T) {
 	}
 }
 
+// TestQueueSynchronousRetry 测试同步模式下的重试，退避等待由假时钟跳过
+func TestQueueSynchronousRetry(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		nil,
+		queue.NewTaskRegistry(),
+		queue.WithMaxRetry(3),
+		queue.WithRetryDelay(time.Minute),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	task := NewFailingTask(2)
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	// QueueTask 返回时任务已结束
+	if task.Status() != queue.StatusCompleted {
+		t.Fatalf("Expected completed task, got %s", task.Status())
+	}
+	if atomic.LoadInt32(&task.failCount) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", task.failCount)
+	}
+	if q.SuccessTasks() != 1 {
+		t.Errorf("Expected 1 success task, got %d", q.SuccessTasks())
+	}
+	if elapsed := fake.Since(start); elapsed != 2*time.Minute {
+		t.Errorf("Expected 2 retry delays on the clock, got %s", elapsed)
+	}
+}
+
+// TestQueueEmailTaskRetry 测试邮件任务发送失败后按队列配置重试
+func TestQueueEmailTaskRetry(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		nil,
+		queue.NewTaskRegistry(),
+		queue.WithMaxRetry(3),
+		queue.WithRetryDelay(time.Minute),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	var attempts int32
+	var sent mailer.Message
+	send := func(ctx context.Context, msg mailer.Message) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("connection refused")
+		}
+		sent = msg
+		return nil
+	}
+
+	msg := mailer.Message{To: []string{"alice@example.com"}, Subject: "Download failed", Text: "disk full"}
+	task, err := queue.NewEmailTask(send, msg, &queue.TaskOwner{ID: 1})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	if task.Status() != queue.StatusCompleted {
+		t.Fatalf("Expected completed task, got %s", task.Status())
+	}
+	if attempts != 3 || sent.Subject != msg.Subject || sent.To[0] != "alice@example.com" {
+		t.Errorf("Unexpected delivery after %d attempts: %+v", attempts, sent)
+	}
+
+	// 从存储恢复的任务使用工厂设置的发送函数
+	restored := queue.NewEmailTaskFactory(send)(task.Model())
+	if summary := restored.Summarize(); summary == nil || summary.Props[queue.SummaryKeyEmailSubject] != msg.Subject {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	if _, err := queue.NewEmailTask(send, mailer.Message{Subject: "empty"}, nil); !errors.Is(err, mailer.ErrNoRecipients) {
+		t.Errorf("Expected ErrNoRecipients, got %v", err)
+	}
+}
+
+// TestQueueWebhookTaskBackoff 测试 Webhook 任务按指数退避自行重试，达到次数上限后不再由队列重试
+func TestQueueWebhookTaskBackoff(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		nil,
+		queue.NewTaskRegistry(),
+		queue.WithMaxRetry(5),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	var (
+		attempts []int
+		finals   []bool
+	)
+	deliver := func(ctx context.Context, deliveryID uint64, attempt int, final bool) error {
+		attempts = append(attempts, attempt)
+		finals = append(finals, final)
+		return errors.New("connection refused")
+	}
+
+	policy := queue.WebhookRetryPolicy{MaxAttempts: 4, RetryDelay: time.Minute, MaxRetryDelay: 3 * time.Minute}
+	task, err := queue.NewWebhookTask(deliver, 7, policy, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	if task.Status() != queue.StatusError {
+		t.Fatalf("Expected error task, got %s", task.Status())
+	}
+	if fmt.Sprint(attempts) != "[1 2 3 4]" || fmt.Sprint(finals) != "[false false false true]" {
+		t.Errorf("Unexpected attempts %v, final flags %v", attempts, finals)
+	}
+	// 1m + 2m + 3m（上限）
+	if elapsed := fake.Since(start); elapsed != 6*time.Minute {
+		t.Errorf("Expected 6 minutes of backoff on the clock, got %s", elapsed)
+	}
+
+	// 从存储恢复的任务带上已尝试的次数
+	restored := queue.NewWebhookTaskFactory(deliver)(task.Model())
+	if summary := restored.Summarize(); summary == nil || summary.Props[queue.SummaryKeyWebhookAttempt] != 4 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+}
+
+// TestQueueTaskAfter 测试延迟提交的任务到时间后才执行，等待由假时钟跳过
+func TestQueueTaskAfter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		nil,
+		queue.NewTaskRegistry(),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	task := NewSimpleTask("delayed")
+	if err := q.QueueTaskAfter(context.Background(), task, 2*time.Hour); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+	if !task.IsExecuted() {
+		t.Fatal("Delayed task should be executed")
+	}
+	if elapsed := fake.Since(start); elapsed != 2*time.Hour {
+		t.Errorf("Expected the task to wait 2h on the clock, got %s", elapsed)
+	}
+
+	// 开始时间已过的任务立即执行
+	past := NewSimpleTask("past")
+	if err := q.QueueTaskAt(context.Background(), past, start); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+	if !past.IsExecuted() || fake.Since(start) != 2*time.Hour {
+		t.Error("Task with a past start time should run immediately")
+	}
+}
+
+// PollingTask 第一次执行后挂起一小时，模拟轮询外部状态的任务
+type PollingTask struct {
+	*queue.DBTask
+	runs int32
+}
+
+func (t *PollingTask) Do(ctx context.Context) (queue.Status, error) {
+	if atomic.AddInt32(&t.runs, 1) == 1 {
+		t.ResumeAfter(time.Hour)
+		return queue.StatusSuspending, nil
+	}
+	return queue.StatusCompleted, nil
+}
+
+// TestQueueWake 测试唤醒挂起的任务，不必等到恢复时间
+func TestQueueWake(t *testing.T) {
+	for _, kind := range []queue.SchedulerKind{queue.SchedulerFIFO, queue.SchedulerFair, queue.SchedulerPriority} {
+		q := queue.New(queue.NewDefaultLogger(), nil, queue.NewTaskRegistry(),
+			queue.WithWorkerCount(1), queue.WithScheduler(kind), queue.WithTaskPullInterval(10*time.Millisecond))
+		q.Start()
+
+		ctx := context.Background()
+		task := &PollingTask{DBTask: &queue.DBTask{TaskModel: &queue.TaskModel{Type: "polling_task"}}}
+		if err := q.QueueTask(ctx, task); err != nil {
+			t.Fatalf("Failed to queue task: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if task.Status() != queue.StatusSuspending {
+			t.Fatalf("%s: expected suspending task, got %s", kind, task.Status())
+		}
+
+		if err := q.Wake(ctx, task.ID()); err != nil {
+			t.Fatalf("%s: failed to wake task: %v", kind, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+		if task.Status() != queue.StatusCompleted {
+			t.Errorf("%s: expected woken task to complete, got %s", kind, task.Status())
+		}
+
+		// 已结束的任务不在注册表中
+		if err := q.Wake(ctx, task.ID()); !errors.Is(err, queue.ErrTaskNotFound) {
+			t.Errorf("%s: expected finished task not to be found, got %v", kind, err)
+		}
+		q.Shutdown()
+	}
+}
+
+// TestSchedulerDelayedTask 测试延迟任务不阻塞之后提交的任务
+func TestSchedulerDelayedTask(t *testing.T) {
+	scheduler := queue.NewFifoScheduler(0, queue.NewDefaultLogger())
+
+	delayed := NewSimpleTask("delayed")
+	delayed.ResumeAfter(time.Hour)
+	_ = scheduler.Queue(delayed)
+	for _, name := range []string{"first", "second"} {
+		_ = scheduler.Queue(NewSimpleTask(name))
+	}
+
+	for _, name := range []string{"first", "second"} {
+		task, err := scheduler.Request()
+		if err != nil {
+			t.Fatalf("Failed to request %s: %v", name, err)
+		}
+		if got := task.(*SimpleTask).Name; got != name {
+			t.Errorf("Expected %s, got %s", name, got)
+		}
+	}
+	if _, err := scheduler.Request(); err != queue.ErrNoTaskInQueue {
+		t.Errorf("Expected ErrNoTaskInQueue for delayed task, got %v", err)
+	}
+}
+
+// fakeDownloader 前 pending 次查询返回下载中（或 pendingState），之后返回已完成
+type fakeDownloader struct {
+	mu           sync.Mutex
+	pending      int
+	pendingState downloader.Status
+	queries      int
+}
+
+func (d *fakeDownloader) CreateTask(ctx context.Context, url string, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	return &downloader.TaskHandle{ID: "fake"}, nil
+}
+
+func (d *fakeDownloader) Info(ctx context.Context, handle *downloader.TaskHandle) (*downloader.TaskStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queries++
+	status := &downloader.TaskStatus{Name: "file.bin", State: downloader.StatusDownloading, Total: 100}
+	if d.pendingState != "" {
+		status.State = d.pendingState
+	}
+	if d.queries > d.pending {
+		status.State = downloader.StatusCompleted
+		status.Downloaded = 100
+	}
+	return status, nil
+}
+
+func (d *fakeDownloader) Cancel(ctx context.Context, handle *downloader.TaskHandle) error {
+	return nil
+}
+
+func (d *fakeDownloader) SetFilesToDownload(ctx context.Context, handle *downloader.TaskHandle, args ...*downloader.SetFileToDownloadArgs) error {
+	return nil
+}
+
+func (d *fakeDownloader) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	return nil
+}
+
+func (d *fakeDownloader) Resume(ctx context.Context, handle *downloader.TaskHandle) error {
+	return nil
+}
+
+func (d *fakeDownloader) SetSpeedLimit(ctx context.Context, handle *downloader.TaskHandle, downloadKBps, uploadKBps int64) error {
+	return nil
+}
+
+func (d *fakeDownloader) Test(ctx context.Context) (string, error) {
+	return "fake", nil
+}
+
+// TestQueueSynchronousRemoteDownload 测试同步模式下远程下载任务的轮询由假时钟驱动
+func TestQueueSynchronousRemoteDownload(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		queue.NewInMemoryTaskRepository(),
+		queue.NewTaskRegistry(),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	created, err := queue.NewRemoteDownloadTask(context.Background(), "http://example.com/file.bin", "fake", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	task := created.(*queue.RemoteDownloadTask)
+	d := &fakeDownloader{pending: 3}
+	task.SetDownloader(d)
+
+	begin := time.Now()
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	if task.Status() != queue.StatusCompleted {
+		t.Fatalf("Expected completed task, got %s (%v)", task.Status(), task.Error())
+	}
+	if d.queries != 4 {
+		t.Errorf("Expected 4 status queries, got %d", d.queries)
+	}
+	// 每次轮询间隔 10 秒，只在假时钟上流逝
+	if elapsed := fake.Since(start); elapsed != 30*time.Second {
+		t.Errorf("Expected 30s on the clock, got %s", elapsed)
+	}
+	if time.Since(begin) > 5*time.Second {
+		t.Errorf("Synchronous execution should not wait in real time")
+	}
+}
+
+// TestQueueRemoteDownloadPaused 测试暂停的下载任务继续轮询，恢复并完成后任务完成
+func TestQueueRemoteDownloadPaused(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		queue.NewInMemoryTaskRepository(),
+		queue.NewTaskRegistry(),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	created, err := queue.NewRemoteDownloadTask(context.Background(), "http://example.com/file.bin", "fake", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	task := created.(*queue.RemoteDownloadTask)
+	d := &fakeDownloader{pending: 2, pendingState: downloader.StatusPaused}
+	task.SetDownloader(d)
+
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	if task.Status() != queue.StatusCompleted {
+		t.Fatalf("Expected completed task, got %s (%v)", task.Status(), task.Error())
+	}
+	if d.queries != 3 {
+		t.Errorf("Expected 3 status queries, got %d", d.queries)
+	}
+}
+
+// fakeFileDownloader 在 fakeDownloader 的基础上支持通过上传的文件创建任务
+type fakeFileDownloader struct {
+	*fakeDownloader
+	files []downloader.SourceFile
+}
+
+func (d *fakeFileDownloader) CreateTaskFromFile(ctx context.Context, file *downloader.SourceFile, options map[string]interface{}) (*downloader.TaskHandle, error) {
+	d.files = append(d.files, *file)
+	return &downloader.TaskHandle{ID: "fake"}, nil
+}
+
+// TestQueueRemoteDownloadFromFile 测试通过上传的种子文件创建下载任务，创建后状态中不再保留文件内容
+func TestQueueRemoteDownloadFromFile(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		queue.NewInMemoryTaskRepository(),
+		queue.NewTaskRegistry(),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	file := &downloader.SourceFile{Name: "ubuntu.torrent", Content: []byte("d4:infod4:name6:ubuntuee")}
+	created, err := queue.NewRemoteDownloadTaskFromFile(context.Background(), file, "fake", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	task := created.(*queue.RemoteDownloadTask)
+	d := &fakeFileDownloader{fakeDownloader: &fakeDownloader{pending: 1}}
+	task.SetDownloader(d)
+
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	if task.Status() != queue.StatusCompleted {
+		t.Fatalf("Expected completed task, got %s (%v)", task.Status(), task.Error())
+	}
+	if len(d.files) != 1 || d.files[0].Name != "ubuntu.torrent" || string(d.files[0].Content) != string(file.Content) {
+		t.Errorf("Unexpected files passed to the downloader: %+v", d.files)
+	}
+	if state := task.GetState(); state.File == nil || state.File.Name != "ubuntu.torrent" || state.File.Content != nil {
+		t.Errorf("Expected only the file name in the state, got %+v", state.File)
+	}
+	if got := task.Summarize().Props[queue.SummaryKeySrcFile]; got != "ubuntu.torrent" {
+		t.Errorf("Expected file name in the summary, got %v", got)
+	}
+
+	// 不支持文件的下载器直接失败，不重试
+	created, err = queue.NewRemoteDownloadTaskFromFile(context.Background(), file, "fake", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	task = created.(*queue.RemoteDownloadTask)
+	task.SetDownloader(&fakeDownloader{})
+
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+	if task.Status() != queue.StatusError || task.Retried() != 0 {
+		t.Errorf("Expected error without retries, got %s after %d retries (%v)", task.Status(), task.Retried(), task.Error())
+	}
+}
+
+// fakeSeedingDownloader 下载完成后一直做种，每次查询上传 uploadStep 字节，暂停后不再上传
+type fakeSeedingDownloader struct {
+	*fakeDownloader
+	uploadStep int64
+	uploaded   int64
+	paused     int
+}
+
+func (d *fakeSeedingDownloader) Info(ctx context.Context, handle *downloader.TaskHandle) (*downloader.TaskStatus, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queries++
+	if d.paused == 0 {
+		d.uploaded += d.uploadStep
+	}
+	return &downloader.TaskStatus{
+		Name:       "ubuntu.iso",
+		State:      downloader.StatusSeeding,
+		Total:      100,
+		Downloaded: 100,
+		Uploaded:   d.uploaded,
+	}, nil
+}
+
+func (d *fakeSeedingDownloader) Pause(ctx context.Context, handle *downloader.TaskHandle) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused++
+	return nil
+}
+
+// TestQueueRemoteDownloadSeedingPolicy 测试达到分享率或做种时长后暂停做种并完成任务
+func TestQueueRemoteDownloadSeedingPolicy(t *testing.T) {
+	cases := []struct {
+		name       string
+		policy     *queue.SeedingPolicy
+		uploadStep int64
+		queries    int
+	}{
+		// 每次查询上传 50 字节，第 4 次查询时分享率达到 2
+		{"ratio", &queue.SeedingPolicy{MaxRatio: 2}, 50, 4},
+		// 每 10 秒查询一次，第 1 次查询开始计时，第 7 次查询时做种满 1 分钟
+		{"seed time", &queue.SeedingPolicy{MaxSeedTime: time.Minute}, 0, 7},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			fake.SetAutoAdvance(true)
+
+			q := queue.New(
+				queue.NewDefaultLogger(),
+				queue.NewInMemoryTaskRepository(),
+				queue.NewTaskRegistry(),
+				queue.WithClock(fake),
+				queue.WithSynchronousExecution(),
+			)
+			defer q.Shutdown()
+
+			created, err := queue.NewRemoteDownloadTask(context.Background(), "magnet:?xt=urn:btih:abc", "fake", nil, nil)
+			if err != nil {
+				t.Fatalf("Failed to create task: %v", err)
+			}
+			task := created.(*queue.RemoteDownloadTask)
+			d := &fakeSeedingDownloader{fakeDownloader: &fakeDownloader{}, uploadStep: c.uploadStep}
+			task.SetDownloader(d)
+			task.SetSeedingPolicy(c.policy)
+
+			if err := q.QueueTask(context.Background(), task); err != nil {
+				t.Fatalf("Failed to queue task: %v", err)
+			}
+
+			if task.Status() != queue.StatusCompleted {
+				t.Fatalf("Expected completed task, got %s (%v)", task.Status(), task.Error())
+			}
+			if d.queries != c.queries || d.paused != 1 {
+				t.Errorf("Expected %d queries and 1 pause, got %d queries and %d pauses", c.queries, d.queries, d.paused)
+			}
+			if status := task.GetDownloadStatus(); status.State != downloader.StatusCompleted {
+				t.Errorf("Expected completed download status, got %s", status.State)
+			}
+		})
+	}
+}
+
+// memoryBroker 进程内的 Broker，模拟多个实例共享的 Redis Stream / JetStream
+type memoryBroker struct {
+	ch    chan uint64
+	acked int32
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, taskID uint64) error {
+	b.ch <- taskID
+	return nil
+}
+
+func (b *memoryBroker) Fetch(ctx context.Context, wait time.Duration) (*queue.Delivery, error) {
+	select {
+	case id := <-b.ch:
+		return &queue.Delivery{TaskID: id}, nil
+	case <-time.After(wait):
+		return nil, queue.ErrNoTaskInQueue
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (b *memoryBroker) Ack(ctx context.Context, d *queue.Delivery) error {
+	atomic.AddInt32(&b.acked, 1)
+	return nil
+}
+
+func (b *memoryBroker) Close() error {
+	return nil
+}
+
+// sharedRepository 多个实例共享的任务仓库，读取时返回副本
+type sharedRepository struct {
+	mu sync.Mutex
+	queue.TaskRepository
+}
+
+func (r *sharedRepository) Create(ctx context.Context, task *queue.TaskModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.TaskRepository.Create(ctx, task)
+}
+
+func (r *sharedRepository) Update(ctx context.Context, task *queue.TaskModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *task
+	return r.TaskRepository.Update(ctx, &c)
+}
+
+func (r *sharedRepository) GetByID(ctx context.Context, id uint64) (*queue.TaskModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, err := r.TaskRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c := *task
+	return &c, nil
+}
+
+// DistributedTask 可从存储恢复的任务，记录各任务的执行次数
+type DistributedTask struct {
+	*queue.DBTask
+}
+
+var distributedRuns sync.Map
+
+func init() {
+	queue.RegisterResumableTaskFactory("distributed_task", func(model *queue.TaskModel) queue.Task {
+		return &DistributedTask{DBTask: &queue.DBTask{TaskModel: model}}
+	})
+}
+
+func (t *DistributedTask) Do(ctx context.Context) (queue.Status, error) {
+	runs, _ := distributedRuns.LoadOrStore(t.ID(), new(int32))
+	atomic.AddInt32(runs.(*int32), 1)
+	time.Sleep(20 * time.Millisecond)
+	return queue.StatusCompleted, nil
+}
+
+// TestQueueDistributed 测试两个实例通过 Broker 组成消费组共同消费任务
+func TestQueueDistributed(t *testing.T) {
+	distributedRuns.Range(func(k, _ interface{}) bool {
+		distributedRuns.Delete(k)
+		return true
+	})
+
+	broker := &memoryBroker{ch: make(chan uint64, 100)}
+	repo := &sharedRepository{TaskRepository: queue.NewInMemoryTaskRepository()}
+
+	newInstance := func(name string) queue.Queue {
+		q := queue.New(
+			queue.NewDefaultLogger(),
+			repo,
+			queue.NewTaskRegistry(),
+			queue.WithName(name),
+			queue.WithWorkerCount(2),
+			queue.WithTaskPullInterval(10*time.Millisecond),
+			queue.WithBroker(broker, 10*time.Millisecond),
+		)
+		q.Start()
+		return q
+	}
+	q1 := newInstance("node-1")
+	q2 := newInstance("node-2")
+	defer q1.Shutdown()
+	defer q2.Shutdown()
+
+	const n = 20
+	ids := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		task := &DistributedTask{DBTask: &queue.DBTask{TaskModel: &queue.TaskModel{Type: "distributed_task"}}}
+		if err := q1.QueueTask(context.Background(), task); err != nil {
+			t.Fatalf("Failed to queue task: %v", err)
+		}
+		ids = append(ids, task.ID())
+	}
+
+	// 内存任务不可在其他实例恢复，仍由提交的实例执行
+	local := NewSimpleTask("local")
+	if err := q2.QueueTask(context.Background(), local); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for q1.SuccessTasks()+q2.SuccessTasks() < n+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !local.IsExecuted() {
+		t.Error("In-memory task should be executed by its own instance")
+	}
+	if q1.SuccessTasks()+q2.SuccessTasks() != n+1 {
+		t.Fatalf("Expected %d completed tasks, got %d + %d", n+1, q1.SuccessTasks(), q2.SuccessTasks())
+	}
+	if q1.SuccessTasks() == 0 || q2.SuccessTasks() <= 1 {
+		t.Errorf("Tasks should be shared by both instances, got %d and %d", q1.SuccessTasks(), q2.SuccessTasks())
+	}
+	if acked := atomic.LoadInt32(&broker.acked); acked != n {
+		t.Errorf("Expected %d acked deliveries, got %d", n, acked)
+	}
+
+	for _, id := range ids {
+		runs, ok := distributedRuns.Load(id)
+		if !ok || atomic.LoadInt32(runs.(*int32)) != 1 {
+			t.Errorf("Task %d should run exactly once", id)
+		}
+		model, err := repo.GetByID(context.Background(), uint64(id))
+		if err != nil || model.Status != queue.StatusCompleted {
+			t.Errorf("Task %d should be persisted as completed, got %+v", id, model)
+		}
+	}
+
+	// 重复投递已开始的任务会被丢弃
+	broker.ch <- uint64(ids[0])
+	time.Sleep(50 * time.Millisecond)
+	if runs, _ := distributedRuns.Load(ids[0]); atomic.LoadInt32(runs.(*int32)) != 1 {
+		t.Error("Duplicated delivery should not run the task again")
+	}
+}
+
 // BenchmarkQueueThroughput 基准测试：队列吞吐量
 func BenchmarkQueueThroughput(b *testing.B) {
 	logger := queue.NewDefaultLogger()
@@ -556,3 +1627,77 @@ func BenchmarkQueueThroughput(b *testing.B) {
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+// TestQueueEventTaskRetry 测试事件总线的异步订阅者以队列任务执行，失败后按队列配置重试，订阅者不存在时不再重试
+func TestQueueEventTaskRetry(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	fake.SetAutoAdvance(true)
+
+	q := queue.New(
+		queue.NewDefaultLogger(),
+		nil,
+		queue.NewTaskRegistry(),
+		queue.WithMaxRetry(3),
+		queue.WithRetryDelay(time.Minute),
+		queue.WithClock(fake),
+		queue.WithSynchronousExecution(),
+	)
+	defer q.Shutdown()
+
+	type disabledEvent struct {
+		Username string `json:"username"`
+	}
+	topic := eventbus.NewTopic[disabledEvent]("user.disabled")
+
+	var tasks []queue.Task
+	bus := eventbus.New(zap.NewNop())
+	bus.SetDispatcher(func(ctx context.Context, d eventbus.Delivery) error {
+		task, err := queue.NewEventTask(bus.Deliver, d, nil)
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, task)
+		return q.QueueTask(ctx, task)
+	})
+
+	var attempts int32
+	var received disabledEvent
+	topic.SubscribeAsync(bus, "webhook", func(ctx context.Context, e disabledEvent) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("connection refused")
+		}
+		received = e
+		return nil
+	})
+
+	topic.Publish(context.Background(), bus, disabledEvent{Username: "alice"})
+
+	if len(tasks) != 1 || tasks[0].Status() != queue.StatusCompleted {
+		t.Fatalf("Expected one completed task, got %d", len(tasks))
+	}
+	if attempts != 3 || received.Username != "alice" {
+		t.Errorf("Unexpected delivery after %d attempts: %+v", attempts, received)
+	}
+
+	summary := queue.NewEventTaskFactory(bus.Deliver)(tasks[0].Model()).Summarize()
+	if summary == nil || summary.Props[queue.SummaryKeyEventSubscriber] != "webhook" {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	// 订阅者已移除的任务直接失败，不再重试
+	var calls int32
+	deliver := func(ctx context.Context, d eventbus.Delivery) error {
+		atomic.AddInt32(&calls, 1)
+		return bus.Deliver(ctx, d)
+	}
+	task, err := queue.NewEventTask(deliver, eventbus.Delivery{Topic: topic.Name(), Subscriber: "removed", Payload: []byte(`{}`)}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := q.QueueTask(context.Background(), task); err != nil {
+		t.Fatalf("Failed to queue task: %v", err)
+	}
+	if task.Status() != queue.StatusError || calls != 1 {
+		t.Errorf("Expected failed task after 1 call, got %s after %d", task.Status(), calls)
+	}
+}
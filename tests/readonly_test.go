@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/middlewares"
+	"github.com/top-system/light-admin/lib"
+)
+
+// TestReadOnlyMiddleware 只读模式下拒绝写请求，读请求、登录及精确匹配的维护接口放行
+func TestReadOnlyMiddleware(t *testing.T) {
+	logger := newTestLogger()
+	readOnly := lib.NewReadOnly(lib.Config{Database: &lib.DatabaseConfig{}}, newTestDB(t), logger)
+
+	engine := echo.New()
+	middlewares.NewReadOnlyMiddleware(lib.HttpHandler{Engine: engine}, logger, readOnly).Setup()
+	ok := func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }
+	engine.GET("/api/v1/users", ok)
+	engine.POST("/api/v1/users", ok)
+	engine.POST("/api/v1/auth/login", ok)
+	engine.GET("/api/v1/maintenance/read-only", ok)
+	engine.PUT("/api/v1/maintenance/read-only", ok)
+	engine.DELETE("/api/v1/maintenance/read-only", ok)
+	engine.POST("/api/v1/maintenance/read-only-reset", ok)
+	engine.POST("/api/v1/debug/pprof/symbol", ok)
+	engine.POST("/api/v1/debug/pprof/profile", ok)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/users").Code, "writes pass when read-only is off")
+
+	readOnly.Enable("failover")
+	rec := serve(http.MethodPost, "/api/v1/users")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), "failover")
+
+	cases := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodGet, "/api/v1/users", http.StatusOK},
+		{http.MethodPost, "/api/v1/auth/login", http.StatusOK},
+		{http.MethodGet, "/api/v1/maintenance/read-only", http.StatusOK},
+		{http.MethodPut, "/api/v1/maintenance/read-only", http.StatusOK},
+		{http.MethodPost, "/api/v1/debug/pprof/symbol", http.StatusOK},
+		// 只放行指定的方法与路径
+		{http.MethodDelete, "/api/v1/maintenance/read-only", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/maintenance/read-only-reset", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/v1/debug/pprof/profile", http.StatusServiceUnavailable},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.code, serve(c.method, c.path).Code, "%s %s", c.method, c.path)
+	}
+
+	readOnly.Disable()
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/users").Code)
+}
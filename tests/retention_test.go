@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+)
+
+// TestRetentionCutoffPerDataType 每个数据类型按各自的保留天数及时间字段清理，不影响其他数据类型
+func TestRetentionCutoffPerDataType(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.RetentionPolicy{}, &system.Log{}, &system.LoginEvent{}, &system.CronTaskRecord{})
+	lockService := service.NewLockService(logger, newTestCache(t), repository.NewUserRepository(db, logger))
+	retentionService := service.NewRetentionService(
+		logger, lib.Config{}, lib.Crontab{}, lib.TaskQueue{}, lockService,
+		service.DownloadMediaService{}, service.DownloadService{}, repository.TagRepository{},
+		repository.TaskRepository{}, repository.NewRetentionRepository(db, logger),
+	)
+
+	daysAgo := func(days int) dto.DateTime {
+		return dto.DateTime(time.Now().AddDate(0, 0, -days))
+	}
+	for _, days := range []int{10, 60} {
+		assert.NoError(t, db.ORM.Create(&system.Log{Module: "test", CreateTime: daysAgo(days)}).Error)
+		assert.NoError(t, db.ORM.Create(&system.LoginEvent{UserID: 1, Username: "alice", CreateTime: daysAgo(days)}).Error)
+	}
+	for _, days := range []int{3, 10} {
+		assert.NoError(t, db.ORM.Create(&system.CronTaskRecord{TaskName: "test", StartTime: daysAgo(days), Status: "success"}).Error)
+	}
+
+	// 操作日志保留 30 天，登录记录保留 90 天，定时任务执行记录保留 7 天
+	policies := map[string]int{
+		system.RetentionOperationLog: 30,
+		system.RetentionLoginLog:     90,
+		system.RetentionCronRecord:   7,
+	}
+	dataTypes := make([]string, 0, len(policies))
+	for dataType, days := range policies {
+		assert.NoError(t, retentionService.Update(dataType, &system.RetentionPolicyForm{Days: days, Enable: true}, 1))
+		dataTypes = append(dataTypes, dataType)
+	}
+
+	result, err := retentionService.Run(&system.RetentionRunForm{DataTypes: dataTypes}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.ElementsMatch(t, dataTypes, result.DataTypes)
+
+	// 未启用任务队列时在后台执行，等待清理结束并释放锁
+	assert.Eventually(t, func() bool {
+		_, err := lockService.Get(system.LockRetentionPurge)
+		if !errors.Is(err, errors.LockNotFound) {
+			return false
+		}
+
+		list, err := retentionService.List()
+		if err != nil {
+			return false
+		}
+		for _, policy := range list {
+			if policies[policy.DataType] > 0 && !policy.LastRunAt.Valid {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 20*time.Millisecond)
+
+	var logs, events, records int64
+	db.ORM.Model(&system.Log{}).Count(&logs)
+	db.ORM.Model(&system.LoginEvent{}).Count(&events)
+	db.ORM.Model(&system.CronTaskRecord{}).Count(&records)
+	assert.Equal(t, int64(1), logs, "operation logs older than 30 days should be purged")
+	assert.Equal(t, int64(2), events, "login records younger than 90 days should be kept")
+	assert.Equal(t, int64(1), records, "cron records older than 7 days should be purged")
+
+	list, err := retentionService.List()
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, policy := range list {
+		switch policy.DataType {
+		case system.RetentionOperationLog, system.RetentionCronRecord:
+			assert.Equal(t, int64(1), policy.LastPurged, policy.DataType)
+		case system.RetentionLoginLog:
+			assert.Equal(t, int64(0), policy.LastPurged, policy.DataType)
+		}
+	}
+}
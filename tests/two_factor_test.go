@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/totp"
+)
+
+func newTwoFactorTestService(t *testing.T, twoFactor *lib.TwoFactorConfig) (service.TwoFactorService, repository.UserRepository) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.User{})
+	assert.NoError(t, db.ORM.Create(&system.User{ID: 1, Username: "alice"}).Error)
+
+	userRepository := repository.NewUserRepository(db, logger)
+	config := lib.Config{Name: "light-admin", Auth: &lib.AuthConfig{Secret: "jwt-secret", TwoFactor: twoFactor}}
+	return service.NewTwoFactorService(newTestCache(t), config, logger, userRepository), userRepository
+}
+
+func TestTwoFactorRequiresConfiguration(t *testing.T) {
+	twoFactorService, userRepository := newTwoFactorTestService(t, nil)
+	claims := &dto.JwtClaims{ID: 1, Username: "alice"}
+
+	_, err := twoFactorService.Enroll(claims)
+	assert.True(t, errors.Is(err, errors.TwoFactorDisabled))
+	assert.True(t, errors.Is(twoFactorService.Activate(claims, "123456"), errors.TwoFactorDisabled))
+
+	// 已启用两步验证的用户在配置移除后不能跳过验证码登录
+	assert.NoError(t, userRepository.UpdateTwoFactor(1, "sealed", true))
+	user, _ := userRepository.Get(1)
+	assert.True(t, errors.Is(twoFactorService.VerifyLogin(user, ""), errors.TwoFactorDisabled))
+
+	assert.NoError(t, twoFactorService.Reset(1))
+	user, _ = userRepository.Get(1)
+	assert.NoError(t, twoFactorService.VerifyLogin(user, ""))
+}
+
+func TestTwoFactorEnrollWithEncryptKey(t *testing.T) {
+	twoFactorService, userRepository := newTwoFactorTestService(t, &lib.TwoFactorConfig{EncryptKey: "totp-encrypt-key"})
+	claims := &dto.JwtClaims{ID: 1, Username: "alice"}
+
+	resp, err := twoFactorService.Enroll(claims)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user, _ := userRepository.Get(1)
+	assert.NotEmpty(t, user.TwoFactorSecret)
+	assert.NotContains(t, user.TwoFactorSecret, resp.Secret)
+
+	code, err := totp.Code(resp.Secret, totp.Counter(time.Now()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, twoFactorService.Activate(claims, code))
+
+	user, _ = userRepository.Get(1)
+	assert.True(t, user.TwoFactorEnabled)
+	assert.True(t, errors.Is(twoFactorService.VerifyLogin(user, ""), errors.AuthTwoFactorRequired))
+}
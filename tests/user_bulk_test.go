@@ -0,0 +1,299 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/top-system/light-admin/api/system/controller"
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/constants"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/dto"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+)
+
+type userBulkFixture struct {
+	db                 lib.Database
+	userService        service.UserService
+	dataScopeService   service.DataScopeService
+	userRoleRepository repository.UserRoleRepository
+}
+
+// newUserBulkFixture 角色 1（编辑）只有用户管理权限，角色 2（管理员）另有角色管理权限；
+// 用户 1 为操作人，拥有角色 1，用户 2（部门 10）、3（部门 20）为普通用户，用户 2 拥有角色 1
+func newUserBulkFixture(t *testing.T) *userBulkFixture {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.User{}, &system.Dept{}, &system.RoleDept{}, &system.UserRole{}, &system.Role{},
+		&system.RoleMenu{}, &system.Menu{}, &system.UserPosition{}, &system.ApiKey{}, &system.RefreshToken{})
+
+	for _, menu := range []*system.Menu{
+		{ID: 1, Name: "查询用户", Type: 4, Perm: "sys:user:query"},
+		{ID: 2, Name: "修改用户", Type: 4, Perm: "sys:user:edit"},
+		{ID: 3, Name: "修改角色", Type: 4, Perm: "sys:role:edit"},
+	} {
+		assert.NoError(t, db.ORM.Create(menu).Error)
+	}
+	for _, role := range []*system.Role{
+		{ID: 1, Name: "编辑", Code: "EDITOR", Status: 1},
+		{ID: 2, Name: "管理员", Code: "ADMIN", Status: 1},
+	} {
+		assert.NoError(t, db.ORM.Create(role).Error)
+	}
+	for _, rm := range []*system.RoleMenu{{RoleID: 1, MenuID: 1}, {RoleID: 1, MenuID: 2}, {RoleID: 2, MenuID: 1}, {RoleID: 2, MenuID: 2}, {RoleID: 2, MenuID: 3}} {
+		assert.NoError(t, db.ORM.Create(rm).Error)
+	}
+	for _, user := range []*system.User{
+		{ID: 1, Username: "operator", Status: 1},
+		{ID: 2, Username: "alice", Status: 1, DeptID: 10},
+		{ID: 3, Username: "bob", Status: 1, DeptID: 20},
+	} {
+		assert.NoError(t, db.ORM.Create(user).Error)
+	}
+	for _, ur := range []*system.UserRole{{UserID: 1, RoleID: 1}, {UserID: 2, RoleID: 1}} {
+		assert.NoError(t, db.ORM.Create(ur).Error)
+	}
+
+	config := lib.Config{SuperAdmin: &lib.SuperAdminConfig{Username: "root"}}
+	userRepository := repository.NewUserRepository(db, logger)
+	userRoleRepository := repository.NewUserRoleRepository(db, logger)
+	roleRepository := repository.NewRoleRepository(db, logger)
+	roleMenuRepository := repository.NewRoleMenuRepository(db, logger)
+	menuRepository := repository.NewMenuRepository(db, logger)
+	deptRepository := repository.NewDeptRepository(db, logger, lib.DBCompat{})
+	permissionCache := service.NewPermissionCache(logger, newTestCache(t), userRoleRepository)
+	permissionService := service.NewPermissionService(logger, lib.HttpHandler{}, lib.NewPermRegistry(), lib.FeatureModules{},
+		permissionCache, menuRepository, roleMenuRepository, userRoleRepository, roleRepository)
+
+	return &userBulkFixture{
+		db: db,
+		userService: service.NewUserService(
+			logger, config, db, userRepository, userRoleRepository, repository.NewUserPositionRepository(db, logger),
+			repository.NewApiKeyRepository(db, logger), roleRepository, roleMenuRepository, menuRepository, deptRepository,
+			permissionCache, permissionService, lib.NewResponseCache(lib.Config{}, newTestCache(t), logger),
+			service.DeptRoleService{}, newTestAuthService(t, newTestAuthConfig(), db), service.WebhookService{}, eventbus.New(logger.DesugarZap),
+		),
+		dataScopeService: service.NewDataScopeService(config, logger, userRepository, roleRepository,
+			repository.NewRoleDeptRepository(db, logger), deptRepository, permissionService),
+		userRoleRepository: userRoleRepository,
+	}
+}
+
+func (f *userBulkFixture) roleIDs(t *testing.T, userID uint64) []uint64 {
+	t.Helper()
+
+	ids, err := f.userRoleRepository.GetRoleIDsByUserID(userID)
+	assert.NoError(t, err)
+	return ids
+}
+
+func (f *userBulkFixture) status(t *testing.T, userID uint64) int {
+	t.Helper()
+
+	var user system.User
+	assert.NoError(t, f.db.ORM.First(&user, userID).Error)
+	return user.Status
+}
+
+// newUserBulkEngine 以 claims 的身份调用批量接口，与事务中间件一样在出错时回滚请求事务
+func newUserBulkEngine(f *userBulkFixture, claims *dto.JwtClaims) *echo.Echo {
+	engine := echo.New()
+	engine.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			tx := f.db.ORM.Begin()
+			ctx.Set(constants.CurrentUser, claims)
+			ctx.Set(constants.DBTransaction, tx)
+			err := next(ctx)
+			if ctx.Response().Status >= http.StatusBadRequest {
+				tx.Rollback()
+			} else {
+				tx.Commit()
+			}
+			return err
+		}
+	})
+
+	userController := controller.NewUserController(f.userService, service.TwoFactorService{}, nil,
+		service.SavedQueryService{}, f.dataScopeService, service.ExportService{}, newTestLogger())
+	engine.PUT("/api/v1/users/status", userController.UpdateStatus)
+	engine.PUT("/api/v1/users/roles", userController.UpdateRoles)
+	engine.POST("/api/v1/users/bulk", userController.Bulk)
+	return engine
+}
+
+func putUserBulk(engine *echo.Echo, method, path, body string) (int, *system.UserBulkResult) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	engine.ServeHTTP(rec, req)
+
+	var resp struct {
+		Data *system.UserBulkResult `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp.Data
+}
+
+// TestUserBatchStatusEndpoint PUT /users/status 批量启用/禁用，当前用户被跳过
+func TestUserBatchStatusEndpoint(t *testing.T) {
+	f := newUserBulkFixture(t)
+	engine := newUserBulkEngine(f, &dto.JwtClaims{ID: 1, Username: "operator"})
+
+	code, result := putUserBulk(engine, http.MethodPut, "/api/v1/users/status", `{"userIds":[1,2,3],"status":0}`)
+	if assert.Equal(t, http.StatusOK, code) && assert.NotNil(t, result) {
+		assert.Equal(t, system.UserBulkDisable, result.Operation)
+		assert.Equal(t, 2, result.Succeeded)
+		assert.False(t, result.Results[0].Success)
+	}
+	assert.Equal(t, constants.StatusEnable, f.status(t, 1))
+	assert.Equal(t, constants.StatusDisable, f.status(t, 2))
+	assert.Equal(t, constants.StatusDisable, f.status(t, 3))
+
+	code, result = putUserBulk(engine, http.MethodPut, "/api/v1/users/status", `{"userIds":[2,3],"status":1}`)
+	if assert.Equal(t, http.StatusOK, code) && assert.NotNil(t, result) {
+		assert.Equal(t, system.UserBulkEnable, result.Operation)
+	}
+	assert.Equal(t, constants.StatusEnable, f.status(t, 2))
+	assert.Equal(t, constants.StatusEnable, f.status(t, 3))
+}
+
+// TestUserBatchRolesEndpoint PUT /users/roles 替换用户角色，不能授予超出当前身份权限的角色
+func TestUserBatchRolesEndpoint(t *testing.T) {
+	f := newUserBulkFixture(t)
+	engine := newUserBulkEngine(f, &dto.JwtClaims{ID: 1, Username: "operator"})
+
+	code, result := putUserBulk(engine, http.MethodPut, "/api/v1/users/roles", `{"userIds":[2,3],"roleIds":[1]}`)
+	if assert.Equal(t, http.StatusOK, code) && assert.NotNil(t, result) {
+		assert.Equal(t, 2, result.Succeeded)
+	}
+	assert.Equal(t, []uint64{1}, f.roleIDs(t, 3))
+
+	// 管理员角色包含操作人没有的 sys:role:edit
+	code, _ = putUserBulk(engine, http.MethodPut, "/api/v1/users/roles", `{"userIds":[3],"roleIds":[2]}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = putUserBulk(engine, http.MethodPost, "/api/v1/users/bulk", `{"userIds":[3],"operation":"assign_roles","roleIds":[2]}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, []uint64{1}, f.roleIDs(t, 3))
+
+	// 移除角色不受限制
+	code, _ = putUserBulk(engine, http.MethodPost, "/api/v1/users/bulk", `{"userIds":[3],"operation":"remove_roles","roleIds":[1]}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, f.roleIDs(t, 3))
+
+	// API Key 按授权范围判断
+	apiKey := newUserBulkEngine(f, &dto.JwtClaims{ID: 1, Username: "operator", ApiKeyID: 7, Scopes: []string{"sys:user:edit"}})
+	code, _ = putUserBulk(apiKey, http.MethodPut, "/api/v1/users/roles", `{"userIds":[3],"roleIds":[1]}`)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// 超级管理员可以授予任意角色
+	root := newUserBulkEngine(f, &dto.JwtClaims{Username: "root"})
+	code, _ = putUserBulk(root, http.MethodPut, "/api/v1/users/roles", `{"userIds":[3],"roleIds":[2]}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []uint64{2}, f.roleIDs(t, 3))
+}
+
+func (f *userBulkFixture) deleted(t *testing.T, userID uint64) bool {
+	t.Helper()
+
+	var user system.User
+	assert.NoError(t, f.db.ORM.First(&user, userID).Error)
+	return user.IsDeleted == 1
+}
+
+// TestUserBulkResults 逐个用户返回结果：不存在的用户、当前用户失败，其余用户成功
+func TestUserBulkResults(t *testing.T) {
+	for _, operation := range []string{system.UserBulkDisable, system.UserBulkDelete} {
+		f := newUserBulkFixture(t)
+		result, err := f.userService.Bulk(&system.UserBulkForm{UserIDs: []uint64{99, 1, 2, 2}, Operation: operation}, 1)
+		if !assert.NoError(t, err, operation) {
+			continue
+		}
+		assert.Equal(t, 3, result.Total, operation)
+		assert.Equal(t, 1, result.Succeeded, operation)
+		assert.Equal(t, 2, result.Failed, operation)
+		if assert.Len(t, result.Results, 3) {
+			assert.Equal(t, errors.UserRecordNotFound.Error(), result.Results[0].Message)
+			assert.False(t, result.Results[1].Success)
+			assert.Equal(t, "operator", result.Results[1].Username)
+			assert.True(t, result.Results[2].Success)
+		}
+	}
+
+	f := newUserBulkFixture(t)
+	result, err := f.userService.Bulk(&system.UserBulkForm{UserIDs: []uint64{1}, Operation: system.UserBulkDisable}, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, errors.UserBulkSelfDisable.Error(), result.Results[0].Message)
+	}
+	assert.Equal(t, constants.StatusEnable, f.status(t, 1))
+
+	result, err = f.userService.Bulk(&system.UserBulkForm{UserIDs: []uint64{1, 3}, Operation: system.UserBulkDelete}, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, errors.UserBulkSelfDelete.Error(), result.Results[0].Message)
+	}
+	assert.False(t, f.deleted(t, 1))
+	assert.True(t, f.deleted(t, 3))
+
+	// 启用不限制当前用户
+	result, err = f.userService.Bulk(&system.UserBulkForm{UserIDs: []uint64{1}, Operation: system.UserBulkEnable}, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, result.Succeeded)
+	}
+
+	_, err = f.userService.Bulk(&system.UserBulkForm{UserIDs: []uint64{0}, Operation: system.UserBulkEnable}, 1)
+	assert.True(t, errors.Is(err, errors.UserBulkEmpty))
+	_, err = f.userService.Bulk(&system.UserBulkForm{UserIDs: []uint64{2}, Operation: "archive"}, 1)
+	assert.True(t, errors.Is(err, errors.UserBulkInvalidOperation))
+}
+
+// TestUserBulkDataScope 数据范围之外的用户被跳过，不影响范围内的用户
+func TestUserBulkDataScope(t *testing.T) {
+	f := newUserBulkFixture(t)
+
+	result, err := f.userService.Bulk(&system.UserBulkForm{
+		UserIDs:   []uint64{2, 3},
+		Operation: system.UserBulkDisable,
+		DataScope: &system.DataScope{DeptIDs: []uint64{10}},
+	}, 1)
+	if assert.NoError(t, err) && assert.Len(t, result.Results, 2) {
+		assert.True(t, result.Results[0].Success)
+		assert.False(t, result.Results[1].Success)
+		assert.Equal(t, errors.UserOutOfDataScope.Error(), result.Results[1].Message)
+	}
+	assert.Equal(t, constants.StatusDisable, f.status(t, 2))
+	assert.Equal(t, constants.StatusEnable, f.status(t, 3))
+
+	// 目标部门也必须在数据范围内
+	_, err = f.userService.Bulk(&system.UserBulkForm{
+		UserIDs:   []uint64{2},
+		Operation: system.UserBulkTransferDept,
+		DeptID:    20,
+		DataScope: &system.DataScope{DeptIDs: []uint64{10}},
+	}, 1)
+	assert.True(t, errors.Is(err, errors.UserDeptOutOfDataScope))
+}
+
+// TestUserBulkRollback 任一步骤失败时整批操作在同一事务中回滚
+func TestUserBulkRollback(t *testing.T) {
+	f := newUserBulkFixture(t)
+	assert.NoError(t, f.db.ORM.Migrator().DropTable(&system.ApiKey{}))
+
+	err := f.db.ORM.Transaction(func(tx *gorm.DB) error {
+		_, err := f.userService.WithTrx(tx).Bulk(&system.UserBulkForm{UserIDs: []uint64{2, 3}, Operation: system.UserBulkDelete}, 1)
+		return err
+	})
+	assert.Error(t, err)
+
+	// 删除 API Key 前已删除的角色关联随事务回滚
+	assert.Equal(t, []uint64{1}, f.roleIDs(t, 2))
+	assert.False(t, f.deleted(t, 2))
+	assert.False(t, f.deleted(t, 3))
+}
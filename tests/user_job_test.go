@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/database"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/crontab"
+)
+
+type userJobFixture struct {
+	db                lib.Database
+	cache             service.PermissionCache
+	userJobService    service.UserJobService
+	userJobRepository repository.UserJobRepository
+}
+
+// newUserJobFixture alice(1) 拥有 sys:download:add，bob(2) 没有
+func newUserJobFixture(t *testing.T) *userJobFixture {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.User{}, &system.UserJob{}, &system.UserRole{}, &system.RoleMenu{}, &system.Menu{}, &system.Role{})
+
+	assert.NoError(t, db.ORM.Create(&system.User{ID: 1, Username: "alice", Status: 1}).Error)
+	assert.NoError(t, db.ORM.Create(&system.User{ID: 2, Username: "bob", Status: 1}).Error)
+	assert.NoError(t, db.ORM.Create(&system.Menu{ID: 1, Name: "新增下载", Type: 4, Perm: "sys:download:add"}).Error)
+	assert.NoError(t, db.ORM.Create(&system.RoleMenu{RoleID: 1, MenuID: 1}).Error)
+	assert.NoError(t, db.ORM.Create(&system.UserRole{UserID: 1, RoleID: 1}).Error)
+
+	config := lib.Config{
+		SuperAdmin: &lib.SuperAdminConfig{Username: "root"},
+		UserJobs:   &lib.UserJobConfig{Enable: true, MinInterval: 1},
+	}
+	userRoleRepository := repository.NewUserRoleRepository(db, logger)
+	cache := service.NewPermissionCache(logger, newTestCache(t), userRoleRepository)
+	permissionService := service.NewPermissionService(
+		logger, lib.HttpHandler{}, lib.NewPermRegistry(), lib.NewFeatureModules(), cache,
+		repository.NewMenuRepository(db, logger), repository.NewRoleMenuRepository(db, logger),
+		userRoleRepository, repository.NewRoleRepository(db, logger),
+	)
+	userJobRepository := repository.NewUserJobRepository(db, logger)
+
+	userJobService := service.NewUserJobService(
+		logger, config, lib.Crontab{Cron: crontab.New(crontab.NewDefaultLogger())}, lib.TaskQueue{}, lib.NewFeatureModules(),
+		nil, service.NoticeService{}, service.DownloadService{},
+		newApiKeyTestUserService(), permissionService,
+		userJobRepository, repository.NewUserRepository(db, logger),
+		repository.LoginEventRepository{}, repository.ApiUsageRepository{},
+	)
+
+	return &userJobFixture{db: db, cache: cache, userJobService: userJobService, userJobRepository: userJobRepository}
+}
+
+func urlDownloadJobForm() *system.UserJobForm {
+	return &system.UserJobForm{
+		Name:     "nightly",
+		TaskType: system.UserJobURLDownload,
+		Spec:     "0 0 3 * * *",
+		Payload:  database.JSONB(`{"url":"https://example.com/file.zip"}`),
+	}
+}
+
+func TestUserJobURLDownloadRequiresPerm(t *testing.T) {
+	f := newUserJobFixture(t)
+
+	_, err := f.userJobService.Create(urlDownloadJobForm(), 2)
+	assert.True(t, errors.Is(err, errors.UserJobPermDenied))
+
+	catalog, err := f.userJobService.Catalog(2)
+	if assert.NoError(t, err) {
+		for _, item := range catalog.Types {
+			assert.NotEqual(t, system.UserJobURLDownload, item.Type)
+		}
+	}
+
+	_, err = f.userJobService.Create(urlDownloadJobForm(), 1)
+	assert.NoError(t, err)
+}
+
+// TestUserJobURLDownloadRechecksPermOnRun 创建后被收回权限的任务执行失败
+func TestUserJobURLDownloadRechecksPermOnRun(t *testing.T) {
+	f := newUserJobFixture(t)
+
+	job, err := f.userJobService.Create(urlDownloadJobForm(), 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, f.db.ORM.Where("user_id = ?", 1).Delete(&system.UserRole{}).Error)
+	f.cache.InvalidateUserCache(1)
+
+	assert.NoError(t, f.userJobService.RunNow(job.ID, 1))
+	assert.Eventually(t, func() bool {
+		stored, err := f.userJobRepository.Get(job.ID)
+		return err == nil && stored.LastStatus == system.UserJobRunFailed
+	}, 5*time.Second, 20*time.Millisecond)
+
+	stored, _ := f.userJobRepository.Get(job.ID)
+	assert.Contains(t, stored.LastError, errors.UserJobPermDenied.Error())
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/lib"
+	"github.com/top-system/light-admin/models/system"
+	"github.com/top-system/light-admin/pkg/eventbus"
+)
+
+// TestWebhookRejectsInternalURL 投递由服务端发起，注册或修改为内网地址时拒绝
+func TestWebhookRejectsInternalURL(t *testing.T) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.Webhook{}, &system.WebhookDelivery{})
+	webhookService := service.NewWebhookService(
+		logger, lib.Config{}, lib.TaskQueue{}, eventbus.New(zap.NewNop()),
+		repository.NewWebhookRepository(db, logger), repository.NewWebhookDeliveryRepository(db, logger),
+	)
+
+	form := &system.WebhookForm{Name: "hook", Events: []string{"*"}, Status: 1}
+	for _, u := range []string{"http://127.0.0.1:9000/hook", "http://localhost/hook", "http://169.254.169.254/latest/meta-data", "http://[::1]/hook", "file:///etc/passwd"} {
+		form.URL = u
+		_, err := webhookService.Create(form, 1)
+		assert.True(t, errors.Is(err, errors.WebhookURLInvalid), u)
+	}
+
+	form.URL = "https://93.184.216.34/hook"
+	created, err := webhookService.Create(form, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	form.URL = "http://10.0.0.1/hook"
+	assert.True(t, errors.Is(webhookService.Update(created.ID, form, 1), errors.WebhookURLInvalid))
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/top-system/light-admin/api/system/repository"
+	"github.com/top-system/light-admin/api/system/service"
+	"github.com/top-system/light-admin/errors"
+	"github.com/top-system/light-admin/models/system"
+	ws "github.com/top-system/light-admin/pkg/websocket"
+)
+
+func newWsEventTestService(t *testing.T) (service.WsEventService, repository.WsEventRepository) {
+	logger := newTestLogger()
+	db := newTestDB(t, &system.WsEvent{}, &system.WsEventDelivery{})
+	wsEventRepository := repository.NewWsEventRepository(db, logger)
+
+	return service.NewWsEventService(logger, ws.New(zap.NewNop()), wsEventRepository), wsEventRepository
+}
+
+// TestWsEventAckRequiresTarget 只有推送对象可以回执，其他用户的回执不会写入送达记录
+func TestWsEventAckRequiresTarget(t *testing.T) {
+	svc, wsEventRepository := newWsEventTestService(t)
+
+	users := system.Users{{ID: 1, Username: "alice"}}
+	eventID, err := svc.Notify(system.WsEventNotice, "hello", users, map[string]interface{}{}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, svc.Ack("alice", &ws.EventAck{EventID: eventID, Status: ws.EventAckDisplayed}))
+	err = svc.Ack("bob", &ws.EventAck{EventID: eventID, Status: ws.EventAckReceived})
+	assert.True(t, errors.Is(err, errors.WsEventNotTargeted))
+
+	deliveries, err := wsEventRepository.ListDeliveries(eventID)
+	if assert.NoError(t, err) && assert.Len(t, deliveries, 1) {
+		assert.Equal(t, "alice", deliveries[0].Username)
+		assert.True(t, deliveries[0].ReceivedTime.Valid)
+		assert.True(t, deliveries[0].DisplayedTime.Valid)
+	}
+}
+
+// TestWsEventBannerAckBackfillsDelivery 横幅推送给全部在线用户，推送后上线的用户回执时补建送达记录
+func TestWsEventBannerAckBackfillsDelivery(t *testing.T) {
+	svc, wsEventRepository := newWsEventTestService(t)
+
+	eventID, err := svc.Banner(&system.WsBannerForm{Title: "maintenance", Content: "tonight"}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, svc.Ack("bob", &ws.EventAck{EventID: eventID, Status: ws.EventAckReceived}))
+
+	deliveries, err := wsEventRepository.ListDeliveries(eventID)
+	if assert.NoError(t, err) && assert.Len(t, deliveries, 1) {
+		assert.Equal(t, "bob", deliveries[0].Username)
+		assert.True(t, deliveries[0].ReceivedTime.Valid)
+		assert.False(t, deliveries[0].DisplayedTime.Valid)
+	}
+}